@@ -37,6 +37,7 @@ const (
 	CloudProviderAliyun     = domain.CloudProviderAliyun
 	CloudProviderAWS        = domain.CloudProviderAWS
 	CloudProviderAzure      = domain.CloudProviderAzure
+	CloudProviderGCP        = domain.CloudProviderGCP
 	CloudProviderTencent    = domain.CloudProviderTencent
 	CloudProviderHuawei     = domain.CloudProviderHuawei
 	CloudProviderVolcano    = domain.CloudProviderVolcano
@@ -3,9 +3,12 @@ package channel
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Message 通知消息
@@ -14,6 +17,8 @@ type Message struct {
 	Content  string
 	Severity domain.Severity
 	Markdown bool
+	// Event 关联的告警事件，Webhook 与事件平台渠道据此输出结构化字段；测试消息等为 nil
+	Event *domain.AlertEvent
 }
 
 // Sender 通知发送接口
@@ -29,20 +34,20 @@ func NewSender(ch domain.NotificationChannel) (Sender, error) {
 		webhook, _ := ch.Config["webhook"].(string)
 		secret, _ := ch.Config["secret"].(string)
 		if webhook == "" {
-			return nil, fmt.Errorf("dingtalk webhook is required")
+			return nil, fmt.Errorf("%w: dingtalk webhook is required", ErrInvalidConfig)
 		}
 		return NewDingTalkSender(webhook, secret), nil
 	case domain.ChannelWeCom:
 		webhook, _ := ch.Config["webhook"].(string)
 		if webhook == "" {
-			return nil, fmt.Errorf("wecom webhook is required")
+			return nil, fmt.Errorf("%w: wecom webhook is required", ErrInvalidConfig)
 		}
 		return NewWeComSender(webhook), nil
 	case domain.ChannelFeishu:
 		webhook, _ := ch.Config["webhook"].(string)
 		secret, _ := ch.Config["secret"].(string)
 		if webhook == "" {
-			return nil, fmt.Errorf("feishu webhook is required")
+			return nil, fmt.Errorf("%w: feishu webhook is required", ErrInvalidConfig)
 		}
 		return NewFeishuSender(webhook, secret), nil
 	case domain.ChannelEmail:
@@ -59,14 +64,60 @@ func NewSender(ch domain.NotificationChannel) (Sender, error) {
 			}
 		}
 		if host == "" || len(to) == 0 {
-			return nil, fmt.Errorf("email smtp_host and to are required")
+			return nil, fmt.Errorf("%w: email smtp_host and to are required", ErrInvalidConfig)
 		}
 		return NewEmailSender(host, int(portF), user, pass, from, to), nil
+	case domain.ChannelWebhook:
+		webhookURL, _ := ch.Config["url"].(string)
+		if webhookURL == "" {
+			return nil, fmt.Errorf("%w: webhook url is required", ErrInvalidConfig)
+		}
+		method, _ := ch.Config["method"].(string)
+		secret, _ := ch.Config["secret"].(string)
+		return NewWebhookSender(WebhookConfig{
+			URL:        webhookURL,
+			Method:     method,
+			Headers:    configStringMap(ch.Config["headers"]),
+			Secret:     secret,
+			MaxRetries: configInt(ch.Config, "max_retries", defaultMaxRetries),
+			Timeout:    time.Duration(configInt(ch.Config, "timeout", 0)) * time.Second,
+		}), nil
+	case domain.ChannelSlack:
+		webhook, _ := ch.Config["webhook"].(string)
+		if webhook == "" {
+			return nil, fmt.Errorf("%w: slack webhook is required", ErrInvalidConfig)
+		}
+		return NewSlackSender(webhook), nil
+	case domain.ChannelIncident:
+		routingKey, _ := ch.Config["routing_key"].(string)
+		if routingKey == "" {
+			return nil, fmt.Errorf("%w: incident routing_key is required", ErrInvalidConfig)
+		}
+		incidentURL, _ := ch.Config["url"].(string)
+		source, _ := ch.Config["source"].(string)
+		return NewIncidentSender(IncidentConfig{
+			URL:        incidentURL,
+			RoutingKey: routingKey,
+			Source:     source,
+			MaxRetries: configInt(ch.Config, "max_retries", defaultMaxRetries),
+		}), nil
 	default:
-		return nil, fmt.Errorf("unsupported channel type: %s", ch.Type)
+		return nil, fmt.Errorf("%w: unsupported channel type: %s", ErrInvalidConfig, ch.Type)
 	}
 }
 
+// NewTrackedSender 创建由调用方记录投递并负责重试的发送器：关闭渠道内置重试，每次 Send 只请求一次，
+// 避免内置重试与投递重试叠加导致单次尝试耗时过长、重复计数
+func NewTrackedSender(ch domain.NotificationChannel) (Sender, error) {
+	cfg := make(map[string]any, len(ch.Config)+1)
+	for k, v := range ch.Config {
+		cfg[k] = v
+	}
+	cfg["max_retries"] = 0
+	ch.Config = cfg
+	return NewSender(ch)
+}
+
 // Dispatcher 渠道分发器
 type Dispatcher struct {
 	senders []Sender
@@ -88,13 +139,65 @@ func NewDispatcher(channels []domain.NotificationChannel) *Dispatcher {
 	return d
 }
 
+// Len 可用发送器数量
+func (d *Dispatcher) Len() int {
+	return len(d.senders)
+}
+
 // Dispatch 分发消息到所有渠道
 func (d *Dispatcher) Dispatch(ctx context.Context, msg *Message) error {
-	var lastErr error
+	return d.DispatchEach(ctx, func(domain.ChannelType) *Message { return msg })
+}
+
+// DispatchEach 按渠道类型构建消息并分发，用于按渠道类型套用消息模板
+// 单个渠道失败不影响其他渠道，返回所有失败渠道的错误
+func (d *Dispatcher) DispatchEach(ctx context.Context, build func(domain.ChannelType) *Message) error {
+	var errs []error
 	for _, sender := range d.senders {
-		if err := sender.Send(ctx, msg); err != nil {
-			lastErr = fmt.Errorf("send to %s failed: %w", sender.Type(), err)
+		if err := sender.Send(ctx, build(sender.Type())); err != nil {
+			errs = append(errs, fmt.Errorf("send to %s failed: %w", sender.Type(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// configInt 读取数值配置：JSON 解码为 float64，从 MongoDB 读出可能为整型
+func configInt(cfg map[string]any, key string, def int) int {
+	switch v := cfg[key].(type) {
+	case float64:
+		return int(v)
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return def
+}
+
+// configStringMap 读取字符串映射配置，兼容从 MongoDB 读出的嵌套文档
+func configStringMap(v any) map[string]string {
+	result := make(map[string]string)
+	switch m := v.(type) {
+	case map[string]any:
+		for k, val := range m {
+			if s, ok := val.(string); ok {
+				result[k] = s
+			}
+		}
+	case primitive.M:
+		for k, val := range m {
+			if s, ok := val.(string); ok {
+				result[k] = s
+			}
+		}
+	case primitive.D:
+		for _, e := range m {
+			if s, ok := e.Value.(string); ok {
+				result[e.Key] = s
+			}
 		}
 	}
-	return lastErr
+	return result
 }
@@ -0,0 +1,272 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recorder 记录收到的请求，前 failures 次返回 failStatus
+type recorder struct {
+	failures   int32
+	failStatus int
+	okStatus   int
+	calls      atomic.Int32
+	header     http.Header
+	body       []byte
+}
+
+func (r *recorder) server(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := r.calls.Add(1)
+		r.header = req.Header.Clone()
+		r.body, _ = io.ReadAll(req.Body)
+		if n <= r.failures {
+			w.WriteHeader(r.failStatus)
+			return
+		}
+		if r.okStatus != 0 {
+			w.WriteHeader(r.okStatus)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testMessage() *Message {
+	return &Message{
+		Title:    "资源即将过期",
+		Content:  "**资源**: i-1\n",
+		Severity: domain.SeverityCritical,
+		Markdown: true,
+		Event: &domain.AlertEvent{
+			ID: 7, Type: domain.AlertTypeExpiration, Severity: domain.SeverityCritical,
+			Content: map[string]any{"asset_id": "i-1"}, Resource: "i-1", Fingerprint: "fp-1",
+			Status: domain.EventStatusPending,
+		},
+	}
+}
+
+func TestWebhookSender_SignAndHeaders(t *testing.T) {
+	rec := &recorder{}
+	srv := rec.server(t)
+
+	sender := NewWebhookSender(WebhookConfig{
+		URL:     srv.URL,
+		Method:  http.MethodPut,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Secret:  "s3cret",
+	})
+	require.NoError(t, sender.Send(context.Background(), testMessage()))
+
+	assert.Equal(t, "Bearer token", rec.header.Get("Authorization"))
+	ts, err := strconv.ParseInt(rec.header.Get(WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, SignWebhook("s3cret", ts, rec.body), rec.header.Get(WebhookSignatureHeader))
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(rec.body, &payload))
+	assert.Equal(t, "资源即将过期", payload.Title)
+	assert.Equal(t, domain.SeverityCritical, payload.Severity)
+	require.NotNil(t, payload.Event)
+	assert.Equal(t, "fp-1", payload.Event.Fingerprint)
+}
+
+func TestWebhookSender_Retry(t *testing.T) {
+	rec := &recorder{failures: 2, failStatus: http.StatusBadGateway}
+	srv := rec.server(t)
+
+	sender := NewWebhookSender(WebhookConfig{URL: srv.URL, MaxRetries: 3, Backoff: time.Millisecond})
+	require.NoError(t, sender.Send(context.Background(), testMessage()))
+	assert.Equal(t, int32(3), rec.calls.Load())
+
+	// 重试次数用尽
+	rec = &recorder{failures: 10, failStatus: http.StatusServiceUnavailable}
+	srv = rec.server(t)
+	sender = NewWebhookSender(WebhookConfig{URL: srv.URL, MaxRetries: 2, Backoff: time.Millisecond})
+	err := sender.Send(context.Background(), testMessage())
+	require.Error(t, err)
+	assert.Equal(t, int32(3), rec.calls.Load())
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.Code)
+
+	// 4xx 为配置错误，不重试
+	rec = &recorder{failures: 10, failStatus: http.StatusUnauthorized}
+	srv = rec.server(t)
+	sender = NewWebhookSender(WebhookConfig{URL: srv.URL, MaxRetries: 3, Backoff: time.Millisecond})
+	require.Error(t, sender.Send(context.Background(), testMessage()))
+	assert.Equal(t, int32(1), rec.calls.Load())
+}
+
+func TestSlackSender(t *testing.T) {
+	rec := &recorder{}
+	srv := rec.server(t)
+
+	require.NoError(t, NewSlackSender(srv.URL).Send(context.Background(), testMessage()))
+
+	var body struct {
+		Text        string `json:"text"`
+		Attachments []struct {
+			Color string `json:"color"`
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		} `json:"attachments"`
+	}
+	require.NoError(t, json.Unmarshal(rec.body, &body))
+	assert.Equal(t, "[critical] 资源即将过期", body.Text)
+	require.Len(t, body.Attachments, 1)
+	assert.Equal(t, "#d32f2f", body.Attachments[0].Color)
+	assert.Equal(t, "*资源*: i-1\n", body.Attachments[0].Text, "转换为 Slack mrkdwn 加粗语法")
+}
+
+func TestIncidentSender(t *testing.T) {
+	rec := &recorder{okStatus: http.StatusAccepted}
+	srv := rec.server(t)
+	sender := NewIncidentSender(IncidentConfig{URL: srv.URL, RoutingKey: "rk"})
+
+	msg := testMessage()
+	require.NoError(t, sender.Send(context.Background(), msg))
+
+	var body struct {
+		RoutingKey  string `json:"routing_key"`
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key"`
+		Payload     struct {
+			Summary       string         `json:"summary"`
+			Source        string         `json:"source"`
+			Severity      string         `json:"severity"`
+			CustomDetails map[string]any `json:"custom_details"`
+		} `json:"payload"`
+	}
+	require.NoError(t, json.Unmarshal(rec.body, &body))
+	assert.Equal(t, "rk", body.RoutingKey)
+	assert.Equal(t, "trigger", body.EventAction)
+	assert.Equal(t, "fp-1", body.DedupKey, "以告警指纹去重")
+	assert.Equal(t, "i-1", body.Payload.Source)
+	assert.Equal(t, "critical", body.Payload.Severity)
+	assert.Equal(t, "i-1", body.Payload.CustomDetails["asset_id"])
+
+	msg.Event.Status = domain.EventStatusResolved
+	require.NoError(t, sender.Send(context.Background(), msg))
+	require.NoError(t, json.Unmarshal(rec.body, &body))
+	assert.Equal(t, "resolve", body.EventAction)
+
+	// 非事件消息（如渠道测试）没有去重键
+	body.DedupKey = ""
+	require.NoError(t, sender.Send(context.Background(), &Message{Title: "测试", Severity: domain.SeverityInfo}))
+	require.NoError(t, json.Unmarshal(rec.body, &body))
+	assert.Empty(t, body.DedupKey)
+	assert.Equal(t, "e-cam", body.Payload.Source)
+}
+
+func TestNewSender_HTTPChannels(t *testing.T) {
+	_, err := NewSender(domain.NotificationChannel{Type: domain.ChannelWebhook, Config: map[string]any{}})
+	assert.Error(t, err)
+	_, err = NewSender(domain.NotificationChannel{Type: domain.ChannelSlack, Config: map[string]any{}})
+	assert.Error(t, err)
+	_, err = NewSender(domain.NotificationChannel{Type: domain.ChannelIncident, Config: map[string]any{}})
+	assert.Error(t, err)
+
+	// 从 MongoDB 读出的配置：嵌套文档为 primitive.D，整数为 int32
+	sender, err := NewSender(domain.NotificationChannel{Type: domain.ChannelWebhook, Config: map[string]any{
+		"url":         "http://example.com/hook",
+		"headers":     primitive.D{{Key: "X-Token", Value: "abc"}},
+		"max_retries": int32(1),
+	}})
+	require.NoError(t, err)
+	webhook := sender.(*WebhookSender)
+	assert.Equal(t, map[string]string{"X-Token": "abc"}, webhook.cfg.Headers)
+	assert.Equal(t, 1, webhook.cfg.MaxRetries)
+	assert.Equal(t, http.MethodPost, webhook.cfg.Method)
+
+	sender, err = NewSender(domain.NotificationChannel{Type: domain.ChannelIncident, Config: map[string]any{"routing_key": "rk"}})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultIncidentURL, sender.(*IncidentSender).cfg.URL)
+	assert.Equal(t, defaultMaxRetries, sender.(*IncidentSender).cfg.MaxRetries)
+}
+
+func TestDispatchEach(t *testing.T) {
+	webhookRec, slackRec := &recorder{}, &recorder{}
+	webhookSrv, slackSrv := webhookRec.server(t), slackRec.server(t)
+
+	d := NewDispatcher([]domain.NotificationChannel{
+		{Type: domain.ChannelWebhook, Enabled: true, Config: map[string]any{"url": webhookSrv.URL}},
+		{Type: domain.ChannelSlack, Enabled: true, Config: map[string]any{"webhook": slackSrv.URL}},
+	})
+	require.Equal(t, 2, d.Len())
+
+	require.NoError(t, d.DispatchEach(context.Background(), func(ct domain.ChannelType) *Message {
+		return &Message{Title: "to " + string(ct), Severity: domain.SeverityInfo}
+	}))
+	assert.Contains(t, string(webhookRec.body), `"title":"to webhook"`)
+	assert.Contains(t, string(slackRec.body), `"title":"to slack"`)
+}
+
+func TestDispatchEach_IndependentChannels(t *testing.T) {
+	down, up := &recorder{failures: 10, failStatus: http.StatusUnauthorized}, &recorder{}
+	downSrv, upSrv := down.server(t), up.server(t)
+
+	d := NewDispatcher([]domain.NotificationChannel{
+		{Type: domain.ChannelWebhook, Enabled: true, Config: map[string]any{"url": downSrv.URL}},
+		{Type: domain.ChannelSlack, Enabled: true, Config: map[string]any{"webhook": upSrv.URL}},
+	})
+	err := d.Dispatch(context.Background(), testMessage())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "send to webhook failed")
+	assert.Equal(t, int32(1), up.calls.Load(), "单个渠道失败不影响其他渠道")
+}
+
+func TestPostJSON_APIError(t *testing.T) {
+	body := `{"errcode":0,"errmsg":"ok"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	ctx := context.Background()
+
+	require.NoError(t, postJSON(ctx, srv.URL, map[string]any{}))
+
+	// 钉钉限流以 HTTP 200 返回错误码，可重试
+	body = `{"errcode":130101,"errmsg":"send too fast"}`
+	err := postJSON(ctx, srv.URL, map[string]any{})
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 130101, apiErr.Code)
+	assert.True(t, Retryable(err))
+
+	// 飞书签名校验失败，不可重试
+	body = `{"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`
+	err = postJSON(ctx, srv.URL, map[string]any{})
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 19021, apiErr.Code)
+	assert.False(t, Retryable(err))
+
+	// 非 JSON 响应（如 Slack 的 ok）视为成功
+	body = "ok"
+	require.NoError(t, postJSON(ctx, srv.URL, map[string]any{}))
+}
+
+func TestRetryable(t *testing.T) {
+	_, err := NewSender(domain.NotificationChannel{Type: domain.ChannelDingTalk, Config: map[string]any{}})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.False(t, Retryable(err), "配置错误")
+
+	assert.True(t, Retryable(&StatusError{Code: http.StatusTooManyRequests}))
+	assert.True(t, Retryable(&StatusError{Code: http.StatusBadGateway}))
+	assert.False(t, Retryable(&StatusError{Code: http.StatusNotFound}))
+	assert.True(t, Retryable(errors.New("send request: connection refused")), "网络错误")
+	assert.False(t, Retryable(nil))
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"time"
@@ -68,6 +69,7 @@ func (s *DingTalkSender) signURL(webhook string) string {
 }
 
 // postJSON 发送 JSON POST 请求
+// IM 机器人被限流或拒绝时通常仍返回 HTTP 200，需解析响应体中的错误码才能判断是否送达
 func postJSON(ctx context.Context, url string, body any) error {
 	data, err := json.Marshal(body)
 	if err != nil {
@@ -87,8 +89,9 @@ func postJSON(ctx context.Context, url string, body any) error {
 	}
 	defer resp.Body.Close()
 
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		return &StatusError{Code: resp.StatusCode, Body: truncate(string(respBody), 512)}
 	}
-	return nil
+	return parseAPIError(respBody)
 }
@@ -0,0 +1,74 @@
+package channel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidConfig 渠道配置无效，重试无法恢复
+var ErrInvalidConfig = errors.New("invalid channel config")
+
+// APIError IM 机器人以 HTTP 200 返回的业务错误（钉钉、企业微信为 errcode，飞书为 code）
+type APIError struct {
+	Code int
+	Msg  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error: code=%d, msg=%s", e.Code, e.Msg)
+}
+
+// throttleCodes 各 IM 机器人表示发送过于频繁的错误码
+var throttleCodes = map[int]bool{
+	130101: true, // 钉钉：发送速度太快而限流
+	45009:  true, // 企业微信：接口调用超过限制
+	9499:   true, // 飞书：请求过于频繁
+	11232:  true, // 飞书：消息发送频率超限
+}
+
+// Throttled 是否被对端限流
+func (e *APIError) Throttled() bool {
+	return throttleCodes[e.Code]
+}
+
+// Retryable 限流可重试，其余业务错误（关键词不匹配、签名错误、机器人已停用等）重试无法恢复
+func (e *APIError) Retryable() bool {
+	return e.Throttled()
+}
+
+// Retryable 判断发送错误能否通过重试恢复：网络错误、限流与服务端错误可重试，配置错误与对端拒绝不可重试
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, ErrInvalidConfig) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable()
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return true
+}
+
+// parseAPIError 解析 IM 机器人响应体中的业务错误码，无法解析或成功时返回 nil
+func parseAPIError(body []byte) error {
+	var resp struct {
+		ErrCode *int   `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+		Code    *int   `json:"code"`
+		Msg     string `json:"msg"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &resp) != nil {
+		return nil
+	}
+	switch {
+	case resp.ErrCode != nil && *resp.ErrCode != 0:
+		return &APIError{Code: *resp.ErrCode, Msg: resp.ErrMsg}
+	case resp.Code != nil && *resp.Code != 0:
+		return &APIError{Code: *resp.Code, Msg: resp.Msg}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// 通用 HTTP 渠道默认参数
+const (
+	defaultHTTPTimeout = 10 * time.Second
+	defaultMaxRetries  = 3
+	defaultBackoff     = time.Second
+)
+
+// StatusError 对端返回非 2xx 状态码
+type StatusError struct {
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status: %d", e.Code)
+	}
+	return fmt.Sprintf("unexpected status: %d, body: %s", e.Code, e.Body)
+}
+
+// Retryable 限流与服务端错误可重试，其余 4xx 视为配置或请求错误
+func (e *StatusError) Retryable() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= http.StatusInternalServerError
+}
+
+// doRequest 发送 HTTP 请求，2xx 视为成功
+func doRequest(ctx context.Context, client *http.Client, method, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{Code: resp.StatusCode, Body: string(data)}
+	}
+	return nil
+}
+
+// withRetry 执行 fn，网络错误与可重试状态码按指数退避重试 maxRetries 次
+func withRetry(ctx context.Context, maxRetries int, backoff time.Duration, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if !Retryable(err) {
+			return err
+		}
+		if attempt >= maxRetries {
+			return fmt.Errorf("after %d attempts: %w", attempt+1, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
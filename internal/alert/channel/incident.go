@@ -0,0 +1,131 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+)
+
+// DefaultIncidentURL PagerDuty Events API v2 入口，其他兼容平台可在渠道配置中覆盖
+const DefaultIncidentURL = "https://events.pagerduty.com/v2/enqueue"
+
+// maxIncidentSummary Events API 摘要长度上限
+const maxIncidentSummary = 1024
+
+// IncidentConfig 事件平台配置
+type IncidentConfig struct {
+	URL        string // 默认 DefaultIncidentURL
+	RoutingKey string // 集成密钥
+	Source     string // 事件来源，默认 e-cam
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+// IncidentSender Events API 风格的事件平台发送器
+// 以告警指纹作为去重键：同一告警的多次通知在平台上归并为一个 incident，恢复事件会关闭它
+type IncidentSender struct {
+	cfg    IncidentConfig
+	client *http.Client
+}
+
+func NewIncidentSender(cfg IncidentConfig) *IncidentSender {
+	if cfg.URL == "" {
+		cfg.URL = DefaultIncidentURL
+	}
+	if cfg.Source == "" {
+		cfg.Source = "e-cam"
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultBackoff
+	}
+	return &IncidentSender{cfg: cfg, client: &http.Client{Timeout: defaultHTTPTimeout}}
+}
+
+func (s *IncidentSender) Type() domain.ChannelType {
+	return domain.ChannelIncident
+}
+
+func (s *IncidentSender) Send(ctx context.Context, msg *Message) error {
+	details := map[string]any{"content": msg.Content}
+	body := map[string]any{
+		"routing_key":  s.cfg.RoutingKey,
+		"event_action": "trigger",
+		"client":       "e-cam",
+	}
+	source := s.cfg.Source
+	if event := msg.Event; event != nil {
+		body["event_action"] = incidentAction(event.Status)
+		if key := incidentDedupKey(*event); key != "" {
+			body["dedup_key"] = key
+		}
+		if event.Resource != "" {
+			source = event.Resource
+		}
+		for k, v := range event.Content {
+			if k != "content" {
+				details[k] = v
+			}
+		}
+	}
+	body["payload"] = map[string]any{
+		"summary":        truncate(fmt.Sprintf("[%s] %s", msg.Severity, msg.Title), maxIncidentSummary),
+		"source":         source,
+		"severity":       incidentSeverity(msg.Severity),
+		"timestamp":      time.Now().UTC().Format(time.RFC3339),
+		"custom_details": details,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+	return withRetry(ctx, s.cfg.MaxRetries, s.cfg.Backoff, func() error {
+		return doRequest(ctx, s.client, http.MethodPost, s.cfg.URL, nil, data)
+	})
+}
+
+func incidentAction(status domain.EventStatus) string {
+	switch status {
+	case domain.EventStatusResolved:
+		return "resolve"
+	case domain.EventStatusAcknowledged:
+		return "acknowledge"
+	default:
+		return "trigger"
+	}
+}
+
+func incidentDedupKey(event domain.AlertEvent) string {
+	if event.Fingerprint != "" {
+		return event.Fingerprint
+	}
+	if event.ID > 0 {
+		return fmt.Sprintf("ecam-event-%d", event.ID)
+	}
+	return ""
+}
+
+// incidentSeverity Events API 只接受 critical / error / warning / info
+func incidentSeverity(severity domain.Severity) string {
+	switch severity {
+	case domain.SeverityCritical:
+		return "critical"
+	case domain.SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:max-3]) + "..."
+}
@@ -0,0 +1,70 @@
+package channel
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"golang.org/x/time/rate"
+)
+
+// defaultRateLimits 各渠道默认每分钟发送上限，参照各 IM 机器人的官方限制；未列出的渠道不限流
+// 渠道配置 rate_limit 可覆盖，设为 0 表示不限流
+var defaultRateLimits = map[domain.ChannelType]int{
+	domain.ChannelDingTalk: 20,
+	domain.ChannelWeCom:    20,
+	domain.ChannelFeishu:   100,
+	domain.ChannelSlack:    60,
+	domain.ChannelIncident: 120,
+}
+
+// RateLimit 渠道每分钟发送上限，0 表示不限流
+func RateLimit(ch domain.NotificationChannel) int {
+	return configInt(ch.Config, "rate_limit", defaultRateLimits[ch.Type])
+}
+
+// RateLimitKey 限流维度：机器人的限制按 Webhook 地址计算，多个渠道共用同一机器人时共享配额
+func RateLimitKey(ch domain.NotificationChannel) string {
+	for _, key := range []string{"webhook", "url", "routing_key"} {
+		if v, _ := ch.Config[key].(string); v != "" {
+			return string(ch.Type) + ":" + v
+		}
+	}
+	return fmt.Sprintf("channel:%d", ch.ID)
+}
+
+// RateLimiter 按 Webhook 维度的令牌桶限流器
+type RateLimiter struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{limiters: make(map[string]*rate.Limiter)}
+}
+
+// Reserve 申请一次发送配额（不阻塞）：返回 0 表示已占用配额可立即发送，
+// 否则返回需要等待的时长，此时不占用配额，由调用方推迟后重新申请
+func (l *RateLimiter) Reserve(key string, perMinute int, now time.Time) time.Duration {
+	if perMinute <= 0 {
+		return 0
+	}
+	limit := rate.Limit(float64(perMinute) / 60)
+
+	l.mu.Lock()
+	lim, ok := l.limiters[key]
+	if !ok || lim.Limit() != limit {
+		// 允许短时突发 1/4 分钟的配额，其余按速率平滑
+		lim = rate.NewLimiter(limit, max(1, perMinute/4))
+		l.limiters[key] = lim
+	}
+	l.mu.Unlock()
+
+	r := lim.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return delay
+	}
+	return 0
+}
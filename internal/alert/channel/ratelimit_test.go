@@ -0,0 +1,40 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	l := NewRateLimiter()
+	now := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+
+	// 每分钟 20 条：突发 5 条，之后每 3 秒 1 条
+	for i := 0; i < 5; i++ {
+		assert.Zero(t, l.Reserve("dingtalk:a", 20, now))
+	}
+	assert.Equal(t, 3*time.Second, l.Reserve("dingtalk:a", 20, now))
+	assert.Equal(t, 3*time.Second, l.Reserve("dingtalk:a", 20, now), "推迟时不占用配额")
+	assert.Zero(t, l.Reserve("dingtalk:b", 20, now), "不同 Webhook 独立限流")
+	assert.Zero(t, l.Reserve("dingtalk:a", 20, now.Add(3*time.Second)))
+
+	// 不限流
+	for i := 0; i < 100; i++ {
+		assert.Zero(t, l.Reserve("webhook:a", 0, now))
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	assert.Equal(t, 20, RateLimit(domain.NotificationChannel{Type: domain.ChannelDingTalk}))
+	assert.Equal(t, 0, RateLimit(domain.NotificationChannel{Type: domain.ChannelWebhook}))
+	assert.Equal(t, 5, RateLimit(domain.NotificationChannel{Type: domain.ChannelWeCom, Config: map[string]any{"rate_limit": int32(5)}}))
+
+	// 共用同一机器人的渠道共享配额
+	a := domain.NotificationChannel{ID: 1, Type: domain.ChannelDingTalk, Config: map[string]any{"webhook": "https://oapi.dingtalk.com/robot/send?access_token=x"}}
+	b := domain.NotificationChannel{ID: 2, Type: domain.ChannelDingTalk, Config: map[string]any{"webhook": "https://oapi.dingtalk.com/robot/send?access_token=x"}}
+	assert.Equal(t, RateLimitKey(a), RateLimitKey(b))
+	assert.Equal(t, "channel:3", RateLimitKey(domain.NotificationChannel{ID: 3, Type: domain.ChannelEmail}))
+}
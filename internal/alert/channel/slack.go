@@ -0,0 +1,60 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+)
+
+// SlackSender Slack Incoming Webhook 发送器
+type SlackSender struct {
+	webhook string
+}
+
+func NewSlackSender(webhook string) *SlackSender {
+	return &SlackSender{webhook: webhook}
+}
+
+func (s *SlackSender) Type() domain.ChannelType {
+	return domain.ChannelSlack
+}
+
+func (s *SlackSender) Send(ctx context.Context, msg *Message) error {
+	text := msg.Content
+	if msg.Markdown {
+		text = slackMarkdown(text)
+	}
+
+	body := map[string]any{
+		// text 用于通知栏预览
+		"text": fmt.Sprintf("[%s] %s", msg.Severity, msg.Title),
+		"attachments": []map[string]any{
+			{
+				"color":     s.severityColor(msg.Severity),
+				"title":     msg.Title,
+				"text":      text,
+				"mrkdwn_in": []string{"text"},
+			},
+		},
+	}
+
+	return postJSON(ctx, s.webhook, body)
+}
+
+func (s *SlackSender) severityColor(severity domain.Severity) string {
+	switch severity {
+	case domain.SeverityCritical:
+		return "#d32f2f"
+	case domain.SeverityWarning:
+		return "#f57c00"
+	default:
+		return "#1976d2"
+	}
+}
+
+// slackMarkdown Slack mrkdwn 的加粗语法为 *text*，内置消息使用 **text**
+func slackMarkdown(s string) string {
+	return strings.ReplaceAll(s, "**", "*")
+}
@@ -0,0 +1,149 @@
+package channel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+)
+
+// TemplateData 消息模板的渲染数据
+// 告警事件字段可直接引用，如 {{.Title}}、{{.Severity}}、{{.Content.asset_id}}、{{.Occurrence}}
+type TemplateData struct {
+	domain.AlertEvent
+	DefaultTitle   string // 内置格式的标题（含 [升级] 等前缀）
+	DefaultContent string // 内置格式的正文，可在模板中整体引用
+	OnCall         string // 当前值班人，未配置值班时为空
+}
+
+// templateFuncs 模板可用函数
+var templateFuncs = template.FuncMap{
+	// upper / lower 接受任意值，便于直接处理 .Severity、.Type 等自定义字符串类型
+	"upper": func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower": func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+	// default 值为空时使用默认值：{{default "-" .Content.region}}
+	"default": func(def any, v any) any {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"toJSON": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// formatTime 格式化时间，layout 为空时使用 2006-01-02 15:04:05
+	"formatTime": func(t time.Time, layout ...string) string {
+		if len(layout) > 0 && layout[0] != "" {
+			return t.Format(layout[0])
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+}
+
+// RenderTemplate 用模板渲染消息，标题或正文模板为空时沿用内置格式
+func RenderTemplate(tmpl domain.MessageTemplate, data TemplateData) (*Message, error) {
+	title, err := execTemplate("title", tmpl.Title, data, data.DefaultTitle)
+	if err != nil {
+		return nil, err
+	}
+	content, err := execTemplate("content", tmpl.Content, data, data.DefaultContent)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Title:    strings.TrimSpace(title),
+		Content:  content,
+		Severity: data.Severity,
+		Markdown: true,
+	}, nil
+}
+
+// ValidateTemplate 校验模板语法，并用示例事件试渲染以发现引用了不存在字段等运行时错误
+func ValidateTemplate(tmpl domain.MessageTemplate) error {
+	if tmpl.Title == "" && tmpl.Content == "" {
+		return fmt.Errorf("标题与正文模板不能同时为空")
+	}
+	_, err := RenderTemplate(tmpl, SampleTemplateData(tmpl.AlertType))
+	return err
+}
+
+// SelectTemplate 选择与渠道类型、告警类型最匹配的启用模板：
+// 渠道类型与告警类型都匹配 > 仅渠道类型匹配 > 仅告警类型匹配 > 通用模板；同优先级取列表中靠前的
+func SelectTemplate(templates []domain.MessageTemplate, channelType domain.ChannelType, alertType domain.AlertType) *domain.MessageTemplate {
+	var best *domain.MessageTemplate
+	bestScore := -1
+	for i := range templates {
+		t := &templates[i]
+		if !t.Enabled ||
+			(t.ChannelType != "" && t.ChannelType != channelType) ||
+			(t.AlertType != "" && t.AlertType != alertType) {
+			continue
+		}
+		score := 0
+		if t.ChannelType != "" {
+			score += 2
+		}
+		if t.AlertType != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+	return best
+}
+
+// SampleTemplateData 示例渲染数据，用于模板校验与预览
+func SampleTemplateData(alertType domain.AlertType) TemplateData {
+	if alertType == "" {
+		alertType = domain.AlertTypeResourceChange
+	}
+	now := time.Now()
+	event := domain.AlertEvent{
+		ID:       1,
+		RuleID:   1,
+		Type:     alertType,
+		Severity: domain.SeverityWarning,
+		Title:    "示例告警",
+		Content: map[string]any{
+			"asset_id":      "i-sample",
+			"asset_name":    "sample-instance",
+			"resource_type": "ecs",
+			"region":        "cn-hangzhou",
+			"account_id":    int64(1),
+		},
+		Source:      "sample",
+		Resource:    "i-sample",
+		Fingerprint: "0123456789abcdef0123456789abcdef",
+		Occurrence:  1,
+		Status:      domain.EventStatusPending,
+		TenantID:    "sample",
+		CreateTime:  now,
+	}
+	return TemplateData{
+		AlertEvent:     event,
+		DefaultTitle:   event.Title,
+		DefaultContent: "**资源**: sample-instance (i-sample)\n",
+		OnCall:         "值班人",
+	}
+}
+
+func execTemplate(name, text string, data TemplateData, fallback string) (string, error) {
+	if text == "" {
+		return fallback, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s 模板语法错误: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s 模板渲染失败: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,71 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{
+		AlertEvent: domain.AlertEvent{
+			Type: domain.AlertTypeExpiration, Severity: domain.SeverityWarning, Title: "资源即将过期",
+			Content:    map[string]any{"asset_id": "i-1", "days": 3},
+			Occurrence: 2, CreateTime: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+		},
+		DefaultTitle:   "[升级] 资源即将过期",
+		DefaultContent: "**资源**: i-1\n",
+		OnCall:         "Bob",
+	}
+
+	msg, err := RenderTemplate(domain.MessageTemplate{
+		Title: `[{{upper .Severity}}] {{.Title}}`,
+		Content: `{{.Content.asset_id}} 将在 {{.Content.days}} 天后过期，第 {{.Occurrence}} 次
+区域: {{default "-" .Content.region}}
+时间: {{formatTime .CreateTime}}
+值班: {{.OnCall}}
+{{.DefaultContent}}`,
+	}, data)
+	require.NoError(t, err)
+	assert.Equal(t, "[WARNING] 资源即将过期", msg.Title)
+	assert.Equal(t, "i-1 将在 3 天后过期，第 2 次\n区域: -\n时间: 2026-10-01 08:00:00\n值班: Bob\n**资源**: i-1\n", msg.Content)
+	assert.Equal(t, domain.SeverityWarning, msg.Severity)
+
+	// 标题模板为空时沿用内置标题
+	msg, err = RenderTemplate(domain.MessageTemplate{Content: `{{toJSON .Content}}`}, data)
+	require.NoError(t, err)
+	assert.Equal(t, "[升级] 资源即将过期", msg.Title)
+	assert.Equal(t, `{"asset_id":"i-1","days":3}`, msg.Content)
+}
+
+func TestValidateTemplate(t *testing.T) {
+	assert.NoError(t, ValidateTemplate(domain.MessageTemplate{Title: "{{.Title}}"}))
+	assert.Error(t, ValidateTemplate(domain.MessageTemplate{}), "标题与正文不能同时为空")
+	assert.Error(t, ValidateTemplate(domain.MessageTemplate{Content: "{{.Title"}), "语法错误")
+	assert.Error(t, ValidateTemplate(domain.MessageTemplate{Content: "{{.NoSuchField}}"}), "引用不存在的字段")
+}
+
+func TestSelectTemplate(t *testing.T) {
+	templates := []domain.MessageTemplate{
+		{ID: 1, Enabled: true},
+		{ID: 2, Enabled: true, AlertType: domain.AlertTypeExpiration},
+		{ID: 3, Enabled: true, ChannelType: domain.ChannelSlack},
+		{ID: 4, Enabled: true, ChannelType: domain.ChannelSlack, AlertType: domain.AlertTypeExpiration},
+		{ID: 5, Enabled: false, ChannelType: domain.ChannelWebhook, AlertType: domain.AlertTypeExpiration},
+	}
+
+	id := func(ct domain.ChannelType, at domain.AlertType) int64 {
+		if tmpl := SelectTemplate(templates, ct, at); tmpl != nil {
+			return tmpl.ID
+		}
+		return 0
+	}
+	assert.Equal(t, int64(4), id(domain.ChannelSlack, domain.AlertTypeExpiration))
+	assert.Equal(t, int64(3), id(domain.ChannelSlack, domain.AlertTypeSyncFailure))
+	assert.Equal(t, int64(2), id(domain.ChannelWebhook, domain.AlertTypeExpiration), "禁用的模板不参与匹配")
+	assert.Equal(t, int64(1), id(domain.ChannelEmail, domain.AlertTypeSyncFailure))
+	assert.Nil(t, SelectTemplate(templates[4:], domain.ChannelWebhook, domain.AlertTypeExpiration))
+}
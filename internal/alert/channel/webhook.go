@@ -0,0 +1,99 @@
+package channel
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+)
+
+// 通用 Webhook 签名请求头
+const (
+	WebhookTimestampHeader = "X-Ecam-Timestamp"
+	WebhookSignatureHeader = "X-Ecam-Signature"
+)
+
+// WebhookConfig 通用 Webhook 配置
+type WebhookConfig struct {
+	URL        string
+	Method     string            // 默认 POST
+	Headers    map[string]string // 自定义请求头，如鉴权 Token
+	Secret     string            // 配置后按 HMAC-SHA256 签名请求体
+	MaxRetries int               // 网络错误、429 与 5xx 的重试次数
+	Backoff    time.Duration     // 首次重试间隔，之后逐次翻倍，默认 1s
+	Timeout    time.Duration     // 单次请求超时，默认 10s
+}
+
+// WebhookPayload 通用 Webhook 请求体
+type WebhookPayload struct {
+	Title     string             `json:"title"`
+	Content   string             `json:"content"`
+	Severity  domain.Severity    `json:"severity"`
+	Event     *domain.AlertEvent `json:"event,omitempty"` // 测试消息等非事件通知为空
+	Timestamp int64              `json:"timestamp"`
+}
+
+// WebhookSender 通用 HTTP Webhook 发送器
+type WebhookSender struct {
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+func NewWebhookSender(cfg WebhookConfig) *WebhookSender {
+	if cfg.Method == "" {
+		cfg.Method = http.MethodPost
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultBackoff
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultHTTPTimeout
+	}
+	return &WebhookSender{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (s *WebhookSender) Type() domain.ChannelType {
+	return domain.ChannelWebhook
+}
+
+func (s *WebhookSender) Send(ctx context.Context, msg *Message) error {
+	payload := WebhookPayload{
+		Title:     msg.Title,
+		Content:   msg.Content,
+		Severity:  msg.Severity,
+		Event:     msg.Event,
+		Timestamp: time.Now().Unix(),
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal body: %w", err)
+	}
+
+	headers := make(map[string]string, len(s.cfg.Headers)+2)
+	for k, v := range s.cfg.Headers {
+		headers[k] = v
+	}
+	if s.cfg.Secret != "" {
+		headers[WebhookTimestampHeader] = strconv.FormatInt(payload.Timestamp, 10)
+		headers[WebhookSignatureHeader] = SignWebhook(s.cfg.Secret, payload.Timestamp, body)
+	}
+
+	return withRetry(ctx, s.cfg.MaxRetries, s.cfg.Backoff, func() error {
+		return doRequest(ctx, s.client, s.cfg.Method, s.cfg.URL, headers, body)
+	})
+}
+
+// SignWebhook 计算 Webhook 签名：HMAC-SHA256(secret, "<timestamp>.<body>")，接收方可据此校验来源与防重放
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(h, "%d.", timestamp)
+	h.Write(body)
+	return "sha256=" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testEvent() domain.AlertEvent {
+	return domain.AlertEvent{
+		Type:     domain.AlertTypeResourceChange,
+		Severity: domain.SeverityWarning,
+		Title:    "资源变更: ecs [aliyun/cn-hangzhou]",
+		Source:   "change_detector:ecs:cn-hangzhou",
+		TenantID: "t1",
+		Content: map[string]any{
+			"changed_field": "status",
+			"region":        "cn-hangzhou",
+			"added_count":   3,
+			"tags":          map[string]any{"env": "prod", "team": "trade"},
+			"regions":       []string{"cn-hangzhou", "cn-shanghai"},
+			// MongoDB 解码得到的嵌套对象与数组
+			"spec": primitive.D{{Key: "cpu", Value: int32(8)}, {Key: "zones", Value: primitive.A{"a", "b"}}},
+		},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{`severity >= warning && content.changed_field in ["status","spec"] && tags.env == "prod"`, true},
+		{`severity > warning`, false},
+		{`severity == critical || severity == warning`, true},
+		{`!(tags.env == "prod")`, false},
+		{`tags.env != "staging"`, true},
+		{`content.added_count >= 3 && content.added_count < 3.5`, true},
+		{`content.added_count == 3`, true},
+		{`content.spec.cpu > 4 && "b" in content.spec.zones`, true},
+		{`content.regions contains "cn-shanghai"`, true},
+		{`"cn-beijing" not in content.regions`, true},
+		{`title contains "ecs"`, true},
+		{`source matches "^change_detector:ecs:"`, true},
+		{`"team" in tags`, true},
+		{`type == "resource_change" && tenant_id == 't1'`, true},
+		// 缺失字段为 null：相等比较与逻辑运算按 null 处理，有序比较不成立
+		{`content.missing == null`, true},
+		{`content.missing`, false},
+		{`content.missing > 1`, false},
+		{`content.missing in ["a"]`, false},
+	}
+	for _, c := range cases {
+		expr, err := Compile(c.expr)
+		require.NoError(t, err, c.expr)
+		got, err := expr.Match(testEvent())
+		require.NoError(t, err, c.expr)
+		assert.Equal(t, c.want, got, c.expr)
+	}
+}
+
+func TestMatch_RuntimeError(t *testing.T) {
+	expr, err := Compile(`content.changed_field > 1`)
+	require.NoError(t, err)
+	_, err = expr.Match(testEvent())
+	assert.Error(t, err, "字符串与数字不可比较")
+
+	expr, err = Compile(`content.changed_field`)
+	require.NoError(t, err)
+	_, err = expr.Match(testEvent())
+	assert.Error(t, err, "结果必须为布尔值")
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`severity >=`,
+		`owner == "me"`,
+		`severity.level == "x"`,
+		`(severity == warning`,
+		`tags.env == "prod`,
+		`content.x in ["a" "b"]`,
+		`title matches "["`,
+		`title matches content.pattern`,
+		`severity == warning extra`,
+		`content.x not "a"`,
+		`severity = warning`,
+	} {
+		_, err := Compile(src)
+		assert.ErrorIs(t, err, ErrInvalidCondition, src)
+	}
+}
+
+func TestFromRule(t *testing.T) {
+	expr, err := FromRule(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, expr)
+
+	expr, err = FromRule(map[string]any{"expr": "  "})
+	assert.NoError(t, err)
+	assert.Nil(t, expr)
+
+	expr, err = FromRule(map[string]any{"expr": `severity == critical`})
+	require.NoError(t, err)
+	assert.Equal(t, `severity == critical`, expr.String())
+
+	_, err = FromRule(map[string]any{"expr": 1})
+	assert.ErrorIs(t, err, ErrInvalidCondition)
+}
@@ -0,0 +1,352 @@
+package condition
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// severityRank 告警级别高低，用于 severity >= warning 之类的比较
+var severityRank = map[string]int{
+	string(domain.SeverityInfo):     1,
+	string(domain.SeverityWarning):  2,
+	string(domain.SeverityCritical): 3,
+}
+
+// Match 对告警事件求值，表达式结果必须为布尔值；引用不存在的字段时取 null
+func (e *Expr) Match(event domain.AlertEvent) (bool, error) {
+	v, err := e.root.eval(&event)
+	if err != nil {
+		return false, err
+	}
+	b, err := truthy(v)
+	if err != nil {
+		return false, fmt.Errorf("表达式结果不是布尔值: %w", err)
+	}
+	return b, nil
+}
+
+type node interface {
+	eval(event *domain.AlertEvent) (any, error)
+}
+
+type literalNode struct{ v any }
+
+func (n *literalNode) eval(*domain.AlertEvent) (any, error) { return n.v, nil }
+
+type listNode struct{ items []node }
+
+func (n *listNode) eval(event *domain.AlertEvent) (any, error) {
+	values := make([]any, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(event)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+type pathNode struct {
+	root string
+	keys []string
+}
+
+func (n *pathNode) eval(event *domain.AlertEvent) (any, error) {
+	var v any
+	switch n.root {
+	case "type":
+		return string(event.Type), nil
+	case "severity":
+		return string(event.Severity), nil
+	case "title":
+		return event.Title, nil
+	case "source":
+		return event.Source, nil
+	case "resource":
+		return event.Resource, nil
+	case "tenant_id":
+		return event.TenantID, nil
+	case "content":
+		v = event.Content
+	case "tags":
+		v = lookup(event.Content, "tags")
+	}
+	for _, key := range n.keys {
+		v = lookup(v, key)
+	}
+	return normalize(v), nil
+}
+
+type notNode struct{ operand node }
+
+func (n *notNode) eval(event *domain.AlertEvent) (any, error) {
+	v, err := n.operand.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	b, err := truthy(v)
+	if err != nil {
+		return nil, fmt.Errorf("! 运算: %w", err)
+	}
+	return !b, nil
+}
+
+type logicalNode struct {
+	or          bool
+	left, right node
+}
+
+func (n *logicalNode) eval(event *domain.AlertEvent) (any, error) {
+	op := "&&"
+	if n.or {
+		op = "||"
+	}
+	l, err := n.left.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	lb, err := truthy(l)
+	if err != nil {
+		return nil, fmt.Errorf("%s 运算: %w", op, err)
+	}
+	if lb == n.or {
+		return lb, nil
+	}
+	r, err := n.right.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	rb, err := truthy(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s 运算: %w", op, err)
+	}
+	return rb, nil
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n *compareNode) eval(event *domain.AlertEvent) (any, error) {
+	l, err := n.left.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(event)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(l, r), nil
+	case "!=":
+		return !equal(l, r), nil
+	case "in":
+		return contains(r, l), nil
+	case "not in":
+		return !contains(r, l), nil
+	case "contains":
+		return contains(l, r), nil
+	}
+
+	// 有序比较：任一侧缺失时不成立
+	if l == nil || r == nil {
+		return false, nil
+	}
+	c, err := compare(l, r)
+	if err != nil {
+		return nil, fmt.Errorf("%s 运算: %w", n.op, err)
+	}
+	switch n.op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+type matchNode struct {
+	left node
+	re   *regexp.Regexp
+}
+
+func (n *matchNode) eval(event *domain.AlertEvent) (any, error) {
+	v, err := n.left.eval(event)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := v.(string)
+	return ok && n.re.MatchString(s), nil
+}
+
+// ========== 值运算 ==========
+
+// truthy 逻辑运算的操作数：布尔值原样返回，null 视为 false
+func truthy(v any) (bool, error) {
+	switch b := v.(type) {
+	case bool:
+		return b, nil
+	case nil:
+		return false, nil
+	default:
+		return false, fmt.Errorf("期望布尔值，实际为 %T", v)
+	}
+}
+
+func equal(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if reflect.TypeOf(a).Comparable() && reflect.TypeOf(b).Comparable() {
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare 数字按大小比较；级别常量按高低比较；其余字符串按字典序比较
+func compare(a, b any) (int, error) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return 0, fmt.Errorf("无法比较 %T 与 %T", a, b)
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, fmt.Errorf("无法比较 %T 与 %T", a, b)
+		}
+		rx, okx := severityRank[x]
+		ry, oky := severityRank[y]
+		if okx && oky {
+			return rx - ry, nil
+		}
+		return strings.Compare(x, y), nil
+	}
+	return 0, fmt.Errorf("无法比较 %T 与 %T", a, b)
+}
+
+// contains 集合包含：列表按元素，字符串按子串，对象按键
+func contains(collection, item any) bool {
+	switch c := collection.(type) {
+	case nil:
+		return false
+	case string:
+		s, ok := item.(string)
+		return ok && strings.Contains(c, s)
+	case []any:
+		for _, v := range c {
+			if equal(v, item) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		key, ok := item.(string)
+		if !ok {
+			return false
+		}
+		_, exists := c[key]
+		return exists
+	}
+	return false
+}
+
+// lookup 取对象的子字段，兼容 JSON 解码与 MongoDB 解码得到的各类对象
+func lookup(v any, key string) any {
+	switch m := v.(type) {
+	case map[string]any:
+		return m[key]
+	case primitive.M:
+		return m[key]
+	case primitive.D:
+		for _, e := range m {
+			if e.Key == key {
+				return e.Value
+			}
+		}
+		return nil
+	case nil:
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+		if x := rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())); x.IsValid() {
+			return x.Interface()
+		}
+	}
+	return nil
+}
+
+// normalize 将字段值统一为 nil / bool / float64 / string / []any / map[string]any
+func normalize(v any) any {
+	switch x := v.(type) {
+	case nil, bool, float64, string, map[string]any:
+		return x
+	case primitive.M:
+		return map[string]any(x)
+	case primitive.D:
+		m := make(map[string]any, len(x))
+		for _, e := range x {
+			m[e.Key] = e.Value
+		}
+		return m
+	case primitive.A:
+		return normalizeSlice(reflect.ValueOf([]any(x)))
+	case []any:
+		return normalizeSlice(reflect.ValueOf(x))
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Slice, reflect.Array:
+		return normalizeSlice(rv)
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			m := make(map[string]any, rv.Len())
+			iter := rv.MapRange()
+			for iter.Next() {
+				m[iter.Key().String()] = iter.Value().Interface()
+			}
+			return m
+		}
+	}
+	return v
+}
+
+func normalizeSlice(rv reflect.Value) []any {
+	values := make([]any, rv.Len())
+	for i := range values {
+		values[i] = normalize(rv.Index(i).Interface())
+	}
+	return values
+}
@@ -0,0 +1,383 @@
+// Package condition 告警规则条件表达式：编译期校验语法与字段，运行期对告警事件求值
+//
+// 语法示例：
+//
+//	severity >= warning && content.changed_field in ["status", "spec"] && tags.env == "prod"
+//
+// 支持 || && ! 与括号；比较运算 == != < <= > >=；集合运算 in / not in / contains；正则 matches。
+// 可引用字段：type severity title source resource tenant_id，content.<key>...（事件内容），
+// tags.<key>（即 content.tags.<key>）。info / warning / critical 为级别常量，按级别高低比较。
+package condition
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Key 条件表达式在 AlertRule.Condition 中的键
+const Key = "expr"
+
+// ErrInvalidCondition 条件表达式无效
+var ErrInvalidCondition = errors.New("告警条件表达式无效")
+
+// Expr 编译后的条件表达式
+type Expr struct {
+	src  string
+	root node
+}
+
+// String 返回表达式源码
+func (e *Expr) String() string { return e.src }
+
+// Compile 编译条件表达式，语法错误、未知字段与非法正则在编译期返回
+func Compile(src string) (*Expr, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCondition, err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.errorf("多余的 %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCondition, err)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// FromRule 读取并编译规则条件中的表达式，未配置表达式时返回 nil
+func FromRule(cond map[string]any) (*Expr, error) {
+	raw, ok := cond[Key]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	src, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: condition.%s 必须为字符串", ErrInvalidCondition, Key)
+	}
+	if strings.TrimSpace(src) == "" {
+		return nil, nil
+	}
+	return Compile(src)
+}
+
+// ========== 词法分析 ==========
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// twoCharOps 双字符运算符
+var twoCharOps = []string{"==", "!=", "<=", ">=", "&&", "||"}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			s, n, err := lexString(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("位置 %d: %v", i, err)
+			}
+			tokens = append(tokens, token{kind: tokString, text: s, pos: i})
+			i += n
+		case isDigit(c) || (c == '-' && i+1 < len(src) && isDigit(src[i+1])):
+			j := i + 1
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[i:j], pos: i})
+			i = j
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(src) && (isIdentStart(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range twoCharOps {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune("<>!()[],.", rune(c)) {
+				op = string(c)
+			}
+			if op == "" {
+				return nil, fmt.Errorf("位置 %d: 无法识别的字符 %q", i, c)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// lexString 读取带引号的字符串，返回内容与消耗的字节数
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 < len(s) {
+				i++
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return "", 0, fmt.Errorf("字符串未闭合")
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// ========== 语法分析 ==========
+
+// eventFields 可直接引用的事件字段
+var eventFields = map[string]bool{
+	"type": true, "severity": true, "title": true, "source": true, "resource": true, "tenant_id": true,
+	"content": true, "tags": true,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOp(text string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == text
+}
+
+func (p *parser) isKeyword(text string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.isOp(text) {
+		return p.errorf("期望 %q", text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("位置 %d: %s", p.peek().pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isOp("!") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	var op string
+	switch {
+	case t.kind == tokOp && (t.text == "==" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">="):
+		op = t.text
+	case t.kind == tokIdent && (t.text == "in" || t.text == "contains" || t.text == "matches"):
+		op = t.text
+	case t.kind == tokIdent && t.text == "not":
+		p.next()
+		if !p.isKeyword("in") {
+			return nil, p.errorf("期望 \"in\"")
+		}
+		op = "not in"
+	default:
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if op != "matches" {
+		return &compareNode{op: op, left: left, right: right}, nil
+	}
+
+	var pattern string
+	var isString bool
+	if lit, ok := right.(*literalNode); ok {
+		pattern, isString = lit.v.(string)
+	}
+	if !isString {
+		return nil, fmt.Errorf("位置 %d: matches 右侧必须为字符串常量", t.pos)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("位置 %d: 正则表达式无效: %v", t.pos, err)
+	}
+	return &matchNode{left: left, re: re}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokString:
+		p.next()
+		return &literalNode{v: t.text}, nil
+	case t.kind == tokNumber:
+		p.next()
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("位置 %d: 数字无效 %q", t.pos, t.text)
+		}
+		return &literalNode{v: f}, nil
+	case t.kind == tokOp && t.text == "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	case t.kind == tokOp && t.text == "[":
+		return p.parseList()
+	case t.kind == tokIdent:
+		return p.parseIdent()
+	case t.kind == tokEOF:
+		return nil, p.errorf("表达式不完整")
+	default:
+		return nil, p.errorf("意外的 %q", t.text)
+	}
+}
+
+func (p *parser) parseList() (node, error) {
+	p.next() // [
+	list := &listNode{}
+	for !p.isOp("]") {
+		item, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		list.items = append(list.items, item)
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+		if !p.isOp("]") {
+			return nil, p.errorf("期望 \",\" 或 \"]\"")
+		}
+	}
+	p.next() // ]
+	return list, nil
+}
+
+func (p *parser) parseIdent() (node, error) {
+	t := p.next()
+	switch t.text {
+	case "true":
+		return &literalNode{v: true}, nil
+	case "false":
+		return &literalNode{v: false}, nil
+	case "null":
+		return &literalNode{v: nil}, nil
+	}
+	if _, ok := severityRank[t.text]; ok {
+		return &literalNode{v: t.text}, nil
+	}
+	if !eventFields[t.text] {
+		return nil, fmt.Errorf("位置 %d: 未知字段 %q", t.pos, t.text)
+	}
+
+	path := &pathNode{root: t.text}
+	for p.isOp(".") {
+		p.next()
+		key := p.next()
+		if key.kind != tokIdent {
+			return nil, fmt.Errorf("位置 %d: \".\" 后应为字段名", key.pos)
+		}
+		path.keys = append(path.keys, key.text)
+	}
+	if path.root != "content" && path.root != "tags" && len(path.keys) > 0 {
+		return nil, fmt.Errorf("位置 %d: 字段 %q 没有子字段", t.pos, t.text)
+	}
+	return path, nil
+}
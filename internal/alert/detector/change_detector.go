@@ -177,13 +177,14 @@ func (d *ChangeDetector) DetectSyncFailure(
 			"reason":       reason,
 		},
 		Source:   fmt.Sprintf("sync_task:%s", taskID),
+		Resource: fmt.Sprintf("account:%d", accountID), // 按账号去重，每次同步的任务ID不同
 		TenantID: tenantID,
 	}
 
 	return d.alertService.EmitEvent(ctx, event)
 }
 
-// DetectExpiration 检测资源过期
+// DetectExpiration 检测资源过期，剩余天数已超出全部提醒天数（已续费）的资源恢复其过期告警
 func (d *ChangeDetector) DetectExpiration(
 	ctx context.Context,
 	tenantID string,
@@ -191,7 +192,12 @@ func (d *ChangeDetector) DetectExpiration(
 	reminderDays []int, // e.g. [7, 3, 1]
 ) error {
 	now := time.Now()
+	maxDays := 0
+	for _, days := range reminderDays {
+		maxDays = max(maxDays, days)
+	}
 
+	var renewed []string
 	for _, inst := range instances {
 		expireTimeStr := inst.GetStringAttribute("expire_time")
 		if expireTimeStr == "" {
@@ -207,6 +213,10 @@ func (d *ChangeDetector) DetectExpiration(
 		if daysLeft < 0 {
 			continue
 		}
+		if daysLeft > maxDays {
+			renewed = append(renewed, inst.AssetID)
+			continue
+		}
 
 		for _, days := range reminderDays {
 			if daysLeft == days {
@@ -241,6 +251,14 @@ func (d *ChangeDetector) DetectExpiration(
 		}
 	}
 
+	// 过期告警以资产ID为资源，一次查出活跃告警后恢复已续费的资源
+	if err := d.alertService.ResolveResources(ctx, tenantID, domain.AlertTypeExpiration, renewed); err != nil {
+		d.logger.Error("恢复过期告警失败",
+			elog.String("tenant_id", tenantID),
+			elog.Int("renewed", len(renewed)),
+			elog.FieldErr(err))
+	}
+
 	return nil
 }
 
@@ -11,6 +11,9 @@ const (
 	AlertTypeSyncFailure    AlertType = "sync_failure"    // 同步失败
 	AlertTypeExpiration     AlertType = "expiration"      // 资源过期
 	AlertTypeSecurityGroup  AlertType = "security_group"  // 安全组变更
+	AlertTypeCostAnomaly    AlertType = "cost_anomaly"    // 成本异常
+	AlertTypeBillDrift      AlertType = "bill_drift"      // 账单对账差异
+	AlertTypeChargeback     AlertType = "chargeback"      // 成本分账单
 )
 
 // Severity 告警级别
@@ -30,6 +33,21 @@ const (
 	EventStatusSent     EventStatus = "sent"
 	EventStatusFailed   EventStatus = "failed"
 	EventStatusSilenced EventStatus = "silenced"
+	EventStatusResolved EventStatus = "resolved"
+	// EventStatusAcknowledged 已确认：值班人已接手，不再重复通知
+	EventStatusAcknowledged EventStatus = "acknowledged"
+	// EventStatusSending 已分发但尚无渠道发送成功，失败的渠道按退避策略独立重试
+	EventStatusSending EventStatus = "sending"
+)
+
+// EventAction 告警事件时间线动作
+type EventAction string
+
+const (
+	EventActionAck      EventAction = "ack"      // 确认
+	EventActionAssign   EventAction = "assign"   // 指派
+	EventActionComment  EventAction = "comment"  // 评论
+	EventActionRenotify EventAction = "renotify" // 超时未确认重新通知
 )
 
 // ChannelType 通知渠道类型
@@ -40,6 +58,9 @@ const (
 	ChannelWeCom    ChannelType = "wecom"
 	ChannelFeishu   ChannelType = "feishu"
 	ChannelEmail    ChannelType = "email"
+	ChannelWebhook  ChannelType = "webhook"  // 通用 HTTP 回调
+	ChannelSlack    ChannelType = "slack"    // Slack Incoming Webhook
+	ChannelIncident ChannelType = "incident" // Events API 风格的事件平台（如 PagerDuty）
 )
 
 // AlertRule 告警规则
@@ -55,6 +76,9 @@ type AlertRule struct {
 	SilenceDuration  int            `json:"silence_duration" bson:"silence_duration"` // 静默期(分钟)
 	EscalateAfter    int            `json:"escalate_after" bson:"escalate_after"`     // 连续N次后升级
 	EscalateChannels []int64        `json:"escalate_channels" bson:"escalate_channels"`
+	AutoResolveAfter int            `json:"auto_resolve_after" bson:"auto_resolve_after"` // 持续N分钟未再触发则自动恢复(0不启用)
+	NodeID           int64          `json:"node_id" bson:"node_id"`                       // 所属服务树节点，按节点值班表路由(0为租户默认值班表)
+	AckTimeout       int            `json:"ack_timeout" bson:"ack_timeout"`               // 发送后N分钟未确认则重新通知(0不启用)
 	TenantID         string         `json:"tenant_id" bson:"tenant_id"`
 	Enabled          bool           `json:"enabled" bson:"enabled"`
 	CreateTime       time.Time      `json:"create_time" bson:"create_time"`
@@ -75,6 +99,48 @@ type AlertEvent struct {
 	RetryCount int            `json:"retry_count" bson:"retry_count"`
 	CreateTime time.Time      `json:"create_time" bson:"create_time"`
 	SentAt     *time.Time     `json:"sent_at" bson:"sent_at"`
+
+	// 去重与升级：同一规则、同一资源、同一类型的事件共享指纹
+	Resource    string            `json:"resource" bson:"resource"` // 为空时由 Content 推断
+	Fingerprint string            `json:"fingerprint" bson:"fingerprint"`
+	Occurrence  int               `json:"occurrence" bson:"occurrence"` // 本轮连续发生次数
+	Escalated   bool              `json:"escalated" bson:"escalated"`
+	ResolvedAt  *time.Time        `json:"resolved_at" bson:"resolved_at"`
+	History     []EventTransition `json:"history" bson:"history"` // 事件时间线
+
+	// 处理流程：指派、确认与超时重新通知
+	Assignee      string     `json:"assignee" bson:"assignee"`
+	AckedBy       string     `json:"acked_by" bson:"acked_by"`
+	AckedAt       *time.Time `json:"acked_at" bson:"acked_at"`
+	AckDeadline   *time.Time `json:"ack_deadline" bson:"ack_deadline"` // 超过该时间仍未确认则重新通知
+	RenotifyCount int        `json:"renotify_count" bson:"renotify_count"`
+}
+
+// EventTransition 告警事件时间线记录，Action 为空表示状态变更
+type EventTransition struct {
+	Action   EventAction `json:"action,omitempty" bson:"action,omitempty"`
+	Status   EventStatus `json:"status" bson:"status"`
+	Reason   string      `json:"reason" bson:"reason"`
+	Operator string      `json:"operator,omitempty" bson:"operator,omitempty"`
+	Comment  string      `json:"comment,omitempty" bson:"comment,omitempty"`
+	Time     time.Time   `json:"time" bson:"time"`
+}
+
+// AlertState 告警指纹状态，记录同一告警的连续发生次数、最近通知时间与升级 / 恢复情况
+type AlertState struct {
+	Fingerprint    string     `json:"fingerprint" bson:"fingerprint"`
+	RuleID         int64      `json:"rule_id" bson:"rule_id"`
+	Type           AlertType  `json:"type" bson:"type"`
+	Resource       string     `json:"resource" bson:"resource"`
+	TenantID       string     `json:"tenant_id" bson:"tenant_id"`
+	Count          int        `json:"count" bson:"count"`
+	FirstSeen      time.Time  `json:"first_seen" bson:"first_seen"`
+	LastSeen       time.Time  `json:"last_seen" bson:"last_seen"`
+	LastNotifiedAt *time.Time `json:"last_notified_at" bson:"last_notified_at"`
+	LastEventID    int64      `json:"last_event_id" bson:"last_event_id"`
+	Escalated      bool       `json:"escalated" bson:"escalated"`
+	Resolved       bool       `json:"resolved" bson:"resolved"`
+	ResolvedAt     *time.Time `json:"resolved_at" bson:"resolved_at"`
 }
 
 // NotificationChannel 通知渠道
@@ -117,8 +183,29 @@ type AlertEventFilter struct {
 	Severity Severity
 	Status   EventStatus
 	RuleID   int64
-	Offset   int64
-	Limit    int64
+	// Fingerprint 按指纹查询同一告警的全部事件
+	Fingerprint string
+	Offset      int64
+	Limit       int64
+}
+
+// RuleDryRunResult 草稿规则对历史事件的回放结果
+type RuleDryRunResult struct {
+	Condition string            `json:"condition"`
+	Total     int               `json:"total"`
+	Matched   int               `json:"matched"`
+	Items     []RuleDryRunEvent `json:"items"`
+}
+
+// RuleDryRunEvent 单个历史事件的回放结果
+type RuleDryRunEvent struct {
+	EventID    int64     `json:"event_id"`
+	Type       AlertType `json:"type"`
+	Severity   Severity  `json:"severity"`
+	Title      string    `json:"title"`
+	CreateTime time.Time `json:"create_time"`
+	Matched    bool      `json:"matched"`
+	Error      string    `json:"error,omitempty"` // 条件求值出错时的原因
 }
 
 // ChannelFilter 通知渠道过滤条件
@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// DeliveryStatus 通知投递状态
+type DeliveryStatus string
+
+const (
+	DeliveryPending  DeliveryStatus = "pending"  // 待发送
+	DeliveryRetrying DeliveryStatus = "retrying" // 发送失败，等待重试
+	DeliverySent     DeliveryStatus = "sent"     // 已送达
+	DeliveryDead     DeliveryStatus = "dead"     // 永久失败，进入死信列表等待人工重投
+	DeliveryCanceled DeliveryStatus = "canceled" // 告警已恢复，取消未完成的投递
+)
+
+// AlertDelivery 告警事件到单个通知渠道的一次投递
+// 每个 (事件, 渠道, 轮次) 独立记录状态与重试进度，某个渠道失败不影响其他渠道，重试时也不会重复发送已送达的渠道
+type AlertDelivery struct {
+	ID          int64          `json:"id" bson:"id"`
+	EventID     int64          `json:"event_id" bson:"event_id"`
+	ChannelID   int64          `json:"channel_id" bson:"channel_id"`
+	ChannelType ChannelType    `json:"channel_type" bson:"channel_type"`
+	ChannelName string         `json:"channel_name" bson:"channel_name"`
+	Round       int            `json:"round" bson:"round"` // 0 为首次通知，N 为第 N 次超时重新通知
+	TenantID    string         `json:"tenant_id" bson:"tenant_id"`
+	Status      DeliveryStatus `json:"status" bson:"status"`
+	Attempts    int            `json:"attempts" bson:"attempts"`   // 已尝试次数，被本地限流推迟不计入
+	Throttled   int            `json:"throttled" bson:"throttled"` // 被本地限流推迟的次数
+
+	NextAttemptAt time.Time  `json:"next_attempt_at" bson:"next_attempt_at"`
+	LastAttemptAt *time.Time `json:"last_attempt_at" bson:"last_attempt_at"`
+	LastError     string     `json:"last_error" bson:"last_error"`
+	SentAt        *time.Time `json:"sent_at" bson:"sent_at"`
+	DeadAt        *time.Time `json:"dead_at" bson:"dead_at"`
+	CreateTime    time.Time  `json:"create_time" bson:"create_time"`
+	UpdateTime    time.Time  `json:"update_time" bson:"update_time"`
+}
+
+// DeliveryFilter 投递记录过滤条件
+type DeliveryFilter struct {
+	TenantID  string
+	EventID   int64
+	ChannelID int64
+	Status    DeliveryStatus
+	Offset    int64
+	Limit     int64
+}
+
+// DeliveryStat 投递统计，按渠道汇总
+type DeliveryStat struct {
+	ChannelID    int64       `json:"channel_id" bson:"channel_id"`
+	ChannelType  ChannelType `json:"channel_type" bson:"channel_type"`
+	ChannelName  string      `json:"channel_name" bson:"channel_name"`
+	Total        int64       `json:"total" bson:"total"`
+	Sent         int64       `json:"sent" bson:"sent"`
+	Pending      int64       `json:"pending" bson:"pending"` // 待发送与等待重试
+	Dead         int64       `json:"dead" bson:"dead"`
+	Canceled     int64       `json:"canceled" bson:"canceled"`
+	Attempts     int64       `json:"attempts" bson:"attempts"`
+	Throttled    int64       `json:"throttled" bson:"throttled"`
+	AvgLatencyMs float64     `json:"avg_latency_ms" bson:"avg_latency_ms"` // 已送达投递从创建到送达的平均耗时
+	SuccessRate  float64     `json:"success_rate" bson:"-"`                // 已送达 / 已结束（送达 + 死信）
+}
+
+// DeliveryStats 投递指标汇总
+type DeliveryStats struct {
+	Since    time.Time      `json:"since"`
+	Total    DeliveryStat   `json:"total"`
+	Channels []DeliveryStat `json:"channels"`
+}
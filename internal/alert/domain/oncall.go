@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// RotationType 值班轮换方式
+type RotationType string
+
+const (
+	RotationDaily  RotationType = "daily"  // 按天轮换
+	RotationWeekly RotationType = "weekly" // 按周轮换
+)
+
+// OnCallMember 值班成员
+type OnCallMember struct {
+	UserID     string  `json:"user_id" bson:"user_id"`
+	Name       string  `json:"name" bson:"name"`
+	ChannelIDs []int64 `json:"channel_ids" bson:"channel_ids"` // 个人通知渠道，值班期间告警额外发送
+}
+
+// OnCallOverride 临时替班，覆盖时间段内的轮换安排
+type OnCallOverride struct {
+	Member OnCallMember `json:"member" bson:"member"`
+	Start  time.Time    `json:"start" bson:"start"`
+	End    time.Time    `json:"end" bson:"end"`
+	Reason string       `json:"reason" bson:"reason"`
+}
+
+// OnCallSchedule 值班表，挂在服务树节点上，子节点未配置时继承上级节点的值班表
+type OnCallSchedule struct {
+	ID         int64            `json:"id" bson:"id"`
+	Name       string           `json:"name" bson:"name"`
+	NodeID     int64            `json:"node_id" bson:"node_id"` // 服务树节点，0 为租户默认值班表
+	Rotation   RotationType     `json:"rotation" bson:"rotation"`
+	Members    []OnCallMember   `json:"members" bson:"members"`       // 按顺序轮换
+	StartTime  time.Time        `json:"start_time" bson:"start_time"` // 第一个班次开始时间，交接时刻与之相同
+	Overrides  []OnCallOverride `json:"overrides" bson:"overrides"`
+	TenantID   string           `json:"tenant_id" bson:"tenant_id"`
+	CreateTime time.Time        `json:"create_time" bson:"create_time"`
+	UpdateTime time.Time        `json:"update_time" bson:"update_time"`
+}
+
+// OnCallShift 值班班次
+type OnCallShift struct {
+	ScheduleID int64        `json:"schedule_id"`
+	NodeID     int64        `json:"node_id"`
+	Member     OnCallMember `json:"member"`
+	Start      time.Time    `json:"start"`
+	End        time.Time    `json:"end"`
+	Override   bool         `json:"override"` // 是否为临时替班
+}
+
+// OnCallScheduleFilter 值班表过滤条件
+type OnCallScheduleFilter struct {
+	TenantID string
+	NodeID   *int64
+	Offset   int64
+	Limit    int64
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// MessageTemplate 通知消息模板（Go text/template 语法）
+// 按渠道类型与告警类型匹配，为空表示匹配全部；未配置模板时使用内置格式
+type MessageTemplate struct {
+	ID          int64       `json:"id" bson:"id"`
+	Name        string      `json:"name" bson:"name"`
+	ChannelType ChannelType `json:"channel_type" bson:"channel_type"`
+	AlertType   AlertType   `json:"alert_type" bson:"alert_type"`
+	Title       string      `json:"title" bson:"title"`     // 标题模板，为空时使用内置标题
+	Content     string      `json:"content" bson:"content"` // 正文模板，为空时使用内置正文
+	Enabled     bool        `json:"enabled" bson:"enabled"`
+	TenantID    string      `json:"tenant_id" bson:"tenant_id"`
+	CreateTime  time.Time   `json:"create_time" bson:"create_time"`
+	UpdateTime  time.Time   `json:"update_time" bson:"update_time"`
+}
+
+// MessageTemplateFilter 消息模板过滤条件
+type MessageTemplateFilter struct {
+	TenantID    string
+	ChannelType ChannelType
+	AlertType   AlertType
+	Enabled     *bool
+	Offset      int64
+	Limit       int64
+}
@@ -17,39 +17,55 @@ import (
 
 // Module 告警通知模块
 type Module struct {
-	AlertService *service.AlertService
-	Detector     *detector.ChangeDetector
-	AlertHandler *web.AlertHandler
-	Logger       *elog.Component
-	stopCh       chan struct{}
+	AlertService  *service.AlertService
+	OnCallService *service.OnCallService
+	Detector      *detector.ChangeDetector
+	AlertHandler  *web.AlertHandler
+	OnCallHandler *web.OnCallHandler
+	Logger        *elog.Component
+	stopCh        chan struct{}
 }
 
 // InitModule 初始化告警模块
 func InitModule(db *mongox.Mongo, logger *elog.Component) (*Module, error) {
 	// 初始化 DAO
 	alertDAO := dao.NewAlertDAO(db)
+	onCallDAO := dao.NewOnCallDAO(db)
+	deliveryDAO := dao.NewDeliveryDAO(db)
 
 	// 初始化索引
 	if err := alertDAO.InitIndexes(context.Background()); err != nil {
 		logger.Error("初始化告警索引失败", elog.FieldErr(err))
 		// 不阻塞启动
 	}
+	if err := onCallDAO.InitIndexes(context.Background()); err != nil {
+		logger.Error("初始化值班表索引失败", elog.FieldErr(err))
+	}
+	if err := deliveryDAO.InitIndexes(context.Background()); err != nil {
+		logger.Error("初始化告警投递索引失败", elog.FieldErr(err))
+	}
 
 	// 初始化服务
 	alertService := service.NewAlertService(alertDAO, logger)
+	onCallService := service.NewOnCallService(onCallDAO, logger)
+	alertService.SetOnCallResolver(onCallService)
+	alertService.SetDeliveryDAO(deliveryDAO)
 
 	// 初始化检测器
 	changeDetector := detector.NewChangeDetector(alertService, logger)
 
 	// 初始化 Handler
 	alertHandler := web.NewAlertHandler(alertService, logger)
+	onCallHandler := web.NewOnCallHandler(onCallService, logger)
 
 	return &Module{
-		AlertService: alertService,
-		Detector:     changeDetector,
-		AlertHandler: alertHandler,
-		Logger:       logger,
-		stopCh:       make(chan struct{}),
+		AlertService:  alertService,
+		OnCallService: onCallService,
+		Detector:      changeDetector,
+		AlertHandler:  alertHandler,
+		OnCallHandler: onCallHandler,
+		Logger:        logger,
+		stopCh:        make(chan struct{}),
 	}, nil
 }
 
@@ -60,6 +76,23 @@ func (m *Module) RegisterRoutes(r *gin.Engine) {
 	alertGroup.Use(middleware.RequireTenant(m.Logger))
 
 	m.AlertHandler.RegisterRoutes(alertGroup)
+	m.OnCallHandler.RegisterRoutes(alertGroup)
+}
+
+// processorStepTimeout 事件处理器每个步骤的超时
+const processorStepTimeout = 30 * time.Second
+
+// runStep 以独立的上下文执行一个处理步骤，避免前序步骤耗尽后续步骤的时间；timeout 为 0 时不设超时
+func (m *Module) runStep(timeout time.Duration, errMsg string, step func(context.Context) error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := step(ctx); err != nil {
+		m.Logger.Error(errMsg, elog.FieldErr(err))
+	}
 }
 
 // StartEventProcessor 启动告警事件处理协程
@@ -73,11 +106,11 @@ func (m *Module) StartEventProcessor(interval time.Duration) {
 		for {
 			select {
 			case <-ticker.C:
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				if err := m.AlertService.ProcessPendingEvents(ctx); err != nil {
-					m.Logger.Error("处理告警事件失败", elog.FieldErr(err))
-				}
-				cancel()
+				m.runStep(processorStepTimeout, "处理告警事件失败", m.AlertService.ProcessPendingEvents)
+				// 投递重试按批自行控制超时
+				m.runStep(0, "重试告警投递失败", m.AlertService.ProcessDeliveries)
+				m.runStep(processorStepTimeout, "重新通知未确认告警失败", m.AlertService.RenotifyUnacknowledged)
+				m.runStep(processorStepTimeout, "自动恢复告警失败", m.AlertService.AutoResolve)
 			case <-m.stopCh:
 				m.Logger.Info("告警事件处理器已停止")
 				return
@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
@@ -12,9 +13,11 @@ import (
 )
 
 const (
-	AlertRulesCollection     = "ecam_alert_rule"
-	AlertEventsCollection    = "ecam_alert_event"
-	NotifyChannelsCollection = "ecam_notification_channel"
+	AlertRulesCollection       = "ecam_alert_rule"
+	AlertEventsCollection      = "ecam_alert_event"
+	NotifyChannelsCollection   = "ecam_notification_channel"
+	AlertStatesCollection      = "ecam_alert_state"
+	MessageTemplatesCollection = "ecam_alert_template"
 )
 
 // AlertDAO 告警数据访问接口
@@ -29,10 +32,38 @@ type AlertDAO interface {
 	// 告警事件
 	CreateEvent(ctx context.Context, event domain.AlertEvent) (int64, error)
 	UpdateEventStatus(ctx context.Context, id int64, status domain.EventStatus) error
+	GetEventByID(ctx context.Context, id int64) (domain.AlertEvent, error)
+	// ResolveEvents 将指纹下未恢复（待发送 / 已发送 / 已静默 / 已确认）的事件置为已恢复，返回更新条数
+	ResolveEvents(ctx context.Context, fingerprint string, t domain.EventTransition) (int64, error)
+	// AckEvent 确认事件：置为已确认并停止超时重新通知；事件已确认 / 已恢复（或不存在）时不更新并返回 false
+	AckEvent(ctx context.Context, id int64, t domain.EventTransition) (bool, error)
+	// AssignEvent 指派事件处理人；事件已恢复（或不存在）时不更新并返回 false
+	AssignEvent(ctx context.Context, id int64, assignee string, t domain.EventTransition) (bool, error)
+	AppendEventHistory(ctx context.Context, id int64, t domain.EventTransition) error
+	SetAckDeadline(ctx context.Context, id int64, deadline *time.Time) error // nil 清除确认期限
+	// RenotifyEvent 记录一次超时重新通知并顺延确认期限
+	RenotifyEvent(ctx context.Context, id int64, deadline time.Time, t domain.EventTransition) error
+	// GetRenotifyEvents 获取已发送但超过确认期限仍未确认的事件
+	GetRenotifyEvents(ctx context.Context, now time.Time, limit int) ([]domain.AlertEvent, error)
 	ListEvents(ctx context.Context, filter domain.AlertEventFilter) ([]domain.AlertEvent, int64, error)
 	GetPendingEvents(ctx context.Context, limit int) ([]domain.AlertEvent, error)
 	IncrementRetry(ctx context.Context, id int64) error
 
+	// 告警指纹状态
+	GetState(ctx context.Context, fingerprint string) (domain.AlertState, error) // 不存在时返回零值
+	// RecordOccurrence 原子累计指纹发生次数：不存在时创建，已恢复或 LastSeen 早于 resetBefore 时开始新一轮计数，返回更新后的状态
+	RecordOccurrence(ctx context.Context, state domain.AlertState, resetBefore time.Time) (domain.AlertState, error)
+	// MarkStateEscalated 将指纹标记为已升级，本轮已升级时返回 false
+	MarkStateEscalated(ctx context.Context, fingerprint string) (bool, error)
+	// MarkStateNotified 上次通知早于 silence 之前（或从未通知）时记录本次通知时间并返回 true，否则返回 false
+	MarkStateNotified(ctx context.Context, fingerprint string, now time.Time, silence time.Duration) (bool, error)
+	SetStateLastEvent(ctx context.Context, fingerprint string, eventID int64) error
+	// ResolveState 将指纹置为已恢复，不存在时创建
+	ResolveState(ctx context.Context, state domain.AlertState, resolvedAt time.Time) error
+	ListActiveStates(ctx context.Context) ([]domain.AlertState, error)
+	// ListActiveStatesByType 获取租户下指定类型未恢复的指纹状态
+	ListActiveStatesByType(ctx context.Context, tenantID string, alertType domain.AlertType) ([]domain.AlertState, error)
+
 	// 通知渠道
 	CreateChannel(ctx context.Context, ch domain.NotificationChannel) (int64, error)
 	UpdateChannel(ctx context.Context, ch domain.NotificationChannel) error
@@ -41,6 +72,13 @@ type AlertDAO interface {
 	DeleteChannel(ctx context.Context, id int64) error
 	GetChannelsByIDs(ctx context.Context, ids []int64) ([]domain.NotificationChannel, error)
 
+	// 消息模板
+	CreateTemplate(ctx context.Context, tmpl domain.MessageTemplate) (int64, error)
+	UpdateTemplate(ctx context.Context, tmpl domain.MessageTemplate) error
+	GetTemplateByID(ctx context.Context, id int64) (domain.MessageTemplate, error)
+	ListTemplates(ctx context.Context, filter domain.MessageTemplateFilter) ([]domain.MessageTemplate, int64, error)
+	DeleteTemplate(ctx context.Context, id int64) error
+
 	// 索引初始化
 	InitIndexes(ctx context.Context) error
 }
@@ -69,16 +107,36 @@ func (d *alertDAO) InitIndexes(ctx context.Context) error {
 		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "status", Value: 1}}},
 		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "create_time", Value: -1}}},
 		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "create_time", Value: 1}}},
+		{Keys: bson.D{{Key: "fingerprint", Value: 1}, {Key: "create_time", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "ack_deadline", Value: 1}}},
 	}
 	if _, err := d.db.Collection(AlertEventsCollection).Indexes().CreateMany(ctx, eventsIndexes); err != nil {
 		return err
 	}
 
+	// 告警指纹状态索引
+	stateIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "fingerprint", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "resolved", Value: 1}, {Key: "last_seen", Value: 1}}},
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "type", Value: 1}, {Key: "resolved", Value: 1}}},
+	}
+	if _, err := d.db.Collection(AlertStatesCollection).Indexes().CreateMany(ctx, stateIndexes); err != nil {
+		return err
+	}
+
 	// 通知渠道索引
 	channelIndexes := []mongo.IndexModel{
 		{Keys: bson.D{{Key: "tenant_id", Value: 1}}},
 	}
-	_, err := d.db.Collection(NotifyChannelsCollection).Indexes().CreateMany(ctx, channelIndexes)
+	if _, err := d.db.Collection(NotifyChannelsCollection).Indexes().CreateMany(ctx, channelIndexes); err != nil {
+		return err
+	}
+
+	// 消息模板索引
+	templateIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "channel_type", Value: 1}, {Key: "alert_type", Value: 1}}},
+	}
+	_, err := d.db.Collection(MessageTemplatesCollection).Indexes().CreateMany(ctx, templateIndexes)
 	return err
 }
 
@@ -162,6 +220,10 @@ func (d *alertDAO) CreateEvent(ctx context.Context, event domain.AlertEvent) (in
 	if event.Status == "" {
 		event.Status = domain.EventStatusPending
 	}
+	// history 需为数组，后续状态流转通过 $push 追加
+	if event.History == nil {
+		event.History = []domain.EventTransition{{Status: event.Status, Time: event.CreateTime}}
+	}
 	if event.ID == 0 {
 		event.ID = d.db.GetIdGenerator(AlertEventsCollection)
 	}
@@ -170,15 +232,133 @@ func (d *alertDAO) CreateEvent(ctx context.Context, event domain.AlertEvent) (in
 }
 
 func (d *alertDAO) UpdateEventStatus(ctx context.Context, id int64, status domain.EventStatus) error {
-	update := bson.M{"$set": bson.M{"status": status}}
-	if status == domain.EventStatusSent {
-		now := time.Now()
+	now := time.Now()
+	update := bson.M{
+		"$set":  bson.M{"status": status},
+		"$push": bson.M{"history": domain.EventTransition{Status: status, Time: now}},
+	}
+	switch status {
+	case domain.EventStatusSent:
 		update["$set"].(bson.M)["sent_at"] = &now
+	case domain.EventStatusResolved:
+		update["$set"].(bson.M)["resolved_at"] = &now
+	}
+	_, err := d.db.Collection(AlertEventsCollection).UpdateOne(ctx, bson.M{"id": id}, update)
+	return err
+}
+
+func (d *alertDAO) GetEventByID(ctx context.Context, id int64) (domain.AlertEvent, error) {
+	var event domain.AlertEvent
+	err := d.db.Collection(AlertEventsCollection).FindOne(ctx, bson.M{"id": id}).Decode(&event)
+	return event, err
+}
+
+func (d *alertDAO) ResolveEvents(ctx context.Context, fingerprint string, t domain.EventTransition) (int64, error) {
+	t.Status = domain.EventStatusResolved
+	filter := bson.M{
+		"fingerprint": fingerprint,
+		"status": bson.M{"$in": []domain.EventStatus{
+			domain.EventStatusPending, domain.EventStatusSending, domain.EventStatusSent,
+			domain.EventStatusSilenced, domain.EventStatusAcknowledged,
+		}},
+	}
+	update := bson.M{
+		"$set":  bson.M{"status": domain.EventStatusResolved, "resolved_at": &t.Time, "ack_deadline": nil},
+		"$push": bson.M{"history": t},
+	}
+	res, err := d.db.Collection(AlertEventsCollection).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (d *alertDAO) AckEvent(ctx context.Context, id int64, t domain.EventTransition) (bool, error) {
+	t.Status = domain.EventStatusAcknowledged
+	// 可确认的状态写入更新条件，并发确认 / 恢复时只有一方生效
+	filter := bson.M{
+		"id": id,
+		"status": bson.M{"$in": []domain.EventStatus{
+			domain.EventStatusPending, domain.EventStatusSending, domain.EventStatusSent,
+			domain.EventStatusFailed, domain.EventStatusSilenced,
+		}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       domain.EventStatusAcknowledged,
+			"acked_by":     t.Operator,
+			"acked_at":     &t.Time,
+			"ack_deadline": nil,
+		},
+		"$push": bson.M{"history": t},
+	}
+	res, err := d.db.Collection(AlertEventsCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (d *alertDAO) AssignEvent(ctx context.Context, id int64, assignee string, t domain.EventTransition) (bool, error) {
+	filter := bson.M{
+		"id":     id,
+		"status": bson.M{"$ne": domain.EventStatusResolved},
+	}
+	update := bson.M{
+		"$set":  bson.M{"assignee": assignee},
+		"$push": bson.M{"history": t},
+	}
+	res, err := d.db.Collection(AlertEventsCollection).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
+func (d *alertDAO) AppendEventHistory(ctx context.Context, id int64, t domain.EventTransition) error {
+	update := bson.M{"$push": bson.M{"history": t}}
+	_, err := d.db.Collection(AlertEventsCollection).UpdateOne(ctx, bson.M{"id": id}, update)
+	return err
+}
+
+func (d *alertDAO) SetAckDeadline(ctx context.Context, id int64, deadline *time.Time) error {
+	update := bson.M{"$set": bson.M{"ack_deadline": deadline}}
+	_, err := d.db.Collection(AlertEventsCollection).UpdateOne(ctx, bson.M{"id": id}, update)
+	return err
+}
+
+func (d *alertDAO) RenotifyEvent(ctx context.Context, id int64, deadline time.Time, t domain.EventTransition) error {
+	update := bson.M{
+		"$set":  bson.M{"ack_deadline": &deadline},
+		"$inc":  bson.M{"renotify_count": 1},
+		"$push": bson.M{"history": t},
 	}
 	_, err := d.db.Collection(AlertEventsCollection).UpdateOne(ctx, bson.M{"id": id}, update)
 	return err
 }
 
+func (d *alertDAO) GetRenotifyEvents(ctx context.Context, now time.Time, limit int) ([]domain.AlertEvent, error) {
+	query := bson.M{
+		"status":       domain.EventStatusSent,
+		"ack_deadline": bson.M{"$ne": nil, "$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "ack_deadline", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := d.db.Collection(AlertEventsCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var events []domain.AlertEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
 func (d *alertDAO) ListEvents(ctx context.Context, filter domain.AlertEventFilter) ([]domain.AlertEvent, int64, error) {
 	query := d.buildEventQuery(filter)
 
@@ -251,9 +431,143 @@ func (d *alertDAO) buildEventQuery(filter domain.AlertEventFilter) bson.M {
 	if filter.RuleID > 0 {
 		query["rule_id"] = filter.RuleID
 	}
+	if filter.Fingerprint != "" {
+		query["fingerprint"] = filter.Fingerprint
+	}
 	return query
 }
 
+// ========== 告警指纹状态 ==========
+
+func (d *alertDAO) GetState(ctx context.Context, fingerprint string) (domain.AlertState, error) {
+	var state domain.AlertState
+	err := d.db.Collection(AlertStatesCollection).FindOne(ctx, bson.M{"fingerprint": fingerprint}).Decode(&state)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.AlertState{}, nil
+	}
+	return state, err
+}
+
+func (d *alertDAO) RecordOccurrence(ctx context.Context, state domain.AlertState, resetBefore time.Time) (domain.AlertState, error) {
+	coll := d.db.Collection(AlertStatesCollection)
+	// 上一轮已恢复或间隔过久：清零后再累加，条件更新保证并发下只重置一次
+	_, err := coll.UpdateOne(ctx,
+		bson.M{
+			"fingerprint": state.Fingerprint,
+			"$or": bson.A{
+				bson.M{"resolved": true},
+				bson.M{"last_seen": bson.M{"$lt": resetBefore}},
+			},
+		},
+		bson.M{"$set": bson.M{
+			"count":            0,
+			"first_seen":       state.LastSeen,
+			"escalated":        false,
+			"last_notified_at": nil,
+			"resolved":         false,
+			"resolved_at":      nil,
+		}})
+	if err != nil {
+		return domain.AlertState{}, err
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"count": 1},
+		"$set": bson.M{"last_seen": state.LastSeen},
+		"$setOnInsert": bson.M{
+			"rule_id":          state.RuleID,
+			"type":             state.Type,
+			"resource":         state.Resource,
+			"tenant_id":        state.TenantID,
+			"first_seen":       state.LastSeen,
+			"last_notified_at": nil,
+			"last_event_id":    int64(0),
+			"escalated":        false,
+			"resolved":         false,
+			"resolved_at":      nil,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var updated domain.AlertState
+	err = coll.FindOneAndUpdate(ctx, bson.M{"fingerprint": state.Fingerprint}, update, opts).Decode(&updated)
+	if mongo.IsDuplicateKeyError(err) {
+		// 并发首次插入冲突，文档已由另一方创建，重试一次即为普通累加
+		err = coll.FindOneAndUpdate(ctx, bson.M{"fingerprint": state.Fingerprint}, update, opts).Decode(&updated)
+	}
+	return updated, err
+}
+
+func (d *alertDAO) MarkStateEscalated(ctx context.Context, fingerprint string) (bool, error) {
+	result, err := d.db.Collection(AlertStatesCollection).UpdateOne(ctx,
+		bson.M{"fingerprint": fingerprint, "escalated": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"escalated": true}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (d *alertDAO) MarkStateNotified(ctx context.Context, fingerprint string, now time.Time, silence time.Duration) (bool, error) {
+	filter := bson.M{"fingerprint": fingerprint}
+	if silence > 0 {
+		filter["$or"] = bson.A{
+			bson.M{"last_notified_at": nil},
+			bson.M{"last_notified_at": bson.M{"$lte": now.Add(-silence)}},
+		}
+	}
+	result, err := d.db.Collection(AlertStatesCollection).UpdateOne(ctx, filter,
+		bson.M{"$set": bson.M{"last_notified_at": now}})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (d *alertDAO) SetStateLastEvent(ctx context.Context, fingerprint string, eventID int64) error {
+	_, err := d.db.Collection(AlertStatesCollection).UpdateOne(ctx,
+		bson.M{"fingerprint": fingerprint},
+		bson.M{"$set": bson.M{"last_event_id": eventID}})
+	return err
+}
+
+func (d *alertDAO) ResolveState(ctx context.Context, state domain.AlertState, resolvedAt time.Time) error {
+	_, err := d.db.Collection(AlertStatesCollection).UpdateOne(ctx,
+		bson.M{"fingerprint": state.Fingerprint},
+		bson.M{
+			"$set": bson.M{"resolved": true, "resolved_at": resolvedAt},
+			"$setOnInsert": bson.M{
+				"rule_id":   state.RuleID,
+				"type":      state.Type,
+				"resource":  state.Resource,
+				"tenant_id": state.TenantID,
+			},
+		},
+		options.Update().SetUpsert(true))
+	return err
+}
+
+func (d *alertDAO) ListActiveStates(ctx context.Context) ([]domain.AlertState, error) {
+	return d.findStates(ctx, bson.M{"resolved": false})
+}
+
+func (d *alertDAO) ListActiveStatesByType(ctx context.Context, tenantID string, alertType domain.AlertType) ([]domain.AlertState, error) {
+	return d.findStates(ctx, bson.M{"tenant_id": tenantID, "type": alertType, "resolved": false})
+}
+
+func (d *alertDAO) findStates(ctx context.Context, filter bson.M) ([]domain.AlertState, error) {
+	cursor, err := d.db.Collection(AlertStatesCollection).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var states []domain.AlertState
+	if err := cursor.All(ctx, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
 // ========== 通知渠道 ==========
 
 func (d *alertDAO) CreateChannel(ctx context.Context, ch domain.NotificationChannel) (int64, error) {
@@ -341,3 +655,80 @@ func (d *alertDAO) buildChannelQuery(filter domain.ChannelFilter) bson.M {
 	}
 	return query
 }
+
+// ========== 消息模板 ==========
+
+func (d *alertDAO) CreateTemplate(ctx context.Context, tmpl domain.MessageTemplate) (int64, error) {
+	now := time.Now()
+	tmpl.CreateTime = now
+	tmpl.UpdateTime = now
+	if tmpl.ID == 0 {
+		tmpl.ID = d.db.GetIdGenerator(MessageTemplatesCollection)
+	}
+	_, err := d.db.Collection(MessageTemplatesCollection).InsertOne(ctx, tmpl)
+	return tmpl.ID, err
+}
+
+func (d *alertDAO) UpdateTemplate(ctx context.Context, tmpl domain.MessageTemplate) error {
+	update := bson.M{"$set": bson.M{
+		"name":         tmpl.Name,
+		"channel_type": tmpl.ChannelType,
+		"alert_type":   tmpl.AlertType,
+		"title":        tmpl.Title,
+		"content":      tmpl.Content,
+		"enabled":      tmpl.Enabled,
+		"update_time":  time.Now(),
+	}}
+	_, err := d.db.Collection(MessageTemplatesCollection).UpdateOne(ctx, bson.M{"id": tmpl.ID}, update)
+	return err
+}
+
+func (d *alertDAO) GetTemplateByID(ctx context.Context, id int64) (domain.MessageTemplate, error) {
+	var tmpl domain.MessageTemplate
+	err := d.db.Collection(MessageTemplatesCollection).FindOne(ctx, bson.M{"id": id}).Decode(&tmpl)
+	return tmpl, err
+}
+
+func (d *alertDAO) ListTemplates(ctx context.Context, filter domain.MessageTemplateFilter) ([]domain.MessageTemplate, int64, error) {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.ChannelType != "" {
+		query["channel_type"] = filter.ChannelType
+	}
+	if filter.AlertType != "" {
+		query["alert_type"] = filter.AlertType
+	}
+	if filter.Enabled != nil {
+		query["enabled"] = *filter.Enabled
+	}
+
+	total, err := d.db.Collection(MessageTemplatesCollection).CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "create_time", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+		opts.SetSkip(filter.Offset)
+	}
+
+	cursor, err := d.db.Collection(MessageTemplatesCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var templates []domain.MessageTemplate
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, 0, err
+	}
+	return templates, total, nil
+}
+
+func (d *alertDAO) DeleteTemplate(ctx context.Context, id int64) error {
+	_, err := d.db.Collection(MessageTemplatesCollection).DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
@@ -0,0 +1,189 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AlertDeliveriesCollection = "ecam_alert_delivery"
+
+// DeliveryDAO 告警投递记录数据访问接口
+type DeliveryDAO interface {
+	// CreateDelivery 创建投递记录，同一 (事件, 渠道, 轮次) 只能有一条
+	CreateDelivery(ctx context.Context, delivery domain.AlertDelivery) (int64, error)
+	UpdateDelivery(ctx context.Context, delivery domain.AlertDelivery) error
+	GetDeliveryByID(ctx context.Context, id int64) (domain.AlertDelivery, error)
+	ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.AlertDelivery, int64, error)
+	ListEventDeliveries(ctx context.Context, eventID int64) ([]domain.AlertDelivery, error)
+	// GetDueDeliveries 获取到期待发送或待重试的投递
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.AlertDelivery, error)
+	// AggregateStats 按渠道统计 since 之后创建的投递
+	AggregateStats(ctx context.Context, tenantID string, since time.Time) ([]domain.DeliveryStat, error)
+
+	InitIndexes(ctx context.Context) error
+}
+
+type deliveryDAO struct {
+	db *mongox.Mongo
+}
+
+func NewDeliveryDAO(db *mongox.Mongo) DeliveryDAO {
+	return &deliveryDAO{db: db}
+}
+
+// InitIndexes 初始化索引
+func (d *deliveryDAO) InitIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "event_id", Value: 1}, {Key: "channel_id", Value: 1}, {Key: "round", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "create_time", Value: -1}}},
+	}
+	_, err := d.db.Collection(AlertDeliveriesCollection).Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (d *deliveryDAO) CreateDelivery(ctx context.Context, delivery domain.AlertDelivery) (int64, error) {
+	now := time.Now()
+	delivery.CreateTime = now
+	delivery.UpdateTime = now
+	if delivery.ID == 0 {
+		delivery.ID = d.db.GetIdGenerator(AlertDeliveriesCollection)
+	}
+	_, err := d.db.Collection(AlertDeliveriesCollection).InsertOne(ctx, delivery)
+	return delivery.ID, err
+}
+
+func (d *deliveryDAO) UpdateDelivery(ctx context.Context, delivery domain.AlertDelivery) error {
+	update := bson.M{"$set": bson.M{
+		"status":          delivery.Status,
+		"attempts":        delivery.Attempts,
+		"throttled":       delivery.Throttled,
+		"next_attempt_at": delivery.NextAttemptAt,
+		"last_attempt_at": delivery.LastAttemptAt,
+		"last_error":      delivery.LastError,
+		"sent_at":         delivery.SentAt,
+		"dead_at":         delivery.DeadAt,
+		"update_time":     time.Now(),
+	}}
+	_, err := d.db.Collection(AlertDeliveriesCollection).UpdateOne(ctx, bson.M{"id": delivery.ID}, update)
+	return err
+}
+
+func (d *deliveryDAO) GetDeliveryByID(ctx context.Context, id int64) (domain.AlertDelivery, error) {
+	var delivery domain.AlertDelivery
+	err := d.db.Collection(AlertDeliveriesCollection).FindOne(ctx, bson.M{"id": id}).Decode(&delivery)
+	return delivery, err
+}
+
+func (d *deliveryDAO) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.AlertDelivery, int64, error) {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.EventID > 0 {
+		query["event_id"] = filter.EventID
+	}
+	if filter.ChannelID > 0 {
+		query["channel_id"] = filter.ChannelID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	total, err := d.db.Collection(AlertDeliveriesCollection).CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "create_time", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+		opts.SetSkip(filter.Offset)
+	}
+	deliveries, err := d.find(ctx, query, opts)
+	return deliveries, total, err
+}
+
+func (d *deliveryDAO) ListEventDeliveries(ctx context.Context, eventID int64) ([]domain.AlertDelivery, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	return d.find(ctx, bson.M{"event_id": eventID}, opts)
+}
+
+func (d *deliveryDAO) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.AlertDelivery, error) {
+	query := bson.M{
+		"status":          bson.M{"$in": []domain.DeliveryStatus{domain.DeliveryPending, domain.DeliveryRetrying}},
+		"next_attempt_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetLimit(int64(limit))
+	return d.find(ctx, query, opts)
+}
+
+func (d *deliveryDAO) AggregateStats(ctx context.Context, tenantID string, since time.Time) ([]domain.DeliveryStat, error) {
+	match := bson.M{"create_time": bson.M{"$gte": since}}
+	if tenantID != "" {
+		match["tenant_id"] = tenantID
+	}
+	countIf := func(statuses ...domain.DeliveryStatus) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$status", statuses}}, 1, 0}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":          "$channel_id",
+			"channel_type": bson.M{"$last": "$channel_type"},
+			"channel_name": bson.M{"$last": "$channel_name"},
+			"total":        bson.M{"$sum": 1},
+			"sent":         countIf(domain.DeliverySent),
+			"pending":      countIf(domain.DeliveryPending, domain.DeliveryRetrying),
+			"dead":         countIf(domain.DeliveryDead),
+			"canceled":     countIf(domain.DeliveryCanceled),
+			"attempts":     bson.M{"$sum": "$attempts"},
+			"throttled":    bson.M{"$sum": "$throttled"},
+			// $avg 忽略 null，只统计已送达的投递
+			"avg_latency_ms": bson.M{"$avg": bson.M{"$cond": bson.A{
+				bson.M{"$eq": bson.A{"$status", domain.DeliverySent}},
+				bson.M{"$subtract": bson.A{"$sent_at", "$create_time"}},
+				nil,
+			}}},
+		}}},
+		{{Key: "$addFields", Value: bson.M{"channel_id": "$_id"}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := d.db.Collection(AlertDeliveriesCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []domain.DeliveryStat
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (d *deliveryDAO) find(ctx context.Context, query bson.M, opts *options.FindOptions) ([]domain.AlertDelivery, error) {
+	cursor, err := d.db.Collection(AlertDeliveriesCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []domain.AlertDelivery
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,112 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const OnCallSchedulesCollection = "ecam_alert_oncall_schedule"
+
+// OnCallDAO 值班表数据访问接口
+type OnCallDAO interface {
+	CreateSchedule(ctx context.Context, schedule domain.OnCallSchedule) (int64, error)
+	UpdateSchedule(ctx context.Context, schedule domain.OnCallSchedule) error
+	GetScheduleByID(ctx context.Context, id int64) (domain.OnCallSchedule, error)
+	ListSchedules(ctx context.Context, filter domain.OnCallScheduleFilter) ([]domain.OnCallSchedule, int64, error)
+	DeleteSchedule(ctx context.Context, id int64) error
+
+	InitIndexes(ctx context.Context) error
+}
+
+type onCallDAO struct {
+	db *mongox.Mongo
+}
+
+func NewOnCallDAO(db *mongox.Mongo) OnCallDAO {
+	return &onCallDAO{db: db}
+}
+
+// InitIndexes 初始化索引
+func (d *onCallDAO) InitIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "node_id", Value: 1}}},
+	}
+	_, err := d.db.Collection(OnCallSchedulesCollection).Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+func (d *onCallDAO) CreateSchedule(ctx context.Context, schedule domain.OnCallSchedule) (int64, error) {
+	now := time.Now()
+	schedule.CreateTime = now
+	schedule.UpdateTime = now
+	if schedule.ID == 0 {
+		schedule.ID = d.db.GetIdGenerator(OnCallSchedulesCollection)
+	}
+	_, err := d.db.Collection(OnCallSchedulesCollection).InsertOne(ctx, schedule)
+	return schedule.ID, err
+}
+
+func (d *onCallDAO) UpdateSchedule(ctx context.Context, schedule domain.OnCallSchedule) error {
+	schedule.UpdateTime = time.Now()
+	update := bson.M{"$set": bson.M{
+		"name":        schedule.Name,
+		"node_id":     schedule.NodeID,
+		"rotation":    schedule.Rotation,
+		"members":     schedule.Members,
+		"start_time":  schedule.StartTime,
+		"overrides":   schedule.Overrides,
+		"update_time": schedule.UpdateTime,
+	}}
+	_, err := d.db.Collection(OnCallSchedulesCollection).UpdateOne(ctx, bson.M{"id": schedule.ID}, update)
+	return err
+}
+
+func (d *onCallDAO) GetScheduleByID(ctx context.Context, id int64) (domain.OnCallSchedule, error) {
+	var schedule domain.OnCallSchedule
+	err := d.db.Collection(OnCallSchedulesCollection).FindOne(ctx, bson.M{"id": id}).Decode(&schedule)
+	return schedule, err
+}
+
+func (d *onCallDAO) ListSchedules(ctx context.Context, filter domain.OnCallScheduleFilter) ([]domain.OnCallSchedule, int64, error) {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.NodeID != nil {
+		query["node_id"] = *filter.NodeID
+	}
+
+	total, err := d.db.Collection(OnCallSchedulesCollection).CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+		opts.SetSkip(filter.Offset)
+	}
+
+	cursor, err := d.db.Collection(OnCallSchedulesCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var schedules []domain.OnCallSchedule
+	if err := cursor.All(ctx, &schedules); err != nil {
+		return nil, 0, err
+	}
+	return schedules, total, nil
+}
+
+func (d *onCallDAO) DeleteSchedule(ctx context.Context, id int64) error {
+	_, err := d.db.Collection(OnCallSchedulesCollection).DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
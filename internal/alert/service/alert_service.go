@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/alert/channel"
+	"github.com/Havens-blog/e-cam-service/internal/alert/condition"
 	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
 	"github.com/Havens-blog/e-cam-service/internal/alert/repository/dao"
 	"github.com/gotomicro/ego/core/elog"
@@ -15,13 +17,29 @@ import (
 
 // AlertService 告警服务
 type AlertService struct {
-	dao    dao.AlertDAO
-	logger *elog.Component
+	dao        dao.AlertDAO
+	logger     *elog.Component
+	now        func() time.Time
+	conditions sync.Map // 条件表达式源码 -> *condition.Expr
+	onCall     OnCallResolver
+	deliveries dao.DeliveryDAO
+	limiter    *channel.RateLimiter
+}
+
+// OnCallResolver 值班查询接口（可选注入）
+// 注入后告警按规则所属服务树节点路由到当前值班人：额外发送到值班人的个人渠道并自动指派
+type OnCallResolver interface {
+	CurrentOnCall(ctx context.Context, tenantID string, nodeID int64, at time.Time) (*domain.OnCallShift, error)
 }
 
 // NewAlertService 创建告警服务
 func NewAlertService(dao dao.AlertDAO, logger *elog.Component) *AlertService {
-	return &AlertService{dao: dao, logger: logger}
+	return &AlertService{dao: dao, logger: logger, now: time.Now}
+}
+
+// SetOnCallResolver 设置值班查询（可选注入）
+func (s *AlertService) SetOnCallResolver(r OnCallResolver) {
+	s.onCall = r
 }
 
 // ========== 告警规则管理 ==========
@@ -33,11 +51,17 @@ func (s *AlertService) CreateRule(ctx context.Context, rule domain.AlertRule) (i
 	if rule.Type == "" {
 		return 0, fmt.Errorf("规则类型不能为空")
 	}
+	if _, err := condition.FromRule(rule.Condition); err != nil {
+		return 0, err
+	}
 	rule.Enabled = true
 	return s.dao.CreateRule(ctx, rule)
 }
 
 func (s *AlertService) UpdateRule(ctx context.Context, rule domain.AlertRule) error {
+	if _, err := condition.FromRule(rule.Condition); err != nil {
+		return err
+	}
 	return s.dao.UpdateRule(ctx, rule)
 }
 
@@ -69,6 +93,8 @@ func (s *AlertService) ListEvents(ctx context.Context, filter domain.AlertEventF
 }
 
 // EmitEvent 触发告警事件 - 匹配规则并创建事件
+// 同一规则、资源、类型的事件按指纹去重：静默期内重复发生的事件标记为 silenced，
+// 连续发生达到 EscalateAfter 次时升级通知到 EscalateChannels
 func (s *AlertService) EmitEvent(ctx context.Context, event domain.AlertEvent) error {
 	// 查找匹配的启用规则
 	enabled := true
@@ -96,24 +122,76 @@ func (s *AlertService) EmitEvent(ctx context.Context, event domain.AlertEvent) e
 
 		evt := event
 		evt.RuleID = rule.ID
-		if _, err := s.dao.CreateEvent(ctx, evt); err != nil {
+		state, tracked := s.trackOccurrence(ctx, rule, &evt)
+		id, err := s.dao.CreateEvent(ctx, evt)
+		if err != nil {
 			s.logger.Error("创建告警事件失败",
 				elog.Int64("rule_id", rule.ID),
 				elog.FieldErr(err))
 			continue
 		}
+		if tracked {
+			if err := s.dao.SetStateLastEvent(ctx, state.Fingerprint, id); err != nil {
+				s.logger.Error("保存告警指纹状态失败",
+					elog.String("fingerprint", state.Fingerprint),
+					elog.FieldErr(err))
+			}
+		}
 
 		s.logger.Info("告警事件已创建",
 			elog.String("title", evt.Title),
 			elog.String("severity", string(evt.Severity)),
+			elog.String("status", string(evt.Status)),
+			elog.Int("occurrence", evt.Occurrence),
 			elog.Int64("rule_id", rule.ID))
 	}
 
 	return nil
 }
 
-// matchRule 检查事件是否匹配规则的过滤条件
+// matchRule 检查事件是否匹配规则的过滤条件与条件表达式，表达式求值出错视为不匹配
 func (s *AlertService) matchRule(rule domain.AlertRule, event domain.AlertEvent) bool {
+	if !s.matchFilters(rule, event) {
+		return false
+	}
+	expr, err := s.ruleCondition(rule)
+	if err == nil {
+		var matched bool
+		if matched, err = matchCondition(expr, event); err == nil {
+			return matched
+		}
+	}
+	s.logger.Warn("告警条件求值失败",
+		elog.Int64("rule_id", rule.ID),
+		elog.FieldErr(err))
+	return false
+}
+
+// ruleCondition 获取规则编译后的条件表达式（按源码缓存），未配置时返回 nil
+func (s *AlertService) ruleCondition(rule domain.AlertRule) (*condition.Expr, error) {
+	src, _ := rule.Condition[condition.Key].(string)
+	if cached, ok := s.conditions.Load(src); ok {
+		return cached.(*condition.Expr), nil
+	}
+	expr, err := condition.FromRule(rule.Condition)
+	if err != nil || expr == nil {
+		return nil, err
+	}
+	s.conditions.Store(src, expr)
+	return expr, nil
+}
+
+// matchCondition 对事件求值条件表达式，资源标识未设置时按去重规则推断
+func matchCondition(expr *condition.Expr, event domain.AlertEvent) (bool, error) {
+	if expr == nil {
+		return true, nil
+	}
+	event.Resource = eventResource(event)
+	return expr.Match(event)
+}
+
+// matchFilters 检查事件是否匹配规则的账号、资源类型与地域过滤
+func (s *AlertService) matchFilters(rule domain.AlertRule, event domain.AlertEvent) bool {
 	// 检查账号过滤
 	if len(rule.AccountIDs) > 0 {
 		accountID, _ := event.Content["account_id"].(float64)
@@ -141,6 +219,61 @@ func (s *AlertService) matchRule(rule domain.AlertRule, event domain.AlertEvent)
 	return true
 }
 
+// 规则试运行回放的事件条数
+const (
+	defaultDryRunLimit = 100
+	maxDryRunLimit     = 1000
+)
+
+// DryRunRule 用草稿规则回放租户最近 limit 条同类型告警事件（类型为空时不限类型），返回逐条匹配结果
+func (s *AlertService) DryRunRule(ctx context.Context, rule domain.AlertRule, limit int64) (*domain.RuleDryRunResult, error) {
+	expr, err := condition.FromRule(rule.Condition)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDryRunLimit
+	}
+	if limit > maxDryRunLimit {
+		limit = maxDryRunLimit
+	}
+
+	events, _, err := s.dao.ListEvents(ctx, domain.AlertEventFilter{
+		TenantID: rule.TenantID,
+		Type:     rule.Type,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("查询告警事件失败: %w", err)
+	}
+
+	result := &domain.RuleDryRunResult{Total: len(events), Items: make([]domain.RuleDryRunEvent, 0, len(events))}
+	if expr != nil {
+		result.Condition = expr.String()
+	}
+	for _, event := range events {
+		item := domain.RuleDryRunEvent{
+			EventID:    event.ID,
+			Type:       event.Type,
+			Severity:   event.Severity,
+			Title:      event.Title,
+			CreateTime: event.CreateTime,
+		}
+		if s.matchFilters(rule, event) {
+			matched, err := matchCondition(expr, event)
+			if err != nil {
+				item.Error = err.Error()
+			}
+			item.Matched = matched
+		}
+		if item.Matched {
+			result.Matched++
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
 // ProcessPendingEvents 处理待发送的告警事件
 func (s *AlertService) ProcessPendingEvents(ctx context.Context) error {
 	events, err := s.dao.GetPendingEvents(ctx, 50)
@@ -149,7 +282,13 @@ func (s *AlertService) ProcessPendingEvents(ctx context.Context) error {
 	}
 
 	for _, event := range events {
-		if err := s.sendEvent(ctx, event); err != nil {
+		rule, err := s.dao.GetRuleByID(ctx, event.RuleID)
+		if err != nil {
+			err = fmt.Errorf("获取规则失败: %w", err)
+		} else {
+			err = s.dispatchEvent(ctx, rule, event, 0)
+		}
+		if err != nil {
 			s.logger.Error("发送告警事件失败",
 				elog.Int64("event_id", event.ID),
 				elog.FieldErr(err))
@@ -159,32 +298,170 @@ func (s *AlertService) ProcessPendingEvents(ctx context.Context) error {
 			}
 			continue
 		}
-		s.dao.UpdateEventStatus(ctx, event.ID, domain.EventStatusSent)
+		if s.deliveries != nil {
+			s.syncEventStatus(ctx, rule, event)
+		} else {
+			s.markSent(ctx, rule, event.ID)
+		}
 	}
 
 	return nil
 }
 
-// sendEvent 发送单个告警事件
-func (s *AlertService) sendEvent(ctx context.Context, event domain.AlertEvent) error {
-	rule, err := s.dao.GetRuleByID(ctx, event.RuleID)
+// markSent 标记事件已发送，规则配置确认超时时设置确认期限
+func (s *AlertService) markSent(ctx context.Context, rule domain.AlertRule, eventID int64) {
+	s.dao.UpdateEventStatus(ctx, eventID, domain.EventStatusSent)
+	if rule.AckTimeout > 0 {
+		deadline := s.now().Add(time.Duration(rule.AckTimeout) * time.Minute)
+		s.dao.SetAckDeadline(ctx, eventID, &deadline)
+	}
+}
+
+// RenotifyUnacknowledged 重新通知超过确认期限仍未确认的已发送事件，并顺延确认期限
+func (s *AlertService) RenotifyUnacknowledged(ctx context.Context) error {
+	now := s.now()
+	events, err := s.dao.GetRenotifyEvents(ctx, now, 50)
 	if err != nil {
-		return fmt.Errorf("获取规则失败: %w", err)
+		return fmt.Errorf("获取待重新通知事件失败: %w", err)
+	}
+
+	for _, event := range events {
+		rule, err := s.dao.GetRuleByID(ctx, event.RuleID)
+		if err != nil || rule.AckTimeout <= 0 {
+			// 规则已删除或已关闭确认超时，停止重新通知
+			s.dao.SetAckDeadline(ctx, event.ID, nil)
+			continue
+		}
+
+		event.RenotifyCount++
+		comment := fmt.Sprintf("超过 %d 分钟未确认，第 %d 次重新通知", rule.AckTimeout, event.RenotifyCount)
+		if err := s.dispatchEvent(ctx, rule, event, event.RenotifyCount); err != nil {
+			s.logger.Error("重新通知告警事件失败",
+				elog.Int64("event_id", event.ID),
+				elog.FieldErr(err))
+			comment += "（发送失败: " + err.Error() + "）"
+		}
+		deadline := now.Add(time.Duration(rule.AckTimeout) * time.Minute)
+		if err := s.dao.RenotifyEvent(ctx, event.ID, deadline, domain.EventTransition{
+			Action:  domain.EventActionRenotify,
+			Status:  event.Status,
+			Comment: comment,
+			Time:    now,
+		}); err != nil {
+			s.logger.Error("记录重新通知失败",
+				elog.Int64("event_id", event.ID),
+				elog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+// dispatchEvent 发送告警事件，round 为通知轮次（0 为首次通知，N 为第 N 次重新通知）：
+// 注入投递记录存储时按渠道独立投递，否则同步分发到全部渠道
+func (s *AlertService) dispatchEvent(ctx context.Context, rule domain.AlertRule, event domain.AlertEvent, round int) error {
+	if s.deliveries != nil {
+		return s.deliverEvent(ctx, rule, event, round)
 	}
+	return s.sendEvent(ctx, rule, event)
+}
 
-	channels, err := s.dao.GetChannelsByIDs(ctx, rule.ChannelIDs)
+// sendEvent 同步发送单个告警事件到全部渠道，任一渠道失败即返回错误
+func (s *AlertService) sendEvent(ctx context.Context, rule domain.AlertRule, event domain.AlertEvent) error {
+	shift := s.currentOnCall(ctx, rule, event)
+	channels, err := s.eventChannels(ctx, rule, event, shift)
 	if err != nil {
-		return fmt.Errorf("获取通知渠道失败: %w", err)
+		return err
 	}
 
 	if len(channels) == 0 {
 		s.logger.Warn("规则无可用通知渠道", elog.Int64("rule_id", rule.ID))
-		return nil
+	} else {
+		n := s.newNotification(ctx, event, shift)
+		dispatcher := channel.NewDispatcher(channels)
+		if err := dispatcher.DispatchEach(ctx, func(channelType domain.ChannelType) *channel.Message {
+			return s.renderMessage(n.templates, channelType, n.data, n.base)
+		}); err != nil {
+			return err
+		}
+	}
+
+	s.assignOnCall(ctx, event, shift)
+	return nil
+}
+
+// eventChannels 获取事件的通知渠道：升级事件额外发送到升级渠道，配置值班时额外发送到当前值班人
+func (s *AlertService) eventChannels(ctx context.Context, rule domain.AlertRule, event domain.AlertEvent, shift *domain.OnCallShift) ([]domain.NotificationChannel, error) {
+	channelIDs := append([]int64{}, rule.ChannelIDs...)
+	if event.Escalated {
+		channelIDs = appendUnique(channelIDs, rule.EscalateChannels...)
+	}
+	if shift != nil {
+		channelIDs = appendUnique(channelIDs, shift.Member.ChannelIDs...)
 	}
 
+	channels, err := s.dao.GetChannelsByIDs(ctx, channelIDs)
+	if err != nil {
+		return nil, fmt.Errorf("获取通知渠道失败: %w", err)
+	}
+	return channels, nil
+}
+
+// notification 一次告警通知的内置格式消息与模板渲染数据
+type notification struct {
+	base      *channel.Message
+	data      channel.TemplateData
+	templates []domain.MessageTemplate
+}
+
+func (s *AlertService) newNotification(ctx context.Context, event domain.AlertEvent, shift *domain.OnCallShift) *notification {
 	msg := s.buildMessage(event)
-	dispatcher := channel.NewDispatcher(channels)
-	return dispatcher.Dispatch(ctx, msg)
+	msg.Event = &event
+	data := channel.TemplateData{AlertEvent: event}
+	if shift != nil {
+		data.OnCall = memberName(shift.Member)
+		msg.Content += fmt.Sprintf("**值班人**: %s\n", data.OnCall)
+	}
+	data.DefaultTitle, data.DefaultContent = msg.Title, msg.Content
+	return &notification{base: msg, data: data, templates: s.eventTemplates(ctx, event.TenantID)}
+}
+
+// assignOnCall 事件尚未指派时指派给当前值班人；无可用渠道时也指派，保证告警有人跟进
+func (s *AlertService) assignOnCall(ctx context.Context, event domain.AlertEvent, shift *domain.OnCallShift) {
+	if shift == nil || event.Assignee != "" {
+		return
+	}
+	if _, err := s.dao.AssignEvent(ctx, event.ID, shift.Member.UserID, domain.EventTransition{
+		Action: domain.EventActionAssign,
+		Status: event.Status,
+		Reason: "按值班表自动指派给 " + memberName(shift.Member),
+		Time:   s.now(),
+	}); err != nil {
+		s.logger.Warn("自动指派值班人失败",
+			elog.Int64("event_id", event.ID),
+			elog.FieldErr(err))
+	}
+}
+
+// currentOnCall 查询规则所属服务树节点的当前值班人，未配置值班或查询失败时返回 nil
+func (s *AlertService) currentOnCall(ctx context.Context, rule domain.AlertRule, event domain.AlertEvent) *domain.OnCallShift {
+	if s.onCall == nil {
+		return nil
+	}
+	shift, err := s.onCall.CurrentOnCall(ctx, event.TenantID, rule.NodeID, s.now())
+	if err != nil {
+		s.logger.Warn("查询当前值班人失败",
+			elog.Int64("rule_id", rule.ID),
+			elog.FieldErr(err))
+		return nil
+	}
+	return shift
+}
+
+func memberName(m domain.OnCallMember) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return m.UserID
 }
 
 // buildMessage 构建通知消息
@@ -200,12 +477,29 @@ func (s *AlertService) buildMessage(event domain.AlertEvent) *channel.Message {
 		s.buildExpirationContent(&content, event)
 	case domain.AlertTypeSecurityGroup:
 		s.buildSecurityGroupContent(&content, event)
+	case domain.AlertTypeCostAnomaly:
+		s.buildCostAnomalyContent(&content, event)
+	case domain.AlertTypeBillDrift:
+		s.buildBillDriftContent(&content, event)
+	case domain.AlertTypeChargeback:
+		summary, _ := event.Content["summary"].(string)
+		content.WriteString(summary)
 	default:
 		content.WriteString(fmt.Sprintf("%v", event.Content))
 	}
 
+	title := event.Title
+	if event.Escalated {
+		title = "[升级] " + title
+		content.WriteString(fmt.Sprintf("**连续发生**: %d 次\n", event.Occurrence))
+	}
+	if event.RenotifyCount > 0 {
+		title = "[未确认] " + title
+		content.WriteString(fmt.Sprintf("**重新通知**: 第 %d 次\n", event.RenotifyCount))
+	}
+
 	return &channel.Message{
-		Title:    event.Title,
+		Title:    title,
 		Content:  content.String(),
 		Severity: event.Severity,
 		Markdown: true,
@@ -269,6 +563,77 @@ func (s *AlertService) buildSecurityGroupContent(b *strings.Builder, event domai
 	b.WriteString(fmt.Sprintf("**时间**: %s\n", time.Now().Format("2006-01-02 15:04:05")))
 }
 
+func (s *AlertService) buildCostAnomalyContent(b *strings.Builder, event domain.AlertEvent) {
+	dimension, _ := event.Content["dimension"].(string)
+	dimensionValue, _ := event.Content["dimension_value"].(string)
+	anomalyDate, _ := event.Content["anomaly_date"].(string)
+	currency, _ := event.Content["currency"].(string)
+	actual, _ := event.Content["actual_amount"].(float64)
+	baseline, _ := event.Content["baseline_amount"].(float64)
+	deviationPct, _ := event.Content["deviation_pct"].(float64)
+	model, _ := event.Content["model"].(string)
+	cause, _ := event.Content["possible_cause"].(string)
+	contributors, _ := event.Content["contributor_summary"].(string)
+
+	b.WriteString(fmt.Sprintf("**异常维度**: %s=%s\n", dimension, dimensionValue))
+	b.WriteString(fmt.Sprintf("**异常日期**: %s\n", anomalyDate))
+	b.WriteString(fmt.Sprintf("**实际成本**: %.2f %s\n", actual, currency))
+	b.WriteString(fmt.Sprintf("**基线成本**: %.2f %s\n", baseline, currency))
+	b.WriteString(fmt.Sprintf("**偏离比例**: %.1f%%\n", deviationPct))
+	if model != "" {
+		b.WriteString(fmt.Sprintf("**检测模型**: %s\n", model))
+	}
+	if cause != "" {
+		b.WriteString(fmt.Sprintf("**可能原因**: %s\n", cause))
+	}
+	if contributors != "" {
+		b.WriteString(fmt.Sprintf("**主要贡献项**:\n%s\n", contributors))
+	}
+}
+
+func (s *AlertService) buildBillDriftContent(b *strings.Builder, event domain.AlertEvent) {
+	accountName, _ := event.Content["account_name"].(string)
+	provider, _ := event.Content["provider"].(string)
+	billingMonth, _ := event.Content["billing_month"].(string)
+	currency, _ := event.Content["currency"].(string)
+	detail, _ := event.Content["detail_amount"].(float64)
+	invoice, _ := event.Content["invoice_amount"].(float64)
+	drift, _ := event.Content["drift"].(float64)
+	driftPct, _ := event.Content["drift_pct"].(float64)
+
+	b.WriteString(fmt.Sprintf("**云账号**: %s (%s)\n", accountName, provider))
+	b.WriteString(fmt.Sprintf("**账期**: %s\n", billingMonth))
+	b.WriteString(fmt.Sprintf("**明细合计**: %.2f %s\n", detail, currency))
+	b.WriteString(fmt.Sprintf("**账单总览**: %.2f %s\n", invoice, currency))
+	b.WriteString(fmt.Sprintf("**差异**: %.2f %s (%.2f%%)\n", drift, currency, driftPct))
+}
+
+// NotifyChannels 直接通过指定通知渠道发送消息（不经过告警规则），仅发送属于该租户且已启用的渠道
+func (s *AlertService) NotifyChannels(ctx context.Context, tenantID string, channelIDs []int64, title, content string) error {
+	channels, err := s.dao.GetChannelsByIDs(ctx, channelIDs)
+	if err != nil {
+		return fmt.Errorf("获取通知渠道失败: %w", err)
+	}
+
+	var owned []domain.NotificationChannel
+	for _, ch := range channels {
+		if tenantID == "" || ch.TenantID == tenantID {
+			owned = append(owned, ch)
+		}
+	}
+	dispatcher := channel.NewDispatcher(owned)
+	if dispatcher.Len() == 0 {
+		return fmt.Errorf("无可用通知渠道")
+	}
+
+	return dispatcher.Dispatch(ctx, &channel.Message{
+		Title:    title,
+		Content:  content,
+		Severity: domain.SeverityInfo,
+		Markdown: true,
+	})
+}
+
 // ========== 通知渠道管理 ==========
 
 func (s *AlertService) CreateChannel(ctx context.Context, ch domain.NotificationChannel) (int64, error) {
@@ -330,6 +695,16 @@ func containsInt64(slice []int64, val int64) bool {
 	return false
 }
 
+// appendUnique 追加不在切片中的元素
+func appendUnique(slice []int64, vals ...int64) []int64 {
+	for _, v := range vals {
+		if !containsInt64(slice, v) {
+			slice = append(slice, v)
+		}
+	}
+	return slice
+}
+
 func containsString(slice []string, val string) bool {
 	for _, v := range slice {
 		if v == val {
@@ -0,0 +1,792 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/condition"
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/internal/alert/repository/dao"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Mock DAO ==========
+
+type mockAlertDAO struct {
+	dao.AlertDAO
+	rules           []domain.AlertRule
+	events          []domain.AlertEvent
+	states          map[string]domain.AlertState
+	channels        []domain.NotificationChannel
+	templates       []domain.MessageTemplate
+	channelRequests [][]int64
+	stateQueries    int
+}
+
+func newMockAlertDAO(rules ...domain.AlertRule) *mockAlertDAO {
+	return &mockAlertDAO{rules: rules, states: make(map[string]domain.AlertState)}
+}
+
+func (m *mockAlertDAO) GetRuleByID(_ context.Context, id int64) (domain.AlertRule, error) {
+	for _, r := range m.rules {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return domain.AlertRule{}, errors.New("not found")
+}
+
+func (m *mockAlertDAO) ListRules(_ context.Context, filter domain.AlertRuleFilter) ([]domain.AlertRule, int64, error) {
+	var result []domain.AlertRule
+	for _, r := range m.rules {
+		if r.TenantID == filter.TenantID && r.Type == filter.Type && r.Enabled {
+			result = append(result, r)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockAlertDAO) CreateEvent(_ context.Context, event domain.AlertEvent) (int64, error) {
+	event.ID = int64(len(m.events) + 1)
+	m.events = append(m.events, event)
+	return event.ID, nil
+}
+
+func (m *mockAlertDAO) UpdateEventStatus(_ context.Context, id int64, status domain.EventStatus) error {
+	e := &m.events[id-1]
+	e.Status = status
+	e.History = append(e.History, domain.EventTransition{Status: status})
+	return nil
+}
+
+func (m *mockAlertDAO) ListEvents(_ context.Context, filter domain.AlertEventFilter) ([]domain.AlertEvent, int64, error) {
+	var result []domain.AlertEvent
+	for i := len(m.events) - 1; i >= 0 && int64(len(result)) < filter.Limit; i-- {
+		e := m.events[i]
+		if e.TenantID == filter.TenantID && (filter.Type == "" || e.Type == filter.Type) {
+			result = append(result, e)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockAlertDAO) CreateRule(_ context.Context, rule domain.AlertRule) (int64, error) {
+	rule.ID = int64(len(m.rules) + 1)
+	m.rules = append(m.rules, rule)
+	return rule.ID, nil
+}
+
+func (m *mockAlertDAO) GetEventByID(_ context.Context, id int64) (domain.AlertEvent, error) {
+	if id < 1 || int(id) > len(m.events) {
+		return domain.AlertEvent{}, errors.New("not found")
+	}
+	return m.events[id-1], nil
+}
+
+func (m *mockAlertDAO) ResolveEvents(_ context.Context, fingerprint string, t domain.EventTransition) (int64, error) {
+	var n int64
+	for i := range m.events {
+		e := &m.events[i]
+		if e.Fingerprint != fingerprint || e.Status == domain.EventStatusResolved || e.Status == domain.EventStatusFailed {
+			continue
+		}
+		e.Status = domain.EventStatusResolved
+		e.ResolvedAt = &t.Time
+		e.AckDeadline = nil
+		e.History = append(e.History, t)
+		n++
+	}
+	return n, nil
+}
+
+func (m *mockAlertDAO) AckEvent(_ context.Context, id int64, t domain.EventTransition) (bool, error) {
+	e := &m.events[id-1]
+	if e.Status == domain.EventStatusAcknowledged || e.Status == domain.EventStatusResolved {
+		return false, nil
+	}
+	e.Status = domain.EventStatusAcknowledged
+	e.AckedBy = t.Operator
+	e.AckedAt = &t.Time
+	e.AckDeadline = nil
+	e.History = append(e.History, t)
+	return true, nil
+}
+
+func (m *mockAlertDAO) AssignEvent(_ context.Context, id int64, assignee string, t domain.EventTransition) (bool, error) {
+	e := &m.events[id-1]
+	if e.Status == domain.EventStatusResolved {
+		return false, nil
+	}
+	e.Assignee = assignee
+	e.History = append(e.History, t)
+	return true, nil
+}
+
+func (m *mockAlertDAO) AppendEventHistory(_ context.Context, id int64, t domain.EventTransition) error {
+	e := &m.events[id-1]
+	e.History = append(e.History, t)
+	return nil
+}
+
+func (m *mockAlertDAO) SetAckDeadline(_ context.Context, id int64, deadline *time.Time) error {
+	m.events[id-1].AckDeadline = deadline
+	return nil
+}
+
+func (m *mockAlertDAO) RenotifyEvent(_ context.Context, id int64, deadline time.Time, t domain.EventTransition) error {
+	e := &m.events[id-1]
+	e.AckDeadline = &deadline
+	e.RenotifyCount++
+	e.History = append(e.History, t)
+	return nil
+}
+
+func (m *mockAlertDAO) GetRenotifyEvents(_ context.Context, now time.Time, _ int) ([]domain.AlertEvent, error) {
+	var result []domain.AlertEvent
+	for _, e := range m.events {
+		if e.Status == domain.EventStatusSent && e.AckDeadline != nil && !e.AckDeadline.After(now) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAlertDAO) GetPendingEvents(_ context.Context, _ int) ([]domain.AlertEvent, error) {
+	var result []domain.AlertEvent
+	for _, e := range m.events {
+		if e.Status == domain.EventStatusPending {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAlertDAO) GetState(_ context.Context, fingerprint string) (domain.AlertState, error) {
+	return m.states[fingerprint], nil
+}
+
+func (m *mockAlertDAO) RecordOccurrence(_ context.Context, state domain.AlertState, resetBefore time.Time) (domain.AlertState, error) {
+	cur, ok := m.states[state.Fingerprint]
+	if !ok || cur.Resolved || cur.LastSeen.Before(resetBefore) {
+		cur = domain.AlertState{
+			Fingerprint: state.Fingerprint,
+			RuleID:      state.RuleID,
+			Type:        state.Type,
+			Resource:    state.Resource,
+			TenantID:    state.TenantID,
+			FirstSeen:   state.LastSeen,
+		}
+	}
+	cur.Count++
+	cur.LastSeen = state.LastSeen
+	m.states[state.Fingerprint] = cur
+	return cur, nil
+}
+
+func (m *mockAlertDAO) MarkStateEscalated(_ context.Context, fingerprint string) (bool, error) {
+	st := m.states[fingerprint]
+	if st.Escalated {
+		return false, nil
+	}
+	st.Escalated = true
+	m.states[fingerprint] = st
+	return true, nil
+}
+
+func (m *mockAlertDAO) MarkStateNotified(_ context.Context, fingerprint string, now time.Time, silence time.Duration) (bool, error) {
+	st := m.states[fingerprint]
+	if silence > 0 && st.LastNotifiedAt != nil && st.LastNotifiedAt.After(now.Add(-silence)) {
+		return false, nil
+	}
+	st.LastNotifiedAt = &now
+	m.states[fingerprint] = st
+	return true, nil
+}
+
+func (m *mockAlertDAO) SetStateLastEvent(_ context.Context, fingerprint string, eventID int64) error {
+	st := m.states[fingerprint]
+	st.LastEventID = eventID
+	m.states[fingerprint] = st
+	return nil
+}
+
+func (m *mockAlertDAO) ResolveState(_ context.Context, state domain.AlertState, resolvedAt time.Time) error {
+	st, ok := m.states[state.Fingerprint]
+	if !ok {
+		st = state
+	}
+	st.Resolved = true
+	st.ResolvedAt = &resolvedAt
+	m.states[state.Fingerprint] = st
+	return nil
+}
+
+func (m *mockAlertDAO) ListActiveStates(_ context.Context) ([]domain.AlertState, error) {
+	var result []domain.AlertState
+	for _, s := range m.states {
+		if !s.Resolved {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAlertDAO) ListActiveStatesByType(_ context.Context, tenantID string, alertType domain.AlertType) ([]domain.AlertState, error) {
+	m.stateQueries++
+	var result []domain.AlertState
+	for _, s := range m.states {
+		if !s.Resolved && s.TenantID == tenantID && s.Type == alertType {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAlertDAO) GetChannelsByIDs(_ context.Context, ids []int64) ([]domain.NotificationChannel, error) {
+	m.channelRequests = append(m.channelRequests, ids)
+	var result []domain.NotificationChannel
+	for _, ch := range m.channels {
+		if containsInt64(ids, ch.ID) {
+			result = append(result, ch)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAlertDAO) CreateTemplate(_ context.Context, tmpl domain.MessageTemplate) (int64, error) {
+	tmpl.ID = int64(len(m.templates) + 1)
+	m.templates = append(m.templates, tmpl)
+	return tmpl.ID, nil
+}
+
+func (m *mockAlertDAO) GetTemplateByID(_ context.Context, id int64) (domain.MessageTemplate, error) {
+	if id < 1 || int(id) > len(m.templates) {
+		return domain.MessageTemplate{}, errors.New("not found")
+	}
+	return m.templates[id-1], nil
+}
+
+func (m *mockAlertDAO) ListTemplates(_ context.Context, filter domain.MessageTemplateFilter) ([]domain.MessageTemplate, int64, error) {
+	var result []domain.MessageTemplate
+	for _, t := range m.templates {
+		if t.TenantID == filter.TenantID && (filter.Enabled == nil || t.Enabled == *filter.Enabled) {
+			result = append(result, t)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+// ========== 测试数据 ==========
+
+func testRule() domain.AlertRule {
+	return domain.AlertRule{
+		ID: 1, Name: "expiring", Type: domain.AlertTypeExpiration, TenantID: "t1", Enabled: true,
+		ChannelIDs: []int64{10}, SilenceDuration: 30, EscalateAfter: 3, EscalateChannels: []int64{10, 20},
+		AutoResolveAfter: 60,
+	}
+}
+
+func testEvent(assetID string) domain.AlertEvent {
+	return domain.AlertEvent{
+		Type: domain.AlertTypeExpiration, Severity: domain.SeverityWarning, Title: "资源即将过期",
+		Content: map[string]any{"asset_id": assetID}, Source: "expiration:" + assetID, TenantID: "t1",
+	}
+}
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time          { return c.t }
+func (c *testClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+func newTestService(d *mockAlertDAO) (*AlertService, *testClock) {
+	clock := &testClock{t: time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)}
+	svc := NewAlertService(d, elog.DefaultLogger)
+	svc.now = clock.now
+	return svc, clock
+}
+
+func statuses(events []domain.AlertEvent) []domain.EventStatus {
+	result := make([]domain.EventStatus, 0, len(events))
+	for _, e := range events {
+		result = append(result, e.Status)
+	}
+	return result
+}
+
+// ========== 去重 / 静默 / 升级 ==========
+
+func TestEmitEvent_SilenceAndEscalate(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, clock := newTestService(d)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+		clock.advance(time.Minute)
+	}
+	// 第 1 次发送，第 2 次静默，第 3 次升级（不受静默期限制），第 4 次仍在升级通知后的静默期内
+	assert.Equal(t, []domain.EventStatus{
+		domain.EventStatusPending, domain.EventStatusSilenced, domain.EventStatusPending, domain.EventStatusSilenced,
+	}, statuses(d.events))
+	assert.False(t, d.events[0].Escalated)
+	assert.True(t, d.events[2].Escalated)
+	assert.False(t, d.events[3].Escalated, "每轮只升级一次")
+	for i, e := range d.events {
+		assert.Equal(t, i+1, e.Occurrence)
+		assert.Equal(t, d.events[0].Fingerprint, e.Fingerprint)
+		assert.Equal(t, "i-1", e.Resource)
+		require.Len(t, e.History, 1)
+		assert.Equal(t, e.Status, e.History[0].Status)
+	}
+
+	state := d.states[d.events[0].Fingerprint]
+	assert.Equal(t, 4, state.Count)
+	assert.True(t, state.Escalated)
+	assert.Equal(t, int64(4), state.LastEventID)
+
+	// 静默期过后再次通知
+	clock.advance(30 * time.Minute)
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	assert.Equal(t, domain.EventStatusPending, d.events[4].Status)
+
+	// 不同资源指纹不同，互不影响
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-2")))
+	assert.Equal(t, domain.EventStatusPending, d.events[5].Status)
+	assert.Equal(t, 1, d.events[5].Occurrence)
+	assert.NotEqual(t, d.events[0].Fingerprint, d.events[5].Fingerprint)
+}
+
+func TestEmitEvent_NoSilenceConfigured(t *testing.T) {
+	rule := testRule()
+	rule.SilenceDuration, rule.EscalateAfter = 0, 0
+	d := newMockAlertDAO(rule)
+	svc, _ := newTestService(d)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, svc.EmitEvent(context.Background(), testEvent("i-1")))
+	}
+	assert.Equal(t, []domain.EventStatus{
+		domain.EventStatusPending, domain.EventStatusPending, domain.EventStatusPending,
+	}, statuses(d.events))
+	assert.Equal(t, 3, d.events[2].Occurrence)
+}
+
+func TestProcessPendingEvents_EscalateChannels(t *testing.T) {
+	rule := testRule()
+	rule.EscalateAfter = 2
+	d := newMockAlertDAO(rule)
+	svc, clock := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	clock.advance(time.Minute)
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	assert.Equal(t, [][]int64{{10}, {10, 20}}, d.channelRequests, "升级事件额外发送到升级渠道")
+	assert.Equal(t, []domain.EventStatus{domain.EventStatusSent, domain.EventStatusSent}, statuses(d.events))
+
+	msg := svc.buildMessage(d.events[1])
+	assert.Equal(t, "[升级] 资源即将过期", msg.Title)
+	assert.Contains(t, msg.Content, "**连续发生**: 2 次")
+}
+
+// ========== 恢复 ==========
+
+func TestResolveEvent(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, clock := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-2")))
+
+	clock.advance(time.Minute)
+	require.NoError(t, svc.ResolveEvent(ctx, testEvent("i-1")))
+	assert.Equal(t, []domain.EventStatus{
+		domain.EventStatusResolved, domain.EventStatusResolved, domain.EventStatusPending,
+	}, statuses(d.events))
+	last := d.events[1].History[len(d.events[1].History)-1]
+	assert.Equal(t, resolveReasonCleared, last.Reason)
+	assert.True(t, d.states[d.events[0].Fingerprint].Resolved)
+
+	// 恢复后再次发生开始新一轮计数，不受上一轮静默期影响
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	assert.Equal(t, domain.EventStatusPending, d.events[3].Status)
+	assert.Equal(t, 1, d.events[3].Occurrence)
+}
+
+func TestResolveEvent_IgnoresRuleCondition(t *testing.T) {
+	rule := testRule()
+	rule.Condition = map[string]any{"expr": `content.days_left <= 3`}
+	d := newMockAlertDAO(rule)
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	evt := testEvent("i-1")
+	evt.Content["days_left"] = float64(1)
+	require.NoError(t, svc.EmitEvent(ctx, evt))
+	require.Len(t, d.events, 1)
+
+	// 续费后的恢复事件不再满足规则条件，仍按指纹恢复
+	require.NoError(t, svc.ResolveEvent(ctx, testEvent("i-1")))
+	assert.Equal(t, domain.EventStatusResolved, d.events[0].Status)
+}
+
+func TestResolveResources(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-2")))
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-3")))
+
+	// 大部分资源从未告警，只恢复存在活跃指纹的资源，且只查询一次
+	require.NoError(t, svc.ResolveResources(ctx, "t1", domain.AlertTypeExpiration, []string{"i-1", "i-3", "i-4", "i-5"}))
+	assert.Equal(t, []domain.EventStatus{
+		domain.EventStatusResolved, domain.EventStatusPending, domain.EventStatusResolved,
+	}, statuses(d.events))
+	assert.Equal(t, 1, d.stateQueries)
+
+	require.NoError(t, svc.ResolveResources(ctx, "t1", domain.AlertTypeExpiration, nil))
+	assert.Equal(t, 1, d.stateQueries)
+}
+
+func TestEmitEvent_ResetsCountAfterGap(t *testing.T) {
+	rule := testRule()
+	rule.AutoResolveAfter, rule.SilenceDuration = 0, 0
+	d := newMockAlertDAO(rule)
+	svc, clock := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	clock.advance(time.Hour)
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	assert.Equal(t, 2, d.events[1].Occurrence)
+
+	// 未配置自动恢复时，超过默认间隔未再发生也重新计数，不会累计到升级阈值
+	clock.advance(occurrenceResetGap + time.Minute)
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	assert.Equal(t, 1, d.events[2].Occurrence)
+	assert.False(t, d.events[2].Escalated)
+}
+
+func TestAutoResolve(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, clock := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	clock.advance(30 * time.Minute)
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-2")))
+
+	clock.advance(30 * time.Minute)
+	require.NoError(t, svc.AutoResolve(ctx))
+	// i-1 已 60 分钟未再发生，i-2 仅 30 分钟
+	assert.Equal(t, []domain.EventStatus{domain.EventStatusResolved, domain.EventStatusPending}, statuses(d.events))
+	assert.Equal(t, resolveReasonStale, d.events[0].History[1].Reason)
+}
+
+func TestResolveEventByID(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+
+	assert.Error(t, svc.ResolveEventByID(ctx, "t2", 1, "alice", ""), "不能恢复其他租户的事件")
+	require.NoError(t, svc.ResolveEventByID(ctx, "t1", 1, "alice", "已续费"))
+	assert.Equal(t, []domain.EventStatus{domain.EventStatusResolved, domain.EventStatusResolved}, statuses(d.events))
+	last := d.events[1].History[1]
+	assert.Equal(t, resolveReasonManual, last.Reason)
+	assert.Equal(t, "alice", last.Operator)
+	assert.Equal(t, "已续费", last.Comment)
+}
+
+// ========== 确认 / 指派 / 评论 / 重新通知 ==========
+
+func TestAckEvent(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	assert.ErrorIs(t, svc.AckEvent(ctx, "t2", 1, "bob", ""), ErrEventNotFound)
+	assert.ErrorIs(t, svc.AckEvent(ctx, "t1", 9, "bob", ""), ErrEventNotFound)
+
+	require.NoError(t, svc.AckEvent(ctx, "t1", 1, "bob", "处理中"))
+	e := d.events[0]
+	assert.Equal(t, domain.EventStatusAcknowledged, e.Status)
+	assert.Equal(t, "bob", e.AckedBy)
+	assert.Equal(t, "bob", e.Assignee, "未指派时指派给确认人")
+
+	// 重复确认不产生新的时间线记录
+	n := len(e.History)
+	require.NoError(t, svc.AckEvent(ctx, "t1", 1, "carol", ""))
+	assert.Len(t, d.events[0].History, n)
+
+	// 已确认的事件仍可恢复，恢复后不能再确认
+	require.NoError(t, svc.ResolveEventByID(ctx, "t1", 1, "bob", ""))
+	assert.Equal(t, domain.EventStatusResolved, d.events[0].Status)
+	assert.ErrorIs(t, svc.AckEvent(ctx, "t1", 1, "bob", ""), ErrInvalidEventOperation)
+}
+
+func TestAssignAndCommentEvent(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+
+	assert.ErrorIs(t, svc.AssignEvent(ctx, "t1", 1, "", "alice", ""), ErrInvalidEventOperation)
+	require.NoError(t, svc.AssignEvent(ctx, "t1", 1, "bob", "alice", "交给存储组"))
+	assert.ErrorIs(t, svc.CommentEvent(ctx, "t1", 1, "bob", ""), ErrInvalidEventOperation)
+	require.NoError(t, svc.CommentEvent(ctx, "t1", 1, "bob", "已联系业务方续费"))
+
+	e, err := svc.GetEvent(ctx, "t1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", e.Assignee)
+	assert.Equal(t, domain.EventStatusPending, e.Status, "指派与评论不改变事件状态")
+
+	// 时间线：首次发生 → 指派 → 评论
+	require.Len(t, e.History, 3)
+	assert.Equal(t, domain.EventAction(""), e.History[0].Action)
+	assert.Equal(t, domain.EventActionAssign, e.History[1].Action)
+	assert.Equal(t, "alice", e.History[1].Operator)
+	assert.Equal(t, "交给存储组", e.History[1].Comment)
+	assert.Equal(t, domain.EventActionComment, e.History[2].Action)
+	assert.Equal(t, "已联系业务方续费", e.History[2].Comment)
+}
+
+// racingAlertDAO 模拟读取事件后、更新前事件被并发恢复
+type racingAlertDAO struct {
+	*mockAlertDAO
+}
+
+func (r racingAlertDAO) AckEvent(ctx context.Context, id int64, t domain.EventTransition) (bool, error) {
+	r.events[id-1].Status = domain.EventStatusResolved
+	return r.mockAlertDAO.AckEvent(ctx, id, t)
+}
+
+func (r racingAlertDAO) AssignEvent(ctx context.Context, id int64, assignee string, t domain.EventTransition) (bool, error) {
+	r.events[id-1].Status = domain.EventStatusResolved
+	return r.mockAlertDAO.AssignEvent(ctx, id, assignee, t)
+}
+
+func TestAckAndAssignEvent_ConcurrentResolve(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc := NewAlertService(racingAlertDAO{d}, elog.DefaultLogger)
+	ctx := context.Background()
+	_, err := d.CreateEvent(ctx, domain.AlertEvent{Status: domain.EventStatusSent, TenantID: "t1"})
+	require.NoError(t, err)
+	_, err = d.CreateEvent(ctx, domain.AlertEvent{Status: domain.EventStatusSent, TenantID: "t1"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, svc.AckEvent(ctx, "t1", 1, "bob", ""), ErrInvalidEventOperation)
+	assert.ErrorIs(t, svc.AssignEvent(ctx, "t1", 2, "bob", "alice", ""), ErrInvalidEventOperation)
+	assert.Empty(t, d.events[0].AckedBy)
+	assert.Empty(t, d.events[1].Assignee)
+}
+
+func TestIncidentChannel_AckAndResolve(t *testing.T) {
+	type incidentBody struct {
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key"`
+	}
+	var incidents []incidentBody
+	incidentSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body incidentBody
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		incidents = append(incidents, body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer incidentSrv.Close()
+	var webhookHits int
+	webhookSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		webhookHits++
+	}))
+	defer webhookSrv.Close()
+
+	rule := testRule()
+	rule.ChannelIDs = []int64{10, 30}
+	d := newMockAlertDAO(rule)
+	d.channels = []domain.NotificationChannel{
+		{ID: 10, Type: domain.ChannelIncident, Enabled: true, TenantID: "t1",
+			Config: map[string]any{"url": incidentSrv.URL, "routing_key": "rk", "max_retries": float64(0)}},
+		{ID: 30, Type: domain.ChannelWebhook, Enabled: true, TenantID: "t1",
+			Config: map[string]any{"url": webhookSrv.URL, "max_retries": float64(0)}},
+	}
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	require.NoError(t, svc.AckEvent(ctx, "t1", 1, "bob", ""))
+	require.NoError(t, svc.ResolveEvent(ctx, testEvent("i-1")))
+
+	// 确认与恢复只推送到事件平台渠道，并以同一去重键确认、关闭 incident
+	fingerprint := d.events[0].Fingerprint
+	assert.Equal(t, []incidentBody{
+		{EventAction: "trigger", DedupKey: fingerprint},
+		{EventAction: "acknowledge", DedupKey: fingerprint},
+		{EventAction: "resolve", DedupKey: fingerprint},
+	}, incidents)
+	assert.Equal(t, 1, webhookHits)
+
+	// 已恢复的告警再次恢复不重复推送
+	require.NoError(t, svc.ResolveEvent(ctx, testEvent("i-1")))
+	assert.Len(t, incidents, 3)
+}
+
+func TestRenotifyUnacknowledged(t *testing.T) {
+	rule := testRule()
+	rule.AckTimeout = 15
+	d := newMockAlertDAO(rule)
+	svc, clock := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	require.NotNil(t, d.events[0].AckDeadline)
+	assert.Equal(t, clock.now().Add(15*time.Minute), *d.events[0].AckDeadline)
+
+	// 未到确认期限不重新通知
+	clock.advance(10 * time.Minute)
+	require.NoError(t, svc.RenotifyUnacknowledged(ctx))
+	assert.Equal(t, 0, d.events[0].RenotifyCount)
+
+	clock.advance(5 * time.Minute)
+	require.NoError(t, svc.RenotifyUnacknowledged(ctx))
+	e := d.events[0]
+	assert.Equal(t, 1, e.RenotifyCount)
+	assert.Equal(t, clock.now().Add(15*time.Minute), *e.AckDeadline, "顺延确认期限")
+	last := e.History[len(e.History)-1]
+	assert.Equal(t, domain.EventActionRenotify, last.Action)
+	assert.Contains(t, last.Comment, "第 1 次重新通知")
+
+	// 确认后不再重新通知
+	require.NoError(t, svc.AckEvent(ctx, "t1", 1, "bob", ""))
+	clock.advance(time.Hour)
+	require.NoError(t, svc.RenotifyUnacknowledged(ctx))
+	assert.Equal(t, 1, d.events[0].RenotifyCount)
+	assert.Nil(t, d.events[0].AckDeadline)
+}
+
+func TestRenotifyUnacknowledged_TimeoutDisabled(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, clock := newTestService(d)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	assert.Nil(t, d.events[0].AckDeadline, "规则未配置确认超时")
+
+	// 规则关闭确认超时后，遗留的确认期限被清除
+	deadline := clock.now()
+	d.events[0].AckDeadline = &deadline
+	require.NoError(t, svc.RenotifyUnacknowledged(ctx))
+	assert.Nil(t, d.events[0].AckDeadline)
+	assert.Equal(t, 0, d.events[0].RenotifyCount)
+}
+
+// ========== 条件表达式 ==========
+
+func TestEmitEvent_Condition(t *testing.T) {
+	rule := testRule()
+	rule.Condition = map[string]any{"expr": `severity >= warning && tags.env == "prod" && resource matches "^i-"`}
+	d := newMockAlertDAO(rule)
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	prod := testEvent("i-1")
+	prod.Content["tags"] = map[string]any{"env": "prod"}
+	staging := testEvent("i-2")
+	staging.Content["tags"] = map[string]any{"env": "staging"}
+	info := testEvent("i-3")
+	info.Severity = domain.SeverityInfo
+	info.Content["tags"] = map[string]any{"env": "prod"}
+
+	for _, e := range []domain.AlertEvent{prod, staging, info} {
+		require.NoError(t, svc.EmitEvent(ctx, e))
+	}
+	require.Len(t, d.events, 1)
+	assert.Equal(t, "i-1", d.events[0].Resource)
+}
+
+func TestCreateRule_InvalidCondition(t *testing.T) {
+	svc, _ := newTestService(newMockAlertDAO())
+	ctx := context.Background()
+
+	rule := testRule()
+	rule.ID = 0
+	rule.Condition = map[string]any{"expr": `owner == "me"`}
+	_, err := svc.CreateRule(ctx, rule)
+	assert.ErrorIs(t, err, condition.ErrInvalidCondition)
+
+	rule.Condition = map[string]any{"expr": `severity == critical`}
+	id, err := svc.CreateRule(ctx, rule)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
+
+func TestDryRunRule(t *testing.T) {
+	d := newMockAlertDAO()
+	for i, sev := range []domain.Severity{domain.SeverityInfo, domain.SeverityCritical, domain.SeverityWarning} {
+		e := testEvent(fmt.Sprintf("i-%d", i))
+		e.Severity = sev
+		e.Content["days_left"] = float64(i)
+		_, _ = d.CreateEvent(context.Background(), e)
+	}
+	other := testEvent("i-9")
+	other.TenantID = "t2"
+	_, _ = d.CreateEvent(context.Background(), other)
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	draft := domain.AlertRule{
+		Type: domain.AlertTypeExpiration, TenantID: "t1",
+		Condition: map[string]any{"expr": `severity >= warning && content.days_left <= 1`},
+	}
+	result, err := svc.DryRunRule(ctx, draft, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 1, result.Matched)
+	// 按时间倒序回放
+	assert.Equal(t, []int64{3, 2, 1}, []int64{result.Items[0].EventID, result.Items[1].EventID, result.Items[2].EventID})
+	assert.True(t, result.Items[1].Matched)
+
+	result, err = svc.DryRunRule(ctx, draft, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+
+	draft.Condition = map[string]any{"expr": `content.days_left > "x"`}
+	result, err = svc.DryRunRule(ctx, draft, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Matched)
+	assert.NotEmpty(t, result.Items[0].Error, "求值错误逐条返回")
+
+	draft.Condition = map[string]any{"expr": `severity >=`}
+	_, err = svc.DryRunRule(ctx, draft, 0)
+	assert.ErrorIs(t, err, condition.ErrInvalidCondition)
+}
+
+func TestEventResource(t *testing.T) {
+	assert.Equal(t, "explicit", eventResource(domain.AlertEvent{Resource: "explicit", Content: map[string]any{"asset_id": "i-1"}}))
+	assert.Equal(t, "sg-1", eventResource(domain.AlertEvent{Content: map[string]any{"security_group_id": "sg-1"}}))
+	assert.Equal(t, "service=ecs", eventResource(domain.AlertEvent{
+		Content: map[string]any{"dimension": "service", "dimension_value": "ecs"}, Source: "anomaly:service:ecs:2026-10-01",
+	}))
+	assert.Equal(t, "budget:1", eventResource(domain.AlertEvent{Source: "budget:1"}))
+}
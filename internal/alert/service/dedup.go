@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// 恢复原因
+const (
+	resolveReasonCleared = "条件已恢复"
+	resolveReasonStale   = "持续未触发，自动恢复"
+	resolveReasonManual  = "手动恢复"
+)
+
+// occurrenceResetGap 规则未配置自动恢复时，超过该间隔未再发生的告警重新开始计数
+const occurrenceResetGap = 24 * time.Hour
+
+// Fingerprint 告警指纹：同一规则、同一资源、同一类型的事件视为同一告警
+func Fingerprint(ruleID int64, alertType domain.AlertType, resource string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s", ruleID, alertType, resource)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// eventResource 事件关联的资源标识，未显式设置时依次取 资源ID / 安全组ID / 异常维度，最后退化为事件来源
+func eventResource(event domain.AlertEvent) string {
+	if event.Resource != "" {
+		return event.Resource
+	}
+	for _, key := range []string{"asset_id", "resource_id", "security_group_id"} {
+		if v, _ := event.Content[key].(string); v != "" {
+			return v
+		}
+	}
+	if dim, _ := event.Content["dimension"].(string); dim != "" {
+		value, _ := event.Content["dimension_value"].(string)
+		return dim + "=" + value
+	}
+	return event.Source
+}
+
+// trackOccurrence 按指纹原子累计发生次数，并据规则的静默期与升级阈值决定事件初始状态
+// 返回更新后的指纹状态；状态更新失败时按普通事件发送，不做去重
+func (s *AlertService) trackOccurrence(ctx context.Context, rule domain.AlertRule, evt *domain.AlertEvent) (domain.AlertState, bool) {
+	now := s.now()
+	evt.Resource = eventResource(*evt)
+	evt.Fingerprint = Fingerprint(rule.ID, evt.Type, evt.Resource)
+	evt.Status = domain.EventStatusPending
+
+	// 超过间隔未再发生视为新一轮：配置了自动恢复时按自动恢复时长，否则按默认间隔
+	gap := occurrenceResetGap
+	if rule.AutoResolveAfter > 0 {
+		gap = time.Duration(rule.AutoResolveAfter) * time.Minute
+	}
+	state, err := s.dao.RecordOccurrence(ctx, domain.AlertState{
+		Fingerprint: evt.Fingerprint,
+		RuleID:      rule.ID,
+		Type:        evt.Type,
+		Resource:    evt.Resource,
+		TenantID:    evt.TenantID,
+		LastSeen:    now,
+	}, now.Add(-gap))
+	if err != nil {
+		s.logger.Warn("更新告警指纹状态失败，按新告警处理",
+			elog.String("fingerprint", evt.Fingerprint),
+			elog.FieldErr(err))
+		evt.Occurrence = 1
+		evt.History = []domain.EventTransition{{Status: evt.Status, Reason: "首次发生", Time: now}}
+		return domain.AlertState{}, false
+	}
+	evt.Occurrence = state.Count
+
+	var reason string
+	switch {
+	case rule.EscalateAfter > 0 && state.Count >= rule.EscalateAfter && !state.Escalated && s.markEscalated(ctx, evt.Fingerprint):
+		// 升级通知不受静默期限制
+		evt.Escalated = true
+		state.Escalated = true
+		s.markNotified(ctx, evt.Fingerprint, now, 0)
+		reason = fmt.Sprintf("连续发生 %d 次，升级通知", state.Count)
+	case !s.markNotified(ctx, evt.Fingerprint, now, time.Duration(rule.SilenceDuration)*time.Minute):
+		evt.Status = domain.EventStatusSilenced
+		reason = fmt.Sprintf("静默期内重复发生（第 %d 次）", state.Count)
+	case state.Count == 1:
+		reason = "首次发生"
+	default:
+		reason = fmt.Sprintf("再次发生（第 %d 次）", state.Count)
+	}
+	evt.History = []domain.EventTransition{{Status: evt.Status, Reason: reason, Time: now}}
+	return state, true
+}
+
+// markEscalated 抢占本轮升级，并发下只有一个事件升级；写入失败时不升级
+func (s *AlertService) markEscalated(ctx context.Context, fingerprint string) bool {
+	ok, err := s.dao.MarkStateEscalated(ctx, fingerprint)
+	if err != nil {
+		s.logger.Warn("标记告警升级失败",
+			elog.String("fingerprint", fingerprint),
+			elog.FieldErr(err))
+		return false
+	}
+	return ok
+}
+
+// markNotified 静默期外记录本次通知时间并返回 true，静默期内返回 false；写入失败时照常通知
+func (s *AlertService) markNotified(ctx context.Context, fingerprint string, now time.Time, silence time.Duration) bool {
+	ok, err := s.dao.MarkStateNotified(ctx, fingerprint, now, silence)
+	if err != nil {
+		s.logger.Warn("记录告警通知时间失败",
+			elog.String("fingerprint", fingerprint),
+			elog.FieldErr(err))
+		return true
+	}
+	return ok
+}
+
+// ResolveEvent 告警条件恢复时调用：按事件类型的规则与资源指纹，将对应告警置为已恢复
+// 恢复事件通常不再满足规则条件，因此只按指纹查找，不做条件匹配
+func (s *AlertService) ResolveEvent(ctx context.Context, event domain.AlertEvent) error {
+	enabled := true
+	rules, _, err := s.dao.ListRules(ctx, domain.AlertRuleFilter{
+		TenantID: event.TenantID,
+		Type:     event.Type,
+		Enabled:  &enabled,
+	})
+	if err != nil {
+		return fmt.Errorf("查询告警规则失败: %w", err)
+	}
+
+	resource := eventResource(event)
+	for _, rule := range rules {
+		state, err := s.dao.GetState(ctx, Fingerprint(rule.ID, event.Type, resource))
+		if err != nil {
+			return fmt.Errorf("读取告警指纹状态失败: %w", err)
+		}
+		if state.Fingerprint == "" || state.Resolved {
+			continue
+		}
+		if err := s.resolveState(ctx, state, domain.EventTransition{Reason: resolveReasonCleared}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveResources 批量恢复租户下指定类型、指定资源的未恢复告警
+// 一次查出该类型的活跃指纹后按资源匹配，适用于同步时对大量资源逐一判定恢复的场景
+func (s *AlertService) ResolveResources(ctx context.Context, tenantID string, alertType domain.AlertType, resources []string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+	states, err := s.dao.ListActiveStatesByType(ctx, tenantID, alertType)
+	if err != nil {
+		return fmt.Errorf("获取活跃告警失败: %w", err)
+	}
+
+	targets := make(map[string]struct{}, len(resources))
+	for _, r := range resources {
+		targets[r] = struct{}{}
+	}
+	for _, state := range states {
+		if _, ok := targets[state.Resource]; !ok {
+			continue
+		}
+		if err := s.resolveState(ctx, state, domain.EventTransition{Reason: resolveReasonCleared}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveEventByID 手动恢复告警事件，同一指纹下未恢复的事件一并恢复
+func (s *AlertService) ResolveEventByID(ctx context.Context, tenantID string, id int64, operator, comment string) error {
+	event, err := s.getTenantEvent(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if event.Status == domain.EventStatusResolved {
+		return nil
+	}
+
+	// 去重前创建的历史事件没有指纹，仅恢复自身
+	if event.Fingerprint == "" {
+		if err := s.dao.UpdateEventStatus(ctx, id, domain.EventStatusResolved); err != nil {
+			return err
+		}
+		event.Status = domain.EventStatusResolved
+		s.notifyIncidentStatus(ctx, event)
+		return nil
+	}
+	state, err := s.dao.GetState(ctx, event.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("读取告警指纹状态失败: %w", err)
+	}
+	if state.Fingerprint == "" {
+		state = domain.AlertState{
+			Fingerprint: event.Fingerprint,
+			RuleID:      event.RuleID,
+			Type:        event.Type,
+			Resource:    event.Resource,
+			TenantID:    event.TenantID,
+		}
+	}
+	if state.LastEventID == 0 {
+		state.LastEventID = event.ID
+	}
+	return s.resolveState(ctx, state, domain.EventTransition{Reason: resolveReasonManual, Operator: operator, Comment: comment})
+}
+
+// AutoResolve 自动恢复：规则配置了 AutoResolveAfter 且超过该时长未再发生的告警视为条件已消除
+func (s *AlertService) AutoResolve(ctx context.Context) error {
+	states, err := s.dao.ListActiveStates(ctx)
+	if err != nil {
+		return fmt.Errorf("获取活跃告警失败: %w", err)
+	}
+
+	now := s.now()
+	rules := make(map[int64]*domain.AlertRule)
+	for _, state := range states {
+		rule, ok := rules[state.RuleID]
+		if !ok {
+			r, err := s.dao.GetRuleByID(ctx, state.RuleID)
+			if err != nil {
+				s.logger.Warn("获取告警规则失败，跳过自动恢复",
+					elog.Int64("rule_id", state.RuleID),
+					elog.FieldErr(err))
+				continue
+			}
+			rule = &r
+			rules[state.RuleID] = rule
+		}
+		if rule.AutoResolveAfter <= 0 ||
+			now.Sub(state.LastSeen) < time.Duration(rule.AutoResolveAfter)*time.Minute {
+			continue
+		}
+		if err := s.resolveState(ctx, state, domain.EventTransition{Reason: resolveReasonStale}); err != nil {
+			s.logger.Error("自动恢复告警失败",
+				elog.String("fingerprint", state.Fingerprint),
+				elog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+// resolveState 将指纹状态置为已恢复并结束本轮计数，未恢复的事件一并恢复（待发送的事件不再发送），
+// 并按最近一次事件关闭事件平台上的 incident
+func (s *AlertService) resolveState(ctx context.Context, state domain.AlertState, t domain.EventTransition) error {
+	now := s.now()
+	if err := s.dao.ResolveState(ctx, state, now); err != nil {
+		return fmt.Errorf("保存告警指纹状态失败: %w", err)
+	}
+
+	t.Status = domain.EventStatusResolved
+	t.Time = now
+	n, err := s.dao.ResolveEvents(ctx, state.Fingerprint, t)
+	if err != nil {
+		return fmt.Errorf("恢复告警事件失败: %w", err)
+	}
+	s.logger.Info("告警已恢复",
+		elog.String("fingerprint", state.Fingerprint),
+		elog.String("resource", state.Resource),
+		elog.String("reason", t.Reason),
+		elog.Int64("events", n))
+
+	if n == 0 || state.LastEventID == 0 {
+		return nil
+	}
+	event, err := s.dao.GetEventByID(ctx, state.LastEventID)
+	if err != nil {
+		s.logger.Warn("获取最近告警事件失败，跳过事件平台恢复通知",
+			elog.String("fingerprint", state.Fingerprint),
+			elog.FieldErr(err))
+		return nil
+	}
+	event.Status = domain.EventStatusResolved
+	s.notifyIncidentStatus(ctx, event)
+	return nil
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/channel"
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/internal/alert/repository/dao"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// 投递重试策略：失败后按 30s、1m、2m、4m 退避重试，累计 5 次仍失败进入死信列表
+const (
+	maxDeliveryAttempts = 5
+	deliveryBackoffBase = 30 * time.Second
+	deliveryBackoffMax  = 30 * time.Minute
+	dueDeliveryBatch    = 100
+	maxRedriveBatch     = 500
+	// deliveryBatchTimeout 单批重试的总时长，剩余时间不足一次尝试时停止，余下的投递留待下一批
+	deliveryBatchTimeout = 2 * time.Minute
+	// deliveryAttemptTimeout 单次投递尝试的发送时长上限
+	deliveryAttemptTimeout = 15 * time.Second
+)
+
+var (
+	// ErrDeliveryNotFound 投递记录不存在或不属于当前租户
+	ErrDeliveryNotFound = errors.New("投递记录不存在")
+	// ErrInvalidDeliveryOperation 投递记录当前状态不允许该操作
+	ErrInvalidDeliveryOperation = errors.New("投递记录操作无效")
+)
+
+// SetDeliveryDAO 设置投递记录存储（可选注入）
+// 注入后告警按渠道独立投递：每个渠道单独记录状态、限流并按指数退避重试，永久失败进入死信列表等待人工重投；
+// 未注入时同步分发到全部渠道
+func (s *AlertService) SetDeliveryDAO(d dao.DeliveryDAO) {
+	s.deliveries = d
+	s.limiter = channel.NewRateLimiter()
+}
+
+// deliverEvent 为事件的每个通知渠道创建本轮投递并立即尝试发送，发送失败的渠道由 ProcessDeliveries 重试
+// 已创建过的 (渠道, 轮次) 跳过，因此事件被重新处理时不会重复发送已送达的渠道；
+// 调用方剩余时间不足一次尝试时只创建投递记录，保持待投递状态由 ProcessDeliveries 发送
+func (s *AlertService) deliverEvent(ctx context.Context, rule domain.AlertRule, event domain.AlertEvent, round int) error {
+	shift := s.currentOnCall(ctx, rule, event)
+	channels, err := s.eventChannels(ctx, rule, event, shift)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if len(channels) == 0 {
+		s.logger.Warn("规则无可用通知渠道", elog.Int64("rule_id", rule.ID))
+	} else {
+		existing, err := s.deliveries.ListEventDeliveries(ctx, event.ID)
+		if err != nil {
+			return fmt.Errorf("获取投递记录失败: %w", err)
+		}
+		n := s.newNotification(ctx, event, shift)
+		deferred := 0
+		for _, ch := range channels {
+			if hasDelivery(existing, ch.ID, round) {
+				continue
+			}
+			delivery := domain.AlertDelivery{
+				EventID:       event.ID,
+				ChannelID:     ch.ID,
+				ChannelType:   ch.Type,
+				ChannelName:   ch.Name,
+				Round:         round,
+				TenantID:      event.TenantID,
+				Status:        domain.DeliveryPending,
+				NextAttemptAt: s.now(),
+			}
+			if delivery.ID, err = s.deliveries.CreateDelivery(ctx, delivery); err != nil {
+				errs = append(errs, fmt.Errorf("创建渠道 %d 投递记录失败: %w", ch.ID, err))
+				continue
+			}
+			if !hasAttemptBudget(ctx) {
+				deferred++
+				continue
+			}
+			s.attemptDelivery(ctx, &delivery, ch, s.renderMessage(n.templates, ch.Type, n.data, n.base))
+		}
+		if deferred > 0 {
+			s.logger.Warn("剩余时间不足，投递留待重试批次发送",
+				elog.Int64("event_id", event.ID),
+				elog.Int("deferred", deferred))
+		}
+	}
+
+	s.assignOnCall(ctx, event, shift)
+	return errors.Join(errs...)
+}
+
+// ProcessDeliveries 重试到期的投递；告警已恢复的投递取消，渠道已删除或禁用的投递转入死信
+// 每批使用独立的超时，不受调用方其他处理步骤耗时的影响
+func (s *AlertService) ProcessDeliveries(ctx context.Context) error {
+	if s.deliveries == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, deliveryBatchTimeout)
+	defer cancel()
+
+	due, err := s.deliveries.GetDueDeliveries(ctx, s.now(), dueDeliveryBatch)
+	if err != nil {
+		return fmt.Errorf("获取待投递记录失败: %w", err)
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	var channelIDs []int64
+	for _, d := range due {
+		channelIDs = appendUnique(channelIDs, d.ChannelID)
+	}
+	channels, err := s.dao.GetChannelsByIDs(ctx, channelIDs)
+	if err != nil {
+		return fmt.Errorf("获取通知渠道失败: %w", err)
+	}
+	channelMap := make(map[int64]domain.NotificationChannel, len(channels))
+	for _, ch := range channels {
+		channelMap[ch.ID] = ch
+	}
+
+	type eventContext struct {
+		event domain.AlertEvent
+		rule  domain.AlertRule
+		n     *notification
+	}
+	events := make(map[int64]*eventContext)
+	var order []int64
+	for i := range due {
+		if !hasAttemptBudget(ctx) {
+			s.logger.Warn("本批投递时间已用尽，剩余投递留待下一批",
+				elog.Int("remaining", len(due)-i))
+			break
+		}
+		d := &due[i]
+		ec, ok := events[d.EventID]
+		if !ok {
+			event, err := s.dao.GetEventByID(ctx, d.EventID)
+			if err != nil {
+				s.logger.Warn("获取投递关联的告警事件失败",
+					elog.Int64("delivery_id", d.ID),
+					elog.Int64("event_id", d.EventID),
+					elog.FieldErr(err))
+				continue
+			}
+			// 规则已删除时仍可投递，只是不再按值班表路由与设置确认期限
+			rule, err := s.dao.GetRuleByID(ctx, event.RuleID)
+			if err != nil {
+				rule = domain.AlertRule{ID: event.RuleID}
+			}
+			ec = &eventContext{event: event, rule: rule}
+			events[d.EventID] = ec
+			order = append(order, d.EventID)
+		}
+
+		if ec.event.Status == domain.EventStatusResolved {
+			d.Status = domain.DeliveryCanceled
+			d.LastError = "告警已恢复，取消投递"
+			s.saveDelivery(ctx, *d)
+			continue
+		}
+		ch, ok := channelMap[d.ChannelID]
+		if !ok {
+			now := s.now()
+			d.Status = domain.DeliveryDead
+			d.DeadAt = &now
+			d.LastError = "通知渠道不存在或已禁用"
+			s.saveDelivery(ctx, *d)
+			continue
+		}
+		if ec.n == nil {
+			ec.n = s.newNotification(ctx, ec.event, s.currentOnCall(ctx, ec.rule, ec.event))
+		}
+		s.attemptDelivery(ctx, d, ch, s.renderMessage(ec.n.templates, ch.Type, ec.n.data, ec.n.base))
+	}
+
+	for _, id := range order {
+		s.syncEventStatus(ctx, events[id].rule, events[id].event)
+	}
+	return nil
+}
+
+// attemptDelivery 尝试发送一次投递并记录结果
+// 超出渠道限流时推迟到可用时间，不计入尝试次数；不可重试的错误或重试次数用尽时转入死信
+func (s *AlertService) attemptDelivery(ctx context.Context, d *domain.AlertDelivery, ch domain.NotificationChannel, msg *channel.Message) {
+	now := s.now()
+	if wait := s.limiter.Reserve(channel.RateLimitKey(ch), channel.RateLimit(ch), now); wait > 0 {
+		d.Throttled++
+		d.NextAttemptAt = now.Add(wait)
+		s.saveDelivery(ctx, *d)
+		return
+	}
+
+	// 重试由投递记录负责，发送器不再内置重试；每次尝试单独限时，卡住的渠道不会拖住整批
+	sender, err := channel.NewTrackedSender(ch)
+	if err == nil {
+		sendCtx, cancel := context.WithTimeout(ctx, deliveryAttemptTimeout)
+		err = sender.Send(sendCtx, msg)
+		cancel()
+	}
+	d.Attempts++
+	d.LastAttemptAt = &now
+	switch {
+	case err == nil:
+		d.Status = domain.DeliverySent
+		d.SentAt = &now
+		d.LastError = ""
+	case !channel.Retryable(err) || d.Attempts >= maxDeliveryAttempts:
+		d.Status = domain.DeliveryDead
+		d.DeadAt = &now
+		d.LastError = err.Error()
+		s.logger.Error("告警投递失败，已转入死信",
+			elog.Int64("delivery_id", d.ID),
+			elog.Int64("event_id", d.EventID),
+			elog.Int64("channel_id", d.ChannelID),
+			elog.Int("attempts", d.Attempts),
+			elog.FieldErr(err))
+	default:
+		d.Status = domain.DeliveryRetrying
+		d.NextAttemptAt = now.Add(deliveryBackoff(d.Attempts))
+		d.LastError = err.Error()
+		s.logger.Warn("告警投递失败，等待重试",
+			elog.Int64("delivery_id", d.ID),
+			elog.Int64("event_id", d.EventID),
+			elog.Int64("channel_id", d.ChannelID),
+			elog.Int("attempts", d.Attempts),
+			elog.FieldErr(err))
+	}
+	s.saveDelivery(ctx, *d)
+}
+
+func (s *AlertService) saveDelivery(ctx context.Context, d domain.AlertDelivery) {
+	if err := s.deliveries.UpdateDelivery(ctx, d); err != nil {
+		s.logger.Error("更新投递记录失败",
+			elog.Int64("delivery_id", d.ID),
+			elog.FieldErr(err))
+	}
+}
+
+// syncEventStatus 按首次通知的投递结果更新事件状态：
+// 任一渠道送达即为已发送，全部进入死信为失败，否则为发送中；已确认、已恢复等状态不受影响
+func (s *AlertService) syncEventStatus(ctx context.Context, rule domain.AlertRule, event domain.AlertEvent) {
+	if event.Status != domain.EventStatusPending && event.Status != domain.EventStatusSending {
+		return
+	}
+	deliveries, err := s.deliveries.ListEventDeliveries(ctx, event.ID)
+	if err != nil {
+		s.logger.Warn("获取投递记录失败",
+			elog.Int64("event_id", event.ID),
+			elog.FieldErr(err))
+		return
+	}
+
+	var total, sent, dead int
+	for _, d := range deliveries {
+		if d.Round != 0 {
+			continue
+		}
+		total++
+		switch d.Status {
+		case domain.DeliverySent:
+			sent++
+		case domain.DeliveryDead:
+			dead++
+		}
+	}
+	switch {
+	case total == 0 || sent > 0:
+		// 无可用渠道时与同步分发保持一致，视为已发送
+		s.markSent(ctx, rule, event.ID)
+	case dead == total:
+		s.dao.UpdateEventStatus(ctx, event.ID, domain.EventStatusFailed)
+	case event.Status == domain.EventStatusPending:
+		s.dao.UpdateEventStatus(ctx, event.ID, domain.EventStatusSending)
+	}
+}
+
+// hasAttemptBudget 剩余时间是否足够完成一次投递尝试（含记录结果）
+func hasAttemptBudget(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > deliveryAttemptTimeout
+}
+
+// deliveryBackoff 第 attempts 次失败后的重试间隔
+func deliveryBackoff(attempts int) time.Duration {
+	backoff := deliveryBackoffBase << (attempts - 1)
+	if backoff <= 0 || backoff > deliveryBackoffMax {
+		return deliveryBackoffMax
+	}
+	return backoff
+}
+
+func hasDelivery(deliveries []domain.AlertDelivery, channelID int64, round int) bool {
+	for _, d := range deliveries {
+		if d.ChannelID == channelID && d.Round == round {
+			return true
+		}
+	}
+	return false
+}
+
+// ========== 投递记录与死信 ==========
+
+// ListDeliveries 查询投递记录，Status 为 dead 即死信列表
+func (s *AlertService) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]domain.AlertDelivery, int64, error) {
+	if err := s.requireDeliveries(); err != nil {
+		return nil, 0, err
+	}
+	return s.deliveries.ListDeliveries(ctx, filter)
+}
+
+// RedriveDelivery 重投死信：重置尝试次数后重新进入投递队列，由 ProcessDeliveries 发送
+func (s *AlertService) RedriveDelivery(ctx context.Context, tenantID string, id int64) (domain.AlertDelivery, error) {
+	if err := s.requireDeliveries(); err != nil {
+		return domain.AlertDelivery{}, err
+	}
+	d, err := s.deliveries.GetDeliveryByID(ctx, id)
+	if err != nil {
+		return domain.AlertDelivery{}, fmt.Errorf("%w: %v", ErrDeliveryNotFound, err)
+	}
+	if tenantID != "" && d.TenantID != tenantID {
+		return domain.AlertDelivery{}, ErrDeliveryNotFound
+	}
+	if d.Status != domain.DeliveryDead {
+		return domain.AlertDelivery{}, fmt.Errorf("%w: 只能重投死信，当前状态为 %s", ErrInvalidDeliveryOperation, d.Status)
+	}
+
+	d.Status = domain.DeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = s.now()
+	d.DeadAt = nil
+	if err := s.deliveries.UpdateDelivery(ctx, d); err != nil {
+		return domain.AlertDelivery{}, fmt.Errorf("更新投递记录失败: %w", err)
+	}
+
+	// 首次通知全部失败的事件恢复为发送中，重投成功后即标记为已发送
+	if d.Round == 0 {
+		if event, err := s.dao.GetEventByID(ctx, d.EventID); err == nil && event.Status == domain.EventStatusFailed {
+			s.dao.UpdateEventStatus(ctx, event.ID, domain.EventStatusSending)
+		}
+	}
+	return d, nil
+}
+
+// RedriveDeliveries 批量重投死信：ids 指定的投递，以及 channelID 大于 0 时该渠道的全部死信（修复渠道配置后使用）
+// 非死信或不属于该租户的投递跳过，返回实际重投数量
+func (s *AlertService) RedriveDeliveries(ctx context.Context, tenantID string, ids []int64, channelID int64) (int, error) {
+	if err := s.requireDeliveries(); err != nil {
+		return 0, err
+	}
+	if channelID > 0 {
+		dead, _, err := s.deliveries.ListDeliveries(ctx, domain.DeliveryFilter{
+			TenantID:  tenantID,
+			ChannelID: channelID,
+			Status:    domain.DeliveryDead,
+			Limit:     maxRedriveBatch,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("获取死信失败: %w", err)
+		}
+		for _, d := range dead {
+			ids = appendUnique(ids, d.ID)
+		}
+	}
+
+	var redriven int
+	for _, id := range ids {
+		if _, err := s.RedriveDelivery(ctx, tenantID, id); err != nil {
+			if errors.Is(err, ErrDeliveryNotFound) || errors.Is(err, ErrInvalidDeliveryOperation) {
+				continue
+			}
+			return redriven, err
+		}
+		redriven++
+	}
+	return redriven, nil
+}
+
+// DeliveryStats 统计 since 之后创建的投递：按渠道汇总送达、重试中、死信数量，尝试与限流次数，成功率与平均送达耗时
+func (s *AlertService) DeliveryStats(ctx context.Context, tenantID string, since time.Time) (domain.DeliveryStats, error) {
+	if err := s.requireDeliveries(); err != nil {
+		return domain.DeliveryStats{}, err
+	}
+	channels, err := s.deliveries.AggregateStats(ctx, tenantID, since)
+	if err != nil {
+		return domain.DeliveryStats{}, fmt.Errorf("统计投递记录失败: %w", err)
+	}
+
+	stats := domain.DeliveryStats{Since: since, Channels: make([]domain.DeliveryStat, 0, len(channels))}
+	var latency float64
+	for _, c := range channels {
+		c.SuccessRate = successRate(c)
+		stats.Channels = append(stats.Channels, c)
+
+		t := &stats.Total
+		t.Total += c.Total
+		t.Sent += c.Sent
+		t.Pending += c.Pending
+		t.Dead += c.Dead
+		t.Canceled += c.Canceled
+		t.Attempts += c.Attempts
+		t.Throttled += c.Throttled
+		latency += c.AvgLatencyMs * float64(c.Sent)
+	}
+	if stats.Total.Sent > 0 {
+		stats.Total.AvgLatencyMs = latency / float64(stats.Total.Sent)
+	}
+	stats.Total.SuccessRate = successRate(stats.Total)
+	return stats, nil
+}
+
+// successRate 已结束投递中送达的比例，尚无结束的投递时为 0
+func successRate(stat domain.DeliveryStat) float64 {
+	if finished := stat.Sent + stat.Dead; finished > 0 {
+		return float64(stat.Sent) / float64(finished)
+	}
+	return 0
+}
+
+func (s *AlertService) requireDeliveries() error {
+	if s.deliveries == nil {
+		return fmt.Errorf("未启用告警投递记录")
+	}
+	return nil
+}
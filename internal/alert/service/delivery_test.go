@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/internal/alert/repository/dao"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Mock DeliveryDAO ==========
+
+type mockDeliveryDAO struct {
+	dao.DeliveryDAO
+	deliveries []domain.AlertDelivery
+}
+
+func (m *mockDeliveryDAO) CreateDelivery(_ context.Context, d domain.AlertDelivery) (int64, error) {
+	for _, existing := range m.deliveries {
+		if existing.EventID == d.EventID && existing.ChannelID == d.ChannelID && existing.Round == d.Round {
+			return 0, errors.New("duplicate key")
+		}
+	}
+	d.ID = int64(len(m.deliveries) + 1)
+	d.CreateTime = d.NextAttemptAt
+	m.deliveries = append(m.deliveries, d)
+	return d.ID, nil
+}
+
+func (m *mockDeliveryDAO) UpdateDelivery(_ context.Context, d domain.AlertDelivery) error {
+	d.CreateTime = m.deliveries[d.ID-1].CreateTime
+	m.deliveries[d.ID-1] = d
+	return nil
+}
+
+func (m *mockDeliveryDAO) GetDeliveryByID(_ context.Context, id int64) (domain.AlertDelivery, error) {
+	if id < 1 || int(id) > len(m.deliveries) {
+		return domain.AlertDelivery{}, errors.New("not found")
+	}
+	return m.deliveries[id-1], nil
+}
+
+func (m *mockDeliveryDAO) ListDeliveries(_ context.Context, filter domain.DeliveryFilter) ([]domain.AlertDelivery, int64, error) {
+	var result []domain.AlertDelivery
+	for _, d := range m.deliveries {
+		if d.TenantID == filter.TenantID &&
+			(filter.ChannelID == 0 || d.ChannelID == filter.ChannelID) &&
+			(filter.Status == "" || d.Status == filter.Status) {
+			result = append(result, d)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockDeliveryDAO) ListEventDeliveries(_ context.Context, eventID int64) ([]domain.AlertDelivery, error) {
+	var result []domain.AlertDelivery
+	for _, d := range m.deliveries {
+		if d.EventID == eventID {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDeliveryDAO) GetDueDeliveries(_ context.Context, now time.Time, _ int) ([]domain.AlertDelivery, error) {
+	var result []domain.AlertDelivery
+	for _, d := range m.deliveries {
+		if (d.Status == domain.DeliveryPending || d.Status == domain.DeliveryRetrying) && !d.NextAttemptAt.After(now) {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDeliveryDAO) AggregateStats(_ context.Context, tenantID string, since time.Time) ([]domain.DeliveryStat, error) {
+	stats := make(map[int64]*domain.DeliveryStat)
+	latency := make(map[int64]float64)
+	for _, d := range m.deliveries {
+		if d.TenantID != tenantID || d.CreateTime.Before(since) {
+			continue
+		}
+		s, ok := stats[d.ChannelID]
+		if !ok {
+			s = &domain.DeliveryStat{ChannelID: d.ChannelID, ChannelType: d.ChannelType, ChannelName: d.ChannelName}
+			stats[d.ChannelID] = s
+		}
+		s.Total++
+		s.Attempts += int64(d.Attempts)
+		s.Throttled += int64(d.Throttled)
+		switch d.Status {
+		case domain.DeliverySent:
+			s.Sent++
+			latency[d.ChannelID] += float64(d.SentAt.Sub(d.CreateTime).Milliseconds())
+		case domain.DeliveryPending, domain.DeliveryRetrying:
+			s.Pending++
+		case domain.DeliveryDead:
+			s.Dead++
+		case domain.DeliveryCanceled:
+			s.Canceled++
+		}
+	}
+	var result []domain.DeliveryStat
+	for id, s := range stats {
+		if s.Sent > 0 {
+			s.AvgLatencyMs = latency[id] / float64(s.Sent)
+		}
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ChannelID < result[j].ChannelID })
+	return result, nil
+}
+
+// ========== 测试数据 ==========
+
+// stubEndpoint 可切换返回状态码的通知端点
+type stubEndpoint struct {
+	status atomic.Int32
+	calls  atomic.Int32
+	url    string
+}
+
+func newStubEndpoint(t *testing.T, status int) *stubEndpoint {
+	e := &stubEndpoint{}
+	e.status.Store(int32(status))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		e.calls.Add(1)
+		w.WriteHeader(int(e.status.Load()))
+	}))
+	t.Cleanup(srv.Close)
+	e.url = srv.URL
+	return e
+}
+
+// webhookChannel 测试用 Webhook 渠道，extra 覆盖默认配置
+func webhookChannel(id int64, url string, extra map[string]any) domain.NotificationChannel {
+	cfg := map[string]any{"url": url, "max_retries": 0}
+	for k, v := range extra {
+		cfg[k] = v
+	}
+	return domain.NotificationChannel{ID: id, Name: "hook", Type: domain.ChannelWebhook, Enabled: true, TenantID: "t1", Config: cfg}
+}
+
+func newDeliveryTestService(t *testing.T, rule domain.AlertRule, channels ...domain.NotificationChannel) (*AlertService, *mockAlertDAO, *mockDeliveryDAO, *testClock) {
+	t.Helper()
+	d := newMockAlertDAO(rule)
+	d.channels = channels
+	deliveries := &mockDeliveryDAO{}
+	svc, clock := newTestService(d)
+	svc.SetDeliveryDAO(deliveries)
+	return svc, d, deliveries, clock
+}
+
+func deliveryStatuses(deliveries []domain.AlertDelivery) []domain.DeliveryStatus {
+	result := make([]domain.DeliveryStatus, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, d.Status)
+	}
+	return result
+}
+
+// ========== 按渠道独立投递 ==========
+
+func TestDeliverEvent_IndependentRetry(t *testing.T) {
+	ok := newStubEndpoint(t, http.StatusOK)
+	flaky := newStubEndpoint(t, http.StatusServiceUnavailable)
+	rule := testRule()
+	rule.ChannelIDs = []int64{10, 20}
+	svc, d, deliveries, clock := newDeliveryTestService(t, rule,
+		webhookChannel(10, ok.url, nil), webhookChannel(20, flaky.url, nil))
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	assert.Equal(t, []domain.DeliveryStatus{domain.DeliverySent, domain.DeliveryRetrying}, deliveryStatuses(deliveries.deliveries))
+	assert.Equal(t, domain.EventStatusSent, d.events[0].Status, "任一渠道送达即为已发送")
+	failed := deliveries.deliveries[1]
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Equal(t, clock.now().Add(30*time.Second), failed.NextAttemptAt)
+	assert.Contains(t, failed.LastError, "503")
+
+	// 未到重试时间
+	clock.advance(10 * time.Second)
+	require.NoError(t, svc.ProcessDeliveries(ctx))
+	assert.Equal(t, int32(1), flaky.calls.Load())
+
+	// 只重试失败的渠道，已送达的渠道不重复发送
+	flaky.status.Store(http.StatusOK)
+	clock.advance(20 * time.Second)
+	require.NoError(t, svc.ProcessDeliveries(ctx))
+	assert.Equal(t, []domain.DeliveryStatus{domain.DeliverySent, domain.DeliverySent}, deliveryStatuses(deliveries.deliveries))
+	assert.Equal(t, int32(1), ok.calls.Load())
+	assert.Equal(t, int32(2), flaky.calls.Load())
+	assert.Equal(t, 2, deliveries.deliveries[1].Attempts)
+}
+
+func TestDeliverEvent_DeadLetterAndRedrive(t *testing.T) {
+	down := newStubEndpoint(t, http.StatusBadGateway)
+	svc, d, deliveries, clock := newDeliveryTestService(t, testRule(), webhookChannel(10, down.url, nil))
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	assert.Equal(t, domain.EventStatusSending, d.events[0].Status, "全部渠道重试中")
+
+	// 退避间隔 30s、1m、2m、4m，第 5 次失败进入死信
+	for _, backoff := range []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute} {
+		assert.Equal(t, clock.now().Add(backoff), deliveries.deliveries[0].NextAttemptAt)
+		clock.advance(backoff)
+		require.NoError(t, svc.ProcessDeliveries(ctx))
+	}
+	dead := deliveries.deliveries[0]
+	assert.Equal(t, domain.DeliveryDead, dead.Status)
+	assert.Equal(t, maxDeliveryAttempts, dead.Attempts)
+	assert.NotNil(t, dead.DeadAt)
+	assert.Equal(t, domain.EventStatusFailed, d.events[0].Status, "全部渠道进入死信")
+
+	letters, _, err := svc.ListDeliveries(ctx, domain.DeliveryFilter{TenantID: "t1", Status: domain.DeliveryDead})
+	require.NoError(t, err)
+	assert.Len(t, letters, 1)
+
+	// 只能重投死信，且不能重投其他租户的投递
+	_, err = svc.RedriveDelivery(ctx, "t2", dead.ID)
+	assert.ErrorIs(t, err, ErrDeliveryNotFound)
+
+	down.status.Store(http.StatusOK)
+	redriven, err := svc.RedriveDelivery(ctx, "t1", dead.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryPending, redriven.Status)
+	assert.Equal(t, 0, redriven.Attempts)
+	assert.Equal(t, domain.EventStatusSending, d.events[0].Status)
+	_, err = svc.RedriveDelivery(ctx, "t1", dead.ID)
+	assert.ErrorIs(t, err, ErrInvalidDeliveryOperation)
+
+	require.NoError(t, svc.ProcessDeliveries(ctx))
+	assert.Equal(t, domain.DeliverySent, deliveries.deliveries[0].Status)
+	assert.Equal(t, domain.EventStatusSent, d.events[0].Status)
+}
+
+func TestDeliverEvent_PermanentError(t *testing.T) {
+	rejected := newStubEndpoint(t, http.StatusUnauthorized)
+	rule := testRule()
+	rule.ChannelIDs = []int64{10, 20}
+	broken := domain.NotificationChannel{ID: 20, Type: domain.ChannelWebhook, Enabled: true, TenantID: "t1", Config: map[string]any{}}
+	svc, d, deliveries, _ := newDeliveryTestService(t, rule, webhookChannel(10, rejected.url, nil), broken)
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	// 4xx 与配置错误重试无法恢复，直接进入死信
+	assert.Equal(t, []domain.DeliveryStatus{domain.DeliveryDead, domain.DeliveryDead}, deliveryStatuses(deliveries.deliveries))
+	assert.Equal(t, 1, deliveries.deliveries[0].Attempts)
+	assert.Contains(t, deliveries.deliveries[1].LastError, "webhook url is required")
+	assert.Equal(t, domain.EventStatusFailed, d.events[0].Status)
+
+	// 修复渠道后按渠道批量重投
+	rejected.status.Store(http.StatusOK)
+	n, err := svc.RedriveDeliveries(ctx, "t1", nil, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, domain.DeliveryPending, deliveries.deliveries[0].Status)
+	assert.Equal(t, domain.DeliveryDead, deliveries.deliveries[1].Status)
+}
+
+func TestDeliverEvent_RateLimited(t *testing.T) {
+	ok := newStubEndpoint(t, http.StatusOK)
+	// 每分钟 4 条：突发 1 条，之后每 15 秒 1 条
+	svc, _, deliveries, clock := newDeliveryTestService(t, testRule(),
+		webhookChannel(10, ok.url, map[string]any{"rate_limit": 4}))
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-2")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	assert.Equal(t, []domain.DeliveryStatus{domain.DeliverySent, domain.DeliveryPending}, deliveryStatuses(deliveries.deliveries))
+	throttled := deliveries.deliveries[1]
+	assert.Equal(t, 0, throttled.Attempts, "限流推迟不计入尝试次数")
+	assert.Equal(t, 1, throttled.Throttled)
+	assert.Equal(t, clock.now().Add(15*time.Second), throttled.NextAttemptAt)
+	assert.Equal(t, int32(1), ok.calls.Load())
+
+	clock.advance(15 * time.Second)
+	require.NoError(t, svc.ProcessDeliveries(ctx))
+	assert.Equal(t, domain.DeliverySent, deliveries.deliveries[1].Status)
+	assert.Equal(t, int32(2), ok.calls.Load())
+}
+
+func TestDeliverEvent_SenderRetryDisabled(t *testing.T) {
+	down := newStubEndpoint(t, http.StatusServiceUnavailable)
+	ch := webhookChannel(10, down.url, nil)
+	delete(ch.Config, "max_retries")
+	svc, d, deliveries, _ := newDeliveryTestService(t, testRule(), ch)
+	ctx := context.Background()
+
+	// 渠道按默认配置会内置重试，投递时每次尝试只请求一次，重试完全由投递记录驱动
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	assert.Equal(t, int32(1), down.calls.Load())
+	assert.Equal(t, 1, deliveries.deliveries[0].Attempts)
+	assert.NotContains(t, d.channels[0].Config, "max_retries", "不修改渠道配置")
+}
+
+func TestProcessDeliveries_BatchBudget(t *testing.T) {
+	down := newStubEndpoint(t, http.StatusServiceUnavailable)
+	svc, _, deliveries, clock := newDeliveryTestService(t, testRule(), webhookChannel(10, down.url, nil))
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	clock.advance(time.Minute)
+
+	// 剩余时间不足一次尝试时不再发起投递，投递保持到期状态留待下一批
+	short, cancel := context.WithTimeout(ctx, deliveryAttemptTimeout/2)
+	defer cancel()
+	require.NoError(t, svc.ProcessDeliveries(short))
+	assert.Equal(t, int32(1), down.calls.Load())
+	assert.Equal(t, 1, deliveries.deliveries[0].Attempts)
+
+	require.NoError(t, svc.ProcessDeliveries(ctx))
+	assert.Equal(t, int32(2), down.calls.Load())
+	assert.Equal(t, 2, deliveries.deliveries[0].Attempts)
+}
+
+func TestDeliverEvent_AttemptBudget(t *testing.T) {
+	ok := newStubEndpoint(t, http.StatusOK)
+	rule := testRule()
+	rule.ChannelIDs = []int64{10, 20}
+	svc, d, deliveries, _ := newDeliveryTestService(t, rule,
+		webhookChannel(10, ok.url, nil), webhookChannel(20, ok.url, nil))
+	ctx := context.Background()
+
+	// 剩余时间不足一次尝试时只创建投递记录，不发起发送
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	short, cancel := context.WithTimeout(ctx, deliveryAttemptTimeout/2)
+	defer cancel()
+	require.NoError(t, svc.ProcessPendingEvents(short))
+	assert.Equal(t, []domain.DeliveryStatus{domain.DeliveryPending, domain.DeliveryPending}, deliveryStatuses(deliveries.deliveries))
+	assert.Equal(t, int32(0), ok.calls.Load())
+	assert.Equal(t, domain.EventStatusSending, d.events[0].Status)
+
+	require.NoError(t, svc.ProcessDeliveries(ctx))
+	assert.Equal(t, []domain.DeliveryStatus{domain.DeliverySent, domain.DeliverySent}, deliveryStatuses(deliveries.deliveries))
+	assert.Equal(t, int32(2), ok.calls.Load())
+	assert.Equal(t, domain.EventStatusSent, d.events[0].Status)
+}
+
+func TestProcessDeliveries_CancelResolved(t *testing.T) {
+	down := newStubEndpoint(t, http.StatusServiceUnavailable)
+	svc, _, deliveries, clock := newDeliveryTestService(t, testRule(), webhookChannel(10, down.url, nil))
+	ctx := context.Background()
+
+	event := testEvent("i-1")
+	require.NoError(t, svc.EmitEvent(ctx, event))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	require.NoError(t, svc.ResolveEvent(ctx, event))
+
+	clock.advance(time.Minute)
+	require.NoError(t, svc.ProcessDeliveries(ctx))
+	assert.Equal(t, domain.DeliveryCanceled, deliveries.deliveries[0].Status)
+	assert.Equal(t, int32(1), down.calls.Load(), "告警恢复后不再重试")
+}
+
+func TestRenotify_CreatesNewRound(t *testing.T) {
+	ok := newStubEndpoint(t, http.StatusOK)
+	rule := testRule()
+	rule.AckTimeout = 15
+	svc, d, deliveries, clock := newDeliveryTestService(t, rule, webhookChannel(10, ok.url, nil))
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	// 重复处理同一轮次不会重复投递
+	require.NoError(t, svc.dispatchEvent(ctx, rule, d.events[0], 0))
+	require.Len(t, deliveries.deliveries, 1)
+
+	clock.advance(15 * time.Minute)
+	require.NoError(t, svc.RenotifyUnacknowledged(ctx))
+	require.Len(t, deliveries.deliveries, 2)
+	assert.Equal(t, 1, deliveries.deliveries[1].Round)
+	assert.Equal(t, domain.DeliverySent, deliveries.deliveries[1].Status)
+	assert.Equal(t, int32(2), ok.calls.Load())
+}
+
+func TestDeliveryStats(t *testing.T) {
+	ok := newStubEndpoint(t, http.StatusOK)
+	down := newStubEndpoint(t, http.StatusForbidden)
+	rule := testRule()
+	rule.ChannelIDs = []int64{10, 20}
+	svc, _, _, clock := newDeliveryTestService(t, rule,
+		webhookChannel(10, ok.url, nil), webhookChannel(20, down.url, nil))
+	ctx := context.Background()
+	since := clock.now()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-2")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	stats, err := svc.DeliveryStats(ctx, "t1", since)
+	require.NoError(t, err)
+	require.Len(t, stats.Channels, 2)
+	assert.Equal(t, int64(2), stats.Channels[0].Sent)
+	assert.Equal(t, 1.0, stats.Channels[0].SuccessRate)
+	assert.Equal(t, int64(2), stats.Channels[1].Dead)
+	assert.Equal(t, 0.0, stats.Channels[1].SuccessRate)
+	assert.Equal(t, int64(4), stats.Total.Total)
+	assert.Equal(t, int64(4), stats.Total.Attempts)
+	assert.Equal(t, 0.5, stats.Total.SuccessRate)
+}
+
+func TestDeliveryBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, deliveryBackoff(1))
+	assert.Equal(t, 4*time.Minute, deliveryBackoff(4))
+	assert.Equal(t, deliveryBackoffMax, deliveryBackoff(10))
+	assert.Equal(t, deliveryBackoffMax, deliveryBackoff(100))
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/channel"
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+var (
+	// ErrEventNotFound 告警事件不存在或不属于当前租户
+	ErrEventNotFound = errors.New("告警事件不存在")
+	// ErrInvalidEventOperation 告警事件当前状态不允许该操作
+	ErrInvalidEventOperation = errors.New("告警事件操作无效")
+)
+
+// GetEvent 获取告警事件详情（含时间线）
+func (s *AlertService) GetEvent(ctx context.Context, tenantID string, id int64) (domain.AlertEvent, error) {
+	return s.getTenantEvent(ctx, tenantID, id)
+}
+
+// AckEvent 确认告警事件：停止超时重新通知；事件尚未指派时指派给确认人，并同步确认事件平台上的 incident
+func (s *AlertService) AckEvent(ctx context.Context, tenantID string, id int64, operator, comment string) error {
+	event, err := s.getTenantEvent(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	switch event.Status {
+	case domain.EventStatusAcknowledged:
+		return nil
+	case domain.EventStatusResolved:
+		return fmt.Errorf("%w: 告警已恢复", ErrInvalidEventOperation)
+	}
+
+	now := s.now()
+	acked, err := s.dao.AckEvent(ctx, id, domain.EventTransition{
+		Action:   domain.EventActionAck,
+		Status:   domain.EventStatusAcknowledged,
+		Operator: operator,
+		Comment:  comment,
+		Time:     now,
+	})
+	if err != nil {
+		return fmt.Errorf("确认告警事件失败: %w", err)
+	}
+	if !acked {
+		// 读取后被并发确认或恢复
+		return fmt.Errorf("%w: 告警已确认或已恢复", ErrInvalidEventOperation)
+	}
+
+	if event.Assignee == "" && operator != "" {
+		if _, err := s.dao.AssignEvent(ctx, id, operator, domain.EventTransition{
+			Action:   domain.EventActionAssign,
+			Status:   domain.EventStatusAcknowledged,
+			Reason:   "确认时指派给确认人",
+			Operator: operator,
+			Time:     now,
+		}); err != nil {
+			return fmt.Errorf("指派告警事件失败: %w", err)
+		}
+	}
+
+	event.Status = domain.EventStatusAcknowledged
+	s.notifyIncidentStatus(ctx, event)
+	return nil
+}
+
+// AssignEvent 指派告警事件处理人
+func (s *AlertService) AssignEvent(ctx context.Context, tenantID string, id int64, assignee, operator, comment string) error {
+	if assignee == "" {
+		return fmt.Errorf("%w: 处理人不能为空", ErrInvalidEventOperation)
+	}
+	event, err := s.getTenantEvent(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if event.Status == domain.EventStatusResolved {
+		return fmt.Errorf("%w: 告警已恢复", ErrInvalidEventOperation)
+	}
+
+	assigned, err := s.dao.AssignEvent(ctx, id, assignee, domain.EventTransition{
+		Action:   domain.EventActionAssign,
+		Status:   event.Status,
+		Reason:   "指派给 " + assignee,
+		Operator: operator,
+		Comment:  comment,
+		Time:     s.now(),
+	})
+	if err != nil {
+		return fmt.Errorf("指派告警事件失败: %w", err)
+	}
+	if !assigned {
+		return fmt.Errorf("%w: 告警已恢复", ErrInvalidEventOperation)
+	}
+	return nil
+}
+
+// CommentEvent 为告警事件添加评论
+func (s *AlertService) CommentEvent(ctx context.Context, tenantID string, id int64, operator, comment string) error {
+	if comment == "" {
+		return fmt.Errorf("%w: 评论内容不能为空", ErrInvalidEventOperation)
+	}
+	event, err := s.getTenantEvent(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.dao.AppendEventHistory(ctx, id, domain.EventTransition{
+		Action:   domain.EventActionComment,
+		Status:   event.Status,
+		Operator: operator,
+		Comment:  comment,
+		Time:     s.now(),
+	})
+}
+
+// notifyIncidentStatus 将告警的确认 / 恢复推送到规则的事件平台渠道，使平台上对应的 incident 随之确认或关闭
+// 其他渠道不推送状态变更；推送失败只记录日志，不影响本地状态
+func (s *AlertService) notifyIncidentStatus(ctx context.Context, event domain.AlertEvent) {
+	rule, err := s.dao.GetRuleByID(ctx, event.RuleID)
+	if err != nil {
+		// 规则已删除，无从得知通知渠道
+		return
+	}
+	channels, err := s.eventChannels(ctx, rule, event, nil)
+	if err != nil {
+		s.logger.Warn("获取事件平台渠道失败",
+			elog.Int64("event_id", event.ID),
+			elog.FieldErr(err))
+		return
+	}
+	var incidents []domain.NotificationChannel
+	for _, ch := range channels {
+		if ch.Type == domain.ChannelIncident {
+			incidents = append(incidents, ch)
+		}
+	}
+	if len(incidents) == 0 {
+		return
+	}
+
+	n := s.newNotification(ctx, event, nil)
+	if err := channel.NewDispatcher(incidents).DispatchEach(ctx, func(channelType domain.ChannelType) *channel.Message {
+		return s.renderMessage(n.templates, channelType, n.data, n.base)
+	}); err != nil {
+		s.logger.Warn("同步事件平台状态失败",
+			elog.Int64("event_id", event.ID),
+			elog.String("status", string(event.Status)),
+			elog.FieldErr(err))
+	}
+}
+
+// getTenantEvent 获取告警事件，不属于该租户时视为不存在
+func (s *AlertService) getTenantEvent(ctx context.Context, tenantID string, id int64) (domain.AlertEvent, error) {
+	event, err := s.dao.GetEventByID(ctx, id)
+	if err != nil {
+		return domain.AlertEvent{}, fmt.Errorf("%w: %v", ErrEventNotFound, err)
+	}
+	if tenantID != "" && event.TenantID != tenantID {
+		return domain.AlertEvent{}, ErrEventNotFound
+	}
+	return event, nil
+}
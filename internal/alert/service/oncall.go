@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/internal/alert/repository/dao"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// ErrInvalidSchedule 值班表配置无效
+var ErrInvalidSchedule = errors.New("值班表配置无效")
+
+// maxShiftRange 值班班次预览的最大时间范围
+const maxShiftRange = 90 * 24 * time.Hour
+
+// NodeResolver 服务树节点查询接口（可选注入）
+// 注入后节点未配置值班表时按服务树向上继承；未注入时只查节点自身与租户默认值班表
+type NodeResolver interface {
+	// AncestorIDs 返回节点的祖先节点 ID，由近及远
+	AncestorIDs(ctx context.Context, nodeID int64) ([]int64, error)
+}
+
+// OnCallService 值班管理服务
+type OnCallService struct {
+	dao    dao.OnCallDAO
+	nodes  NodeResolver
+	logger *elog.Component
+	now    func() time.Time
+}
+
+// NewOnCallService 创建值班管理服务
+func NewOnCallService(dao dao.OnCallDAO, logger *elog.Component) *OnCallService {
+	return &OnCallService{dao: dao, logger: logger, now: time.Now}
+}
+
+// SetNodeResolver 设置服务树节点查询（可选注入）
+func (s *OnCallService) SetNodeResolver(nodes NodeResolver) {
+	s.nodes = nodes
+}
+
+// ========== 值班表管理 ==========
+
+func (s *OnCallService) CreateSchedule(ctx context.Context, schedule domain.OnCallSchedule) (int64, error) {
+	if err := validateSchedule(schedule); err != nil {
+		return 0, err
+	}
+	return s.dao.CreateSchedule(ctx, schedule)
+}
+
+func (s *OnCallService) UpdateSchedule(ctx context.Context, schedule domain.OnCallSchedule) error {
+	if _, err := s.GetSchedule(ctx, schedule.TenantID, schedule.ID); err != nil {
+		return err
+	}
+	if err := validateSchedule(schedule); err != nil {
+		return err
+	}
+	return s.dao.UpdateSchedule(ctx, schedule)
+}
+
+// GetSchedule 获取值班表，不属于该租户时视为不存在
+func (s *OnCallService) GetSchedule(ctx context.Context, tenantID string, id int64) (domain.OnCallSchedule, error) {
+	schedule, err := s.dao.GetScheduleByID(ctx, id)
+	if err != nil {
+		return domain.OnCallSchedule{}, fmt.Errorf("获取值班表失败: %w", err)
+	}
+	if tenantID != "" && schedule.TenantID != tenantID {
+		return domain.OnCallSchedule{}, fmt.Errorf("%w: 值班表不存在", ErrInvalidSchedule)
+	}
+	return schedule, nil
+}
+
+func (s *OnCallService) ListSchedules(ctx context.Context, filter domain.OnCallScheduleFilter) ([]domain.OnCallSchedule, int64, error) {
+	return s.dao.ListSchedules(ctx, filter)
+}
+
+func (s *OnCallService) DeleteSchedule(ctx context.Context, tenantID string, id int64) error {
+	if _, err := s.GetSchedule(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.dao.DeleteSchedule(ctx, id)
+}
+
+// AddOverride 添加临时替班，同时清理已结束的替班记录
+func (s *OnCallService) AddOverride(ctx context.Context, tenantID string, id int64, override domain.OnCallOverride) error {
+	schedule, err := s.GetSchedule(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := validateOverride(override); err != nil {
+		return err
+	}
+
+	now := s.now()
+	overrides := make([]domain.OnCallOverride, 0, len(schedule.Overrides)+1)
+	for _, o := range schedule.Overrides {
+		if o.End.After(now) {
+			overrides = append(overrides, o)
+		}
+	}
+	schedule.Overrides = append(overrides, override)
+	return s.dao.UpdateSchedule(ctx, schedule)
+}
+
+func validateSchedule(schedule domain.OnCallSchedule) error {
+	if schedule.Name == "" {
+		return fmt.Errorf("%w: 名称不能为空", ErrInvalidSchedule)
+	}
+	if schedule.NodeID < 0 {
+		return fmt.Errorf("%w: 服务树节点无效", ErrInvalidSchedule)
+	}
+	if rotationPeriod(schedule.Rotation) == 0 {
+		return fmt.Errorf("%w: 不支持的轮换方式 %q", ErrInvalidSchedule, schedule.Rotation)
+	}
+	if schedule.StartTime.IsZero() {
+		return fmt.Errorf("%w: 开始时间不能为空", ErrInvalidSchedule)
+	}
+	if len(schedule.Members) == 0 {
+		return fmt.Errorf("%w: 至少需要一名值班成员", ErrInvalidSchedule)
+	}
+	for _, m := range schedule.Members {
+		if m.UserID == "" {
+			return fmt.Errorf("%w: 值班成员 user_id 不能为空", ErrInvalidSchedule)
+		}
+	}
+	for _, o := range schedule.Overrides {
+		if err := validateOverride(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateOverride(o domain.OnCallOverride) error {
+	if o.Member.UserID == "" {
+		return fmt.Errorf("%w: 替班成员 user_id 不能为空", ErrInvalidSchedule)
+	}
+	if !o.End.After(o.Start) {
+		return fmt.Errorf("%w: 替班结束时间必须晚于开始时间", ErrInvalidSchedule)
+	}
+	return nil
+}
+
+// ========== 值班查询 ==========
+
+// CurrentOnCall 实现 OnCallResolver：依次查找节点自身、祖先节点与租户默认（节点 0）值班表，返回第一个有人值班的班次
+func (s *OnCallService) CurrentOnCall(ctx context.Context, tenantID string, nodeID int64, at time.Time) (*domain.OnCallShift, error) {
+	nodeIDs := []int64{nodeID}
+	if nodeID > 0 && s.nodes != nil {
+		ancestors, err := s.nodes.AncestorIDs(ctx, nodeID)
+		if err != nil {
+			s.logger.Warn("查询服务树祖先节点失败，仅使用节点自身值班表",
+				elog.Int64("node_id", nodeID),
+				elog.FieldErr(err))
+		}
+		nodeIDs = append(nodeIDs, ancestors...)
+	}
+	if nodeID != 0 {
+		nodeIDs = append(nodeIDs, 0)
+	}
+
+	for _, id := range nodeIDs {
+		schedules, _, err := s.dao.ListSchedules(ctx, domain.OnCallScheduleFilter{TenantID: tenantID, NodeID: &id})
+		if err != nil {
+			return nil, fmt.Errorf("查询值班表失败: %w", err)
+		}
+		for _, schedule := range schedules {
+			if shift, ok := shiftAt(schedule, at); ok {
+				return &shift, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// ListShifts 预览值班表在时间范围内的班次，临时替班单独列出（替班期间以替班人为准）
+func (s *OnCallService) ListShifts(ctx context.Context, tenantID string, id int64, start, end time.Time) ([]domain.OnCallShift, error) {
+	if !end.After(start) || end.Sub(start) > maxShiftRange {
+		return nil, fmt.Errorf("%w: 时间范围无效（最长 90 天）", ErrInvalidSchedule)
+	}
+	schedule, err := s.GetSchedule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var shifts []domain.OnCallShift
+	period := rotationPeriod(schedule.Rotation)
+	if period > 0 && len(schedule.Members) > 0 {
+		n := int64(0)
+		if start.After(schedule.StartTime) {
+			n = int64(start.Sub(schedule.StartTime) / period)
+		}
+		for ; ; n++ {
+			shiftStart := schedule.StartTime.Add(time.Duration(n) * period)
+			if !shiftStart.Before(end) {
+				break
+			}
+			shifts = append(shifts, domain.OnCallShift{
+				ScheduleID: schedule.ID,
+				NodeID:     schedule.NodeID,
+				Member:     schedule.Members[n%int64(len(schedule.Members))],
+				Start:      shiftStart,
+				End:        shiftStart.Add(period),
+			})
+		}
+	}
+	for _, o := range schedule.Overrides {
+		if o.Start.Before(end) && o.End.After(start) {
+			shifts = append(shifts, overrideShift(schedule, o))
+		}
+	}
+	sort.SliceStable(shifts, func(i, j int) bool { return shifts[i].Start.Before(shifts[j].Start) })
+	return shifts, nil
+}
+
+// shiftAt 计算值班表在指定时刻的班次：临时替班优先（后添加的优先），否则按轮换顺序
+func shiftAt(schedule domain.OnCallSchedule, at time.Time) (domain.OnCallShift, bool) {
+	for i := len(schedule.Overrides) - 1; i >= 0; i-- {
+		o := schedule.Overrides[i]
+		if !at.Before(o.Start) && at.Before(o.End) {
+			return overrideShift(schedule, o), true
+		}
+	}
+
+	period := rotationPeriod(schedule.Rotation)
+	if period == 0 || len(schedule.Members) == 0 || at.Before(schedule.StartTime) {
+		return domain.OnCallShift{}, false
+	}
+	n := int64(at.Sub(schedule.StartTime) / period)
+	start := schedule.StartTime.Add(time.Duration(n) * period)
+	return domain.OnCallShift{
+		ScheduleID: schedule.ID,
+		NodeID:     schedule.NodeID,
+		Member:     schedule.Members[n%int64(len(schedule.Members))],
+		Start:      start,
+		End:        start.Add(period),
+	}, true
+}
+
+func overrideShift(schedule domain.OnCallSchedule, o domain.OnCallOverride) domain.OnCallShift {
+	return domain.OnCallShift{
+		ScheduleID: schedule.ID,
+		NodeID:     schedule.NodeID,
+		Member:     o.Member,
+		Start:      o.Start,
+		End:        o.End,
+		Override:   true,
+	}
+}
+
+func rotationPeriod(rotation domain.RotationType) time.Duration {
+	switch rotation {
+	case domain.RotationDaily:
+		return 24 * time.Hour
+	case domain.RotationWeekly:
+		return 7 * 24 * time.Hour
+	}
+	return 0
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/internal/alert/repository/dao"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOnCallDAO struct {
+	dao.OnCallDAO
+	schedules []domain.OnCallSchedule
+}
+
+func (m *mockOnCallDAO) CreateSchedule(_ context.Context, schedule domain.OnCallSchedule) (int64, error) {
+	schedule.ID = int64(len(m.schedules) + 1)
+	m.schedules = append(m.schedules, schedule)
+	return schedule.ID, nil
+}
+
+func (m *mockOnCallDAO) UpdateSchedule(_ context.Context, schedule domain.OnCallSchedule) error {
+	m.schedules[schedule.ID-1] = schedule
+	return nil
+}
+
+func (m *mockOnCallDAO) GetScheduleByID(_ context.Context, id int64) (domain.OnCallSchedule, error) {
+	if id < 1 || int(id) > len(m.schedules) {
+		return domain.OnCallSchedule{}, errors.New("not found")
+	}
+	return m.schedules[id-1], nil
+}
+
+func (m *mockOnCallDAO) ListSchedules(_ context.Context, filter domain.OnCallScheduleFilter) ([]domain.OnCallSchedule, int64, error) {
+	var result []domain.OnCallSchedule
+	for _, s := range m.schedules {
+		if s.TenantID == filter.TenantID && (filter.NodeID == nil || s.NodeID == *filter.NodeID) {
+			result = append(result, s)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+// mockNodes 服务树：3 → 2 → 1
+type mockNodes map[int64][]int64
+
+func (m mockNodes) AncestorIDs(_ context.Context, nodeID int64) ([]int64, error) {
+	return m[nodeID], nil
+}
+
+var (
+	oncallStart = time.Date(2026, 10, 5, 9, 0, 0, 0, time.UTC) // 周一 09:00 交接
+	alice       = domain.OnCallMember{UserID: "alice", Name: "Alice", ChannelIDs: []int64{101}}
+	bob         = domain.OnCallMember{UserID: "bob", Name: "Bob", ChannelIDs: []int64{102}}
+	carol       = domain.OnCallMember{UserID: "carol", Name: "Carol", ChannelIDs: []int64{103}}
+)
+
+func testSchedule(nodeID int64, rotation domain.RotationType) domain.OnCallSchedule {
+	return domain.OnCallSchedule{
+		Name: "sre", NodeID: nodeID, Rotation: rotation, TenantID: "t1",
+		Members: []domain.OnCallMember{alice, bob}, StartTime: oncallStart,
+	}
+}
+
+func TestShiftAt(t *testing.T) {
+	weekly := testSchedule(0, domain.RotationWeekly)
+
+	_, ok := shiftAt(weekly, oncallStart.Add(-time.Minute))
+	assert.False(t, ok, "开始时间之前无人值班")
+
+	shift, ok := shiftAt(weekly, oncallStart.AddDate(0, 0, 6))
+	require.True(t, ok)
+	assert.Equal(t, "alice", shift.Member.UserID)
+	assert.Equal(t, oncallStart, shift.Start)
+	assert.Equal(t, oncallStart.AddDate(0, 0, 7), shift.End)
+
+	shift, _ = shiftAt(weekly, oncallStart.AddDate(0, 0, 7))
+	assert.Equal(t, "bob", shift.Member.UserID, "交接时刻切换到下一位")
+	shift, _ = shiftAt(weekly, oncallStart.AddDate(0, 0, 14))
+	assert.Equal(t, "alice", shift.Member.UserID, "按成员顺序循环")
+
+	daily := testSchedule(0, domain.RotationDaily)
+	shift, _ = shiftAt(daily, oncallStart.Add(25*time.Hour))
+	assert.Equal(t, "bob", shift.Member.UserID)
+
+	// 替班优先于轮换，重叠时后添加的优先
+	weekly.Overrides = []domain.OnCallOverride{
+		{Member: bob, Start: oncallStart.Add(time.Hour), End: oncallStart.Add(5 * time.Hour)},
+		{Member: carol, Start: oncallStart.Add(2 * time.Hour), End: oncallStart.Add(3 * time.Hour)},
+	}
+	shift, _ = shiftAt(weekly, oncallStart.Add(90*time.Minute))
+	assert.Equal(t, "bob", shift.Member.UserID)
+	assert.True(t, shift.Override)
+	shift, _ = shiftAt(weekly, oncallStart.Add(150*time.Minute))
+	assert.Equal(t, "carol", shift.Member.UserID)
+	shift, _ = shiftAt(weekly, oncallStart.Add(5*time.Hour))
+	assert.Equal(t, "alice", shift.Member.UserID, "替班结束后恢复轮换")
+	assert.False(t, shift.Override)
+}
+
+func TestCurrentOnCall_Inheritance(t *testing.T) {
+	d := &mockOnCallDAO{}
+	svc := NewOnCallService(d, elog.DefaultLogger)
+	svc.SetNodeResolver(mockNodes{3: {2, 1}, 2: {1}})
+	ctx := context.Background()
+	at := oncallStart.Add(time.Hour)
+
+	shift, err := svc.CurrentOnCall(ctx, "t1", 3, at)
+	require.NoError(t, err)
+	assert.Nil(t, shift, "未配置任何值班表")
+
+	defaultSchedule := testSchedule(0, domain.RotationWeekly)
+	defaultSchedule.Members = []domain.OnCallMember{carol}
+	_, err = svc.CreateSchedule(ctx, defaultSchedule)
+	require.NoError(t, err)
+	shift, _ = svc.CurrentOnCall(ctx, "t1", 3, at)
+	require.NotNil(t, shift)
+	assert.Equal(t, "carol", shift.Member.UserID, "回退到租户默认值班表")
+
+	_, err = svc.CreateSchedule(ctx, testSchedule(1, domain.RotationWeekly))
+	require.NoError(t, err)
+	shift, _ = svc.CurrentOnCall(ctx, "t1", 3, at)
+	assert.Equal(t, "alice", shift.Member.UserID, "继承上级节点值班表")
+	assert.Equal(t, int64(1), shift.NodeID)
+
+	shift, _ = svc.CurrentOnCall(ctx, "t2", 3, at)
+	assert.Nil(t, shift, "不使用其他租户的值班表")
+}
+
+func TestOnCallService_Validate(t *testing.T) {
+	d := &mockOnCallDAO{}
+	svc := NewOnCallService(d, elog.DefaultLogger)
+	ctx := context.Background()
+
+	bad := testSchedule(0, "monthly")
+	_, err := svc.CreateSchedule(ctx, bad)
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+	bad = testSchedule(0, domain.RotationDaily)
+	bad.Members = nil
+	_, err = svc.CreateSchedule(ctx, bad)
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	id, err := svc.CreateSchedule(ctx, testSchedule(0, domain.RotationDaily))
+	require.NoError(t, err)
+	err = svc.AddOverride(ctx, "t1", id, domain.OnCallOverride{Member: carol, Start: oncallStart, End: oncallStart})
+	assert.ErrorIs(t, err, ErrInvalidSchedule, "结束时间必须晚于开始时间")
+	assert.ErrorIs(t, svc.DeleteSchedule(ctx, "t2", id), ErrInvalidSchedule, "不能删除其他租户的值班表")
+}
+
+func TestAddOverride_PrunesExpired(t *testing.T) {
+	d := &mockOnCallDAO{}
+	svc := NewOnCallService(d, elog.DefaultLogger)
+	svc.now = func() time.Time { return oncallStart.AddDate(0, 0, 3) }
+	ctx := context.Background()
+
+	schedule := testSchedule(0, domain.RotationDaily)
+	schedule.Overrides = []domain.OnCallOverride{{Member: carol, Start: oncallStart, End: oncallStart.Add(time.Hour)}}
+	id, err := svc.CreateSchedule(ctx, schedule)
+	require.NoError(t, err)
+
+	override := domain.OnCallOverride{Member: carol, Start: oncallStart.AddDate(0, 0, 4), End: oncallStart.AddDate(0, 0, 5)}
+	require.NoError(t, svc.AddOverride(ctx, "t1", id, override))
+	assert.Equal(t, []domain.OnCallOverride{override}, d.schedules[0].Overrides)
+}
+
+func TestListShifts(t *testing.T) {
+	d := &mockOnCallDAO{}
+	svc := NewOnCallService(d, elog.DefaultLogger)
+	ctx := context.Background()
+
+	schedule := testSchedule(0, domain.RotationDaily)
+	schedule.Overrides = []domain.OnCallOverride{{Member: carol, Start: oncallStart.Add(26 * time.Hour), End: oncallStart.Add(30 * time.Hour)}}
+	id, err := svc.CreateSchedule(ctx, schedule)
+	require.NoError(t, err)
+
+	shifts, err := svc.ListShifts(ctx, "t1", id, oncallStart.Add(12*time.Hour), oncallStart.Add(72*time.Hour))
+	require.NoError(t, err)
+	var users []string
+	for _, s := range shifts {
+		users = append(users, s.Member.UserID)
+	}
+	// 包含查询起点所在的班次，替班按开始时间插入
+	assert.Equal(t, []string{"alice", "bob", "carol", "alice"}, users)
+	assert.Equal(t, oncallStart, shifts[0].Start)
+	assert.True(t, shifts[2].Override)
+
+	_, err = svc.ListShifts(ctx, "t1", id, oncallStart, oncallStart.AddDate(0, 0, 91))
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+}
+
+func TestSendEvent_RoutesToOnCall(t *testing.T) {
+	onCallDAO := &mockOnCallDAO{}
+	onCall := NewOnCallService(onCallDAO, elog.DefaultLogger)
+	_, err := onCall.CreateSchedule(context.Background(), testSchedule(0, domain.RotationWeekly))
+	require.NoError(t, err)
+
+	d := newMockAlertDAO(testRule())
+	svc, clock := newTestService(d)
+	svc.SetOnCallResolver(onCall)
+	clock.t = oncallStart.AddDate(0, 0, 8) // bob 值班
+	ctx := context.Background()
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	require.Len(t, d.channelRequests, 1)
+	assert.Equal(t, []int64{10, 102}, d.channelRequests[0], "规则渠道 + 值班人个人渠道")
+	e := d.events[0]
+	assert.Equal(t, "bob", e.Assignee)
+	assert.Equal(t, domain.EventStatusSent, e.Status)
+	assert.Equal(t, domain.EventActionAssign, e.History[1].Action)
+
+	// 已指派的事件不被值班自动指派覆盖
+	require.NoError(t, svc.AssignEvent(ctx, "t1", 1, "dave", "alice", ""))
+	d.events[0].Status = domain.EventStatusPending
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+	assert.Equal(t, "dave", d.events[0].Assignee)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/channel"
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// ErrInvalidTemplate 消息模板无效
+var ErrInvalidTemplate = errors.New("消息模板无效")
+
+// ========== 消息模板管理 ==========
+
+func (s *AlertService) CreateTemplate(ctx context.Context, tmpl domain.MessageTemplate) (int64, error) {
+	if err := validateTemplate(tmpl); err != nil {
+		return 0, err
+	}
+	return s.dao.CreateTemplate(ctx, tmpl)
+}
+
+func (s *AlertService) UpdateTemplate(ctx context.Context, tmpl domain.MessageTemplate) error {
+	if _, err := s.GetTemplate(ctx, tmpl.TenantID, tmpl.ID); err != nil {
+		return err
+	}
+	if err := validateTemplate(tmpl); err != nil {
+		return err
+	}
+	return s.dao.UpdateTemplate(ctx, tmpl)
+}
+
+// GetTemplate 获取消息模板，不属于该租户时视为不存在
+func (s *AlertService) GetTemplate(ctx context.Context, tenantID string, id int64) (domain.MessageTemplate, error) {
+	tmpl, err := s.dao.GetTemplateByID(ctx, id)
+	if err != nil {
+		return domain.MessageTemplate{}, fmt.Errorf("获取消息模板失败: %w", err)
+	}
+	if tenantID != "" && tmpl.TenantID != tenantID {
+		return domain.MessageTemplate{}, fmt.Errorf("%w: 消息模板不存在", ErrInvalidTemplate)
+	}
+	return tmpl, nil
+}
+
+func (s *AlertService) ListTemplates(ctx context.Context, filter domain.MessageTemplateFilter) ([]domain.MessageTemplate, int64, error) {
+	return s.dao.ListTemplates(ctx, filter)
+}
+
+func (s *AlertService) DeleteTemplate(ctx context.Context, tenantID string, id int64) error {
+	if _, err := s.GetTemplate(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.dao.DeleteTemplate(ctx, id)
+}
+
+// PreviewTemplate 预览模板渲染结果：指定事件时用该事件渲染，否则用示例事件
+func (s *AlertService) PreviewTemplate(ctx context.Context, tmpl domain.MessageTemplate, eventID int64) (*channel.Message, error) {
+	if err := channel.ValidateTemplate(tmpl); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	data := channel.SampleTemplateData(tmpl.AlertType)
+	if eventID > 0 {
+		event, err := s.getTenantEvent(ctx, tmpl.TenantID, eventID)
+		if err != nil {
+			return nil, err
+		}
+		base := s.buildMessage(event)
+		data = channel.TemplateData{AlertEvent: event, DefaultTitle: base.Title, DefaultContent: base.Content}
+	}
+	msg, err := channel.RenderTemplate(tmpl, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return msg, nil
+}
+
+func validateTemplate(tmpl domain.MessageTemplate) error {
+	if tmpl.Name == "" {
+		return fmt.Errorf("%w: 名称不能为空", ErrInvalidTemplate)
+	}
+	if err := channel.ValidateTemplate(tmpl); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return nil
+}
+
+// ========== 消息渲染 ==========
+
+// eventTemplates 获取租户启用的消息模板，查询失败时退化为内置格式
+func (s *AlertService) eventTemplates(ctx context.Context, tenantID string) []domain.MessageTemplate {
+	enabled := true
+	templates, _, err := s.dao.ListTemplates(ctx, domain.MessageTemplateFilter{TenantID: tenantID, Enabled: &enabled})
+	if err != nil {
+		s.logger.Warn("获取消息模板失败，使用内置格式",
+			elog.String("tenant_id", tenantID),
+			elog.FieldErr(err))
+		return nil
+	}
+	return templates
+}
+
+// renderMessage 按渠道类型套用消息模板，无匹配模板或渲染失败时返回内置格式的消息
+func (s *AlertService) renderMessage(templates []domain.MessageTemplate, channelType domain.ChannelType, data channel.TemplateData, base *channel.Message) *channel.Message {
+	tmpl := channel.SelectTemplate(templates, channelType, data.Type)
+	if tmpl == nil {
+		return base
+	}
+	msg, err := channel.RenderTemplate(*tmpl, data)
+	if err != nil {
+		s.logger.Warn("渲染消息模板失败，使用内置格式",
+			elog.Int64("template_id", tmpl.ID),
+			elog.String("channel_type", string(channelType)),
+			elog.FieldErr(err))
+		return base
+	}
+	msg.Event = base.Event
+	return msg
+}
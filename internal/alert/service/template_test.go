@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/channel"
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTemplate_Validate(t *testing.T) {
+	d := newMockAlertDAO()
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	_, err := svc.CreateTemplate(ctx, domain.MessageTemplate{Name: "bad", Content: "{{.Title"})
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+	_, err = svc.CreateTemplate(ctx, domain.MessageTemplate{Content: "{{.Title}}"})
+	assert.ErrorIs(t, err, ErrInvalidTemplate, "名称不能为空")
+
+	id, err := svc.CreateTemplate(ctx, domain.MessageTemplate{Name: "ok", Title: "{{.Title}}", TenantID: "t1", Enabled: true})
+	require.NoError(t, err)
+	_, err = svc.GetTemplate(ctx, "t2", id)
+	assert.ErrorIs(t, err, ErrInvalidTemplate, "不能读取其他租户的模板")
+}
+
+func TestPreviewTemplate(t *testing.T) {
+	d := newMockAlertDAO(testRule())
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+	tmpl := domain.MessageTemplate{Title: "{{.Title}} @{{.Resource}}", Content: "{{.DefaultContent}}", TenantID: "t1"}
+
+	msg, err := svc.PreviewTemplate(ctx, tmpl, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "示例告警 @i-sample", msg.Title)
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	msg, err = svc.PreviewTemplate(ctx, tmpl, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "资源即将过期 @i-1", msg.Title)
+	assert.Equal(t, svc.buildMessage(d.events[0]).Content, msg.Content)
+
+	tmpl.TenantID = "t2"
+	_, err = svc.PreviewTemplate(ctx, tmpl, 1)
+	assert.ErrorIs(t, err, ErrEventNotFound)
+}
+
+func TestSendEvent_Template(t *testing.T) {
+	var payload channel.WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &payload)
+	}))
+	defer srv.Close()
+
+	d := newMockAlertDAO(testRule())
+	d.channels = []domain.NotificationChannel{
+		{ID: 10, Type: domain.ChannelWebhook, Enabled: true, TenantID: "t1", Config: map[string]any{"url": srv.URL}},
+	}
+	svc, _ := newTestService(d)
+	ctx := context.Background()
+
+	// 只有匹配渠道类型的模板生效
+	_, err := svc.CreateTemplate(ctx, domain.MessageTemplate{
+		Name: "slack", ChannelType: domain.ChannelSlack, Title: "slack {{.Title}}", TenantID: "t1", Enabled: true,
+	})
+	require.NoError(t, err)
+	_, err = svc.CreateTemplate(ctx, domain.MessageTemplate{
+		Name: "webhook", ChannelType: domain.ChannelWebhook, AlertType: domain.AlertTypeExpiration,
+		Title: "{{upper .Severity}} {{.Content.asset_id}}", TenantID: "t1", Enabled: true,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.EmitEvent(ctx, testEvent("i-1")))
+	require.NoError(t, svc.ProcessPendingEvents(ctx))
+
+	assert.Equal(t, "WARNING i-1", payload.Title)
+	assert.Equal(t, svc.buildMessage(d.events[0]).Content, payload.Content, "正文模板为空时沿用内置正文")
+	require.NotNil(t, payload.Event)
+	assert.Equal(t, d.events[0].Fingerprint, payload.Event.Fingerprint)
+	assert.Equal(t, domain.EventStatusSent, d.events[0].Status)
+}
@@ -2,11 +2,16 @@
 package web
 
 import (
+	"errors"
+	"io"
 	"strconv"
+	"time"
 
+	"github.com/Havens-blog/e-cam-service/internal/alert/condition"
 	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
 	"github.com/Havens-blog/e-cam-service/internal/alert/service"
 	"github.com/Havens-blog/e-cam-service/internal/cam/middleware"
+	sharedmw "github.com/Havens-blog/e-cam-service/internal/shared/middleware"
 	"github.com/gin-gonic/gin"
 	"github.com/gotomicro/ego/core/elog"
 )
@@ -29,6 +34,7 @@ func (h *AlertHandler) RegisterRoutes(r *gin.RouterGroup) {
 		// 告警规则
 		rules := alert.Group("/rules")
 		rules.POST("", h.CreateRule)
+		rules.POST("/dry-run", h.DryRunRule)
 		rules.GET("", h.ListRules)
 		rules.GET("/:id", h.GetRule)
 		rules.PUT("/:id", h.UpdateRule)
@@ -38,6 +44,11 @@ func (h *AlertHandler) RegisterRoutes(r *gin.RouterGroup) {
 		// 告警事件
 		events := alert.Group("/events")
 		events.GET("", h.ListEvents)
+		events.GET("/:id", h.GetEvent)
+		events.PUT("/:id/ack", h.AckEvent)
+		events.PUT("/:id/assign", h.AssignEvent)
+		events.PUT("/:id/resolve", h.ResolveEvent)
+		events.POST("/:id/comments", h.CommentEvent)
 
 		// 通知渠道
 		channels := alert.Group("/channels")
@@ -47,6 +58,22 @@ func (h *AlertHandler) RegisterRoutes(r *gin.RouterGroup) {
 		channels.PUT("/:id", h.UpdateChannel)
 		channels.DELETE("/:id", h.DeleteChannel)
 		channels.POST("/:id/test", h.TestChannel)
+
+		// 消息模板
+		templates := alert.Group("/templates")
+		templates.POST("", h.CreateTemplate)
+		templates.POST("/preview", h.PreviewTemplate)
+		templates.GET("", h.ListTemplates)
+		templates.GET("/:id", h.GetTemplate)
+		templates.PUT("/:id", h.UpdateTemplate)
+		templates.DELETE("/:id", h.DeleteTemplate)
+
+		// 投递记录与死信
+		deliveries := alert.Group("/deliveries")
+		deliveries.GET("", h.ListDeliveries)
+		deliveries.GET("/stats", h.DeliveryStats)
+		deliveries.POST("/redrive", h.RedriveDeliveries)
+		deliveries.POST("/:id/redrive", h.RedriveDelivery)
 	}
 }
 
@@ -81,12 +108,15 @@ func (h *AlertHandler) CreateRule(c *gin.Context) {
 		SilenceDuration:  req.SilenceDuration,
 		EscalateAfter:    req.EscalateAfter,
 		EscalateChannels: req.EscalateChannels,
+		AutoResolveAfter: req.AutoResolveAfter,
+		NodeID:           req.NodeID,
+		AckTimeout:       req.AckTimeout,
 		TenantID:         tenantID,
 	}
 
 	id, err := h.alertService.CreateRule(c.Request.Context(), rule)
 	if err != nil {
-		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		c.JSON(ruleErrorStatus(err), gin.H{"code": ruleErrorStatus(err), "msg": err.Error()})
 		return
 	}
 
@@ -181,10 +211,13 @@ func (h *AlertHandler) UpdateRule(c *gin.Context) {
 		SilenceDuration:  req.SilenceDuration,
 		EscalateAfter:    req.EscalateAfter,
 		EscalateChannels: req.EscalateChannels,
+		AutoResolveAfter: req.AutoResolveAfter,
+		NodeID:           req.NodeID,
+		AckTimeout:       req.AckTimeout,
 	}
 
 	if err := h.alertService.UpdateRule(c.Request.Context(), rule); err != nil {
-		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		c.JSON(ruleErrorStatus(err), gin.H{"code": ruleErrorStatus(err), "msg": err.Error()})
 		return
 	}
 
@@ -245,6 +278,48 @@ func (h *AlertHandler) ToggleRule(c *gin.Context) {
 	c.JSON(200, gin.H{"code": 0, "msg": "success"})
 }
 
+// DryRunRule 规则试运行
+// @Summary 规则试运行（用草稿规则回放最近的告警事件，不落库）
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param body body DryRunRuleReq true "草稿规则"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/rules/dry-run [post]
+func (h *AlertHandler) DryRunRule(c *gin.Context) {
+	var req DryRunRuleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	rule := domain.AlertRule{
+		Type:          domain.AlertType(req.Type),
+		Condition:     req.Condition,
+		AccountIDs:    req.AccountIDs,
+		ResourceTypes: req.ResourceTypes,
+		Regions:       req.Regions,
+		TenantID:      middleware.GetTenantID(c),
+	}
+
+	result, err := h.alertService.DryRunRule(c.Request.Context(), rule, req.Limit)
+	if err != nil {
+		c.JSON(ruleErrorStatus(err), gin.H{"code": ruleErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": result})
+}
+
+// ruleErrorStatus 条件表达式无效返回 400，其余为 500
+func ruleErrorStatus(err error) int {
+	if errors.Is(err, condition.ErrInvalidCondition) {
+		return 400
+	}
+	return 500
+}
+
 // ========== 告警事件 ==========
 
 // ListEvents 查询告警事件列表
@@ -255,6 +330,8 @@ func (h *AlertHandler) ToggleRule(c *gin.Context) {
 // @Param type query string false "告警类型"
 // @Param severity query string false "告警级别"
 // @Param status query string false "事件状态"
+// @Param rule_id query int false "规则ID"
+// @Param fingerprint query string false "告警指纹"
 // @Param offset query int false "偏移量"
 // @Param limit query int false "限制数量"
 // @Success 200 {object} Result
@@ -263,12 +340,14 @@ func (h *AlertHandler) ListEvents(c *gin.Context) {
 	tenantID := middleware.GetTenantID(c)
 
 	filter := domain.AlertEventFilter{
-		TenantID: tenantID,
-		Type:     domain.AlertType(c.Query("type")),
-		Severity: domain.Severity(c.Query("severity")),
-		Status:   domain.EventStatus(c.Query("status")),
-		Offset:   parseIntDefault(c.Query("offset"), 0),
-		Limit:    parseIntDefault(c.Query("limit"), 20),
+		TenantID:    tenantID,
+		Type:        domain.AlertType(c.Query("type")),
+		Severity:    domain.Severity(c.Query("severity")),
+		Status:      domain.EventStatus(c.Query("status")),
+		RuleID:      parseIntDefault(c.Query("rule_id"), 0),
+		Fingerprint: c.Query("fingerprint"),
+		Offset:      parseIntDefault(c.Query("offset"), 0),
+		Limit:       parseIntDefault(c.Query("limit"), 20),
 	}
 
 	events, total, err := h.alertService.ListEvents(c.Request.Context(), filter)
@@ -280,6 +359,152 @@ func (h *AlertHandler) ListEvents(c *gin.Context) {
 	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"items": events, "total": total}})
 }
 
+// GetEvent 获取告警事件详情
+// @Summary 获取告警事件详情（含处理时间线）
+// @Tags 告警管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "事件ID"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/events/{id} [get]
+func (h *AlertHandler) GetEvent(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	event, err := h.alertService.GetEvent(c.Request.Context(), middleware.GetTenantID(c), id)
+	if err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"code": eventErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": event})
+}
+
+// AckEvent 确认告警事件
+// @Summary 确认告警事件（停止超时重新通知，未指派时指派给确认人）
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "事件ID"
+// @Param body body EventActionReq false "备注"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/events/{id}/ack [put]
+func (h *AlertHandler) AckEvent(c *gin.Context) {
+	id, req, ok := bindEventAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.AckEvent(c.Request.Context(), middleware.GetTenantID(c), id, sharedmw.GetUsername(c), req.Comment); err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"code": eventErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// AssignEvent 指派告警事件处理人
+// @Summary 指派告警事件处理人
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "事件ID"
+// @Param body body EventActionReq true "处理人与备注"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/events/{id}/assign [put]
+func (h *AlertHandler) AssignEvent(c *gin.Context) {
+	id, req, ok := bindEventAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.AssignEvent(c.Request.Context(), middleware.GetTenantID(c), id, req.Assignee, sharedmw.GetUsername(c), req.Comment); err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"code": eventErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// ResolveEvent 手动恢复告警事件
+// @Summary 手动恢复告警事件（同一指纹下未恢复的事件一并恢复）
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "事件ID"
+// @Param body body EventActionReq false "备注"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/events/{id}/resolve [put]
+func (h *AlertHandler) ResolveEvent(c *gin.Context) {
+	id, req, ok := bindEventAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.ResolveEventByID(c.Request.Context(), middleware.GetTenantID(c), id, sharedmw.GetUsername(c), req.Comment); err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"code": eventErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// CommentEvent 评论告警事件
+// @Summary 评论告警事件（记入事件时间线）
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "事件ID"
+// @Param body body EventActionReq true "评论内容"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/events/{id}/comments [post]
+func (h *AlertHandler) CommentEvent(c *gin.Context) {
+	id, req, ok := bindEventAction(c)
+	if !ok {
+		return
+	}
+
+	if err := h.alertService.CommentEvent(c.Request.Context(), middleware.GetTenantID(c), id, sharedmw.GetUsername(c), req.Comment); err != nil {
+		c.JSON(eventErrorStatus(err), gin.H{"code": eventErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// bindEventAction 解析事件ID与可选的请求体，失败时已写入响应
+func bindEventAction(c *gin.Context) (int64, EventActionReq, bool) {
+	var req EventActionReq
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return 0, req, false
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return 0, req, false
+	}
+	return id, req, true
+}
+
+// eventErrorStatus 事件不存在返回 404，状态不允许的操作返回 400，其余为 500
+func eventErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrEventNotFound):
+		return 404
+	case errors.Is(err, service.ErrInvalidEventOperation):
+		return 400
+	}
+	return 500
+}
+
 // ========== 通知渠道 ==========
 
 // CreateChannel 创建通知渠道
@@ -453,6 +678,328 @@ func (h *AlertHandler) TestChannel(c *gin.Context) {
 	c.JSON(200, gin.H{"code": 0, "msg": "success"})
 }
 
+// ========== 消息模板 ==========
+
+// CreateTemplate 创建消息模板
+// @Summary 创建消息模板（Go text/template 语法，按渠道类型与告警类型匹配）
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param body body MessageTemplateReq true "消息模板"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/templates [post]
+func (h *AlertHandler) CreateTemplate(c *gin.Context) {
+	var req MessageTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	tmpl := toMessageTemplate(req)
+	tmpl.TenantID = middleware.GetTenantID(c)
+
+	id, err := h.alertService.CreateTemplate(c.Request.Context(), tmpl)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"code": templateErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"id": id}})
+}
+
+// ListTemplates 查询消息模板列表
+// @Summary 查询消息模板列表
+// @Tags 告警管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param channel_type query string false "渠道类型"
+// @Param alert_type query string false "告警类型"
+// @Param offset query int false "偏移量"
+// @Param limit query int false "限制数量"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/templates [get]
+func (h *AlertHandler) ListTemplates(c *gin.Context) {
+	filter := domain.MessageTemplateFilter{
+		TenantID:    middleware.GetTenantID(c),
+		ChannelType: domain.ChannelType(c.Query("channel_type")),
+		AlertType:   domain.AlertType(c.Query("alert_type")),
+		Offset:      parseIntDefault(c.Query("offset"), 0),
+		Limit:       parseIntDefault(c.Query("limit"), 20),
+	}
+
+	templates, total, err := h.alertService.ListTemplates(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"items": templates, "total": total}})
+}
+
+// GetTemplate 获取消息模板详情
+// @Summary 获取消息模板详情
+// @Tags 告警管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "模板ID"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/templates/{id} [get]
+func (h *AlertHandler) GetTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	tmpl, err := h.alertService.GetTemplate(c.Request.Context(), middleware.GetTenantID(c), id)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"code": templateErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": tmpl})
+}
+
+// UpdateTemplate 更新消息模板
+// @Summary 更新消息模板
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "模板ID"
+// @Param body body MessageTemplateReq true "消息模板"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/templates/{id} [put]
+func (h *AlertHandler) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	var req MessageTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	tmpl := toMessageTemplate(req)
+	tmpl.ID = id
+	tmpl.TenantID = middleware.GetTenantID(c)
+
+	if err := h.alertService.UpdateTemplate(c.Request.Context(), tmpl); err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"code": templateErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// DeleteTemplate 删除消息模板
+// @Summary 删除消息模板
+// @Tags 告警管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "模板ID"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/templates/{id} [delete]
+func (h *AlertHandler) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	if err := h.alertService.DeleteTemplate(c.Request.Context(), middleware.GetTenantID(c), id); err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"code": templateErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// PreviewTemplate 预览消息模板
+// @Summary 预览消息模板（指定 event_id 时用该事件渲染，否则用示例事件，不落库）
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param body body PreviewTemplateReq true "草稿模板"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/templates/preview [post]
+func (h *AlertHandler) PreviewTemplate(c *gin.Context) {
+	var req PreviewTemplateReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	tmpl := domain.MessageTemplate{
+		ChannelType: domain.ChannelType(req.ChannelType),
+		AlertType:   domain.AlertType(req.AlertType),
+		Title:       req.Title,
+		Content:     req.Content,
+		TenantID:    middleware.GetTenantID(c),
+	}
+
+	msg, err := h.alertService.PreviewTemplate(c.Request.Context(), tmpl, req.EventID)
+	if err != nil {
+		c.JSON(templateErrorStatus(err), gin.H{"code": templateErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"title": msg.Title, "content": msg.Content}})
+}
+
+func toMessageTemplate(req MessageTemplateReq) domain.MessageTemplate {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return domain.MessageTemplate{
+		Name:        req.Name,
+		ChannelType: domain.ChannelType(req.ChannelType),
+		AlertType:   domain.AlertType(req.AlertType),
+		Title:       req.Title,
+		Content:     req.Content,
+		Enabled:     enabled,
+	}
+}
+
+// templateErrorStatus 模板无效返回 400，预览的事件不存在返回 404，其余为 500
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidTemplate):
+		return 400
+	case errors.Is(err, service.ErrEventNotFound):
+		return 404
+	}
+	return 500
+}
+
+// ========== 投递记录与死信 ==========
+
+// ListDeliveries 查询告警投递记录
+// @Summary 查询告警投递记录（status=dead 为死信列表）
+// @Tags 告警管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param event_id query int false "事件ID"
+// @Param channel_id query int false "渠道ID"
+// @Param status query string false "投递状态: pending, retrying, sent, dead, canceled"
+// @Param offset query int false "偏移量"
+// @Param limit query int false "限制数量"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/deliveries [get]
+func (h *AlertHandler) ListDeliveries(c *gin.Context) {
+	filter := domain.DeliveryFilter{
+		TenantID:  middleware.GetTenantID(c),
+		EventID:   parseIntDefault(c.Query("event_id"), 0),
+		ChannelID: parseIntDefault(c.Query("channel_id"), 0),
+		Status:    domain.DeliveryStatus(c.Query("status")),
+		Offset:    parseIntDefault(c.Query("offset"), 0),
+		Limit:     parseIntDefault(c.Query("limit"), 20),
+	}
+
+	deliveries, total, err := h.alertService.ListDeliveries(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"items": deliveries, "total": total}})
+}
+
+// RedriveDelivery 重投死信
+// @Summary 重投死信（重置尝试次数后重新进入投递队列）
+// @Tags 告警管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "投递ID"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/deliveries/{id}/redrive [post]
+func (h *AlertHandler) RedriveDelivery(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	delivery, err := h.alertService.RedriveDelivery(c.Request.Context(), middleware.GetTenantID(c), id)
+	if err != nil {
+		c.JSON(deliveryErrorStatus(err), gin.H{"code": deliveryErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": delivery})
+}
+
+// RedriveDeliveries 批量重投死信
+// @Summary 批量重投死信（按投递ID或渠道，非死信跳过）
+// @Tags 告警管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param body body RedriveDeliveriesReq true "重投范围"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/deliveries/redrive [post]
+func (h *AlertHandler) RedriveDeliveries(c *gin.Context) {
+	var req RedriveDeliveriesReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	if len(req.IDs) == 0 && req.ChannelID <= 0 {
+		c.JSON(400, gin.H{"code": 400, "msg": "ids 与 channel_id 不能同时为空"})
+		return
+	}
+
+	redriven, err := h.alertService.RedriveDeliveries(c.Request.Context(), middleware.GetTenantID(c), req.IDs, req.ChannelID)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"redriven": redriven}})
+}
+
+// DeliveryStats 告警投递指标
+// @Summary 告警投递指标（按渠道统计送达、重试、死信、限流次数、成功率与平均送达耗时）
+// @Tags 告警管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param hours query int false "统计最近多少小时，默认 24，最大 720"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/deliveries/stats [get]
+func (h *AlertHandler) DeliveryStats(c *gin.Context) {
+	hours := parseIntDefault(c.Query("hours"), 24)
+	if hours <= 0 || hours > 720 {
+		c.JSON(400, gin.H{"code": 400, "msg": "hours 取值范围为 1-720"})
+		return
+	}
+
+	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+	stats, err := h.alertService.DeliveryStats(c.Request.Context(), middleware.GetTenantID(c), since)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": stats})
+}
+
+// deliveryErrorStatus 投递记录不存在返回 404，非死信重投返回 400，其余为 500
+func deliveryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrDeliveryNotFound):
+		return 404
+	case errors.Is(err, service.ErrInvalidDeliveryOperation):
+		return 400
+	}
+	return 500
+}
+
 // ========== 辅助函数 ==========
 
 func parseIntDefault(s string, defaultVal int64) int64 {
@@ -0,0 +1,309 @@
+package web
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	"github.com/Havens-blog/e-cam-service/internal/alert/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// defaultShiftDays 班次预览默认天数
+const defaultShiftDays = 14
+
+// OnCallHandler 值班管理处理器
+type OnCallHandler struct {
+	onCallService *service.OnCallService
+	logger        *elog.Component
+}
+
+// NewOnCallHandler 创建值班管理处理器
+func NewOnCallHandler(onCallService *service.OnCallService, logger *elog.Component) *OnCallHandler {
+	return &OnCallHandler{onCallService: onCallService, logger: logger}
+}
+
+// RegisterRoutes 注册路由
+func (h *OnCallHandler) RegisterRoutes(r *gin.RouterGroup) {
+	oncall := r.Group("/alert/oncall")
+	{
+		schedules := oncall.Group("/schedules")
+		schedules.POST("", h.CreateSchedule)
+		schedules.GET("", h.ListSchedules)
+		schedules.GET("/:id", h.GetSchedule)
+		schedules.PUT("/:id", h.UpdateSchedule)
+		schedules.DELETE("/:id", h.DeleteSchedule)
+		schedules.POST("/:id/overrides", h.AddOverride)
+		schedules.GET("/:id/shifts", h.ListShifts)
+
+		oncall.GET("/current", h.CurrentOnCall)
+	}
+}
+
+// CreateSchedule 创建值班表
+// @Summary 创建值班表
+// @Tags 值班管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param body body OnCallScheduleReq true "值班表"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/schedules [post]
+func (h *OnCallHandler) CreateSchedule(c *gin.Context) {
+	var req OnCallScheduleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	schedule := toOnCallSchedule(req)
+	schedule.TenantID = middleware.GetTenantID(c)
+
+	id, err := h.onCallService.CreateSchedule(c.Request.Context(), schedule)
+	if err != nil {
+		c.JSON(scheduleErrorStatus(err), gin.H{"code": scheduleErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"id": id}})
+}
+
+// ListSchedules 查询值班表列表
+// @Summary 查询值班表列表
+// @Tags 值班管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param node_id query int false "服务树节点ID"
+// @Param offset query int false "偏移量"
+// @Param limit query int false "限制数量"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/schedules [get]
+func (h *OnCallHandler) ListSchedules(c *gin.Context) {
+	filter := domain.OnCallScheduleFilter{
+		TenantID: middleware.GetTenantID(c),
+		Offset:   parseIntDefault(c.Query("offset"), 0),
+		Limit:    parseIntDefault(c.Query("limit"), 20),
+	}
+	if v := c.Query("node_id"); v != "" {
+		nodeID := parseIntDefault(v, 0)
+		filter.NodeID = &nodeID
+	}
+
+	schedules, total, err := h.onCallService.ListSchedules(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": gin.H{"items": schedules, "total": total}})
+}
+
+// GetSchedule 获取值班表详情
+// @Summary 获取值班表详情
+// @Tags 值班管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "值班表ID"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/schedules/{id} [get]
+func (h *OnCallHandler) GetSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	schedule, err := h.onCallService.GetSchedule(c.Request.Context(), middleware.GetTenantID(c), id)
+	if err != nil {
+		c.JSON(scheduleErrorStatus(err), gin.H{"code": scheduleErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": schedule})
+}
+
+// UpdateSchedule 更新值班表
+// @Summary 更新值班表
+// @Tags 值班管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "值班表ID"
+// @Param body body OnCallScheduleReq true "值班表"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/schedules/{id} [put]
+func (h *OnCallHandler) UpdateSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	var req OnCallScheduleReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	schedule := toOnCallSchedule(req)
+	schedule.ID = id
+	schedule.TenantID = middleware.GetTenantID(c)
+
+	if err := h.onCallService.UpdateSchedule(c.Request.Context(), schedule); err != nil {
+		c.JSON(scheduleErrorStatus(err), gin.H{"code": scheduleErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// DeleteSchedule 删除值班表
+// @Summary 删除值班表
+// @Tags 值班管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "值班表ID"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/schedules/{id} [delete]
+func (h *OnCallHandler) DeleteSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	if err := h.onCallService.DeleteSchedule(c.Request.Context(), middleware.GetTenantID(c), id); err != nil {
+		c.JSON(scheduleErrorStatus(err), gin.H{"code": scheduleErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// AddOverride 添加临时替班
+// @Summary 添加临时替班（替班期间覆盖轮换安排）
+// @Tags 值班管理
+// @Accept json
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "值班表ID"
+// @Param body body OnCallOverrideReq true "替班"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/schedules/{id}/overrides [post]
+func (h *OnCallHandler) AddOverride(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	var req OnCallOverrideReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	if err := h.onCallService.AddOverride(c.Request.Context(), middleware.GetTenantID(c), id, toOnCallOverride(req)); err != nil {
+		c.JSON(scheduleErrorStatus(err), gin.H{"code": scheduleErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success"})
+}
+
+// ListShifts 预览值班班次
+// @Summary 预览值班班次
+// @Tags 值班管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param id path int true "值班表ID"
+// @Param start query string false "开始日期 2006-01-02，默认今天"
+// @Param end query string false "结束日期 2006-01-02（不含），默认开始日期后14天"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/schedules/{id}/shifts [get]
+func (h *OnCallHandler) ListShifts(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"code": 400, "msg": "invalid id"})
+		return
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if v := c.Query("start"); v != "" {
+		if start, err = time.ParseInLocation("2006-01-02", v, now.Location()); err != nil {
+			c.JSON(400, gin.H{"code": 400, "msg": "invalid start"})
+			return
+		}
+	}
+	end := start.AddDate(0, 0, defaultShiftDays)
+	if v := c.Query("end"); v != "" {
+		if end, err = time.ParseInLocation("2006-01-02", v, now.Location()); err != nil {
+			c.JSON(400, gin.H{"code": 400, "msg": "invalid end"})
+			return
+		}
+	}
+
+	shifts, err := h.onCallService.ListShifts(c.Request.Context(), middleware.GetTenantID(c), id, start, end)
+	if err != nil {
+		c.JSON(scheduleErrorStatus(err), gin.H{"code": scheduleErrorStatus(err), "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": shifts})
+}
+
+// CurrentOnCall 查询当前值班人
+// @Summary 查询服务树节点当前值班人（节点未配置时向上继承）
+// @Tags 值班管理
+// @Produce json
+// @Param X-Tenant-ID header string true "租户ID"
+// @Param node_id query int false "服务树节点ID，默认租户默认值班表"
+// @Success 200 {object} Result
+// @Router /api/v1/cam/alert/oncall/current [get]
+func (h *OnCallHandler) CurrentOnCall(c *gin.Context) {
+	nodeID := parseIntDefault(c.Query("node_id"), 0)
+
+	shift, err := h.onCallService.CurrentOnCall(c.Request.Context(), middleware.GetTenantID(c), nodeID, time.Now())
+	if err != nil {
+		c.JSON(500, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(200, gin.H{"code": 0, "msg": "success", "data": shift})
+}
+
+func toOnCallSchedule(req OnCallScheduleReq) domain.OnCallSchedule {
+	schedule := domain.OnCallSchedule{
+		Name:      req.Name,
+		NodeID:    req.NodeID,
+		Rotation:  domain.RotationType(req.Rotation),
+		StartTime: req.StartTime,
+	}
+	for _, m := range req.Members {
+		schedule.Members = append(schedule.Members, toOnCallMember(m))
+	}
+	for _, o := range req.Overrides {
+		schedule.Overrides = append(schedule.Overrides, toOnCallOverride(o))
+	}
+	return schedule
+}
+
+func toOnCallOverride(req OnCallOverrideReq) domain.OnCallOverride {
+	return domain.OnCallOverride{Member: toOnCallMember(req.Member), Start: req.Start, End: req.End, Reason: req.Reason}
+}
+
+func toOnCallMember(req OnCallMemberReq) domain.OnCallMember {
+	return domain.OnCallMember{UserID: req.UserID, Name: req.Name, ChannelIDs: req.ChannelIDs}
+}
+
+// scheduleErrorStatus 值班表配置无效返回 400，其余为 500
+func scheduleErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidSchedule) {
+		return 400
+	}
+	return 500
+}
@@ -1,10 +1,12 @@
 package web
 
+import "time"
+
 // CreateRuleReq 创建告警规则请求
 type CreateRuleReq struct {
 	Name             string         `json:"name" binding:"required"`
 	Type             string         `json:"type" binding:"required"` // resource_change, sync_failure, expiration, security_group
-	Condition        map[string]any `json:"condition"`
+	Condition        map[string]any `json:"condition"`               // {"expr": "<条件表达式>"}，语法见 alert/condition
 	ChannelIDs       []int64        `json:"channel_ids" binding:"required"`
 	AccountIDs       []int64        `json:"account_ids"`
 	ResourceTypes    []string       `json:"resource_types"`
@@ -12,6 +14,25 @@ type CreateRuleReq struct {
 	SilenceDuration  int            `json:"silence_duration"`
 	EscalateAfter    int            `json:"escalate_after"`
 	EscalateChannels []int64        `json:"escalate_channels"`
+	AutoResolveAfter int            `json:"auto_resolve_after"` // 持续N分钟未再触发则自动恢复
+	NodeID           int64          `json:"node_id"`            // 服务树节点，用于匹配值班表，0 为租户默认值班表
+	AckTimeout       int            `json:"ack_timeout"`        // 发送后N分钟未确认则重新通知
+}
+
+// DryRunRuleReq 规则试运行请求：用草稿规则回放最近的告警事件
+type DryRunRuleReq struct {
+	Type          string         `json:"type"`      // 为空时回放所有类型
+	Condition     map[string]any `json:"condition"` // {"expr": "severity >= warning && tags.env == \"prod\""}
+	AccountIDs    []int64        `json:"account_ids"`
+	ResourceTypes []string       `json:"resource_types"`
+	Regions       []string       `json:"regions"`
+	Limit         int64          `json:"limit"` // 回放最近N条事件，默认100，最多1000
+}
+
+// EventActionReq 告警事件处理请求（确认/指派/恢复/评论）
+type EventActionReq struct {
+	Assignee string `json:"assignee"` // 指派时必填
+	Comment  string `json:"comment"`  // 评论时必填，其余操作可选
 }
 
 // ToggleRuleReq 启用/禁用告警规则请求
@@ -22,13 +43,63 @@ type ToggleRuleReq struct {
 // CreateChannelReq 创建通知渠道请求
 type CreateChannelReq struct {
 	Name   string         `json:"name" binding:"required"`
-	Type   string         `json:"type" binding:"required"` // dingtalk, wecom, feishu, email
+	Type   string         `json:"type" binding:"required"` // dingtalk, wecom, feishu, email, webhook, slack, incident
 	Config map[string]any `json:"config" binding:"required"`
 }
 
+// MessageTemplateReq 创建/更新消息模板请求
+type MessageTemplateReq struct {
+	Name        string `json:"name" binding:"required"`
+	ChannelType string `json:"channel_type"` // 为空时匹配全部渠道类型
+	AlertType   string `json:"alert_type"`   // 为空时匹配全部告警类型
+	Title       string `json:"title"`        // 标题模板，如 "[{{upper .Severity}}] {{.Title}}"
+	Content     string `json:"content"`      // 正文模板，可用 {{.DefaultContent}} 引用内置正文
+	Enabled     *bool  `json:"enabled"`      // 默认启用
+}
+
+// PreviewTemplateReq 预览消息模板请求
+type PreviewTemplateReq struct {
+	ChannelType string `json:"channel_type"`
+	AlertType   string `json:"alert_type"`
+	Title       string `json:"title"`
+	Content     string `json:"content"`
+	EventID     int64  `json:"event_id"` // 用指定事件渲染，为空时使用示例事件
+}
+
+// RedriveDeliveriesReq 批量重投死信请求
+type RedriveDeliveriesReq struct {
+	IDs       []int64 `json:"ids"`
+	ChannelID int64   `json:"channel_id"` // 重投该渠道的全部死信，用于修复渠道配置后
+}
+
 // Result 统一响应
 type Result struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg"`
 	Data any    `json:"data,omitempty"`
 }
+
+// OnCallMemberReq 值班成员
+type OnCallMemberReq struct {
+	UserID     string  `json:"user_id" binding:"required"`
+	Name       string  `json:"name"`
+	ChannelIDs []int64 `json:"channel_ids"` // 个人通知渠道，值班期间告警额外发送
+}
+
+// OnCallScheduleReq 创建/更新值班表请求
+type OnCallScheduleReq struct {
+	Name      string              `json:"name" binding:"required"`
+	NodeID    int64               `json:"node_id"`                       // 服务树节点，0 为租户默认值班表
+	Rotation  string              `json:"rotation" binding:"required"`   // daily, weekly
+	Members   []OnCallMemberReq   `json:"members" binding:"required"`    // 按顺序轮换
+	StartTime time.Time           `json:"start_time" binding:"required"` // 第一个班次开始时间
+	Overrides []OnCallOverrideReq `json:"overrides"`
+}
+
+// OnCallOverrideReq 临时替班请求
+type OnCallOverrideReq struct {
+	Member OnCallMemberReq `json:"member" binding:"required"`
+	Start  time.Time       `json:"start" binding:"required"`
+	End    time.Time       `json:"end" binding:"required"`
+	Reason string          `json:"reason"`
+}
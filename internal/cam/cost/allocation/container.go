@@ -0,0 +1,116 @@
+package allocation
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// ContainerSplitter 容器成本拆分接口（可选注入）
+// 返回账期内各 K8s 节点（按云主机资源 ID）的成本拆分份额；命中的节点账单不再走分摊规则，
+// 按份额拆分到 集群/命名空间/工作负载，未被 Pod 占用的容量计入集群空闲桶
+type ContainerSplitter interface {
+	SplitPlan(ctx context.Context, tenantID, period string) (map[string][]costdomain.ContainerShare, error)
+}
+
+// SetContainerSplitter 设置容器成本拆分（可选注入）
+func (s *AllocationService) SetContainerSplitter(splitter ContainerSplitter) {
+	s.containerSplitter = splitter
+}
+
+// resolveContainerShares 获取账期的容器拆分份额，失败时记录日志并按普通账单分摊
+func (s *AllocationService) resolveContainerShares(ctx context.Context, tenantID, period string) map[string][]costdomain.ContainerShare {
+	if s.containerSplitter == nil {
+		return nil
+	}
+	plan, err := s.containerSplitter.SplitPlan(ctx, tenantID, period)
+	if err != nil {
+		s.logger.Warn("resolve container split plan failed, k8s node bills fall back to rules",
+			elog.String("tenant_id", tenantID),
+			elog.String("period", period),
+			elog.FieldErr(err))
+		return nil
+	}
+	return plan
+}
+
+// allocateContainerShares 将 K8s 节点账单按份额拆分为容器分摊结果
+func (s *AllocationService) allocateContainerShares(bill costdomain.UnifiedBill, billAmount float64, shares []costdomain.ContainerShare, period string, now int64) []costdomain.CostAllocation {
+	allocs := make([]costdomain.CostAllocation, 0, len(shares))
+	for _, share := range shares {
+		path := containerPath(share)
+		name := share.Workload
+		if share.Idle {
+			name = "空闲容量"
+		}
+		allocs = append(allocs, costdomain.CostAllocation{
+			DimType:      costdomain.DimK8s,
+			DimValue:     path,
+			DimPath:      path,
+			Period:       period,
+			TotalAmount:  billAmount * share.Fraction,
+			SharedAmount: billAmount * share.Fraction,
+			TargetName:   name,
+			Labels:       share.Labels,
+			TenantID:     bill.TenantID,
+			CreateTime:   now,
+		})
+	}
+	return allocs
+}
+
+// containerPath 容器分摊路径：集群/命名空间/工作负载，空闲容量为 集群/__idle__
+func containerPath(share costdomain.ContainerShare) string {
+	if share.Idle {
+		return share.Cluster + "/" + costdomain.K8sIdleWorkload
+	}
+	return share.Cluster + "/" + share.Namespace + "/" + share.Workload
+}
+
+// buildPathTree 按 DimPath 逐级构建树并向上汇总金额（用于容器分摊，路径前缀作为节点 ID 避免跨集群重名）
+func (s *AllocationService) buildPathTree(allocs []costdomain.CostAllocation, rootID, dimType string) *AllocationTreeNode {
+	root := &AllocationTreeNode{
+		NodeID:   "",
+		NodeName: "全部",
+		DimType:  dimType,
+		Currency: allocCurrency(allocs),
+	}
+	nodes := map[string]*AllocationTreeNode{"": root}
+
+	for _, alloc := range allocs {
+		path := alloc.DimPath
+		if path == "" {
+			path = alloc.DimValue
+		}
+		root.TotalAmount += alloc.TotalAmount
+		parent := root
+		parts := strings.Split(path, "/")
+		for i := range parts {
+			id := strings.Join(parts[:i+1], "/")
+			node, ok := nodes[id]
+			if !ok {
+				node = &AllocationTreeNode{NodeID: id, NodeName: parts[i], DimType: dimType}
+				if parts[i] == costdomain.K8sIdleWorkload {
+					node.NodeName = "空闲容量"
+				}
+				nodes[id] = node
+				parent.Children = append(parent.Children, node)
+			}
+			node.TotalAmount += alloc.TotalAmount
+			parent = node
+		}
+	}
+
+	for _, node := range nodes {
+		sort.Slice(node.Children, func(i, j int) bool { return node.Children[i].NodeID < node.Children[j].NodeID })
+	}
+	if node, ok := nodes[rootID]; ok && rootID != "" {
+		node.Currency = root.Currency
+		return node
+	}
+	root.NodeID = rootID
+	return root
+}
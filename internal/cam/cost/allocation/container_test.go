@@ -0,0 +1,103 @@
+package allocation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticSplitter struct {
+	plan map[string][]costdomain.ContainerShare
+	err  error
+}
+
+func (s staticSplitter) SplitPlan(_ context.Context, _, _ string) (map[string][]costdomain.ContainerShare, error) {
+	return s.plan, s.err
+}
+
+func nodeSplitPlan() map[string][]costdomain.ContainerShare {
+	return map[string][]costdomain.ContainerShare{
+		"i-node1": {
+			{Cluster: "prod", NodeName: "node1", Namespace: "shop", Workload: "api", Labels: map[string]string{"team": "trade"}, Fraction: 0.5},
+			{Cluster: "prod", NodeName: "node1", Namespace: "shop", Workload: "worker", Fraction: 0.2},
+			{Cluster: "prod", NodeName: "node1", Namespace: "search", Workload: "es", Fraction: 0.1},
+			{Cluster: "prod", NodeName: "node1", Workload: costdomain.K8sIdleWorkload, Fraction: 0.2, Idle: true},
+		},
+	}
+}
+
+func TestAllocateCosts_ContainerSplit(t *testing.T) {
+	svc, allocDAO, _, inserted := setupUsageAllocation(t)
+	svc.billDAO.(*mockBillDAO).listUnifiedBillsFn = func(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+		return []costdomain.UnifiedBill{
+			{ID: 1, ResourceID: "kafka-1", AmountCNY: 1000, TenantID: "t1"},
+			{ID: 2, ResourceID: "i-node1", AmountCNY: 300, BillingDate: "2024-01-02", TenantID: "t1"},
+			{ID: 3, ResourceID: "i-node1", AmountCNY: 700, BillingDate: "2024-01-03", TenantID: "t1"},
+		}, nil
+	}
+	svc.SetContainerSplitter(staticSplitter{plan: nodeSplitPlan()})
+	ctx := context.Background()
+
+	require.NoError(t, svc.AllocateCosts(ctx, "t1", "2024-01"))
+
+	byPath := make(map[string]float64)
+	var ruleRows int
+	for _, a := range *inserted {
+		if a.DimType != costdomain.DimK8s {
+			ruleRows++
+			continue
+		}
+		assert.Equal(t, "i-node1", a.ResourceID)
+		byPath[a.DimPath] += a.TotalAmount
+	}
+	assert.Positive(t, ruleRows, "非 K8s 节点账单仍按分摊规则处理")
+	assert.InDelta(t, 500, byPath["prod/shop/api"], 1e-9)
+	assert.InDelta(t, 200, byPath["prod/shop/worker"], 1e-9)
+	assert.InDelta(t, 100, byPath["prod/search/es"], 1e-9)
+	assert.InDelta(t, 200, byPath["prod/__idle__"], 1e-9)
+
+	// 分摊树按 集群/命名空间/工作负载 逐级汇总
+	allocDAO.listAllocationsFn = func(_ context.Context, filter repository.AllocationFilter) ([]costdomain.CostAllocation, error) {
+		var result []costdomain.CostAllocation
+		for _, a := range *inserted {
+			if a.DimType == filter.DimType {
+				result = append(result, a)
+			}
+		}
+		return result, nil
+	}
+	tree, err := svc.GetAllocationTree(ctx, "t1", costdomain.DimK8s, "", "2024-01")
+	require.NoError(t, err)
+	assert.InDelta(t, 1000, tree.TotalAmount, 1e-9)
+	require.Len(t, tree.Children, 1)
+	cluster := tree.Children[0]
+	assert.Equal(t, "prod", cluster.NodeID)
+	require.Len(t, cluster.Children, 3)
+	assert.Equal(t, "prod/__idle__", cluster.Children[0].NodeID)
+	assert.Equal(t, "空闲容量", cluster.Children[0].NodeName)
+	assert.Equal(t, "prod/search", cluster.Children[1].NodeID)
+	assert.Equal(t, "prod/shop", cluster.Children[2].NodeID)
+	assert.InDelta(t, 700, cluster.Children[2].TotalAmount, 1e-9)
+	require.Len(t, cluster.Children[2].Children, 2)
+
+	sub, err := svc.GetAllocationTree(ctx, "t1", costdomain.DimK8s, "prod/shop", "2024-01")
+	require.NoError(t, err)
+	assert.Equal(t, "shop", sub.NodeName)
+	assert.InDelta(t, 700, sub.TotalAmount, 1e-9)
+}
+
+func TestAllocateCosts_ContainerSplitFailureFallsBack(t *testing.T) {
+	svc, _, _, inserted := setupUsageAllocation(t)
+	svc.SetContainerSplitter(staticSplitter{err: errors.New("provider down")})
+
+	require.NoError(t, svc.AllocateCosts(context.Background(), "t1", "2024-01"))
+	for _, a := range *inserted {
+		assert.NotEqual(t, costdomain.DimK8s, a.DimType)
+	}
+	assert.NotEmpty(t, *inserted)
+}
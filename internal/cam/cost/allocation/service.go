@@ -10,6 +10,7 @@ import (
 	"time"
 
 	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/gotomicro/ego/core/elog"
 )
@@ -52,14 +53,19 @@ type AllocationTreeNode struct {
 	NodeName    string                `json:"node_name"`
 	DimType     string                `json:"dim_type"`
 	TotalAmount float64               `json:"total_amount"`
+	Currency    string                `json:"currency,omitempty"`
 	Children    []*AllocationTreeNode `json:"children,omitempty"`
 }
 
 // AllocationService 成本分摊服务
 type AllocationService struct {
-	allocationDAO repository.AllocationDAO
-	billDAO       repository.BillDAO
-	logger        *elog.Component
+	allocationDAO     repository.AllocationDAO
+	billDAO           repository.BillDAO
+	converter         exchange.ReportingConverter
+	usageDAO          repository.UsageDAO
+	usageSources      map[string]UsageSource
+	containerSplitter ContainerSplitter
+	logger            *elog.Component
 }
 
 // NewAllocationService 创建成本分摊服务
@@ -80,6 +86,11 @@ func (s *AllocationService) Logger() *elog.Component {
 	return s.logger
 }
 
+// SetCurrencyConverter 设置报表币种换算器（可选注入，未设置时按人民币分摊）
+func (s *AllocationService) SetCurrencyConverter(converter exchange.ReportingConverter) {
+	s.converter = converter
+}
+
 // CreateAllocationRule 创建分摊规则
 func (s *AllocationService) CreateAllocationRule(ctx context.Context, rule costdomain.AllocationRule) (int64, error) {
 	if err := s.validateRule(rule); err != nil {
@@ -113,6 +124,7 @@ func (s *AllocationService) SetDefaultAllocationPolicy(ctx context.Context, poli
 }
 
 // AllocateCosts 执行成本分摊计算
+// 分摊金额按账单日期汇率折算为租户报表币种；租户切换币种后需重新分摊历史账期
 func (s *AllocationService) AllocateCosts(ctx context.Context, tenantID string, period string) error {
 	// 1. Delete existing allocations for this period
 	if err := s.allocationDAO.DeleteAllocationsByPeriod(ctx, tenantID, period); err != nil {
@@ -141,25 +153,54 @@ func (s *AllocationService) AllocateCosts(ctx context.Context, tenantID string,
 		return fmt.Errorf("list bills: %w", err)
 	}
 
+	currency, err := s.reportingCurrency(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	// 用量加权规则按账期重新计算权重
+	usageWeights := s.resolveUsageWeights(ctx, tenantID, period, rules)
+	// K8s 节点账单按容器份额拆分，优先于分摊规则
+	containerShares := s.resolveContainerShares(ctx, tenantID, period)
+
 	var allocations []costdomain.CostAllocation
 	now := time.Now().UnixMilli()
 
 	for _, bill := range bills {
+		amount, err := s.toReporting(ctx, currency, bill.AmountCNY, bill.BillingDate)
+		if err != nil {
+			return fmt.Errorf("convert bill %d: %w", bill.ID, err)
+		}
 		matched := false
 
-		for _, rule := range rules {
-			allocs := s.matchAndAllocate(bill, rule, period, now)
-			if len(allocs) > 0 {
-				allocations = append(allocations, allocs...)
-				matched = true
-				break // first matching rule wins (priority order)
+		var allocs []costdomain.CostAllocation
+		if shares, ok := containerShares[bill.ResourceID]; ok && bill.ResourceID != "" {
+			allocs = s.allocateContainerShares(bill, amount, shares, period, now)
+			matched = true
+		} else {
+			for _, rule := range rules {
+				allocs = s.matchAndAllocate(bill, amount, rule, usageWeights[rule.ID], period, now)
+				if len(allocs) > 0 {
+					matched = true
+					break // first matching rule wins (priority order)
+				}
 			}
 		}
 
 		if !matched {
-			alloc := s.createUnmatchedAllocation(bill, period, now, hasDefaultPolicy, defaultPolicy)
-			allocations = append(allocations, alloc)
+			allocs = []costdomain.CostAllocation{s.createUnmatchedAllocation(bill, amount, period, now, hasDefaultPolicy, defaultPolicy)}
 		}
+		// 记录来源资源与账单日期，供分账单统计 Top 资源、单位成本按日统计
+		for i := range allocs {
+			allocs[i].ResourceID = bill.ResourceID
+			allocs[i].ResourceName = bill.ResourceName
+			allocs[i].ServiceType = bill.ServiceType
+			allocs[i].BillingDate = bill.BillingDate
+		}
+		allocations = append(allocations, allocs...)
+	}
+	for i := range allocations {
+		allocations[i].Currency = currency
 	}
 
 	// 5. Batch insert allocations
@@ -169,6 +210,13 @@ func (s *AllocationService) AllocateCosts(ctx context.Context, tenantID string,
 		}
 	}
 
+	// 6. Persist usage weights for audit
+	if s.usageDAO != nil {
+		if err := s.usageDAO.ReplaceWeights(ctx, tenantID, period, usageWeightsList(usageWeights)); err != nil {
+			return fmt.Errorf("save usage weights: %w", err)
+		}
+	}
+
 	s.logger.Info("cost allocation completed",
 		elog.String("tenant_id", tenantID),
 		elog.String("period", period),
@@ -217,6 +265,11 @@ func (s *AllocationService) GetAllocationTree(ctx context.Context, tenantID, dim
 		return nil, fmt.Errorf("list allocations for tree: %w", err)
 	}
 
+	// 容器分摊只来自分摊结果（账单中没有集群 / 命名空间维度）
+	if dimType == costdomain.DimK8s {
+		return s.buildPathTree(allocs, rootID, dimType), nil
+	}
+
 	// 有分摊结果则用分摊结果构建树
 	if len(allocs) > 0 {
 		return s.buildTree(allocs, rootID, dimType), nil
@@ -289,25 +342,53 @@ func (s *AllocationService) validateRule(rule costdomain.AllocationRule) error {
 		}
 	}
 
+	if rule.RuleType == "usage_weighted" {
+		cfg := rule.UsageConfig
+		if cfg == nil {
+			return fmt.Errorf("usage_config cannot be nil for usage_weighted rule")
+		}
+		if len(cfg.ResourceIDs) == 0 {
+			return fmt.Errorf("resource_ids cannot be empty for usage_weighted rule")
+		}
+		if !validUsageSources[cfg.Source] {
+			return fmt.Errorf("%w: unsupported usage source %q", costdomain.ErrUsageInvalid, cfg.Source)
+		}
+		if cfg.Subject == "" {
+			return fmt.Errorf("subject cannot be empty for usage_weighted rule")
+		}
+		if len(cfg.FallbackRatios) > 0 {
+			var ratioSum float64
+			for _, ratio := range cfg.FallbackRatios {
+				ratioSum += ratio
+			}
+			if math.Abs(ratioSum-ratioTarget) > ratioTolerance {
+				return costdomain.ErrAllocationRatioInvalid
+			}
+		}
+	}
+
 	return nil
 }
 
 // matchAndAllocate 匹配规则并生成分摊结果
-func (s *AllocationService) matchAndAllocate(bill costdomain.UnifiedBill, rule costdomain.AllocationRule, period string, now int64) []costdomain.CostAllocation {
+// amount 为账单折算到报表币种后的金额，weights 为用量加权规则当期的权重
+func (s *AllocationService) matchAndAllocate(bill costdomain.UnifiedBill, amount float64, rule costdomain.AllocationRule, weights *costdomain.AllocationUsageWeights, period string, now int64) []costdomain.CostAllocation {
 	switch rule.RuleType {
 	case "dimension_combo":
-		return s.allocateByDimensionCombo(bill, rule, period, now)
+		return s.allocateByDimensionCombo(bill, amount, rule, period, now)
 	case "tag_mapping":
-		return s.allocateByTagMapping(bill, rule, period, now)
+		return s.allocateByTagMapping(bill, amount, rule, period, now)
 	case "shared_ratio":
-		return s.allocateBySharedRatio(bill, rule, period, now)
+		return s.allocateBySharedRatio(bill, amount, rule, period, now)
+	case "usage_weighted":
+		return s.allocateByUsageWeights(bill, amount, rule, weights, period, now)
 	default:
 		return nil
 	}
 }
 
 // allocateByDimensionCombo 按维度组合分摊
-func (s *AllocationService) allocateByDimensionCombo(bill costdomain.UnifiedBill, rule costdomain.AllocationRule, period string, now int64) []costdomain.CostAllocation {
+func (s *AllocationService) allocateByDimensionCombo(bill costdomain.UnifiedBill, billAmount float64, rule costdomain.AllocationRule, period string, now int64) []costdomain.CostAllocation {
 	// Check if any combo matches the bill
 	anyMatch := false
 	for _, combo := range rule.DimensionCombos {
@@ -322,10 +403,11 @@ func (s *AllocationService) allocateByDimensionCombo(bill costdomain.UnifiedBill
 
 	var allocs []costdomain.CostAllocation
 	for _, combo := range rule.DimensionCombos {
-		amount := bill.AmountCNY * combo.Ratio / 100.0
+		amount := billAmount * combo.Ratio / 100.0
 		allocs = append(allocs, costdomain.CostAllocation{
 			DimType:     rule.DimensionCombos[0].Dimensions[0].DimType,
 			DimValue:    combo.TargetID,
+			TargetName:  combo.TargetName,
 			Period:      period,
 			TotalAmount: amount,
 			RatioAmount: amount,
@@ -369,7 +451,7 @@ func (s *AllocationService) dimensionMatches(bill costdomain.UnifiedBill, dim co
 }
 
 // allocateByTagMapping 按标签映射分摊
-func (s *AllocationService) allocateByTagMapping(bill costdomain.UnifiedBill, rule costdomain.AllocationRule, period string, now int64) []costdomain.CostAllocation {
+func (s *AllocationService) allocateByTagMapping(bill costdomain.UnifiedBill, billAmount float64, rule costdomain.AllocationRule, period string, now int64) []costdomain.CostAllocation {
 	if bill.Tags == nil {
 		return nil
 	}
@@ -388,8 +470,8 @@ func (s *AllocationService) allocateByTagMapping(bill costdomain.UnifiedBill, ru
 			DimValue:     fmt.Sprintf("%s=%s", rule.TagKey, tagValue),
 			NodeID:       nodeID,
 			Period:       period,
-			TotalAmount:  bill.AmountCNY,
-			DirectAmount: bill.AmountCNY,
+			TotalAmount:  billAmount,
+			DirectAmount: billAmount,
 			RuleID:       rule.ID,
 			TenantID:     bill.TenantID,
 			CreateTime:   now,
@@ -398,7 +480,7 @@ func (s *AllocationService) allocateByTagMapping(bill costdomain.UnifiedBill, ru
 }
 
 // allocateBySharedRatio 按共享资源比例分摊
-func (s *AllocationService) allocateBySharedRatio(bill costdomain.UnifiedBill, rule costdomain.AllocationRule, period string, now int64) []costdomain.CostAllocation {
+func (s *AllocationService) allocateBySharedRatio(bill costdomain.UnifiedBill, billAmount float64, rule costdomain.AllocationRule, period string, now int64) []costdomain.CostAllocation {
 	if rule.SharedConfig == nil {
 		return nil
 	}
@@ -417,7 +499,7 @@ func (s *AllocationService) allocateBySharedRatio(bill costdomain.UnifiedBill, r
 
 	var allocs []costdomain.CostAllocation
 	for nodeID, ratio := range rule.SharedConfig.Ratios {
-		amount := bill.AmountCNY * ratio / 100.0
+		amount := billAmount * ratio / 100.0
 		allocs = append(allocs, costdomain.CostAllocation{
 			DimType:      "shared",
 			NodeID:       nodeID,
@@ -433,16 +515,17 @@ func (s *AllocationService) allocateBySharedRatio(bill costdomain.UnifiedBill, r
 }
 
 // createUnmatchedAllocation 创建未匹配的分摊记录
-func (s *AllocationService) createUnmatchedAllocation(bill costdomain.UnifiedBill, period string, now int64, hasDefault bool, policy costdomain.DefaultAllocationPolicy) costdomain.CostAllocation {
+func (s *AllocationService) createUnmatchedAllocation(bill costdomain.UnifiedBill, amount float64, period string, now int64, hasDefault bool, policy costdomain.DefaultAllocationPolicy) costdomain.CostAllocation {
 	alloc := costdomain.CostAllocation{
 		Period:      period,
-		TotalAmount: bill.AmountCNY,
+		TotalAmount: amount,
 		TenantID:    bill.TenantID,
 		CreateTime:  now,
 	}
 
 	if hasDefault {
 		alloc.DimValue = policy.TargetID
+		alloc.TargetName = policy.TargetName
 		alloc.DefaultFlag = true
 	} else {
 		alloc.DimValue = "unallocated"
@@ -500,6 +583,7 @@ func (s *AllocationService) buildTree(allocs []costdomain.CostAllocation, rootID
 	}
 
 	if root, ok := nodeMap[rootID]; ok {
+		root.Currency = allocCurrency(allocs)
 		return root
 	}
 
@@ -508,6 +592,7 @@ func (s *AllocationService) buildTree(allocs []costdomain.CostAllocation, rootID
 		NodeID:   rootID,
 		NodeName: "全部",
 		DimType:  dimType,
+		Currency: allocCurrency(allocs),
 	}
 	for _, node := range nodeMap {
 		root.TotalAmount += node.TotalAmount
@@ -521,9 +606,14 @@ func (s *AllocationService) buildTreeFromBills(ctx context.Context, tenantID, di
 	startDate := period + "-01"
 	endDate := s.periodEndDate(period)
 
+	currency, err := s.reportingCurrency(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 标签维度需要特殊处理：展开 tags map 再聚合
 	if dimType == "tag" {
-		return s.buildTreeFromTags(ctx, tenantID, rootID, startDate, endDate)
+		return s.buildTreeFromTags(ctx, tenantID, currency, rootID, startDate, endDate)
 	}
 
 	field := s.dimTypeToField(dimType)
@@ -531,7 +621,7 @@ func (s *AllocationService) buildTreeFromBills(ctx context.Context, tenantID, di
 		field = "provider"
 	}
 
-	results, err := s.billDAO.AggregateByField(ctx, tenantID, field, startDate, endDate, repository.UnifiedBillFilter{})
+	results, err := s.aggregateByField(ctx, tenantID, currency, field, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("aggregate bills by field: %w", err)
 	}
@@ -540,6 +630,7 @@ func (s *AllocationService) buildTreeFromBills(ctx context.Context, tenantID, di
 		NodeID:   rootID,
 		NodeName: "全部",
 		DimType:  dimType,
+		Currency: currency,
 	}
 
 	for _, r := range results {
@@ -561,8 +652,8 @@ func (s *AllocationService) buildTreeFromBills(ctx context.Context, tenantID, di
 }
 
 // buildTreeFromTags 按标签维度聚合：展开 tags map，按 tag value 分组
-func (s *AllocationService) buildTreeFromTags(ctx context.Context, tenantID, rootID, startDate, endDate string) (*AllocationTreeNode, error) {
-	results, err := s.billDAO.AggregateByTag(ctx, tenantID, startDate, endDate)
+func (s *AllocationService) buildTreeFromTags(ctx context.Context, tenantID, currency, rootID, startDate, endDate string) (*AllocationTreeNode, error) {
+	results, err := s.aggregateByTag(ctx, tenantID, currency, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("aggregate bills by tag: %w", err)
 	}
@@ -571,6 +662,7 @@ func (s *AllocationService) buildTreeFromTags(ctx context.Context, tenantID, roo
 		NodeID:   rootID,
 		NodeName: "全部",
 		DimType:  "tag",
+		Currency: currency,
 	}
 
 	for _, r := range results {
@@ -591,6 +683,108 @@ func (s *AllocationService) buildTreeFromTags(ctx context.Context, tenantID, roo
 	return root, nil
 }
 
+// aggregateByField 按字段聚合区间成本，AmountCNY 为报表币种金额
+// 非人民币时按日聚合后逐日折算，保证使用账单当日汇率
+func (s *AllocationService) aggregateByField(ctx context.Context, tenantID, currency, field, startDate, endDate string) ([]repository.AggregateResult, error) {
+	if currency == exchange.CurrencyCNY {
+		return s.billDAO.AggregateByField(ctx, tenantID, field, startDate, endDate, repository.UnifiedBillFilter{})
+	}
+
+	daily, err := s.billDAO.AggregateByFieldDaily(ctx, tenantID, field, startDate, endDate, repository.UnifiedBillFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var results []repository.AggregateResult
+	index := make(map[string]int)
+	for _, d := range daily {
+		amount, err := s.toReporting(ctx, currency, d.AmountCNY, d.Date)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := index[d.Key]
+		if !ok {
+			i = len(results)
+			index[d.Key] = i
+			results = append(results, repository.AggregateResult{Key: d.Key})
+		}
+		results[i].Amount += d.Amount
+		results[i].AmountCNY += amount
+	}
+	return results, nil
+}
+
+// aggregateByTag 按标签聚合区间成本，AmountCNY 为报表币种金额
+// 标签聚合没有按日维度，非人民币时逐日查询后折算
+func (s *AllocationService) aggregateByTag(ctx context.Context, tenantID, currency, startDate, endDate string) ([]repository.AggregateResult, error) {
+	if currency == exchange.CurrencyCNY {
+		return s.billDAO.AggregateByTag(ctx, tenantID, startDate, endDate)
+	}
+
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, fmt.Errorf("parse start date: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, fmt.Errorf("parse end date: %w", err)
+	}
+
+	var results []repository.AggregateResult
+	index := make(map[string]int)
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		daily, err := s.billDAO.AggregateByTag(ctx, tenantID, date, date)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range daily {
+			amount, err := s.toReporting(ctx, currency, r.AmountCNY, date)
+			if err != nil {
+				return nil, err
+			}
+			i, ok := index[r.Key]
+			if !ok {
+				i = len(results)
+				index[r.Key] = i
+				results = append(results, repository.AggregateResult{Key: r.Key})
+			}
+			results[i].Amount += r.Amount
+			results[i].AmountCNY += amount
+		}
+	}
+	return results, nil
+}
+
+// reportingCurrency 获取租户报表币种，未注入换算器时为 CNY
+func (s *AllocationService) reportingCurrency(ctx context.Context, tenantID string) (string, error) {
+	if s.converter == nil {
+		return exchange.CurrencyCNY, nil
+	}
+	currency, err := s.converter.ReportingCurrency(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("get reporting currency: %w", err)
+	}
+	return currency, nil
+}
+
+// toReporting 将人民币金额按 date 当日汇率折算为报表币种
+func (s *AllocationService) toReporting(ctx context.Context, currency string, amountCNY float64, date string) (float64, error) {
+	if s.converter == nil || currency == exchange.CurrencyCNY {
+		return amountCNY, nil
+	}
+	return s.converter.FromCNY(ctx, currency, amountCNY, date)
+}
+
+// allocCurrency 分摊结果币种，历史结果未记录币种时为 CNY
+func allocCurrency(allocs []costdomain.CostAllocation) string {
+	for _, a := range allocs {
+		if a.Currency != "" {
+			return a.Currency
+		}
+	}
+	return exchange.CurrencyCNY
+}
+
 // dimTypeToField 将维度类型映射到统一账单的字段名
 func (s *AllocationService) dimTypeToField(dimType string) string {
 	switch dimType {
@@ -171,6 +171,12 @@ func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _, _ string)
 func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
 func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
@@ -178,6 +184,18 @@ func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) (
 	return nil, nil
 }
 
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, _ []repository.AmountCNYUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(_ context.Context, _, _, _, _, _, _ string) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
 // --- Test Setup ---
 
 func setupTestService(t *testing.T) (*AllocationService, *mockAllocationDAO, *mockBillDAO) {
@@ -383,6 +401,67 @@ func TestAllocateCosts_WithMatchingRules(t *testing.T) {
 	assert.InDelta(t, 400, inserted[1].TotalAmount, 0.01)
 }
 
+// fakeConverter 固定报表币种，汇率按日期取值
+type fakeConverter struct {
+	currency string
+	rates    map[string]float64
+}
+
+func (f *fakeConverter) ReportingCurrency(_ context.Context, _ string) (string, error) {
+	return f.currency, nil
+}
+
+func (f *fakeConverter) FromCNY(_ context.Context, _ string, amountCNY float64, date string) (float64, error) {
+	return amountCNY * f.rates[date], nil
+}
+
+func TestAllocateCosts_ReportingCurrency(t *testing.T) {
+	svc, allocDAO, billDAO := setupTestService(t)
+	svc.SetCurrencyConverter(&fakeConverter{
+		currency: "USD",
+		rates:    map[string]float64{"2024-01-05": 0.1, "2024-01-20": 0.2},
+	})
+
+	billDAO.listUnifiedBillsFn = func(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+		return []costdomain.UnifiedBill{
+			{ID: 1, Region: "us-east-1", AmountCNY: 1000, BillingDate: "2024-01-05", TenantID: "t1"},
+			{ID: 2, Region: "ap-south-1", AmountCNY: 1000, BillingDate: "2024-01-20", TenantID: "t1"},
+		}, nil
+	}
+	allocDAO.listActiveRulesFn = func(_ context.Context, _ string) ([]costdomain.AllocationRule, error) {
+		return []costdomain.AllocationRule{
+			{
+				ID:       1,
+				RuleType: "dimension_combo",
+				DimensionCombos: []costdomain.DimensionCombo{
+					{
+						Dimensions: []costdomain.DimensionFilter{{DimType: costdomain.DimRegion, DimValue: "us-east-1"}},
+						TargetID:   "dept-1",
+						Ratio:      100,
+					},
+				},
+			},
+		}, nil
+	}
+
+	var inserted []costdomain.CostAllocation
+	allocDAO.insertAllocationsFn = func(_ context.Context, allocs []costdomain.CostAllocation) (int64, error) {
+		inserted = allocs
+		return int64(len(allocs)), nil
+	}
+
+	err := svc.AllocateCosts(context.Background(), "t1", "2024-01")
+	require.NoError(t, err)
+	require.Len(t, inserted, 2)
+	// 每张账单按各自账单日期汇率折算
+	assert.InDelta(t, 100, inserted[0].TotalAmount, 0.01)
+	assert.InDelta(t, 200, inserted[1].TotalAmount, 0.01)
+	assert.True(t, inserted[1].UnallocatedFlag)
+	for _, alloc := range inserted {
+		assert.Equal(t, "USD", alloc.Currency)
+	}
+}
+
 func TestAllocateCosts_DefaultPolicy(t *testing.T) {
 	svc, allocDAO, billDAO := setupTestService(t)
 
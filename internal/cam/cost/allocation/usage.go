@@ -0,0 +1,370 @@
+package allocation
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// validUsageSources 有效的用量数据源集合
+var validUsageSources = map[string]bool{
+	costdomain.UsageSourceK8sNamespace: true,
+	costdomain.UsageSourceTopoEdge:     true,
+	costdomain.UsageSourceCSV:          true,
+}
+
+// usageCSVRequired 用量 CSV 必需列（metric 列可选）
+var usageCSVRequired = []string{"period", "subject", "consumer", "value"}
+
+// UsageSource 用量数据源：返回共享对象在账期内各消费方的用量
+type UsageSource interface {
+	ConsumerUsage(ctx context.Context, tenantID, period string, cfg costdomain.UsageConfig) (map[string]float64, error)
+}
+
+// RecordUsageSource 基于已存储用量记录的数据源（上传的 CSV、外部推送的 K8s 命名空间指标）
+type RecordUsageSource struct {
+	usageDAO repository.UsageDAO
+}
+
+// NewRecordUsageSource 创建基于用量记录的数据源
+func NewRecordUsageSource(usageDAO repository.UsageDAO) *RecordUsageSource {
+	return &RecordUsageSource{usageDAO: usageDAO}
+}
+
+// ConsumerUsage 按消费方汇总账期内的用量记录
+func (s *RecordUsageSource) ConsumerUsage(ctx context.Context, tenantID, period string, cfg costdomain.UsageConfig) (map[string]float64, error) {
+	records, err := s.usageDAO.ListRecords(ctx, repository.UsageRecordFilter{
+		TenantID: tenantID,
+		Source:   cfg.Source,
+		Subject:  cfg.Subject,
+		Metric:   cfg.Metric,
+		Period:   period,
+	})
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]float64)
+	for _, r := range records {
+		usage[r.Consumer] += r.Value
+	}
+	return usage, nil
+}
+
+// SnapshotUsageSource 为只保存实时值的数据源（如拓扑连线请求计数）按账期留存快照：
+// 当期读取实时值并写入用量记录，历史账期只读取当期留存的快照，不能用当前值重算历史账期
+type SnapshotUsageSource struct {
+	live     UsageSource
+	usageDAO repository.UsageDAO
+	now      func() time.Time
+}
+
+// NewSnapshotUsageSource 创建按账期留存快照的数据源
+func NewSnapshotUsageSource(live UsageSource, usageDAO repository.UsageDAO) *SnapshotUsageSource {
+	return &SnapshotUsageSource{live: live, usageDAO: usageDAO, now: time.Now}
+}
+
+// ConsumerUsage 当期返回实时用量并覆盖快照，历史账期返回已留存的快照（无快照时为空）
+func (s *SnapshotUsageSource) ConsumerUsage(ctx context.Context, tenantID, period string, cfg costdomain.UsageConfig) (map[string]float64, error) {
+	if period != s.now().Format("2006-01") {
+		return NewRecordUsageSource(s.usageDAO).ConsumerUsage(ctx, tenantID, period, cfg)
+	}
+
+	usage, err := s.live.ConsumerUsage(ctx, tenantID, period, cfg)
+	if err != nil {
+		return nil, err
+	}
+	consumers := make([]string, 0, len(usage))
+	for c := range usage {
+		consumers = append(consumers, c)
+	}
+	sort.Strings(consumers)
+	records := make([]costdomain.UsageRecord, 0, len(consumers))
+	for _, c := range consumers {
+		records = append(records, costdomain.UsageRecord{
+			Source:   cfg.Source,
+			Subject:  cfg.Subject,
+			Consumer: c,
+			Metric:   cfg.Metric,
+			Period:   period,
+			Value:    usage[c],
+			TenantID: tenantID,
+		})
+	}
+	if _, err := s.usageDAO.ReplaceRecords(ctx, tenantID, cfg.Source, cfg.Subject, period, records); err != nil {
+		return nil, fmt.Errorf("save usage snapshot: %w", err)
+	}
+	return usage, nil
+}
+
+// SetUsageDAO 设置用量 DAO（可选注入，未设置时不持久化权重快照、不支持用量导入）
+func (s *AllocationService) SetUsageDAO(usageDAO repository.UsageDAO) {
+	s.usageDAO = usageDAO
+}
+
+// SetUsageSource 注册用量数据源
+func (s *AllocationService) SetUsageSource(source string, src UsageSource) {
+	if s.usageSources == nil {
+		s.usageSources = make(map[string]UsageSource)
+	}
+	s.usageSources[source] = src
+}
+
+// ImportUsageRecords 写入用量记录，按 (共享对象, 账期) 整体替换，供 K8s 指标推送与 CSV 上传共用
+func (s *AllocationService) ImportUsageRecords(ctx context.Context, tenantID, source string, records []costdomain.UsageRecord) (int64, error) {
+	if s.usageDAO == nil {
+		return 0, fmt.Errorf("usage import is not configured")
+	}
+	if source != costdomain.UsageSourceK8sNamespace && source != costdomain.UsageSourceCSV {
+		return 0, fmt.Errorf("%w: source %q does not accept imported records", costdomain.ErrUsageInvalid, source)
+	}
+	if len(records) == 0 {
+		return 0, fmt.Errorf("%w: no usage records", costdomain.ErrUsageInvalid)
+	}
+
+	type groupKey struct{ subject, period string }
+	groups := make(map[groupKey][]costdomain.UsageRecord)
+	var keys []groupKey
+	for i, r := range records {
+		if _, err := time.Parse("2006-01", r.Period); err != nil {
+			return 0, fmt.Errorf("%w: record %d: invalid period %q", costdomain.ErrUsageInvalid, i+1, r.Period)
+		}
+		if r.Subject == "" || r.Consumer == "" {
+			return 0, fmt.Errorf("%w: record %d: subject and consumer are required", costdomain.ErrUsageInvalid, i+1)
+		}
+		if r.Value < 0 {
+			return 0, fmt.Errorf("%w: record %d: negative value", costdomain.ErrUsageInvalid, i+1)
+		}
+		r.Source = source
+		r.TenantID = tenantID
+		key := groupKey{r.Subject, r.Period}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	var total int64
+	for _, key := range keys {
+		n, err := s.usageDAO.ReplaceRecords(ctx, tenantID, source, key.subject, key.period, groups[key])
+		if err != nil {
+			return total, fmt.Errorf("save usage records: %w", err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// ImportUsageCSV 解析用量 CSV（表头 period,subject,consumer,metric,value）并写入
+func (s *AllocationService) ImportUsageCSV(ctx context.Context, tenantID string, r io.Reader) (int64, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("%w: read header: %v", costdomain.ErrUsageInvalid, err)
+	}
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, name := range usageCSVRequired {
+		if _, ok := col[name]; !ok {
+			return 0, fmt.Errorf("%w: missing column %q", costdomain.ErrUsageInvalid, name)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var records []costdomain.UsageRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: %v", costdomain.ErrUsageInvalid, line, err)
+		}
+		value, err := strconv.ParseFloat(field(row, "value"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: line %d: invalid value %q", costdomain.ErrUsageInvalid, line, field(row, "value"))
+		}
+		records = append(records, costdomain.UsageRecord{
+			Period:   field(row, "period"),
+			Subject:  field(row, "subject"),
+			Consumer: field(row, "consumer"),
+			Metric:   field(row, "metric"),
+			Value:    value,
+		})
+	}
+	return s.ImportUsageRecords(ctx, tenantID, costdomain.UsageSourceCSV, records)
+}
+
+// ListUsageWeights 查询账期内用量加权规则实际采用的权重，ruleID 为 0 时返回全部规则
+func (s *AllocationService) ListUsageWeights(ctx context.Context, tenantID, period string, ruleID int64) ([]costdomain.AllocationUsageWeights, error) {
+	if s.usageDAO == nil {
+		return nil, nil
+	}
+	return s.usageDAO.ListWeights(ctx, tenantID, period, ruleID)
+}
+
+// resolveUsageWeights 按账期从数据源读取用量并计算各用量加权规则的权重
+// 数据源不可用时记录错误并按静态比例兜底，不中断整体分摊
+func (s *AllocationService) resolveUsageWeights(ctx context.Context, tenantID, period string, rules []costdomain.AllocationRule) map[int64]*costdomain.AllocationUsageWeights {
+	result := make(map[int64]*costdomain.AllocationUsageWeights)
+	for _, rule := range rules {
+		if rule.RuleType != "usage_weighted" || rule.UsageConfig == nil {
+			continue
+		}
+		var (
+			usage map[string]float64
+			err   error
+		)
+		if src, ok := s.usageSources[rule.UsageConfig.Source]; ok {
+			usage, err = src.ConsumerUsage(ctx, tenantID, period, *rule.UsageConfig)
+		} else {
+			err = fmt.Errorf("usage source %q not configured", rule.UsageConfig.Source)
+		}
+		if err != nil {
+			s.logger.Warn("load allocation usage failed, using fallback ratios",
+				elog.Int64("rule_id", rule.ID),
+				elog.String("period", period),
+				elog.FieldErr(err))
+		}
+		weights := computeUsageWeights(rule, period, usage)
+		if err != nil {
+			weights.Error = err.Error()
+		}
+		weights.TenantID = tenantID
+		result[rule.ID] = &weights
+	}
+	return result
+}
+
+// computeUsageWeights 按用量计算权重；当期无用量时使用静态比例兜底
+func computeUsageWeights(rule costdomain.AllocationRule, period string, usage map[string]float64) costdomain.AllocationUsageWeights {
+	cfg := rule.UsageConfig
+	w := costdomain.AllocationUsageWeights{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		Period:   period,
+		Source:   cfg.Source,
+		Subject:  cfg.Subject,
+		Metric:   cfg.Metric,
+		Weights:  []costdomain.UsageWeight{},
+	}
+
+	consumers := make([]string, 0, len(usage))
+	for consumer, v := range usage {
+		if v > 0 {
+			consumers = append(consumers, consumer)
+			w.TotalUsage += v
+		}
+	}
+	sort.Strings(consumers)
+
+	if w.TotalUsage > 0 {
+		for _, consumer := range consumers {
+			v := usage[consumer]
+			nodeID := cfg.ConsumerMap[consumer]
+			if nodeID == 0 {
+				w.UnmappedUsage += v
+			}
+			w.Weights = append(w.Weights, costdomain.UsageWeight{
+				Consumer: consumer,
+				NodeID:   nodeID,
+				Usage:    v,
+				Weight:   v / w.TotalUsage * 100.0,
+			})
+		}
+		return w
+	}
+
+	if len(cfg.FallbackRatios) > 0 {
+		w.Fallback = true
+		nodeIDs := make([]int64, 0, len(cfg.FallbackRatios))
+		for nodeID := range cfg.FallbackRatios {
+			nodeIDs = append(nodeIDs, nodeID)
+		}
+		sort.Slice(nodeIDs, func(i, j int) bool { return nodeIDs[i] < nodeIDs[j] })
+		for _, nodeID := range nodeIDs {
+			w.Weights = append(w.Weights, costdomain.UsageWeight{NodeID: nodeID, Weight: cfg.FallbackRatios[nodeID]})
+		}
+	}
+	return w
+}
+
+// allocateByUsageWeights 按用量权重分摊共享资源，未映射消费方的份额计入未分摊
+func (s *AllocationService) allocateByUsageWeights(bill costdomain.UnifiedBill, billAmount float64, rule costdomain.AllocationRule, weights *costdomain.AllocationUsageWeights, period string, now int64) []costdomain.CostAllocation {
+	if rule.UsageConfig == nil || weights == nil || len(weights.Weights) == 0 {
+		return nil
+	}
+	isShared := false
+	for _, rid := range rule.UsageConfig.ResourceIDs {
+		if bill.ResourceID == rid {
+			isShared = true
+			break
+		}
+	}
+	if !isShared {
+		return nil
+	}
+
+	var (
+		allocs   []costdomain.CostAllocation
+		unmapped float64
+	)
+	for _, w := range weights.Weights {
+		amount := billAmount * w.Weight / 100.0
+		if w.NodeID == 0 {
+			unmapped += amount
+			continue
+		}
+		allocs = append(allocs, costdomain.CostAllocation{
+			DimType:      "usage",
+			DimValue:     w.Consumer,
+			NodeID:       w.NodeID,
+			Period:       period,
+			TotalAmount:  amount,
+			SharedAmount: amount,
+			RuleID:       rule.ID,
+			TenantID:     bill.TenantID,
+			CreateTime:   now,
+		})
+	}
+	if unmapped != 0 {
+		allocs = append(allocs, costdomain.CostAllocation{
+			DimType:         "usage",
+			DimValue:        "unallocated",
+			Period:          period,
+			TotalAmount:     unmapped,
+			UnallocatedFlag: true,
+			RuleID:          rule.ID,
+			TenantID:        bill.TenantID,
+			CreateTime:      now,
+		})
+	}
+	return allocs
+}
+
+// usageWeightsList 权重快照按规则 ID 排序，用于持久化
+func usageWeightsList(weights map[int64]*costdomain.AllocationUsageWeights) []costdomain.AllocationUsageWeights {
+	list := make([]costdomain.AllocationUsageWeights, 0, len(weights))
+	for _, w := range weights {
+		list = append(list, *w)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].RuleID < list[j].RuleID })
+	return list
+}
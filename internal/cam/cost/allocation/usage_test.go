@@ -0,0 +1,257 @@
+package allocation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- Mock UsageDAO ---
+
+type mockUsageDAO struct {
+	records []costdomain.UsageRecord
+	weights []costdomain.AllocationUsageWeights
+}
+
+func (m *mockUsageDAO) ReplaceRecords(_ context.Context, tenantID, source, subject, period string, records []costdomain.UsageRecord) (int64, error) {
+	kept := m.records[:0]
+	for _, r := range m.records {
+		if !(r.TenantID == tenantID && r.Source == source && r.Subject == subject && r.Period == period) {
+			kept = append(kept, r)
+		}
+	}
+	m.records = append(kept, records...)
+	return int64(len(records)), nil
+}
+func (m *mockUsageDAO) ListRecords(_ context.Context, filter repository.UsageRecordFilter) ([]costdomain.UsageRecord, error) {
+	var result []costdomain.UsageRecord
+	for _, r := range m.records {
+		if r.TenantID == filter.TenantID && r.Source == filter.Source && r.Subject == filter.Subject &&
+			r.Period == filter.Period && (filter.Metric == "" || r.Metric == filter.Metric) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+func (m *mockUsageDAO) ReplaceWeights(_ context.Context, _, _ string, weights []costdomain.AllocationUsageWeights) error {
+	m.weights = weights
+	return nil
+}
+func (m *mockUsageDAO) ListWeights(_ context.Context, _, _ string, _ int64) ([]costdomain.AllocationUsageWeights, error) {
+	return m.weights, nil
+}
+
+type staticUsageSource struct {
+	usage map[string]float64
+	err   error
+}
+
+func (s staticUsageSource) ConsumerUsage(_ context.Context, _, _ string, _ costdomain.UsageConfig) (map[string]float64, error) {
+	return s.usage, s.err
+}
+
+func usageRule() costdomain.AllocationRule {
+	return costdomain.AllocationRule{
+		ID:       5,
+		Name:     "kafka by bytes",
+		RuleType: "usage_weighted",
+		UsageConfig: &costdomain.UsageConfig{
+			ResourceIDs:    []string{"kafka-1"},
+			Source:         costdomain.UsageSourceCSV,
+			Subject:        "kafka-prod",
+			Metric:         "bytes",
+			ConsumerMap:    map[string]int64{"orders": 10, "payments": 20},
+			FallbackRatios: map[int64]float64{10: 50, 20: 50},
+		},
+	}
+}
+
+func setupUsageAllocation(t *testing.T) (*AllocationService, *mockAllocationDAO, *mockUsageDAO, *[]costdomain.CostAllocation) {
+	t.Helper()
+	svc, allocDAO, billDAO := setupTestService(t)
+	usageDAO := &mockUsageDAO{}
+	svc.SetUsageDAO(usageDAO)
+	svc.SetUsageSource(costdomain.UsageSourceCSV, NewRecordUsageSource(usageDAO))
+
+	billDAO.listUnifiedBillsFn = func(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+		return []costdomain.UnifiedBill{
+			{ID: 1, ResourceID: "kafka-1", AmountCNY: 1000, TenantID: "t1"},
+			{ID: 2, ResourceID: "ecs-1", AmountCNY: 200, TenantID: "t1"},
+		}, nil
+	}
+	allocDAO.listActiveRulesFn = func(_ context.Context, _ string) ([]costdomain.AllocationRule, error) {
+		return []costdomain.AllocationRule{usageRule()}, nil
+	}
+	inserted := &[]costdomain.CostAllocation{}
+	allocDAO.insertAllocationsFn = func(_ context.Context, allocs []costdomain.CostAllocation) (int64, error) {
+		*inserted = allocs
+		return int64(len(allocs)), nil
+	}
+	return svc, allocDAO, usageDAO, inserted
+}
+
+func sharedByNode(allocs []costdomain.CostAllocation) (map[int64]float64, float64) {
+	byNode := make(map[int64]float64)
+	var unallocated float64
+	for _, a := range allocs {
+		switch {
+		case a.RuleID != 5:
+		case a.UnallocatedFlag:
+			unallocated += a.TotalAmount
+		default:
+			byNode[a.NodeID] += a.SharedAmount
+		}
+	}
+	return byNode, unallocated
+}
+
+func TestAllocateCosts_UsageWeighted(t *testing.T) {
+	svc, _, usageDAO, inserted := setupUsageAllocation(t)
+	ctx := context.Background()
+
+	csvData := "period,subject,consumer,metric,value\n" +
+		"2024-01,kafka-prod,orders,bytes,600\n" +
+		"2024-01,kafka-prod,payments,bytes,300\n" +
+		"2024-01,kafka-prod,search,bytes,100\n" +
+		"2024-01,kafka-prod,orders,messages,999999\n"
+	n, err := svc.ImportUsageCSV(ctx, "t1", strings.NewReader(csvData))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	require.NoError(t, svc.AllocateCosts(ctx, "t1", "2024-01"))
+
+	byNode, unallocated := sharedByNode(*inserted)
+	assert.InDelta(t, 600, byNode[10], 0.01)
+	assert.InDelta(t, 300, byNode[20], 0.01)
+	assert.InDelta(t, 100, unallocated, 0.01, "未映射消费方的份额计入未分摊")
+
+	// 权重快照持久化用于审计
+	require.Len(t, usageDAO.weights, 1)
+	w := usageDAO.weights[0]
+	assert.Equal(t, int64(5), w.RuleID)
+	assert.Equal(t, "2024-01", w.Period)
+	assert.False(t, w.Fallback)
+	assert.InDelta(t, 1000, w.TotalUsage, 0.01)
+	assert.InDelta(t, 100, w.UnmappedUsage, 0.01)
+	assert.Equal(t, []costdomain.UsageWeight{
+		{Consumer: "orders", NodeID: 10, Usage: 600, Weight: 60},
+		{Consumer: "payments", NodeID: 20, Usage: 300, Weight: 30},
+		{Consumer: "search", Usage: 100, Weight: 10},
+	}, w.Weights)
+
+	// 重新上传同一账期的用量后重新分摊，权重随之更新
+	_, err = svc.ImportUsageCSV(ctx, "t1", strings.NewReader("period,subject,consumer,metric,value\n2024-01,kafka-prod,payments,bytes,1\n"))
+	require.NoError(t, err)
+	require.NoError(t, svc.AllocateCosts(ctx, "t1", "2024-01"))
+	byNode, unallocated = sharedByNode(*inserted)
+	assert.InDelta(t, 1000, byNode[20], 0.01)
+	assert.Zero(t, byNode[10])
+	assert.Zero(t, unallocated)
+}
+
+func TestAllocateCosts_UsageWeightedFallback(t *testing.T) {
+	svc, _, usageDAO, inserted := setupUsageAllocation(t)
+	svc.SetUsageSource(costdomain.UsageSourceCSV, staticUsageSource{err: errors.New("source down")})
+
+	require.NoError(t, svc.AllocateCosts(context.Background(), "t1", "2024-01"))
+
+	byNode, _ := sharedByNode(*inserted)
+	assert.InDelta(t, 500, byNode[10], 0.01)
+	assert.InDelta(t, 500, byNode[20], 0.01)
+	require.Len(t, usageDAO.weights, 1)
+	assert.True(t, usageDAO.weights[0].Fallback)
+	assert.Equal(t, "source down", usageDAO.weights[0].Error)
+}
+
+func TestAllocateCosts_UsageWeightedNoData(t *testing.T) {
+	svc, allocDAO, _, inserted := setupUsageAllocation(t)
+	rule := usageRule()
+	rule.UsageConfig.FallbackRatios = nil
+	allocDAO.listActiveRulesFn = func(_ context.Context, _ string) ([]costdomain.AllocationRule, error) {
+		return []costdomain.AllocationRule{rule}, nil
+	}
+
+	require.NoError(t, svc.AllocateCosts(context.Background(), "t1", "2024-01"))
+
+	// 无用量且无兜底比例时规则不匹配，共享资源按未分摊处理
+	require.Len(t, *inserted, 2)
+	for _, a := range *inserted {
+		assert.True(t, a.UnallocatedFlag)
+		assert.Zero(t, a.RuleID)
+	}
+}
+
+func TestCreateAllocationRule_UsageWeightedValidation(t *testing.T) {
+	svc, _, _ := setupTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateAllocationRule(ctx, costdomain.AllocationRule{Name: "no config", RuleType: "usage_weighted"})
+	assert.Error(t, err)
+
+	rule := usageRule()
+	rule.UsageConfig.Source = "prometheus"
+	_, err = svc.CreateAllocationRule(ctx, rule)
+	assert.ErrorIs(t, err, costdomain.ErrUsageInvalid)
+
+	rule = usageRule()
+	rule.UsageConfig.FallbackRatios = map[int64]float64{10: 70}
+	_, err = svc.CreateAllocationRule(ctx, rule)
+	assert.ErrorIs(t, err, costdomain.ErrAllocationRatioInvalid)
+
+	_, err = svc.CreateAllocationRule(ctx, usageRule())
+	assert.NoError(t, err)
+}
+
+func TestImportUsageRecords_Invalid(t *testing.T) {
+	svc, _, _ := setupTestService(t)
+	svc.SetUsageDAO(&mockUsageDAO{})
+	ctx := context.Background()
+
+	_, err := svc.ImportUsageRecords(ctx, "t1", costdomain.UsageSourceTopoEdge, []costdomain.UsageRecord{
+		{Period: "2024-01", Subject: "s", Consumer: "c", Value: 1},
+	})
+	assert.ErrorIs(t, err, costdomain.ErrUsageInvalid)
+
+	_, err = svc.ImportUsageRecords(ctx, "t1", costdomain.UsageSourceK8sNamespace, []costdomain.UsageRecord{
+		{Period: "2024-1", Subject: "prod", Consumer: "default", Value: 1},
+	})
+	assert.ErrorIs(t, err, costdomain.ErrUsageInvalid)
+
+	_, err = svc.ImportUsageCSV(ctx, "t1", strings.NewReader("period,subject,value\n2024-01,kafka,1\n"))
+	assert.ErrorIs(t, err, costdomain.ErrUsageInvalid)
+
+	_, err = svc.ImportUsageCSV(ctx, "t1", strings.NewReader("period,subject,consumer,value\n2024-01,kafka,orders,abc\n"))
+	assert.ErrorIs(t, err, costdomain.ErrUsageInvalid)
+}
+
+func TestSnapshotUsageSource(t *testing.T) {
+	usageDAO := &mockUsageDAO{}
+	live := staticUsageSource{usage: map[string]float64{"orders": 30, "payments": 70}}
+	src := NewSnapshotUsageSource(live, usageDAO)
+	src.now = func() time.Time { return time.Date(2026, 9, 15, 0, 0, 0, 0, time.Local) }
+	cfg := costdomain.UsageConfig{Source: costdomain.UsageSourceTopoEdge, Subject: "redis-shared"}
+
+	// 当期读取实时值并留存快照
+	usage, err := src.ConsumerUsage(context.Background(), "t1", "2026-09", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, live.usage, usage)
+	require.Len(t, usageDAO.records, 2)
+
+	// 历史账期不使用实时值：有快照读快照，无快照为空
+	src.live = staticUsageSource{usage: map[string]float64{"orders": 1}}
+	src.now = func() time.Time { return time.Date(2026, 10, 2, 0, 0, 0, 0, time.Local) }
+	usage, err = src.ConsumerUsage(context.Background(), "t1", "2026-09", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"orders": 30, "payments": 70}, usage)
+
+	usage, err = src.ConsumerUsage(context.Background(), "t1", "2026-08", cfg)
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+}
@@ -9,6 +9,8 @@ import (
 	"sort"
 	"time"
 
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/redis/go-redis/v9"
@@ -34,6 +36,7 @@ type CostFilter struct {
 	Region      string
 	StartDate   string // YYYY-MM-DD
 	EndDate     string // YYYY-MM-DD
+	View        string // 成本口径：billed（默认）/ amortized / net
 }
 
 // CostTrendFilter 成本趋势查询筛选条件
@@ -42,8 +45,9 @@ type CostTrendFilter struct {
 	Granularity string // "daily" | "weekly" | "monthly"
 }
 
-// CostSummary 成本概览
+// CostSummary 成本概览（金额以租户报表币种表示）
 type CostSummary struct {
+	Currency           string  `json:"currency"`
 	CurrentMonthAmount float64 `json:"current_month_amount"`
 	LastMonthAmount    float64 `json:"last_month_amount"`
 	MoMChangePercent   float64 `json:"mom_change_percent"` // 环比变化百分比（与上月同期对比）
@@ -55,18 +59,24 @@ type CostTrendPoint struct {
 	Date      string  `json:"date"`
 	Amount    float64 `json:"amount"`
 	AmountCNY float64 `json:"amount_cny"`
+	// ReportingAmount 按账单日期汇率折算的报表币种金额
+	ReportingAmount float64 `json:"reporting_amount"`
+	Currency        string  `json:"currency"`
 }
 
 // CostDistItem 成本分布项
 type CostDistItem struct {
-	Key       string  `json:"key"`
-	Amount    float64 `json:"amount"`
-	AmountCNY float64 `json:"amount_cny"`
-	Percent   float64 `json:"percent"` // 占比百分比
+	Key             string  `json:"key"`
+	Amount          float64 `json:"amount"`
+	AmountCNY       float64 `json:"amount_cny"`
+	ReportingAmount float64 `json:"reporting_amount"` // 报表币种金额
+	Currency        string  `json:"currency"`
+	Percent         float64 `json:"percent"` // 占比百分比（按报表币种金额计算）
 }
 
-// ComparisonResult 同比/环比对比结果
+// ComparisonResult 同比/环比对比结果（金额以租户报表币种表示）
 type ComparisonResult struct {
+	Currency       string  `json:"currency"`
 	CurrentAmount  float64 `json:"current_amount"`
 	PreviousAmount float64 `json:"previous_amount"`
 	ChangePercent  float64 `json:"change_percent"`
@@ -78,6 +88,7 @@ type ComparisonResult struct {
 type CostService struct {
 	billDAO    repository.BillDAO
 	summaryDAO SummaryQuerier
+	converter  exchange.ReportingConverter
 	redisCache redis.Cmdable
 	logger     *elog.Component
 }
@@ -87,6 +98,7 @@ type SummaryQuerier interface {
 	SumAmount(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error)
 	AggregateByField(ctx context.Context, tenantID string, field string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.AggregateResult, error)
 	AggregateDailyAmount(ctx context.Context, tenantID string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error)
+	AggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error)
 	HasData(ctx context.Context) bool
 }
 
@@ -104,9 +116,22 @@ func (s *CostService) SetSummaryDAO(dao SummaryQuerier) {
 	s.summaryDAO = dao
 }
 
+// SetCurrencyConverter 设置报表币种换算器（可选注入，未设置时金额均以人民币表示）
+func (s *CostService) SetCurrencyConverter(converter exchange.ReportingConverter) {
+	s.converter = converter
+}
+
+// useSummary 汇总表仅承载账单口径，摊销 / 净额口径直接查询明细表
+func (s *CostService) useSummary(ctx context.Context, filter repository.UnifiedBillFilter) bool {
+	if filter.View != "" && filter.View != domain.CostViewBilled {
+		return false
+	}
+	return s.summaryDAO != nil && s.summaryDAO.HasData(ctx)
+}
+
 // sumAmount 优先从汇总表查询，降级到明细表
 func (s *CostService) sumAmount(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error) {
-	if s.summaryDAO != nil && s.summaryDAO.HasData(ctx) {
+	if s.useSummary(ctx, filter) {
 		return s.summaryDAO.SumAmount(ctx, filter)
 	}
 	return s.billDAO.SumAmount(ctx, filter)
@@ -114,7 +139,7 @@ func (s *CostService) sumAmount(ctx context.Context, filter repository.UnifiedBi
 
 // aggregateByField 优先从汇总表查询，降级到明细表
 func (s *CostService) aggregateByField(ctx context.Context, tenantID string, field string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.AggregateResult, error) {
-	if s.summaryDAO != nil && s.summaryDAO.HasData(ctx) {
+	if s.useSummary(ctx, filter) {
 		return s.summaryDAO.AggregateByField(ctx, tenantID, field, startDate, endDate, filter)
 	}
 	return s.billDAO.AggregateByField(ctx, tenantID, field, startDate, endDate, filter)
@@ -122,12 +147,57 @@ func (s *CostService) aggregateByField(ctx context.Context, tenantID string, fie
 
 // aggregateDailyAmount 优先从汇总表查询，降级到明细表
 func (s *CostService) aggregateDailyAmount(ctx context.Context, tenantID string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
-	if s.summaryDAO != nil && s.summaryDAO.HasData(ctx) {
+	if s.useSummary(ctx, filter) {
 		return s.summaryDAO.AggregateDailyAmount(ctx, tenantID, startDate, endDate, filter)
 	}
 	return s.billDAO.AggregateDailyAmount(ctx, tenantID, startDate, endDate, filter)
 }
 
+// aggregateByFieldDaily 优先从汇总表查询，降级到明细表
+func (s *CostService) aggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	if s.useSummary(ctx, filter) {
+		return s.summaryDAO.AggregateByFieldDaily(ctx, tenantID, field, startDate, endDate, filter)
+	}
+	return s.billDAO.AggregateByFieldDaily(ctx, tenantID, field, startDate, endDate, filter)
+}
+
+// reportingCurrency 获取租户报表币种
+func (s *CostService) reportingCurrency(ctx context.Context, tenantID string) (string, error) {
+	if s.converter == nil {
+		return exchange.CurrencyCNY, nil
+	}
+	return s.converter.ReportingCurrency(ctx, tenantID)
+}
+
+// toReporting 按账单日期汇率将人民币金额折算为报表币种
+func (s *CostService) toReporting(ctx context.Context, currency string, amountCNY float64, date string) (float64, error) {
+	if s.converter == nil || currency == exchange.CurrencyCNY {
+		return amountCNY, nil
+	}
+	return s.converter.FromCNY(ctx, currency, amountCNY, date)
+}
+
+// sumReporting 汇总报表币种金额
+// 非人民币时按日聚合后逐日折算，保证与逐笔账单按当日汇率折算的结果一致
+func (s *CostService) sumReporting(ctx context.Context, filter repository.UnifiedBillFilter, currency string) (float64, error) {
+	if s.converter == nil || currency == exchange.CurrencyCNY {
+		return s.sumAmount(ctx, filter)
+	}
+	daily, err := s.aggregateDailyAmount(ctx, filter.TenantID, filter.StartDate, filter.EndDate, filter)
+	if err != nil {
+		return 0, err
+	}
+	var total float64
+	for _, d := range daily {
+		amount, err := s.converter.FromCNY(ctx, currency, d.AmountCNY, d.Date)
+		if err != nil {
+			return 0, err
+		}
+		total += amount
+	}
+	return total, nil
+}
+
 // toUnifiedBillFilter 将 CostFilter 转换为 UnifiedBillFilter
 func toUnifiedBillFilter(f CostFilter) repository.UnifiedBillFilter {
 	return repository.UnifiedBillFilter{
@@ -138,6 +208,7 @@ func toUnifiedBillFilter(f CostFilter) repository.UnifiedBillFilter {
 		Region:      f.Region,
 		StartDate:   f.StartDate,
 		EndDate:     f.EndDate,
+		View:        f.View,
 	}
 }
 
@@ -150,8 +221,13 @@ func getCacheKey(prefix, tenantID string, filter interface{}) string {
 
 // GetCostSummary 获取成本概览（当月/上月/环比）
 func (s *CostService) GetCostSummary(ctx context.Context, filter CostFilter) (*CostSummary, error) {
-	// 尝试从缓存获取
-	cacheKey := getCacheKey(summaryCachePrefix, filter.TenantID, filter)
+	currency, err := s.reportingCurrency(ctx, filter.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get reporting currency: %w", err)
+	}
+
+	// 尝试从缓存获取（缓存按报表币种区分，切换币种后立即生效）
+	cacheKey := getCacheKey(summaryCachePrefix+":"+currency, filter.TenantID, filter)
 	if cached, err := s.getFromCache(ctx, cacheKey); err == nil {
 		var summary CostSummary
 		if json.Unmarshal(cached, &summary) == nil {
@@ -193,7 +269,7 @@ func (s *CostService) GetCostSummary(ctx context.Context, filter CostFilter) (*C
 		f := toUnifiedBillFilter(filter)
 		f.StartDate = currentStart.Format("2006-01-02")
 		f.EndDate = currentEnd.Format("2006-01-02")
-		amount, err := s.sumAmount(ctx, f)
+		amount, err := s.sumReporting(ctx, f, currency)
 		currentCh <- sumResult{amount, err}
 	}()
 
@@ -202,7 +278,7 @@ func (s *CostService) GetCostSummary(ctx context.Context, filter CostFilter) (*C
 		f := toUnifiedBillFilter(filter)
 		f.StartDate = lastMonthStart.Format("2006-01-02")
 		f.EndDate = lastMonthEnd.Format("2006-01-02")
-		amount, err := s.sumAmount(ctx, f)
+		amount, err := s.sumReporting(ctx, f, currency)
 		lastCh <- sumResult{amount, err}
 	}()
 
@@ -211,7 +287,7 @@ func (s *CostService) GetCostSummary(ctx context.Context, filter CostFilter) (*C
 		f := toUnifiedBillFilter(filter)
 		f.StartDate = lastMonthSameDay.Format("2006-01-02")
 		f.EndDate = lastMonthSameDayEnd.Format("2006-01-02")
-		amount, err := s.sumAmount(ctx, f)
+		amount, err := s.sumReporting(ctx, f, currency)
 		lastSameCh <- sumResult{amount, err}
 	}()
 
@@ -244,6 +320,7 @@ func (s *CostService) GetCostSummary(ctx context.Context, filter CostFilter) (*C
 	}
 
 	summary := &CostSummary{
+		Currency:           currency,
 		CurrentMonthAmount: currentAmount,
 		LastMonthAmount:    lastAmount,
 		MoMChangePercent:   momChange,
@@ -266,8 +343,13 @@ func (s *CostService) GetCostTrend(ctx context.Context, filter CostTrendFilter)
 		filter.StartDate = start.Format("2006-01-02")
 	}
 
+	currency, err := s.reportingCurrency(ctx, filter.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get reporting currency: %w", err)
+	}
+
 	// 尝试从缓存获取
-	cacheKey := getCacheKey(trendCachePrefix, filter.TenantID, filter)
+	cacheKey := getCacheKey(trendCachePrefix+":"+currency, filter.TenantID, filter)
 	if cached, err := s.getFromCache(ctx, cacheKey); err == nil {
 		var points []CostTrendPoint
 		if json.Unmarshal(cached, &points) == nil {
@@ -285,15 +367,32 @@ func (s *CostService) GetCostTrend(ctx context.Context, filter CostTrendFilter)
 
 	// 转换为趋势数据点
 	var points []CostTrendPoint
+	bucket := func(date string) string { return date }
 	switch filter.Granularity {
 	case "weekly":
 		points = aggregateWeekly(dailyAmounts)
+		bucket = weekKey
 	case "monthly":
 		points = aggregateMonthly(dailyAmounts)
+		bucket = monthKey
 	default: // "daily"
 		points = convertDailyToPoints(dailyAmounts)
 	}
 
+	// 逐日折算报表币种金额后按周期累加
+	reporting := make(map[string]float64, len(points))
+	for _, d := range dailyAmounts {
+		amount, err := s.toReporting(ctx, currency, d.AmountCNY, d.Date)
+		if err != nil {
+			return nil, fmt.Errorf("convert to %s: %w", currency, err)
+		}
+		reporting[bucket(d.Date)] += amount
+	}
+	for i := range points {
+		points[i].ReportingAmount = reporting[points[i].Date]
+		points[i].Currency = currency
+	}
+
 	// 写入缓存
 	s.setCache(ctx, cacheKey, points, trendCacheTTL)
 
@@ -310,12 +409,25 @@ func (s *CostService) GetCostDistribution(ctx context.Context, filter CostFilter
 		filter.StartDate = start.Format("2006-01-02")
 	}
 
-	results, err := s.aggregateByField(ctx, filter.TenantID, dimension, filter.StartDate, filter.EndDate, repository.UnifiedBillFilter{
+	currency, err := s.reportingCurrency(ctx, filter.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get reporting currency: %w", err)
+	}
+
+	ubf := repository.UnifiedBillFilter{
 		Provider:    filter.Provider,
 		AccountID:   filter.AccountID,
 		ServiceType: filter.ServiceType,
 		Region:      filter.Region,
-	})
+		View:        filter.View,
+	}
+
+	var items []CostDistItem
+	if s.converter == nil || currency == exchange.CurrencyCNY {
+		items, err = s.distributionCNY(ctx, filter, dimension, ubf)
+	} else {
+		items, err = s.distributionReporting(ctx, filter, dimension, ubf, currency)
+	}
 	if err != nil {
 		s.logger.Error("failed to aggregate by field",
 			elog.String("dimension", dimension),
@@ -325,26 +437,73 @@ func (s *CostService) GetCostDistribution(ctx context.Context, filter CostFilter
 	}
 
 	// 计算总金额
-	var totalCNY float64
-	for _, r := range results {
-		totalCNY += r.AmountCNY
+	var total float64
+	for _, item := range items {
+		total += item.ReportingAmount
+	}
+
+	// 计算百分比
+	for i := range items {
+		if total > 0 {
+			items[i].Percent = items[i].ReportingAmount / total * 100
+		}
+		items[i].Currency = currency
+	}
+
+	return items, nil
+}
+
+// distributionCNY 人民币报表：直接按字段聚合
+func (s *CostService) distributionCNY(ctx context.Context, filter CostFilter, dimension string, ubf repository.UnifiedBillFilter) ([]CostDistItem, error) {
+	results, err := s.aggregateByField(ctx, filter.TenantID, dimension, filter.StartDate, filter.EndDate, ubf)
+	if err != nil {
+		return nil, err
 	}
 
-	// 构建分布项并计算百分比
 	items := make([]CostDistItem, 0, len(results))
 	for _, r := range results {
-		var pct float64
-		if totalCNY > 0 {
-			pct = r.AmountCNY / totalCNY * 100
-		}
 		items = append(items, CostDistItem{
-			Key:       r.Key,
-			Amount:    r.Amount,
-			AmountCNY: r.AmountCNY,
-			Percent:   pct,
+			Key:             r.Key,
+			Amount:          r.Amount,
+			AmountCNY:       r.AmountCNY,
+			ReportingAmount: r.AmountCNY,
 		})
 	}
+	return items, nil
+}
+
+// distributionReporting 非人民币报表：按字段和日期聚合后逐日折算，再按字段累加
+func (s *CostService) distributionReporting(ctx context.Context, filter CostFilter, dimension string, ubf repository.UnifiedBillFilter, currency string) ([]CostDistItem, error) {
+	results, err := s.aggregateByFieldDaily(ctx, filter.TenantID, dimension, filter.StartDate, filter.EndDate, ubf)
+	if err != nil {
+		return nil, err
+	}
+
+	itemMap := make(map[string]*CostDistItem)
+	var keys []string
+	for _, r := range results {
+		amount, err := s.converter.FromCNY(ctx, currency, r.AmountCNY, r.Date)
+		if err != nil {
+			return nil, fmt.Errorf("convert to %s: %w", currency, err)
+		}
+		item, ok := itemMap[r.Key]
+		if !ok {
+			item = &CostDistItem{Key: r.Key}
+			itemMap[r.Key] = item
+			keys = append(keys, r.Key)
+		}
+		item.Amount += r.Amount
+		item.AmountCNY += r.AmountCNY
+		item.ReportingAmount += amount
+	}
 
+	items := make([]CostDistItem, 0, len(keys))
+	for _, k := range keys {
+		items = append(items, *itemMap[k])
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ReportingAmount > items[j].ReportingAmount
+	})
 	return items, nil
 }
 
@@ -370,6 +529,11 @@ func (s *CostService) GetYoYComparison(ctx context.Context, filter CostFilter) (
 		return nil, fmt.Errorf("parse end date: %w", err)
 	}
 
+	currency, err := s.reportingCurrency(ctx, filter.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get reporting currency: %w", err)
+	}
+
 	// 计算去年同期
 	prevStart := startDate.AddDate(-1, 0, 0)
 	prevEnd := endDate.AddDate(-1, 0, 0)
@@ -384,7 +548,7 @@ func (s *CostService) GetYoYComparison(ctx context.Context, filter CostFilter) (
 
 	go func() {
 		currentFilter := toUnifiedBillFilter(filter)
-		amount, err := s.sumAmount(ctx, currentFilter)
+		amount, err := s.sumReporting(ctx, currentFilter, currency)
 		currentCh <- sumResult{amount, err}
 	}()
 
@@ -392,7 +556,7 @@ func (s *CostService) GetYoYComparison(ctx context.Context, filter CostFilter) (
 		prevFilter := toUnifiedBillFilter(filter)
 		prevFilter.StartDate = prevStart.Format("2006-01-02")
 		prevFilter.EndDate = prevEnd.Format("2006-01-02")
-		amount, err := s.sumAmount(ctx, prevFilter)
+		amount, err := s.sumReporting(ctx, prevFilter, currency)
 		prevCh <- sumResult{amount, err}
 	}()
 
@@ -416,6 +580,7 @@ func (s *CostService) GetYoYComparison(ctx context.Context, filter CostFilter) (
 	}
 
 	return &ComparisonResult{
+		Currency:       currency,
 		CurrentAmount:  currentAmount,
 		PreviousAmount: prevAmount,
 		ChangePercent:  changePct,
@@ -480,21 +645,14 @@ func aggregateWeekly(daily []repository.DailyAmount) []CostTrendPoint {
 		if err != nil {
 			continue
 		}
-		// 计算该日期所在周的周一
-		weekday := t.Weekday()
-		offset := int(weekday - time.Monday)
-		if offset < 0 {
-			offset += 7
-		}
-		monday := t.AddDate(0, 0, -offset)
-		weekKey := monday.Format("2006-01-02")
+		key := mondayOf(t).Format("2006-01-02")
 
-		if _, exists := weekMap[weekKey]; !exists {
-			weekMap[weekKey] = &CostTrendPoint{Date: weekKey}
-			weekKeys = append(weekKeys, weekKey)
+		if _, exists := weekMap[key]; !exists {
+			weekMap[key] = &CostTrendPoint{Date: key}
+			weekKeys = append(weekKeys, key)
 		}
-		weekMap[weekKey].Amount += d.Amount
-		weekMap[weekKey].AmountCNY += d.AmountCNY
+		weekMap[key].Amount += d.Amount
+		weekMap[key].AmountCNY += d.AmountCNY
 	}
 
 	sort.Strings(weekKeys)
@@ -515,14 +673,14 @@ func aggregateMonthly(daily []repository.DailyAmount) []CostTrendPoint {
 		if err != nil {
 			continue
 		}
-		monthKey := t.Format("2006-01")
+		key := t.Format("2006-01")
 
-		if _, exists := monthMap[monthKey]; !exists {
-			monthMap[monthKey] = &CostTrendPoint{Date: monthKey}
-			monthKeys = append(monthKeys, monthKey)
+		if _, exists := monthMap[key]; !exists {
+			monthMap[key] = &CostTrendPoint{Date: key}
+			monthKeys = append(monthKeys, key)
 		}
-		monthMap[monthKey].Amount += d.Amount
-		monthMap[monthKey].AmountCNY += d.AmountCNY
+		monthMap[key].Amount += d.Amount
+		monthMap[key].AmountCNY += d.AmountCNY
 	}
 
 	sort.Strings(monthKeys)
@@ -532,3 +690,30 @@ func aggregateMonthly(daily []repository.DailyAmount) []CostTrendPoint {
 	}
 	return points
 }
+
+// mondayOf 计算日期所在周的周一
+func mondayOf(t time.Time) time.Time {
+	offset := int(t.Weekday() - time.Monday)
+	if offset < 0 {
+		offset += 7
+	}
+	return t.AddDate(0, 0, -offset)
+}
+
+// weekKey 日期所在周的周一（YYYY-MM-DD）
+func weekKey(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return mondayOf(t).Format("2006-01-02")
+}
+
+// monthKey 日期所在月份（YYYY-MM）
+func monthKey(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return ""
+	}
+	return t.Format("2006-01")
+}
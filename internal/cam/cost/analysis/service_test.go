@@ -2,6 +2,9 @@ package analysis
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,6 +21,7 @@ type mockBillDAO struct {
 	sumAmountFn        func(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error)
 	aggregateByFieldFn func(ctx context.Context, tenantID string, field string, startDate, endDate string) ([]repository.AggregateResult, error)
 	aggregateDailyFn   func(ctx context.Context, tenantID string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error)
+	fieldDailyFn       func(ctx context.Context, tenantID string, field string, startDate, endDate string) ([]repository.FieldDailyAmount, error)
 }
 
 func (m *mockBillDAO) SumAmount(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error) {
@@ -77,6 +81,12 @@ func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _, _ string)
 func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
 func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
@@ -84,6 +94,39 @@ func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) (
 	return nil, nil
 }
 
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, _ []repository.AmountCNYUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	if m.fieldDailyFn != nil {
+		return m.fieldDailyFn(ctx, tenantID, field, startDate, endDate)
+	}
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(_ context.Context, _, _, _, _, _, _ string) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+// fakeConverter 固定报表币种，汇率按日期取值
+type fakeConverter struct {
+	currency string
+	rates    map[string]float64 // date -> CNY 兑报表币种汇率
+}
+
+func (f *fakeConverter) ReportingCurrency(_ context.Context, _ string) (string, error) {
+	return f.currency, nil
+}
+
+func (f *fakeConverter) FromCNY(_ context.Context, _ string, amountCNY float64, date string) (float64, error) {
+	rate, ok := f.rates[date]
+	if !ok {
+		return 0, fmt.Errorf("no rate for %s", date)
+	}
+	return amountCNY * rate, nil
+}
+
 // --- Test helpers ---
 
 func setupTestService(t *testing.T, dao *mockBillDAO) (*CostService, *miniredis.Miniredis) {
@@ -94,18 +137,28 @@ func setupTestService(t *testing.T, dao *mockBillDAO) (*CostService, *miniredis.
 	return svc, mr
 }
 
+// currentMonthStart 返回当月 1 号（GetCostSummary 当月查询的起始日期）
+func currentMonthStart() string {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02")
+}
+
+// sumByStartDate 按查询起始日期返回金额：start 命中时返回 hit，否则返回 other。
+// GetCostSummary / GetYoYComparison 并行发起各区间查询，mock 不能依赖调用顺序
+func sumByStartDate(start string, hit, other float64) func(context.Context, repository.UnifiedBillFilter) (float64, error) {
+	return func(_ context.Context, filter repository.UnifiedBillFilter) (float64, error) {
+		if filter.StartDate == start {
+			return hit, nil
+		}
+		return other, nil
+	}
+}
+
 // --- Tests ---
 
 func TestGetCostSummary(t *testing.T) {
-	callCount := 0
 	dao := &mockBillDAO{
-		sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
-			callCount++
-			if callCount == 1 {
-				return 1500.0, nil
-			}
-			return 1000.0, nil
-		},
+		sumAmountFn: sumByStartDate(currentMonthStart(), 1500.0, 1000.0),
 	}
 	svc, _ := setupTestService(t, dao)
 
@@ -117,15 +170,8 @@ func TestGetCostSummary(t *testing.T) {
 }
 
 func TestGetCostSummary_LastMonthZero(t *testing.T) {
-	callCount := 0
 	dao := &mockBillDAO{
-		sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
-			callCount++
-			if callCount == 1 {
-				return 500.0, nil
-			}
-			return 0, nil
-		},
+		sumAmountFn: sumByStartDate(currentMonthStart(), 500.0, 0),
 	}
 	svc, _ := setupTestService(t, dao)
 
@@ -137,20 +183,17 @@ func TestGetCostSummary_LastMonthZero(t *testing.T) {
 }
 
 func TestGetCostSummary_Cache(t *testing.T) {
-	callCount := 0
+	var callCount atomic.Int32
+	current := currentMonthStart()
 	dao := &mockBillDAO{
-		sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
-			callCount++
-			if callCount <= 3 {
-				if callCount == 1 {
-					return 100.0, nil // 当月
-				}
-				if callCount == 2 {
-					return 80.0, nil // 上月整月
-				}
-				return 50.0, nil // 上月同期
+		sumAmountFn: func(_ context.Context, filter repository.UnifiedBillFilter) (float64, error) {
+			if callCount.Add(1) > 3 {
+				return 999.0, nil
 			}
-			return 999.0, nil
+			if filter.StartDate == current {
+				return 100.0, nil // 当月
+			}
+			return 80.0, nil // 上月整月 / 上月同期
 		},
 	}
 	svc, _ := setupTestService(t, dao)
@@ -165,7 +208,7 @@ func TestGetCostSummary_Cache(t *testing.T) {
 	s2, err := svc.GetCostSummary(ctx, filter)
 	require.NoError(t, err)
 	assert.Equal(t, 100.0, s2.CurrentMonthAmount)
-	assert.Equal(t, 3, callCount)
+	assert.Equal(t, int32(3), callCount.Load())
 }
 
 func TestGetCostTrend_Daily(t *testing.T) {
@@ -277,15 +320,8 @@ func TestGetCostDistribution_Empty(t *testing.T) {
 }
 
 func TestGetYoYComparison(t *testing.T) {
-	callCount := 0
 	dao := &mockBillDAO{
-		sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
-			callCount++
-			if callCount == 1 {
-				return 1200.0, nil
-			}
-			return 1000.0, nil
-		},
+		sumAmountFn: sumByStartDate("2024-01-01", 1200.0, 1000.0),
 	}
 	svc, _ := setupTestService(t, dao)
 
@@ -303,15 +339,8 @@ func TestGetYoYComparison(t *testing.T) {
 }
 
 func TestGetYoYComparison_PreviousZero(t *testing.T) {
-	callCount := 0
 	dao := &mockBillDAO{
-		sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
-			callCount++
-			if callCount == 1 {
-				return 500.0, nil
-			}
-			return 0, nil
-		},
+		sumAmountFn: sumByStartDate("2024-06-01", 500.0, 0),
 	}
 	svc, _ := setupTestService(t, dao)
 
@@ -400,9 +429,14 @@ func TestAggregateWeekly_SundayBelongsToPreviousWeek(t *testing.T) {
 }
 
 func TestGetCostSummary_DateRanges(t *testing.T) {
-	var capturedFilters []repository.UnifiedBillFilter
+	var (
+		mu              sync.Mutex
+		capturedFilters []repository.UnifiedBillFilter
+	)
 	dao := &mockBillDAO{
 		sumAmountFn: func(_ context.Context, filter repository.UnifiedBillFilter) (float64, error) {
+			mu.Lock()
+			defer mu.Unlock()
 			capturedFilters = append(capturedFilters, filter)
 			return 100.0, nil
 		},
@@ -413,11 +447,74 @@ func TestGetCostSummary_DateRanges(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, capturedFilters, 3) // 当月、上月整月、上月同期
 
+	// 三个查询并行执行，按起始日期校验而不依赖调用顺序
 	now := time.Now()
-	expectedCurrentStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02")
-	assert.Equal(t, expectedCurrentStart, capturedFilters[0].StartDate)
+	lastMonthStart := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location()).Format("2006-01-02")
+	starts := make([]string, 0, len(capturedFilters))
+	for _, f := range capturedFilters {
+		starts = append(starts, f.StartDate)
+	}
+	assert.ElementsMatch(t, []string{currentMonthStart(), lastMonthStart, lastMonthStart}, starts)
+}
+
+func TestGetCostTrend_ReportingCurrency(t *testing.T) {
+	dao := &mockBillDAO{
+		aggregateDailyFn: func(_ context.Context, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+			return []repository.DailyAmount{
+				{Date: "2024-01-15", Amount: 100, AmountCNY: 100},
+				{Date: "2024-01-20", Amount: 50, AmountCNY: 50},
+				{Date: "2024-02-10", Amount: 200, AmountCNY: 200},
+			}, nil
+		},
+	}
+	svc, _ := setupTestService(t, dao)
+	svc.SetCurrencyConverter(&fakeConverter{
+		currency: "USD",
+		rates:    map[string]float64{"2024-01-15": 0.1, "2024-01-20": 0.2, "2024-02-10": 0.15},
+	})
 
-	// 第三个查询是上月同期
-	lastMonthSameDay := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
-	assert.Equal(t, lastMonthSameDay.Format("2006-01-02"), capturedFilters[2].StartDate)
+	points, err := svc.GetCostTrend(context.Background(), CostTrendFilter{
+		CostFilter:  CostFilter{TenantID: "t1", StartDate: "2024-01-01", EndDate: "2024-02-28"},
+		Granularity: "monthly",
+	})
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, "USD", points[0].Currency)
+	assert.InDelta(t, 150.0, points[0].AmountCNY, 0.01)
+	// 每日按当日汇率折算后再汇总：100*0.1 + 50*0.2
+	assert.InDelta(t, 20.0, points[0].ReportingAmount, 0.01)
+	assert.InDelta(t, 30.0, points[1].ReportingAmount, 0.01)
+}
+
+func TestGetCostDistribution_ReportingCurrency(t *testing.T) {
+	dao := &mockBillDAO{
+		fieldDailyFn: func(_ context.Context, _ string, _ string, _, _ string) ([]repository.FieldDailyAmount, error) {
+			return []repository.FieldDailyAmount{
+				{Key: "aliyun", Date: "2024-01-01", AmountCNY: 100},
+				{Key: "aliyun", Date: "2024-01-02", AmountCNY: 100},
+				{Key: "aws", Date: "2024-01-02", AmountCNY: 300},
+			}, nil
+		},
+	}
+	svc, _ := setupTestService(t, dao)
+	svc.SetCurrencyConverter(&fakeConverter{
+		currency: "USD",
+		rates:    map[string]float64{"2024-01-01": 0.5, "2024-01-02": 0.1},
+	})
+
+	items, err := svc.GetCostDistribution(context.Background(), CostFilter{
+		TenantID:  "t1",
+		StartDate: "2024-01-01",
+		EndDate:   "2024-01-02",
+	}, "provider")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	// aliyun: 100*0.5 + 100*0.1 = 60; aws: 300*0.1 = 30
+	assert.Equal(t, "aliyun", items[0].Key)
+	assert.Equal(t, "USD", items[0].Currency)
+	assert.InDelta(t, 60.0, items[0].ReportingAmount, 0.01)
+	assert.InDelta(t, 200.0, items[0].AmountCNY, 0.01)
+	assert.InDelta(t, 66.67, items[0].Percent, 0.01)
+	assert.Equal(t, "aws", items[1].Key)
+	assert.InDelta(t, 30.0, items[1].ReportingAmount, 0.01)
 }
@@ -0,0 +1,161 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+)
+
+// maxBacktestDays 单次回测最大天数
+const maxBacktestDays = 180
+
+// BacktestRequest 回测请求
+type BacktestRequest struct {
+	StartDate  string   `json:"start_date"`
+	EndDate    string   `json:"end_date"`
+	Dimensions []string `json:"dimensions"` // 为空时回测全部检测维度
+	Models     []string `json:"models"`     // 为空时回测全部模型
+}
+
+// BacktestResult 回测结果
+type BacktestResult struct {
+	StartDate string          `json:"start_date"`
+	EndDate   string          `json:"end_date"`
+	Currency  string          `json:"currency"`
+	Models    []ModelBacktest `json:"models"`
+}
+
+// ModelBacktest 单个模型的回测统计
+type ModelBacktest struct {
+	Model       string         `json:"model"`
+	Total       int            `json:"total"`
+	BySeverity  map[string]int `json:"by_severity"`
+	ByDimension map[string]int `json:"by_dimension"`
+	ByDate      map[string]int `json:"by_date"`
+}
+
+// Backtest 用历史统一账单回放检测过程，统计各模型在区间内会产生的异常数量
+// 每个模型使用默认参数（mean 模型使用当前阈值），不读取租户模型配置，也不写入异常事件
+func (s *AnomalyService) Backtest(ctx context.Context, tenantID string, req BacktestRequest) (*BacktestResult, error) {
+	start, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_date", costdomain.ErrAnomalyModelInvalid)
+	}
+	end, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid end_date", costdomain.ErrAnomalyModelInvalid)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end_date before start_date", costdomain.ErrAnomalyModelInvalid)
+	}
+	if end.Sub(start) > maxBacktestDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: backtest range exceeds %d days", costdomain.ErrAnomalyModelInvalid, maxBacktestDays)
+	}
+
+	dimensions := req.Dimensions
+	if len(dimensions) == 0 {
+		dimensions = detectDimensions
+	}
+	for _, dim := range dimensions {
+		if _, ok := dimensionFields[dim]; !ok {
+			return nil, fmt.Errorf("%w: unsupported dimension %q", costdomain.ErrAnomalyModelInvalid, dim)
+		}
+	}
+
+	models := req.Models
+	if len(models) == 0 {
+		models = Models
+	}
+	detectors := make([]Detector, 0, len(models))
+	maxWindow := 0
+	for _, model := range models {
+		detector, err := NewDetector(model, s.modelParams(costdomain.AnomalyModelConfig{}))
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, detector)
+		if detector.WindowDays() > maxWindow {
+			maxWindow = detector.WindowDays()
+		}
+	}
+
+	currency, err := s.reportingCurrency(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ModelBacktest, len(detectors))
+	for i, detector := range detectors {
+		results[i] = ModelBacktest{
+			Model:       detector.Name(),
+			BySeverity:  make(map[string]int),
+			ByDimension: make(map[string]int),
+			ByDate:      make(map[string]int),
+		}
+	}
+
+	// 每个维度一次性取回「最大窗口 + 回测区间」的逐日数据，在内存中逐日回放
+	// 按月出账的维度值需要 monthlyHistoryMonths 个月历史，取两者中更早的起点
+	fetchStart := start.AddDate(0, 0, -maxWindow)
+	if monthly := start.AddDate(0, -monthlyHistoryMonths-1, 0); monthly.Before(fetchStart) {
+		fetchStart = monthly
+	}
+	for _, dim := range dimensions {
+		series, err := s.dailyByField(ctx, tenantID, currency, dimensionFields[dim], fetchStart.Format("2006-01-02"), req.EndDate)
+		if err != nil {
+			return nil, fmt.Errorf("aggregate %s: %w", dim, err)
+		}
+		keys := make([]string, 0, len(series))
+		for key := range series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			for _, key := range keys {
+				// 按月出账的维度值与 DetectAnomalies 一致：月初检测上一个完整月份
+				point := DailyPoint{Date: day}
+				monthly := isMonthlySeries(series[key])
+				if monthly {
+					if day.Day() != 1 {
+						continue
+					}
+					point.Date = day.AddDate(0, -1, 0)
+				}
+				point.Amount = series[key][point.Date.Format("2006-01-02")]
+				if point.Amount <= 0 {
+					continue
+				}
+				for i, detector := range detectors {
+					var history []DailyPoint
+					if monthly {
+						history = buildMonthlySeries(series[key], point.Date, monthlyHistoryMonths)
+					} else {
+						history = buildSeries(series[key], day, detector.WindowDays())
+					}
+					if len(history) == 0 {
+						continue
+					}
+					verdict := detector.Detect(history, point)
+					if !verdict.Anomalous {
+						continue
+					}
+					results[i].Total++
+					results[i].BySeverity[verdict.Severity]++
+					results[i].ByDimension[dim]++
+					results[i].ByDate[point.Date.Format("2006-01-02")]++
+				}
+			}
+		}
+	}
+
+	return &BacktestResult{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Currency:  currency,
+		Models:    results,
+	}, nil
+}
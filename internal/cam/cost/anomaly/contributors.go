@@ -0,0 +1,155 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// maxContributorsPerType 每种下钻类型保留的贡献项数量
+const maxContributorsPerType = 5
+
+// contributorBreakdowns 根因下钻类型及对应的统一账单字段
+var contributorBreakdowns = []struct {
+	typ   string
+	field string
+}{
+	{costdomain.ContributorResource, "resource_id"},
+	{costdomain.ContributorRegion, "region"},
+	{costdomain.ContributorChargeType, "charge_type"},
+	{costdomain.ContributorTag, "tags"},
+}
+
+// findContributors 在异常维度值内按资源 / 地域 / 计费类型 / 标签下钻，
+// 找出检测日相对基线 [baselineStart, targetDate) 内每期（日或月）均值增量最大的贡献项
+// 下钻失败不影响异常本身，只记录日志
+func (s *AnomalyService) findContributors(
+	ctx context.Context,
+	tenantID, currency, dimension, value string,
+	baselineStart time.Time,
+	periods int,
+	targetDate time.Time,
+) []costdomain.AnomalyContributor {
+	field := dimensionFields[dimension]
+	date := targetDate.Format("2006-01-02")
+	start := baselineStart.Format("2006-01-02")
+
+	var contributors []costdomain.AnomalyContributor
+	for _, b := range contributorBreakdowns {
+		// 与异常维度相同的字段没有下钻意义（如 region 维度不再按地域拆分）
+		if b.field == field {
+			continue
+		}
+		items, err := s.breakdown(ctx, tenantID, currency, field, value, b.typ, b.field, start, date, periods)
+		if err != nil {
+			s.logger.Warn("find anomaly contributors failed",
+				elog.String("dimension", dimension),
+				elog.String("dimension_value", value),
+				elog.String("breakdown", b.typ),
+				elog.FieldErr(err))
+			continue
+		}
+		contributors = append(contributors, items...)
+	}
+	return contributors
+}
+
+// breakdown 计算单一下钻类型的贡献项，只保留正增量并按增量降序取前 maxContributorsPerType 个
+func (s *AnomalyService) breakdown(
+	ctx context.Context,
+	tenantID, currency, matchField, matchValue, typ, groupField, startDate, date string,
+	periods int,
+) ([]costdomain.AnomalyContributor, error) {
+	daily, err := s.billDAO.AggregateBreakdownDaily(ctx, tenantID, matchField, matchValue, groupField, startDate, date)
+	if err != nil {
+		return nil, err
+	}
+
+	actual := make(map[string]float64)
+	baselineTotal := make(map[string]float64)
+	for _, d := range daily {
+		amount, err := s.toReporting(ctx, currency, d.AmountCNY, d.Date)
+		if err != nil {
+			return nil, err
+		}
+		if d.Date == date {
+			actual[d.Key] += amount
+		} else {
+			baselineTotal[d.Key] += amount
+		}
+	}
+
+	var items []costdomain.AnomalyContributor
+	var totalDelta float64
+	for key, amount := range actual {
+		if key == "" {
+			continue
+		}
+		baseline := baselineTotal[key] / float64(periods)
+		delta := amount - baseline
+		if delta <= 0 {
+			continue
+		}
+		totalDelta += delta
+		items = append(items, costdomain.AnomalyContributor{
+			Type:           typ,
+			Key:            key,
+			ActualAmount:   amount,
+			BaselineAmount: baseline,
+			Delta:          delta,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Delta != items[j].Delta {
+			return items[i].Delta > items[j].Delta
+		}
+		return items[i].Key < items[j].Key
+	})
+	if len(items) > maxContributorsPerType {
+		items = items[:maxContributorsPerType]
+	}
+	for i := range items {
+		items[i].SharePct = items[i].Delta / totalDelta * 100
+	}
+	return items, nil
+}
+
+// contributorsContent 将贡献项转换为告警内容中的通用结构
+func contributorsContent(contributors []costdomain.AnomalyContributor) []map[string]any {
+	items := make([]map[string]any, 0, len(contributors))
+	for _, c := range contributors {
+		items = append(items, map[string]any{
+			"type":            c.Type,
+			"key":             c.Key,
+			"actual_amount":   c.ActualAmount,
+			"baseline_amount": c.BaselineAmount,
+			"delta":           c.Delta,
+			"share_pct":       c.SharePct,
+		})
+	}
+	return items
+}
+
+// summarizeContributors 生成贡献项文本摘要，每种类型一行，用于告警消息
+func summarizeContributors(contributors []costdomain.AnomalyContributor, currency string) string {
+	var lines []string
+	for _, b := range contributorBreakdowns {
+		var parts []string
+		for _, c := range contributors {
+			if c.Type != b.typ {
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s +%.2f %s (%.0f%%)", c.Key, c.Delta, currency, c.SharePct))
+		}
+		if len(parts) > 0 {
+			lines = append(lines, fmt.Sprintf("%s: %s", b.typ, strings.Join(parts, ", ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,327 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+)
+
+const (
+	// defaultSensitivity 默认置信带宽度（标准差倍数）
+	defaultSensitivity = 3.0
+
+	// defaultEWMAAlpha 默认 EWMA 平滑系数
+	defaultEWMAAlpha = 0.3
+
+	// seasonalWindowDays 季节性模型窗口：12 周，覆盖每个星期几 12 个样本及 2~3 个月初
+	seasonalWindowDays = 84
+
+	// minBandRatio 置信带最小半宽（占期望值比例），避免平稳序列上的微小波动触发告警
+	minBandRatio = 0.1
+
+	// madScale MAD 换算为标准差的系数（正态分布）
+	madScale = 1.4826
+)
+
+// DailyPoint 日成本数据点
+type DailyPoint struct {
+	Date   time.Time
+	Amount float64
+}
+
+// Verdict 检测结论
+type Verdict struct {
+	Expected     float64 // 期望值（基线）
+	Lower        float64 // 正常区间下界
+	Upper        float64 // 正常区间上界
+	DeviationPct float64 // 相对期望值的偏离百分比
+	Anomalous    bool
+	Severity     string
+}
+
+// Detector 异常检测模型
+// 只检测成本突增：实际值超出正常区间上界才判定为异常
+type Detector interface {
+	// Name 模型标识，记录到 CostAnomaly.Model
+	Name() string
+	// WindowDays 所需历史窗口天数
+	WindowDays() int
+	// Detect 根据历史日成本序列（按日期升序、缺失日补零）判断当日成本是否异常
+	Detect(history []DailyPoint, current DailyPoint) Verdict
+}
+
+// ModelParams 模型参数，零值使用默认值
+type ModelParams struct {
+	ThresholdPct float64 // mean 模型偏离阈值百分比
+	Sensitivity  float64 // 置信带宽度（标准差倍数）
+	Alpha        float64 // EWMA 平滑系数
+	WindowDays   int     // 历史窗口天数
+}
+
+// Models 支持的检测模型
+var Models = []string{
+	costdomain.AnomalyModelMean,
+	costdomain.AnomalyModelSeasonal,
+	costdomain.AnomalyModelMAD,
+	costdomain.AnomalyModelEWMA,
+}
+
+// NewDetector 根据模型名称创建检测器
+func NewDetector(model string, params ModelParams) (Detector, error) {
+	if params.Sensitivity <= 0 {
+		params.Sensitivity = defaultSensitivity
+	}
+	if params.ThresholdPct <= 0 {
+		params.ThresholdPct = defaultThresholdPct
+	}
+	if params.Alpha <= 0 || params.Alpha > 1 {
+		params.Alpha = defaultEWMAAlpha
+	}
+	if params.WindowDays < 0 {
+		return nil, fmt.Errorf("%w: window_days must be positive", costdomain.ErrAnomalyModelInvalid)
+	}
+
+	switch model {
+	case costdomain.AnomalyModelMean, "":
+		return &meanDetector{thresholdPct: params.ThresholdPct, window: windowOr(params.WindowDays, baselineWindowDays)}, nil
+	case costdomain.AnomalyModelSeasonal:
+		return &seasonalDetector{k: params.Sensitivity, window: windowOr(params.WindowDays, seasonalWindowDays)}, nil
+	case costdomain.AnomalyModelMAD:
+		return &madDetector{k: params.Sensitivity, window: windowOr(params.WindowDays, baselineWindowDays)}, nil
+	case costdomain.AnomalyModelEWMA:
+		return &ewmaDetector{k: params.Sensitivity, alpha: params.Alpha, window: windowOr(params.WindowDays, baselineWindowDays)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", costdomain.ErrAnomalyModelInvalid, model)
+	}
+}
+
+func windowOr(days, def int) int {
+	if days > 0 {
+		return days
+	}
+	return def
+}
+
+// meanDetector 窗口日均值 + 固定偏离阈值（原有检测逻辑）
+type meanDetector struct {
+	thresholdPct float64
+	window       int
+}
+
+func (d *meanDetector) Name() string    { return costdomain.AnomalyModelMean }
+func (d *meanDetector) WindowDays() int { return d.window }
+
+func (d *meanDetector) Detect(history []DailyPoint, current DailyPoint) Verdict {
+	var total float64
+	for _, p := range history {
+		total += p.Amount
+	}
+	days := len(history)
+	if days == 0 {
+		days = 1
+	}
+	return d.judge(total/float64(days), current.Amount)
+}
+
+// judge 按固定阈值判断，严重程度沿用 classifySeverity 的 100%/200% 分级
+func (d *meanDetector) judge(expected, actual float64) Verdict {
+	v := Verdict{
+		Expected: expected,
+		Upper:    expected * (1 + d.thresholdPct/100),
+	}
+	if expected <= 0 {
+		return v
+	}
+	v.DeviationPct = (actual - expected) / expected * 100
+	if v.DeviationPct > d.thresholdPct {
+		v.Anomalous = true
+		v.Severity = classifySeverity(v.DeviationPct)
+	}
+	return v
+}
+
+// seasonalDetector 季节性基线
+// 月初（1 号）与历史月初比较，其余日期与历史同星期几（不含月初）比较，
+// 避免批量出账的周一、月初被误判为突增
+type seasonalDetector struct {
+	k      float64
+	window int
+}
+
+func (d *seasonalDetector) Name() string    { return costdomain.AnomalyModelSeasonal }
+func (d *seasonalDetector) WindowDays() int { return d.window }
+
+func (d *seasonalDetector) Detect(history []DailyPoint, current DailyPoint) Verdict {
+	monthStart := current.Date.Day() == 1
+	var samples []float64
+	for _, p := range history {
+		if monthStart {
+			if p.Date.Day() == 1 {
+				samples = append(samples, p.Amount)
+			}
+			continue
+		}
+		if p.Date.Weekday() == current.Date.Weekday() && p.Date.Day() != 1 {
+			samples = append(samples, p.Amount)
+		}
+	}
+	// 同期样本不足时退化为全窗口
+	if len(samples) < 2 {
+		samples = amounts(history)
+	}
+	mean, std := meanStd(samples)
+	return bandVerdict(mean, std, d.k, current.Amount)
+}
+
+// madDetector 滚动中位数 + MAD，对窗口内的历史尖峰不敏感
+type madDetector struct {
+	k      float64
+	window int
+}
+
+func (d *madDetector) Name() string    { return costdomain.AnomalyModelMAD }
+func (d *madDetector) WindowDays() int { return d.window }
+
+func (d *madDetector) Detect(history []DailyPoint, current DailyPoint) Verdict {
+	values := amounts(history)
+	med := median(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - med)
+	}
+	return bandVerdict(med, median(deviations)*madScale, d.k, current.Amount)
+}
+
+// ewmaDetector 指数加权均值与方差，近期数据权重更高
+type ewmaDetector struct {
+	k      float64
+	alpha  float64
+	window int
+}
+
+func (d *ewmaDetector) Name() string    { return costdomain.AnomalyModelEWMA }
+func (d *ewmaDetector) WindowDays() int { return d.window }
+
+func (d *ewmaDetector) Detect(history []DailyPoint, current DailyPoint) Verdict {
+	if len(history) == 0 {
+		return Verdict{}
+	}
+	mean := history[0].Amount
+	var variance float64
+	for _, p := range history[1:] {
+		diff := p.Amount - mean
+		incr := d.alpha * diff
+		mean += incr
+		variance = (1 - d.alpha) * (variance + diff*incr)
+	}
+	return bandVerdict(mean, math.Sqrt(variance), d.k, current.Amount)
+}
+
+// bandVerdict 按 expected ± k*spread 置信带判断
+// 严重程度按超出幅度（以半带宽为单位）分级：1~2 倍 info，2~3 倍 warning，3 倍以上 critical
+func bandVerdict(expected, spread, k, actual float64) Verdict {
+	halfBand := k * math.Max(spread, expected*minBandRatio)
+	v := Verdict{
+		Expected: expected,
+		Lower:    math.Max(0, expected-halfBand),
+		Upper:    expected + halfBand,
+	}
+	if expected <= 0 || halfBand <= 0 {
+		return v
+	}
+	v.DeviationPct = (actual - expected) / expected * 100
+	if actual <= v.Upper {
+		return v
+	}
+
+	v.Anomalous = true
+	score := (actual - expected) / halfBand
+	switch {
+	case score > 3:
+		v.Severity = "critical"
+	case score > 2:
+		v.Severity = "warning"
+	default:
+		v.Severity = "info"
+	}
+	return v
+}
+
+func amounts(points []DailyPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Amount
+	}
+	return values
+}
+
+func meanStd(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)))
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// buildSeries 构造 [end-days, end) 区间的日成本序列，缺失日补零
+func buildSeries(byDate map[string]float64, end time.Time, days int) []DailyPoint {
+	series := make([]DailyPoint, 0, days)
+	for d := end.AddDate(0, 0, -days); d.Before(end); d = d.AddDate(0, 0, 1) {
+		series = append(series, DailyPoint{Date: d, Amount: byDate[d.Format("2006-01-02")]})
+	}
+	return series
+}
+
+// isMonthlySeries 判断是否为按月出账的序列：存在成本且非零成本全部落在月初
+func isMonthlySeries(byDate map[string]float64) bool {
+	found := false
+	for date, amount := range byDate {
+		if amount == 0 {
+			continue
+		}
+		if !strings.HasSuffix(date, "-01") {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// buildMonthlySeries 构造 [month-months, month) 区间的月初成本序列，缺失月份补零；
+// 首个有成本的月份之前的空月不计入，避免新账号的空白月份拉低基线
+func buildMonthlySeries(byDate map[string]float64, month time.Time, months int) []DailyPoint {
+	var series []DailyPoint
+	for m := month.AddDate(0, -months, 0); m.Before(month); m = m.AddDate(0, 1, 0) {
+		amount := byDate[m.Format("2006-01-02")]
+		if len(series) == 0 && amount == 0 {
+			continue
+		}
+		series = append(series, DailyPoint{Date: m, Amount: amount})
+	}
+	return series
+}
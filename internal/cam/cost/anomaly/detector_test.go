@@ -0,0 +1,136 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// weeklySeries 构造以 end 为界的历史序列：周一 monday，其余日期 other
+func weeklySeries(end time.Time, days int, monday, other float64) map[string]float64 {
+	byDate := make(map[string]float64)
+	for d := end.AddDate(0, 0, -days); d.Before(end); d = d.AddDate(0, 0, 1) {
+		amount := other
+		if d.Weekday() == time.Monday {
+			amount = monday
+		}
+		byDate[d.Format("2006-01-02")] = amount
+	}
+	return byDate
+}
+
+func mustDetector(t *testing.T, model string) Detector {
+	t.Helper()
+	d, err := NewDetector(model, ModelParams{})
+	require.NoError(t, err)
+	return d
+}
+
+func TestNewDetector(t *testing.T) {
+	for _, model := range Models {
+		d := mustDetector(t, model)
+		assert.Equal(t, model, d.Name())
+		assert.Greater(t, d.WindowDays(), 0)
+	}
+
+	d := mustDetector(t, "")
+	assert.Equal(t, costdomain.AnomalyModelMean, d.Name(), "未指定模型时使用 mean")
+
+	d, err := NewDetector(costdomain.AnomalyModelMAD, ModelParams{WindowDays: 14})
+	require.NoError(t, err)
+	assert.Equal(t, 14, d.WindowDays())
+
+	_, err = NewDetector("prophet", ModelParams{})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+}
+
+func TestSeasonalDetector_WeeklyBatch(t *testing.T) {
+	// 2024-03-11 为周一；每周一批量出账 500，其余日期 100
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	byDate := weeklySeries(monday, seasonalWindowDays, 500, 100)
+
+	seasonal := mustDetector(t, costdomain.AnomalyModelSeasonal)
+	mean := mustDetector(t, costdomain.AnomalyModelMean)
+
+	history := buildSeries(byDate, monday, seasonal.WindowDays())
+	v := seasonal.Detect(history, DailyPoint{Date: monday, Amount: 520})
+	assert.False(t, v.Anomalous, "周一批量出账不应被季节性模型判定为异常")
+	assert.InDelta(t, 500, v.Expected, 0.01)
+
+	v = mean.Detect(buildSeries(byDate, monday, mean.WindowDays()), DailyPoint{Date: monday, Amount: 520})
+	assert.True(t, v.Anomalous, "mean 模型会在周一误报")
+
+	// 周二出现周一量级的成本才是真正的突增
+	tuesday := monday.AddDate(0, 0, 1)
+	byDate = weeklySeries(tuesday, seasonalWindowDays, 500, 100)
+	v = seasonal.Detect(buildSeries(byDate, tuesday, seasonal.WindowDays()), DailyPoint{Date: tuesday, Amount: 500})
+	require.True(t, v.Anomalous)
+	assert.Equal(t, "critical", v.Severity)
+	assert.InDelta(t, 100, v.Expected, 0.01)
+	assert.Greater(t, v.Upper, v.Expected)
+}
+
+func TestSeasonalDetector_MonthStart(t *testing.T) {
+	// 每月 1 号出账 1000，其余日期 100
+	target := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	byDate := make(map[string]float64)
+	for d := target.AddDate(0, 0, -seasonalWindowDays); d.Before(target); d = d.AddDate(0, 0, 1) {
+		amount := 100.0
+		if d.Day() == 1 {
+			amount = 1000
+		}
+		byDate[d.Format("2006-01-02")] = amount
+	}
+
+	seasonal := mustDetector(t, costdomain.AnomalyModelSeasonal)
+	v := seasonal.Detect(buildSeries(byDate, target, seasonal.WindowDays()), DailyPoint{Date: target, Amount: 1050})
+	assert.False(t, v.Anomalous, "月初出账应与历史月初比较")
+	assert.InDelta(t, 1000, v.Expected, 0.01)
+}
+
+func TestMADDetector_RobustToPastSpike(t *testing.T) {
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	byDate := weeklySeries(end, baselineWindowDays, 100, 100)
+	// 窗口内出现一次历史尖峰
+	byDate["2024-03-15"] = 5000
+
+	mad := mustDetector(t, costdomain.AnomalyModelMAD)
+	v := mad.Detect(buildSeries(byDate, end, mad.WindowDays()), DailyPoint{Date: end, Amount: 400})
+	assert.InDelta(t, 100, v.Expected, 0.01, "中位数不受历史尖峰影响")
+	assert.True(t, v.Anomalous)
+
+	v = mad.Detect(buildSeries(byDate, end, mad.WindowDays()), DailyPoint{Date: end, Amount: 120})
+	assert.False(t, v.Anomalous, "最小置信带内的波动不告警")
+}
+
+func TestEWMADetector(t *testing.T) {
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	// 成本从 100 逐步上升到 200，EWMA 期望值应贴近近期水平
+	byDate := make(map[string]float64)
+	for i := 0; i < baselineWindowDays; i++ {
+		d := end.AddDate(0, 0, -baselineWindowDays+i)
+		byDate[d.Format("2006-01-02")] = 100 + float64(i)*100/float64(baselineWindowDays-1)
+	}
+
+	ewma := mustDetector(t, costdomain.AnomalyModelEWMA)
+	history := buildSeries(byDate, end, ewma.WindowDays())
+	v := ewma.Detect(history, DailyPoint{Date: end, Amount: 205})
+	assert.False(t, v.Anomalous)
+	assert.Greater(t, v.Expected, 180.0)
+
+	v = ewma.Detect(history, DailyPoint{Date: end, Amount: 600})
+	assert.True(t, v.Anomalous)
+	assert.Equal(t, "critical", v.Severity)
+}
+
+func TestBandVerdict_Severity(t *testing.T) {
+	// expected=100, spread=10, k=3 → 半带宽 30
+	assert.False(t, bandVerdict(100, 10, 3, 130).Anomalous)
+	assert.Equal(t, "info", bandVerdict(100, 10, 3, 150).Severity)
+	assert.Equal(t, "warning", bandVerdict(100, 10, 3, 175).Severity)
+	assert.Equal(t, "critical", bandVerdict(100, 10, 3, 200).Severity)
+	assert.False(t, bandVerdict(0, 0, 3, 200).Anomalous, "无基线时不判定")
+}
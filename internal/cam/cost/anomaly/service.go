@@ -4,34 +4,49 @@ package anomaly
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
 	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
 	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/gotomicro/ego/core/elog"
 )
 
 const (
-	// alertTypeCostAnomaly 成本异常告警类型
-	alertTypeCostAnomaly = "cost_anomaly"
-
 	// defaultThresholdPct 默认偏离阈值百分比
 	defaultThresholdPct = 50.0
 
 	// baselineWindowDays 基线计算窗口（天）
 	baselineWindowDays = 30
+
+	// monthlyHistoryMonths 按月出账维度值的历史窗口（月）
+	monthlyHistoryMonths = 12
 )
 
 // 异常检测维度
-var detectDimensions = []string{"cloud_account", "service_type", "service_tree_node"}
+var detectDimensions = []string{"provider", "cloud_account", "service_type", "region"}
+
+// DimensionUnitCost 单位成本异常维度（由单位经济服务检测，可单独配置检测模型）
+const DimensionUnitCost = "unit_cost"
+
+// dimensionFields 检测维度对应的统一账单字段
+var dimensionFields = map[string]string{
+	"provider":      "provider",
+	"cloud_account": "account_name",
+	"service_type":  "service_type",
+	"region":        "region",
+}
 
 // AnomalyService 异常检测服务
 type AnomalyService struct {
 	anomalyDAO   repository.AnomalyDAO
 	billDAO      repository.BillDAO
+	modelDAO     repository.AnomalyModelConfigDAO
 	alertSvc     *alertservice.AlertService
+	converter    exchange.ReportingConverter
 	logger       *elog.Component
 	thresholdPct float64 // 偏离阈值百分比
 }
@@ -57,20 +72,38 @@ func (s *AnomalyService) SetThreshold(pct float64) {
 	s.thresholdPct = pct
 }
 
+// SetCurrencyConverter 设置报表币种换算器（可选注入，未设置时按人民币检测）
+func (s *AnomalyService) SetCurrencyConverter(converter exchange.ReportingConverter) {
+	s.converter = converter
+}
+
+// SetModelConfigDAO 设置检测模型配置 DAO（可选注入，未设置时所有维度使用 mean 模型）
+func (s *AnomalyService) SetModelConfigDAO(dao repository.AnomalyModelConfigDAO) {
+	s.modelDAO = dao
+}
+
 // DetectAnomalies 每日异常检测
+// 当日成本与基线均按账单日期汇率折算为租户报表币种后比较，各维度按租户配置选择检测模型
 func (s *AnomalyService) DetectAnomalies(ctx context.Context, tenantID, date string) error {
-	// 计算基线窗口
 	targetDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
 		return fmt.Errorf("parse date %q: %w", date, err)
 	}
-	baselineStart := targetDate.AddDate(0, 0, -baselineWindowDays).Format("2006-01-02")
-	baselineEnd := targetDate.AddDate(0, 0, -1).Format("2006-01-02")
+
+	currency, err := s.reportingCurrency(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	detectors, err := s.detectorsFor(ctx, tenantID)
+	if err != nil {
+		return err
+	}
 
 	var anomalies []costdomain.CostAnomaly
 
 	for _, dim := range detectDimensions {
-		dimAnomalies, err := s.detectForDimension(ctx, tenantID, dim, date, baselineStart, baselineEnd)
+		dimAnomalies, err := s.detectForDimension(ctx, tenantID, currency, dim, detectors[dim], targetDate)
 		if err != nil {
 			s.logger.Error("detect anomalies for dimension failed",
 				elog.String("dimension", dim),
@@ -81,6 +114,11 @@ func (s *AnomalyService) DetectAnomalies(ctx context.Context, tenantID, date str
 		anomalies = append(anomalies, dimAnomalies...)
 	}
 
+	return s.RecordAnomalies(ctx, anomalies)
+}
+
+// RecordAnomalies 保存异常事件并发送告警
+func (s *AnomalyService) RecordAnomalies(ctx context.Context, anomalies []costdomain.CostAnomaly) error {
 	if len(anomalies) == 0 {
 		return nil
 	}
@@ -106,73 +144,277 @@ func (s *AnomalyService) DetectAnomalies(ctx context.Context, tenantID, date str
 // detectForDimension 对单个维度执行异常检测
 func (s *AnomalyService) detectForDimension(
 	ctx context.Context,
-	tenantID, dimension, date, baselineStart, baselineEnd string,
+	tenantID, currency, dimension string,
+	detector Detector,
+	targetDate time.Time,
 ) ([]costdomain.CostAnomaly, error) {
-	// 获取基线数据：过去 30 天按维度聚合的日均成本
-	baselineDays, err := s.billDAO.AggregateDailyAmount(ctx, tenantID, baselineStart, baselineEnd, repository.UnifiedBillFilter{
-		TenantID: tenantID,
-	})
+	date := targetDate.Format("2006-01-02")
+	field := dimensionFields[dimension]
+	baselineStart := targetDate.AddDate(0, 0, -detector.WindowDays()).Format("2006-01-02")
+	baselineEnd := targetDate.AddDate(0, 0, -1).Format("2006-01-02")
+
+	// 按月出账的维度值只有月初数据，月初时单独按月检测，不参与逐日检测
+	anomalies, monthlyKeys, err := s.detectMonthly(ctx, tenantID, currency, dimension, detector, targetDate)
 	if err != nil {
-		return nil, fmt.Errorf("aggregate baseline: %w", err)
+		return nil, err
 	}
 
 	// 获取当日按维度聚合的成本
-	currentCosts, err := s.billDAO.AggregateByField(ctx, tenantID, dimension, date, date, repository.UnifiedBillFilter{})
+	currentCosts, err := s.billDAO.AggregateByField(ctx, tenantID, field, date, date, repository.UnifiedBillFilter{})
 	if err != nil {
 		return nil, fmt.Errorf("aggregate current costs: %w", err)
 	}
+	if len(currentCosts) == 0 {
+		return anomalies, nil
+	}
 
-	// 计算基线：按维度值聚合的日均成本
-	baselineByDim, err := s.computeBaseline(ctx, tenantID, dimension, baselineStart, baselineEnd)
+	// mean 模型只需窗口总额；其他模型需要逐日序列
+	var baselineByDim map[string]float64
+	var seriesByDim map[string]map[string]float64
+	mean, isMean := detector.(*meanDetector)
+	if isMean {
+		baselineByDim, err = s.computeBaseline(ctx, tenantID, currency, field, baselineStart, baselineEnd)
+	} else {
+		seriesByDim, err = s.dailyByField(ctx, tenantID, currency, field, baselineStart, baselineEnd)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("compute baseline: %w", err)
 	}
-	// 使用 baselineDays 来确定实际天数
-	dayCount := len(baselineDays)
-	if dayCount == 0 {
-		dayCount = baselineWindowDays
-	}
-	_ = dayCount // baseline already computed in computeBaseline
 
-	var anomalies []costdomain.CostAnomaly
 	for _, cur := range currentCosts {
-		baseline, ok := baselineByDim[cur.Key]
-		if !ok || baseline <= 0 {
-			// 无基线数据，跳过（或基线为零）
+		if monthlyKeys[cur.Key] {
 			continue
 		}
+		actual, err := s.toReporting(ctx, currency, cur.AmountCNY, date)
+		if err != nil {
+			return nil, fmt.Errorf("convert current costs: %w", err)
+		}
 
-		deviationPct := (cur.AmountCNY - baseline) / baseline * 100
-		if deviationPct <= s.thresholdPct {
+		var verdict Verdict
+		if isMean {
+			verdict = mean.judge(baselineByDim[cur.Key], actual)
+		} else {
+			history := buildSeries(seriesByDim[cur.Key], targetDate, detector.WindowDays())
+			verdict = detector.Detect(history, DailyPoint{Date: targetDate, Amount: actual})
+		}
+		// 无基线数据（或基线为零）时不判定
+		if !verdict.Anomalous {
 			continue
 		}
 
-		severity := classifySeverity(deviationPct)
-		cause := fmt.Sprintf("成本突增: %s=%s 日成本 %.2f 元，偏离基线 %.2f 元 %.0f%%",
-			dimension, cur.Key, cur.AmountCNY, baseline, deviationPct)
-
 		anomalies = append(anomalies, costdomain.CostAnomaly{
 			Dimension:      dimension,
 			DimensionValue: cur.Key,
 			AnomalyDate:    date,
-			ActualAmount:   cur.AmountCNY,
-			BaselineAmount: baseline,
-			DeviationPct:   deviationPct,
-			Severity:       severity,
-			PossibleCause:  cause,
-			TenantID:       tenantID,
+			ActualAmount:   actual,
+			BaselineAmount: verdict.Expected,
+			ExpectedLower:  verdict.Lower,
+			ExpectedUpper:  verdict.Upper,
+			Model:          detector.Name(),
+			Currency:       currency,
+			DeviationPct:   verdict.DeviationPct,
+			Severity:       verdict.Severity,
+			PossibleCause:  describeCause(dimension, cur.Key, currency, detector.Name(), "日", actual, verdict),
+			Contributors: s.findContributors(ctx, tenantID, currency, dimension, cur.Key,
+				targetDate.AddDate(0, 0, -detector.WindowDays()), detector.WindowDays(), targetDate),
+			TenantID: tenantID,
 		})
 	}
 
 	return anomalies, nil
 }
 
+// detectMonthly 月初检测按月出账的维度值
+// 这类维度值的账单全部记在每月 1 号，当月 1 号的记录仍在累计，
+// 因此检测上一个完整月份，与其之前至多 monthlyHistoryMonths 个月的月初金额比较；
+// 返回异常及按月出账的维度值集合（逐日检测需跳过）
+func (s *AnomalyService) detectMonthly(
+	ctx context.Context,
+	tenantID, currency, dimension string,
+	detector Detector,
+	targetDate time.Time,
+) ([]costdomain.CostAnomaly, map[string]bool, error) {
+	if targetDate.Day() != 1 {
+		return nil, nil, nil
+	}
+	month := targetDate.AddDate(0, -1, 0)
+	start := month.AddDate(0, -monthlyHistoryMonths, 0)
+	seriesByDim, err := s.dailyByField(ctx, tenantID, currency, dimensionFields[dimension],
+		start.Format("2006-01-02"), targetDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("compute monthly baseline: %w", err)
+	}
+
+	keys := make([]string, 0, len(seriesByDim))
+	for key, byDate := range seriesByDim {
+		if isMonthlySeries(byDate) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	monthlyKeys := make(map[string]bool, len(keys))
+	var anomalies []costdomain.CostAnomaly
+	for _, key := range keys {
+		byDate := seriesByDim[key]
+		monthlyKeys[key] = true
+
+		actual := byDate[month.Format("2006-01-02")]
+		history := buildMonthlySeries(byDate, month, monthlyHistoryMonths)
+		if actual <= 0 || len(history) == 0 {
+			continue
+		}
+		verdict := detector.Detect(history, DailyPoint{Date: month, Amount: actual})
+		if !verdict.Anomalous {
+			continue
+		}
+
+		anomalies = append(anomalies, costdomain.CostAnomaly{
+			Dimension:      dimension,
+			DimensionValue: key,
+			AnomalyDate:    month.Format("2006-01-02"),
+			ActualAmount:   actual,
+			BaselineAmount: verdict.Expected,
+			ExpectedLower:  verdict.Lower,
+			ExpectedUpper:  verdict.Upper,
+			Model:          detector.Name(),
+			Currency:       currency,
+			DeviationPct:   verdict.DeviationPct,
+			Severity:       verdict.Severity,
+			PossibleCause:  describeCause(dimension, key, currency, detector.Name(), "月", actual, verdict),
+			Contributors:   s.findContributors(ctx, tenantID, currency, dimension, key, history[0].Date, len(history), month),
+			TenantID:       tenantID,
+		})
+	}
+	return anomalies, monthlyKeys, nil
+}
+
+// describeCause 生成异常原因描述，period 为检测粒度（日 / 月）
+func describeCause(dimension, value, currency, model, period string, actual float64, verdict Verdict) string {
+	unit := "元"
+	if currency != exchange.CurrencyCNY {
+		unit = currency
+	}
+	if model == costdomain.AnomalyModelMean {
+		return fmt.Sprintf("成本突增: %s=%s %s成本 %.2f %s，偏离基线 %.2f %s %.0f%%",
+			dimension, value, period, actual, unit, verdict.Expected, unit, verdict.DeviationPct)
+	}
+	return fmt.Sprintf("成本突增: %s=%s %s成本 %.2f %s，超出 %s 模型预期区间 [%.2f, %.2f] %s（基线 %.2f，偏离 %.0f%%）",
+		dimension, value, period, actual, unit, model, verdict.Lower, verdict.Upper, unit, verdict.Expected, verdict.DeviationPct)
+}
+
+// detectorsFor 按租户配置确定各维度的检测模型
+// 优先级：维度配置 > 租户默认配置（dimension 为空）> mean 模型
+func (s *AnomalyService) detectorsFor(ctx context.Context, tenantID string) (map[string]Detector, error) {
+	configs, err := s.modelConfigs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	detectors := make(map[string]Detector, len(detectDimensions))
+	for _, dim := range detectDimensions {
+		detectors[dim] = s.detectorFor(configs, tenantID, dim)
+	}
+	return detectors, nil
+}
+
+// DetectorFor 按租户配置确定单个维度的检测模型（供单位成本等外部检测使用）
+func (s *AnomalyService) DetectorFor(ctx context.Context, tenantID, dimension string) (Detector, error) {
+	configs, err := s.modelConfigs(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.detectorFor(configs, tenantID, dimension), nil
+}
+
+// modelConfigs 加载租户的检测模型配置，按维度索引
+func (s *AnomalyService) modelConfigs(ctx context.Context, tenantID string) (map[string]costdomain.AnomalyModelConfig, error) {
+	configs := make(map[string]costdomain.AnomalyModelConfig)
+	if s.modelDAO != nil && tenantID != "" {
+		list, err := s.modelDAO.ListByTenant(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("list anomaly model configs: %w", err)
+		}
+		for _, cfg := range list {
+			configs[cfg.Dimension] = cfg
+		}
+	}
+	return configs, nil
+}
+
+// detectorFor 按维度配置 > 租户默认配置 > mean 模型创建检测器
+func (s *AnomalyService) detectorFor(configs map[string]costdomain.AnomalyModelConfig, tenantID, dimension string) Detector {
+	cfg, ok := configs[dimension]
+	if !ok {
+		cfg = configs[""]
+	}
+	detector, err := NewDetector(cfg.Model, s.modelParams(cfg))
+	if err != nil {
+		// 配置写入时已校验，这里兜底回退到 mean 模型
+		s.logger.Warn("invalid anomaly model config, fallback to mean",
+			elog.String("tenant_id", tenantID),
+			elog.String("dimension", dimension),
+			elog.FieldErr(err))
+		detector, _ = NewDetector(costdomain.AnomalyModelMean, s.modelParams(costdomain.AnomalyModelConfig{}))
+	}
+	return detector
+}
+
+// modelParams 由模型配置生成检测参数
+func (s *AnomalyService) modelParams(cfg costdomain.AnomalyModelConfig) ModelParams {
+	return ModelParams{
+		ThresholdPct: s.thresholdPct,
+		Sensitivity:  cfg.Sensitivity,
+		Alpha:        cfg.Alpha,
+		WindowDays:   cfg.WindowDays,
+	}
+}
+
+// ListModelConfigs 查询租户的检测模型配置
+func (s *AnomalyService) ListModelConfigs(ctx context.Context, tenantID string) ([]costdomain.AnomalyModelConfig, error) {
+	if s.modelDAO == nil {
+		return nil, nil
+	}
+	return s.modelDAO.ListByTenant(ctx, tenantID)
+}
+
+// SaveModelConfig 保存租户维度的检测模型配置，dimension 为空表示租户默认模型
+func (s *AnomalyService) SaveModelConfig(ctx context.Context, cfg costdomain.AnomalyModelConfig) error {
+	if s.modelDAO == nil {
+		return fmt.Errorf("anomaly model config is not enabled")
+	}
+	if cfg.TenantID == "" {
+		return fmt.Errorf("tenant_id is required")
+	}
+	if _, ok := dimensionFields[cfg.Dimension]; cfg.Dimension != "" && cfg.Dimension != DimensionUnitCost && !ok {
+		return fmt.Errorf("%w: unsupported dimension %q", costdomain.ErrAnomalyModelInvalid, cfg.Dimension)
+	}
+	if cfg.Model == "" {
+		return fmt.Errorf("%w: model is required", costdomain.ErrAnomalyModelInvalid)
+	}
+	if cfg.Sensitivity < 0 || cfg.Alpha < 0 || cfg.Alpha > 1 {
+		return fmt.Errorf("%w: sensitivity must be positive and alpha in (0, 1]", costdomain.ErrAnomalyModelInvalid)
+	}
+	if _, err := NewDetector(cfg.Model, s.modelParams(cfg)); err != nil {
+		return err
+	}
+	return s.modelDAO.Upsert(ctx, cfg)
+}
+
+// DeleteModelConfig 删除租户维度的检测模型配置（恢复默认模型）
+func (s *AnomalyService) DeleteModelConfig(ctx context.Context, tenantID, dimension string) error {
+	if s.modelDAO == nil {
+		return nil
+	}
+	return s.modelDAO.Delete(ctx, tenantID, dimension)
+}
+
 // computeBaseline 计算各维度值的日均基线
 func (s *AnomalyService) computeBaseline(
 	ctx context.Context,
-	tenantID, dimension, startDate, endDate string,
+	tenantID, currency, field, startDate, endDate string,
 ) (map[string]float64, error) {
-	results, err := s.billDAO.AggregateByField(ctx, tenantID, dimension, startDate, endDate, repository.UnifiedBillFilter{})
+	totals, err := s.sumByField(ctx, tenantID, currency, field, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -191,13 +433,86 @@ func (s *AnomalyService) computeBaseline(
 		days = 1
 	}
 
-	baseline := make(map[string]float64, len(results))
-	for _, r := range results {
-		baseline[r.Key] = r.AmountCNY / float64(days)
+	baseline := make(map[string]float64, len(totals))
+	for key, total := range totals {
+		baseline[key] = total / float64(days)
 	}
 	return baseline, nil
 }
 
+// sumByField 按维度值汇总区间成本（报表币种）
+// 非人民币时按日聚合后逐日折算，保证基线使用账单当日汇率
+func (s *AnomalyService) sumByField(
+	ctx context.Context,
+	tenantID, currency, field, startDate, endDate string,
+) (map[string]float64, error) {
+	totals := make(map[string]float64)
+	if currency == exchange.CurrencyCNY {
+		results, err := s.billDAO.AggregateByField(ctx, tenantID, field, startDate, endDate, repository.UnifiedBillFilter{})
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range results {
+			totals[r.Key] = r.AmountCNY
+		}
+		return totals, nil
+	}
+
+	daily, err := s.dailyByField(ctx, tenantID, currency, field, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for key, byDate := range daily {
+		for _, amount := range byDate {
+			totals[key] += amount
+		}
+	}
+	return totals, nil
+}
+
+// dailyByField 按维度值、日期聚合区间成本（报表币种），返回 key -> date -> amount
+func (s *AnomalyService) dailyByField(
+	ctx context.Context,
+	tenantID, currency, field, startDate, endDate string,
+) (map[string]map[string]float64, error) {
+	daily, err := s.billDAO.AggregateByFieldDaily(ctx, tenantID, field, startDate, endDate, repository.UnifiedBillFilter{})
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]map[string]float64)
+	for _, d := range daily {
+		amount, err := s.toReporting(ctx, currency, d.AmountCNY, d.Date)
+		if err != nil {
+			return nil, err
+		}
+		if result[d.Key] == nil {
+			result[d.Key] = make(map[string]float64)
+		}
+		result[d.Key][d.Date] += amount
+	}
+	return result, nil
+}
+
+// reportingCurrency 获取租户报表币种，未注入换算器时为 CNY
+func (s *AnomalyService) reportingCurrency(ctx context.Context, tenantID string) (string, error) {
+	if s.converter == nil {
+		return exchange.CurrencyCNY, nil
+	}
+	currency, err := s.converter.ReportingCurrency(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("get reporting currency: %w", err)
+	}
+	return currency, nil
+}
+
+// toReporting 将人民币金额按 date 当日汇率折算为报表币种
+func (s *AnomalyService) toReporting(ctx context.Context, currency string, amountCNY float64, date string) (float64, error) {
+	if s.converter == nil || currency == exchange.CurrencyCNY {
+		return amountCNY, nil
+	}
+	return s.converter.FromCNY(ctx, currency, amountCNY, date)
+}
+
 // classifySeverity 根据偏离百分比确定严重程度
 func classifySeverity(deviationPct float64) string {
 	switch {
@@ -221,18 +536,24 @@ func (s *AnomalyService) emitAlert(ctx context.Context, anomaly costdomain.CostA
 	}
 
 	event := domain.AlertEvent{
-		Type:     domain.AlertType(alertTypeCostAnomaly),
+		Type:     domain.AlertTypeCostAnomaly,
 		Severity: severity,
 		Title:    fmt.Sprintf("成本异常: %s=%s 偏离基线 %.0f%%", anomaly.Dimension, anomaly.DimensionValue, anomaly.DeviationPct),
 		Content: map[string]any{
-			"dimension":       anomaly.Dimension,
-			"dimension_value": anomaly.DimensionValue,
-			"anomaly_date":    anomaly.AnomalyDate,
-			"actual_amount":   anomaly.ActualAmount,
-			"baseline_amount": anomaly.BaselineAmount,
-			"deviation_pct":   anomaly.DeviationPct,
-			"severity":        anomaly.Severity,
-			"possible_cause":  anomaly.PossibleCause,
+			"dimension":           anomaly.Dimension,
+			"dimension_value":     anomaly.DimensionValue,
+			"anomaly_date":        anomaly.AnomalyDate,
+			"actual_amount":       anomaly.ActualAmount,
+			"baseline_amount":     anomaly.BaselineAmount,
+			"expected_lower":      anomaly.ExpectedLower,
+			"expected_upper":      anomaly.ExpectedUpper,
+			"model":               anomaly.Model,
+			"currency":            anomaly.Currency,
+			"deviation_pct":       anomaly.DeviationPct,
+			"severity":            anomaly.Severity,
+			"possible_cause":      anomaly.PossibleCause,
+			"contributors":        contributorsContent(anomaly.Contributors),
+			"contributor_summary": summarizeContributors(anomaly.Contributors, anomaly.Currency),
 		},
 		Source:     fmt.Sprintf("anomaly:%s:%s:%s", anomaly.Dimension, anomaly.DimensionValue, anomaly.AnomalyDate),
 		TenantID:   anomaly.TenantID,
@@ -2,7 +2,10 @@ package anomaly
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
 	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
@@ -66,6 +69,8 @@ type mockBillDAO struct {
 	aggregateByFieldFn func(ctx context.Context, tenantID, field, startDate, endDate string) ([]repository.AggregateResult, error)
 	aggregateDailyFn   func(ctx context.Context, tenantID, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error)
 	sumAmountFn        func(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error)
+	fieldDailyFn       func(ctx context.Context, tenantID, field, startDate, endDate string) ([]repository.FieldDailyAmount, error)
+	breakdownFn        func(ctx context.Context, tenantID, matchField, matchValue, groupField, startDate, endDate string) ([]repository.FieldDailyAmount, error)
 }
 
 func (m *mockBillDAO) AggregateByField(ctx context.Context, tenantID, field, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.AggregateResult, error) {
@@ -124,6 +129,12 @@ func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _, _ string)
 func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
 func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
@@ -131,6 +142,42 @@ func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) (
 	return nil, nil
 }
 
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, _ []repository.AmountCNYUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	if m.fieldDailyFn != nil {
+		return m.fieldDailyFn(ctx, tenantID, field, startDate, endDate)
+	}
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(ctx context.Context, tenantID, matchField, matchValue, groupField, startDate, endDate string) ([]repository.FieldDailyAmount, error) {
+	if m.breakdownFn != nil {
+		return m.breakdownFn(ctx, tenantID, matchField, matchValue, groupField, startDate, endDate)
+	}
+	return nil, nil
+}
+
+// fakeConverter 固定报表币种，汇率按日期取值（未配置日期使用 defaultRate）
+type fakeConverter struct {
+	currency    string
+	defaultRate float64
+	rates       map[string]float64
+}
+
+func (f *fakeConverter) ReportingCurrency(_ context.Context, _ string) (string, error) {
+	return f.currency, nil
+}
+
+func (f *fakeConverter) FromCNY(_ context.Context, _ string, amountCNY float64, date string) (float64, error) {
+	if rate, ok := f.rates[date]; ok {
+		return amountCNY * rate, nil
+	}
+	return amountCNY * f.defaultRate, nil
+}
+
 type mockAlertDAO struct {
 	emittedEvents []alertdomain.AlertEvent
 	listRulesFn   func(ctx context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error)
@@ -164,6 +211,53 @@ func (m *mockAlertDAO) GetPendingEvents(_ context.Context, _ int) ([]alertdomain
 	return nil, nil
 }
 func (m *mockAlertDAO) IncrementRetry(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetEventByID(_ context.Context, _ int64) (alertdomain.AlertEvent, error) {
+	return alertdomain.AlertEvent{}, nil
+}
+func (m *mockAlertDAO) ResolveEvents(_ context.Context, _ string, _ alertdomain.EventTransition) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) AckEvent(_ context.Context, _ int64, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AssignEvent(_ context.Context, _ int64, _ string, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AppendEventHistory(_ context.Context, _ int64, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) SetAckDeadline(_ context.Context, _ int64, _ *time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) RenotifyEvent(_ context.Context, _ int64, _ time.Time, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) GetRenotifyEvents(_ context.Context, _ time.Time, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) GetState(_ context.Context, _ string) (alertdomain.AlertState, error) {
+	return alertdomain.AlertState{}, nil
+}
+func (m *mockAlertDAO) RecordOccurrence(_ context.Context, state alertdomain.AlertState, _ time.Time) (alertdomain.AlertState, error) {
+	state.Count = 1
+	return state, nil
+}
+func (m *mockAlertDAO) MarkStateEscalated(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) MarkStateNotified(_ context.Context, _ string, _ time.Time, _ time.Duration) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) SetStateLastEvent(_ context.Context, _ string, _ int64) error { return nil }
+func (m *mockAlertDAO) ResolveState(_ context.Context, _ alertdomain.AlertState, _ time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) ListActiveStates(_ context.Context) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) ListActiveStatesByType(_ context.Context, _ string, _ alertdomain.AlertType) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
 func (m *mockAlertDAO) CreateChannel(_ context.Context, _ alertdomain.NotificationChannel) (int64, error) {
 	return 0, nil
 }
@@ -180,6 +274,21 @@ func (m *mockAlertDAO) DeleteChannel(_ context.Context, _ int64) error { return
 func (m *mockAlertDAO) GetChannelsByIDs(_ context.Context, _ []int64) ([]alertdomain.NotificationChannel, error) {
 	return nil, nil
 }
+func (m *mockAlertDAO) CreateTemplate(_ context.Context, _ alertdomain.MessageTemplate) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateTemplate(_ context.Context, _ alertdomain.MessageTemplate) error {
+	return nil
+}
+func (m *mockAlertDAO) GetTemplateByID(_ context.Context, _ int64) (alertdomain.MessageTemplate, error) {
+	return alertdomain.MessageTemplate{}, nil
+}
+func (m *mockAlertDAO) ListTemplates(_ context.Context, _ alertdomain.MessageTemplateFilter) ([]alertdomain.MessageTemplate, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteTemplate(_ context.Context, _ int64) error {
+	return nil
+}
 func (m *mockAlertDAO) InitIndexes(_ context.Context) error { return nil }
 
 // ========== Test Setup ==========
@@ -496,3 +605,414 @@ func TestSetThreshold(t *testing.T) {
 	svc.SetThreshold(30.0)
 	assert.Equal(t, 30.0, svc.thresholdPct)
 }
+
+func TestDetectAnomalies_ReportingCurrency(t *testing.T) {
+	anomalyDAO := &mockAnomalyDAO{}
+	// 人民币口径：基线 100/天，当日 150 → 偏离 50%，不触发
+	// 美元口径：基线期汇率 0.1 → 10 USD/天，当日汇率 0.2 → 30 USD，偏离 200%
+	billDAO := &mockBillDAO{
+		aggregateByFieldFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.AggregateResult, error) {
+			if field == "service_type" && startDate == "2024-01-15" && endDate == "2024-01-15" {
+				return []repository.AggregateResult{{Key: "ecs", AmountCNY: 150}}, nil
+			}
+			return nil, nil
+		},
+		fieldDailyFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.FieldDailyAmount, error) {
+			if field != "service_type" {
+				return nil, nil
+			}
+			assert.Equal(t, "2023-12-16", startDate)
+			assert.Equal(t, "2024-01-14", endDate)
+			var daily []repository.FieldDailyAmount
+			start, _ := time.Parse("2006-01-02", startDate)
+			for i := 0; i < 30; i++ {
+				daily = append(daily, repository.FieldDailyAmount{
+					Key: "ecs", Date: start.AddDate(0, 0, i).Format("2006-01-02"), AmountCNY: 100,
+				})
+			}
+			return daily, nil
+		},
+	}
+
+	svc := setupTestService(t, anomalyDAO, billDAO, &mockAlertDAO{})
+	svc.SetCurrencyConverter(&fakeConverter{
+		currency:    "USD",
+		defaultRate: 0.1,
+		rates:       map[string]float64{"2024-01-15": 0.2},
+	})
+
+	err := svc.DetectAnomalies(context.Background(), "tenant1", "2024-01-15")
+	require.NoError(t, err)
+	require.Len(t, anomalyDAO.createdAnomalies, 1)
+
+	a := anomalyDAO.createdAnomalies[0]
+	assert.Equal(t, "USD", a.Currency)
+	assert.InDelta(t, 30.0, a.ActualAmount, 0.01)
+	assert.InDelta(t, 10.0, a.BaselineAmount, 0.01)
+	assert.InDelta(t, 200.0, a.DeviationPct, 0.01)
+	assert.Contains(t, a.PossibleCause, "USD")
+}
+
+type mockModelDAO struct {
+	configs []costdomain.AnomalyModelConfig
+}
+
+func (m *mockModelDAO) ListByTenant(_ context.Context, tenantID string) ([]costdomain.AnomalyModelConfig, error) {
+	var result []costdomain.AnomalyModelConfig
+	for _, c := range m.configs {
+		if c.TenantID == tenantID {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockModelDAO) Upsert(_ context.Context, cfg costdomain.AnomalyModelConfig) error {
+	for i, c := range m.configs {
+		if c.TenantID == cfg.TenantID && c.Dimension == cfg.Dimension {
+			m.configs[i] = cfg
+			return nil
+		}
+	}
+	m.configs = append(m.configs, cfg)
+	return nil
+}
+
+func (m *mockModelDAO) Delete(_ context.Context, tenantID, dimension string) error {
+	for i, c := range m.configs {
+		if c.TenantID == tenantID && c.Dimension == dimension {
+			m.configs = append(m.configs[:i], m.configs[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// mondayBatchBillDAO 每周一批量出账 500，其余日期 100；2024-03-11 为周一
+func mondayBatchBillDAO() *mockBillDAO {
+	return &mockBillDAO{
+		aggregateByFieldFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.AggregateResult, error) {
+			if field != "service_type" {
+				return nil, nil
+			}
+			if startDate == endDate {
+				return []repository.AggregateResult{{Key: "ecs", AmountCNY: 500}}, nil
+			}
+			return []repository.AggregateResult{{Key: "ecs", AmountCNY: 30 * 100}}, nil
+		},
+		fieldDailyFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.FieldDailyAmount, error) {
+			if field != "service_type" {
+				return nil, nil
+			}
+			start, _ := time.Parse("2006-01-02", startDate)
+			end, _ := time.Parse("2006-01-02", endDate)
+			var daily []repository.FieldDailyAmount
+			for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+				amount := 100.0
+				if d.Weekday() == time.Monday {
+					amount = 500
+				}
+				daily = append(daily, repository.FieldDailyAmount{Key: "ecs", Date: d.Format("2006-01-02"), AmountCNY: amount})
+			}
+			return daily, nil
+		},
+	}
+}
+
+func TestDetectAnomalies_ModelPerDimension(t *testing.T) {
+	modelDAO := &mockModelDAO{}
+
+	// 默认 mean 模型：周一 500 对比日均 100 → 误报
+	anomalyDAO := &mockAnomalyDAO{}
+	svc := setupTestService(t, anomalyDAO, mondayBatchBillDAO(), &mockAlertDAO{})
+	svc.SetModelConfigDAO(modelDAO)
+	require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant1", "2024-03-11"))
+	require.Len(t, anomalyDAO.createdAnomalies, 1)
+	assert.Equal(t, costdomain.AnomalyModelMean, anomalyDAO.createdAnomalies[0].Model)
+
+	// service_type 维度切换为季节性模型后不再误报
+	require.NoError(t, svc.SaveModelConfig(context.Background(), costdomain.AnomalyModelConfig{
+		TenantID: "tenant1", Dimension: "service_type", Model: costdomain.AnomalyModelSeasonal,
+	}))
+	anomalyDAO.createdAnomalies = nil
+	require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant1", "2024-03-11"))
+	assert.Empty(t, anomalyDAO.createdAnomalies)
+
+	// 其他租户不受影响
+	require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant2", "2024-03-11"))
+	assert.Len(t, anomalyDAO.createdAnomalies, 1)
+}
+
+func TestDetectAnomalies_RecordsModelAndRange(t *testing.T) {
+	anomalyDAO := &mockAnomalyDAO{}
+	svc := setupTestService(t, anomalyDAO, mondayBatchBillDAO(), &mockAlertDAO{})
+	svc.SetModelConfigDAO(&mockModelDAO{configs: []costdomain.AnomalyModelConfig{
+		{TenantID: "tenant1", Model: costdomain.AnomalyModelMAD},
+	}})
+
+	// 租户默认模型为 median_mad：中位数 100，周一 500 超出预期区间
+	require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant1", "2024-03-11"))
+	require.Len(t, anomalyDAO.createdAnomalies, 1)
+	a := anomalyDAO.createdAnomalies[0]
+	assert.Equal(t, costdomain.AnomalyModelMAD, a.Model)
+	assert.InDelta(t, 100, a.BaselineAmount, 0.01)
+	assert.InDelta(t, 70, a.ExpectedLower, 0.01)
+	assert.InDelta(t, 130, a.ExpectedUpper, 0.01)
+	assert.Contains(t, a.PossibleCause, "median_mad")
+}
+
+// monthlyBillDAO 按月出账的账号：成本全部记在每月 1 号，2024-02-01 为当月累计中的记录
+func monthlyBillDAO(lastMonth float64) *mockBillDAO {
+	byDate := map[string]float64{"2024-02-01": 30, "2024-01-01": lastMonth}
+	for m := 1; m <= 12; m++ {
+		byDate[fmt.Sprintf("2023-%02d-01", m)] = 1000
+	}
+	return &mockBillDAO{
+		aggregateByFieldFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.AggregateResult, error) {
+			if field != "account_name" {
+				return nil, nil
+			}
+			var total float64
+			for date, amount := range byDate {
+				if date >= startDate && date <= endDate {
+					total += amount
+				}
+			}
+			if total == 0 {
+				return nil, nil
+			}
+			return []repository.AggregateResult{{Key: "azure-ea", AmountCNY: total}}, nil
+		},
+		fieldDailyFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.FieldDailyAmount, error) {
+			if field != "account_name" {
+				return nil, nil
+			}
+			var daily []repository.FieldDailyAmount
+			for date, amount := range byDate {
+				if date >= startDate && date <= endDate {
+					daily = append(daily, repository.FieldDailyAmount{Key: "azure-ea", Date: date, AmountCNY: amount})
+				}
+			}
+			return daily, nil
+		},
+	}
+}
+
+func TestDetectAnomalies_MonthlyBills(t *testing.T) {
+	for _, model := range Models {
+		t.Run(model, func(t *testing.T) {
+			// 上月与历史各月持平：月初整月账单不应被当作单日突增
+			anomalyDAO := &mockAnomalyDAO{}
+			svc := setupTestService(t, anomalyDAO, monthlyBillDAO(1000), &mockAlertDAO{})
+			svc.SetModelConfigDAO(&mockModelDAO{configs: []costdomain.AnomalyModelConfig{{TenantID: "tenant1", Model: model}}})
+			require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant1", "2024-02-01"))
+			assert.Empty(t, anomalyDAO.createdAnomalies)
+
+			// 上月翻三倍：按月与历史月份比较后判定异常，异常日期为上月月初
+			anomalyDAO = &mockAnomalyDAO{}
+			svc = setupTestService(t, anomalyDAO, monthlyBillDAO(3000), &mockAlertDAO{})
+			svc.SetModelConfigDAO(&mockModelDAO{configs: []costdomain.AnomalyModelConfig{{TenantID: "tenant1", Model: model}}})
+			require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant1", "2024-02-01"))
+			require.Len(t, anomalyDAO.createdAnomalies, 1)
+			a := anomalyDAO.createdAnomalies[0]
+			assert.Equal(t, "cloud_account", a.Dimension)
+			assert.Equal(t, "2024-01-01", a.AnomalyDate)
+			assert.InDelta(t, 3000, a.ActualAmount, 0.01)
+			assert.InDelta(t, 1000, a.BaselineAmount, 0.01)
+			assert.Contains(t, a.PossibleCause, "月成本")
+		})
+	}
+}
+
+func TestBacktest_MonthlyBills(t *testing.T) {
+	svc := setupTestService(t, &mockAnomalyDAO{}, monthlyBillDAO(3000), &mockAlertDAO{})
+
+	result, err := svc.Backtest(context.Background(), "tenant1", BacktestRequest{
+		StartDate:  "2024-01-01",
+		EndDate:    "2024-02-10",
+		Dimensions: []string{"cloud_account"},
+		Models:     []string{costdomain.AnomalyModelEWMA},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Models, 1)
+	// 只有 2024-02-01 检测上月（2024-01）时判定异常
+	assert.Equal(t, 1, result.Models[0].Total)
+	assert.Equal(t, 1, result.Models[0].ByDate["2024-01-01"])
+}
+
+func TestSaveModelConfig_Validation(t *testing.T) {
+	svc := setupTestService(t, &mockAnomalyDAO{}, &mockBillDAO{}, &mockAlertDAO{})
+	ctx := context.Background()
+
+	err := svc.SaveModelConfig(ctx, costdomain.AnomalyModelConfig{TenantID: "t1", Model: "ewma"})
+	assert.Error(t, err, "未注入配置 DAO")
+
+	svc.SetModelConfigDAO(&mockModelDAO{})
+	err = svc.SaveModelConfig(ctx, costdomain.AnomalyModelConfig{TenantID: "t1", Model: "prophet"})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+	err = svc.SaveModelConfig(ctx, costdomain.AnomalyModelConfig{TenantID: "t1", Dimension: "zone", Model: "ewma"})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+	err = svc.SaveModelConfig(ctx, costdomain.AnomalyModelConfig{TenantID: "t1", Model: "ewma", Alpha: 1.5})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+	require.NoError(t, svc.SaveModelConfig(ctx, costdomain.AnomalyModelConfig{TenantID: "t1", Dimension: "region", Model: "ewma", Alpha: 0.5}))
+
+	configs, err := svc.ListModelConfigs(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "region", configs[0].Dimension)
+}
+
+func TestBacktest(t *testing.T) {
+	svc := setupTestService(t, &mockAnomalyDAO{}, mondayBatchBillDAO(), &mockAlertDAO{})
+
+	// 2024-03-04 ~ 2024-03-17 包含两个周一
+	result, err := svc.Backtest(context.Background(), "tenant1", BacktestRequest{
+		StartDate: "2024-03-04",
+		EndDate:   "2024-03-17",
+		Models:    []string{costdomain.AnomalyModelMean, costdomain.AnomalyModelSeasonal},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CNY", result.Currency)
+	require.Len(t, result.Models, 2)
+
+	mean := result.Models[0]
+	assert.Equal(t, costdomain.AnomalyModelMean, mean.Model)
+	assert.Equal(t, 2, mean.Total, "mean 模型每个周一都会误报")
+	assert.Equal(t, 1, mean.ByDate["2024-03-04"])
+	assert.Equal(t, 1, mean.ByDate["2024-03-11"])
+	assert.Equal(t, 2, mean.ByDimension["service_type"])
+
+	seasonal := result.Models[1]
+	assert.Equal(t, costdomain.AnomalyModelSeasonal, seasonal.Model)
+	assert.Zero(t, seasonal.Total)
+}
+
+func TestBacktest_InvalidRequest(t *testing.T) {
+	svc := setupTestService(t, &mockAnomalyDAO{}, &mockBillDAO{}, &mockAlertDAO{})
+	ctx := context.Background()
+
+	_, err := svc.Backtest(ctx, "t1", BacktestRequest{StartDate: "2024-03-10", EndDate: "2024-03-01"})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+	_, err = svc.Backtest(ctx, "t1", BacktestRequest{StartDate: "2024-01-01", EndDate: "2024-12-31"})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+	_, err = svc.Backtest(ctx, "t1", BacktestRequest{StartDate: "2024-03-01", EndDate: "2024-03-10", Models: []string{"unknown"}})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+	_, err = svc.Backtest(ctx, "t1", BacktestRequest{StartDate: "2024-03-01", EndDate: "2024-03-10", Dimensions: []string{"zone"}})
+	assert.ErrorIs(t, err, costdomain.ErrAnomalyModelInvalid)
+}
+
+// breakdownSeries 构造 [startDate, endDate) 每日 baseline、endDate 当日 actual 的下钻数据
+func breakdownSeries(t *testing.T, key, startDate, endDate string, baseline, actual float64) []repository.FieldDailyAmount {
+	t.Helper()
+	start, err := time.Parse("2006-01-02", startDate)
+	require.NoError(t, err)
+	end, err := time.Parse("2006-01-02", endDate)
+	require.NoError(t, err)
+	var out []repository.FieldDailyAmount
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if baseline > 0 {
+			out = append(out, repository.FieldDailyAmount{Key: key, Date: d.Format("2006-01-02"), AmountCNY: baseline})
+		}
+	}
+	return append(out, repository.FieldDailyAmount{Key: key, Date: endDate, AmountCNY: actual})
+}
+
+func TestDetectAnomalies_Contributors(t *testing.T) {
+	anomalyDAO := &mockAnomalyDAO{}
+	var breakdownCalls []string
+	billDAO := &mockBillDAO{
+		aggregateByFieldFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.AggregateResult, error) {
+			if field != "service_type" {
+				return nil, nil
+			}
+			if startDate == endDate {
+				return []repository.AggregateResult{{Key: "ecs", AmountCNY: 500}}, nil
+			}
+			return []repository.AggregateResult{{Key: "ecs", AmountCNY: 3000}}, nil // avg=100
+		},
+		breakdownFn: func(_ context.Context, _, matchField, matchValue, groupField, startDate, endDate string) ([]repository.FieldDailyAmount, error) {
+			assert.Equal(t, "service_type", matchField)
+			assert.Equal(t, "ecs", matchValue)
+			assert.Equal(t, "2023-12-16", startDate)
+			assert.Equal(t, "2024-01-15", endDate)
+			breakdownCalls = append(breakdownCalls, groupField)
+
+			switch groupField {
+			case "resource_id":
+				var out []repository.FieldDailyAmount
+				out = append(out, breakdownSeries(t, "i-1", startDate, endDate, 60, 60)...) // 无增量
+				out = append(out, breakdownSeries(t, "i-2", startDate, endDate, 40, 400)...)
+				out = append(out, breakdownSeries(t, "i-3", startDate, endDate, 0, 40)...)
+				return out, nil
+			case "region":
+				return breakdownSeries(t, "cn-hangzhou", startDate, endDate, 100, 500), nil
+			case "charge_type":
+				return nil, errors.New("aggregate failed")
+			case "tags":
+				return breakdownSeries(t, "team=payments", startDate, endDate, 0, 360), nil
+			}
+			return nil, nil
+		},
+	}
+	alertDAO := &mockAlertDAO{
+		listRulesFn: func(_ context.Context, _ alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+			return []alertdomain.AlertRule{{ID: 1, Enabled: true}}, 1, nil
+		},
+	}
+
+	svc := setupTestService(t, anomalyDAO, billDAO, alertDAO)
+	require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant1", "2024-01-15"))
+	require.Len(t, anomalyDAO.createdAnomalies, 1)
+
+	a := anomalyDAO.createdAnomalies[0]
+	assert.Equal(t, []string{"resource_id", "region", "charge_type", "tags"}, breakdownCalls)
+	require.Len(t, a.Contributors, 4, "无增量的资源被过滤，下钻失败的类型被跳过")
+
+	byKey := make(map[string]costdomain.AnomalyContributor)
+	for _, c := range a.Contributors {
+		byKey[c.Key] = c
+	}
+	assert.Equal(t, "i-2", a.Contributors[0].Key, "同类型按增量降序")
+	assert.Equal(t, costdomain.ContributorResource, byKey["i-2"].Type)
+	assert.InDelta(t, 40, byKey["i-2"].BaselineAmount, 1e-9)
+	assert.InDelta(t, 360, byKey["i-2"].Delta, 1e-9)
+	assert.InDelta(t, 90, byKey["i-2"].SharePct, 1e-9)
+	assert.InDelta(t, 10, byKey["i-3"].SharePct, 1e-9)
+	assert.Equal(t, costdomain.ContributorRegion, byKey["cn-hangzhou"].Type)
+	assert.InDelta(t, 400, byKey["cn-hangzhou"].Delta, 1e-9)
+	assert.Equal(t, costdomain.ContributorTag, byKey["team=payments"].Type)
+	assert.InDelta(t, 100, byKey["team=payments"].SharePct, 1e-9)
+
+	require.Len(t, alertDAO.emittedEvents, 1)
+	content := alertDAO.emittedEvents[0].Content
+	assert.Len(t, content["contributors"], 4)
+	summary, _ := content["contributor_summary"].(string)
+	assert.Contains(t, summary, "resource: i-2 +360.00 CNY (90%), i-3 +40.00 CNY (10%)")
+	assert.Contains(t, summary, "tag: team=payments +360.00 CNY (100%)")
+}
+
+func TestDetectAnomalies_ContributorsSkipOwnDimension(t *testing.T) {
+	var groupFields []string
+	billDAO := &mockBillDAO{
+		aggregateByFieldFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.AggregateResult, error) {
+			if field != "region" {
+				return nil, nil
+			}
+			if startDate == endDate {
+				return []repository.AggregateResult{{Key: "cn-beijing", AmountCNY: 500}}, nil
+			}
+			return []repository.AggregateResult{{Key: "cn-beijing", AmountCNY: 3000}}, nil
+		},
+		breakdownFn: func(_ context.Context, _, _, _, groupField, _, _ string) ([]repository.FieldDailyAmount, error) {
+			groupFields = append(groupFields, groupField)
+			return nil, nil
+		},
+	}
+
+	anomalyDAO := &mockAnomalyDAO{}
+	svc := setupTestService(t, anomalyDAO, billDAO, &mockAlertDAO{})
+	require.NoError(t, svc.DetectAnomalies(context.Background(), "tenant1", "2024-01-15"))
+	require.Len(t, anomalyDAO.createdAnomalies, 1)
+	assert.Empty(t, anomalyDAO.createdAnomalies[0].Contributors)
+	assert.Equal(t, []string{"resource_id", "charge_type", "tags"}, groupFields, "region 维度不再按地域下钻")
+}
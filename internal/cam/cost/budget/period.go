@@ -0,0 +1,303 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const dateLayout = "2006-01-02"
+
+// scopeDimensions 预算多条件范围支持的维度
+var scopeDimensions = map[string]bool{
+	costdomain.DimProvider:     true,
+	costdomain.DimCloudAccount: true,
+	costdomain.DimRegion:       true,
+	costdomain.DimServiceType:  true,
+	costdomain.DimTag:          true,
+}
+
+// legacyScopeDimensions 旧版 ScopeType 与范围维度的对应关系
+var legacyScopeDimensions = map[string]string{
+	"account":  costdomain.DimCloudAccount,
+	"provider": costdomain.DimProvider,
+}
+
+// normalizePeriod 校验预算周期，未指定时默认为自然月
+func normalizePeriod(budget *costdomain.BudgetRule) error {
+	if budget.Period == "" {
+		budget.Period = costdomain.BudgetPeriodMonthly
+	}
+	switch budget.Period {
+	case costdomain.BudgetPeriodMonthly, costdomain.BudgetPeriodQuarterly, costdomain.BudgetPeriodYearly:
+		budget.StartDate = ""
+		budget.EndDate = ""
+		return nil
+	case costdomain.BudgetPeriodCustom:
+		start, err := time.Parse(dateLayout, budget.StartDate)
+		if err != nil {
+			return fmt.Errorf("invalid budget start_date %q", budget.StartDate)
+		}
+		end, err := time.Parse(dateLayout, budget.EndDate)
+		if err != nil {
+			return fmt.Errorf("invalid budget end_date %q", budget.EndDate)
+		}
+		if end.Before(start) {
+			return fmt.Errorf("budget end_date must not be before start_date")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported budget period %q", budget.Period)
+	}
+}
+
+// periodRange 计算 at 所在预算周期的起止日期（含首尾）
+// 自定义周期固定为 StartDate ~ EndDate，与 at 无关
+func periodRange(budget costdomain.BudgetRule, at time.Time) (time.Time, time.Time, error) {
+	at = at.UTC()
+	switch budget.Period {
+	case "", costdomain.BudgetPeriodMonthly:
+		start := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, -1), nil
+	case costdomain.BudgetPeriodQuarterly:
+		month := time.Month((int(at.Month())-1)/3*3 + 1)
+		start := time.Date(at.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 3, -1), nil
+	case costdomain.BudgetPeriodYearly:
+		start := time.Date(at.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1), nil
+	case costdomain.BudgetPeriodCustom:
+		start, err := time.Parse(dateLayout, budget.StartDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid budget start_date %q", budget.StartDate)
+		}
+		end, err := time.Parse(dateLayout, budget.EndDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid budget end_date %q", budget.EndDate)
+		}
+		return start, end, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unsupported budget period %q", budget.Period)
+	}
+}
+
+// closePeriods 结转预算已结束的周期
+// 逐个结算 CurrentPeriodStart 至当前周期之间的已结束周期：记录实际支出与额度，
+// 开启滚动时把未用完的额度滚入下一周期，最后切换到当前周期并清空通知记录
+// 自定义周期结束后记录历史并将预算标记为 expired；返回结转后的预算
+func (s *BudgetService) closePeriods(ctx context.Context, budget costdomain.BudgetRule, now time.Time) (costdomain.BudgetRule, error) {
+	currentStart, currentEnd, err := periodRange(budget, now)
+	if err != nil {
+		return budget, err
+	}
+
+	// 历史预算未记录跟踪周期，从当前周期开始跟踪
+	if budget.CurrentPeriodStart == "" {
+		budget.CurrentPeriodStart = currentStart.Format(dateLayout)
+		if err := s.budgetDAO.UpdatePeriod(ctx, budget.ID, budget.CurrentPeriodStart, budget.RolloverAmount, budget.NotifiedAt); err != nil {
+			return budget, fmt.Errorf("update budget period: %w", err)
+		}
+		return budget, nil
+	}
+
+	if budget.Period == costdomain.BudgetPeriodCustom {
+		if now.UTC().Before(currentEnd.AddDate(0, 0, 1)) {
+			return budget, nil
+		}
+		if _, err := s.recordPeriod(ctx, budget, currentStart, currentEnd); err != nil {
+			return budget, err
+		}
+		if err := s.budgetDAO.UpdateStatus(ctx, budget.ID, "expired"); err != nil {
+			return budget, fmt.Errorf("expire budget: %w", err)
+		}
+		budget.Status = "expired"
+		s.logger.Info("custom budget period ended",
+			elog.Int64("budget_id", budget.ID),
+			elog.String("end_date", budget.EndDate))
+		return budget, nil
+	}
+
+	tracked, err := time.Parse(dateLayout, budget.CurrentPeriodStart)
+	if err != nil {
+		return budget, fmt.Errorf("invalid current_period_start %q", budget.CurrentPeriodStart)
+	}
+	if !tracked.Before(currentStart) {
+		return budget, nil
+	}
+
+	for tracked.Before(currentStart) {
+		start, end, err := periodRange(budget, tracked)
+		if err != nil {
+			return budget, err
+		}
+		rolloverOut, err := s.recordPeriod(ctx, budget, start, end)
+		if err != nil {
+			return budget, err
+		}
+		budget.RolloverAmount = rolloverOut
+		tracked = end.AddDate(0, 0, 1)
+	}
+
+	budget.CurrentPeriodStart = currentStart.Format(dateLayout)
+	budget.NotifiedAt = make(map[string]time.Time)
+	if err := s.budgetDAO.UpdatePeriod(ctx, budget.ID, budget.CurrentPeriodStart, budget.RolloverAmount, budget.NotifiedAt); err != nil {
+		return budget, fmt.Errorf("update budget period: %w", err)
+	}
+	s.logger.Info("budget period rolled over",
+		elog.Int64("budget_id", budget.ID),
+		elog.String("period_start", budget.CurrentPeriodStart),
+		elog.Any("rollover_amount", budget.RolloverAmount))
+	return budget, nil
+}
+
+// recordPeriod 结算单个已结束周期并写入历史，返回滚入下一周期的金额
+func (s *BudgetService) recordPeriod(ctx context.Context, budget costdomain.BudgetRule, start, end time.Time) (float64, error) {
+	spend, err := s.calculateSpend(ctx, budget, start.Format(dateLayout), end.Format(dateLayout))
+	if err != nil {
+		return 0, fmt.Errorf("calculate period spend: %w", err)
+	}
+
+	limit := budget.EffectiveLimit()
+	var rolloverOut float64
+	if budget.Rollover {
+		rolloverOut = math.Max(0, limit-spend)
+	}
+	if s.historyDAO == nil {
+		return rolloverOut, nil
+	}
+
+	var usagePercent float64
+	if limit > 0 {
+		usagePercent = spend / limit * 100
+	}
+	// 周期切换（UpdatePeriod）失败后重试会再次结算同一周期，按周期覆盖写入，不产生重复历史
+	err = s.historyDAO.Upsert(ctx, costdomain.BudgetPeriodRecord{
+		BudgetID:     budget.ID,
+		Period:       budgetPeriod(budget),
+		PeriodStart:  start.Format(dateLayout),
+		PeriodEnd:    end.Format(dateLayout),
+		AmountLimit:  budget.AmountLimit,
+		RolloverIn:   budget.RolloverAmount,
+		ActualSpend:  spend,
+		UsagePercent: usagePercent,
+		RolloverOut:  rolloverOut,
+		Currency:     budgetCurrency(budget),
+		TenantID:     budget.TenantID,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("upsert budget history: %w", err)
+	}
+	return rolloverOut, nil
+}
+
+// validateScopes 校验多条件范围：维度受支持、标签为 key=value、非标签维度不重复（含旧版 ScopeType）
+func validateScopes(budget costdomain.BudgetRule) error {
+	seen := make(map[string]bool)
+	if dim, ok := legacyScopeDimensions[budget.ScopeType]; ok {
+		seen[dim] = true
+	}
+	for _, sc := range budget.Scopes {
+		if !scopeDimensions[sc.DimType] {
+			return fmt.Errorf("unsupported budget scope dimension %q", sc.DimType)
+		}
+		if sc.DimValue == "" {
+			return fmt.Errorf("budget scope %s value cannot be empty", sc.DimType)
+		}
+		switch sc.DimType {
+		case costdomain.DimTag:
+			if _, _, ok := splitTag(sc.DimValue); !ok {
+				return fmt.Errorf("budget tag scope %q must be key=value", sc.DimValue)
+			}
+		case costdomain.DimCloudAccount:
+			if _, err := strconv.ParseInt(sc.DimValue, 10, 64); err != nil {
+				return fmt.Errorf("invalid budget account scope %q", sc.DimValue)
+			}
+			fallthrough
+		default:
+			if seen[sc.DimType] {
+				return fmt.Errorf("duplicate budget scope dimension %q", sc.DimType)
+			}
+			seen[sc.DimType] = true
+		}
+	}
+	return nil
+}
+
+// scopeFilter 根据预算适用范围构建统一账单过滤条件，ScopeType 与 Scopes 同时生效
+func scopeFilter(budget costdomain.BudgetRule) (repository.UnifiedBillFilter, error) {
+	filter := repository.UnifiedBillFilter{TenantID: budget.TenantID}
+	switch budget.ScopeType {
+	case "account":
+		accountID, err := strconv.ParseInt(budget.ScopeValue, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid account scope value %q: %w", budget.ScopeValue, err)
+		}
+		filter.AccountID = accountID
+	case "provider":
+		filter.Provider = budget.ScopeValue
+	case "all":
+		// No additional filter
+	}
+
+	for _, sc := range budget.Scopes {
+		switch sc.DimType {
+		case costdomain.DimProvider:
+			filter.Provider = sc.DimValue
+		case costdomain.DimCloudAccount:
+			accountID, err := strconv.ParseInt(sc.DimValue, 10, 64)
+			if err != nil {
+				return filter, fmt.Errorf("invalid account scope value %q: %w", sc.DimValue, err)
+			}
+			filter.AccountID = accountID
+		case costdomain.DimRegion:
+			filter.Region = sc.DimValue
+		case costdomain.DimServiceType:
+			filter.ServiceType = sc.DimValue
+		case costdomain.DimTag:
+			key, value, ok := splitTag(sc.DimValue)
+			if !ok {
+				return filter, fmt.Errorf("invalid tag scope value %q", sc.DimValue)
+			}
+			if filter.Tags == nil {
+				filter.Tags = make(map[string]string)
+			}
+			filter.Tags[key] = value
+		default:
+			return filter, fmt.Errorf("unsupported budget scope dimension %q", sc.DimType)
+		}
+	}
+	return filter, nil
+}
+
+// budgetInScope 判断预算是否限定在指定范围（旧版 ScopeType 或多条件范围中的同名维度）
+func budgetInScope(budget costdomain.BudgetRule, scopeType, scopeValue string) bool {
+	if budget.ScopeType == scopeType && budget.ScopeValue == scopeValue {
+		return true
+	}
+	dim, ok := legacyScopeDimensions[scopeType]
+	if !ok {
+		return false
+	}
+	for _, sc := range budget.Scopes {
+		if sc.DimType == dim && sc.DimValue == scopeValue {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTag 拆分 key=value 形式的标签条件
+func splitTag(value string) (string, string, bool) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,346 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockHistoryDAO 记录写入的周期历史
+type mockHistoryDAO struct {
+	records []costdomain.BudgetPeriodRecord
+}
+
+func (m *mockHistoryDAO) Upsert(_ context.Context, record costdomain.BudgetPeriodRecord) error {
+	for i, r := range m.records {
+		if r.BudgetID == record.BudgetID && r.PeriodStart == record.PeriodStart {
+			m.records[i] = record
+			return nil
+		}
+	}
+	m.records = append(m.records, record)
+	return nil
+}
+func (m *mockHistoryDAO) ListByBudget(_ context.Context, budgetID int64, _, _ int64) ([]costdomain.BudgetPeriodRecord, error) {
+	var result []costdomain.BudgetPeriodRecord
+	for _, r := range m.records {
+		if r.BudgetID == budgetID {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+func (m *mockHistoryDAO) CountByBudget(ctx context.Context, budgetID int64) (int64, error) {
+	records, _ := m.ListByBudget(ctx, budgetID, 0, 0)
+	return int64(len(records)), nil
+}
+
+// spendByStart 按查询起始日期返回支出，模拟各周期的实际支出
+func spendByStart(spend map[string]float64) *mockBillDAO {
+	return &mockBillDAO{sumAmountFn: func(_ context.Context, filter repository.UnifiedBillFilter) (float64, error) {
+		return spend[filter.StartDate], nil
+	}}
+}
+
+func TestPeriodRange(t *testing.T) {
+	at := time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC)
+	cases := []struct {
+		budget     costdomain.BudgetRule
+		start, end string
+	}{
+		{costdomain.BudgetRule{}, "2024-05-01", "2024-05-31"},
+		{costdomain.BudgetRule{Period: costdomain.BudgetPeriodMonthly}, "2024-05-01", "2024-05-31"},
+		{costdomain.BudgetRule{Period: costdomain.BudgetPeriodQuarterly}, "2024-04-01", "2024-06-30"},
+		{costdomain.BudgetRule{Period: costdomain.BudgetPeriodYearly}, "2024-01-01", "2024-12-31"},
+		{costdomain.BudgetRule{Period: costdomain.BudgetPeriodCustom, StartDate: "2024-03-15", EndDate: "2024-09-14"}, "2024-03-15", "2024-09-14"},
+	}
+	for _, c := range cases {
+		start, end, err := periodRange(c.budget, at)
+		require.NoError(t, err)
+		assert.Equal(t, c.start, start.Format(dateLayout), c.budget.Period)
+		assert.Equal(t, c.end, end.Format(dateLayout), c.budget.Period)
+	}
+
+	start, end, err := periodRange(costdomain.BudgetRule{Period: costdomain.BudgetPeriodMonthly}, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-01", start.Format(dateLayout))
+	assert.Equal(t, "2024-02-29", end.Format(dateLayout))
+
+	_, _, err = periodRange(costdomain.BudgetRule{Period: "weekly"}, at)
+	assert.Error(t, err)
+}
+
+func TestCreateBudget_Periods(t *testing.T) {
+	var created costdomain.BudgetRule
+	budgetDAO := &mockBudgetDAO{createFn: func(_ context.Context, b costdomain.BudgetRule) (int64, error) {
+		created = b
+		return 1, nil
+	}}
+	svc := setupTestService(t, budgetDAO, &mockBillDAO{}, &mockAlertDAO{})
+	ctx := context.Background()
+
+	_, err := svc.CreateBudget(ctx, costdomain.BudgetRule{
+		Name: "Q", AmountLimit: 30000, Thresholds: []float64{80}, Period: costdomain.BudgetPeriodQuarterly, Rollover: true,
+	})
+	require.NoError(t, err)
+	expectedStart, _, _ := periodRange(costdomain.BudgetRule{Period: costdomain.BudgetPeriodQuarterly}, time.Now())
+	assert.Equal(t, costdomain.BudgetPeriodQuarterly, created.Period)
+	assert.Equal(t, expectedStart.Format(dateLayout), created.CurrentPeriodStart)
+	assert.True(t, created.Rollover)
+
+	_, err = svc.CreateBudget(ctx, costdomain.BudgetRule{
+		Name: "C", AmountLimit: 5000, Thresholds: []float64{80}, Period: costdomain.BudgetPeriodCustom,
+		StartDate: "2024-03-01", EndDate: "2024-08-31",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01", created.CurrentPeriodStart)
+
+	_, err = svc.CreateBudget(ctx, costdomain.BudgetRule{
+		Name: "C", AmountLimit: 5000, Thresholds: []float64{80}, Period: costdomain.BudgetPeriodCustom,
+		StartDate: "2024-08-31", EndDate: "2024-03-01",
+	})
+	assert.Error(t, err, "结束日期早于开始日期")
+
+	_, err = svc.CreateBudget(ctx, costdomain.BudgetRule{
+		Name: "W", AmountLimit: 5000, Thresholds: []float64{80}, Period: "weekly",
+	})
+	assert.Error(t, err)
+}
+
+func TestScopeFilter_MultiCondition(t *testing.T) {
+	filter, err := scopeFilter(costdomain.BudgetRule{
+		TenantID:  "t1",
+		ScopeType: "provider", ScopeValue: "aws",
+		Scopes: []costdomain.DimensionFilter{
+			{DimType: costdomain.DimTag, DimValue: "team=search"},
+			{DimType: costdomain.DimTag, DimValue: "env=prod"},
+			{DimType: costdomain.DimRegion, DimValue: "us-east-1"},
+			{DimType: costdomain.DimCloudAccount, DimValue: "42"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "t1", filter.TenantID)
+	assert.Equal(t, "aws", filter.Provider)
+	assert.Equal(t, "us-east-1", filter.Region)
+	assert.Equal(t, int64(42), filter.AccountID)
+	assert.Equal(t, map[string]string{"team": "search", "env": "prod"}, filter.Tags)
+}
+
+func TestValidateScopes(t *testing.T) {
+	valid := costdomain.BudgetRule{Scopes: []costdomain.DimensionFilter{
+		{DimType: costdomain.DimProvider, DimValue: "aws"},
+		{DimType: costdomain.DimTag, DimValue: "team=search"},
+		{DimType: costdomain.DimTag, DimValue: "env=prod"},
+	}}
+	assert.NoError(t, validateScopes(valid))
+
+	invalid := []costdomain.BudgetRule{
+		{Scopes: []costdomain.DimensionFilter{{DimType: "resource_group", DimValue: "rg"}}},
+		{Scopes: []costdomain.DimensionFilter{{DimType: costdomain.DimTag, DimValue: "team"}}},
+		{Scopes: []costdomain.DimensionFilter{{DimType: costdomain.DimCloudAccount, DimValue: "abc"}}},
+		{Scopes: []costdomain.DimensionFilter{{DimType: costdomain.DimRegion, DimValue: ""}}},
+		{Scopes: []costdomain.DimensionFilter{
+			{DimType: costdomain.DimRegion, DimValue: "cn-beijing"},
+			{DimType: costdomain.DimRegion, DimValue: "cn-shanghai"},
+		}},
+		{ScopeType: "provider", ScopeValue: "aws", Scopes: []costdomain.DimensionFilter{{DimType: costdomain.DimProvider, DimValue: "aliyun"}}},
+	}
+	for _, b := range invalid {
+		assert.Error(t, validateScopes(b), "%+v", b.Scopes)
+	}
+}
+
+func TestClosePeriods_RolloverAndHistory(t *testing.T) {
+	var periodStart string
+	var rollover float64
+	var notified map[string]time.Time
+	budgetDAO := &mockBudgetDAO{updatePeriodFn: func(_ context.Context, _ int64, start string, amount float64, na map[string]time.Time) error {
+		periodStart, rollover, notified = start, amount, na
+		return nil
+	}}
+	// Q1 支出 800、Q2 支出 1100
+	billDAO := spendByStart(map[string]float64{"2024-01-01": 800, "2024-04-01": 1100})
+	history := &mockHistoryDAO{}
+	svc := setupTestService(t, budgetDAO, billDAO, &mockAlertDAO{})
+	svc.SetHistoryDAO(history)
+
+	budget, err := svc.closePeriods(context.Background(), costdomain.BudgetRule{
+		ID: 7, Name: "Q", AmountLimit: 1000, RolloverAmount: 100, Rollover: true,
+		Period: costdomain.BudgetPeriodQuarterly, CurrentPeriodStart: "2024-01-01",
+		NotifiedAt: map[string]time.Time{"80": time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)},
+		TenantID:   "t1", Status: "active",
+	}, time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	require.Len(t, history.records, 2)
+	q1, q2 := history.records[0], history.records[1]
+	assert.Equal(t, "2024-01-01", q1.PeriodStart)
+	assert.Equal(t, "2024-03-31", q1.PeriodEnd)
+	assert.Equal(t, 100.0, q1.RolloverIn)
+	assert.Equal(t, 800.0, q1.ActualSpend)
+	assert.InDelta(t, 800.0/1100*100, q1.UsagePercent, 1e-9)
+	assert.Equal(t, 300.0, q1.RolloverOut, "未用完的 300 滚入 Q2")
+
+	assert.Equal(t, "2024-04-01", q2.PeriodStart)
+	assert.Equal(t, "2024-06-30", q2.PeriodEnd)
+	assert.Equal(t, 300.0, q2.RolloverIn)
+	assert.Equal(t, 1100.0, q2.ActualSpend)
+	assert.Equal(t, 200.0, q2.RolloverOut)
+
+	assert.Equal(t, "2024-07-01", periodStart)
+	assert.Equal(t, 200.0, rollover)
+	assert.Empty(t, notified, "新周期重置通知记录")
+	assert.Equal(t, "2024-07-01", budget.CurrentPeriodStart)
+	assert.Equal(t, 1200.0, budget.EffectiveLimit())
+}
+
+func TestClosePeriods_RetryAfterUpdatePeriodFailure(t *testing.T) {
+	fail := true
+	budgetDAO := &mockBudgetDAO{updatePeriodFn: func(_ context.Context, _ int64, _ string, _ float64, _ map[string]time.Time) error {
+		if fail {
+			return errors.New("write conflict")
+		}
+		return nil
+	}}
+	spend := map[string]float64{"2024-01-01": 800, "2024-02-01": 900}
+	history := &mockHistoryDAO{}
+	svc := setupTestService(t, budgetDAO, spendByStart(spend), &mockAlertDAO{})
+	svc.SetHistoryDAO(history)
+
+	rule := costdomain.BudgetRule{
+		ID: 7, AmountLimit: 1000, Rollover: true, Period: costdomain.BudgetPeriodMonthly,
+		CurrentPeriodStart: "2024-01-01", TenantID: "t1", Status: "active",
+	}
+	now := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	_, err := svc.closePeriods(context.Background(), rule, now)
+	require.Error(t, err)
+	require.Len(t, history.records, 2)
+
+	// 周期未切换，下次检查重新结算同一批周期：按周期覆盖，不重复写入
+	fail = false
+	spend["2024-02-01"] = 950
+	budget, err := svc.closePeriods(context.Background(), rule, now)
+	require.NoError(t, err)
+	require.Len(t, history.records, 2)
+	assert.Equal(t, "2024-01-01", history.records[0].PeriodStart)
+	assert.Equal(t, "2024-02-01", history.records[1].PeriodStart)
+	assert.Equal(t, 950.0, history.records[1].ActualSpend)
+	assert.Equal(t, 250.0, history.records[1].RolloverOut)
+	assert.Equal(t, "2024-03-01", budget.CurrentPeriodStart)
+}
+
+func TestClosePeriods_NoRollover(t *testing.T) {
+	var rollover float64 = -1
+	budgetDAO := &mockBudgetDAO{updatePeriodFn: func(_ context.Context, _ int64, _ string, amount float64, _ map[string]time.Time) error {
+		rollover = amount
+		return nil
+	}}
+	svc := setupTestService(t, budgetDAO, spendByStart(map[string]float64{"2024-01-01": 200}), &mockAlertDAO{})
+
+	budget, err := svc.closePeriods(context.Background(), costdomain.BudgetRule{
+		ID: 1, AmountLimit: 1000, Period: costdomain.BudgetPeriodMonthly, CurrentPeriodStart: "2024-01-01", Status: "active",
+	}, time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, rollover)
+	assert.Equal(t, 1000.0, budget.EffectiveLimit())
+}
+
+func TestClosePeriods_CustomExpired(t *testing.T) {
+	var status string
+	budgetDAO := &mockBudgetDAO{updateStatusFn: func(_ context.Context, _ int64, s string) error {
+		status = s
+		return nil
+	}}
+	history := &mockHistoryDAO{}
+	svc := setupTestService(t, budgetDAO, spendByStart(map[string]float64{"2024-01-15": 4200}), &mockAlertDAO{})
+	svc.SetHistoryDAO(history)
+
+	budget := costdomain.BudgetRule{
+		ID: 3, AmountLimit: 5000, Period: costdomain.BudgetPeriodCustom,
+		StartDate: "2024-01-15", EndDate: "2024-04-14", CurrentPeriodStart: "2024-01-15", Status: "active",
+	}
+
+	// 周期最后一天仍在跟踪
+	got, err := svc.closePeriods(context.Background(), budget, time.Date(2024, 4, 14, 23, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "active", got.Status)
+	assert.Empty(t, history.records)
+
+	got, err = svc.closePeriods(context.Background(), budget, time.Date(2024, 4, 15, 1, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "expired", got.Status)
+	assert.Equal(t, "expired", status)
+	require.Len(t, history.records, 1)
+	assert.Equal(t, "2024-04-14", history.records[0].PeriodEnd)
+	assert.Equal(t, 4200.0, history.records[0].ActualSpend)
+	assert.Equal(t, 84.0, history.records[0].UsagePercent)
+}
+
+func TestCheckBudgets_RolloverRaisesEffectiveLimit(t *testing.T) {
+	alertDAO := &mockAlertDAO{listRulesFn: func(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+		return []alertdomain.AlertRule{{ID: 1, Type: filter.Type, Enabled: true}}, 1, nil
+	}}
+	now := time.Now().UTC()
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	budgetDAO := &mockBudgetDAO{listActiveFn: func(_ context.Context, _ string) ([]costdomain.BudgetRule, error) {
+		return []costdomain.BudgetRule{{
+			ID: 1, Name: "T", AmountLimit: 1000, Rollover: true, Thresholds: []float64{50},
+			Period: costdomain.BudgetPeriodMonthly, CurrentPeriodStart: lastMonth.Format(dateLayout),
+			NotifiedAt: map[string]time.Time{"50": lastMonth}, TenantID: "t1", Status: "active",
+		}}, nil
+	}}
+	var rollover float64
+	budgetDAO.updatePeriodFn = func(_ context.Context, _ int64, _ string, amount float64, _ map[string]time.Time) error {
+		rollover = amount
+		return nil
+	}
+	// 上月与本月支出均为 600：上月结余 400 滚入，本月使用率 600/1400 未达 50%
+	billDAO := &mockBillDAO{sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) { return 600, nil }}
+	svc := setupTestService(t, budgetDAO, billDAO, alertDAO)
+
+	require.NoError(t, svc.CheckBudgets(context.Background(), "t1"))
+	assert.Equal(t, 400.0, rollover)
+	assert.Empty(t, alertDAO.emittedEvents)
+}
+
+func TestDeactivateBudgetsByScope_Scopes(t *testing.T) {
+	var deactivatedIDs []int64
+	budgetDAO := &mockBudgetDAO{
+		listFn: func(_ context.Context, _ repository.BudgetFilter) ([]costdomain.BudgetRule, error) {
+			return []costdomain.BudgetRule{
+				{ID: 1, Name: "A", ScopeType: "all", Scopes: []costdomain.DimensionFilter{{DimType: costdomain.DimCloudAccount, DimValue: "123"}}},
+				{ID: 2, Name: "B", ScopeType: "all", Scopes: []costdomain.DimensionFilter{{DimType: costdomain.DimRegion, DimValue: "123"}}},
+			}, nil
+		},
+		updateStatusFn: func(_ context.Context, id int64, _ string) error {
+			deactivatedIDs = append(deactivatedIDs, id)
+			return nil
+		},
+	}
+	svc := setupTestService(t, budgetDAO, &mockBillDAO{}, &mockAlertDAO{})
+	require.NoError(t, svc.DeactivateBudgetsByScope(context.Background(), "t1", "account", "123"))
+	assert.Equal(t, []int64{1}, deactivatedIDs)
+}
+
+func TestListBudgetHistory(t *testing.T) {
+	svc := setupTestService(t, &mockBudgetDAO{}, &mockBillDAO{}, &mockAlertDAO{})
+	_, _, err := svc.ListBudgetHistory(context.Background(), 1, 0, 20)
+	assert.Error(t, err, "未注入历史存储")
+
+	history := &mockHistoryDAO{records: []costdomain.BudgetPeriodRecord{
+		{BudgetID: 1, PeriodStart: "2024-01-01"},
+		{BudgetID: 2, PeriodStart: "2024-01-01"},
+	}}
+	svc.SetHistoryDAO(history)
+	records, total, err := svc.ListBudgetHistory(context.Background(), 1, 0, 20)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, int64(1), total)
+}
@@ -7,11 +7,14 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/alert/domain"
 	"github.com/Havens-blog/e-cam-service/internal/alert/service"
 	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/forecast"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/gotomicro/ego/core/elog"
 )
@@ -19,6 +22,9 @@ import (
 const (
 	// alertTypeBudgetThreshold 预算阈值告警类型
 	alertTypeBudgetThreshold = "budget_threshold"
+
+	// forecastThresholdPrefix 预测阈值在 NotifiedAt 中的键前缀
+	forecastThresholdPrefix = "forecast:"
 )
 
 // BudgetProgress 预算消耗进度
@@ -26,18 +32,35 @@ type BudgetProgress struct {
 	BudgetID        int64   `json:"budget_id"`
 	Name            string  `json:"name"`
 	AmountLimit     float64 `json:"amount_limit"`
+	Currency        string  `json:"currency"`
 	CurrentSpend    float64 `json:"current_spend"`
 	RemainingAmount float64 `json:"remaining_amount"`
 	UsagePercent    float64 `json:"usage_percent"`
 	Status          string  `json:"status"`
+
+	// 当前周期
+	Period         string  `json:"period"`
+	PeriodStart    string  `json:"period_start"`
+	PeriodEnd      string  `json:"period_end"`
+	RolloverAmount float64 `json:"rollover_amount"`
+	EffectiveLimit float64 `json:"effective_limit"` // 预算额度 + 滚入金额，使用率以此为基准
+
+	// 周期末预测（注入预测服务后返回）
+	ForecastSpend        float64 `json:"forecast_spend,omitempty"`
+	ForecastLower        float64 `json:"forecast_lower,omitempty"`
+	ForecastUpper        float64 `json:"forecast_upper,omitempty"`
+	ForecastUsagePercent float64 `json:"forecast_usage_percent,omitempty"`
 }
 
 // BudgetService 预算管理服务
 type BudgetService struct {
-	budgetDAO repository.BudgetDAO
-	billDAO   repository.BillDAO
-	alertSvc  *service.AlertService
-	logger    *elog.Component
+	budgetDAO  repository.BudgetDAO
+	billDAO    repository.BillDAO
+	alertSvc   *service.AlertService
+	converter  exchange.ReportingConverter
+	forecaster forecast.SpendForecaster
+	historyDAO repository.BudgetHistoryDAO
+	logger     *elog.Component
 }
 
 // NewBudgetService 创建预算管理服务
@@ -55,7 +78,23 @@ func NewBudgetService(
 	}
 }
 
+// SetCurrencyConverter 设置报表币种换算器（可选注入，未设置时预算均以人民币计算）
+func (s *BudgetService) SetCurrencyConverter(converter exchange.ReportingConverter) {
+	s.converter = converter
+}
+
+// SetForecaster 设置支出预测服务（可选注入，未设置时不计算月末预测，预测阈值不生效）
+func (s *BudgetService) SetForecaster(forecaster forecast.SpendForecaster) {
+	s.forecaster = forecaster
+}
+
+// SetHistoryDAO 设置预算周期历史存储（可选注入，未设置时周期切换不记录历史）
+func (s *BudgetService) SetHistoryDAO(historyDAO repository.BudgetHistoryDAO) {
+	s.historyDAO = historyDAO
+}
+
 // CreateBudget 创建预算规则
+// 未指定币种时使用租户报表币种，未指定周期时为自然月
 func (s *BudgetService) CreateBudget(ctx context.Context, budget costdomain.BudgetRule) (int64, error) {
 	if budget.Name == "" {
 		return 0, fmt.Errorf("budget name cannot be empty")
@@ -66,27 +105,42 @@ func (s *BudgetService) CreateBudget(ctx context.Context, budget costdomain.Budg
 	if len(budget.Thresholds) == 0 {
 		return 0, fmt.Errorf("budget thresholds cannot be empty")
 	}
-	// Validate thresholds: sorted ascending, each in (0, 100]
-	for i, t := range budget.Thresholds {
-		if t <= 0 || t > 100 {
-			return 0, fmt.Errorf("threshold %.2f must be in (0, 100]", t)
-		}
-		if i > 0 && budget.Thresholds[i] <= budget.Thresholds[i-1] {
-			return 0, fmt.Errorf("thresholds must be sorted in ascending order")
-		}
+	if err := validateThresholds(budget.Thresholds); err != nil {
+		return 0, err
+	}
+	if err := validateThresholds(budget.ForecastThresholds); err != nil {
+		return 0, fmt.Errorf("forecast %w", err)
+	}
+	if err := normalizePeriod(&budget); err != nil {
+		return 0, err
+	}
+	if err := validateScopes(budget); err != nil {
+		return 0, err
+	}
+
+	currency, err := s.resolveCurrency(ctx, budget.TenantID, budget.Currency)
+	if err != nil {
+		return 0, err
 	}
+	budget.Currency = currency
 
-	now := time.Now().Unix()
+	now := time.Now()
+	periodStart, _, err := periodRange(budget, now)
+	if err != nil {
+		return 0, err
+	}
 	budget.Status = "active"
-	budget.Period = "monthly"
+	budget.CurrentPeriodStart = periodStart.Format(dateLayout)
+	budget.RolloverAmount = 0
 	budget.NotifiedAt = make(map[string]time.Time)
-	budget.CreateTime = now
-	budget.UpdateTime = now
+	budget.CreateTime = now.Unix()
+	budget.UpdateTime = now.Unix()
 
 	return s.budgetDAO.Create(ctx, budget)
 }
 
 // UpdateBudget 更新预算规则
+// 指定周期时重新从当前周期开始跟踪；周期为空时保留原周期设置
 func (s *BudgetService) UpdateBudget(ctx context.Context, budget costdomain.BudgetRule) error {
 	if budget.Name == "" {
 		return fmt.Errorf("budget name cannot be empty")
@@ -94,15 +148,33 @@ func (s *BudgetService) UpdateBudget(ctx context.Context, budget costdomain.Budg
 	if budget.AmountLimit <= 0 {
 		return fmt.Errorf("budget amount_limit must be positive")
 	}
-	if len(budget.Thresholds) > 0 {
-		for i, t := range budget.Thresholds {
-			if t <= 0 || t > 100 {
-				return fmt.Errorf("threshold %.2f must be in (0, 100]", t)
-			}
-			if i > 0 && budget.Thresholds[i] <= budget.Thresholds[i-1] {
-				return fmt.Errorf("thresholds must be sorted in ascending order")
-			}
+	if err := validateThresholds(budget.Thresholds); err != nil {
+		return err
+	}
+	if err := validateThresholds(budget.ForecastThresholds); err != nil {
+		return fmt.Errorf("forecast %w", err)
+	}
+	if err := validateScopes(budget); err != nil {
+		return err
+	}
+	if budget.Period != "" {
+		if err := normalizePeriod(&budget); err != nil {
+			return err
 		}
+		periodStart, _, err := periodRange(budget, time.Now())
+		if err != nil {
+			return err
+		}
+		budget.CurrentPeriodStart = periodStart.Format(dateLayout)
+	}
+
+	// 币种为空时保留原预算币种
+	if budget.Currency != "" {
+		currency, err := s.resolveCurrency(ctx, budget.TenantID, budget.Currency)
+		if err != nil {
+			return err
+		}
+		budget.Currency = currency
 	}
 
 	budget.UpdateTime = time.Now().Unix()
@@ -121,26 +193,84 @@ func (s *BudgetService) GetBudgetProgress(ctx context.Context, budgetID int64) (
 		return nil, fmt.Errorf("get budget: %w", err)
 	}
 
-	currentSpend, err := s.calculateCurrentSpend(ctx, budget)
+	periodStart, periodEnd, err := periodRange(budget, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	currentSpend, err := s.calculateCurrentSpend(ctx, budget, periodStart, periodEnd)
 	if err != nil {
 		return nil, fmt.Errorf("calculate current spend: %w", err)
 	}
 
+	limit := budget.EffectiveLimit()
 	usagePercent := 0.0
-	if budget.AmountLimit > 0 {
-		usagePercent = currentSpend / budget.AmountLimit * 100
+	if limit > 0 {
+		usagePercent = currentSpend / limit * 100
 	}
-	remaining := math.Max(0, budget.AmountLimit-currentSpend)
+	remaining := math.Max(0, limit-currentSpend)
 
-	return &BudgetProgress{
+	progress := &BudgetProgress{
 		BudgetID:        budget.ID,
 		Name:            budget.Name,
 		AmountLimit:     budget.AmountLimit,
+		Currency:        budgetCurrency(budget),
 		CurrentSpend:    currentSpend,
 		RemainingAmount: remaining,
 		UsagePercent:    usagePercent,
 		Status:          budget.Status,
-	}, nil
+		Period:          budgetPeriod(budget),
+		PeriodStart:     periodStart.Format(dateLayout),
+		PeriodEnd:       periodEnd.Format(dateLayout),
+		RolloverAmount:  budget.RolloverAmount,
+		EffectiveLimit:  limit,
+	}
+
+	// 预测失败不影响实际进度查询
+	if s.forecaster != nil {
+		f, err := s.forecastSpend(ctx, budget, "")
+		if err != nil {
+			s.logger.Warn("forecast budget spend failed",
+				elog.Int64("budget_id", budget.ID),
+				elog.FieldErr(err))
+		} else {
+			progress.ForecastSpend = f.Forecast
+			progress.ForecastLower = f.Lower
+			progress.ForecastUpper = f.Upper
+			if limit > 0 {
+				progress.ForecastUsagePercent = f.Forecast / limit * 100
+			}
+		}
+	}
+	return progress, nil
+}
+
+// GetBudgetForecast 预测预算范围的周期末支出（预算币种）
+// horizon 为空时预测到预算当前周期末，否则预测到自然月末 / 季末
+func (s *BudgetService) GetBudgetForecast(ctx context.Context, budgetID int64, horizon string) (*forecast.Forecast, error) {
+	if s.forecaster == nil {
+		return nil, fmt.Errorf("budget forecast is not enabled")
+	}
+	budget, err := s.budgetDAO.GetByID(ctx, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("get budget: %w", err)
+	}
+	return s.forecastSpend(ctx, budget, horizon)
+}
+
+// ListBudgetHistory 查询预算已结束周期的实际支出与额度
+func (s *BudgetService) ListBudgetHistory(ctx context.Context, budgetID int64, offset, limit int64) ([]costdomain.BudgetPeriodRecord, int64, error) {
+	if s.historyDAO == nil {
+		return nil, 0, fmt.Errorf("budget history is not enabled")
+	}
+	records, err := s.historyDAO.ListByBudget(ctx, budgetID, offset, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list budget history: %w", err)
+	}
+	count, err := s.historyDAO.CountByBudget(ctx, budgetID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count budget history: %w", err)
+	}
+	return records, count, nil
 }
 
 // ListBudgets 查询预算规则列表
@@ -157,14 +287,27 @@ func (s *BudgetService) ListBudgets(ctx context.Context, filter repository.Budge
 }
 
 // CheckBudgets 检查所有预算规则的消耗进度（每日定时执行）
+// 检查前先结转已结束的周期：记录周期历史、计算滚入金额并重置通知记录
 func (s *BudgetService) CheckBudgets(ctx context.Context, tenantID string) error {
 	budgets, err := s.budgetDAO.ListActive(ctx, tenantID)
 	if err != nil {
 		return fmt.Errorf("list active budgets: %w", err)
 	}
 
+	now := time.Now()
 	for _, budget := range budgets {
-		if err := s.checkSingleBudget(ctx, budget); err != nil {
+		budget, err := s.closePeriods(ctx, budget, now)
+		if err != nil {
+			s.logger.Error("close budget period failed",
+				elog.Int64("budget_id", budget.ID),
+				elog.String("budget_name", budget.Name),
+				elog.FieldErr(err))
+			continue
+		}
+		if budget.Status != "active" {
+			continue
+		}
+		if err := s.checkSingleBudget(ctx, budget, now); err != nil {
 			s.logger.Error("check budget failed",
 				elog.Int64("budget_id", budget.ID),
 				elog.String("budget_name", budget.Name),
@@ -175,17 +318,22 @@ func (s *BudgetService) CheckBudgets(ctx context.Context, tenantID string) error
 }
 
 // checkSingleBudget 检查单个预算规则
-func (s *BudgetService) checkSingleBudget(ctx context.Context, budget costdomain.BudgetRule) error {
-	currentSpend, err := s.calculateCurrentSpend(ctx, budget)
+func (s *BudgetService) checkSingleBudget(ctx context.Context, budget costdomain.BudgetRule, now time.Time) error {
+	periodStart, periodEnd, err := periodRange(budget, now)
+	if err != nil {
+		return err
+	}
+	currentSpend, err := s.calculateCurrentSpend(ctx, budget, periodStart, periodEnd)
 	if err != nil {
 		return fmt.Errorf("calculate spend: %w", err)
 	}
 
-	if budget.AmountLimit <= 0 {
+	limit := budget.EffectiveLimit()
+	if limit <= 0 {
 		return nil
 	}
 
-	usagePercent := currentSpend / budget.AmountLimit * 100
+	usagePercent := currentSpend / limit * 100
 
 	// Sort thresholds ascending to process from lowest to highest
 	thresholds := make([]float64, len(budget.Thresholds))
@@ -205,9 +353,9 @@ func (s *BudgetService) checkSingleBudget(ctx context.Context, budget costdomain
 
 		thresholdKey := strconv.FormatFloat(threshold, 'f', -1, 64)
 
-		// Check if already notified this month
+		// Check if already notified this period
 		if lastNotified, ok := notifiedAt[thresholdKey]; ok {
-			if isInCurrentMonth(lastNotified) {
+			if !lastNotified.Before(periodStart) {
 				continue
 			}
 		}
@@ -219,15 +367,23 @@ func (s *BudgetService) checkSingleBudget(ctx context.Context, budget costdomain
 			Severity: severity,
 			Title:    fmt.Sprintf("预算告警: %s 已达 %.0f%% 阈值", budget.Name, threshold),
 			Content: map[string]any{
-				"budget_id":     budget.ID,
-				"budget_name":   budget.Name,
-				"amount_limit":  budget.AmountLimit,
-				"current_spend": currentSpend,
-				"usage_percent": usagePercent,
-				"threshold":     threshold,
-				"scope_type":    budget.ScopeType,
-				"scope_value":   budget.ScopeValue,
+				"budget_id":       budget.ID,
+				"budget_name":     budget.Name,
+				"amount_limit":    budget.AmountLimit,
+				"rollover_amount": budget.RolloverAmount,
+				"effective_limit": limit,
+				"currency":        budgetCurrency(budget),
+				"current_spend":   currentSpend,
+				"usage_percent":   usagePercent,
+				"threshold":       threshold,
+				"period":          budgetPeriod(budget),
+				"period_start":    periodStart.Format(dateLayout),
+				"period_end":      periodEnd.Format(dateLayout),
+				"scope_type":      budget.ScopeType,
+				"scope_value":     budget.ScopeValue,
+				"scopes":          budget.Scopes,
 			},
+			Resource:   budgetResource(budget.ID, "threshold", threshold),
 			Source:     fmt.Sprintf("budget:%d", budget.ID),
 			TenantID:   budget.TenantID,
 			Status:     domain.EventStatusPending,
@@ -252,6 +408,10 @@ func (s *BudgetService) checkSingleBudget(ctx context.Context, budget costdomain
 			elog.Any("usage_percent", usagePercent))
 	}
 
+	if s.checkForecastThresholds(ctx, budget, periodStart, notifiedAt) {
+		updated = true
+	}
+
 	if updated {
 		if err := s.budgetDAO.UpdateNotifiedAt(ctx, budget.ID, notifiedAt); err != nil {
 			return fmt.Errorf("update notified_at: %w", err)
@@ -261,12 +421,129 @@ func (s *BudgetService) checkSingleBudget(ctx context.Context, budget costdomain
 	return nil
 }
 
+// checkForecastThresholds 按周期末预测支出检查预测阈值，在实际支出越线前提前告警
+// 预测阈值的通知记录以 "forecast:" 前缀与实际阈值区分，同样每个周期只通知一次；返回是否有新通知
+func (s *BudgetService) checkForecastThresholds(
+	ctx context.Context,
+	budget costdomain.BudgetRule,
+	periodStart time.Time,
+	notifiedAt map[string]time.Time,
+) bool {
+	limit := budget.EffectiveLimit()
+	if s.forecaster == nil || len(budget.ForecastThresholds) == 0 || limit <= 0 {
+		return false
+	}
+
+	f, err := s.forecastSpend(ctx, budget, "")
+	if err != nil {
+		s.logger.Error("forecast budget spend failed",
+			elog.Int64("budget_id", budget.ID),
+			elog.FieldErr(err))
+		return false
+	}
+	forecastPercent := f.Forecast / limit * 100
+
+	thresholds := make([]float64, len(budget.ForecastThresholds))
+	copy(thresholds, budget.ForecastThresholds)
+	sort.Float64s(thresholds)
+
+	updated := false
+	for _, threshold := range thresholds {
+		if forecastPercent < threshold {
+			continue
+		}
+
+		thresholdKey := forecastThresholdPrefix + strconv.FormatFloat(threshold, 'f', -1, 64)
+		if lastNotified, ok := notifiedAt[thresholdKey]; ok && !lastNotified.Before(periodStart) {
+			continue
+		}
+
+		event := domain.AlertEvent{
+			Type:     domain.AlertType(alertTypeBudgetThreshold),
+			Severity: forecastSeverity(threshold),
+			Title:    fmt.Sprintf("预算预测告警: %s 预计周期末达 %.0f%% 阈值", budget.Name, threshold),
+			Content: map[string]any{
+				"budget_id":        budget.ID,
+				"budget_name":      budget.Name,
+				"amount_limit":     budget.AmountLimit,
+				"effective_limit":  limit,
+				"currency":         budgetCurrency(budget),
+				"basis":            "forecast",
+				"actual_to_date":   f.ActualToDate,
+				"forecast_spend":   f.Forecast,
+				"forecast_lower":   f.Lower,
+				"forecast_upper":   f.Upper,
+				"forecast_percent": forecastPercent,
+				"period":           budgetPeriod(budget),
+				"period_end":       f.PeriodEnd,
+				"threshold":        threshold,
+				"scope_type":       budget.ScopeType,
+				"scope_value":      budget.ScopeValue,
+			},
+			Resource:   budgetResource(budget.ID, "forecast", threshold),
+			Source:     fmt.Sprintf("budget:%d", budget.ID),
+			TenantID:   budget.TenantID,
+			Status:     domain.EventStatusPending,
+			CreateTime: time.Now(),
+		}
+
+		if err := s.alertSvc.EmitEvent(ctx, event); err != nil {
+			s.logger.Error("emit budget forecast alert failed",
+				elog.Int64("budget_id", budget.ID),
+				elog.Any("threshold", threshold),
+				elog.FieldErr(err))
+			continue
+		}
+
+		notifiedAt[thresholdKey] = time.Now()
+		updated = true
+
+		s.logger.Info("budget forecast threshold alert triggered",
+			elog.Int64("budget_id", budget.ID),
+			elog.String("budget_name", budget.Name),
+			elog.Any("threshold", threshold),
+			elog.Any("forecast_percent", forecastPercent))
+	}
+	return updated
+}
+
+// budgetResource 预算告警的资源标识，每个阈值（实际 / 预测）单独去重与升级计数，
+// 避免低阈值告警的静默期压制随后触发的高阈值告警
+func budgetResource(budgetID int64, kind string, threshold float64) string {
+	return fmt.Sprintf("budget:%d:%s:%s", budgetID, kind, strconv.FormatFloat(threshold, 'f', -1, 64))
+}
+
+// forecastSpend 以预算币种预测预算范围的周期末支出
+// horizon 为空时按预算当前周期的起止日期预测
+func (s *BudgetService) forecastSpend(ctx context.Context, budget costdomain.BudgetRule, horizon string) (*forecast.Forecast, error) {
+	filter, err := scopeFilter(budget)
+	if err != nil {
+		return nil, err
+	}
+	req := forecast.SpendRequest{
+		TenantID: budget.TenantID,
+		Currency: budgetCurrency(budget),
+		Horizon:  horizon,
+		Filter:   filter,
+	}
+	if horizon == "" {
+		periodStart, periodEnd, err := periodRange(budget, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		req.Horizon = forecastHorizon(budget.Period)
+		req.PeriodStart = periodStart
+		req.PeriodEnd = periodEnd
+	}
+	return s.forecaster.ForecastSpend(ctx, req)
+}
+
 // DeactivateBudgetsByScope 预算失效处理：适用范围对应的云账号被删除时标记为 inactive
+// 旧版 ScopeType 与多条件范围中的同名维度均参与匹配
 func (s *BudgetService) DeactivateBudgetsByScope(ctx context.Context, tenantID string, scopeType string, scopeValue string) error {
 	filter := repository.BudgetFilter{
-		TenantID:  tenantID,
-		ScopeType: scopeType,
-		Status:    "active",
+		TenantID: tenantID,
+		Status:   "active",
 	}
 	budgets, err := s.budgetDAO.List(ctx, filter)
 	if err != nil {
@@ -274,7 +551,7 @@ func (s *BudgetService) DeactivateBudgetsByScope(ctx context.Context, tenantID s
 	}
 
 	for _, budget := range budgets {
-		if budget.ScopeValue != scopeValue {
+		if !budgetInScope(budget, scopeType, scopeValue) {
 			continue
 		}
 
@@ -297,6 +574,7 @@ func (s *BudgetService) DeactivateBudgetsByScope(ctx context.Context, tenantID s
 				"scope_type":  budget.ScopeType,
 				"scope_value": budget.ScopeValue,
 			},
+			Resource:   fmt.Sprintf("budget:%d:deactivated", budget.ID),
 			Source:     fmt.Sprintf("budget:%d", budget.ID),
 			TenantID:   budget.TenantID,
 			Status:     domain.EventStatusPending,
@@ -318,32 +596,103 @@ func (s *BudgetService) DeactivateBudgetsByScope(ctx context.Context, tenantID s
 	return nil
 }
 
-// calculateCurrentSpend 计算当月实际支出
-func (s *BudgetService) calculateCurrentSpend(ctx context.Context, budget costdomain.BudgetRule) (float64, error) {
-	now := time.Now()
-	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
-	endDate := now.Format("2006-01-02")
+// calculateCurrentSpend 计算当前周期截至今日的实际支出
+func (s *BudgetService) calculateCurrentSpend(ctx context.Context, budget costdomain.BudgetRule, periodStart, periodEnd time.Time) (float64, error) {
+	end := time.Now().UTC()
+	if end.After(periodEnd) {
+		end = periodEnd
+	}
+	return s.calculateSpend(ctx, budget, periodStart.Format(dateLayout), end.Format(dateLayout))
+}
+
+// calculateSpend 计算预算范围在日期区间内的实际支出（预算币种）
+func (s *BudgetService) calculateSpend(ctx context.Context, budget costdomain.BudgetRule, startDate, endDate string) (float64, error) {
+	filter, err := scopeFilter(budget)
+	if err != nil {
+		return 0, err
+	}
+	filter.StartDate = startDate
+	filter.EndDate = endDate
 
-	filter := repository.UnifiedBillFilter{
-		TenantID:  budget.TenantID,
-		StartDate: startDate,
-		EndDate:   endDate,
+	currency := budgetCurrency(budget)
+	if s.converter == nil || currency == exchange.CurrencyCNY {
+		return s.billDAO.SumAmount(ctx, filter)
 	}
 
-	switch budget.ScopeType {
-	case "account":
-		accountID, err := strconv.ParseInt(budget.ScopeValue, 10, 64)
+	// 非人民币预算按日聚合后逐日折算，使用账单当日汇率而非当前汇率
+	daily, err := s.billDAO.AggregateDailyAmount(ctx, budget.TenantID, startDate, endDate, filter)
+	if err != nil {
+		return 0, err
+	}
+	var spend float64
+	for _, d := range daily {
+		amount, err := s.converter.FromCNY(ctx, currency, d.AmountCNY, d.Date)
 		if err != nil {
-			return 0, fmt.Errorf("invalid account scope value %q: %w", budget.ScopeValue, err)
+			return 0, fmt.Errorf("convert to %s: %w", currency, err)
 		}
-		filter.AccountID = accountID
-	case "provider":
-		filter.Provider = budget.ScopeValue
-	case "all":
-		// No additional filter
+		spend += amount
 	}
+	return spend, nil
+}
 
-	return s.billDAO.SumAmount(ctx, filter)
+// resolveCurrency 确定预算币种：显式指定优先，否则使用租户报表币种
+func (s *BudgetService) resolveCurrency(ctx context.Context, tenantID, currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" {
+		if currency != exchange.CurrencyCNY && s.converter == nil {
+			return "", fmt.Errorf("budget currency %s is not supported without exchange rates", currency)
+		}
+		return currency, nil
+	}
+	if s.converter == nil {
+		return exchange.CurrencyCNY, nil
+	}
+	currency, err := s.converter.ReportingCurrency(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("get reporting currency: %w", err)
+	}
+	return currency, nil
+}
+
+// budgetPeriod 预算周期，历史预算未记录周期时为自然月
+func budgetPeriod(budget costdomain.BudgetRule) string {
+	if budget.Period == "" {
+		return costdomain.BudgetPeriodMonthly
+	}
+	return budget.Period
+}
+
+// forecastHorizon 预算周期对应的预测周期标识，年度与自定义周期沿用预算周期名
+func forecastHorizon(period string) string {
+	switch period {
+	case "", costdomain.BudgetPeriodMonthly:
+		return forecast.HorizonMonth
+	case costdomain.BudgetPeriodQuarterly:
+		return forecast.HorizonQuarter
+	default:
+		return period
+	}
+}
+
+// budgetCurrency 预算币种，历史预算未记录币种时为 CNY
+func budgetCurrency(budget costdomain.BudgetRule) string {
+	if budget.Currency == "" {
+		return exchange.CurrencyCNY
+	}
+	return budget.Currency
+}
+
+// validateThresholds 校验阈值：每项在 (0, 100] 内且严格升序
+func validateThresholds(thresholds []float64) error {
+	for i, t := range thresholds {
+		if t <= 0 || t > 100 {
+			return fmt.Errorf("threshold %.2f must be in (0, 100]", t)
+		}
+		if i > 0 && thresholds[i] <= thresholds[i-1] {
+			return fmt.Errorf("thresholds must be sorted in ascending order")
+		}
+	}
+	return nil
 }
 
 // thresholdSeverity 根据阈值百分比确定告警级别
@@ -358,8 +707,10 @@ func thresholdSeverity(threshold float64) domain.Severity {
 	}
 }
 
-// isInCurrentMonth 判断时间是否在当前月份
-func isInCurrentMonth(t time.Time) bool {
-	now := time.Now()
-	return t.Year() == now.Year() && t.Month() == now.Month()
+// forecastSeverity 预测阈值告警级别：尚未实际超支，最高为 warning
+func forecastSeverity(threshold float64) domain.Severity {
+	if threshold >= 100 {
+		return domain.SeverityWarning
+	}
+	return domain.SeverityInfo
 }
@@ -8,6 +8,7 @@ import (
 	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
 	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
 	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/forecast"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/stretchr/testify/assert"
@@ -23,6 +24,7 @@ type mockBudgetDAO struct {
 	listActiveFn     func(ctx context.Context, tenantID string) ([]costdomain.BudgetRule, error)
 	updateStatusFn   func(ctx context.Context, id int64, status string) error
 	updateNotifiedFn func(ctx context.Context, id int64, notifiedAt map[string]time.Time) error
+	updatePeriodFn   func(ctx context.Context, id int64, periodStart string, rolloverAmount float64, notifiedAt map[string]time.Time) error
 	deleteFn         func(ctx context.Context, id int64) error
 }
 
@@ -74,6 +76,12 @@ func (m *mockBudgetDAO) UpdateNotifiedAt(ctx context.Context, id int64, notified
 	}
 	return nil
 }
+func (m *mockBudgetDAO) UpdatePeriod(ctx context.Context, id int64, periodStart string, rolloverAmount float64, notifiedAt map[string]time.Time) error {
+	if m.updatePeriodFn != nil {
+		return m.updatePeriodFn(ctx, id, periodStart, rolloverAmount, notifiedAt)
+	}
+	return nil
+}
 func (m *mockBudgetDAO) Delete(ctx context.Context, id int64) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
@@ -82,7 +90,8 @@ func (m *mockBudgetDAO) Delete(ctx context.Context, id int64) error {
 }
 
 type mockBillDAO struct {
-	sumAmountFn func(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error)
+	sumAmountFn      func(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error)
+	aggregateDailyFn func(ctx context.Context, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error)
 }
 
 func (m *mockBillDAO) SumAmount(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error) {
@@ -124,13 +133,22 @@ func (m *mockBillDAO) CountUnifiedBills(_ context.Context, _ repository.UnifiedB
 func (m *mockBillDAO) AggregateByField(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.AggregateResult, error) {
 	return nil, nil
 }
-func (m *mockBillDAO) AggregateDailyAmount(_ context.Context, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+func (m *mockBillDAO) AggregateDailyAmount(ctx context.Context, _ string, _, _ string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+	if m.aggregateDailyFn != nil {
+		return m.aggregateDailyFn(ctx, filter)
+	}
 	return nil, nil
 }
 func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _, _ string) error { return nil }
 func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
 func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
@@ -138,9 +156,22 @@ func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) (
 	return nil, nil
 }
 
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, _ []repository.AmountCNYUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(_ context.Context, _, _, _, _, _, _ string) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
 type mockAlertDAO struct {
 	emittedEvents []alertdomain.AlertEvent
 	listRulesFn   func(ctx context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error)
+	notifiedAt    map[string]time.Time
 }
 
 func (m *mockAlertDAO) CreateRule(_ context.Context, _ alertdomain.AlertRule) (int64, error) {
@@ -171,6 +202,60 @@ func (m *mockAlertDAO) GetPendingEvents(_ context.Context, _ int) ([]alertdomain
 	return nil, nil
 }
 func (m *mockAlertDAO) IncrementRetry(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetEventByID(_ context.Context, _ int64) (alertdomain.AlertEvent, error) {
+	return alertdomain.AlertEvent{}, nil
+}
+func (m *mockAlertDAO) ResolveEvents(_ context.Context, _ string, _ alertdomain.EventTransition) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) AckEvent(_ context.Context, _ int64, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AssignEvent(_ context.Context, _ int64, _ string, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AppendEventHistory(_ context.Context, _ int64, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) SetAckDeadline(_ context.Context, _ int64, _ *time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) RenotifyEvent(_ context.Context, _ int64, _ time.Time, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) GetRenotifyEvents(_ context.Context, _ time.Time, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) GetState(_ context.Context, _ string) (alertdomain.AlertState, error) {
+	return alertdomain.AlertState{}, nil
+}
+func (m *mockAlertDAO) RecordOccurrence(_ context.Context, state alertdomain.AlertState, _ time.Time) (alertdomain.AlertState, error) {
+	state.Count = 1
+	return state, nil
+}
+func (m *mockAlertDAO) MarkStateEscalated(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) MarkStateNotified(_ context.Context, fingerprint string, now time.Time, silence time.Duration) (bool, error) {
+	if last, ok := m.notifiedAt[fingerprint]; ok && now.Sub(last) < silence {
+		return false, nil
+	}
+	if m.notifiedAt == nil {
+		m.notifiedAt = make(map[string]time.Time)
+	}
+	m.notifiedAt[fingerprint] = now
+	return true, nil
+}
+func (m *mockAlertDAO) SetStateLastEvent(_ context.Context, _ string, _ int64) error { return nil }
+func (m *mockAlertDAO) ResolveState(_ context.Context, _ alertdomain.AlertState, _ time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) ListActiveStates(_ context.Context) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) ListActiveStatesByType(_ context.Context, _ string, _ alertdomain.AlertType) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
 func (m *mockAlertDAO) CreateChannel(_ context.Context, _ alertdomain.NotificationChannel) (int64, error) {
 	return 0, nil
 }
@@ -187,8 +272,49 @@ func (m *mockAlertDAO) DeleteChannel(_ context.Context, _ int64) error { return
 func (m *mockAlertDAO) GetChannelsByIDs(_ context.Context, _ []int64) ([]alertdomain.NotificationChannel, error) {
 	return nil, nil
 }
+func (m *mockAlertDAO) CreateTemplate(_ context.Context, _ alertdomain.MessageTemplate) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateTemplate(_ context.Context, _ alertdomain.MessageTemplate) error {
+	return nil
+}
+func (m *mockAlertDAO) GetTemplateByID(_ context.Context, _ int64) (alertdomain.MessageTemplate, error) {
+	return alertdomain.MessageTemplate{}, nil
+}
+func (m *mockAlertDAO) ListTemplates(_ context.Context, _ alertdomain.MessageTemplateFilter) ([]alertdomain.MessageTemplate, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteTemplate(_ context.Context, _ int64) error {
+	return nil
+}
 func (m *mockAlertDAO) InitIndexes(_ context.Context) error { return nil }
 
+// fakeConverter 固定报表币种，汇率按日期取值
+type fakeConverter struct {
+	currency string
+	rates    map[string]float64
+}
+
+func (f *fakeConverter) ReportingCurrency(_ context.Context, _ string) (string, error) {
+	return f.currency, nil
+}
+
+func (f *fakeConverter) FromCNY(_ context.Context, _ string, amountCNY float64, date string) (float64, error) {
+	return amountCNY * f.rates[date], nil
+}
+
+// fakeForecaster 返回固定预测结果，并记录最近一次请求
+type fakeForecaster struct {
+	result  forecast.Forecast
+	lastReq forecast.SpendRequest
+}
+
+func (f *fakeForecaster) ForecastSpend(_ context.Context, req forecast.SpendRequest) (*forecast.Forecast, error) {
+	f.lastReq = req
+	result := f.result
+	return &result, nil
+}
+
 func setupTestService(t *testing.T, budgetDAO *mockBudgetDAO, billDAO *mockBillDAO, alertDAO *mockAlertDAO) *BudgetService {
 	t.Helper()
 	logger := elog.DefaultLogger
@@ -291,6 +417,33 @@ func TestCheckBudgets_TriggersAlert(t *testing.T) {
 	assert.Contains(t, notifiedAt, "80")
 }
 
+func TestCheckBudgets_HigherThresholdNotSilenced(t *testing.T) {
+	alertDAO := &mockAlertDAO{listRulesFn: func(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+		return []alertdomain.AlertRule{{ID: 1, Type: filter.Type, Enabled: true, SilenceDuration: 60}}, 1, nil
+	}}
+	var notifiedAt map[string]time.Time
+	budgetDAO := &mockBudgetDAO{listActiveFn: func(_ context.Context, _ string) ([]costdomain.BudgetRule, error) {
+		return []costdomain.BudgetRule{{ID: 1, Name: "T", AmountLimit: 10000, ScopeType: "all", Thresholds: []float64{50, 100},
+			NotifiedAt: notifiedAt, TenantID: "t1", Status: "active"}}, nil
+	}}
+	budgetDAO.updateNotifiedFn = func(_ context.Context, _ int64, na map[string]time.Time) error { notifiedAt = na; return nil }
+	spend := 5000.0
+	billDAO := &mockBillDAO{sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) { return spend, nil }}
+	svc := setupTestService(t, budgetDAO, billDAO, alertDAO)
+
+	require.NoError(t, svc.CheckBudgets(context.Background(), "t1"))
+	spend = 10000
+	require.NoError(t, svc.CheckBudgets(context.Background(), "t1"))
+
+	// 50% 与 100% 在同一静默期内触发，两条告警都应送达
+	require.Len(t, alertDAO.emittedEvents, 2)
+	assert.Equal(t, "budget:1:threshold:50", alertDAO.emittedEvents[0].Resource)
+	assert.Equal(t, "budget:1:threshold:100", alertDAO.emittedEvents[1].Resource)
+	for _, e := range alertDAO.emittedEvents {
+		assert.NotEqual(t, alertdomain.EventStatusSilenced, e.Status)
+	}
+}
+
 func TestCheckBudgets_NoAlertBelowThreshold(t *testing.T) {
 	alertDAO := &mockAlertDAO{}
 	budgetDAO := &mockBudgetDAO{listActiveFn: func(_ context.Context, _ string) ([]costdomain.BudgetRule, error) {
@@ -354,14 +507,6 @@ func TestThresholdSeverity(t *testing.T) {
 	assert.Equal(t, alertdomain.SeverityCritical, thresholdSeverity(100))
 }
 
-func TestIsInCurrentMonth(t *testing.T) {
-	now := time.Now()
-	assert.True(t, isInCurrentMonth(now))
-	assert.True(t, isInCurrentMonth(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)))
-	assert.False(t, isInCurrentMonth(now.AddDate(0, -1, 0)))
-	assert.False(t, isInCurrentMonth(now.AddDate(-1, 0, 0)))
-}
-
 func TestDeleteBudget(t *testing.T) {
 	deleted := false
 	budgetDAO := &mockBudgetDAO{deleteFn: func(_ context.Context, id int64) error { assert.Equal(t, int64(42), id); deleted = true; return nil }}
@@ -378,3 +523,150 @@ func TestUpdateBudget_Validation(t *testing.T) {
 	err = svc.UpdateBudget(context.Background(), costdomain.BudgetRule{Name: "Test", AmountLimit: 0})
 	assert.Error(t, err)
 }
+
+func TestCreateBudget_DefaultsToReportingCurrency(t *testing.T) {
+	var created costdomain.BudgetRule
+	budgetDAO := &mockBudgetDAO{
+		createFn: func(_ context.Context, b costdomain.BudgetRule) (int64, error) {
+			created = b
+			return 1, nil
+		},
+	}
+	svc := setupTestService(t, budgetDAO, &mockBillDAO{}, &mockAlertDAO{})
+
+	_, err := svc.CreateBudget(context.Background(), costdomain.BudgetRule{
+		Name: "Default", AmountLimit: 1000, Thresholds: []float64{80}, TenantID: "tenant1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CNY", created.Currency, "未注入换算器时为人民币")
+
+	svc.SetCurrencyConverter(&fakeConverter{currency: "USD"})
+	_, err = svc.CreateBudget(context.Background(), costdomain.BudgetRule{
+		Name: "Overseas", AmountLimit: 1000, Thresholds: []float64{80}, TenantID: "tenant1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", created.Currency)
+
+	_, err = svc.CreateBudget(context.Background(), costdomain.BudgetRule{
+		Name: "Explicit", AmountLimit: 1000, Currency: "eur", Thresholds: []float64{80}, TenantID: "tenant1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", created.Currency)
+}
+
+func TestGetBudgetProgress_ForeignCurrency(t *testing.T) {
+	budgetDAO := &mockBudgetDAO{
+		getByIDFn: func(_ context.Context, id int64) (costdomain.BudgetRule, error) {
+			return costdomain.BudgetRule{
+				ID: id, Name: "USD Budget", AmountLimit: 100, Currency: "USD",
+				ScopeType: "all", Period: "monthly", Status: "active", TenantID: "tenant1",
+			}, nil
+		},
+	}
+	billDAO := &mockBillDAO{
+		sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
+			t.Fatal("foreign currency budget should not sum CNY amounts directly")
+			return 0, nil
+		},
+		aggregateDailyFn: func(_ context.Context, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+			return []repository.DailyAmount{
+				{Date: "2024-03-01", AmountCNY: 200},
+				{Date: "2024-03-02", AmountCNY: 300},
+			}, nil
+		},
+	}
+	svc := setupTestService(t, budgetDAO, billDAO, &mockAlertDAO{})
+	svc.SetCurrencyConverter(&fakeConverter{
+		currency: "USD",
+		rates:    map[string]float64{"2024-03-01": 0.1, "2024-03-02": 0.2},
+	})
+
+	progress, err := svc.GetBudgetProgress(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", progress.Currency)
+	// 200*0.1 + 300*0.2 = 80 USD
+	assert.InDelta(t, 80.0, progress.CurrentSpend, 0.01)
+	assert.InDelta(t, 80.0, progress.UsagePercent, 0.01)
+	assert.InDelta(t, 20.0, progress.RemainingAmount, 0.01)
+}
+
+func TestCreateBudget_InvalidForecastThresholds(t *testing.T) {
+	svc := setupTestService(t, &mockBudgetDAO{}, &mockBillDAO{}, &mockAlertDAO{})
+	_, err := svc.CreateBudget(context.Background(), costdomain.BudgetRule{
+		Name: "T", AmountLimit: 1000, Thresholds: []float64{80}, ForecastThresholds: []float64{100, 90},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "forecast")
+}
+
+func TestCheckBudgets_ForecastThresholds(t *testing.T) {
+	alertDAO := &mockAlertDAO{listRulesFn: func(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+		return []alertdomain.AlertRule{{ID: 1, Type: filter.Type, Enabled: true}}, 1, nil
+	}}
+	budgetDAO := &mockBudgetDAO{listActiveFn: func(_ context.Context, _ string) ([]costdomain.BudgetRule, error) {
+		return []costdomain.BudgetRule{{
+			ID: 1, Name: "T", AmountLimit: 10000, ScopeType: "provider", ScopeValue: "aws",
+			Thresholds: []float64{80}, ForecastThresholds: []float64{90, 100, 110},
+			NotifiedAt: map[string]time.Time{"forecast:90": time.Now().AddDate(0, -1, 0)},
+			TenantID:   "t1", Status: "active",
+		}}, nil
+	}}
+	// 月初实际支出仅 30%，预测月末 105%
+	billDAO := &mockBillDAO{sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) { return 3000, nil }}
+	var notifiedAt map[string]time.Time
+	budgetDAO.updateNotifiedFn = func(_ context.Context, _ int64, na map[string]time.Time) error { notifiedAt = na; return nil }
+
+	forecaster := &fakeForecaster{result: forecast.Forecast{ActualToDate: 3000, Forecast: 10500, Lower: 9800, Upper: 11200, PeriodEnd: "2024-03-31"}}
+	svc := setupTestService(t, budgetDAO, billDAO, alertDAO)
+	svc.SetForecaster(forecaster)
+	require.NoError(t, svc.CheckBudgets(context.Background(), "t1"))
+
+	assert.Equal(t, "aws", forecaster.lastReq.Filter.Provider, "按预算范围预测")
+	assert.Equal(t, "CNY", forecaster.lastReq.Currency)
+	assert.Equal(t, forecast.HorizonMonth, forecaster.lastReq.Horizon)
+
+	// 实际 80% 阈值未触发；预测 90%（上次通知在上月）、100% 阈值触发，110% 未达到
+	require.Len(t, alertDAO.emittedEvents, 2)
+	for _, evt := range alertDAO.emittedEvents {
+		assert.Contains(t, evt.Title, "预算预测告警")
+		assert.Equal(t, "forecast", evt.Content["basis"])
+		assert.Equal(t, 10500.0, evt.Content["forecast_spend"])
+	}
+	assert.Equal(t, alertdomain.SeverityWarning, alertDAO.emittedEvents[1].Severity)
+	assert.Contains(t, notifiedAt, "forecast:90")
+	assert.Contains(t, notifiedAt, "forecast:100")
+	assert.NotContains(t, notifiedAt, "80")
+}
+
+func TestCheckBudgets_ForecastThresholdsWithoutForecaster(t *testing.T) {
+	alertDAO := &mockAlertDAO{}
+	budgetDAO := &mockBudgetDAO{listActiveFn: func(_ context.Context, _ string) ([]costdomain.BudgetRule, error) {
+		return []costdomain.BudgetRule{{
+			ID: 1, Name: "T", AmountLimit: 10000, ScopeType: "all",
+			Thresholds: []float64{80}, ForecastThresholds: []float64{50}, TenantID: "t1", Status: "active",
+		}}, nil
+	}}
+	billDAO := &mockBillDAO{sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) { return 3000, nil }}
+	svc := setupTestService(t, budgetDAO, billDAO, alertDAO)
+	require.NoError(t, svc.CheckBudgets(context.Background(), "t1"))
+	assert.Empty(t, alertDAO.emittedEvents)
+}
+
+func TestGetBudgetProgress_Forecast(t *testing.T) {
+	budgetDAO := &mockBudgetDAO{
+		getByIDFn: func(_ context.Context, id int64) (costdomain.BudgetRule, error) {
+			return costdomain.BudgetRule{ID: id, Name: "T", AmountLimit: 10000, ScopeType: "all", TenantID: "t1", Status: "active"}, nil
+		},
+	}
+	billDAO := &mockBillDAO{sumAmountFn: func(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) { return 3000, nil }}
+	svc := setupTestService(t, budgetDAO, billDAO, &mockAlertDAO{})
+	svc.SetForecaster(&fakeForecaster{result: forecast.Forecast{Forecast: 12000, Lower: 11000, Upper: 13000}})
+
+	progress, err := svc.GetBudgetProgress(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, progress.UsagePercent)
+	assert.Equal(t, 12000.0, progress.ForecastSpend)
+	assert.Equal(t, 11000.0, progress.ForecastLower)
+	assert.Equal(t, 13000.0, progress.ForecastUpper)
+	assert.Equal(t, 120.0, progress.ForecastUsagePercent)
+}
@@ -0,0 +1,161 @@
+package chargeback
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// A4 版面（单位 pt）
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+	pdfLineFactor = 1.6
+)
+
+// pdfDoc 最小化的纯文本 PDF 生成器
+// 使用 PDF 阅读器内置的 STSong-Light（Adobe-GB1）CID 字体与 UniGB-UCS2-H 编码，无需嵌入字体即可显示中文
+type pdfDoc struct {
+	pages []*bytes.Buffer
+	y     float64 // 当前行顶部的纵坐标
+}
+
+func newPDFDoc() *pdfDoc {
+	d := &pdfDoc{}
+	d.addPage()
+	return d
+}
+
+func (d *pdfDoc) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// ensure 剩余空间不足一行时换页
+func (d *pdfDoc) ensure(size float64) {
+	if d.y-size*pdfLineFactor < pdfMargin {
+		d.addPage()
+	}
+}
+
+// text 在当前行 x 处（相对左边距）输出文本，不换行
+func (d *pdfDoc) text(x float64, s string, size float64) {
+	d.ensure(size)
+	page := d.pages[len(d.pages)-1]
+	fmt.Fprintf(page, "BT /F1 %s Tf %s %s Td <%s> Tj ET\n",
+		pdfNum(size), pdfNum(pdfMargin+x), pdfNum(d.y-size), encodeUCS2(s))
+}
+
+// newline 按字号换行
+func (d *pdfDoc) newline(size float64) {
+	d.y -= size * pdfLineFactor
+}
+
+// row 按列位置输出一行表格，超出列宽的内容截断
+func (d *pdfDoc) row(cols []float64, cells []string, size float64) {
+	d.ensure(size)
+	for i, cell := range cells {
+		if i >= len(cols) {
+			break
+		}
+		width := pdfPageWidth - 2*pdfMargin - cols[i]
+		if i+1 < len(cols) {
+			width = cols[i+1] - cols[i] - size/2
+		}
+		d.text(cols[i], truncateWidth(cell, width, size), size)
+	}
+	d.newline(size)
+}
+
+// bytes 输出 PDF 文件内容
+func (d *pdfDoc) bytes() []byte {
+	var objects []string
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // Pages，页对象编号确定后填充
+		"<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>",
+		"<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light"+
+			" /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >>"+
+			" /FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>",
+		"<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880]"+
+			" /ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>",
+	)
+
+	var kids bytes.Buffer
+	for _, content := range d.pages {
+		pageNum := len(objects) + 1
+		fmt.Fprintf(&kids, "%d 0 R ", pageNum)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfNum(pdfPageWidth), pdfNum(pdfPageHeight), pageNum+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), len(d.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// encodeUCS2 将文本编码为 UCS-2 大端十六进制串，超出基本多文种平面的字符替换为 ?
+func encodeUCS2(s string) string {
+	var b bytes.Buffer
+	for _, r := range s {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+// textWidth 估算文本宽度：ASCII 半角，其余全角
+func textWidth(s string, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		if r < 0x80 {
+			w += size / 2
+		} else {
+			w += size
+		}
+	}
+	return w
+}
+
+// truncateWidth 将文本截断到指定宽度内，截断时以 ... 结尾
+func truncateWidth(s string, width, size float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	limit := width - textWidth("...", size)
+	var w float64
+	for i, r := range s {
+		rw := size
+		if r < 0x80 {
+			rw = size / 2
+		}
+		if w+rw > limit {
+			return s[:i] + "..."
+		}
+		w += rw
+	}
+	return s
+}
+
+// pdfNum PDF 数值格式化
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,230 @@
+package chargeback
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"strconv"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+)
+
+// 分账单渲染格式
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+	FormatCSV  = "csv"
+)
+
+// Render 按格式渲染分账单，返回内容与 Content-Type
+func Render(stmt domain.ChargebackStatement, format string) ([]byte, string, error) {
+	switch format {
+	case FormatHTML:
+		data, err := RenderHTML(stmt)
+		return data, "text/html; charset=utf-8", err
+	case FormatPDF:
+		return RenderPDF(stmt), "application/pdf", nil
+	case FormatCSV:
+		data, err := RenderCSV(stmt)
+		return data, "text/csv; charset=utf-8", err
+	}
+	return nil, "", fmt.Errorf("%w: unsupported format %q", domain.ErrChargebackInvalid, format)
+}
+
+// statementView 分账单渲染视图，HTML / CSV / PDF 共用同一组行
+type statementView struct {
+	Title     string
+	Generated string
+	Summary   [][2]string
+	Resources [][]string
+	Children  [][]string
+	Diff      [][]string
+}
+
+var (
+	resourceHeader = []string{"资源", "服务类型", "本月金额", "上月金额", "环比变化"}
+	childHeader    = []string{"子节点", "金额", "占比"}
+	diffHeader     = []string{"资源", "本版本金额", "上一版本金额", "变化"}
+)
+
+// newStatementView 构建分账单渲染视图
+func newStatementView(stmt domain.ChargebackStatement) statementView {
+	v := statementView{
+		Title:     statementTitle(stmt),
+		Generated: time.UnixMilli(stmt.CreateTime).Format("2006-01-02 15:04:05"),
+	}
+	v.Summary = [][2]string{{targetTypeLabel(stmt.TargetType), fmt.Sprintf("%s (%s)", stmt.TargetName, stmt.TargetID)}}
+	if stmt.Owner != "" {
+		v.Summary = append(v.Summary, [2]string{"负责人", stmt.Owner})
+	}
+	v.Summary = append(v.Summary, [][2]string{
+		{"账期", stmt.Period},
+		{"版本", "v" + strconv.Itoa(stmt.Version)},
+		{"币种", stmt.Currency},
+		{"总成本", money(stmt.TotalAmount)},
+		{"直接归属", money(stmt.DirectAmount)},
+		{"共享分摊", money(stmt.SharedAmount)},
+		{"比例分摊", money(stmt.RatioAmount)},
+		{"默认归属", money(stmt.DefaultAmount)},
+		{"上月总成本", money(stmt.PrevTotalAmount)},
+		{"环比变化", fmt.Sprintf("%+.2f (%+.2f%%)", stmt.MoMDelta, stmt.MoMPct)},
+		{"租户当期总成本", money(stmt.PeriodTotalAmount)},
+		{"租户未分摊", fmt.Sprintf("%s (%.2f%%)", money(stmt.UnallocatedAmount), stmt.UnallocatedPct)},
+	}...)
+
+	for _, r := range stmt.TopResources {
+		v.Resources = append(v.Resources, []string{
+			resourceLabel(r), r.ServiceType, money(r.Amount), money(r.PrevAmount), fmt.Sprintf("%+.2f", r.Amount-r.PrevAmount),
+		})
+	}
+	for _, c := range stmt.Children {
+		v.Children = append(v.Children, []string{
+			fmt.Sprintf("%s (%s)", c.Name, c.Key), money(c.Amount), fmt.Sprintf("%.2f%%", pct(c.Amount, stmt.TotalAmount)),
+		})
+	}
+	if stmt.Diff != nil {
+		v.Diff = append(v.Diff, []string{
+			fmt.Sprintf("总成本（较 v%d）", stmt.Diff.BaseVersion),
+			money(stmt.TotalAmount), money(stmt.TotalAmount - stmt.Diff.TotalDelta), fmt.Sprintf("%+.2f", stmt.Diff.TotalDelta),
+		})
+		for _, r := range stmt.Diff.ResourceDelta {
+			v.Diff = append(v.Diff, []string{
+				resourceLabel(r), money(r.Amount), money(r.PrevAmount), fmt.Sprintf("%+.2f", r.Amount-r.PrevAmount),
+			})
+		}
+	}
+	return v
+}
+
+var statementTemplate = template.Must(template.New("statement").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; color: #1f2329; margin: 32px; }
+h1 { font-size: 20px; }
+h2 { font-size: 16px; margin-top: 24px; }
+table { border-collapse: collapse; min-width: 480px; }
+th, td { border: 1px solid #dee0e3; padding: 6px 10px; font-size: 13px; text-align: left; }
+th { background: #f5f6f7; }
+.muted { color: #8f959e; font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">生成时间: {{.Generated}}</p>
+<table>
+{{range .Summary}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+{{if .Resources}}<h2>Top 资源</h2>
+<table>
+<tr>{{range $.ResourceHeader}}<th>{{.}}</th>{{end}}</tr>
+{{range .Resources}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{end}}
+{{if .Children}}<h2>子节点</h2>
+<table>
+<tr>{{range $.ChildHeader}}<th>{{.}}</th>{{end}}</tr>
+{{range .Children}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{end}}
+{{if .Diff}}<h2>版本差异</h2>
+<table>
+<tr>{{range $.DiffHeader}}<th>{{.}}</th>{{end}}</tr>
+{{range .Diff}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML 渲染 HTML 分账单
+func RenderHTML(stmt domain.ChargebackStatement) ([]byte, error) {
+	data := struct {
+		statementView
+		ResourceHeader []string
+		ChildHeader    []string
+		DiffHeader     []string
+	}{newStatementView(stmt), resourceHeader, childHeader, diffHeader}
+
+	var buf bytes.Buffer
+	if err := statementTemplate.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("render html: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderCSV 渲染 CSV 分账单：汇总、Top 资源、子节点与版本差异分段输出，段间空行分隔
+func RenderCSV(stmt domain.ChargebackStatement) ([]byte, error) {
+	v := newStatementView(stmt)
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	records := [][]string{{"项目", "值"}}
+	for _, kv := range v.Summary {
+		records = append(records, []string{kv[0], kv[1]})
+	}
+	sections := []struct {
+		header []string
+		rows   [][]string
+	}{
+		{resourceHeader, v.Resources},
+		{childHeader, v.Children},
+		{diffHeader, v.Diff},
+	}
+	for _, sec := range sections {
+		if len(sec.rows) == 0 {
+			continue
+		}
+		records = append(records, nil, sec.header)
+		records = append(records, sec.rows...)
+	}
+	if err := w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("render csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPDF 渲染 PDF 分账单
+func RenderPDF(stmt domain.ChargebackStatement) []byte {
+	v := newStatementView(stmt)
+	doc := newPDFDoc()
+	doc.text(0, v.Title, 16)
+	doc.newline(16)
+	doc.text(0, "生成时间: "+v.Generated, 9)
+	doc.newline(9)
+	doc.newline(9)
+
+	for _, kv := range v.Summary {
+		doc.row([]float64{0, 140}, kv[:], 10)
+	}
+
+	sections := []struct {
+		title  string
+		cols   []float64
+		header []string
+		rows   [][]string
+	}{
+		{"Top 资源", []float64{0, 210, 290, 360, 430}, resourceHeader, v.Resources},
+		{"子节点", []float64{0, 300, 400}, childHeader, v.Children},
+		{"版本差异", []float64{0, 250, 340, 430}, diffHeader, v.Diff},
+	}
+	for _, sec := range sections {
+		if len(sec.rows) == 0 {
+			continue
+		}
+		doc.newline(10)
+		doc.text(0, sec.title, 13)
+		doc.newline(13)
+		doc.row(sec.cols, sec.header, 10)
+		for _, r := range sec.rows {
+			doc.row(sec.cols, r, 9)
+		}
+	}
+	return doc.bytes()
+}
+
+// money 金额格式化
+func money(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,72 @@
+package chargeback
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement() domain.ChargebackStatement {
+	return domain.ChargebackStatement{
+		ID: 1, Period: "2026-09", TargetType: domain.ChargebackTargetNode, TargetID: "1",
+		TargetName: "电商<平台>", Owner: "张三", Version: 2, Currency: "CNY",
+		TotalAmount: 800, DirectAmount: 600, SharedAmount: 200,
+		PrevTotalAmount: 400, MoMDelta: 400, MoMPct: 100,
+		PeriodTotalAmount: 1000, UnallocatedAmount: 20, UnallocatedPct: 2,
+		TopResources: []domain.StatementResource{{ResourceID: "i-order", ResourceName: "order-api", ServiceType: "ecs", Amount: 600, PrevAmount: 400}},
+		Children:     []domain.StatementLine{{Key: "2", Name: "订单", Amount: 800}},
+		Diff:         &domain.StatementDiff{BaseVersion: 1, TotalDelta: 100},
+	}
+}
+
+func TestRenderCSV_Sections(t *testing.T) {
+	data, _, err := Render(testStatement(), FormatCSV)
+	require.NoError(t, err)
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"项目", "值"}, records[0])
+	assert.Contains(t, records, []string{"总成本", "800.00"})
+	assert.Contains(t, records, []string{"环比变化", "+400.00 (+100.00%)"})
+	assert.Contains(t, records, resourceHeader)
+	assert.Contains(t, records, []string{"order-api (i-order)", "ecs", "600.00", "400.00", "+200.00"})
+	assert.Contains(t, records, []string{"订单 (2)", "800.00", "100.00%"})
+	assert.Contains(t, records, []string{"总成本（较 v1）", "800.00", "700.00", "+100.00"})
+}
+
+func TestRenderHTML_Escapes(t *testing.T) {
+	data, contentType, err := Render(testStatement(), FormatHTML)
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", contentType)
+	assert.Contains(t, string(data), "电商&lt;平台&gt;")
+	assert.Contains(t, string(data), "版本差异")
+}
+
+func TestRenderPDF(t *testing.T) {
+	data, contentType, err := Render(testStatement(), FormatPDF)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", contentType)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+	assert.Contains(t, string(data), encodeUCS2("订单"))
+	assert.Contains(t, string(data), "/Count 1")
+}
+
+func TestRender_UnsupportedFormat(t *testing.T) {
+	_, _, err := Render(testStatement(), "xlsx")
+	assert.ErrorIs(t, err, domain.ErrChargebackInvalid)
+}
+
+func TestTruncateWidth(t *testing.T) {
+	assert.Equal(t, "abc", truncateWidth("abc", 100, 10))
+	// 每个中文 10pt，"..." 15pt：45pt 可容纳 3 个中文，40pt 只能容纳 2 个
+	assert.Equal(t, "订单服...", truncateWidth("订单服务网关", 45, 10))
+	assert.Equal(t, "订单...", truncateWidth("订单服务网关", 40, 10))
+}
@@ -0,0 +1,618 @@
+// Package chargeback 按服务树节点 / 分摊目标生成成本分账单（showback / chargeback）
+package chargeback
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// topResourceLimit 分账单展示的 Top 资源数量
+	topResourceLimit = 10
+	// diffResourceLimit 版本差异中展示的资源变化数量
+	diffResourceLimit = 20
+)
+
+// Node 服务树节点
+type Node struct {
+	ID       int64
+	ParentID int64
+	Name     string
+	Owner    string
+}
+
+// NodeResolver 服务树节点查询接口（可选注入）
+// 注入后节点分账单使用节点名称与负责人，并按服务树层级向上汇总子节点成本
+type NodeResolver interface {
+	ListNodes(ctx context.Context, tenantID string) ([]Node, error)
+}
+
+// ChargebackService 成本分账单服务
+type ChargebackService struct {
+	allocationDAO repository.AllocationDAO
+	chargebackDAO repository.ChargebackDAO
+	alertSvc      *alertservice.AlertService
+	nodes         NodeResolver
+	linkBase      string
+	logger        *elog.Component
+}
+
+// NewChargebackService 创建成本分账单服务
+func NewChargebackService(
+	allocationDAO repository.AllocationDAO,
+	chargebackDAO repository.ChargebackDAO,
+	alertSvc *alertservice.AlertService,
+	logger *elog.Component,
+) *ChargebackService {
+	return &ChargebackService{
+		allocationDAO: allocationDAO,
+		chargebackDAO: chargebackDAO,
+		alertSvc:      alertSvc,
+		logger:        logger,
+	}
+}
+
+// SetNodeResolver 设置服务树节点查询（可选注入）
+func (s *ChargebackService) SetNodeResolver(nodes NodeResolver) {
+	s.nodes = nodes
+}
+
+// SetLinkBase 设置通知消息中分账单下载链接的地址前缀（如 https://finops.example.com），为空时不附带链接
+func (s *ChargebackService) SetLinkBase(base string) {
+	s.linkBase = strings.TrimRight(base, "/")
+}
+
+// GenerateStatements 基于账期分摊结果生成分账单，tenantID 为空时生成全部租户
+// 内容与最新版本一致的对象不生成新版本；返回本次新生成的分账单
+func (s *ChargebackService) GenerateStatements(ctx context.Context, tenantID, period string) ([]domain.ChargebackStatement, error) {
+	start, err := time.Parse("2006-01", period)
+	if err != nil {
+		return nil, fmt.Errorf("%w: period must be YYYY-MM", domain.ErrChargebackInvalid)
+	}
+	prevPeriod := start.AddDate(0, -1, 0).Format("2006-01")
+
+	allocs, err := s.allocationDAO.ListAllocations(ctx, repository.AllocationFilter{TenantID: tenantID, Period: period})
+	if err != nil {
+		return nil, fmt.Errorf("list allocations: %w", err)
+	}
+	prevAllocs, err := s.allocationDAO.ListAllocations(ctx, repository.AllocationFilter{TenantID: tenantID, Period: prevPeriod})
+	if err != nil {
+		return nil, fmt.Errorf("list previous allocations: %w", err)
+	}
+
+	current := groupByTenant(allocs)
+	previous := groupByTenant(prevAllocs)
+	tenants := make([]string, 0, len(current))
+	for t := range current {
+		tenants = append(tenants, t)
+	}
+	sort.Strings(tenants)
+
+	var generated []domain.ChargebackStatement
+	for _, t := range tenants {
+		stmts, err := s.buildStatements(ctx, t, period, current[t], previous[t])
+		if err != nil {
+			return generated, err
+		}
+		for _, stmt := range stmts {
+			saved, created, err := s.saveVersion(ctx, stmt)
+			if err != nil {
+				return generated, fmt.Errorf("save statement %s/%s: %w", stmt.TargetType, stmt.TargetID, err)
+			}
+			if created {
+				generated = append(generated, saved)
+			}
+		}
+	}
+
+	s.logger.Info("chargeback statements generated",
+		elog.String("tenant_id", tenantID),
+		elog.String("period", period),
+		elog.Int("generated", len(generated)))
+	return generated, nil
+}
+
+// StartScheduledGeneration 生成上一自然月的分账单，并对新版本发送分账单通知（按告警规则路由到通知渠道）
+func (s *ChargebackService) StartScheduledGeneration(ctx context.Context, now time.Time) error {
+	period := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0).Format("2006-01")
+	stmts, err := s.GenerateStatements(ctx, "", period)
+	if err != nil {
+		return err
+	}
+	if s.alertSvc == nil {
+		return nil
+	}
+	for _, stmt := range stmts {
+		if err := s.alertSvc.EmitEvent(ctx, s.buildEvent(stmt)); err != nil {
+			s.logger.Error("emit chargeback event failed", elog.Int64("id", stmt.ID), elog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+// GetStatement 获取分账单，不属于该租户时视为不存在
+func (s *ChargebackService) GetStatement(ctx context.Context, tenantID string, id int64) (domain.ChargebackStatement, error) {
+	stmt, err := s.chargebackDAO.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return stmt, fmt.Errorf("%w: statement %d not found", domain.ErrChargebackInvalid, id)
+		}
+		return stmt, err
+	}
+	if tenantID != "" && stmt.TenantID != tenantID {
+		return domain.ChargebackStatement{}, fmt.Errorf("%w: statement %d not found", domain.ErrChargebackInvalid, id)
+	}
+	return stmt, nil
+}
+
+// ListStatements 查询分账单
+func (s *ChargebackService) ListStatements(ctx context.Context, tenantID string, filter repository.ChargebackFilter) ([]domain.ChargebackStatement, int64, error) {
+	filter.TenantID = tenantID
+	items, err := s.chargebackDAO.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.chargebackDAO.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// RenderStatement 按格式（html / pdf / csv）渲染分账单，返回内容、Content-Type 与文件名
+func (s *ChargebackService) RenderStatement(ctx context.Context, tenantID string, id int64, format string) ([]byte, string, string, error) {
+	stmt, err := s.GetStatement(ctx, tenantID, id)
+	if err != nil {
+		return nil, "", "", err
+	}
+	data, contentType, err := Render(stmt, format)
+	if err != nil {
+		return nil, "", "", err
+	}
+	filename := fmt.Sprintf("chargeback-%s-%s-%s-v%d.%s", stmt.Period, stmt.TargetType, stmt.TargetID, stmt.Version, format)
+	return data, contentType, filename, nil
+}
+
+// DeliverStatement 通过指定通知渠道发送分账单摘要
+func (s *ChargebackService) DeliverStatement(ctx context.Context, tenantID string, id int64, channelIDs []int64) error {
+	if len(channelIDs) == 0 {
+		return fmt.Errorf("%w: channel_ids is required", domain.ErrChargebackInvalid)
+	}
+	if s.alertSvc == nil {
+		return fmt.Errorf("alert service not configured")
+	}
+	stmt, err := s.GetStatement(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.alertSvc.NotifyChannels(ctx, stmt.TenantID, channelIDs, statementTitle(stmt), s.summary(stmt)); err != nil {
+		return fmt.Errorf("deliver statement: %w", err)
+	}
+	return s.chargebackDAO.MarkDelivered(ctx, stmt.ID, channelIDs, time.Now().UnixMilli())
+}
+
+// accumulator 单个分账对象的金额累加
+type accumulator struct {
+	targetType string
+	targetID   string
+	name       string
+	total      float64
+	direct     float64
+	shared     float64
+	ratio      float64
+	deflt      float64
+	resources  map[string]*domain.StatementResource
+	children   map[int64]float64
+}
+
+func newAccumulator(targetType, targetID string) *accumulator {
+	return &accumulator{
+		targetType: targetType,
+		targetID:   targetID,
+		resources:  make(map[string]*domain.StatementResource),
+		children:   make(map[int64]float64),
+	}
+}
+
+func (a *accumulator) add(alloc domain.CostAllocation) {
+	a.total += alloc.TotalAmount
+	a.direct += alloc.DirectAmount
+	a.shared += alloc.SharedAmount
+	a.ratio += alloc.RatioAmount
+	if alloc.DefaultFlag {
+		a.deflt += alloc.TotalAmount
+	}
+	r, ok := a.resources[alloc.ResourceID]
+	if !ok {
+		r = &domain.StatementResource{ResourceID: alloc.ResourceID, ResourceName: alloc.ResourceName, ServiceType: alloc.ServiceType}
+		a.resources[alloc.ResourceID] = r
+	}
+	r.Amount += alloc.TotalAmount
+}
+
+// addOwn 累加直接分摊到该对象的分摊结果，并以分摊结果的目标名称作为对象名称
+func (a *accumulator) addOwn(alloc domain.CostAllocation) {
+	a.add(alloc)
+	if a.name == "" {
+		a.name = alloc.TargetName
+	}
+}
+
+// aggregation 租户账期的分摊汇总
+type aggregation struct {
+	targets     map[string]*accumulator
+	total       float64
+	unallocated float64
+}
+
+// aggregate 汇总分摊结果：服务树节点分摊计入节点及其全部祖先节点，其他分摊计入分摊目标
+// 祖先节点只累加金额不取名称，名称由 buildStatements 按服务树补全
+func aggregate(allocs []domain.CostAllocation, parents map[int64]int64) aggregation {
+	agg := aggregation{targets: make(map[string]*accumulator)}
+	get := func(targetType, targetID string) *accumulator {
+		key := targetType + ":" + targetID
+		acc, ok := agg.targets[key]
+		if !ok {
+			acc = newAccumulator(targetType, targetID)
+			agg.targets[key] = acc
+		}
+		return acc
+	}
+
+	for _, alloc := range allocs {
+		agg.total += alloc.TotalAmount
+		switch {
+		case alloc.UnallocatedFlag:
+			agg.unallocated += alloc.TotalAmount
+		case alloc.NodeID != 0:
+			child := alloc.NodeID
+			acc := get(domain.ChargebackTargetNode, strconv.FormatInt(child, 10))
+			acc.addOwn(alloc)
+			// 向上汇总，seen 防止服务树数据异常形成环
+			seen := map[int64]bool{child: true}
+			for parent, ok := parents[child]; ok && parent != 0 && !seen[parent]; parent, ok = parents[child] {
+				seen[parent] = true
+				pacc := get(domain.ChargebackTargetNode, strconv.FormatInt(parent, 10))
+				pacc.add(alloc)
+				pacc.children[child] += alloc.TotalAmount
+				child = parent
+			}
+		case alloc.DimValue != "":
+			get(domain.ChargebackTargetTarget, alloc.DimValue).addOwn(alloc)
+		}
+	}
+	return agg
+}
+
+// buildStatements 构建租户账期全部分账对象的分账单（尚未版本化）
+func (s *ChargebackService) buildStatements(ctx context.Context, tenantID, period string, allocs, prevAllocs []domain.CostAllocation) ([]domain.ChargebackStatement, error) {
+	nodes := make(map[int64]Node)
+	parents := make(map[int64]int64)
+	if s.nodes != nil {
+		list, err := s.nodes.ListNodes(ctx, tenantID)
+		if err != nil {
+			return nil, fmt.Errorf("list service tree nodes: %w", err)
+		}
+		for _, n := range list {
+			nodes[n.ID] = n
+			parents[n.ID] = n.ParentID
+		}
+	}
+
+	cur := aggregate(allocs, parents)
+	prev := aggregate(prevAllocs, parents)
+	currency := exchange.CurrencyCNY
+	for _, a := range allocs {
+		if a.Currency != "" {
+			currency = a.Currency
+			break
+		}
+	}
+
+	// 本期已无成本但存在旧分账单的对象，生成零金额新版本，使重新分摊的变化可追溯
+	existing, err := s.chargebackDAO.List(ctx, repository.ChargebackFilter{TenantID: tenantID, Period: period})
+	if err != nil {
+		return nil, fmt.Errorf("list existing statements: %w", err)
+	}
+	for _, e := range existing {
+		key := e.TargetType + ":" + e.TargetID
+		if _, ok := cur.targets[key]; !ok {
+			acc := newAccumulator(e.TargetType, e.TargetID)
+			acc.name = e.TargetName
+			cur.targets[key] = acc
+		}
+	}
+
+	keys := make([]string, 0, len(cur.targets))
+	for k := range cur.targets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	stmts := make([]domain.ChargebackStatement, 0, len(keys))
+	for _, key := range keys {
+		acc := cur.targets[key]
+		stmt := domain.ChargebackStatement{
+			Period:            period,
+			TargetType:        acc.targetType,
+			TargetID:          acc.targetID,
+			TargetName:        acc.name,
+			Currency:          currency,
+			TotalAmount:       round2(acc.total),
+			DirectAmount:      round2(acc.direct),
+			SharedAmount:      round2(acc.shared),
+			RatioAmount:       round2(acc.ratio),
+			DefaultAmount:     round2(acc.deflt),
+			PeriodTotalAmount: round2(cur.total),
+			UnallocatedAmount: round2(cur.unallocated),
+			UnallocatedPct:    pct(cur.unallocated, cur.total),
+			TenantID:          tenantID,
+		}
+		if acc.targetType == domain.ChargebackTargetNode {
+			id, _ := strconv.ParseInt(acc.targetID, 10, 64)
+			if n, ok := nodes[id]; ok {
+				stmt.TargetName = n.Name
+				stmt.Owner = n.Owner
+			}
+			stmt.Children = childLines(acc.children, nodes)
+		}
+		if stmt.TargetName == "" {
+			stmt.TargetName = acc.targetID
+		}
+
+		var prevAcc *accumulator
+		if p, ok := prev.targets[key]; ok {
+			prevAcc = p
+			stmt.PrevTotalAmount = round2(p.total)
+		}
+		stmt.MoMDelta = round2(stmt.TotalAmount - stmt.PrevTotalAmount)
+		if stmt.PrevTotalAmount != 0 {
+			stmt.MoMPct = round2(stmt.MoMDelta / math.Abs(stmt.PrevTotalAmount) * 100)
+		}
+		stmt.TopResources = topResources(acc, prevAcc)
+		stmt.Checksum = checksum(stmt)
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
+
+// saveVersion 内容变化时写入新版本并附带与上一版本的差异，返回分账单及是否新建
+func (s *ChargebackService) saveVersion(ctx context.Context, stmt domain.ChargebackStatement) (domain.ChargebackStatement, bool, error) {
+	latest, err := s.chargebackDAO.GetLatest(ctx, stmt.TenantID, stmt.Period, stmt.TargetType, stmt.TargetID)
+	switch {
+	case err == nil:
+		if latest.Checksum == stmt.Checksum {
+			return latest, false, nil
+		}
+		stmt.Version = latest.Version + 1
+		stmt.Diff = diffStatements(latest, stmt)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		stmt.Version = 1
+	default:
+		return stmt, false, err
+	}
+
+	stmt.CreateTime = time.Now().UnixMilli()
+	id, err := s.chargebackDAO.Insert(ctx, stmt)
+	if err != nil {
+		return stmt, false, err
+	}
+	stmt.ID = id
+	stmt.Latest = true
+	return stmt, true, nil
+}
+
+// buildEvent 构建分账单通知事件
+func (s *ChargebackService) buildEvent(stmt domain.ChargebackStatement) alertdomain.AlertEvent {
+	return alertdomain.AlertEvent{
+		Type:     alertdomain.AlertTypeChargeback,
+		Severity: alertdomain.SeverityInfo,
+		Title:    statementTitle(stmt),
+		Content: map[string]any{
+			"statement_id": stmt.ID,
+			"period":       stmt.Period,
+			"target_type":  stmt.TargetType,
+			"target_id":    stmt.TargetID,
+			"total_amount": stmt.TotalAmount,
+			"currency":     stmt.Currency,
+			"summary":      s.summary(stmt),
+		},
+		Source:     fmt.Sprintf("chargeback:%s:%s:%s", stmt.TargetType, stmt.TargetID, stmt.Period),
+		TenantID:   stmt.TenantID,
+		Status:     alertdomain.EventStatusPending,
+		CreateTime: time.Now(),
+	}
+}
+
+// summary 分账单 Markdown 摘要（用于通知渠道）
+func (s *ChargebackService) summary(stmt domain.ChargebackStatement) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("**%s**: %s\n", targetTypeLabel(stmt.TargetType), stmt.TargetName))
+	if stmt.Owner != "" {
+		b.WriteString(fmt.Sprintf("**负责人**: %s\n", stmt.Owner))
+	}
+	b.WriteString(fmt.Sprintf("**账期**: %s（v%d）\n", stmt.Period, stmt.Version))
+	b.WriteString(fmt.Sprintf("**总成本**: %.2f %s，环比 %+.2f（%+.2f%%）\n", stmt.TotalAmount, stmt.Currency, stmt.MoMDelta, stmt.MoMPct))
+	b.WriteString(fmt.Sprintf("**构成**: 直接 %.2f / 共享 %.2f / 比例分摊 %.2f / 默认归属 %.2f\n",
+		stmt.DirectAmount, stmt.SharedAmount, stmt.RatioAmount, stmt.DefaultAmount))
+	b.WriteString(fmt.Sprintf("**租户未分摊占比**: %.2f%%\n", stmt.UnallocatedPct))
+	if len(stmt.TopResources) > 0 {
+		b.WriteString("**Top 资源**:\n")
+		for i, r := range stmt.TopResources {
+			if i == 3 {
+				break
+			}
+			b.WriteString(fmt.Sprintf("- %s: %.2f\n", resourceLabel(r), r.Amount))
+		}
+	}
+	if stmt.Diff != nil {
+		b.WriteString(fmt.Sprintf("**较 v%d 变化**: %+.2f %s\n", stmt.Diff.BaseVersion, stmt.Diff.TotalDelta, stmt.Currency))
+	}
+	if s.linkBase != "" && stmt.ID != 0 {
+		link := fmt.Sprintf("%s/api/v1/cam/cost/chargeback/statements/%d/download", s.linkBase, stmt.ID)
+		b.WriteString(fmt.Sprintf("[下载 PDF](%s?format=pdf) | [下载 CSV](%s?format=csv)\n", link, link))
+	}
+	return b.String()
+}
+
+// groupByTenant 按租户分组分摊结果
+func groupByTenant(allocs []domain.CostAllocation) map[string][]domain.CostAllocation {
+	grouped := make(map[string][]domain.CostAllocation)
+	for _, a := range allocs {
+		grouped[a.TenantID] = append(grouped[a.TenantID], a)
+	}
+	return grouped
+}
+
+// topResources 金额最高的资源，附上月同一分账对象下的金额
+func topResources(acc, prevAcc *accumulator) []domain.StatementResource {
+	resources := make([]domain.StatementResource, 0, len(acc.resources))
+	for id, r := range acc.resources {
+		item := *r
+		item.Amount = round2(item.Amount)
+		if prevAcc != nil {
+			if p, ok := prevAcc.resources[id]; ok {
+				item.PrevAmount = round2(p.Amount)
+			}
+		}
+		resources = append(resources, item)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Amount != resources[j].Amount {
+			return resources[i].Amount > resources[j].Amount
+		}
+		return resources[i].ResourceID < resources[j].ResourceID
+	})
+	if len(resources) > topResourceLimit {
+		resources = resources[:topResourceLimit]
+	}
+	return resources
+}
+
+// childLines 直接子节点小计，按金额倒序
+func childLines(children map[int64]float64, nodes map[int64]Node) []domain.StatementLine {
+	lines := make([]domain.StatementLine, 0, len(children))
+	for id, amount := range children {
+		name := nodes[id].Name
+		if name == "" {
+			name = strconv.FormatInt(id, 10)
+		}
+		lines = append(lines, domain.StatementLine{Key: strconv.FormatInt(id, 10), Name: name, Amount: round2(amount)})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Amount != lines[j].Amount {
+			return lines[i].Amount > lines[j].Amount
+		}
+		return lines[i].Key < lines[j].Key
+	})
+	return lines
+}
+
+// diffStatements 计算本版本相对基准版本的差异
+func diffStatements(base, cur domain.ChargebackStatement) *domain.StatementDiff {
+	diff := &domain.StatementDiff{
+		BaseVersion:  base.Version,
+		TotalDelta:   round2(cur.TotalAmount - base.TotalAmount),
+		DirectDelta:  round2(cur.DirectAmount - base.DirectAmount),
+		SharedDelta:  round2(cur.SharedAmount - base.SharedAmount),
+		RatioDelta:   round2(cur.RatioAmount - base.RatioAmount),
+		DefaultDelta: round2(cur.DefaultAmount - base.DefaultAmount),
+	}
+
+	before := make(map[string]domain.StatementResource, len(base.TopResources))
+	for _, r := range base.TopResources {
+		before[r.ResourceID] = r
+	}
+	seen := make(map[string]bool)
+	for _, r := range cur.TopResources {
+		seen[r.ResourceID] = true
+		old := before[r.ResourceID]
+		if r.Amount != old.Amount {
+			diff.ResourceDelta = append(diff.ResourceDelta, domain.StatementResource{
+				ResourceID: r.ResourceID, ResourceName: r.ResourceName, ServiceType: r.ServiceType,
+				Amount: r.Amount, PrevAmount: old.Amount,
+			})
+		}
+	}
+	for _, r := range base.TopResources {
+		if !seen[r.ResourceID] {
+			diff.ResourceDelta = append(diff.ResourceDelta, domain.StatementResource{
+				ResourceID: r.ResourceID, ResourceName: r.ResourceName, ServiceType: r.ServiceType,
+				PrevAmount: r.Amount,
+			})
+		}
+	}
+	sort.SliceStable(diff.ResourceDelta, func(i, j int) bool {
+		di := math.Abs(diff.ResourceDelta[i].Amount - diff.ResourceDelta[i].PrevAmount)
+		dj := math.Abs(diff.ResourceDelta[j].Amount - diff.ResourceDelta[j].PrevAmount)
+		return di > dj
+	})
+	if len(diff.ResourceDelta) > diffResourceLimit {
+		diff.ResourceDelta = diff.ResourceDelta[:diffResourceLimit]
+	}
+	return diff
+}
+
+// checksum 分账单内容摘要（不含版本、投递等元数据）
+func checksum(stmt domain.ChargebackStatement) string {
+	stmt.ID, stmt.Version, stmt.Latest, stmt.Diff = 0, 0, false, nil
+	stmt.Checksum, stmt.DeliveredAt, stmt.DeliveredChannels, stmt.CreateTime = "", 0, nil, 0
+	data, _ := json.Marshal(stmt)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// statementTitle 分账单标题
+func statementTitle(stmt domain.ChargebackStatement) string {
+	return fmt.Sprintf("成本分账单: %s %s", stmt.TargetName, stmt.Period)
+}
+
+// targetTypeLabel 分账对象类型名称
+func targetTypeLabel(targetType string) string {
+	if targetType == domain.ChargebackTargetNode {
+		return "服务树节点"
+	}
+	return "分摊目标"
+}
+
+// resourceLabel 资源展示名称
+func resourceLabel(r domain.StatementResource) string {
+	switch {
+	case r.ResourceID == "":
+		return "（无资源 ID）"
+	case r.ResourceName != "" && r.ResourceName != r.ResourceID:
+		return fmt.Sprintf("%s (%s)", r.ResourceName, r.ResourceID)
+	default:
+		return r.ResourceID
+	}
+}
+
+// pct 占比（%），保留两位小数
+func pct(part, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round2(part / total * 100)
+}
+
+// round2 金额保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
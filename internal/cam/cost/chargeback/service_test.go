@@ -0,0 +1,444 @@
+package chargeback
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ========== Mock AllocationDAO ==========
+
+type mockAllocationDAO struct {
+	repository.AllocationDAO
+	byPeriod map[string][]domain.CostAllocation
+}
+
+func (m *mockAllocationDAO) ListAllocations(_ context.Context, filter repository.AllocationFilter) ([]domain.CostAllocation, error) {
+	var result []domain.CostAllocation
+	for _, a := range m.byPeriod[filter.Period] {
+		if filter.TenantID == "" || a.TenantID == filter.TenantID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// ========== Mock ChargebackDAO ==========
+
+type mockChargebackDAO struct {
+	stmts []domain.ChargebackStatement
+}
+
+func (m *mockChargebackDAO) Insert(_ context.Context, stmt domain.ChargebackStatement) (int64, error) {
+	for i := range m.stmts {
+		s := &m.stmts[i]
+		if s.TenantID == stmt.TenantID && s.Period == stmt.Period && s.TargetType == stmt.TargetType && s.TargetID == stmt.TargetID {
+			s.Latest = false
+		}
+	}
+	stmt.ID = int64(len(m.stmts) + 1)
+	stmt.Latest = true
+	m.stmts = append(m.stmts, stmt)
+	return stmt.ID, nil
+}
+func (m *mockChargebackDAO) GetByID(_ context.Context, id int64) (domain.ChargebackStatement, error) {
+	for _, s := range m.stmts {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return domain.ChargebackStatement{}, mongo.ErrNoDocuments
+}
+func (m *mockChargebackDAO) GetLatest(_ context.Context, tenantID, period, targetType, targetID string) (domain.ChargebackStatement, error) {
+	for _, s := range m.stmts {
+		if s.Latest && s.TenantID == tenantID && s.Period == period && s.TargetType == targetType && s.TargetID == targetID {
+			return s, nil
+		}
+	}
+	return domain.ChargebackStatement{}, mongo.ErrNoDocuments
+}
+func (m *mockChargebackDAO) List(_ context.Context, filter repository.ChargebackFilter) ([]domain.ChargebackStatement, error) {
+	var result []domain.ChargebackStatement
+	for _, s := range m.stmts {
+		if (filter.TenantID == "" || s.TenantID == filter.TenantID) && (filter.Period == "" || s.Period == filter.Period) &&
+			(filter.AllVersions || s.Latest) {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+func (m *mockChargebackDAO) Count(ctx context.Context, filter repository.ChargebackFilter) (int64, error) {
+	items, _ := m.List(ctx, filter)
+	return int64(len(items)), nil
+}
+func (m *mockChargebackDAO) MarkDelivered(_ context.Context, id int64, channelIDs []int64, at int64) error {
+	for i := range m.stmts {
+		if m.stmts[i].ID == id {
+			m.stmts[i].DeliveredAt = at
+			m.stmts[i].DeliveredChannels = channelIDs
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+// ========== Mock NodeResolver ==========
+
+type staticNodes []Node
+
+func (n staticNodes) ListNodes(_ context.Context, _ string) ([]Node, error) {
+	return n, nil
+}
+
+// ========== Mock AlertDAO ==========
+
+type mockAlertDAO struct {
+	emittedEvents []alertdomain.AlertEvent
+	channels      []alertdomain.NotificationChannel
+	listRulesFn   func(ctx context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error)
+}
+
+func (m *mockAlertDAO) CreateRule(_ context.Context, _ alertdomain.AlertRule) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateRule(_ context.Context, _ alertdomain.AlertRule) error { return nil }
+func (m *mockAlertDAO) GetRuleByID(_ context.Context, _ int64) (alertdomain.AlertRule, error) {
+	return alertdomain.AlertRule{}, nil
+}
+func (m *mockAlertDAO) ListRules(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+	if m.listRulesFn != nil {
+		return m.listRulesFn(nil, filter)
+	}
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteRule(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) CreateEvent(_ context.Context, event alertdomain.AlertEvent) (int64, error) {
+	m.emittedEvents = append(m.emittedEvents, event)
+	return int64(len(m.emittedEvents)), nil
+}
+func (m *mockAlertDAO) UpdateEventStatus(_ context.Context, _ int64, _ alertdomain.EventStatus) error {
+	return nil
+}
+func (m *mockAlertDAO) ListEvents(_ context.Context, _ alertdomain.AlertEventFilter) ([]alertdomain.AlertEvent, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) GetPendingEvents(_ context.Context, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) IncrementRetry(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetEventByID(_ context.Context, _ int64) (alertdomain.AlertEvent, error) {
+	return alertdomain.AlertEvent{}, nil
+}
+func (m *mockAlertDAO) ResolveEvents(_ context.Context, _ string, _ alertdomain.EventTransition) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) AckEvent(_ context.Context, _ int64, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AssignEvent(_ context.Context, _ int64, _ string, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AppendEventHistory(_ context.Context, _ int64, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) SetAckDeadline(_ context.Context, _ int64, _ *time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) RenotifyEvent(_ context.Context, _ int64, _ time.Time, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) GetRenotifyEvents(_ context.Context, _ time.Time, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) GetState(_ context.Context, _ string) (alertdomain.AlertState, error) {
+	return alertdomain.AlertState{}, nil
+}
+func (m *mockAlertDAO) RecordOccurrence(_ context.Context, state alertdomain.AlertState, _ time.Time) (alertdomain.AlertState, error) {
+	state.Count = 1
+	return state, nil
+}
+func (m *mockAlertDAO) MarkStateEscalated(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) MarkStateNotified(_ context.Context, _ string, _ time.Time, _ time.Duration) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) SetStateLastEvent(_ context.Context, _ string, _ int64) error { return nil }
+func (m *mockAlertDAO) ResolveState(_ context.Context, _ alertdomain.AlertState, _ time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) ListActiveStates(_ context.Context) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) ListActiveStatesByType(_ context.Context, _ string, _ alertdomain.AlertType) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) CreateChannel(_ context.Context, _ alertdomain.NotificationChannel) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateChannel(_ context.Context, _ alertdomain.NotificationChannel) error {
+	return nil
+}
+func (m *mockAlertDAO) GetChannelByID(_ context.Context, _ int64) (alertdomain.NotificationChannel, error) {
+	return alertdomain.NotificationChannel{}, nil
+}
+func (m *mockAlertDAO) ListChannels(_ context.Context, _ alertdomain.ChannelFilter) ([]alertdomain.NotificationChannel, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteChannel(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetChannelsByIDs(_ context.Context, _ []int64) ([]alertdomain.NotificationChannel, error) {
+	return m.channels, nil
+}
+func (m *mockAlertDAO) CreateTemplate(_ context.Context, _ alertdomain.MessageTemplate) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateTemplate(_ context.Context, _ alertdomain.MessageTemplate) error {
+	return nil
+}
+func (m *mockAlertDAO) GetTemplateByID(_ context.Context, _ int64) (alertdomain.MessageTemplate, error) {
+	return alertdomain.MessageTemplate{}, nil
+}
+func (m *mockAlertDAO) ListTemplates(_ context.Context, _ alertdomain.MessageTemplateFilter) ([]alertdomain.MessageTemplate, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteTemplate(_ context.Context, _ int64) error {
+	return nil
+}
+func (m *mockAlertDAO) InitIndexes(_ context.Context) error { return nil }
+
+// ========== Test Setup ==========
+
+// 服务树: 1 电商(张三) -> 2 订单、3 支付
+var testNodes = staticNodes{
+	{ID: 1, Name: "电商", Owner: "张三"},
+	{ID: 2, ParentID: 1, Name: "订单"},
+	{ID: 3, ParentID: 1, Name: "支付"},
+}
+
+func testAllocations() map[string][]domain.CostAllocation {
+	return map[string][]domain.CostAllocation{
+		"2026-09": {
+			{NodeID: 2, DimType: domain.DimTag, TotalAmount: 600, DirectAmount: 600, ResourceID: "i-order", ResourceName: "order-api", ServiceType: "ecs", Currency: "CNY", TenantID: "t1"},
+			{NodeID: 2, DimType: "shared", TotalAmount: 100, SharedAmount: 100, ResourceID: "rds-shared", ServiceType: "rds", Currency: "CNY", TenantID: "t1"},
+			{NodeID: 3, DimType: "shared", TotalAmount: 100, SharedAmount: 100, ResourceID: "rds-shared", ServiceType: "rds", Currency: "CNY", TenantID: "t1"},
+			{DimType: domain.DimRegion, DimValue: "bigdata", TargetName: "大数据", TotalAmount: 150, RatioAmount: 150, ResourceID: "oss-1", ServiceType: "oss", Currency: "CNY", TenantID: "t1"},
+			{DimValue: "platform", TargetName: "平台", DefaultFlag: true, TotalAmount: 30, ResourceID: "cdn-1", Currency: "CNY", TenantID: "t1"},
+			{DimValue: "unallocated", UnallocatedFlag: true, TotalAmount: 20, ResourceID: "misc", Currency: "CNY", TenantID: "t1"},
+		},
+		"2026-08": {
+			{NodeID: 2, DimType: domain.DimTag, TotalAmount: 400, DirectAmount: 400, ResourceID: "i-order", Currency: "CNY", TenantID: "t1"},
+		},
+	}
+}
+
+type testEnv struct {
+	svc      *ChargebackService
+	allocDAO *mockAllocationDAO
+	dao      *mockChargebackDAO
+	alertDAO *mockAlertDAO
+}
+
+func newTestEnv() *testEnv {
+	env := &testEnv{
+		allocDAO: &mockAllocationDAO{byPeriod: testAllocations()},
+		dao:      &mockChargebackDAO{},
+		alertDAO: &mockAlertDAO{
+			listRulesFn: func(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+				return []alertdomain.AlertRule{{ID: 1, Type: filter.Type, Enabled: true}}, 1, nil
+			},
+		},
+	}
+	logger := elog.DefaultLogger
+	env.svc = NewChargebackService(env.allocDAO, env.dao, alertservice.NewAlertService(env.alertDAO, logger), logger)
+	env.svc.SetNodeResolver(testNodes)
+	return env
+}
+
+func statementsByKey(stmts []domain.ChargebackStatement) map[string]domain.ChargebackStatement {
+	m := make(map[string]domain.ChargebackStatement, len(stmts))
+	for _, s := range stmts {
+		m[s.TargetType+":"+s.TargetID] = s
+	}
+	return m
+}
+
+// ========== Tests ==========
+
+func TestGenerateStatements_Breakdown(t *testing.T) {
+	env := newTestEnv()
+
+	stmts, err := env.svc.GenerateStatements(context.Background(), "t1", "2026-09")
+	require.NoError(t, err)
+	byKey := statementsByKey(stmts)
+	require.Len(t, byKey, 5)
+
+	order := byKey["node:2"]
+	assert.Equal(t, "订单", order.TargetName)
+	assert.Equal(t, 700.0, order.TotalAmount)
+	assert.Equal(t, 600.0, order.DirectAmount)
+	assert.Equal(t, 100.0, order.SharedAmount)
+	assert.Equal(t, 400.0, order.PrevTotalAmount)
+	assert.Equal(t, 300.0, order.MoMDelta)
+	assert.Equal(t, 75.0, order.MoMPct)
+	require.Len(t, order.TopResources, 2)
+	assert.Equal(t, "i-order", order.TopResources[0].ResourceID)
+	assert.Equal(t, 400.0, order.TopResources[0].PrevAmount)
+	assert.Equal(t, 1, order.Version)
+
+	// 父节点汇总子节点成本并列出子节点小计
+	root := byKey["node:1"]
+	assert.Equal(t, "电商", root.TargetName)
+	assert.Equal(t, "张三", root.Owner)
+	assert.Equal(t, 800.0, root.TotalAmount)
+	assert.Equal(t, []domain.StatementLine{
+		{Key: "2", Name: "订单", Amount: 700},
+		{Key: "3", Name: "支付", Amount: 100},
+	}, root.Children)
+
+	bigdata := byKey["target:bigdata"]
+	assert.Equal(t, "大数据", bigdata.TargetName)
+	assert.Equal(t, 150.0, bigdata.RatioAmount)
+	assert.Equal(t, 30.0, byKey["target:platform"].DefaultAmount)
+
+	assert.Equal(t, 1000.0, order.PeriodTotalAmount)
+	assert.Equal(t, 20.0, order.UnallocatedAmount)
+	assert.Equal(t, 2.0, order.UnallocatedPct)
+	assert.NotContains(t, byKey, "target:unallocated")
+}
+
+func TestAggregate_AncestorNameNotTakenFromChild(t *testing.T) {
+	allocs := []domain.CostAllocation{
+		{NodeID: 2, TargetName: "订单", TotalAmount: 10},
+		{NodeID: 3, TargetName: "支付", TotalAmount: 5},
+	}
+	agg := aggregate(allocs, map[int64]int64{2: 1, 3: 1})
+
+	assert.Equal(t, "订单", agg.targets["node:2"].name)
+	assert.Equal(t, "支付", agg.targets["node:3"].name)
+	// 祖先节点名称由服务树补全，不能沿用第一个子节点的名称
+	assert.Empty(t, agg.targets["node:1"].name)
+	assert.Equal(t, 15.0, agg.targets["node:1"].total)
+}
+
+func TestGenerateStatements_VersionsOnChange(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+
+	_, err := env.svc.GenerateStatements(ctx, "t1", "2026-09")
+	require.NoError(t, err)
+
+	// 分摊结果未变化时不产生新版本
+	again, err := env.svc.GenerateStatements(ctx, "t1", "2026-09")
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	// 重新分摊：支付节点的共享数据库成本改为全部归属订单节点
+	allocs := env.allocDAO.byPeriod["2026-09"]
+	allocs[1].TotalAmount, allocs[1].SharedAmount = 200, 200
+	env.allocDAO.byPeriod["2026-09"] = append(allocs[:2], allocs[3:]...)
+
+	changed, err := env.svc.GenerateStatements(ctx, "t1", "2026-09")
+	require.NoError(t, err)
+	byKey := statementsByKey(changed)
+	assert.NotContains(t, byKey, "target:bigdata")
+	assert.NotContains(t, byKey, "target:platform")
+
+	order := byKey["node:2"]
+	assert.Equal(t, 2, order.Version)
+	require.NotNil(t, order.Diff)
+	assert.Equal(t, 1, order.Diff.BaseVersion)
+	assert.Equal(t, 100.0, order.Diff.TotalDelta)
+	assert.Equal(t, 100.0, order.Diff.SharedDelta)
+	require.Len(t, order.Diff.ResourceDelta, 1)
+	assert.Equal(t, "rds-shared", order.Diff.ResourceDelta[0].ResourceID)
+	assert.Equal(t, 200.0, order.Diff.ResourceDelta[0].Amount)
+	assert.Equal(t, 100.0, order.Diff.ResourceDelta[0].PrevAmount)
+
+	// 父节点总额不变，但子节点构成变化
+	root := byKey["node:1"]
+	assert.Equal(t, 2, root.Version)
+	assert.Equal(t, 0.0, root.Diff.TotalDelta)
+	assert.Equal(t, []domain.StatementLine{{Key: "2", Name: "订单", Amount: 800}}, root.Children)
+
+	// 本期已无成本的对象生成零金额版本
+	pay := byKey["node:3"]
+	assert.Equal(t, 0.0, pay.TotalAmount)
+	assert.Equal(t, -100.0, pay.Diff.TotalDelta)
+
+	latest, total, err := env.svc.ListStatements(ctx, "t1", repository.ChargebackFilter{Period: "2026-09"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	versions := make([]int, 0, len(latest))
+	for _, s := range latest {
+		versions = append(versions, s.Version)
+	}
+	sort.Ints(versions)
+	assert.Equal(t, []int{1, 1, 2, 2, 2}, versions)
+}
+
+func TestGenerateStatements_InvalidPeriod(t *testing.T) {
+	env := newTestEnv()
+	_, err := env.svc.GenerateStatements(context.Background(), "t1", "2026-9")
+	assert.ErrorIs(t, err, domain.ErrChargebackInvalid)
+}
+
+func TestStartScheduledGeneration_EmitsEvents(t *testing.T) {
+	env := newTestEnv()
+	env.svc.SetLinkBase("https://finops.example.com/")
+
+	require.NoError(t, env.svc.StartScheduledGeneration(context.Background(), time.Date(2026, 10, 3, 6, 0, 0, 0, time.UTC)))
+	require.Len(t, env.alertDAO.emittedEvents, 5)
+	evt := env.alertDAO.emittedEvents[0]
+	assert.Equal(t, alertdomain.AlertTypeChargeback, evt.Type)
+	assert.Equal(t, "t1", evt.TenantID)
+	summary, _ := evt.Content["summary"].(string)
+	assert.Contains(t, summary, "2026-09")
+	assert.Contains(t, summary, "https://finops.example.com/api/v1/cam/cost/chargeback/statements/")
+}
+
+func TestDeliverStatement(t *testing.T) {
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		_, _ = w.Write([]byte(`{"errcode":0}`))
+	}))
+	defer server.Close()
+
+	env := newTestEnv()
+	ctx := context.Background()
+	env.alertDAO.channels = []alertdomain.NotificationChannel{
+		{ID: 9, Type: alertdomain.ChannelWeCom, Config: map[string]any{"webhook": server.URL}, TenantID: "t1", Enabled: true},
+	}
+	_, err := env.svc.GenerateStatements(ctx, "t1", "2026-09")
+	require.NoError(t, err)
+	stmts, _, err := env.svc.ListStatements(ctx, "t1", repository.ChargebackFilter{TargetType: domain.ChargebackTargetNode, Period: "2026-09"})
+	require.NoError(t, err)
+	id := stmts[0].ID
+
+	require.NoError(t, env.svc.DeliverStatement(ctx, "t1", id, []int64{9}))
+	require.NotNil(t, body)
+	assert.Equal(t, "markdown", body["msgtype"])
+	stmt, err := env.svc.GetStatement(ctx, "t1", id)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{9}, stmt.DeliveredChannels)
+	assert.NotZero(t, stmt.DeliveredAt)
+
+	// 其他租户的分账单与渠道不可用
+	assert.ErrorIs(t, env.svc.DeliverStatement(ctx, "t2", id, []int64{9}), domain.ErrChargebackInvalid)
+	assert.ErrorIs(t, env.svc.DeliverStatement(ctx, "t1", id, nil), domain.ErrChargebackInvalid)
+}
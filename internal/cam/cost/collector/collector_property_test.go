@@ -63,6 +63,10 @@ func (m *mockCollectLogDAO) Count(_ context.Context, _ repository.CollectLogFilt
 	return 0, nil
 }
 
+func (m *mockCollectLogDAO) GetSuccessByChecksum(_ context.Context, _ int64, _ string) (domain.CollectLog, error) {
+	return domain.CollectLog{}, errors.New("no success log")
+}
+
 // newTestCollectorService creates a CollectorService with the given mock DAO for testing.
 func newTestCollectorService(dao repository.CollectLogDAO) *CollectorService {
 	return &CollectorService{
@@ -0,0 +1,423 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// maxImportErrors 导入结果中保留的错误行示例数
+	maxImportErrors = 10
+	// importProcessedDir / importFailedDir 目录投递时已处理文件的归档子目录
+	importProcessedDir = "processed"
+	importFailedDir    = "failed"
+)
+
+// ImportRequest 账单文件导入请求
+type ImportRequest struct {
+	SourceID int64
+	TenantID string // 非空时校验来源归属
+	FileName string
+	File     ImportFile
+}
+
+// ImportResult 账单文件导入结果
+type ImportResult struct {
+	LogID     int64    `json:"log_id"`
+	Duplicate bool     `json:"duplicate"` // 同一文件已导入成功，本次跳过
+	RowCount  int64    `json:"row_count"` // 读取的账单行数
+	Skipped   int64    `json:"skipped"`   // 无法解析而跳过的行数
+	Imported  int64    `json:"imported"`  // 写入的统一账单数（含摊销派生行）
+	Months    []string `json:"months"`
+	Errors    []string `json:"errors,omitempty"` // 跳过行的错误示例
+}
+
+// SetImportSourceDAO 设置账单导入来源 DAO，未设置时不支持文件导入
+func (s *CollectorService) SetImportSourceDAO(dao repository.ImportSourceDAO) {
+	s.importSourceDAO = dao
+}
+
+// SetImportDirs 设置目录投递根目录与文件最小静置时长
+// 来源的投递目录为 <root>/<租户 ID>/<dir>，root 为空时不扫描任何目录；
+// 最后修改时间距今不足 minAge 的文件视为仍在写入，留待下次扫描
+func (s *CollectorService) SetImportDirs(root string, minAge time.Duration) {
+	s.importRoot = root
+	s.importMinAge = minAge
+}
+
+// SaveImportSource 创建或更新账单导入来源，Provider 为空时取云账号的厂商
+func (s *CollectorService) SaveImportSource(ctx context.Context, source domain.BillImportSource) (domain.BillImportSource, error) {
+	if s.importSourceDAO == nil {
+		return source, fmt.Errorf("bill import is not configured")
+	}
+	if err := validateImportSource(source); err != nil {
+		return source, err
+	}
+	account, err := s.accountSvc.GetAccount(ctx, source.AccountID)
+	if err != nil {
+		return source, fmt.Errorf("get account %d: %w", source.AccountID, err)
+	}
+	if source.TenantID != "" && account.TenantID != source.TenantID {
+		return source, fmt.Errorf("%w: account %d does not belong to tenant", domain.ErrImportInvalid, source.AccountID)
+	}
+	source.TenantID = account.TenantID
+	if source.Provider == "" {
+		source.Provider = string(account.Provider)
+	}
+
+	if source.ID == 0 {
+		source.ID, err = s.importSourceDAO.Create(ctx, source)
+		return source, err
+	}
+	if _, err = s.getImportSource(ctx, source.TenantID, source.ID); err != nil {
+		return source, err
+	}
+	return source, s.importSourceDAO.Update(ctx, source)
+}
+
+// ListImportSources 查询租户的账单导入来源
+func (s *CollectorService) ListImportSources(ctx context.Context, tenantID string) ([]domain.BillImportSource, error) {
+	if s.importSourceDAO == nil {
+		return nil, nil
+	}
+	return s.importSourceDAO.List(ctx, tenantID)
+}
+
+// DeleteImportSource 删除账单导入来源（已导入的账单保留）
+func (s *CollectorService) DeleteImportSource(ctx context.Context, tenantID string, id int64) error {
+	if s.importSourceDAO == nil {
+		return domain.ErrImportSourceNotFound
+	}
+	if _, err := s.getImportSource(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.importSourceDAO.Delete(ctx, id)
+}
+
+func (s *CollectorService) getImportSource(ctx context.Context, tenantID string, id int64) (domain.BillImportSource, error) {
+	source, err := s.importSourceDAO.GetByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && tenantID != "" && source.TenantID != tenantID) {
+		return source, domain.ErrImportSourceNotFound
+	}
+	return source, err
+}
+
+func validateImportSource(source domain.BillImportSource) error {
+	if strings.TrimSpace(source.Name) == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrImportInvalid)
+	}
+	if source.AccountID <= 0 {
+		return fmt.Errorf("%w: account_id is required", domain.ErrImportInvalid)
+	}
+	// 投递目录只能是租户目录下的相对路径，不允许绝对路径与 ..
+	if source.Dir != "" && !filepath.IsLocal(source.Dir) {
+		return fmt.Errorf("%w: dir must be a relative path without ..", domain.ErrImportInvalid)
+	}
+	switch source.Format {
+	case domain.ImportFormatCUR:
+		return nil
+	case domain.ImportFormatCSV, domain.ImportFormatXLSX:
+	default:
+		return fmt.Errorf("%w: unsupported format %q", domain.ErrImportInvalid, source.Format)
+	}
+	if source.Mapping.Amount == "" || source.Mapping.BillingDate == "" {
+		return fmt.Errorf("%w: amount and billing_date columns are required", domain.ErrImportInvalid)
+	}
+	return nil
+}
+
+// ImportBills 导入账单文件：按来源列映射解析为原始账单，经标准化写入统一账单并记录采集日志
+// 同一文件（SHA-256 相同）已导入成功时直接返回；内容更正后重新上传会按月份覆盖该账号的旧账单
+func (s *CollectorService) ImportBills(ctx context.Context, req ImportRequest) (ImportResult, error) {
+	if s.importSourceDAO == nil {
+		return ImportResult{}, fmt.Errorf("bill import is not configured")
+	}
+	source, err := s.getImportSource(ctx, req.TenantID, req.SourceID)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	checksum, err := fileChecksum(req.File)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("read import file: %w", err)
+	}
+	if prev, err := s.collectLogDAO.GetSuccessByChecksum(ctx, source.AccountID, checksum); err == nil {
+		s.logger.Info("bill file already imported, skipping",
+			elog.Int64("source_id", source.ID),
+			elog.String("file_name", req.FileName),
+			elog.Int64("log_id", prev.ID),
+		)
+		return ImportResult{LogID: prev.ID, Duplicate: true, Imported: prev.RecordCount}, nil
+	}
+
+	acquired, err := s.acquireLock(ctx, source.AccountID)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("%w: %v", domain.ErrCollectLockFailed, err)
+	}
+	if !acquired {
+		return ImportResult{}, domain.ErrCollectAlreadyRunning
+	}
+	defer s.releaseLock(ctx, source.AccountID)
+
+	collectStart := time.Now()
+	account, err := s.accountSvc.GetAccount(ctx, source.AccountID)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("get account %d: %w", source.AccountID, err)
+	}
+	provider := shareddomain.CloudProvider(source.Provider)
+	if provider == "" {
+		provider = account.Provider
+	}
+
+	collectLog := domain.CollectLog{
+		AccountID:  account.ID,
+		Provider:   string(provider),
+		Status:     "running",
+		StartTime:  collectStart,
+		TenantID:   account.TenantID,
+		CreateTime: time.Now().Unix(),
+		Source:     domain.CollectSourceImport,
+		SourceID:   source.ID,
+		FileName:   req.FileName,
+		Checksum:   checksum,
+	}
+	logID, err := s.collectLogDAO.Create(ctx, collectLog)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("create collect log: %w", err)
+	}
+	collectLog.ID = logID
+	result := ImportResult{LogID: logID}
+
+	items, err := s.parseImportFile(source, provider, req.File, &result)
+	if err == nil && len(items) == 0 {
+		err = fmt.Errorf("%w: no bill rows found", domain.ErrImportInvalid)
+	}
+	if err != nil {
+		s.finishCollectLog(ctx, &collectLog, collectStart, 0, "failed", err.Error())
+		return result, err
+	}
+
+	result.Months = importMonths(items)
+	collectLog.BillStart, _ = time.Parse("2006-01", result.Months[0])
+	lastMonth, _ := time.Parse("2006-01", result.Months[len(result.Months)-1])
+	collectLog.BillEnd = lastMonth.AddDate(0, 1, 0).Add(-time.Second)
+
+	unifiedBills, err := s.normalizer.Normalize(ctx, items)
+	if err != nil {
+		s.finishCollectLog(ctx, &collectLog, collectStart, 0, "failed", fmt.Sprintf("normalize: %v", err))
+		return result, fmt.Errorf("normalize bills: %w", err)
+	}
+	for i := range unifiedBills {
+		unifiedBills[i].AccountID = account.ID
+		unifiedBills[i].AccountName = account.Name
+		unifiedBills[i].TenantID = account.TenantID
+	}
+
+	inserted, err := s.replaceBills(ctx, account.ID, result.Months, items, unifiedBills, strconv.FormatInt(logID, 10))
+	if err != nil {
+		s.finishCollectLog(ctx, &collectLog, collectStart, 0, "failed", err.Error())
+		return result, err
+	}
+	result.Imported = inserted
+
+	s.finishCollectLog(ctx, &collectLog, collectStart, inserted, "success", "")
+	s.invalidateCostCache(ctx)
+
+	s.logger.Info("bill file imported",
+		elog.Int64("source_id", source.ID),
+		elog.Int64("account_id", account.ID),
+		elog.String("file_name", req.FileName),
+		elog.Int64("rows", result.RowCount),
+		elog.Int64("skipped", result.Skipped),
+		elog.Int64("record_count", inserted),
+	)
+	return result, nil
+}
+
+// parseImportFile 读取账单文件并按列映射转换，无法解析的行计入 Skipped
+func (s *CollectorService) parseImportFile(source domain.BillImportSource, provider shareddomain.CloudProvider,
+	file ImportFile, result *ImportResult) ([]billing.RawBillItem, error) {
+	mapping := effectiveMapping(source)
+	table, err := openTable(source, file)
+	if err != nil {
+		return nil, err
+	}
+	header, err := readHeader(table, mapping)
+	if err != nil {
+		return nil, err
+	}
+	mapper := newRowMapper(mapping, provider, header)
+
+	var items []billing.RawBillItem
+	for line := 1; ; line++ {
+		row, err := table.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrImportInvalid, err)
+		}
+		item, skip, err := mapper.mapRow(row)
+		if skip {
+			continue
+		}
+		result.RowCount++
+		if err != nil {
+			result.Skipped++
+			if len(result.Errors) < maxImportErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", line, err))
+			}
+			continue
+		}
+		items = append(items, item)
+	}
+}
+
+// ScanImportDirs 扫描配置了投递目录的导入来源，导入新文件后移入 processed / failed 子目录
+// 只处理普通文件：子目录、符号链接、隐藏文件以及仍在写入（修改时间不足最小静置时长）的文件均跳过
+func (s *CollectorService) ScanImportDirs(ctx context.Context) error {
+	if s.importRoot == "" {
+		return nil
+	}
+	root, err := filepath.EvalSymlinks(s.importRoot)
+	if err != nil {
+		return fmt.Errorf("resolve import root: %w", err)
+	}
+	sources, err := s.ListImportSources(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list import sources: %w", err)
+	}
+	for _, source := range sources {
+		if source.Dir == "" {
+			continue
+		}
+		dir, err := importSourceDir(root, source)
+		if err != nil {
+			s.logger.Warn("invalid import dir",
+				elog.Int64("source_id", source.ID),
+				elog.String("dir", source.Dir),
+				elog.FieldErr(err),
+			)
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			s.logger.Warn("failed to read import dir",
+				elog.Int64("source_id", source.ID),
+				elog.String("dir", dir),
+				elog.FieldErr(err),
+			)
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || time.Since(info.ModTime()) < s.importMinAge {
+				continue
+			}
+			s.importDirFile(ctx, source, dir, entry.Name())
+		}
+	}
+	return nil
+}
+
+// importSourceDir 解析来源的投递目录 <root>/<租户 ID>/<dir>
+// root 须为已解析符号链接的路径；租户目录及其下的任何一级为符号链接时拒绝，避免读取或移动根目录之外的文件
+func importSourceDir(root string, source domain.BillImportSource) (string, error) {
+	if source.TenantID == "" || strings.ContainsAny(source.TenantID, `/\`) || !filepath.IsLocal(source.TenantID) {
+		return "", fmt.Errorf("%w: invalid tenant id %q", domain.ErrImportInvalid, source.TenantID)
+	}
+	if !filepath.IsLocal(source.Dir) {
+		return "", fmt.Errorf("%w: dir must be a relative path without ..", domain.ErrImportInvalid)
+	}
+	dir := filepath.Join(root, source.TenantID, source.Dir)
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	if resolved != dir {
+		return "", fmt.Errorf("%w: import dir must not contain symlinks", domain.ErrImportInvalid)
+	}
+	return dir, nil
+}
+
+// importDirFile 导入投递目录中的单个文件，账号采集中时保留文件等待下次扫描
+func (s *CollectorService) importDirFile(ctx context.Context, source domain.BillImportSource, dir, name string) {
+	path := filepath.Join(dir, name)
+	f, err := os.Open(path)
+	if err != nil {
+		s.logger.Warn("failed to open import file", elog.String("path", path), elog.FieldErr(err))
+		return
+	}
+	_, err = s.ImportBills(ctx, ImportRequest{SourceID: source.ID, FileName: name, File: f})
+	f.Close()
+	if errors.Is(err, domain.ErrCollectAlreadyRunning) {
+		return
+	}
+
+	archive := importProcessedDir
+	if err != nil {
+		archive = importFailedDir
+		s.logger.Error("failed to import bill file",
+			elog.Int64("source_id", source.ID),
+			elog.String("path", path),
+			elog.FieldErr(err),
+		)
+	}
+	archiveDir := filepath.Join(dir, archive)
+	if err := os.MkdirAll(archiveDir, 0o755); err != nil {
+		s.logger.Warn("failed to create archive dir", elog.String("dir", archiveDir), elog.FieldErr(err))
+		return
+	}
+	if err := os.Rename(path, filepath.Join(archiveDir, name)); err != nil {
+		s.logger.Warn("failed to archive import file", elog.String("path", path), elog.FieldErr(err))
+	}
+}
+
+// fileChecksum 计算文件 SHA-256 并将读取位置重置到文件开头
+func fileChecksum(f ImportFile) (string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// importMonths 返回账单覆盖的月份（升序）
+func importMonths(items []billing.RawBillItem) []string {
+	seen := make(map[string]bool)
+	var months []string
+	for _, item := range items {
+		if !seen[item.BillingCycle] {
+			seen[item.BillingCycle] = true
+			months = append(months, item.BillingCycle)
+		}
+	}
+	sort.Strings(months)
+	return months
+}
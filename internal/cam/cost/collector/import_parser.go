@@ -0,0 +1,289 @@
+package collector
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// headerScanRows 表头前允许的标题行数（对账单常在首行写公司名与账期）
+const headerScanRows = 20
+
+// ImportFile 待导入的账单文件（multipart.File、*os.File、*bytes.Reader 均满足）
+type ImportFile interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+// tableReader 逐行读取表格
+type tableReader interface {
+	// Read 返回下一行，读完时返回 io.EOF
+	Read() ([]string, error)
+}
+
+type sliceTable struct {
+	rows [][]string
+}
+
+func (t *sliceTable) Read() ([]string, error) {
+	if len(t.rows) == 0 {
+		return nil, io.EOF
+	}
+	row := t.rows[0]
+	t.rows = t.rows[1:]
+	return row, nil
+}
+
+// openTable 按来源格式打开账单文件：xlsx 读取工作表，cur / csv 按 CSV 读取（自动识别 gzip 压缩）
+func openTable(source domain.BillImportSource, f ImportFile) (tableReader, error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if source.Format == domain.ImportFormatXLSX {
+		rows, err := readXLSXRows(f, size, source.Sheet)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrImportInvalid, err)
+		}
+		return &sliceTable{rows: rows}, nil
+	}
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrImportInvalid, err)
+		}
+		r = gz
+	}
+	if strings.EqualFold(source.Encoding, "gbk") {
+		r = simplifiedchinese.GBK.NewDecoder().Reader(r)
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	return cr, nil
+}
+
+// effectiveMapping 返回来源实际使用的列映射，CUR 未配置的列使用默认映射
+func effectiveMapping(source domain.BillImportSource) domain.ColumnMapping {
+	m := source.Mapping
+	if source.Format != domain.ImportFormatCUR {
+		return m
+	}
+	def := domain.CURColumnMapping
+	fill := func(v *string, d string) {
+		if *v == "" {
+			*v = d
+		}
+	}
+	fill(&m.BillingDate, def.BillingDate)
+	fill(&m.ServiceType, def.ServiceType)
+	fill(&m.ResourceID, def.ResourceID)
+	fill(&m.ResourceName, def.ResourceName)
+	fill(&m.Region, def.Region)
+	fill(&m.Amount, def.Amount)
+	fill(&m.Currency, def.Currency)
+	fill(&m.DefaultCurrency, def.DefaultCurrency)
+	fill(&m.TagPrefix, def.TagPrefix)
+	return m
+}
+
+// rowMapper 按列映射将表格行转换为原始账单
+type rowMapper struct {
+	mapping  domain.ColumnMapping
+	provider shareddomain.CloudProvider
+	header   []string
+	index    map[string]int
+	tagCols  map[int]string
+}
+
+// readHeader 定位表头：跳过标题行，取第一条包含金额列与账期列的行
+func readHeader(table tableReader, mapping domain.ColumnMapping) ([]string, error) {
+	for i := 0; i < headerScanRows; i++ {
+		row, err := table.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrImportInvalid, err)
+		}
+		header := make([]string, len(row))
+		for j, col := range row {
+			header[j] = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+		}
+		if containsColumn(header, mapping.Amount) && containsColumn(header, mapping.BillingDate) {
+			return header, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: header with columns %q and %q not found",
+		domain.ErrImportInvalid, mapping.Amount, mapping.BillingDate)
+}
+
+func containsColumn(header []string, col string) bool {
+	for _, h := range header {
+		if h == col {
+			return true
+		}
+	}
+	return false
+}
+
+func newRowMapper(mapping domain.ColumnMapping, provider shareddomain.CloudProvider, header []string) *rowMapper {
+	m := &rowMapper{
+		mapping:  mapping,
+		provider: provider,
+		header:   header,
+		index:    make(map[string]int, len(header)),
+		tagCols:  make(map[int]string),
+	}
+	for i, col := range header {
+		if _, ok := m.index[col]; !ok {
+			m.index[col] = i
+		}
+		if mapping.TagPrefix != "" && strings.HasPrefix(col, mapping.TagPrefix) {
+			m.tagCols[i] = strings.TrimPrefix(col, mapping.TagPrefix)
+		}
+	}
+	return m
+}
+
+func (m *rowMapper) value(row []string, col string) string {
+	if col == "" {
+		return ""
+	}
+	i, ok := m.index[col]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// mapRow 转换单行，空行与金额为空的行（小计、备注）返回 skip
+func (m *rowMapper) mapRow(row []string) (item billing.RawBillItem, skip bool, err error) {
+	amountText := m.value(row, m.mapping.Amount)
+	if amountText == "" {
+		return item, true, nil
+	}
+	amount, err := parseImportAmount(amountText)
+	if err != nil {
+		return item, false, err
+	}
+	billingDate, err := parseImportDate(m.value(row, m.mapping.BillingDate), m.mapping.DateLayout)
+	if err != nil {
+		return item, false, err
+	}
+
+	raw := make(map[string]interface{}, len(m.header))
+	for i, col := range m.header {
+		if col != "" && i < len(row) && row[i] != "" {
+			raw[col] = row[i]
+		}
+	}
+	var tags map[string]string
+	for i, key := range m.tagCols {
+		if i < len(row) && strings.TrimSpace(row[i]) != "" {
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+			tags[key] = strings.TrimSpace(row[i])
+		}
+	}
+
+	currency := m.value(row, m.mapping.Currency)
+	if currency == "" {
+		currency = m.mapping.DefaultCurrency
+	}
+	return billing.RawBillItem{
+		Provider:     m.provider,
+		RawData:      raw,
+		ServiceType:  m.value(row, m.mapping.ServiceType),
+		ResourceID:   m.value(row, m.mapping.ResourceID),
+		ResourceName: m.value(row, m.mapping.ResourceName),
+		Region:       m.value(row, m.mapping.Region),
+		Amount:       amount,
+		Currency:     strings.ToUpper(currency),
+		BillingCycle: billingDate.Format("2006-01"),
+		Tags:         tags,
+	}, false, nil
+}
+
+// parseImportAmount 解析金额，兼容千分位、货币符号与会计格式负数 (12.30)
+func parseImportAmount(s string) (float64, error) {
+	v := strings.NewReplacer(",", "", "¥", "", "￥", "", "$", "", " ", "").Replace(s)
+	negative := strings.HasPrefix(v, "(") && strings.HasSuffix(v, ")")
+	v = strings.Trim(v, "()")
+	amount, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+// importDateLayouts 未配置日期格式时依次尝试的格式
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"2006/1/2",
+	"2006-01",
+	"2006/01",
+	"200601",
+	"2006年01月",
+	"2006年1月",
+}
+
+// excelEpoch Excel 日期序列号起点
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// parseImportDate 解析账期或账单日期，兼容 Excel 日期序列号
+func parseImportDate(s, layout string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("billing date is empty")
+	}
+	if layout != "" {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid billing date %q for layout %q", s, layout)
+		}
+		return t.UTC(), nil
+	}
+	for _, l := range importDateLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	// CUR 账期列形如 2024-03-01T00:00:00Z/2024-04-01T00:00:00Z，取起始时间
+	if start, _, ok := strings.Cut(s, "/"); ok && strings.Contains(start, "T") {
+		if t, err := time.Parse(time.RFC3339, start); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial > 20000 && serial < 100000 {
+		return excelEpoch.AddDate(0, 0, int(serial)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid billing date %q", s)
+}
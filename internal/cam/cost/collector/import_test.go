@@ -0,0 +1,441 @@
+package collector
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	accountservice "github.com/Havens-blog/e-cam-service/internal/account/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/normalizer"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gotomicro/ego/core/elog"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// importBillDAO 记录导入写入与按月删除
+type importBillDAO struct {
+	repository.BillDAO
+	mu            sync.Mutex
+	raw           []domain.RawBillRecord
+	unified       []domain.UnifiedBill
+	deletedMonths []string
+}
+
+func (m *importBillDAO) InsertRawBills(_ context.Context, records []domain.RawBillRecord) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.raw = append(m.raw, records...)
+	return int64(len(records)), nil
+}
+
+func (m *importBillDAO) InsertUnifiedBills(_ context.Context, bills []domain.UnifiedBill) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unified = append(m.unified, bills...)
+	return int64(len(bills)), nil
+}
+
+func (m *importBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+
+func (m *importBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, month string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deletedMonths = append(m.deletedMonths, month)
+	return 0, nil
+}
+
+// importCollectLogDAO 内存采集日志，支持按校验和查询成功日志
+type importCollectLogDAO struct {
+	mockCollectLogDAO
+	logs []domain.CollectLog
+}
+
+func (m *importCollectLogDAO) Create(_ context.Context, log domain.CollectLog) (int64, error) {
+	log.ID = int64(len(m.logs) + 1)
+	m.logs = append(m.logs, log)
+	return log.ID, nil
+}
+
+func (m *importCollectLogDAO) Update(_ context.Context, log domain.CollectLog) error {
+	m.logs[log.ID-1] = log
+	return nil
+}
+
+func (m *importCollectLogDAO) GetSuccessByChecksum(_ context.Context, accountID int64, checksum string) (domain.CollectLog, error) {
+	for _, log := range m.logs {
+		if log.AccountID == accountID && log.Checksum == checksum && log.Status == "success" {
+			return log, nil
+		}
+	}
+	return domain.CollectLog{}, mongo.ErrNoDocuments
+}
+
+type mockImportSourceDAO struct {
+	sources map[int64]domain.BillImportSource
+}
+
+func (m *mockImportSourceDAO) Create(_ context.Context, source domain.BillImportSource) (int64, error) {
+	source.ID = int64(len(m.sources) + 1)
+	m.sources[source.ID] = source
+	return source.ID, nil
+}
+
+func (m *mockImportSourceDAO) Update(_ context.Context, source domain.BillImportSource) error {
+	m.sources[source.ID] = source
+	return nil
+}
+
+func (m *mockImportSourceDAO) GetByID(_ context.Context, id int64) (domain.BillImportSource, error) {
+	source, ok := m.sources[id]
+	if !ok {
+		return source, mongo.ErrNoDocuments
+	}
+	return source, nil
+}
+
+func (m *mockImportSourceDAO) List(_ context.Context, _ string) ([]domain.BillImportSource, error) {
+	var sources []domain.BillImportSource
+	for _, source := range m.sources {
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func (m *mockImportSourceDAO) Delete(_ context.Context, id int64) error {
+	delete(m.sources, id)
+	return nil
+}
+
+type importAccountService struct {
+	accountservice.CloudAccountService
+	account *shareddomain.CloudAccount
+}
+
+func (m *importAccountService) GetAccount(_ context.Context, id int64) (*shareddomain.CloudAccount, error) {
+	if id != m.account.ID {
+		return nil, errors.New("account not found")
+	}
+	return m.account, nil
+}
+
+type importFixture struct {
+	svc     *CollectorService
+	billDAO *importBillDAO
+	logDAO  *importCollectLogDAO
+}
+
+func newImportFixture(t *testing.T, sources ...domain.BillImportSource) importFixture {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	billDAO := &importBillDAO{}
+	logDAO := &importCollectLogDAO{}
+	sourceDAO := &mockImportSourceDAO{sources: make(map[int64]domain.BillImportSource)}
+	for _, source := range sources {
+		sourceDAO.sources[source.ID] = source
+	}
+	accountSvc := &importAccountService{account: &shareddomain.CloudAccount{
+		ID: 9, Name: "reseller-prod", Provider: shareddomain.CloudProviderAliyun, TenantID: "tenant-1",
+	}}
+
+	svc := NewCollectorService(normalizer.NewNormalizerService(billDAO, elog.DefaultLogger),
+		billDAO, logDAO, accountSvc, client, elog.DefaultLogger)
+	svc.SetImportSourceDAO(sourceDAO)
+	return importFixture{svc: svc, billDAO: billDAO, logDAO: logDAO}
+}
+
+func resellerSource() domain.BillImportSource {
+	return domain.BillImportSource{
+		ID:        1,
+		Name:      "reseller",
+		AccountID: 9,
+		Provider:  "aliyun",
+		Format:    domain.ImportFormatCSV,
+		TenantID:  "tenant-1",
+		Mapping: domain.ColumnMapping{
+			BillingDate:     "账期",
+			ServiceType:     "产品",
+			ResourceID:      "实例ID",
+			Region:          "地域",
+			Amount:          "应付金额",
+			DefaultCurrency: "CNY",
+		},
+	}
+}
+
+const resellerCSV = "\ufeff某代理商 2024年3月对账单\n" +
+	"账期,产品,实例ID,地域,应付金额\n" +
+	"2024-03,ecs,i-1,cn-hangzhou,\"1,200.50\"\n" +
+	"2024-03,rds,rm-1,cn-hangzhou,¥300\n" +
+	"2024-03,oss,bucket-1,cn-hangzhou,abc\n" +
+	"合计,,,,\n"
+
+func TestImportBills_CSV(t *testing.T) {
+	f := newImportFixture(t, resellerSource())
+
+	result, err := f.svc.ImportBills(context.Background(), ImportRequest{
+		SourceID: 1, TenantID: "tenant-1", FileName: "2024-03.csv", File: strings.NewReader(resellerCSV),
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Duplicate)
+	assert.Equal(t, int64(3), result.RowCount)
+	assert.Equal(t, int64(1), result.Skipped)
+	assert.Equal(t, int64(2), result.Imported)
+	assert.Equal(t, []string{"2024-03"}, result.Months)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0], "invalid amount")
+
+	require.Len(t, f.billDAO.unified, 2)
+	ecs := f.billDAO.unified[0]
+	assert.Equal(t, 1200.5, ecs.Amount)
+	assert.Equal(t, "CNY", ecs.Currency)
+	assert.Equal(t, "aliyun", ecs.Provider)
+	assert.Equal(t, domain.ServiceTypeCompute, ecs.ServiceType)
+	assert.Equal(t, "2024-03-01", ecs.BillingDate)
+	assert.Equal(t, int64(9), ecs.AccountID)
+	assert.Equal(t, "reseller-prod", ecs.AccountName)
+	assert.Equal(t, "tenant-1", ecs.TenantID)
+	assert.Equal(t, []string{"2024-03"}, f.billDAO.deletedMonths)
+
+	require.Len(t, f.billDAO.raw, 2)
+	assert.Equal(t, "1", f.billDAO.raw[0].CollectID)
+	assert.Equal(t, "i-1", f.billDAO.raw[0].RawData["实例ID"])
+
+	require.Len(t, f.logDAO.logs, 1)
+	log := f.logDAO.logs[0]
+	assert.Equal(t, "success", log.Status)
+	assert.Equal(t, domain.CollectSourceImport, log.Source)
+	assert.Equal(t, "2024-03.csv", log.FileName)
+	assert.Len(t, log.Checksum, 64)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), log.BillStart)
+	assert.Equal(t, time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC), log.BillEnd)
+	assert.Equal(t, int64(2), log.RecordCount)
+}
+
+func TestImportBills_SameFileIsIdempotent(t *testing.T) {
+	f := newImportFixture(t, resellerSource())
+	req := func() ImportRequest {
+		return ImportRequest{SourceID: 1, FileName: "2024-03.csv", File: strings.NewReader(resellerCSV)}
+	}
+
+	_, err := f.svc.ImportBills(context.Background(), req())
+	require.NoError(t, err)
+	result, err := f.svc.ImportBills(context.Background(), req())
+	require.NoError(t, err)
+	assert.True(t, result.Duplicate)
+	assert.Equal(t, int64(1), result.LogID)
+	assert.Equal(t, int64(2), result.Imported)
+	assert.Len(t, f.billDAO.unified, 2, "重复上传不再写入")
+	assert.Len(t, f.logDAO.logs, 1)
+
+	// 更正后的对账单按月份覆盖旧账单
+	corrected := strings.Replace(resellerCSV, "¥300", "¥280", 1)
+	_, err = f.svc.ImportBills(context.Background(), ImportRequest{SourceID: 1, File: strings.NewReader(corrected)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-03", "2024-03"}, f.billDAO.deletedMonths)
+	assert.Len(t, f.logDAO.logs, 2)
+}
+
+func TestImportBills_Errors(t *testing.T) {
+	f := newImportFixture(t, resellerSource())
+
+	_, err := f.svc.ImportBills(context.Background(), ImportRequest{SourceID: 2, File: strings.NewReader(resellerCSV)})
+	assert.ErrorIs(t, err, domain.ErrImportSourceNotFound)
+
+	_, err = f.svc.ImportBills(context.Background(), ImportRequest{SourceID: 1, TenantID: "tenant-2", File: strings.NewReader(resellerCSV)})
+	assert.ErrorIs(t, err, domain.ErrImportSourceNotFound)
+
+	_, err = f.svc.ImportBills(context.Background(), ImportRequest{SourceID: 1, File: strings.NewReader("a,b\n1,2\n")})
+	assert.ErrorIs(t, err, domain.ErrImportInvalid)
+	require.Len(t, f.logDAO.logs, 1)
+	assert.Equal(t, "failed", f.logDAO.logs[0].Status)
+}
+
+func TestImportBills_CURGzip(t *testing.T) {
+	source := domain.BillImportSource{ID: 1, Name: "cur", AccountID: 9, Provider: "aws", Format: domain.ImportFormatCUR, TenantID: "tenant-1"}
+	f := newImportFixture(t, source)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte("identity/LineItemId,lineItem/UsageStartDate,lineItem/ProductCode,lineItem/ResourceId,product/region,lineItem/UnblendedCost,lineItem/CurrencyCode,resourceTags/user:team\n" +
+		"a1,2024-02-10T00:00:00Z,AmazonEC2,i-abc,us-east-1,12.5,USD,web\n" +
+		"a2,2024-03-01T00:00:00Z,AmazonS3,my-bucket,us-east-1,0.75,USD,\n"))
+	require.NoError(t, gz.Close())
+
+	result, err := f.svc.ImportBills(context.Background(), ImportRequest{SourceID: 1, File: bytes.NewReader(buf.Bytes())})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-02", "2024-03"}, result.Months)
+	require.Len(t, f.billDAO.unified, 2)
+	ec2 := f.billDAO.unified[0]
+	assert.Equal(t, "aws", ec2.Provider)
+	assert.Equal(t, "USD", ec2.Currency)
+	assert.Equal(t, "i-abc", ec2.ResourceID)
+	assert.Equal(t, map[string]string{"team": "web"}, ec2.Tags)
+	assert.Empty(t, f.billDAO.unified[1].Tags)
+}
+
+func TestImportBills_XLSX(t *testing.T) {
+	source := resellerSource()
+	source.Format = domain.ImportFormatXLSX
+	f := newImportFixture(t, source)
+
+	data := buildXLSX(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>地域</t></is></c><c r="E1" t="s"><v>2</v></c></row>`+
+		`<row r="2"><c r="A2"><v>45352</v></c><c r="B2" t="inlineStr"><is><t>ecs</t></is></c><c r="E2"><v>88.8</v></c></row>`)
+
+	result, err := f.svc.ImportBills(context.Background(), ImportRequest{SourceID: 1, File: bytes.NewReader(data)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-03"}, result.Months)
+	require.Len(t, f.billDAO.unified, 1)
+	assert.Equal(t, 88.8, f.billDAO.unified[0].Amount)
+	assert.Equal(t, "ecs", f.billDAO.unified[0].ServiceTypeName)
+}
+
+func TestScanImportDirs(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "tenant-1", "reseller")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	source := resellerSource()
+	source.Dir = "reseller"
+	f := newImportFixture(t, source)
+	f.svc.SetImportDirs(root, 0)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2024-03.csv"), []byte(resellerCSV), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.csv"), []byte("a,b\n"), 0o644))
+
+	require.NoError(t, f.svc.ScanImportDirs(context.Background()))
+	assert.FileExists(t, filepath.Join(dir, importProcessedDir, "2024-03.csv"))
+	assert.FileExists(t, filepath.Join(dir, importFailedDir, "broken.csv"))
+	assert.NoFileExists(t, filepath.Join(dir, "2024-03.csv"))
+	assert.Len(t, f.billDAO.unified, 2)
+}
+
+func TestScanImportDirs_SkipsFilesStillBeingWritten(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "tenant-1", "reseller")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	source := resellerSource()
+	source.Dir = "reseller"
+	f := newImportFixture(t, source)
+	f.svc.SetImportDirs(root, time.Minute)
+
+	path := filepath.Join(dir, "2024-03.csv")
+	require.NoError(t, os.WriteFile(path, []byte(resellerCSV), 0o644))
+	require.NoError(t, f.svc.ScanImportDirs(context.Background()))
+	assert.FileExists(t, path, "刚写入的文件留待下次扫描")
+	assert.Empty(t, f.billDAO.unified)
+
+	old := time.Now().Add(-2 * time.Minute)
+	require.NoError(t, os.Chtimes(path, old, old))
+	require.NoError(t, f.svc.ScanImportDirs(context.Background()))
+	assert.FileExists(t, filepath.Join(dir, importProcessedDir, "2024-03.csv"))
+}
+
+func TestScanImportDirs_RejectsPathsOutsideTenantDir(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "2024-03.csv"), []byte(resellerCSV), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "tenant-1"), 0o755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "tenant-1", "link")))
+
+	for _, dir := range []string{"link", "../tenant-2", outside} {
+		source := resellerSource()
+		source.Dir = dir
+		f := newImportFixture(t, source)
+		f.svc.SetImportDirs(root, 0)
+
+		require.NoError(t, f.svc.ScanImportDirs(context.Background()))
+		assert.FileExists(t, filepath.Join(outside, "2024-03.csv"), dir)
+		assert.NoDirExists(t, filepath.Join(outside, importFailedDir), dir)
+		assert.Empty(t, f.billDAO.unified, dir)
+	}
+
+	// 未配置投递根目录时不扫描
+	source := resellerSource()
+	source.Dir = "reseller"
+	f := newImportFixture(t, source)
+	require.NoError(t, f.svc.ScanImportDirs(context.Background()))
+	assert.Empty(t, f.billDAO.unified)
+}
+
+func TestSaveImportSource(t *testing.T) {
+	f := newImportFixture(t)
+
+	_, err := f.svc.SaveImportSource(context.Background(), domain.BillImportSource{Name: "x", AccountID: 9, Format: "pdf"})
+	assert.ErrorIs(t, err, domain.ErrImportInvalid)
+	_, err = f.svc.SaveImportSource(context.Background(), domain.BillImportSource{Name: "x", AccountID: 9, Format: domain.ImportFormatCSV})
+	assert.ErrorIs(t, err, domain.ErrImportInvalid, "csv 需配置金额与账期列")
+	for _, dir := range []string{"/var/log", "../other", "a/../../b"} {
+		_, err = f.svc.SaveImportSource(context.Background(), domain.BillImportSource{Name: "x", AccountID: 9, Format: domain.ImportFormatCUR, Dir: dir})
+		assert.ErrorIs(t, err, domain.ErrImportInvalid, "投递目录须为相对路径：%s", dir)
+	}
+	_, err = f.svc.SaveImportSource(context.Background(), domain.BillImportSource{Name: "x", AccountID: 9, Format: domain.ImportFormatCUR, TenantID: "tenant-2"})
+	assert.ErrorIs(t, err, domain.ErrImportInvalid, "账号不属于租户")
+
+	source, err := f.svc.SaveImportSource(context.Background(), domain.BillImportSource{Name: "cur", AccountID: 9, Format: domain.ImportFormatCUR})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), source.ID)
+	assert.Equal(t, "aliyun", source.Provider)
+	assert.Equal(t, "tenant-1", source.TenantID)
+}
+
+func TestParseImportValues(t *testing.T) {
+	for in, want := range map[string]float64{"1,234.5": 1234.5, "(12.30)": -12.3, "$0.5": 0.5, "-3": -3} {
+		got, err := parseImportAmount(in)
+		require.NoError(t, err, in)
+		assert.InDelta(t, want, got, 1e-9, in)
+	}
+	_, err := parseImportAmount("N/A")
+	assert.Error(t, err)
+
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, in := range []string{"2024-03", "2024/03/01", "202403", "2024年3月", "45352", "2024-03-01T00:00:00Z/2024-04-01T00:00:00Z"} {
+		got, err := parseImportDate(in, "")
+		require.NoError(t, err, in)
+		assert.Equal(t, march, got, in)
+	}
+	got, err := parseImportDate("01.03.2024", "02.01.2006")
+	require.NoError(t, err)
+	assert.Equal(t, march, got)
+	_, err = parseImportDate("soon", "")
+	assert.Error(t, err)
+}
+
+// buildXLSX 生成只含一个工作表的最小 xlsx 文件
+func buildXLSX(t *testing.T, rows string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="账单" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>账期</t></si><si><r><t>产</t></r><r><t>品</t></r></si><si><t>应付金额</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + rows + `</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
@@ -43,6 +43,10 @@ type CollectorService struct {
 	accountSvc    accountservice.CloudAccountService
 	redisClient   redis.Cmdable
 	logger        *elog.Component
+
+	importSourceDAO repository.ImportSourceDAO
+	importRoot      string        // 目录投递根目录，为空时不扫描
+	importMinAge    time.Duration // 文件最后修改后至少经过该时长才导入
 }
 
 // NewCollectorService 创建采集服务（Wire DI 兼容）
@@ -129,6 +133,22 @@ func (s *CollectorService) TriggerManualCollection(ctx context.Context, req Manu
 // CollectAccount 采集单个云账号的账单
 // 流程：获取锁 → 获取账号信息 → 创建日志 → 分页拉取 → 标准化 → 存储 → 更新日志 → 释放锁
 func (s *CollectorService) CollectAccount(ctx context.Context, accountID int64, startTime, endTime time.Time) error {
+	return s.collectAccount(ctx, accountID, startTime, endTime, "")
+}
+
+// RecollectMonth 重新采集云账号指定账期（YYYY-MM）的账单，整月替换已有数据
+// 拉取区间为 [月初, 次月初)，仅保留该账期的条目，避免按月遍历的适配器带入次月数据；
+// 厂商返回空账单时同样清除该月旧数据
+func (s *CollectorService) RecollectMonth(ctx context.Context, accountID int64, month string) error {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return fmt.Errorf("invalid billing month %q: %w", month, err)
+	}
+	return s.collectAccount(ctx, accountID, start, start.AddDate(0, 1, 0), month)
+}
+
+// collectAccount 采集账号账单，month 非空时仅替换该账期
+func (s *CollectorService) collectAccount(ctx context.Context, accountID int64, startTime, endTime time.Time, month string) error {
 	// 1. 获取分布式锁
 	acquired, err := s.acquireLock(ctx, accountID)
 	if err != nil {
@@ -193,12 +213,18 @@ func (s *CollectorService) CollectAccount(ctx context.Context, accountID int64,
 		return fmt.Errorf("fetch bill details: %w", fetchErr)
 	}
 
+	months := collectBillingMonths(startTime, endTime)
+	if month != "" {
+		items = filterBillingCycle(items, month)
+		months = []string{month}
+	}
+
 	s.logger.Info("fetched raw bill items",
 		elog.Int64("account_id", accountID),
 		elog.Int("item_count", len(items)),
 	)
 
-	if len(items) > 0 {
+	if len(items) > 0 || month != "" {
 		collectID := strconv.FormatInt(logID, 10)
 
 		// 6. 标准化（CPU 密集，先做）
@@ -215,63 +241,13 @@ func (s *CollectorService) CollectAccount(ctx context.Context, accountID int64,
 			unifiedBills[i].TenantID = account.TenantID
 		}
 
-		// 6.5 去重：按月份删除该账号的旧账单数据（先删后插）
+		// 7. 去重写入：按月份删除该账号的旧账单数据后并发写入 raw bills 和 unified bills（先删后插）
 		// 云厂商 API 按月返回账单，BillingDate 统一为月份第一天（如 2025-04-01）
 		// 必须按月份匹配删除，否则增量采集时日期范围不覆盖月初会导致重复
-		months := collectBillingMonths(startTime, endTime)
-		var delRaw, delUnified int64
-		for _, month := range months {
-			// month 格式: "2025-04"，匹配 billing_date 以该月份开头的记录
-			dr, _ := s.billDAO.DeleteRawBillsByAccountAndMonth(ctx, accountID, month)
-			du, _ := s.billDAO.DeleteUnifiedBillsByAccountAndMonth(ctx, accountID, month)
-			delRaw += dr
-			delUnified += du
-		}
-		if delRaw > 0 || delUnified > 0 {
-			s.logger.Info("dedup: deleted old bills before re-insert",
-				elog.Int64("account_id", accountID),
-				elog.Int64("deleted_raw", delRaw),
-				elog.Int64("deleted_unified", delUnified),
-				elog.Any("months", months),
-			)
-		}
-
-		// 7. 并发写入 raw bills 和 unified bills
-		var wg sync.WaitGroup
-		var rawErr, unifiedErr error
-		var inserted int64
-
-		wg.Add(2)
-
-		// 写入原始账单（审计用）
-		go func() {
-			defer wg.Done()
-			rawRecords := s.convertToRawRecords(items, accountID, collectID)
-			if err := s.batchInsertRawBills(ctx, rawRecords); err != nil {
-				rawErr = err
-			}
-		}()
-
-		// 写入统一账单
-		go func() {
-			defer wg.Done()
-			if len(unifiedBills) > 0 {
-				n, err := s.batchInsertUnifiedBills(ctx, unifiedBills)
-				if err != nil {
-					unifiedErr = err
-				}
-				inserted = n
-			}
-		}()
-
-		wg.Wait()
-
-		if rawErr != nil {
-			s.logger.Warn("failed to insert raw bills", elog.FieldErr(rawErr))
-		}
-		if unifiedErr != nil {
-			s.finishCollectLog(ctx, &collectLog, collectStart, 0, "failed", fmt.Sprintf("insert unified bills: %v", unifiedErr))
-			return fmt.Errorf("insert unified bills: %w", unifiedErr)
+		inserted, storeErr := s.replaceBills(ctx, accountID, months, items, unifiedBills, collectID)
+		if storeErr != nil {
+			s.finishCollectLog(ctx, &collectLog, collectStart, 0, "failed", storeErr.Error())
+			return storeErr
 		}
 		totalRecords = inserted
 	}
@@ -290,6 +266,64 @@ func (s *CollectorService) CollectAccount(ctx context.Context, accountID int64,
 	return nil
 }
 
+// replaceBills 按月份删除账号旧账单后并发写入原始账单与统一账单，返回写入的统一账单数
+func (s *CollectorService) replaceBills(ctx context.Context, accountID int64, months []string,
+	items []billing.RawBillItem, unifiedBills []domain.UnifiedBill, collectID string) (int64, error) {
+	var delRaw, delUnified int64
+	for _, month := range months {
+		// month 格式: "2025-04"，匹配 billing_date 以该月份开头的记录
+		dr, _ := s.billDAO.DeleteRawBillsByAccountAndMonth(ctx, accountID, month)
+		du, _ := s.billDAO.DeleteUnifiedBillsByAccountAndMonth(ctx, accountID, month)
+		delRaw += dr
+		delUnified += du
+	}
+	if delRaw > 0 || delUnified > 0 {
+		s.logger.Info("dedup: deleted old bills before re-insert",
+			elog.Int64("account_id", accountID),
+			elog.Int64("deleted_raw", delRaw),
+			elog.Int64("deleted_unified", delUnified),
+			elog.Any("months", months),
+		)
+	}
+
+	var wg sync.WaitGroup
+	var rawErr, unifiedErr error
+	var inserted int64
+
+	wg.Add(2)
+
+	// 写入原始账单（审计用）
+	go func() {
+		defer wg.Done()
+		rawRecords := s.convertToRawRecords(items, accountID, collectID)
+		if err := s.batchInsertRawBills(ctx, rawRecords); err != nil {
+			rawErr = err
+		}
+	}()
+
+	// 写入统一账单
+	go func() {
+		defer wg.Done()
+		if len(unifiedBills) > 0 {
+			n, err := s.batchInsertUnifiedBills(ctx, unifiedBills)
+			if err != nil {
+				unifiedErr = err
+			}
+			inserted = n
+		}
+	}()
+
+	wg.Wait()
+
+	if rawErr != nil {
+		s.logger.Warn("failed to insert raw bills", elog.FieldErr(rawErr))
+	}
+	if unifiedErr != nil {
+		return 0, fmt.Errorf("insert unified bills: %w", unifiedErr)
+	}
+	return inserted, nil
+}
+
 // calculateIncrementalRange 计算增量采集时间范围
 // 优先检查失败日志进行重试，否则从上次成功采集的 BillEnd 开始
 // 如果没有历史记录，默认从当月第一天开始
@@ -433,3 +467,14 @@ func collectBillingMonths(startTime, endTime time.Time) []string {
 	}
 	return months
 }
+
+// filterBillingCycle 仅保留指定账期的原始账单
+func filterBillingCycle(items []billing.RawBillItem, month string) []billing.RawBillItem {
+	kept := items[:0]
+	for _, item := range items {
+		if item.BillingCycle == month {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recollectProvider 仅用于重新采集测试的计费适配器注册名
+const recollectProvider shareddomain.CloudProvider = "recollect_test_provider"
+
+// monthSpanAdapter 按月遍历的适配器：返回区间内所有月份以及次月的条目
+type monthSpanAdapter struct {
+	params []billing.FetchBillParams
+}
+
+func (a *monthSpanAdapter) GetProvider() shareddomain.CloudProvider { return recollectProvider }
+
+func (a *monthSpanAdapter) FetchBillDetails(_ context.Context, params billing.FetchBillParams) ([]billing.RawBillItem, error) {
+	a.params = append(a.params, params)
+	return []billing.RawBillItem{
+		{Provider: shareddomain.CloudProviderAliyun, ServiceType: "ecs", ResourceID: "i-1", Amount: 100, Currency: "CNY", BillingCycle: "2026-09"},
+		{Provider: shareddomain.CloudProviderAliyun, ServiceType: "rds", ResourceID: "rm-1", Amount: 50, Currency: "CNY", BillingCycle: "2026-09"},
+		{Provider: shareddomain.CloudProviderAliyun, ServiceType: "ecs", ResourceID: "i-1", Amount: 10, Currency: "CNY", BillingCycle: "2026-10"},
+	}, nil
+}
+
+func (a *monthSpanAdapter) FetchBillTotal(_ context.Context, _ billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	return &billing.BillTotal{}, nil
+}
+
+type credentialAccountService struct {
+	importAccountService
+}
+
+func (m *credentialAccountService) GetAccountWithCredentials(ctx context.Context, id int64) (*shareddomain.CloudAccount, error) {
+	return m.GetAccount(ctx, id)
+}
+
+func TestRecollectMonth_ReplacesMonth(t *testing.T) {
+	adapter := &monthSpanAdapter{}
+	billing.RegisterBillingAdapter(recollectProvider, func(_ *shareddomain.CloudAccount) (billing.BillingAdapter, error) {
+		return adapter, nil
+	})
+
+	f := newImportFixture(t)
+	f.svc.accountSvc = &credentialAccountService{importAccountService{account: &shareddomain.CloudAccount{
+		ID: 9, Name: "prod", Provider: recollectProvider, TenantID: "tenant-1",
+	}}}
+
+	require.NoError(t, f.svc.RecollectMonth(context.Background(), 9, "2026-09"))
+
+	require.Len(t, adapter.params, 1)
+	assert.Equal(t, time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), adapter.params[0].StartTime)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), adapter.params[0].EndTime)
+	// 只替换目标账期，次月条目被丢弃
+	assert.Equal(t, []string{"2026-09"}, f.billDAO.deletedMonths)
+	require.Len(t, f.billDAO.raw, 2)
+	require.Len(t, f.billDAO.unified, 2)
+	for _, bill := range f.billDAO.unified {
+		assert.Equal(t, int64(9), bill.AccountID)
+	}
+	assert.Equal(t, "success", f.logDAO.logs[0].Status)
+
+	assert.Error(t, f.svc.RecollectMonth(context.Background(), 9, "2026/09"))
+}
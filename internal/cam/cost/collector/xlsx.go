@@ -0,0 +1,206 @@
+package collector
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// xlsx 对账单只需读取单元格文本，按 OOXML 结构直接解析工作表，不引入完整的 Excel 库
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText 纯文本 <t> 或富文本 <r><t>
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	for _, r := range t.Runs {
+		sb.WriteString(r.Text)
+	}
+	return sb.String()
+}
+
+type xlsxRow struct {
+	Cells []struct {
+		Ref    string       `xml:"r,attr"`
+		Type   string       `xml:"t,attr"`
+		Value  string       `xml:"v"`
+		Inline xlsxRichText `xml:"is"`
+	} `xml:"c"`
+}
+
+// readXLSXRows 读取工作表全部行，sheet 为空时读取第一个工作表
+func readXLSXRows(r io.ReaderAt, size int64, sheet string) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("open xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := xlsxSheetPath(files, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, fmt.Errorf("read shared strings: %w", err)
+		}
+	}
+
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("worksheet %s not found", sheetPath)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rows [][]string
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read worksheet: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row xlsxRow
+		if err := dec.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("read worksheet row: %w", err)
+		}
+		rows = append(rows, row.values(shared.Items))
+	}
+}
+
+// values 按单元格引用还原列位置，空单元格补空串
+func (row xlsxRow) values(shared []xlsxRichText) []string {
+	var values []string
+	for i, c := range row.Cells {
+		col := i
+		if c.Ref != "" {
+			col = xlsxColumnIndex(c.Ref)
+		}
+		for len(values) <= col {
+			values = append(values, "")
+		}
+		switch c.Type {
+		case "s":
+			var idx int
+			if _, err := fmt.Sscanf(c.Value, "%d", &idx); err == nil && idx >= 0 && idx < len(shared) {
+				values[col] = shared[idx].String()
+			}
+		case "inlineStr":
+			values[col] = c.Inline.String()
+		default:
+			values[col] = c.Value
+		}
+	}
+	return values
+}
+
+// xlsxColumnIndex 单元格引用（如 AB12）转换为从 0 开始的列序号
+func xlsxColumnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+	return col - 1
+}
+
+// xlsxSheetPath 按工作表名解析工作表 XML 路径
+func xlsxSheetPath(files map[string]*zip.File, sheet string) (string, error) {
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", fmt.Errorf("xl/workbook.xml not found")
+	}
+	var wb xlsxWorkbook
+	if err := decodeZipXML(wbFile, &wb); err != nil {
+		return "", fmt.Errorf("read workbook: %w", err)
+	}
+	if len(wb.Sheets) == 0 {
+		return "", fmt.Errorf("workbook has no sheets")
+	}
+
+	rid := wb.Sheets[0].RID
+	if sheet != "" {
+		rid = ""
+		for _, s := range wb.Sheets {
+			if s.Name == sheet {
+				rid = s.RID
+				break
+			}
+		}
+		if rid == "" {
+			return "", fmt.Errorf("sheet %q not found", sheet)
+		}
+	}
+
+	var rels xlsxRelationships
+	if f, ok := files["xl/_rels/workbook.xml.rels"]; ok {
+		if err := decodeZipXML(f, &rels); err != nil {
+			return "", fmt.Errorf("read workbook relationships: %w", err)
+		}
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != rid {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return "xl/worksheets/sheet1.xml", nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
@@ -0,0 +1,475 @@
+// Package commitment 预留实例 / 节省计划 / 资源包等承诺消费分析
+package commitment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// syncWindowDays 利用率 / 覆盖率统计窗口（天）
+	syncWindowDays = 30
+	// expiryNoticeDays 到期提醒提前天数
+	expiryNoticeDays = 30
+)
+
+// 报表分组维度
+const (
+	GroupByAccount        = "account"
+	GroupByRegion         = "region"
+	GroupByInstanceFamily = "instance_family"
+	GroupByType           = "type"
+)
+
+// AccountProvider 云账号查询接口
+type AccountProvider interface {
+	GetAccountWithCredentials(ctx context.Context, id int64) (*shareddomain.CloudAccount, error)
+	ListAccounts(ctx context.Context, filter shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error)
+}
+
+// CoverageItem 覆盖率报表条目
+type CoverageItem struct {
+	Key           string  `json:"key"`
+	CoveredHours  float64 `json:"covered_hours"`
+	OnDemandHours float64 `json:"on_demand_hours"`
+	CoveragePct   float64 `json:"coverage_pct"`
+	OnDemandCost  float64 `json:"on_demand_cost"`
+	Currency      string  `json:"currency"`
+}
+
+// UtilizationItem 利用率报表条目
+type UtilizationItem struct {
+	Key            string  `json:"key"`
+	Count          int     `json:"count"`
+	PeriodCost     float64 `json:"period_cost"`
+	UtilizationPct float64 `json:"utilization_pct"` // 按摊销费用加权
+	UnusedCost     float64 `json:"unused_cost"`     // 未被使用部分的摊销费用
+	ExpiringSoon   int     `json:"expiring_soon"`   // 30 天内到期数量
+	Currency       string  `json:"currency"`
+}
+
+// CommitmentService 承诺消费服务
+type CommitmentService struct {
+	commitmentDAO repository.CommitmentDAO
+	accountSvc    AccountProvider
+	alertSvc      *alertservice.AlertService
+	logger        *elog.Component
+	adapterFor    func(account *shareddomain.CloudAccount) (billing.BillingAdapter, error)
+	supports      func(provider shareddomain.CloudProvider) bool
+}
+
+// NewCommitmentService 创建承诺消费服务
+func NewCommitmentService(
+	commitmentDAO repository.CommitmentDAO,
+	accountSvc AccountProvider,
+	alertSvc *alertservice.AlertService,
+	logger *elog.Component,
+) *CommitmentService {
+	return &CommitmentService{
+		commitmentDAO: commitmentDAO,
+		accountSvc:    accountSvc,
+		alertSvc:      alertSvc,
+		logger:        logger,
+		adapterFor:    newBillingAdapter,
+		supports:      billing.SupportsCommitments,
+	}
+}
+
+// newBillingAdapter 通过计费适配器注册表创建云账号的适配器
+func newBillingAdapter(account *shareddomain.CloudAccount) (billing.BillingAdapter, error) {
+	creator, err := billing.GetBillingAdapter(account.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return creator(account)
+}
+
+// SyncAll 同步所有活跃云账号的承诺消费，tenantID 为空时同步全部租户
+// 单个账号失败不影响其他账号
+func (s *CommitmentService) SyncAll(ctx context.Context, tenantID string) error {
+	accounts, _, err := s.accountSvc.ListAccounts(ctx, shareddomain.CloudAccountFilter{
+		Status:   shareddomain.CloudAccountStatusActive,
+		TenantID: tenantID,
+		Limit:    1000,
+	})
+	if err != nil {
+		return fmt.Errorf("list active accounts: %w", err)
+	}
+
+	for _, acct := range accounts {
+		if _, err := s.SyncAccount(ctx, acct.ID); err != nil {
+			if errors.Is(err, domain.ErrCommitmentUnsupported) {
+				continue
+			}
+			s.logger.Error("sync commitments failed for account",
+				elog.Int64("account_id", acct.ID),
+				elog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+// SyncAccount 同步单个云账号的承诺消费与覆盖率，返回写入的承诺数量
+// 云厂商未实现承诺消费接口时返回 ErrCommitmentUnsupported
+func (s *CommitmentService) SyncAccount(ctx context.Context, accountID int64) (int64, error) {
+	account, err := s.accountSvc.GetAccountWithCredentials(ctx, accountID)
+	if err != nil {
+		return 0, fmt.Errorf("get account %d: %w", accountID, err)
+	}
+
+	adapter, err := s.adapterFor(account)
+	if err != nil {
+		return 0, fmt.Errorf("create billing adapter for %s: %w", account.Provider, err)
+	}
+	ca, ok := adapter.(billing.CommitmentAdapter)
+	if !ok {
+		return 0, domain.ErrCommitmentUnsupported
+	}
+
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -syncWindowDays)
+	params := billing.FetchCommitmentParams{
+		AccountID: strconv.FormatInt(accountID, 10),
+		StartTime: start,
+		EndTime:   end,
+	}
+
+	raws, err := ca.FetchCommitments(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("fetch commitments: %w", err)
+	}
+	commitments := make([]domain.Commitment, 0, len(raws))
+	for _, raw := range raws {
+		commitments = append(commitments, toCommitment(raw, account, now))
+	}
+	count, err := s.commitmentDAO.UpsertCommitments(ctx, commitments)
+	if err != nil {
+		return 0, fmt.Errorf("upsert commitments: %w", err)
+	}
+
+	rawCoverage, err := ca.FetchCoverage(ctx, params)
+	if err != nil {
+		return count, fmt.Errorf("fetch coverage: %w", err)
+	}
+	coverage := make([]domain.CommitmentCoverage, 0, len(rawCoverage))
+	for _, rc := range rawCoverage {
+		coverage = append(coverage, domain.CommitmentCoverage{
+			Provider:       string(account.Provider),
+			AccountID:      account.ID,
+			Region:         rc.Region,
+			InstanceFamily: rc.InstanceFamily,
+			CoveredHours:   rc.CoveredHours,
+			OnDemandHours:  rc.OnDemandHours,
+			OnDemandCost:   rc.OnDemandCost,
+			Currency:       rc.Currency,
+			PeriodStart:    start.Format("2006-01-02"),
+			PeriodEnd:      end.Format("2006-01-02"),
+			TenantID:       account.TenantID,
+		})
+	}
+	if err := s.commitmentDAO.ReplaceCoverage(ctx, account.TenantID, account.ID, coverage); err != nil {
+		return count, fmt.Errorf("replace coverage: %w", err)
+	}
+
+	s.logger.Info("commitments synced",
+		elog.Int64("account_id", accountID),
+		elog.Int("commitments", len(commitments)),
+		elog.Int("coverage_groups", len(coverage)))
+	return count, nil
+}
+
+// toCommitment 将适配器返回的承诺消费转换为领域模型，状态按到期时间归一
+func toCommitment(raw billing.RawCommitment, account *shareddomain.CloudAccount, now time.Time) domain.Commitment {
+	status := domain.CommitmentStatusActive
+	if !raw.EndTime.IsZero() && !raw.EndTime.After(now) {
+		status = domain.CommitmentStatusExpired
+	}
+	return domain.Commitment{
+		Provider:         string(account.Provider),
+		AccountID:        account.ID,
+		CommitmentID:     raw.CommitmentID,
+		Type:             raw.Type,
+		Region:           raw.Region,
+		InstanceFamily:   raw.InstanceFamily,
+		ServiceType:      raw.ServiceType,
+		InstanceType:     raw.InstanceType,
+		Quantity:         raw.Quantity,
+		Unit:             raw.Unit,
+		HourlyCommitment: raw.HourlyCommitment,
+		UtilizationPct:   raw.UtilizationPct,
+		PeriodCost:       raw.PeriodCost,
+		Currency:         raw.Currency,
+		StartTime:        raw.StartTime,
+		EndTime:          raw.EndTime,
+		Status:           status,
+		ProviderStatus:   raw.Status,
+		TenantID:         account.TenantID,
+	}
+}
+
+// UnsupportedProviders 返回租户活跃云账号中不支持承诺消费分析的云厂商
+// 这些云厂商的账号不会出现在承诺消费列表与报表中，供接口显式告知调用方
+func (s *CommitmentService) UnsupportedProviders(ctx context.Context, tenantID string) ([]string, error) {
+	accounts, _, err := s.accountSvc.ListAccounts(ctx, shareddomain.CloudAccountFilter{
+		Status:   shareddomain.CloudAccountStatusActive,
+		TenantID: tenantID,
+		Limit:    1000,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list active accounts: %w", err)
+	}
+
+	seen := make(map[shareddomain.CloudProvider]bool)
+	providers := make([]string, 0)
+	for _, acct := range accounts {
+		if seen[acct.Provider] || s.supports(acct.Provider) {
+			continue
+		}
+		seen[acct.Provider] = true
+		providers = append(providers, string(acct.Provider))
+	}
+	sort.Strings(providers)
+	return providers, nil
+}
+
+// ListCommitments 获取承诺消费列表
+func (s *CommitmentService) ListCommitments(ctx context.Context, tenantID string, filter repository.CommitmentFilter) ([]domain.Commitment, int64, error) {
+	filter.TenantID = tenantID
+	commitments, err := s.commitmentDAO.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list commitments: %w", err)
+	}
+	count, err := s.commitmentDAO.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("count commitments: %w", err)
+	}
+	return commitments, count, nil
+}
+
+// ListActiveCommitments 获取租户当前有效的承诺消费（供优化建议参考）
+func (s *CommitmentService) ListActiveCommitments(ctx context.Context, tenantID string) ([]domain.Commitment, error) {
+	return s.commitmentDAO.List(ctx, repository.CommitmentFilter{
+		TenantID: tenantID,
+		Status:   domain.CommitmentStatusActive,
+	})
+}
+
+// GetCoverageReport 覆盖率报表，按账号 / 地域 / 实例族分组
+// 覆盖率 = 承诺覆盖小时 / (承诺覆盖小时 + 按需小时)，不同币种分开汇总
+func (s *CommitmentService) GetCoverageReport(ctx context.Context, tenantID, groupBy string, filter repository.CommitmentFilter) ([]CoverageItem, error) {
+	if err := validateGroupBy(groupBy, false); err != nil {
+		return nil, err
+	}
+	filter.TenantID = tenantID
+	rows, err := s.commitmentDAO.ListCoverage(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list coverage: %w", err)
+	}
+
+	type groupKey struct{ key, currency string }
+	groups := make(map[groupKey]*CoverageItem)
+	for _, row := range rows {
+		k := groupKey{key: coverageKey(row, groupBy), currency: row.Currency}
+		item, ok := groups[k]
+		if !ok {
+			item = &CoverageItem{Key: k.key, Currency: k.currency}
+			groups[k] = item
+		}
+		item.CoveredHours += row.CoveredHours
+		item.OnDemandHours += row.OnDemandHours
+		item.OnDemandCost += row.OnDemandCost
+	}
+
+	items := make([]CoverageItem, 0, len(groups))
+	for _, item := range groups {
+		if total := item.CoveredHours + item.OnDemandHours; total > 0 {
+			item.CoveragePct = item.CoveredHours / total * 100
+		}
+		items = append(items, *item)
+	}
+	// 按需费用高的分组排在前面，便于定位覆盖缺口
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].OnDemandCost != items[j].OnDemandCost {
+			return items[i].OnDemandCost > items[j].OnDemandCost
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items, nil
+}
+
+// GetUtilizationReport 利用率报表，按账号 / 地域 / 实例族 / 承诺类型分组（仅统计有效承诺）
+func (s *CommitmentService) GetUtilizationReport(ctx context.Context, tenantID, groupBy string, filter repository.CommitmentFilter) ([]UtilizationItem, error) {
+	if err := validateGroupBy(groupBy, true); err != nil {
+		return nil, err
+	}
+	filter.TenantID = tenantID
+	filter.Status = domain.CommitmentStatusActive
+	commitments, err := s.commitmentDAO.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list commitments: %w", err)
+	}
+
+	now := time.Now()
+	expiryLine := now.AddDate(0, 0, expiryNoticeDays)
+	type groupKey struct{ key, currency string }
+	groups := make(map[groupKey]*UtilizationItem)
+	usedCost := make(map[groupKey]float64)
+	for _, c := range commitments {
+		k := groupKey{key: commitmentKey(c, groupBy), currency: c.Currency}
+		item, ok := groups[k]
+		if !ok {
+			item = &UtilizationItem{Key: k.key, Currency: k.currency}
+			groups[k] = item
+		}
+		item.Count++
+		item.PeriodCost += c.PeriodCost
+		usedCost[k] += c.PeriodCost * c.UtilizationPct / 100
+		if !c.EndTime.IsZero() && c.EndTime.Before(expiryLine) {
+			item.ExpiringSoon++
+		}
+	}
+
+	items := make([]UtilizationItem, 0, len(groups))
+	for k, item := range groups {
+		if item.PeriodCost > 0 {
+			item.UtilizationPct = usedCost[k] / item.PeriodCost * 100
+		}
+		item.UnusedCost = item.PeriodCost - usedCost[k]
+		items = append(items, *item)
+	}
+	// 浪费金额高的分组排在前面
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].UnusedCost != items[j].UnusedCost {
+			return items[i].UnusedCost > items[j].UnusedCost
+		}
+		return items[i].Key < items[j].Key
+	})
+	return items, nil
+}
+
+// CheckExpiring 检查即将到期的承诺消费并发送到期提醒，tenantID 为空时检查全部租户
+// 每个承诺只提醒一次，返回本次提醒的数量
+func (s *CommitmentService) CheckExpiring(ctx context.Context, tenantID string, now time.Time) (int, error) {
+	commitments, err := s.commitmentDAO.List(ctx, repository.CommitmentFilter{
+		TenantID:     tenantID,
+		Status:       domain.CommitmentStatusActive,
+		ExpireAfter:  now,
+		ExpireBefore: now.AddDate(0, 0, expiryNoticeDays),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("list expiring commitments: %w", err)
+	}
+
+	notified := 0
+	for _, c := range commitments {
+		if c.ExpiryNotifiedAt != nil {
+			continue
+		}
+		if err := s.emitExpiryAlert(ctx, c, now); err != nil {
+			s.logger.Error("emit commitment expiry alert failed",
+				elog.String("commitment_id", c.CommitmentID),
+				elog.FieldErr(err))
+			continue
+		}
+		if err := s.commitmentDAO.MarkExpiryNotified(ctx, c.ID, now); err != nil {
+			s.logger.Error("mark commitment expiry notified failed",
+				elog.Int64("id", c.ID),
+				elog.FieldErr(err))
+			continue
+		}
+		notified++
+	}
+	return notified, nil
+}
+
+// emitExpiryAlert 以资源过期告警发送承诺消费到期提醒
+func (s *CommitmentService) emitExpiryAlert(ctx context.Context, c domain.Commitment, now time.Time) error {
+	daysLeft := float64(int(c.EndTime.Sub(now).Hours() / 24))
+	severity := alertdomain.SeverityWarning
+	if daysLeft <= 7 {
+		severity = alertdomain.SeverityCritical
+	}
+
+	event := alertdomain.AlertEvent{
+		Type:     alertdomain.AlertTypeExpiration,
+		Severity: severity,
+		Title:    fmt.Sprintf("承诺消费即将到期: %s %s 剩余 %.0f 天", c.Type, c.CommitmentID, daysLeft),
+		Content: map[string]any{
+			"resource_type":   c.Type,
+			"asset_id":        c.CommitmentID,
+			"asset_name":      commitmentName(c),
+			"expire_time":     c.EndTime.Format("2006-01-02 15:04:05"),
+			"days_left":       daysLeft,
+			"provider":        c.Provider,
+			"account_id":      c.AccountID,
+			"region":          c.Region,
+			"utilization_pct": c.UtilizationPct,
+		},
+		Source:     fmt.Sprintf("commitment:%s:%s", c.Provider, c.CommitmentID),
+		TenantID:   c.TenantID,
+		Status:     alertdomain.EventStatusPending,
+		CreateTime: now,
+	}
+	return s.alertSvc.EmitEvent(ctx, event)
+}
+
+// commitmentName 承诺消费的展示名称（规格或实例族）
+func commitmentName(c domain.Commitment) string {
+	switch {
+	case c.InstanceType != "":
+		return fmt.Sprintf("%s x%.0f", c.InstanceType, c.Quantity)
+	case c.InstanceFamily != "":
+		return c.InstanceFamily
+	default:
+		return ""
+	}
+}
+
+func validateGroupBy(groupBy string, allowType bool) error {
+	switch groupBy {
+	case GroupByAccount, GroupByRegion, GroupByInstanceFamily:
+		return nil
+	case GroupByType:
+		if allowType {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported group_by %q", groupBy)
+}
+
+func coverageKey(row domain.CommitmentCoverage, groupBy string) string {
+	switch groupBy {
+	case GroupByRegion:
+		return row.Region
+	case GroupByInstanceFamily:
+		return row.InstanceFamily
+	default:
+		return strconv.FormatInt(row.AccountID, 10)
+	}
+}
+
+func commitmentKey(c domain.Commitment, groupBy string) string {
+	switch groupBy {
+	case GroupByRegion:
+		return c.Region
+	case GroupByInstanceFamily:
+		return c.InstanceFamily
+	case GroupByType:
+		return c.Type
+	default:
+		return strconv.FormatInt(c.AccountID, 10)
+	}
+}
@@ -0,0 +1,391 @@
+package commitment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Mock CommitmentDAO ==========
+
+type mockCommitmentDAO struct {
+	commitments []domain.Commitment
+	coverage    []domain.CommitmentCoverage
+	listFilters []repository.CommitmentFilter
+	notified    map[int64]time.Time
+}
+
+func (m *mockCommitmentDAO) UpsertCommitments(_ context.Context, commitments []domain.Commitment) (int64, error) {
+	m.commitments = append(m.commitments, commitments...)
+	return int64(len(commitments)), nil
+}
+func (m *mockCommitmentDAO) List(_ context.Context, filter repository.CommitmentFilter) ([]domain.Commitment, error) {
+	m.listFilters = append(m.listFilters, filter)
+	var result []domain.Commitment
+	for _, c := range m.commitments {
+		if filter.Status != "" && c.Status != filter.Status {
+			continue
+		}
+		if !filter.ExpireAfter.IsZero() && c.EndTime.Before(filter.ExpireAfter) {
+			continue
+		}
+		if !filter.ExpireBefore.IsZero() && !c.EndTime.Before(filter.ExpireBefore) {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+func (m *mockCommitmentDAO) Count(_ context.Context, _ repository.CommitmentFilter) (int64, error) {
+	return int64(len(m.commitments)), nil
+}
+func (m *mockCommitmentDAO) MarkExpiryNotified(_ context.Context, id int64, at time.Time) error {
+	if m.notified == nil {
+		m.notified = make(map[int64]time.Time)
+	}
+	m.notified[id] = at
+	return nil
+}
+func (m *mockCommitmentDAO) ReplaceCoverage(_ context.Context, _ string, _ int64, items []domain.CommitmentCoverage) error {
+	m.coverage = items
+	return nil
+}
+func (m *mockCommitmentDAO) ListCoverage(_ context.Context, _ repository.CommitmentFilter) ([]domain.CommitmentCoverage, error) {
+	return m.coverage, nil
+}
+
+// ========== Mock AccountProvider ==========
+
+type mockAccountProvider struct {
+	accounts []*shareddomain.CloudAccount
+}
+
+func (m *mockAccountProvider) GetAccountWithCredentials(_ context.Context, id int64) (*shareddomain.CloudAccount, error) {
+	for _, a := range m.accounts {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return nil, assert.AnError
+}
+func (m *mockAccountProvider) ListAccounts(_ context.Context, _ shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error) {
+	return m.accounts, int64(len(m.accounts)), nil
+}
+
+// ========== Mock Adapters ==========
+
+type mockCommitmentAdapter struct {
+	commitments []billing.RawCommitment
+	coverage    []billing.RawCoverage
+	params      billing.FetchCommitmentParams
+}
+
+func (m *mockCommitmentAdapter) GetProvider() shareddomain.CloudProvider {
+	return shareddomain.CloudProviderAWS
+}
+func (m *mockCommitmentAdapter) FetchBillDetails(_ context.Context, _ billing.FetchBillParams) ([]billing.RawBillItem, error) {
+	return nil, nil
+}
+func (m *mockCommitmentAdapter) FetchBillTotal(_ context.Context, _ billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	return &billing.BillTotal{}, nil
+}
+func (m *mockCommitmentAdapter) FetchCommitments(_ context.Context, params billing.FetchCommitmentParams) ([]billing.RawCommitment, error) {
+	m.params = params
+	return m.commitments, nil
+}
+func (m *mockCommitmentAdapter) FetchCoverage(_ context.Context, _ billing.FetchCommitmentParams) ([]billing.RawCoverage, error) {
+	return m.coverage, nil
+}
+
+// billOnlyAdapter 未实现承诺消费接口的适配器
+type billOnlyAdapter struct{}
+
+func (billOnlyAdapter) GetProvider() shareddomain.CloudProvider {
+	return shareddomain.CloudProviderAzure
+}
+func (billOnlyAdapter) FetchBillDetails(_ context.Context, _ billing.FetchBillParams) ([]billing.RawBillItem, error) {
+	return nil, nil
+}
+func (billOnlyAdapter) FetchBillTotal(_ context.Context, _ billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	return &billing.BillTotal{}, nil
+}
+
+// ========== Mock AlertDAO ==========
+
+type mockAlertDAO struct {
+	emittedEvents []alertdomain.AlertEvent
+	listRulesFn   func(ctx context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error)
+}
+
+func (m *mockAlertDAO) CreateRule(_ context.Context, _ alertdomain.AlertRule) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateRule(_ context.Context, _ alertdomain.AlertRule) error { return nil }
+func (m *mockAlertDAO) GetRuleByID(_ context.Context, _ int64) (alertdomain.AlertRule, error) {
+	return alertdomain.AlertRule{}, nil
+}
+func (m *mockAlertDAO) ListRules(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+	if m.listRulesFn != nil {
+		return m.listRulesFn(nil, filter)
+	}
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteRule(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) CreateEvent(_ context.Context, event alertdomain.AlertEvent) (int64, error) {
+	m.emittedEvents = append(m.emittedEvents, event)
+	return int64(len(m.emittedEvents)), nil
+}
+func (m *mockAlertDAO) UpdateEventStatus(_ context.Context, _ int64, _ alertdomain.EventStatus) error {
+	return nil
+}
+func (m *mockAlertDAO) ListEvents(_ context.Context, _ alertdomain.AlertEventFilter) ([]alertdomain.AlertEvent, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) GetPendingEvents(_ context.Context, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) IncrementRetry(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetEventByID(_ context.Context, _ int64) (alertdomain.AlertEvent, error) {
+	return alertdomain.AlertEvent{}, nil
+}
+func (m *mockAlertDAO) ResolveEvents(_ context.Context, _ string, _ alertdomain.EventTransition) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) AckEvent(_ context.Context, _ int64, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AssignEvent(_ context.Context, _ int64, _ string, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AppendEventHistory(_ context.Context, _ int64, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) SetAckDeadline(_ context.Context, _ int64, _ *time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) RenotifyEvent(_ context.Context, _ int64, _ time.Time, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) GetRenotifyEvents(_ context.Context, _ time.Time, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) GetState(_ context.Context, _ string) (alertdomain.AlertState, error) {
+	return alertdomain.AlertState{}, nil
+}
+func (m *mockAlertDAO) RecordOccurrence(_ context.Context, state alertdomain.AlertState, _ time.Time) (alertdomain.AlertState, error) {
+	state.Count = 1
+	return state, nil
+}
+func (m *mockAlertDAO) MarkStateEscalated(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) MarkStateNotified(_ context.Context, _ string, _ time.Time, _ time.Duration) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) SetStateLastEvent(_ context.Context, _ string, _ int64) error { return nil }
+func (m *mockAlertDAO) ResolveState(_ context.Context, _ alertdomain.AlertState, _ time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) ListActiveStates(_ context.Context) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) ListActiveStatesByType(_ context.Context, _ string, _ alertdomain.AlertType) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) CreateChannel(_ context.Context, _ alertdomain.NotificationChannel) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateChannel(_ context.Context, _ alertdomain.NotificationChannel) error {
+	return nil
+}
+func (m *mockAlertDAO) GetChannelByID(_ context.Context, _ int64) (alertdomain.NotificationChannel, error) {
+	return alertdomain.NotificationChannel{}, nil
+}
+func (m *mockAlertDAO) ListChannels(_ context.Context, _ alertdomain.ChannelFilter) ([]alertdomain.NotificationChannel, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteChannel(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetChannelsByIDs(_ context.Context, _ []int64) ([]alertdomain.NotificationChannel, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) CreateTemplate(_ context.Context, _ alertdomain.MessageTemplate) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateTemplate(_ context.Context, _ alertdomain.MessageTemplate) error {
+	return nil
+}
+func (m *mockAlertDAO) GetTemplateByID(_ context.Context, _ int64) (alertdomain.MessageTemplate, error) {
+	return alertdomain.MessageTemplate{}, nil
+}
+func (m *mockAlertDAO) ListTemplates(_ context.Context, _ alertdomain.MessageTemplateFilter) ([]alertdomain.MessageTemplate, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteTemplate(_ context.Context, _ int64) error {
+	return nil
+}
+func (m *mockAlertDAO) InitIndexes(_ context.Context) error { return nil }
+
+// ========== Test Setup ==========
+
+func newTestService(dao *mockCommitmentDAO, accounts *mockAccountProvider, alertDAO *mockAlertDAO, adapter billing.BillingAdapter) *CommitmentService {
+	logger := elog.DefaultLogger
+	svc := NewCommitmentService(dao, accounts, alertservice.NewAlertService(alertDAO, logger), logger)
+	svc.adapterFor = func(_ *shareddomain.CloudAccount) (billing.BillingAdapter, error) {
+		return adapter, nil
+	}
+	return svc
+}
+
+func awsAccount() *shareddomain.CloudAccount {
+	return &shareddomain.CloudAccount{ID: 7, Provider: shareddomain.CloudProviderAWS, TenantID: "tenant1"}
+}
+
+// ========== Tests ==========
+
+func TestSyncAccount_StoresCommitmentsAndCoverage(t *testing.T) {
+	dao := &mockCommitmentDAO{}
+	adapter := &mockCommitmentAdapter{
+		commitments: []billing.RawCommitment{
+			{CommitmentID: "ri-1", Type: billing.CommitmentTypeReservedInstance, Region: "us-east-1", ServiceType: "compute", UtilizationPct: 75, EndTime: time.Now().AddDate(1, 0, 0), Status: "Active"},
+			{CommitmentID: "ri-old", Type: billing.CommitmentTypeReservedInstance, EndTime: time.Now().AddDate(0, 0, -1), Status: "Retired"},
+		},
+		coverage: []billing.RawCoverage{
+			{Region: "us-east-1", InstanceFamily: "m5", CoveredHours: 600, OnDemandHours: 200, Currency: "USD"},
+		},
+	}
+	svc := newTestService(dao, &mockAccountProvider{accounts: []*shareddomain.CloudAccount{awsAccount()}}, &mockAlertDAO{}, adapter)
+
+	count, err := svc.SyncAccount(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, syncWindowDays*24.0, adapter.params.EndTime.Sub(adapter.params.StartTime).Hours())
+
+	require.Len(t, dao.commitments, 2)
+	assert.Equal(t, "aws", dao.commitments[0].Provider)
+	assert.Equal(t, int64(7), dao.commitments[0].AccountID)
+	assert.Equal(t, "tenant1", dao.commitments[0].TenantID)
+	assert.Equal(t, domain.CommitmentStatusActive, dao.commitments[0].Status)
+	assert.Equal(t, "compute", dao.commitments[0].ServiceType)
+	assert.Equal(t, domain.CommitmentStatusExpired, dao.commitments[1].Status)
+	assert.Equal(t, "Retired", dao.commitments[1].ProviderStatus)
+
+	require.Len(t, dao.coverage, 1)
+	assert.Equal(t, int64(7), dao.coverage[0].AccountID)
+	assert.Equal(t, "m5", dao.coverage[0].InstanceFamily)
+}
+
+func TestSyncAccount_UnsupportedProvider(t *testing.T) {
+	svc := newTestService(&mockCommitmentDAO{}, &mockAccountProvider{accounts: []*shareddomain.CloudAccount{awsAccount()}}, &mockAlertDAO{}, billOnlyAdapter{})
+
+	_, err := svc.SyncAccount(context.Background(), 7)
+	assert.ErrorIs(t, err, domain.ErrCommitmentUnsupported)
+	assert.NoError(t, svc.SyncAll(context.Background(), ""), "不支持的云厂商在批量同步中跳过")
+}
+
+func TestUnsupportedProviders(t *testing.T) {
+	accounts := &mockAccountProvider{accounts: []*shareddomain.CloudAccount{
+		awsAccount(),
+		{ID: 8, Provider: shareddomain.CloudProviderTencent, TenantID: "tenant1"},
+		{ID: 9, Provider: shareddomain.CloudProviderTencent, TenantID: "tenant1"},
+		{ID: 10, Provider: shareddomain.CloudProviderHuawei, TenantID: "tenant1"},
+	}}
+	svc := newTestService(&mockCommitmentDAO{}, accounts, &mockAlertDAO{}, nil)
+	svc.supports = func(p shareddomain.CloudProvider) bool { return p == shareddomain.CloudProviderAWS }
+
+	providers, err := svc.UnsupportedProviders(context.Background(), "tenant1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"huawei", "tencent"}, providers)
+}
+
+func TestGetCoverageReport(t *testing.T) {
+	dao := &mockCommitmentDAO{coverage: []domain.CommitmentCoverage{
+		{AccountID: 1, Region: "us-east-1", InstanceFamily: "m5", CoveredHours: 600, OnDemandHours: 200, OnDemandCost: 20, Currency: "USD"},
+		{AccountID: 1, Region: "us-west-2", InstanceFamily: "m5", CoveredHours: 0, OnDemandHours: 200, OnDemandCost: 30, Currency: "USD"},
+		{AccountID: 2, Region: "us-east-1", InstanceFamily: "c5", CoveredHours: 100, OnDemandHours: 0, Currency: "USD"},
+	}}
+	svc := newTestService(dao, &mockAccountProvider{}, &mockAlertDAO{}, nil)
+
+	items, err := svc.GetCoverageReport(context.Background(), "tenant1", GroupByInstanceFamily, repository.CommitmentFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "m5", items[0].Key, "按需费用高的分组排在前面")
+	assert.InDelta(t, 60.0, items[0].CoveragePct, 0.001)
+	assert.Equal(t, 50.0, items[0].OnDemandCost)
+	assert.InDelta(t, 100.0, items[1].CoveragePct, 0.001)
+
+	items, err = svc.GetCoverageReport(context.Background(), "tenant1", GroupByAccount, repository.CommitmentFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "1", items[0].Key)
+
+	_, err = svc.GetCoverageReport(context.Background(), "tenant1", GroupByType, repository.CommitmentFilter{})
+	assert.Error(t, err, "覆盖率数据没有承诺类型维度")
+}
+
+func TestGetUtilizationReport(t *testing.T) {
+	now := time.Now()
+	dao := &mockCommitmentDAO{commitments: []domain.Commitment{
+		{Type: domain.CommitmentTypeReservedInstance, Status: domain.CommitmentStatusActive, PeriodCost: 300, UtilizationPct: 50, Currency: "USD", EndTime: now.AddDate(0, 0, 10)},
+		{Type: domain.CommitmentTypeReservedInstance, Status: domain.CommitmentStatusActive, PeriodCost: 100, UtilizationPct: 100, Currency: "USD", EndTime: now.AddDate(1, 0, 0)},
+		{Type: domain.CommitmentTypeSavingsPlan, Status: domain.CommitmentStatusActive, PeriodCost: 200, UtilizationPct: 90, Currency: "USD", EndTime: now.AddDate(1, 0, 0)},
+		{Type: domain.CommitmentTypeSavingsPlan, Status: domain.CommitmentStatusExpired, PeriodCost: 999, UtilizationPct: 0, Currency: "USD"},
+	}}
+	svc := newTestService(dao, &mockAccountProvider{}, &mockAlertDAO{}, nil)
+
+	items, err := svc.GetUtilizationReport(context.Background(), "tenant1", GroupByType, repository.CommitmentFilter{})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	ri := items[0]
+	assert.Equal(t, domain.CommitmentTypeReservedInstance, ri.Key)
+	assert.Equal(t, 2, ri.Count)
+	assert.InDelta(t, 62.5, ri.UtilizationPct, 0.001)
+	assert.InDelta(t, 150.0, ri.UnusedCost, 0.001)
+	assert.Equal(t, 1, ri.ExpiringSoon)
+
+	sp := items[1]
+	assert.Equal(t, 1, sp.Count, "已过期的承诺不参与统计")
+	assert.InDelta(t, 20.0, sp.UnusedCost, 0.001)
+}
+
+func TestCheckExpiring_NotifiesOnce(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	notifiedAt := now.AddDate(0, 0, -1)
+	dao := &mockCommitmentDAO{commitments: []domain.Commitment{
+		{ID: 1, Provider: "aws", CommitmentID: "ri-soon", Type: domain.CommitmentTypeReservedInstance, InstanceType: "m5.large", Quantity: 2, Status: domain.CommitmentStatusActive, EndTime: now.AddDate(0, 0, 5), TenantID: "tenant1"},
+		{ID: 2, Provider: "aws", CommitmentID: "sp-later", Type: domain.CommitmentTypeSavingsPlan, Status: domain.CommitmentStatusActive, EndTime: now.AddDate(0, 0, 20), TenantID: "tenant1"},
+		{ID: 3, Provider: "aws", CommitmentID: "sp-notified", Type: domain.CommitmentTypeSavingsPlan, Status: domain.CommitmentStatusActive, EndTime: now.AddDate(0, 0, 10), ExpiryNotifiedAt: &notifiedAt, TenantID: "tenant1"},
+		{ID: 4, Provider: "aws", CommitmentID: "ri-far", Type: domain.CommitmentTypeReservedInstance, Status: domain.CommitmentStatusActive, EndTime: now.AddDate(0, 2, 0), TenantID: "tenant1"},
+	}}
+	alertDAO := &mockAlertDAO{
+		listRulesFn: func(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+			return []alertdomain.AlertRule{{ID: 1, Type: filter.Type, Enabled: true}}, 1, nil
+		},
+	}
+	svc := newTestService(dao, &mockAccountProvider{}, alertDAO, nil)
+
+	count, err := svc.CheckExpiring(context.Background(), "tenant1", now)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Contains(t, dao.notified, int64(1))
+	assert.Contains(t, dao.notified, int64(2))
+	assert.NotContains(t, dao.notified, int64(3))
+
+	require.Len(t, alertDAO.emittedEvents, 2)
+	soon := alertDAO.emittedEvents[0]
+	assert.Equal(t, alertdomain.AlertTypeExpiration, soon.Type)
+	assert.Equal(t, alertdomain.SeverityCritical, soon.Severity)
+	assert.Equal(t, 5.0, soon.Content["days_left"])
+	assert.Equal(t, "m5.large x2", soon.Content["asset_name"])
+	assert.Equal(t, alertdomain.SeverityWarning, alertDAO.emittedEvents[1].Severity)
+}
@@ -2,22 +2,29 @@ package domain
 
 // CostAllocation 成本分摊结果
 type CostAllocation struct {
-	ID              int64   `bson:"id" json:"id"`
-	DimType         string  `bson:"dim_type" json:"dim_type"`
-	DimValue        string  `bson:"dim_value" json:"dim_value"`
-	DimPath         string  `bson:"dim_path" json:"dim_path"`
-	NodeID          int64   `bson:"node_id" json:"node_id"`
-	NodePath        string  `bson:"node_path" json:"node_path"`
-	Period          string  `bson:"period" json:"period"`
-	TotalAmount     float64 `bson:"total_amount" json:"total_amount"`
-	DirectAmount    float64 `bson:"direct_amount" json:"direct_amount"`
-	SharedAmount    float64 `bson:"shared_amount" json:"shared_amount"`
-	RatioAmount     float64 `bson:"ratio_amount" json:"ratio_amount"`
-	UnallocatedFlag bool    `bson:"unallocated_flag" json:"unallocated_flag"`
-	DefaultFlag     bool    `bson:"default_flag" json:"default_flag"`
-	RuleID          int64   `bson:"rule_id" json:"rule_id"`
-	TenantID        string  `bson:"tenant_id" json:"tenant_id"`
-	CreateTime      int64   `bson:"ctime" json:"ctime"`
+	ID              int64             `bson:"id" json:"id"`
+	DimType         string            `bson:"dim_type" json:"dim_type"`
+	DimValue        string            `bson:"dim_value" json:"dim_value"`
+	DimPath         string            `bson:"dim_path" json:"dim_path"`
+	NodeID          int64             `bson:"node_id" json:"node_id"`
+	NodePath        string            `bson:"node_path" json:"node_path"`
+	Period          string            `bson:"period" json:"period"`
+	TotalAmount     float64           `bson:"total_amount" json:"total_amount"`
+	DirectAmount    float64           `bson:"direct_amount" json:"direct_amount"`
+	SharedAmount    float64           `bson:"shared_amount" json:"shared_amount"`
+	RatioAmount     float64           `bson:"ratio_amount" json:"ratio_amount"`
+	Currency        string            `bson:"currency" json:"currency"` // 金额币种（分摊时的租户报表币种）
+	UnallocatedFlag bool              `bson:"unallocated_flag" json:"unallocated_flag"`
+	DefaultFlag     bool              `bson:"default_flag" json:"default_flag"`
+	RuleID          int64             `bson:"rule_id" json:"rule_id"`
+	TargetName      string            `bson:"target_name,omitempty" json:"target_name,omitempty"`     // 分摊目标名称（维度组合 / 默认策略）
+	ResourceID      string            `bson:"resource_id,omitempty" json:"resource_id,omitempty"`     // 来源账单资源 ID
+	ResourceName    string            `bson:"resource_name,omitempty" json:"resource_name,omitempty"` // 来源账单资源名称
+	ServiceType     string            `bson:"service_type,omitempty" json:"service_type,omitempty"`   // 来源账单服务类型
+	BillingDate     string            `bson:"billing_date,omitempty" json:"billing_date,omitempty"`   // 来源账单日期，用于按日统计节点成本
+	Labels          map[string]string `bson:"labels,omitempty" json:"labels,omitempty"`               // 容器分摊时工作负载的 K8s 标签
+	TenantID        string            `bson:"tenant_id" json:"tenant_id"`
+	CreateTime      int64             `bson:"ctime" json:"ctime"`
 }
 
 // AllocationRule 分摊规则
@@ -29,6 +36,7 @@ type AllocationRule struct {
 	TagKey          string           `bson:"tag_key" json:"tag_key"`
 	TagValueMap     map[string]int64 `bson:"tag_value_map" json:"tag_value_map"`
 	SharedConfig    *SharedConfig    `bson:"shared_config" json:"shared_config"`
+	UsageConfig     *UsageConfig     `bson:"usage_config,omitempty" json:"usage_config,omitempty"`
 	Priority        int              `bson:"priority" json:"priority"`
 	Status          string           `bson:"status" json:"status"`
 	TenantID        string           `bson:"tenant_id" json:"tenant_id"`
@@ -2,15 +2,60 @@ package domain
 
 // CostAnomaly 成本异常事件
 type CostAnomaly struct {
-	ID             int64   `bson:"id" json:"id"`
-	Dimension      string  `bson:"dimension" json:"dimension"`
-	DimensionValue string  `bson:"dimension_value" json:"dimension_value"`
-	AnomalyDate    string  `bson:"anomaly_date" json:"anomaly_date"`
-	ActualAmount   float64 `bson:"actual_amount" json:"actual_amount"`
-	BaselineAmount float64 `bson:"baseline_amount" json:"baseline_amount"`
-	DeviationPct   float64 `bson:"deviation_pct" json:"deviation_pct"`
-	Severity       string  `bson:"severity" json:"severity"`
-	PossibleCause  string  `bson:"possible_cause" json:"possible_cause"`
-	TenantID       string  `bson:"tenant_id" json:"tenant_id"`
-	CreateTime     int64   `bson:"ctime" json:"ctime"`
+	ID             int64                `bson:"id" json:"id"`
+	Dimension      string               `bson:"dimension" json:"dimension"`
+	DimensionValue string               `bson:"dimension_value" json:"dimension_value"`
+	AnomalyDate    string               `bson:"anomaly_date" json:"anomaly_date"`
+	ActualAmount   float64              `bson:"actual_amount" json:"actual_amount"`
+	BaselineAmount float64              `bson:"baseline_amount" json:"baseline_amount"`
+	ExpectedLower  float64              `bson:"expected_lower" json:"expected_lower"` // 模型给出的正常区间下界
+	ExpectedUpper  float64              `bson:"expected_upper" json:"expected_upper"` // 模型给出的正常区间上界
+	Model          string               `bson:"model" json:"model"`                   // 检测模型
+	Currency       string               `bson:"currency" json:"currency"`             // 金额币种（租户报表币种）
+	DeviationPct   float64              `bson:"deviation_pct" json:"deviation_pct"`
+	Severity       string               `bson:"severity" json:"severity"`
+	PossibleCause  string               `bson:"possible_cause" json:"possible_cause"`
+	Contributors   []AnomalyContributor `bson:"contributors" json:"contributors"` // 相对基线增量最大的资源 / 地域 / 计费类型 / 标签
+	TenantID       string               `bson:"tenant_id" json:"tenant_id"`
+	CreateTime     int64                `bson:"ctime" json:"ctime"`
+}
+
+// 异常贡献项类型
+const (
+	ContributorResource   = "resource"
+	ContributorRegion     = "region"
+	ContributorChargeType = "charge_type"
+	ContributorTag        = "tag"
+)
+
+// AnomalyContributor 异常贡献项
+type AnomalyContributor struct {
+	Type           string  `bson:"type" json:"type"`                       // resource / region / charge_type / tag
+	Key            string  `bson:"key" json:"key"`                         // 资源 ID、地域、计费类型或 "key=value" 标签
+	ActualAmount   float64 `bson:"actual_amount" json:"actual_amount"`     // 异常当日金额
+	BaselineAmount float64 `bson:"baseline_amount" json:"baseline_amount"` // 基线窗口日均金额
+	Delta          float64 `bson:"delta" json:"delta"`                     // 相对基线增量
+	SharePct       float64 `bson:"share_pct" json:"share_pct"`             // 占同类型总增量的百分比
+}
+
+// 异常检测模型
+const (
+	AnomalyModelMean     = "mean"       // 窗口日均值 + 固定偏离阈值
+	AnomalyModelSeasonal = "seasonal"   // 同星期几 / 月初季节性基线
+	AnomalyModelMAD      = "median_mad" // 滚动中位数 + MAD
+	AnomalyModelEWMA     = "ewma"       // 指数加权均值 + 置信带
+)
+
+// AnomalyModelConfig 租户按维度选择的异常检测模型
+// Dimension 为空表示该租户所有维度的默认模型
+type AnomalyModelConfig struct {
+	ID          int64   `bson:"id" json:"id"`
+	TenantID    string  `bson:"tenant_id" json:"tenant_id"`
+	Dimension   string  `bson:"dimension" json:"dimension"`
+	Model       string  `bson:"model" json:"model"`
+	Sensitivity float64 `bson:"sensitivity" json:"sensitivity"` // 置信带宽度（标准差倍数），0 使用默认值
+	Alpha       float64 `bson:"alpha" json:"alpha"`             // EWMA 平滑系数，0 使用默认值
+	WindowDays  int     `bson:"window_days" json:"window_days"` // 历史窗口天数，0 使用模型默认值
+	CreateTime  int64   `bson:"ctime" json:"ctime"`
+	UpdateTime  int64   `bson:"utime" json:"utime"`
 }
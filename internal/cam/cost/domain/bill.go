@@ -18,12 +18,29 @@ type UnifiedBill struct {
 	Amount          float64           `bson:"amount" json:"amount"`
 	Currency        string            `bson:"currency" json:"currency"`
 	AmountCNY       float64           `bson:"amount_cny" json:"amount_cny"`
+	ExchangeRate    float64           `bson:"exchange_rate" json:"exchange_rate"` // 折算人民币所用汇率
 	ChargeType      string            `bson:"charge_type" json:"charge_type"`
 	Tags            map[string]string `bson:"tags" json:"tags"`
 	TenantID        string            `bson:"tenant_id" json:"tenant_id"`
 	BillingDate     string            `bson:"billing_date" json:"billing_date"`
 	CreateTime      int64             `bson:"ctime" json:"ctime"`
 	UpdateTime      int64             `bson:"utime" json:"utime"`
+
+	// LineType 账单行类型：用量 / 预付费购买 / 退款 / 抵扣 / 摊销派生行
+	LineType string `bson:"line_type" json:"line_type"`
+	// 金额明细（原币种）：Amount 为云厂商账单金额，ListAmount 为官网原价，
+	// DiscountAmount 为折扣优惠，CreditAmount 为代金券 / 储值卡等抵扣，NetAmount 为实付净额
+	ListAmount     float64 `bson:"list_amount" json:"list_amount"`
+	DiscountAmount float64 `bson:"discount_amount" json:"discount_amount"`
+	CreditAmount   float64 `bson:"credit_amount" json:"credit_amount"`
+	NetAmount      float64 `bson:"net_amount" json:"net_amount"`
+	// AmortizedAmount 摊销金额（原币种），预付费购买行为 0，由摊销派生行按服务期承担
+	AmortizedAmount float64 `bson:"amortized_amount" json:"amortized_amount"`
+	// ServiceStart / ServiceEnd 预付费服务期（YYYY-MM-DD），非预付费为空
+	ServiceStart string `bson:"service_start,omitempty" json:"service_start,omitempty"`
+	ServiceEnd   string `bson:"service_end,omitempty" json:"service_end,omitempty"`
+	// AmortizedFrom 摊销派生行对应购买行的账单日期，重新采集该账期时一并删除
+	AmortizedFrom string `bson:"amortized_from,omitempty" json:"amortized_from,omitempty"`
 }
 
 // RawBillRecord 原始账单记录（审计用）
@@ -2,18 +2,56 @@ package domain
 
 import "time"
 
+// 预算周期
+const (
+	BudgetPeriodMonthly   = "monthly"   // 自然月
+	BudgetPeriodQuarterly = "quarterly" // 自然季度
+	BudgetPeriodYearly    = "yearly"    // 自然年
+	BudgetPeriodCustom    = "custom"    // 自定义日期区间（StartDate ~ EndDate，单周期）
+)
+
 // BudgetRule 预算规则
 type BudgetRule struct {
-	ID          int64                `bson:"id" json:"id"`
-	Name        string               `bson:"name" json:"name"`
-	AmountLimit float64              `bson:"amount_limit" json:"amount_limit"`
-	Period      string               `bson:"period" json:"period"`
-	ScopeType   string               `bson:"scope_type" json:"scope_type"`
-	ScopeValue  string               `bson:"scope_value" json:"scope_value"`
-	Thresholds  []float64            `bson:"thresholds" json:"thresholds"`
-	NotifiedAt  map[string]time.Time `bson:"notified_at" json:"notified_at"`
-	Status      string               `bson:"status" json:"status"`
-	TenantID    string               `bson:"tenant_id" json:"tenant_id"`
-	CreateTime  int64                `bson:"ctime" json:"ctime"`
-	UpdateTime  int64                `bson:"utime" json:"utime"`
+	ID                 int64                `bson:"id" json:"id"`
+	Name               string               `bson:"name" json:"name"`
+	AmountLimit        float64              `bson:"amount_limit" json:"amount_limit"`
+	Currency           string               `bson:"currency" json:"currency"` // 预算币种，为空时视为 CNY
+	Period             string               `bson:"period" json:"period"`
+	StartDate          string               `bson:"start_date" json:"start_date"` // 自定义周期开始日期 YYYY-MM-DD
+	EndDate            string               `bson:"end_date" json:"end_date"`     // 自定义周期结束日期 YYYY-MM-DD
+	Rollover           bool                 `bson:"rollover" json:"rollover"`     // 未用完的预算是否滚入下一周期
+	RolloverAmount     float64              `bson:"rollover_amount" json:"rollover_amount"`
+	CurrentPeriodStart string               `bson:"current_period_start" json:"current_period_start"` // 当前跟踪周期的开始日期
+	ScopeType          string               `bson:"scope_type" json:"scope_type"`
+	ScopeValue         string               `bson:"scope_value" json:"scope_value"`
+	Scopes             []DimensionFilter    `bson:"scopes" json:"scopes"` // 多条件范围，与 ScopeType/ScopeValue 同时生效（AND）
+	Thresholds         []float64            `bson:"thresholds" json:"thresholds"`
+	ForecastThresholds []float64            `bson:"forecast_thresholds" json:"forecast_thresholds"` // 基于周期末预测支出的阈值百分比
+	NotifiedAt         map[string]time.Time `bson:"notified_at" json:"notified_at"`
+	Status             string               `bson:"status" json:"status"`
+	TenantID           string               `bson:"tenant_id" json:"tenant_id"`
+	CreateTime         int64                `bson:"ctime" json:"ctime"`
+	UpdateTime         int64                `bson:"utime" json:"utime"`
+}
+
+// EffectiveLimit 当前周期可用额度（预算额度 + 上一周期滚入金额）
+func (b BudgetRule) EffectiveLimit() float64 {
+	return b.AmountLimit + b.RolloverAmount
+}
+
+// BudgetPeriodRecord 预算周期历史（每个已结束周期的实际支出与额度）
+type BudgetPeriodRecord struct {
+	ID           int64   `bson:"id" json:"id"`
+	BudgetID     int64   `bson:"budget_id" json:"budget_id"`
+	Period       string  `bson:"period" json:"period"`
+	PeriodStart  string  `bson:"period_start" json:"period_start"`
+	PeriodEnd    string  `bson:"period_end" json:"period_end"`
+	AmountLimit  float64 `bson:"amount_limit" json:"amount_limit"`
+	RolloverIn   float64 `bson:"rollover_in" json:"rollover_in"`   // 从上一周期滚入的金额
+	ActualSpend  float64 `bson:"actual_spend" json:"actual_spend"` // 周期实际支出
+	UsagePercent float64 `bson:"usage_percent" json:"usage_percent"`
+	RolloverOut  float64 `bson:"rollover_out" json:"rollover_out"` // 滚入下一周期的金额
+	Currency     string  `bson:"currency" json:"currency"`
+	TenantID     string  `bson:"tenant_id" json:"tenant_id"`
+	CreateTime   int64   `bson:"ctime" json:"ctime"`
 }
@@ -0,0 +1,72 @@
+package domain
+
+// 分账单对象类型
+const (
+	ChargebackTargetNode   = "node"   // 服务树节点（含子节点汇总）
+	ChargebackTargetTarget = "target" // 分摊目标（维度组合 / 默认策略的 target_id）
+)
+
+// ChargebackStatement 成本分账单（showback / chargeback）
+// 按 tenant_id + period + target_type + target_id 版本化存储，重新分摊后内容变化时生成新版本
+type ChargebackStatement struct {
+	ID         int64  `bson:"id" json:"id"`
+	Period     string `bson:"period" json:"period"` // YYYY-MM
+	TargetType string `bson:"target_type" json:"target_type"`
+	TargetID   string `bson:"target_id" json:"target_id"`
+	TargetName string `bson:"target_name" json:"target_name"`
+	Owner      string `bson:"owner,omitempty" json:"owner,omitempty"` // 服务树节点负责人
+	Version    int    `bson:"version" json:"version"`
+	Latest     bool   `bson:"latest" json:"latest"` // 是否为该对象当期的最新版本
+	Currency   string `bson:"currency" json:"currency"`
+
+	TotalAmount   float64 `bson:"total_amount" json:"total_amount"`
+	DirectAmount  float64 `bson:"direct_amount" json:"direct_amount"`   // 直接归属（标签映射）
+	SharedAmount  float64 `bson:"shared_amount" json:"shared_amount"`   // 共享资源按比例分摊
+	RatioAmount   float64 `bson:"ratio_amount" json:"ratio_amount"`     // 维度组合按比例分摊
+	DefaultAmount float64 `bson:"default_amount" json:"default_amount"` // 默认策略兜底归属
+
+	PrevTotalAmount float64 `bson:"prev_total_amount" json:"prev_total_amount"` // 上月总额
+	MoMDelta        float64 `bson:"mom_delta" json:"mom_delta"`                 // 环比变化额
+	MoMPct          float64 `bson:"mom_pct" json:"mom_pct"`                     // 环比变化率（%），上月为 0 时为 0
+
+	PeriodTotalAmount float64 `bson:"period_total_amount" json:"period_total_amount"` // 租户当期分摊总额
+	UnallocatedAmount float64 `bson:"unallocated_amount" json:"unallocated_amount"`   // 租户当期未分摊金额
+	UnallocatedPct    float64 `bson:"unallocated_pct" json:"unallocated_pct"`         // 未分摊占租户当期总额的比例（%）
+
+	TopResources []StatementResource `bson:"top_resources" json:"top_resources"`
+	Children     []StatementLine     `bson:"children,omitempty" json:"children,omitempty"` // 直接子节点小计
+	Diff         *StatementDiff      `bson:"diff,omitempty" json:"diff,omitempty"`         // 与上一版本的差异
+
+	Checksum          string  `bson:"checksum" json:"checksum"` // 内容摘要，用于判断重新生成时是否变化
+	DeliveredAt       int64   `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	DeliveredChannels []int64 `bson:"delivered_channels,omitempty" json:"delivered_channels,omitempty"`
+	TenantID          string  `bson:"tenant_id" json:"tenant_id"`
+	CreateTime        int64   `bson:"ctime" json:"ctime"`
+}
+
+// StatementResource 分账单资源明细
+type StatementResource struct {
+	ResourceID   string  `bson:"resource_id" json:"resource_id"`
+	ResourceName string  `bson:"resource_name" json:"resource_name"`
+	ServiceType  string  `bson:"service_type" json:"service_type"`
+	Amount       float64 `bson:"amount" json:"amount"`
+	PrevAmount   float64 `bson:"prev_amount" json:"prev_amount"` // 上月金额
+}
+
+// StatementLine 分账单小计行
+type StatementLine struct {
+	Key    string  `bson:"key" json:"key"`
+	Name   string  `bson:"name" json:"name"`
+	Amount float64 `bson:"amount" json:"amount"`
+}
+
+// StatementDiff 分账单版本差异（本版本 - 基准版本）
+type StatementDiff struct {
+	BaseVersion   int                 `bson:"base_version" json:"base_version"`
+	TotalDelta    float64             `bson:"total_delta" json:"total_delta"`
+	DirectDelta   float64             `bson:"direct_delta" json:"direct_delta"`
+	SharedDelta   float64             `bson:"shared_delta" json:"shared_delta"`
+	RatioDelta    float64             `bson:"ratio_delta" json:"ratio_delta"`
+	DefaultDelta  float64             `bson:"default_delta" json:"default_delta"`
+	ResourceDelta []StatementResource `bson:"resource_delta,omitempty" json:"resource_delta,omitempty"` // Amount 为本版本，PrevAmount 为基准版本
+}
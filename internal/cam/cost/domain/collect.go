@@ -17,4 +17,10 @@ type CollectLog struct {
 	ErrorMsg    string    `bson:"error_msg" json:"error_msg"`
 	TenantID    string    `bson:"tenant_id" json:"tenant_id"`
 	CreateTime  int64     `bson:"ctime" json:"ctime"`
+
+	// 账单文件导入（Source 为空表示计费 API 采集）
+	Source   string `bson:"source,omitempty" json:"source,omitempty"`
+	SourceID int64  `bson:"source_id,omitempty" json:"source_id,omitempty"`
+	FileName string `bson:"file_name,omitempty" json:"file_name,omitempty"`
+	Checksum string `bson:"checksum,omitempty" json:"checksum,omitempty"` // 文件 SHA-256，重复上传时跳过
 }
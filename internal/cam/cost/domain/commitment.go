@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// 承诺消费类型
+const (
+	CommitmentTypeReservedInstance = "reserved_instance" // 预留实例
+	CommitmentTypeSavingsPlan      = "savings_plan"      // 节省计划
+	CommitmentTypeResourcePackage  = "resource_package"  // 预付费资源包
+)
+
+// 承诺消费状态（按到期时间归一，厂商原始状态保存在 ProviderStatus）
+const (
+	CommitmentStatusActive  = "active"
+	CommitmentStatusExpired = "expired"
+)
+
+// Commitment 已购买的承诺消费（预留实例 / 节省计划 / 资源包）
+// 按 provider + commitment_id 唯一，每次同步覆盖利用率等统计字段
+type Commitment struct {
+	ID               int64      `bson:"id" json:"id"`
+	Provider         string     `bson:"provider" json:"provider"`
+	AccountID        int64      `bson:"account_id" json:"account_id"`
+	CommitmentID     string     `bson:"commitment_id" json:"commitment_id"` // 厂商侧实例 ID / ARN
+	Type             string     `bson:"type" json:"type"`
+	Region           string     `bson:"region" json:"region"`                   // 为空表示不限地域
+	InstanceFamily   string     `bson:"instance_family" json:"instance_family"` // 为空表示不限实例族
+	ServiceType      string     `bson:"service_type" json:"service_type"`       // 适用服务类别，为空表示不限服务
+	InstanceType     string     `bson:"instance_type" json:"instance_type"`
+	Quantity         float64    `bson:"quantity" json:"quantity"` // 预留实例数量 / 资源包总量
+	Unit             string     `bson:"unit" json:"unit"`
+	HourlyCommitment float64    `bson:"hourly_commitment" json:"hourly_commitment"` // 节省计划每小时承诺金额
+	UtilizationPct   float64    `bson:"utilization_pct" json:"utilization_pct"`     // 最近统计窗口利用率
+	PeriodCost       float64    `bson:"period_cost" json:"period_cost"`             // 最近统计窗口摊销费用
+	Currency         string     `bson:"currency" json:"currency"`
+	StartTime        time.Time  `bson:"start_time" json:"start_time"`
+	EndTime          time.Time  `bson:"end_time" json:"end_time"`
+	Status           string     `bson:"status" json:"status"`
+	ProviderStatus   string     `bson:"provider_status" json:"provider_status"`
+	ExpiryNotifiedAt *time.Time `bson:"expiry_notified_at" json:"expiry_notified_at"` // 到期提醒发送时间
+	TenantID         string     `bson:"tenant_id" json:"tenant_id"`
+	SyncTime         int64      `bson:"sync_time" json:"sync_time"`
+	CreateTime       int64      `bson:"ctime" json:"ctime"`
+}
+
+// CommitmentCoverage 计算用量覆盖情况（按账号 + 地域 + 实例族，每次同步整体替换）
+type CommitmentCoverage struct {
+	ID             int64   `bson:"id" json:"id"`
+	Provider       string  `bson:"provider" json:"provider"`
+	AccountID      int64   `bson:"account_id" json:"account_id"`
+	Region         string  `bson:"region" json:"region"`
+	InstanceFamily string  `bson:"instance_family" json:"instance_family"`
+	CoveredHours   float64 `bson:"covered_hours" json:"covered_hours"`
+	OnDemandHours  float64 `bson:"on_demand_hours" json:"on_demand_hours"`
+	OnDemandCost   float64 `bson:"on_demand_cost" json:"on_demand_cost"`
+	Currency       string  `bson:"currency" json:"currency"`
+	PeriodStart    string  `bson:"period_start" json:"period_start"` // YYYY-MM-DD
+	PeriodEnd      string  `bson:"period_end" json:"period_end"`     // YYYY-MM-DD（开区间）
+	TenantID       string  `bson:"tenant_id" json:"tenant_id"`
+	CreateTime     int64   `bson:"ctime" json:"ctime"`
+}
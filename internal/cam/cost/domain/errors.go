@@ -14,4 +14,17 @@ var (
 	ErrAllocationDimExceed    = errors.New("allocation rule exceeds max 5 dimension combos")
 	ErrAllocationDimInvalid   = errors.New("invalid allocation dimension type")
 	ErrNormalizeMissingField  = errors.New("required field missing in raw bill")
+	ErrExchangeRateNotFound   = errors.New("exchange rate not found")
+	ErrExchangeRateInvalid    = errors.New("invalid exchange rate")
+	ErrAnomalyModelInvalid    = errors.New("invalid anomaly detection model")
+	ErrForecastInvalid        = errors.New("invalid forecast request")
+	ErrCommitmentUnsupported  = errors.New("provider does not support commitment analysis")
+	ErrExportInvalid          = errors.New("invalid cost export request")
+	ErrImportSourceNotFound   = errors.New("bill import source not found")
+	ErrImportInvalid          = errors.New("invalid bill import file")
+	ErrReconcileInvalid       = errors.New("invalid bill reconciliation request")
+	ErrChargebackInvalid      = errors.New("invalid chargeback statement request")
+	ErrUsageInvalid           = errors.New("invalid allocation usage data")
+	ErrUnitCostInvalid        = errors.New("invalid unit cost request")
+	ErrK8sCostInvalid         = errors.New("invalid k8s cost request")
 )
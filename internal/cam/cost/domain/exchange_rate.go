@@ -0,0 +1,19 @@
+package domain
+
+// 汇率来源
+const (
+	ExchangeRateSourceManual = "manual" // 手工上传
+)
+
+// ExchangeRate 汇率记录（每个币种对每日一条）
+// Rate 表示 1 单位 BaseCurrency 兑换的 QuoteCurrency 数量
+type ExchangeRate struct {
+	ID            int64   `bson:"id" json:"id"`
+	BaseCurrency  string  `bson:"base_currency" json:"base_currency"`
+	QuoteCurrency string  `bson:"quote_currency" json:"quote_currency"`
+	Date          string  `bson:"date" json:"date"` // YYYY-MM-DD
+	Rate          float64 `bson:"rate" json:"rate"`
+	Source        string  `bson:"source" json:"source"`
+	CreateTime    int64   `bson:"ctime" json:"ctime"`
+	UpdateTime    int64   `bson:"utime" json:"utime"`
+}
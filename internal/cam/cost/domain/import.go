@@ -0,0 +1,57 @@
+package domain
+
+// 账单导入文件格式
+const (
+	ImportFormatCUR  = "cur"  // AWS Cost and Usage Report（CSV，支持 gzip 压缩）
+	ImportFormatCSV  = "csv"  // 代理商 CSV 对账单
+	ImportFormatXLSX = "xlsx" // 代理商 Excel 对账单
+)
+
+// 采集来源
+const (
+	CollectSourceAPI    = "api"    // 云厂商计费 API（默认）
+	CollectSourceImport = "import" // 账单文件导入
+)
+
+// BillImportSource 账单导入来源（代理商对账单、CUR 文件等），列映射按来源配置
+// 一个来源对应一个离线 / 代理商云账号，导入文件需包含所涉月份该账号的完整账单
+type BillImportSource struct {
+	ID         int64         `bson:"id" json:"id"`
+	Name       string        `bson:"name" json:"name"`
+	AccountID  int64         `bson:"account_id" json:"account_id"`
+	Provider   string        `bson:"provider" json:"provider"` // 账单对应的云厂商，为空时取云账号的厂商
+	Format     string        `bson:"format" json:"format"`     // cur / csv / xlsx
+	Mapping    ColumnMapping `bson:"mapping" json:"mapping"`
+	Sheet      string        `bson:"sheet" json:"sheet"`         // xlsx 工作表名，默认第一个
+	Encoding   string        `bson:"encoding" json:"encoding"`   // csv 文件编码：utf-8（默认）/ gbk
+	Dir        string        `bson:"dir" json:"dir"`             // 目录投递：投递根目录下本租户目录内的相对路径，定时扫描新文件
+	TenantID   string        `bson:"tenant_id" json:"tenant_id"` // 租户 ID
+	CreateTime int64         `bson:"ctime" json:"ctime"`
+	UpdateTime int64         `bson:"utime" json:"utime"`
+}
+
+// ColumnMapping 列映射，值为文件表头中的列名
+type ColumnMapping struct {
+	BillingDate     string `bson:"billing_date" json:"billing_date"`         // 账期或账单日期列
+	DateLayout      string `bson:"date_layout" json:"date_layout"`           // 日期格式（Go layout），为空时自动识别
+	ServiceType     string `bson:"service_type" json:"service_type"`         // 产品 / 服务列
+	ResourceID      string `bson:"resource_id" json:"resource_id"`           // 资源 ID 列
+	ResourceName    string `bson:"resource_name" json:"resource_name"`       // 资源名称列
+	Region          string `bson:"region" json:"region"`                     // 地域列
+	Amount          string `bson:"amount" json:"amount"`                     // 应付金额列（必填）
+	Currency        string `bson:"currency" json:"currency"`                 // 币种列
+	DefaultCurrency string `bson:"default_currency" json:"default_currency"` // 文件无币种列时使用
+	TagPrefix       string `bson:"tag_prefix" json:"tag_prefix"`             // 以该前缀开头的列作为资源标签
+}
+
+// CURColumnMapping AWS CUR 默认列映射，来源未配置的列使用该映射
+var CURColumnMapping = ColumnMapping{
+	BillingDate:     "lineItem/UsageStartDate",
+	ServiceType:     "lineItem/ProductCode",
+	ResourceID:      "lineItem/ResourceId",
+	Region:          "product/region",
+	Amount:          "lineItem/UnblendedCost",
+	Currency:        "lineItem/CurrencyCode",
+	DefaultCurrency: "USD",
+	TagPrefix:       "resourceTags/user:",
+}
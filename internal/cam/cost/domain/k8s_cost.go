@@ -0,0 +1,75 @@
+package domain
+
+const (
+	// DimK8s 容器成本分摊维度，DimPath 为 集群/命名空间/工作负载
+	DimK8s = "k8s"
+	// K8sIdleWorkload 集群空闲容量桶，DimPath 为 集群/__idle__
+	K8sIdleWorkload = "__idle__"
+)
+
+// K8s 成本汇总方式
+const (
+	K8sGroupByCluster   = "cluster"
+	K8sGroupByNamespace = "namespace"
+	K8sGroupByWorkload  = "workload"
+	K8sGroupByLabel     = "label"
+)
+
+// K8sNodeMetrics K8s 节点在账期内的资源容量与 Pod 用量（按租户、账期、集群、节点唯一）
+// CPU 单位为核，内存单位为 GiB；取账期内的平均值
+type K8sNodeMetrics struct {
+	ID             int64           `bson:"id" json:"id"`
+	Cluster        string          `bson:"cluster" json:"cluster"`
+	NodeName       string          `bson:"node_name" json:"node_name"`
+	ResourceID     string          `bson:"resource_id" json:"resource_id"` // 节点对应的云主机实例 ID（UnifiedBill.ResourceID）
+	Period         string          `bson:"period" json:"period"`           // YYYY-MM
+	CPUCapacity    float64         `bson:"cpu_capacity" json:"cpu_capacity"`
+	MemoryCapacity float64         `bson:"memory_capacity" json:"memory_capacity"`
+	Pods           []K8sPodMetrics `bson:"pods" json:"pods"`
+	TenantID       string          `bson:"tenant_id" json:"tenant_id"`
+	CreateTime     int64           `bson:"ctime" json:"ctime"`
+	UpdateTime     int64           `bson:"utime" json:"utime"`
+}
+
+// K8sPodMetrics Pod 的资源申请量与实际用量
+type K8sPodMetrics struct {
+	Namespace     string            `bson:"namespace" json:"namespace"`
+	Name          string            `bson:"name" json:"name"`
+	Workload      string            `bson:"workload" json:"workload"`           // 所属工作负载名，为空时按 Pod 名计
+	WorkloadKind  string            `bson:"workload_kind" json:"workload_kind"` // Deployment / StatefulSet / DaemonSet / Job
+	Labels        map[string]string `bson:"labels,omitempty" json:"labels,omitempty"`
+	CPURequest    float64           `bson:"cpu_request" json:"cpu_request"`
+	CPUUsage      float64           `bson:"cpu_usage" json:"cpu_usage"`
+	MemoryRequest float64           `bson:"memory_request" json:"memory_request"`
+	MemoryUsage   float64           `bson:"memory_usage" json:"memory_usage"`
+}
+
+// ContainerShare 节点成本拆分到工作负载（或空闲容量）的份额
+type ContainerShare struct {
+	Cluster      string            `json:"cluster"`
+	NodeName     string            `json:"node_name"`
+	Namespace    string            `json:"namespace"` // 空闲容量为空
+	Workload     string            `json:"workload"`  // 空闲容量为 K8sIdleWorkload
+	WorkloadKind string            `json:"workload_kind"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Fraction     float64           `json:"fraction"` // 占节点成本的比例（0~1），同一节点各份额之和为 1
+	Idle         bool              `json:"idle"`
+}
+
+// K8sCostReport 容器成本汇总
+type K8sCostReport struct {
+	Period      string         `json:"period"`
+	GroupBy     string         `json:"group_by"`
+	LabelKey    string         `json:"label_key,omitempty"`
+	Currency    string         `json:"currency"`
+	TotalAmount float64        `json:"total_amount"` // K8s 节点总成本（含空闲容量）
+	IdleAmount  float64        `json:"idle_amount"`  // 空闲容量成本，单独列示不计入分组
+	Groups      []K8sCostGroup `json:"groups"`
+}
+
+// K8sCostGroup 容器成本分组小计
+type K8sCostGroup struct {
+	Key    string  `json:"key"` // 分组键；按标签汇总时无该标签的工作负载归入空键
+	Amount float64 `json:"amount"`
+	Pct    float64 `json:"pct"` // 占 K8s 节点总成本的比例（%）
+}
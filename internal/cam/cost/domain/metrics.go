@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ResourceMetricDaily 资源日聚合监控指标缓存
+// 按 resource_id + date 唯一，由指标同步任务写入，expire_at 到期后由 TTL 索引自动清理
+type ResourceMetricDaily struct {
+	ID            int64     `bson:"id" json:"id"`
+	TenantID      string    `bson:"tenant_id" json:"tenant_id"`
+	AccountID     int64     `bson:"account_id" json:"account_id"`
+	Provider      string    `bson:"provider" json:"provider"`
+	Region        string    `bson:"region" json:"region"`
+	ResourceType  string    `bson:"resource_type" json:"resource_type"` // ecs / rds / redis
+	ResourceID    string    `bson:"resource_id" json:"resource_id"`
+	Date          string    `bson:"date" json:"date"` // YYYY-MM-DD
+	CPUAvg        float64   `bson:"cpu_avg" json:"cpu_avg"`
+	CPUMax        float64   `bson:"cpu_max" json:"cpu_max"`
+	MemoryAvg     float64   `bson:"memory_avg" json:"memory_avg"`
+	MemoryMax     float64   `bson:"memory_max" json:"memory_max"`
+	HasMemory     bool      `bson:"has_memory" json:"has_memory"`
+	NetworkInBps  float64   `bson:"network_in_bps" json:"network_in_bps"`   // 字节/秒
+	NetworkOutBps float64   `bson:"network_out_bps" json:"network_out_bps"` // 字节/秒
+	DiskReadIOPS  float64   `bson:"disk_read_iops" json:"disk_read_iops"`
+	DiskWriteIOPS float64   `bson:"disk_write_iops" json:"disk_write_iops"`
+	ExpireAt      time.Time `bson:"expire_at" json:"expire_at"`
+	SyncTime      int64     `bson:"sync_time" json:"sync_time"`
+	CreateTime    int64     `bson:"ctime" json:"ctime"`
+}
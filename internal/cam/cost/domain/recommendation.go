@@ -4,19 +4,41 @@ import "time"
 
 // Recommendation 优化建议
 type Recommendation struct {
-	ID              int64      `bson:"id" json:"id"`
-	Type            string     `bson:"type" json:"type"`
-	Provider        string     `bson:"provider" json:"provider"`
-	AccountID       int64      `bson:"account_id" json:"account_id"`
-	ResourceID      string     `bson:"resource_id" json:"resource_id"`
-	ResourceName    string     `bson:"resource_name" json:"resource_name"`
-	Region          string     `bson:"region" json:"region"`
-	Reason          string     `bson:"reason" json:"reason"`
-	EstimatedSaving float64    `bson:"estimated_saving" json:"estimated_saving"`
-	Status          string     `bson:"status" json:"status"`
-	DismissedAt     *time.Time `bson:"dismissed_at" json:"dismissed_at"`
-	DismissExpiry   *time.Time `bson:"dismiss_expiry" json:"dismiss_expiry"`
-	TenantID        string     `bson:"tenant_id" json:"tenant_id"`
-	CreateTime      int64      `bson:"ctime" json:"ctime"`
-	UpdateTime      int64      `bson:"utime" json:"utime"`
+	ID              int64   `bson:"id" json:"id"`
+	Type            string  `bson:"type" json:"type"`
+	Provider        string  `bson:"provider" json:"provider"`
+	AccountID       int64   `bson:"account_id" json:"account_id"`
+	ResourceID      string  `bson:"resource_id" json:"resource_id"`
+	ResourceName    string  `bson:"resource_name" json:"resource_name"`
+	Region          string  `bson:"region" json:"region"`
+	Reason          string  `bson:"reason" json:"reason"`
+	EstimatedSaving float64 `bson:"estimated_saving" json:"estimated_saving"`
+	// Rightsizing 降配目标规格（仅降配建议且能确定目标规格时存在）
+	Rightsizing   *RightsizingDetail `bson:"rightsizing,omitempty" json:"rightsizing,omitempty"`
+	Status        string             `bson:"status" json:"status"`
+	DismissedAt   *time.Time         `bson:"dismissed_at" json:"dismissed_at"`
+	DismissExpiry *time.Time         `bson:"dismiss_expiry" json:"dismiss_expiry"`
+	TenantID      string             `bson:"tenant_id" json:"tenant_id"`
+	CreateTime    int64              `bson:"ctime" json:"ctime"`
+	UpdateTime    int64              `bson:"utime" json:"utime"`
+}
+
+// RightsizingDetail 降配目标规格与价差
+type RightsizingDetail struct {
+	Kind                string  `bson:"kind" json:"kind"` // ecs / rds / redis
+	CurrentType         string  `bson:"current_type" json:"current_type"`
+	TargetType          string  `bson:"target_type" json:"target_type"`
+	SameFamily          bool    `bson:"same_family" json:"same_family"` // 目标规格是否与当前规格同族
+	CurrentCPU          int     `bson:"current_cpu" json:"current_cpu"`
+	TargetCPU           int     `bson:"target_cpu" json:"target_cpu"`
+	CurrentMemoryGB     float64 `bson:"current_memory_gb" json:"current_memory_gb"`
+	TargetMemoryGB      float64 `bson:"target_memory_gb" json:"target_memory_gb"`
+	PeakCPUPct          float64 `bson:"peak_cpu_pct" json:"peak_cpu_pct"`                   // 观测窗口内 CPU 峰值
+	PeakMemoryPct       float64 `bson:"peak_memory_pct" json:"peak_memory_pct"`             // 观测窗口内内存峰值，无监控数据时为 0
+	ProjectedCPUPct     float64 `bson:"projected_cpu_pct" json:"projected_cpu_pct"`         // 按峰值折算到目标规格的 CPU 利用率
+	ProjectedMemoryPct  float64 `bson:"projected_memory_pct" json:"projected_memory_pct"`   // 按峰值折算到目标规格的内存利用率
+	CurrentMonthlyPrice float64 `bson:"current_monthly_price" json:"current_monthly_price"` // 价格目录月价
+	TargetMonthlyPrice  float64 `bson:"target_monthly_price" json:"target_monthly_price"`
+	MonthlyPriceDelta   float64 `bson:"monthly_price_delta" json:"monthly_price_delta"` // 当前月价 - 目标月价
+	Currency            string  `bson:"currency" json:"currency"`                       // 价格目录币种
 }
@@ -0,0 +1,30 @@
+package domain
+
+// 对账状态
+const (
+	ReconcileStatusMatched = "matched" // 差异在容差范围内
+	ReconcileStatusDrift   = "drift"   // 差异超出容差
+	ReconcileStatusFailed  = "failed"  // 账单总览拉取失败或币种不一致
+)
+
+// BillReconciliation 账单对账记录：采集明细合计与厂商账单总览的差异
+// 按 account_id + billing_month 唯一，每次对账覆盖
+type BillReconciliation struct {
+	ID             int64   `bson:"id" json:"id"`
+	AccountID      int64   `bson:"account_id" json:"account_id"`
+	AccountName    string  `bson:"account_name" json:"account_name"`
+	Provider       string  `bson:"provider" json:"provider"`
+	BillingMonth   string  `bson:"billing_month" json:"billing_month"`   // YYYY-MM
+	DetailAmount   float64 `bson:"detail_amount" json:"detail_amount"`   // 统一账单明细合计（账单口径，原币种）
+	InvoiceAmount  float64 `bson:"invoice_amount" json:"invoice_amount"` // 厂商账单总览应付总额
+	Drift          float64 `bson:"drift" json:"drift"`                   // 明细合计 - 账单总览
+	DriftPct       float64 `bson:"drift_pct" json:"drift_pct"`           // 差异占账单总览的百分比（绝对值）
+	Currency       string  `bson:"currency" json:"currency"`
+	Status         string  `bson:"status" json:"status"`
+	ErrorMsg       string  `bson:"error_msg,omitempty" json:"error_msg,omitempty"`
+	Alerted        bool    `bson:"alerted" json:"alerted"`                 // 当前差异已发送告警，差异消除后重置
+	RecollectCount int     `bson:"recollect_count" json:"recollect_count"` // 因对账差异触发的重新采集次数
+	TenantID       string  `bson:"tenant_id" json:"tenant_id"`
+	CheckTime      int64   `bson:"check_time" json:"check_time"`
+	CreateTime     int64   `bson:"ctime" json:"ctime"`
+}
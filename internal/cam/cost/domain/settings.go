@@ -0,0 +1,10 @@
+package domain
+
+// CostSettings 租户级成本设置
+type CostSettings struct {
+	TenantID string `bson:"tenant_id" json:"tenant_id"`
+	// ReportingCurrency 报表币种，成本汇总、预算、异常基线与分摊结果均以该币种表示，默认 CNY
+	ReportingCurrency string `bson:"reporting_currency" json:"reporting_currency"`
+	CreateTime        int64  `bson:"ctime" json:"ctime"`
+	UpdateTime        int64  `bson:"utime" json:"utime"`
+}
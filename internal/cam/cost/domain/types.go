@@ -6,6 +6,8 @@ type CloudProvider string
 const (
 	CloudProviderAliyun  CloudProvider = "aliyun"  // 阿里云
 	CloudProviderAWS     CloudProvider = "aws"     // Amazon Web Services
+	CloudProviderAzure   CloudProvider = "azure"   // Microsoft Azure
+	CloudProviderGCP     CloudProvider = "gcp"     // Google Cloud
 	CloudProviderVolcano CloudProvider = "volcano" // 火山引擎
 	CloudProviderHuawei  CloudProvider = "huawei"  // 华为云
 	CloudProviderTencent CloudProvider = "tencent" // 腾讯云
@@ -21,6 +23,29 @@ const (
 	ServiceTypeOther      = "other"      // 其他
 )
 
+// ChargeType 计费方式常量
+const (
+	ChargeTypePrepaid  = "prepaid"  // 包年包月
+	ChargeTypePostpaid = "postpaid" // 按量付费
+	ChargeTypeReserved = "reserved" // 预留实例
+)
+
+// LineType 账单行类型常量
+const (
+	LineTypeUsage        = "usage"        // 用量消费
+	LineTypePurchase     = "purchase"     // 预付费购买（新购 / 续费）
+	LineTypeRefund       = "refund"       // 退款 / 退订
+	LineTypeCredit       = "credit"       // 代金券 / 信用抵扣等负向调整
+	LineTypeAmortization = "amortization" // 预付费按服务期摊销的派生行，仅计入摊销视图
+)
+
+// CostView 成本视图常量
+const (
+	CostViewBilled    = "billed"    // 账单金额（默认）
+	CostViewAmortized = "amortized" // 摊销金额：预付费按服务期分摊到各月
+	CostViewNet       = "net"       // 净额：扣除折扣与代金券等抵扣后的实付金额
+)
+
 // AllocationDimensionType 分摊维度类型常量
 const (
 	DimDepartment    = "department"     // 部门
@@ -30,4 +55,5 @@ const (
 	DimCloudAccount  = "cloud_account"  // 云账号
 	DimRegion        = "region"         // 地域
 	DimServiceType   = "service_type"   // 服务类型
+	DimProvider      = "provider"       // 云厂商（仅用于预算范围）
 )
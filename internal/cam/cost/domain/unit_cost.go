@@ -0,0 +1,34 @@
+package domain
+
+// BusinessMetricPoint 业务指标数据点（按租户、指标名、服务树节点、日期唯一）
+// 如订单数、活跃用户数、API 调用量，用于计算单位成本
+type BusinessMetricPoint struct {
+	ID         int64   `bson:"id" json:"id"`
+	Metric     string  `bson:"metric" json:"metric"`   // 指标名，如 orders、active_users、api_calls
+	NodeID     int64   `bson:"node_id" json:"node_id"` // 服务树节点
+	Date       string  `bson:"date" json:"date"`       // YYYY-MM-DD
+	Value      float64 `bson:"value" json:"value"`
+	TenantID   string  `bson:"tenant_id" json:"tenant_id"`
+	CreateTime int64   `bson:"ctime" json:"ctime"`
+	UpdateTime int64   `bson:"utime" json:"utime"`
+}
+
+// UnitCostPoint 单日单位成本
+type UnitCostPoint struct {
+	Date        string  `json:"date"`
+	Cost        float64 `json:"cost"`
+	MetricValue float64 `json:"metric_value"`
+	UnitCost    float64 `json:"unit_cost"` // 业务量为 0 时为 0
+}
+
+// UnitCostTrend 服务树节点（含子节点）的单位成本趋势
+type UnitCostTrend struct {
+	NodeID      int64           `json:"node_id"`
+	NodeName    string          `json:"node_name"`
+	Metric      string          `json:"metric"`
+	Currency    string          `json:"currency"`
+	TotalCost   float64         `json:"total_cost"`
+	TotalMetric float64         `json:"total_metric"`
+	UnitCost    float64         `json:"unit_cost"` // 区间总成本 / 区间总业务量
+	Points      []UnitCostPoint `json:"points"`
+}
@@ -0,0 +1,58 @@
+package domain
+
+// 用量数据源
+const (
+	UsageSourceK8sNamespace = "k8s_namespace" // K8s 命名空间指标（如 CPU 核时），由外部采集推送
+	UsageSourceTopoEdge     = "topo_edge"     // 拓扑调用边请求量（TopoEdge.RequestCount）
+	UsageSourceCSV          = "csv"           // 上传的用量 CSV
+)
+
+// UsageConfig 按用量加权分摊配置（rule_type = usage_weighted）
+// 每个账期按数据源中各消费方的实际用量重新计算权重
+type UsageConfig struct {
+	ResourceIDs    []string          `bson:"resource_ids" json:"resource_ids"`                           // 共享资源（账单 ResourceID）
+	Source         string            `bson:"source" json:"source"`                                       // 用量数据源
+	Subject        string            `bson:"subject" json:"subject"`                                     // 数据源中的共享对象：K8s 集群、拓扑节点 ID 或 CSV 数据集名
+	Metric         string            `bson:"metric,omitempty" json:"metric,omitempty"`                   // 用量指标（如 cpu_hours、bytes），为空时不过滤
+	ConsumerMap    map[string]int64  `bson:"consumer_map" json:"consumer_map"`                           // 消费方（命名空间 / 调用方节点 / CSV 消费方）→ 服务树节点
+	FallbackRatios map[int64]float64 `bson:"fallback_ratios,omitempty" json:"fallback_ratios,omitempty"` // 当期无用量数据时的静态比例，为空则规则不匹配
+}
+
+// UsageRecord 用量记录（上传的 CSV 或外部推送的 K8s 命名空间指标）
+type UsageRecord struct {
+	ID         int64   `bson:"id" json:"id"`
+	Source     string  `bson:"source" json:"source"`
+	Subject    string  `bson:"subject" json:"subject"`
+	Consumer   string  `bson:"consumer" json:"consumer"`
+	Metric     string  `bson:"metric" json:"metric"`
+	Period     string  `bson:"period" json:"period"` // YYYY-MM
+	Value      float64 `bson:"value" json:"value"`
+	TenantID   string  `bson:"tenant_id" json:"tenant_id"`
+	CreateTime int64   `bson:"ctime" json:"ctime"`
+}
+
+// AllocationUsageWeights 用量加权规则在某账期实际采用的权重快照，用于审计
+type AllocationUsageWeights struct {
+	ID            int64         `bson:"id" json:"id"`
+	RuleID        int64         `bson:"rule_id" json:"rule_id"`
+	RuleName      string        `bson:"rule_name" json:"rule_name"`
+	Period        string        `bson:"period" json:"period"`
+	Source        string        `bson:"source" json:"source"`
+	Subject       string        `bson:"subject" json:"subject"`
+	Metric        string        `bson:"metric,omitempty" json:"metric,omitempty"`
+	TotalUsage    float64       `bson:"total_usage" json:"total_usage"`
+	UnmappedUsage float64       `bson:"unmapped_usage" json:"unmapped_usage"` // 未映射到服务树节点的用量，对应份额计入未分摊
+	Fallback      bool          `bson:"fallback" json:"fallback"`             // 当期无用量数据，使用静态比例
+	Error         string        `bson:"error,omitempty" json:"error,omitempty"`
+	Weights       []UsageWeight `bson:"weights" json:"weights"`
+	TenantID      string        `bson:"tenant_id" json:"tenant_id"`
+	CreateTime    int64         `bson:"ctime" json:"ctime"`
+}
+
+// UsageWeight 单个消费方的用量与权重
+type UsageWeight struct {
+	Consumer string  `bson:"consumer" json:"consumer"` // 使用静态比例时为空
+	NodeID   int64   `bson:"node_id" json:"node_id"`   // 0 表示未映射
+	Usage    float64 `bson:"usage" json:"usage"`
+	Weight   float64 `bson:"weight" json:"weight"` // 百分比
+}
@@ -0,0 +1,143 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// rateCacheTTL 报表币种汇率缓存有效期（汇率修正后最多延迟该时长生效）
+const rateCacheTTL = 10 * time.Minute
+
+// ReportingConverter 报表币种换算接口
+// 成本金额以人民币入库，查询时按账单日期汇率折算为租户报表币种
+type ReportingConverter interface {
+	// ReportingCurrency 获取租户报表币种，未设置时为 CNY
+	ReportingCurrency(ctx context.Context, tenantID string) (string, error)
+	// FromCNY 按 date 当日汇率将人民币金额折算为 currency
+	FromCNY(ctx context.Context, currency string, amountCNY float64, date string) (float64, error)
+}
+
+// rateGetter 汇率查询（由 ExchangeRateService 实现）
+type rateGetter interface {
+	GetRate(ctx context.Context, from, to, date string) (float64, error)
+	LatestRate(ctx context.Context, from, to, date string) (float64, error)
+}
+
+// cachedConvRate 缓存的折算汇率
+type cachedConvRate struct {
+	rate     float64
+	expireAt time.Time
+}
+
+// Converter 报表币种换算器
+type Converter struct {
+	rates       rateGetter
+	settingsDAO repository.CostSettingsDAO
+
+	mu    sync.Mutex
+	cache map[string]cachedConvRate
+}
+
+// NewConverter 创建报表币种换算器
+func NewConverter(rates *ExchangeRateService, settingsDAO repository.CostSettingsDAO) *Converter {
+	return newConverter(rates, settingsDAO)
+}
+
+func newConverter(rates rateGetter, settingsDAO repository.CostSettingsDAO) *Converter {
+	return &Converter{
+		rates:       rates,
+		settingsDAO: settingsDAO,
+		cache:       make(map[string]cachedConvRate),
+	}
+}
+
+// ReportingCurrency 获取租户报表币种，未设置时为 CNY
+func (c *Converter) ReportingCurrency(ctx context.Context, tenantID string) (string, error) {
+	if tenantID == "" {
+		return CurrencyCNY, nil
+	}
+	settings, err := c.settingsDAO.Get(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return CurrencyCNY, nil
+		}
+		return "", fmt.Errorf("get cost settings: %w", err)
+	}
+	if settings.ReportingCurrency == "" {
+		return CurrencyCNY, nil
+	}
+	return settings.ReportingCurrency, nil
+}
+
+// SetReportingCurrency 设置租户报表币种
+// 非人民币币种须已有兑人民币汇率，避免切换后报表无法折算
+func (c *Converter) SetReportingCurrency(ctx context.Context, tenantID, currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if tenantID == "" {
+		return fmt.Errorf("tenant_id is required")
+	}
+	if !currencyPattern.MatchString(currency) {
+		return fmt.Errorf("%w: currency should be ISO 4217 code, got %q", costdomain.ErrExchangeRateInvalid, currency)
+	}
+	if currency != CurrencyCNY {
+		if _, err := c.rates.GetRate(ctx, CurrencyCNY, currency, time.Now().Format("2006-01-02")); err != nil {
+			return err
+		}
+	}
+	return c.settingsDAO.Upsert(ctx, costdomain.CostSettings{
+		TenantID:          tenantID,
+		ReportingCurrency: currency,
+	})
+}
+
+// FromCNY 按 date 当日汇率将人民币金额折算为 currency
+// 当日汇率缺失或过旧时回退到不晚于 date 的最近汇率，仍没有则使用当前最新汇率，
+// 避免个别日期缺汇率导致整个报表查询失败；该币种从未录入汇率时返回错误
+func (c *Converter) FromCNY(ctx context.Context, currency string, amountCNY float64, date string) (float64, error) {
+	if currency == "" || currency == CurrencyCNY || amountCNY == 0 {
+		return amountCNY, nil
+	}
+	rate, err := c.rate(ctx, currency, date)
+	if err != nil {
+		return 0, err
+	}
+	return amountCNY * rate, nil
+}
+
+// rate 查询人民币兑 currency 的日汇率，带短期缓存（报表查询会对同一日期反复折算）
+func (c *Converter) rate(ctx context.Context, currency, date string) (float64, error) {
+	key := currency + "@" + date
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expireAt) {
+		return cached.rate, nil
+	}
+
+	rate, err := c.rates.GetRate(ctx, CurrencyCNY, currency, date)
+	if errors.Is(err, costdomain.ErrExchangeRateNotFound) {
+		rate, err = c.rates.LatestRate(ctx, CurrencyCNY, currency, date)
+	}
+	if errors.Is(err, costdomain.ErrExchangeRateNotFound) {
+		// date 早于第一条汇率
+		rate, err = c.rates.LatestRate(ctx, CurrencyCNY, currency, now.Format("2006-01-02"))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	c.cache[key] = cachedConvRate{rate: rate, expireAt: now.Add(rateCacheTTL)}
+	c.mu.Unlock()
+	return rate, nil
+}
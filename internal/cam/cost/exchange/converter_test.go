@@ -0,0 +1,109 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockSettingsDAO 内存成本设置
+type mockSettingsDAO struct {
+	settings map[string]costdomain.CostSettings
+}
+
+func (m *mockSettingsDAO) Get(_ context.Context, tenantID string) (costdomain.CostSettings, error) {
+	s, ok := m.settings[tenantID]
+	if !ok {
+		return costdomain.CostSettings{}, mongo.ErrNoDocuments
+	}
+	return s, nil
+}
+
+func (m *mockSettingsDAO) Upsert(_ context.Context, settings costdomain.CostSettings) error {
+	if m.settings == nil {
+		m.settings = make(map[string]costdomain.CostSettings)
+	}
+	m.settings[settings.TenantID] = settings
+	return nil
+}
+
+func TestConverter_ReportingCurrency(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	svc, _, _ := newTestService(rate("USD", "CNY", today, 7.2))
+	settingsDAO := &mockSettingsDAO{}
+	converter := NewConverter(svc, settingsDAO)
+	ctx := context.Background()
+
+	currency, err := converter.ReportingCurrency(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, CurrencyCNY, currency, "未设置时默认人民币")
+
+	require.NoError(t, converter.SetReportingCurrency(ctx, "tenant-1", " usd "))
+	currency, err = converter.ReportingCurrency(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", currency)
+
+	currency, err = converter.ReportingCurrency(ctx, "tenant-2")
+	require.NoError(t, err)
+	assert.Equal(t, CurrencyCNY, currency, "租户间互不影响")
+
+	err = converter.SetReportingCurrency(ctx, "tenant-1", "EURO")
+	assert.ErrorIs(t, err, costdomain.ErrExchangeRateInvalid)
+
+	err = converter.SetReportingCurrency(ctx, "tenant-1", "CHF")
+	assert.ErrorIs(t, err, costdomain.ErrExchangeRateNotFound, "无汇率的币种不能设为报表币种")
+
+	err = converter.SetReportingCurrency(ctx, "", "USD")
+	assert.Error(t, err)
+}
+
+func TestConverter_FromCNY(t *testing.T) {
+	svc, rateDAO, _ := newTestService(
+		rate("USD", "CNY", "2024-03-01", 8.0),
+		rate("USD", "CNY", "2024-03-02", 5.0),
+	)
+	converter := NewConverter(svc, &mockSettingsDAO{})
+	ctx := context.Background()
+
+	// 按账单日期汇率折算，而非最新汇率
+	got, err := converter.FromCNY(ctx, "USD", 80, "2024-03-01")
+	require.NoError(t, err)
+	assert.InDelta(t, 10.0, got, 1e-9)
+
+	got, err = converter.FromCNY(ctx, "USD", 80, "2024-03-02")
+	require.NoError(t, err)
+	assert.InDelta(t, 16.0, got, 1e-9)
+
+	got, err = converter.FromCNY(ctx, CurrencyCNY, 80, "2024-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, got)
+
+	got, err = converter.FromCNY(ctx, "USD", 0, "2023-01-01")
+	require.NoError(t, err, "零金额无需汇率")
+	assert.Equal(t, 0.0, got)
+
+	// 早于第一条汇率时使用当前最新汇率
+	got, err = converter.FromCNY(ctx, "USD", 80, "2024-02-01")
+	require.NoError(t, err)
+	assert.InDelta(t, 16.0, got, 1e-9)
+
+	// 超过回溯天数的日期回退到最近一条汇率，而非整体失败
+	got, err = converter.FromCNY(ctx, "USD", 80, "2024-06-01")
+	require.NoError(t, err)
+	assert.InDelta(t, 16.0, got, 1e-9)
+
+	// 从未录入汇率的币种仍返回错误
+	_, err = converter.FromCNY(ctx, "CHF", 80, "2024-03-01")
+	assert.ErrorIs(t, err, costdomain.ErrExchangeRateNotFound)
+
+	// 同一日期重复折算命中缓存
+	rateDAO.rates = nil
+	got, err = converter.FromCNY(ctx, "USD", 40, "2024-03-01")
+	require.NoError(t, err)
+	assert.InDelta(t, 5.0, got, 1e-9)
+}
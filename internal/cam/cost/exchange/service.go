@@ -0,0 +1,400 @@
+// Package exchange 汇率管理：按币种对维护日汇率，支持手工上传、数据源同步与历史账单重算
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/normalizer"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// CurrencyCNY 人民币
+	CurrencyCNY = "CNY"
+	// CurrencyUSD 美元（交叉汇率的中间币种）
+	CurrencyUSD = "USD"
+
+	// maxRateAgeDays 汇率最大回溯天数，超过则视为无可用汇率（避免节假日缺失时使用过旧汇率）
+	maxRateAgeDays = 31
+	// recomputePageSize 重算时每批读取的账单数量
+	recomputePageSize = 1000
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// SummaryRebuilder 汇总表重建接口（重算人民币金额后刷新每日汇总）
+type SummaryRebuilder interface {
+	RebuildFromSource(ctx context.Context, startDate, endDate string) error
+}
+
+// RecomputeRequest 历史账单人民币金额重算请求
+type RecomputeRequest struct {
+	TenantID  string
+	Currency  string // 为空时重算全部非人民币账单
+	StartDate string // YYYY-MM-DD
+	EndDate   string // YYYY-MM-DD
+}
+
+// RecomputeResult 重算结果
+type RecomputeResult struct {
+	Scanned int64 `json:"scanned"`
+	Updated int64 `json:"updated"`
+	Missing int64 `json:"missing"` // 无可用汇率的账单数量
+}
+
+// CNYRateLookup 币种兑人民币汇率查询（含美元固定汇率兜底），由标准化服务的币种配置实现
+type CNYRateLookup interface {
+	RateToCNY(ctx context.Context, currency, date string) (float64, error)
+}
+
+// ExchangeRateService 汇率管理服务
+type ExchangeRateService struct {
+	rateDAO  repository.ExchangeRateDAO
+	billDAO  repository.BillDAO
+	summary  SummaryRebuilder
+	cnyRates CNYRateLookup
+	logger   *elog.Component
+
+	mu      sync.RWMutex
+	sources []RateSource
+}
+
+// NewExchangeRateService 创建汇率管理服务
+func NewExchangeRateService(
+	rateDAO repository.ExchangeRateDAO,
+	billDAO repository.BillDAO,
+	logger *elog.Component,
+) *ExchangeRateService {
+	s := &ExchangeRateService{
+		rateDAO: rateDAO,
+		billDAO: billDAO,
+		logger:  logger,
+	}
+	// 默认使用独立的币种配置，生产环境通过 SetCNYRateLookup 与标准化服务共用
+	currency := normalizer.NewCurrencyConfig(normalizer.DefaultUSDToCNYRate)
+	currency.SetRateProvider(s)
+	s.cnyRates = currency
+	return s
+}
+
+// Logger 返回日志组件（供 handler 异步场景使用）
+func (s *ExchangeRateService) Logger() *elog.Component {
+	return s.logger
+}
+
+// SetSummaryRebuilder 注入汇总表重建器
+func (s *ExchangeRateService) SetSummaryRebuilder(summary SummaryRebuilder) {
+	s.summary = summary
+}
+
+// SetCNYRateLookup 注入人民币汇率查询，使历史重算与标准化入库使用同一汇率口径
+func (s *ExchangeRateService) SetCNYRateLookup(lookup CNYRateLookup) {
+	s.cnyRates = lookup
+}
+
+// RegisterSource 注册汇率数据源
+func (s *ExchangeRateService) RegisterSource(source RateSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources = append(s.sources, source)
+}
+
+// UploadRates 手工上传汇率，同一币种对同一日期已存在则覆盖
+func (s *ExchangeRateService) UploadRates(ctx context.Context, rates []costdomain.ExchangeRate) (int64, error) {
+	if len(rates) == 0 {
+		return 0, fmt.Errorf("%w: rates cannot be empty", costdomain.ErrExchangeRateInvalid)
+	}
+	normalized := make([]costdomain.ExchangeRate, 0, len(rates))
+	for _, rate := range rates {
+		if rate.Source == "" {
+			rate.Source = costdomain.ExchangeRateSourceManual
+		}
+		r, err := normalizeRate(rate)
+		if err != nil {
+			return 0, err
+		}
+		normalized = append(normalized, r)
+	}
+	return s.rateDAO.UpsertBatch(ctx, normalized)
+}
+
+// SyncRates 从所有已注册数据源拉取指定日期的汇率
+// 单个数据源失败不影响其他数据源，返回写入条数与最后一个错误
+func (s *ExchangeRateService) SyncRates(ctx context.Context, date time.Time) (int64, error) {
+	s.mu.RLock()
+	sources := append([]RateSource(nil), s.sources...)
+	s.mu.RUnlock()
+
+	var total int64
+	var lastErr error
+	for _, source := range sources {
+		rates, err := source.FetchRates(ctx, date)
+		if err != nil {
+			s.logger.Error("fetch exchange rates failed",
+				elog.String("source", source.Name()),
+				elog.FieldErr(err))
+			lastErr = err
+			continue
+		}
+
+		valid := make([]costdomain.ExchangeRate, 0, len(rates))
+		for _, rate := range rates {
+			rate.Source = source.Name()
+			if rate.Date == "" {
+				rate.Date = date.Format("2006-01-02")
+			}
+			r, err := normalizeRate(rate)
+			if err != nil {
+				s.logger.Warn("skip invalid exchange rate",
+					elog.String("source", source.Name()),
+					elog.FieldErr(err))
+				continue
+			}
+			valid = append(valid, r)
+		}
+
+		n, err := s.rateDAO.UpsertBatch(ctx, valid)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		total += n
+		s.logger.Info("sync exchange rates success",
+			elog.String("source", source.Name()),
+			elog.Int("count", len(valid)))
+	}
+	return total, lastErr
+}
+
+// GetRate 获取 date 当日生效的 1 单位 from 兑换 to 的汇率
+// 依次尝试直接汇率、反向汇率与经美元的交叉汇率
+func (s *ExchangeRateService) GetRate(ctx context.Context, from, to, date string) (float64, error) {
+	return s.lookupRate(ctx, from, to, date, true)
+}
+
+// LatestRate 获取不晚于 date 的最近一条汇率，不限回溯天数
+// 用于报表折算在日汇率缺失或过旧时兜底，入库折算仍使用 GetRate
+func (s *ExchangeRateService) LatestRate(ctx context.Context, from, to, date string) (float64, error) {
+	return s.lookupRate(ctx, from, to, date, false)
+}
+
+// lookupRate 依次尝试直接汇率、反向汇率与经美元的交叉汇率，fresh 为 true 时不使用超过回溯天数的汇率
+func (s *ExchangeRateService) lookupRate(ctx context.Context, from, to, date string, fresh bool) (float64, error) {
+	from = strings.ToUpper(strings.TrimSpace(from))
+	to = strings.ToUpper(strings.TrimSpace(to))
+	if from == to {
+		return 1, nil
+	}
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return 0, fmt.Errorf("%w: date %q should be YYYY-MM-DD", costdomain.ErrExchangeRateInvalid, date)
+	}
+
+	if rate, err := s.pairRate(ctx, from, to, date, fresh); !errors.Is(err, costdomain.ErrExchangeRateNotFound) {
+		return rate, err
+	}
+
+	if from != CurrencyUSD && to != CurrencyUSD {
+		fromUSD, err := s.pairRate(ctx, from, CurrencyUSD, date, fresh)
+		if err != nil && !errors.Is(err, costdomain.ErrExchangeRateNotFound) {
+			return 0, err
+		}
+		if err == nil {
+			usdTo, err := s.pairRate(ctx, CurrencyUSD, to, date, fresh)
+			if err != nil && !errors.Is(err, costdomain.ErrExchangeRateNotFound) {
+				return 0, err
+			}
+			if err == nil {
+				return fromUSD * usdTo, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %s/%s on %s", costdomain.ErrExchangeRateNotFound, from, to, date)
+}
+
+// pairRate 查询直接或反向汇率
+func (s *ExchangeRateService) pairRate(ctx context.Context, from, to, date string, fresh bool) (float64, error) {
+	rate, err := s.effectiveRate(ctx, from, to, date, fresh)
+	if err == nil {
+		return rate, nil
+	}
+	if !errors.Is(err, costdomain.ErrExchangeRateNotFound) {
+		return 0, err
+	}
+
+	inverse, err := s.effectiveRate(ctx, to, from, date, fresh)
+	if err != nil {
+		return 0, err
+	}
+	return 1 / inverse, nil
+}
+
+// effectiveRate 查询不晚于 date 的汇率，fresh 为 true 时要求未超过回溯天数
+func (s *ExchangeRateService) effectiveRate(ctx context.Context, base, quote, date string, fresh bool) (float64, error) {
+	rate, err := s.rateDAO.FindEffective(ctx, base, quote, date)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return 0, costdomain.ErrExchangeRateNotFound
+		}
+		return 0, err
+	}
+	if rate.Rate <= 0 || (fresh && isStale(rate.Date, date)) {
+		return 0, costdomain.ErrExchangeRateNotFound
+	}
+	return rate.Rate, nil
+}
+
+// ListRates 查询汇率列表
+func (s *ExchangeRateService) ListRates(ctx context.Context, filter repository.ExchangeRateFilter) ([]costdomain.ExchangeRate, int64, error) {
+	filter.BaseCurrency = strings.ToUpper(filter.BaseCurrency)
+	filter.QuoteCurrency = strings.ToUpper(filter.QuoteCurrency)
+
+	rates, err := s.rateDAO.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.rateDAO.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rates, total, nil
+}
+
+// DeleteRate 删除汇率
+func (s *ExchangeRateService) DeleteRate(ctx context.Context, id int64) error {
+	return s.rateDAO.Delete(ctx, id)
+}
+
+// RecomputeAmountCNY 按当前汇率库重算历史统一账单的人民币金额
+// 用于汇率修正或补录后刷新 AmountCNY，完成后重建对应日期范围的每日汇总；
+// 美元缺失日汇率时与标准化入库一致，回退到固定汇率
+func (s *ExchangeRateService) RecomputeAmountCNY(ctx context.Context, req RecomputeRequest) (*RecomputeResult, error) {
+	if req.StartDate == "" || req.EndDate == "" {
+		return nil, fmt.Errorf("start_date and end_date are required")
+	}
+	if req.StartDate > req.EndDate {
+		return nil, fmt.Errorf("start_date must not be after end_date")
+	}
+
+	// 摊销口径包含摊销派生行，否则汇率修正后摊销行的人民币金额不会刷新
+	filter := repository.UnifiedBillFilter{
+		TenantID:  req.TenantID,
+		Currency:  strings.ToUpper(req.Currency),
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		View:      costdomain.CostViewAmortized,
+		Limit:     recomputePageSize,
+	}
+
+	result := &RecomputeResult{}
+	rates := make(map[string]float64)
+	for {
+		bills, err := s.billDAO.ListUnifiedBills(ctx, filter)
+		if err != nil {
+			return result, fmt.Errorf("list unified bills failed: %w", err)
+		}
+
+		updates := make([]repository.AmountCNYUpdate, 0, len(bills))
+		for _, bill := range bills {
+			result.Scanned++
+			currency := strings.ToUpper(bill.Currency)
+			if currency == "" || currency == CurrencyCNY {
+				continue
+			}
+
+			key := currency + "@" + bill.BillingDate
+			rate, ok := rates[key]
+			if !ok {
+				rate, err = s.cnyRates.RateToCNY(ctx, currency, bill.BillingDate)
+				if err != nil && !errors.Is(err, costdomain.ErrExchangeRateNotFound) {
+					return result, err
+				}
+				rates[key] = rate
+			}
+			if rate == 0 {
+				result.Missing++
+				continue
+			}
+
+			amountCNY := bill.Amount * rate
+			if amountCNY == bill.AmountCNY && rate == bill.ExchangeRate {
+				continue
+			}
+			updates = append(updates, repository.AmountCNYUpdate{
+				ID:           bill.ID,
+				AmountCNY:    amountCNY,
+				ExchangeRate: rate,
+			})
+		}
+
+		if len(updates) > 0 {
+			n, err := s.billDAO.UpdateUnifiedBillAmountCNY(ctx, updates)
+			if err != nil {
+				return result, fmt.Errorf("update amount_cny failed: %w", err)
+			}
+			result.Updated += n
+		}
+
+		if int64(len(bills)) < recomputePageSize {
+			break
+		}
+		filter.Offset += recomputePageSize
+	}
+
+	if result.Updated > 0 && s.summary != nil {
+		if err := s.summary.RebuildFromSource(ctx, req.StartDate, req.EndDate); err != nil {
+			s.logger.Error("rebuild daily summary after recompute failed", elog.FieldErr(err))
+		}
+	}
+
+	s.logger.Info("recompute amount_cny completed",
+		elog.String("tenant_id", req.TenantID),
+		elog.String("currency", filter.Currency),
+		elog.String("start_date", req.StartDate),
+		elog.String("end_date", req.EndDate),
+		elog.Int64("scanned", result.Scanned),
+		elog.Int64("updated", result.Updated),
+		elog.Int64("missing", result.Missing))
+
+	return result, nil
+}
+
+// normalizeRate 校验并规范化汇率记录
+func normalizeRate(rate costdomain.ExchangeRate) (costdomain.ExchangeRate, error) {
+	rate.BaseCurrency = strings.ToUpper(strings.TrimSpace(rate.BaseCurrency))
+	rate.QuoteCurrency = strings.ToUpper(strings.TrimSpace(rate.QuoteCurrency))
+
+	if !currencyPattern.MatchString(rate.BaseCurrency) || !currencyPattern.MatchString(rate.QuoteCurrency) {
+		return rate, fmt.Errorf("%w: currency should be ISO 4217 code, got %q/%q",
+			costdomain.ErrExchangeRateInvalid, rate.BaseCurrency, rate.QuoteCurrency)
+	}
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return rate, fmt.Errorf("%w: base and quote currency are the same", costdomain.ErrExchangeRateInvalid)
+	}
+	if _, err := time.Parse("2006-01-02", rate.Date); err != nil {
+		return rate, fmt.Errorf("%w: date %q should be YYYY-MM-DD", costdomain.ErrExchangeRateInvalid, rate.Date)
+	}
+	if rate.Rate <= 0 {
+		return rate, fmt.Errorf("%w: rate must be positive, got %f", costdomain.ErrExchangeRateInvalid, rate.Rate)
+	}
+	return rate, nil
+}
+
+// isStale 判断汇率日期距查询日期是否超过最大回溯天数
+func isStale(rateDate, date string) bool {
+	rd, err1 := time.Parse("2006-01-02", rateDate)
+	d, err2 := time.Parse("2006-01-02", date)
+	if err1 != nil || err2 != nil {
+		return true
+	}
+	return d.Sub(rd) > maxRateAgeDays*24*time.Hour
+}
@@ -0,0 +1,429 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/normalizer"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ========== Mock DAOs ==========
+
+// mockRateDAO 内存汇率库，FindEffective 返回不晚于指定日期的最近一条
+type mockRateDAO struct {
+	rates     []costdomain.ExchangeRate
+	findCalls int
+}
+
+func (m *mockRateDAO) Upsert(ctx context.Context, rate costdomain.ExchangeRate) error {
+	_, err := m.UpsertBatch(ctx, []costdomain.ExchangeRate{rate})
+	return err
+}
+
+func (m *mockRateDAO) UpsertBatch(_ context.Context, rates []costdomain.ExchangeRate) (int64, error) {
+	for _, rate := range rates {
+		replaced := false
+		for i, r := range m.rates {
+			if r.BaseCurrency == rate.BaseCurrency && r.QuoteCurrency == rate.QuoteCurrency && r.Date == rate.Date {
+				m.rates[i] = rate
+				replaced = true
+			}
+		}
+		if !replaced {
+			rate.ID = int64(len(m.rates) + 1)
+			m.rates = append(m.rates, rate)
+		}
+	}
+	return int64(len(rates)), nil
+}
+
+func (m *mockRateDAO) FindEffective(_ context.Context, base, quote, date string) (costdomain.ExchangeRate, error) {
+	m.findCalls++
+	var found *costdomain.ExchangeRate
+	for i, r := range m.rates {
+		if r.BaseCurrency == base && r.QuoteCurrency == quote && r.Date <= date {
+			if found == nil || r.Date > found.Date {
+				found = &m.rates[i]
+			}
+		}
+	}
+	if found == nil {
+		return costdomain.ExchangeRate{}, mongo.ErrNoDocuments
+	}
+	return *found, nil
+}
+
+func (m *mockRateDAO) List(_ context.Context, _ repository.ExchangeRateFilter) ([]costdomain.ExchangeRate, error) {
+	return m.rates, nil
+}
+
+func (m *mockRateDAO) Count(_ context.Context, _ repository.ExchangeRateFilter) (int64, error) {
+	return int64(len(m.rates)), nil
+}
+
+func (m *mockRateDAO) Delete(_ context.Context, _ int64) error {
+	return nil
+}
+
+// mockBillDAO 仅实现重算依赖的统一账单查询与更新
+type mockBillDAO struct {
+	bills   []costdomain.UnifiedBill
+	updates []repository.AmountCNYUpdate
+}
+
+func (m *mockBillDAO) InsertRawBill(_ context.Context, _ costdomain.RawBillRecord) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) InsertRawBills(_ context.Context, _ []costdomain.RawBillRecord) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) GetRawBillByID(_ context.Context, _ int64) (costdomain.RawBillRecord, error) {
+	return costdomain.RawBillRecord{}, nil
+}
+func (m *mockBillDAO) ListRawBills(_ context.Context, _ int64, _, _ string) ([]costdomain.RawBillRecord, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) ListRawBillsByCollectID(_ context.Context, _ string) ([]costdomain.RawBillRecord, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) InsertUnifiedBill(_ context.Context, _ costdomain.UnifiedBill) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) InsertUnifiedBills(_ context.Context, _ []costdomain.UnifiedBill) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) GetUnifiedBillByID(_ context.Context, _ int64) (costdomain.UnifiedBill, error) {
+	return costdomain.UnifiedBill{}, nil
+}
+func (m *mockBillDAO) ListUnifiedBills(_ context.Context, filter repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+	var matched []costdomain.UnifiedBill
+	for _, b := range m.bills {
+		if filter.Currency != "" && b.Currency != filter.Currency {
+			continue
+		}
+		if b.BillingDate < filter.StartDate || b.BillingDate > filter.EndDate {
+			continue
+		}
+		if filter.View != costdomain.CostViewAmortized && b.LineType == costdomain.LineTypeAmortization {
+			continue
+		}
+		matched = append(matched, b)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	if filter.Offset >= int64(len(matched)) {
+		return nil, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && int64(len(matched)) > filter.Limit {
+		matched = matched[:filter.Limit]
+	}
+	return matched, nil
+}
+func (m *mockBillDAO) CountUnifiedBills(_ context.Context, _ repository.UnifiedBillFilter) (int64, error) {
+	return int64(len(m.bills)), nil
+}
+func (m *mockBillDAO) AggregateByField(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.AggregateResult, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) AggregateDailyAmount(_ context.Context, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) SumAmount(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _ string, _ string) error {
+	return nil
+}
+func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) ([]repository.AggregateResult, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, updates []repository.AmountCNYUpdate) (int64, error) {
+	m.updates = append(m.updates, updates...)
+	return int64(len(updates)), nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(_ context.Context, _, _, _, _, _, _ string) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+type mockSummaryRebuilder struct {
+	calls [][2]string
+}
+
+func (m *mockSummaryRebuilder) RebuildFromSource(_ context.Context, startDate, endDate string) error {
+	m.calls = append(m.calls, [2]string{startDate, endDate})
+	return nil
+}
+
+type mockRateSource struct {
+	name  string
+	rates []costdomain.ExchangeRate
+	err   error
+}
+
+func (m *mockRateSource) Name() string { return m.name }
+
+func (m *mockRateSource) FetchRates(_ context.Context, _ time.Time) ([]costdomain.ExchangeRate, error) {
+	return m.rates, m.err
+}
+
+// ========== Helpers ==========
+
+func newTestService(rates ...costdomain.ExchangeRate) (*ExchangeRateService, *mockRateDAO, *mockBillDAO) {
+	rateDAO := &mockRateDAO{rates: rates}
+	billDAO := &mockBillDAO{}
+	return NewExchangeRateService(rateDAO, billDAO, elog.DefaultLogger), rateDAO, billDAO
+}
+
+func rate(base, quote, date string, value float64) costdomain.ExchangeRate {
+	return costdomain.ExchangeRate{BaseCurrency: base, QuoteCurrency: quote, Date: date, Rate: value}
+}
+
+// ========== Tests ==========
+
+func TestGetRate(t *testing.T) {
+	svc, _, _ := newTestService(
+		rate("USD", "CNY", "2024-03-01", 7.10),
+		rate("USD", "CNY", "2024-03-04", 7.20),
+		rate("EUR", "USD", "2024-03-01", 1.08),
+		rate("CNY", "JPY", "2024-03-01", 20.0),
+		rate("GBP", "CNY", "2024-01-01", 9.0),
+	)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		date     string
+		expected float64
+		wantErr  error
+	}{
+		{"相同币种", "cny", "CNY", "2024-03-01", 1, nil},
+		{"直接汇率", "USD", "CNY", "2024-03-01", 7.10, nil},
+		{"取不晚于日期的最近汇率", "USD", "CNY", "2024-03-03", 7.10, nil},
+		{"当日汇率", "usd", "cny", "2024-03-05", 7.20, nil},
+		{"反向汇率", "JPY", "CNY", "2024-03-01", 0.05, nil},
+		{"经美元交叉汇率", "EUR", "CNY", "2024-03-02", 1.08 * 7.10, nil},
+		{"日期早于所有汇率", "USD", "CNY", "2024-02-28", 0, costdomain.ErrExchangeRateNotFound},
+		{"汇率过旧", "GBP", "CNY", "2024-03-01", 0, costdomain.ErrExchangeRateNotFound},
+		{"未知币种", "CHF", "CNY", "2024-03-01", 0, costdomain.ErrExchangeRateNotFound},
+		{"日期格式错误", "USD", "CNY", "20240301", 0, costdomain.ErrExchangeRateInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.GetRate(ctx, tt.from, tt.to, tt.date)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, got, 1e-9)
+		})
+	}
+}
+
+func TestUploadRates(t *testing.T) {
+	svc, rateDAO, _ := newTestService()
+	ctx := context.Background()
+
+	n, err := svc.UploadRates(ctx, []costdomain.ExchangeRate{
+		rate(" usd", "cny ", "2024-03-01", 7.1),
+		rate("EUR", "CNY", "2024-03-01", 7.8),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	require.Len(t, rateDAO.rates, 2)
+	assert.Equal(t, "USD", rateDAO.rates[0].BaseCurrency)
+	assert.Equal(t, "CNY", rateDAO.rates[0].QuoteCurrency)
+	assert.Equal(t, costdomain.ExchangeRateSourceManual, rateDAO.rates[0].Source)
+
+	// 同一币种对同一日期覆盖
+	_, err = svc.UploadRates(ctx, []costdomain.ExchangeRate{rate("USD", "CNY", "2024-03-01", 7.15)})
+	require.NoError(t, err)
+	require.Len(t, rateDAO.rates, 2)
+	assert.Equal(t, 7.15, rateDAO.rates[0].Rate)
+
+	invalid := []struct {
+		name string
+		rate costdomain.ExchangeRate
+	}{
+		{"币种非法", rate("US", "CNY", "2024-03-01", 7.1)},
+		{"币种相同", rate("CNY", "CNY", "2024-03-01", 1)},
+		{"日期非法", rate("USD", "CNY", "2024/03/01", 7.1)},
+		{"汇率非正", rate("USD", "CNY", "2024-03-01", 0)},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.UploadRates(ctx, []costdomain.ExchangeRate{tt.rate})
+			assert.ErrorIs(t, err, costdomain.ErrExchangeRateInvalid)
+		})
+	}
+
+	_, err = svc.UploadRates(ctx, nil)
+	assert.ErrorIs(t, err, costdomain.ErrExchangeRateInvalid)
+}
+
+func TestSyncRates(t *testing.T) {
+	svc, rateDAO, _ := newTestService()
+	svc.RegisterSource(&mockRateSource{name: "boc", rates: []costdomain.ExchangeRate{
+		{BaseCurrency: "USD", QuoteCurrency: "CNY", Rate: 7.1},
+		{BaseCurrency: "XX", QuoteCurrency: "CNY", Rate: 1},
+	}})
+	svc.RegisterSource(&mockRateSource{name: "broken", err: errors.New("timeout")})
+
+	n, err := svc.SyncRates(context.Background(), time.Date(2024, 3, 1, 1, 0, 0, 0, time.Local))
+	assert.Error(t, err)
+	assert.Equal(t, int64(1), n)
+	require.Len(t, rateDAO.rates, 1)
+	assert.Equal(t, "2024-03-01", rateDAO.rates[0].Date)
+	assert.Equal(t, "boc", rateDAO.rates[0].Source)
+}
+
+func TestRecomputeAmountCNY(t *testing.T) {
+	svc, _, billDAO := newTestService(
+		rate("USD", "CNY", "2024-03-01", 7.2),
+		rate("EUR", "CNY", "2024-03-01", 7.8),
+	)
+	summary := &mockSummaryRebuilder{}
+	svc.SetSummaryRebuilder(summary)
+
+	billDAO.bills = []costdomain.UnifiedBill{
+		{ID: 1, Currency: "USD", Amount: 10, AmountCNY: 72, ExchangeRate: 7.2, BillingDate: "2024-03-01"}, // 未变化
+		{ID: 2, Currency: "USD", Amount: 10, AmountCNY: 71, ExchangeRate: 7.1, BillingDate: "2024-03-02"}, // 汇率已修正
+		{ID: 3, Currency: "EUR", Amount: 10, AmountCNY: 0, BillingDate: "2024-03-02"},                     // 补录汇率
+		{ID: 4, Currency: "CNY", Amount: 10, AmountCNY: 10, ExchangeRate: 1, BillingDate: "2024-03-02"},
+		{ID: 5, Currency: "JPY", Amount: 1000, AmountCNY: 0, BillingDate: "2024-03-02"}, // 无可用汇率
+		{ID: 6, Currency: "USD", Amount: 10, AmountCNY: 0, BillingDate: "2024-04-01"},   // 不在范围内
+	}
+
+	result, err := svc.RecomputeAmountCNY(context.Background(), RecomputeRequest{
+		StartDate: "2024-03-01",
+		EndDate:   "2024-03-31",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), result.Scanned)
+	assert.Equal(t, int64(2), result.Updated)
+	assert.Equal(t, int64(1), result.Missing)
+
+	require.Len(t, billDAO.updates, 2)
+	assert.Equal(t, int64(2), billDAO.updates[0].ID)
+	assert.InDelta(t, 72.0, billDAO.updates[0].AmountCNY, 1e-9)
+	assert.Equal(t, 7.2, billDAO.updates[0].ExchangeRate)
+	assert.Equal(t, int64(3), billDAO.updates[1].ID)
+	assert.InDelta(t, 78.0, billDAO.updates[1].AmountCNY, 1e-9)
+
+	assert.Equal(t, [][2]string{{"2024-03-01", "2024-03-31"}}, summary.calls)
+}
+
+func TestRecomputeAmountCNY_IncludesAmortizationRows(t *testing.T) {
+	svc, _, billDAO := newTestService(rate("USD", "CNY", "2024-03-01", 7.2))
+	billDAO.bills = []costdomain.UnifiedBill{
+		{ID: 1, Currency: "USD", Amount: 120, AmountCNY: 852, ExchangeRate: 7.1, BillingDate: "2024-03-01"},
+		{ID: 2, Currency: "USD", Amount: 10, AmountCNY: 71, ExchangeRate: 7.1, BillingDate: "2024-03-01",
+			LineType: costdomain.LineTypeAmortization},
+	}
+
+	result, err := svc.RecomputeAmountCNY(context.Background(), RecomputeRequest{
+		StartDate: "2024-03-01",
+		EndDate:   "2024-03-31",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), result.Scanned)
+	require.Len(t, billDAO.updates, 2)
+	assert.Equal(t, int64(2), billDAO.updates[1].ID)
+	assert.InDelta(t, 72.0, billDAO.updates[1].AmountCNY, 1e-9)
+}
+
+func TestRecomputeAmountCNY_USDFallback(t *testing.T) {
+	svc, _, billDAO := newTestService()
+	billDAO.bills = []costdomain.UnifiedBill{
+		{ID: 1, Currency: "USD", Amount: 10, AmountCNY: 0, BillingDate: "2024-03-01"},
+		{ID: 2, Currency: "EUR", Amount: 10, AmountCNY: 0, BillingDate: "2024-03-01"},
+	}
+
+	result, err := svc.RecomputeAmountCNY(context.Background(), RecomputeRequest{
+		StartDate: "2024-03-01",
+		EndDate:   "2024-03-31",
+	})
+	require.NoError(t, err)
+	// 美元无日汇率时与标准化入库一致，使用固定汇率兜底
+	assert.Equal(t, int64(1), result.Updated)
+	assert.Equal(t, int64(1), result.Missing)
+	require.Len(t, billDAO.updates, 1)
+	assert.InDelta(t, 72.0, billDAO.updates[0].AmountCNY, 1e-9)
+
+	// 与标准化服务共用币种配置后跟随其固定汇率
+	cfg := normalizer.NewCurrencyConfig(7.0)
+	cfg.SetRateProvider(svc)
+	svc.SetCNYRateLookup(cfg)
+	billDAO.updates = nil
+	_, err = svc.RecomputeAmountCNY(context.Background(), RecomputeRequest{
+		StartDate: "2024-03-01",
+		EndDate:   "2024-03-31",
+	})
+	require.NoError(t, err)
+	require.Len(t, billDAO.updates, 1)
+	assert.InDelta(t, 70.0, billDAO.updates[0].AmountCNY, 1e-9)
+}
+
+func TestRecomputeAmountCNY_CurrencyFilterAndRateCache(t *testing.T) {
+	svc, rateDAO, billDAO := newTestService(rate("USD", "CNY", "2024-03-01", 7.2))
+	summary := &mockSummaryRebuilder{}
+	svc.SetSummaryRebuilder(summary)
+
+	for i := 1; i <= 3; i++ {
+		billDAO.bills = append(billDAO.bills, costdomain.UnifiedBill{
+			ID: int64(i), Currency: "USD", Amount: 1, AmountCNY: 7.2, ExchangeRate: 7.2, BillingDate: "2024-03-01",
+		})
+	}
+	billDAO.bills = append(billDAO.bills, costdomain.UnifiedBill{ID: 4, Currency: "EUR", Amount: 1, BillingDate: "2024-03-01"})
+
+	result, err := svc.RecomputeAmountCNY(context.Background(), RecomputeRequest{
+		Currency:  "usd",
+		StartDate: "2024-03-01",
+		EndDate:   "2024-03-01",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Scanned)
+	assert.Zero(t, result.Updated)
+	// 同一币种同一日期只查询一次汇率
+	assert.Equal(t, 1, rateDAO.findCalls)
+	// 无更新时不重建汇总
+	assert.Empty(t, summary.calls)
+}
+
+func TestRecomputeAmountCNY_InvalidRange(t *testing.T) {
+	svc, _, _ := newTestService()
+	ctx := context.Background()
+
+	_, err := svc.RecomputeAmountCNY(ctx, RecomputeRequest{StartDate: "2024-03-01"})
+	assert.Error(t, err)
+	_, err = svc.RecomputeAmountCNY(ctx, RecomputeRequest{StartDate: "2024-03-02", EndDate: "2024-03-01"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+)
+
+// RateSource 汇率数据源接口
+// 实现方按日期拉取汇率（如央行中间价、第三方汇率 API），由 SyncRates 统一写入汇率库
+type RateSource interface {
+	// Name 数据源标识，写入 ExchangeRate.Source
+	Name() string
+	// FetchRates 拉取指定日期的汇率列表
+	FetchRates(ctx context.Context, date time.Time) ([]costdomain.ExchangeRate, error)
+}
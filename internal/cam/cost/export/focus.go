@@ -0,0 +1,252 @@
+// Package export 成本数据导出（FinOps FOCUS 规范）
+package export
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+)
+
+// FOCUS 1.0 ChargeCategory 取值
+const (
+	ChargeCategoryUsage      = "Usage"
+	ChargeCategoryPurchase   = "Purchase"
+	ChargeCategoryCredit     = "Credit"
+	ChargeCategoryAdjustment = "Adjustment"
+)
+
+// FOCUS 1.0 ChargeFrequency 取值
+const (
+	ChargeFrequencyOneTime    = "One-Time"
+	ChargeFrequencyRecurring  = "Recurring"
+	ChargeFrequencyUsageBased = "Usage-Based"
+)
+
+// FOCUS 1.0 PricingCategory 取值
+const (
+	PricingCategoryStandard  = "Standard"
+	PricingCategoryCommitted = "Committed"
+)
+
+// ChargeClassCorrection 对历史费用的更正（退款）
+const ChargeClassCorrection = "Correction"
+
+// FocusRow FOCUS 1.0 成本数据行，列名与规范一致，x_ 前缀为 CAM 扩展列
+// 字段顺序即 CSV 列顺序，新增列须同步 FocusColumns 与 csvRecord
+type FocusRow struct {
+	BilledCost         float64   `parquet:"BilledCost"`
+	BillingAccountId   string    `parquet:"BillingAccountId"`
+	BillingAccountName string    `parquet:"BillingAccountName,optional"`
+	BillingCurrency    string    `parquet:"BillingCurrency"`
+	BillingPeriodStart time.Time `parquet:"BillingPeriodStart,timestamp(millisecond)"`
+	BillingPeriodEnd   time.Time `parquet:"BillingPeriodEnd,timestamp(millisecond)"`
+	ChargeCategory     string    `parquet:"ChargeCategory"`
+	ChargeClass        string    `parquet:"ChargeClass,optional"`
+	ChargeDescription  string    `parquet:"ChargeDescription,optional"`
+	ChargeFrequency    string    `parquet:"ChargeFrequency,optional"`
+	ChargePeriodStart  time.Time `parquet:"ChargePeriodStart,timestamp(millisecond)"`
+	ChargePeriodEnd    time.Time `parquet:"ChargePeriodEnd,timestamp(millisecond)"`
+	ContractedCost     float64   `parquet:"ContractedCost"`
+	EffectiveCost      float64   `parquet:"EffectiveCost"`
+	InvoiceIssuerName  string    `parquet:"InvoiceIssuerName"`
+	ListCost           float64   `parquet:"ListCost"`
+	PricingCategory    string    `parquet:"PricingCategory,optional"`
+	ProviderName       string    `parquet:"ProviderName"`
+	PublisherName      string    `parquet:"PublisherName"`
+	RegionId           string    `parquet:"RegionId,optional"`
+	RegionName         string    `parquet:"RegionName,optional"`
+	ResourceId         string    `parquet:"ResourceId,optional"`
+	ResourceName       string    `parquet:"ResourceName,optional"`
+	ServiceCategory    string    `parquet:"ServiceCategory"`
+	ServiceName        string    `parquet:"ServiceName"`
+	Tags               string    `parquet:"Tags,optional"` // JSON 对象
+	XBilledCostCNY     float64   `parquet:"x_BilledCostCNY"`
+	XExchangeRate      float64   `parquet:"x_ExchangeRate"`
+	XChargeType        string    `parquet:"x_ChargeType,optional"`
+	XLineType          string    `parquet:"x_LineType,optional"`
+}
+
+// FocusColumns CSV 表头，与 FocusRow 字段顺序一致
+var FocusColumns = []string{
+	"BilledCost", "BillingAccountId", "BillingAccountName", "BillingCurrency",
+	"BillingPeriodStart", "BillingPeriodEnd", "ChargeCategory", "ChargeClass",
+	"ChargeDescription", "ChargeFrequency", "ChargePeriodStart", "ChargePeriodEnd",
+	"ContractedCost", "EffectiveCost", "InvoiceIssuerName", "ListCost",
+	"PricingCategory", "ProviderName", "PublisherName", "RegionId", "RegionName",
+	"ResourceId", "ResourceName", "ServiceCategory", "ServiceName", "Tags",
+	"x_BilledCostCNY", "x_ExchangeRate", "x_ChargeType", "x_LineType",
+}
+
+// csvRecord 按 FocusColumns 顺序格式化为 CSV 记录，时间使用 RFC 3339（UTC）
+func (r FocusRow) csvRecord() []string {
+	return []string{
+		formatCost(r.BilledCost), r.BillingAccountId, r.BillingAccountName, r.BillingCurrency,
+		formatTime(r.BillingPeriodStart), formatTime(r.BillingPeriodEnd), r.ChargeCategory, r.ChargeClass,
+		r.ChargeDescription, r.ChargeFrequency, formatTime(r.ChargePeriodStart), formatTime(r.ChargePeriodEnd),
+		formatCost(r.ContractedCost), formatCost(r.EffectiveCost), r.InvoiceIssuerName, formatCost(r.ListCost),
+		r.PricingCategory, r.ProviderName, r.PublisherName, r.RegionId, r.RegionName,
+		r.ResourceId, r.ResourceName, r.ServiceCategory, r.ServiceName, r.Tags,
+		formatCost(r.XBilledCostCNY), formatCost(r.XExchangeRate), r.XChargeType, r.XLineType,
+	}
+}
+
+// providerNames 云厂商 → FOCUS ProviderName
+var providerNames = map[string]string{
+	"aliyun":     "Alibaba Cloud",
+	"aws":        "AWS",
+	"azure":      "Microsoft",
+	"gcp":        "Google Cloud",
+	"huawei":     "Huawei Cloud",
+	"tencent":    "Tencent Cloud",
+	"volcano":    "Volcengine",
+	"volcengine": "Volcengine",
+}
+
+// serviceCategories 统一服务类型 → FOCUS ServiceCategory
+var serviceCategories = map[string]string{
+	domain.ServiceTypeCompute:    "Compute",
+	domain.ServiceTypeStorage:    "Storage",
+	domain.ServiceTypeNetwork:    "Networking",
+	domain.ServiceTypeDatabase:   "Databases",
+	domain.ServiceTypeMiddleware: "Integration",
+}
+
+// ToFocusRow 将统一账单转换为 FOCUS 数据行
+// BilledCost 为账单金额，EffectiveCost 为摊销后金额（预付费购买行为 0，由摊销派生行承担），
+// 历史数据缺少金额明细时 ListCost / ContractedCost / EffectiveCost 回退为账单金额
+func ToFocusRow(bill domain.UnifiedBill) FocusRow {
+	periodStart := monthStart(bill.BillingDate, bill.BillingStart)
+	chargeEnd := bill.BillingEnd.Add(time.Second) // FOCUS 区间右开
+	if bill.BillingEnd.IsZero() {
+		chargeEnd = periodStart.AddDate(0, 1, 0)
+	}
+	chargeStart := bill.BillingStart
+	if chargeStart.IsZero() {
+		chargeStart = periodStart
+	}
+
+	row := FocusRow{
+		BilledCost:         bill.Amount,
+		BillingAccountId:   strconv.FormatInt(bill.AccountID, 10),
+		BillingAccountName: bill.AccountName,
+		BillingCurrency:    bill.Currency,
+		BillingPeriodStart: periodStart,
+		BillingPeriodEnd:   periodStart.AddDate(0, 1, 0),
+		ChargePeriodStart:  chargeStart.UTC(),
+		ChargePeriodEnd:    chargeEnd.UTC(),
+		ListCost:           bill.Amount,
+		ContractedCost:     bill.Amount,
+		EffectiveCost:      bill.Amount,
+		ProviderName:       providerName(bill.Provider),
+		RegionId:           bill.Region,
+		RegionName:         bill.Region,
+		ResourceId:         bill.ResourceID,
+		ResourceName:       bill.ResourceName,
+		ServiceCategory:    serviceCategory(bill.ServiceType),
+		ServiceName:        bill.ServiceTypeName,
+		ChargeDescription:  chargeDescription(bill),
+		Tags:               formatTags(bill.Tags),
+		XBilledCostCNY:     bill.AmountCNY,
+		XExchangeRate:      bill.ExchangeRate,
+		XChargeType:        bill.ChargeType,
+		XLineType:          bill.LineType,
+	}
+	row.PublisherName = row.ProviderName
+	row.InvoiceIssuerName = row.ProviderName
+	if row.ServiceName == "" {
+		row.ServiceName = bill.ServiceType
+	}
+
+	if bill.LineType != "" {
+		row.ListCost = bill.ListAmount
+		row.ContractedCost = bill.ListAmount - bill.DiscountAmount
+		row.EffectiveCost = bill.AmortizedAmount
+	}
+
+	switch bill.LineType {
+	case domain.LineTypePurchase:
+		row.ChargeCategory = ChargeCategoryPurchase
+		row.ChargeFrequency = ChargeFrequencyOneTime
+	case domain.LineTypeRefund:
+		row.ChargeCategory = ChargeCategoryPurchase
+		row.ChargeClass = ChargeClassCorrection
+		row.ChargeFrequency = ChargeFrequencyOneTime
+	case domain.LineTypeCredit:
+		row.ChargeCategory = ChargeCategoryCredit
+		row.ChargeFrequency = ChargeFrequencyOneTime
+	case domain.LineTypeAmortization:
+		row.ChargeCategory = ChargeCategoryUsage
+		row.ChargeFrequency = ChargeFrequencyRecurring
+	default:
+		row.ChargeCategory = ChargeCategoryUsage
+		row.ChargeFrequency = ChargeFrequencyUsageBased
+	}
+
+	if row.ChargeCategory == ChargeCategoryUsage {
+		row.PricingCategory = PricingCategoryStandard
+		if bill.ChargeType == domain.ChargeTypePrepaid || bill.ChargeType == domain.ChargeTypeReserved {
+			row.PricingCategory = PricingCategoryCommitted
+		}
+	}
+	return row
+}
+
+// monthStart 返回账单所属月份第一天（UTC）
+func monthStart(billingDate string, fallback time.Time) time.Time {
+	t, err := time.Parse("2006-01-02", billingDate)
+	if err != nil {
+		t = fallback
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func providerName(provider string) string {
+	if name, ok := providerNames[provider]; ok {
+		return name
+	}
+	return provider
+}
+
+func serviceCategory(serviceType string) string {
+	if category, ok := serviceCategories[serviceType]; ok {
+		return category
+	}
+	return "Other"
+}
+
+// chargeDescription 生成费用描述，摊销派生行注明来源购买日期
+func chargeDescription(bill domain.UnifiedBill) string {
+	desc := bill.ServiceTypeName
+	if bill.ResourceName != "" {
+		desc += " " + bill.ResourceName
+	}
+	if bill.LineType == domain.LineTypeAmortization && bill.AmortizedFrom != "" {
+		desc += " (amortized from " + bill.AmortizedFrom + ")"
+	}
+	return desc
+}
+
+// formatTags 将标签序列化为 JSON 对象，无标签时为空
+func formatTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func formatCost(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,239 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// pageSize 分页读取账单的批大小
+const pageSize = 2000
+
+// unsafeNameChars 文件名中需要替换的字符
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// Request 导出请求
+type Request struct {
+	TenantID string
+	Format   string // csv | parquet，默认 csv
+	// Filter 账单筛选条件，StartDate / EndDate 必填；TenantID 与 View 由导出服务设置
+	Filter repository.UnifiedBillFilter
+}
+
+// Result 导出结果
+type Result struct {
+	FileName string `json:"file_name"`
+	Location string `json:"location"`
+	RowCount int64  `json:"row_count"`
+}
+
+// AccountLister 云账号查询接口（定时导出按账号所属租户逐个导出）
+type AccountLister interface {
+	ListAccounts(ctx context.Context, filter shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error)
+}
+
+// TaskSubmitter 异步任务提交接口，设置后定时导出通过任务队列执行
+type TaskSubmitter interface {
+	SubmitCostExportTask(ctx context.Context, tenantID, startDate, endDate, format string) (string, error)
+}
+
+// ExportService FOCUS 成本数据导出服务
+type ExportService struct {
+	billDAO    repository.BillDAO
+	accountSvc AccountLister
+	sink       Sink
+	submitter  TaskSubmitter
+	logger     *elog.Component
+}
+
+// NewExportService 创建导出服务
+func NewExportService(billDAO repository.BillDAO, accountSvc AccountLister, logger *elog.Component) *ExportService {
+	return &ExportService{
+		billDAO:    billDAO,
+		accountSvc: accountSvc,
+		logger:     logger,
+	}
+}
+
+// SetSink 设置导出文件存储（本地目录或对象存储），未设置时仅支持下载
+func (s *ExportService) SetSink(sink Sink) {
+	s.sink = sink
+}
+
+// SetTaskSubmitter 设置异步任务提交器
+func (s *ExportService) SetTaskSubmitter(submitter TaskSubmitter) {
+	s.submitter = submitter
+}
+
+// Validate 校验并补全导出请求
+func (s *ExportService) Validate(req *Request) error {
+	if req.Format == "" {
+		req.Format = FormatCSV
+	}
+	if req.Format != FormatCSV && req.Format != FormatParquet {
+		return fmt.Errorf("%w: unsupported format %q", domain.ErrExportInvalid, req.Format)
+	}
+	start, err := time.Parse("2006-01-02", req.Filter.StartDate)
+	if err != nil {
+		return fmt.Errorf("%w: start_date should be YYYY-MM-DD", domain.ErrExportInvalid)
+	}
+	end, err := time.Parse("2006-01-02", req.Filter.EndDate)
+	if err != nil {
+		return fmt.Errorf("%w: end_date should be YYYY-MM-DD", domain.ErrExportInvalid)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("%w: end_date is before start_date", domain.ErrExportInvalid)
+	}
+	return nil
+}
+
+// FileName 生成导出文件名：focus_<tenant>_<start>_<end>.<format>
+func (s *ExportService) FileName(req Request) string {
+	tenant := unsafeNameChars.ReplaceAllString(req.TenantID, "_")
+	if tenant == "" {
+		tenant = "all"
+	}
+	return fmt.Sprintf("focus_%s_%s_%s.%s", tenant, req.Filter.StartDate, req.Filter.EndDate, req.Format)
+}
+
+// Export 将账单转换为 FOCUS 格式流式写入 w，返回写入行数
+// 导出包含摊销派生行，使 EffectiveCost 合计与摊销口径一致
+func (s *ExportService) Export(ctx context.Context, req Request, w io.Writer) (int64, error) {
+	if err := s.Validate(&req); err != nil {
+		return 0, err
+	}
+	writer, err := newRowWriter(req.Format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := req.Filter
+	filter.TenantID = req.TenantID
+	filter.View = domain.CostViewAmortized
+	filter.Limit = pageSize
+
+	var count int64
+	rows := make([]FocusRow, 0, pageSize)
+	for offset := int64(0); ; offset += pageSize {
+		filter.Offset = offset
+		bills, err := s.billDAO.ListUnifiedBills(ctx, filter)
+		if err != nil {
+			return count, fmt.Errorf("list unified bills: %w", err)
+		}
+		rows = rows[:0]
+		for _, bill := range bills {
+			rows = append(rows, ToFocusRow(bill))
+		}
+		if len(rows) > 0 {
+			if err = writer.Write(rows); err != nil {
+				return count, fmt.Errorf("write %s rows: %w", req.Format, err)
+			}
+			count += int64(len(rows))
+		}
+		if len(bills) < pageSize {
+			break
+		}
+	}
+	if err = writer.Close(); err != nil {
+		return count, fmt.Errorf("close %s writer: %w", req.Format, err)
+	}
+	return count, nil
+}
+
+// ExportToSink 导出到配置的存储，先写临时文件再上传
+func (s *ExportService) ExportToSink(ctx context.Context, req Request) (Result, error) {
+	if s.sink == nil {
+		return Result{}, fmt.Errorf("%w: export sink is not configured", domain.ErrExportInvalid)
+	}
+	if err := s.Validate(&req); err != nil {
+		return Result{}, err
+	}
+
+	tmp, err := os.CreateTemp("", "focus-export-*")
+	if err != nil {
+		return Result{}, err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	count, err := s.Export(ctx, req, tmp)
+	if err != nil {
+		return Result{}, err
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return Result{}, err
+	}
+
+	name := s.FileName(req)
+	location, err := s.sink.Put(ctx, name, tmp)
+	if err != nil {
+		return Result{}, fmt.Errorf("put export file: %w", err)
+	}
+	s.logger.Info("cost export finished",
+		elog.String("tenant_id", req.TenantID),
+		elog.String("location", location),
+		elog.Int64("rows", count))
+	return Result{FileName: name, Location: location, RowCount: count}, nil
+}
+
+// ExportPeriod 导出租户指定日期范围的全部账单到存储，供任务执行器调用
+func (s *ExportService) ExportPeriod(ctx context.Context, tenantID, startDate, endDate, format string) (string, int64, error) {
+	result, err := s.ExportToSink(ctx, Request{
+		TenantID: tenantID,
+		Format:   format,
+		Filter:   repository.UnifiedBillFilter{StartDate: startDate, EndDate: endDate},
+	})
+	return result.Location, result.RowCount, err
+}
+
+// StartScheduledExport 导出上一自然月账单：按活跃云账号所属租户逐个导出，
+// 设置任务提交器时提交异步任务，否则同步执行
+func (s *ExportService) StartScheduledExport(ctx context.Context, format string, now time.Time) error {
+	if s.accountSvc == nil {
+		return fmt.Errorf("account service is not configured")
+	}
+	accounts, _, err := s.accountSvc.ListAccounts(ctx, shareddomain.CloudAccountFilter{
+		Status: shareddomain.CloudAccountStatusActive,
+		Limit:  1000,
+	})
+	if err != nil {
+		return fmt.Errorf("list active accounts: %w", err)
+	}
+
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	startDate := thisMonth.AddDate(0, -1, 0).Format("2006-01-02")
+	endDate := thisMonth.AddDate(0, 0, -1).Format("2006-01-02")
+
+	seen := make(map[string]bool)
+	for _, acct := range accounts {
+		if acct.TenantID == "" || seen[acct.TenantID] {
+			continue
+		}
+		seen[acct.TenantID] = true
+
+		if s.submitter != nil {
+			if _, err := s.submitter.SubmitCostExportTask(ctx, acct.TenantID, startDate, endDate, format); err != nil {
+				s.logger.Error("submit cost export task failed",
+					elog.String("tenant_id", acct.TenantID),
+					elog.FieldErr(err))
+			}
+			continue
+		}
+		if _, _, err := s.ExportPeriod(ctx, acct.TenantID, startDate, endDate, format); err != nil {
+			s.logger.Error("cost export failed for tenant",
+				elog.String("tenant_id", acct.TenantID),
+				elog.FieldErr(err))
+		}
+	}
+	return nil
+}
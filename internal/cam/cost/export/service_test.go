@@ -0,0 +1,281 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockBillDAO 仅实现导出用到的 ListUnifiedBills，按 Offset / Limit 分页返回
+type mockBillDAO struct {
+	repository.BillDAO
+	bills   []domain.UnifiedBill
+	filters []repository.UnifiedBillFilter
+}
+
+func (m *mockBillDAO) ListUnifiedBills(_ context.Context, filter repository.UnifiedBillFilter) ([]domain.UnifiedBill, error) {
+	m.filters = append(m.filters, filter)
+	if filter.Offset >= int64(len(m.bills)) {
+		return nil, nil
+	}
+	end := min(filter.Offset+filter.Limit, int64(len(m.bills)))
+	return m.bills[filter.Offset:end], nil
+}
+
+type mockAccountLister struct {
+	accounts []*shareddomain.CloudAccount
+}
+
+func (m *mockAccountLister) ListAccounts(_ context.Context, _ shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error) {
+	return m.accounts, int64(len(m.accounts)), nil
+}
+
+type submittedExport struct {
+	tenantID, startDate, endDate, format string
+}
+
+type mockSubmitter struct {
+	submitted []submittedExport
+}
+
+func (m *mockSubmitter) SubmitCostExportTask(_ context.Context, tenantID, startDate, endDate, format string) (string, error) {
+	m.submitted = append(m.submitted, submittedExport{tenantID, startDate, endDate, format})
+	return "task-1", nil
+}
+
+func usageBill() domain.UnifiedBill {
+	return domain.UnifiedBill{
+		Provider:        "aliyun",
+		AccountID:       7,
+		AccountName:     "prod",
+		BillingStart:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		BillingEnd:      time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC),
+		ServiceType:     domain.ServiceTypeCompute,
+		ServiceTypeName: "ecs",
+		ResourceID:      "i-1",
+		ResourceName:    "web-1",
+		Region:          "cn-hangzhou",
+		Amount:          80,
+		Currency:        "CNY",
+		AmountCNY:       80,
+		ExchangeRate:    1,
+		ChargeType:      domain.ChargeTypePostpaid,
+		LineType:        domain.LineTypeUsage,
+		ListAmount:      100,
+		DiscountAmount:  20,
+		NetAmount:       80,
+		AmortizedAmount: 80,
+		Tags:            map[string]string{"team": "web"},
+		TenantID:        "tenant-1",
+		BillingDate:     "2024-03-01",
+	}
+}
+
+func newTestService(bills []domain.UnifiedBill) (*ExportService, *mockBillDAO) {
+	billDAO := &mockBillDAO{bills: bills}
+	return NewExportService(billDAO, &mockAccountLister{}, elog.DefaultLogger), billDAO
+}
+
+func exportRequest(format string) Request {
+	return Request{
+		TenantID: "tenant-1",
+		Format:   format,
+		Filter:   repository.UnifiedBillFilter{StartDate: "2024-03-01", EndDate: "2024-03-31"},
+	}
+}
+
+func TestToFocusRow_Usage(t *testing.T) {
+	row := ToFocusRow(usageBill())
+
+	assert.Equal(t, 80.0, row.BilledCost)
+	assert.Equal(t, 80.0, row.EffectiveCost)
+	assert.Equal(t, 100.0, row.ListCost)
+	assert.Equal(t, 80.0, row.ContractedCost)
+	assert.Equal(t, "7", row.BillingAccountId)
+	assert.Equal(t, "Alibaba Cloud", row.ProviderName)
+	assert.Equal(t, "Compute", row.ServiceCategory)
+	assert.Equal(t, "ecs", row.ServiceName)
+	assert.Equal(t, ChargeCategoryUsage, row.ChargeCategory)
+	assert.Equal(t, ChargeFrequencyUsageBased, row.ChargeFrequency)
+	assert.Equal(t, PricingCategoryStandard, row.PricingCategory)
+	assert.Equal(t, `{"team":"web"}`, row.Tags)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), row.BillingPeriodStart)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), row.BillingPeriodEnd)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), row.ChargePeriodEnd)
+}
+
+func TestToFocusRow_LineTypes(t *testing.T) {
+	purchase := usageBill()
+	purchase.LineType = domain.LineTypePurchase
+	purchase.ChargeType = domain.ChargeTypePrepaid
+	purchase.AmortizedAmount = 0
+	row := ToFocusRow(purchase)
+	assert.Equal(t, ChargeCategoryPurchase, row.ChargeCategory)
+	assert.Equal(t, ChargeFrequencyOneTime, row.ChargeFrequency)
+	assert.Equal(t, 80.0, row.BilledCost)
+	assert.Equal(t, 0.0, row.EffectiveCost)
+	assert.Empty(t, row.PricingCategory)
+
+	amortized := purchase
+	amortized.LineType = domain.LineTypeAmortization
+	amortized.Amount, amortized.AmortizedAmount = 0, 6.67
+	amortized.BillingDate = "2024-05-01"
+	amortized.AmortizedFrom = "2024-03-01"
+	row = ToFocusRow(amortized)
+	assert.Equal(t, ChargeCategoryUsage, row.ChargeCategory)
+	assert.Equal(t, ChargeFrequencyRecurring, row.ChargeFrequency)
+	assert.Equal(t, PricingCategoryCommitted, row.PricingCategory)
+	assert.Equal(t, 0.0, row.BilledCost)
+	assert.Equal(t, 6.67, row.EffectiveCost)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), row.BillingPeriodStart)
+	assert.Contains(t, row.ChargeDescription, "amortized from 2024-03-01")
+
+	refund := usageBill()
+	refund.LineType = domain.LineTypeRefund
+	row = ToFocusRow(refund)
+	assert.Equal(t, ChargeCategoryPurchase, row.ChargeCategory)
+	assert.Equal(t, ChargeClassCorrection, row.ChargeClass)
+
+	credit := usageBill()
+	credit.LineType = domain.LineTypeCredit
+	row = ToFocusRow(credit)
+	assert.Equal(t, ChargeCategoryCredit, row.ChargeCategory)
+}
+
+func TestToFocusRow_LegacyBillFallsBackToAmount(t *testing.T) {
+	bill := usageBill()
+	bill.LineType, bill.ListAmount, bill.DiscountAmount, bill.AmortizedAmount = "", 0, 0, 0
+	bill.Provider = "unknown"
+	bill.ServiceType = "other"
+
+	row := ToFocusRow(bill)
+	assert.Equal(t, 80.0, row.ListCost)
+	assert.Equal(t, 80.0, row.ContractedCost)
+	assert.Equal(t, 80.0, row.EffectiveCost)
+	assert.Equal(t, "unknown", row.ProviderName)
+	assert.Equal(t, "Other", row.ServiceCategory)
+}
+
+func TestExport_CSV(t *testing.T) {
+	svc, billDAO := newTestService([]domain.UnifiedBill{usageBill()})
+
+	var buf bytes.Buffer
+	count, err := svc.Export(context.Background(), exportRequest(FormatCSV), &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, FocusColumns, records[0])
+	require.Len(t, records[1], len(FocusColumns))
+
+	record := make(map[string]string)
+	for i, col := range FocusColumns {
+		record[col] = records[1][i]
+	}
+	assert.Equal(t, "80", record["BilledCost"])
+	assert.Equal(t, "2024-03-01T00:00:00Z", record["BillingPeriodStart"])
+	assert.Equal(t, "i-1", record["ResourceId"])
+	assert.Equal(t, `{"team":"web"}`, record["Tags"])
+
+	// 导出包含摊销派生行，租户由请求指定
+	require.NotEmpty(t, billDAO.filters)
+	assert.Equal(t, domain.CostViewAmortized, billDAO.filters[0].View)
+	assert.Equal(t, "tenant-1", billDAO.filters[0].TenantID)
+}
+
+func TestExport_Parquet(t *testing.T) {
+	svc, _ := newTestService([]domain.UnifiedBill{usageBill()})
+
+	var buf bytes.Buffer
+	_, err := svc.Export(context.Background(), exportRequest(FormatParquet), &buf)
+	require.NoError(t, err)
+
+	rows, err := parquet.Read[FocusRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, ToFocusRow(usageBill()), rows[0])
+}
+
+func TestExport_Paginates(t *testing.T) {
+	bills := make([]domain.UnifiedBill, pageSize+3)
+	for i := range bills {
+		bills[i] = usageBill()
+	}
+	svc, billDAO := newTestService(bills)
+
+	var buf bytes.Buffer
+	count, err := svc.Export(context.Background(), exportRequest(FormatCSV), &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(pageSize+3), count)
+	assert.Len(t, billDAO.filters, 2)
+}
+
+func TestExport_InvalidRequest(t *testing.T) {
+	svc, _ := newTestService(nil)
+
+	req := exportRequest("xlsx")
+	_, err := svc.Export(context.Background(), req, &bytes.Buffer{})
+	assert.ErrorIs(t, err, domain.ErrExportInvalid)
+
+	req = exportRequest(FormatCSV)
+	req.Filter.EndDate = "2024-02-01"
+	_, err = svc.Export(context.Background(), req, &bytes.Buffer{})
+	assert.ErrorIs(t, err, domain.ErrExportInvalid)
+}
+
+func TestExportToSink_Local(t *testing.T) {
+	svc, _ := newTestService([]domain.UnifiedBill{usageBill()})
+	_, err := svc.ExportToSink(context.Background(), exportRequest(FormatCSV))
+	assert.ErrorIs(t, err, domain.ErrExportInvalid, "未配置存储")
+
+	dir := filepath.Join(t.TempDir(), "focus")
+	sink, err := NewLocalSink(dir)
+	require.NoError(t, err)
+	svc.SetSink(sink)
+
+	result, err := svc.ExportToSink(context.Background(), exportRequest(FormatParquet))
+	require.NoError(t, err)
+	assert.Equal(t, "focus_tenant-1_2024-03-01_2024-03-31.parquet", result.FileName)
+	assert.Equal(t, filepath.Join(dir, result.FileName), result.Location)
+	assert.Equal(t, int64(1), result.RowCount)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "临时文件已清理")
+	info, err := os.Stat(result.Location)
+	require.NoError(t, err)
+	assert.Positive(t, info.Size())
+}
+
+func TestStartScheduledExport(t *testing.T) {
+	svc, _ := newTestService(nil)
+	svc.accountSvc = &mockAccountLister{accounts: []*shareddomain.CloudAccount{
+		{ID: 1, TenantID: "tenant-1"},
+		{ID: 2, TenantID: "tenant-1"},
+		{ID: 3, TenantID: "tenant-2"},
+		{ID: 4},
+	}}
+	submitter := &mockSubmitter{}
+	svc.SetTaskSubmitter(submitter)
+
+	now := time.Date(2024, 3, 2, 4, 0, 0, 0, time.UTC)
+	require.NoError(t, svc.StartScheduledExport(context.Background(), FormatParquet, now))
+	assert.Equal(t, []submittedExport{
+		{"tenant-1", "2024-02-01", "2024-02-29", FormatParquet},
+		{"tenant-2", "2024-02-01", "2024-02-29", FormatParquet},
+	}, submitter.submitted)
+}
@@ -0,0 +1,97 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// Sink 导出文件存储
+type Sink interface {
+	// Put 写入名为 name 的文件，返回文件位置（本地路径或 oss://bucket/key）
+	Put(ctx context.Context, name string, r io.Reader) (string, error)
+}
+
+// LocalSink 写入本地目录
+type LocalSink struct {
+	dir string
+}
+
+// NewLocalSink 创建本地目录存储，目录不存在时自动创建
+func NewLocalSink(dir string) (*LocalSink, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("export dir is required")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create export dir: %w", err)
+	}
+	return &LocalSink{dir: dir}, nil
+}
+
+// Put 先写临时文件再重命名，避免下游读取到未写完的文件
+func (s *LocalSink) Put(_ context.Context, name string, r io.Reader) (string, error) {
+	target := filepath.Join(s.dir, filepath.Base(name))
+	tmp, err := os.CreateTemp(s.dir, "."+filepath.Base(name)+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// OSSSinkConfig 对象存储配置
+type OSSSinkConfig struct {
+	Endpoint        string `mapstructure:"endpoint"`
+	Bucket          string `mapstructure:"bucket"`
+	Prefix          string `mapstructure:"prefix"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	AccessKeySecret string `mapstructure:"access_key_secret"`
+}
+
+// OSSSink 写入阿里云 OSS（兼容 S3 协议的存储可配置对应 endpoint）
+type OSSSink struct {
+	bucket *oss.Bucket
+	name   string
+	prefix string
+}
+
+// NewOSSSink 创建对象存储
+func NewOSSSink(cfg OSSSinkConfig) (*OSSSink, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("oss endpoint and bucket are required")
+	}
+	client, err := oss.New(cfg.Endpoint, cfg.AccessKeyID, cfg.AccessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("create oss client: %w", err)
+	}
+	bucket, err := client.Bucket(cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("open oss bucket: %w", err)
+	}
+	return &OSSSink{bucket: bucket, name: cfg.Bucket, prefix: strings.Trim(cfg.Prefix, "/")}, nil
+}
+
+func (s *OSSSink) Put(ctx context.Context, name string, r io.Reader) (string, error) {
+	key := path.Join(s.prefix, name)
+	if err := s.bucket.PutObject(key, r, oss.WithContext(ctx)); err != nil {
+		return "", fmt.Errorf("put oss object %s: %w", key, err)
+	}
+	return "oss://" + s.name + "/" + key, nil
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/snappy"
+)
+
+// 导出文件格式
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+)
+
+// rowWriter 按批写入 FOCUS 数据行
+type rowWriter interface {
+	Write(rows []FocusRow) error
+	// Close 刷新缓冲并写入文件尾，不关闭底层 io.Writer
+	Close() error
+}
+
+// newRowWriter 按格式创建写入器
+func newRowWriter(format string, w io.Writer) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(FocusColumns); err != nil {
+			return nil, err
+		}
+		return &csvRowWriter{w: cw}, nil
+	case FormatParquet:
+		return &parquetRowWriter{
+			w: parquet.NewGenericWriter[FocusRow](w, parquet.Compression(&snappy.Codec{})),
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: unsupported format %q", domain.ErrExportInvalid, format)
+}
+
+// ContentType 返回导出格式对应的 MIME 类型
+func ContentType(format string) string {
+	if format == FormatParquet {
+		return "application/vnd.apache.parquet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) Write(rows []FocusRow) error {
+	for _, row := range rows {
+		if err := c.w.Write(row.csvRecord()); err != nil {
+			return err
+		}
+	}
+	// 按批刷新，流式下载时尽早输出
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type parquetRowWriter struct {
+	w *parquet.GenericWriter[FocusRow]
+}
+
+func (p *parquetRowWriter) Write(rows []FocusRow) error {
+	_, err := p.w.Write(rows)
+	return err
+}
+
+func (p *parquetRowWriter) Close() error {
+	return p.w.Close()
+}
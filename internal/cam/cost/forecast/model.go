@@ -0,0 +1,153 @@
+package forecast
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// minSeasonalDays 拟合星期效应所需的最少历史天数（至少两周）
+	minSeasonalDays = 14
+
+	// backfitIterations 趋势与星期效应交替拟合的迭代次数
+	backfitIterations = 20
+)
+
+// DailyPoint 日成本数据点
+type DailyPoint struct {
+	Date   time.Time
+	Amount float64
+}
+
+// Model 线性趋势 + 星期季节性模型
+// amount(t) = intercept + slope*t + weekday[t.Weekday()]，t 为距历史首日的天数
+type Model struct {
+	origin    time.Time
+	intercept float64
+	slope     float64
+	weekday   [7]float64
+	sigma     float64 // 残差标准差
+}
+
+// Fit 基于按日期升序、缺失日补零的历史序列拟合模型
+// 历史首个非零日之前的数据视为尚未开始计费，不参与拟合，避免新账号被拉低趋势
+func Fit(history []DailyPoint) *Model {
+	start := 0
+	for start < len(history) && history[start].Amount == 0 {
+		start++
+	}
+	history = history[start:]
+	if len(history) == 0 {
+		return &Model{}
+	}
+
+	m := &Model{origin: history[0].Date}
+	n := float64(len(history))
+	xs := make([]float64, len(history))
+	ys := make([]float64, len(history))
+	for i, p := range history {
+		xs[i] = m.offset(p.Date)
+		ys[i] = p.Amount
+	}
+
+	seasonal := len(history) >= minSeasonalDays
+	iterations := 1
+	if seasonal {
+		iterations = backfitIterations
+	}
+	// 交替拟合（backfitting）：趋势项拟合去季节序列，季节项取去趋势残差的星期均值，
+	// 收敛到趋势与星期效应的联合最小二乘解；样本不足两周时只拟合趋势
+	deseasonalized := make([]float64, len(ys))
+	for iter := 0; iter < iterations; iter++ {
+		for i, p := range history {
+			deseasonalized[i] = ys[i] - m.weekday[p.Date.Weekday()]
+		}
+		m.intercept, m.slope = linearFit(xs, deseasonalized)
+		if seasonal {
+			m.weekday = weekdayEffects(history, ys, func(i int) float64 { return m.intercept + m.slope*xs[i] })
+		}
+	}
+
+	var sq float64
+	for i, p := range history {
+		r := ys[i] - m.fitted(p.Date)
+		sq += r * r
+	}
+	dof := n - 2
+	if seasonal {
+		dof -= 6
+	}
+	if dof < 1 {
+		dof = 1
+	}
+	m.sigma = math.Sqrt(sq / dof)
+	return m
+}
+
+// Predict 预测指定日期的成本，结果不小于零
+func (m *Model) Predict(date time.Time) float64 {
+	return math.Max(0, m.fitted(date))
+}
+
+// Sigma 单日预测误差的标准差
+func (m *Model) Sigma() float64 {
+	return m.sigma
+}
+
+func (m *Model) fitted(date time.Time) float64 {
+	return m.intercept + m.slope*m.offset(date) + m.weekday[date.Weekday()]
+}
+
+func (m *Model) offset(date time.Time) float64 {
+	if m.origin.IsZero() {
+		return 0
+	}
+	return date.Sub(m.origin).Hours() / 24
+}
+
+// linearFit 最小二乘拟合 y = intercept + slope*x，样本不足两点时斜率为零
+func linearFit(xs, ys []float64) (float64, float64) {
+	meanX, meanY := mean(xs), mean(ys)
+	var sxy, sxx float64
+	for i := range xs {
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+	var slope float64
+	if sxx > 0 {
+		slope = sxy / sxx
+	}
+	return meanY - slope*meanX, slope
+}
+
+// weekdayEffects 按星期几求去趋势残差的均值，并中心化保证季节项不改变整体水平
+func weekdayEffects(history []DailyPoint, ys []float64, trend func(i int) float64) [7]float64 {
+	var sums, counts, effects [7]float64
+	for i, p := range history {
+		wd := p.Date.Weekday()
+		sums[wd] += ys[i] - trend(i)
+		counts[wd]++
+	}
+	var total float64
+	for wd := range sums {
+		if counts[wd] > 0 {
+			effects[wd] = sums[wd] / counts[wd]
+		}
+		total += effects[wd]
+	}
+	for wd := range effects {
+		effects[wd] -= total / 7
+	}
+	return effects
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
@@ -0,0 +1,69 @@
+package forecast
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// series 构造从 start 起 days 天的日成本序列
+func series(start time.Time, days int, fn func(i int, d time.Time) float64) []DailyPoint {
+	points := make([]DailyPoint, days)
+	for i := 0; i < days; i++ {
+		d := start.AddDate(0, 0, i)
+		points[i] = DailyPoint{Date: d, Amount: fn(i, d)}
+	}
+	return points
+}
+
+func TestFit_LinearTrend(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := Fit(series(start, 60, func(i int, _ time.Time) float64 { return 100 + 2*float64(i) }))
+
+	assert.InDelta(t, 100+2*70.0, m.Predict(start.AddDate(0, 0, 70)), 1e-6)
+	assert.InDelta(t, 0, m.Sigma(), 1e-6)
+}
+
+func TestFit_WeekdaySeasonality(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := Fit(series(start, 84, func(_ int, d time.Time) float64 {
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			return 50
+		}
+		return 150
+	}))
+
+	saturday := time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	assert.InDelta(t, 50, m.Predict(saturday), 1)
+	assert.InDelta(t, 150, m.Predict(monday), 1)
+}
+
+func TestFit_IgnoresLeadingZeros(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 前 60 天尚未计费，之后稳定在 100
+	m := Fit(series(start, 90, func(i int, _ time.Time) float64 {
+		if i < 60 {
+			return 0
+		}
+		return 100
+	}))
+	assert.InDelta(t, 100, m.Predict(start.AddDate(0, 0, 100)), 1e-6, "新账号不应被历史零值拉出上升趋势")
+}
+
+func TestFit_Empty(t *testing.T) {
+	m := Fit(nil)
+	assert.Equal(t, 0.0, m.Predict(time.Now()))
+	assert.Equal(t, 0.0, m.Sigma())
+
+	// 单点样本退化为均值
+	m = Fit([]DailyPoint{{Date: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Amount: 80}})
+	assert.Equal(t, 80.0, m.Predict(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestFit_PredictNonNegative(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := Fit(series(start, 30, func(i int, _ time.Time) float64 { return 300 - 10*float64(i) }))
+	assert.Equal(t, 0.0, m.Predict(start.AddDate(0, 0, 60)))
+}
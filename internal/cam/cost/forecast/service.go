@@ -0,0 +1,433 @@
+// Package forecast 成本预测
+package forecast
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// HorizonMonth 预测至月末
+	HorizonMonth = "month"
+	// HorizonQuarter 预测至季末
+	HorizonQuarter = "quarter"
+
+	// historyDays 按日拟合使用的历史天数
+	historyDays = 90
+	// historyMonths 按月拟合使用的历史月数（不含当月）
+	historyMonths = 12
+
+	// GranularityDaily 历史账单按日入账，按日拟合
+	GranularityDaily = "daily"
+	// GranularityMonthly 历史账单按月入账（账单日期均为月初），按月拟合
+	GranularityMonthly = "monthly"
+
+	// defaultConfidence 默认置信水平
+	defaultConfidence = 0.95
+)
+
+// zScores 支持的置信水平对应的正态分位数
+var zScores = map[float64]float64{
+	0.8:  1.2816,
+	0.9:  1.6449,
+	0.95: 1.9600,
+	0.99: 2.5758,
+}
+
+// dimensionFields 预测维度对应的统一账单字段
+var dimensionFields = map[string]string{
+	"provider":      "provider",
+	"cloud_account": "account_name",
+	"service_type":  "service_type",
+	"region":        "region",
+}
+
+// SpendRequest 支出预测请求
+type SpendRequest struct {
+	TenantID   string
+	Currency   string // 为空时使用租户报表币种
+	Horizon    string // month / quarter，为空时为 month；指定 PeriodStart/PeriodEnd 时仅作标识
+	Confidence float64
+	Filter     repository.UnifiedBillFilter // 预测范围（云厂商 / 账号 / 服务类型 / 地域 / 标签）
+	AsOf       time.Time                    // 预测基准日，零值为当天
+	// PeriodStart / PeriodEnd 显式指定预测周期（如预算的季度、年度或自定义周期），非零时忽略 Horizon
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+}
+
+// Forecast 支出预测结果
+// 基准日当天账单尚未出全，实际支出统计至基准日前一天，基准日起至周期末为预测值
+type Forecast struct {
+	Dimension      string          `json:"dimension,omitempty"`
+	DimensionValue string          `json:"dimension_value,omitempty"`
+	Horizon        string          `json:"horizon"`
+	Granularity    string          `json:"granularity"` // 拟合粒度 daily / monthly，按月拟合时不返回逐日预测
+	PeriodStart    string          `json:"period_start"`
+	PeriodEnd      string          `json:"period_end"`
+	Currency       string          `json:"currency"`
+	Confidence     float64         `json:"confidence"`
+	ActualToDate   float64         `json:"actual_to_date"` // 周期开始至基准日前一天的实际支出
+	Forecast       float64         `json:"forecast"`       // 周期末预计总支出（实际 + 剩余日预测）
+	Lower          float64         `json:"lower"`          // 置信区间下界
+	Upper          float64         `json:"upper"`          // 置信区间上界
+	Daily          []ForecastPoint `json:"daily,omitempty"`
+}
+
+// ForecastPoint 剩余日的逐日预测
+type ForecastPoint struct {
+	Date   string  `json:"date"`
+	Amount float64 `json:"amount"`
+	Lower  float64 `json:"lower"`
+	Upper  float64 `json:"upper"`
+}
+
+// SpendForecaster 支出预测能力，供预算管理注入
+type SpendForecaster interface {
+	ForecastSpend(ctx context.Context, req SpendRequest) (*Forecast, error)
+}
+
+// ForecastService 成本预测服务
+type ForecastService struct {
+	billDAO   repository.BillDAO
+	converter exchange.ReportingConverter
+	logger    *elog.Component
+	now       func() time.Time
+}
+
+// NewForecastService 创建成本预测服务
+func NewForecastService(billDAO repository.BillDAO, logger *elog.Component) *ForecastService {
+	return &ForecastService{
+		billDAO: billDAO,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// SetCurrencyConverter 设置报表币种换算器（可选注入，未设置时按人民币预测）
+func (s *ForecastService) SetCurrencyConverter(converter exchange.ReportingConverter) {
+	s.converter = converter
+}
+
+// ForecastSpend 预测指定范围在周期末（月末 / 季末）的总支出
+func (s *ForecastService) ForecastSpend(ctx context.Context, req SpendRequest) (*Forecast, error) {
+	explicit := !req.PeriodStart.IsZero() && !req.PeriodEnd.IsZero()
+	horizon := req.Horizon
+	if explicit {
+		if req.PeriodEnd.Before(req.PeriodStart) {
+			return nil, fmt.Errorf("%w: period end before start", costdomain.ErrForecastInvalid)
+		}
+		// 周期由 setPeriod 覆盖，Horizon 仅作为结果标识（如预算周期 yearly）
+		horizon = HorizonMonth
+	}
+	w, err := s.window(horizon, req.Confidence, req.AsOf)
+	if err != nil {
+		return nil, err
+	}
+	if explicit {
+		w.horizon = req.Horizon
+		w.setPeriod(req.PeriodStart, req.PeriodEnd)
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency, err = s.reportingCurrency(ctx, req.TenantID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	filter := req.Filter
+	filter.TenantID = req.TenantID
+	daily, err := s.billDAO.AggregateDailyAmount(ctx, req.TenantID, w.historyStart, w.historyEnd, filter)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate daily amount: %w", err)
+	}
+	byDate := make(map[string]float64, len(daily))
+	for _, d := range daily {
+		amount, err := s.toReporting(ctx, currency, d.AmountCNY, d.Date)
+		if err != nil {
+			return nil, err
+		}
+		byDate[d.Date] += amount
+	}
+
+	result := w.project(byDate)
+	result.Currency = currency
+	return result, nil
+}
+
+// ForecastByDimension 按维度值分别预测周期末支出，按预测值降序返回
+func (s *ForecastService) ForecastByDimension(
+	ctx context.Context,
+	tenantID, dimension, horizon string,
+	confidence float64,
+) ([]Forecast, error) {
+	field, ok := dimensionFields[dimension]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported dimension %q", costdomain.ErrForecastInvalid, dimension)
+	}
+	w, err := s.window(horizon, confidence, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	currency, err := s.reportingCurrency(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := s.billDAO.AggregateByFieldDaily(ctx, tenantID, field, w.historyStart, w.historyEnd, repository.UnifiedBillFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("aggregate %s daily: %w", dimension, err)
+	}
+	series := make(map[string]map[string]float64)
+	for _, d := range daily {
+		amount, err := s.toReporting(ctx, currency, d.AmountCNY, d.Date)
+		if err != nil {
+			return nil, err
+		}
+		if series[d.Key] == nil {
+			series[d.Key] = make(map[string]float64)
+		}
+		series[d.Key][d.Date] += amount
+	}
+
+	results := make([]Forecast, 0, len(series))
+	for key, byDate := range series {
+		f := w.project(byDate)
+		f.Dimension = dimension
+		f.DimensionValue = key
+		f.Currency = currency
+		// 维度明细不返回逐日预测，避免响应过大
+		f.Daily = nil
+		results = append(results, *f)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Forecast != results[j].Forecast {
+			return results[i].Forecast > results[j].Forecast
+		}
+		return results[i].DimensionValue < results[j].DimensionValue
+	})
+	return results, nil
+}
+
+// window 预测窗口：历史区间、周期区间与置信水平
+type window struct {
+	horizon      string
+	confidence   float64
+	z            float64
+	asOf         time.Time
+	periodStart  time.Time
+	periodEnd    time.Time
+	historyStart string
+	historyEnd   string
+}
+
+func (s *ForecastService) window(horizon string, confidence float64, asOf time.Time) (*window, error) {
+	if horizon == "" {
+		horizon = HorizonMonth
+	}
+	if confidence == 0 {
+		confidence = defaultConfidence
+	}
+	z, ok := zScores[confidence]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported confidence %v", costdomain.ErrForecastInvalid, confidence)
+	}
+	if asOf.IsZero() {
+		asOf = s.now()
+	}
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+
+	w := &window{horizon: horizon, confidence: confidence, z: z, asOf: asOf}
+	switch horizon {
+	case HorizonMonth:
+		w.periodStart = time.Date(asOf.Year(), asOf.Month(), 1, 0, 0, 0, 0, time.UTC)
+		w.periodEnd = w.periodStart.AddDate(0, 1, -1)
+	case HorizonQuarter:
+		firstMonth := time.Month((int(asOf.Month())-1)/3*3 + 1)
+		w.periodStart = time.Date(asOf.Year(), firstMonth, 1, 0, 0, 0, 0, time.UTC)
+		w.periodEnd = w.periodStart.AddDate(0, 3, -1)
+	default:
+		return nil, fmt.Errorf("%w: unsupported horizon %q", costdomain.ErrForecastInvalid, horizon)
+	}
+	w.setPeriod(w.periodStart, w.periodEnd)
+	return w, nil
+}
+
+// setPeriod 设置预测周期；历史区间需同时覆盖模型窗口与周期内已发生部分
+func (w *window) setPeriod(start, end time.Time) {
+	w.periodStart = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	w.periodEnd = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	historyStart := w.asOf.AddDate(0, 0, -historyDays)
+	if monthly := monthStart(w.asOf).AddDate(0, -historyMonths, 0); monthly.Before(historyStart) {
+		historyStart = monthly
+	}
+	if w.periodStart.Before(historyStart) {
+		historyStart = w.periodStart
+	}
+	w.historyStart = historyStart.Format("2006-01-02")
+	w.historyEnd = w.asOf.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// project 汇总周期内实际支出并预测剩余部分
+// 云厂商账单按月入账时（账单日期均为月初）按月拟合，否则按日拟合
+func (w *window) project(byDate map[string]float64) *Forecast {
+	result := &Forecast{
+		Horizon:     w.horizon,
+		PeriodStart: w.periodStart.Format("2006-01-02"),
+		PeriodEnd:   w.periodEnd.Format("2006-01-02"),
+		Confidence:  w.confidence,
+	}
+	// 周期内已发生部分：[periodStart, min(asOf-1, periodEnd)]
+	for d := w.periodStart; d.Before(w.asOf) && !d.After(w.periodEnd); d = d.AddDate(0, 0, 1) {
+		result.ActualToDate += byDate[d.Format("2006-01-02")]
+	}
+
+	if isMonthly(byDate) {
+		result.Granularity = GranularityMonthly
+		w.projectMonthly(byDate, result)
+	} else {
+		result.Granularity = GranularityDaily
+		w.projectDaily(byDate, result)
+	}
+	return result
+}
+
+// projectDaily 基于历史日成本拟合模型，预测剩余各日
+// 剩余各日误差视为独立，总量区间半宽为 z·σ·√剩余天数
+func (w *window) projectDaily(byDate map[string]float64, result *Forecast) {
+	history := make([]DailyPoint, 0, historyDays)
+	for d := w.asOf.AddDate(0, 0, -historyDays); d.Before(w.asOf); d = d.AddDate(0, 0, 1) {
+		history = append(history, DailyPoint{Date: d, Amount: byDate[d.Format("2006-01-02")]})
+	}
+	model := Fit(history)
+
+	// 预测部分：[max(asOf, periodStart), periodEnd]
+	forecastStart := w.asOf
+	if forecastStart.Before(w.periodStart) {
+		forecastStart = w.periodStart
+	}
+	halfBand := w.z * model.Sigma()
+	var predicted float64
+	remaining := 0
+	for d := forecastStart; !d.After(w.periodEnd); d = d.AddDate(0, 0, 1) {
+		amount := model.Predict(d)
+		predicted += amount
+		remaining++
+		result.Daily = append(result.Daily, ForecastPoint{
+			Date:   d.Format("2006-01-02"),
+			Amount: amount,
+			Lower:  math.Max(0, amount-halfBand),
+			Upper:  amount + halfBand,
+		})
+	}
+
+	result.setPredicted(predicted, halfBand*math.Sqrt(float64(remaining)))
+}
+
+// projectMonthly 按月入账的账单：以已结束各月的月度总额拟合趋势（月初日期作为数据点），
+// 当月账单为截至基准日前一天的累计金额，按已过天数外推为全月并与趋势预测按已过天数比例加权，
+// 使月中即可反映当月的支出速度；周期内后续月份直接取趋势预测
+func (w *window) projectMonthly(byDate map[string]float64, result *Forecast) {
+	current := monthStart(w.asOf)
+	history := make([]DailyPoint, 0, historyMonths)
+	fitted := false
+	for m := current.AddDate(0, -historyMonths, 0); m.Before(current); m = m.AddDate(0, 1, 0) {
+		amount := byDate[m.Format("2006-01-02")]
+		history = append(history, DailyPoint{Date: m, Amount: amount})
+		fitted = fitted || amount != 0
+	}
+	model := Fit(history)
+
+	// 当月估计：elapsed 为已出账天数，基准日为月初时当月尚无账单
+	days := float64(current.AddDate(0, 1, -1).Day())
+	elapsed := float64(w.asOf.Day() - 1)
+	monthToDate := byDate[current.Format("2006-01-02")]
+	estimate, sigma := model.Predict(current), model.Sigma()
+	if elapsed > 0 {
+		runRate := monthToDate / elapsed * days
+		if fitted {
+			weight := elapsed / days
+			estimate = weight*runRate + (1-weight)*estimate
+			sigma *= 1 - weight
+		} else {
+			// 无完整月份历史（新账号）时只能按当月速度外推
+			estimate, sigma = runRate, 0
+		}
+	}
+	estimate = math.Max(estimate, monthToDate)
+
+	// 账单计入月初所在周期，与预算已发生支出的统计口径一致
+	var predicted, variance float64
+	for m := monthStart(w.periodStart); !m.After(w.periodEnd); m = m.AddDate(0, 1, 0) {
+		switch {
+		case m.Before(w.periodStart) || m.Before(current):
+			// 月初不在周期内，或已计入实际支出
+		case m.Equal(current):
+			predicted += estimate - monthToDate
+			variance += sigma * sigma
+		case fitted:
+			predicted += model.Predict(m)
+			variance += model.Sigma() * model.Sigma()
+		default:
+			predicted += estimate
+		}
+	}
+	result.setPredicted(predicted, w.z*math.Sqrt(variance))
+}
+
+// setPredicted 设置周期末预测值与置信区间（下界不低于已发生支出）
+func (f *Forecast) setPredicted(predicted, band float64) {
+	f.Forecast = f.ActualToDate + predicted
+	f.Lower = f.ActualToDate + math.Max(0, predicted-band)
+	f.Upper = f.ActualToDate + predicted + band
+}
+
+// isMonthly 有成本的日期均为月初时视为按月入账的账单
+func isMonthly(byDate map[string]float64) bool {
+	found := false
+	for date, amount := range byDate {
+		if amount == 0 {
+			continue
+		}
+		if len(date) != len("2006-01-02") || date[8:] != "01" {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// reportingCurrency 获取租户报表币种，未注入换算器时为 CNY
+func (s *ForecastService) reportingCurrency(ctx context.Context, tenantID string) (string, error) {
+	if s.converter == nil {
+		return exchange.CurrencyCNY, nil
+	}
+	currency, err := s.converter.ReportingCurrency(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("get reporting currency: %w", err)
+	}
+	return currency, nil
+}
+
+// toReporting 将人民币金额按 date 当日汇率折算为目标币种
+func (s *ForecastService) toReporting(ctx context.Context, currency string, amountCNY float64, date string) (float64, error) {
+	if currency == exchange.CurrencyCNY {
+		return amountCNY, nil
+	}
+	if s.converter == nil {
+		return 0, fmt.Errorf("currency %s is not supported without exchange rates", currency)
+	}
+	return s.converter.FromCNY(ctx, currency, amountCNY, date)
+}
@@ -0,0 +1,306 @@
+package forecast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBillDAO struct {
+	aggregateDailyFn func(ctx context.Context, tenantID, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error)
+	fieldDailyFn     func(ctx context.Context, tenantID, field, startDate, endDate string) ([]repository.FieldDailyAmount, error)
+}
+
+func (m *mockBillDAO) SumAmount(_ context.Context, _ repository.UnifiedBillFilter) (float64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) InsertRawBill(_ context.Context, _ costdomain.RawBillRecord) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) InsertRawBills(_ context.Context, _ []costdomain.RawBillRecord) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) GetRawBillByID(_ context.Context, _ int64) (costdomain.RawBillRecord, error) {
+	return costdomain.RawBillRecord{}, nil
+}
+func (m *mockBillDAO) ListRawBills(_ context.Context, _ int64, _, _ string) ([]costdomain.RawBillRecord, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) ListRawBillsByCollectID(_ context.Context, _ string) ([]costdomain.RawBillRecord, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) InsertUnifiedBill(_ context.Context, _ costdomain.UnifiedBill) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) InsertUnifiedBills(_ context.Context, _ []costdomain.UnifiedBill) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) GetUnifiedBillByID(_ context.Context, _ int64) (costdomain.UnifiedBill, error) {
+	return costdomain.UnifiedBill{}, nil
+}
+func (m *mockBillDAO) ListUnifiedBills(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) CountUnifiedBills(_ context.Context, _ repository.UnifiedBillFilter) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) AggregateByField(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.AggregateResult, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) AggregateDailyAmount(ctx context.Context, tenantID string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+	if m.aggregateDailyFn != nil {
+		return m.aggregateDailyFn(ctx, tenantID, startDate, endDate, filter)
+	}
+	return nil, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _, _ string) error { return nil }
+func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) ([]repository.AggregateResult, error) {
+	return nil, nil
+}
+
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, _ []repository.AmountCNYUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	if m.fieldDailyFn != nil {
+		return m.fieldDailyFn(ctx, tenantID, field, startDate, endDate)
+	}
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(_ context.Context, _, _, _, _, _, _ string) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+// fakeConverter 固定报表币种与汇率
+type fakeConverter struct {
+	currency string
+	rate     float64
+}
+
+func (f *fakeConverter) ReportingCurrency(_ context.Context, _ string) (string, error) {
+	return f.currency, nil
+}
+
+func (f *fakeConverter) FromCNY(_ context.Context, _ string, amountCNY float64, _ string) (float64, error) {
+	return amountCNY * f.rate, nil
+}
+
+// asOf 测试基准日 2024-03-10
+var asOf = time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+func setupTestService(billDAO *mockBillDAO) *ForecastService {
+	svc := NewForecastService(billDAO, elog.DefaultLogger)
+	svc.now = func() time.Time { return asOf }
+	return svc
+}
+
+// dailyAmounts 生成 [startDate, endDate] 区间的日成本
+func dailyAmounts(t *testing.T, startDate, endDate string, fn func(d time.Time) float64) []repository.DailyAmount {
+	t.Helper()
+	start, err := time.Parse("2006-01-02", startDate)
+	require.NoError(t, err)
+	end, err := time.Parse("2006-01-02", endDate)
+	require.NoError(t, err)
+	var out []repository.DailyAmount
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		out = append(out, repository.DailyAmount{Date: d.Format("2006-01-02"), AmountCNY: fn(d)})
+	}
+	return out
+}
+
+func TestForecastSpend_MonthEnd(t *testing.T) {
+	var gotFilter repository.UnifiedBillFilter
+	billDAO := &mockBillDAO{
+		aggregateDailyFn: func(_ context.Context, tenantID, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+			assert.Equal(t, "t1", tenantID)
+			assert.Equal(t, "2023-03-01", startDate, "覆盖按月拟合所需的 12 个完整月份")
+			assert.Equal(t, "2024-03-09", endDate, "基准日当天账单不完整，不计入历史")
+			gotFilter = filter
+			return dailyAmounts(t, startDate, endDate, func(time.Time) float64 { return 100 }), nil
+		},
+	}
+	svc := setupTestService(billDAO)
+
+	f, err := svc.ForecastSpend(context.Background(), SpendRequest{
+		TenantID: "t1",
+		Filter:   repository.UnifiedBillFilter{Provider: "aws"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "aws", gotFilter.Provider)
+	assert.Equal(t, HorizonMonth, f.Horizon)
+	assert.Equal(t, GranularityDaily, f.Granularity)
+	assert.Equal(t, "2024-03-01", f.PeriodStart)
+	assert.Equal(t, "2024-03-31", f.PeriodEnd)
+	assert.Equal(t, "CNY", f.Currency)
+	assert.Equal(t, 0.95, f.Confidence)
+	assert.InDelta(t, 900, f.ActualToDate, 1e-6)
+	assert.InDelta(t, 3100, f.Forecast, 1e-6)
+	assert.InDelta(t, 3100, f.Lower, 1e-6, "平稳序列置信区间收敛")
+	assert.InDelta(t, 3100, f.Upper, 1e-6)
+	require.Len(t, f.Daily, 22)
+	assert.Equal(t, "2024-03-10", f.Daily[0].Date)
+	assert.Equal(t, "2024-03-31", f.Daily[21].Date)
+}
+
+// monthStartAmounts 模拟云厂商按月入账的账单：仅月初有金额，当月为截至基准日前一天的累计金额
+func monthStartAmounts(t *testing.T, startDate, endDate string, fn func(month time.Time) float64) []repository.DailyAmount {
+	return dailyAmounts(t, startDate, endDate, func(d time.Time) float64 {
+		if d.Day() != 1 {
+			return 0
+		}
+		return fn(d)
+	})
+}
+
+func TestForecastSpend_MonthlyBills(t *testing.T) {
+	current := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	billDAO := &mockBillDAO{
+		aggregateDailyFn: func(_ context.Context, _, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+			return monthStartAmounts(t, startDate, endDate, func(m time.Time) float64 {
+				if m.Equal(current) {
+					return 1500 // 3 月前 9 天累计，支出速度明显高于以往每月 3000
+				}
+				return 3000
+			}), nil
+		},
+	}
+	svc := setupTestService(billDAO)
+
+	// 当月外推 1500/9*31，与趋势预测 3000 按已过天数 9/31 加权
+	want := 1500 + 22.0/31*3000
+	f, err := svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1"})
+	require.NoError(t, err)
+	assert.Equal(t, GranularityMonthly, f.Granularity)
+	assert.InDelta(t, 1500, f.ActualToDate, 1e-6)
+	assert.InDelta(t, want, f.Forecast, 1e-6, "月中即反映当月支出速度，而非停留在已发生金额")
+	assert.Nil(t, f.Daily)
+
+	q, err := svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1", Horizon: HorizonQuarter})
+	require.NoError(t, err)
+	assert.InDelta(t, 7500, q.ActualToDate, 1e-6)
+	assert.InDelta(t, 6000+want, q.Forecast, 1e-6)
+}
+
+func TestForecastSpend_MonthlyBillsNewAccount(t *testing.T) {
+	billDAO := &mockBillDAO{
+		aggregateDailyFn: func(_ context.Context, _, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+			return []repository.DailyAmount{{Date: "2024-03-01", AmountCNY: 900}}, nil
+		},
+	}
+	svc := setupTestService(billDAO)
+
+	// 无完整月份历史时按当月速度外推，季度内后续月份沿用当月估计
+	f, err := svc.ForecastSpend(context.Background(), SpendRequest{
+		TenantID:    "t1",
+		Horizon:     "custom",
+		PeriodStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, GranularityMonthly, f.Granularity)
+	assert.InDelta(t, 900.0/9*31*2, f.Forecast, 1e-6)
+}
+
+func TestForecastSpend_QuarterInterval(t *testing.T) {
+	billDAO := &mockBillDAO{
+		aggregateDailyFn: func(_ context.Context, _, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+			return dailyAmounts(t, startDate, endDate, func(d time.Time) float64 {
+				if d.Day()%2 == 0 {
+					return 120
+				}
+				return 80
+			}), nil
+		},
+	}
+	svc := setupTestService(billDAO)
+
+	f, err := svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1", Horizon: HorizonQuarter, Confidence: 0.8})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01", f.PeriodStart)
+	assert.Equal(t, "2024-03-31", f.PeriodEnd)
+	assert.Less(t, f.Lower, f.Forecast)
+	assert.Greater(t, f.Upper, f.Forecast)
+	assert.GreaterOrEqual(t, f.Lower, f.ActualToDate, "下界不低于已发生支出")
+
+	wide, err := svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1", Horizon: HorizonQuarter, Confidence: 0.99})
+	require.NoError(t, err)
+	assert.Greater(t, wide.Upper-wide.Lower, f.Upper-f.Lower, "置信水平越高区间越宽")
+}
+
+func TestForecastSpend_Currency(t *testing.T) {
+	billDAO := &mockBillDAO{
+		aggregateDailyFn: func(_ context.Context, _, startDate, endDate string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+			return dailyAmounts(t, startDate, endDate, func(time.Time) float64 { return 100 }), nil
+		},
+	}
+	svc := setupTestService(billDAO)
+	svc.SetCurrencyConverter(&fakeConverter{currency: "USD", rate: 0.5})
+
+	f, err := svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1"})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", f.Currency)
+	assert.InDelta(t, 1550, f.Forecast, 1e-6)
+
+	// 显式币种（预算币种）优先于报表币种
+	f, err = svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1", Currency: "CNY"})
+	require.NoError(t, err)
+	assert.Equal(t, "CNY", f.Currency)
+	assert.InDelta(t, 3100, f.Forecast, 1e-6)
+}
+
+func TestForecastSpend_InvalidRequest(t *testing.T) {
+	svc := setupTestService(&mockBillDAO{})
+	_, err := svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1", Horizon: "year"})
+	assert.ErrorIs(t, err, costdomain.ErrForecastInvalid)
+	_, err = svc.ForecastSpend(context.Background(), SpendRequest{TenantID: "t1", Confidence: 0.5})
+	assert.ErrorIs(t, err, costdomain.ErrForecastInvalid)
+}
+
+func TestForecastByDimension(t *testing.T) {
+	billDAO := &mockBillDAO{
+		fieldDailyFn: func(_ context.Context, _, field, startDate, endDate string) ([]repository.FieldDailyAmount, error) {
+			assert.Equal(t, "account_name", field)
+			var out []repository.FieldDailyAmount
+			for _, d := range dailyAmounts(t, startDate, endDate, func(time.Time) float64 { return 0 }) {
+				out = append(out,
+					repository.FieldDailyAmount{Key: "prod", Date: d.Date, AmountCNY: 200},
+					repository.FieldDailyAmount{Key: "dev", Date: d.Date, AmountCNY: 50},
+				)
+			}
+			return out, nil
+		},
+	}
+	svc := setupTestService(billDAO)
+
+	items, err := svc.ForecastByDimension(context.Background(), "t1", "cloud_account", HorizonMonth, 0)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "prod", items[0].DimensionValue)
+	assert.Equal(t, "cloud_account", items[0].Dimension)
+	assert.InDelta(t, 6200, items[0].Forecast, 1e-6)
+	assert.InDelta(t, 1550, items[1].Forecast, 1e-6)
+	assert.Nil(t, items[0].Daily)
+
+	_, err = svc.ForecastByDimension(context.Background(), "t1", "tag", HorizonMonth, 0)
+	assert.ErrorIs(t, err, costdomain.ErrForecastInvalid)
+}
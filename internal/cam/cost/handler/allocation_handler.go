@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -23,6 +24,7 @@ type CreateAllocationRuleReq struct {
 	TagKey          string                      `json:"tag_key"`
 	TagValueMap     map[string]int64            `json:"tag_value_map"`
 	SharedConfig    *costdomain.SharedConfig    `json:"shared_config"`
+	UsageConfig     *costdomain.UsageConfig     `json:"usage_config"`
 	Priority        int                         `json:"priority"`
 }
 
@@ -34,6 +36,7 @@ type UpdateAllocationRuleReq struct {
 	TagKey          string                      `json:"tag_key"`
 	TagValueMap     map[string]int64            `json:"tag_value_map"`
 	SharedConfig    *costdomain.SharedConfig    `json:"shared_config"`
+	UsageConfig     *costdomain.UsageConfig     `json:"usage_config"`
 	Priority        int                         `json:"priority"`
 }
 
@@ -48,6 +51,12 @@ type ReAllocateReq struct {
 	Period string `json:"period"`
 }
 
+// ImportUsageRecordsReq 推送分摊用量记录请求（如 K8s 命名空间 CPU 核时）
+type ImportUsageRecordsReq struct {
+	Source  string                   `json:"source"` // k8s_namespace | csv
+	Records []costdomain.UsageRecord `json:"records"`
+}
+
 // AllocationHandler 成本分摊 API 处理器
 type AllocationHandler struct {
 	allocationSvc *allocation.AllocationService
@@ -69,6 +78,9 @@ func (h *AllocationHandler) PrivateRoutes(server *gin.Engine) {
 	g.GET("/allocation/by-node/:nodeId", h.GetAllocationByNode)
 	g.GET("/allocation/tree", h.GetAllocationTree)
 	g.POST("/allocation/reallocate", ginx.WrapBody(h.ReAllocateHistory))
+	g.POST("/allocation/usage/records", ginx.WrapBody(h.ImportUsageRecords))
+	g.POST("/allocation/usage/upload", ginx.Wrap(h.UploadUsageCSV))
+	g.GET("/allocation/usage/weights", h.ListUsageWeights)
 }
 
 // CreateAllocationRule 创建分摊规则
@@ -81,6 +93,7 @@ func (h *AllocationHandler) CreateAllocationRule(ctx *gin.Context, req CreateAll
 		TagKey:          req.TagKey,
 		TagValueMap:     req.TagValueMap,
 		SharedConfig:    req.SharedConfig,
+		UsageConfig:     req.UsageConfig,
 		Priority:        req.Priority,
 		TenantID:        tenantID,
 	}
@@ -109,6 +122,7 @@ func (h *AllocationHandler) UpdateAllocationRule(ctx *gin.Context, req UpdateAll
 		TagKey:          req.TagKey,
 		TagValueMap:     req.TagValueMap,
 		SharedConfig:    req.SharedConfig,
+		UsageConfig:     req.UsageConfig,
 		Priority:        req.Priority,
 		TenantID:        tenantID,
 	}
@@ -241,3 +255,58 @@ func (h *AllocationHandler) ReAllocateHistory(ctx *gin.Context, req ReAllocateRe
 
 	return web.Result(gin.H{"message": "分摊计算已提交"}), nil
 }
+
+// ImportUsageRecords 推送分摊用量记录，按 (共享对象, 账期) 整体替换
+func (h *AllocationHandler) ImportUsageRecords(ctx *gin.Context, req ImportUsageRecordsReq) (ginx.Result, error) {
+	n, err := h.allocationSvc.ImportUsageRecords(ctx.Request.Context(), ctx.GetString("tenant_id"), req.Source, req.Records)
+	if err != nil {
+		return usageErrorResult(err), nil
+	}
+	return web.Result(gin.H{"imported": n}), nil
+}
+
+// UploadUsageCSV 上传用量 CSV（period,subject,consumer,metric,value）
+func (h *AllocationHandler) UploadUsageCSV(ctx *gin.Context) (ginx.Result, error) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.ParamsError, "file is required"), nil
+	}
+	file, err := header.Open()
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	defer file.Close()
+
+	n, err := h.allocationSvc.ImportUsageCSV(ctx.Request.Context(), ctx.GetString("tenant_id"), file)
+	if err != nil {
+		return usageErrorResult(err), nil
+	}
+	return web.Result(gin.H{"imported": n}), nil
+}
+
+// ListUsageWeights 查询账期内用量加权规则实际采用的权重
+func (h *AllocationHandler) ListUsageWeights(ctx *gin.Context) {
+	period := ctx.Query("period")
+	if period == "" {
+		ctx.JSON(http.StatusBadRequest, web.ErrorResult(errs.ParamsError))
+		return
+	}
+	ruleID, _ := strconv.ParseInt(ctx.Query("rule_id"), 10, 64)
+
+	weights, err := h.allocationSvc.ListUsageWeights(ctx.Request.Context(), ctx.GetString("tenant_id"), period, ruleID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, web.ErrorResultWithMsg(errs.SystemError, err.Error()))
+		return
+	}
+	ctx.JSON(http.StatusOK, web.Result(gin.H{
+		"items": weights,
+	}))
+}
+
+// usageErrorResult 用量数据错误返回参数错误，其余为系统错误
+func usageErrorResult(err error) ginx.Result {
+	if errors.Is(err, costdomain.ErrUsageInvalid) {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error())
+	}
+	return web.ErrorResultWithMsg(errs.SystemError, err.Error())
+}
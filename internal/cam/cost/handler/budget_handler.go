@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -15,20 +16,34 @@ import (
 
 // CreateBudgetReq 创建预算请求
 type CreateBudgetReq struct {
-	Name        string    `json:"name"`
-	AmountLimit float64   `json:"amount_limit"`
-	ScopeType   string    `json:"scope_type"`
-	ScopeValue  string    `json:"scope_value"`
-	Thresholds  []float64 `json:"thresholds"`
+	Name               string                       `json:"name"`
+	AmountLimit        float64                      `json:"amount_limit"`
+	Currency           string                       `json:"currency"` // 为空时使用租户报表币种
+	Period             string                       `json:"period"`   // monthly / quarterly / yearly / custom，为空时为 monthly
+	StartDate          string                       `json:"start_date"`
+	EndDate            string                       `json:"end_date"`
+	Rollover           bool                         `json:"rollover"`
+	ScopeType          string                       `json:"scope_type"`
+	ScopeValue         string                       `json:"scope_value"`
+	Scopes             []costdomain.DimensionFilter `json:"scopes"` // 多条件范围，如 provider=aws 且 tag team=search
+	Thresholds         []float64                    `json:"thresholds"`
+	ForecastThresholds []float64                    `json:"forecast_thresholds"` // 基于周期末预测支出的阈值，可选
 }
 
 // UpdateBudgetReq 更新预算请求
 type UpdateBudgetReq struct {
-	Name        string    `json:"name"`
-	AmountLimit float64   `json:"amount_limit"`
-	ScopeType   string    `json:"scope_type"`
-	ScopeValue  string    `json:"scope_value"`
-	Thresholds  []float64 `json:"thresholds"`
+	Name               string                       `json:"name"`
+	AmountLimit        float64                      `json:"amount_limit"`
+	Currency           string                       `json:"currency"` // 为空时保留原币种
+	Period             string                       `json:"period"`   // 为空时保留原周期，指定时从当前周期重新跟踪
+	StartDate          string                       `json:"start_date"`
+	EndDate            string                       `json:"end_date"`
+	Rollover           bool                         `json:"rollover"`
+	ScopeType          string                       `json:"scope_type"`
+	ScopeValue         string                       `json:"scope_value"`
+	Scopes             []costdomain.DimensionFilter `json:"scopes"`
+	Thresholds         []float64                    `json:"thresholds"`
+	ForecastThresholds []float64                    `json:"forecast_thresholds"` // 基于周期末预测支出的阈值，可选
 }
 
 // BudgetHandler 预算管理 API 处理器
@@ -47,6 +62,8 @@ func (h *BudgetHandler) PrivateRoutes(server *gin.Engine) {
 	g.POST("/budget", ginx.WrapBody(h.CreateBudget))
 	g.GET("/budget", h.ListBudgets)
 	g.GET("/budget/:id/progress", h.GetBudgetProgress)
+	g.GET("/budget/:id/forecast", ginx.Wrap(h.GetBudgetForecast))
+	g.GET("/budget/:id/history", ginx.Wrap(h.ListBudgetHistory))
 	g.PUT("/budget/:id", ginx.WrapBody(h.UpdateBudget))
 	g.DELETE("/budget/:id", h.DeleteBudget)
 }
@@ -55,12 +72,19 @@ func (h *BudgetHandler) PrivateRoutes(server *gin.Engine) {
 func (h *BudgetHandler) CreateBudget(ctx *gin.Context, req CreateBudgetReq) (ginx.Result, error) {
 	tenantID := ctx.GetString("tenant_id")
 	rule := costdomain.BudgetRule{
-		Name:        req.Name,
-		AmountLimit: req.AmountLimit,
-		ScopeType:   req.ScopeType,
-		ScopeValue:  req.ScopeValue,
-		Thresholds:  req.Thresholds,
-		TenantID:    tenantID,
+		Name:               req.Name,
+		AmountLimit:        req.AmountLimit,
+		Currency:           req.Currency,
+		Period:             req.Period,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		Rollover:           req.Rollover,
+		ScopeType:          req.ScopeType,
+		ScopeValue:         req.ScopeValue,
+		Scopes:             req.Scopes,
+		Thresholds:         req.Thresholds,
+		ForecastThresholds: req.ForecastThresholds,
+		TenantID:           tenantID,
 	}
 
 	id, err := h.budgetSvc.CreateBudget(ctx.Request.Context(), rule)
@@ -112,6 +136,43 @@ func (h *BudgetHandler) GetBudgetProgress(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, web.Result(progress))
 }
 
+// GetBudgetForecast 预算范围支出预测
+// horizon 为空时预测到预算当前周期末，可指定 month / quarter
+func (h *BudgetHandler) GetBudgetForecast(ctx *gin.Context) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return web.ErrorResult(errs.ParamsError), nil
+	}
+
+	result, err := h.budgetSvc.GetBudgetForecast(ctx.Request.Context(), id, ctx.Query("horizon"))
+	if err != nil {
+		if errors.Is(err, costdomain.ErrForecastInvalid) {
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		}
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(result), nil
+}
+
+// ListBudgetHistory 预算已结束周期的实际支出与额度
+func (h *BudgetHandler) ListBudgetHistory(ctx *gin.Context) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return web.ErrorResult(errs.ParamsError), nil
+	}
+	offset, _ := strconv.ParseInt(ctx.DefaultQuery("offset", "0"), 10, 64)
+	limit, _ := strconv.ParseInt(ctx.DefaultQuery("limit", "20"), 10, 64)
+
+	records, total, err := h.budgetSvc.ListBudgetHistory(ctx.Request.Context(), id, offset, limit)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{
+		"items": records,
+		"total": total,
+	}), nil
+}
+
 // UpdateBudget 更新预算规则
 func (h *BudgetHandler) UpdateBudget(ctx *gin.Context, req UpdateBudgetReq) (ginx.Result, error) {
 	idStr := ctx.Param("id")
@@ -122,13 +183,20 @@ func (h *BudgetHandler) UpdateBudget(ctx *gin.Context, req UpdateBudgetReq) (gin
 
 	tenantID := ctx.GetString("tenant_id")
 	rule := costdomain.BudgetRule{
-		ID:          id,
-		Name:        req.Name,
-		AmountLimit: req.AmountLimit,
-		ScopeType:   req.ScopeType,
-		ScopeValue:  req.ScopeValue,
-		Thresholds:  req.Thresholds,
-		TenantID:    tenantID,
+		ID:                 id,
+		Name:               req.Name,
+		AmountLimit:        req.AmountLimit,
+		Currency:           req.Currency,
+		Period:             req.Period,
+		StartDate:          req.StartDate,
+		EndDate:            req.EndDate,
+		Rollover:           req.Rollover,
+		ScopeType:          req.ScopeType,
+		ScopeValue:         req.ScopeValue,
+		Scopes:             req.Scopes,
+		Thresholds:         req.Thresholds,
+		ForecastThresholds: req.ForecastThresholds,
+		TenantID:           tenantID,
 	}
 
 	if err := h.budgetSvc.UpdateBudget(ctx.Request.Context(), rule); err != nil {
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/chargeback"
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
+	"github.com/Havens-blog/e-cam-service/internal/cam/web"
+	"github.com/Havens-blog/e-cam-service/pkg/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+// GenerateChargebackReq 生成分账单请求
+type GenerateChargebackReq struct {
+	Period string `json:"period"` // 账期 YYYY-MM
+}
+
+// DeliverChargebackReq 发送分账单请求
+type DeliverChargebackReq struct {
+	ChannelIDs []int64 `json:"channel_ids"` // 告警通知渠道 ID
+}
+
+// ChargebackHandler 分账单 API 处理器
+type ChargebackHandler struct {
+	chargebackSvc *chargeback.ChargebackService
+}
+
+// NewChargebackHandler 创建分账单处理器
+func NewChargebackHandler(chargebackSvc *chargeback.ChargebackService) *ChargebackHandler {
+	return &ChargebackHandler{chargebackSvc: chargebackSvc}
+}
+
+// PrivateRoutes 注册分账单相关路由
+func (h *ChargebackHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/api/v1/cam")
+	g.POST("/cost/chargeback/statements/generate", ginx.WrapBody(h.Generate))
+	g.GET("/cost/chargeback/statements", h.ListStatements)
+	g.GET("/cost/chargeback/statements/:id", ginx.Wrap(h.GetStatement))
+	g.GET("/cost/chargeback/statements/:id/download", h.Download)
+	g.POST("/cost/chargeback/statements/:id/deliver", ginx.WrapBody(h.Deliver))
+}
+
+// Generate 生成指定账期的分账单，仅返回内容变化后新生成的版本
+func (h *ChargebackHandler) Generate(ctx *gin.Context, req GenerateChargebackReq) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	stmts, err := h.chargebackSvc.GenerateStatements(ctx.Request.Context(), tenantID, req.Period)
+	if err != nil {
+		return chargebackErrorResult(err), nil
+	}
+	return web.Result(stmts), nil
+}
+
+// ListStatements 分账单列表，默认仅返回各对象的最新版本
+func (h *ChargebackHandler) ListStatements(ctx *gin.Context) {
+	tenantID := ctx.GetString("tenant_id")
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	allVersions, _ := strconv.ParseBool(ctx.Query("all_versions"))
+
+	items, total, err := h.chargebackSvc.ListStatements(ctx.Request.Context(), tenantID, repository.ChargebackFilter{
+		Period:      ctx.Query("period"),
+		TargetType:  ctx.Query("target_type"),
+		TargetID:    ctx.Query("target_id"),
+		AllVersions: allVersions,
+		Offset:      int64(offset),
+		Limit:       int64(limit),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, web.ErrorResultWithMsg(errs.SystemError, err.Error()))
+		return
+	}
+	ctx.JSON(http.StatusOK, web.Result(gin.H{
+		"items": items,
+		"total": total,
+	}))
+}
+
+// GetStatement 分账单详情（含版本差异）
+func (h *ChargebackHandler) GetStatement(ctx *gin.Context) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.ParamsError, "无效的分账单 ID"), nil
+	}
+	stmt, err := h.chargebackSvc.GetStatement(ctx.Request.Context(), ctx.GetString("tenant_id"), id)
+	if err != nil {
+		return chargebackErrorResult(err), nil
+	}
+	return web.Result(stmt), nil
+}
+
+// Download 下载分账单，format 支持 pdf（默认）、html、csv
+func (h *ChargebackHandler) Download(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, web.ErrorResultWithMsg(errs.ParamsError, "无效的分账单 ID"))
+		return
+	}
+	data, contentType, filename, err := h.chargebackSvc.RenderStatement(ctx.Request.Context(),
+		ctx.GetString("tenant_id"), id, ctx.DefaultQuery("format", chargeback.FormatPDF))
+	if err != nil {
+		if errors.Is(err, costdomain.ErrChargebackInvalid) {
+			ctx.JSON(http.StatusBadRequest, web.ErrorResultWithMsg(errs.ParamsError, err.Error()))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, web.ErrorResultWithMsg(errs.SystemError, err.Error()))
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// Deliver 通过告警通知渠道发送分账单
+func (h *ChargebackHandler) Deliver(ctx *gin.Context, req DeliverChargebackReq) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.ParamsError, "无效的分账单 ID"), nil
+	}
+	if err := h.chargebackSvc.DeliverStatement(ctx.Request.Context(), ctx.GetString("tenant_id"), id, req.ChannelIDs); err != nil {
+		return chargebackErrorResult(err), nil
+	}
+	return web.Result(nil), nil
+}
+
+// chargebackErrorResult 分账单错误映射：参数错误与不存在返回参数错误，其余为系统错误
+func chargebackErrorResult(err error) ginx.Result {
+	if errors.Is(err, costdomain.ErrChargebackInvalid) {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error())
+	}
+	return web.ErrorResultWithMsg(errs.SystemError, err.Error())
+}
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/collector"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
 	"github.com/Havens-blog/e-cam-service/internal/cam/task"
@@ -38,6 +40,11 @@ func (h *CollectorHandler) PrivateRoutes(server *gin.Engine) {
 	g := server.Group("/api/v1/cam")
 	g.POST("/cost/collect", ginx.WrapBody(h.TriggerManualCollection))
 	g.GET("/cost/collect/logs", h.ListCollectLogs)
+	g.GET("/cost/imports/sources", ginx.Wrap(h.ListImportSources))
+	g.POST("/cost/imports/sources", ginx.WrapBody(h.SaveImportSource))
+	g.PUT("/cost/imports/sources/:id", ginx.WrapBody(h.SaveImportSource))
+	g.DELETE("/cost/imports/sources/:id", ginx.Wrap(h.DeleteImportSource))
+	g.POST("/cost/imports/sources/:id/upload", ginx.Wrap(h.UploadBillFile))
 }
 
 // TriggerManualCollection 手动触发采集（通过异步任务队列）
@@ -85,6 +92,7 @@ func (h *CollectorHandler) ListCollectLogs(ctx *gin.Context) {
 		TenantID:  tenantID,
 		AccountID: accountID,
 		Status:    ctx.Query("status"),
+		Source:    ctx.Query("source"),
 		Offset:    int64(offset),
 		Limit:     int64(limit),
 	}
@@ -99,3 +107,80 @@ func (h *CollectorHandler) ListCollectLogs(ctx *gin.Context) {
 		"total": total,
 	}))
 }
+
+// ListImportSources 账单导入来源列表
+func (h *CollectorHandler) ListImportSources(ctx *gin.Context) (ginx.Result, error) {
+	sources, err := h.collectorSvc.ListImportSources(ctx.Request.Context(), ctx.GetString("tenant_id"))
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(sources), nil
+}
+
+// SaveImportSource 创建（POST）或更新（PUT /:id）账单导入来源
+func (h *CollectorHandler) SaveImportSource(ctx *gin.Context, req domain.BillImportSource) (ginx.Result, error) {
+	req.ID = 0
+	if idStr := ctx.Param("id"); idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return web.ErrorResult(errs.ParamsError), nil
+		}
+		req.ID = id
+	}
+	req.TenantID = ctx.GetString("tenant_id")
+
+	source, err := h.collectorSvc.SaveImportSource(ctx.Request.Context(), req)
+	if err != nil {
+		return importErrorResult(err), nil
+	}
+	return web.Result(source), nil
+}
+
+// DeleteImportSource 删除账单导入来源
+func (h *CollectorHandler) DeleteImportSource(ctx *gin.Context) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return web.ErrorResult(errs.ParamsError), nil
+	}
+	if err := h.collectorSvc.DeleteImportSource(ctx.Request.Context(), ctx.GetString("tenant_id"), id); err != nil {
+		return importErrorResult(err), nil
+	}
+	return web.Result(nil), nil
+}
+
+// UploadBillFile 上传账单文件（multipart 字段 file），按来源列映射同步导入
+func (h *CollectorHandler) UploadBillFile(ctx *gin.Context) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return web.ErrorResult(errs.ParamsError), nil
+	}
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.ParamsError, "file is required"), nil
+	}
+	file, err := header.Open()
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	defer file.Close()
+
+	result, err := h.collectorSvc.ImportBills(ctx.Request.Context(), collector.ImportRequest{
+		SourceID: id,
+		TenantID: ctx.GetString("tenant_id"),
+		FileName: header.Filename,
+		File:     file,
+	})
+	if err != nil {
+		return importErrorResult(err), nil
+	}
+	return web.Result(result), nil
+}
+
+// importErrorResult 来源不存在与文件 / 配置错误返回参数错误，其余返回系统错误
+func importErrorResult(err error) ginx.Result {
+	if errors.Is(err, domain.ErrImportSourceNotFound) || errors.Is(err, domain.ErrImportInvalid) ||
+		errors.Is(err, domain.ErrCollectAlreadyRunning) {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error())
+	}
+	return web.ErrorResultWithMsg(errs.SystemError, err.Error())
+}
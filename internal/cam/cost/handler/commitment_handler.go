@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/commitment"
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
+	"github.com/Havens-blog/e-cam-service/internal/cam/web"
+	"github.com/Havens-blog/e-cam-service/pkg/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+// SyncCommitmentsReq 同步承诺消费请求
+type SyncCommitmentsReq struct {
+	AccountID int64 `json:"account_id"` // 为 0 时同步租户全部活跃云账号
+}
+
+// CommitmentHandler 预留实例 / 节省计划 / 资源包分析 API 处理器
+type CommitmentHandler struct {
+	commitmentSvc *commitment.CommitmentService
+}
+
+// NewCommitmentHandler 创建承诺消费处理器
+func NewCommitmentHandler(commitmentSvc *commitment.CommitmentService) *CommitmentHandler {
+	return &CommitmentHandler{commitmentSvc: commitmentSvc}
+}
+
+// PrivateRoutes 注册承诺消费相关路由
+func (h *CommitmentHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/api/v1/cam")
+	g.GET("/cost/commitments", h.ListCommitments)
+	g.GET("/cost/commitments/coverage", ginx.Wrap(h.GetCoverageReport))
+	g.GET("/cost/commitments/utilization", ginx.Wrap(h.GetUtilizationReport))
+	g.POST("/cost/commitments/sync", ginx.WrapBody(h.SyncCommitments))
+}
+
+// commitmentFilter 从查询参数构建承诺消费筛选条件
+func commitmentFilter(ctx *gin.Context) repository.CommitmentFilter {
+	accountID, _ := strconv.ParseInt(ctx.Query("account_id"), 10, 64)
+	return repository.CommitmentFilter{
+		Provider:  ctx.Query("provider"),
+		AccountID: accountID,
+		Region:    ctx.Query("region"),
+		Type:      ctx.Query("type"),
+		Status:    ctx.Query("status"),
+	}
+}
+
+// ListCommitments 承诺消费列表
+func (h *CommitmentHandler) ListCommitments(ctx *gin.Context) {
+	tenantID := ctx.GetString("tenant_id")
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	filter := commitmentFilter(ctx)
+	filter.Offset = int64(offset)
+	filter.Limit = int64(limit)
+
+	items, total, err := h.commitmentSvc.ListCommitments(ctx.Request.Context(), tenantID, filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, web.ErrorResultWithMsg(errs.SystemError, err.Error()))
+		return
+	}
+	ctx.JSON(http.StatusOK, web.Result(gin.H{
+		"items": items,
+		"total": total,
+	}))
+}
+
+// GetCoverageReport 覆盖率报表（group_by: account / region / instance_family）
+// unsupported_providers 列出租户账号中不支持承诺消费分析、未纳入报表的云厂商
+func (h *CommitmentHandler) GetCoverageReport(ctx *gin.Context) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	groupBy := ctx.DefaultQuery("group_by", commitment.GroupByAccount)
+
+	items, err := h.commitmentSvc.GetCoverageReport(ctx.Request.Context(), tenantID, groupBy, commitmentFilter(ctx))
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+	}
+	unsupported, err := h.commitmentSvc.UnsupportedProviders(ctx.Request.Context(), tenantID)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{
+		"items":                 items,
+		"unsupported_providers": unsupported,
+	}), nil
+}
+
+// GetUtilizationReport 利用率报表（group_by: account / region / instance_family / type）
+// unsupported_providers 含义同覆盖率报表
+func (h *CommitmentHandler) GetUtilizationReport(ctx *gin.Context) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	groupBy := ctx.DefaultQuery("group_by", commitment.GroupByAccount)
+
+	items, err := h.commitmentSvc.GetUtilizationReport(ctx.Request.Context(), tenantID, groupBy, commitmentFilter(ctx))
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+	}
+	unsupported, err := h.commitmentSvc.UnsupportedProviders(ctx.Request.Context(), tenantID)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{
+		"items":                 items,
+		"unsupported_providers": unsupported,
+	}), nil
+}
+
+// SyncCommitments 手动同步承诺消费
+func (h *CommitmentHandler) SyncCommitments(ctx *gin.Context, req SyncCommitmentsReq) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	if req.AccountID == 0 {
+		if err := h.commitmentSvc.SyncAll(ctx.Request.Context(), tenantID); err != nil {
+			return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+		}
+		// 不支持的云厂商在批量同步中跳过，显式返回给调用方
+		unsupported, err := h.commitmentSvc.UnsupportedProviders(ctx.Request.Context(), tenantID)
+		if err != nil {
+			return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+		}
+		return web.Result(gin.H{"unsupported_providers": unsupported}), nil
+	}
+
+	count, err := h.commitmentSvc.SyncAccount(ctx.Request.Context(), req.AccountID)
+	if err != nil {
+		if errors.Is(err, costdomain.ErrCommitmentUnsupported) {
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		}
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{"count": count}), nil
+}
@@ -3,12 +3,16 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/analysis"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/anomaly"
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/export"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/forecast"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/optimizer"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
@@ -23,6 +27,8 @@ type CostHandler struct {
 	costSvc      *analysis.CostService
 	anomalySvc   *anomaly.AnomalyService
 	optimizerSvc *optimizer.OptimizerService
+	forecastSvc  *forecast.ForecastService
+	exportSvc    *export.ExportService
 }
 
 // NewCostHandler 创建成本分析处理器
@@ -30,11 +36,15 @@ func NewCostHandler(
 	costSvc *analysis.CostService,
 	anomalySvc *anomaly.AnomalyService,
 	optimizerSvc *optimizer.OptimizerService,
+	forecastSvc *forecast.ForecastService,
+	exportSvc *export.ExportService,
 ) *CostHandler {
 	return &CostHandler{
 		costSvc:      costSvc,
 		anomalySvc:   anomalySvc,
 		optimizerSvc: optimizerSvc,
+		forecastSvc:  forecastSvc,
+		exportSvc:    exportSvc,
 	}
 }
 
@@ -56,8 +66,15 @@ func (h *CostHandler) PrivateRoutes(server *gin.Engine) {
 	g.GET("/cost/trend", h.GetCostTrend)
 	g.GET("/cost/distribution", h.GetCostDistribution)
 	g.GET("/cost/comparison", h.GetYoYComparison)
+	g.GET("/cost/export", h.ExportCost)
+	g.GET("/cost/forecast", ginx.Wrap(h.GetCostForecast))
+	g.GET("/cost/forecast/breakdown", ginx.Wrap(h.GetCostForecastBreakdown))
 	g.GET("/cost/anomalies", h.GetAnomalyEvents)
 	g.POST("/cost/anomalies/detect", h.TriggerAnomalyDetection)
+	g.GET("/cost/anomalies/models", ginx.Wrap(h.ListAnomalyModels))
+	g.PUT("/cost/anomalies/models", ginx.WrapBody(h.SaveAnomalyModel))
+	g.DELETE("/cost/anomalies/models", ginx.Wrap(h.DeleteAnomalyModel))
+	g.POST("/cost/anomalies/backtest", ginx.WrapBody(h.BacktestAnomalyModels))
 	g.GET("/cost/recommendations", h.ListRecommendations)
 	g.POST("/cost/recommendations/:id/dismiss", ginx.Wrap(h.DismissRecommendation))
 }
@@ -72,6 +89,7 @@ func (h *CostHandler) GetCostSummary(ctx *gin.Context) {
 		Region:      ctx.Query("region"),
 		StartDate:   ctx.Query("start_date"),
 		EndDate:     ctx.Query("end_date"),
+		View:        ctx.Query("view"),
 	}
 	if aid := ctx.Query("account_id"); aid != "" {
 		filter.AccountID, _ = strconv.ParseInt(aid, 10, 64)
@@ -99,6 +117,7 @@ func (h *CostHandler) GetCostTrend(ctx *gin.Context) {
 			Region:      ctx.Query("region"),
 			StartDate:   ctx.Query("start_date"),
 			EndDate:     ctx.Query("end_date"),
+			View:        ctx.Query("view"),
 		},
 		Granularity: ctx.DefaultQuery("granularity", "daily"),
 	}
@@ -128,6 +147,7 @@ func (h *CostHandler) GetCostDistribution(ctx *gin.Context) {
 		Region:      ctx.Query("region"),
 		StartDate:   ctx.Query("start_date"),
 		EndDate:     ctx.Query("end_date"),
+		View:        ctx.Query("view"),
 	}
 	if aid := ctx.Query("account_id"); aid != "" {
 		filter.AccountID, _ = strconv.ParseInt(aid, 10, 64)
@@ -154,6 +174,7 @@ func (h *CostHandler) GetYoYComparison(ctx *gin.Context) {
 		Region:      ctx.Query("region"),
 		StartDate:   ctx.Query("start_date"),
 		EndDate:     ctx.Query("end_date"),
+		View:        ctx.Query("view"),
 	}
 	if aid := ctx.Query("account_id"); aid != "" {
 		filter.AccountID, _ = strconv.ParseInt(aid, 10, 64)
@@ -170,6 +191,93 @@ func (h *CostHandler) GetYoYComparison(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, web.Result(result))
 }
 
+// ExportCost 按 FOCUS 格式导出成本数据（format=csv|parquet），以附件形式流式下载
+func (h *CostHandler) ExportCost(ctx *gin.Context) {
+	req := export.Request{
+		TenantID: getTenantID(ctx),
+		Format:   ctx.Query("format"),
+		Filter: repository.UnifiedBillFilter{
+			Provider:    ctx.Query("provider"),
+			ServiceType: ctx.Query("service_type"),
+			Region:      ctx.Query("region"),
+			StartDate:   ctx.Query("start_date"),
+			EndDate:     ctx.Query("end_date"),
+		},
+	}
+	if aid := ctx.Query("account_id"); aid != "" {
+		req.Filter.AccountID, _ = strconv.ParseInt(aid, 10, 64)
+	}
+	if err := h.exportSvc.Validate(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, web.ErrorResultWithMsg(errs.ParamsError, err.Error()))
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="`+h.exportSvc.FileName(req)+`"`)
+	ctx.Header("Content-Type", export.ContentType(req.Format))
+	ctx.Status(http.StatusOK)
+
+	// 响应头已发送，导出中途失败只能记录日志
+	if _, err := h.exportSvc.Export(ctx.Request.Context(), req, ctx.Writer); err != nil {
+		elog.DefaultLogger.Error("export cost data failed",
+			elog.String("tenant_id", req.TenantID),
+			elog.FieldErr(err))
+	}
+}
+
+// GetCostForecast 月末 / 季末支出预测（含置信区间与剩余日逐日预测）
+func (h *CostHandler) GetCostForecast(ctx *gin.Context) (ginx.Result, error) {
+	req := forecast.SpendRequest{
+		TenantID: getTenantID(ctx),
+		Horizon:  ctx.DefaultQuery("horizon", forecast.HorizonMonth),
+		Filter: repository.UnifiedBillFilter{
+			Provider:    ctx.Query("provider"),
+			ServiceType: ctx.Query("service_type"),
+			Region:      ctx.Query("region"),
+		},
+	}
+	if aid := ctx.Query("account_id"); aid != "" {
+		req.Filter.AccountID, _ = strconv.ParseInt(aid, 10, 64)
+	}
+	if c := ctx.Query("confidence"); c != "" {
+		req.Confidence, _ = strconv.ParseFloat(c, 64)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	result, err := h.forecastSvc.ForecastSpend(reqCtx, req)
+	if err != nil {
+		if errors.Is(err, costdomain.ErrForecastInvalid) {
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		}
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(result), nil
+}
+
+// GetCostForecastBreakdown 按维度值分别预测月末 / 季末支出
+func (h *CostHandler) GetCostForecastBreakdown(ctx *gin.Context) (ginx.Result, error) {
+	tenantID := getTenantID(ctx)
+	dimension := ctx.DefaultQuery("dimension", "provider")
+	horizon := ctx.DefaultQuery("horizon", forecast.HorizonMonth)
+	var confidence float64
+	if c := ctx.Query("confidence"); c != "" {
+		confidence, _ = strconv.ParseFloat(c, 64)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), 15*time.Second)
+	defer cancel()
+
+	items, err := h.forecastSvc.ForecastByDimension(reqCtx, tenantID, dimension, horizon, confidence)
+	if err != nil {
+		if errors.Is(err, costdomain.ErrForecastInvalid) {
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		}
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(items), nil
+}
+
 // TriggerAnomalyDetection 手动触发异常检测
 func (h *CostHandler) TriggerAnomalyDetection(ctx *gin.Context) {
 	tenantID := getTenantID(ctx)
@@ -212,6 +320,72 @@ func (h *CostHandler) GetAnomalyEvents(ctx *gin.Context) {
 	}))
 }
 
+// SaveAnomalyModelReq 保存异常检测模型配置请求
+type SaveAnomalyModelReq struct {
+	Dimension   string  `json:"dimension"` // provider / cloud_account / service_type / region，为空表示租户默认
+	Model       string  `json:"model"`     // mean / seasonal / median_mad / ewma
+	Sensitivity float64 `json:"sensitivity"`
+	Alpha       float64 `json:"alpha"`
+	WindowDays  int     `json:"window_days"`
+}
+
+// ListAnomalyModels 查询可用检测模型及租户配置
+func (h *CostHandler) ListAnomalyModels(ctx *gin.Context) (ginx.Result, error) {
+	tenantID := getTenantID(ctx)
+	configs, err := h.anomalySvc.ListModelConfigs(ctx.Request.Context(), tenantID)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{
+		"models":  anomaly.Models,
+		"configs": configs,
+	}), nil
+}
+
+// SaveAnomalyModel 保存租户维度的检测模型配置
+func (h *CostHandler) SaveAnomalyModel(ctx *gin.Context, req SaveAnomalyModelReq) (ginx.Result, error) {
+	cfg := costdomain.AnomalyModelConfig{
+		TenantID:    getTenantID(ctx),
+		Dimension:   req.Dimension,
+		Model:       req.Model,
+		Sensitivity: req.Sensitivity,
+		Alpha:       req.Alpha,
+		WindowDays:  req.WindowDays,
+	}
+	if err := h.anomalySvc.SaveModelConfig(ctx.Request.Context(), cfg); err != nil {
+		if errors.Is(err, costdomain.ErrAnomalyModelInvalid) {
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		}
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(nil), nil
+}
+
+// DeleteAnomalyModel 删除租户维度的检测模型配置
+func (h *CostHandler) DeleteAnomalyModel(ctx *gin.Context) (ginx.Result, error) {
+	tenantID := getTenantID(ctx)
+	if err := h.anomalySvc.DeleteModelConfig(ctx.Request.Context(), tenantID, ctx.Query("dimension")); err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(nil), nil
+}
+
+// BacktestAnomalyModels 回放历史账单，对比各检测模型的异常数量
+func (h *CostHandler) BacktestAnomalyModels(ctx *gin.Context, req anomaly.BacktestRequest) (ginx.Result, error) {
+	tenantID := getTenantID(ctx)
+	reqCtx, cancel := context.WithTimeout(ctx.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	result, err := h.anomalySvc.Backtest(reqCtx, tenantID, req)
+	if err != nil {
+		if errors.Is(err, costdomain.ErrAnomalyModelInvalid) {
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		}
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(result), nil
+}
+
 // ListRecommendations 优化建议列表
 func (h *CostHandler) ListRecommendations(ctx *gin.Context) {
 	tenantID := getTenantID(ctx)
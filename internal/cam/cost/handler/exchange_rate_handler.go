@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
+	"github.com/Havens-blog/e-cam-service/internal/cam/web"
+	"github.com/Havens-blog/e-cam-service/pkg/ginx"
+	"github.com/gin-gonic/gin"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// ExchangeRateItem 汇率上传条目
+type ExchangeRateItem struct {
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Date          string  `json:"date"` // YYYY-MM-DD
+	Rate          float64 `json:"rate"`
+}
+
+// UploadExchangeRatesReq 手工上传汇率请求
+type UploadExchangeRatesReq struct {
+	Rates []ExchangeRateItem `json:"rates"`
+}
+
+// SyncExchangeRatesReq 同步汇率请求
+type SyncExchangeRatesReq struct {
+	Date string `json:"date"` // YYYY-MM-DD，为空时同步当天
+}
+
+// RecomputeAmountCNYReq 重算历史人民币金额请求
+type RecomputeAmountCNYReq struct {
+	Currency  string `json:"currency"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+}
+
+// SetReportingCurrencyReq 设置租户报表币种请求
+type SetReportingCurrencyReq struct {
+	Currency string `json:"currency"` // ISO 4217 币种代码，如 CNY、USD
+}
+
+// ExchangeRateHandler 汇率管理 API 处理器
+type ExchangeRateHandler struct {
+	exchangeSvc *exchange.ExchangeRateService
+	converter   *exchange.Converter
+}
+
+// NewExchangeRateHandler 创建汇率管理处理器
+func NewExchangeRateHandler(exchangeSvc *exchange.ExchangeRateService, converter *exchange.Converter) *ExchangeRateHandler {
+	return &ExchangeRateHandler{exchangeSvc: exchangeSvc, converter: converter}
+}
+
+// PrivateRoutes 注册汇率管理相关路由
+func (h *ExchangeRateHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/api/v1/cam")
+	g.GET("/cost/exchange-rates", h.ListExchangeRates)
+	g.POST("/cost/exchange-rates", ginx.WrapBody(h.UploadExchangeRates))
+	g.DELETE("/cost/exchange-rates/:id", ginx.Wrap(h.DeleteExchangeRate))
+	g.GET("/cost/exchange-rates/lookup", h.LookupExchangeRate)
+	g.POST("/cost/exchange-rates/sync", ginx.WrapBody(h.SyncExchangeRates))
+	g.POST("/cost/exchange-rates/recompute", ginx.WrapBody(h.RecomputeAmountCNY))
+	g.GET("/cost/settings/reporting-currency", ginx.Wrap(h.GetReportingCurrency))
+	g.PUT("/cost/settings/reporting-currency", ginx.WrapBody(h.SetReportingCurrency))
+}
+
+// ListExchangeRates 汇率列表
+func (h *ExchangeRateHandler) ListExchangeRates(ctx *gin.Context) {
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+
+	filter := repository.ExchangeRateFilter{
+		BaseCurrency:  ctx.Query("base_currency"),
+		QuoteCurrency: ctx.Query("quote_currency"),
+		Source:        ctx.Query("source"),
+		StartDate:     ctx.Query("start_date"),
+		EndDate:       ctx.Query("end_date"),
+		Offset:        int64(offset),
+		Limit:         int64(limit),
+	}
+
+	rates, total, err := h.exchangeSvc.ListRates(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, web.ErrorResultWithMsg(errs.SystemError, err.Error()))
+		return
+	}
+	ctx.JSON(http.StatusOK, web.Result(gin.H{
+		"items": rates,
+		"total": total,
+	}))
+}
+
+// UploadExchangeRates 手工上传汇率（同一币种对同一日期覆盖）
+func (h *ExchangeRateHandler) UploadExchangeRates(ctx *gin.Context, req UploadExchangeRatesReq) (ginx.Result, error) {
+	rates := make([]costdomain.ExchangeRate, 0, len(req.Rates))
+	for _, item := range req.Rates {
+		rates = append(rates, costdomain.ExchangeRate{
+			BaseCurrency:  item.BaseCurrency,
+			QuoteCurrency: item.QuoteCurrency,
+			Date:          item.Date,
+			Rate:          item.Rate,
+			Source:        costdomain.ExchangeRateSourceManual,
+		})
+	}
+
+	count, err := h.exchangeSvc.UploadRates(ctx.Request.Context(), rates)
+	if err != nil {
+		if errors.Is(err, costdomain.ErrExchangeRateInvalid) {
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		}
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{"count": count}), nil
+}
+
+// DeleteExchangeRate 删除汇率
+func (h *ExchangeRateHandler) DeleteExchangeRate(ctx *gin.Context) (ginx.Result, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return web.ErrorResult(errs.ParamsError), nil
+	}
+
+	if err := h.exchangeSvc.DeleteRate(ctx.Request.Context(), id); err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(nil), nil
+}
+
+// LookupExchangeRate 查询指定日期生效的汇率
+func (h *ExchangeRateHandler) LookupExchangeRate(ctx *gin.Context) {
+	from := ctx.Query("from")
+	to := ctx.DefaultQuery("to", exchange.CurrencyCNY)
+	date := ctx.DefaultQuery("date", time.Now().Format("2006-01-02"))
+	if from == "" {
+		ctx.JSON(http.StatusBadRequest, web.ErrorResultWithMsg(errs.ParamsError, "from is required"))
+		return
+	}
+
+	rate, err := h.exchangeSvc.GetRate(ctx.Request.Context(), from, to, date)
+	if err != nil {
+		switch {
+		case errors.Is(err, costdomain.ErrExchangeRateInvalid):
+			ctx.JSON(http.StatusBadRequest, web.ErrorResultWithMsg(errs.ParamsError, err.Error()))
+		case errors.Is(err, costdomain.ErrExchangeRateNotFound):
+			ctx.JSON(http.StatusNotFound, web.ErrorResultWithMsg(errs.ParamsError, err.Error()))
+		default:
+			ctx.JSON(http.StatusInternalServerError, web.ErrorResultWithMsg(errs.SystemError, err.Error()))
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, web.Result(gin.H{
+		"from": from,
+		"to":   to,
+		"date": date,
+		"rate": rate,
+	}))
+}
+
+// SyncExchangeRates 从已注册的数据源同步指定日期的汇率
+func (h *ExchangeRateHandler) SyncExchangeRates(ctx *gin.Context, req SyncExchangeRatesReq) (ginx.Result, error) {
+	date := time.Now()
+	if req.Date != "" {
+		d, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return web.ErrorResultWithMsg(errs.ParamsError, "invalid date format, use YYYY-MM-DD"), nil
+		}
+		date = d
+	}
+
+	count, err := h.exchangeSvc.SyncRates(ctx.Request.Context(), date)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{"count": count}), nil
+}
+
+// RecomputeAmountCNY 按当前汇率重算历史账单人民币金额（异步执行）
+func (h *ExchangeRateHandler) RecomputeAmountCNY(ctx *gin.Context, req RecomputeAmountCNYReq) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	if req.StartDate == "" || req.EndDate == "" || req.StartDate > req.EndDate {
+		return web.ErrorResultWithMsg(errs.ParamsError, "start_date and end_date are required"), nil
+	}
+
+	recomputeReq := exchange.RecomputeRequest{
+		TenantID:  tenantID,
+		Currency:  req.Currency,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	}
+
+	// 异步执行重算，避免 HTTP 超时
+	go func() {
+		bgCtx := context.Background()
+		if _, err := h.exchangeSvc.RecomputeAmountCNY(bgCtx, recomputeReq); err != nil {
+			h.exchangeSvc.Logger().Warn("async RecomputeAmountCNY failed",
+				elog.String("tenant_id", tenantID),
+				elog.String("currency", req.Currency),
+				elog.FieldErr(err))
+		}
+	}()
+
+	return web.Result(gin.H{"message": "汇率重算已提交"}), nil
+}
+
+// GetReportingCurrency 获取当前租户报表币种
+func (h *ExchangeRateHandler) GetReportingCurrency(ctx *gin.Context) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	currency, err := h.converter.ReportingCurrency(ctx.Request.Context(), tenantID)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(gin.H{"currency": currency}), nil
+}
+
+// SetReportingCurrency 设置当前租户报表币种
+// 成本汇总、预算、异常基线与分摊结果均按该币种展示
+func (h *ExchangeRateHandler) SetReportingCurrency(ctx *gin.Context, req SetReportingCurrencyReq) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	if tenantID == "" {
+		return web.ErrorResultWithMsg(errs.ParamsError, "tenant_id is required"), nil
+	}
+
+	if err := h.converter.SetReportingCurrency(ctx.Request.Context(), tenantID, req.Currency); err != nil {
+		switch {
+		case errors.Is(err, costdomain.ErrExchangeRateInvalid), errors.Is(err, costdomain.ErrExchangeRateNotFound):
+			return web.ErrorResultWithMsg(errs.ParamsError, err.Error()), nil
+		default:
+			return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+		}
+	}
+	return web.Result(nil), nil
+}
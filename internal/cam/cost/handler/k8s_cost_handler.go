@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/k8scost"
+	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
+	"github.com/Havens-blog/e-cam-service/internal/cam/web"
+	"github.com/Havens-blog/e-cam-service/pkg/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+// IngestK8sMetricsReq 推送 K8s 节点指标请求（由集群内采集组件按账期上报）
+type IngestK8sMetricsReq struct {
+	Nodes []costdomain.K8sNodeMetrics `json:"nodes"`
+}
+
+// K8sCostHandler 容器成本 API 处理器
+type K8sCostHandler struct {
+	k8sCostSvc *k8scost.K8sCostService
+}
+
+// NewK8sCostHandler 创建容器成本处理器
+func NewK8sCostHandler(k8sCostSvc *k8scost.K8sCostService) *K8sCostHandler {
+	return &K8sCostHandler{k8sCostSvc: k8sCostSvc}
+}
+
+// PrivateRoutes 注册容器成本相关路由
+// 集群 / 命名空间 / 工作负载树形视图使用 /allocation/tree?dim_type=k8s
+func (h *K8sCostHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/api/v1/cam")
+	g.POST("/cost/k8s/metrics", ginx.WrapBody(h.IngestMetrics))
+	g.GET("/cost/k8s/report", ginx.Wrap(h.GetReport))
+}
+
+// IngestMetrics 推送节点容量与 Pod 资源申请 / 用量，重新分摊后生效
+func (h *K8sCostHandler) IngestMetrics(ctx *gin.Context, req IngestK8sMetricsReq) (ginx.Result, error) {
+	n, err := h.k8sCostSvc.IngestNodeMetrics(ctx.Request.Context(), ctx.GetString("tenant_id"), req.Nodes)
+	if err != nil {
+		return k8sCostErrorResult(err), nil
+	}
+	return web.Result(gin.H{"written": n}), nil
+}
+
+// GetReport 按集群、命名空间、工作负载或标签汇总容器成本，空闲容量单独列示
+func (h *K8sCostHandler) GetReport(ctx *gin.Context) (ginx.Result, error) {
+	report, err := h.k8sCostSvc.GetCostReport(ctx.Request.Context(), ctx.GetString("tenant_id"),
+		ctx.Query("period"), ctx.DefaultQuery("group_by", costdomain.K8sGroupByNamespace), ctx.Query("label_key"))
+	if err != nil {
+		return k8sCostErrorResult(err), nil
+	}
+	return web.Result(report), nil
+}
+
+// k8sCostErrorResult 容器成本错误映射：参数错误返回参数错误，其余为系统错误
+func k8sCostErrorResult(err error) ginx.Result {
+	if errors.Is(err, costdomain.ErrK8sCostInvalid) {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error())
+	}
+	return web.ErrorResultWithMsg(errs.SystemError, err.Error())
+}
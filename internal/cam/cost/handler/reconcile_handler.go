@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/reconcile"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
+	"github.com/Havens-blog/e-cam-service/internal/cam/web"
+	"github.com/Havens-blog/e-cam-service/pkg/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+// ReconcileReq 账单对账 / 重新采集请求
+type ReconcileReq struct {
+	AccountID    int64  `json:"account_id"`    // 对账时为 0 表示租户全部活跃云账号
+	BillingMonth string `json:"billing_month"` // 账期 YYYY-MM
+}
+
+// ReconcileHandler 账单对账 API 处理器
+type ReconcileHandler struct {
+	reconcileSvc *reconcile.ReconcileService
+}
+
+// NewReconcileHandler 创建账单对账处理器
+func NewReconcileHandler(reconcileSvc *reconcile.ReconcileService) *ReconcileHandler {
+	return &ReconcileHandler{reconcileSvc: reconcileSvc}
+}
+
+// PrivateRoutes 注册账单对账相关路由
+func (h *ReconcileHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/api/v1/cam")
+	g.GET("/cost/reconciliations", h.ListReconciliations)
+	g.POST("/cost/reconciliations/check", ginx.WrapBody(h.Reconcile))
+	g.POST("/cost/reconciliations/recollect", ginx.WrapBody(h.Recollect))
+}
+
+// ListReconciliations 对账记录列表
+func (h *ReconcileHandler) ListReconciliations(ctx *gin.Context) {
+	tenantID := ctx.GetString("tenant_id")
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	accountID, _ := strconv.ParseInt(ctx.Query("account_id"), 10, 64)
+
+	items, total, err := h.reconcileSvc.ListReconciliations(ctx.Request.Context(), tenantID, repository.ReconcileFilter{
+		Provider:     ctx.Query("provider"),
+		AccountID:    accountID,
+		BillingMonth: ctx.Query("billing_month"),
+		Status:       ctx.Query("status"),
+		Offset:       int64(offset),
+		Limit:        int64(limit),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, web.ErrorResultWithMsg(errs.SystemError, err.Error()))
+		return
+	}
+	ctx.JSON(http.StatusOK, web.Result(gin.H{
+		"items": items,
+		"total": total,
+	}))
+}
+
+// Reconcile 手动对账指定账期
+func (h *ReconcileHandler) Reconcile(ctx *gin.Context, req ReconcileReq) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	if req.AccountID == 0 {
+		if err := h.reconcileSvc.ReconcileMonth(ctx.Request.Context(), tenantID, req.BillingMonth); err != nil {
+			return reconcileErrorResult(err), nil
+		}
+		return web.Result(nil), nil
+	}
+
+	rec, err := h.reconcileSvc.ReconcileAccount(ctx.Request.Context(), tenantID, req.AccountID, req.BillingMonth)
+	if err != nil {
+		return reconcileErrorResult(err), nil
+	}
+	return web.Result(rec), nil
+}
+
+// Recollect 重新采集指定账期（整月替换）并重新对账
+func (h *ReconcileHandler) Recollect(ctx *gin.Context, req ReconcileReq) (ginx.Result, error) {
+	tenantID := ctx.GetString("tenant_id")
+	rec, err := h.reconcileSvc.Recollect(ctx.Request.Context(), tenantID, req.AccountID, req.BillingMonth)
+	if err != nil {
+		return reconcileErrorResult(err), nil
+	}
+	return web.Result(rec), nil
+}
+
+// reconcileErrorResult 对账错误映射：参数错误与采集冲突返回参数错误，其余为系统错误
+func reconcileErrorResult(err error) ginx.Result {
+	if errors.Is(err, costdomain.ErrReconcileInvalid) || errors.Is(err, costdomain.ErrCollectAlreadyRunning) {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error())
+	}
+	return web.ErrorResultWithMsg(errs.SystemError, err.Error())
+}
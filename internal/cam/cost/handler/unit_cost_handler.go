@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/unitcost"
+	"github.com/Havens-blog/e-cam-service/internal/cam/errs"
+	"github.com/Havens-blog/e-cam-service/internal/cam/web"
+	"github.com/Havens-blog/e-cam-service/pkg/ginx"
+	"github.com/gin-gonic/gin"
+)
+
+// IngestBusinessMetricsReq 业务指标上报请求
+type IngestBusinessMetricsReq struct {
+	Points []BusinessMetricPointReq `json:"points"`
+}
+
+// BusinessMetricPointReq 业务指标数据点
+type BusinessMetricPointReq struct {
+	Metric string  `json:"metric"`  // 指标名，如 orders、active_users、api_calls
+	NodeID int64   `json:"node_id"` // 服务树节点 ID
+	Date   string  `json:"date"`    // YYYY-MM-DD
+	Value  float64 `json:"value"`
+}
+
+// UnitCostHandler 单位成本 API 处理器
+type UnitCostHandler struct {
+	unitCostSvc *unitcost.UnitCostService
+}
+
+// NewUnitCostHandler 创建单位成本处理器
+func NewUnitCostHandler(unitCostSvc *unitcost.UnitCostService) *UnitCostHandler {
+	return &UnitCostHandler{unitCostSvc: unitCostSvc}
+}
+
+// PrivateRoutes 注册单位成本相关路由
+func (h *UnitCostHandler) PrivateRoutes(server *gin.Engine) {
+	g := server.Group("/api/v1/cam")
+	g.POST("/cost/unit-economics/metrics", ginx.WrapBody(h.IngestMetrics))
+	g.GET("/cost/unit-economics/metrics/names", ginx.Wrap(h.ListMetricNames))
+	g.GET("/cost/unit-economics/trend", ginx.Wrap(h.GetTrend))
+	g.GET("/cost/unit-economics/product-lines", ginx.Wrap(h.ListProductLines))
+}
+
+// IngestMetrics 上报业务指标（按指标、节点、日期覆盖写入）
+func (h *UnitCostHandler) IngestMetrics(ctx *gin.Context, req IngestBusinessMetricsReq) (ginx.Result, error) {
+	points := make([]costdomain.BusinessMetricPoint, 0, len(req.Points))
+	for _, p := range req.Points {
+		points = append(points, costdomain.BusinessMetricPoint{
+			Metric: p.Metric,
+			NodeID: p.NodeID,
+			Date:   p.Date,
+			Value:  p.Value,
+		})
+	}
+	n, err := h.unitCostSvc.IngestMetrics(ctx.Request.Context(), ctx.GetString("tenant_id"), points)
+	if err != nil {
+		return unitCostErrorResult(err), nil
+	}
+	return web.Result(gin.H{"written": n}), nil
+}
+
+// ListMetricNames 已上报的业务指标名
+func (h *UnitCostHandler) ListMetricNames(ctx *gin.Context) (ginx.Result, error) {
+	names, err := h.unitCostSvc.ListMetricNames(ctx.Request.Context(), ctx.GetString("tenant_id"))
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.SystemError, err.Error()), nil
+	}
+	return web.Result(names), nil
+}
+
+// GetTrend 服务树节点（含子节点）的逐日单位成本趋势
+func (h *UnitCostHandler) GetTrend(ctx *gin.Context) (ginx.Result, error) {
+	nodeID, err := strconv.ParseInt(ctx.Query("node_id"), 10, 64)
+	if err != nil {
+		return web.ErrorResultWithMsg(errs.ParamsError, "无效的节点 ID"), nil
+	}
+	trend, err := h.unitCostSvc.GetUnitCostTrend(ctx.Request.Context(), ctx.GetString("tenant_id"),
+		ctx.Query("metric"), nodeID, ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		return unitCostErrorResult(err), nil
+	}
+	return web.Result(trend), nil
+}
+
+// ListProductLines 各产品线的单位成本趋势，level 为服务树层级（默认 2 = 产品）
+func (h *UnitCostHandler) ListProductLines(ctx *gin.Context) (ginx.Result, error) {
+	level, _ := strconv.Atoi(ctx.Query("level"))
+	trends, err := h.unitCostSvc.ListProductLineUnitCosts(ctx.Request.Context(), ctx.GetString("tenant_id"),
+		ctx.Query("metric"), level, ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		return unitCostErrorResult(err), nil
+	}
+	return web.Result(trends), nil
+}
+
+// unitCostErrorResult 单位成本错误映射：参数错误返回参数错误，其余为系统错误
+func unitCostErrorResult(err error) ginx.Result {
+	if errors.Is(err, costdomain.ErrUnitCostInvalid) {
+		return web.ErrorResultWithMsg(errs.ParamsError, err.Error())
+	}
+	return web.ErrorResultWithMsg(errs.SystemError, err.Error())
+}
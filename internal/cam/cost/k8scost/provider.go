@@ -0,0 +1,53 @@
+package k8scost
+
+import (
+	"context"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+)
+
+// MetricsProvider K8s 资源指标提供方（可插拔：外部推送、Prometheus、metrics-server 等）
+type MetricsProvider interface {
+	// Name 提供方标识，用于日志
+	Name() string
+	// ListNodeMetrics 获取租户账期内各节点的容量与 Pod 资源申请 / 用量
+	ListNodeMetrics(ctx context.Context, tenantID, period string) ([]domain.K8sNodeMetrics, error)
+}
+
+// RecordMetricsProvider 基于外部推送并持久化的节点指标实现 MetricsProvider
+type RecordMetricsProvider struct {
+	dao repository.K8sMetricsDAO
+}
+
+// NewRecordMetricsProvider 创建基于推送记录的指标提供方
+func NewRecordMetricsProvider(dao repository.K8sMetricsDAO) *RecordMetricsProvider {
+	return &RecordMetricsProvider{dao: dao}
+}
+
+func (p *RecordMetricsProvider) Name() string { return "record" }
+
+func (p *RecordMetricsProvider) ListNodeMetrics(ctx context.Context, tenantID, period string) ([]domain.K8sNodeMetrics, error) {
+	return p.dao.ListNodes(ctx, tenantID, period)
+}
+
+// FakeMetricsProvider 固定返回给定节点指标的提供方，用于测试与演示
+type FakeMetricsProvider struct {
+	Nodes []domain.K8sNodeMetrics
+	Err   error
+}
+
+func (p *FakeMetricsProvider) Name() string { return "fake" }
+
+func (p *FakeMetricsProvider) ListNodeMetrics(_ context.Context, tenantID, period string) ([]domain.K8sNodeMetrics, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+	var nodes []domain.K8sNodeMetrics
+	for _, n := range p.Nodes {
+		if n.TenantID == tenantID && n.Period == period {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes, nil
+}
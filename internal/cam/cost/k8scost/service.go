@@ -0,0 +1,265 @@
+// Package k8scost K8s 容器成本分摊：按 Pod 资源申请 / 用量将节点账单拆分到命名空间与工作负载
+package k8scost
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// defaultCPUCostRatio 节点成本中 CPU 所占比例，其余归内存
+const defaultCPUCostRatio = 0.5
+
+// K8sCostService 容器成本服务
+type K8sCostService struct {
+	metricsDAO    repository.K8sMetricsDAO
+	allocationDAO repository.AllocationDAO
+	providers     []MetricsProvider
+	cpuRatio      float64
+	logger        *elog.Component
+}
+
+// NewK8sCostService 创建容器成本服务
+func NewK8sCostService(
+	metricsDAO repository.K8sMetricsDAO,
+	allocationDAO repository.AllocationDAO,
+	logger *elog.Component,
+) *K8sCostService {
+	return &K8sCostService{
+		metricsDAO:    metricsDAO,
+		allocationDAO: allocationDAO,
+		cpuRatio:      defaultCPUCostRatio,
+		logger:        logger,
+	}
+}
+
+// AddMetricsProvider 注册指标提供方，多个提供方上报同一节点时以先注册的为准
+func (s *K8sCostService) AddMetricsProvider(p MetricsProvider) {
+	s.providers = append(s.providers, p)
+}
+
+// SetCPUCostRatio 设置节点成本中 CPU 所占比例（0~1），超出范围时忽略
+func (s *K8sCostService) SetCPUCostRatio(ratio float64) {
+	if ratio >= 0 && ratio <= 1 {
+		s.cpuRatio = ratio
+	}
+}
+
+// IngestNodeMetrics 写入外部推送的节点指标，按 (账期, 集群, 节点) 覆盖
+func (s *K8sCostService) IngestNodeMetrics(ctx context.Context, tenantID string, nodes []domain.K8sNodeMetrics) (int64, error) {
+	if s.metricsDAO == nil {
+		return 0, fmt.Errorf("k8s metrics ingestion is not configured")
+	}
+	if len(nodes) == 0 {
+		return 0, fmt.Errorf("%w: no node metrics", domain.ErrK8sCostInvalid)
+	}
+	for i := range nodes {
+		n := &nodes[i]
+		if err := validateNode(*n); err != nil {
+			return 0, fmt.Errorf("%w: node %d: %v", domain.ErrK8sCostInvalid, i+1, err)
+		}
+		n.TenantID = tenantID
+	}
+
+	written, err := s.metricsDAO.UpsertNodes(ctx, nodes)
+	if err != nil {
+		return 0, fmt.Errorf("save node metrics: %w", err)
+	}
+	return written, nil
+}
+
+func validateNode(n domain.K8sNodeMetrics) error {
+	if n.Cluster == "" || n.NodeName == "" || n.ResourceID == "" {
+		return fmt.Errorf("cluster, node_name and resource_id are required")
+	}
+	if _, err := time.Parse("2006-01", n.Period); err != nil {
+		return fmt.Errorf("invalid period %q", n.Period)
+	}
+	if n.CPUCapacity <= 0 || n.MemoryCapacity <= 0 {
+		return fmt.Errorf("cpu_capacity and memory_capacity must be positive")
+	}
+	for _, p := range n.Pods {
+		if p.Namespace == "" || p.Name == "" {
+			return fmt.Errorf("pod namespace and name are required")
+		}
+		if p.CPURequest < 0 || p.CPUUsage < 0 || p.MemoryRequest < 0 || p.MemoryUsage < 0 {
+			return fmt.Errorf("pod %s/%s has negative resource values", p.Namespace, p.Name)
+		}
+	}
+	return nil
+}
+
+// SplitPlan 实现 allocation.ContainerSplitter：汇总各提供方的节点指标，计算每个节点的拆分份额
+func (s *K8sCostService) SplitPlan(ctx context.Context, tenantID, period string) (map[string][]domain.ContainerShare, error) {
+	plan := make(map[string][]domain.ContainerShare)
+	for _, p := range s.providers {
+		nodes, err := p.ListNodeMetrics(ctx, tenantID, period)
+		if err != nil {
+			return nil, fmt.Errorf("list node metrics from %s: %w", p.Name(), err)
+		}
+		for _, n := range nodes {
+			if n.ResourceID == "" || n.CPUCapacity <= 0 || n.MemoryCapacity <= 0 {
+				s.logger.Warn("skip k8s node without resource id or capacity",
+					elog.String("provider", p.Name()),
+					elog.String("cluster", n.Cluster),
+					elog.String("node", n.NodeName))
+				continue
+			}
+			if _, ok := plan[n.ResourceID]; ok {
+				continue
+			}
+			plan[n.ResourceID] = ComputeShares(n, s.cpuRatio)
+		}
+	}
+	return plan, nil
+}
+
+// ComputeShares 按 Pod 资源占用拆分节点成本
+// 每个 Pod 的 CPU / 内存占用取 max(申请量, 用量)；超卖时按比例缩放到节点容量，剩余容量计入空闲桶。
+// 节点成本按 cpuRatio 分为 CPU 与内存两部分，同一工作负载在节点上的多个 Pod 合并为一个份额
+func ComputeShares(node domain.K8sNodeMetrics, cpuRatio float64) []domain.ContainerShare {
+	var cpuUsed, memUsed float64
+	for _, p := range node.Pods {
+		cpuUsed += math.Max(p.CPURequest, p.CPUUsage)
+		memUsed += math.Max(p.MemoryRequest, p.MemoryUsage)
+	}
+	cpuScale := 1.0
+	if cpuUsed > node.CPUCapacity {
+		cpuScale = node.CPUCapacity / cpuUsed
+	}
+	memScale := 1.0
+	if memUsed > node.MemoryCapacity {
+		memScale = node.MemoryCapacity / memUsed
+	}
+
+	type workloadKey struct{ namespace, workload string }
+	byWorkload := make(map[workloadKey]*domain.ContainerShare)
+	var keys []workloadKey
+	var allocated float64
+	for _, p := range node.Pods {
+		cpuFrac := math.Max(p.CPURequest, p.CPUUsage) * cpuScale / node.CPUCapacity
+		memFrac := math.Max(p.MemoryRequest, p.MemoryUsage) * memScale / node.MemoryCapacity
+		frac := cpuRatio*cpuFrac + (1-cpuRatio)*memFrac
+
+		workload := p.Workload
+		if workload == "" {
+			workload = p.Name
+		}
+		key := workloadKey{p.Namespace, workload}
+		share, ok := byWorkload[key]
+		if !ok {
+			share = &domain.ContainerShare{
+				Cluster:      node.Cluster,
+				NodeName:     node.NodeName,
+				Namespace:    p.Namespace,
+				Workload:     workload,
+				WorkloadKind: p.WorkloadKind,
+				Labels:       p.Labels,
+			}
+			byWorkload[key] = share
+			keys = append(keys, key)
+		}
+		share.Fraction += frac
+		allocated += frac
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].workload < keys[j].workload
+	})
+	shares := make([]domain.ContainerShare, 0, len(keys)+1)
+	for _, k := range keys {
+		if byWorkload[k].Fraction > 0 {
+			shares = append(shares, *byWorkload[k])
+		}
+	}
+	if idle := 1 - allocated; idle > 1e-9 {
+		shares = append(shares, domain.ContainerShare{
+			Cluster:  node.Cluster,
+			NodeName: node.NodeName,
+			Workload: domain.K8sIdleWorkload,
+			Fraction: idle,
+			Idle:     true,
+		})
+	}
+	return shares
+}
+
+// GetCostReport 按集群、命名空间、工作负载或标签汇总账期的容器成本，空闲容量单独列示
+// 命名空间与工作负载跨集群合并（工作负载键为 命名空间/工作负载），按集群查看可使用分摊树
+func (s *K8sCostService) GetCostReport(ctx context.Context, tenantID, period, groupBy, labelKey string) (*domain.K8sCostReport, error) {
+	if _, err := time.Parse("2006-01", period); err != nil {
+		return nil, fmt.Errorf("%w: period must be YYYY-MM", domain.ErrK8sCostInvalid)
+	}
+	switch groupBy {
+	case domain.K8sGroupByCluster, domain.K8sGroupByNamespace, domain.K8sGroupByWorkload:
+	case domain.K8sGroupByLabel:
+		if labelKey == "" {
+			return nil, fmt.Errorf("%w: label_key is required when grouping by label", domain.ErrK8sCostInvalid)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported group_by %q", domain.ErrK8sCostInvalid, groupBy)
+	}
+
+	allocs, err := s.allocationDAO.ListAllocations(ctx, repository.AllocationFilter{
+		TenantID: tenantID,
+		DimType:  domain.DimK8s,
+		Period:   period,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list container allocations: %w", err)
+	}
+
+	report := &domain.K8sCostReport{Period: period, GroupBy: groupBy, LabelKey: labelKey, Currency: exchange.CurrencyCNY}
+	amounts := make(map[string]float64)
+	for i, a := range allocs {
+		if i == 0 && a.Currency != "" {
+			report.Currency = a.Currency
+		}
+		report.TotalAmount += a.TotalAmount
+
+		parts := strings.SplitN(a.DimPath, "/", 3)
+		if len(parts) < 3 {
+			// 集群/__idle__
+			report.IdleAmount += a.TotalAmount
+			continue
+		}
+		var key string
+		switch groupBy {
+		case domain.K8sGroupByCluster:
+			key = parts[0]
+		case domain.K8sGroupByNamespace:
+			key = parts[1]
+		case domain.K8sGroupByWorkload:
+			key = parts[1] + "/" + parts[2]
+		case domain.K8sGroupByLabel:
+			key = a.Labels[labelKey]
+		}
+		amounts[key] += a.TotalAmount
+	}
+
+	for key, amount := range amounts {
+		group := domain.K8sCostGroup{Key: key, Amount: amount}
+		if report.TotalAmount > 0 {
+			group.Pct = amount / report.TotalAmount * 100
+		}
+		report.Groups = append(report.Groups, group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Amount != report.Groups[j].Amount {
+			return report.Groups[i].Amount > report.Groups[j].Amount
+		}
+		return report.Groups[i].Key < report.Groups[j].Key
+	})
+	return report, nil
+}
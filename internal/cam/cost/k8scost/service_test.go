@@ -0,0 +1,241 @@
+package k8scost
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Mock DAO ==========
+
+type mockMetricsDAO struct {
+	nodes []domain.K8sNodeMetrics
+}
+
+func (m *mockMetricsDAO) UpsertNodes(_ context.Context, nodes []domain.K8sNodeMetrics) (int64, error) {
+	m.nodes = append(m.nodes, nodes...)
+	return int64(len(nodes)), nil
+}
+
+func (m *mockMetricsDAO) ListNodes(_ context.Context, tenantID, period string) ([]domain.K8sNodeMetrics, error) {
+	var result []domain.K8sNodeMetrics
+	for _, n := range m.nodes {
+		if n.TenantID == tenantID && n.Period == period {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+type mockAllocationDAO struct {
+	repository.AllocationDAO
+	allocs []domain.CostAllocation
+}
+
+func (m *mockAllocationDAO) ListAllocations(_ context.Context, filter repository.AllocationFilter) ([]domain.CostAllocation, error) {
+	var result []domain.CostAllocation
+	for _, a := range m.allocs {
+		if a.TenantID == filter.TenantID && a.Period == filter.Period && a.DimType == filter.DimType {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// ========== 测试数据 ==========
+
+// testNode 8 核 32 GiB 节点
+func testNode() domain.K8sNodeMetrics {
+	return domain.K8sNodeMetrics{
+		Cluster: "prod", NodeName: "node1", ResourceID: "i-node1", Period: "2026-09", TenantID: "t1",
+		CPUCapacity: 8, MemoryCapacity: 32,
+		Pods: []domain.K8sPodMetrics{
+			// 用量超过申请量时按用量计
+			{Namespace: "shop", Name: "api-1", Workload: "api", WorkloadKind: "Deployment", Labels: map[string]string{"team": "trade"},
+				CPURequest: 1, CPUUsage: 2, MemoryRequest: 8, MemoryUsage: 4},
+			{Namespace: "shop", Name: "api-2", Workload: "api", WorkloadKind: "Deployment", Labels: map[string]string{"team": "trade"},
+				CPURequest: 2, CPUUsage: 1, MemoryRequest: 8, MemoryUsage: 2},
+			// 无工作负载的裸 Pod 按 Pod 名计
+			{Namespace: "ops", Name: "debug", CPURequest: 0, MemoryRequest: 0},
+		},
+	}
+}
+
+func sharesByWorkload(shares []domain.ContainerShare) map[string]float64 {
+	m := make(map[string]float64)
+	for _, s := range shares {
+		m[s.Namespace+"/"+s.Workload] += s.Fraction
+	}
+	return m
+}
+
+// ========== ComputeShares ==========
+
+func TestComputeShares(t *testing.T) {
+	shares := ComputeShares(testNode(), 0.5)
+
+	// api: CPU (2+2)/8 = 0.5，内存 (8+8)/32 = 0.5 -> 0.5；空闲 0.5；零占用的裸 Pod 不产生份额
+	require.Len(t, shares, 2)
+	assert.Equal(t, "api", shares[0].Workload)
+	assert.Equal(t, "Deployment", shares[0].WorkloadKind)
+	assert.Equal(t, map[string]string{"team": "trade"}, shares[0].Labels)
+	assert.InDelta(t, 0.5, shares[0].Fraction, 1e-9)
+	assert.True(t, shares[1].Idle)
+	assert.Equal(t, domain.K8sIdleWorkload, shares[1].Workload)
+	assert.InDelta(t, 0.5, shares[1].Fraction, 1e-9)
+
+	// CPU 权重 1 时只看 CPU 占用
+	shares = ComputeShares(testNode(), 1)
+	assert.InDelta(t, 0.5, sharesByWorkload(shares)["shop/api"], 1e-9)
+}
+
+func TestComputeShares_Overcommit(t *testing.T) {
+	node := testNode()
+	node.Pods = []domain.K8sPodMetrics{
+		{Namespace: "a", Name: "p1", Workload: "w1", CPURequest: 12, MemoryRequest: 16},
+		{Namespace: "b", Name: "p2", Workload: "w2", CPURequest: 4, MemoryRequest: 8},
+	}
+
+	shares := ComputeShares(node, 0.5)
+	byWorkload := sharesByWorkload(shares)
+	// CPU 超卖按比例缩放：w1 12/16，w2 4/16；内存 w1 0.5，w2 0.25，空闲 0.25
+	assert.InDelta(t, 0.5*0.75+0.5*0.5, byWorkload["a/w1"], 1e-9)
+	assert.InDelta(t, 0.5*0.25+0.5*0.25, byWorkload["b/w2"], 1e-9)
+	assert.InDelta(t, 0.5*0.25, byWorkload["/"+domain.K8sIdleWorkload], 1e-9)
+
+	var total float64
+	for _, s := range shares {
+		total += s.Fraction
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+}
+
+func TestComputeShares_NoPods(t *testing.T) {
+	node := testNode()
+	node.Pods = nil
+	shares := ComputeShares(node, 0.5)
+	require.Len(t, shares, 1)
+	assert.True(t, shares[0].Idle)
+	assert.InDelta(t, 1, shares[0].Fraction, 1e-9)
+}
+
+// ========== SplitPlan ==========
+
+func TestSplitPlan(t *testing.T) {
+	svc := NewK8sCostService(nil, nil, elog.DefaultLogger)
+	duplicate := testNode()
+	duplicate.Pods = nil
+	noResource := testNode()
+	noResource.NodeName, noResource.ResourceID = "node2", ""
+	other := testNode()
+	other.TenantID = "t2"
+
+	svc.AddMetricsProvider(&FakeMetricsProvider{Nodes: []domain.K8sNodeMetrics{testNode(), noResource, other}})
+	svc.AddMetricsProvider(&FakeMetricsProvider{Nodes: []domain.K8sNodeMetrics{duplicate}})
+
+	plan, err := svc.SplitPlan(context.Background(), "t1", "2026-09")
+	require.NoError(t, err)
+	require.Len(t, plan, 1)
+	assert.Len(t, plan["i-node1"], 2, "同一节点以先注册的提供方为准")
+
+	svc.AddMetricsProvider(&FakeMetricsProvider{Err: errors.New("unavailable")})
+	_, err = svc.SplitPlan(context.Background(), "t1", "2026-09")
+	assert.Error(t, err)
+}
+
+// ========== IngestNodeMetrics ==========
+
+func TestIngestNodeMetrics(t *testing.T) {
+	dao := &mockMetricsDAO{}
+	svc := NewK8sCostService(dao, nil, elog.DefaultLogger)
+	svc.AddMetricsProvider(NewRecordMetricsProvider(dao))
+	ctx := context.Background()
+
+	node := testNode()
+	node.TenantID = "spoofed"
+	n, err := svc.IngestNodeMetrics(ctx, "t1", []domain.K8sNodeMetrics{node})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	plan, err := svc.SplitPlan(ctx, "t1", "2026-09")
+	require.NoError(t, err)
+	assert.Contains(t, plan, "i-node1", "推送的指标经记录提供方参与拆分")
+
+	invalid := []func(*domain.K8sNodeMetrics){
+		func(n *domain.K8sNodeMetrics) { n.ResourceID = "" },
+		func(n *domain.K8sNodeMetrics) { n.Period = "2026-09-01" },
+		func(n *domain.K8sNodeMetrics) { n.CPUCapacity = 0 },
+		func(n *domain.K8sNodeMetrics) { n.Pods[0].Namespace = "" },
+		func(n *domain.K8sNodeMetrics) { n.Pods[0].MemoryUsage = -1 },
+	}
+	for i, mutate := range invalid {
+		node := testNode()
+		mutate(&node)
+		_, err := svc.IngestNodeMetrics(ctx, "t1", []domain.K8sNodeMetrics{node})
+		assert.ErrorIs(t, err, domain.ErrK8sCostInvalid, "case %d", i)
+	}
+}
+
+// ========== GetCostReport ==========
+
+func TestGetCostReport(t *testing.T) {
+	row := func(path string, amount float64, labels map[string]string) domain.CostAllocation {
+		return domain.CostAllocation{
+			DimType: domain.DimK8s, DimValue: path, DimPath: path, TotalAmount: amount, Labels: labels,
+			Period: "2026-09", Currency: "USD", TenantID: "t1",
+		}
+	}
+	allocDAO := &mockAllocationDAO{allocs: []domain.CostAllocation{
+		row("prod/shop/api", 400, map[string]string{"team": "trade"}),
+		row("prod/shop/worker", 100, map[string]string{"team": "trade"}),
+		row("staging/shop/api", 100, nil),
+		row("prod/search/es", 200, map[string]string{"team": "search"}),
+		row("prod/__idle__", 150, nil),
+		row("staging/__idle__", 50, nil),
+	}}
+	svc := NewK8sCostService(nil, allocDAO, elog.DefaultLogger)
+	ctx := context.Background()
+
+	report, err := svc.GetCostReport(ctx, "t1", "2026-09", domain.K8sGroupByNamespace, "")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", report.Currency)
+	assert.InDelta(t, 1000, report.TotalAmount, 1e-9)
+	assert.InDelta(t, 200, report.IdleAmount, 1e-9)
+	assert.Equal(t, []domain.K8sCostGroup{
+		{Key: "shop", Amount: 600, Pct: 60},
+		{Key: "search", Amount: 200, Pct: 20},
+	}, report.Groups)
+
+	report, err = svc.GetCostReport(ctx, "t1", "2026-09", domain.K8sGroupByWorkload, "")
+	require.NoError(t, err)
+	assert.Equal(t, "shop/api", report.Groups[0].Key)
+	assert.InDelta(t, 500, report.Groups[0].Amount, 1e-9)
+
+	report, err = svc.GetCostReport(ctx, "t1", "2026-09", domain.K8sGroupByCluster, "")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.K8sCostGroup{
+		{Key: "prod", Amount: 700, Pct: 70},
+		{Key: "staging", Amount: 100, Pct: 10},
+	}, report.Groups)
+
+	report, err = svc.GetCostReport(ctx, "t1", "2026-09", domain.K8sGroupByLabel, "team")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.K8sCostGroup{
+		{Key: "trade", Amount: 500, Pct: 50},
+		{Key: "search", Amount: 200, Pct: 20},
+		{Key: "", Amount: 100, Pct: 10},
+	}, report.Groups)
+
+	_, err = svc.GetCostReport(ctx, "t1", "2026-09", domain.K8sGroupByLabel, "")
+	assert.ErrorIs(t, err, domain.ErrK8sCostInvalid)
+	_, err = svc.GetCostReport(ctx, "t1", "2026-09", "pod", "")
+	assert.ErrorIs(t, err, domain.ErrK8sCostInvalid)
+	_, err = svc.GetCostReport(ctx, "t1", "202609", domain.K8sGroupByCluster, "")
+	assert.ErrorIs(t, err, domain.ErrK8sCostInvalid)
+}
@@ -0,0 +1,340 @@
+// Package metrics 资源监控指标同步与缓存
+// 定时通过云监控适配器拉取资源日聚合指标写入 MongoDB 缓存，并作为 optimizer.ResourceMetrics 的实现供优化建议使用
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/optimizer"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	cloudmetrics "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// DefaultSyncDays 定时同步回溯天数（覆盖云监控数据延迟）
+	DefaultSyncDays = 3
+	// MaxSyncDays 单次同步最大回溯天数
+	MaxSyncDays = 30
+	// retentionDays 指标缓存保留天数，到期由 TTL 索引清理
+	retentionDays = 90
+	// diskCostWindowDays 未挂载云盘月成本统计窗口
+	diskCostWindowDays = 30
+	// kindDisk 云盘资源种类（CMDB 模型 UID 后缀）
+	kindDisk = "disk"
+)
+
+// syncResourceTypes 需要同步监控指标的资源类型
+var syncResourceTypes = []string{
+	cloudmetrics.ResourceTypeECS,
+	cloudmetrics.ResourceTypeRDS,
+	cloudmetrics.ResourceTypeRedis,
+}
+
+// unattachedDiskStatuses 云盘未挂载状态（各云厂商状态值统一转小写后比较）
+var unattachedDiskStatuses = map[string]bool{
+	"available":  true, // 阿里云 / 华为云 / AWS / 火山引擎
+	"unattached": true, // 腾讯云
+}
+
+// AccountProvider 云账号查询接口
+type AccountProvider interface {
+	GetAccountWithCredentials(ctx context.Context, id int64) (*shareddomain.CloudAccount, error)
+	ListAccounts(ctx context.Context, filter shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error)
+}
+
+// Resource 资产清单中的资源
+type Resource struct {
+	ResourceID   string
+	ResourceName string
+	Region       string
+	Attributes   map[string]interface{}
+}
+
+// ResourceLister 资产清单查询接口
+type ResourceLister interface {
+	// ListResources 列出云账号下指定种类（ecs / rds / redis / disk）的资源
+	ListResources(ctx context.Context, account *shareddomain.CloudAccount, kind string) ([]Resource, error)
+}
+
+// TaskSubmitter 指标同步任务提交接口（可选注入，未设置时定时同步在当前协程内逐个账号执行）
+type TaskSubmitter interface {
+	SubmitMetricsSyncTask(ctx context.Context, accountID int64, tenantID string, days int) (string, error)
+}
+
+// MetricsService 资源监控指标服务
+type MetricsService struct {
+	metricsDAO repository.MetricsDAO
+	billDAO    repository.BillDAO
+	accountSvc AccountProvider
+	resources  ResourceLister
+	submitter  TaskSubmitter
+	logger     *elog.Component
+	adapterFor func(account *shareddomain.CloudAccount) (cloudmetrics.MetricsAdapter, error)
+	now        func() time.Time
+}
+
+// NewMetricsService 创建资源监控指标服务
+func NewMetricsService(
+	metricsDAO repository.MetricsDAO,
+	billDAO repository.BillDAO,
+	accountSvc AccountProvider,
+	resources ResourceLister,
+	logger *elog.Component,
+) *MetricsService {
+	return &MetricsService{
+		metricsDAO: metricsDAO,
+		billDAO:    billDAO,
+		accountSvc: accountSvc,
+		resources:  resources,
+		logger:     logger,
+		adapterFor: newMetricsAdapter,
+		now:        time.Now,
+	}
+}
+
+// newMetricsAdapter 通过监控适配器注册表创建云账号的适配器
+func newMetricsAdapter(account *shareddomain.CloudAccount) (cloudmetrics.MetricsAdapter, error) {
+	creator, err := cloudmetrics.GetMetricsAdapter(account.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return creator(account)
+}
+
+// SetTaskSubmitter 设置同步任务提交器
+func (s *MetricsService) SetTaskSubmitter(submitter TaskSubmitter) {
+	s.submitter = submitter
+}
+
+// StartScheduledSync 为所有活跃云账号发起指标同步
+// 设置了任务提交器时每个账号提交一个异步任务，否则逐个账号同步；单个账号失败不影响其他账号
+func (s *MetricsService) StartScheduledSync(ctx context.Context) error {
+	accounts, _, err := s.accountSvc.ListAccounts(ctx, shareddomain.CloudAccountFilter{
+		Status: shareddomain.CloudAccountStatusActive,
+		Limit:  1000,
+	})
+	if err != nil {
+		return fmt.Errorf("list active accounts: %w", err)
+	}
+
+	for _, acct := range accounts {
+		if !cloudmetrics.IsMetricsProviderRegistered(acct.Provider) {
+			continue
+		}
+		if s.submitter != nil {
+			if _, err := s.submitter.SubmitMetricsSyncTask(ctx, acct.ID, acct.TenantID, DefaultSyncDays); err != nil {
+				s.logger.Error("submit metrics sync task failed",
+					elog.Int64("account_id", acct.ID),
+					elog.FieldErr(err))
+			}
+			continue
+		}
+		if _, err := s.SyncAccount(ctx, acct.ID, DefaultSyncDays); err != nil {
+			s.logger.Error("sync metrics failed for account",
+				elog.Int64("account_id", acct.ID),
+				elog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+// SyncAccount 同步云账号下 ECS / RDS / Redis 资源最近 days 天的日聚合指标，返回写入的记录数
+// 资源按地域与类型分组拉取，单组失败记录日志后继续
+func (s *MetricsService) SyncAccount(ctx context.Context, accountID int64, days int) (int64, error) {
+	if days <= 0 {
+		days = DefaultSyncDays
+	}
+	days = min(days, MaxSyncDays)
+
+	account, err := s.accountSvc.GetAccountWithCredentials(ctx, accountID)
+	if err != nil {
+		return 0, fmt.Errorf("get account %d: %w", accountID, err)
+	}
+	adapter, err := s.adapterFor(account)
+	if err != nil {
+		return 0, fmt.Errorf("create metrics adapter for %s: %w", account.Provider, err)
+	}
+
+	end := s.now()
+	start := end.AddDate(0, 0, -days)
+
+	var total int64
+	for _, resourceType := range syncResourceTypes {
+		resources, err := s.resources.ListResources(ctx, account, resourceType)
+		if err != nil {
+			return total, fmt.Errorf("list %s resources: %w", resourceType, err)
+		}
+		for region, ids := range groupByRegion(resources) {
+			daily, err := adapter.FetchDailyMetrics(ctx, cloudmetrics.FetchMetricsParams{
+				Region:       region,
+				ResourceType: resourceType,
+				ResourceIDs:  ids,
+				StartTime:    start,
+				EndTime:      end,
+			})
+			if err != nil {
+				s.logger.Warn("fetch daily metrics failed",
+					elog.Int64("account_id", accountID),
+					elog.String("region", region),
+					elog.String("resource_type", resourceType),
+					elog.FieldErr(err))
+				continue
+			}
+			n, err := s.metricsDAO.UpsertDaily(ctx, s.toDomain(account, region, daily))
+			if err != nil {
+				return total, fmt.Errorf("upsert daily metrics: %w", err)
+			}
+			total += n
+		}
+	}
+
+	s.logger.Info("metrics sync completed",
+		elog.Int64("account_id", accountID),
+		elog.Int("days", days),
+		elog.Int64("count", total))
+	return total, nil
+}
+
+// groupByRegion 按地域分组资源 ID，无地域的资源跳过
+func groupByRegion(resources []Resource) map[string][]string {
+	groups := make(map[string][]string)
+	for _, r := range resources {
+		if r.Region == "" || r.ResourceID == "" {
+			continue
+		}
+		groups[r.Region] = append(groups[r.Region], r.ResourceID)
+	}
+	return groups
+}
+
+// toDomain 转换为缓存记录，过期时间为指标日期 + 保留天数
+func (s *MetricsService) toDomain(account *shareddomain.CloudAccount, region string, daily []cloudmetrics.DailyMetric) []domain.ResourceMetricDaily {
+	items := make([]domain.ResourceMetricDaily, 0, len(daily))
+	for _, m := range daily {
+		date, err := time.Parse("2006-01-02", m.Date)
+		if err != nil {
+			continue
+		}
+		items = append(items, domain.ResourceMetricDaily{
+			TenantID:      account.TenantID,
+			AccountID:     account.ID,
+			Provider:      string(account.Provider),
+			Region:        region,
+			ResourceType:  m.ResourceType,
+			ResourceID:    m.ResourceID,
+			Date:          m.Date,
+			CPUAvg:        m.CPUAvg,
+			CPUMax:        m.CPUMax,
+			MemoryAvg:     m.MemoryAvg,
+			MemoryMax:     m.MemoryMax,
+			HasMemory:     m.HasMemory,
+			NetworkInBps:  m.NetworkInBps,
+			NetworkOutBps: m.NetworkOutBps,
+			DiskReadIOPS:  m.DiskReadIOPS,
+			DiskWriteIOPS: m.DiskWriteIOPS,
+			ExpireAt:      date.AddDate(0, 0, retentionDays),
+		})
+	}
+	return items
+}
+
+// listDaily 查询资源最近 days 天（不含今天）的缓存指标
+func (s *MetricsService) listDaily(ctx context.Context, resourceID string, days int) ([]domain.ResourceMetricDaily, error) {
+	today := s.now()
+	startDate := today.AddDate(0, 0, -days).Format("2006-01-02")
+	endDate := today.AddDate(0, 0, -1).Format("2006-01-02")
+	return s.metricsDAO.ListDaily(ctx, resourceID, startDate, endDate)
+}
+
+// GetCPUUtilization 获取资源过去 N 天的每日 CPU 利用率（实现 optimizer.ResourceMetrics）
+func (s *MetricsService) GetCPUUtilization(ctx context.Context, resourceID string, days int) ([]optimizer.DailyCPU, error) {
+	items, err := s.listDaily(ctx, resourceID, days)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]optimizer.DailyCPU, 0, len(items))
+	for _, item := range items {
+		result = append(result, optimizer.DailyCPU{Date: item.Date, AvgCPU: item.CPUAvg, MaxCPU: item.CPUMax})
+	}
+	return result, nil
+}
+
+// GetMemoryUtilization 获取资源过去 N 天的每日内存利用率，未采集到内存指标的日期不返回
+func (s *MetricsService) GetMemoryUtilization(ctx context.Context, resourceID string, days int) ([]optimizer.DailyMemory, error) {
+	items, err := s.listDaily(ctx, resourceID, days)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]optimizer.DailyMemory, 0, len(items))
+	for _, item := range items {
+		if !item.HasMemory {
+			continue
+		}
+		result = append(result, optimizer.DailyMemory{Date: item.Date, AvgMemory: item.MemoryAvg, MaxMemory: item.MemoryMax})
+	}
+	return result, nil
+}
+
+// GetUnattachedDisks 获取租户下未挂载的云盘（基于资产清单中的挂载状态），月成本取最近 30 天账单
+func (s *MetricsService) GetUnattachedDisks(ctx context.Context, tenantID string) ([]optimizer.DiskInfo, error) {
+	accounts, _, err := s.accountSvc.ListAccounts(ctx, shareddomain.CloudAccountFilter{
+		Status:   shareddomain.CloudAccountStatusActive,
+		TenantID: tenantID,
+		Limit:    1000,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list active accounts: %w", err)
+	}
+
+	now := s.now()
+	startDate := now.AddDate(0, 0, -diskCostWindowDays).Format("2006-01-02")
+	endDate := now.Format("2006-01-02")
+
+	var disks []optimizer.DiskInfo
+	for _, acct := range accounts {
+		resources, err := s.resources.ListResources(ctx, acct, kindDisk)
+		if err != nil {
+			return nil, fmt.Errorf("list disks of account %d: %w", acct.ID, err)
+		}
+		for _, r := range resources {
+			if !isUnattached(r.Attributes) {
+				continue
+			}
+			cost, err := s.billDAO.SumAmount(ctx, repository.UnifiedBillFilter{
+				TenantID:   tenantID,
+				ResourceID: r.ResourceID,
+				StartDate:  startDate,
+				EndDate:    endDate,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("sum disk cost %s: %w", r.ResourceID, err)
+			}
+			disks = append(disks, optimizer.DiskInfo{
+				ResourceID:   r.ResourceID,
+				ResourceName: r.ResourceName,
+				Provider:     string(acct.Provider),
+				AccountID:    acct.ID,
+				Region:       r.Region,
+				MonthlyCost:  cost,
+			})
+		}
+	}
+	sort.Slice(disks, func(i, j int) bool { return disks[i].MonthlyCost > disks[j].MonthlyCost })
+	return disks, nil
+}
+
+// isUnattached 云盘未关联实例且处于可挂载状态
+func isUnattached(attrs map[string]interface{}) bool {
+	if id, _ := attrs["instance_id"].(string); id != "" {
+		return false
+	}
+	status, _ := attrs["status"].(string)
+	return unattachedDiskStatuses[strings.ToLower(status)]
+}
@@ -0,0 +1,318 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/optimizer"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	cloudmetrics "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Mock MetricsDAO ==========
+
+type mockMetricsDAO struct {
+	items map[string]costdomain.ResourceMetricDaily // resource_id/date -> 指标
+}
+
+func newMockMetricsDAO() *mockMetricsDAO {
+	return &mockMetricsDAO{items: make(map[string]costdomain.ResourceMetricDaily)}
+}
+
+func (m *mockMetricsDAO) UpsertDaily(_ context.Context, items []costdomain.ResourceMetricDaily) (int64, error) {
+	for _, item := range items {
+		m.items[item.ResourceID+"/"+item.Date] = item
+	}
+	return int64(len(items)), nil
+}
+
+func (m *mockMetricsDAO) ListDaily(_ context.Context, resourceID string, startDate, endDate string) ([]costdomain.ResourceMetricDaily, error) {
+	var result []costdomain.ResourceMetricDaily
+	for _, item := range m.items {
+		if item.ResourceID == resourceID && item.Date >= startDate && item.Date <= endDate {
+			result = append(result, item)
+		}
+	}
+	// 按日期升序
+	for i := 1; i < len(result); i++ {
+		for j := i; j > 0 && result[j].Date < result[j-1].Date; j-- {
+			result[j], result[j-1] = result[j-1], result[j]
+		}
+	}
+	return result, nil
+}
+
+// ========== Mock AccountProvider ==========
+
+type mockAccountProvider struct {
+	accounts []*shareddomain.CloudAccount
+}
+
+func (m *mockAccountProvider) GetAccountWithCredentials(_ context.Context, id int64) (*shareddomain.CloudAccount, error) {
+	for _, a := range m.accounts {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return nil, assert.AnError
+}
+func (m *mockAccountProvider) ListAccounts(_ context.Context, _ shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error) {
+	return m.accounts, int64(len(m.accounts)), nil
+}
+
+// ========== Mock ResourceLister ==========
+
+type mockResourceLister struct {
+	resources map[string][]Resource // kind -> 资源
+}
+
+func (m *mockResourceLister) ListResources(_ context.Context, _ *shareddomain.CloudAccount, kind string) ([]Resource, error) {
+	return m.resources[kind], nil
+}
+
+// ========== Mock TaskSubmitter ==========
+
+type mockSubmitter struct {
+	accountIDs []int64
+}
+
+func (m *mockSubmitter) SubmitMetricsSyncTask(_ context.Context, accountID int64, _ string, _ int) (string, error) {
+	m.accountIDs = append(m.accountIDs, accountID)
+	return "task-1", nil
+}
+
+// ========== Mock BillDAO ==========
+
+type mockBillDAO struct {
+	sums map[string]float64 // resource_id -> 金额
+}
+
+func (m *mockBillDAO) SumAmount(_ context.Context, filter repository.UnifiedBillFilter) (float64, error) {
+	return m.sums[filter.ResourceID], nil
+}
+
+// Stub methods for BillDAO interface
+func (m *mockBillDAO) ListUnifiedBills(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+	return nil, nil
+}
+
+// Stub methods for BillDAO interface
+func (m *mockBillDAO) InsertRawBill(_ context.Context, _ costdomain.RawBillRecord) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) InsertRawBills(_ context.Context, _ []costdomain.RawBillRecord) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) GetRawBillByID(_ context.Context, _ int64) (costdomain.RawBillRecord, error) {
+	return costdomain.RawBillRecord{}, nil
+}
+func (m *mockBillDAO) ListRawBills(_ context.Context, _ int64, _, _ string) ([]costdomain.RawBillRecord, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) ListRawBillsByCollectID(_ context.Context, _ string) ([]costdomain.RawBillRecord, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) InsertUnifiedBill(_ context.Context, _ costdomain.UnifiedBill) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) InsertUnifiedBills(_ context.Context, _ []costdomain.UnifiedBill) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) GetUnifiedBillByID(_ context.Context, _ int64) (costdomain.UnifiedBill, error) {
+	return costdomain.UnifiedBill{}, nil
+}
+func (m *mockBillDAO) CountUnifiedBills(_ context.Context, _ repository.UnifiedBillFilter) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) AggregateByField(_ context.Context, _, _, _, _ string, _ repository.UnifiedBillFilter) ([]repository.AggregateResult, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) AggregateDailyAmount(_ context.Context, _, _, _ string, _ repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
+	return nil, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _, _ string) error { return nil }
+func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) ([]repository.AggregateResult, error) {
+	return nil, nil
+}
+
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, _ []repository.AmountCNYUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(_ context.Context, _, _, _, _, _, _ string) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+// ========== Tests ==========
+
+var testNow = time.Date(2024, 3, 10, 9, 0, 0, 0, time.Local)
+
+func aliyunAccount() *shareddomain.CloudAccount {
+	return &shareddomain.CloudAccount{ID: 1, Provider: shareddomain.CloudProviderAliyun, TenantID: "tenant1"}
+}
+
+func newTestService(dao *mockMetricsDAO, lister *mockResourceLister, bills *mockBillDAO, adapter *cloudmetrics.FakeAdapter) *MetricsService {
+	svc := NewMetricsService(dao, bills, &mockAccountProvider{accounts: []*shareddomain.CloudAccount{aliyunAccount()}}, lister, elog.DefaultLogger)
+	svc.adapterFor = func(*shareddomain.CloudAccount) (cloudmetrics.MetricsAdapter, error) { return adapter, nil }
+	svc.now = func() time.Time { return testNow }
+	return svc
+}
+
+// fakeDailyCPU 生成 3 月 1 日起连续 days 天的指标
+func fakeDailyCPU(resourceID, resourceType string, days int, cpu, memory float64) []cloudmetrics.DailyMetric {
+	var result []cloudmetrics.DailyMetric
+	for i := 0; i < days; i++ {
+		result = append(result, cloudmetrics.DailyMetric{
+			ResourceID:   resourceID,
+			ResourceType: resourceType,
+			Date:         time.Date(2024, 3, 1+i, 0, 0, 0, 0, time.UTC).Format("2006-01-02"),
+			CPUAvg:       cpu,
+			CPUMax:       cpu * 2,
+			MemoryAvg:    memory,
+			MemoryMax:    memory,
+			HasMemory:    memory > 0,
+		})
+	}
+	return result
+}
+
+func TestSyncAccount(t *testing.T) {
+	fake := cloudmetrics.NewFakeAdapter(shareddomain.CloudProviderAliyun)
+	fake.AddMetrics("cn-hangzhou", fakeDailyCPU("i-1", cloudmetrics.ResourceTypeECS, 9, 3, 20)...)
+	fake.AddMetrics("cn-beijing", fakeDailyCPU("rm-1", cloudmetrics.ResourceTypeRDS, 9, 10, 0)...)
+
+	lister := &mockResourceLister{resources: map[string][]Resource{
+		cloudmetrics.ResourceTypeECS: {
+			{ResourceID: "i-1", Region: "cn-hangzhou"},
+			{ResourceID: "i-no-region"},
+		},
+		cloudmetrics.ResourceTypeRDS: {{ResourceID: "rm-1", Region: "cn-beijing"}},
+	}}
+	dao := newMockMetricsDAO()
+	svc := newTestService(dao, lister, &mockBillDAO{}, fake)
+
+	count, err := svc.SyncAccount(context.Background(), 1, 7)
+	require.NoError(t, err)
+	// 2024-03-03 ~ 2024-03-09 共 7 天 × 2 个资源
+	assert.Equal(t, int64(14), count)
+
+	calls := fake.Calls()
+	require.Len(t, calls, 2, "redis has no resources, region-less ECS is skipped")
+	assert.Equal(t, []string{"i-1"}, calls[0].ResourceIDs)
+	assert.Equal(t, testNow.AddDate(0, 0, -7), calls[0].StartTime)
+
+	item := dao.items["i-1/2024-03-05"]
+	assert.Equal(t, "tenant1", item.TenantID)
+	assert.Equal(t, int64(1), item.AccountID)
+	assert.Equal(t, "aliyun", item.Provider)
+	assert.Equal(t, "cn-hangzhou", item.Region)
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), item.ExpireAt)
+}
+
+func TestSyncAccount_AdapterErrorSkipsGroup(t *testing.T) {
+	fake := cloudmetrics.NewFakeAdapter(shareddomain.CloudProviderAliyun)
+	fake.SetError(errors.New("throttled"))
+	lister := &mockResourceLister{resources: map[string][]Resource{
+		cloudmetrics.ResourceTypeECS: {{ResourceID: "i-1", Region: "cn-hangzhou"}},
+	}}
+	svc := newTestService(newMockMetricsDAO(), lister, &mockBillDAO{}, fake)
+
+	count, err := svc.SyncAccount(context.Background(), 1, 0)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Equal(t, testNow.AddDate(0, 0, -DefaultSyncDays), fake.Calls()[0].StartTime)
+
+	_, err = svc.SyncAccount(context.Background(), 404, 3)
+	assert.Error(t, err)
+}
+
+func TestResourceMetrics_FromCache(t *testing.T) {
+	fake := cloudmetrics.NewFakeAdapter(shareddomain.CloudProviderAliyun)
+	fake.AddMetrics("cn-hangzhou", fakeDailyCPU("i-1", cloudmetrics.ResourceTypeECS, 9, 3, 20)...)
+	fake.AddMetrics("cn-hangzhou", fakeDailyCPU("i-2", cloudmetrics.ResourceTypeECS, 9, 50, 0)...)
+	lister := &mockResourceLister{resources: map[string][]Resource{
+		cloudmetrics.ResourceTypeECS: {{ResourceID: "i-1", Region: "cn-hangzhou"}, {ResourceID: "i-2", Region: "cn-hangzhou"}},
+	}}
+	svc := newTestService(newMockMetricsDAO(), lister, &mockBillDAO{}, fake)
+	_, err := svc.SyncAccount(context.Background(), 1, MaxSyncDays)
+	require.NoError(t, err)
+
+	var metrics optimizer.ResourceMetrics = svc
+	cpu, err := metrics.GetCPUUtilization(context.Background(), "i-1", 7)
+	require.NoError(t, err)
+	require.Len(t, cpu, 7)
+	assert.Equal(t, "2024-03-03", cpu[0].Date)
+	assert.Equal(t, 3.0, cpu[0].AvgCPU)
+	assert.Equal(t, 6.0, cpu[0].MaxCPU)
+
+	mem, err := metrics.GetMemoryUtilization(context.Background(), "i-1", 7)
+	require.NoError(t, err)
+	assert.Len(t, mem, 7)
+
+	mem, err = metrics.GetMemoryUtilization(context.Background(), "i-2", 7)
+	require.NoError(t, err)
+	assert.Empty(t, mem, "days without memory metrics are not returned")
+}
+
+func TestGetUnattachedDisks(t *testing.T) {
+	lister := &mockResourceLister{resources: map[string][]Resource{
+		kindDisk: {
+			{ResourceID: "d-1", ResourceName: "data", Region: "cn-hangzhou", Attributes: map[string]interface{}{"status": "Available", "instance_id": ""}},
+			{ResourceID: "d-2", Region: "cn-hangzhou", Attributes: map[string]interface{}{"status": "In_use", "instance_id": "i-1"}},
+			{ResourceID: "d-3", Region: "cn-hangzhou", Attributes: map[string]interface{}{"status": "UNATTACHED"}},
+			{ResourceID: "d-4", Region: "cn-hangzhou", Attributes: map[string]interface{}{"status": "Creating"}},
+		},
+	}}
+	bills := &mockBillDAO{sums: map[string]float64{"d-1": 30, "d-3": 90}}
+	svc := newTestService(newMockMetricsDAO(), lister, bills, cloudmetrics.NewFakeAdapter(shareddomain.CloudProviderAliyun))
+
+	disks, err := svc.GetUnattachedDisks(context.Background(), "tenant1")
+	require.NoError(t, err)
+	require.Len(t, disks, 2)
+	assert.Equal(t, "d-3", disks[0].ResourceID)
+	assert.Equal(t, 90.0, disks[0].MonthlyCost)
+	assert.Equal(t, "d-1", disks[1].ResourceID)
+	assert.Equal(t, "data", disks[1].ResourceName)
+	assert.Equal(t, "aliyun", disks[1].Provider)
+	assert.Equal(t, int64(1), disks[1].AccountID)
+}
+
+func TestStartScheduledSync(t *testing.T) {
+	// 未注册监控适配器的云厂商跳过
+	fake := cloudmetrics.NewFakeAdapter(shareddomain.CloudProviderAliyun)
+	cloudmetrics.RegisterMetricsAdapter(shareddomain.CloudProviderAliyun, fake.Creator())
+
+	submitter := &mockSubmitter{}
+	svc := newTestService(newMockMetricsDAO(), &mockResourceLister{}, &mockBillDAO{}, cloudmetrics.NewFakeAdapter(shareddomain.CloudProviderAliyun))
+	svc.accountSvc = &mockAccountProvider{accounts: []*shareddomain.CloudAccount{
+		aliyunAccount(),
+		{ID: 2, Provider: "unknown_cloud"},
+	}}
+	svc.SetTaskSubmitter(submitter)
+
+	require.NoError(t, svc.StartScheduledSync(context.Background()))
+	assert.Equal(t, []int64{1}, submitter.accountIDs)
+}
@@ -0,0 +1,383 @@
+package normalizer
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+// maxAmortizationMonths 单笔预付费购买最多摊销的月数（5 年）
+const maxAmortizationMonths = 60
+
+// chargeBreakdown 从原始账单中提取的金额明细（原币种）
+type chargeBreakdown struct {
+	chargeType string
+	lineType   string
+	list       float64
+	discount   float64
+	credit     float64
+	net        float64
+	// amortized 云厂商直接给出的摊销金额（如 AWS AmortizedCost），nil 表示需自行计算
+	amortized    *float64
+	serviceStart time.Time
+	serviceEnd   time.Time
+}
+
+// chargeExtractor 各云厂商金额明细提取函数
+type chargeExtractor func(item billing.RawBillItem, c *chargeBreakdown)
+
+var chargeExtractors = map[shareddomain.CloudProvider]chargeExtractor{
+	shareddomain.CloudProviderAliyun:     extractAliyunCharges,
+	shareddomain.CloudProviderAWS:        extractAWSCharges,
+	shareddomain.CloudProviderHuawei:     extractHuaweiCharges,
+	shareddomain.CloudProviderTencent:    extractTencentCharges,
+	shareddomain.CloudProviderVolcano:    extractVolcanoCharges,
+	shareddomain.CloudProviderVolcengine: extractVolcanoCharges,
+	shareddomain.CloudProviderGCP:        extractGCPCharges,
+	shareddomain.CloudProviderAzure:      extractAzureCharges,
+}
+
+// extractCharges 提取原价、折扣、抵扣、净额及计费方式，缺失字段按账单金额兜底
+func extractCharges(item billing.RawBillItem) chargeBreakdown {
+	c := chargeBreakdown{
+		chargeType: domain.ChargeTypePostpaid,
+		lineType:   domain.LineTypeUsage,
+		list:       item.Amount,
+		net:        item.Amount,
+	}
+	if ct := rawString(item.RawData, "ChargeType"); ct != "" {
+		c.chargeType = ct
+	}
+	if extract, ok := chargeExtractors[item.Provider]; ok {
+		extract(item, &c)
+	}
+
+	switch {
+	case c.lineType == domain.LineTypeRefund:
+	case item.Amount < 0:
+		// 非退款的负数行视为抵扣（代金券、赠金等）
+		c.lineType = domain.LineTypeCredit
+		c.list, c.discount, c.credit, c.net = 0, 0, -item.Amount, item.Amount
+	case c.chargeType == domain.ChargeTypePrepaid || c.chargeType == domain.ChargeTypeReserved:
+		c.lineType = domain.LineTypePurchase
+	}
+	return c
+}
+
+func extractAliyunCharges(item billing.RawBillItem, c *chargeBreakdown) {
+	raw := item.RawData
+	if gross, ok := rawFloat(raw, "PretaxGrossAmount"); ok {
+		c.list = gross
+	}
+	c.discount = rawSum(raw, "InvoiceDiscount", "DeductedByCoupons")
+	c.credit = rawSum(raw, "DeductedByCashCoupons", "DeductedByPrepaidCard")
+	c.net = item.Amount - c.credit
+	if rawString(raw, "SubscriptionType") == "Subscription" {
+		c.chargeType = domain.ChargeTypePrepaid
+	}
+	if rawString(raw, "Item") == "Refund" {
+		c.lineType = domain.LineTypeRefund
+	}
+
+	c.serviceStart = parseChargeTime(rawString(raw, "UsageStartTime"))
+	c.serviceEnd = parseChargeTime(rawString(raw, "UsageEndTime"))
+	if c.serviceStart.IsZero() || !c.serviceEnd.After(c.serviceStart) {
+		// 兜底：账期起始 + 服务时长
+		c.serviceStart, c.serviceEnd = aliyunServicePeriod(item, raw)
+	}
+}
+
+// aliyunServicePeriod 按 ServicePeriod + ServicePeriodUnit 推算服务期
+func aliyunServicePeriod(item billing.RawBillItem, raw map[string]interface{}) (time.Time, time.Time) {
+	period, ok := rawFloat(raw, "ServicePeriod")
+	if !ok || period <= 0 {
+		return time.Time{}, time.Time{}
+	}
+	start, _ := parseBillingCycle(item.BillingCycle)
+	n := int(period)
+	switch strings.ToLower(rawString(raw, "ServicePeriodUnit")) {
+	case "year":
+		return start, start.AddDate(n, 0, 0)
+	case "month":
+		return start, start.AddDate(0, n, 0)
+	case "day":
+		return start, start.AddDate(0, 0, n)
+	}
+	return time.Time{}, time.Time{}
+}
+
+func extractAWSCharges(item billing.RawBillItem, c *chargeBreakdown) {
+	raw := item.RawData
+	if net, ok := rawFloat(raw, "NetUnblendedCost"); ok {
+		c.net = net
+		if d := item.Amount - net; d > 0 {
+			c.discount = d
+		}
+	}
+	if amortized, ok := rawFloat(raw, "AmortizedCost"); ok {
+		c.amortized = &amortized
+	}
+}
+
+func extractHuaweiCharges(item billing.RawBillItem, c *chargeBreakdown) {
+	raw := item.RawData
+	if official, ok := rawFloat(raw, "OfficialAmount"); ok {
+		c.list = official
+	}
+	c.discount = rawSum(raw, "DiscountAmount")
+	if c.discount == 0 && c.list > item.Amount {
+		c.discount = c.list - item.Amount
+	}
+	c.credit = rawSum(raw, "CouponAmount", "FlexipurchaseCouponAmount", "StoredCardAmount", "BonusAmount")
+	c.net = item.Amount - c.credit
+	// 4: 退订，20: 退款，24: 退款（预留实例）
+	if billType, ok := rawFloat(raw, "BillType"); ok {
+		switch int(billType) {
+		case 4, 20, 24:
+			c.lineType = domain.LineTypeRefund
+		}
+	}
+	c.serviceStart = parseChargeTime(rawString(raw, "EffectiveTime"))
+	c.serviceEnd = parseChargeTime(rawString(raw, "ExpireTime"))
+}
+
+func extractTencentCharges(item billing.RawBillItem, c *chargeBreakdown) {
+	raw := item.RawData
+	if listCost, ok := rawFloat(raw, "ListCost"); ok && listCost != 0 {
+		c.list = listCost
+		if d := listCost - item.Amount; d > 0 {
+			c.discount = d
+		}
+	}
+	c.credit = rawSum(raw, "VoucherPayAmount", "IncentivePayAmount")
+	c.net = item.Amount - c.credit
+	if strings.Contains(rawString(raw, "ActionTypeName"), "退") {
+		c.lineType = domain.LineTypeRefund
+	}
+	c.serviceStart = parseChargeTime(rawString(raw, "FeeBeginTime"))
+	c.serviceEnd = parseChargeTime(rawString(raw, "FeeEndTime"))
+}
+
+func extractVolcanoCharges(item billing.RawBillItem, c *chargeBreakdown) {
+	raw := item.RawData
+	if original, ok := rawFloat(raw, "OriginalBillAmount"); ok && original != 0 {
+		c.list = original
+		if discounted, ok := rawFloat(raw, "DiscountBillAmount"); ok {
+			c.discount = original - discounted
+		}
+	}
+	c.credit = rawSum(raw, "CouponAmount")
+	c.net = item.Amount
+	switch mode := rawString(raw, "BillingMode"); {
+	case strings.Contains(mode, "包年包月"), strings.EqualFold(mode, "Subscription"), strings.EqualFold(mode, "PrePaid"):
+		c.chargeType = domain.ChargeTypePrepaid
+	}
+	c.serviceStart = parseChargeTime(rawString(raw, "ExpenseBeginTime"))
+	c.serviceEnd = parseChargeTime(rawString(raw, "ExpenseEndTime"))
+}
+
+func extractGCPCharges(item billing.RawBillItem, c *chargeBreakdown) {
+	raw := item.RawData
+	// 账单金额已扣除 credits（credits 为负数）
+	if cost, ok := rawFloat(raw, "Cost"); ok {
+		c.list = cost
+	}
+	if credits, ok := rawFloat(raw, "Credits"); ok {
+		c.credit = -credits
+	}
+	c.net = item.Amount
+}
+
+// extractAzureCharges 按 Azure 成本明细（Cost details 导出 / 导入文件）的字段提取金额明细
+// 适配器通过 Query API 拉取的 ActualCost 只有 Cost 汇总，没有费用类型、计费模式与价目，
+// 此时按账单金额兜底（原价 = 净额，无折扣），也无法识别预留实例购买与退款
+func extractAzureCharges(item billing.RawBillItem, c *chargeBreakdown) {
+	raw := item.RawData
+	// Azure 的 ChargeType 是费用类型（Usage / Purchase / Refund 等），不是统一计费方式，恢复默认值后重新判断
+	c.chargeType = domain.ChargeTypePostpaid
+	switch strings.ToLower(rawString(raw, "PricingModel")) {
+	case "reservation", "savingsplan":
+		c.chargeType = domain.ChargeTypeReserved
+	}
+	switch strings.ToLower(rawString(raw, "ChargeType")) {
+	case "refund":
+		c.lineType = domain.LineTypeRefund
+	case "purchase":
+		if c.chargeType == domain.ChargeTypeReserved {
+			// 预留 / 节省计划购买：Term 为承诺月数，从购买日开始摊销
+			c.serviceStart = parseChargeTime(rawString(raw, "Date"))
+			if months := azureTermMonths(rawString(raw, "Term")); months > 0 && !c.serviceStart.IsZero() {
+				c.serviceEnd = c.serviceStart.AddDate(0, months, 0)
+			}
+		}
+	}
+
+	// 原价 = 即用即付单价 × 用量，仅在高于实际金额时记为折扣
+	if payg, ok := rawFloat(raw, "PayGPrice"); ok && payg > 0 {
+		if qty, ok := rawFloat(raw, "Quantity"); ok && qty > 0 && payg*qty > item.Amount {
+			c.list = payg * qty
+			c.discount = c.list - item.Amount
+		}
+	}
+	c.net = item.Amount
+}
+
+// azureTermMonths 解析 Azure 承诺期限，支持月数（12 / 36）与 ISO 8601 期限（P1Y / P3Y / P1M）
+func azureTermMonths(term string) int {
+	term = strings.ToUpper(strings.TrimSpace(term))
+	if n, err := strconv.Atoi(term); err == nil {
+		return n
+	}
+	if !strings.HasPrefix(term, "P") || len(term) < 3 {
+		return 0
+	}
+	n, err := strconv.Atoi(term[1 : len(term)-1])
+	if err != nil {
+		return 0
+	}
+	switch term[len(term)-1] {
+	case 'Y':
+		return n * 12
+	case 'M':
+		return n
+	}
+	return 0
+}
+
+// applyCharges 将金额明细写入统一账单，返回预付费购买行的摊销派生行
+func applyCharges(bill *domain.UnifiedBill, c chargeBreakdown) []domain.UnifiedBill {
+	bill.ChargeType = c.chargeType
+	bill.LineType = c.lineType
+	bill.ListAmount = c.list
+	bill.DiscountAmount = c.discount
+	bill.CreditAmount = c.credit
+	bill.NetAmount = c.net
+	bill.AmortizedAmount = bill.Amount
+	if c.amortized != nil {
+		bill.AmortizedAmount = *c.amortized
+		return nil
+	}
+
+	if c.lineType != domain.LineTypePurchase || c.serviceStart.IsZero() || !c.serviceEnd.After(c.serviceStart) {
+		return nil
+	}
+	bill.ServiceStart = c.serviceStart.Format("2006-01-02")
+	bill.ServiceEnd = c.serviceEnd.Format("2006-01-02")
+	rows := amortizeBill(*bill, c.serviceStart, c.serviceEnd)
+	if len(rows) > 0 {
+		bill.AmortizedAmount = 0
+	}
+	return rows
+}
+
+// amortizeBill 将预付费购买金额按服务期内各月天数占比拆分为摊销派生行，
+// 派生行的账单金额为 0，仅承担 AmortizedAmount，末行吸收舍入误差
+func amortizeBill(purchase domain.UnifiedBill, start, end time.Time) []domain.UnifiedBill {
+	if limit := start.AddDate(0, maxAmortizationMonths, 0); end.After(limit) {
+		end = limit
+	}
+	total := end.Sub(start).Seconds()
+	if total <= 0 || purchase.Amount == 0 {
+		return nil
+	}
+
+	var rows []domain.UnifiedBill
+	allocated := 0.0
+	monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for monthStart.Before(end) {
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		from, to := start, end
+		if monthStart.After(from) {
+			from = monthStart
+		}
+		if monthEnd.Before(to) {
+			to = monthEnd
+		}
+		share := math.Round(purchase.Amount*to.Sub(from).Seconds()/total*100) / 100
+
+		row := purchase
+		row.ID = 0
+		row.LineType = domain.LineTypeAmortization
+		row.Amount, row.AmountCNY = 0, 0
+		row.ListAmount, row.DiscountAmount, row.CreditAmount, row.NetAmount = 0, 0, 0, 0
+		row.AmortizedAmount = share
+		row.BillingStart = monthStart
+		row.BillingEnd = monthEnd.Add(-time.Second)
+		row.BillingDate = monthStart.Format("2006-01-02")
+		row.AmortizedFrom = purchase.BillingDate
+		rows = append(rows, row)
+
+		allocated += share
+		monthStart = monthEnd
+	}
+	if len(rows) > 0 {
+		last := &rows[len(rows)-1]
+		last.AmortizedAmount = math.Round((last.AmortizedAmount+purchase.Amount-allocated)*100) / 100
+	}
+	return rows
+}
+
+// chargeTimeLayouts 各云厂商账单中服务期时间的格式
+var chargeTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04Z",
+	"2006-01-02",
+	"01/02/2006", // Azure 成本明细导出
+}
+
+// parseChargeTime 解析服务期时间，无法解析时返回零值
+func parseChargeTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}
+	}
+	for _, layout := range chargeTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// rawFloat 读取原始账单中的数值字段，兼容 SDK 返回的字符串金额
+func rawFloat(raw map[string]interface{}, key string) (float64, bool) {
+	switch v := raw[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// rawSum 累加多个数值字段，缺失字段视为 0
+func rawSum(raw map[string]interface{}, keys ...string) float64 {
+	sum := 0.0
+	for _, key := range keys {
+		v, _ := rawFloat(raw, key)
+		sum += v
+	}
+	return sum
+}
+
+// rawString 读取原始账单中的字符串字段
+func rawString(raw map[string]interface{}, key string) string {
+	s, _ := raw[key].(string)
+	return s
+}
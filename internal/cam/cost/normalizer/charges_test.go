@@ -0,0 +1,241 @@
+package normalizer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractCharges_Aliyun(t *testing.T) {
+	item := billing.RawBillItem{
+		Provider: shareddomain.CloudProviderAliyun,
+		Amount:   80,
+		RawData: map[string]interface{}{
+			"PretaxGrossAmount":     100.0,
+			"InvoiceDiscount":       15.0,
+			"DeductedByCoupons":     5.0,
+			"DeductedByCashCoupons": 10.0,
+			"DeductedByPrepaidCard": 0.0,
+			"SubscriptionType":      "PayAsYouGo",
+		},
+	}
+
+	c := extractCharges(item)
+	assert.Equal(t, domain.ChargeTypePostpaid, c.chargeType)
+	assert.Equal(t, domain.LineTypeUsage, c.lineType)
+	assert.Equal(t, 100.0, c.list)
+	assert.Equal(t, 20.0, c.discount)
+	assert.Equal(t, 10.0, c.credit)
+	assert.Equal(t, 70.0, c.net)
+	assert.Equal(t, c.list-c.discount-c.credit, c.net)
+}
+
+func TestExtractCharges_LineTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       billing.RawBillItem
+		lineType   string
+		chargeType string
+	}{
+		{
+			name: "阿里云包年包月为购买行",
+			item: billing.RawBillItem{Provider: shareddomain.CloudProviderAliyun, Amount: 1200,
+				RawData: map[string]interface{}{"SubscriptionType": "Subscription"}},
+			lineType:   domain.LineTypePurchase,
+			chargeType: domain.ChargeTypePrepaid,
+		},
+		{
+			name: "阿里云退款",
+			item: billing.RawBillItem{Provider: shareddomain.CloudProviderAliyun, Amount: -300,
+				RawData: map[string]interface{}{"SubscriptionType": "Subscription", "Item": "Refund"}},
+			lineType:   domain.LineTypeRefund,
+			chargeType: domain.ChargeTypePrepaid,
+		},
+		{
+			name: "华为云退订",
+			item: billing.RawBillItem{Provider: shareddomain.CloudProviderHuawei, Amount: -50,
+				RawData: map[string]interface{}{"BillType": int32(4), "ChargeType": "prepaid"}},
+			lineType:   domain.LineTypeRefund,
+			chargeType: domain.ChargeTypePrepaid,
+		},
+		{
+			name: "腾讯云退费",
+			item: billing.RawBillItem{Provider: shareddomain.CloudProviderTencent, Amount: -20,
+				RawData: map[string]interface{}{"ActionTypeName": "包年包月退费"}},
+			lineType:   domain.LineTypeRefund,
+			chargeType: domain.ChargeTypePostpaid,
+		},
+		{
+			name: "Azure 预留实例购买",
+			item: billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: 3650,
+				RawData: map[string]interface{}{"ChargeType": "Purchase", "PricingModel": "Reservation"}},
+			lineType:   domain.LineTypePurchase,
+			chargeType: domain.ChargeTypeReserved,
+		},
+		{
+			name: "Azure 预留实例退款",
+			item: billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: -1000,
+				RawData: map[string]interface{}{"ChargeType": "Refund", "PricingModel": "Reservation"}},
+			lineType:   domain.LineTypeRefund,
+			chargeType: domain.ChargeTypeReserved,
+		},
+		{
+			name: "Azure 按需用量",
+			item: billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: 12,
+				RawData: map[string]interface{}{"ChargeType": "Usage", "PricingModel": "OnDemand"}},
+			lineType:   domain.LineTypeUsage,
+			chargeType: domain.ChargeTypePostpaid,
+		},
+		{
+			name:       "非退款负数行为抵扣",
+			item:       billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: -8},
+			lineType:   domain.LineTypeCredit,
+			chargeType: domain.ChargeTypePostpaid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := extractCharges(tt.item)
+			assert.Equal(t, tt.lineType, c.lineType)
+			assert.Equal(t, tt.chargeType, c.chargeType)
+		})
+	}
+
+	credit := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: -8})
+	assert.Equal(t, 8.0, credit.credit)
+	assert.Equal(t, -8.0, credit.net)
+}
+
+func TestExtractCharges_ProviderAmounts(t *testing.T) {
+	huawei := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderHuawei, Amount: 90,
+		RawData: map[string]interface{}{"OfficialAmount": 120.0, "CouponAmount": 10.0, "BonusAmount": 5.0}})
+	assert.Equal(t, 120.0, huawei.list)
+	assert.Equal(t, 30.0, huawei.discount)
+	assert.Equal(t, 15.0, huawei.credit)
+	assert.Equal(t, 75.0, huawei.net)
+
+	tencent := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderTencent, Amount: 60,
+		RawData: map[string]interface{}{"ListCost": 100.0, "VoucherPayAmount": 20.0, "IncentivePayAmount": 0.0}})
+	assert.Equal(t, 100.0, tencent.list)
+	assert.Equal(t, 40.0, tencent.discount)
+	assert.Equal(t, 20.0, tencent.credit)
+	assert.Equal(t, 40.0, tencent.net)
+
+	volcano := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderVolcano, Amount: 70,
+		RawData: map[string]interface{}{"OriginalBillAmount": "100", "DiscountBillAmount": "80", "CouponAmount": "10"}})
+	assert.Equal(t, 100.0, volcano.list)
+	assert.Equal(t, 20.0, volcano.discount)
+	assert.Equal(t, 10.0, volcano.credit)
+	assert.Equal(t, 70.0, volcano.net)
+
+	gcp := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderGCP, Amount: 8,
+		RawData: map[string]interface{}{"Cost": 10.0, "Credits": -2.0}})
+	assert.Equal(t, 10.0, gcp.list)
+	assert.Equal(t, 2.0, gcp.credit)
+	assert.Equal(t, 8.0, gcp.net)
+
+	aws := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderAWS, Amount: 50,
+		RawData: map[string]interface{}{"UnblendedCost": 50.0, "NetUnblendedCost": 45.0, "AmortizedCost": 30.0}})
+	assert.Equal(t, 45.0, aws.net)
+	assert.Equal(t, 5.0, aws.discount)
+	require.NotNil(t, aws.amortized)
+	assert.Equal(t, 30.0, *aws.amortized)
+}
+
+func TestExtractCharges_Azure(t *testing.T) {
+	// 成本明细导出：即用即付价目计算原价，预留购买按 Term 推算服务期
+	usage := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: 6,
+		RawData: map[string]interface{}{"ChargeType": "Usage", "PricingModel": "OnDemand", "PayGPrice": "0.1", "Quantity": "100"}})
+	assert.Equal(t, 10.0, usage.list)
+	assert.Equal(t, 4.0, usage.discount)
+	assert.Equal(t, 6.0, usage.net)
+
+	purchase := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: 3650,
+		RawData: map[string]interface{}{"ChargeType": "Purchase", "PricingModel": "Reservation", "Date": "01/15/2024", "Term": "P1Y"}})
+	assert.Equal(t, "2024-01-15", purchase.serviceStart.Format("2006-01-02"))
+	assert.Equal(t, "2025-01-15", purchase.serviceEnd.Format("2006-01-02"))
+	assert.Equal(t, 36, azureTermMonths("36"))
+
+	// Query API 的 ActualCost 行只有 Cost：无法识别计费模式与折扣，按账单金额兜底
+	query := extractCharges(billing.RawBillItem{Provider: shareddomain.CloudProviderAzure, Amount: 20,
+		RawData: map[string]interface{}{"Cost": 20.0, "ServiceName": "Virtual Machines"}})
+	assert.Equal(t, domain.LineTypeUsage, query.lineType)
+	assert.Equal(t, domain.ChargeTypePostpaid, query.chargeType)
+	assert.Equal(t, 20.0, query.list)
+	assert.Zero(t, query.discount)
+	assert.Equal(t, 20.0, query.net)
+	assert.Nil(t, query.amortized)
+}
+
+func TestNormalize_AmortizesPrepaidPurchase(t *testing.T) {
+	svc := newTestService()
+	items := []billing.RawBillItem{
+		{
+			Provider:     shareddomain.CloudProviderAliyun,
+			ServiceType:  "ecs",
+			ResourceID:   "i-prepaid",
+			Amount:       1200,
+			Currency:     "CNY",
+			BillingCycle: "2024-01",
+			RawData: map[string]interface{}{
+				"SubscriptionType": "Subscription",
+				"UsageStartTime":   "2024-01-01 00:00:00",
+				"UsageEndTime":     "2025-01-01 00:00:00",
+			},
+		},
+		{
+			Provider:     shareddomain.CloudProviderAliyun,
+			ServiceType:  "ecs",
+			ResourceID:   "i-postpaid",
+			Amount:       30,
+			Currency:     "CNY",
+			BillingCycle: "2024-01",
+			RawData:      map[string]interface{}{"SubscriptionType": "PayAsYouGo"},
+		},
+	}
+
+	bills, err := svc.Normalize(context.Background(), items)
+	require.NoError(t, err)
+	require.Len(t, bills, 14)
+
+	purchase := bills[0]
+	assert.Equal(t, domain.LineTypePurchase, purchase.LineType)
+	assert.Equal(t, domain.ChargeTypePrepaid, purchase.ChargeType)
+	assert.Equal(t, 1200.0, purchase.Amount)
+	assert.Equal(t, 0.0, purchase.AmortizedAmount)
+	assert.Equal(t, "2024-01-01", purchase.ServiceStart)
+	assert.Equal(t, "2025-01-01", purchase.ServiceEnd)
+
+	total := 0.0
+	for i, row := range bills[1:13] {
+		assert.Equal(t, domain.LineTypeAmortization, row.LineType)
+		assert.Equal(t, "i-prepaid", row.ResourceID)
+		assert.Equal(t, 0.0, row.Amount)
+		assert.Equal(t, 0.0, row.AmountCNY)
+		assert.Equal(t, "2024-01-01", row.AmortizedFrom)
+		assert.Equal(t, purchase.BillingStart.AddDate(0, i, 0).Format("2006-01-02"), row.BillingDate)
+		total += row.AmortizedAmount
+	}
+	// 按天数占比：2024 年 1 月 31 天 / 366 天
+	assert.InDelta(t, 1200.0*31/366, bills[1].AmortizedAmount, 0.01)
+	assert.InDelta(t, 1200.0*29/366, bills[2].AmortizedAmount, 0.01)
+	assert.InDelta(t, 1200.0, total, 1e-6)
+
+	postpaid := bills[13]
+	assert.Equal(t, domain.LineTypeUsage, postpaid.LineType)
+	assert.Equal(t, domain.ChargeTypePostpaid, postpaid.ChargeType)
+	assert.Equal(t, 30.0, postpaid.AmortizedAmount)
+	assert.Equal(t, 30.0, postpaid.NetAmount)
+}
+
+func TestAmortizeBill_CapsLongTerm(t *testing.T) {
+	purchase := domain.UnifiedBill{Amount: 6000, BillingDate: "2024-01-01"}
+	start := parseChargeTime("2024-01-01")
+	rows := amortizeBill(purchase, start, start.AddDate(10, 0, 0))
+	assert.Len(t, rows, maxAmortizationMonths)
+}
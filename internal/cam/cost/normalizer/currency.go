@@ -1,6 +1,12 @@
 package normalizer
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
 	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
 )
 
@@ -13,10 +19,18 @@ const (
 	DefaultUSDToCNYRate = 7.2
 )
 
+// RateProvider 汇率查询接口，由汇率服务实现
+// 返回 date 当日生效的 1 单位 from 兑换 to 的汇率
+type RateProvider interface {
+	GetRate(ctx context.Context, from, to, date string) (float64, error)
+}
+
 // CurrencyConfig 币种配置与汇率转换
 type CurrencyConfig struct {
-	// USDToCNYRate 美元兑人民币汇率
+	// USDToCNYRate 美元兑人民币汇率（汇率库缺失时的兜底）
 	USDToCNYRate float64
+	// rates 日汇率查询，为空时仅使用 USDToCNYRate
+	rates RateProvider
 }
 
 // NewCurrencyConfig 创建币种配置
@@ -33,32 +47,113 @@ func NewCurrencyConfig(usdToCNYRate float64) *CurrencyConfig {
 type CurrencyResult struct {
 	Currency  string
 	AmountCNY float64
+	// Rate 折算人民币所用汇率，无可用汇率时为 0
+	Rate float64
 }
 
-// Convert 根据云厂商和原始金额计算币种和人民币等值金额
+// Convert 根据云厂商和原始金额计算币种和人民币等值金额（不查询日汇率）
 func (c *CurrencyConfig) Convert(provider shareddomain.CloudProvider, amount float64, rawCurrency string) CurrencyResult {
-	switch provider {
-	case shareddomain.CloudProviderAliyun,
-		shareddomain.CloudProviderHuawei,
-		shareddomain.CloudProviderTencent,
-		shareddomain.CloudProviderVolcano:
-		return CurrencyResult{
-			Currency:  CurrencyCNY,
-			AmountCNY: amount,
-		}
-	case shareddomain.CloudProviderAWS:
+	// 不查询汇率库，只可能是汇率未找到，不会返回错误
+	r, _ := c.ConvertAt(context.Background(), provider, amount, rawCurrency, "")
+	return r
+}
+
+// ConvertAt 按账单日期的汇率计算币种和人民币等值金额
+// 优先使用账单原始币种，缺失时按云厂商默认结算币种；
+// 汇率优先取 date 当日生效的日汇率，美元缺失时回退到 USDToCNYRate，
+// 其余币种无可用汇率时 AmountCNY 设为 0；汇率库查询失败时返回错误，不以 0 折算
+func (c *CurrencyConfig) ConvertAt(ctx context.Context, provider shareddomain.CloudProvider, amount float64, rawCurrency, date string) (CurrencyResult, error) {
+	currency := c.resolveCurrency(provider, rawCurrency)
+	rate, err := c.RateToCNY(ctx, currency, date)
+	if errors.Is(err, domain.ErrExchangeRateNotFound) {
 		return CurrencyResult{
-			Currency:  CurrencyUSD,
-			AmountCNY: amount * c.USDToCNYRate,
-		}
+			Currency:  currency,
+			AmountCNY: 0,
+		}, nil
+	}
+	if err != nil {
+		return CurrencyResult{}, fmt.Errorf("query %s/CNY rate on %s: %w", currency, date, err)
+	}
+	return CurrencyResult{
+		Currency:  currency,
+		AmountCNY: amount * rate,
+		Rate:      rate,
+	}, nil
+}
+
+// resolveCurrency 确定账单币种：原始币种优先，缺失时使用云厂商默认结算币种
+func (c *CurrencyConfig) resolveCurrency(provider shareddomain.CloudProvider, rawCurrency string) string {
+	if currency := strings.ToUpper(strings.TrimSpace(rawCurrency)); currency != "" {
+		return currency
+	}
+	switch provider {
+	case shareddomain.CloudProviderAWS,
+		shareddomain.CloudProviderAzure,
+		shareddomain.CloudProviderGCP:
+		return CurrencyUSD
 	default:
-		// 未知厂商：保留原始币种，AmountCNY 设为 0
-		if rawCurrency == "" {
-			rawCurrency = CurrencyCNY
+		return CurrencyCNY
+	}
+}
+
+// RateToCNY 获取 date 当日生效的币种兑人民币汇率
+// 人民币固定为 1；日汇率缺失时美元回退到 USDToCNYRate，其余币种返回 domain.ErrExchangeRateNotFound。
+// 标准化入库与历史账单重算共用此查询，保证两者折算口径一致
+func (c *CurrencyConfig) RateToCNY(ctx context.Context, currency, date string) (float64, error) {
+	if currency == CurrencyCNY {
+		return 1, nil
+	}
+	if c.rates != nil && date != "" {
+		rate, err := c.rates.GetRate(ctx, currency, CurrencyCNY, date)
+		if err == nil && rate > 0 {
+			return rate, nil
 		}
-		return CurrencyResult{
-			Currency:  rawCurrency,
-			AmountCNY: 0,
+		if err != nil && !errors.Is(err, domain.ErrExchangeRateNotFound) {
+			return 0, err
 		}
 	}
+	if currency == CurrencyUSD {
+		return c.USDToCNYRate, nil
+	}
+	return 0, domain.ErrExchangeRateNotFound
+}
+
+// SetRateProvider 设置日汇率查询，未设置时仅使用固定美元汇率
+func (c *CurrencyConfig) SetRateProvider(rates RateProvider) {
+	c.rates = rates
+}
+
+// withCache 返回共享配置、带批次内汇率缓存的副本，避免同一批账单重复查询汇率库
+func (c *CurrencyConfig) withCache() *CurrencyConfig {
+	if c.rates == nil {
+		return c
+	}
+	return &CurrencyConfig{
+		USDToCNYRate: c.USDToCNYRate,
+		rates:        &cachedRateProvider{next: c.rates, cache: make(map[string]cachedRate)},
+	}
+}
+
+// cachedRate 缓存的汇率查询结果（含未找到）
+type cachedRate struct {
+	rate float64
+	err  error
+}
+
+// cachedRateProvider 批次内的汇率查询缓存，非并发安全；汇率库查询失败不缓存
+type cachedRateProvider struct {
+	next  RateProvider
+	cache map[string]cachedRate
+}
+
+func (p *cachedRateProvider) GetRate(ctx context.Context, from, to, date string) (float64, error) {
+	key := from + "/" + to + "@" + date
+	if r, ok := p.cache[key]; ok {
+		return r.rate, r.err
+	}
+	rate, err := p.next.GetRate(ctx, from, to, date)
+	if err == nil || errors.Is(err, domain.ErrExchangeRateNotFound) {
+		p.cache[key] = cachedRate{rate: rate, err: err}
+	}
+	return rate, err
 }
@@ -20,6 +20,8 @@ func NewServiceTypeMapper() *ServiceTypeMapper {
 	}
 	m.initAliyun()
 	m.initAWS()
+	m.initAzure()
+	m.initGCP()
 	m.initVolcano()
 	m.initHuawei()
 	m.initTencent()
@@ -115,6 +117,78 @@ func (m *ServiceTypeMapper) initAWS() {
 	}
 }
 
+func (m *ServiceTypeMapper) initAzure() {
+	// Cost Management 的 ServiceName 维度
+	m.mappings[shareddomain.CloudProviderAzure] = map[string]string{
+		// compute
+		"virtual machines":           domain.ServiceTypeCompute,
+		"virtual machine scale sets": domain.ServiceTypeCompute,
+		"azure app service":          domain.ServiceTypeCompute,
+		"functions":                  domain.ServiceTypeCompute,
+		"azure kubernetes service":   domain.ServiceTypeCompute,
+		"container instances":        domain.ServiceTypeCompute,
+		"virtual machines licenses":  domain.ServiceTypeCompute,
+		// storage
+		"storage":            domain.ServiceTypeStorage,
+		"backup":             domain.ServiceTypeStorage,
+		"azure netapp files": domain.ServiceTypeStorage,
+		// network
+		"virtual network":          domain.ServiceTypeNetwork,
+		"load balancer":            domain.ServiceTypeNetwork,
+		"application gateway":      domain.ServiceTypeNetwork,
+		"bandwidth":                domain.ServiceTypeNetwork,
+		"azure dns":                domain.ServiceTypeNetwork,
+		"azure front door service": domain.ServiceTypeNetwork,
+		"content delivery network": domain.ServiceTypeNetwork,
+		"vpn gateway":              domain.ServiceTypeNetwork,
+		"nat gateway":              domain.ServiceTypeNetwork,
+		// database
+		"sql database":                  domain.ServiceTypeDatabase,
+		"sql managed instance":          domain.ServiceTypeDatabase,
+		"azure database for mysql":      domain.ServiceTypeDatabase,
+		"azure database for postgresql": domain.ServiceTypeDatabase,
+		"azure cosmos db":               domain.ServiceTypeDatabase,
+		"redis cache":                   domain.ServiceTypeDatabase,
+		// middleware
+		"event hubs":     domain.ServiceTypeMiddleware,
+		"service bus":    domain.ServiceTypeMiddleware,
+		"api management": domain.ServiceTypeMiddleware,
+	}
+}
+
+func (m *ServiceTypeMapper) initGCP() {
+	// BigQuery 账单导出的 service.description
+	m.mappings[shareddomain.CloudProviderGCP] = map[string]string{
+		// compute
+		"compute engine":    domain.ServiceTypeCompute,
+		"kubernetes engine": domain.ServiceTypeCompute,
+		"cloud run":         domain.ServiceTypeCompute,
+		"cloud functions":   domain.ServiceTypeCompute,
+		"app engine":        domain.ServiceTypeCompute,
+		// storage
+		"cloud storage":     domain.ServiceTypeStorage,
+		"cloud filestore":   domain.ServiceTypeStorage,
+		"artifact registry": domain.ServiceTypeStorage,
+		// network
+		"networking":           domain.ServiceTypeNetwork,
+		"cloud dns":            domain.ServiceTypeNetwork,
+		"cloud load balancing": domain.ServiceTypeNetwork,
+		"cloud cdn":            domain.ServiceTypeNetwork,
+		"cloud nat":            domain.ServiceTypeNetwork,
+		"cloud vpn":            domain.ServiceTypeNetwork,
+		// database
+		"cloud sql":                   domain.ServiceTypeDatabase,
+		"cloud memorystore for redis": domain.ServiceTypeDatabase,
+		"cloud spanner":               domain.ServiceTypeDatabase,
+		"cloud bigtable":              domain.ServiceTypeDatabase,
+		"cloud firestore":             domain.ServiceTypeDatabase,
+		// middleware
+		"cloud pub/sub":  domain.ServiceTypeMiddleware,
+		"bigquery":       domain.ServiceTypeMiddleware,
+		"cloud dataflow": domain.ServiceTypeMiddleware,
+	}
+}
+
 func (m *ServiceTypeMapper) initVolcano() {
 	m.mappings[shareddomain.CloudProviderVolcano] = map[string]string{
 		// compute
@@ -48,25 +48,30 @@ func NewNormalizerServiceWithConfig(
 	}
 }
 
-// Normalize 将原始账单批量转换为统一账单模型
+// Normalize 将原始账单批量转换为统一账单模型，预付费购买行会追加按月摊销的派生行
+// 汇率库查询失败时整批返回错误，避免以 0 人民币金额写入账单
 func (s *NormalizerService) Normalize(ctx context.Context, items []billing.RawBillItem) ([]domain.UnifiedBill, error) {
+	currencyConfig := s.currencyConfig.withCache()
 	bills := make([]domain.UnifiedBill, 0, len(items))
 	for i := range items {
-		bill, err := s.NormalizeOne(items[i])
+		bill, amortized, err := s.normalizeOne(ctx, items[i], currencyConfig)
 		if err != nil {
-			s.logger.Warn("normalize item failed, skipping",
-				elog.FieldErr(err),
-				elog.Int("index", i),
-			)
-			continue
+			return nil, fmt.Errorf("normalize item %d: %w", i, err)
 		}
 		bills = append(bills, bill)
+		bills = append(bills, amortized...)
 	}
 	return bills, nil
 }
 
-// NormalizeOne 标准化单条账单
+// NormalizeOne 标准化单条账单（不含摊销派生行）
 func (s *NormalizerService) NormalizeOne(item billing.RawBillItem) (domain.UnifiedBill, error) {
+	bill, _, err := s.normalizeOne(context.Background(), item, s.currencyConfig)
+	return bill, err
+}
+
+// normalizeOne 标准化单条账单，币种按 BillingDate 当日汇率折算，同时返回摊销派生行
+func (s *NormalizerService) normalizeOne(ctx context.Context, item billing.RawBillItem, currencyConfig *CurrencyConfig) (domain.UnifiedBill, []domain.UnifiedBill, error) {
 	provider := string(item.Provider)
 	if provider == "" {
 		s.logger.Warn("missing provider in raw bill item, using 'unknown'",
@@ -94,15 +99,25 @@ func (s *NormalizerService) NormalizeOne(item billing.RawBillItem) (domain.Unifi
 		resourceID = "unknown"
 	}
 
-	// 币种转换
-	currResult := s.currencyConfig.Convert(item.Provider, item.Amount, item.Currency)
-
 	// 解析计费周期
 	billingStart, billingEnd := parseBillingCycle(item.BillingCycle)
 
 	// BillingDate = BillingStart 格式化为 "YYYY-MM-DD"
 	billingDate := billingStart.Format("2006-01-02")
 
+	// 币种转换（使用 BillingDate 当日汇率）
+	currResult, err := currencyConfig.ConvertAt(ctx, item.Provider, item.Amount, item.Currency, billingDate)
+	if err != nil {
+		return domain.UnifiedBill{}, nil, err
+	}
+	if currResult.Rate == 0 && item.Amount != 0 {
+		s.logger.Warn("no exchange rate for currency, amount_cny set to 0",
+			elog.String("provider", provider),
+			elog.String("currency", currResult.Currency),
+			elog.String("billing_date", billingDate),
+		)
+	}
+
 	now := time.Now().Unix()
 
 	bill := domain.UnifiedBill{
@@ -117,13 +132,17 @@ func (s *NormalizerService) NormalizeOne(item billing.RawBillItem) (domain.Unifi
 		Amount:          item.Amount,
 		Currency:        currResult.Currency,
 		AmountCNY:       currResult.AmountCNY,
+		ExchangeRate:    currResult.Rate,
 		Tags:            item.Tags,
 		BillingDate:     billingDate,
 		CreateTime:      now,
 		UpdateTime:      now,
 	}
 
-	return bill, nil
+	// 金额明细与摊销
+	amortized := applyCharges(&bill, extractCharges(item))
+
+	return bill, amortized, nil
 }
 
 // parseBillingCycle 解析计费周期字符串 "YYYY-MM" 为起止时间
@@ -163,7 +182,12 @@ func (s *NormalizerService) SetExchangeRate(rate float64) error {
 	return nil
 }
 
-// GetCurrencyConfig 获取当前币种配置（用于测试）
+// SetRateProvider 设置日汇率查询，未设置时仅使用固定美元汇率
+func (s *NormalizerService) SetRateProvider(rates RateProvider) {
+	s.currencyConfig.SetRateProvider(rates)
+}
+
+// GetCurrencyConfig 获取当前币种配置（汇率服务重算历史账单时共用）
 func (s *NormalizerService) GetCurrencyConfig() *CurrencyConfig {
 	return s.currencyConfig
 }
@@ -2,6 +2,7 @@ package normalizer
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
@@ -75,6 +76,48 @@ func TestNormalizeOne_AWSWithExchangeRate(t *testing.T) {
 	assert.InDelta(t, 350.0, bill.AmountCNY, 0.01)
 }
 
+func TestNormalizeOne_AzureWithExchangeRate(t *testing.T) {
+	svc := newTestServiceWithRate(7.0)
+	item := billing.RawBillItem{
+		Provider:     shareddomain.CloudProviderAzure,
+		ServiceType:  "Virtual Machines",
+		ResourceID:   "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1",
+		ResourceName: "vm-1",
+		Region:       "eastus",
+		Amount:       20.0,
+		Currency:     "USD",
+		BillingCycle: "2024-03",
+	}
+
+	bill, err := svc.NormalizeOne(item)
+	assert.NoError(t, err)
+	assert.Equal(t, "azure", bill.Provider)
+	assert.Equal(t, domain.ServiceTypeCompute, bill.ServiceType)
+	assert.Equal(t, "USD", bill.Currency)
+	assert.InDelta(t, 140.0, bill.AmountCNY, 0.01)
+}
+
+func TestNormalizeOne_GCPWithExchangeRate(t *testing.T) {
+	svc := newTestServiceWithRate(7.0)
+	item := billing.RawBillItem{
+		Provider:     shareddomain.CloudProviderGCP,
+		ServiceType:  "Cloud SQL",
+		ResourceID:   "//sqladmin.googleapis.com/projects/test-project/instances/db-prod",
+		ResourceName: "db-prod",
+		Region:       "us-central1",
+		Amount:       10.0,
+		Currency:     "USD",
+		BillingCycle: "2024-03",
+	}
+
+	bill, err := svc.NormalizeOne(item)
+	assert.NoError(t, err)
+	assert.Equal(t, "gcp", bill.Provider)
+	assert.Equal(t, domain.ServiceTypeDatabase, bill.ServiceType)
+	assert.Equal(t, "USD", bill.Currency)
+	assert.InDelta(t, 70.0, bill.AmountCNY, 0.01)
+}
+
 func TestNormalizeOne_HuaweiCNY(t *testing.T) {
 	svc := newTestService()
 	item := billing.RawBillItem{
@@ -261,6 +304,11 @@ func TestServiceTypeMapper_AllProviders(t *testing.T) {
 		{shareddomain.CloudProviderAliyun, "kafka", domain.ServiceTypeMiddleware},
 		{shareddomain.CloudProviderAWS, "amazon elastic compute cloud", domain.ServiceTypeCompute},
 		{shareddomain.CloudProviderAWS, "amazon simple storage service", domain.ServiceTypeStorage},
+		{shareddomain.CloudProviderAzure, "virtual machines", domain.ServiceTypeCompute},
+		{shareddomain.CloudProviderAzure, "sql database", domain.ServiceTypeDatabase},
+		{shareddomain.CloudProviderGCP, "compute engine", domain.ServiceTypeCompute},
+		{shareddomain.CloudProviderGCP, "cloud memorystore for redis", domain.ServiceTypeDatabase},
+		{shareddomain.CloudProviderGCP, "cloud pub/sub", domain.ServiceTypeMiddleware},
 		{shareddomain.CloudProviderVolcano, "ecs", domain.ServiceTypeCompute},
 		{shareddomain.CloudProviderVolcano, "tos", domain.ServiceTypeStorage},
 		{shareddomain.CloudProviderHuawei, "hws.service.type.ec2", domain.ServiceTypeCompute},
@@ -291,6 +339,22 @@ func TestCurrencyConfig_Convert(t *testing.T) {
 	assert.Equal(t, "USD", r.Currency)
 	assert.InDelta(t, 720.0, r.AmountCNY, 0.01)
 
+	// Azure → USD with conversion, Azure China keeps CNY
+	r = cfg.Convert(shareddomain.CloudProviderAzure, 100.0, "USD")
+	assert.Equal(t, "USD", r.Currency)
+	assert.InDelta(t, 720.0, r.AmountCNY, 0.01)
+	r = cfg.Convert(shareddomain.CloudProviderAzure, 100.0, "CNY")
+	assert.Equal(t, "CNY", r.Currency)
+	assert.Equal(t, 100.0, r.AmountCNY)
+
+	// GCP → USD with conversion, CNY billing account keeps CNY
+	r = cfg.Convert(shareddomain.CloudProviderGCP, 100.0, "USD")
+	assert.Equal(t, "USD", r.Currency)
+	assert.InDelta(t, 720.0, r.AmountCNY, 0.01)
+	r = cfg.Convert(shareddomain.CloudProviderGCP, 100.0, "CNY")
+	assert.Equal(t, "CNY", r.Currency)
+	assert.Equal(t, 100.0, r.AmountCNY)
+
 	// Huawei → CNY
 	r = cfg.Convert(shareddomain.CloudProviderHuawei, 200.0, "CNY")
 	assert.Equal(t, "CNY", r.Currency)
@@ -331,6 +395,111 @@ func TestSetExchangeRate(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// fakeRateProvider 按 "币种@日期" 返回汇率并记录查询次数
+type fakeRateProvider struct {
+	rates map[string]float64
+	calls int
+	err   error // 非空时模拟汇率库查询失败
+}
+
+func (f *fakeRateProvider) GetRate(_ context.Context, from, _ string, date string) (float64, error) {
+	f.calls++
+	if f.err != nil {
+		return 0, f.err
+	}
+	if rate, ok := f.rates[from+"@"+date]; ok {
+		return rate, nil
+	}
+	return 0, domain.ErrExchangeRateNotFound
+}
+
+func TestCurrencyConfig_ConvertAt(t *testing.T) {
+	cfg := NewCurrencyConfig(7.2)
+	cfg.rates = &fakeRateProvider{rates: map[string]float64{
+		"USD@2024-03-01": 7.1,
+		"EUR@2024-03-01": 7.8,
+	}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		provider     shareddomain.CloudProvider
+		rawCurrency  string
+		date         string
+		wantCurrency string
+		wantCNY      float64
+		wantRate     float64
+	}{
+		{"国际站阿里云按原始币种", shareddomain.CloudProviderAliyun, "USD", "2024-03-01", "USD", 710, 7.1},
+		{"AWS 欧元账单", shareddomain.CloudProviderAWS, "eur", "2024-03-01", "EUR", 780, 7.8},
+		{"华为云人民币", shareddomain.CloudProviderHuawei, "CNY", "2024-03-01", "CNY", 100, 1},
+		{"美元缺失日汇率回退固定汇率", shareddomain.CloudProviderAWS, "USD", "2024-02-01", "USD", 720, 7.2},
+		{"未知币种无汇率", shareddomain.CloudProviderAWS, "JPY", "2024-03-01", "JPY", 0, 0},
+		{"缺失币种按云厂商默认", shareddomain.CloudProviderGCP, "", "2024-03-01", "USD", 710, 7.1},
+		{"缺失币种国内云默认人民币", shareddomain.CloudProviderTencent, "", "2024-03-01", "CNY", 100, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := cfg.ConvertAt(ctx, tt.provider, 100.0, tt.rawCurrency, tt.date)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCurrency, r.Currency)
+			assert.InDelta(t, tt.wantCNY, r.AmountCNY, 0.0001)
+			assert.InDelta(t, tt.wantRate, r.Rate, 0.0001)
+		})
+	}
+}
+
+func TestNormalize_DatedExchangeRate(t *testing.T) {
+	svc := newTestService()
+	rates := &fakeRateProvider{rates: map[string]float64{
+		"EUR@2024-03-01": 7.8,
+		"EUR@2024-04-01": 7.9,
+	}}
+	svc.SetRateProvider(rates)
+
+	items := []billing.RawBillItem{
+		{Provider: shareddomain.CloudProviderAWS, ServiceType: "Amazon S3", ResourceID: "b-1", Amount: 10, Currency: "EUR", BillingCycle: "2024-03"},
+		{Provider: shareddomain.CloudProviderAWS, ServiceType: "Amazon S3", ResourceID: "b-2", Amount: 20, Currency: "EUR", BillingCycle: "2024-03"},
+		{Provider: shareddomain.CloudProviderAWS, ServiceType: "Amazon S3", ResourceID: "b-3", Amount: 10, Currency: "EUR", BillingCycle: "2024-04"},
+	}
+
+	bills, err := svc.Normalize(context.Background(), items)
+	assert.NoError(t, err)
+	assert.Len(t, bills, 3)
+	assert.Equal(t, "EUR", bills[0].Currency)
+	assert.InDelta(t, 78.0, bills[0].AmountCNY, 0.0001)
+	assert.Equal(t, 7.8, bills[0].ExchangeRate)
+	assert.InDelta(t, 156.0, bills[1].AmountCNY, 0.0001)
+	assert.InDelta(t, 79.0, bills[2].AmountCNY, 0.0001)
+	// 同一批次内相同币种和日期只查询一次
+	assert.Equal(t, 2, rates.calls)
+}
+
+func TestNormalize_RateStoreError(t *testing.T) {
+	svc := newTestService()
+	rates := &fakeRateProvider{err: errors.New("mongo: connection refused")}
+	svc.SetRateProvider(rates)
+
+	items := []billing.RawBillItem{
+		{Provider: shareddomain.CloudProviderAWS, ServiceType: "Amazon S3", ResourceID: "b-1", Amount: 10, Currency: "USD", BillingCycle: "2024-03"},
+	}
+
+	// 汇率库故障不按汇率缺失处理：不回退固定汇率，也不以 0 写入人民币金额
+	bills, err := svc.Normalize(context.Background(), items)
+	assert.Error(t, err)
+	assert.Nil(t, bills)
+
+	_, err = svc.GetCurrencyConfig().ConvertAt(context.Background(), shareddomain.CloudProviderAWS, 10, "USD", "2024-03-01")
+	assert.Error(t, err)
+
+	// 故障恢复后重新查询，失败结果未被缓存
+	rates.err = nil
+	rates.rates = map[string]float64{"USD@2024-03-01": 7.1}
+	bills, err = svc.Normalize(context.Background(), items)
+	assert.NoError(t, err)
+	assert.InDelta(t, 71.0, bills[0].AmountCNY, 0.0001)
+}
+
 func TestParseBillingCycle(t *testing.T) {
 	start, end := parseBillingCycle("2024-02")
 	assert.Equal(t, 2024, start.Year())
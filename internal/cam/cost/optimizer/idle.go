@@ -0,0 +1,510 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// RecTypeReleaseEIP 未绑定弹性公网 IP 释放建议
+	RecTypeReleaseEIP = "release_eip"
+	// RecTypeReleaseLB 无健康后端负载均衡释放建议
+	RecTypeReleaseLB = "release_lb"
+	// RecTypeDeleteSnapshot 孤立 / 过期快照删除建议
+	RecTypeDeleteSnapshot = "delete_snapshot"
+	// RecTypeDeleteImage 未使用自定义镜像删除建议
+	RecTypeDeleteImage = "delete_image"
+	// RecTypeDeleteBucket 空存储桶删除建议
+	RecTypeDeleteBucket = "delete_bucket"
+	// RecTypeReleaseStoppedInstance 已停机但仍在计费的实例释放建议
+	RecTypeReleaseStoppedInstance = "release_stopped_instance"
+	// RecTypeReleaseNAT 未绑定公网 IP 的 NAT 网关释放建议
+	RecTypeReleaseNAT = "release_nat"
+	// RecTypeReleaseENI 未挂载弹性网卡释放建议
+	RecTypeReleaseENI = "release_eni"
+
+	// idleCostWindowDays 闲置资源成本统计窗口
+	idleCostWindowDays = 30
+	// defaultSnapshotRetentionDays 默认快照保留天数，超过后建议删除
+	defaultSnapshotRetentionDays = 180
+	// emptyBucketGraceDays 新建存储桶的观察期，期内为空不视为闲置
+	emptyBucketGraceDays = 7
+)
+
+// 资产类型，与资产同步写入的 model_uid 后缀一致
+const (
+	AssetKindECS      = "ecs"
+	AssetKindDisk     = "disk"
+	AssetKindSnapshot = "snapshot"
+	AssetKindImage    = "image"
+	AssetKindEIP      = "eip"
+	AssetKindENI      = "eni"
+	AssetKindLB       = "lb"
+	AssetKindOSS      = "oss"
+)
+
+// AssetInventory 资产清单查询接口（数据来自资产同步）
+type AssetInventory interface {
+	// ListAssets 查询租户下指定类型的全部资产，kind 见 AssetKind* 常量
+	ListAssets(ctx context.Context, tenantID, kind string) ([]Asset, error)
+}
+
+// Asset 资产同步写入的资源实例
+type Asset struct {
+	ResourceID   string
+	ResourceName string
+	Provider     string
+	AccountID    int64
+	Region       string
+	Attributes   map[string]interface{}
+}
+
+// eipUnboundStatuses 各云平台 EIP 未绑定状态（小写）
+var eipUnboundStatuses = map[string]bool{
+	"":          true, // AWS 无状态字段
+	"available": true, // 阿里云 / 火山引擎
+	"unbind":    true, // 腾讯云
+	"down":      true, // 华为云
+}
+
+// lbUnhealthyStatuses 后端服务器不健康状态（小写）
+var lbUnhealthyStatuses = map[string]bool{
+	"abnormal":    true,
+	"unhealthy":   true,
+	"unavailable": true,
+	"offline":     true,
+	"dead":        true,
+}
+
+// natIDPrefixes NAT 网关资源 ID 前缀
+// 仅包含 EIP 绑定信息中直接记录 NAT 网关 ID 的云平台；AWS / 华为云的 EIP 经网卡关联 NAT，无法据此判断
+var natIDPrefixes = map[string]string{
+	"aliyun":     "ngw-",
+	"tencent":    "nat-",
+	"volcano":    "ngw-",
+	"volcengine": "ngw-",
+}
+
+// SetAssetInventory 设置资产清单查询（可选注入，未设置时不检测闲置 / 孤立资源）
+func (s *OptimizerService) SetAssetInventory(assets AssetInventory) {
+	s.assets = assets
+}
+
+// SetSnapshotRetention 设置快照保留天数，超过保留期的快照生成删除建议
+func (s *OptimizerService) SetSnapshotRetention(days int) {
+	if days > 0 {
+		s.snapshotRetentionDays = days
+	}
+}
+
+// idleScan 单次闲置资源检测的上下文，缓存资产清单与近 30 天账单
+type idleScan struct {
+	tenantID string
+	now      time.Time
+	costs    map[string]*billStats
+	natBills map[string]*billStats
+	assets   map[string][]Asset
+}
+
+// monthlyCost 资源按日均账单折算的月成本，无账单时为 0
+func (sc *idleScan) monthlyCost(resourceIDs ...string) float64 {
+	var total float64
+	for _, id := range resourceIDs {
+		if stats, ok := sc.costs[id]; ok && len(stats.days) > 0 {
+			total += stats.totalAmount / float64(len(stats.days)) * 30
+		}
+	}
+	return total
+}
+
+// accountsWith 有指定类型资产的云账号，用于避免资产未同步时误判为孤立
+func (sc *idleScan) accountsWith(kind string) map[int64]bool {
+	accounts := make(map[int64]bool)
+	for _, a := range sc.assets[kind] {
+		accounts[a.AccountID] = true
+	}
+	return accounts
+}
+
+// detectIdleResources 基于资产同步数据检测闲置与孤立资源，预估节省取自近 30 天账单
+func (s *OptimizerService) detectIdleResources(ctx context.Context, tenantID string) ([]domain.Recommendation, error) {
+	if s.assets == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	bills, err := s.billDAO.ListUnifiedBills(ctx, repository.UnifiedBillFilter{
+		TenantID:  tenantID,
+		StartDate: now.AddDate(0, 0, -idleCostWindowDays).Format("2006-01-02"),
+		EndDate:   now.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list bills: %w", err)
+	}
+
+	var natBills []domain.UnifiedBill
+	for _, bill := range bills {
+		if bill.ServiceType == domain.ServiceTypeNetwork && isNATGatewayID(bill.Provider, bill.ResourceID) {
+			natBills = append(natBills, bill)
+		}
+	}
+
+	scan := &idleScan{
+		tenantID: tenantID,
+		now:      now,
+		costs:    aggregateBills(bills),
+		natBills: aggregateBills(natBills),
+		assets:   make(map[string][]Asset),
+	}
+	for _, kind := range []string{AssetKindECS, AssetKindDisk, AssetKindSnapshot, AssetKindImage,
+		AssetKindEIP, AssetKindENI, AssetKindLB, AssetKindOSS} {
+		assets, err := s.assets.ListAssets(ctx, tenantID, kind)
+		if err != nil {
+			// 单类资产查询失败只跳过依赖该类资产的检测
+			s.logger.Warn("list assets failed",
+				elog.String("tenant_id", tenantID),
+				elog.String("kind", kind),
+				elog.FieldErr(err))
+			continue
+		}
+		scan.assets[kind] = assets
+	}
+
+	var recs []domain.Recommendation
+	recs = append(recs, scan.unboundEIPs()...)
+	recs = append(recs, scan.idleLoadBalancers()...)
+	recs = append(recs, scan.staleSnapshots(s.snapshotRetentionDays)...)
+	recs = append(recs, scan.unusedImages()...)
+	recs = append(recs, scan.emptyBuckets()...)
+	recs = append(recs, scan.stoppedInstances()...)
+	recs = append(recs, scan.unboundNATGateways()...)
+	recs = append(recs, scan.detachedENIs()...)
+	return recs, nil
+}
+
+// idleRecommendation 构造闲置资源建议
+func (sc *idleScan) idleRecommendation(recType string, asset Asset, reason string, saving float64) domain.Recommendation {
+	return domain.Recommendation{
+		Type:            recType,
+		Provider:        asset.Provider,
+		AccountID:       asset.AccountID,
+		ResourceID:      asset.ResourceID,
+		ResourceName:    asset.ResourceName,
+		Region:          asset.Region,
+		Reason:          reason,
+		EstimatedSaving: saving,
+		Status:          StatusPending,
+		TenantID:        sc.tenantID,
+	}
+}
+
+// unboundEIPs 未绑定任何实例的弹性公网 IP
+func (sc *idleScan) unboundEIPs() []domain.Recommendation {
+	var recs []domain.Recommendation
+	for _, eip := range sc.assets[AssetKindEIP] {
+		if stringAttr(eip.Attributes, "instance_id") != "" {
+			continue
+		}
+		if !eipUnboundStatuses[strings.ToLower(stringAttr(eip.Attributes, "status"))] {
+			continue
+		}
+		cost := sc.monthlyCost(eip.ResourceID)
+		recs = append(recs, sc.idleRecommendation(RecTypeReleaseEIP, eip,
+			fmt.Sprintf("弹性公网 IP %s 未绑定任何实例，月成本约 %.2f 元，建议释放",
+				stringAttr(eip.Attributes, "ip_address"), cost),
+			cost))
+	}
+	return recs
+}
+
+// idleLoadBalancers 无后端服务器或后端全部不健康的负载均衡
+// 同步时未成功拉取后端信息（backend_fetched 非 true）的负载均衡无法判断是否闲置，直接跳过
+func (sc *idleScan) idleLoadBalancers() []domain.Recommendation {
+	var recs []domain.Recommendation
+	for _, lb := range sc.assets[AssetKindLB] {
+		if fetched, _ := lb.Attributes["backend_fetched"].(bool); !fetched {
+			continue
+		}
+		backends := mapSliceAttr(lb.Attributes, "backend_servers")
+		var reason string
+		switch {
+		case len(backends) == 0 && numberAttr(lb.Attributes, "backend_server_count") == 0:
+			reason = "负载均衡未挂载任何后端服务器"
+		case len(backends) > 0 && allBackendsUnhealthy(backends):
+			reason = fmt.Sprintf("负载均衡的 %d 个后端服务器均不健康", len(backends))
+		default:
+			continue
+		}
+		cost := sc.monthlyCost(lb.ResourceID)
+		recs = append(recs, sc.idleRecommendation(RecTypeReleaseLB, lb,
+			fmt.Sprintf("%s，月成本约 %.2f 元，建议释放", reason, cost), cost))
+	}
+	return recs
+}
+
+// allBackendsUnhealthy 后端服务器是否全部处于不健康状态，状态缺失时视为健康
+func allBackendsUnhealthy(backends []map[string]interface{}) bool {
+	for _, b := range backends {
+		if !lbUnhealthyStatuses[strings.ToLower(stringAttr(b, "status"))] {
+			return false
+		}
+	}
+	return true
+}
+
+// staleSnapshots 源云盘已删除或超过保留期的快照，已被镜像引用的快照不处理
+func (sc *idleScan) staleSnapshots(retentionDays int) []domain.Recommendation {
+	disks := make(map[string]bool)
+	for _, d := range sc.assets[AssetKindDisk] {
+		disks[d.ResourceID] = true
+	}
+	diskAccounts := sc.accountsWith(AssetKindDisk)
+	cutoff := sc.now.AddDate(0, 0, -retentionDays)
+
+	var recs []domain.Recommendation
+	for _, snap := range sc.assets[AssetKindSnapshot] {
+		if numberAttr(snap.Attributes, "used_image_count") > 0 {
+			continue
+		}
+		var reason string
+		sourceDisk := stringAttr(snap.Attributes, "source_disk_id")
+		created, hasCreated := timeAttr(snap.Attributes, "creation_time")
+		switch {
+		case sourceDisk != "" && diskAccounts[snap.AccountID] && !disks[sourceDisk]:
+			reason = fmt.Sprintf("快照的源云盘 %s 已不存在", sourceDisk)
+		case hasCreated && created.Before(cutoff):
+			reason = fmt.Sprintf("快照创建于 %s，已超过 %d 天保留期", created.Format("2006-01-02"), retentionDays)
+		default:
+			continue
+		}
+		cost := sc.monthlyCost(snap.ResourceID)
+		recs = append(recs, sc.idleRecommendation(RecTypeDeleteSnapshot, snap,
+			fmt.Sprintf("%s，月成本约 %.2f 元，建议删除", reason, cost), cost))
+	}
+	return recs
+}
+
+// unusedImages 未被任何实例使用的自定义镜像
+func (sc *idleScan) unusedImages() []domain.Recommendation {
+	used := make(map[string]bool)
+	for _, inst := range sc.assets[AssetKindECS] {
+		if id := stringAttr(inst.Attributes, "image_id"); id != "" {
+			used[id] = true
+		}
+	}
+	ecsAccounts := sc.accountsWith(AssetKindECS)
+
+	var recs []domain.Recommendation
+	for _, img := range sc.assets[AssetKindImage] {
+		if !isCustomImage(img.Attributes) || !ecsAccounts[img.AccountID] {
+			continue
+		}
+		if used[img.ResourceID] || numberAttr(img.Attributes, "instance_count") > 0 {
+			continue
+		}
+		cost := sc.monthlyCost(img.ResourceID)
+		recs = append(recs, sc.idleRecommendation(RecTypeDeleteImage, img,
+			fmt.Sprintf("自定义镜像未被任何实例使用，月成本约 %.2f 元，建议删除", cost), cost))
+	}
+	return recs
+}
+
+// isCustomImage 是否为账号自有的私有镜像
+func isCustomImage(attrs map[string]interface{}) bool {
+	if public, _ := attrs["is_public"].(bool); public {
+		return false
+	}
+	switch strings.ToLower(stringAttr(attrs, "image_owner_alias")) {
+	case "", "self", "private":
+		return true
+	}
+	return false
+}
+
+// emptyBuckets 对象数与存储量均为 0 且已过观察期的存储桶
+// 对象数或存储量缺失（未采集到统计）的存储桶不视为空桶
+func (sc *idleScan) emptyBuckets() []domain.Recommendation {
+	grace := sc.now.AddDate(0, 0, -emptyBucketGraceDays)
+	var recs []domain.Recommendation
+	for _, bucket := range sc.assets[AssetKindOSS] {
+		if !hasAttrs(bucket.Attributes, "object_count", "storage_size") {
+			continue
+		}
+		if numberAttr(bucket.Attributes, "object_count") > 0 || numberAttr(bucket.Attributes, "storage_size") > 0 {
+			continue
+		}
+		if created, ok := timeAttr(bucket.Attributes, "creation_time"); ok && created.After(grace) {
+			continue
+		}
+		cost := sc.monthlyCost(bucket.ResourceID)
+		recs = append(recs, sc.idleRecommendation(RecTypeDeleteBucket, bucket,
+			fmt.Sprintf("存储桶中没有任何对象，月成本约 %.2f 元，建议删除", cost), cost))
+	}
+	return recs
+}
+
+// hasAttrs 属性中是否同时存在且非空的指定字段
+func hasAttrs(attrs map[string]interface{}, keys ...string) bool {
+	for _, key := range keys {
+		if v, ok := attrs[key]; !ok || v == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// stoppedInstances 已停机但实例或云盘仍在计费的实例
+func (sc *idleScan) stoppedInstances() []domain.Recommendation {
+	var recs []domain.Recommendation
+	for _, inst := range sc.assets[AssetKindECS] {
+		if !types.IsStoppedStatus(stringAttr(inst.Attributes, "status")) {
+			continue
+		}
+		diskIDs := instanceDiskIDs(inst.Attributes)
+		diskCost := sc.monthlyCost(diskIDs...)
+		cost := sc.monthlyCost(inst.ResourceID) + diskCost
+		if cost <= 0 {
+			continue
+		}
+		recs = append(recs, sc.idleRecommendation(RecTypeReleaseStoppedInstance, inst,
+			fmt.Sprintf("实例已停机，仍在计费（其中 %d 块云盘月成本约 %.2f 元），合计月成本约 %.2f 元，建议释放或制作镜像后释放",
+				len(diskIDs), diskCost, cost),
+			cost))
+	}
+	return recs
+}
+
+// instanceDiskIDs 实例挂载的系统盘与数据盘 ID
+func instanceDiskIDs(attrs map[string]interface{}) []string {
+	var ids []string
+	if id := stringAttr(attrs, "system_disk_id"); id != "" {
+		ids = append(ids, id)
+	}
+	for _, d := range mapSliceAttr(attrs, "data_disks") {
+		// 数据盘结构体未声明 bson 标签，字段名按小写存储
+		for _, key := range []string{"diskid", "disk_id"} {
+			if id := stringAttr(d, key); id != "" {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// unboundNATGateways 有账单但没有任何 EIP 绑定的 NAT 网关
+func (sc *idleScan) unboundNATGateways() []domain.Recommendation {
+	bound := make(map[string]bool)
+	for _, eip := range sc.assets[AssetKindEIP] {
+		if id := stringAttr(eip.Attributes, "instance_id"); id != "" {
+			bound[id] = true
+		}
+	}
+	eipAccounts := sc.accountsWith(AssetKindEIP)
+
+	var recs []domain.Recommendation
+	for resourceID, stats := range sc.natBills {
+		if bound[resourceID] || !eipAccounts[stats.accountID] {
+			continue
+		}
+		nat := Asset{
+			ResourceID:   resourceID,
+			ResourceName: stats.resourceName,
+			Provider:     stats.provider,
+			AccountID:    stats.accountID,
+			Region:       stats.region,
+		}
+		cost := sc.monthlyCost(resourceID)
+		recs = append(recs, sc.idleRecommendation(RecTypeReleaseNAT, nat,
+			fmt.Sprintf("NAT 网关未绑定任何弹性公网 IP，月成本约 %.2f 元，建议释放", cost), cost))
+	}
+	return recs
+}
+
+// isNATGatewayID 按资源 ID 前缀判断是否为 NAT 网关
+func isNATGatewayID(provider, resourceID string) bool {
+	prefix, ok := natIDPrefixes[provider]
+	return ok && strings.HasPrefix(resourceID, prefix)
+}
+
+// detachedENIs 未挂载到任何实例的辅助弹性网卡
+func (sc *idleScan) detachedENIs() []domain.Recommendation {
+	var recs []domain.Recommendation
+	for _, eni := range sc.assets[AssetKindENI] {
+		if stringAttr(eni.Attributes, "type") == types.ENITypePrimary || stringAttr(eni.Attributes, "instance_id") != "" {
+			continue
+		}
+		if types.NormalizeENIStatus(eni.Provider, stringAttr(eni.Attributes, "status")) != types.ENIStatusAvailable {
+			continue
+		}
+		cost := sc.monthlyCost(eni.ResourceID)
+		recs = append(recs, sc.idleRecommendation(RecTypeReleaseENI, eni,
+			fmt.Sprintf("弹性网卡未挂载到任何实例，月成本约 %.2f 元，建议释放", cost), cost))
+	}
+	return recs
+}
+
+// timeAttrLayouts 资产同步中各云平台创建时间的常见格式
+var timeAttrLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z",
+	"2006-01-02T15:04:05Z",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// timeAttr 解析资产属性中的时间，支持字符串与 MongoDB 日期类型
+func timeAttr(attrs map[string]interface{}, key string) (time.Time, bool) {
+	switch v := attrs[key].(type) {
+	case time.Time:
+		return v, !v.IsZero()
+	case primitive.DateTime:
+		return v.Time(), v != 0
+	case string:
+		for _, layout := range timeAttrLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// mapSliceAttr 解析资产属性中的对象数组，兼容 MongoDB 解码后的 primitive 类型
+func mapSliceAttr(attrs map[string]interface{}, key string) []map[string]interface{} {
+	var items []interface{}
+	switch v := attrs[key].(type) {
+	case primitive.A:
+		items = v
+	case []interface{}:
+		items = v
+	case []map[string]interface{}:
+		return v
+	default:
+		return nil
+	}
+	result := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		switch it := item.(type) {
+		case map[string]interface{}:
+			result = append(result, it)
+		case primitive.M:
+			result = append(result, it)
+		case primitive.D:
+			m := make(map[string]interface{}, len(it))
+			for _, e := range it {
+				m[e.Key] = e.Value
+			}
+			result = append(result, m)
+		}
+	}
+	return result
+}
@@ -0,0 +1,225 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mockAssetInventory struct {
+	assets map[string][]Asset
+	errs   map[string]error
+}
+
+func (m *mockAssetInventory) ListAssets(_ context.Context, _, kind string) ([]Asset, error) {
+	if err := m.errs[kind]; err != nil {
+		return nil, err
+	}
+	return m.assets[kind], nil
+}
+
+func asset(id string, attrs map[string]interface{}) Asset {
+	return Asset{ResourceID: id, ResourceName: id + "-name", Provider: "aliyun", AccountID: 1, Region: "cn-hangzhou", Attributes: attrs}
+}
+
+// dailyBills 生成资源近 days 天每日金额相同的账单
+func dailyBills(resourceID, serviceType string, amount float64, days int) []costdomain.UnifiedBill {
+	var bills []costdomain.UnifiedBill
+	for i := 0; i < days; i++ {
+		bills = append(bills, costdomain.UnifiedBill{
+			Provider:    "aliyun",
+			AccountID:   1,
+			ServiceType: serviceType,
+			ResourceID:  resourceID,
+			Region:      "cn-hangzhou",
+			AmountCNY:   amount,
+			TenantID:    "tenant-1",
+			BillingDate: time.Now().AddDate(0, 0, -i).Format("2006-01-02"),
+		})
+	}
+	return bills
+}
+
+func newIdleTestService(inventory *mockAssetInventory, bills []costdomain.UnifiedBill) (*OptimizerService, *mockOptimizerDAO) {
+	optimizerDAO := &mockOptimizerDAO{}
+	billDAO := &mockBillDAO{
+		listUnifiedBillsFn: func(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+			return bills, nil
+		},
+	}
+	svc := NewOptimizerService(optimizerDAO, billDAO, elog.DefaultLogger)
+	svc.SetAssetInventory(inventory)
+	return svc, optimizerDAO
+}
+
+func recsByType(recs []costdomain.Recommendation) map[string][]string {
+	result := make(map[string][]string)
+	for _, rec := range recs {
+		result[rec.Type] = append(result[rec.Type], rec.ResourceID)
+	}
+	return result
+}
+
+func TestDetectIdleResources(t *testing.T) {
+	old := time.Now().AddDate(0, -8, 0).Format(time.RFC3339)
+	recent := time.Now().AddDate(0, 0, -2).Format(time.RFC3339)
+
+	inventory := &mockAssetInventory{assets: map[string][]Asset{
+		AssetKindEIP: {
+			asset("eip-free", map[string]interface{}{"status": "Available", "ip_address": "1.1.1.1"}),
+			asset("eip-bound", map[string]interface{}{"status": "InUse", "instance_id": "i-running"}),
+			asset("eip-nat", map[string]interface{}{"status": "InUse", "instance_id": "ngw-bound", "instance_type": "Nat"}),
+		},
+		AssetKindLB: {
+			asset("lb-empty", map[string]interface{}{"backend_server_count": int32(0), "backend_fetched": true}),
+			// 后端查询失败或未上报 backend_fetched 的负载均衡不能判定为闲置
+			asset("lb-fetch-failed", map[string]interface{}{"backend_server_count": int32(0), "backend_fetched": false}),
+			asset("lb-legacy", map[string]interface{}{"backend_server_count": int32(0)}),
+			asset("lb-unhealthy", map[string]interface{}{"backend_fetched": true, "backend_servers": primitive.A{
+				primitive.M{"serverid": "i-1", "status": "abnormal"},
+				primitive.D{{Key: "serverid", Value: "i-2"}, {Key: "status", Value: "Unhealthy"}},
+			}}),
+			asset("lb-ok", map[string]interface{}{"backend_fetched": true, "backend_servers": primitive.A{
+				primitive.M{"serverid": "i-1", "status": "abnormal"},
+				primitive.M{"serverid": "i-2", "status": "normal"},
+			}}),
+		},
+		AssetKindDisk: {
+			asset("d-live", map[string]interface{}{"instance_id": "i-running"}),
+			asset("d-sys", map[string]interface{}{"instance_id": "i-stopped"}),
+			asset("d-data", map[string]interface{}{"instance_id": "i-stopped"}),
+		},
+		AssetKindSnapshot: {
+			asset("s-orphan", map[string]interface{}{"source_disk_id": "d-gone", "creation_time": recent}),
+			asset("s-old", map[string]interface{}{"source_disk_id": "d-live", "creation_time": old}),
+			asset("s-fresh", map[string]interface{}{"source_disk_id": "d-live", "creation_time": recent}),
+			asset("s-image", map[string]interface{}{"source_disk_id": "d-gone", "used_image_count": int64(1)}),
+		},
+		AssetKindECS: {
+			asset("i-running", map[string]interface{}{"status": "Running", "image_id": "m-used"}),
+			asset("i-stopped", map[string]interface{}{
+				"status":         "Stopped",
+				"image_id":       "m-used",
+				"system_disk_id": "d-sys",
+				"data_disks":     primitive.A{primitive.M{"diskid": "d-data"}},
+			}),
+		},
+		AssetKindImage: {
+			asset("m-used", map[string]interface{}{"image_owner_alias": "self"}),
+			asset("m-unused", map[string]interface{}{"image_owner_alias": "self"}),
+			asset("m-system", map[string]interface{}{"image_owner_alias": "system", "is_public": true}),
+		},
+		AssetKindOSS: {
+			asset("bucket-empty", map[string]interface{}{"object_count": int64(0), "storage_size": int64(0), "creation_time": old}),
+			asset("bucket-new", map[string]interface{}{"object_count": int64(0), "storage_size": int64(0), "creation_time": recent}),
+			asset("bucket-used", map[string]interface{}{"object_count": int64(10), "storage_size": int64(1024)}),
+			// 统计字段缺失时不能判定为空桶
+			asset("bucket-no-size", map[string]interface{}{"object_count": int64(0), "creation_time": old}),
+			asset("bucket-no-stats", map[string]interface{}{"creation_time": old}),
+		},
+		AssetKindENI: {
+			asset("eni-free", map[string]interface{}{"status": "Available", "type": "Secondary"}),
+			asset("eni-primary", map[string]interface{}{"status": "Available", "type": "Primary"}),
+			asset("eni-used", map[string]interface{}{"status": "InUse", "type": "Secondary", "instance_id": "i-running"}),
+		},
+	}}
+
+	var bills []costdomain.UnifiedBill
+	bills = append(bills, dailyBills("eip-free", "network", 1, 10)...)
+	bills = append(bills, dailyBills("d-sys", "storage", 2, 10)...)
+	bills = append(bills, dailyBills("d-data", "storage", 3, 10)...)
+	bills = append(bills, dailyBills("ngw-idle", "network", 4, 10)...)
+	bills = append(bills, dailyBills("ngw-bound", "network", 4, 10)...)
+
+	svc, _ := newIdleTestService(inventory, bills)
+	recs, err := svc.detectIdleResources(context.Background(), "tenant-1")
+	require.NoError(t, err)
+
+	byType := recsByType(recs)
+	assert.Equal(t, []string{"eip-free"}, byType[RecTypeReleaseEIP])
+	assert.ElementsMatch(t, []string{"lb-empty", "lb-unhealthy"}, byType[RecTypeReleaseLB])
+	assert.ElementsMatch(t, []string{"s-orphan", "s-old"}, byType[RecTypeDeleteSnapshot])
+	assert.Equal(t, []string{"m-unused"}, byType[RecTypeDeleteImage])
+	assert.Equal(t, []string{"bucket-empty"}, byType[RecTypeDeleteBucket])
+	assert.Equal(t, []string{"i-stopped"}, byType[RecTypeReleaseStoppedInstance])
+	assert.Equal(t, []string{"ngw-idle"}, byType[RecTypeReleaseNAT])
+	assert.Equal(t, []string{"eni-free"}, byType[RecTypeReleaseENI])
+
+	for _, rec := range recs {
+		assert.Equal(t, "tenant-1", rec.TenantID)
+		assert.Equal(t, StatusPending, rec.Status)
+		switch rec.ResourceID {
+		case "eip-free":
+			assert.InDelta(t, 30.0, rec.EstimatedSaving, 0.01)
+		case "i-stopped":
+			// 系统盘 2 元/天 + 数据盘 3 元/天
+			assert.InDelta(t, 150.0, rec.EstimatedSaving, 0.01)
+		case "ngw-idle":
+			assert.InDelta(t, 120.0, rec.EstimatedSaving, 0.01)
+		}
+	}
+}
+
+func TestDetectIdleResources_SkipsOrphanChecksWithoutSyncedSources(t *testing.T) {
+	// 账号下没有同步云盘 / 实例 / EIP 时，不能据此判定快照、镜像、NAT 为孤立
+	inventory := &mockAssetInventory{
+		assets: map[string][]Asset{
+			AssetKindSnapshot: {asset("s-1", map[string]interface{}{"source_disk_id": "d-1"})},
+			AssetKindImage:    {asset("m-1", map[string]interface{}{"image_owner_alias": "self"})},
+		},
+		errs: map[string]error{AssetKindDisk: errors.New("mongo timeout")},
+	}
+	svc, _ := newIdleTestService(inventory, dailyBills("ngw-1", "network", 1, 3))
+
+	recs, err := svc.detectIdleResources(context.Background(), "tenant-1")
+	require.NoError(t, err)
+	assert.Empty(t, recs)
+}
+
+func TestDetectIdleResources_SnapshotRetention(t *testing.T) {
+	created := time.Now().AddDate(0, 0, -40).Format("2006-01-02T15:04Z")
+	inventory := &mockAssetInventory{assets: map[string][]Asset{
+		AssetKindSnapshot: {asset("s-1", map[string]interface{}{"creation_time": created})},
+	}}
+	svc, _ := newIdleTestService(inventory, nil)
+
+	recs, err := svc.detectIdleResources(context.Background(), "tenant-1")
+	require.NoError(t, err)
+	assert.Empty(t, recs, "默认保留期内不建议删除")
+
+	svc.SetSnapshotRetention(30)
+	recs, err = svc.detectIdleResources(context.Background(), "tenant-1")
+	require.NoError(t, err)
+	require.Len(t, recs, 1)
+	assert.Contains(t, recs[0].Reason, "30 天保留期")
+}
+
+func TestGenerateRecommendations_IdleRespectsDismiss(t *testing.T) {
+	inventory := &mockAssetInventory{assets: map[string][]Asset{
+		AssetKindEIP: {
+			asset("eip-1", map[string]interface{}{"status": "Available"}),
+			asset("eip-2", map[string]interface{}{"status": "Available"}),
+		},
+	}}
+	svc, optimizerDAO := newIdleTestService(inventory, nil)
+	expiry := time.Now().Add(24 * time.Hour)
+	optimizerDAO.findByResourceTypeFn = func(_ context.Context, _, resourceID, recType string) (costdomain.Recommendation, error) {
+		if resourceID == "eip-1" && recType == RecTypeReleaseEIP {
+			return costdomain.Recommendation{Status: StatusDismissed, DismissExpiry: &expiry}, nil
+		}
+		return costdomain.Recommendation{}, mongo.ErrNoDocuments
+	}
+
+	require.NoError(t, svc.GenerateRecommendations(context.Background(), "tenant-1"))
+	require.Len(t, optimizerDAO.createdRecs, 1)
+	assert.Equal(t, "eip-2", optimizerDAO.createdRecs[0].ResourceID)
+}
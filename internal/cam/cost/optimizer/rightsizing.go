@@ -0,0 +1,400 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/pricing"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// cpuHeadroomPct 按峰值折算到目标规格后的 CPU 利用率上限
+	cpuHeadroomPct = 70.0
+	// memoryHeadroomPct 按峰值折算到目标规格后的内存利用率上限
+	memoryHeadroomPct = 80.0
+)
+
+// ResourceInventory 资源当前规格查询接口（数据来自资产同步）
+type ResourceInventory interface {
+	// GetResourceSpec 查询资源当前规格，kind 为 ecs / rds / redis，资源不存在时返回 false
+	GetResourceSpec(ctx context.Context, tenantID, provider, kind, resourceID string) (ResourceSpec, bool, error)
+}
+
+// ResourceSpec 资源当前规格
+type ResourceSpec struct {
+	SpecName     string
+	CPU          int
+	MemoryGB     float64
+	Architecture string
+}
+
+// InstanceTypeSource 可用实例规格查询接口
+type InstanceTypeSource interface {
+	// ListAvailableInstanceTypes 查询云账号在指定地域可售的实例规格
+	ListAvailableInstanceTypes(ctx context.Context, accountID int64, region string) ([]types.InstanceTypeInfo, error)
+}
+
+// SpecFromAttributes 从资产同步写入的实例属性解析规格（内存属性单位为 MB）
+// 资产属性不含 CPU 架构，由价格目录补全
+func SpecFromAttributes(kind string, attrs map[string]interface{}) ResourceSpec {
+	var spec ResourceSpec
+	switch kind {
+	case pricing.KindECS:
+		spec.SpecName = stringAttr(attrs, "instance_type")
+		spec.CPU = int(numberAttr(attrs, "cpu"))
+		spec.MemoryGB = numberAttr(attrs, "memory") / 1024
+	case pricing.KindRDS:
+		spec.SpecName = stringAttr(attrs, "db_instance_class")
+		if spec.SpecName == "" {
+			spec.SpecName = stringAttr(attrs, "instance_class")
+		}
+		spec.CPU = int(numberAttr(attrs, "cpu"))
+		spec.MemoryGB = numberAttr(attrs, "memory") / 1024
+	case pricing.KindRedis:
+		// Redis 的 architecture 属性表示集群 / 标准版架构，不是 CPU 架构
+		spec.SpecName = stringAttr(attrs, "instance_class")
+		spec.MemoryGB = numberAttr(attrs, "capacity") / 1024
+	}
+	return spec
+}
+
+func stringAttr(attrs map[string]interface{}, key string) string {
+	v, _ := attrs[key].(string)
+	return v
+}
+
+func numberAttr(attrs map[string]interface{}, key string) float64 {
+	switch v := attrs[key].(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// usageProfile 资源在观测窗口内的利用率
+type usageProfile struct {
+	cpuDays    []DailyCPU
+	peakCPU    float64
+	peakMemory float64
+	hasMemory  bool
+}
+
+// hasCPU 是否有完整观测窗口的 CPU 数据
+func (u usageProfile) hasCPU() bool {
+	return len(u.cpuDays) >= lowCPUConsecutiveDays
+}
+
+// lowCPU 观测窗口内每日平均 CPU 均低于阈值
+func (u usageProfile) lowCPU() bool {
+	if !u.hasCPU() {
+		return false
+	}
+	for _, day := range u.cpuDays {
+		if day.AvgCPU >= lowCPUThreshold {
+			return false
+		}
+	}
+	return true
+}
+
+// loadUsage 加载资源观测窗口内的 CPU / 内存利用率，内存数据缺失时仅记录日志
+func (s *OptimizerService) loadUsage(ctx context.Context, resourceID string) (usageProfile, error) {
+	cpuDays, err := s.metrics.GetCPUUtilization(ctx, resourceID, lowCPUConsecutiveDays)
+	if err != nil {
+		return usageProfile{}, fmt.Errorf("get cpu utilization: %w", err)
+	}
+	usage := usageProfile{cpuDays: cpuDays}
+	for _, day := range cpuDays {
+		usage.peakCPU = max(usage.peakCPU, day.MaxCPU, day.AvgCPU)
+	}
+
+	memDays, err := s.metrics.GetMemoryUtilization(ctx, resourceID, lowCPUConsecutiveDays)
+	if err != nil {
+		s.logger.Warn("get memory utilization failed",
+			elog.String("resource_id", resourceID),
+			elog.FieldErr(err))
+		return usage, nil
+	}
+	if len(memDays) >= lowCPUConsecutiveDays {
+		usage.hasMemory = true
+		for _, day := range memDays {
+			usage.peakMemory = max(usage.peakMemory, day.MaxMemory, day.AvgMemory)
+		}
+	}
+	return usage, nil
+}
+
+// rightsizingRun 单次建议生成过程的上下文，缓存各账号地域的可售规格
+type rightsizingRun struct {
+	tenantID  string
+	available map[string]map[string]bool
+}
+
+func newRightsizingRun(tenantID string) *rightsizingRun {
+	return &rightsizingRun{tenantID: tenantID, available: make(map[string]map[string]bool)}
+}
+
+// rightsizeCompute 为低 CPU 计算实例生成降配建议
+// 规格或价格未知时退化为按比例估算节省的通用建议；规格已知但无满足余量的更便宜规格时不生成建议
+func (s *OptimizerService) rightsizeCompute(ctx context.Context, run *rightsizingRun, resourceID string, stats *billStats, usage usageProfile) (domain.Recommendation, bool) {
+	dailyAvg := stats.totalAmount / float64(len(stats.days))
+	generic := s.downsizeRecommendation(run.tenantID, resourceID, stats,
+		fmt.Sprintf("计算实例近 %d 天平均 CPU 均低于 %.0f%%，峰值 %.1f%%，日均成本 %.2f 元，建议降配以节省成本",
+			len(usage.cpuDays), lowCPUThreshold, usage.peakCPU, dailyAvg),
+		dailyAvg*30*downsizeSavingRatio, nil)
+
+	spec, current, ok := s.resolveSpec(ctx, run.tenantID, resourceID, stats, pricing.KindECS)
+	if !ok {
+		return generic, true
+	}
+	detail, ok := s.pickTarget(ctx, run, stats, pricing.KindECS, spec, current, usage)
+	if !ok {
+		return domain.Recommendation{}, false
+	}
+	return s.rightsizingRecommendation(run.tenantID, resourceID, stats, detail), true
+}
+
+// detectDatabaseRightsizing 检测 RDS / Redis 降配候选
+// 需要同时注入监控数据、资源规格和价格目录，仅在找到满足 CPU 与内存余量的更便宜规格时生成建议
+func (s *OptimizerService) detectDatabaseRightsizing(ctx context.Context, tenantID string) ([]domain.Recommendation, error) {
+	if s.metrics == nil || s.inventory == nil || s.catalogue == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	bills, err := s.billDAO.ListUnifiedBills(ctx, repository.UnifiedBillFilter{
+		TenantID:    tenantID,
+		ServiceType: "database",
+		StartDate:   now.AddDate(0, 0, -lowCPUConsecutiveDays).Format("2006-01-02"),
+		EndDate:     now.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list database bills: %w", err)
+	}
+
+	run := newRightsizingRun(tenantID)
+	var recs []domain.Recommendation
+	for resourceID, stats := range aggregateBills(bills) {
+		if len(stats.days) < lowCPUConsecutiveDays {
+			continue
+		}
+		for _, kind := range []string{pricing.KindRDS, pricing.KindRedis} {
+			spec, current, ok := s.resolveSpec(ctx, tenantID, resourceID, stats, kind)
+			if !ok {
+				continue
+			}
+			usage, err := s.loadUsage(ctx, resourceID)
+			if err != nil {
+				s.logger.Warn("load resource metrics failed",
+					elog.String("resource_id", resourceID),
+					elog.FieldErr(err))
+				break
+			}
+			if detail, ok := s.pickTarget(ctx, run, stats, kind, spec, current, usage); ok {
+				recs = append(recs, s.rightsizingRecommendation(tenantID, resourceID, stats, detail))
+			}
+			break
+		}
+	}
+	return recs, nil
+}
+
+// resolveSpec 查询资源当前规格及其目录价格
+func (s *OptimizerService) resolveSpec(ctx context.Context, tenantID, resourceID string, stats *billStats, kind string) (ResourceSpec, pricing.Spec, bool) {
+	if s.inventory == nil || s.catalogue == nil {
+		return ResourceSpec{}, pricing.Spec{}, false
+	}
+	spec, found, err := s.inventory.GetResourceSpec(ctx, tenantID, stats.provider, kind, resourceID)
+	if err != nil {
+		s.logger.Warn("get resource spec failed",
+			elog.String("resource_id", resourceID),
+			elog.String("kind", kind),
+			elog.FieldErr(err))
+		return ResourceSpec{}, pricing.Spec{}, false
+	}
+	if !found || spec.SpecName == "" {
+		return ResourceSpec{}, pricing.Spec{}, false
+	}
+	current, ok := s.catalogue.Lookup(stats.provider, kind, spec.SpecName)
+	if !ok {
+		return ResourceSpec{}, pricing.Spec{}, false
+	}
+	// 资产属性缺失的字段以价格目录为准
+	if spec.CPU == 0 {
+		spec.CPU = current.CPU
+	}
+	if spec.MemoryGB == 0 {
+		spec.MemoryGB = current.MemoryGB
+	}
+	if spec.Architecture == "" {
+		spec.Architecture = current.Architecture
+	}
+	return spec, current, true
+}
+
+// pickTarget 在价格目录中选择目标规格：同架构、更便宜、按峰值折算后 CPU 与内存均有余量，
+// 取月价最低者，同价时优先同规格族
+func (s *OptimizerService) pickTarget(ctx context.Context, run *rightsizingRun, stats *billStats, kind string, spec ResourceSpec, current pricing.Spec, usage usageProfile) (*domain.RightsizingDetail, bool) {
+	currentPrice := current.PriceIn(stats.region)
+	available := s.availableTypes(ctx, run, stats, kind)
+
+	var best *domain.RightsizingDetail
+	for _, candidate := range s.catalogue.List(stats.provider, kind) {
+		if strings.EqualFold(candidate.Name, current.Name) {
+			continue
+		}
+		if available != nil && !available[strings.ToLower(candidate.Name)] {
+			continue
+		}
+		if archClass(candidate.Architecture) != archClass(spec.Architecture) {
+			continue
+		}
+		price := candidate.PriceIn(stats.region)
+		if price >= currentPrice {
+			continue
+		}
+		projectedCPU, ok := projectCPU(usage, spec.CPU, candidate.CPU)
+		if !ok {
+			continue
+		}
+		projectedMemory, ok := projectMemory(usage, spec.MemoryGB, candidate.MemoryGB)
+		if !ok {
+			continue
+		}
+
+		sameFamily := candidate.Family == current.Family
+		if best != nil && (price > best.TargetMonthlyPrice || (price == best.TargetMonthlyPrice && (best.SameFamily || !sameFamily))) {
+			continue
+		}
+		best = &domain.RightsizingDetail{
+			Kind:                kind,
+			CurrentType:         current.Name,
+			TargetType:          candidate.Name,
+			SameFamily:          sameFamily,
+			CurrentCPU:          spec.CPU,
+			TargetCPU:           candidate.CPU,
+			CurrentMemoryGB:     spec.MemoryGB,
+			TargetMemoryGB:      candidate.MemoryGB,
+			PeakCPUPct:          usage.peakCPU,
+			PeakMemoryPct:       usage.peakMemory,
+			ProjectedCPUPct:     projectedCPU,
+			ProjectedMemoryPct:  projectedMemory,
+			CurrentMonthlyPrice: currentPrice,
+			TargetMonthlyPrice:  price,
+			MonthlyPriceDelta:   currentPrice - price,
+			Currency:            current.Currency,
+		}
+	}
+	return best, best != nil
+}
+
+// availableTypes 获取账号地域内可售的 ECS 规格（小写），未注入或查询失败时返回 nil 表示不限制
+func (s *OptimizerService) availableTypes(ctx context.Context, run *rightsizingRun, stats *billStats, kind string) map[string]bool {
+	if kind != pricing.KindECS || s.typeSource == nil {
+		return nil
+	}
+	key := fmt.Sprintf("%d/%s", stats.accountID, stats.region)
+	if cached, ok := run.available[key]; ok {
+		return cached
+	}
+
+	infos, err := s.typeSource.ListAvailableInstanceTypes(ctx, stats.accountID, stats.region)
+	if err != nil {
+		s.logger.Warn("list available instance types failed",
+			elog.Int64("account_id", stats.accountID),
+			elog.String("region", stats.region),
+			elog.FieldErr(err))
+		run.available[key] = nil
+		return nil
+	}
+	available := make(map[string]bool, len(infos))
+	for _, info := range infos {
+		available[strings.ToLower(info.InstanceType)] = true
+	}
+	run.available[key] = available
+	return available
+}
+
+// projectCPU 按峰值折算目标规格的 CPU 利用率；两侧均无 CPU 维度（如按内存计费的 Redis）时不校验
+func projectCPU(usage usageProfile, currentCPU, targetCPU int) (float64, bool) {
+	if currentCPU == 0 && targetCPU == 0 {
+		return 0, true
+	}
+	if currentCPU == 0 || targetCPU == 0 || !usage.hasCPU() {
+		return 0, false
+	}
+	projected := usage.peakCPU * float64(currentCPU) / float64(targetCPU)
+	return projected, projected <= cpuHeadroomPct
+}
+
+// projectMemory 按峰值折算目标规格的内存利用率；无内存监控数据时要求目标内存不低于当前内存
+func projectMemory(usage usageProfile, currentGB, targetGB float64) (float64, bool) {
+	if currentGB <= 0 || targetGB <= 0 {
+		return 0, false
+	}
+	if !usage.hasMemory {
+		return 0, targetGB >= currentGB
+	}
+	projected := usage.peakMemory * currentGB / targetGB
+	return projected, projected <= memoryHeadroomPct
+}
+
+// archClass 归一 CPU 架构名称
+func archClass(arch string) string {
+	switch strings.ToLower(arch) {
+	case "x86_64", "x86", "amd64", "i386":
+		return "x86"
+	case "arm64", "aarch64", "arm":
+		return "arm"
+	}
+	return strings.ToLower(arch)
+}
+
+// rightsizingRecommendation 根据目标规格构造降配建议，预估节省按近期账单月成本 × 目录价降幅比例计算
+func (s *OptimizerService) rightsizingRecommendation(tenantID, resourceID string, stats *billStats, detail *domain.RightsizingDetail) domain.Recommendation {
+	monthlyCost := stats.totalAmount / float64(len(stats.days)) * 30
+	saving := monthlyCost * detail.MonthlyPriceDelta / detail.CurrentMonthlyPrice
+
+	usage := fmt.Sprintf("CPU 峰值 %.1f%%", detail.PeakCPUPct)
+	if detail.PeakMemoryPct > 0 {
+		usage += fmt.Sprintf("、内存峰值 %.1f%%", detail.PeakMemoryPct)
+	}
+	reason := fmt.Sprintf("%s近 %d 天%s，建议由 %s（%s）调整为 %s（%s），目录月价降低 %.2f %s",
+		kindLabel(detail.Kind), len(stats.days), usage,
+		detail.CurrentType, specLabel(detail.CurrentCPU, detail.CurrentMemoryGB),
+		detail.TargetType, specLabel(detail.TargetCPU, detail.TargetMemoryGB),
+		detail.MonthlyPriceDelta, detail.Currency)
+
+	return s.downsizeRecommendation(tenantID, resourceID, stats, reason, saving, detail)
+}
+
+func kindLabel(kind string) string {
+	switch kind {
+	case pricing.KindRDS:
+		return "RDS 实例"
+	case pricing.KindRedis:
+		return "Redis 实例"
+	}
+	return "计算实例"
+}
+
+func specLabel(cpu int, memoryGB float64) string {
+	if cpu == 0 {
+		return fmt.Sprintf("%g GiB", memoryGB)
+	}
+	return fmt.Sprintf("%d 核 %g GiB", cpu, memoryGB)
+}
@@ -0,0 +1,280 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/pricing"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMetrics struct {
+	cpu    map[string]float64 // resourceID -> 每日平均 / 峰值 CPU
+	memory map[string]float64 // resourceID -> 每日峰值内存，未配置时返回空
+}
+
+func (m *mockMetrics) GetCPUUtilization(_ context.Context, resourceID string, days int) ([]DailyCPU, error) {
+	v, ok := m.cpu[resourceID]
+	if !ok {
+		return nil, nil
+	}
+	result := make([]DailyCPU, days)
+	for i := range result {
+		result[i] = DailyCPU{Date: time.Now().AddDate(0, 0, -i).Format("2006-01-02"), AvgCPU: v / 2, MaxCPU: v}
+	}
+	return result, nil
+}
+
+func (m *mockMetrics) GetMemoryUtilization(_ context.Context, resourceID string, days int) ([]DailyMemory, error) {
+	v, ok := m.memory[resourceID]
+	if !ok {
+		return nil, errors.New("no memory metrics")
+	}
+	result := make([]DailyMemory, days)
+	for i := range result {
+		result[i] = DailyMemory{Date: time.Now().AddDate(0, 0, -i).Format("2006-01-02"), AvgMemory: v / 2, MaxMemory: v}
+	}
+	return result, nil
+}
+
+func (m *mockMetrics) GetUnattachedDisks(_ context.Context, _ string) ([]DiskInfo, error) {
+	return nil, nil
+}
+
+type mockInventory struct {
+	specs map[string]ResourceSpec // kind/resourceID -> spec
+}
+
+func (m *mockInventory) GetResourceSpec(_ context.Context, _, _, kind, resourceID string) (ResourceSpec, bool, error) {
+	spec, ok := m.specs[kind+"/"+resourceID]
+	return spec, ok, nil
+}
+
+type mockTypeSource struct {
+	types []string
+	calls int
+}
+
+func (m *mockTypeSource) ListAvailableInstanceTypes(_ context.Context, _ int64, _ string) ([]types.InstanceTypeInfo, error) {
+	m.calls++
+	infos := make([]types.InstanceTypeInfo, 0, len(m.types))
+	for _, t := range m.types {
+		infos = append(infos, types.InstanceTypeInfo{InstanceType: t})
+	}
+	return infos, nil
+}
+
+func billsByServiceType(byType map[string][]costdomain.UnifiedBill) *mockBillDAO {
+	return &mockBillDAO{
+		listUnifiedBillsFn: func(_ context.Context, filter repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+			return byType[filter.ServiceType], nil
+		},
+	}
+}
+
+func setupRightsizingService(t *testing.T, optDAO *mockOptimizerDAO, billDAO *mockBillDAO, metrics *mockMetrics, inventory *mockInventory) *OptimizerService {
+	t.Helper()
+	catalogue, err := pricing.NewCatalogue()
+	require.NoError(t, err)
+	svc := setupTestService(t, optDAO, billDAO)
+	svc.SetMetrics(metrics)
+	svc.SetInventory(inventory)
+	svc.SetCatalogue(catalogue)
+	return svc
+}
+
+func downsizeRecs(recs []costdomain.Recommendation) map[string]costdomain.Recommendation {
+	result := make(map[string]costdomain.Recommendation)
+	for _, rec := range recs {
+		if rec.Type == RecTypeDownsize {
+			result[rec.ResourceID] = rec
+		}
+	}
+	return result
+}
+
+func TestRightsizing_ECSTargets(t *testing.T) {
+	var bills []costdomain.UnifiedBill
+	for _, id := range []string{"i-same-family", "i-no-memory", "i-unknown", "i-busy"} {
+		bills = append(bills, generateComputeBills(id, id, "aliyun", "tenant1", 100, 8, 40.0)...)
+	}
+	billDAO := billsByServiceType(map[string][]costdomain.UnifiedBill{"compute": bills})
+	metrics := &mockMetrics{
+		cpu:    map[string]float64{"i-same-family": 8, "i-no-memory": 8, "i-unknown": 8, "i-busy": 30},
+		memory: map[string]float64{"i-same-family": 20},
+	}
+	current := ResourceSpec{SpecName: "ecs.g6.2xlarge", CPU: 8, MemoryGB: 32}
+	inventory := &mockInventory{specs: map[string]ResourceSpec{
+		"ecs/i-same-family": current,
+		"ecs/i-no-memory":   current,
+		"ecs/i-busy":        current,
+	}}
+	optDAO := &mockOptimizerDAO{}
+	svc := setupRightsizingService(t, optDAO, billDAO, metrics, inventory)
+
+	require.NoError(t, svc.GenerateRecommendations(context.Background(), "tenant1"))
+	recs := downsizeRecs(optDAO.createdRecs)
+
+	// 内存峰值 20%：降到同族 2 核 8G，CPU 折算 32%、内存折算 80%
+	rec, ok := recs["i-same-family"]
+	require.True(t, ok)
+	require.NotNil(t, rec.Rightsizing)
+	assert.Equal(t, "ecs.g6.large", rec.Rightsizing.TargetType)
+	assert.True(t, rec.Rightsizing.SameFamily)
+	assert.InDelta(t, 32.0, rec.Rightsizing.ProjectedCPUPct, 1e-9)
+	assert.InDelta(t, 80.0, rec.Rightsizing.ProjectedMemoryPct, 1e-9)
+	assert.InDelta(t, 1132-283, rec.Rightsizing.MonthlyPriceDelta, 1e-9)
+	assert.Equal(t, "CNY", rec.Rightsizing.Currency)
+	assert.InDelta(t, 40*30*(1132.0-283)/1132, rec.EstimatedSaving, 1e-6)
+	assert.Contains(t, rec.Reason, "ecs.g6.large")
+
+	// 无内存监控：目标内存不得低于当前 32G，选内存型 4 核 32G
+	rec, ok = recs["i-no-memory"]
+	require.True(t, ok)
+	require.NotNil(t, rec.Rightsizing)
+	assert.Equal(t, "ecs.r6.xlarge", rec.Rightsizing.TargetType)
+	assert.False(t, rec.Rightsizing.SameFamily)
+	assert.GreaterOrEqual(t, rec.Rightsizing.TargetMemoryGB, rec.Rightsizing.CurrentMemoryGB)
+
+	// 规格未知：退化为按比例估算的通用建议
+	rec, ok = recs["i-unknown"]
+	require.True(t, ok)
+	assert.Nil(t, rec.Rightsizing)
+	assert.InDelta(t, 40*30*downsizeSavingRatio, rec.EstimatedSaving, 1e-6)
+
+	// CPU 日均未持续低于阈值
+	_, ok = recs["i-busy"]
+	assert.False(t, ok)
+}
+
+func TestRightsizing_RespectsAvailableInstanceTypes(t *testing.T) {
+	var bills []costdomain.UnifiedBill
+	for _, id := range []string{"i-1", "i-2"} {
+		bills = append(bills, generateComputeBills(id, id, "aliyun", "tenant1", 100, 8, 40.0)...)
+	}
+	billDAO := billsByServiceType(map[string][]costdomain.UnifiedBill{"compute": bills})
+	metrics := &mockMetrics{
+		cpu:    map[string]float64{"i-1": 8, "i-2": 8},
+		memory: map[string]float64{"i-1": 20, "i-2": 20},
+	}
+	current := ResourceSpec{SpecName: "ecs.g6.2xlarge", CPU: 8, MemoryGB: 32}
+	inventory := &mockInventory{specs: map[string]ResourceSpec{"ecs/i-1": current, "ecs/i-2": current}}
+	optDAO := &mockOptimizerDAO{}
+	svc := setupRightsizingService(t, optDAO, billDAO, metrics, inventory)
+	source := &mockTypeSource{types: []string{"ecs.g6.2xlarge", "ecs.g6.xlarge", "ecs.G7.LARGE"}}
+	svc.SetInstanceTypeSource(source)
+
+	require.NoError(t, svc.GenerateRecommendations(context.Background(), "tenant1"))
+	recs := downsizeRecs(optDAO.createdRecs)
+
+	for _, id := range []string{"i-1", "i-2"} {
+		rec, ok := recs[id]
+		require.True(t, ok)
+		require.NotNil(t, rec.Rightsizing)
+		assert.Equal(t, "ecs.g7.large", rec.Rightsizing.TargetType, "ecs.g6.large is not sold in the region")
+		assert.False(t, rec.Rightsizing.SameFamily)
+	}
+	assert.Equal(t, 1, source.calls, "available types should be cached per account and region")
+}
+
+func TestRightsizing_Database(t *testing.T) {
+	var bills []costdomain.UnifiedBill
+	for _, id := range []string{"rm-1", "r-1", "rm-busy"} {
+		for _, b := range generateComputeBills(id, id, "aliyun", "tenant1", 100, 8, 60.0) {
+			b.ServiceType = "database"
+			bills = append(bills, b)
+		}
+	}
+	billDAO := billsByServiceType(map[string][]costdomain.UnifiedBill{"database": bills})
+	metrics := &mockMetrics{
+		cpu:    map[string]float64{"rm-1": 10, "rm-busy": 60},
+		memory: map[string]float64{"rm-1": 20, "r-1": 15, "rm-busy": 70},
+	}
+	inventory := &mockInventory{specs: map[string]ResourceSpec{
+		"rds/rm-1":    {SpecName: "mysql.n4.xlarge.1", CPU: 8, MemoryGB: 32},
+		"rds/rm-busy": {SpecName: "mysql.n4.xlarge.1", CPU: 8, MemoryGB: 32},
+		"redis/r-1":   {SpecName: "redis.master.large.default", MemoryGB: 8},
+	}}
+	optDAO := &mockOptimizerDAO{}
+	svc := setupRightsizingService(t, optDAO, billDAO, metrics, inventory)
+
+	require.NoError(t, svc.GenerateRecommendations(context.Background(), "tenant1"))
+	recs := downsizeRecs(optDAO.createdRecs)
+
+	rec, ok := recs["rm-1"]
+	require.True(t, ok)
+	require.NotNil(t, rec.Rightsizing)
+	assert.Equal(t, pricing.KindRDS, rec.Rightsizing.Kind)
+	assert.Equal(t, "mysql.n4.medium.1", rec.Rightsizing.TargetType)
+	assert.InDelta(t, 1920-480, rec.Rightsizing.MonthlyPriceDelta, 1e-9)
+	assert.Contains(t, rec.Reason, "RDS")
+
+	rec, ok = recs["r-1"]
+	require.True(t, ok)
+	require.NotNil(t, rec.Rightsizing)
+	assert.Equal(t, pricing.KindRedis, rec.Rightsizing.Kind)
+	assert.Equal(t, "redis.master.mid.default", rec.Rightsizing.TargetType)
+	assert.InDelta(t, 60.0, rec.Rightsizing.ProjectedMemoryPct, 1e-9)
+
+	_, ok = recs["rm-busy"]
+	assert.False(t, ok)
+}
+
+func TestRightsizing_DatabaseRequiresDependencies(t *testing.T) {
+	var bills []costdomain.UnifiedBill
+	for _, b := range generateComputeBills("rm-1", "rm-1", "aliyun", "tenant1", 100, 8, 60.0) {
+		b.ServiceType = "database"
+		bills = append(bills, b)
+	}
+	optDAO := &mockOptimizerDAO{}
+	svc := setupTestService(t, optDAO, billsByServiceType(map[string][]costdomain.UnifiedBill{"database": bills}))
+
+	recs, err := svc.detectDatabaseRightsizing(context.Background(), "tenant1")
+	require.NoError(t, err)
+	assert.Empty(t, recs)
+}
+
+func TestSpecFromAttributes(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  string
+		attrs map[string]interface{}
+		want  ResourceSpec
+	}{
+		{
+			name:  "ecs",
+			kind:  pricing.KindECS,
+			attrs: map[string]interface{}{"instance_type": "ecs.g6.xlarge", "cpu": int32(4), "memory": int64(16384)},
+			want:  ResourceSpec{SpecName: "ecs.g6.xlarge", CPU: 4, MemoryGB: 16},
+		},
+		{
+			name:  "rds from task sync",
+			kind:  pricing.KindRDS,
+			attrs: map[string]interface{}{"db_instance_class": "mysql.n2.medium.1", "cpu": 2, "memory": 4096.0},
+			want:  ResourceSpec{SpecName: "mysql.n2.medium.1", CPU: 2, MemoryGB: 4},
+		},
+		{
+			name:  "rds from asset sync",
+			kind:  pricing.KindRDS,
+			attrs: map[string]interface{}{"instance_class": "db.m5.large", "cpu": 2, "memory": 8192},
+			want:  ResourceSpec{SpecName: "db.m5.large", CPU: 2, MemoryGB: 8},
+		},
+		{
+			name:  "redis",
+			kind:  pricing.KindRedis,
+			attrs: map[string]interface{}{"instance_class": "redis.master.mid.default", "capacity": int64(2048), "architecture": "standard"},
+			want:  ResourceSpec{SpecName: "redis.master.mid.default", MemoryGB: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SpecFromAttributes(tt.kind, tt.attrs))
+		})
+	}
+}
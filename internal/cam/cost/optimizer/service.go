@@ -4,9 +4,11 @@ package optimizer
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/pricing"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/gotomicro/ego/core/elog"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -41,12 +43,22 @@ const (
 	lowCPUConsecutiveDays = 7
 	// onDemandRunningDays 按量付费运行天数阈值
 	onDemandRunningDays = 30
+	// commitmentHeadroomPct 已有承诺消费利用率低于该值时视为仍有余量，不再建议新购
+	commitmentHeadroomPct = 80.0
 )
 
+// CommitmentProvider 已购承诺消费查询接口
+type CommitmentProvider interface {
+	// ListActiveCommitments 获取租户当前有效的预留实例 / 节省计划 / 资源包
+	ListActiveCommitments(ctx context.Context, tenantID string) ([]domain.Commitment, error)
+}
+
 // ResourceMetrics 资源利用率数据接口
 type ResourceMetrics interface {
 	// GetCPUUtilization 获取指定资源过去 N 天的每日平均 CPU 利用率
 	GetCPUUtilization(ctx context.Context, resourceID string, days int) ([]DailyCPU, error)
+	// GetMemoryUtilization 获取指定资源过去 N 天的每日内存利用率
+	GetMemoryUtilization(ctx context.Context, resourceID string, days int) ([]DailyMemory, error)
 	// GetUnattachedDisks 获取未挂载的云盘列表
 	GetUnattachedDisks(ctx context.Context, tenantID string) ([]DiskInfo, error)
 }
@@ -55,6 +67,14 @@ type ResourceMetrics interface {
 type DailyCPU struct {
 	Date   string  // YYYY-MM-DD
 	AvgCPU float64 // 平均 CPU 利用率百分比
+	MaxCPU float64 // 峰值 CPU 利用率百分比，为 0 时按平均值计
+}
+
+// DailyMemory 每日内存利用率
+type DailyMemory struct {
+	Date      string  // YYYY-MM-DD
+	AvgMemory float64 // 平均内存利用率百分比
+	MaxMemory float64 // 峰值内存利用率百分比，为 0 时按平均值计
 }
 
 // DiskInfo 云盘信息
@@ -71,7 +91,15 @@ type DiskInfo struct {
 type OptimizerService struct {
 	optimizerDAO repository.OptimizerDAO
 	billDAO      repository.BillDAO
+	commitments  CommitmentProvider
+	metrics      ResourceMetrics
+	inventory    ResourceInventory
+	catalogue    *pricing.Catalogue
+	typeSource   InstanceTypeSource
+	assets       AssetInventory
 	logger       *elog.Component
+
+	snapshotRetentionDays int
 }
 
 // NewOptimizerService 创建优化建议服务
@@ -84,9 +112,36 @@ func NewOptimizerService(
 		optimizerDAO: optimizerDAO,
 		billDAO:      billDAO,
 		logger:       logger,
+
+		snapshotRetentionDays: defaultSnapshotRetentionDays,
 	}
 }
 
+// SetCommitmentProvider 设置承诺消费查询（可选注入，未设置时转包年包月建议不考虑已购承诺）
+func (s *OptimizerService) SetCommitmentProvider(provider CommitmentProvider) {
+	s.commitments = provider
+}
+
+// SetMetrics 设置资源监控数据（可选注入，未设置时降配建议退化为账单启发式）
+func (s *OptimizerService) SetMetrics(metrics ResourceMetrics) {
+	s.metrics = metrics
+}
+
+// SetInventory 设置资源规格查询（可选注入，与价格目录同时设置后降配建议给出目标规格）
+func (s *OptimizerService) SetInventory(inventory ResourceInventory) {
+	s.inventory = inventory
+}
+
+// SetCatalogue 设置规格价格目录
+func (s *OptimizerService) SetCatalogue(catalogue *pricing.Catalogue) {
+	s.catalogue = catalogue
+}
+
+// SetInstanceTypeSource 设置可用实例规格查询（可选注入，设置后 ECS 目标规格限定为地域内可售规格）
+func (s *OptimizerService) SetInstanceTypeSource(source InstanceTypeSource) {
+	s.typeSource = source
+}
+
 // GenerateRecommendations 每日生成优化建议
 func (s *OptimizerService) GenerateRecommendations(ctx context.Context, tenantID string) error {
 	var recs []domain.Recommendation
@@ -111,7 +166,17 @@ func (s *OptimizerService) GenerateRecommendations(ctx context.Context, tenantID
 		recs = append(recs, diskRecs...)
 	}
 
-	// 3. 检测按量转包年包月候选
+	// 3. 检测 RDS / Redis 降配候选
+	dbRecs, err := s.detectDatabaseRightsizing(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("detect database rightsizing failed",
+			elog.String("tenant_id", tenantID),
+			elog.FieldErr(err))
+	} else {
+		recs = append(recs, dbRecs...)
+	}
+
+	// 4. 检测按量转包年包月候选
 	convertRecs, err := s.detectOnDemandConvert(ctx, tenantID)
 	if err != nil {
 		s.logger.Error("detect on-demand convert candidates failed",
@@ -121,6 +186,16 @@ func (s *OptimizerService) GenerateRecommendations(ctx context.Context, tenantID
 		recs = append(recs, convertRecs...)
 	}
 
+	// 5. 检测闲置与孤立资源（基于资产同步数据）
+	idleRecs, err := s.detectIdleResources(ctx, tenantID)
+	if err != nil {
+		s.logger.Error("detect idle resources failed",
+			elog.String("tenant_id", tenantID),
+			elog.FieldErr(err))
+	} else {
+		recs = append(recs, idleRecs...)
+	}
+
 	if len(recs) == 0 {
 		return nil
 	}
@@ -140,7 +215,8 @@ func (s *OptimizerService) GenerateRecommendations(ctx context.Context, tenantID
 }
 
 // detectLowCPUInstances 检测低 CPU 利用率实例
-// 使用账单数据启发式方法：连续 7+ 天有计算类型账单且金额较低的资源
+// 未注入监控数据时使用账单数据启发式方法：连续 7+ 天有计算类型账单的资源；
+// 注入监控数据后按每日平均 CPU 判定，并在能确定当前规格时给出目标规格与价差
 func (s *OptimizerService) detectLowCPUInstances(ctx context.Context, tenantID string) ([]domain.Recommendation, error) {
 	now := time.Now()
 	endDate := now.Format("2006-01-02")
@@ -157,20 +233,58 @@ func (s *OptimizerService) detectLowCPUInstances(ctx context.Context, tenantID s
 		return nil, fmt.Errorf("list compute bills: %w", err)
 	}
 
-	// 按资源 ID 聚合，统计出现天数和总金额
-	type resourceStats struct {
-		days         map[string]bool
-		totalAmount  float64
-		resourceName string
-		provider     string
-		accountID    int64
-		region       string
+	run := newRightsizingRun(tenantID)
+	var recs []domain.Recommendation
+	for resourceID, stats := range aggregateBills(bills) {
+		if len(stats.days) < lowCPUConsecutiveDays {
+			continue
+		}
+		// 计算日均成本
+		dailyAvg := stats.totalAmount / float64(len(stats.days))
+
+		if s.metrics == nil {
+			recs = append(recs, s.downsizeRecommendation(tenantID, resourceID, stats,
+				fmt.Sprintf("计算实例连续 %d 天运行，日均成本 %.2f 元，建议降配以节省成本", len(stats.days), dailyAvg),
+				dailyAvg*30*downsizeSavingRatio, nil))
+			continue
+		}
+
+		usage, err := s.loadUsage(ctx, resourceID)
+		if err != nil {
+			s.logger.Warn("load resource metrics failed",
+				elog.String("resource_id", resourceID),
+				elog.FieldErr(err))
+			continue
+		}
+		if !usage.lowCPU() {
+			continue
+		}
+
+		if rec, ok := s.rightsizeCompute(ctx, run, resourceID, stats, usage); ok {
+			recs = append(recs, rec)
+		}
 	}
-	resourceMap := make(map[string]*resourceStats)
+
+	return recs, nil
+}
+
+// billStats 单个资源在统计窗口内的账单汇总
+type billStats struct {
+	days         map[string]bool
+	totalAmount  float64
+	resourceName string
+	provider     string
+	accountID    int64
+	region       string
+}
+
+// aggregateBills 按资源 ID 聚合账单，统计出现天数和总金额
+func aggregateBills(bills []domain.UnifiedBill) map[string]*billStats {
+	resourceMap := make(map[string]*billStats)
 	for _, bill := range bills {
 		stats, ok := resourceMap[bill.ResourceID]
 		if !ok {
-			stats = &resourceStats{
+			stats = &billStats{
 				days:         make(map[string]bool),
 				resourceName: bill.ResourceName,
 				provider:     bill.Provider,
@@ -182,31 +296,24 @@ func (s *OptimizerService) detectLowCPUInstances(ctx context.Context, tenantID s
 		stats.days[bill.BillingDate] = true
 		stats.totalAmount += bill.AmountCNY
 	}
+	return resourceMap
+}
 
-	var recs []domain.Recommendation
-	for resourceID, stats := range resourceMap {
-		if len(stats.days) < lowCPUConsecutiveDays {
-			continue
-		}
-		// 计算日均成本
-		dailyAvg := stats.totalAmount / float64(len(stats.days))
-		estimatedSaving := dailyAvg * 30 * downsizeSavingRatio
-
-		recs = append(recs, domain.Recommendation{
-			Type:            RecTypeDownsize,
-			Provider:        stats.provider,
-			AccountID:       stats.accountID,
-			ResourceID:      resourceID,
-			ResourceName:    stats.resourceName,
-			Region:          stats.region,
-			Reason:          fmt.Sprintf("计算实例连续 %d 天运行，日均成本 %.2f 元，建议降配以节省成本", len(stats.days), dailyAvg),
-			EstimatedSaving: estimatedSaving,
-			Status:          StatusPending,
-			TenantID:        tenantID,
-		})
+// downsizeRecommendation 构造降配建议
+func (s *OptimizerService) downsizeRecommendation(tenantID, resourceID string, stats *billStats, reason string, saving float64, detail *domain.RightsizingDetail) domain.Recommendation {
+	return domain.Recommendation{
+		Type:            RecTypeDownsize,
+		Provider:        stats.provider,
+		AccountID:       stats.accountID,
+		ResourceID:      resourceID,
+		ResourceName:    stats.resourceName,
+		Region:          stats.region,
+		Reason:          reason,
+		EstimatedSaving: saving,
+		Rightsizing:     detail,
+		Status:          StatusPending,
+		TenantID:        tenantID,
 	}
-
-	return recs, nil
 }
 
 // detectUnattachedDisks 检测未挂载云盘
@@ -318,6 +425,7 @@ func (s *OptimizerService) detectOnDemandConvert(ctx context.Context, tenantID s
 		provider     string
 		accountID    int64
 		region       string
+		serviceType  string
 		chargeType   string
 	}
 	resourceMap := make(map[string]*resourceStats)
@@ -333,6 +441,7 @@ func (s *OptimizerService) detectOnDemandConvert(ctx context.Context, tenantID s
 				provider:     bill.Provider,
 				accountID:    bill.AccountID,
 				region:       bill.Region,
+				serviceType:  bill.ServiceType,
 				chargeType:   bill.ChargeType,
 			}
 			resourceMap[bill.ResourceID] = stats
@@ -341,6 +450,19 @@ func (s *OptimizerService) detectOnDemandConvert(ctx context.Context, tenantID s
 		stats.totalAmount += bill.AmountCNY
 	}
 
+	commitments, err := s.activeCommitments(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	// 存在限定实例族的承诺时才需要查询资源规格
+	familyScoped := false
+	for _, c := range commitments {
+		if c.InstanceFamily != "" {
+			familyScoped = true
+			break
+		}
+	}
+
 	var recs []domain.Recommendation
 	for resourceID, stats := range resourceMap {
 		if len(stats.days) < onDemandRunningDays {
@@ -349,6 +471,22 @@ func (s *OptimizerService) detectOnDemandConvert(ctx context.Context, tenantID s
 		dailyAvg := stats.totalAmount / float64(len(stats.days))
 		estimatedSaving := dailyAvg * 30 * convertPrepaidSavingRatio
 
+		reason := fmt.Sprintf("按量付费实例已运行 %d 天，日均成本 %.2f 元，建议转为包年包月", len(stats.days), dailyAvg)
+		family := ""
+		if familyScoped {
+			family = s.resourceFamily(ctx, tenantID, stats.provider, stats.serviceType, resourceID)
+		}
+		matched := matchCommitments(commitments, stats.provider, stats.accountID, stats.region, stats.serviceType, family)
+		if len(matched) > 0 {
+			// 任一已有承诺消费仍有余量时，按量用量可由其覆盖，不建议新购
+			if hasHeadroom(matched) {
+				continue
+			}
+			utilization := weightedUtilization(matched)
+			reason = fmt.Sprintf("按量付费实例已运行 %d 天，日均成本 %.2f 元；同账号同地域已有 %d 个承诺消费且利用率已达 %.0f%%，覆盖不足，建议转为包年包月或追加购买",
+				len(stats.days), dailyAvg, len(matched), utilization)
+		}
+
 		recs = append(recs, domain.Recommendation{
 			Type:            RecTypeConvertPrepaid,
 			Provider:        stats.provider,
@@ -356,7 +494,7 @@ func (s *OptimizerService) detectOnDemandConvert(ctx context.Context, tenantID s
 			ResourceID:      resourceID,
 			ResourceName:    stats.resourceName,
 			Region:          stats.region,
-			Reason:          fmt.Sprintf("按量付费实例已运行 %d 天，日均成本 %.2f 元，建议转为包年包月", len(stats.days), dailyAvg),
+			Reason:          reason,
 			EstimatedSaving: estimatedSaving,
 			Status:          StatusPending,
 			TenantID:        tenantID,
@@ -366,6 +504,101 @@ func (s *OptimizerService) detectOnDemandConvert(ctx context.Context, tenantID s
 	return recs, nil
 }
 
+// activeCommitments 获取租户有效的计算类承诺消费（资源包不覆盖实例用量，不参与判断）
+func (s *OptimizerService) activeCommitments(ctx context.Context, tenantID string) ([]domain.Commitment, error) {
+	if s.commitments == nil {
+		return nil, nil
+	}
+	all, err := s.commitments.ListActiveCommitments(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list active commitments: %w", err)
+	}
+	var commitments []domain.Commitment
+	for _, c := range all {
+		if c.Type == domain.CommitmentTypeResourcePackage {
+			continue
+		}
+		commitments = append(commitments, c)
+	}
+	return commitments, nil
+}
+
+// matchCommitments 筛选可覆盖指定资源的承诺消费：同云厂商、同账号，地域、服务类别、实例族相同或不限
+// 资源实例族未知时，限定实例族的承诺无法确认覆盖关系，不参与匹配
+func matchCommitments(commitments []domain.Commitment, provider string, accountID int64, region, serviceType, family string) []domain.Commitment {
+	var matched []domain.Commitment
+	for _, c := range commitments {
+		if c.Provider != provider || c.AccountID != accountID {
+			continue
+		}
+		if c.Region != "" && c.Region != region {
+			continue
+		}
+		if c.ServiceType != "" && c.ServiceType != serviceType {
+			continue
+		}
+		if c.InstanceFamily != "" && !strings.EqualFold(c.InstanceFamily, family) {
+			continue
+		}
+		matched = append(matched, c)
+	}
+	return matched
+}
+
+// resourceFamily 通过资源清单查询按量资源的实例族，查询失败或无规格时返回空
+func (s *OptimizerService) resourceFamily(ctx context.Context, tenantID, provider, serviceType, resourceID string) string {
+	if s.inventory == nil {
+		return ""
+	}
+	kind := pricing.KindECS
+	if serviceType == domain.ServiceTypeDatabase {
+		kind = pricing.KindRDS
+	}
+	spec, ok, err := s.inventory.GetResourceSpec(ctx, tenantID, provider, kind, resourceID)
+	if err != nil {
+		s.logger.Warn("get resource spec for commitment matching failed",
+			elog.String("resource_id", resourceID),
+			elog.FieldErr(err))
+		return ""
+	}
+	if !ok {
+		return ""
+	}
+	return specFamily(spec.SpecName)
+}
+
+// specFamily 从规格名解析实例族，去掉最后一段尺寸（ecs.g6.large → ecs.g6，m5.large → m5）
+func specFamily(spec string) string {
+	if i := strings.LastIndex(spec, "."); i > 0 {
+		return spec[:i]
+	}
+	return ""
+}
+
+// hasHeadroom 是否存在利用率低于阈值的承诺消费
+func hasHeadroom(commitments []domain.Commitment) bool {
+	for _, c := range commitments {
+		if c.UtilizationPct < commitmentHeadroomPct {
+			return true
+		}
+	}
+	return false
+}
+
+// weightedUtilization 按摊销费用加权的利用率，费用均为 0 时取算术平均
+func weightedUtilization(commitments []domain.Commitment) float64 {
+	var cost, used, sum float64
+	for _, c := range commitments {
+		cost += c.PeriodCost
+		used += c.PeriodCost * c.UtilizationPct / 100
+		sum += c.UtilizationPct
+	}
+	if cost > 0 {
+		return used / cost * 100
+	}
+	return sum / float64(len(commitments))
+}
+
 // filterDismissed 过滤已忽略且未过期的建议
 func (s *OptimizerService) filterDismissed(ctx context.Context, tenantID string, recs []domain.Recommendation) []domain.Recommendation {
 	now := time.Now()
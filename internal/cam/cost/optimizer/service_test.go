@@ -131,6 +131,12 @@ func (m *mockBillDAO) DeleteUnifiedBillsByPeriod(_ context.Context, _, _ string)
 func (m *mockBillDAO) DeleteRawBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
+func (m *mockBillDAO) DeleteRawBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
+func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndMonth(_ context.Context, _ int64, _ string) (int64, error) {
+	return 0, nil
+}
 func (m *mockBillDAO) DeleteUnifiedBillsByAccountAndRange(_ context.Context, _ int64, _, _ string) (int64, error) {
 	return 0, nil
 }
@@ -138,6 +144,18 @@ func (m *mockBillDAO) AggregateByTag(_ context.Context, _ string, _, _ string) (
 	return nil, nil
 }
 
+func (m *mockBillDAO) UpdateUnifiedBillAmountCNY(_ context.Context, _ []repository.AmountCNYUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *mockBillDAO) AggregateByFieldDaily(_ context.Context, _ string, _ string, _, _ string, _ repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
+func (m *mockBillDAO) AggregateBreakdownDaily(_ context.Context, _, _, _, _, _, _ string) ([]repository.FieldDailyAmount, error) {
+	return nil, nil
+}
+
 // ========== Test Setup ==========
 
 func setupTestService(t *testing.T, optDAO *mockOptimizerDAO, billDAO *mockBillDAO) *OptimizerService {
@@ -437,3 +455,119 @@ func TestDismissRecommendation_AlreadyDismissed(t *testing.T) {
 	newExpiry := time.Now().Add(dismissDuration)
 	assert.WithinDuration(t, newExpiry, *updated.DismissExpiry, 5*time.Second)
 }
+
+// ========== Commitment-aware Convert Tests ==========
+
+type mockCommitmentProvider struct {
+	commitments []costdomain.Commitment
+}
+
+func (m *mockCommitmentProvider) ListActiveCommitments(_ context.Context, _ string) ([]costdomain.Commitment, error) {
+	return m.commitments, nil
+}
+
+// postpaidBills 生成连续 days 天的按量付费账单
+func postpaidBills(resourceID, region string, accountID int64, days int) []costdomain.UnifiedBill {
+	now := time.Now()
+	var bills []costdomain.UnifiedBill
+	for i := 0; i < days; i++ {
+		bills = append(bills, costdomain.UnifiedBill{
+			Provider: "aws", AccountID: accountID,
+			ResourceID: resourceID, ServiceType: "compute", Region: region,
+			AmountCNY: 100.0, ChargeType: "postpaid", TenantID: "tenant1",
+			BillingDate: now.AddDate(0, 0, -days+i).Format("2006-01-02"),
+		})
+	}
+	return bills
+}
+
+func TestDetectOnDemandConvert_RespectsExistingCommitments(t *testing.T) {
+	var bills []costdomain.UnifiedBill
+	bills = append(bills, postpaidBills("i-covered", "us-east-1", 200, 31)...)
+	bills = append(bills, postpaidBills("i-saturated", "ap-southeast-1", 200, 31)...)
+	bills = append(bills, postpaidBills("i-other-account", "us-east-1", 300, 31)...)
+
+	billDAO := &mockBillDAO{
+		listUnifiedBillsFn: func(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+			return bills, nil
+		},
+	}
+	svc := setupTestService(t, &mockOptimizerDAO{}, billDAO)
+	svc.SetCommitmentProvider(&mockCommitmentProvider{commitments: []costdomain.Commitment{
+		// us-east-1 的预留实例利用率 50%，仍有余量
+		{Provider: "aws", AccountID: 200, Type: costdomain.CommitmentTypeReservedInstance, Region: "us-east-1", UtilizationPct: 50, PeriodCost: 100},
+		// 不限地域的节省计划已用满
+		{Provider: "aws", AccountID: 200, Type: costdomain.CommitmentTypeSavingsPlan, UtilizationPct: 100, PeriodCost: 300},
+		// 资源包不覆盖实例用量
+		{Provider: "aws", AccountID: 300, Type: costdomain.CommitmentTypeResourcePackage, UtilizationPct: 10},
+	}})
+
+	recs, err := svc.detectOnDemandConvert(context.Background(), "tenant1")
+	require.NoError(t, err)
+
+	byResource := make(map[string]costdomain.Recommendation)
+	for _, rec := range recs {
+		byResource[rec.ResourceID] = rec
+	}
+	assert.NotContains(t, byResource, "i-covered", "同地域预留实例利用率仅 50%，不应建议新购")
+	require.Contains(t, byResource, "i-saturated")
+	assert.Contains(t, byResource["i-saturated"].Reason, "利用率已达 100%")
+	require.Contains(t, byResource, "i-other-account")
+	assert.NotContains(t, byResource["i-other-account"].Reason, "承诺消费")
+}
+
+func TestDetectOnDemandConvert_MatchesServiceAndFamily(t *testing.T) {
+	var bills []costdomain.UnifiedBill
+	bills = append(bills, postpaidBills("i-m5", "us-east-1", 200, 31)...)
+	bills = append(bills, postpaidBills("i-c5", "us-east-1", 200, 31)...)
+	bills = append(bills, postpaidBills("i-unknown", "us-east-1", 200, 31)...)
+	for _, bill := range postpaidBills("db-1", "us-east-1", 200, 31) {
+		bill.ServiceType = costdomain.ServiceTypeDatabase
+		bills = append(bills, bill)
+	}
+
+	billDAO := &mockBillDAO{
+		listUnifiedBillsFn: func(_ context.Context, _ repository.UnifiedBillFilter) ([]costdomain.UnifiedBill, error) {
+			return bills, nil
+		},
+	}
+	svc := setupTestService(t, &mockOptimizerDAO{}, billDAO)
+	svc.SetInventory(&mockInventory{specs: map[string]ResourceSpec{
+		"ecs/i-m5": {SpecName: "m5.large"},
+		"ecs/i-c5": {SpecName: "c5.xlarge"},
+	}})
+	svc.SetCommitmentProvider(&mockCommitmentProvider{commitments: []costdomain.Commitment{
+		// m5 计算预留实例仍有余量
+		{Provider: "aws", AccountID: 200, Type: costdomain.CommitmentTypeReservedInstance, Region: "us-east-1",
+			ServiceType: costdomain.ServiceTypeCompute, InstanceFamily: "m5", UtilizationPct: 50, PeriodCost: 100},
+	}})
+
+	recs, err := svc.detectOnDemandConvert(context.Background(), "tenant1")
+	require.NoError(t, err)
+
+	byResource := make(map[string]costdomain.Recommendation)
+	for _, rec := range recs {
+		byResource[rec.ResourceID] = rec
+	}
+	assert.NotContains(t, byResource, "i-m5", "同实例族预留实例仍有余量，不应建议新购")
+	assert.Contains(t, byResource, "i-c5", "其他实例族不被 m5 预留实例覆盖")
+	assert.Contains(t, byResource, "i-unknown", "实例族未知时限定实例族的承诺不参与匹配")
+	assert.Contains(t, byResource, "db-1", "计算类承诺不覆盖数据库用量")
+}
+
+func TestSpecFamily(t *testing.T) {
+	assert.Equal(t, "m5", specFamily("m5.large"))
+	assert.Equal(t, "ecs.g6", specFamily("ecs.g6.large"))
+	assert.Empty(t, specFamily("m5"))
+}
+
+func TestWeightedUtilization(t *testing.T) {
+	assert.InDelta(t, 62.5, weightedUtilization([]costdomain.Commitment{
+		{UtilizationPct: 50, PeriodCost: 300},
+		{UtilizationPct: 100, PeriodCost: 100},
+	}), 0.001)
+	assert.InDelta(t, 75.0, weightedUtilization([]costdomain.Commitment{
+		{UtilizationPct: 50},
+		{UtilizationPct: 100},
+	}), 0.001)
+}
@@ -0,0 +1,115 @@
+// Package pricing 本地规格价格目录
+// 云厂商询价接口各不相同且调用成本高，优化建议计算规格价差时使用内置的参考月价，
+// 价格为按量付费折算的月度刊例价（730 小时），仅用于比较规格间的相对价差
+package pricing
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 资源种类（与 CMDB 模型 UID 后缀一致，如 aliyun_ecs / aws_rds）
+const (
+	KindECS   = "ecs"
+	KindRDS   = "rds"
+	KindRedis = "redis"
+)
+
+//go:embed catalogue.json
+var builtinCatalogue []byte
+
+// Spec 规格价格条目
+type Spec struct {
+	Provider     string             `json:"provider"`
+	Kind         string             `json:"kind"`
+	Name         string             `json:"name"`   // 规格名称，如 ecs.g6.large / db.m5.large
+	Family       string             `json:"family"` // 规格族，如 ecs.g6 / db.m5
+	CPU          int                `json:"cpu"`    // vCPU 核数，Redis 等按内存计费的规格为 0
+	MemoryGB     float64            `json:"memory_gb"`
+	Architecture string             `json:"architecture"`
+	MonthlyPrice float64            `json:"monthly_price"` // 默认地域月价
+	Currency     string             `json:"currency"`
+	RegionPrices map[string]float64 `json:"region_prices"` // 地域差异价，未配置的地域使用默认月价
+}
+
+// PriceIn 获取指定地域的月价
+func (s Spec) PriceIn(region string) float64 {
+	if price, ok := s.RegionPrices[region]; ok {
+		return price
+	}
+	return s.MonthlyPrice
+}
+
+// Catalogue 规格价格目录
+type Catalogue struct {
+	Version string
+	specs   map[string][]Spec // provider/kind -> 规格列表（按默认月价升序）
+	index   map[string]Spec   // provider/kind/name -> 规格
+}
+
+type catalogueFile struct {
+	Version string `json:"version"`
+	Specs   []Spec `json:"specs"`
+}
+
+// NewCatalogue 加载内置价格目录
+func NewCatalogue() (*Catalogue, error) {
+	return LoadCatalogue(builtinCatalogue)
+}
+
+// LoadCatalogue 从 JSON 数据加载价格目录
+func LoadCatalogue(data []byte) (*Catalogue, error) {
+	var file catalogueFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse price catalogue: %w", err)
+	}
+
+	c := &Catalogue{
+		Version: file.Version,
+		specs:   make(map[string][]Spec),
+		index:   make(map[string]Spec),
+	}
+	for _, spec := range file.Specs {
+		if spec.Provider == "" || spec.Kind == "" || spec.Name == "" {
+			return nil, fmt.Errorf("price catalogue entry missing provider/kind/name: %+v", spec)
+		}
+		if spec.MonthlyPrice <= 0 {
+			return nil, fmt.Errorf("price catalogue entry %s has no monthly price", spec.Name)
+		}
+		key := indexKey(spec.Provider, spec.Kind, spec.Name)
+		if _, exists := c.index[key]; exists {
+			return nil, fmt.Errorf("duplicate price catalogue entry %s", key)
+		}
+		c.index[key] = spec
+		group := groupKey(spec.Provider, spec.Kind)
+		c.specs[group] = append(c.specs[group], spec)
+	}
+	for _, specs := range c.specs {
+		sort.SliceStable(specs, func(i, j int) bool {
+			return specs[i].MonthlyPrice < specs[j].MonthlyPrice
+		})
+	}
+	return c, nil
+}
+
+// Lookup 查询规格价格，规格名称不区分大小写
+func (c *Catalogue) Lookup(provider, kind, name string) (Spec, bool) {
+	spec, ok := c.index[indexKey(provider, kind, name)]
+	return spec, ok
+}
+
+// List 列出云厂商某类资源的全部规格（按默认月价升序）
+func (c *Catalogue) List(provider, kind string) []Spec {
+	return c.specs[groupKey(provider, kind)]
+}
+
+func groupKey(provider, kind string) string {
+	return strings.ToLower(provider) + "/" + strings.ToLower(kind)
+}
+
+func indexKey(provider, kind, name string) string {
+	return groupKey(provider, kind) + "/" + strings.ToLower(name)
+}
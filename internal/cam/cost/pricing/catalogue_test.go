@@ -0,0 +1,67 @@
+package pricing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCatalogue_Builtin(t *testing.T) {
+	c, err := NewCatalogue()
+	require.NoError(t, err)
+	assert.NotEmpty(t, c.Version)
+
+	for _, group := range []struct{ provider, kind string }{
+		{"aliyun", KindECS}, {"aws", KindECS},
+		{"aliyun", KindRDS}, {"aws", KindRDS},
+		{"aliyun", KindRedis}, {"aws", KindRedis},
+	} {
+		specs := c.List(group.provider, group.kind)
+		require.NotEmpty(t, specs, "%s/%s", group.provider, group.kind)
+		for i := 1; i < len(specs); i++ {
+			assert.LessOrEqual(t, specs[i-1].MonthlyPrice, specs[i].MonthlyPrice)
+		}
+	}
+}
+
+func TestCatalogue_Lookup(t *testing.T) {
+	c, err := NewCatalogue()
+	require.NoError(t, err)
+
+	spec, ok := c.Lookup("aliyun", KindECS, "ECS.G6.XLARGE")
+	require.True(t, ok)
+	assert.Equal(t, "ecs.g6", spec.Family)
+	assert.Equal(t, 4, spec.CPU)
+	assert.Equal(t, 16.0, spec.MemoryGB)
+	assert.Equal(t, "CNY", spec.Currency)
+
+	_, ok = c.Lookup("aliyun", KindRDS, "ecs.g6.xlarge")
+	assert.False(t, ok, "kind should be part of the key")
+	_, ok = c.Lookup("aws", KindECS, "unknown.large")
+	assert.False(t, ok)
+}
+
+func TestSpec_PriceIn(t *testing.T) {
+	spec := Spec{MonthlyPrice: 100, RegionPrices: map[string]float64{"cn-hongkong": 125}}
+	assert.Equal(t, 125.0, spec.PriceIn("cn-hongkong"))
+	assert.Equal(t, 100.0, spec.PriceIn("cn-beijing"))
+}
+
+func TestLoadCatalogue_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed", `{`},
+		{"missing name", `{"specs":[{"provider":"aws","kind":"ecs","monthly_price":1}]}`},
+		{"zero price", `{"specs":[{"provider":"aws","kind":"ecs","name":"m5.large"}]}`},
+		{"duplicate", `{"specs":[{"provider":"aws","kind":"ecs","name":"m5.large","monthly_price":1},{"provider":"aws","kind":"ecs","name":"M5.LARGE","monthly_price":2}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCatalogue([]byte(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,360 @@
+// Package reconcile 采集明细与云厂商账单总览对账
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// defaultTolerancePct 默认相对容差（%）
+	defaultTolerancePct = 1.0
+	// defaultToleranceAbs 默认绝对容差（账单币种），避免小额账单的百分比差异误报
+	defaultToleranceAbs = 1.0
+	// criticalDriftPct 差异达到该比例时告警级别为 critical
+	criticalDriftPct = 10.0
+)
+
+// AccountProvider 云账号查询接口
+type AccountProvider interface {
+	GetAccountWithCredentials(ctx context.Context, id int64) (*shareddomain.CloudAccount, error)
+	ListAccounts(ctx context.Context, filter shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error)
+}
+
+// Recollector 按账期重新采集账单（整月替换已有数据）
+type Recollector interface {
+	RecollectMonth(ctx context.Context, accountID int64, month string) error
+}
+
+// ReconcileService 账单对账服务
+// 按云账号 + 账期比较统一账单明细合计与厂商账单总览，差异超出容差时告警
+type ReconcileService struct {
+	billDAO      repository.BillDAO
+	reconcileDAO repository.ReconcileDAO
+	accountSvc   AccountProvider
+	collector    Recollector
+	alertSvc     *alertservice.AlertService
+	logger       *elog.Component
+	adapterFor   func(account *shareddomain.CloudAccount) (billing.BillingAdapter, error)
+
+	tolerancePct float64
+	toleranceAbs float64
+}
+
+// NewReconcileService 创建账单对账服务
+func NewReconcileService(
+	billDAO repository.BillDAO,
+	reconcileDAO repository.ReconcileDAO,
+	accountSvc AccountProvider,
+	collector Recollector,
+	alertSvc *alertservice.AlertService,
+	logger *elog.Component,
+) *ReconcileService {
+	return &ReconcileService{
+		billDAO:      billDAO,
+		reconcileDAO: reconcileDAO,
+		accountSvc:   accountSvc,
+		collector:    collector,
+		alertSvc:     alertSvc,
+		logger:       logger,
+		adapterFor:   newBillingAdapter,
+		tolerancePct: defaultTolerancePct,
+		toleranceAbs: defaultToleranceAbs,
+	}
+}
+
+// newBillingAdapter 通过计费适配器注册表创建云账号的适配器
+func newBillingAdapter(account *shareddomain.CloudAccount) (billing.BillingAdapter, error) {
+	creator, err := billing.GetBillingAdapter(account.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return creator(account)
+}
+
+// SetTolerance 设置对账容差：差异同时超过相对容差（%）与绝对容差时判定为差异，非正值保持默认
+func (s *ReconcileService) SetTolerance(pct, abs float64) {
+	if pct > 0 {
+		s.tolerancePct = pct
+	}
+	if abs > 0 {
+		s.toleranceAbs = abs
+	}
+}
+
+// ReconcileAll 对账最近 months 个已结束的自然月，tenantID 为空时对账全部租户
+func (s *ReconcileService) ReconcileAll(ctx context.Context, tenantID string, months int, now time.Time) error {
+	if months <= 0 {
+		months = 1
+	}
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := months; i >= 1; i-- {
+		if err := s.ReconcileMonth(ctx, tenantID, current.AddDate(0, -i, 0).Format("2006-01")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReconcileMonth 对账租户全部活跃云账号的指定账期，单个账号失败不影响其他账号
+func (s *ReconcileService) ReconcileMonth(ctx context.Context, tenantID, month string) error {
+	if _, err := parseMonth(month); err != nil {
+		return err
+	}
+	accounts, _, err := s.accountSvc.ListAccounts(ctx, shareddomain.CloudAccountFilter{
+		Status:   shareddomain.CloudAccountStatusActive,
+		TenantID: tenantID,
+		Limit:    1000,
+	})
+	if err != nil {
+		return fmt.Errorf("list active accounts: %w", err)
+	}
+
+	for _, acct := range accounts {
+		if _, err := s.ReconcileAccount(ctx, "", acct.ID, month); err != nil {
+			s.logger.Error("reconcile bills failed for account",
+				elog.Int64("account_id", acct.ID),
+				elog.String("billing_month", month),
+				elog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+// ReconcileAccount 对账单个云账号的指定账期（YYYY-MM），tenantID 非空时校验账号归属
+// 账单总览拉取失败时同样记录对账结果（状态 failed）并返回错误
+func (s *ReconcileService) ReconcileAccount(ctx context.Context, tenantID string, accountID int64, month string) (domain.BillReconciliation, error) {
+	start, err := parseMonth(month)
+	if err != nil {
+		return domain.BillReconciliation{}, err
+	}
+	account, err := s.getAccount(ctx, tenantID, accountID)
+	if err != nil {
+		return domain.BillReconciliation{}, err
+	}
+
+	rec := domain.BillReconciliation{
+		AccountID:    account.ID,
+		AccountName:  account.Name,
+		Provider:     string(account.Provider),
+		BillingMonth: month,
+		TenantID:     account.TenantID,
+	}
+
+	total, fetchErr := s.fetchBillTotal(ctx, account, month)
+	if fetchErr != nil {
+		rec.Status = domain.ReconcileStatusFailed
+		rec.ErrorMsg = fetchErr.Error()
+		if _, err := s.reconcileDAO.Upsert(ctx, rec); err != nil {
+			return rec, fmt.Errorf("save reconciliation: %w", err)
+		}
+		return rec, fetchErr
+	}
+
+	// 账单口径（不含摊销派生行）按币种汇总明细，与账单总览的原币种金额比较
+	groups, err := s.billDAO.AggregateByField(ctx, "", "currency",
+		start.Format("2006-01-02"), start.AddDate(0, 1, -1).Format("2006-01-02"),
+		repository.UnifiedBillFilter{AccountID: account.ID, View: domain.CostViewBilled})
+	if err != nil {
+		return rec, fmt.Errorf("sum bill details: %w", err)
+	}
+	rec.Currency = total.Currency
+	rec.InvoiceAmount = roundAmount(total.Amount)
+	var otherCurrencies []string
+	for _, g := range groups {
+		switch {
+		case g.Key == total.Currency:
+			rec.DetailAmount = roundAmount(g.Amount)
+		case g.Amount != 0:
+			otherCurrencies = append(otherCurrencies, g.Key)
+		}
+	}
+	rec.Drift = roundAmount(rec.DetailAmount - rec.InvoiceAmount)
+	rec.DriftPct = driftPct(rec.Drift, rec.InvoiceAmount)
+
+	switch {
+	case len(otherCurrencies) > 0:
+		rec.Status = domain.ReconcileStatusFailed
+		rec.ErrorMsg = fmt.Sprintf("bill details contain currencies %v, invoice currency is %s", otherCurrencies, total.Currency)
+	case s.exceedsTolerance(rec.Drift, rec.DriftPct):
+		rec.Status = domain.ReconcileStatusDrift
+	default:
+		rec.Status = domain.ReconcileStatusMatched
+	}
+
+	saved, err := s.reconcileDAO.Upsert(ctx, rec)
+	if err != nil {
+		return rec, fmt.Errorf("save reconciliation: %w", err)
+	}
+	s.notify(ctx, &saved)
+
+	s.logger.Info("bills reconciled",
+		elog.Int64("account_id", account.ID),
+		elog.String("billing_month", month),
+		elog.String("status", saved.Status),
+		elog.Any("drift", saved.Drift))
+	return saved, nil
+}
+
+// Recollect 重新采集云账号指定账期的账单（整月替换，不产生重复行）后重新对账
+func (s *ReconcileService) Recollect(ctx context.Context, tenantID string, accountID int64, month string) (domain.BillReconciliation, error) {
+	if _, err := parseMonth(month); err != nil {
+		return domain.BillReconciliation{}, err
+	}
+	if _, err := s.getAccount(ctx, tenantID, accountID); err != nil {
+		return domain.BillReconciliation{}, err
+	}
+	if err := s.collector.RecollectMonth(ctx, accountID, month); err != nil {
+		return domain.BillReconciliation{}, fmt.Errorf("recollect %s: %w", month, err)
+	}
+
+	rec, err := s.ReconcileAccount(ctx, tenantID, accountID, month)
+	if rec.ID == 0 {
+		return rec, err
+	}
+	if incErr := s.reconcileDAO.IncrRecollect(ctx, rec.ID); incErr != nil {
+		s.logger.Warn("increase recollect count failed", elog.Int64("id", rec.ID), elog.FieldErr(incErr))
+	} else {
+		rec.RecollectCount++
+	}
+	return rec, err
+}
+
+// ListReconciliations 查询对账记录
+func (s *ReconcileService) ListReconciliations(ctx context.Context, tenantID string, filter repository.ReconcileFilter) ([]domain.BillReconciliation, int64, error) {
+	filter.TenantID = tenantID
+	items, err := s.reconcileDAO.List(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.reconcileDAO.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// getAccount 获取云账号，tenantID 非空时不属于该租户的账号视为不存在
+func (s *ReconcileService) getAccount(ctx context.Context, tenantID string, accountID int64) (*shareddomain.CloudAccount, error) {
+	if accountID <= 0 {
+		return nil, fmt.Errorf("%w: account_id is required", domain.ErrReconcileInvalid)
+	}
+	account, err := s.accountSvc.GetAccountWithCredentials(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("get account %d: %w", accountID, err)
+	}
+	if tenantID != "" && account.TenantID != tenantID {
+		return nil, fmt.Errorf("%w: account %d not found", domain.ErrReconcileInvalid, accountID)
+	}
+	return account, nil
+}
+
+// fetchBillTotal 通过计费适配器拉取账期账单总览
+func (s *ReconcileService) fetchBillTotal(ctx context.Context, account *shareddomain.CloudAccount, month string) (*billing.BillTotal, error) {
+	adapter, err := s.adapterFor(account)
+	if err != nil {
+		return nil, fmt.Errorf("create billing adapter for %s: %w", account.Provider, err)
+	}
+	total, err := adapter.FetchBillTotal(ctx, billing.FetchBillTotalParams{
+		AccountID:    strconv.FormatInt(account.ID, 10),
+		BillingCycle: month,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch bill total: %w", err)
+	}
+	return total, nil
+}
+
+// exceedsTolerance 差异同时超过绝对容差与相对容差
+func (s *ReconcileService) exceedsTolerance(drift, pct float64) bool {
+	return math.Abs(drift) > s.toleranceAbs && pct > s.tolerancePct
+}
+
+// notify 差异首次出现时告警，差异消除后重置告警标记，使再次出现差异时重新告警
+func (s *ReconcileService) notify(ctx context.Context, rec *domain.BillReconciliation) {
+	switch {
+	case rec.Status == domain.ReconcileStatusDrift && !rec.Alerted:
+		if s.alertSvc == nil {
+			return
+		}
+		if err := s.emitDriftAlert(ctx, *rec); err != nil {
+			s.logger.Error("emit bill drift alert failed", elog.Int64("id", rec.ID), elog.FieldErr(err))
+			return
+		}
+		rec.Alerted = true
+	case rec.Status == domain.ReconcileStatusMatched && rec.Alerted:
+		rec.Alerted = false
+	default:
+		return
+	}
+	if err := s.reconcileDAO.SetAlerted(ctx, rec.ID, rec.Alerted); err != nil {
+		s.logger.Error("update reconciliation alert flag failed", elog.Int64("id", rec.ID), elog.FieldErr(err))
+	}
+}
+
+// emitDriftAlert 发送账单对账差异告警
+func (s *ReconcileService) emitDriftAlert(ctx context.Context, rec domain.BillReconciliation) error {
+	severity := alertdomain.SeverityWarning
+	if rec.DriftPct >= criticalDriftPct {
+		severity = alertdomain.SeverityCritical
+	}
+
+	event := alertdomain.AlertEvent{
+		Type:     alertdomain.AlertTypeBillDrift,
+		Severity: severity,
+		Title: fmt.Sprintf("账单对账差异: %s %s 差异 %.2f %s (%.2f%%)",
+			rec.AccountName, rec.BillingMonth, rec.Drift, rec.Currency, rec.DriftPct),
+		Content: map[string]any{
+			"account_id":     rec.AccountID,
+			"account_name":   rec.AccountName,
+			"provider":       rec.Provider,
+			"billing_month":  rec.BillingMonth,
+			"currency":       rec.Currency,
+			"detail_amount":  rec.DetailAmount,
+			"invoice_amount": rec.InvoiceAmount,
+			"drift":          rec.Drift,
+			"drift_pct":      rec.DriftPct,
+		},
+		Source:     fmt.Sprintf("reconcile:%d:%s", rec.AccountID, rec.BillingMonth),
+		TenantID:   rec.TenantID,
+		Status:     alertdomain.EventStatusPending,
+		CreateTime: time.Now(),
+	}
+	return s.alertSvc.EmitEvent(ctx, event)
+}
+
+// parseMonth 解析账期（YYYY-MM）
+func parseMonth(month string) (time.Time, error) {
+	t, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: billing_month must be YYYY-MM", domain.ErrReconcileInvalid)
+	}
+	return t, nil
+}
+
+// driftPct 差异占账单总览的百分比（绝对值），账单总览为 0 时有差异即为 100%
+func driftPct(drift, invoice float64) float64 {
+	if invoice == 0 {
+		if drift == 0 {
+			return 0
+		}
+		return 100
+	}
+	return math.Round(math.Abs(drift)/math.Abs(invoice)*10000) / 100
+}
+
+// roundAmount 金额保留两位小数，消除浮点累加误差
+func roundAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
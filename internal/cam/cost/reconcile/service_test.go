@@ -0,0 +1,415 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	alertdomain "github.com/Havens-blog/e-cam-service/internal/alert/domain"
+	alertservice "github.com/Havens-blog/e-cam-service/internal/alert/service"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Mock ReconcileDAO ==========
+
+type mockReconcileDAO struct {
+	records map[string]domain.BillReconciliation
+	nextID  int64
+}
+
+func newMockReconcileDAO() *mockReconcileDAO {
+	return &mockReconcileDAO{records: make(map[string]domain.BillReconciliation)}
+}
+
+func (m *mockReconcileDAO) key(accountID int64, month string) string {
+	return fmt.Sprintf("%d/%s", accountID, month)
+}
+func (m *mockReconcileDAO) Upsert(_ context.Context, rec domain.BillReconciliation) (domain.BillReconciliation, error) {
+	k := m.key(rec.AccountID, rec.BillingMonth)
+	if old, ok := m.records[k]; ok {
+		rec.ID, rec.Alerted, rec.RecollectCount = old.ID, old.Alerted, old.RecollectCount
+	} else {
+		m.nextID++
+		rec.ID = m.nextID
+	}
+	m.records[k] = rec
+	return rec, nil
+}
+func (m *mockReconcileDAO) Get(_ context.Context, accountID int64, month string) (domain.BillReconciliation, error) {
+	return m.records[m.key(accountID, month)], nil
+}
+func (m *mockReconcileDAO) List(_ context.Context, _ repository.ReconcileFilter) ([]domain.BillReconciliation, error) {
+	var result []domain.BillReconciliation
+	for _, r := range m.records {
+		result = append(result, r)
+	}
+	return result, nil
+}
+func (m *mockReconcileDAO) Count(_ context.Context, _ repository.ReconcileFilter) (int64, error) {
+	return int64(len(m.records)), nil
+}
+func (m *mockReconcileDAO) SetAlerted(_ context.Context, id int64, alerted bool) error {
+	return m.update(id, func(r *domain.BillReconciliation) { r.Alerted = alerted })
+}
+func (m *mockReconcileDAO) IncrRecollect(_ context.Context, id int64) error {
+	return m.update(id, func(r *domain.BillReconciliation) { r.RecollectCount++ })
+}
+func (m *mockReconcileDAO) update(id int64, fn func(r *domain.BillReconciliation)) error {
+	for k, r := range m.records {
+		if r.ID == id {
+			fn(&r)
+			m.records[k] = r
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+// ========== Mock BillDAO ==========
+
+type mockBillDAO struct {
+	repository.BillDAO
+	groups  []repository.AggregateResult
+	filters []repository.UnifiedBillFilter
+	ranges  [][2]string
+}
+
+func (m *mockBillDAO) AggregateByField(_ context.Context, _ string, _ string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.AggregateResult, error) {
+	m.filters = append(m.filters, filter)
+	m.ranges = append(m.ranges, [2]string{startDate, endDate})
+	return m.groups, nil
+}
+
+// ========== Mock AccountProvider / Recollector / Adapter ==========
+
+type mockAccountProvider struct {
+	accounts []*shareddomain.CloudAccount
+}
+
+func (m *mockAccountProvider) GetAccountWithCredentials(_ context.Context, id int64) (*shareddomain.CloudAccount, error) {
+	for _, a := range m.accounts {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return nil, assert.AnError
+}
+func (m *mockAccountProvider) ListAccounts(_ context.Context, _ shareddomain.CloudAccountFilter) ([]*shareddomain.CloudAccount, int64, error) {
+	return m.accounts, int64(len(m.accounts)), nil
+}
+
+type mockRecollector struct {
+	calls []string
+	after func()
+}
+
+func (m *mockRecollector) RecollectMonth(_ context.Context, _ int64, month string) error {
+	m.calls = append(m.calls, month)
+	if m.after != nil {
+		m.after()
+	}
+	return nil
+}
+
+type fakeTotalAdapter struct {
+	total  *billing.BillTotal
+	err    error
+	params []billing.FetchBillTotalParams
+}
+
+func (f *fakeTotalAdapter) GetProvider() shareddomain.CloudProvider {
+	return shareddomain.CloudProviderAliyun
+}
+func (f *fakeTotalAdapter) FetchBillDetails(_ context.Context, _ billing.FetchBillParams) ([]billing.RawBillItem, error) {
+	return nil, nil
+}
+func (f *fakeTotalAdapter) FetchBillTotal(_ context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	f.params = append(f.params, params)
+	return f.total, f.err
+}
+
+// ========== Mock AlertDAO ==========
+
+type mockAlertDAO struct {
+	emittedEvents []alertdomain.AlertEvent
+	listRulesFn   func(ctx context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error)
+}
+
+func (m *mockAlertDAO) CreateRule(_ context.Context, _ alertdomain.AlertRule) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateRule(_ context.Context, _ alertdomain.AlertRule) error { return nil }
+func (m *mockAlertDAO) GetRuleByID(_ context.Context, _ int64) (alertdomain.AlertRule, error) {
+	return alertdomain.AlertRule{}, nil
+}
+func (m *mockAlertDAO) ListRules(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+	if m.listRulesFn != nil {
+		return m.listRulesFn(nil, filter)
+	}
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteRule(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) CreateEvent(_ context.Context, event alertdomain.AlertEvent) (int64, error) {
+	m.emittedEvents = append(m.emittedEvents, event)
+	return int64(len(m.emittedEvents)), nil
+}
+func (m *mockAlertDAO) UpdateEventStatus(_ context.Context, _ int64, _ alertdomain.EventStatus) error {
+	return nil
+}
+func (m *mockAlertDAO) ListEvents(_ context.Context, _ alertdomain.AlertEventFilter) ([]alertdomain.AlertEvent, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) GetPendingEvents(_ context.Context, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) IncrementRetry(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetEventByID(_ context.Context, _ int64) (alertdomain.AlertEvent, error) {
+	return alertdomain.AlertEvent{}, nil
+}
+func (m *mockAlertDAO) ResolveEvents(_ context.Context, _ string, _ alertdomain.EventTransition) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) AckEvent(_ context.Context, _ int64, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AssignEvent(_ context.Context, _ int64, _ string, _ alertdomain.EventTransition) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) AppendEventHistory(_ context.Context, _ int64, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) SetAckDeadline(_ context.Context, _ int64, _ *time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) RenotifyEvent(_ context.Context, _ int64, _ time.Time, _ alertdomain.EventTransition) error {
+	return nil
+}
+func (m *mockAlertDAO) GetRenotifyEvents(_ context.Context, _ time.Time, _ int) ([]alertdomain.AlertEvent, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) GetState(_ context.Context, _ string) (alertdomain.AlertState, error) {
+	return alertdomain.AlertState{}, nil
+}
+func (m *mockAlertDAO) RecordOccurrence(_ context.Context, state alertdomain.AlertState, _ time.Time) (alertdomain.AlertState, error) {
+	state.Count = 1
+	return state, nil
+}
+func (m *mockAlertDAO) MarkStateEscalated(_ context.Context, _ string) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) MarkStateNotified(_ context.Context, _ string, _ time.Time, _ time.Duration) (bool, error) {
+	return true, nil
+}
+func (m *mockAlertDAO) SetStateLastEvent(_ context.Context, _ string, _ int64) error { return nil }
+func (m *mockAlertDAO) ResolveState(_ context.Context, _ alertdomain.AlertState, _ time.Time) error {
+	return nil
+}
+func (m *mockAlertDAO) ListActiveStates(_ context.Context) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) ListActiveStatesByType(_ context.Context, _ string, _ alertdomain.AlertType) ([]alertdomain.AlertState, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) CreateChannel(_ context.Context, _ alertdomain.NotificationChannel) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateChannel(_ context.Context, _ alertdomain.NotificationChannel) error {
+	return nil
+}
+func (m *mockAlertDAO) GetChannelByID(_ context.Context, _ int64) (alertdomain.NotificationChannel, error) {
+	return alertdomain.NotificationChannel{}, nil
+}
+func (m *mockAlertDAO) ListChannels(_ context.Context, _ alertdomain.ChannelFilter) ([]alertdomain.NotificationChannel, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteChannel(_ context.Context, _ int64) error { return nil }
+func (m *mockAlertDAO) GetChannelsByIDs(_ context.Context, _ []int64) ([]alertdomain.NotificationChannel, error) {
+	return nil, nil
+}
+func (m *mockAlertDAO) CreateTemplate(_ context.Context, _ alertdomain.MessageTemplate) (int64, error) {
+	return 0, nil
+}
+func (m *mockAlertDAO) UpdateTemplate(_ context.Context, _ alertdomain.MessageTemplate) error {
+	return nil
+}
+func (m *mockAlertDAO) GetTemplateByID(_ context.Context, _ int64) (alertdomain.MessageTemplate, error) {
+	return alertdomain.MessageTemplate{}, nil
+}
+func (m *mockAlertDAO) ListTemplates(_ context.Context, _ alertdomain.MessageTemplateFilter) ([]alertdomain.MessageTemplate, int64, error) {
+	return nil, 0, nil
+}
+func (m *mockAlertDAO) DeleteTemplate(_ context.Context, _ int64) error {
+	return nil
+}
+func (m *mockAlertDAO) InitIndexes(_ context.Context) error { return nil }
+
+// ========== Test Setup ==========
+
+type testEnv struct {
+	svc         *ReconcileService
+	billDAO     *mockBillDAO
+	dao         *mockReconcileDAO
+	alertDAO    *mockAlertDAO
+	adapter     *fakeTotalAdapter
+	recollector *mockRecollector
+}
+
+func newTestEnv() *testEnv {
+	env := &testEnv{
+		billDAO: &mockBillDAO{},
+		dao:     newMockReconcileDAO(),
+		alertDAO: &mockAlertDAO{
+			listRulesFn: func(_ context.Context, filter alertdomain.AlertRuleFilter) ([]alertdomain.AlertRule, int64, error) {
+				return []alertdomain.AlertRule{{ID: 1, Type: filter.Type, Enabled: true}}, 1, nil
+			},
+		},
+		adapter:     &fakeTotalAdapter{total: &billing.BillTotal{BillingCycle: "2026-09", Amount: 1000, Currency: "CNY"}},
+		recollector: &mockRecollector{},
+	}
+	accounts := &mockAccountProvider{accounts: []*shareddomain.CloudAccount{
+		{ID: 7, Name: "prod", Provider: shareddomain.CloudProviderAliyun, TenantID: "tenant1"},
+	}}
+	logger := elog.DefaultLogger
+	env.svc = NewReconcileService(env.billDAO, env.dao, accounts, env.recollector,
+		alertservice.NewAlertService(env.alertDAO, logger), logger)
+	env.svc.adapterFor = func(_ *shareddomain.CloudAccount) (billing.BillingAdapter, error) {
+		return env.adapter, nil
+	}
+	return env
+}
+
+// ========== Tests ==========
+
+func TestReconcileAccount_Matched(t *testing.T) {
+	env := newTestEnv()
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 1000.4}}
+
+	rec, err := env.svc.ReconcileAccount(context.Background(), "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReconcileStatusMatched, rec.Status)
+	assert.InDelta(t, 0.4, rec.Drift, 1e-9)
+	assert.Equal(t, "2026-09", env.adapter.params[0].BillingCycle)
+	assert.Equal(t, [2]string{"2026-09-01", "2026-09-30"}, env.billDAO.ranges[0])
+	assert.Equal(t, int64(7), env.billDAO.filters[0].AccountID)
+	assert.Equal(t, domain.CostViewBilled, env.billDAO.filters[0].View)
+	assert.Empty(t, env.alertDAO.emittedEvents)
+}
+
+func TestReconcileAccount_DriftAlertsOnceAndResets(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	// 漏采了部分分页：明细合计 900，账单总览 1000
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 900}}
+
+	rec, err := env.svc.ReconcileAccount(ctx, "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReconcileStatusDrift, rec.Status)
+	assert.Equal(t, -100.0, rec.Drift)
+	assert.Equal(t, 10.0, rec.DriftPct)
+	assert.True(t, rec.Alerted)
+	require.Len(t, env.alertDAO.emittedEvents, 1)
+	evt := env.alertDAO.emittedEvents[0]
+	assert.Equal(t, alertdomain.AlertTypeBillDrift, evt.Type)
+	assert.Equal(t, alertdomain.SeverityCritical, evt.Severity)
+	assert.Equal(t, "reconcile:7:2026-09", evt.Source)
+
+	// 差异未消除时不重复告警
+	_, err = env.svc.ReconcileAccount(ctx, "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Len(t, env.alertDAO.emittedEvents, 1)
+
+	// 差异消除后重置告警标记，再次出现差异时重新告警
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 1000}}
+	rec, err = env.svc.ReconcileAccount(ctx, "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReconcileStatusMatched, rec.Status)
+	assert.False(t, rec.Alerted)
+
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 1020}}
+	rec, err = env.svc.ReconcileAccount(ctx, "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReconcileStatusDrift, rec.Status)
+	require.Len(t, env.alertDAO.emittedEvents, 2)
+	assert.Equal(t, alertdomain.SeverityWarning, env.alertDAO.emittedEvents[1].Severity)
+}
+
+func TestReconcileAccount_Tolerance(t *testing.T) {
+	env := newTestEnv()
+	env.svc.SetTolerance(5, 10)
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 960}}
+
+	rec, err := env.svc.ReconcileAccount(context.Background(), "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReconcileStatusMatched, rec.Status)
+	assert.Equal(t, 4.0, rec.DriftPct)
+}
+
+func TestReconcileAccount_FetchFailedIsRecorded(t *testing.T) {
+	env := newTestEnv()
+	env.adapter.err = errors.New("throttled")
+
+	_, err := env.svc.ReconcileAccount(context.Background(), "tenant1", 7, "2026-09")
+	require.Error(t, err)
+	rec, _ := env.dao.Get(context.Background(), 7, "2026-09")
+	assert.Equal(t, domain.ReconcileStatusFailed, rec.Status)
+	assert.Contains(t, rec.ErrorMsg, "throttled")
+	assert.Empty(t, env.alertDAO.emittedEvents)
+}
+
+func TestReconcileAccount_CurrencyMismatch(t *testing.T) {
+	env := newTestEnv()
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 1000}, {Key: "USD", Amount: 12}}
+
+	rec, err := env.svc.ReconcileAccount(context.Background(), "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ReconcileStatusFailed, rec.Status)
+	assert.Contains(t, rec.ErrorMsg, "USD")
+}
+
+func TestReconcileAccount_Invalid(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+
+	_, err := env.svc.ReconcileAccount(ctx, "tenant1", 7, "2026/09")
+	assert.ErrorIs(t, err, domain.ErrReconcileInvalid)
+	_, err = env.svc.ReconcileAccount(ctx, "other", 7, "2026-09")
+	assert.ErrorIs(t, err, domain.ErrReconcileInvalid)
+	assert.Empty(t, env.adapter.params)
+}
+
+func TestRecollect_ReplacesMonthAndReconciles(t *testing.T) {
+	env := newTestEnv()
+	ctx := context.Background()
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 900}}
+	_, err := env.svc.ReconcileAccount(ctx, "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+
+	env.recollector.after = func() {
+		env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 1000}}
+	}
+	rec, err := env.svc.Recollect(ctx, "tenant1", 7, "2026-09")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-09"}, env.recollector.calls)
+	assert.Equal(t, domain.ReconcileStatusMatched, rec.Status)
+	assert.Equal(t, 1, rec.RecollectCount)
+	assert.False(t, rec.Alerted)
+}
+
+func TestReconcileAll_PreviousMonths(t *testing.T) {
+	env := newTestEnv()
+	env.billDAO.groups = []repository.AggregateResult{{Key: "CNY", Amount: 1000}}
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, env.svc.ReconcileAll(context.Background(), "", 2, now))
+	require.Len(t, env.adapter.params, 2)
+	assert.Equal(t, "2026-08", env.adapter.params[0].BillingCycle)
+	assert.Equal(t, "2026-09", env.adapter.params[1].BillingCycle)
+}
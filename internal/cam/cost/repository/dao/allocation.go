@@ -53,6 +53,7 @@ func (d *allocationDAO) UpdateRule(ctx context.Context, rule domain.AllocationRu
 		"tag_key":          rule.TagKey,
 		"tag_value_map":    rule.TagValueMap,
 		"shared_config":    rule.SharedConfig,
+		"usage_config":     rule.UsageConfig,
 		"priority":         rule.Priority,
 		"status":           rule.Status,
 		"utime":            rule.UpdateTime,
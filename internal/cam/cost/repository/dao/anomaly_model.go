@@ -0,0 +1,63 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AnomalyModelCollection = "ecam_cost_anomaly_model"
+
+type anomalyModelDAO struct {
+	db *mongox.Mongo
+}
+
+// NewAnomalyModelConfigDAO 创建异常检测模型配置 DAO
+func NewAnomalyModelConfigDAO(db *mongox.Mongo) repository.AnomalyModelConfigDAO {
+	return &anomalyModelDAO{db: db}
+}
+
+func (d *anomalyModelDAO) ListByTenant(ctx context.Context, tenantID string) ([]domain.AnomalyModelConfig, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "dimension", Value: 1}})
+	cursor, err := d.db.Collection(AnomalyModelCollection).Find(ctx, bson.M{"tenant_id": tenantID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var configs []domain.AnomalyModelConfig
+	if err := cursor.All(ctx, &configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+func (d *anomalyModelDAO) Upsert(ctx context.Context, cfg domain.AnomalyModelConfig) error {
+	now := time.Now().UnixMilli()
+	filter := bson.M{"tenant_id": cfg.TenantID, "dimension": cfg.Dimension}
+	update := bson.M{
+		"$set": bson.M{
+			"model":       cfg.Model,
+			"sensitivity": cfg.Sensitivity,
+			"alpha":       cfg.Alpha,
+			"window_days": cfg.WindowDays,
+			"utime":       now,
+		},
+		"$setOnInsert": bson.M{
+			"id":    d.db.GetIdGenerator(AnomalyModelCollection),
+			"ctime": now,
+		},
+	}
+	_, err := d.db.Collection(AnomalyModelCollection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (d *anomalyModelDAO) Delete(ctx context.Context, tenantID, dimension string) error {
+	_, err := d.db.Collection(AnomalyModelCollection).DeleteOne(ctx, bson.M{"tenant_id": tenantID, "dimension": dimension})
+	return err
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/Havens-blog/e-cam-service/pkg/mongox"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -148,7 +149,8 @@ func (d *billDAO) ListUnifiedBills(ctx context.Context, filter repository.Unifie
 	if filter.Limit > 0 {
 		opts.SetLimit(filter.Limit)
 	}
-	opts.SetSort(bson.D{{Key: "billing_date", Value: -1}, {Key: "ctime", Value: -1}})
+	// id 作为最后排序键，保证分页遍历时顺序稳定
+	opts.SetSort(bson.D{{Key: "billing_date", Value: -1}, {Key: "ctime", Value: -1}, {Key: "id", Value: -1}})
 
 	cursor, err := d.db.Collection(UnifiedBillCollection).Find(ctx, query, opts)
 	if err != nil {
@@ -185,13 +187,16 @@ func (d *billDAO) AggregateByField(ctx context.Context, tenantID string, field s
 	if filter.Region != "" {
 		match["region"] = filter.Region
 	}
+	matchTags(match, filter.Tags)
+	matchView(match, filter.View)
+	amount, amountCNY := viewAmountFields(filter.View)
 
 	pipeline := bson.A{
 		bson.M{"$match": match},
 		bson.M{"$group": bson.M{
 			"_id":        "$" + field,
-			"amount":     bson.M{"$sum": "$amount"},
-			"amount_cny": bson.M{"$sum": "$amount_cny"},
+			"amount":     bson.M{"$sum": amount},
+			"amount_cny": bson.M{"$sum": amountCNY},
 		}},
 		bson.M{"$sort": bson.M{"amount_cny": -1}},
 	}
@@ -208,6 +213,40 @@ func (d *billDAO) AggregateByField(ctx context.Context, tenantID string, field s
 	return results, err
 }
 
+func (d *billDAO) AggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	match := bson.M{
+		"billing_date": bson.M{"$gte": startDate, "$lte": endDate},
+	}
+	if tenantID != "" {
+		match["tenant_id"] = tenantID
+	}
+	if filter.Provider != "" {
+		match["provider"] = filter.Provider
+	}
+	if filter.AccountID > 0 {
+		match["account_id"] = filter.AccountID
+	}
+	if filter.ServiceType != "" {
+		match["service_type"] = filter.ServiceType
+	}
+	if filter.Region != "" {
+		match["region"] = filter.Region
+	}
+	matchTags(match, filter.Tags)
+	matchView(match, filter.View)
+
+	cursor, err := d.db.Collection(UnifiedBillCollection).Aggregate(ctx, fieldDailyPipeline(match, field, filter.View),
+		options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []repository.FieldDailyAmount
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
 func (d *billDAO) AggregateDailyAmount(ctx context.Context, tenantID string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
 	match := bson.M{
 		"billing_date": bson.M{"$gte": startDate, "$lte": endDate},
@@ -227,13 +266,16 @@ func (d *billDAO) AggregateDailyAmount(ctx context.Context, tenantID string, sta
 	if filter.Region != "" {
 		match["region"] = filter.Region
 	}
+	matchTags(match, filter.Tags)
+	matchView(match, filter.View)
+	amount, amountCNY := viewAmountFields(filter.View)
 
 	pipeline := bson.A{
 		bson.M{"$match": match},
 		bson.M{"$group": bson.M{
 			"_id":        "$billing_date",
-			"amount":     bson.M{"$sum": "$amount"},
-			"amount_cny": bson.M{"$sum": "$amount_cny"},
+			"amount":     bson.M{"$sum": amount},
+			"amount_cny": bson.M{"$sum": amountCNY},
 		}},
 		bson.M{"$sort": bson.M{"_id": 1}},
 	}
@@ -252,11 +294,12 @@ func (d *billDAO) AggregateDailyAmount(ctx context.Context, tenantID string, sta
 
 func (d *billDAO) SumAmount(ctx context.Context, filter repository.UnifiedBillFilter) (float64, error) {
 	query := d.buildUnifiedBillQuery(filter)
+	_, amountCNY := viewAmountFields(filter.View)
 	pipeline := bson.A{
 		bson.M{"$match": query},
 		bson.M{"$group": bson.M{
 			"_id":        nil,
-			"amount_cny": bson.M{"$sum": "$amount_cny"},
+			"amount_cny": bson.M{"$sum": amountCNY},
 		}},
 	}
 
@@ -280,12 +323,8 @@ func (d *billDAO) SumAmount(ctx context.Context, filter repository.UnifiedBillFi
 }
 
 func (d *billDAO) DeleteUnifiedBillsByPeriod(ctx context.Context, tenantID string, period string) error {
-	filter := bson.M{
-		"tenant_id": tenantID,
-		"billing_date": bson.M{
-			"$regex": "^" + period, // period is YYYY-MM, billing_date is YYYY-MM-DD
-		},
-	}
+	// period is YYYY-MM, billing_date is YYYY-MM-DD
+	filter := withAmortizedRows(bson.M{"tenant_id": tenantID}, bson.M{"$regex": "^" + period})
 	_, err := d.db.Collection(UnifiedBillCollection).DeleteMany(ctx, filter)
 	return err
 }
@@ -303,10 +342,7 @@ func (d *billDAO) DeleteRawBillsByAccountAndRange(ctx context.Context, accountID
 }
 
 func (d *billDAO) DeleteUnifiedBillsByAccountAndRange(ctx context.Context, accountID int64, startDate, endDate string) (int64, error) {
-	filter := bson.M{
-		"account_id":   accountID,
-		"billing_date": bson.M{"$gte": startDate, "$lte": endDate},
-	}
+	filter := withAmortizedRows(bson.M{"account_id": accountID}, bson.M{"$gte": startDate, "$lte": endDate})
 	result, err := d.db.Collection(UnifiedBillCollection).DeleteMany(ctx, filter)
 	if err != nil {
 		return 0, err
@@ -329,11 +365,9 @@ func (d *billDAO) DeleteRawBillsByAccountAndMonth(ctx context.Context, accountID
 }
 
 // DeleteUnifiedBillsByAccountAndMonth 按账号和月份删除统一账单
+// 该月购买行派生的摊销行（可能落在后续月份）一并删除，该月内由其他月份购买派生的摊销行保留
 func (d *billDAO) DeleteUnifiedBillsByAccountAndMonth(ctx context.Context, accountID int64, month string) (int64, error) {
-	filter := bson.M{
-		"account_id":   accountID,
-		"billing_date": bson.M{"$regex": "^" + month},
-	}
+	filter := withAmortizedRows(bson.M{"account_id": accountID}, bson.M{"$regex": "^" + month})
 	result, err := d.db.Collection(UnifiedBillCollection).DeleteMany(ctx, filter)
 	if err != nil {
 		return 0, err
@@ -349,6 +383,7 @@ func (d *billDAO) AggregateByTag(ctx context.Context, tenantID string, startDate
 	if tenantID != "" {
 		match["tenant_id"] = tenantID
 	}
+	matchView(match, domain.CostViewBilled)
 
 	// 将 tags map 展开为 k/v 数组，按 value 聚合金额
 	pipeline := bson.A{
@@ -376,6 +411,28 @@ func (d *billDAO) AggregateByTag(ctx context.Context, tenantID string, startDate
 	return results, err
 }
 
+func (d *billDAO) UpdateUnifiedBillAmountCNY(ctx context.Context, updates []repository.AmountCNYUpdate) (int64, error) {
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	models := make([]mongo.WriteModel, 0, len(updates))
+	for _, u := range updates {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"id": u.ID}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"amount_cny":    u.AmountCNY,
+				"exchange_rate": u.ExchangeRate,
+				"utime":         now,
+			}}))
+	}
+	result, err := d.db.Collection(UnifiedBillCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
 func (d *billDAO) buildUnifiedBillQuery(filter repository.UnifiedBillFilter) bson.M {
 	query := bson.M{}
 	if filter.TenantID != "" {
@@ -396,6 +453,11 @@ func (d *billDAO) buildUnifiedBillQuery(filter repository.UnifiedBillFilter) bso
 	if filter.ResourceID != "" {
 		query["resource_id"] = filter.ResourceID
 	}
+	matchTags(query, filter.Tags)
+	matchView(query, filter.View)
+	if filter.Currency != "" {
+		query["currency"] = filter.Currency
+	}
 	if filter.StartDate != "" && filter.EndDate != "" {
 		query["billing_date"] = bson.M{"$gte": filter.StartDate, "$lte": filter.EndDate}
 	} else if filter.StartDate != "" {
@@ -405,3 +467,111 @@ func (d *billDAO) buildUnifiedBillQuery(filter repository.UnifiedBillFilter) bso
 	}
 	return query
 }
+
+// matchTags 追加标签匹配条件（tags.key = value）
+func matchTags(match bson.M, tags map[string]string) {
+	for k, v := range tags {
+		match["tags."+k] = v
+	}
+}
+
+// matchView 追加成本口径条件：仅摊销口径包含摊销派生行（$ne 同时匹配历史数据中缺失的 line_type）
+func matchView(match bson.M, view string) {
+	if view != domain.CostViewAmortized {
+		match["line_type"] = bson.M{"$ne": domain.LineTypeAmortization}
+	}
+}
+
+// viewAmountFields 返回成本口径对应的原币种与人民币金额表达式，
+// 历史数据缺少净额 / 摊销字段时回退为账单金额
+func viewAmountFields(view string) (interface{}, interface{}) {
+	var field string
+	switch view {
+	case domain.CostViewNet:
+		field = "$net_amount"
+	case domain.CostViewAmortized:
+		field = "$amortized_amount"
+	default:
+		return "$amount", "$amount_cny"
+	}
+	return bson.M{"$ifNull": bson.A{field, "$amount"}},
+		bson.M{"$ifNull": bson.A{bson.M{"$multiply": bson.A{field, "$exchange_rate"}}, "$amount_cny"}}
+}
+
+// withAmortizedRows 构建重新采集时的删除条件：按账单日期删除非摊销行，
+// 并按 amortized_from 删除由这些购买行派生的摊销行
+func withAmortizedRows(filter bson.M, dateCond bson.M) bson.M {
+	filter["$or"] = bson.A{
+		bson.M{"billing_date": dateCond, "line_type": bson.M{"$ne": domain.LineTypeAmortization}},
+		bson.M{"amortized_from": dateCond},
+	}
+	return filter
+}
+
+func (d *billDAO) AggregateBreakdownDaily(ctx context.Context, tenantID, matchField, matchValue, groupField, startDate, endDate string) ([]repository.FieldDailyAmount, error) {
+	match := bson.M{
+		"billing_date": bson.M{"$gte": startDate, "$lte": endDate},
+		matchField:     matchValue,
+	}
+	if tenantID != "" {
+		match["tenant_id"] = tenantID
+	}
+	matchView(match, domain.CostViewBilled)
+
+	var pipeline bson.A
+	if groupField == "tags" {
+		// 展开 tags map，按 "key=value" 分组
+		match["tags"] = bson.M{"$ne": nil, "$type": "object"}
+		pipeline = bson.A{
+			bson.M{"$match": match},
+			bson.M{"$project": bson.M{
+				"billing_date": 1,
+				"amount":       1,
+				"amount_cny":   1,
+				"tag_arr":      bson.M{"$objectToArray": "$tags"},
+			}},
+			bson.M{"$unwind": "$tag_arr"},
+			bson.M{"$addFields": bson.M{
+				"tag": bson.M{"$concat": bson.A{"$tag_arr.k", "=", bson.M{"$toString": "$tag_arr.v"}}},
+			}},
+		}
+		pipeline = append(pipeline, fieldDailyPipeline(bson.M{}, "tag", domain.CostViewBilled)...)
+	} else {
+		pipeline = fieldDailyPipeline(match, groupField, domain.CostViewBilled)
+	}
+
+	cursor, err := d.db.Collection(UnifiedBillCollection).Aggregate(ctx, pipeline,
+		options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []repository.FieldDailyAmount
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
+// fieldDailyPipeline 构建按字段和账单日期分组的聚合管道（明细表与汇总表共用）
+func fieldDailyPipeline(match bson.M, field, view string) bson.A {
+	amount, amountCNY := viewAmountFields(view)
+	return bson.A{
+		bson.M{"$match": match},
+		bson.M{"$group": bson.M{
+			"_id": bson.M{
+				"key":  "$" + field,
+				"date": "$billing_date",
+			},
+			"amount":     bson.M{"$sum": amount},
+			"amount_cny": bson.M{"$sum": amountCNY},
+		}},
+		bson.M{"$project": bson.M{
+			"_id":        0,
+			"key":        "$_id.key",
+			"date":       "$_id.date",
+			"amount":     1,
+			"amount_cny": 1,
+		}},
+		bson.M{"$sort": bson.D{{Key: "key", Value: 1}, {Key: "date", Value: 1}}},
+	}
+}
@@ -140,3 +140,38 @@ func assertBSONValueEqual(t *testing.T, key string, original, decoded interface{
 		assert.Equal(t, original, decoded, "RawData[%q] value should match", key)
 	}
 }
+
+func TestMatchView(t *testing.T) {
+	billed := bson.M{}
+	matchView(billed, "")
+	assert.Equal(t, bson.M{"$ne": domain.LineTypeAmortization}, billed["line_type"])
+
+	net := bson.M{}
+	matchView(net, domain.CostViewNet)
+	assert.Contains(t, net, "line_type")
+
+	amortized := bson.M{}
+	matchView(amortized, domain.CostViewAmortized)
+	assert.NotContains(t, amortized, "line_type")
+}
+
+func TestViewAmountFields(t *testing.T) {
+	amount, amountCNY := viewAmountFields(domain.CostViewBilled)
+	assert.Equal(t, "$amount", amount)
+	assert.Equal(t, "$amount_cny", amountCNY)
+
+	amount, amountCNY = viewAmountFields(domain.CostViewAmortized)
+	assert.Equal(t, bson.M{"$ifNull": bson.A{"$amortized_amount", "$amount"}}, amount)
+	assert.Equal(t, bson.M{"$ifNull": bson.A{
+		bson.M{"$multiply": bson.A{"$amortized_amount", "$exchange_rate"}}, "$amount_cny",
+	}}, amountCNY)
+}
+
+func TestWithAmortizedRows(t *testing.T) {
+	filter := withAmortizedRows(bson.M{"account_id": int64(1)}, bson.M{"$regex": "^2024-01"})
+	assert.Equal(t, int64(1), filter["account_id"])
+	assert.Equal(t, bson.A{
+		bson.M{"billing_date": bson.M{"$regex": "^2024-01"}, "line_type": bson.M{"$ne": domain.LineTypeAmortization}},
+		bson.M{"amortized_from": bson.M{"$regex": "^2024-01"}},
+	}, filter["$or"])
+}
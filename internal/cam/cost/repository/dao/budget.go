@@ -40,16 +40,31 @@ func (d *budgetDAO) Create(ctx context.Context, budget domain.BudgetRule) (int64
 func (d *budgetDAO) Update(ctx context.Context, budget domain.BudgetRule) error {
 	budget.UpdateTime = time.Now().UnixMilli()
 	filter := bson.M{"id": budget.ID}
-	update := bson.M{"$set": bson.M{
-		"name":         budget.Name,
-		"amount_limit": budget.AmountLimit,
-		"period":       budget.Period,
-		"scope_type":   budget.ScopeType,
-		"scope_value":  budget.ScopeValue,
-		"thresholds":   budget.Thresholds,
-		"status":       budget.Status,
-		"utime":        budget.UpdateTime,
-	}}
+	set := bson.M{
+		"name":                budget.Name,
+		"amount_limit":        budget.AmountLimit,
+		"scope_type":          budget.ScopeType,
+		"scope_value":         budget.ScopeValue,
+		"scopes":              budget.Scopes,
+		"thresholds":          budget.Thresholds,
+		"forecast_thresholds": budget.ForecastThresholds,
+		"rollover":            budget.Rollover,
+		"utime":               budget.UpdateTime,
+	}
+	if budget.Currency != "" {
+		set["currency"] = budget.Currency
+	}
+	// 周期为空时保留原周期配置
+	if budget.Period != "" {
+		set["period"] = budget.Period
+		set["start_date"] = budget.StartDate
+		set["end_date"] = budget.EndDate
+		set["current_period_start"] = budget.CurrentPeriodStart
+	}
+	if budget.Status != "" {
+		set["status"] = budget.Status
+	}
+	update := bson.M{"$set": set}
 	result, err := d.db.Collection(BudgetCollection).UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
@@ -120,6 +135,18 @@ func (d *budgetDAO) UpdateStatus(ctx context.Context, id int64, status string) e
 	return err
 }
 
+func (d *budgetDAO) UpdatePeriod(ctx context.Context, id int64, periodStart string, rolloverAmount float64, notifiedAt map[string]time.Time) error {
+	filter := bson.M{"id": id}
+	update := bson.M{"$set": bson.M{
+		"current_period_start": periodStart,
+		"rollover_amount":      rolloverAmount,
+		"notified_at":          notifiedAt,
+		"utime":                time.Now().UnixMilli(),
+	}}
+	_, err := d.db.Collection(BudgetCollection).UpdateOne(ctx, filter, update)
+	return err
+}
+
 func (d *budgetDAO) UpdateNotifiedAt(ctx context.Context, id int64, notifiedAt map[string]time.Time) error {
 	filter := bson.M{"id": id}
 	update := bson.M{"$set": bson.M{
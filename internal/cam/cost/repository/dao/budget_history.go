@@ -0,0 +1,70 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const BudgetHistoryCollection = "ecam_cost_budget_history"
+
+type budgetHistoryDAO struct {
+	db *mongox.Mongo
+}
+
+// NewBudgetHistoryDAO 创建预算周期历史 DAO
+func NewBudgetHistoryDAO(db *mongox.Mongo) repository.BudgetHistoryDAO {
+	return &budgetHistoryDAO{db: db}
+}
+
+func (d *budgetHistoryDAO) Upsert(ctx context.Context, record domain.BudgetPeriodRecord) error {
+	filter := bson.M{"budget_id": record.BudgetID, "period_start": record.PeriodStart}
+	update := bson.M{
+		"$set": bson.M{
+			"period":        record.Period,
+			"period_end":    record.PeriodEnd,
+			"amount_limit":  record.AmountLimit,
+			"rollover_in":   record.RolloverIn,
+			"actual_spend":  record.ActualSpend,
+			"usage_percent": record.UsagePercent,
+			"rollover_out":  record.RolloverOut,
+			"currency":      record.Currency,
+			"tenant_id":     record.TenantID,
+		},
+		"$setOnInsert": bson.M{
+			"id":    d.db.GetIdGenerator(BudgetHistoryCollection),
+			"ctime": time.Now().UnixMilli(),
+		},
+	}
+	_, err := d.db.Collection(BudgetHistoryCollection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (d *budgetHistoryDAO) ListByBudget(ctx context.Context, budgetID int64, offset, limit int64) ([]domain.BudgetPeriodRecord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "period_start", Value: -1}})
+	if offset > 0 {
+		opts.SetSkip(offset)
+	}
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := d.db.Collection(BudgetHistoryCollection).Find(ctx, bson.M{"budget_id": budgetID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []domain.BudgetPeriodRecord
+	err = cursor.All(ctx, &records)
+	return records, err
+}
+
+func (d *budgetHistoryDAO) CountByBudget(ctx context.Context, budgetID int64) (int64, error) {
+	return d.db.Collection(BudgetHistoryCollection).CountDocuments(ctx, bson.M{"budget_id": budgetID})
+}
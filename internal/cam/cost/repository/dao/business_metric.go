@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const BusinessMetricCollection = "ecam_cost_business_metric"
+
+type businessMetricDAO struct {
+	db *mongox.Mongo
+}
+
+// NewBusinessMetricDAO 创建业务指标 DAO
+func NewBusinessMetricDAO(db *mongox.Mongo) repository.BusinessMetricDAO {
+	return &businessMetricDAO{db: db}
+}
+
+func (d *businessMetricDAO) Upsert(ctx context.Context, points []domain.BusinessMetricPoint) (int64, error) {
+	if len(points) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	models := make([]mongo.WriteModel, 0, len(points))
+	for _, p := range points {
+		filter := bson.M{"tenant_id": p.TenantID, "metric": p.Metric, "node_id": p.NodeID, "date": p.Date}
+		update := bson.M{
+			"$set": bson.M{
+				"value": p.Value,
+				"utime": now,
+			},
+			"$setOnInsert": bson.M{
+				"id":    d.db.GetIdGenerator(BusinessMetricCollection),
+				"ctime": now,
+			},
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	result, err := d.db.Collection(BusinessMetricCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+	return result.UpsertedCount + result.ModifiedCount, nil
+}
+
+func (d *businessMetricDAO) List(ctx context.Context, filter repository.BusinessMetricFilter) ([]domain.BusinessMetricPoint, error) {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.Metric != "" {
+		query["metric"] = filter.Metric
+	}
+	if len(filter.NodeIDs) > 0 {
+		query["node_id"] = bson.M{"$in": filter.NodeIDs}
+	}
+	dateRange := bson.M{}
+	if filter.StartDate != "" {
+		dateRange["$gte"] = filter.StartDate
+	}
+	if filter.EndDate != "" {
+		dateRange["$lte"] = filter.EndDate
+	}
+	if len(dateRange) > 0 {
+		query["date"] = dateRange
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "node_id", Value: 1}})
+	cursor, err := d.db.Collection(BusinessMetricCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var points []domain.BusinessMetricPoint
+	err = cursor.All(ctx, &points)
+	return points, err
+}
+
+func (d *businessMetricDAO) ListMetricNames(ctx context.Context, tenantID string) ([]string, error) {
+	values, err := d.db.Collection(BusinessMetricCollection).Distinct(ctx, "metric", bson.M{"tenant_id": tenantID})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		if name, ok := v.(string); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
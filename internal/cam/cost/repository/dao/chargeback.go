@@ -0,0 +1,122 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ChargebackCollection = "ecam_cost_chargeback"
+
+type chargebackDAO struct {
+	db *mongox.Mongo
+}
+
+// NewChargebackDAO 创建成本分账单 DAO
+func NewChargebackDAO(db *mongox.Mongo) repository.ChargebackDAO {
+	return &chargebackDAO{db: db}
+}
+
+func (d *chargebackDAO) Insert(ctx context.Context, stmt domain.ChargebackStatement) (int64, error) {
+	coll := d.db.Collection(ChargebackCollection)
+	_, err := coll.UpdateMany(ctx, bson.M{
+		"tenant_id":   stmt.TenantID,
+		"period":      stmt.Period,
+		"target_type": stmt.TargetType,
+		"target_id":   stmt.TargetID,
+		"latest":      true,
+	}, bson.M{"$set": bson.M{"latest": false}})
+	if err != nil {
+		return 0, err
+	}
+
+	stmt.ID = d.db.GetIdGenerator(ChargebackCollection)
+	stmt.Latest = true
+	if _, err := coll.InsertOne(ctx, stmt); err != nil {
+		return 0, err
+	}
+	return stmt.ID, nil
+}
+
+func (d *chargebackDAO) GetByID(ctx context.Context, id int64) (domain.ChargebackStatement, error) {
+	var stmt domain.ChargebackStatement
+	err := d.db.Collection(ChargebackCollection).FindOne(ctx, bson.M{"id": id}).Decode(&stmt)
+	return stmt, err
+}
+
+func (d *chargebackDAO) GetLatest(ctx context.Context, tenantID, period, targetType, targetID string) (domain.ChargebackStatement, error) {
+	var stmt domain.ChargebackStatement
+	opts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})
+	err := d.db.Collection(ChargebackCollection).FindOne(ctx, bson.M{
+		"tenant_id":   tenantID,
+		"period":      period,
+		"target_type": targetType,
+		"target_id":   targetID,
+	}, opts).Decode(&stmt)
+	return stmt, err
+}
+
+func (d *chargebackDAO) List(ctx context.Context, filter repository.ChargebackFilter) ([]domain.ChargebackStatement, error) {
+	opts := options.Find().SetSort(bson.D{
+		{Key: "period", Value: -1},
+		{Key: "total_amount", Value: -1},
+		{Key: "version", Value: -1},
+	})
+	if filter.Offset > 0 {
+		opts.SetSkip(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := d.db.Collection(ChargebackCollection).Find(ctx, d.buildQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stmts []domain.ChargebackStatement
+	err = cursor.All(ctx, &stmts)
+	return stmts, err
+}
+
+func (d *chargebackDAO) Count(ctx context.Context, filter repository.ChargebackFilter) (int64, error) {
+	return d.db.Collection(ChargebackCollection).CountDocuments(ctx, d.buildQuery(filter))
+}
+
+func (d *chargebackDAO) MarkDelivered(ctx context.Context, id int64, channelIDs []int64, at int64) error {
+	result, err := d.db.Collection(ChargebackCollection).UpdateOne(ctx, bson.M{"id": id},
+		bson.M{"$set": bson.M{"delivered_at": at, "delivered_channels": channelIDs}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (d *chargebackDAO) buildQuery(filter repository.ChargebackFilter) bson.M {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.Period != "" {
+		query["period"] = filter.Period
+	}
+	if filter.TargetType != "" {
+		query["target_type"] = filter.TargetType
+	}
+	if filter.TargetID != "" {
+		query["target_id"] = filter.TargetID
+	}
+	if !filter.AllVersions {
+		query["latest"] = true
+	}
+	return query
+}
@@ -40,6 +40,8 @@ func (d *collectLogDAO) Update(ctx context.Context, log domain.CollectLog) error
 	update := bson.M{"$set": bson.M{
 		"status":       log.Status,
 		"end_time":     log.EndTime,
+		"bill_start":   log.BillStart,
+		"bill_end":     log.BillEnd,
 		"record_count": log.RecordCount,
 		"duration_ms":  log.Duration,
 		"error_msg":    log.ErrorMsg,
@@ -60,6 +62,7 @@ func (d *collectLogDAO) GetLastSuccess(ctx context.Context, accountID int64) (do
 	filter := bson.M{
 		"account_id": accountID,
 		"status":     "success",
+		"source":     bson.M{"$ne": domain.CollectSourceImport},
 	}
 	opts := options.FindOne().SetSort(bson.D{{Key: "ctime", Value: -1}})
 	err := d.db.Collection(CollectLogCollection).FindOne(ctx, filter, opts).Decode(&log)
@@ -71,6 +74,19 @@ func (d *collectLogDAO) GetLastFailed(ctx context.Context, accountID int64) (dom
 	filter := bson.M{
 		"account_id": accountID,
 		"status":     "failed",
+		"source":     bson.M{"$ne": domain.CollectSourceImport},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "ctime", Value: -1}})
+	err := d.db.Collection(CollectLogCollection).FindOne(ctx, filter, opts).Decode(&log)
+	return log, err
+}
+
+func (d *collectLogDAO) GetSuccessByChecksum(ctx context.Context, accountID int64, checksum string) (domain.CollectLog, error) {
+	var log domain.CollectLog
+	filter := bson.M{
+		"account_id": accountID,
+		"status":     "success",
+		"checksum":   checksum,
 	}
 	opts := options.FindOne().SetSort(bson.D{{Key: "ctime", Value: -1}})
 	err := d.db.Collection(CollectLogCollection).FindOne(ctx, filter, opts).Decode(&log)
@@ -115,5 +131,12 @@ func (d *collectLogDAO) buildQuery(filter repository.CollectLogFilter) bson.M {
 	if filter.Status != "" {
 		query["status"] = filter.Status
 	}
+	switch filter.Source {
+	case "":
+	case domain.CollectSourceAPI:
+		query["source"] = bson.M{"$ne": domain.CollectSourceImport}
+	default:
+		query["source"] = filter.Source
+	}
 	return query
 }
@@ -0,0 +1,188 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CommitmentCollection         = "ecam_cost_commitment"
+	CommitmentCoverageCollection = "ecam_cost_commitment_coverage"
+)
+
+type commitmentDAO struct {
+	db *mongox.Mongo
+}
+
+// NewCommitmentDAO 创建承诺消费 DAO
+func NewCommitmentDAO(db *mongox.Mongo) repository.CommitmentDAO {
+	return &commitmentDAO{db: db}
+}
+
+func (d *commitmentDAO) UpsertCommitments(ctx context.Context, commitments []domain.Commitment) (int64, error) {
+	if len(commitments) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	models := make([]mongo.WriteModel, 0, len(commitments))
+	for _, c := range commitments {
+		filter := bson.M{"provider": c.Provider, "commitment_id": c.CommitmentID}
+		update := bson.M{
+			"$set": bson.M{
+				"account_id":        c.AccountID,
+				"type":              c.Type,
+				"region":            c.Region,
+				"instance_family":   c.InstanceFamily,
+				"instance_type":     c.InstanceType,
+				"quantity":          c.Quantity,
+				"unit":              c.Unit,
+				"hourly_commitment": c.HourlyCommitment,
+				"utilization_pct":   c.UtilizationPct,
+				"period_cost":       c.PeriodCost,
+				"currency":          c.Currency,
+				"start_time":        c.StartTime,
+				"end_time":          c.EndTime,
+				"status":            c.Status,
+				"provider_status":   c.ProviderStatus,
+				"tenant_id":         c.TenantID,
+				"sync_time":         now,
+			},
+			"$setOnInsert": bson.M{
+				"id":    d.db.GetIdGenerator(CommitmentCollection),
+				"ctime": now,
+			},
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	result, err := d.db.Collection(CommitmentCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+	return result.UpsertedCount + result.ModifiedCount, nil
+}
+
+func (d *commitmentDAO) List(ctx context.Context, filter repository.CommitmentFilter) ([]domain.Commitment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "end_time", Value: 1}, {Key: "id", Value: 1}})
+	if filter.Offset > 0 {
+		opts.SetSkip(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := d.db.Collection(CommitmentCollection).Find(ctx, d.buildQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var commitments []domain.Commitment
+	err = cursor.All(ctx, &commitments)
+	return commitments, err
+}
+
+func (d *commitmentDAO) Count(ctx context.Context, filter repository.CommitmentFilter) (int64, error) {
+	return d.db.Collection(CommitmentCollection).CountDocuments(ctx, d.buildQuery(filter))
+}
+
+func (d *commitmentDAO) MarkExpiryNotified(ctx context.Context, id int64, at time.Time) error {
+	result, err := d.db.Collection(CommitmentCollection).UpdateOne(ctx,
+		bson.M{"id": id},
+		bson.M{"$set": bson.M{"expiry_notified_at": at}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (d *commitmentDAO) ReplaceCoverage(ctx context.Context, tenantID string, accountID int64, items []domain.CommitmentCoverage) error {
+	collection := d.db.Collection(CommitmentCoverageCollection)
+	if _, err := collection.DeleteMany(ctx, bson.M{"tenant_id": tenantID, "account_id": accountID}); err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	docs := make([]interface{}, len(items))
+	for i := range items {
+		items[i].CreateTime = now
+		if items[i].ID == 0 {
+			items[i].ID = d.db.GetIdGenerator(CommitmentCoverageCollection)
+		}
+		docs[i] = items[i]
+	}
+	_, err := collection.InsertMany(ctx, docs)
+	return err
+}
+
+func (d *commitmentDAO) ListCoverage(ctx context.Context, filter repository.CommitmentFilter) ([]domain.CommitmentCoverage, error) {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.Provider != "" {
+		query["provider"] = filter.Provider
+	}
+	if filter.AccountID > 0 {
+		query["account_id"] = filter.AccountID
+	}
+	if filter.Region != "" {
+		query["region"] = filter.Region
+	}
+
+	cursor, err := d.db.Collection(CommitmentCoverageCollection).Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []domain.CommitmentCoverage
+	err = cursor.All(ctx, &items)
+	return items, err
+}
+
+func (d *commitmentDAO) buildQuery(filter repository.CommitmentFilter) bson.M {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.Provider != "" {
+		query["provider"] = filter.Provider
+	}
+	if filter.AccountID > 0 {
+		query["account_id"] = filter.AccountID
+	}
+	if filter.Region != "" {
+		query["region"] = filter.Region
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	endTime := bson.M{}
+	if !filter.ExpireAfter.IsZero() {
+		endTime["$gte"] = filter.ExpireAfter
+	}
+	if !filter.ExpireBefore.IsZero() {
+		endTime["$lt"] = filter.ExpireBefore
+	}
+	if len(endTime) > 0 {
+		query["end_time"] = endTime
+	}
+	return query
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
 	"github.com/Havens-blog/e-cam-service/pkg/mongox"
 	"github.com/gotomicro/ego/core/elog"
@@ -73,6 +74,8 @@ func (d *DailySummaryDAO) RebuildFromSource(ctx context.Context, startDate, endD
 	if startDate != "" && endDate != "" {
 		match["billing_date"] = bson.M{"$gte": startDate, "$lte": endDate}
 	}
+	// 汇总表仅承载账单口径
+	matchView(match, domain.CostViewBilled)
 
 	pipeline := bson.A{
 		bson.M{"$match": match},
@@ -228,6 +231,38 @@ func (d *DailySummaryDAO) AggregateByField(ctx context.Context, tenantID string,
 	return results, err
 }
 
+// AggregateByFieldDaily 从汇总表按字段和日期聚合
+func (d *DailySummaryDAO) AggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.FieldDailyAmount, error) {
+	match := bson.M{
+		"billing_date": bson.M{"$gte": startDate, "$lte": endDate},
+	}
+	if tenantID != "" {
+		match["tenant_id"] = tenantID
+	}
+	if filter.Provider != "" {
+		match["provider"] = filter.Provider
+	}
+	if filter.AccountID > 0 {
+		match["account_id"] = filter.AccountID
+	}
+	if filter.ServiceType != "" {
+		match["service_type"] = filter.ServiceType
+	}
+	if filter.Region != "" {
+		match["region"] = filter.Region
+	}
+
+	cursor, err := d.db.Collection(DailySummaryCollection).Aggregate(ctx, fieldDailyPipeline(match, field, domain.CostViewBilled))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []repository.FieldDailyAmount
+	err = cursor.All(ctx, &results)
+	return results, err
+}
+
 // AggregateDailyAmount 从汇总表按日聚合
 func (d *DailySummaryDAO) AggregateDailyAmount(ctx context.Context, tenantID string, startDate, endDate string, filter repository.UnifiedBillFilter) ([]repository.DailyAmount, error) {
 	match := bson.M{
@@ -0,0 +1,133 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ExchangeRateCollection = "ecam_cost_exchange_rate"
+
+type exchangeRateDAO struct {
+	db *mongox.Mongo
+}
+
+// NewExchangeRateDAO 创建汇率 DAO
+func NewExchangeRateDAO(db *mongox.Mongo) repository.ExchangeRateDAO {
+	return &exchangeRateDAO{db: db}
+}
+
+func (d *exchangeRateDAO) Upsert(ctx context.Context, rate domain.ExchangeRate) error {
+	_, err := d.UpsertBatch(ctx, []domain.ExchangeRate{rate})
+	return err
+}
+
+func (d *exchangeRateDAO) UpsertBatch(ctx context.Context, rates []domain.ExchangeRate) (int64, error) {
+	if len(rates) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	models := make([]mongo.WriteModel, 0, len(rates))
+	for _, rate := range rates {
+		filter := bson.M{
+			"base_currency":  rate.BaseCurrency,
+			"quote_currency": rate.QuoteCurrency,
+			"date":           rate.Date,
+		}
+		update := bson.M{
+			"$set": bson.M{
+				"rate":   rate.Rate,
+				"source": rate.Source,
+				"utime":  now,
+			},
+			"$setOnInsert": bson.M{
+				"id":    d.db.GetIdGenerator(ExchangeRateCollection),
+				"ctime": now,
+			},
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+	result, err := d.db.Collection(ExchangeRateCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+	return result.UpsertedCount + result.ModifiedCount, nil
+}
+
+func (d *exchangeRateDAO) FindEffective(ctx context.Context, base, quote, date string) (domain.ExchangeRate, error) {
+	var rate domain.ExchangeRate
+	filter := bson.M{
+		"base_currency":  base,
+		"quote_currency": quote,
+		"date":           bson.M{"$lte": date},
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "date", Value: -1}})
+	err := d.db.Collection(ExchangeRateCollection).FindOne(ctx, filter, opts).Decode(&rate)
+	return rate, err
+}
+
+func (d *exchangeRateDAO) List(ctx context.Context, filter repository.ExchangeRateFilter) ([]domain.ExchangeRate, error) {
+	query := d.buildQuery(filter)
+	opts := options.Find()
+	if filter.Offset > 0 {
+		opts.SetSkip(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+	opts.SetSort(bson.D{{Key: "date", Value: -1}, {Key: "base_currency", Value: 1}})
+
+	cursor, err := d.db.Collection(ExchangeRateCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rates []domain.ExchangeRate
+	err = cursor.All(ctx, &rates)
+	return rates, err
+}
+
+func (d *exchangeRateDAO) Count(ctx context.Context, filter repository.ExchangeRateFilter) (int64, error) {
+	query := d.buildQuery(filter)
+	return d.db.Collection(ExchangeRateCollection).CountDocuments(ctx, query)
+}
+
+func (d *exchangeRateDAO) Delete(ctx context.Context, id int64) error {
+	filter := bson.M{"id": id}
+	result, err := d.db.Collection(ExchangeRateCollection).DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (d *exchangeRateDAO) buildQuery(filter repository.ExchangeRateFilter) bson.M {
+	query := bson.M{}
+	if filter.BaseCurrency != "" {
+		query["base_currency"] = filter.BaseCurrency
+	}
+	if filter.QuoteCurrency != "" {
+		query["quote_currency"] = filter.QuoteCurrency
+	}
+	if filter.Source != "" {
+		query["source"] = filter.Source
+	}
+	if filter.StartDate != "" && filter.EndDate != "" {
+		query["date"] = bson.M{"$gte": filter.StartDate, "$lte": filter.EndDate}
+	} else if filter.StartDate != "" {
+		query["date"] = bson.M{"$gte": filter.StartDate}
+	} else if filter.EndDate != "" {
+		query["date"] = bson.M{"$lte": filter.EndDate}
+	}
+	return query
+}
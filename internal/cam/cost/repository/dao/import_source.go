@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ImportSourceCollection = "ecam_cost_import_source"
+
+type importSourceDAO struct {
+	db *mongox.Mongo
+}
+
+// NewImportSourceDAO 创建账单导入来源 DAO
+func NewImportSourceDAO(db *mongox.Mongo) repository.ImportSourceDAO {
+	return &importSourceDAO{db: db}
+}
+
+func (d *importSourceDAO) Create(ctx context.Context, source domain.BillImportSource) (int64, error) {
+	now := time.Now().UnixMilli()
+	source.CreateTime = now
+	source.UpdateTime = now
+	if source.ID == 0 {
+		source.ID = d.db.GetIdGenerator(ImportSourceCollection)
+	}
+	if _, err := d.db.Collection(ImportSourceCollection).InsertOne(ctx, source); err != nil {
+		return 0, err
+	}
+	return source.ID, nil
+}
+
+func (d *importSourceDAO) Update(ctx context.Context, source domain.BillImportSource) error {
+	update := bson.M{"$set": bson.M{
+		"name":       source.Name,
+		"account_id": source.AccountID,
+		"provider":   source.Provider,
+		"format":     source.Format,
+		"mapping":    source.Mapping,
+		"sheet":      source.Sheet,
+		"encoding":   source.Encoding,
+		"dir":        source.Dir,
+		"utime":      time.Now().UnixMilli(),
+	}}
+	_, err := d.db.Collection(ImportSourceCollection).UpdateOne(ctx, bson.M{"id": source.ID}, update)
+	return err
+}
+
+func (d *importSourceDAO) GetByID(ctx context.Context, id int64) (domain.BillImportSource, error) {
+	var source domain.BillImportSource
+	err := d.db.Collection(ImportSourceCollection).FindOne(ctx, bson.M{"id": id}).Decode(&source)
+	return source, err
+}
+
+func (d *importSourceDAO) List(ctx context.Context, tenantID string) ([]domain.BillImportSource, error) {
+	query := bson.M{}
+	if tenantID != "" {
+		query["tenant_id"] = tenantID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "id", Value: 1}})
+	cursor, err := d.db.Collection(ImportSourceCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sources []domain.BillImportSource
+	if err := cursor.All(ctx, &sources); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func (d *importSourceDAO) Delete(ctx context.Context, id int64) error {
+	_, err := d.db.Collection(ImportSourceCollection).DeleteOne(ctx, bson.M{"id": id})
+	return err
+}
@@ -28,6 +28,9 @@ func InitCostIndexes(db *mongox.Mongo) error {
 	if err := initBudgetIndexes(ctx, db); err != nil {
 		return err
 	}
+	if err := initBudgetHistoryIndexes(ctx, db); err != nil {
+		return err
+	}
 	if err := initAllocationIndexes(ctx, db); err != nil {
 		return err
 	}
@@ -40,6 +43,39 @@ func InitCostIndexes(db *mongox.Mongo) error {
 	if err := initRecommendationIndexes(ctx, db); err != nil {
 		return err
 	}
+	if err := initExchangeRateIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initCostSettingsIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initAnomalyModelIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initCommitmentIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initMetricsIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initImportSourceIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initReconcileIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initChargebackIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initUsageIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initBusinessMetricIndexes(ctx, db); err != nil {
+		return err
+	}
+	if err := initK8sMetricsIndexes(ctx, db); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -173,6 +209,13 @@ func initCollectLogIndexes(ctx context.Context, db *mongox.Mongo) error {
 				{Key: "ctime", Value: -1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "account_id", Value: 1},
+				{Key: "checksum", Value: 1},
+			},
+			Options: options.Index().SetSparse(true),
+		},
 	}
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
 	return err
@@ -199,6 +242,27 @@ func initBudgetIndexes(ctx context.Context, db *mongox.Mongo) error {
 	return err
 }
 
+// initBudgetHistoryIndexes 初始化预算周期历史集合索引
+func initBudgetHistoryIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(BudgetHistoryCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "budget_id", Value: 1},
+				{Key: "period_start", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+			},
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
 // initAllocationIndexes 初始化成本分摊结果集合索引
 func initAllocationIndexes(ctx context.Context, db *mongox.Mongo) error {
 	collection := db.Collection(AllocationCollection)
@@ -306,3 +370,255 @@ func initRecommendationIndexes(ctx context.Context, db *mongox.Mongo) error {
 	_, err := collection.Indexes().CreateMany(ctx, indexes)
 	return err
 }
+
+// initExchangeRateIndexes 初始化汇率集合索引
+func initExchangeRateIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(ExchangeRateCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "base_currency", Value: 1},
+				{Key: "quote_currency", Value: 1},
+				{Key: "date", Value: -1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initCostSettingsIndexes 初始化租户成本设置集合索引
+func initCostSettingsIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(CostSettingsCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initAnomalyModelIndexes 初始化异常检测模型配置集合索引
+func initAnomalyModelIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(AnomalyModelCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "dimension", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initCommitmentIndexes 初始化承诺消费与覆盖率集合索引
+func initCommitmentIndexes(ctx context.Context, db *mongox.Mongo) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "provider", Value: 1},
+				{Key: "commitment_id", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "end_time", Value: 1},
+			},
+		},
+	}
+	if _, err := db.Collection(CommitmentCollection).Indexes().CreateMany(ctx, indexes); err != nil {
+		return err
+	}
+
+	coverageIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "account_id", Value: 1},
+			},
+		},
+	}
+	_, err := db.Collection(CommitmentCoverageCollection).Indexes().CreateMany(ctx, coverageIndexes)
+	return err
+}
+
+// initMetricsIndexes 初始化资源监控指标缓存集合索引
+// expire_at 为 TTL 索引，到期文档由 MongoDB 后台自动删除
+func initMetricsIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(ResourceMetricDailyCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "resource_id", Value: 1},
+				{Key: "date", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "account_id", Value: 1},
+				{Key: "date", Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "expire_at", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initImportSourceIndexes 初始化账单导入来源集合索引
+func initImportSourceIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(ImportSourceCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "tenant_id", Value: 1}},
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initReconcileIndexes 初始化账单对账集合索引
+func initReconcileIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(ReconcileCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "account_id", Value: 1},
+				{Key: "billing_month", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "billing_month", Value: -1},
+			},
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initChargebackIndexes 初始化成本分账单集合索引
+func initChargebackIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(ChargebackCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "period", Value: 1},
+				{Key: "target_type", Value: 1},
+				{Key: "target_id", Value: 1},
+				{Key: "version", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "period", Value: -1},
+				{Key: "latest", Value: 1},
+			},
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initUsageIndexes 初始化分摊用量记录与权重快照集合索引
+func initUsageIndexes(ctx context.Context, db *mongox.Mongo) error {
+	_, err := db.Collection(UsageRecordCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "source", Value: 1},
+				{Key: "subject", Value: 1},
+				{Key: "period", Value: 1},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection(AllocationWeightsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "period", Value: 1},
+				{Key: "rule_id", Value: 1},
+			},
+		},
+	})
+	return err
+}
+
+// initBusinessMetricIndexes 初始化业务指标集合索引
+func initBusinessMetricIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(BusinessMetricCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "metric", Value: 1},
+				{Key: "node_id", Value: 1},
+				{Key: "date", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "date", Value: 1},
+				{Key: "tenant_id", Value: 1},
+			},
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
+
+// initK8sMetricsIndexes 初始化 K8s 节点指标集合索引
+func initK8sMetricsIndexes(ctx context.Context, db *mongox.Mongo) error {
+	collection := db.Collection(K8sMetricsCollection)
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "period", Value: 1},
+				{Key: "cluster", Value: 1},
+				{Key: "node_name", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err := collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
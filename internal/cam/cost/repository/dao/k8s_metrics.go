@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const K8sMetricsCollection = "ecam_cost_k8s_node_metrics"
+
+type k8sMetricsDAO struct {
+	db *mongox.Mongo
+}
+
+// NewK8sMetricsDAO 创建 K8s 节点指标 DAO
+func NewK8sMetricsDAO(db *mongox.Mongo) repository.K8sMetricsDAO {
+	return &k8sMetricsDAO{db: db}
+}
+
+func (d *k8sMetricsDAO) UpsertNodes(ctx context.Context, nodes []domain.K8sNodeMetrics) (int64, error) {
+	if len(nodes) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	models := make([]mongo.WriteModel, 0, len(nodes))
+	for _, n := range nodes {
+		filter := bson.M{"tenant_id": n.TenantID, "period": n.Period, "cluster": n.Cluster, "node_name": n.NodeName}
+		update := bson.M{
+			"$set": bson.M{
+				"resource_id":     n.ResourceID,
+				"cpu_capacity":    n.CPUCapacity,
+				"memory_capacity": n.MemoryCapacity,
+				"pods":            n.Pods,
+				"utime":           now,
+			},
+			"$setOnInsert": bson.M{
+				"id":    d.db.GetIdGenerator(K8sMetricsCollection),
+				"ctime": now,
+			},
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	result, err := d.db.Collection(K8sMetricsCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+	return result.UpsertedCount + result.ModifiedCount, nil
+}
+
+func (d *k8sMetricsDAO) ListNodes(ctx context.Context, tenantID, period string) ([]domain.K8sNodeMetrics, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "cluster", Value: 1}, {Key: "node_name", Value: 1}})
+	cursor, err := d.db.Collection(K8sMetricsCollection).Find(ctx, bson.M{"tenant_id": tenantID, "period": period}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var nodes []domain.K8sNodeMetrics
+	err = cursor.All(ctx, &nodes)
+	return nodes, err
+}
@@ -0,0 +1,91 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ResourceMetricDailyCollection = "ecam_cost_resource_metric_daily"
+
+type metricsDAO struct {
+	db *mongox.Mongo
+}
+
+// NewMetricsDAO 创建资源监控指标缓存 DAO
+func NewMetricsDAO(db *mongox.Mongo) repository.MetricsDAO {
+	return &metricsDAO{db: db}
+}
+
+func (d *metricsDAO) UpsertDaily(ctx context.Context, items []domain.ResourceMetricDaily) (int64, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	now := time.Now().UnixMilli()
+	models := make([]mongo.WriteModel, 0, len(items))
+	for _, item := range items {
+		filter := bson.M{"resource_id": item.ResourceID, "date": item.Date}
+		update := bson.M{
+			"$set": bson.M{
+				"tenant_id":       item.TenantID,
+				"account_id":      item.AccountID,
+				"provider":        item.Provider,
+				"region":          item.Region,
+				"resource_type":   item.ResourceType,
+				"cpu_avg":         item.CPUAvg,
+				"cpu_max":         item.CPUMax,
+				"memory_avg":      item.MemoryAvg,
+				"memory_max":      item.MemoryMax,
+				"has_memory":      item.HasMemory,
+				"network_in_bps":  item.NetworkInBps,
+				"network_out_bps": item.NetworkOutBps,
+				"disk_read_iops":  item.DiskReadIOPS,
+				"disk_write_iops": item.DiskWriteIOPS,
+				"expire_at":       item.ExpireAt,
+				"sync_time":       now,
+			},
+			"$setOnInsert": bson.M{
+				"id":    d.db.GetIdGenerator(ResourceMetricDailyCollection),
+				"ctime": now,
+			},
+		}
+		models = append(models, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update).SetUpsert(true))
+	}
+
+	result, err := d.db.Collection(ResourceMetricDailyCollection).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, err
+	}
+	return result.UpsertedCount + result.ModifiedCount, nil
+}
+
+func (d *metricsDAO) ListDaily(ctx context.Context, resourceID string, startDate, endDate string) ([]domain.ResourceMetricDaily, error) {
+	query := bson.M{"resource_id": resourceID}
+	dateRange := bson.M{}
+	if startDate != "" {
+		dateRange["$gte"] = startDate
+	}
+	if endDate != "" {
+		dateRange["$lte"] = endDate
+	}
+	if len(dateRange) > 0 {
+		query["date"] = dateRange
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}})
+	cursor, err := d.db.Collection(ResourceMetricDailyCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []domain.ResourceMetricDaily
+	err = cursor.All(ctx, &items)
+	return items, err
+}
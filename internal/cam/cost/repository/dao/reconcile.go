@@ -0,0 +1,125 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ReconcileCollection = "ecam_cost_reconcile"
+
+type reconcileDAO struct {
+	db *mongox.Mongo
+}
+
+// NewReconcileDAO 创建账单对账 DAO
+func NewReconcileDAO(db *mongox.Mongo) repository.ReconcileDAO {
+	return &reconcileDAO{db: db}
+}
+
+func (d *reconcileDAO) Upsert(ctx context.Context, rec domain.BillReconciliation) (domain.BillReconciliation, error) {
+	now := time.Now().UnixMilli()
+	filter := bson.M{"account_id": rec.AccountID, "billing_month": rec.BillingMonth}
+	update := bson.M{
+		"$set": bson.M{
+			"account_name":   rec.AccountName,
+			"provider":       rec.Provider,
+			"detail_amount":  rec.DetailAmount,
+			"invoice_amount": rec.InvoiceAmount,
+			"drift":          rec.Drift,
+			"drift_pct":      rec.DriftPct,
+			"currency":       rec.Currency,
+			"status":         rec.Status,
+			"error_msg":      rec.ErrorMsg,
+			"tenant_id":      rec.TenantID,
+			"check_time":     now,
+		},
+		"$setOnInsert": bson.M{
+			"id":              d.db.GetIdGenerator(ReconcileCollection),
+			"alerted":         false,
+			"recollect_count": 0,
+			"ctime":           now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var saved domain.BillReconciliation
+	err := d.db.Collection(ReconcileCollection).FindOneAndUpdate(ctx, filter, update, opts).Decode(&saved)
+	return saved, err
+}
+
+func (d *reconcileDAO) Get(ctx context.Context, accountID int64, billingMonth string) (domain.BillReconciliation, error) {
+	var rec domain.BillReconciliation
+	err := d.db.Collection(ReconcileCollection).FindOne(ctx,
+		bson.M{"account_id": accountID, "billing_month": billingMonth}).Decode(&rec)
+	return rec, err
+}
+
+func (d *reconcileDAO) List(ctx context.Context, filter repository.ReconcileFilter) ([]domain.BillReconciliation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "billing_month", Value: -1}, {Key: "account_id", Value: 1}})
+	if filter.Offset > 0 {
+		opts.SetSkip(filter.Offset)
+	}
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cursor, err := d.db.Collection(ReconcileCollection).Find(ctx, d.buildQuery(filter), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var recs []domain.BillReconciliation
+	err = cursor.All(ctx, &recs)
+	return recs, err
+}
+
+func (d *reconcileDAO) Count(ctx context.Context, filter repository.ReconcileFilter) (int64, error) {
+	return d.db.Collection(ReconcileCollection).CountDocuments(ctx, d.buildQuery(filter))
+}
+
+func (d *reconcileDAO) SetAlerted(ctx context.Context, id int64, alerted bool) error {
+	return d.updateByID(ctx, id, bson.M{"$set": bson.M{"alerted": alerted}})
+}
+
+func (d *reconcileDAO) IncrRecollect(ctx context.Context, id int64) error {
+	return d.updateByID(ctx, id, bson.M{"$inc": bson.M{"recollect_count": 1}})
+}
+
+func (d *reconcileDAO) updateByID(ctx context.Context, id int64, update bson.M) error {
+	result, err := d.db.Collection(ReconcileCollection).UpdateOne(ctx, bson.M{"id": id}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (d *reconcileDAO) buildQuery(filter repository.ReconcileFilter) bson.M {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.Provider != "" {
+		query["provider"] = filter.Provider
+	}
+	if filter.AccountID > 0 {
+		query["account_id"] = filter.AccountID
+	}
+	if filter.BillingMonth != "" {
+		query["billing_month"] = filter.BillingMonth
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	return query
+}
@@ -0,0 +1,45 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const CostSettingsCollection = "ecam_cost_settings"
+
+type costSettingsDAO struct {
+	db *mongox.Mongo
+}
+
+// NewCostSettingsDAO 创建租户成本设置 DAO
+func NewCostSettingsDAO(db *mongox.Mongo) repository.CostSettingsDAO {
+	return &costSettingsDAO{db: db}
+}
+
+func (d *costSettingsDAO) Get(ctx context.Context, tenantID string) (domain.CostSettings, error) {
+	var settings domain.CostSettings
+	err := d.db.Collection(CostSettingsCollection).FindOne(ctx, bson.M{"tenant_id": tenantID}).Decode(&settings)
+	return settings, err
+}
+
+func (d *costSettingsDAO) Upsert(ctx context.Context, settings domain.CostSettings) error {
+	now := time.Now().UnixMilli()
+	update := bson.M{
+		"$set": bson.M{
+			"reporting_currency": settings.ReportingCurrency,
+			"utime":              now,
+		},
+		"$setOnInsert": bson.M{
+			"ctime": now,
+		},
+	}
+	_, err := d.db.Collection(CostSettingsCollection).UpdateOne(ctx,
+		bson.M{"tenant_id": settings.TenantID}, update, options.Update().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,122 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/Havens-blog/e-cam-service/pkg/mongox"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	UsageRecordCollection       = "ecam_cost_usage_record"
+	AllocationWeightsCollection = "ecam_cost_allocation_weights"
+)
+
+type usageDAO struct {
+	db *mongox.Mongo
+}
+
+// NewUsageDAO 创建分摊用量 DAO
+func NewUsageDAO(db *mongox.Mongo) repository.UsageDAO {
+	return &usageDAO{db: db}
+}
+
+func (d *usageDAO) ReplaceRecords(ctx context.Context, tenantID, source, subject, period string, records []domain.UsageRecord) (int64, error) {
+	coll := d.db.Collection(UsageRecordCollection)
+	_, err := coll.DeleteMany(ctx, bson.M{
+		"tenant_id": tenantID,
+		"source":    source,
+		"subject":   subject,
+		"period":    period,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now().UnixMilli()
+	docs := make([]interface{}, len(records))
+	for i := range records {
+		records[i].ID = d.db.GetIdGenerator(UsageRecordCollection)
+		records[i].CreateTime = now
+		docs[i] = records[i]
+	}
+	result, err := coll.InsertMany(ctx, docs)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(result.InsertedIDs)), nil
+}
+
+func (d *usageDAO) ListRecords(ctx context.Context, filter repository.UsageRecordFilter) ([]domain.UsageRecord, error) {
+	query := bson.M{}
+	if filter.TenantID != "" {
+		query["tenant_id"] = filter.TenantID
+	}
+	if filter.Source != "" {
+		query["source"] = filter.Source
+	}
+	if filter.Subject != "" {
+		query["subject"] = filter.Subject
+	}
+	if filter.Metric != "" {
+		query["metric"] = filter.Metric
+	}
+	if filter.Period != "" {
+		query["period"] = filter.Period
+	}
+
+	cursor, err := d.db.Collection(UsageRecordCollection).Find(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []domain.UsageRecord
+	err = cursor.All(ctx, &records)
+	return records, err
+}
+
+func (d *usageDAO) ReplaceWeights(ctx context.Context, tenantID, period string, weights []domain.AllocationUsageWeights) error {
+	coll := d.db.Collection(AllocationWeightsCollection)
+	if _, err := coll.DeleteMany(ctx, bson.M{"tenant_id": tenantID, "period": period}); err != nil {
+		return err
+	}
+	if len(weights) == 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	docs := make([]interface{}, len(weights))
+	for i := range weights {
+		weights[i].ID = d.db.GetIdGenerator(AllocationWeightsCollection)
+		weights[i].CreateTime = now
+		docs[i] = weights[i]
+	}
+	_, err := coll.InsertMany(ctx, docs)
+	return err
+}
+
+func (d *usageDAO) ListWeights(ctx context.Context, tenantID, period string, ruleID int64) ([]domain.AllocationUsageWeights, error) {
+	query := bson.M{"tenant_id": tenantID, "period": period}
+	if ruleID != 0 {
+		query["rule_id"] = ruleID
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "rule_id", Value: 1}})
+
+	cursor, err := d.db.Collection(AllocationWeightsCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var weights []domain.AllocationUsageWeights
+	err = cursor.All(ctx, &weights)
+	return weights, err
+}
@@ -48,6 +48,20 @@ type BillDAO interface {
 	DeleteUnifiedBillsByAccountAndMonth(ctx context.Context, accountID int64, month string) (int64, error)
 	// AggregateByTag 按标签 key 聚合统一账单金额（展开 tags map）
 	AggregateByTag(ctx context.Context, tenantID string, startDate, endDate string) ([]AggregateResult, error)
+	// UpdateUnifiedBillAmountCNY 批量更新统一账单的人民币金额与汇率（汇率修正后重算）
+	UpdateUnifiedBillAmountCNY(ctx context.Context, updates []AmountCNYUpdate) (int64, error)
+	// AggregateByFieldDaily 按指定字段和账单日期聚合统一账单金额（用于按日汇率折算报表币种）
+	AggregateByFieldDaily(ctx context.Context, tenantID string, field string, startDate, endDate string, filter UnifiedBillFilter) ([]FieldDailyAmount, error)
+	// AggregateBreakdownDaily 在 matchField = matchValue 的账单中按 groupField 和账单日期聚合（异常根因下钻）
+	// groupField 为 "tags" 时展开标签，Key 为 "key=value"
+	AggregateBreakdownDaily(ctx context.Context, tenantID, matchField, matchValue, groupField, startDate, endDate string) ([]FieldDailyAmount, error)
+}
+
+// AmountCNYUpdate 统一账单人民币金额更新
+type AmountCNYUpdate struct {
+	ID           int64
+	AmountCNY    float64
+	ExchangeRate float64
 }
 
 // UnifiedBillFilter 统一账单筛选条件
@@ -57,9 +71,12 @@ type UnifiedBillFilter struct {
 	AccountID   int64
 	ServiceType string
 	Region      string
+	Currency    string
 	StartDate   string // YYYY-MM-DD
 	EndDate     string // YYYY-MM-DD
 	ResourceID  string
+	Tags        map[string]string // 标签条件，全部匹配
+	View        string            // 成本口径：billed（默认）/ amortized / net
 	Offset      int64
 	Limit       int64
 }
@@ -78,6 +95,14 @@ type DailyAmount struct {
 	AmountCNY float64 `bson:"amount_cny" json:"amount_cny"`
 }
 
+// FieldDailyAmount 按字段和日期聚合结果
+type FieldDailyAmount struct {
+	Key       string  `bson:"key" json:"key"`
+	Date      string  `bson:"date" json:"date"`
+	Amount    float64 `bson:"amount" json:"amount"`
+	AmountCNY float64 `bson:"amount_cny" json:"amount_cny"`
+}
+
 // CollectLogDAO 采集日志数据访问接口
 type CollectLogDAO interface {
 	// Create 创建采集日志
@@ -94,6 +119,8 @@ type CollectLogDAO interface {
 	List(ctx context.Context, filter CollectLogFilter) ([]domain.CollectLog, error)
 	// Count 统计采集日志数量
 	Count(ctx context.Context, filter CollectLogFilter) (int64, error)
+	// GetSuccessByChecksum 获取账号导入同一文件（按 SHA-256）的成功日志
+	GetSuccessByChecksum(ctx context.Context, accountID int64, checksum string) (domain.CollectLog, error)
 }
 
 // CollectLogFilter 采集日志筛选条件
@@ -101,10 +128,25 @@ type CollectLogFilter struct {
 	TenantID  string
 	AccountID int64
 	Status    string
+	Source    string // api / import，为空不限
 	Offset    int64
 	Limit     int64
 }
 
+// ImportSourceDAO 账单导入来源数据访问接口
+type ImportSourceDAO interface {
+	// Create 创建导入来源
+	Create(ctx context.Context, source domain.BillImportSource) (int64, error)
+	// Update 更新导入来源
+	Update(ctx context.Context, source domain.BillImportSource) error
+	// GetByID 根据 ID 获取导入来源
+	GetByID(ctx context.Context, id int64) (domain.BillImportSource, error)
+	// List 查询导入来源，tenantID 为空时返回全部
+	List(ctx context.Context, tenantID string) ([]domain.BillImportSource, error)
+	// Delete 删除导入来源
+	Delete(ctx context.Context, id int64) error
+}
+
 // BudgetDAO 预算规则数据访问接口
 type BudgetDAO interface {
 	// Create 创建预算规则
@@ -123,10 +165,22 @@ type BudgetDAO interface {
 	UpdateStatus(ctx context.Context, id int64, status string) error
 	// UpdateNotifiedAt 更新阈值通知时间
 	UpdateNotifiedAt(ctx context.Context, id int64, notifiedAt map[string]time.Time) error
+	// UpdatePeriod 切换当前跟踪周期，同时更新滚入金额与阈值通知记录
+	UpdatePeriod(ctx context.Context, id int64, periodStart string, rolloverAmount float64, notifiedAt map[string]time.Time) error
 	// Delete 删除预算规则
 	Delete(ctx context.Context, id int64) error
 }
 
+// BudgetHistoryDAO 预算周期历史数据访问接口
+type BudgetHistoryDAO interface {
+	// Upsert 按 (budget_id, period_start) 写入已结束周期，重复结算同一周期时覆盖为最新结果
+	Upsert(ctx context.Context, record domain.BudgetPeriodRecord) error
+	// ListByBudget 按周期倒序查询预算历史
+	ListByBudget(ctx context.Context, budgetID int64, offset, limit int64) ([]domain.BudgetPeriodRecord, error)
+	// CountByBudget 统计预算历史周期数
+	CountByBudget(ctx context.Context, budgetID int64) (int64, error)
+}
+
 // BudgetFilter 预算规则筛选条件
 type BudgetFilter struct {
 	TenantID  string
@@ -188,6 +242,54 @@ type AllocationFilter struct {
 	Limit    int64
 }
 
+// K8sMetricsDAO K8s 节点资源指标数据访问接口
+type K8sMetricsDAO interface {
+	// UpsertNodes 按 (租户, 账期, 集群, 节点) 覆盖写入节点指标
+	UpsertNodes(ctx context.Context, nodes []domain.K8sNodeMetrics) (int64, error)
+	// ListNodes 查询租户账期内的全部节点指标
+	ListNodes(ctx context.Context, tenantID, period string) ([]domain.K8sNodeMetrics, error)
+}
+
+// UsageDAO 分摊用量数据访问接口（用量记录与用量加权权重快照）
+type UsageDAO interface {
+	// ReplaceRecords 替换租户某数据源、共享对象与账期下的全部用量记录
+	ReplaceRecords(ctx context.Context, tenantID, source, subject, period string, records []domain.UsageRecord) (int64, error)
+	// ListRecords 按筛选条件查询用量记录
+	ListRecords(ctx context.Context, filter UsageRecordFilter) ([]domain.UsageRecord, error)
+	// ReplaceWeights 替换租户某账期的权重快照（每次分摊重新计算）
+	ReplaceWeights(ctx context.Context, tenantID, period string, weights []domain.AllocationUsageWeights) error
+	// ListWeights 查询租户某账期的权重快照，ruleID 为 0 时不过滤
+	ListWeights(ctx context.Context, tenantID, period string, ruleID int64) ([]domain.AllocationUsageWeights, error)
+}
+
+// UsageRecordFilter 用量记录筛选条件
+type UsageRecordFilter struct {
+	TenantID string
+	Source   string
+	Subject  string
+	Metric   string
+	Period   string
+}
+
+// BusinessMetricDAO 业务指标数据访问接口
+type BusinessMetricDAO interface {
+	// Upsert 按 (租户, 指标, 节点, 日期) 写入业务指标，已存在时覆盖
+	Upsert(ctx context.Context, points []domain.BusinessMetricPoint) (int64, error)
+	// List 按筛选条件查询业务指标（按日期升序）
+	List(ctx context.Context, filter BusinessMetricFilter) ([]domain.BusinessMetricPoint, error)
+	// ListMetricNames 查询租户已上报的指标名
+	ListMetricNames(ctx context.Context, tenantID string) ([]string, error)
+}
+
+// BusinessMetricFilter 业务指标筛选条件
+type BusinessMetricFilter struct {
+	TenantID  string
+	Metric    string
+	NodeIDs   []int64
+	StartDate string
+	EndDate   string
+}
+
 // AnomalyDAO 异常事件数据访问接口
 type AnomalyDAO interface {
 	// Create 创建异常事件
@@ -202,6 +304,16 @@ type AnomalyDAO interface {
 	Count(ctx context.Context, filter AnomalyFilter) (int64, error)
 }
 
+// AnomalyModelConfigDAO 异常检测模型配置数据访问接口
+type AnomalyModelConfigDAO interface {
+	// ListByTenant 查询租户的全部模型配置
+	ListByTenant(ctx context.Context, tenantID string) ([]domain.AnomalyModelConfig, error)
+	// Upsert 按租户 + 维度创建或更新模型配置
+	Upsert(ctx context.Context, cfg domain.AnomalyModelConfig) error
+	// Delete 删除租户指定维度的模型配置
+	Delete(ctx context.Context, tenantID, dimension string) error
+}
+
 // AnomalyFilter 异常事件筛选条件
 type AnomalyFilter struct {
 	TenantID  string
@@ -242,3 +354,130 @@ type RecommendationFilter struct {
 	Offset         int64
 	Limit          int64
 }
+
+// ExchangeRateDAO 汇率数据访问接口
+type ExchangeRateDAO interface {
+	// Upsert 按币种对和日期写入汇率（已存在则覆盖）
+	Upsert(ctx context.Context, rate domain.ExchangeRate) error
+	// UpsertBatch 批量写入汇率
+	UpsertBatch(ctx context.Context, rates []domain.ExchangeRate) (int64, error)
+	// FindEffective 获取指定日期生效的汇率（不晚于该日期的最近一条）
+	FindEffective(ctx context.Context, base, quote, date string) (domain.ExchangeRate, error)
+	// List 按筛选条件查询汇率
+	List(ctx context.Context, filter ExchangeRateFilter) ([]domain.ExchangeRate, error)
+	// Count 统计汇率数量
+	Count(ctx context.Context, filter ExchangeRateFilter) (int64, error)
+	// Delete 删除汇率
+	Delete(ctx context.Context, id int64) error
+}
+
+// ExchangeRateFilter 汇率筛选条件
+type ExchangeRateFilter struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	Source        string
+	StartDate     string // YYYY-MM-DD
+	EndDate       string // YYYY-MM-DD
+	Offset        int64
+	Limit         int64
+}
+
+// CostSettingsDAO 租户成本设置数据访问接口
+type CostSettingsDAO interface {
+	// Get 获取租户成本设置，不存在时返回 mongo.ErrNoDocuments
+	Get(ctx context.Context, tenantID string) (domain.CostSettings, error)
+	// Upsert 写入租户成本设置
+	Upsert(ctx context.Context, settings domain.CostSettings) error
+}
+
+// CommitmentDAO 承诺消费数据访问接口
+type CommitmentDAO interface {
+	// UpsertCommitments 按 provider + commitment_id 写入承诺消费（已存在则覆盖统计字段，保留到期提醒记录）
+	UpsertCommitments(ctx context.Context, commitments []domain.Commitment) (int64, error)
+	// List 按筛选条件查询承诺消费
+	List(ctx context.Context, filter CommitmentFilter) ([]domain.Commitment, error)
+	// Count 统计承诺消费数量
+	Count(ctx context.Context, filter CommitmentFilter) (int64, error)
+	// MarkExpiryNotified 记录到期提醒发送时间
+	MarkExpiryNotified(ctx context.Context, id int64, at time.Time) error
+	// ReplaceCoverage 整体替换账号的覆盖率数据
+	ReplaceCoverage(ctx context.Context, tenantID string, accountID int64, items []domain.CommitmentCoverage) error
+	// ListCoverage 按筛选条件查询覆盖率数据
+	ListCoverage(ctx context.Context, filter CommitmentFilter) ([]domain.CommitmentCoverage, error)
+}
+
+// CommitmentFilter 承诺消费筛选条件
+type CommitmentFilter struct {
+	TenantID     string
+	Provider     string
+	AccountID    int64
+	Region       string
+	Type         string
+	Status       string
+	ExpireAfter  time.Time // 到期时间不早于（零值不限）
+	ExpireBefore time.Time // 到期时间早于（零值不限）
+	Offset       int64
+	Limit        int64
+}
+
+// ReconcileDAO 账单对账数据访问接口
+type ReconcileDAO interface {
+	// Upsert 按 account_id + billing_month 写入对账结果（保留告警与重新采集记录）
+	Upsert(ctx context.Context, rec domain.BillReconciliation) (domain.BillReconciliation, error)
+	// Get 查询云账号指定账期的对账记录
+	Get(ctx context.Context, accountID int64, billingMonth string) (domain.BillReconciliation, error)
+	// List 按筛选条件查询对账记录，按账期倒序
+	List(ctx context.Context, filter ReconcileFilter) ([]domain.BillReconciliation, error)
+	// Count 统计对账记录数量
+	Count(ctx context.Context, filter ReconcileFilter) (int64, error)
+	// SetAlerted 更新差异告警标记
+	SetAlerted(ctx context.Context, id int64, alerted bool) error
+	// IncrRecollect 重新采集次数加一
+	IncrRecollect(ctx context.Context, id int64) error
+}
+
+// ReconcileFilter 对账记录筛选条件
+type ReconcileFilter struct {
+	TenantID     string
+	Provider     string
+	AccountID    int64
+	BillingMonth string
+	Status       string
+	Offset       int64
+	Limit        int64
+}
+
+// ChargebackDAO 成本分账单数据访问接口
+type ChargebackDAO interface {
+	// Insert 写入新版本分账单，并将同一对象当期的旧版本标记为非最新
+	Insert(ctx context.Context, stmt domain.ChargebackStatement) (int64, error)
+	// GetByID 根据 ID 获取分账单
+	GetByID(ctx context.Context, id int64) (domain.ChargebackStatement, error)
+	// GetLatest 获取对象当期最新版本分账单
+	GetLatest(ctx context.Context, tenantID, period, targetType, targetID string) (domain.ChargebackStatement, error)
+	// List 按筛选条件查询分账单，按账期倒序、金额倒序
+	List(ctx context.Context, filter ChargebackFilter) ([]domain.ChargebackStatement, error)
+	// Count 统计分账单数量
+	Count(ctx context.Context, filter ChargebackFilter) (int64, error)
+	// MarkDelivered 记录分账单投递时间与渠道
+	MarkDelivered(ctx context.Context, id int64, channelIDs []int64, at int64) error
+}
+
+// ChargebackFilter 分账单筛选条件
+type ChargebackFilter struct {
+	TenantID    string
+	Period      string
+	TargetType  string
+	TargetID    string
+	AllVersions bool // 为 false 时仅返回最新版本
+	Offset      int64
+	Limit       int64
+}
+
+// MetricsDAO 资源监控指标缓存数据访问接口
+type MetricsDAO interface {
+	// UpsertDaily 按 resource_id + date 写入日聚合指标（已存在则覆盖）
+	UpsertDaily(ctx context.Context, items []domain.ResourceMetricDaily) (int64, error)
+	// ListDaily 查询资源在日期范围内的日聚合指标，按日期升序
+	ListDaily(ctx context.Context, resourceID string, startDate, endDate string) ([]domain.ResourceMetricDaily, error)
+}
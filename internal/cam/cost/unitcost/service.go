@@ -0,0 +1,517 @@
+// Package unitcost 单位经济：按服务树节点计算单位业务量成本（每订单 / 每活跃用户 / 每次 API 调用）
+package unitcost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/anomaly"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// maxRangeDays 单次查询的最大日期跨度
+	maxRangeDays = 366
+	// defaultProductLineLevel 产品线汇总默认使用的服务树层级（产品）
+	defaultProductLineLevel = 2
+)
+
+// Node 服务树节点
+type Node struct {
+	ID       int64
+	ParentID int64
+	Name     string
+	Level    int
+}
+
+// NodeResolver 服务树节点查询接口（可选注入）
+// 注入后节点单位成本按服务树向下汇总子节点的成本与业务量；未注入时只统计节点自身
+type NodeResolver interface {
+	ListNodes(ctx context.Context, tenantID string) ([]Node, error)
+}
+
+// AnomalyRecorder 异常检测接口，复用成本异常的检测模型配置、异常记录与告警
+type AnomalyRecorder interface {
+	DetectorFor(ctx context.Context, tenantID, dimension string) (anomaly.Detector, error)
+	RecordAnomalies(ctx context.Context, anomalies []domain.CostAnomaly) error
+}
+
+// UnitCostService 单位成本服务
+type UnitCostService struct {
+	metricDAO     repository.BusinessMetricDAO
+	allocationDAO repository.AllocationDAO
+	nodes         NodeResolver
+	anomalies     AnomalyRecorder
+	logger        *elog.Component
+	now           func() time.Time
+}
+
+// NewUnitCostService 创建单位成本服务
+func NewUnitCostService(
+	metricDAO repository.BusinessMetricDAO,
+	allocationDAO repository.AllocationDAO,
+	logger *elog.Component,
+) *UnitCostService {
+	return &UnitCostService{
+		metricDAO:     metricDAO,
+		allocationDAO: allocationDAO,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+// SetNodeResolver 设置服务树节点查询（可选注入）
+func (s *UnitCostService) SetNodeResolver(nodes NodeResolver) {
+	s.nodes = nodes
+}
+
+// SetAnomalyService 设置异常检测（可选注入，未设置时不做单位成本异常检测）
+func (s *UnitCostService) SetAnomalyService(anomalies AnomalyRecorder) {
+	s.anomalies = anomalies
+}
+
+// IngestMetrics 写入业务指标数据点，按 (指标, 节点, 日期) 覆盖已有值
+func (s *UnitCostService) IngestMetrics(ctx context.Context, tenantID string, points []domain.BusinessMetricPoint) (int64, error) {
+	if len(points) == 0 {
+		return 0, fmt.Errorf("%w: no metric points", domain.ErrUnitCostInvalid)
+	}
+	for i := range points {
+		p := &points[i]
+		p.Metric = strings.TrimSpace(p.Metric)
+		if p.Metric == "" {
+			return 0, fmt.Errorf("%w: point %d: metric is required", domain.ErrUnitCostInvalid, i+1)
+		}
+		if p.NodeID <= 0 {
+			return 0, fmt.Errorf("%w: point %d: node_id is required", domain.ErrUnitCostInvalid, i+1)
+		}
+		if _, err := time.Parse("2006-01-02", p.Date); err != nil {
+			return 0, fmt.Errorf("%w: point %d: invalid date %q", domain.ErrUnitCostInvalid, i+1, p.Date)
+		}
+		if p.Value < 0 {
+			return 0, fmt.Errorf("%w: point %d: negative value", domain.ErrUnitCostInvalid, i+1)
+		}
+		p.TenantID = tenantID
+	}
+
+	n, err := s.metricDAO.Upsert(ctx, points)
+	if err != nil {
+		return 0, fmt.Errorf("save metric points: %w", err)
+	}
+	return n, nil
+}
+
+// ListMetricNames 列出租户已上报的业务指标名
+func (s *UnitCostService) ListMetricNames(ctx context.Context, tenantID string) ([]string, error) {
+	return s.metricDAO.ListMetricNames(ctx, tenantID)
+}
+
+// GetUnitCostTrend 查询节点（含子节点）在日期区间内的逐日单位成本
+func (s *UnitCostService) GetUnitCostTrend(ctx context.Context, tenantID, metric string, nodeID int64, startDate, endDate string) (*domain.UnitCostTrend, error) {
+	if nodeID <= 0 {
+		return nil, fmt.Errorf("%w: node_id is required", domain.ErrUnitCostInvalid)
+	}
+	start, end, err := parseRange(metric, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := s.loadTree(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := s.dailyNodeCosts(ctx, tenantID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.dailyNodeMetrics(ctx, tenantID, metric, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	trend := buildTrend(nodeID, tree.name(nodeID), metric, tree.subtree(nodeID), costs, values, start, end)
+	return &trend, nil
+}
+
+// ListProductLineUnitCosts 按服务树层级（默认产品层）汇总各产品线的单位成本趋势，按区间单位成本降序
+func (s *UnitCostService) ListProductLineUnitCosts(ctx context.Context, tenantID, metric string, level int, startDate, endDate string) ([]domain.UnitCostTrend, error) {
+	if s.nodes == nil {
+		return nil, fmt.Errorf("service tree is not configured")
+	}
+	if level <= 0 {
+		level = defaultProductLineLevel
+	}
+	start, end, err := parseRange(metric, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := s.loadTree(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	costs, err := s.dailyNodeCosts(ctx, tenantID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.dailyNodeMetrics(ctx, tenantID, metric, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var trends []domain.UnitCostTrend
+	for _, node := range tree.nodes {
+		if node.Level != level {
+			continue
+		}
+		trend := buildTrend(node.ID, node.Name, metric, tree.subtree(node.ID), costs, values, start, end)
+		if trend.TotalCost == 0 && trend.TotalMetric == 0 {
+			continue
+		}
+		trends = append(trends, trend)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].UnitCost != trends[j].UnitCost {
+			return trends[i].UnitCost > trends[j].UnitCost
+		}
+		return trends[i].NodeID < trends[j].NodeID
+	})
+	return trends, nil
+}
+
+// DetectUnitCostAnomalies 对指定日期有业务指标上报的节点检测单位成本异常，tenantID 为空时检测全部租户
+// 以单位成本而非原始支出判定：业务量同比例增长带来的成本上涨不视为异常
+func (s *UnitCostService) DetectUnitCostAnomalies(ctx context.Context, tenantID, date string) error {
+	if s.anomalies == nil {
+		return nil
+	}
+	target, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return fmt.Errorf("%w: invalid date %q", domain.ErrUnitCostInvalid, date)
+	}
+
+	points, err := s.metricDAO.List(ctx, repository.BusinessMetricFilter{TenantID: tenantID, StartDate: date, EndDate: date})
+	if err != nil {
+		return fmt.Errorf("list metric points: %w", err)
+	}
+	byTenant := make(map[string][]domain.BusinessMetricPoint)
+	for _, p := range points {
+		byTenant[p.TenantID] = append(byTenant[p.TenantID], p)
+	}
+	tenants := make([]string, 0, len(byTenant))
+	for t := range byTenant {
+		tenants = append(tenants, t)
+	}
+	sort.Strings(tenants)
+
+	for _, t := range tenants {
+		if err := s.detectTenant(ctx, t, target, byTenant[t]); err != nil {
+			s.logger.Error("detect unit cost anomalies failed",
+				elog.String("tenant_id", t),
+				elog.String("date", date),
+				elog.FieldErr(err))
+		}
+	}
+	return nil
+}
+
+// detectTenant 检测单个租户当日上报节点的单位成本，只读取已有分摊结果，不触发重新分摊
+func (s *UnitCostService) detectTenant(ctx context.Context, tenantID string, target time.Time, points []domain.BusinessMetricPoint) error {
+	detector, err := s.anomalies.DetectorFor(ctx, tenantID, anomaly.DimensionUnitCost)
+	if err != nil {
+		return err
+	}
+	start := target.AddDate(0, 0, -detector.WindowDays())
+
+	tree, err := s.loadTree(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	costs, err := s.dailyNodeCosts(ctx, tenantID, start, target)
+	if err != nil {
+		return err
+	}
+	date := target.Format("2006-01-02")
+	if costs.undated[target.Format("2006-01")] {
+		s.logger.Warn("allocations without billing date, skip unit cost detection",
+			elog.String("tenant_id", tenantID),
+			elog.String("date", date))
+		return nil
+	}
+
+	type subject struct {
+		metric string
+		nodeID int64
+	}
+	seen := make(map[subject]bool)
+	values := make(map[string]map[int64]map[string]float64)
+
+	var found []domain.CostAnomaly
+	for _, p := range points {
+		key := subject{p.Metric, p.NodeID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if _, ok := values[p.Metric]; !ok {
+			v, err := s.dailyNodeMetrics(ctx, tenantID, p.Metric, start, target)
+			if err != nil {
+				return err
+			}
+			values[p.Metric] = v
+		}
+
+		name := tree.name(p.NodeID)
+		trend := buildTrend(p.NodeID, name, p.Metric, tree.subtree(p.NodeID), costs, values[p.Metric], start, target)
+		current := trend.Points[len(trend.Points)-1]
+		if current.MetricValue <= 0 || current.Cost <= 0 {
+			continue
+		}
+		// 业务量为 0 的日期单位成本无意义，不纳入历史
+		var history []anomaly.DailyPoint
+		for _, pt := range trend.Points[:len(trend.Points)-1] {
+			if pt.MetricValue <= 0 {
+				continue
+			}
+			d, _ := time.Parse("2006-01-02", pt.Date)
+			history = append(history, anomaly.DailyPoint{Date: d, Amount: pt.UnitCost})
+		}
+
+		verdict := detector.Detect(history, anomaly.DailyPoint{Date: target, Amount: current.UnitCost})
+		if !verdict.Anomalous {
+			continue
+		}
+		found = append(found, domain.CostAnomaly{
+			Dimension:      anomaly.DimensionUnitCost,
+			DimensionValue: p.Metric + "@" + name,
+			AnomalyDate:    date,
+			ActualAmount:   current.UnitCost,
+			BaselineAmount: verdict.Expected,
+			ExpectedLower:  verdict.Lower,
+			ExpectedUpper:  verdict.Upper,
+			Model:          detector.Name(),
+			Currency:       costs.currency,
+			DeviationPct:   verdict.DeviationPct,
+			Severity:       verdict.Severity,
+			PossibleCause: fmt.Sprintf("单位成本突增: %s 每单位 %s 成本 %.4f（当日成本 %.2f / 业务量 %.0f），基线 %.4f，偏离 %.0f%%",
+				name, p.Metric, current.UnitCost, current.Cost, current.MetricValue, verdict.Expected, verdict.DeviationPct),
+			TenantID: tenantID,
+		})
+	}
+
+	return s.anomalies.RecordAnomalies(ctx, found)
+}
+
+// nodeCosts 节点逐日成本
+type nodeCosts struct {
+	byNode   map[int64]map[string]float64 // nodeID -> date -> 金额
+	currency string
+	// undated 存在缺少账单日期的分摊结果（记录账单日期之前生成）的账期，无法按日统计，趋势与检测跳过这些账期
+	undated map[string]bool
+}
+
+// dailyNodeCosts 按节点、账单日期汇总分摊结果，按月出账的账期均摊到各日
+func (s *UnitCostService) dailyNodeCosts(ctx context.Context, tenantID string, start, end time.Time) (nodeCosts, error) {
+	startDate, endDate := start.Format("2006-01-02"), end.Format("2006-01-02")
+	costs := nodeCosts{byNode: make(map[int64]map[string]float64), undated: make(map[string]bool)}
+
+	month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for !month.After(end) {
+		period := month.Format("2006-01")
+		allocs, err := s.allocationDAO.ListAllocations(ctx, repository.AllocationFilter{TenantID: tenantID, Period: period})
+		if err != nil {
+			return nodeCosts{}, fmt.Errorf("list allocations: %w", err)
+		}
+		// 按月出账的账期只有 1 号的账单，均摊到各日后再与逐日业务量相除
+		spreadDays := s.monthStartDays(month, allocs)
+		for _, a := range allocs {
+			if a.NodeID == 0 {
+				continue
+			}
+			if a.BillingDate == "" {
+				costs.undated[period] = true
+				continue
+			}
+			if costs.currency == "" {
+				costs.currency = a.Currency
+			}
+			if spreadDays == 0 {
+				costs.add(a.NodeID, a.BillingDate, a.TotalAmount, startDate, endDate)
+				continue
+			}
+			for i := 0; i < spreadDays; i++ {
+				date := month.AddDate(0, 0, i).Format("2006-01-02")
+				costs.add(a.NodeID, date, a.TotalAmount/float64(spreadDays), startDate, endDate)
+			}
+		}
+		month = month.AddDate(0, 1, 0)
+	}
+	return costs, nil
+}
+
+// add 累加节点在 [startDate, endDate] 区间内某日的成本
+func (c nodeCosts) add(nodeID int64, date string, amount float64, startDate, endDate string) {
+	if date < startDate || date > endDate {
+		return
+	}
+	if c.byNode[nodeID] == nil {
+		c.byNode[nodeID] = make(map[string]float64)
+	}
+	c.byNode[nodeID][date] += amount
+}
+
+// monthStartDays 判断账期是否按月出账（分摊结果的账单日期全部为 1 号），返回月账单需均摊的天数，按日出账返回 0
+// 已结束的账期均摊到整月；当月账单为月初至今的累计，均摊到截至昨日的天数。
+// 当月前两天的数据无法与按日出账区分，仍按日统计
+func (s *UnitCostService) monthStartDays(month time.Time, allocs []domain.CostAllocation) int {
+	dated := false
+	for _, a := range allocs {
+		if a.BillingDate == "" {
+			continue
+		}
+		if !strings.HasSuffix(a.BillingDate, "-01") {
+			return 0
+		}
+		dated = true
+	}
+	if !dated {
+		return 0
+	}
+
+	now := s.now().UTC()
+	next := month.AddDate(0, 1, 0)
+	if !now.Before(next) {
+		return next.AddDate(0, 0, -1).Day()
+	}
+	if now.Before(month) || now.Day() <= 2 {
+		return 0
+	}
+	return now.Day() - 1
+}
+
+// dailyNodeMetrics 查询指标数据点，返回 nodeID -> date -> 业务量
+func (s *UnitCostService) dailyNodeMetrics(ctx context.Context, tenantID, metric string, start, end time.Time) (map[int64]map[string]float64, error) {
+	points, err := s.metricDAO.List(ctx, repository.BusinessMetricFilter{
+		TenantID:  tenantID,
+		Metric:    metric,
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list metric points: %w", err)
+	}
+	values := make(map[int64]map[string]float64)
+	for _, p := range points {
+		if values[p.NodeID] == nil {
+			values[p.NodeID] = make(map[string]float64)
+		}
+		values[p.NodeID][p.Date] += p.Value
+	}
+	return values, nil
+}
+
+// buildTrend 汇总子树的逐日成本与业务量，计算单位成本，无法按日统计的账期不输出数据点
+func buildTrend(nodeID int64, name, metric string, subtree []int64, costs nodeCosts, values map[int64]map[string]float64, start, end time.Time) domain.UnitCostTrend {
+	trend := domain.UnitCostTrend{NodeID: nodeID, NodeName: name, Metric: metric, Currency: costs.currency}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if costs.undated[d.Format("2006-01")] {
+			continue
+		}
+		date := d.Format("2006-01-02")
+		pt := domain.UnitCostPoint{Date: date}
+		for _, id := range subtree {
+			pt.Cost += costs.byNode[id][date]
+			pt.MetricValue += values[id][date]
+		}
+		pt.UnitCost = unitCost(pt.Cost, pt.MetricValue)
+		trend.TotalCost += pt.Cost
+		trend.TotalMetric += pt.MetricValue
+		trend.Points = append(trend.Points, pt)
+	}
+	trend.UnitCost = unitCost(trend.TotalCost, trend.TotalMetric)
+	return trend
+}
+
+func unitCost(cost, value float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	return cost / value
+}
+
+// parseRange 校验指标名与日期区间
+func parseRange(metric, startDate, endDate string) (time.Time, time.Time, error) {
+	if metric == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: metric is required", domain.ErrUnitCostInvalid)
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid start_date %q", domain.ErrUnitCostInvalid, startDate)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid end_date %q", domain.ErrUnitCostInvalid, endDate)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: end_date before start_date", domain.ErrUnitCostInvalid)
+	}
+	if end.Sub(start) >= maxRangeDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: date range exceeds %d days", domain.ErrUnitCostInvalid, maxRangeDays)
+	}
+	return start, end, nil
+}
+
+// nodeTree 服务树节点索引
+type nodeTree struct {
+	nodes    []Node
+	byID     map[int64]Node
+	children map[int64][]int64
+}
+
+func (s *UnitCostService) loadTree(ctx context.Context, tenantID string) (*nodeTree, error) {
+	t := &nodeTree{byID: make(map[int64]Node), children: make(map[int64][]int64)}
+	if s.nodes == nil {
+		return t, nil
+	}
+	nodes, err := s.nodes.ListNodes(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list service tree nodes: %w", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	t.nodes = nodes
+	for _, n := range nodes {
+		t.byID[n.ID] = n
+		if n.ParentID != 0 {
+			t.children[n.ParentID] = append(t.children[n.ParentID], n.ID)
+		}
+	}
+	return t, nil
+}
+
+// subtree 返回节点及其全部后代
+func (t *nodeTree) subtree(root int64) []int64 {
+	ids := []int64{root}
+	seen := map[int64]bool{root: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range t.children[ids[i]] {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+	return ids
+}
+
+func (t *nodeTree) name(id int64) string {
+	if n, ok := t.byID[id]; ok && n.Name != "" {
+		return n.Name
+	}
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,377 @@
+package unitcost
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/anomaly"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/repository"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== Mock AllocationDAO ==========
+
+type mockAllocationDAO struct {
+	repository.AllocationDAO
+	allocs []domain.CostAllocation
+}
+
+func (m *mockAllocationDAO) ListAllocations(_ context.Context, filter repository.AllocationFilter) ([]domain.CostAllocation, error) {
+	var result []domain.CostAllocation
+	for _, a := range m.allocs {
+		if a.TenantID == filter.TenantID && a.Period == filter.Period {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// ========== Mock BusinessMetricDAO ==========
+
+type mockMetricDAO struct {
+	points map[string]domain.BusinessMetricPoint
+}
+
+func newMockMetricDAO() *mockMetricDAO {
+	return &mockMetricDAO{points: make(map[string]domain.BusinessMetricPoint)}
+}
+
+func (m *mockMetricDAO) Upsert(_ context.Context, points []domain.BusinessMetricPoint) (int64, error) {
+	for _, p := range points {
+		m.points[fmt.Sprintf("%s/%s/%d/%s", p.TenantID, p.Metric, p.NodeID, p.Date)] = p
+	}
+	return int64(len(points)), nil
+}
+
+func (m *mockMetricDAO) List(_ context.Context, filter repository.BusinessMetricFilter) ([]domain.BusinessMetricPoint, error) {
+	var result []domain.BusinessMetricPoint
+	for _, p := range m.points {
+		if (filter.TenantID == "" || p.TenantID == filter.TenantID) &&
+			(filter.Metric == "" || p.Metric == filter.Metric) &&
+			p.Date >= filter.StartDate && p.Date <= filter.EndDate {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result, nil
+}
+
+func (m *mockMetricDAO) ListMetricNames(_ context.Context, tenantID string) ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range m.points {
+		if p.TenantID == tenantID && !seen[p.Metric] {
+			seen[p.Metric] = true
+			names = append(names, p.Metric)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ========== Mock 依赖 ==========
+
+type staticNodes []Node
+
+func (n staticNodes) ListNodes(_ context.Context, _ string) ([]Node, error) {
+	return n, nil
+}
+
+type mockRecorder struct {
+	recorded []domain.CostAnomaly
+}
+
+func (m *mockRecorder) DetectorFor(_ context.Context, _, _ string) (anomaly.Detector, error) {
+	return anomaly.NewDetector(domain.AnomalyModelMean, anomaly.ModelParams{ThresholdPct: 50})
+}
+
+func (m *mockRecorder) RecordAnomalies(_ context.Context, anomalies []domain.CostAnomaly) error {
+	m.recorded = append(m.recorded, anomalies...)
+	return nil
+}
+
+// ========== 测试数据 ==========
+
+// 服务树：1 业务线 -> 2、3 产品 -> 4 模块（挂在 2 下）
+var testNodes = staticNodes{
+	{ID: 1, Name: "电商", Level: 1},
+	{ID: 2, ParentID: 1, Name: "交易", Level: 2},
+	{ID: 3, ParentID: 1, Name: "搜索", Level: 2},
+	{ID: 4, ParentID: 2, Name: "下单", Level: 3},
+}
+
+func alloc(nodeID int64, date string, amount float64) domain.CostAllocation {
+	return domain.CostAllocation{
+		NodeID: nodeID, Period: date[:7], BillingDate: date, TotalAmount: amount,
+		Currency: "CNY", DimType: "node", TenantID: "t1",
+	}
+}
+
+func metric(name string, nodeID int64, date string, value float64) domain.BusinessMetricPoint {
+	return domain.BusinessMetricPoint{Metric: name, NodeID: nodeID, Date: date, Value: value, TenantID: "t1"}
+}
+
+func newTestService(allocs []domain.CostAllocation, points []domain.BusinessMetricPoint) (*UnitCostService, *mockMetricDAO) {
+	metricDAO := newMockMetricDAO()
+	_, _ = metricDAO.Upsert(context.Background(), points)
+	svc := NewUnitCostService(metricDAO, &mockAllocationDAO{allocs: allocs}, elog.DefaultLogger)
+	svc.SetNodeResolver(testNodes)
+	// 固定当前时间：9 月为当月且只到 2 号，月初数据按日统计
+	svc.now = func() time.Time { return time.Date(2026, 9, 2, 8, 0, 0, 0, time.UTC) }
+	return svc, metricDAO
+}
+
+// ========== IngestMetrics ==========
+
+func TestIngestMetrics(t *testing.T) {
+	svc, metricDAO := newTestService(nil, nil)
+	ctx := context.Background()
+
+	n, err := svc.IngestMetrics(ctx, "t1", []domain.BusinessMetricPoint{
+		{Metric: " orders ", NodeID: 2, Date: "2026-09-01", Value: 100},
+		{Metric: "orders", NodeID: 3, Date: "2026-09-01", Value: 50, TenantID: "other"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	for _, p := range metricDAO.points {
+		assert.Equal(t, "t1", p.TenantID, "租户以请求上下文为准")
+		assert.Equal(t, "orders", p.Metric)
+	}
+
+	names, err := svc.ListMetricNames(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders"}, names)
+
+	invalid := []domain.BusinessMetricPoint{
+		{Metric: "", NodeID: 2, Date: "2026-09-01"},
+		{Metric: "orders", NodeID: 0, Date: "2026-09-01"},
+		{Metric: "orders", NodeID: 2, Date: "2026/09/01"},
+		{Metric: "orders", NodeID: 2, Date: "2026-09-01", Value: -1},
+	}
+	for _, p := range invalid {
+		_, err := svc.IngestMetrics(ctx, "t1", []domain.BusinessMetricPoint{p})
+		assert.ErrorIs(t, err, domain.ErrUnitCostInvalid)
+	}
+	_, err = svc.IngestMetrics(ctx, "t1", nil)
+	assert.ErrorIs(t, err, domain.ErrUnitCostInvalid)
+}
+
+// ========== GetUnitCostTrend ==========
+
+func TestGetUnitCostTrend_RollsUpSubtree(t *testing.T) {
+	allocs := []domain.CostAllocation{
+		alloc(2, "2026-08-31", 40),
+		alloc(4, "2026-08-31", 60),
+		alloc(4, "2026-09-01", 90),
+		alloc(3, "2026-09-01", 500), // 兄弟节点不计入
+		alloc(0, "2026-09-01", 70),  // 未分摊不计入
+	}
+	points := []domain.BusinessMetricPoint{
+		metric("orders", 2, "2026-08-31", 20),
+		metric("orders", 4, "2026-08-31", 30),
+		metric("orders", 4, "2026-09-01", 30),
+		metric("api_calls", 4, "2026-09-01", 1000),
+	}
+	svc, _ := newTestService(allocs, points)
+
+	trend, err := svc.GetUnitCostTrend(context.Background(), "t1", "orders", 2, "2026-08-31", "2026-09-02")
+	require.NoError(t, err)
+
+	assert.Equal(t, "交易", trend.NodeName)
+	assert.Equal(t, "CNY", trend.Currency)
+	require.Len(t, trend.Points, 3)
+	assert.Equal(t, domain.UnitCostPoint{Date: "2026-08-31", Cost: 100, MetricValue: 50, UnitCost: 2}, trend.Points[0])
+	assert.Equal(t, domain.UnitCostPoint{Date: "2026-09-01", Cost: 90, MetricValue: 30, UnitCost: 3}, trend.Points[1])
+	assert.Equal(t, domain.UnitCostPoint{Date: "2026-09-02"}, trend.Points[2], "无业务量时单位成本为 0")
+	assert.InDelta(t, 190, trend.TotalCost, 1e-9)
+	assert.InDelta(t, 80, trend.TotalMetric, 1e-9)
+	assert.InDelta(t, 2.375, trend.UnitCost, 1e-9)
+}
+
+func TestGetUnitCostTrend_InvalidParams(t *testing.T) {
+	svc, _ := newTestService(nil, nil)
+	ctx := context.Background()
+
+	cases := []struct {
+		metric     string
+		nodeID     int64
+		start, end string
+	}{
+		{"", 2, "2026-09-01", "2026-09-02"},
+		{"orders", 0, "2026-09-01", "2026-09-02"},
+		{"orders", 2, "2026-09", "2026-09-02"},
+		{"orders", 2, "2026-09-03", "2026-09-02"},
+		{"orders", 2, "2025-01-01", "2026-09-02"},
+	}
+	for _, c := range cases {
+		_, err := svc.GetUnitCostTrend(ctx, "t1", c.metric, c.nodeID, c.start, c.end)
+		assert.ErrorIs(t, err, domain.ErrUnitCostInvalid, "%+v", c)
+	}
+}
+
+// ========== ListProductLineUnitCosts ==========
+
+func TestListProductLineUnitCosts(t *testing.T) {
+	allocs := []domain.CostAllocation{
+		alloc(4, "2026-09-01", 100),
+		alloc(3, "2026-09-01", 300),
+	}
+	points := []domain.BusinessMetricPoint{
+		metric("orders", 4, "2026-09-01", 100),
+		metric("orders", 3, "2026-09-01", 100),
+	}
+	svc, _ := newTestService(allocs, points)
+
+	trends, err := svc.ListProductLineUnitCosts(context.Background(), "t1", "orders", 0, "2026-09-01", "2026-09-01")
+	require.NoError(t, err)
+	require.Len(t, trends, 2)
+	assert.Equal(t, int64(3), trends[0].NodeID, "按单位成本降序")
+	assert.InDelta(t, 3, trends[0].UnitCost, 1e-9)
+	assert.Equal(t, int64(2), trends[1].NodeID)
+	assert.InDelta(t, 1, trends[1].UnitCost, 1e-9)
+
+	lines, err := svc.ListProductLineUnitCosts(context.Background(), "t1", "orders", 1, "2026-09-01", "2026-09-01")
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.InDelta(t, 2, lines[0].UnitCost, 1e-9)
+}
+
+// ========== DetectUnitCostAnomalies ==========
+
+func TestDetectUnitCostAnomalies_UnitCostNotRawSpend(t *testing.T) {
+	target := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	var allocs []domain.CostAllocation
+	var points []domain.BusinessMetricPoint
+	for d := target.AddDate(0, 0, -7); d.Before(target); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		allocs = append(allocs, alloc(4, date, 100), alloc(3, date, 100))
+		points = append(points, metric("orders", 4, date, 100), metric("orders", 3, date, 100))
+	}
+	date := target.Format("2006-01-02")
+	// 节点 4：支出翻倍但业务量同比翻倍，单位成本不变
+	allocs = append(allocs, alloc(4, date, 200))
+	points = append(points, metric("orders", 4, date, 200))
+	// 节点 3：支出翻倍而业务量不变，单位成本翻倍
+	allocs = append(allocs, alloc(3, date, 200))
+	points = append(points, metric("orders", 3, date, 100))
+
+	svc, _ := newTestService(allocs, points)
+	recorder := &mockRecorder{}
+	svc.SetAnomalyService(recorder)
+
+	require.NoError(t, svc.DetectUnitCostAnomalies(context.Background(), "", date))
+
+	require.Len(t, recorder.recorded, 1)
+	a := recorder.recorded[0]
+	assert.Equal(t, anomaly.DimensionUnitCost, a.Dimension)
+	assert.Equal(t, "orders@搜索", a.DimensionValue)
+	assert.Equal(t, date, a.AnomalyDate)
+	assert.Equal(t, "t1", a.TenantID)
+	assert.InDelta(t, 2, a.ActualAmount, 1e-9)
+	assert.InDelta(t, 1, a.BaselineAmount, 1e-9)
+	assert.InDelta(t, 100, a.DeviationPct, 1e-9)
+}
+
+func TestDetectUnitCostAnomalies_SkipsUndatedPeriod(t *testing.T) {
+	target := time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+	date := target.Format("2006-01-02")
+	var allocs []domain.CostAllocation
+	var points []domain.BusinessMetricPoint
+	for d := target.AddDate(0, 0, -7); !d.After(target); d = d.AddDate(0, 0, 1) {
+		points = append(points, metric("orders", 3, d.Format("2006-01-02"), 100))
+	}
+	allocs = append(allocs, alloc(3, date, 500))
+	// 缺少账单日期的分摊结果无法按日统计，当期不做检测
+	undated := alloc(3, date, 100)
+	undated.BillingDate = ""
+	allocs = append(allocs, undated)
+
+	svc, _ := newTestService(allocs, points)
+	recorder := &mockRecorder{}
+	svc.SetAnomalyService(recorder)
+
+	require.NoError(t, svc.DetectUnitCostAnomalies(context.Background(), "", date))
+	assert.Empty(t, recorder.recorded)
+}
+
+func TestGetUnitCostTrend_SkipsUndatedPeriod(t *testing.T) {
+	undated := alloc(3, "2026-08-31", 100)
+	undated.BillingDate = ""
+	allocs := []domain.CostAllocation{alloc(3, "2026-08-31", 50), undated, alloc(3, "2026-09-01", 30)}
+	points := []domain.BusinessMetricPoint{metric("orders", 3, "2026-08-31", 10), metric("orders", 3, "2026-09-01", 10)}
+	svc, _ := newTestService(allocs, points)
+
+	trend, err := svc.GetUnitCostTrend(context.Background(), "t1", "orders", 3, "2026-08-31", "2026-09-01")
+	require.NoError(t, err)
+	require.Len(t, trend.Points, 1)
+	assert.Equal(t, "2026-09-01", trend.Points[0].Date)
+	assert.InDelta(t, 3, trend.UnitCost, 1e-9)
+}
+
+func TestGetUnitCostTrend_MonthlyBills(t *testing.T) {
+	// 按月出账：整月账单记在 8 月 1 号，均摊到 31 天
+	allocs := []domain.CostAllocation{alloc(3, "2026-08-01", 3100)}
+	var points []domain.BusinessMetricPoint
+	for d := 1; d <= 31; d++ {
+		points = append(points, metric("orders", 3, fmt.Sprintf("2026-08-%02d", d), 100))
+	}
+	svc, _ := newTestService(allocs, points)
+
+	trend, err := svc.GetUnitCostTrend(context.Background(), "t1", "orders", 3, "2026-08-01", "2026-08-31")
+	require.NoError(t, err)
+	require.Len(t, trend.Points, 31)
+	for _, pt := range trend.Points {
+		assert.InDelta(t, 100, pt.Cost, 1e-9, pt.Date)
+		assert.InDelta(t, 1, pt.UnitCost, 1e-9, pt.Date)
+	}
+	assert.InDelta(t, 3100, trend.TotalCost, 1e-9)
+
+	// 当月账单为月初至今的累计：9 月 11 日时 1 号记录均摊到 1~10 日
+	svc, _ = newTestService([]domain.CostAllocation{alloc(3, "2026-09-01", 1000)}, nil)
+	svc.now = func() time.Time { return time.Date(2026, 9, 11, 8, 0, 0, 0, time.UTC) }
+	trend, err = svc.GetUnitCostTrend(context.Background(), "t1", "orders", 3, "2026-09-01", "2026-09-11")
+	require.NoError(t, err)
+	require.Len(t, trend.Points, 11)
+	assert.InDelta(t, 100, trend.Points[0].Cost, 1e-9)
+	assert.InDelta(t, 100, trend.Points[9].Cost, 1e-9)
+	assert.Zero(t, trend.Points[10].Cost)
+}
+
+func TestDetectUnitCostAnomalies_MonthlyBills(t *testing.T) {
+	var points []domain.BusinessMetricPoint
+	for d := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC); d.Month() <= time.August; d = d.AddDate(0, 0, 1) {
+		points = append(points, metric("orders", 3, d.Format("2006-01-02"), 100))
+	}
+
+	// 7、8 月按月出账且业务量平稳：均摊后单位成本持平，不判定异常
+	svc, _ := newTestService([]domain.CostAllocation{alloc(3, "2026-07-01", 3100), alloc(3, "2026-08-01", 3100)}, points)
+	recorder := &mockRecorder{}
+	svc.SetAnomalyService(recorder)
+	require.NoError(t, svc.DetectUnitCostAnomalies(context.Background(), "", "2026-08-01"))
+	require.NoError(t, svc.DetectUnitCostAnomalies(context.Background(), "", "2026-08-15"))
+	assert.Empty(t, recorder.recorded)
+
+	// 8 月账单翻倍而业务量不变：8 月各日单位成本翻倍，与 7 月均摊后的基线比较判定异常
+	svc, _ = newTestService([]domain.CostAllocation{alloc(3, "2026-07-01", 3100), alloc(3, "2026-08-01", 6200)}, points)
+	recorder = &mockRecorder{}
+	svc.SetAnomalyService(recorder)
+	require.NoError(t, svc.DetectUnitCostAnomalies(context.Background(), "", "2026-08-01"))
+	require.Len(t, recorder.recorded, 1)
+	assert.Equal(t, "2026-08-01", recorder.recorded[0].AnomalyDate)
+	assert.InDelta(t, 2, recorder.recorded[0].ActualAmount, 1e-9)
+	assert.InDelta(t, 1, recorder.recorded[0].BaselineAmount, 1e-9)
+}
+
+func TestDetectUnitCostAnomalies_Disabled(t *testing.T) {
+	svc, _ := newTestService(nil, nil)
+	assert.NoError(t, svc.DetectUnitCostAnomalies(context.Background(), "", "bad-date"))
+
+	svc.SetAnomalyService(&mockRecorder{})
+	assert.ErrorIs(t, svc.DetectUnitCostAnomalies(context.Background(), "", "bad-date"), domain.ErrUnitCostInvalid)
+}
@@ -37,6 +37,7 @@ var seedTypes = []SeedType{
 			{Value: "tencent", Label: "腾讯云", SortOrder: 4, Extra: map[string]interface{}{"icon": "icon-tencent", "color": "#006eff"}},
 			{Value: "huawei", Label: "华为云", SortOrder: 5, Extra: map[string]interface{}{"icon": "icon-huawei", "color": "#ff0000"}},
 			{Value: "volcano", Label: "火山引擎", SortOrder: 6, Extra: map[string]interface{}{"icon": "icon-volcano", "color": "#3370ff"}},
+			{Value: "gcp", Label: "Google Cloud", SortOrder: 7, Extra: map[string]interface{}{"icon": "icon-gcp", "color": "#4285f4"}},
 		},
 	},
 	{
@@ -142,6 +143,8 @@ var seedTypes = []SeedType{
 			{Value: "access_key", Label: "Access Key", SortOrder: 2},
 			{Value: "ram_user", Label: "RAM用户", SortOrder: 3},
 			{Value: "iam_user", Label: "IAM用户", SortOrder: 4},
+			{Value: "entra_user", Label: "Entra ID用户", SortOrder: 5},
+			{Value: "service_account", Label: "服务账号", SortOrder: 6},
 		},
 	},
 	{
@@ -231,6 +234,14 @@ var seedTypes = []SeedType{
 			{Value: "release_disk", Label: "释放云盘", SortOrder: 2},
 			{Value: "convert_prepaid", Label: "转包年包月", SortOrder: 3},
 			{Value: "idle_resource", Label: "闲置资源", SortOrder: 4},
+			{Value: "release_eip", Label: "释放弹性公网IP", SortOrder: 5},
+			{Value: "release_lb", Label: "释放负载均衡", SortOrder: 6},
+			{Value: "delete_snapshot", Label: "删除快照", SortOrder: 7},
+			{Value: "delete_image", Label: "删除镜像", SortOrder: 8},
+			{Value: "delete_bucket", Label: "删除存储桶", SortOrder: 9},
+			{Value: "release_stopped_instance", Label: "释放停机实例", SortOrder: 10},
+			{Value: "release_nat", Label: "释放NAT网关", SortOrder: 11},
+			{Value: "release_eni", Label: "释放弹性网卡", SortOrder: 12},
 		},
 	},
 	{
@@ -58,6 +58,7 @@ func TestSeedTypes_CloudProviderConsistency(t *testing.T) {
 	assert.True(t, values[string(domain.CloudProviderAzure)], "should contain azure")
 	assert.True(t, values[string(domain.CloudProviderTencent)], "should contain tencent")
 	assert.True(t, values[string(domain.CloudProviderHuawei)], "should contain huawei")
+	assert.True(t, values[string(domain.CloudProviderGCP)], "should contain gcp")
 	// volcano 是额外的，不在 domain 常量中
 	assert.True(t, values["volcano"], "should contain volcano")
 }
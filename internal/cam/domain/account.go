@@ -22,6 +22,7 @@ const (
 	CloudProviderAliyun  CloudProvider = "aliyun"  // 阿里云
 	CloudProviderAWS     CloudProvider = "aws"     // Amazon Web Services
 	CloudProviderAzure   CloudProvider = "azure"   // Microsoft Azure
+	CloudProviderGCP     CloudProvider = "gcp"     // Google Cloud
 	CloudProviderTencent CloudProvider = "tencent" // 腾讯云
 	CloudProviderHuawei  CloudProvider = "huawei"  // 华为云
 )
@@ -9,4 +9,6 @@ const (
 	CloudProviderHuawei  CloudProvider = "huawei"  // 华为云
 	CloudProviderTencent CloudProvider = "tencent" // 腾讯云
 	CloudProviderVolcano CloudProvider = "volcano" // 火山云
+	CloudProviderAzure   CloudProvider = "azure"   // Microsoft Azure
+	CloudProviderGCP     CloudProvider = "gcp"     // Google Cloud
 )
@@ -15,10 +15,12 @@ const CloudIAMUsersCollection = "ecam_iam_user"
 type CloudUserType string
 
 const (
-	CloudUserTypeAPIKey    CloudUserType = "api_key"
-	CloudUserTypeAccessKey CloudUserType = "access_key"
-	CloudUserTypeRAMUser   CloudUserType = "ram_user"
-	CloudUserTypeIAMUser   CloudUserType = "iam_user"
+	CloudUserTypeAPIKey         CloudUserType = "api_key"
+	CloudUserTypeAccessKey      CloudUserType = "access_key"
+	CloudUserTypeRAMUser        CloudUserType = "ram_user"
+	CloudUserTypeIAMUser        CloudUserType = "iam_user"
+	CloudUserTypeEntraUser      CloudUserType = "entra_user"
+	CloudUserTypeServiceAccount CloudUserType = "service_account"
 )
 
 // CloudUserStatus 用户状态
@@ -337,6 +337,7 @@ func (s *userGroupService) validatePolicies(policies []domain.PermissionPolicy)
 			domain.CloudProviderHuawei:  true,
 			domain.CloudProviderTencent: true,
 			domain.CloudProviderAzure:   true,
+			domain.CloudProviderGCP:     true,
 		}
 		if !validProviders[policy.Provider] {
 			return fmt.Errorf("策略%d的云厂商不支持: %s", i+1, policy.Provider)
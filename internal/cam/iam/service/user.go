@@ -283,10 +283,12 @@ func (s *cloudUserService) validateCreateUserRequest(req *domain.CreateCloudUser
 	}
 
 	validTypes := map[domain.CloudUserType]bool{
-		domain.CloudUserTypeAPIKey:    true,
-		domain.CloudUserTypeAccessKey: true,
-		domain.CloudUserTypeRAMUser:   true,
-		domain.CloudUserTypeIAMUser:   true,
+		domain.CloudUserTypeAPIKey:         true,
+		domain.CloudUserTypeAccessKey:      true,
+		domain.CloudUserTypeRAMUser:        true,
+		domain.CloudUserTypeIAMUser:        true,
+		domain.CloudUserTypeEntraUser:      true,
+		domain.CloudUserTypeServiceAccount: true,
 	}
 	if !validTypes[req.UserType] {
 		return errs.UserInvalidType
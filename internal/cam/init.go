@@ -3,6 +3,7 @@ package cam
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	// 使用新的独立 IAM 模块
@@ -11,23 +12,43 @@ import (
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/analysis"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/anomaly"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/budget"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/chargeback"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/collector"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/commitment"
+	costdomain "github.com/Havens-blog/e-cam-service/internal/cam/cost/domain"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/exchange"
+	costexport "github.com/Havens-blog/e-cam-service/internal/cam/cost/export"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/forecast"
 	costhandler "github.com/Havens-blog/e-cam-service/internal/cam/cost/handler"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/k8scost"
+	costmetrics "github.com/Havens-blog/e-cam-service/internal/cam/cost/metrics"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/normalizer"
 	"github.com/Havens-blog/e-cam-service/internal/cam/cost/optimizer"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/pricing"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/reconcile"
 	costdao "github.com/Havens-blog/e-cam-service/internal/cam/cost/repository/dao"
+	"github.com/Havens-blog/e-cam-service/internal/cam/cost/unitcost"
 	"github.com/Havens-blog/e-cam-service/internal/cam/dictionary"
 	"github.com/Havens-blog/e-cam-service/internal/cam/dns"
+	"github.com/Havens-blog/e-cam-service/internal/cam/domain"
 	"github.com/Havens-blog/e-cam-service/internal/cam/iam"
 	"github.com/Havens-blog/e-cam-service/internal/cam/repository"
 	"github.com/Havens-blog/e-cam-service/internal/cam/repository/dao"
 	"github.com/Havens-blog/e-cam-service/internal/cam/servicetree"
+	stdomain "github.com/Havens-blog/e-cam-service/internal/cam/servicetree/domain"
+	stservice "github.com/Havens-blog/e-cam-service/internal/cam/servicetree/service"
 	"github.com/Havens-blog/e-cam-service/internal/cam/tag"
+	"github.com/Havens-blog/e-cam-service/internal/cam/task"
+	taskservice "github.com/Havens-blog/e-cam-service/internal/cam/task/service"
 	"github.com/Havens-blog/e-cam-service/internal/cam/template"
 	cmdbrepository "github.com/Havens-blog/e-cam-service/internal/cmdb/repository"
 	cmdbdao "github.com/Havens-blog/e-cam-service/internal/cmdb/repository/dao"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
 	shareddomain "github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	topodomain "github.com/Havens-blog/e-cam-service/internal/topology/domain"
+	toporepo "github.com/Havens-blog/e-cam-service/internal/topology/repository"
+	topodao "github.com/Havens-blog/e-cam-service/internal/topology/repository/dao"
 	"github.com/Havens-blog/e-cam-service/pkg/mongox"
 	"github.com/Havens-blog/e-cam-service/pkg/taskx"
 	"github.com/gotomicro/ego/core/elog"
@@ -36,9 +57,18 @@ import (
 	// 注册各云厂商 billing adapter（触发 init() 注册到全局注册表）
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing/aliyun"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing/aws"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing/azure"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing/gcp"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing/huawei"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing/tencent"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing/volcano"
+
+	// 注册各云厂商监控指标 adapter
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics/aliyun"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics/aws"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics/huawei"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics/tencent"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics/volcano"
 )
 
 // InitModuleWithIAM 初始化CAM模块（包含IAM和成本管理）
@@ -88,6 +118,11 @@ func InitModuleWithIAM(db *mongox.Mongo, redisClient redis.Cmdable, alertModule
 
 	module.ServiceTreeModule = stModule
 
+	// 值班表按服务树向上继承：节点未配置值班表时使用上级节点的值班表
+	if alertModule != nil && alertModule.OnCallService != nil {
+		alertModule.OnCallService.SetNodeResolver(&alertOnCallNodes{treeSvc: stModule.TreeService})
+	}
+
 	// 初始化数据字典模块
 	logger.Info("开始初始化数据字典模块")
 	if err := dictionary.InitIndexes(db); err != nil {
@@ -178,16 +213,38 @@ func initCostModule(module *Module, db *mongox.Mongo, redisClient redis.Cmdable,
 	billDAO := costdao.NewBillDAO(db)
 	collectLogDAO := costdao.NewCollectLogDAO(db)
 	budgetDAO := costdao.NewBudgetDAO(db)
+	budgetHistoryDAO := costdao.NewBudgetHistoryDAO(db)
 	allocationDAO := costdao.NewAllocationDAO(db)
 	anomalyDAO := costdao.NewAnomalyDAO(db)
+	anomalyModelDAO := costdao.NewAnomalyModelConfigDAO(db)
 	optimizerDAO := costdao.NewOptimizerDAO(db)
+	exchangeRateDAO := costdao.NewExchangeRateDAO(db)
+	costSettingsDAO := costdao.NewCostSettingsDAO(db)
+	commitmentDAO := costdao.NewCommitmentDAO(db)
+	metricsDAO := costdao.NewMetricsDAO(db)
+	reconcileDAO := costdao.NewReconcileDAO(db)
+	chargebackDAO := costdao.NewChargebackDAO(db)
+	usageDAO := costdao.NewUsageDAO(db)
+	businessMetricDAO := costdao.NewBusinessMetricDAO(db)
+	k8sMetricsDAO := costdao.NewK8sMetricsDAO(db)
+
+	// 初始化汇率服务（标准化时按账单日期取汇率）
+	exchangeSvc := exchange.NewExchangeRateService(exchangeRateDAO, billDAO, logger)
 
 	// 初始化标准化服务
 	normalizerSvc := normalizer.NewNormalizerService(billDAO, logger)
+	normalizerSvc.SetRateProvider(exchangeSvc)
+	// 历史账单重算与标准化入库共用同一币种配置（含美元固定汇率兜底）
+	exchangeSvc.SetCNYRateLookup(normalizerSvc.GetCurrencyConfig())
+
+	// 初始化报表币种换算（成本查询按账单日期汇率折算为租户报表币种）
+	converter := exchange.NewConverter(exchangeSvc, costSettingsDAO)
 
 	// 初始化采集服务
 	// 使用 cam 模块的 AccountSvc，它满足 accountservice.CloudAccountService 接口
 	collectorSvc := collector.NewCollectorService(normalizerSvc, billDAO, collectLogDAO, module.AccountSvc, redisClient, logger)
+	// 代理商对账单与 CUR 文件通过账单导入写入
+	collectorSvc.SetImportSourceDAO(costdao.NewImportSourceDAO(db))
 
 	// 初始化成本分析服务
 	costSvc := analysis.NewCostService(billDAO, redisClient, logger)
@@ -195,6 +252,8 @@ func initCostModule(module *Module, db *mongox.Mongo, redisClient redis.Cmdable,
 	// 初始化每日汇总 DAO 并注入到成本服务（查询走汇总表，避免明细表全表扫描）
 	dailySummaryDAO := costdao.NewDailySummaryDAO(db, logger)
 	costSvc.SetSummaryDAO(dailySummaryDAO)
+	exchangeSvc.SetSummaryRebuilder(dailySummaryDAO)
+	costSvc.SetCurrencyConverter(converter)
 
 	// 异步初始化汇总表索引和数据
 	go func() {
@@ -213,37 +272,335 @@ func initCostModule(module *Module, db *mongox.Mongo, redisClient redis.Cmdable,
 		}
 	}()
 
+	// 初始化成本预测服务
+	forecastSvc := forecast.NewForecastService(billDAO, logger)
+	forecastSvc.SetCurrencyConverter(converter)
+
 	// 初始化预算管理服务
 	var alertSvc = alertModule.AlertService
 	budgetSvc := budget.NewBudgetService(budgetDAO, billDAO, alertSvc, logger)
+	budgetSvc.SetCurrencyConverter(converter)
+	budgetSvc.SetForecaster(forecastSvc)
+	budgetSvc.SetHistoryDAO(budgetHistoryDAO)
 
 	// 初始化成本分摊服务
 	allocationSvc := allocation.NewAllocationService(allocationDAO, billDAO, logger)
+	allocationSvc.SetCurrencyConverter(converter)
+
+	// 注入用量数据源（用量加权分摊）：K8s 命名空间指标与 CSV 走用量记录，调用量取拓扑连线并按账期留存快照
+	allocationSvc.SetUsageDAO(usageDAO)
+	recordUsage := allocation.NewRecordUsageSource(usageDAO)
+	allocationSvc.SetUsageSource(costdomain.UsageSourceK8sNamespace, recordUsage)
+	allocationSvc.SetUsageSource(costdomain.UsageSourceCSV, recordUsage)
+	allocationSvc.SetUsageSource(costdomain.UsageSourceTopoEdge, allocation.NewSnapshotUsageSource(&topologyEdgeUsage{
+		edgeRepo: toporepo.NewEdgeRepository(topodao.NewEdgeDAO(db)),
+	}, usageDAO))
+
+	// 初始化容器成本服务（K8s 节点账单按 Pod 资源拆分到命名空间 / 工作负载，指标由外部推送）
+	k8sCostSvc := k8scost.NewK8sCostService(k8sMetricsDAO, allocationDAO, logger)
+	k8sCostSvc.AddMetricsProvider(k8scost.NewRecordMetricsProvider(k8sMetricsDAO))
+	allocationSvc.SetContainerSplitter(k8sCostSvc)
 
 	// 初始化异常检测服务
 	anomalySvc := anomaly.NewAnomalyService(anomalyDAO, billDAO, alertSvc, logger)
+	anomalySvc.SetCurrencyConverter(converter)
+	anomalySvc.SetModelConfigDAO(anomalyModelDAO)
 
 	// 初始化优化建议服务
 	optimizerSvc := optimizer.NewOptimizerService(optimizerDAO, billDAO, logger)
 
+	// 注入资源规格、价格目录与可售规格查询（降配建议给出目标规格与价差）
+	instanceRepo := repository.NewInstanceRepository(dao.NewInstanceDAO(db))
+	optimizerSvc.SetInventory(&instanceSpecInventory{instanceRepo: instanceRepo})
+	if catalogue, err := pricing.NewCatalogue(); err != nil {
+		logger.Warn("加载规格价格目录失败，降配建议不提供目标规格", elog.FieldErr(err))
+	} else {
+		optimizerSvc.SetCatalogue(catalogue)
+	}
+	optimizerSvc.SetInstanceTypeSource(&instanceTypeSource{
+		accountSvc:     module.AccountSvc,
+		adapterFactory: cloudx.NewAdapterFactory(logger),
+	})
+
+	// 注入资产清单（检测闲置 EIP、负载均衡、快照、镜像等孤立资源）
+	optimizerSvc.SetAssetInventory(&instanceAssetInventory{instanceRepo: instanceRepo})
+
+	// 初始化资源监控指标服务（云监控日聚合指标缓存，作为优化建议的利用率数据源）
+	metricsSvc := costmetrics.NewMetricsService(metricsDAO, billDAO, module.AccountSvc,
+		&instanceResourceLister{instanceRepo: instanceRepo}, logger)
+	if module.TaskSvc != nil {
+		metricsSvc.SetTaskSubmitter(&metricsTaskSubmitter{taskSvc: module.TaskSvc})
+	}
+	optimizerSvc.SetMetrics(metricsSvc)
+
+	// 初始化承诺消费服务（转包年包月建议参考已购预留实例 / 节省计划）
+	commitmentSvc := commitment.NewCommitmentService(commitmentDAO, module.AccountSvc, alertSvc, logger)
+	optimizerSvc.SetCommitmentProvider(commitmentSvc)
+
+	// 初始化账单对账服务（重新采集走采集服务按月替换，避免重复写入）
+	reconcileSvc := reconcile.NewReconcileService(billDAO, reconcileDAO, module.AccountSvc, collectorSvc, alertSvc, logger)
+
+	// 初始化分账单服务（服务树节点名称、负责人与层级来自服务树模块）
+	chargebackSvc := chargeback.NewChargebackService(allocationDAO, chargebackDAO, alertSvc, logger)
+	if module.ServiceTreeModule != nil {
+		chargebackSvc.SetNodeResolver(&serviceTreeNodes{treeSvc: module.ServiceTreeModule.TreeService})
+	}
+
+	// 初始化单位成本服务（业务指标按服务树节点汇总，读取已有分摊结果）
+	unitCostSvc := unitcost.NewUnitCostService(businessMetricDAO, allocationDAO, logger)
+	unitCostSvc.SetAnomalyService(anomalySvc)
+	if module.ServiceTreeModule != nil {
+		unitCostSvc.SetNodeResolver(&unitCostNodes{treeSvc: module.ServiceTreeModule.TreeService})
+	}
+
+	// 初始化 FOCUS 成本导出服务（存储由定时任务配置注入）
+	exportSvc := costexport.NewExportService(billDAO, module.AccountSvc, logger)
+	if module.TaskSvc != nil {
+		exportSvc.SetTaskSubmitter(&exportTaskSubmitter{taskSvc: module.TaskSvc})
+	}
+
 	// 初始化 HTTP 处理器
-	module.CostHdl = costhandler.NewCostHandler(costSvc, anomalySvc, optimizerSvc)
+	module.CostHdl = costhandler.NewCostHandler(costSvc, anomalySvc, optimizerSvc, forecastSvc, exportSvc)
 	module.BudgetHdl = costhandler.NewBudgetHandler(budgetSvc)
 	module.AllocationHdl = costhandler.NewAllocationHandler(allocationSvc)
 	module.CollectorHdl = costhandler.NewCollectorHandler(collectorSvc, module.TaskSvc)
+	module.ExchangeRateHdl = costhandler.NewExchangeRateHandler(exchangeSvc, converter)
+	module.CommitmentHdl = costhandler.NewCommitmentHandler(commitmentSvc)
+	module.ReconcileHdl = costhandler.NewReconcileHandler(reconcileSvc)
+	module.ChargebackHdl = costhandler.NewChargebackHandler(chargebackSvc)
+	module.UnitCostHdl = costhandler.NewUnitCostHandler(unitCostSvc)
+	module.K8sCostHdl = costhandler.NewK8sCostHandler(k8sCostSvc)
 
 	// 注册账单采集执行器到任务队列
 	module.TaskModule.RegisterBillingExecutor(normalizerSvc, billDAO, collectLogDAO, module.AccountSvc, redisClient, logger)
+	module.TaskModule.RegisterMetricsExecutor(metricsSvc, logger)
+	module.TaskModule.RegisterExportExecutor(exportSvc, logger)
 
 	// 设置服务引用（供定时任务使用）
 	module.CostCollectorSvc = collectorSvc
 	module.CostBudgetSvc = budgetSvc
 	module.CostAnomalySvc = anomalySvc
 	module.CostOptimizerSvc = optimizerSvc
+	module.CostExchangeRateSvc = exchangeSvc
+	module.CostCommitmentSvc = commitmentSvc
+	module.CostMetricsSvc = metricsSvc
+	module.CostExportSvc = exportSvc
+	module.CostReconcileSvc = reconcileSvc
+	module.CostChargebackSvc = chargebackSvc
+	module.CostUnitCostSvc = unitCostSvc
 
 	return nil
 }
 
+// instanceSpecInventory 基于资产实例属性实现 optimizer.ResourceInventory
+type instanceSpecInventory struct {
+	instanceRepo repository.InstanceRepository
+}
+
+func (i *instanceSpecInventory) GetResourceSpec(ctx context.Context, tenantID, provider, kind, resourceID string) (optimizer.ResourceSpec, bool, error) {
+	inst, err := i.instanceRepo.GetByAssetID(ctx, tenantID, provider+"_"+kind, resourceID)
+	if err != nil {
+		return optimizer.ResourceSpec{}, false, err
+	}
+	if inst.ID == 0 {
+		return optimizer.ResourceSpec{}, false, nil
+	}
+	return optimizer.SpecFromAttributes(kind, inst.Attributes), true, nil
+}
+
+// instanceAssetInventory 基于资产实例实现 optimizer.AssetInventory
+type instanceAssetInventory struct {
+	instanceRepo repository.InstanceRepository
+}
+
+func (i *instanceAssetInventory) ListAssets(ctx context.Context, tenantID, kind string) ([]optimizer.Asset, error) {
+	instances, err := i.instanceRepo.List(ctx, domain.InstanceFilter{
+		ModelUID: kind,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]optimizer.Asset, 0, len(instances))
+	for _, inst := range instances {
+		provider, _ := inst.Attributes["provider"].(string)
+		if provider == "" {
+			provider = strings.TrimSuffix(inst.ModelUID, "_"+kind)
+		}
+		region, _ := inst.Attributes["region"].(string)
+		assets = append(assets, optimizer.Asset{
+			ResourceID:   inst.AssetID,
+			ResourceName: inst.AssetName,
+			Provider:     provider,
+			AccountID:    inst.AccountID,
+			Region:       region,
+			Attributes:   inst.Attributes,
+		})
+	}
+	return assets, nil
+}
+
+// instanceTypeSource 通过云厂商资源查询适配器实现 optimizer.InstanceTypeSource
+type instanceTypeSource struct {
+	accountSvc     CloudAccountService
+	adapterFactory *cloudx.AdapterFactory
+}
+
+func (s *instanceTypeSource) ListAvailableInstanceTypes(ctx context.Context, accountID int64, region string) ([]types.InstanceTypeInfo, error) {
+	account, err := s.accountSvc.GetAccountWithCredentials(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("get account %d: %w", accountID, err)
+	}
+	adapter, err := s.adapterFactory.CreateAdapter(account)
+	if err != nil {
+		return nil, fmt.Errorf("create adapter: %w", err)
+	}
+	query := adapter.ResourceQuery()
+	if query == nil {
+		return nil, fmt.Errorf("provider %s does not support resource query", account.Provider)
+	}
+	return query.ListAvailableInstanceTypes(ctx, region)
+}
+
+// instanceResourceLister 基于资产实例实现 costmetrics.ResourceLister
+type instanceResourceLister struct {
+	instanceRepo repository.InstanceRepository
+}
+
+func (l *instanceResourceLister) ListResources(ctx context.Context, account *shareddomain.CloudAccount, kind string) ([]costmetrics.Resource, error) {
+	instances, err := l.instanceRepo.List(ctx, domain.InstanceFilter{
+		ModelUID:  string(account.Provider) + "_" + kind,
+		TenantID:  account.TenantID,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]costmetrics.Resource, 0, len(instances))
+	for _, inst := range instances {
+		region, _ := inst.Attributes["region"].(string)
+		resources = append(resources, costmetrics.Resource{
+			ResourceID:   inst.AssetID,
+			ResourceName: inst.AssetName,
+			Region:       region,
+			Attributes:   inst.Attributes,
+		})
+	}
+	return resources, nil
+}
+
+// metricsTaskSubmitter 通过任务队列异步执行监控指标同步
+type metricsTaskSubmitter struct {
+	taskSvc taskservice.TaskService
+}
+
+func (s *metricsTaskSubmitter) SubmitMetricsSyncTask(ctx context.Context, accountID int64, tenantID string, days int) (string, error) {
+	return s.taskSvc.SubmitSyncMetricsTask(ctx, task.SyncMetricsParams{
+		AccountID: accountID,
+		Days:      days,
+		TenantID:  tenantID,
+	}, "system")
+}
+
+// exportTaskSubmitter 通过任务队列异步执行成本导出
+type exportTaskSubmitter struct {
+	taskSvc taskservice.TaskService
+}
+
+func (s *exportTaskSubmitter) SubmitCostExportTask(ctx context.Context, tenantID, startDate, endDate, format string) (string, error) {
+	return s.taskSvc.SubmitExportCostTask(ctx, task.ExportCostParams{
+		TenantID:  tenantID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Format:    format,
+	}, "system")
+}
+
+// serviceTreeNodes 基于服务树实现 chargeback.NodeResolver
+type serviceTreeNodes struct {
+	treeSvc stservice.TreeService
+}
+
+func (n *serviceTreeNodes) ListNodes(ctx context.Context, tenantID string) ([]chargeback.Node, error) {
+	nodes, _, err := n.treeSvc.ListNodes(ctx, stdomain.NodeFilter{TenantID: tenantID})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]chargeback.Node, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, chargeback.Node{
+			ID:       node.ID,
+			ParentID: node.ParentID,
+			Name:     node.Name,
+			Owner:    node.Owner,
+		})
+	}
+	return result, nil
+}
+
+// unitCostNodes 基于服务树实现 unitcost.NodeResolver
+type unitCostNodes struct {
+	treeSvc stservice.TreeService
+}
+
+func (n *unitCostNodes) ListNodes(ctx context.Context, tenantID string) ([]unitcost.Node, error) {
+	nodes, _, err := n.treeSvc.ListNodes(ctx, stdomain.NodeFilter{TenantID: tenantID})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]unitcost.Node, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, unitcost.Node{
+			ID:       node.ID,
+			ParentID: node.ParentID,
+			Name:     node.Name,
+			Level:    node.Level,
+		})
+	}
+	return result, nil
+}
+
+// alertOnCallNodes 基于服务树实现 alert/service.NodeResolver
+type alertOnCallNodes struct {
+	treeSvc stservice.TreeService
+}
+
+// AncestorIDs 服务树按路径返回祖先节点（由根到父），值班继承需由近及远
+func (n *alertOnCallNodes) AncestorIDs(ctx context.Context, nodeID int64) ([]int64, error) {
+	ancestors, err := n.treeSvc.GetAncestors(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(ancestors))
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		ids = append(ids, ancestors[i].ID)
+	}
+	return ids, nil
+}
+
+// topologyEdgeUsage 基于拓扑连线实现 allocation.UsageSource：按调用方汇总指向共享对象的请求量
+// 拓扑连线只保存最新的请求计数，只能代表当期用量，需经 allocation.SnapshotUsageSource 按账期留存
+type topologyEdgeUsage struct {
+	edgeRepo toporepo.EdgeRepository
+}
+
+func (u *topologyEdgeUsage) ConsumerUsage(ctx context.Context, tenantID, period string, cfg costdomain.UsageConfig) (map[string]float64, error) {
+	edges, err := u.edgeRepo.Find(ctx, topodomain.EdgeFilter{
+		TenantID:  tenantID,
+		TargetIDs: []string{cfg.Subject},
+	})
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[string]float64)
+	for _, edge := range edges {
+		if edge.RequestCount != nil && *edge.RequestCount > 0 {
+			usage[edge.SourceID] += float64(*edge.RequestCount)
+		}
+	}
+	return usage, nil
+}
+
 // initTemplateModule 初始化主机模板子模块
 func initTemplateModule(module *Module, db *mongox.Mongo, logger *elog.Component) error {
 	// 初始化索引
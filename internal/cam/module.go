@@ -2,7 +2,9 @@ package cam
 
 import (
 	"context"
+	"time"
 
+	costexport "github.com/Havens-blog/e-cam-service/internal/cam/cost/export"
 	costhandler "github.com/Havens-blog/e-cam-service/internal/cam/cost/handler"
 	"github.com/Havens-blog/e-cam-service/internal/cam/dictionary"
 	"github.com/Havens-blog/e-cam-service/internal/cam/dns"
@@ -41,10 +43,16 @@ type Module struct {
 	Logger            *elog.Component              // 日志组件
 
 	// 成本管理模块处理器
-	CostHdl       *costhandler.CostHandler       // 成本分析处理器
-	BudgetHdl     *costhandler.BudgetHandler     // 预算管理处理器
-	AllocationHdl *costhandler.AllocationHandler // 成本分摊处理器
-	CollectorHdl  *costhandler.CollectorHandler  // 采集管理处理器
+	CostHdl         *costhandler.CostHandler         // 成本分析处理器
+	BudgetHdl       *costhandler.BudgetHandler       // 预算管理处理器
+	AllocationHdl   *costhandler.AllocationHandler   // 成本分摊处理器
+	CollectorHdl    *costhandler.CollectorHandler    // 采集管理处理器
+	ExchangeRateHdl *costhandler.ExchangeRateHandler // 汇率管理处理器
+	CommitmentHdl   *costhandler.CommitmentHandler   // 承诺消费分析处理器
+	ReconcileHdl    *costhandler.ReconcileHandler    // 账单对账处理器
+	ChargebackHdl   *costhandler.ChargebackHandler   // 分账单处理器
+	UnitCostHdl     *costhandler.UnitCostHandler     // 单位成本处理器
+	K8sCostHdl      *costhandler.K8sCostHandler      // 容器成本处理器
 
 	// 数据字典模块处理器
 	DictHdl *dictionary.DictHandler
@@ -59,15 +67,24 @@ type Module struct {
 	DNSHdl *dns.DNSHandler
 
 	// 成本管理模块服务（供定时任务使用）
-	CostCollectorSvc CostCollectorService
-	CostBudgetSvc    CostBudgetService
-	CostAnomalySvc   CostAnomalyService
-	CostOptimizerSvc CostOptimizerService
+	CostCollectorSvc    CostCollectorService
+	CostBudgetSvc       CostBudgetService
+	CostAnomalySvc      CostAnomalyService
+	CostOptimizerSvc    CostOptimizerService
+	CostExchangeRateSvc CostExchangeRateService
+	CostCommitmentSvc   CostCommitmentService
+	CostMetricsSvc      CostMetricsService
+	CostExportSvc       CostExportService
+	CostReconcileSvc    CostReconcileService
+	CostChargebackSvc   CostChargebackService
+	CostUnitCostSvc     CostUnitCostService
 }
 
 // CostCollectorService 采集服务接口（供定时任务使用）
 type CostCollectorService interface {
 	StartScheduledCollection(ctx context.Context) error
+	SetImportDirs(root string, minAge time.Duration)
+	ScanImportDirs(ctx context.Context) error
 }
 
 // CostBudgetService 预算检查服务接口（供定时任务使用）
@@ -85,6 +102,45 @@ type CostOptimizerService interface {
 	GenerateRecommendations(ctx context.Context, tenantID string) error
 }
 
+// CostExchangeRateService 汇率同步服务接口（供定时任务使用）
+type CostExchangeRateService interface {
+	SyncRates(ctx context.Context, date time.Time) (int64, error)
+}
+
+// CostCommitmentService 承诺消费同步与到期检查服务接口（供定时任务使用）
+type CostCommitmentService interface {
+	SyncAll(ctx context.Context, tenantID string) error
+	CheckExpiring(ctx context.Context, tenantID string, now time.Time) (int, error)
+}
+
+// CostMetricsService 资源监控指标同步服务接口（供定时任务使用）
+type CostMetricsService interface {
+	StartScheduledSync(ctx context.Context) error
+}
+
+// CostExportService FOCUS 成本导出服务接口（供定时任务使用）
+type CostExportService interface {
+	StartScheduledExport(ctx context.Context, format string, now time.Time) error
+	SetSink(sink costexport.Sink)
+}
+
+// CostReconcileService 账单对账服务接口（供定时任务使用）
+type CostReconcileService interface {
+	ReconcileAll(ctx context.Context, tenantID string, months int, now time.Time) error
+	SetTolerance(pct, abs float64)
+}
+
+// CostChargebackService 分账单服务接口（供定时任务使用）
+type CostChargebackService interface {
+	StartScheduledGeneration(ctx context.Context, now time.Time) error
+	SetLinkBase(base string)
+}
+
+// CostUnitCostService 单位成本异常检测服务接口（供定时任务使用）
+type CostUnitCostService interface {
+	DetectUnitCostAnomalies(ctx context.Context, tenantID, date string) error
+}
+
 // RegisterRoutes 注册所有路由
 func (m *Module) RegisterRoutes(r *gin.Engine) {
 	camGroup := r.Group("/api/v1/cam")
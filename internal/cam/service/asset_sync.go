@@ -1070,6 +1070,7 @@ func (s *assetSyncService) syncLBInstances(
 			"charge_type": inst.ChargeType, "zone": inst.Zone,
 			"slave_zone": inst.SlaveZone, "listener_count": inst.ListenerCount,
 			"backend_server_count": inst.BackendServerCount,
+			"backend_fetched":      inst.BackendFetched,
 			"creation_time":        inst.CreationTime, "expired_time": inst.ExpiredTime,
 			"resource_group_id": inst.ResourceGroupID,
 			"tags":              inst.Tags, "description": inst.Description,
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/pkg/taskx"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCostExporter struct {
+	tenantID, startDate, endDate, format string
+	err                                  error
+}
+
+func (m *mockCostExporter) ExportPeriod(_ context.Context, tenantID, startDate, endDate, format string) (string, int64, error) {
+	m.tenantID, m.startDate, m.endDate, m.format = tenantID, startDate, endDate, format
+	if m.err != nil {
+		return "", 0, m.err
+	}
+	return "/data/focus/focus_t1.csv", 12, nil
+}
+
+func TestExportCostExecutor_Execute(t *testing.T) {
+	repo := &mockTaskRepo{}
+	repo.On("UpdateProgress", mock.Anything, "task-1", 10, mock.Anything).Return(nil)
+	exporter := &mockCostExporter{}
+	e := NewExportCostExecutor(exporter, repo, elog.DefaultLogger)
+	assert.Equal(t, TaskTypeExportCost, e.GetType())
+
+	task := &taskx.Task{ID: "task-1", Params: map[string]interface{}{
+		"tenant_id": "t1", "start_date": "2024-02-01", "end_date": "2024-02-29", "format": "csv",
+	}}
+	require.NoError(t, e.Execute(context.Background(), task))
+	assert.Equal(t, "t1", exporter.tenantID)
+	assert.Equal(t, "2024-02-01", exporter.startDate)
+	assert.Equal(t, "2024-02-29", exporter.endDate)
+	assert.Equal(t, "csv", exporter.format)
+	assert.Equal(t, 100, task.Progress)
+	assert.Equal(t, "/data/focus/focus_t1.csv", task.Result["location"])
+	assert.Equal(t, int64(12), task.Result["row_count"])
+}
+
+func TestExportCostExecutor_Errors(t *testing.T) {
+	repo := &mockTaskRepo{}
+	repo.On("UpdateProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	e := NewExportCostExecutor(&mockCostExporter{err: errors.New("sink down")}, repo, elog.DefaultLogger)
+
+	assert.Error(t, e.Execute(context.Background(), &taskx.Task{ID: "t", Params: map[string]interface{}{}}))
+	assert.ErrorContains(t, e.Execute(context.Background(), &taskx.Task{ID: "t", Params: map[string]interface{}{"tenant_id": "t1"}}), "sink down")
+}
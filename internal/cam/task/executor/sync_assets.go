@@ -1751,6 +1751,7 @@ func (e *SyncAssetsExecutor) convertLBToInstance(inst types.LBInstance, account
 		"bandwidth_package_id":  inst.BandwidthPackageID,
 		"listener_count":        inst.ListenerCount,
 		"backend_server_count":  inst.BackendServerCount,
+		"backend_fetched":       inst.BackendFetched,
 		"listeners":             inst.Listeners,
 		"backend_servers":       inst.BackendServers,
 		"charge_type":           inst.ChargeType,
@@ -0,0 +1,78 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Havens-blog/e-cam-service/pkg/taskx"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	TaskTypeSyncMetrics taskx.TaskType = "cam:sync_metrics"
+)
+
+// syncMetricsParams 监控指标同步参数（executor 内部解析用）
+type syncMetricsParams struct {
+	AccountID int64  `json:"account_id"`
+	Days      int    `json:"days"`
+	TenantID  string `json:"tenant_id"`
+}
+
+// MetricsSyncer 监控指标同步接口，由成本模块的指标服务实现
+type MetricsSyncer interface {
+	SyncAccount(ctx context.Context, accountID int64, days int) (int64, error)
+}
+
+// SyncMetricsExecutor 监控指标同步任务执行器
+type SyncMetricsExecutor struct {
+	syncer   MetricsSyncer
+	taskRepo taskx.TaskRepository
+	logger   *elog.Component
+}
+
+// NewSyncMetricsExecutor 创建监控指标同步执行器
+func NewSyncMetricsExecutor(syncer MetricsSyncer, taskRepo taskx.TaskRepository, logger *elog.Component) *SyncMetricsExecutor {
+	return &SyncMetricsExecutor{
+		syncer:   syncer,
+		taskRepo: taskRepo,
+		logger:   logger,
+	}
+}
+
+// GetType 获取任务类型
+func (e *SyncMetricsExecutor) GetType() taskx.TaskType {
+	return TaskTypeSyncMetrics
+}
+
+// Execute 执行监控指标同步任务
+func (e *SyncMetricsExecutor) Execute(ctx context.Context, t *taskx.Task) error {
+	var params syncMetricsParams
+	paramsBytes, _ := json.Marshal(t.Params)
+	if err := json.Unmarshal(paramsBytes, &params); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+	if params.AccountID <= 0 {
+		return fmt.Errorf("无效的云账号 ID: %d", params.AccountID)
+	}
+
+	e.taskRepo.UpdateProgress(ctx, t.ID, 10, "正在拉取云监控指标")
+	count, err := e.syncer.SyncAccount(ctx, params.AccountID, params.Days)
+	if err != nil {
+		return fmt.Errorf("同步监控指标失败: %w", err)
+	}
+
+	e.logger.Info("监控指标同步完成",
+		elog.Int64("account_id", params.AccountID),
+		elog.Int64("count", count))
+
+	t.Result = map[string]interface{}{
+		"record_count": count,
+		"account_id":   params.AccountID,
+		"days":         params.Days,
+	}
+	t.Progress = 100
+	t.Message = fmt.Sprintf("同步完成，共 %d 条日指标", count)
+	return nil
+}
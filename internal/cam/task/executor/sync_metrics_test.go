@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/pkg/taskx"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMetricsSyncer struct {
+	accountID int64
+	days      int
+	count     int64
+	err       error
+}
+
+func (m *mockMetricsSyncer) SyncAccount(_ context.Context, accountID int64, days int) (int64, error) {
+	m.accountID, m.days = accountID, days
+	return m.count, m.err
+}
+
+func TestSyncMetricsExecutor_Execute(t *testing.T) {
+	repo := &mockTaskRepo{}
+	repo.On("UpdateProgress", mock.Anything, "task-1", 10, mock.Anything).Return(nil)
+	syncer := &mockMetricsSyncer{count: 42}
+	e := NewSyncMetricsExecutor(syncer, repo, elog.DefaultLogger)
+	assert.Equal(t, TaskTypeSyncMetrics, e.GetType())
+
+	task := &taskx.Task{ID: "task-1", Params: map[string]interface{}{"account_id": 7, "days": 3, "tenant_id": "t1"}}
+	require.NoError(t, e.Execute(context.Background(), task))
+	assert.Equal(t, int64(7), syncer.accountID)
+	assert.Equal(t, 3, syncer.days)
+	assert.Equal(t, 100, task.Progress)
+	assert.Equal(t, int64(42), task.Result["record_count"])
+}
+
+func TestSyncMetricsExecutor_Errors(t *testing.T) {
+	repo := &mockTaskRepo{}
+	repo.On("UpdateProgress", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	e := NewSyncMetricsExecutor(&mockMetricsSyncer{err: errors.New("boom")}, repo, elog.DefaultLogger)
+
+	assert.Error(t, e.Execute(context.Background(), &taskx.Task{ID: "t", Params: map[string]interface{}{}}))
+	assert.ErrorContains(t, e.Execute(context.Background(), &taskx.Task{ID: "t", Params: map[string]interface{}{"account_id": 1}}), "boom")
+}
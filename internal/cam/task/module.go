@@ -83,6 +83,18 @@ func (m *Module) RegisterBillingExecutor(
 	logger.Info("账单采集执行器已注册")
 }
 
+// RegisterMetricsExecutor 注册监控指标同步执行器（在成本模块初始化后调用）
+func (m *Module) RegisterMetricsExecutor(syncer executor.MetricsSyncer, logger *elog.Component) {
+	m.Queue.RegisterExecutor(executor.NewSyncMetricsExecutor(syncer, m.TaskRepo, logger))
+	logger.Info("监控指标同步执行器已注册")
+}
+
+// RegisterExportExecutor 注册 FOCUS 成本导出执行器（在成本模块初始化后调用）
+func (m *Module) RegisterExportExecutor(exporter executor.CostExporter, logger *elog.Component) {
+	m.Queue.RegisterExecutor(executor.NewExportCostExecutor(exporter, m.TaskRepo, logger))
+	logger.Info("成本导出执行器已注册")
+}
+
 // Stop 停止任务模块
 func (m *Module) Stop() {
 	if m.Queue != nil {
@@ -21,6 +21,12 @@ type TaskService interface {
 	// SubmitBillingCollectTask 提交账单采集任务
 	SubmitBillingCollectTask(ctx context.Context, params task.SyncBillingParams, createdBy string) (string, error)
 
+	// SubmitSyncMetricsTask 提交监控指标同步任务
+	SubmitSyncMetricsTask(ctx context.Context, params task.SyncMetricsParams, createdBy string) (string, error)
+
+	// SubmitExportCostTask 提交 FOCUS 成本导出任务
+	SubmitExportCostTask(ctx context.Context, params task.ExportCostParams, createdBy string) (string, error)
+
 	// GetTask 获取任务
 	GetTask(ctx context.Context, taskID string) (*taskx.Task, error)
 
@@ -186,3 +192,70 @@ func (s *taskService) SubmitBillingCollectTask(ctx context.Context, params task.
 	s.logger.Info("账单采集任务已提交", elog.String("task_id", taskID))
 	return taskID, nil
 }
+
+// SubmitSyncMetricsTask 提交监控指标同步任务
+func (s *taskService) SubmitSyncMetricsTask(ctx context.Context, params task.SyncMetricsParams, createdBy string) (string, error) {
+	s.logger.Info("提交监控指标同步任务",
+		elog.Int64("account_id", params.AccountID),
+		elog.String("created_by", createdBy))
+
+	taskID := uuid.New().String()
+
+	paramsMap := map[string]interface{}{
+		"account_id": params.AccountID,
+		"days":       params.Days,
+		"tenant_id":  params.TenantID,
+	}
+
+	t := &taskx.Task{
+		ID:        taskID,
+		Type:      task.TaskTypeSyncMetrics,
+		Status:    taskx.TaskStatusPending,
+		Params:    paramsMap,
+		Progress:  0,
+		Message:   "监控指标同步任务已创建，等待执行",
+		CreatedBy: createdBy,
+	}
+
+	if err := s.queue.Submit(t); err != nil {
+		return "", fmt.Errorf("提交任务失败: %w", err)
+	}
+
+	s.logger.Info("监控指标同步任务已提交", elog.String("task_id", taskID))
+	return taskID, nil
+}
+
+// SubmitExportCostTask 提交 FOCUS 成本导出任务
+func (s *taskService) SubmitExportCostTask(ctx context.Context, params task.ExportCostParams, createdBy string) (string, error) {
+	s.logger.Info("提交成本导出任务",
+		elog.String("tenant_id", params.TenantID),
+		elog.String("start_date", params.StartDate),
+		elog.String("end_date", params.EndDate),
+		elog.String("created_by", createdBy))
+
+	taskID := uuid.New().String()
+
+	paramsMap := map[string]interface{}{
+		"tenant_id":  params.TenantID,
+		"start_date": params.StartDate,
+		"end_date":   params.EndDate,
+		"format":     params.Format,
+	}
+
+	t := &taskx.Task{
+		ID:        taskID,
+		Type:      task.TaskTypeExportCost,
+		Status:    taskx.TaskStatusPending,
+		Params:    paramsMap,
+		Progress:  0,
+		Message:   "成本导出任务已创建，等待执行",
+		CreatedBy: createdBy,
+	}
+
+	if err := s.queue.Submit(t); err != nil {
+		return "", fmt.Errorf("提交任务失败: %w", err)
+	}
+
+	s.logger.Info("成本导出任务已提交", elog.String("task_id", taskID))
+	return taskID, nil
+}
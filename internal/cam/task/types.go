@@ -7,6 +7,8 @@ const (
 	TaskTypeSyncAssets     taskx.TaskType = "cam:sync_assets"
 	TaskTypeDiscoverAssets taskx.TaskType = "cam:discover_assets"
 	TaskTypeSyncBilling    taskx.TaskType = "cam:sync_billing"
+	TaskTypeSyncMetrics    taskx.TaskType = "cam:sync_metrics"
+	TaskTypeExportCost     taskx.TaskType = "cam:export_cost"
 )
 
 // SyncAssetsParams 同步资产任务参数
@@ -59,3 +61,18 @@ type SyncBillingResult struct {
 	BillRange   string                 `json:"bill_range"`
 	Details     map[string]interface{} `json:"details,omitempty"`
 }
+
+// SyncMetricsParams 监控指标同步任务参数
+type SyncMetricsParams struct {
+	AccountID int64  `json:"account_id"`
+	Days      int    `json:"days"` // 回溯天数
+	TenantID  string `json:"tenant_id"`
+}
+
+// ExportCostParams FOCUS 成本导出任务参数
+type ExportCostParams struct {
+	TenantID  string `json:"tenant_id"`
+	StartDate string `json:"start_date"` // YYYY-MM-DD
+	EndDate   string `json:"end_date"`   // YYYY-MM-DD
+	Format    string `json:"format"`     // csv | parquet
+}
@@ -17,6 +17,8 @@ import (
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/aliyun"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/asset"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/aws"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/azure"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/gcp"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/huawei"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/tencent"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/volcano"
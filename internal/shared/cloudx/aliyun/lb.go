@@ -63,7 +63,8 @@ func (a *LBAdapter) GetInstance(ctx context.Context, region, lbID string) (*type
 	instance := a.convertDetailToLBInstance(response, region)
 
 	// 获取虚拟服务器组（VServer Group）的后端服务器
-	vsgServers := a.fetchVServerGroupBackendServers(client, region, lbID)
+	vsgServers, fetched := a.fetchVServerGroupBackendServers(client, region, lbID)
+	instance.BackendFetched = fetched
 	if len(vsgServers) > 0 {
 		// 用默认服务器组已有的 ServerID:Port 去重
 		seen := make(map[string]bool)
@@ -170,6 +171,7 @@ func (a *LBAdapter) ListInstancesWithFilter(ctx context.Context, region string,
 		allInstances[i].ListenerCount = detail.ListenerCount
 		allInstances[i].BackendServers = detail.BackendServers
 		allInstances[i].BackendServerCount = detail.BackendServerCount
+		allInstances[i].BackendFetched = detail.BackendFetched
 	}
 
 	// 查询 ALB 实例
@@ -290,7 +292,8 @@ func (a *LBAdapter) convertDetailToLBInstance(resp *slb.DescribeLoadBalancerAttr
 
 // fetchVServerGroupBackendServers 获取 CLB 虚拟服务器组的后端服务器
 // 阿里云 CLB 的后端服务器分两种：默认服务器组（DescribeLoadBalancerAttribute 返回）和虚拟服务器组（需要额外查询）
-func (a *LBAdapter) fetchVServerGroupBackendServers(client *slb.Client, region, lbID string) []types.LBBackendServer {
+// 任一查询失败时 fetched 为 false
+func (a *LBAdapter) fetchVServerGroupBackendServers(client *slb.Client, region, lbID string) ([]types.LBBackendServer, bool) {
 	// 1. 获取虚拟服务器组列表
 	vsgRequest := slb.CreateDescribeVServerGroupsRequest()
 	vsgRequest.RegionId = region
@@ -301,16 +304,17 @@ func (a *LBAdapter) fetchVServerGroupBackendServers(client *slb.Client, region,
 		a.logger.Debug("获取虚拟服务器组列表失败",
 			elog.String("lb_id", lbID),
 			elog.FieldErr(err))
-		return nil
+		return nil, false
 	}
 
 	if len(vsgResponse.VServerGroups.VServerGroup) == 0 {
-		return nil
+		return nil, true
 	}
 
 	// 2. 逐个获取虚拟服务器组的后端服务器
 	var allServers []types.LBBackendServer
 	seen := make(map[string]bool) // 去重：同一个 ServerID+Port 只保留一次
+	fetched := true
 
 	for _, vsg := range vsgResponse.VServerGroups.VServerGroup {
 		attrRequest := slb.CreateDescribeVServerGroupAttributeRequest()
@@ -322,6 +326,7 @@ func (a *LBAdapter) fetchVServerGroupBackendServers(client *slb.Client, region,
 			a.logger.Debug("获取虚拟服务器组详情失败",
 				elog.String("vsg_id", vsg.VServerGroupId),
 				elog.FieldErr(err))
+			fetched = false
 			continue
 		}
 
@@ -350,7 +355,7 @@ func (a *LBAdapter) fetchVServerGroupBackendServers(client *slb.Client, region,
 			elog.Int("server_count", len(allServers)))
 	}
 
-	return allServers
+	return allServers, fetched
 }
 
 // ==================== ALB (Application Load Balancer) ====================
@@ -520,21 +525,22 @@ func (a *LBAdapter) listALBInstances(region string) ([]types.LBInstance, error)
 
 	// 为每个ALB实例获取监听器和后端服务器组信息
 	for i := range allInstances {
-		listeners, sgIDs, listenerCount := a.fetchALBListeners(client, region, allInstances[i].LoadBalancerID)
+		listeners, sgIDs, listenerCount, listenersOK := a.fetchALBListeners(client, region, allInstances[i].LoadBalancerID)
 		allInstances[i].Listeners = listeners
 		allInstances[i].ListenerCount = listenerCount
 
 		// 只查当前 ALB 监听器关联的服务器组，不查全量
-		backendServers := a.fetchALBBackendServersByGroups(client, region, sgIDs)
+		backendServers, serversOK := a.fetchALBBackendServersByGroups(client, region, sgIDs)
 		allInstances[i].BackendServers = backendServers
 		allInstances[i].BackendServerCount = len(backendServers)
+		allInstances[i].BackendFetched = listenersOK && serversOK
 	}
 
 	return allInstances, nil
 }
 
-// fetchALBListeners 获取ALB监听器列表，同时提取关联的 ServerGroupId
-func (a *LBAdapter) fetchALBListeners(client *sdk.Client, region, lbID string) ([]types.LBListener, []string, int) {
+// fetchALBListeners 获取ALB监听器列表，同时提取关联的 ServerGroupId，查询失败时 ok 为 false
+func (a *LBAdapter) fetchALBListeners(client *sdk.Client, region, lbID string) ([]types.LBListener, []string, int, bool) {
 	request := requests.NewCommonRequest()
 	request.Method = "POST"
 	request.Scheme = "https"
@@ -546,13 +552,13 @@ func (a *LBAdapter) fetchALBListeners(client *sdk.Client, region, lbID string) (
 	response, err := client.ProcessCommonRequest(request)
 	if err != nil {
 		a.logger.Warn("获取ALB监听器列表失败", elog.String("lb_id", lbID), elog.FieldErr(err))
-		return nil, nil, 0
+		return nil, nil, 0, false
 	}
 
 	var resp albListListenersResponse
 	if err := json.Unmarshal(response.GetHttpContentBytes(), &resp); err != nil {
 		a.logger.Warn("解析ALB监听器响应失败", elog.String("lb_id", lbID), elog.FieldErr(err))
-		return nil, nil, 0
+		return nil, nil, 0, false
 	}
 
 	var listeners []types.LBListener
@@ -593,7 +599,7 @@ func (a *LBAdapter) fetchALBListeners(client *sdk.Client, region, lbID string) (
 		elog.String("lb_id", lbID),
 		elog.Int("sg_count", len(sgIDs)))
 
-	return listeners, sgIDs, resp.TotalCount
+	return listeners, sgIDs, resp.TotalCount, true
 }
 
 // ==================== ALB 转发规则 ====================
@@ -696,16 +702,20 @@ type albServerItem struct {
 
 // fetchALBBackendServersByGroups 根据指定的 ServerGroupId 列表获取后端服务器
 // 只查当前 ALB 监听器关联的服务器组，避免查全量导致数据错乱
-func (a *LBAdapter) fetchALBBackendServersByGroups(client *sdk.Client, region string, serverGroupIDs []string) []types.LBBackendServer {
+func (a *LBAdapter) fetchALBBackendServersByGroups(client *sdk.Client, region string, serverGroupIDs []string) ([]types.LBBackendServer, bool) {
 	if len(serverGroupIDs) == 0 {
-		return nil
+		return nil, true
 	}
 
 	var allServers []types.LBBackendServer
 	seen := make(map[string]bool)
+	fetched := true
 
 	for _, sgID := range serverGroupIDs {
-		servers := a.fetchServerGroupServers(client, region, sgID)
+		servers, ok := a.fetchServerGroupServers(client, region, sgID)
+		if !ok {
+			fetched = false
+		}
 		for _, s := range servers {
 			key := fmt.Sprintf("%s:%d", s.ServerID, s.Port)
 			if seen[key] {
@@ -716,11 +726,11 @@ func (a *LBAdapter) fetchALBBackendServersByGroups(client *sdk.Client, region st
 		}
 	}
 
-	return allServers
+	return allServers, fetched
 }
 
 // fetchServerGroupServers 获取单个服务器组中的后端服务器
-func (a *LBAdapter) fetchServerGroupServers(client *sdk.Client, region, serverGroupID string) []types.LBBackendServer {
+func (a *LBAdapter) fetchServerGroupServers(client *sdk.Client, region, serverGroupID string) ([]types.LBBackendServer, bool) {
 	var allServers []types.LBBackendServer
 	nextToken := ""
 
@@ -743,7 +753,7 @@ func (a *LBAdapter) fetchServerGroupServers(client *sdk.Client, region, serverGr
 			a.logger.Warn("获取ALB服务器组后端失败",
 				elog.String("server_group_id", serverGroupID),
 				elog.FieldErr(err))
-			return allServers
+			return allServers, false
 		}
 
 		var resp albListServerGroupServersResponse
@@ -751,7 +761,7 @@ func (a *LBAdapter) fetchServerGroupServers(client *sdk.Client, region, serverGr
 			a.logger.Warn("解析ALB服务器组后端响应失败",
 				elog.String("server_group_id", serverGroupID),
 				elog.FieldErr(err))
-			return allServers
+			return allServers, false
 		}
 
 		for _, s := range resp.Servers {
@@ -774,7 +784,7 @@ func (a *LBAdapter) fetchServerGroupServers(client *sdk.Client, region, serverGr
 		nextToken = resp.NextToken
 	}
 
-	return allServers
+	return allServers, true
 }
 
 // convertALBToLBInstance 将ALB实例转换为通用LB实例
@@ -871,20 +881,21 @@ func (a *LBAdapter) listNLBInstances(region string) ([]types.LBInstance, error)
 
 	// 为每个NLB实例获取监听器和后端服务器组信息
 	for i := range allInstances {
-		listeners, sgIDs, listenerCount := a.fetchNLBListeners(client, region, allInstances[i].LoadBalancerID)
+		listeners, sgIDs, listenerCount, listenersOK := a.fetchNLBListeners(client, region, allInstances[i].LoadBalancerID)
 		allInstances[i].Listeners = listeners
 		allInstances[i].ListenerCount = listenerCount
 
-		backendServers := a.fetchNLBBackendServersByGroups(client, region, sgIDs)
+		backendServers, serversOK := a.fetchNLBBackendServersByGroups(client, region, sgIDs)
 		allInstances[i].BackendServers = backendServers
 		allInstances[i].BackendServerCount = len(backendServers)
+		allInstances[i].BackendFetched = listenersOK && serversOK
 	}
 
 	return allInstances, nil
 }
 
-// fetchNLBListeners 获取NLB监听器列表，同时提取关联的 ServerGroupId
-func (a *LBAdapter) fetchNLBListeners(client *sdk.Client, region, lbID string) ([]types.LBListener, []string, int) {
+// fetchNLBListeners 获取NLB监听器列表，同时提取关联的 ServerGroupId，查询失败时 ok 为 false
+func (a *LBAdapter) fetchNLBListeners(client *sdk.Client, region, lbID string) ([]types.LBListener, []string, int, bool) {
 	request := requests.NewCommonRequest()
 	request.Method = "POST"
 	request.Scheme = "https"
@@ -896,13 +907,13 @@ func (a *LBAdapter) fetchNLBListeners(client *sdk.Client, region, lbID string) (
 	response, err := client.ProcessCommonRequest(request)
 	if err != nil {
 		a.logger.Warn("获取NLB监听器列表失败", elog.String("lb_id", lbID), elog.FieldErr(err))
-		return nil, nil, 0
+		return nil, nil, 0, false
 	}
 
 	var resp nlbListListenersResponse
 	if err := json.Unmarshal(response.GetHttpContentBytes(), &resp); err != nil {
 		a.logger.Warn("解析NLB监听器响应失败", elog.String("lb_id", lbID), elog.FieldErr(err))
-		return nil, nil, 0
+		return nil, nil, 0, false
 	}
 
 	var listeners []types.LBListener
@@ -925,20 +936,24 @@ func (a *LBAdapter) fetchNLBListeners(client *sdk.Client, region, lbID string) (
 		sgIDs = append(sgIDs, id)
 	}
 
-	return listeners, sgIDs, resp.TotalCount
+	return listeners, sgIDs, resp.TotalCount, true
 }
 
 // fetchNLBBackendServersByGroups 根据指定的 ServerGroupId 列表获取 NLB 后端服务器
-func (a *LBAdapter) fetchNLBBackendServersByGroups(client *sdk.Client, region string, serverGroupIDs []string) []types.LBBackendServer {
+func (a *LBAdapter) fetchNLBBackendServersByGroups(client *sdk.Client, region string, serverGroupIDs []string) ([]types.LBBackendServer, bool) {
 	if len(serverGroupIDs) == 0 {
-		return nil
+		return nil, true
 	}
 
 	var allServers []types.LBBackendServer
 	seen := make(map[string]bool)
+	fetched := true
 
 	for _, sgID := range serverGroupIDs {
-		servers := a.fetchNLBServerGroupServers(client, region, sgID)
+		servers, ok := a.fetchNLBServerGroupServers(client, region, sgID)
+		if !ok {
+			fetched = false
+		}
 		for _, s := range servers {
 			key := fmt.Sprintf("%s:%d", s.ServerID, s.Port)
 			if seen[key] {
@@ -949,7 +964,7 @@ func (a *LBAdapter) fetchNLBBackendServersByGroups(client *sdk.Client, region st
 		}
 	}
 
-	return allServers
+	return allServers, fetched
 }
 
 // nlbListServerGroupServersResponse NLB ListServerGroupServers 响应
@@ -971,7 +986,7 @@ type nlbServerItem struct {
 }
 
 // fetchNLBServerGroupServers 获取单个 NLB 服务器组中的后端服务器
-func (a *LBAdapter) fetchNLBServerGroupServers(client *sdk.Client, region, serverGroupID string) []types.LBBackendServer {
+func (a *LBAdapter) fetchNLBServerGroupServers(client *sdk.Client, region, serverGroupID string) ([]types.LBBackendServer, bool) {
 	var allServers []types.LBBackendServer
 	nextToken := ""
 
@@ -994,7 +1009,7 @@ func (a *LBAdapter) fetchNLBServerGroupServers(client *sdk.Client, region, serve
 			a.logger.Warn("获取NLB服务器组后端失败",
 				elog.String("server_group_id", serverGroupID),
 				elog.FieldErr(err))
-			return allServers
+			return allServers, false
 		}
 
 		var resp nlbListServerGroupServersResponse
@@ -1002,7 +1017,7 @@ func (a *LBAdapter) fetchNLBServerGroupServers(client *sdk.Client, region, serve
 			a.logger.Warn("解析NLB服务器组后端响应失败",
 				elog.String("server_group_id", serverGroupID),
 				elog.FieldErr(err))
-			return allServers
+			return allServers, false
 		}
 
 		for _, s := range resp.Servers {
@@ -1025,7 +1040,7 @@ func (a *LBAdapter) fetchNLBServerGroupServers(client *sdk.Client, region, serve
 		nextToken = resp.NextToken
 	}
 
-	return allServers
+	return allServers, true
 }
 
 // convertNLBToLBInstance 将NLB实例转换为通用LB实例
@@ -12,6 +12,8 @@ import (
 	// 导入各云厂商适配器以触发 init() 注册
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/aliyun"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/aws"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/azure"
+	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/gcp"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/huawei"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/tencent"
 	_ "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/volcano"
@@ -277,9 +277,10 @@ func (a *LBAdapter) enrichLBDetails(ctx context.Context, client *elbv2.Client, i
 		instances[i].ListenerCount = listenerCount
 
 		// 获取后端服务器详情 (通过目标组)
-		backendServers, backendServerCount := a.fetchTargetGroupCount(ctx, client, lbARN)
+		backendServers, backendServerCount, fetched := a.fetchTargetGroupCount(ctx, client, lbARN)
 		instances[i].BackendServers = backendServers
 		instances[i].BackendServerCount = backendServerCount
+		instances[i].BackendFetched = fetched
 	}
 }
 
@@ -308,7 +309,8 @@ func (a *LBAdapter) fetchListeners(ctx context.Context, client *elbv2.Client, lb
 }
 
 // fetchTargetGroupCount 获取LB关联的目标组，并查询后端目标详情
-func (a *LBAdapter) fetchTargetGroupCount(ctx context.Context, client *elbv2.Client, lbARN string) ([]types.LBBackendServer, int) {
+// 任一目标组查询失败时 fetched 为 false，结果不完整
+func (a *LBAdapter) fetchTargetGroupCount(ctx context.Context, client *elbv2.Client, lbARN string) ([]types.LBBackendServer, int, bool) {
 	input := &elbv2.DescribeTargetGroupsInput{
 		LoadBalancerArn: aws.String(lbARN),
 	}
@@ -316,11 +318,12 @@ func (a *LBAdapter) fetchTargetGroupCount(ctx context.Context, client *elbv2.Cli
 	output, err := client.DescribeTargetGroups(ctx, input)
 	if err != nil {
 		a.logger.Warn("获取AWS目标组列表失败", elog.String("lb_arn", lbARN), elog.FieldErr(err))
-		return nil, 0
+		return nil, 0, false
 	}
 
 	var allServers []types.LBBackendServer
 	seen := make(map[string]bool)
+	fetched := true
 
 	for _, tg := range output.TargetGroups {
 		if tg.TargetGroupArn == nil {
@@ -334,6 +337,7 @@ func (a *LBAdapter) fetchTargetGroupCount(ctx context.Context, client *elbv2.Cli
 			a.logger.Warn("获取AWS目标健康状态失败",
 				elog.String("target_group", aws.ToString(tg.TargetGroupArn)),
 				elog.FieldErr(err))
+			fetched = false
 			continue
 		}
 		for _, thd := range healthOutput.TargetHealthDescriptions {
@@ -371,5 +375,5 @@ func (a *LBAdapter) fetchTargetGroupCount(ctx context.Context, client *elbv2.Cli
 		}
 	}
 
-	return allServers, len(allServers)
+	return allServers, len(allServers), fetched
 }
@@ -0,0 +1,249 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common"
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func init() {
+	// 注册Azure适配器创建函数
+	cloudx.RegisterAdapter(domain.CloudProviderAzure, func(account *domain.CloudAccount) (cloudx.CloudAdapter, error) {
+		return NewAdapter(account)
+	})
+}
+
+// Adapter Azure统一适配器
+// 所有子适配器共享同一个 REST 客户端 (令牌缓存、限流)
+type Adapter struct {
+	account       *domain.CloudAccount
+	logger        *elog.Component
+	client        *azurecommon.Client
+	asset         *AssetAdapter
+	ecs           *ECSAdapter
+	securityGroup *SecurityGroupAdapter
+	disk          *DiskAdapter
+	rds           *RDSAdapter
+	redis         *RedisAdapter
+	mongodb       *MongoDBAdapter
+	vpc           *VPCAdapter
+	eip           *EIPAdapter
+	lb            *LBAdapter
+	oss           *StorageAccountAdapter
+	iam           *IAMAdapter
+	vswitch       *VSwitchAdapter
+	dns           *DNSAdapter
+	tag           *TagAdapterImpl
+}
+
+// NewAdapter 创建Azure适配器
+// 账号 AccessKeyID 格式为 tenantID/clientID[/subscriptionID]，AccessKeySecret 为客户端密钥
+func NewAdapter(account *domain.CloudAccount) (*Adapter, error) {
+	if account == nil {
+		return nil, cloudx.ErrInvalidConfig
+	}
+
+	client, err := azurecommon.NewClientFromAccount(account)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", cloudx.ErrInvalidConfig, err)
+	}
+	return NewAdapterWithClient(account, client), nil
+}
+
+// NewAdapterWithClient 使用指定的 REST 客户端创建Azure适配器
+func NewAdapterWithClient(account *domain.CloudAccount, client *azurecommon.Client) *Adapter {
+	logger := elog.DefaultLogger
+	if logger == nil {
+		logger = elog.EgoLogger
+	}
+
+	// 获取默认地域
+	defaultRegion := "eastus"
+	if len(account.Regions) > 0 {
+		defaultRegion = account.Regions[0]
+	}
+
+	adapter := &Adapter{
+		account: account,
+		logger:  logger,
+		client:  client,
+	}
+
+	adapter.ecs = NewECSAdapter(client, defaultRegion, logger)
+	adapter.asset = NewAssetAdapter(adapter.ecs)
+	adapter.securityGroup = NewSecurityGroupAdapter(client, defaultRegion, logger)
+	adapter.disk = NewDiskAdapter(client, defaultRegion, logger)
+	// Azure SQL Database
+	adapter.rds = NewRDSAdapter(client, defaultRegion, logger)
+	// Azure Cache for Redis
+	adapter.redis = NewRedisAdapter(client, defaultRegion, logger)
+	// Azure Cosmos DB
+	adapter.mongodb = NewMongoDBAdapter(client, defaultRegion, logger)
+	// Virtual Network
+	adapter.vpc = NewVPCAdapter(client, defaultRegion, logger)
+	// Subnet
+	adapter.vswitch = NewVSwitchAdapter(client, defaultRegion, logger)
+	// Public IP Address
+	adapter.eip = NewEIPAdapter(client, defaultRegion, logger)
+	// Load Balancer
+	adapter.lb = NewLBAdapter(client, defaultRegion, logger)
+	// Storage Account
+	adapter.oss = NewStorageAccountAdapter(client, logger)
+	adapter.iam = NewIAMAdapter(account, logger)
+	// Azure DNS
+	adapter.dns = NewDNSAdapter(client, logger)
+	adapter.tag = NewTagAdapter(client, logger)
+
+	return adapter
+}
+
+// GetProvider 获取云厂商类型
+func (a *Adapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderAzure
+}
+
+// Asset 获取资产适配器
+// Deprecated: 请使用 ECS() 获取云虚拟机适配器
+func (a *Adapter) Asset() cloudx.AssetAdapter {
+	return a.asset
+}
+
+// ECS 获取ECS适配器 (Azure Virtual Machines)
+func (a *Adapter) ECS() cloudx.ECSAdapter {
+	return a.ecs
+}
+
+// SecurityGroup 获取安全组适配器 (Azure NSG)
+func (a *Adapter) SecurityGroup() cloudx.SecurityGroupAdapter {
+	return a.securityGroup
+}
+
+// Image 获取镜像适配器 (暂不支持)
+func (a *Adapter) Image() cloudx.ImageAdapter {
+	return nil
+}
+
+// Disk 获取云盘适配器 (Azure Managed Disks)
+func (a *Adapter) Disk() cloudx.DiskAdapter {
+	return a.disk
+}
+
+// Snapshot 获取快照适配器 (暂不支持)
+func (a *Adapter) Snapshot() cloudx.SnapshotAdapter {
+	return nil
+}
+
+// RDS 获取RDS适配器 (Azure SQL Database)
+func (a *Adapter) RDS() cloudx.RDSAdapter {
+	return a.rds
+}
+
+// Redis 获取Redis适配器 (Azure Cache for Redis)
+func (a *Adapter) Redis() cloudx.RedisAdapter {
+	return a.redis
+}
+
+// MongoDB 获取MongoDB适配器 (Azure Cosmos DB)
+func (a *Adapter) MongoDB() cloudx.MongoDBAdapter {
+	return a.mongodb
+}
+
+// VPC 获取VPC适配器 (Azure Virtual Network)
+func (a *Adapter) VPC() cloudx.VPCAdapter {
+	return a.vpc
+}
+
+// EIP 获取EIP适配器 (Azure Public IP)
+func (a *Adapter) EIP() cloudx.EIPAdapter {
+	return a.eip
+}
+
+// ENI 获取弹性网卡适配器 (暂不支持)
+func (a *Adapter) ENI() cloudx.ENIAdapter {
+	return nil
+}
+
+// LB 获取负载均衡适配器 (Azure Load Balancer)
+func (a *Adapter) LB() cloudx.LBAdapter {
+	return a.lb
+}
+
+// CDN 获取CDN适配器 (暂不支持)
+func (a *Adapter) CDN() cloudx.CDNAdapter {
+	return nil
+}
+
+// WAF 获取WAF适配器 (暂不支持)
+func (a *Adapter) WAF() cloudx.WAFAdapter {
+	return nil
+}
+
+// DNS 获取DNS适配器 (Azure DNS)
+func (a *Adapter) DNS() cloudx.DNSAdapter {
+	return a.dns
+}
+
+// NAS 获取NAS适配器 (暂不支持)
+func (a *Adapter) NAS() cloudx.NASAdapter {
+	return common.NewNASStubAdapter(string(types.ProviderAzure))
+}
+
+// OSS 获取OSS适配器 (Azure Storage Account)
+func (a *Adapter) OSS() cloudx.OSSAdapter {
+	return a.oss
+}
+
+// Kafka 获取Kafka适配器 (暂不支持)
+func (a *Adapter) Kafka() cloudx.KafkaAdapter {
+	return nil
+}
+
+// Elasticsearch 获取Elasticsearch适配器 (暂不支持)
+func (a *Adapter) Elasticsearch() cloudx.ElasticsearchAdapter {
+	return nil
+}
+
+// IAM 获取IAM适配器
+func (a *Adapter) IAM() cloudx.IAMAdapter {
+	return a.iam
+}
+
+// VSwitch 获取交换机/子网适配器 (Azure Subnet)
+func (a *Adapter) VSwitch() cloudx.VSwitchAdapter {
+	return a.vswitch
+}
+
+// ECSCreate 获取 ECS 创建适配器 (暂不支持)
+func (a *Adapter) ECSCreate() cloudx.ECSCreateAdapter {
+	return nil
+}
+
+// ResourceQuery 获取资源查询适配器
+func (a *Adapter) ResourceQuery() cloudx.ResourceQueryAdapter {
+	return cloudx.NewGenericResourceQueryAdapter(a)
+}
+
+// Tag 获取标签适配器
+func (a *Adapter) Tag() cloudx.TagAdapter {
+	return a.tag
+}
+
+// ValidateCredentials 验证凭证
+func (a *Adapter) ValidateCredentials(ctx context.Context) error {
+	_, err := a.ecs.GetRegions(ctx)
+	if err != nil {
+		return fmt.Errorf("Azure凭证验证失败: %w", err)
+	}
+
+	a.logger.Info("Azure凭证验证成功",
+		elog.Int64("account_id", a.account.ID),
+		elog.String("account_name", a.account.Name))
+
+	return nil
+}
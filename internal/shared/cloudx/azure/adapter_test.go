@@ -0,0 +1,394 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTenantID       = "00000000-0000-0000-0000-000000000001"
+	testClientID       = "00000000-0000-0000-0000-000000000002"
+	testSubscriptionID = "00000000-0000-0000-0000-000000000003"
+	testSubPath        = "/subscriptions/" + testSubscriptionID
+)
+
+// fixtureRoutes ARM 列表路径 (小写) 到录制响应文件的映射
+var fixtureRoutes = map[string]string{
+	"/providers/microsoft.compute/virtualmachines":                                        "virtual_machines.json",
+	"/providers/microsoft.compute/disks":                                                  "disks.json",
+	"/providers/microsoft.network/networkinterfaces":                                      "network_interfaces.json",
+	"/providers/microsoft.network/publicipaddresses":                                      "public_ip_addresses.json",
+	"/providers/microsoft.network/virtualnetworks":                                        "virtual_networks.json",
+	"/providers/microsoft.network/networksecuritygroups":                                  "network_security_groups.json",
+	"/providers/microsoft.network/loadbalancers":                                          "load_balancers.json",
+	"/providers/microsoft.sql/servers":                                                    "sql_servers.json",
+	"/resourcegroups/rg-prod/providers/microsoft.sql/servers/sql-prod/databases":          "sql_databases.json",
+	"/providers/microsoft.network/dnszones":                                               "dns_zones.json",
+	"/resourcegroups/rg-prod/providers/microsoft.network/dnszones/example.com/recordsets": "dns_recordsets.json",
+	"/resourcegroups/rg-prod/providers/microsoft.network/dnszones/example.com/a/www":      "dns_recordset_a_www.json",
+	"/providers/microsoft.cache/redis":                                                    "redis_caches.json",
+	"/providers/microsoft.documentdb/databaseaccounts":                                    "cosmos_accounts.json",
+	"/providers/microsoft.storage/storageaccounts":                                        "storage_accounts.json",
+	stprodassetsPath + "/providers/microsoft.insights/metrics":                            "storage_metrics_used_capacity.json",
+	stprodassetsPath + "/blobservices/default/providers/microsoft.insights/metrics":       "storage_metrics_blob_count.json",
+	"/tagnames": "tag_names.json",
+}
+
+// stprodassetsPath 已录制监控指标的存储账号路径 (小写)
+const stprodassetsPath = "/resourcegroups/rg-prod/providers/microsoft.storage/storageaccounts/stprodassets"
+
+// fixtureServer 回放 testdata 中录制的 ARM 响应，并记录写请求
+type fixtureServer struct {
+	t      *testing.T
+	mu     sync.Mutex
+	writes []recordedRequest
+}
+
+// recordedRequest 记录的写请求
+type recordedRequest struct {
+	Method string
+	Path   string
+	Body   map[string]any
+}
+
+func (s *fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") {
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		s.mu.Lock()
+		s.writes = append(s.writes, recordedRequest{Method: r.Method, Path: r.URL.Path, Body: body})
+		s.mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+		return
+	}
+
+	path := strings.ToLower(strings.TrimPrefix(r.URL.Path, testSubPath))
+	if file, ok := fixtureRoutes[path]; ok {
+		data, err := os.ReadFile("testdata/" + file)
+		require.NoError(s.t, err)
+		_, _ = w.Write(data)
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"error":{"code":"ResourceNotFound","message":"not recorded"}}`))
+}
+
+// newTestAdapter 创建指向本地 fixture 服务的Azure适配器
+func newTestAdapter(t *testing.T) (*Adapter, *fixtureServer) {
+	t.Helper()
+	fs := &fixtureServer{t: t}
+	srv := httptest.NewServer(fs)
+	t.Cleanup(srv.Close)
+
+	cred, err := azurecommon.ParseCredential(testTenantID+"/"+testClientID+"/"+testSubscriptionID, "secret")
+	require.NoError(t, err)
+	client := azurecommon.NewClient(cred, azurecommon.Endpoints{Login: srv.URL, Management: srv.URL, Graph: srv.URL})
+
+	account := &domain.CloudAccount{ID: 1, Provider: domain.CloudProviderAzure, Regions: []string{"eastus"}}
+	return NewAdapterWithClient(account, client), fs
+}
+
+func TestECSAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	instances, err := adapter.ECS().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+
+	vm := instances[0]
+	assert.Equal(t, "vm-web-01", vm.InstanceName)
+	assert.Equal(t, "running", vm.Status)
+	assert.Equal(t, "eastus", vm.Region)
+	assert.Equal(t, "Standard_D2s_v3", vm.InstanceType)
+	assert.Equal(t, "D2s", vm.InstanceTypeFamily)
+	assert.Equal(t, "linux", vm.OSType)
+	assert.Equal(t, "PostPaid", vm.ChargeType)
+	assert.Equal(t, "rg-prod", vm.ProjectName)
+	assert.Equal(t, "10.0.1.4", vm.PrivateIP)
+	assert.Equal(t, "20.51.10.20", vm.PublicIP)
+	assert.Equal(t, "vnet-prod", vm.VPCName)
+	assert.Equal(t, "snet-app", vm.VSwitchName)
+	require.Len(t, vm.SecurityGroups, 1)
+	assert.Equal(t, "nsg-web", vm.SecurityGroups[0].Name)
+	assert.Equal(t, 30, vm.SystemDisk.Size)
+	require.Len(t, vm.DataDisks, 1)
+	assert.Equal(t, "lun0", vm.DataDisks[0].Device)
+	assert.Equal(t, "prod", vm.Tags["env"])
+
+	// 其他地域不返回
+	instances, err = adapter.ECS().ListInstances(context.Background(), "westeurope")
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+}
+
+func TestDiskAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	disks, err := adapter.Disk().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, disks, 2)
+
+	byName := map[string]types.DiskInstance{}
+	for _, d := range disks {
+		byName[d.DiskName] = d
+	}
+	assert.Equal(t, "system", byName["vm-web-01-osdisk"].DiskType)
+	assert.Equal(t, "in_use", byName["vm-web-01-osdisk"].Status)
+	assert.Equal(t, "Premium_LRS", byName["vm-web-01-osdisk"].Category)
+	assert.Equal(t, "data", byName["orphan-data"].DiskType)
+	assert.Equal(t, "available", byName["orphan-data"].Status)
+	assert.Equal(t, 256, byName["orphan-data"].Size)
+}
+
+func TestVPCAndVSwitchAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	vpcs, err := adapter.VPC().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, vpcs, 1)
+	assert.Equal(t, "10.0.0.0/16", vpcs[0].CidrBlock)
+	assert.Equal(t, []string{"10.1.0.0/16"}, vpcs[0].SecondaryCidrs)
+	assert.Equal(t, 2, vpcs[0].VSwitchCount)
+	assert.Equal(t, 1, vpcs[0].NatGatewayCount)
+
+	subnets, err := adapter.VSwitch().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, subnets, 2)
+	assert.Equal(t, "snet-app", subnets[0].VSwitchName)
+	assert.Equal(t, vpcs[0].VPCID, subnets[0].VPCID)
+	assert.Equal(t, int64(256), subnets[0].TotalIPCount)
+	// 每个子网保留 5 个地址，另有 1 个已分配给网卡
+	assert.Equal(t, int64(250), subnets[0].AvailableIPCount)
+}
+
+func TestSecurityGroupAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	groups, err := adapter.SecurityGroup().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+
+	nsg := groups[0]
+	assert.Equal(t, "nsg-web", nsg.SecurityGroupName)
+	assert.Equal(t, 2, nsg.IngressRuleCount)
+	assert.Equal(t, 1, nsg.EgressRuleCount)
+	assert.Equal(t, 1, nsg.InstanceCount)
+
+	ssh := nsg.IngressRules[0]
+	assert.Equal(t, "ingress", ssh.Direction)
+	assert.Equal(t, "tcp", ssh.Protocol)
+	assert.Equal(t, "22/22", ssh.PortRange)
+	assert.Equal(t, "accept", ssh.Policy)
+	assert.Equal(t, "80/80,443/443", nsg.IngressRules[1].PortRange)
+
+	deny := nsg.EgressRules[0]
+	assert.Equal(t, "all", deny.Protocol)
+	assert.Equal(t, "-1/-1", deny.PortRange)
+	assert.Equal(t, "drop", deny.Policy)
+}
+
+func TestEIPAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	eips, err := adapter.EIP().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, eips, 1)
+	assert.Equal(t, "20.51.10.20", eips[0].IPAddress)
+	assert.Equal(t, "pip-web-01", eips[0].Name)
+}
+
+func TestLBAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	lbs, err := adapter.LB().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, lbs, 1)
+
+	lb := lbs[0]
+	assert.Equal(t, "lb-web", lb.LoadBalancerName)
+	assert.Equal(t, "nlb", lb.LoadBalancerType)
+	assert.Equal(t, "internet", lb.AddressType)
+	require.Len(t, lb.Listeners, 1)
+	assert.Equal(t, 80, lb.Listeners[0].ListenerPort)
+	assert.Equal(t, 8080, lb.Listeners[0].BackendPort)
+	require.Len(t, lb.BackendServers, 1)
+	assert.Equal(t, "eni", lb.BackendServers[0].Type)
+	assert.True(t, lb.BackendFetched)
+}
+
+func TestRDSAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	dbs, err := adapter.RDS().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	// master 系统库不同步
+	require.Len(t, dbs, 1)
+
+	db := dbs[0]
+	assert.Equal(t, "sql-prod/orders", db.InstanceName)
+	assert.Equal(t, "SQLServer", db.Engine)
+	assert.Equal(t, "sql-prod.database.windows.net", db.ConnectionString)
+	assert.Equal(t, 1433, db.Port)
+	assert.Equal(t, 32, db.Storage)
+	assert.Equal(t, "running", db.Status)
+}
+
+func TestRedisAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	caches, err := adapter.Redis().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, caches, 1)
+
+	c := caches[0]
+	assert.Equal(t, "redis-session", c.InstanceName)
+	assert.Equal(t, "eastus", c.Region)
+	assert.Equal(t, "eastus-1", c.Zone)
+	assert.Equal(t, "Premium_P1", c.InstanceClass)
+	assert.Equal(t, "cluster", c.Architecture)
+	// P1 单分片 6GB，两个分片
+	assert.Equal(t, 2*6144, c.Capacity)
+	// 未开启非 SSL 端口时使用 SSL 端口
+	assert.Equal(t, 6380, c.Port)
+	assert.True(t, c.SSLEnabled)
+	assert.Equal(t, "double", c.NodeType)
+	assert.Equal(t, 1, c.ReplicaCount)
+	assert.Equal(t, testSubPath+"/resourceGroups/rg-prod/providers/Microsoft.Network/virtualNetworks/vnet-prod", c.VPCID)
+	assert.Equal(t, "10.0.3.10", c.PrivateIP)
+	assert.Equal(t, "rg-prod", c.ProjectID)
+
+	all, err := adapter.Redis().ListInstances(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	basic := all[1]
+	assert.Equal(t, "single", basic.NodeType)
+	assert.Equal(t, 0, basic.ReplicaCount)
+	assert.Equal(t, 250, basic.Capacity)
+	assert.Equal(t, 6379, basic.Port)
+	assert.False(t, basic.SSLEnabled)
+}
+
+func TestMongoDBAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	accounts, err := adapter.MongoDB().ListInstances(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+
+	mongo := accounts[0]
+	assert.Equal(t, "cosmos-orders", mongo.InstanceName)
+	assert.Equal(t, "mongodb", mongo.DBInstanceType)
+	assert.Equal(t, "4.2", mongo.EngineVersion)
+	assert.Equal(t, "Provisioned", mongo.InstanceClass)
+	assert.Equal(t, 2, mongo.NodeCount)
+	assert.Equal(t, "replicas: westus2", mongo.Description)
+	assert.Equal(t, []string{"20.51.10.0/24"}, mongo.SecurityIPList)
+	assert.Equal(t, testSubPath+"/resourceGroups/rg-prod/providers/Microsoft.Network/virtualNetworks/vnet-prod", mongo.VPCID)
+	assert.Equal(t, 7, mongo.BackupRetentionPeriod)
+
+	sql := accounts[1]
+	assert.Equal(t, "sql", sql.DBInstanceType)
+	assert.Equal(t, "Serverless", sql.InstanceClass)
+	assert.Equal(t, 0, sql.BackupRetentionPeriod)
+
+	filtered, err := adapter.MongoDB().ListInstancesWithFilter(context.Background(), "eastus",
+		&types.MongoDBInstanceFilter{DBInstanceType: "MongoDB"})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "cosmos-orders", filtered[0].InstanceName)
+}
+
+func TestOSSAdapter_ListBuckets(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	buckets, err := adapter.OSS().ListBuckets(context.Background(), "eastus")
+	require.NoError(t, err)
+	require.Len(t, buckets, 2)
+
+	prod := buckets[0]
+	assert.Equal(t, "stprodassets", prod.BucketName)
+	assert.Equal(t, "Cool", prod.StorageClass)
+	assert.Equal(t, "private", prod.ACL)
+	assert.True(t, prod.BlockPublicAccess)
+	assert.True(t, prod.CrossRegionReplication)
+	assert.Equal(t, "KMS", prod.ServerSideEncryption)
+	assert.Equal(t, "cmk-storage", prod.KMSKeyID)
+	// 取最近一个有值的数据点
+	assert.Equal(t, int64(5472051200), prod.StorageSize)
+	assert.Equal(t, int64(18342), prod.ObjectCount)
+
+	// 未录制指标的账号统计查询失败，仍返回账号本身
+	dev := buckets[1]
+	assert.Equal(t, "stdevlogs", dev.BucketName)
+	assert.Equal(t, "Hot", dev.StorageClass)
+	assert.Equal(t, "public-read", dev.ACL)
+	assert.False(t, dev.CrossRegionReplication)
+	assert.Equal(t, "AES256", dev.ServerSideEncryption)
+	assert.Zero(t, dev.ObjectCount)
+
+	stats, err := adapter.OSS().GetBucketStats(context.Background(), "stprodassets")
+	require.NoError(t, err)
+	assert.Equal(t, int64(18342), stats.ObjectCount)
+}
+
+func TestDNSAdapter(t *testing.T) {
+	ctx := context.Background()
+	adapter, fs := newTestAdapter(t)
+
+	domains, err := adapter.DNS().ListDomains(ctx)
+	require.NoError(t, err)
+	require.Len(t, domains, 1)
+	assert.Equal(t, "example.com", domains[0].DomainName)
+
+	records, err := adapter.DNS().ListRecords(ctx, "example.com")
+	require.NoError(t, err)
+	// SOA 不返回，A 记录集按值展开为两条
+	require.Len(t, records, 4)
+	assert.Equal(t, "A/www/0", records[0].RecordID)
+	assert.Equal(t, "20.51.10.21", records[1].Value)
+	assert.Equal(t, "MX", records[2].Type)
+	assert.Equal(t, 10, records[2].Priority)
+
+	// 新增值追加到已有记录集
+	_, err = adapter.DNS().CreateRecord(ctx, "example.com", types.CreateDNSRecordRequest{
+		RR: "www", Type: "A", Value: "20.51.10.22", TTL: 300,
+	})
+	require.NoError(t, err)
+	require.Len(t, fs.writes, 1)
+	assert.Equal(t, http.MethodPut, fs.writes[0].Method)
+	assert.True(t, strings.HasSuffix(fs.writes[0].Path, "/dnszones/example.com/A/www"))
+	props := fs.writes[0].Body["properties"].(map[string]any)
+	assert.Len(t, props["ARecords"], 3)
+}
+
+func TestTagAdapter_ListTagKeys(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	keys, err := adapter.Tag().ListTagKeys(context.Background(), "eastus")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env", "team"}, keys)
+
+	values, err := adapter.Tag().ListTagValues(context.Background(), "eastus", "ENV")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod", "dev"}, values)
+}
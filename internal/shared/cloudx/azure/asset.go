@@ -0,0 +1,28 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+)
+
+// AssetAdapter Azure资产适配器 (已废弃，委托给 ECSAdapter)
+type AssetAdapter struct {
+	ecs *ECSAdapter
+}
+
+// NewAssetAdapter 创建Azure资产适配器
+func NewAssetAdapter(ecs *ECSAdapter) *AssetAdapter {
+	return &AssetAdapter{ecs: ecs}
+}
+
+// GetRegions 获取支持的地域列表
+func (a *AssetAdapter) GetRegions(ctx context.Context) ([]types.Region, error) {
+	return a.ecs.GetRegions(ctx)
+}
+
+// GetECSInstances 获取虚拟机实例列表
+// Deprecated: 请使用 ECSAdapter.ListInstances
+func (a *AssetAdapter) GetECSInstances(ctx context.Context, region string) ([]types.ECSInstance, error) {
+	return a.ecs.ListInstances(ctx, region)
+}
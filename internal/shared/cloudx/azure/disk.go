@@ -0,0 +1,215 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// DiskAdapter Azure 托管磁盘适配器
+type DiskAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewDiskAdapter 创建 Azure 托管磁盘适配器
+func NewDiskAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *DiskAdapter {
+	return &DiskAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// diskProperties 托管磁盘属性
+type diskProperties struct {
+	OSType            string `json:"osType"`
+	DiskSizeGB        int    `json:"diskSizeGB"`
+	DiskIOPSReadWrite int    `json:"diskIOPSReadWrite"`
+	DiskMBpsReadWrite int    `json:"diskMBpsReadWrite"`
+	DiskState         string `json:"diskState"`
+	TimeCreated       string `json:"timeCreated"`
+	MaxShares         int    `json:"maxShares"`
+	Tier              string `json:"tier"`
+	CreationData      struct {
+		CreateOption     string `json:"createOption"`
+		SourceResourceID string `json:"sourceResourceId"`
+		ImageReference   *struct {
+			ID string `json:"id"`
+		} `json:"imageReference"`
+	} `json:"creationData"`
+	Encryption struct {
+		Type                string `json:"type"`
+		DiskEncryptionSetID string `json:"diskEncryptionSetId"`
+	} `json:"encryption"`
+}
+
+// ListInstances 获取托管磁盘列表
+func (a *DiskAdapter) ListInstances(ctx context.Context, region string) ([]types.DiskInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Compute/disks", computeAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure托管磁盘列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[diskProperties](items)
+
+	disks := make([]types.DiskInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		disks = append(disks, convertDisk(r, props[i]))
+	}
+
+	a.logger.Info("获取Azure托管磁盘列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(disks)))
+
+	return disks, nil
+}
+
+// GetInstance 获取单个磁盘详情
+func (a *DiskAdapter) GetInstance(ctx context.Context, region, diskID string) (*types.DiskInstance, error) {
+	disks, err := a.ListInstancesByIDs(ctx, region, []string{diskID})
+	if err != nil {
+		return nil, err
+	}
+	if len(disks) == 0 {
+		return nil, fmt.Errorf("磁盘不存在: %s", diskID)
+	}
+	return &disks[0], nil
+}
+
+// ListInstancesByIDs 批量获取磁盘
+func (a *DiskAdapter) ListInstancesByIDs(ctx context.Context, region string, diskIDs []string) ([]types.DiskInstance, error) {
+	if len(diskIDs) == 0 {
+		return []types.DiskInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.DiskFilter{DiskIDs: diskIDs})
+}
+
+// GetInstanceStatus 获取磁盘状态
+func (a *DiskAdapter) GetInstanceStatus(ctx context.Context, region, diskID string) (string, error) {
+	disk, err := a.GetInstance(ctx, region, diskID)
+	if err != nil {
+		return "", err
+	}
+	return disk.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取磁盘列表
+func (a *DiskAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.DiskFilter) ([]types.DiskInstance, error) {
+	disks, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return disks, nil
+	}
+
+	result := make([]types.DiskInstance, 0, len(disks))
+	for _, d := range disks {
+		if len(filter.DiskIDs) > 0 && !containsID(filter.DiskIDs, d.DiskID, d.DiskName) {
+			continue
+		}
+		if !matchName(d.DiskName, filter.DiskName) {
+			continue
+		}
+		if filter.DiskType != "" && d.DiskType != filter.DiskType {
+			continue
+		}
+		if filter.Category != "" && !strings.EqualFold(d.Category, filter.Category) {
+			continue
+		}
+		if filter.Status != "" && !strings.EqualFold(d.Status, filter.Status) {
+			continue
+		}
+		if filter.InstanceID != "" && !azurecommon.SameID(d.InstanceID, filter.InstanceID) {
+			continue
+		}
+		if filter.Encrypted != nil && d.Encrypted != *filter.Encrypted {
+			continue
+		}
+		if filter.ResourceGroupID != "" && !strings.EqualFold(d.ResourceGroupID, filter.ResourceGroupID) {
+			continue
+		}
+		if !matchTags(d.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, d)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// ListByInstanceID 获取虚拟机挂载的磁盘
+func (a *DiskAdapter) ListByInstanceID(ctx context.Context, region, instanceID string) ([]types.DiskInstance, error) {
+	return a.ListInstancesWithFilter(ctx, region, &types.DiskFilter{InstanceID: instanceID})
+}
+
+// convertDisk 转换 Azure 托管磁盘为通用格式
+func convertDisk(r azurecommon.Resource, p diskProperties) types.DiskInstance {
+	diskType := "data"
+	if p.OSType != "" {
+		diskType = "system"
+	}
+
+	category := ""
+	if r.SKU != nil {
+		category = r.SKU.Name
+	}
+
+	// Azure 磁盘状态: Attached / Unattached / Reserved / ActiveSAS ...
+	status := strings.ToLower(p.DiskState)
+	switch p.DiskState {
+	case "Attached", "Reserved":
+		status = "in_use"
+	case "Unattached":
+		status = "available"
+	}
+
+	imageID := ""
+	if p.CreationData.ImageReference != nil {
+		imageID = p.CreationData.ImageReference.ID
+	}
+
+	sourceSnapshotID := ""
+	if azurecommon.ResourceTypeFromID(p.CreationData.SourceResourceID) == "Microsoft.Compute/snapshots" {
+		sourceSnapshotID = p.CreationData.SourceResourceID
+	}
+
+	return types.DiskInstance{
+		DiskID:           r.ID,
+		DiskName:         r.Name,
+		DiskType:         diskType,
+		Category:         category,
+		PerformanceLevel: p.Tier,
+		Size:             p.DiskSizeGB,
+		IOPS:             p.DiskIOPSReadWrite,
+		Throughput:       p.DiskMBpsReadWrite,
+		Status:           status,
+		Portable:         true,
+		InstanceID:       r.ManagedBy,
+		InstanceName:     azurecommon.NameFromID(r.ManagedBy),
+		Encrypted:        p.Encryption.Type != "",
+		KMSKeyID:         p.Encryption.DiskEncryptionSetID,
+		SourceSnapshotID: sourceSnapshotID,
+		Zone:             firstZone(r.Location, r.Zones),
+		Region:           azurecommon.NormalizeLocation(r.Location),
+		ImageID:          imageID,
+		ChargeType:       "PostPaid",
+		ResourceGroupID:  azurecommon.ResourceGroupFromID(r.ID),
+		CreationTime:     formatTime(p.TimeCreated),
+		Tags:             copyTags(r.Tags),
+		Provider:         string(types.ProviderAzure),
+		MultiAttach:      p.MaxShares > 1,
+	}
+}
@@ -0,0 +1,481 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const dnsAPIVersion = "2018-05-01"
+
+// DNSAdapter Azure DNS 适配器
+// Azure 以记录集 (名称+类型) 为单位管理解析，记录集内每个值对应一条 DNSRecord，
+// RecordID 格式为 "{类型}/{主机记录}/{序号}"
+type DNSAdapter struct {
+	client *azurecommon.Client
+	logger *elog.Component
+}
+
+// NewDNSAdapter 创建 Azure DNS 适配器
+func NewDNSAdapter(client *azurecommon.Client, logger *elog.Component) *DNSAdapter {
+	return &DNSAdapter{
+		client: client,
+		logger: logger,
+	}
+}
+
+// dnsZoneProperties DNS 区域属性
+type dnsZoneProperties struct {
+	NumberOfRecordSets int64  `json:"numberOfRecordSets"`
+	ZoneType           string `json:"zoneType"`
+}
+
+// recordSetProperties 记录集属性
+type recordSetProperties struct {
+	TTL      int `json:"TTL"`
+	ARecords []struct {
+		IPv4Address string `json:"ipv4Address"`
+	} `json:"ARecords,omitempty"`
+	AAAARecords []struct {
+		IPv6Address string `json:"ipv6Address"`
+	} `json:"AAAARecords,omitempty"`
+	CNAMERecord *struct {
+		CNAME string `json:"cname"`
+	} `json:"CNAMERecord,omitempty"`
+	MXRecords []struct {
+		Preference int    `json:"preference"`
+		Exchange   string `json:"exchange"`
+	} `json:"MXRecords,omitempty"`
+	NSRecords []struct {
+		NSDName string `json:"nsdname"`
+	} `json:"NSRecords,omitempty"`
+	TXTRecords []struct {
+		Value []string `json:"value"`
+	} `json:"TXTRecords,omitempty"`
+	SRVRecords []struct {
+		Priority int    `json:"priority"`
+		Weight   int    `json:"weight"`
+		Port     int    `json:"port"`
+		Target   string `json:"target"`
+	} `json:"SRVRecords,omitempty"`
+	CAARecords []struct {
+		Flags int    `json:"flags"`
+		Tag   string `json:"tag"`
+		Value string `json:"value"`
+	} `json:"CAARecords,omitempty"`
+	PTRRecords []struct {
+		PTRDName string `json:"ptrdname"`
+	} `json:"PTRRecords,omitempty"`
+}
+
+// recordValue 记录集中的单个值
+type recordValue struct {
+	Value    string
+	Priority int
+}
+
+// values 按记录类型展开记录集中的值
+func (p recordSetProperties) values(recordType string) []recordValue {
+	var vals []recordValue
+	switch recordType {
+	case "A":
+		for _, r := range p.ARecords {
+			vals = append(vals, recordValue{Value: r.IPv4Address})
+		}
+	case "AAAA":
+		for _, r := range p.AAAARecords {
+			vals = append(vals, recordValue{Value: r.IPv6Address})
+		}
+	case "CNAME":
+		if p.CNAMERecord != nil {
+			vals = append(vals, recordValue{Value: p.CNAMERecord.CNAME})
+		}
+	case "MX":
+		for _, r := range p.MXRecords {
+			vals = append(vals, recordValue{Value: r.Exchange, Priority: r.Preference})
+		}
+	case "NS":
+		for _, r := range p.NSRecords {
+			vals = append(vals, recordValue{Value: r.NSDName})
+		}
+	case "TXT":
+		for _, r := range p.TXTRecords {
+			vals = append(vals, recordValue{Value: strings.Join(r.Value, "")})
+		}
+	case "SRV":
+		for _, r := range p.SRVRecords {
+			vals = append(vals, recordValue{
+				Value:    fmt.Sprintf("%d %d %s", r.Weight, r.Port, r.Target),
+				Priority: r.Priority,
+			})
+		}
+	case "CAA":
+		for _, r := range p.CAARecords {
+			vals = append(vals, recordValue{Value: fmt.Sprintf("%d %s %q", r.Flags, r.Tag, r.Value)})
+		}
+	case "PTR":
+		for _, r := range p.PTRRecords {
+			vals = append(vals, recordValue{Value: r.PTRDName})
+		}
+	}
+	return vals
+}
+
+// buildRecordSet 由值列表构造记录集属性
+func buildRecordSet(recordType string, ttl int, vals []recordValue) (recordSetProperties, error) {
+	p := recordSetProperties{TTL: ttl}
+	for _, v := range vals {
+		switch recordType {
+		case "A":
+			p.ARecords = append(p.ARecords, struct {
+				IPv4Address string `json:"ipv4Address"`
+			}{v.Value})
+		case "AAAA":
+			p.AAAARecords = append(p.AAAARecords, struct {
+				IPv6Address string `json:"ipv6Address"`
+			}{v.Value})
+		case "CNAME":
+			p.CNAMERecord = &struct {
+				CNAME string `json:"cname"`
+			}{v.Value}
+		case "MX":
+			p.MXRecords = append(p.MXRecords, struct {
+				Preference int    `json:"preference"`
+				Exchange   string `json:"exchange"`
+			}{v.Priority, v.Value})
+		case "NS":
+			p.NSRecords = append(p.NSRecords, struct {
+				NSDName string `json:"nsdname"`
+			}{v.Value})
+		case "TXT":
+			p.TXTRecords = append(p.TXTRecords, struct {
+				Value []string `json:"value"`
+			}{[]string{v.Value}})
+		case "SRV":
+			// 值格式: "权重 端口 目标"
+			fields := strings.Fields(v.Value)
+			if len(fields) != 3 {
+				return p, fmt.Errorf("azure: invalid SRV value %q, expect \"weight port target\"", v.Value)
+			}
+			weight, _ := strconv.Atoi(fields[0])
+			port, _ := strconv.Atoi(fields[1])
+			p.SRVRecords = append(p.SRVRecords, struct {
+				Priority int    `json:"priority"`
+				Weight   int    `json:"weight"`
+				Port     int    `json:"port"`
+				Target   string `json:"target"`
+			}{v.Priority, weight, port, fields[2]})
+		case "PTR":
+			p.PTRRecords = append(p.PTRRecords, struct {
+				PTRDName string `json:"ptrdname"`
+			}{v.Value})
+		default:
+			return p, fmt.Errorf("azure: unsupported DNS record type %s", recordType)
+		}
+	}
+	return p, nil
+}
+
+// recordSet 记录集
+type recordSet struct {
+	ID         string              `json:"id"`
+	Name       string              `json:"name"`
+	Type       string              `json:"type"`
+	Properties recordSetProperties `json:"properties"`
+}
+
+// ListDomains 查询托管域名列表 (仅公网区域)
+func (a *DNSAdapter) ListDomains(ctx context.Context) ([]types.DNSDomain, error) {
+	resources, props, err := a.listZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]types.DNSDomain, 0, len(resources))
+	for i, r := range resources {
+		domains = append(domains, types.DNSDomain{
+			DomainID:    r.ID,
+			DomainName:  r.Name,
+			RecordCount: props[i].NumberOfRecordSets,
+			Status:      "normal",
+		})
+	}
+
+	a.logger.Info("获取Azure DNS域名列表成功", elog.Int("count", len(domains)))
+	return domains, nil
+}
+
+// ListRecords 查询域名下解析记录列表，domain 可以是域名或区域资源ID
+func (a *DNSAdapter) ListRecords(ctx context.Context, domain string) ([]types.DNSRecord, error) {
+	zoneID, err := a.resolveZoneID(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.client.ListARM(ctx, zoneID+"/recordsets", dnsAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("azure: list record sets for zone %s failed: %w", domain, err)
+	}
+
+	var records []types.DNSRecord
+	for _, item := range items {
+		var rs recordSet
+		if err := json.Unmarshal(item, &rs); err != nil {
+			continue
+		}
+		recordType := azurecommon.NameFromID(rs.Type)
+		// SOA 记录由 Azure 托管，不对外暴露
+		if recordType == "SOA" {
+			continue
+		}
+		for i, v := range rs.Properties.values(recordType) {
+			records = append(records, toDNSRecord(domain, rs.Name, recordType, i, rs.Properties.TTL, v))
+		}
+	}
+	return records, nil
+}
+
+// GetRecord 查询单条解析记录详情
+func (a *DNSAdapter) GetRecord(ctx context.Context, domain, recordID string) (*types.DNSRecord, error) {
+	recordType, rr, index, err := parseRecordID(recordID)
+	if err != nil {
+		return nil, err
+	}
+	zoneID, err := a.resolveZoneID(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := a.getRecordSet(ctx, zoneID, recordType, rr)
+	if err != nil {
+		return nil, err
+	}
+	vals := rs.Properties.values(recordType)
+	if rs.ID == "" || index >= len(vals) {
+		return nil, fmt.Errorf("azure: DNS record %s not found in zone %s", recordID, domain)
+	}
+	record := toDNSRecord(domain, rr, recordType, index, rs.Properties.TTL, vals[index])
+	return &record, nil
+}
+
+// CreateRecord 创建解析记录，同名同类型记录集已存在时追加值
+func (a *DNSAdapter) CreateRecord(ctx context.Context, domain string, req types.CreateDNSRecordRequest) (*types.DNSRecord, error) {
+	zoneID, err := a.resolveZoneID(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	recordType := strings.ToUpper(req.Type)
+	rr := normalizeRR(req.RR)
+	rs, err := a.getRecordSet(ctx, zoneID, recordType, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := req.TTL
+	if ttl == 0 {
+		ttl = rs.Properties.TTL
+	}
+	if ttl == 0 {
+		ttl = 300
+	}
+
+	vals := rs.Properties.values(recordType)
+	vals = append(vals, recordValue{Value: req.Value, Priority: req.Priority})
+	if recordType == "CNAME" {
+		vals = vals[len(vals)-1:]
+	}
+	if err := a.putRecordSet(ctx, zoneID, recordType, rr, ttl, vals); err != nil {
+		return nil, fmt.Errorf("azure: create DNS record failed: %w", err)
+	}
+
+	record := toDNSRecord(domain, rr, recordType, len(vals)-1, ttl, vals[len(vals)-1])
+	return &record, nil
+}
+
+// UpdateRecord 修改解析记录
+// 主机记录或类型变化时，从原记录集移除该值并写入新记录集
+func (a *DNSAdapter) UpdateRecord(ctx context.Context, domain, recordID string, req types.UpdateDNSRecordRequest) (*types.DNSRecord, error) {
+	recordType, rr, index, err := parseRecordID(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	newType := recordType
+	if req.Type != "" {
+		newType = strings.ToUpper(req.Type)
+	}
+	newRR := rr
+	if req.RR != "" {
+		newRR = normalizeRR(req.RR)
+	}
+	if newType != recordType || newRR != rr {
+		if err := a.DeleteRecord(ctx, domain, recordID); err != nil {
+			return nil, err
+		}
+		return a.CreateRecord(ctx, domain, types.CreateDNSRecordRequest{
+			RR:       newRR,
+			Type:     newType,
+			Value:    req.Value,
+			TTL:      req.TTL,
+			Priority: req.Priority,
+			Line:     req.Line,
+		})
+	}
+
+	zoneID, err := a.resolveZoneID(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := a.getRecordSet(ctx, zoneID, recordType, rr)
+	if err != nil {
+		return nil, err
+	}
+	vals := rs.Properties.values(recordType)
+	if rs.ID == "" || index >= len(vals) {
+		return nil, fmt.Errorf("azure: DNS record %s not found in zone %s", recordID, domain)
+	}
+
+	if req.Value != "" {
+		vals[index].Value = req.Value
+	}
+	if req.Priority != 0 {
+		vals[index].Priority = req.Priority
+	}
+	ttl := rs.Properties.TTL
+	if req.TTL != 0 {
+		ttl = req.TTL
+	}
+	if err := a.putRecordSet(ctx, zoneID, recordType, rr, ttl, vals); err != nil {
+		return nil, fmt.Errorf("azure: update DNS record %s failed: %w", recordID, err)
+	}
+
+	record := toDNSRecord(domain, rr, recordType, index, ttl, vals[index])
+	return &record, nil
+}
+
+// DeleteRecord 删除解析记录，记录集中最后一个值被删除时删除整个记录集
+func (a *DNSAdapter) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	recordType, rr, index, err := parseRecordID(recordID)
+	if err != nil {
+		return err
+	}
+	zoneID, err := a.resolveZoneID(ctx, domain)
+	if err != nil {
+		return err
+	}
+	rs, err := a.getRecordSet(ctx, zoneID, recordType, rr)
+	if err != nil {
+		return err
+	}
+	vals := rs.Properties.values(recordType)
+	if rs.ID == "" || index >= len(vals) {
+		return fmt.Errorf("azure: DNS record %s not found in zone %s", recordID, domain)
+	}
+
+	vals = append(vals[:index], vals[index+1:]...)
+	if len(vals) == 0 {
+		path := fmt.Sprintf("%s/%s/%s", zoneID, recordType, rr)
+		return a.client.DoARM(ctx, http.MethodDelete, path, dnsAPIVersion, nil, nil, nil)
+	}
+	return a.putRecordSet(ctx, zoneID, recordType, rr, rs.Properties.TTL, vals)
+}
+
+// listZones 获取订阅下所有 DNS 区域
+func (a *DNSAdapter) listZones(ctx context.Context) ([]azurecommon.Resource, []dnsZoneProperties, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Network/dnszones", dnsAPIVersion, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("azure: list dns zones failed: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[dnsZoneProperties](items)
+	return resources, props, nil
+}
+
+// resolveZoneID 将域名解析为 DNS 区域资源ID
+func (a *DNSAdapter) resolveZoneID(ctx context.Context, domain string) (string, error) {
+	if strings.HasPrefix(domain, "/subscriptions/") {
+		return domain, nil
+	}
+	resources, _, err := a.listZones(ctx)
+	if err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(domain, ".")
+	for _, r := range resources {
+		if strings.EqualFold(r.Name, name) {
+			return r.ID, nil
+		}
+	}
+	return "", fmt.Errorf("azure: dns zone %s not found", domain)
+}
+
+// getRecordSet 获取记录集，不存在时返回空记录集
+func (a *DNSAdapter) getRecordSet(ctx context.Context, zoneID, recordType, rr string) (*recordSet, error) {
+	var rs recordSet
+	path := fmt.Sprintf("%s/%s/%s", zoneID, recordType, rr)
+	if err := a.client.DoARM(ctx, http.MethodGet, path, dnsAPIVersion, nil, nil, &rs); err != nil {
+		if azurecommon.IsNotFoundError(err) {
+			return &recordSet{}, nil
+		}
+		return nil, fmt.Errorf("azure: get record set %s/%s failed: %w", recordType, rr, err)
+	}
+	return &rs, nil
+}
+
+// putRecordSet 整体写入记录集
+func (a *DNSAdapter) putRecordSet(ctx context.Context, zoneID, recordType, rr string, ttl int, vals []recordValue) error {
+	props, err := buildRecordSet(recordType, ttl, vals)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("%s/%s/%s", zoneID, recordType, rr)
+	body := map[string]any{"properties": props}
+	return a.client.DoARM(ctx, http.MethodPut, path, dnsAPIVersion, nil, body, nil)
+}
+
+// toDNSRecord 构造通用解析记录
+func toDNSRecord(domain, rr, recordType string, index, ttl int, v recordValue) types.DNSRecord {
+	return types.DNSRecord{
+		RecordID: fmt.Sprintf("%s/%s/%d", recordType, rr, index),
+		Domain:   domain,
+		RR:       rr,
+		Type:     recordType,
+		Value:    v.Value,
+		TTL:      ttl,
+		Priority: v.Priority,
+		Line:     "default",
+		Status:   "enable",
+	}
+}
+
+// parseRecordID 解析 RecordID: "{类型}/{主机记录}/{序号}"
+func parseRecordID(recordID string) (recordType, rr string, index int, err error) {
+	parts := strings.Split(recordID, "/")
+	if len(parts) != 3 {
+		return "", "", 0, fmt.Errorf("azure: invalid DNS record id %q", recordID)
+	}
+	index, err = strconv.Atoi(parts[2])
+	if err != nil || index < 0 {
+		return "", "", 0, fmt.Errorf("azure: invalid DNS record id %q", recordID)
+	}
+	return strings.ToUpper(parts[0]), parts[1], index, nil
+}
+
+// normalizeRR 统一主机记录格式，空值表示根域名
+func normalizeRR(rr string) string {
+	if rr == "" {
+		return "@"
+	}
+	return rr
+}
@@ -0,0 +1,360 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	computeAPIVersion = "2023-09-01"
+	networkAPIVersion = "2023-09-01"
+)
+
+// ECSAdapter Azure 虚拟机适配器
+type ECSAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewECSAdapter 创建 Azure 虚拟机适配器
+func NewECSAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *ECSAdapter {
+	return &ECSAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// vmProperties 虚拟机属性
+type vmProperties struct {
+	VMID            string `json:"vmId"`
+	TimeCreated     string `json:"timeCreated"`
+	Priority        string `json:"priority"`
+	HardwareProfile struct {
+		VMSize string `json:"vmSize"`
+	} `json:"hardwareProfile"`
+	StorageProfile struct {
+		ImageReference struct {
+			ID        string `json:"id"`
+			Publisher string `json:"publisher"`
+			Offer     string `json:"offer"`
+			SKU       string `json:"sku"`
+			Version   string `json:"version"`
+		} `json:"imageReference"`
+		OSDisk    vmDisk   `json:"osDisk"`
+		DataDisks []vmDisk `json:"dataDisks"`
+	} `json:"storageProfile"`
+	OSProfile struct {
+		ComputerName string `json:"computerName"`
+	} `json:"osProfile"`
+	NetworkProfile struct {
+		NetworkInterfaces []azurecommon.SubResource `json:"networkInterfaces"`
+	} `json:"networkProfile"`
+	InstanceView struct {
+		Statuses []struct {
+			Code string `json:"code"`
+		} `json:"statuses"`
+	} `json:"instanceView"`
+}
+
+// vmDisk 虚拟机挂载磁盘
+type vmDisk struct {
+	Name         string `json:"name"`
+	OSType       string `json:"osType"`
+	Lun          int    `json:"lun"`
+	DiskSizeGB   int    `json:"diskSizeGB"`
+	DeleteOption string `json:"deleteOption"`
+	ManagedDisk  struct {
+		ID                 string `json:"id"`
+		StorageAccountType string `json:"storageAccountType"`
+	} `json:"managedDisk"`
+}
+
+// nicProperties 网卡属性
+type nicProperties struct {
+	NetworkSecurityGroup *azurecommon.SubResource `json:"networkSecurityGroup"`
+	IPConfigurations     []struct {
+		Properties struct {
+			Primary          bool                     `json:"primary"`
+			PrivateIPAddress string                   `json:"privateIPAddress"`
+			Subnet           *azurecommon.SubResource `json:"subnet"`
+			PublicIPAddress  *azurecommon.SubResource `json:"publicIPAddress"`
+		} `json:"properties"`
+	} `json:"ipConfigurations"`
+}
+
+// nicInfo 从网卡解析出的网络信息
+type nicInfo struct {
+	privateIP  string
+	subnetID   string
+	publicIPID string
+	nsgID      string
+}
+
+// GetRegions 获取支持的地域列表
+func (a *ECSAdapter) GetRegions(ctx context.Context) ([]types.Region, error) {
+	locations, err := a.client.ListLocations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure地域列表失败: %w", err)
+	}
+
+	regions := make([]types.Region, 0, len(locations))
+	for _, loc := range locations {
+		regions = append(regions, types.Region{
+			ID:          loc.Name,
+			Name:        loc.Name,
+			LocalName:   loc.DisplayName,
+			Description: loc.RegionalDisplayName,
+		})
+	}
+	return regions, nil
+}
+
+// ListInstances 获取虚拟机列表
+func (a *ECSAdapter) ListInstances(ctx context.Context, region string) ([]types.ECSInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// statusOnly=true 同时返回 instanceView 中的电源状态
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Compute/virtualMachines",
+		computeAPIVersion, url.Values{"statusOnly": []string{"true"}})
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure虚拟机列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[vmProperties](items)
+
+	nics, err := a.loadNICs(ctx, subPath)
+	if err != nil {
+		a.logger.Warn("获取Azure网卡信息失败，虚拟机IP信息将为空", elog.FieldErr(err))
+	}
+	publicIPs, err := a.loadPublicIPs(ctx, subPath)
+	if err != nil {
+		a.logger.Warn("获取Azure公网IP信息失败", elog.FieldErr(err))
+	}
+
+	instances := make([]types.ECSInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		instances = append(instances, convertVM(r, props[i], nics, publicIPs))
+	}
+
+	a.logger.Info("获取Azure虚拟机列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(instances)))
+
+	return instances, nil
+}
+
+// GetInstance 获取单个虚拟机详情
+func (a *ECSAdapter) GetInstance(ctx context.Context, region, instanceID string) (*types.ECSInstance, error) {
+	instances, err := a.ListInstancesByIDs(ctx, region, []string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("虚拟机不存在: %s", instanceID)
+	}
+	return &instances[0], nil
+}
+
+// ListInstancesByIDs 批量获取虚拟机
+func (a *ECSAdapter) ListInstancesByIDs(ctx context.Context, region string, instanceIDs []string) ([]types.ECSInstance, error) {
+	if len(instanceIDs) == 0 {
+		return []types.ECSInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &cloudx.ECSInstanceFilter{InstanceIDs: instanceIDs})
+}
+
+// GetInstanceStatus 获取虚拟机状态
+func (a *ECSAdapter) GetInstanceStatus(ctx context.Context, region, instanceID string) (string, error) {
+	instance, err := a.GetInstance(ctx, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取虚拟机列表
+func (a *ECSAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *cloudx.ECSInstanceFilter) ([]types.ECSInstance, error) {
+	instances, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return instances, nil
+	}
+
+	result := make([]types.ECSInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(filter.InstanceIDs) > 0 && !containsID(filter.InstanceIDs, inst.InstanceID, inst.InstanceName) {
+			continue
+		}
+		if !matchName(inst.InstanceName, filter.InstanceName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, inst.Status) {
+			continue
+		}
+		if filter.VPCID != "" && !azurecommon.SameID(inst.VPCID, filter.VPCID) {
+			continue
+		}
+		if filter.Zone != "" && inst.Zone != filter.Zone {
+			continue
+		}
+		if !matchTags(inst.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, inst)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// loadNICs 加载订阅下所有网卡，key 为网卡ID(小写)
+func (a *ECSAdapter) loadNICs(ctx context.Context, subPath string) (map[string]nicInfo, error) {
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Network/networkInterfaces", networkAPIVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	resources, props := azurecommon.DecodeResources[nicProperties](items)
+
+	result := make(map[string]nicInfo, len(resources))
+	for i, r := range resources {
+		info := nicInfo{}
+		if props[i].NetworkSecurityGroup != nil {
+			info.nsgID = props[i].NetworkSecurityGroup.ID
+		}
+		for j, ipc := range props[i].IPConfigurations {
+			// 优先使用主 IP 配置
+			if j > 0 && !ipc.Properties.Primary {
+				continue
+			}
+			info.privateIP = ipc.Properties.PrivateIPAddress
+			if ipc.Properties.Subnet != nil {
+				info.subnetID = ipc.Properties.Subnet.ID
+			}
+			if ipc.Properties.PublicIPAddress != nil {
+				info.publicIPID = ipc.Properties.PublicIPAddress.ID
+			}
+		}
+		result[strings.ToLower(r.ID)] = info
+	}
+	return result, nil
+}
+
+// loadPublicIPs 加载订阅下所有公网IP，key 为公网IP资源ID(小写)，value 为IP地址
+func (a *ECSAdapter) loadPublicIPs(ctx context.Context, subPath string) (map[string]string, error) {
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Network/publicIPAddresses", networkAPIVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	resources, props := azurecommon.DecodeResources[publicIPProperties](items)
+
+	result := make(map[string]string, len(resources))
+	for i, r := range resources {
+		result[strings.ToLower(r.ID)] = props[i].IPAddress
+	}
+	return result, nil
+}
+
+// convertVM 转换 Azure 虚拟机为通用格式
+func convertVM(r azurecommon.Resource, p vmProperties, nics map[string]nicInfo, publicIPs map[string]string) types.ECSInstance {
+	status := types.StatusUnknown
+	for _, s := range p.InstanceView.Statuses {
+		if strings.HasPrefix(s.Code, "PowerState/") {
+			status = types.NormalizeStatus(strings.TrimPrefix(s.Code, "PowerState/"))
+		}
+	}
+
+	chargeType := "PostPaid"
+	if strings.EqualFold(p.Priority, "Spot") {
+		chargeType = "Spot"
+	}
+
+	inst := types.ECSInstance{
+		InstanceID:   r.ID,
+		InstanceName: r.Name,
+		Status:       status,
+		Region:       azurecommon.NormalizeLocation(r.Location),
+		Zone:         firstZone(r.Location, r.Zones),
+		InstanceType: p.HardwareProfile.VMSize,
+		OSType:       strings.ToLower(p.StorageProfile.OSDisk.OSType),
+		ChargeType:   chargeType,
+		CreationTime: formatTime(p.TimeCreated),
+		NetworkType:  "vpc",
+		ProjectID:    azurecommon.ResourceGroupFromID(r.ID),
+		ProjectName:  azurecommon.ResourceGroupFromID(r.ID),
+		Tags:         copyTags(r.Tags),
+		Provider:     string(types.ProviderAzure),
+		HostName:     p.OSProfile.ComputerName,
+	}
+
+	// VM Size 形如 Standard_D2s_v3，规格族取中间段
+	if parts := strings.Split(p.HardwareProfile.VMSize, "_"); len(parts) >= 2 {
+		inst.InstanceTypeFamily = parts[1]
+	}
+
+	img := p.StorageProfile.ImageReference
+	if img.ID != "" {
+		inst.ImageID = img.ID
+	} else if img.Publisher != "" {
+		inst.ImageID = strings.Join([]string{img.Publisher, img.Offer, img.SKU, img.Version}, ":")
+		inst.ImageName = img.Offer + " " + img.SKU
+		inst.OSName = inst.ImageName
+	}
+
+	osDisk := p.StorageProfile.OSDisk
+	inst.SystemDisk = types.SystemDisk{
+		DiskID:   osDisk.ManagedDisk.ID,
+		Category: osDisk.ManagedDisk.StorageAccountType,
+		Size:     osDisk.DiskSizeGB,
+	}
+	for _, d := range p.StorageProfile.DataDisks {
+		inst.DataDisks = append(inst.DataDisks, types.DataDisk{
+			DiskID:             d.ManagedDisk.ID,
+			Category:           d.ManagedDisk.StorageAccountType,
+			Size:               d.DiskSizeGB,
+			Device:             fmt.Sprintf("lun%d", d.Lun),
+			DeleteWithInstance: strings.EqualFold(d.DeleteOption, "Delete"),
+		})
+	}
+
+	// 网络信息取第一块网卡
+	for _, ref := range p.NetworkProfile.NetworkInterfaces {
+		nic, ok := nics[strings.ToLower(ref.ID)]
+		if !ok {
+			continue
+		}
+		inst.PrivateIP = nic.privateIP
+		inst.VSwitchID = nic.subnetID
+		inst.VSwitchName = azurecommon.NameFromID(nic.subnetID)
+		// 子网ID形如 {vnetID}/subnets/{name}
+		if nic.subnetID != "" {
+			inst.VPCID = azurecommon.ParentID(nic.subnetID)
+			inst.VPCName = azurecommon.NameFromID(inst.VPCID)
+		}
+		if nic.publicIPID != "" {
+			inst.PublicIP = publicIPs[strings.ToLower(nic.publicIPID)]
+		}
+		if nic.nsgID != "" {
+			inst.SecurityGroups = append(inst.SecurityGroups, types.SecurityGroup{
+				ID:   nic.nsgID,
+				Name: azurecommon.NameFromID(nic.nsgID),
+			})
+		}
+		break
+	}
+
+	return inst
+}
@@ -0,0 +1,189 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// EIPAdapter Azure 公网IP适配器
+type EIPAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewEIPAdapter 创建 Azure 公网IP适配器
+func NewEIPAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *EIPAdapter {
+	return &EIPAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// publicIPProperties 公网IP属性
+type publicIPProperties struct {
+	IPAddress                string                   `json:"ipAddress"`
+	PublicIPAllocationMethod string                   `json:"publicIPAllocationMethod"`
+	PublicIPAddressVersion   string                   `json:"publicIPAddressVersion"`
+	ProvisioningState        string                   `json:"provisioningState"`
+	IPConfiguration          *azurecommon.SubResource `json:"ipConfiguration"`
+	NatGateway               *azurecommon.SubResource `json:"natGateway"`
+	DNSSettings              *struct {
+		FQDN string `json:"fqdn"`
+	} `json:"dnsSettings"`
+}
+
+// ListInstances 获取公网IP列表
+func (a *EIPAdapter) ListInstances(ctx context.Context, region string) ([]types.EIPInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Network/publicIPAddresses", networkAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure公网IP列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[publicIPProperties](items)
+
+	eips := make([]types.EIPInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		eips = append(eips, convertPublicIP(r, props[i]))
+	}
+
+	a.logger.Info("获取Azure公网IP列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(eips)))
+
+	return eips, nil
+}
+
+// GetInstance 获取单个公网IP详情
+func (a *EIPAdapter) GetInstance(ctx context.Context, region, allocationID string) (*types.EIPInstance, error) {
+	eips, err := a.ListInstancesByIDs(ctx, region, []string{allocationID})
+	if err != nil {
+		return nil, err
+	}
+	if len(eips) == 0 {
+		return nil, fmt.Errorf("公网IP不存在: %s", allocationID)
+	}
+	return &eips[0], nil
+}
+
+// ListInstancesByIDs 批量获取公网IP
+func (a *EIPAdapter) ListInstancesByIDs(ctx context.Context, region string, allocationIDs []string) ([]types.EIPInstance, error) {
+	if len(allocationIDs) == 0 {
+		return []types.EIPInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.EIPInstanceFilter{AllocationIDs: allocationIDs})
+}
+
+// GetInstanceStatus 获取公网IP状态
+func (a *EIPAdapter) GetInstanceStatus(ctx context.Context, region, allocationID string) (string, error) {
+	eip, err := a.GetInstance(ctx, region, allocationID)
+	if err != nil {
+		return "", err
+	}
+	return eip.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取公网IP列表
+func (a *EIPAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.EIPInstanceFilter) ([]types.EIPInstance, error) {
+	eips, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return eips, nil
+	}
+
+	result := make([]types.EIPInstance, 0, len(eips))
+	for _, e := range eips {
+		if len(filter.AllocationIDs) > 0 && !containsID(filter.AllocationIDs, e.AllocationID, e.Name) {
+			continue
+		}
+		if len(filter.IPAddresses) > 0 && !containsFold(filter.IPAddresses, e.IPAddress) {
+			continue
+		}
+		if !matchName(e.Name, filter.Name) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, e.Status) {
+			continue
+		}
+		if filter.InstanceID != "" && !azurecommon.SameID(e.InstanceID, filter.InstanceID) {
+			continue
+		}
+		if filter.InstanceType != "" && !strings.EqualFold(e.InstanceType, filter.InstanceType) {
+			continue
+		}
+		if filter.AssociatedOnly && e.InstanceID == "" {
+			continue
+		}
+		if filter.UnassociatedOnly && e.InstanceID != "" {
+			continue
+		}
+		if !matchTags(e.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertPublicIP 转换 Azure 公网IP为通用格式
+func convertPublicIP(r azurecommon.Resource, p publicIPProperties) types.EIPInstance {
+	status := "Available"
+	instanceID := ""
+	instanceType := ""
+
+	// ipConfiguration 指向绑定对象的子资源，如 {nicID}/ipConfigurations/ipconfig1
+	if p.IPConfiguration != nil && p.IPConfiguration.ID != "" {
+		status = "InUse"
+		instanceID = azurecommon.ParentID(p.IPConfiguration.ID)
+		switch azurecommon.ResourceTypeFromID(instanceID) {
+		case "Microsoft.Network/networkInterfaces":
+			instanceType = "NetworkInterface"
+		case "Microsoft.Network/loadBalancers":
+			instanceType = "SlbInstance"
+		case "Microsoft.Network/virtualNetworkGateways":
+			instanceType = "VpnGateway"
+		default:
+			instanceType = azurecommon.ResourceTypeFromID(instanceID)
+		}
+	} else if p.NatGateway != nil && p.NatGateway.ID != "" {
+		status = "InUse"
+		instanceID = p.NatGateway.ID
+		instanceType = "Nat"
+	}
+
+	return types.EIPInstance{
+		AllocationID:       r.ID,
+		Name:               r.Name,
+		Status:             status,
+		Region:             azurecommon.NormalizeLocation(r.Location),
+		Zone:               firstZone(r.Location, r.Zones),
+		IPAddress:          p.IPAddress,
+		IPVersion:          strings.ToLower(p.PublicIPAddressVersion),
+		InternetChargeType: "PayByTraffic",
+		InstanceID:         instanceID,
+		InstanceType:       instanceType,
+		InstanceName:       azurecommon.NameFromID(instanceID),
+		Netmode:            "public",
+		ResourceGroupID:    azurecommon.ResourceGroupFromID(r.ID),
+		ChargeType:         "PostPaid",
+		ProjectID:          azurecommon.ResourceGroupFromID(r.ID),
+		ProjectName:        azurecommon.ResourceGroupFromID(r.ID),
+		Tags:               copyTags(r.Tags),
+		Provider:           string(types.ProviderAzure),
+	}
+}
@@ -0,0 +1,238 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// LBAdapter Azure 负载均衡适配器
+type LBAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewLBAdapter 创建 Azure 负载均衡适配器
+func NewLBAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *LBAdapter {
+	return &LBAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// lbProperties 负载均衡属性
+type lbProperties struct {
+	ProvisioningState        string `json:"provisioningState"`
+	FrontendIPConfigurations []struct {
+		ID         string `json:"id"`
+		Properties struct {
+			PrivateIPAddress string                   `json:"privateIPAddress"`
+			PublicIPAddress  *azurecommon.SubResource `json:"publicIPAddress"`
+			Subnet           *azurecommon.SubResource `json:"subnet"`
+		} `json:"properties"`
+	} `json:"frontendIPConfigurations"`
+	BackendAddressPools []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			BackendIPConfigurations      []azurecommon.SubResource `json:"backendIPConfigurations"`
+			LoadBalancerBackendAddresses []struct {
+				Name       string `json:"name"`
+				Properties struct {
+					IPAddress string `json:"ipAddress"`
+				} `json:"properties"`
+			} `json:"loadBalancerBackendAddresses"`
+		} `json:"properties"`
+	} `json:"backendAddressPools"`
+	LoadBalancingRules []struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Properties struct {
+			Protocol     string `json:"protocol"`
+			FrontendPort int    `json:"frontendPort"`
+			BackendPort  int    `json:"backendPort"`
+		} `json:"properties"`
+	} `json:"loadBalancingRules"`
+}
+
+// ListInstances 获取负载均衡列表
+func (a *LBAdapter) ListInstances(ctx context.Context, region string) ([]types.LBInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Network/loadBalancers", networkAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure负载均衡列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[lbProperties](items)
+
+	lbs := make([]types.LBInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		lbs = append(lbs, convertLB(r, props[i]))
+	}
+
+	a.logger.Info("获取Azure负载均衡列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(lbs)))
+
+	return lbs, nil
+}
+
+// GetInstance 获取单个负载均衡详情
+func (a *LBAdapter) GetInstance(ctx context.Context, region, lbID string) (*types.LBInstance, error) {
+	lbs, err := a.ListInstancesByIDs(ctx, region, []string{lbID})
+	if err != nil {
+		return nil, err
+	}
+	if len(lbs) == 0 {
+		return nil, fmt.Errorf("负载均衡不存在: %s", lbID)
+	}
+	return &lbs[0], nil
+}
+
+// ListInstancesByIDs 批量获取负载均衡
+func (a *LBAdapter) ListInstancesByIDs(ctx context.Context, region string, lbIDs []string) ([]types.LBInstance, error) {
+	if len(lbIDs) == 0 {
+		return []types.LBInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.LBInstanceFilter{LoadBalancerIDs: lbIDs})
+}
+
+// GetInstanceStatus 获取负载均衡状态
+func (a *LBAdapter) GetInstanceStatus(ctx context.Context, region, lbID string) (string, error) {
+	lb, err := a.GetInstance(ctx, region, lbID)
+	if err != nil {
+		return "", err
+	}
+	return lb.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取负载均衡列表
+func (a *LBAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.LBInstanceFilter) ([]types.LBInstance, error) {
+	lbs, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return lbs, nil
+	}
+
+	result := make([]types.LBInstance, 0, len(lbs))
+	for _, lb := range lbs {
+		if len(filter.LoadBalancerIDs) > 0 && !containsID(filter.LoadBalancerIDs, lb.LoadBalancerID, lb.LoadBalancerName) {
+			continue
+		}
+		if !matchName(lb.LoadBalancerName, filter.LoadBalancerName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, lb.Status) {
+			continue
+		}
+		if filter.AddressType != "" && lb.AddressType != filter.AddressType {
+			continue
+		}
+		if filter.VPCID != "" && !azurecommon.SameID(lb.VPCID, filter.VPCID) {
+			continue
+		}
+		if !matchTags(lb.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, lb)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertLB 转换 Azure 负载均衡为通用格式
+// 公网型负载均衡的 Address 为前端公网IP资源ID
+func convertLB(r azurecommon.Resource, p lbProperties) types.LBInstance {
+	lb := types.LBInstance{
+		LoadBalancerID:   r.ID,
+		LoadBalancerName: r.Name,
+		LoadBalancerType: "nlb",
+		Status:           provisioningStatus(p.ProvisioningState),
+		Region:           azurecommon.NormalizeLocation(r.Location),
+		AddressType:      "intranet",
+		AddressIPVersion: "ipv4",
+		NetworkType:      "vpc",
+		ChargeType:       "PostPaid",
+		ProjectID:        azurecommon.ResourceGroupFromID(r.ID),
+		ProjectName:      azurecommon.ResourceGroupFromID(r.ID),
+		ResourceGroupID:  azurecommon.ResourceGroupFromID(r.ID),
+		Tags:             copyTags(r.Tags),
+		Provider:         string(types.ProviderAzure),
+	}
+	if r.SKU != nil {
+		lb.LoadBalancerSpec = r.SKU.Name
+		lb.LoadBalancerEdition = r.SKU.Tier
+	}
+
+	if len(p.FrontendIPConfigurations) > 0 {
+		fe := p.FrontendIPConfigurations[0].Properties
+		if fe.PublicIPAddress != nil {
+			lb.AddressType = "internet"
+			lb.Address = fe.PublicIPAddress.ID
+		} else {
+			lb.Address = fe.PrivateIPAddress
+		}
+		if fe.Subnet != nil {
+			lb.VSwitchID = fe.Subnet.ID
+			lb.VPCID = azurecommon.ParentID(fe.Subnet.ID)
+			lb.VPCName = azurecommon.NameFromID(lb.VPCID)
+		}
+	}
+
+	for _, rule := range p.LoadBalancingRules {
+		lb.Listeners = append(lb.Listeners, types.LBListener{
+			ListenerID:       rule.ID,
+			ListenerPort:     rule.Properties.FrontendPort,
+			ListenerProtocol: strings.ToUpper(rule.Properties.Protocol),
+			BackendPort:      rule.Properties.BackendPort,
+			Status:           "running",
+			Description:      rule.Name,
+		})
+	}
+
+	for _, pool := range p.BackendAddressPools {
+		// NIC 类型后端: {nicID}/ipConfigurations/{name}
+		for _, ipc := range pool.Properties.BackendIPConfigurations {
+			nicID := azurecommon.ParentID(ipc.ID)
+			lb.BackendServers = append(lb.BackendServers, types.LBBackendServer{
+				ServerID:   ipc.ID,
+				ServerName: azurecommon.NameFromID(nicID),
+				InstanceID: nicID,
+				Type:       "eni",
+				Weight:     100,
+			})
+		}
+		// IP 类型后端
+		for _, addr := range pool.Properties.LoadBalancerBackendAddresses {
+			if addr.Properties.IPAddress == "" {
+				continue
+			}
+			lb.BackendServers = append(lb.BackendServers, types.LBBackendServer{
+				ServerID:   addr.Name,
+				ServerName: addr.Name,
+				IP:         addr.Properties.IPAddress,
+				Type:       "ip",
+				Weight:     100,
+			})
+		}
+	}
+
+	lb.ListenerCount = len(lb.Listeners)
+	lb.BackendServerCount = len(lb.BackendServers)
+	// 后端池随负载均衡资源一并返回，无需额外查询
+	lb.BackendFetched = true
+	return lb
+}
@@ -0,0 +1,243 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const cosmosAPIVersion = "2023-04-15"
+
+// MongoDBAdapter Azure Cosmos DB 适配器
+// 同步所有 Cosmos DB 账号，DBInstanceType 记录账号使用的 API (mongodb/sql/cassandra/gremlin/table)
+type MongoDBAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewMongoDBAdapter 创建 Azure Cosmos DB 适配器
+func NewMongoDBAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *MongoDBAdapter {
+	return &MongoDBAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// cosmosProperties Cosmos DB 账号属性
+type cosmosProperties struct {
+	ProvisioningState string `json:"provisioningState"`
+	DocumentEndpoint  string `json:"documentEndpoint"`
+	MinimalTLSVersion string `json:"minimalTlsVersion"`
+	APIProperties     *struct {
+		ServerVersion string `json:"serverVersion"`
+	} `json:"apiProperties"`
+	Locations []struct {
+		LocationName     string `json:"locationName"`
+		FailoverPriority int    `json:"failoverPriority"`
+	} `json:"locations"`
+	Capabilities []struct {
+		Name string `json:"name"`
+	} `json:"capabilities"`
+	IPRules []struct {
+		IPAddressOrRange string `json:"ipAddressOrRange"`
+	} `json:"ipRules"`
+	VirtualNetworkRules []struct {
+		ID string `json:"id"`
+	} `json:"virtualNetworkRules"`
+	BackupPolicy *struct {
+		Type                   string `json:"type"`
+		PeriodicModeProperties *struct {
+			BackupRetentionIntervalInHours int `json:"backupRetentionIntervalInHours"`
+		} `json:"periodicModeProperties"`
+	} `json:"backupPolicy"`
+}
+
+// hasCapability 判断账号是否开启指定能力
+func (p cosmosProperties) hasCapability(name string) bool {
+	for _, c := range p.Capabilities {
+		if strings.EqualFold(c.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListInstances 获取 Cosmos DB 账号列表
+func (a *MongoDBAdapter) ListInstances(ctx context.Context, region string) ([]types.MongoDBInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.DocumentDB/databaseAccounts", cosmosAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure Cosmos DB账号列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[cosmosProperties](items)
+
+	instances := make([]types.MongoDBInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		instances = append(instances, convertCosmosAccount(r, props[i]))
+	}
+
+	a.logger.Info("获取Azure Cosmos DB账号列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(instances)))
+
+	return instances, nil
+}
+
+// GetInstance 获取单个 Cosmos DB 账号详情
+func (a *MongoDBAdapter) GetInstance(ctx context.Context, region, instanceID string) (*types.MongoDBInstance, error) {
+	instances, err := a.ListInstancesByIDs(ctx, region, []string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("Cosmos DB账号不存在: %s", instanceID)
+	}
+	return &instances[0], nil
+}
+
+// ListInstancesByIDs 批量获取 Cosmos DB 账号
+func (a *MongoDBAdapter) ListInstancesByIDs(ctx context.Context, region string, instanceIDs []string) ([]types.MongoDBInstance, error) {
+	if len(instanceIDs) == 0 {
+		return []types.MongoDBInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.MongoDBInstanceFilter{InstanceIDs: instanceIDs})
+}
+
+// GetInstanceStatus 获取 Cosmos DB 账号状态
+func (a *MongoDBAdapter) GetInstanceStatus(ctx context.Context, region, instanceID string) (string, error) {
+	instance, err := a.GetInstance(ctx, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取 Cosmos DB 账号列表
+func (a *MongoDBAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.MongoDBInstanceFilter) ([]types.MongoDBInstance, error) {
+	instances, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return instances, nil
+	}
+
+	result := make([]types.MongoDBInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(filter.InstanceIDs) > 0 && !containsID(filter.InstanceIDs, inst.InstanceID, inst.InstanceName) {
+			continue
+		}
+		if !matchName(inst.InstanceName, filter.InstanceName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, inst.Status) {
+			continue
+		}
+		if filter.DBInstanceType != "" && !strings.EqualFold(inst.DBInstanceType, filter.DBInstanceType) {
+			continue
+		}
+		if filter.VPCID != "" && !azurecommon.SameID(inst.VPCID, filter.VPCID) {
+			continue
+		}
+		if !matchTags(inst.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, inst)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// cosmosAPIType 识别 Cosmos DB 账号使用的 API
+func cosmosAPIType(kind string, p cosmosProperties) string {
+	switch {
+	case strings.EqualFold(kind, "MongoDB"):
+		return "mongodb"
+	case p.hasCapability("EnableCassandra"):
+		return "cassandra"
+	case p.hasCapability("EnableGremlin"):
+		return "gremlin"
+	case p.hasCapability("EnableTable"):
+		return "table"
+	default:
+		return "sql"
+	}
+}
+
+// convertCosmosAccount 转换 Cosmos DB 账号为通用格式
+func convertCosmosAccount(r azurecommon.Resource, p cosmosProperties) types.MongoDBInstance {
+	instanceClass := "Provisioned"
+	if p.hasCapability("EnableServerless") {
+		instanceClass = "Serverless"
+	}
+
+	engineVersion := ""
+	if p.APIProperties != nil {
+		engineVersion = p.APIProperties.ServerVersion
+	}
+
+	// failoverPriority 为 0 的是写区域，其余为只读副本区域
+	secondary := make([]string, 0, len(p.Locations))
+	for _, loc := range p.Locations {
+		if loc.FailoverPriority > 0 {
+			secondary = append(secondary, azurecommon.NormalizeLocation(loc.LocationName))
+		}
+	}
+
+	whitelist := make([]string, 0, len(p.IPRules))
+	for _, rule := range p.IPRules {
+		whitelist = append(whitelist, rule.IPAddressOrRange)
+	}
+
+	vpcID, vswitchID := "", ""
+	if len(p.VirtualNetworkRules) > 0 {
+		vswitchID = p.VirtualNetworkRules[0].ID
+		vpcID = azurecommon.ParentID(vswitchID)
+	}
+
+	retentionDays := 0
+	if p.BackupPolicy != nil && p.BackupPolicy.PeriodicModeProperties != nil {
+		retentionDays = p.BackupPolicy.PeriodicModeProperties.BackupRetentionIntervalInHours / 24
+	}
+
+	description := ""
+	if len(secondary) > 0 {
+		description = "replicas: " + strings.Join(secondary, ",")
+	}
+
+	return types.MongoDBInstance{
+		InstanceID:            r.ID,
+		InstanceName:          r.Name,
+		Status:                types.NormalizeMongoDBStatus(p.ProvisioningState),
+		Region:                azurecommon.NormalizeLocation(r.Location),
+		EngineVersion:         engineVersion,
+		InstanceClass:         instanceClass,
+		DBInstanceType:        cosmosAPIType(r.Kind, p),
+		ConnectionString:      p.DocumentEndpoint,
+		Port:                  443,
+		VPCID:                 vpcID,
+		VSwitchID:             vswitchID,
+		NodeCount:             len(p.Locations),
+		ChargeType:            "PostPaid",
+		SecurityIPList:        whitelist,
+		SSLEnabled:            true,
+		BackupRetentionPeriod: retentionDays,
+		ProjectID:             azurecommon.ResourceGroupFromID(r.ID),
+		ProjectName:           azurecommon.ResourceGroupFromID(r.ID),
+		Tags:                  copyTags(r.Tags),
+		Description:           description,
+		Provider:              string(types.ProviderAzure),
+	}
+}
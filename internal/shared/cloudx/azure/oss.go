@@ -0,0 +1,258 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	storageAPIVersion = "2023-01-01"
+	metricsAPIVersion = "2018-01-01"
+)
+
+// StorageAccountAdapter Azure 存储账号适配器
+// 以存储账号作为存储桶同步，容量统计来自 Azure Monitor 指标
+type StorageAccountAdapter struct {
+	client *azurecommon.Client
+	logger *elog.Component
+}
+
+// NewStorageAccountAdapter 创建 Azure 存储账号适配器
+func NewStorageAccountAdapter(client *azurecommon.Client, logger *elog.Component) *StorageAccountAdapter {
+	return &StorageAccountAdapter{
+		client: client,
+		logger: logger,
+	}
+}
+
+// storageAccountProperties 存储账号属性
+type storageAccountProperties struct {
+	CreationTime          time.Time `json:"creationTime"`
+	AccessTier            string    `json:"accessTier"`
+	AllowBlobPublicAccess *bool     `json:"allowBlobPublicAccess"`
+	PrimaryEndpoints      struct {
+		Blob string `json:"blob"`
+	} `json:"primaryEndpoints"`
+	SecondaryLocation string `json:"secondaryLocation"`
+	Encryption        *struct {
+		KeySource          string `json:"keySource"`
+		KeyVaultProperties *struct {
+			KeyName string `json:"keyname"`
+		} `json:"keyvaultproperties"`
+	} `json:"encryption"`
+}
+
+// metricsResponse Azure Monitor 指标查询响应
+type metricsResponse struct {
+	Value []struct {
+		Timeseries []struct {
+			Data []struct {
+				TimeStamp string   `json:"timeStamp"`
+				Average   *float64 `json:"average"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"value"`
+}
+
+// latest 返回最近一个有值的数据点
+func (m metricsResponse) latest() int64 {
+	for _, v := range m.Value {
+		for _, ts := range v.Timeseries {
+			for i := len(ts.Data) - 1; i >= 0; i-- {
+				if ts.Data[i].Average != nil {
+					return int64(*ts.Data[i].Average)
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// listAccounts 获取订阅下所有存储账号
+func (a *StorageAccountAdapter) listAccounts(ctx context.Context) ([]azurecommon.Resource, []storageAccountProperties, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Storage/storageAccounts", storageAPIVersion, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取Azure存储账号列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[storageAccountProperties](items)
+	return resources, props, nil
+}
+
+// ListBuckets 获取存储账号列表
+func (a *StorageAccountAdapter) ListBuckets(ctx context.Context, region string) ([]types.OSSBucket, error) {
+	resources, props, err := a.listAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]types.OSSBucket, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		bucket := convertStorageAccount(r, props[i])
+
+		stats, err := a.accountStats(ctx, r.ID)
+		if err == nil {
+			bucket.ObjectCount = stats.ObjectCount
+			bucket.StorageSize = stats.StorageSize
+		} else {
+			a.logger.Warn("获取Azure存储账号统计信息失败",
+				elog.String("account", r.Name),
+				elog.FieldErr(err))
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	a.logger.Info("获取Azure存储账号列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(buckets)))
+
+	return buckets, nil
+}
+
+// GetBucket 获取单个存储账号详情
+func (a *StorageAccountAdapter) GetBucket(ctx context.Context, bucketName string) (*types.OSSBucket, error) {
+	resources, props, err := a.listAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range resources {
+		if strings.EqualFold(r.Name, bucketName) {
+			bucket := convertStorageAccount(r, props[i])
+			return &bucket, nil
+		}
+	}
+	return nil, fmt.Errorf("存储账号不存在: %s", bucketName)
+}
+
+// GetBucketStats 获取存储账号统计信息
+func (a *StorageAccountAdapter) GetBucketStats(ctx context.Context, bucketName string) (*types.OSSBucketStats, error) {
+	resources, _, err := a.listAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range resources {
+		if strings.EqualFold(r.Name, bucketName) {
+			return a.accountStats(ctx, r.ID)
+		}
+	}
+	return nil, fmt.Errorf("存储账号不存在: %s", bucketName)
+}
+
+// accountStats 查询存储账号的已用容量和 Blob 数量
+// 容量指标按小时上报，Blob 数量按天上报
+func (a *StorageAccountAdapter) accountStats(ctx context.Context, accountID string) (*types.OSSBucketStats, error) {
+	now := time.Now().UTC()
+	stats := &types.OSSBucketStats{BucketName: azurecommon.NameFromID(accountID)}
+
+	var capacity metricsResponse
+	query := url.Values{}
+	query.Set("metricnames", "UsedCapacity")
+	query.Set("aggregation", "Average")
+	query.Set("interval", "PT1H")
+	query.Set("timespan", now.Add(-24*time.Hour).Format(time.RFC3339)+"/"+now.Format(time.RFC3339))
+	if err := a.client.DoARM(ctx, http.MethodGet, accountID+"/providers/Microsoft.Insights/metrics", metricsAPIVersion, query, nil, &capacity); err != nil {
+		return nil, err
+	}
+	stats.StorageSize = capacity.latest()
+
+	var count metricsResponse
+	query.Set("metricnames", "BlobCount")
+	query.Set("interval", "P1D")
+	query.Set("timespan", now.Add(-72*time.Hour).Format(time.RFC3339)+"/"+now.Format(time.RFC3339))
+	if err := a.client.DoARM(ctx, http.MethodGet, accountID+"/blobServices/default/providers/Microsoft.Insights/metrics", metricsAPIVersion, query, nil, &count); err != nil {
+		return nil, err
+	}
+	stats.ObjectCount = count.latest()
+
+	return stats, nil
+}
+
+// ListBucketsWithFilter 带过滤条件获取存储账号列表
+func (a *StorageAccountAdapter) ListBucketsWithFilter(ctx context.Context, region string, filter *types.OSSBucketFilter) ([]types.OSSBucket, error) {
+	buckets, err := a.ListBuckets(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return buckets, nil
+	}
+
+	result := make([]types.OSSBucket, 0, len(buckets))
+	for _, b := range buckets {
+		if len(filter.BucketNames) > 0 && !containsFold(filter.BucketNames, b.BucketName) {
+			continue
+		}
+		if filter.Prefix != "" && !strings.HasPrefix(b.BucketName, filter.Prefix) {
+			continue
+		}
+		if filter.StorageClass != "" && !strings.EqualFold(b.StorageClass, filter.StorageClass) {
+			continue
+		}
+		if !matchTags(b.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, b)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertStorageAccount 转换 Azure 存储账号为通用格式
+// SKU 名称中的冗余类型 (如 Standard_GRS/Standard_RAGZRS) 决定是否跨区域复制
+func convertStorageAccount(r azurecommon.Resource, p storageAccountProperties) types.OSSBucket {
+	storageClass := p.AccessTier
+	if storageClass == "" {
+		storageClass = "Hot"
+	}
+
+	acl := "private"
+	blockPublic := true
+	if p.AllowBlobPublicAccess != nil && *p.AllowBlobPublicAccess {
+		acl = "public-read"
+		blockPublic = false
+	}
+
+	crossRegion := p.SecondaryLocation != ""
+	if r.SKU != nil && strings.Contains(strings.ToUpper(r.SKU.Name), "GRS") {
+		crossRegion = true
+	}
+
+	encryption := "AES256"
+	kmsKeyID := ""
+	if p.Encryption != nil && strings.EqualFold(p.Encryption.KeySource, "Microsoft.Keyvault") {
+		encryption = "KMS"
+		if p.Encryption.KeyVaultProperties != nil {
+			kmsKeyID = p.Encryption.KeyVaultProperties.KeyName
+		}
+	}
+
+	return types.OSSBucket{
+		BucketName:             r.Name,
+		Region:                 azurecommon.NormalizeLocation(r.Location),
+		Location:               azurecommon.NormalizeLocation(r.Location),
+		CreationTime:           p.CreationTime,
+		StorageClass:           storageClass,
+		ACL:                    acl,
+		CrossRegionReplication: crossRegion,
+		ExtranetEndpoint:       p.PrimaryEndpoints.Blob,
+		ServerSideEncryption:   encryption,
+		KMSKeyID:               kmsKeyID,
+		BlockPublicAccess:      blockPublic,
+		Tags:                   copyTags(r.Tags),
+		Provider:               string(types.ProviderAzure),
+	}
+}
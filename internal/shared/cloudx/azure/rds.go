@@ -0,0 +1,210 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const sqlAPIVersion = "2021-11-01"
+
+// RDSAdapter Azure SQL Database 适配器
+// 以数据库为粒度同步，逻辑服务器提供连接地址和版本信息
+type RDSAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewRDSAdapter 创建 Azure SQL Database 适配器
+func NewRDSAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *RDSAdapter {
+	return &RDSAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// sqlServerProperties 逻辑服务器属性
+type sqlServerProperties struct {
+	FullyQualifiedDomainName string `json:"fullyQualifiedDomainName"`
+	Version                  string `json:"version"`
+	PublicNetworkAccess      string `json:"publicNetworkAccess"`
+	MinimalTLSVersion        string `json:"minimalTlsVersion"`
+}
+
+// sqlDatabaseProperties 数据库属性
+type sqlDatabaseProperties struct {
+	Status                       string `json:"status"`
+	MaxSizeBytes                 int64  `json:"maxSizeBytes"`
+	CurrentServiceObjectiveName  string `json:"currentServiceObjectiveName"`
+	ZoneRedundant                bool   `json:"zoneRedundant"`
+	CreationDate                 string `json:"creationDate"`
+	ReadScale                    string `json:"readScale"`
+	HighAvailabilityReplicaCount int    `json:"highAvailabilityReplicaCount"`
+	CurrentSku                   *struct {
+		Name     string `json:"name"`
+		Tier     string `json:"tier"`
+		Family   string `json:"family"`
+		Capacity int    `json:"capacity"`
+	} `json:"currentSku"`
+}
+
+// ListInstances 获取 SQL 数据库列表 (不含 master 系统库)
+func (a *RDSAdapter) ListInstances(ctx context.Context, region string) ([]types.RDSInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	serverItems, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Sql/servers", sqlAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure SQL服务器列表失败: %w", err)
+	}
+	servers, serverProps := azurecommon.DecodeResources[sqlServerProperties](serverItems)
+
+	var instances []types.RDSInstance
+	for i, server := range servers {
+		if !azurecommon.MatchLocation(server.Location, region) {
+			continue
+		}
+
+		dbItems, err := a.client.ListARM(ctx, server.ID+"/databases", sqlAPIVersion, nil)
+		if err != nil {
+			a.logger.Warn("获取Azure SQL数据库列表失败",
+				elog.String("server", server.Name),
+				elog.FieldErr(err))
+			continue
+		}
+		dbs, dbProps := azurecommon.DecodeResources[sqlDatabaseProperties](dbItems)
+		for j, db := range dbs {
+			if strings.EqualFold(db.Name, "master") {
+				continue
+			}
+			instances = append(instances, convertSQLDatabase(server, serverProps[i], db, dbProps[j]))
+		}
+	}
+
+	a.logger.Info("获取Azure SQL数据库列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(instances)))
+
+	return instances, nil
+}
+
+// GetInstance 获取单个 SQL 数据库详情
+func (a *RDSAdapter) GetInstance(ctx context.Context, region, instanceID string) (*types.RDSInstance, error) {
+	instances, err := a.ListInstancesByIDs(ctx, region, []string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("SQL数据库不存在: %s", instanceID)
+	}
+	return &instances[0], nil
+}
+
+// ListInstancesByIDs 批量获取 SQL 数据库
+func (a *RDSAdapter) ListInstancesByIDs(ctx context.Context, region string, instanceIDs []string) ([]types.RDSInstance, error) {
+	if len(instanceIDs) == 0 {
+		return []types.RDSInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.RDSInstanceFilter{InstanceIDs: instanceIDs})
+}
+
+// GetInstanceStatus 获取 SQL 数据库状态
+func (a *RDSAdapter) GetInstanceStatus(ctx context.Context, region, instanceID string) (string, error) {
+	instance, err := a.GetInstance(ctx, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取 SQL 数据库列表
+func (a *RDSAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.RDSInstanceFilter) ([]types.RDSInstance, error) {
+	instances, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return instances, nil
+	}
+
+	result := make([]types.RDSInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(filter.InstanceIDs) > 0 && !containsID(filter.InstanceIDs, inst.InstanceID, inst.InstanceName) {
+			continue
+		}
+		if !matchName(inst.InstanceName, filter.InstanceName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, inst.Status) {
+			continue
+		}
+		if filter.Engine != "" && !strings.EqualFold(inst.Engine, filter.Engine) {
+			continue
+		}
+		if !matchTags(inst.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, inst)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertSQLDatabase 转换 Azure SQL 数据库为通用格式
+func convertSQLDatabase(server azurecommon.Resource, sp sqlServerProperties, db azurecommon.Resource, p sqlDatabaseProperties) types.RDSInstance {
+	class := p.CurrentServiceObjectiveName
+	cpu := 0
+	storageType := ""
+	if p.CurrentSku != nil {
+		if class == "" {
+			class = p.CurrentSku.Name
+		}
+		// vCore 模型的 capacity 为 vCPU 数，DTU 模型为 DTU 数
+		if p.CurrentSku.Family != "" {
+			cpu = p.CurrentSku.Capacity
+		}
+		storageType = p.CurrentSku.Tier
+	}
+
+	category := "Basic"
+	if p.ZoneRedundant || p.HighAvailabilityReplicaCount > 0 {
+		category = "HighAvailability"
+	}
+
+	readReplicas := 0
+	if strings.EqualFold(p.ReadScale, "Enabled") {
+		readReplicas = 1
+	}
+
+	return types.RDSInstance{
+		InstanceID:       db.ID,
+		InstanceName:     server.Name + "/" + db.Name,
+		Status:           types.NormalizeRDSStatus(p.Status),
+		Region:           azurecommon.NormalizeLocation(db.Location),
+		Zone:             firstZone(db.Location, db.Zones),
+		Engine:           "SQLServer",
+		EngineVersion:    sp.Version,
+		DBInstanceClass:  class,
+		CPU:              cpu,
+		Storage:          int(p.MaxSizeBytes / (1024 * 1024 * 1024)),
+		StorageType:      storageType,
+		ConnectionString: sp.FullyQualifiedDomainName,
+		Port:             1433,
+		Category:         category,
+		ReadReplicaCount: readReplicas,
+		ChargeType:       "PostPaid",
+		CreationTime:     p.CreationDate,
+		SSLEnabled:       sp.MinimalTLSVersion != "" && sp.MinimalTLSVersion != "None",
+		ProjectID:        azurecommon.ResourceGroupFromID(db.ID),
+		ProjectName:      azurecommon.ResourceGroupFromID(db.ID),
+		Tags:             copyTags(db.Tags),
+		Provider:         string(types.ProviderAzure),
+	}
+}
@@ -0,0 +1,210 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const redisAPIVersion = "2023-08-01"
+
+// redisCapacityMB Azure Cache for Redis 各规格容量 (MB)
+// C 系列 (Basic/Standard) 与 P 系列 (Premium) 的 capacity 取值含义不同
+var redisCapacityMB = map[string][]int{
+	"C": {250, 1024, 2560, 6144, 13312, 26624, 53248},
+	"P": {0, 6144, 13312, 26624, 53248, 122880},
+}
+
+// RedisAdapter Azure Cache for Redis 适配器
+type RedisAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewRedisAdapter 创建 Azure Cache for Redis 适配器
+func NewRedisAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *RedisAdapter {
+	return &RedisAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// redisProperties Redis 缓存属性
+type redisProperties struct {
+	ProvisioningState string `json:"provisioningState"`
+	RedisVersion      string `json:"redisVersion"`
+	HostName          string `json:"hostName"`
+	Port              int    `json:"port"`
+	SSLPort           int    `json:"sslPort"`
+	EnableNonSSLPort  bool   `json:"enableNonSslPort"`
+	ShardCount        int    `json:"shardCount"`
+	ReplicasPerMaster int    `json:"replicasPerMaster"`
+	SubnetID          string `json:"subnetId"`
+	StaticIP          string `json:"staticIP"`
+	SKU               struct {
+		Name     string `json:"name"`
+		Family   string `json:"family"`
+		Capacity int    `json:"capacity"`
+	} `json:"sku"`
+}
+
+// ListInstances 获取 Redis 缓存列表
+func (a *RedisAdapter) ListInstances(ctx context.Context, region string) ([]types.RedisInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Cache/redis", redisAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure Redis缓存列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[redisProperties](items)
+
+	instances := make([]types.RedisInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		instances = append(instances, convertRedis(r, props[i]))
+	}
+
+	a.logger.Info("获取Azure Redis缓存列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(instances)))
+
+	return instances, nil
+}
+
+// GetInstance 获取单个 Redis 缓存详情
+func (a *RedisAdapter) GetInstance(ctx context.Context, region, instanceID string) (*types.RedisInstance, error) {
+	instances, err := a.ListInstancesByIDs(ctx, region, []string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("Redis缓存不存在: %s", instanceID)
+	}
+	return &instances[0], nil
+}
+
+// ListInstancesByIDs 批量获取 Redis 缓存
+func (a *RedisAdapter) ListInstancesByIDs(ctx context.Context, region string, instanceIDs []string) ([]types.RedisInstance, error) {
+	if len(instanceIDs) == 0 {
+		return []types.RedisInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.RedisInstanceFilter{InstanceIDs: instanceIDs})
+}
+
+// GetInstanceStatus 获取 Redis 缓存状态
+func (a *RedisAdapter) GetInstanceStatus(ctx context.Context, region, instanceID string) (string, error) {
+	instance, err := a.GetInstance(ctx, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取 Redis 缓存列表
+func (a *RedisAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.RedisInstanceFilter) ([]types.RedisInstance, error) {
+	instances, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return instances, nil
+	}
+
+	result := make([]types.RedisInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(filter.InstanceIDs) > 0 && !containsID(filter.InstanceIDs, inst.InstanceID, inst.InstanceName) {
+			continue
+		}
+		if !matchName(inst.InstanceName, filter.InstanceName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, inst.Status) {
+			continue
+		}
+		if filter.Architecture != "" && inst.Architecture != filter.Architecture {
+			continue
+		}
+		if filter.VPCID != "" && !azurecommon.SameID(inst.VPCID, filter.VPCID) {
+			continue
+		}
+		if !matchTags(inst.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, inst)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertRedis 转换 Azure Redis 缓存为通用格式
+func convertRedis(r azurecommon.Resource, p redisProperties) types.RedisInstance {
+	architecture := "standard"
+	if p.ShardCount > 0 {
+		architecture = "cluster"
+	}
+
+	nodeType := "double"
+	replicas := 1
+	if strings.EqualFold(p.SKU.Name, "Basic") {
+		nodeType = "single"
+		replicas = 0
+	} else if p.ReplicasPerMaster > 0 {
+		replicas = p.ReplicasPerMaster
+	}
+
+	capacity := 0
+	if sizes, ok := redisCapacityMB[p.SKU.Family]; ok && p.SKU.Capacity >= 0 && p.SKU.Capacity < len(sizes) {
+		capacity = sizes[p.SKU.Capacity]
+	}
+	if p.ShardCount > 0 {
+		capacity *= p.ShardCount
+	}
+
+	// 未开启非 SSL 端口时只能通过 SSL 端口连接
+	port := p.SSLPort
+	if p.EnableNonSSLPort {
+		port = p.Port
+	}
+
+	vpcID := ""
+	if p.SubnetID != "" {
+		vpcID = azurecommon.ParentID(p.SubnetID)
+	}
+
+	return types.RedisInstance{
+		InstanceID:       r.ID,
+		InstanceName:     r.Name,
+		Status:           types.NormalizeRedisStatus(p.ProvisioningState),
+		Region:           azurecommon.NormalizeLocation(r.Location),
+		Zone:             firstZone(r.Location, r.Zones),
+		EngineVersion:    p.RedisVersion,
+		InstanceClass:    fmt.Sprintf("%s_%s%d", p.SKU.Name, p.SKU.Family, p.SKU.Capacity),
+		Architecture:     architecture,
+		Capacity:         capacity,
+		ShardCount:       p.ShardCount,
+		ConnectionDomain: p.HostName,
+		Port:             port,
+		VPCID:            vpcID,
+		VSwitchID:        p.SubnetID,
+		PrivateIP:        p.StaticIP,
+		NodeType:         nodeType,
+		ReplicaCount:     replicas,
+		ChargeType:       "PostPaid",
+		SSLEnabled:       !p.EnableNonSSLPort,
+		Password:         true,
+		ProjectID:        azurecommon.ResourceGroupFromID(r.ID),
+		ProjectName:      azurecommon.ResourceGroupFromID(r.ID),
+		Tags:             copyTags(r.Tags),
+		Provider:         string(types.ProviderAzure),
+	}
+}
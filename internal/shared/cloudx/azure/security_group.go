@@ -0,0 +1,269 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// SecurityGroupAdapter Azure 网络安全组(NSG)适配器
+type SecurityGroupAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewSecurityGroupAdapter 创建 Azure 网络安全组适配器
+func NewSecurityGroupAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *SecurityGroupAdapter {
+	return &SecurityGroupAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// nsgProperties 网络安全组属性
+type nsgProperties struct {
+	SecurityRules        []nsgRule                 `json:"securityRules"`
+	NetworkInterfaces    []azurecommon.SubResource `json:"networkInterfaces"`
+	Subnets              []azurecommon.SubResource `json:"subnets"`
+	DefaultSecurityRules []nsgRule                 `json:"defaultSecurityRules"`
+}
+
+// nsgRule 网络安全组规则
+type nsgRule struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		Description                string   `json:"description"`
+		Protocol                   string   `json:"protocol"`
+		SourcePortRange            string   `json:"sourcePortRange"`
+		DestinationPortRange       string   `json:"destinationPortRange"`
+		DestinationPortRanges      []string `json:"destinationPortRanges"`
+		SourceAddressPrefix        string   `json:"sourceAddressPrefix"`
+		SourceAddressPrefixes      []string `json:"sourceAddressPrefixes"`
+		DestinationAddressPrefix   string   `json:"destinationAddressPrefix"`
+		DestinationAddressPrefixes []string `json:"destinationAddressPrefixes"`
+		Access                     string   `json:"access"`
+		Priority                   int      `json:"priority"`
+		Direction                  string   `json:"direction"`
+	} `json:"properties"`
+}
+
+// ListInstances 获取网络安全组列表
+func (a *SecurityGroupAdapter) ListInstances(ctx context.Context, region string) ([]types.SecurityGroupInstance, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := a.client.ListARM(ctx, subPath+"/providers/Microsoft.Network/networkSecurityGroups", networkAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取Azure网络安全组列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[nsgProperties](items)
+
+	groups := make([]types.SecurityGroupInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		groups = append(groups, convertNSG(r, props[i]))
+	}
+
+	a.logger.Info("获取Azure网络安全组列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(groups)))
+
+	return groups, nil
+}
+
+// GetInstance 获取单个网络安全组详情 (包含规则)
+func (a *SecurityGroupAdapter) GetInstance(ctx context.Context, region, securityGroupID string) (*types.SecurityGroupInstance, error) {
+	groups, err := a.ListInstancesByIDs(ctx, region, []string{securityGroupID})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("网络安全组不存在: %s", securityGroupID)
+	}
+	return &groups[0], nil
+}
+
+// ListInstancesByIDs 批量获取网络安全组
+func (a *SecurityGroupAdapter) ListInstancesByIDs(ctx context.Context, region string, securityGroupIDs []string) ([]types.SecurityGroupInstance, error) {
+	if len(securityGroupIDs) == 0 {
+		return []types.SecurityGroupInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.SecurityGroupFilter{SecurityGroupIDs: securityGroupIDs})
+}
+
+// ListInstancesWithFilter 带过滤条件获取网络安全组列表
+// Azure NSG 不隶属于虚拟网络，VPCID 过滤条件不生效
+func (a *SecurityGroupAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.SecurityGroupFilter) ([]types.SecurityGroupInstance, error) {
+	groups, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return groups, nil
+	}
+
+	result := make([]types.SecurityGroupInstance, 0, len(groups))
+	for _, g := range groups {
+		if len(filter.SecurityGroupIDs) > 0 && !containsID(filter.SecurityGroupIDs, g.SecurityGroupID, g.SecurityGroupName) {
+			continue
+		}
+		if !matchName(g.SecurityGroupName, filter.SecurityGroupName) {
+			continue
+		}
+		if filter.ResourceGroupID != "" && !strings.EqualFold(g.ResourceGroupID, filter.ResourceGroupID) {
+			continue
+		}
+		if !matchTags(g.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, g)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// GetSecurityGroupRules 获取网络安全组规则
+func (a *SecurityGroupAdapter) GetSecurityGroupRules(ctx context.Context, region, securityGroupID string) ([]types.SecurityGroupRule, error) {
+	group, err := a.GetInstance(ctx, region, securityGroupID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]types.SecurityGroupRule, 0, len(group.IngressRules)+len(group.EgressRules))
+	rules = append(rules, group.IngressRules...)
+	rules = append(rules, group.EgressRules...)
+	return rules, nil
+}
+
+// ListByInstanceID 获取虚拟机关联的网络安全组
+// 通过虚拟机网卡关联的 NSG 查找
+func (a *SecurityGroupAdapter) ListByInstanceID(ctx context.Context, region, instanceID string) ([]types.SecurityGroupInstance, error) {
+	var vm struct {
+		Properties vmProperties `json:"properties"`
+	}
+	if err := a.client.DoARM(ctx, "GET", instanceID, computeAPIVersion, nil, nil, &vm); err != nil {
+		return nil, fmt.Errorf("获取Azure虚拟机详情失败: %w", err)
+	}
+
+	groups, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.SecurityGroupInstance
+	for _, g := range groups {
+		for _, nic := range vm.Properties.NetworkProfile.NetworkInterfaces {
+			if containsID(g.InstanceIDs, nic.ID, "") {
+				result = append(result, g)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// convertNSG 转换 Azure 网络安全组为通用格式
+// InstanceIDs 记录关联的网卡ID
+func convertNSG(r azurecommon.Resource, p nsgProperties) types.SecurityGroupInstance {
+	var ingress, egress []types.SecurityGroupRule
+	for _, rule := range p.SecurityRules {
+		converted := convertNSGRule(rule)
+		if converted.Direction == "ingress" {
+			ingress = append(ingress, converted)
+		} else {
+			egress = append(egress, converted)
+		}
+	}
+
+	nicIDs := make([]string, 0, len(p.NetworkInterfaces))
+	for _, nic := range p.NetworkInterfaces {
+		nicIDs = append(nicIDs, nic.ID)
+	}
+
+	return types.SecurityGroupInstance{
+		SecurityGroupID:   r.ID,
+		SecurityGroupName: r.Name,
+		SecurityGroupType: "nsg",
+		IngressRuleCount:  len(ingress),
+		EgressRuleCount:   len(egress),
+		InstanceCount:     len(nicIDs),
+		InstanceIDs:       nicIDs,
+		IngressRules:      ingress,
+		EgressRules:       egress,
+		Region:            azurecommon.NormalizeLocation(r.Location),
+		ResourceGroupID:   azurecommon.ResourceGroupFromID(r.ID),
+		Tags:              copyTags(r.Tags),
+		Provider:          string(types.ProviderAzure),
+	}
+}
+
+// convertNSGRule 转换 NSG 规则
+func convertNSGRule(rule nsgRule) types.SecurityGroupRule {
+	p := rule.Properties
+
+	direction := "egress"
+	if strings.EqualFold(p.Direction, "Inbound") {
+		direction = "ingress"
+	}
+
+	ports := p.DestinationPortRanges
+	if p.DestinationPortRange != "" {
+		ports = []string{p.DestinationPortRange}
+	}
+	portRanges := make([]string, 0, len(ports))
+	for _, port := range ports {
+		portRanges = append(portRanges, convertPortRange(port))
+	}
+	portRange := strings.Join(portRanges, ",")
+
+	source := p.SourceAddressPrefix
+	if source == "" && len(p.SourceAddressPrefixes) > 0 {
+		source = strings.Join(p.SourceAddressPrefixes, ",")
+	}
+	dest := p.DestinationAddressPrefix
+	if dest == "" && len(p.DestinationAddressPrefixes) > 0 {
+		dest = strings.Join(p.DestinationAddressPrefixes, ",")
+	}
+
+	policy := "drop"
+	if strings.EqualFold(p.Access, "Allow") {
+		policy = "accept"
+	}
+
+	protocol := strings.ToLower(p.Protocol)
+	if protocol == "*" {
+		protocol = "all"
+	}
+
+	return types.SecurityGroupRule{
+		RuleID:      rule.ID,
+		Direction:   direction,
+		Protocol:    protocol,
+		PortRange:   portRange,
+		SourceCIDR:  source,
+		DestCIDR:    dest,
+		Priority:    p.Priority,
+		Policy:      policy,
+		Description: p.Description,
+	}
+}
+
+// convertPortRange 转换端口范围格式: "*" -> "-1/-1", "22" -> "22/22", "80-90" -> "80/90"
+func convertPortRange(port string) string {
+	if port == "*" || port == "" {
+		return "-1/-1"
+	}
+	if from, to, ok := strings.Cut(port, "-"); ok {
+		return from + "/" + to
+	}
+	return port + "/" + port
+}
@@ -0,0 +1,193 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const resourcesAPIVersion = "2021-04-01"
+
+// TagAdapterImpl Azure 标签适配器
+// Azure 标签为订阅级别，region 参数仅用于过滤 ListResourcesByTag 的结果
+type TagAdapterImpl struct {
+	client *azurecommon.Client
+	logger *elog.Component
+}
+
+// NewTagAdapter 创建 Azure 标签适配器
+func NewTagAdapter(client *azurecommon.Client, logger *elog.Component) *TagAdapterImpl {
+	return &TagAdapterImpl{
+		client: client,
+		logger: logger,
+	}
+}
+
+// tagName 订阅下的标签名及其取值
+type tagName struct {
+	TagName string `json:"tagName"`
+	Values  []struct {
+		TagValue string `json:"tagValue"`
+	} `json:"values"`
+}
+
+// tagsResource 资源标签
+type tagsResource struct {
+	Properties struct {
+		Tags map[string]string `json:"tags"`
+	} `json:"properties"`
+}
+
+// listTagNames 获取订阅下所有标签名
+func (a *TagAdapterImpl) listTagNames(ctx context.Context) ([]tagName, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := a.client.ListARM(ctx, subPath+"/tagNames", resourcesAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("azure: list tag names failed: %w", err)
+	}
+	names := make([]tagName, 0, len(items))
+	for _, item := range items {
+		var n tagName
+		if err := json.Unmarshal(item, &n); err != nil {
+			continue
+		}
+		names = append(names, n)
+	}
+	return names, nil
+}
+
+// ListTagKeys 查询标签键列表
+func (a *TagAdapterImpl) ListTagKeys(ctx context.Context, region string) ([]string, error) {
+	names, err := a.listTagNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(names))
+	for _, n := range names {
+		keys = append(keys, n.TagName)
+	}
+	return keys, nil
+}
+
+// ListTagValues 查询指定标签键的值列表
+func (a *TagAdapterImpl) ListTagValues(ctx context.Context, region, key string) ([]string, error) {
+	names, err := a.listTagNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, n := range names {
+		if !strings.EqualFold(n.TagName, key) {
+			continue
+		}
+		for _, v := range n.Values {
+			values = append(values, v.TagValue)
+		}
+	}
+	return values, nil
+}
+
+// GetResourceTags 查询资源绑定的标签，resourceID 为 ARM 资源ID
+func (a *TagAdapterImpl) GetResourceTags(ctx context.Context, region, resourceType, resourceID string) (map[string]string, error) {
+	var resp tagsResource
+	if err := a.client.DoARM(ctx, http.MethodGet, tagsPath(resourceID), resourcesAPIVersion, nil, nil, &resp); err != nil {
+		return nil, fmt.Errorf("azure: get tags of %s failed: %w", resourceID, err)
+	}
+	tags := resp.Properties.Tags
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	return tags, nil
+}
+
+// TagResource 为资源绑定标签 (合并到已有标签)
+func (a *TagAdapterImpl) TagResource(ctx context.Context, region, resourceType, resourceID string, tags map[string]string) error {
+	body := map[string]any{
+		"operation":  "Merge",
+		"properties": map[string]any{"tags": tags},
+	}
+	if err := a.client.DoARM(ctx, http.MethodPatch, tagsPath(resourceID), resourcesAPIVersion, nil, body, nil); err != nil {
+		return fmt.Errorf("azure: tag resource %s failed: %w", resourceID, err)
+	}
+	return nil
+}
+
+// UntagResource 解绑资源标签
+// Delete 操作按键值对匹配删除，需先读取当前标签值
+func (a *TagAdapterImpl) UntagResource(ctx context.Context, region, resourceType, resourceID string, tagKeys []string) error {
+	current, err := a.GetResourceTags(ctx, region, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+
+	toDelete := make(map[string]string, len(tagKeys))
+	for _, k := range tagKeys {
+		if v, ok := current[k]; ok {
+			toDelete[k] = v
+		}
+	}
+	if len(toDelete) == 0 {
+		return nil
+	}
+
+	body := map[string]any{
+		"operation":  "Delete",
+		"properties": map[string]any{"tags": toDelete},
+	}
+	if err := a.client.DoARM(ctx, http.MethodPatch, tagsPath(resourceID), resourcesAPIVersion, nil, body, nil); err != nil {
+		return fmt.Errorf("azure: untag resource %s failed: %w", resourceID, err)
+	}
+	return nil
+}
+
+// ListResourcesByTag 按标签查询资源列表
+func (a *TagAdapterImpl) ListResourcesByTag(ctx context.Context, region, key, value string) ([]cloudx.TaggedResource, error) {
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("$filter", fmt.Sprintf("tagName eq '%s' and tagValue eq '%s'", escapeOData(key), escapeOData(value)))
+	items, err := a.client.ListARM(ctx, subPath+"/resources", resourcesAPIVersion, query)
+	if err != nil {
+		return nil, fmt.Errorf("azure: list resources by tag failed: %w", err)
+	}
+
+	resources, _ := azurecommon.DecodeResources[struct{}](items)
+	result := make([]cloudx.TaggedResource, 0, len(resources))
+	for _, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		result = append(result, cloudx.TaggedResource{
+			ResourceType: r.Type,
+			ResourceID:   r.ID,
+			Region:       azurecommon.NormalizeLocation(r.Location),
+		})
+	}
+	return result, nil
+}
+
+// tagsPath 资源标签子资源路径
+func tagsPath(resourceID string) string {
+	return strings.TrimRight(resourceID, "/") + "/providers/Microsoft.Resources/tags/default"
+}
+
+// escapeOData 转义 OData 字符串字面量中的单引号
+func escapeOData(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// Ensure compile-time interface compliance
+var _ cloudx.TagAdapter = (*TagAdapterImpl)(nil)
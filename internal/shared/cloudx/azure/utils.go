@@ -0,0 +1,91 @@
+package azure
+
+import (
+	"strings"
+	"time"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+)
+
+// containsID 判断资源ID列表中是否包含指定ID (大小写不敏感，同时支持只传资源名称)
+func containsID(ids []string, id, name string) bool {
+	for _, v := range ids {
+		if azurecommon.SameID(v, id) || strings.EqualFold(v, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold 大小写不敏感的字符串包含判断
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTags 判断资源标签是否包含全部过滤标签
+func matchTags(resourceTags, filterTags map[string]string) bool {
+	for k, v := range filterTags {
+		if resourceTags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// matchName 名称模糊匹配，filter 为空表示不过滤
+func matchName(name, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+// paginate 按页码截取结果，pageSize 为 0 表示不分页
+func paginate[T any](items []T, pageNumber, pageSize int) []T {
+	if pageSize <= 0 {
+		return items
+	}
+	if pageNumber <= 0 {
+		pageNumber = 1
+	}
+	start := (pageNumber - 1) * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// copyTags 复制标签，避免 nil map
+func copyTags(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = v
+	}
+	return result
+}
+
+// formatTime 统一时间格式为 RFC3339
+func formatTime(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// firstZone 返回资源的第一个可用区
+func firstZone(location string, zones []string) string {
+	if len(zones) == 0 {
+		return ""
+	}
+	return azurecommon.NormalizeLocation(location) + "-" + zones[0]
+}
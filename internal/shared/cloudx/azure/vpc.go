@@ -0,0 +1,212 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// VPCAdapter Azure 虚拟网络适配器
+type VPCAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewVPCAdapter 创建 Azure 虚拟网络适配器
+func NewVPCAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *VPCAdapter {
+	return &VPCAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// vnetProperties 虚拟网络属性
+type vnetProperties struct {
+	ProvisioningState string `json:"provisioningState"`
+	AddressSpace      struct {
+		AddressPrefixes []string `json:"addressPrefixes"`
+	} `json:"addressSpace"`
+	DhcpOptions struct {
+		DNSServers []string `json:"dnsServers"`
+	} `json:"dhcpOptions"`
+	Subnets []struct {
+		ID         string           `json:"id"`
+		Name       string           `json:"name"`
+		Properties subnetProperties `json:"properties"`
+	} `json:"subnets"`
+}
+
+// subnetProperties 子网属性
+type subnetProperties struct {
+	ProvisioningState    string                    `json:"provisioningState"`
+	AddressPrefix        string                    `json:"addressPrefix"`
+	AddressPrefixes      []string                  `json:"addressPrefixes"`
+	NetworkSecurityGroup *azurecommon.SubResource  `json:"networkSecurityGroup"`
+	RouteTable           *azurecommon.SubResource  `json:"routeTable"`
+	NatGateway           *azurecommon.SubResource  `json:"natGateway"`
+	IPConfigurations     []azurecommon.SubResource `json:"ipConfigurations"`
+}
+
+// listVNets 获取订阅下所有虚拟网络
+func listVNets(ctx context.Context, client *azurecommon.Client) ([]azurecommon.Resource, []vnetProperties, error) {
+	subPath, err := client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := client.ListARM(ctx, subPath+"/providers/Microsoft.Network/virtualNetworks", networkAPIVersion, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("获取Azure虚拟网络列表失败: %w", err)
+	}
+	resources, props := azurecommon.DecodeResources[vnetProperties](items)
+	return resources, props, nil
+}
+
+// ListInstances 获取虚拟网络列表
+func (a *VPCAdapter) ListInstances(ctx context.Context, region string) ([]types.VPCInstance, error) {
+	resources, props, err := listVNets(ctx, a.client)
+	if err != nil {
+		return nil, err
+	}
+
+	vpcs := make([]types.VPCInstance, 0, len(resources))
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		vpcs = append(vpcs, convertVNet(r, props[i]))
+	}
+
+	a.logger.Info("获取Azure虚拟网络列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(vpcs)))
+
+	return vpcs, nil
+}
+
+// GetInstance 获取单个虚拟网络详情
+func (a *VPCAdapter) GetInstance(ctx context.Context, region, vpcID string) (*types.VPCInstance, error) {
+	vpcs, err := a.ListInstancesByIDs(ctx, region, []string{vpcID})
+	if err != nil {
+		return nil, err
+	}
+	if len(vpcs) == 0 {
+		return nil, fmt.Errorf("虚拟网络不存在: %s", vpcID)
+	}
+	return &vpcs[0], nil
+}
+
+// ListInstancesByIDs 批量获取虚拟网络
+func (a *VPCAdapter) ListInstancesByIDs(ctx context.Context, region string, vpcIDs []string) ([]types.VPCInstance, error) {
+	if len(vpcIDs) == 0 {
+		return []types.VPCInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.VPCInstanceFilter{VPCIDs: vpcIDs})
+}
+
+// GetInstanceStatus 获取虚拟网络状态
+func (a *VPCAdapter) GetInstanceStatus(ctx context.Context, region, vpcID string) (string, error) {
+	vpc, err := a.GetInstance(ctx, region, vpcID)
+	if err != nil {
+		return "", err
+	}
+	return vpc.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取虚拟网络列表
+func (a *VPCAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.VPCInstanceFilter) ([]types.VPCInstance, error) {
+	vpcs, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return vpcs, nil
+	}
+
+	result := make([]types.VPCInstance, 0, len(vpcs))
+	for _, v := range vpcs {
+		if len(filter.VPCIDs) > 0 && !containsID(filter.VPCIDs, v.VPCID, v.VPCName) {
+			continue
+		}
+		if !matchName(v.VPCName, filter.VPCName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, v.Status) {
+			continue
+		}
+		if filter.CidrBlock != "" && v.CidrBlock != filter.CidrBlock && !containsFold(v.SecondaryCidrs, filter.CidrBlock) {
+			continue
+		}
+		if filter.IsDefault != nil && v.IsDefault != *filter.IsDefault {
+			continue
+		}
+		if !matchTags(v.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertVNet 转换 Azure 虚拟网络为通用格式
+func convertVNet(r azurecommon.Resource, p vnetProperties) types.VPCInstance {
+	cidr := ""
+	var secondary []string
+	if len(p.AddressSpace.AddressPrefixes) > 0 {
+		cidr = p.AddressSpace.AddressPrefixes[0]
+		secondary = p.AddressSpace.AddressPrefixes[1:]
+	}
+
+	ipv6 := ""
+	for _, prefix := range p.AddressSpace.AddressPrefixes {
+		if strings.Contains(prefix, ":") {
+			ipv6 = prefix
+			break
+		}
+	}
+
+	natCount := 0
+	for _, s := range p.Subnets {
+		if s.Properties.NatGateway != nil {
+			natCount++
+		}
+	}
+
+	return types.VPCInstance{
+		VPCID:           r.ID,
+		VPCName:         r.Name,
+		Status:          provisioningStatus(p.ProvisioningState),
+		Region:          azurecommon.NormalizeLocation(r.Location),
+		CidrBlock:       cidr,
+		SecondaryCidrs:  secondary,
+		IPv6CidrBlock:   ipv6,
+		EnableIPv6:      ipv6 != "",
+		VSwitchCount:    len(p.Subnets),
+		NatGatewayCount: natCount,
+		ProjectID:       azurecommon.ResourceGroupFromID(r.ID),
+		ProjectName:     azurecommon.ResourceGroupFromID(r.ID),
+		Tags:            copyTags(r.Tags),
+		Provider:        string(types.ProviderAzure),
+	}
+}
+
+// provisioningStatus 将 ARM provisioningState 映射为通用状态
+func provisioningStatus(state string) string {
+	switch state {
+	case "Succeeded":
+		return "available"
+	case "Updating", "Creating":
+		return "pending"
+	case "Deleting":
+		return "deleting"
+	case "Failed":
+		return types.StatusError
+	default:
+		return strings.ToLower(state)
+	}
+}
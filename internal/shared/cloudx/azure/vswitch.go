@@ -0,0 +1,168 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// azureReservedIPCount Azure 每个子网保留 5 个IP地址
+const azureReservedIPCount = 5
+
+// VSwitchAdapter Azure 子网适配器
+type VSwitchAdapter struct {
+	client        *azurecommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewVSwitchAdapter 创建 Azure 子网适配器
+func NewVSwitchAdapter(client *azurecommon.Client, defaultRegion string, logger *elog.Component) *VSwitchAdapter {
+	return &VSwitchAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// ListInstances 获取子网列表 (子网内嵌在虚拟网络响应中)
+func (a *VSwitchAdapter) ListInstances(ctx context.Context, region string) ([]types.VSwitchInstance, error) {
+	resources, props, err := listVNets(ctx, a.client)
+	if err != nil {
+		return nil, err
+	}
+
+	var subnets []types.VSwitchInstance
+	for i, r := range resources {
+		if !azurecommon.MatchLocation(r.Location, region) {
+			continue
+		}
+		for _, s := range props[i].Subnets {
+			subnets = append(subnets, convertSubnet(r, s.ID, s.Name, s.Properties))
+		}
+	}
+
+	a.logger.Info("获取Azure子网列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(subnets)))
+
+	return subnets, nil
+}
+
+// GetInstance 获取单个子网详情
+func (a *VSwitchAdapter) GetInstance(ctx context.Context, region, vswitchID string) (*types.VSwitchInstance, error) {
+	subnets, err := a.ListInstancesByIDs(ctx, region, []string{vswitchID})
+	if err != nil {
+		return nil, err
+	}
+	if len(subnets) == 0 {
+		return nil, fmt.Errorf("子网不存在: %s", vswitchID)
+	}
+	return &subnets[0], nil
+}
+
+// ListInstancesByIDs 批量获取子网
+func (a *VSwitchAdapter) ListInstancesByIDs(ctx context.Context, region string, vswitchIDs []string) ([]types.VSwitchInstance, error) {
+	if len(vswitchIDs) == 0 {
+		return []types.VSwitchInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.VSwitchInstanceFilter{VSwitchIDs: vswitchIDs})
+}
+
+// GetInstanceStatus 获取子网状态
+func (a *VSwitchAdapter) GetInstanceStatus(ctx context.Context, region, vswitchID string) (string, error) {
+	subnet, err := a.GetInstance(ctx, region, vswitchID)
+	if err != nil {
+		return "", err
+	}
+	return subnet.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取子网列表
+func (a *VSwitchAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.VSwitchInstanceFilter) ([]types.VSwitchInstance, error) {
+	subnets, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return subnets, nil
+	}
+
+	result := make([]types.VSwitchInstance, 0, len(subnets))
+	for _, s := range subnets {
+		if len(filter.VSwitchIDs) > 0 && !containsID(filter.VSwitchIDs, s.VSwitchID, s.VSwitchName) {
+			continue
+		}
+		if !matchName(s.VSwitchName, filter.VSwitchName) {
+			continue
+		}
+		if filter.VPCID != "" && !azurecommon.SameID(s.VPCID, filter.VPCID) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, s.Status) {
+			continue
+		}
+		if filter.IsDefault != nil && s.IsDefault != *filter.IsDefault {
+			continue
+		}
+		if !matchTags(s.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, s)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertSubnet 转换 Azure 子网为通用格式
+func convertSubnet(vnet azurecommon.Resource, id, name string, p subnetProperties) types.VSwitchInstance {
+	cidr := p.AddressPrefix
+	if cidr == "" && len(p.AddressPrefixes) > 0 {
+		cidr = p.AddressPrefixes[0]
+	}
+
+	total := subnetIPCount(cidr)
+	available := total - azureReservedIPCount - int64(len(p.IPConfigurations))
+	if available < 0 {
+		available = 0
+	}
+
+	routeTableID := ""
+	if p.RouteTable != nil {
+		routeTableID = p.RouteTable.ID
+	}
+
+	return types.VSwitchInstance{
+		VSwitchID:        id,
+		VSwitchName:      name,
+		Status:           provisioningStatus(p.ProvisioningState),
+		Region:           azurecommon.NormalizeLocation(vnet.Location),
+		CidrBlock:        cidr,
+		VPCID:            vnet.ID,
+		VPCName:          vnet.Name,
+		AvailableIPCount: available,
+		TotalIPCount:     total,
+		RouteTableID:     routeTableID,
+		ProjectID:        azurecommon.ResourceGroupFromID(id),
+		ProjectName:      azurecommon.ResourceGroupFromID(id),
+		ResourceGroupID:  azurecommon.ResourceGroupFromID(id),
+		Tags:             copyTags(vnet.Tags),
+		Provider:         string(types.ProviderAzure),
+	}
+}
+
+// subnetIPCount 计算 IPv4 CIDR 的地址总数
+func subnetIPCount(cidr string) int64 {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits != 32 {
+		return 0
+	}
+	return int64(1) << uint(bits-ones)
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
 	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
 )
 
@@ -52,7 +53,7 @@ func (v *AzureValidator) ValidateCredentials(ctx context.Context, account *domai
 		Message:      "Azure 凭证验证成功功",
 		Regions:      regions,
 		Permissions:  []string{"Microsoft.Compute/virtualMachines/read", "Microsoft.Sql/servers/read", "Microsoft.Storage/storageAccounts/read"},
-		AccountInfo:  fmt.Sprintf("ClientId: %s", maskAccessKey(account.AccessKeyID)),
+		AccountInfo:  fmt.Sprintf("ClientId: %s", maskAccessKey(azureClientID(account.AccessKeyID))),
 		ValidatedAt:  time.Now(),
 		ResponseTime: time.Since(startTime).Milliseconds(),
 	}, nil
@@ -60,7 +61,17 @@ func (v *AzureValidator) ValidateCredentials(ctx context.Context, account *domai
 
 // GetSupportedRegions 获取 Azure 支持的地域
 func (v *AzureValidator) GetSupportedRegions(ctx context.Context, account *domain.CloudAccount) ([]string, error) {
-	// TODO: 调用 Azure API 获取真实地域列表
+	if client, err := azurecommon.NewClientFromAccount(account); err == nil {
+		if locations, err := client.ListLocations(ctx); err == nil && len(locations) > 0 {
+			regions := make([]string, 0, len(locations))
+			for _, loc := range locations {
+				regions = append(regions, loc.Name)
+			}
+			return regions, nil
+		}
+	}
+
+	// 降级为常用地域列表
 	return []string{
 		"eastus",             // 美国东部
 		"eastus2",            // 美国东部 2
@@ -103,40 +114,38 @@ func (v *AzureValidator) TestConnection(ctx context.Context, account *domain.Clo
 }
 
 // validateCredentialFormat 验证 Azure 凭证格式
+// AccessKeyID 格式为 tenantID/clientID[/subscriptionID]，各段均为 GUID
 func (v *AzureValidator) validateCredentialFormat(account *domain.CloudAccount) error {
-	// Azure Client ID 是 GUID 格式，长度为 36 位（包含连字符）
-	if len(account.AccessKeyID) != 36 {
-		return fmt.Errorf("Azure Client ID 长度应为 36 位，当前为 %d 位", len(account.AccessKeyID))
-	}
-
-	// 检查 GUID 格式：xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
-	parts := strings.Split(account.AccessKeyID, "-")
-	if len(parts) != 5 {
-		return fmt.Errorf("Azure Client ID 格式不正确，应为 GUID 格式")
-	}
-
-	// Client Secret 长度通常在 32-44 位之间
-	if len(account.AccessKeySecret) < 32 || len(account.AccessKeySecret) > 44 {
-		return fmt.Errorf("Azure Client Secret 长度应在 32-44 位之间，当前为 %d 位", len(account.AccessKeySecret))
+	if _, err := azurecommon.ParseCredential(account.AccessKeyID, account.AccessKeySecret); err != nil {
+		return fmt.Errorf("Azure 凭证格式不正确: %w", err)
 	}
-
 	return nil
 }
 
 // callAzureAPI 调用 Azure API 进行验证
+// 获取服务主体令牌并解析订阅，可同时校验客户端密钥与订阅访问权限
 func (v *AzureValidator) callAzureAPI(ctx context.Context, account *domain.CloudAccount) error {
-	// TODO: 实际集成功 Azure SDK
-	// 可以调用 Azure Resource Manager API 来验证凭证
-
-	// 模拟 API 调用
-	select {
-	case <-ctx.Done():
-		return ErrConnectionTimeout
-	case <-time.After(200 * time.Millisecond):
-		// 模拟验证逻辑
-		if strings.Contains(account.AccessKeyID, "invalid") {
-			return ErrInvalidCredentials
+	client, err := azurecommon.NewClientFromAccount(account)
+	if err != nil {
+		return err
+	}
+	if _, err := client.SubscriptionID(ctx); err != nil {
+		if azurecommon.IsPermissionError(err) {
+			return fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		if ctx.Err() != nil {
+			return ErrConnectionTimeout
 		}
-		return nil
+		return err
+	}
+	return nil
+}
+
+// azureClientID 从 tenantID/clientID[/subscriptionID] 中提取 clientID
+func azureClientID(accessKeyID string) string {
+	parts := strings.Split(accessKeyID, "/")
+	if len(parts) >= 2 {
+		return parts[1]
 	}
+	return accessKeyID
 }
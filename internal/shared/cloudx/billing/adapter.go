@@ -16,6 +16,10 @@ type BillingAdapter interface {
 	// startTime/endTime 为计费周期的起止时间（UTC）
 	// 返回原始账单数据列表，每条记录对应一笔费用明细
 	FetchBillDetails(ctx context.Context, params FetchBillParams) ([]RawBillItem, error)
+
+	// FetchBillTotal 拉取指定账期的账单总览应付总额，用于与采集明细对账
+	// 金额口径与 FetchBillDetails 返回的 RawBillItem.Amount 一致（优惠后、抵扣前）
+	FetchBillTotal(ctx context.Context, params FetchBillTotalParams) (*BillTotal, error)
 }
 
 // FetchBillParams 账单拉取参数
@@ -28,6 +32,20 @@ type FetchBillParams struct {
 	Granularity string    // 粒度: "daily" | "monthly"
 }
 
+// FetchBillTotalParams 账单总览拉取参数
+type FetchBillTotalParams struct {
+	AccountID    string // 云账号 ID
+	BillingCycle string // 账期，格式 YYYY-MM
+}
+
+// BillTotal 账单总览（厂商侧账期汇总金额）
+type BillTotal struct {
+	Provider     domain.CloudProvider // 云厂商
+	BillingCycle string               // 账期
+	Amount       float64              // 应付总额
+	Currency     string               // 币种
+}
+
 // RawBillItem 原始账单条目（云厂商原始格式）
 type RawBillItem struct {
 	Provider     domain.CloudProvider   // 云厂商
@@ -33,6 +33,7 @@ type AliyunBillingAdapter struct {
 
 func init() {
 	billing.RegisterBillingAdapter(domain.CloudProviderAliyun, newAliyunBillingAdapter)
+	billing.RegisterCommitmentProvider(domain.CloudProviderAliyun)
 }
 
 // newAliyunBillingAdapter 创建阿里云计费适配器
@@ -207,6 +208,12 @@ func (a *AliyunBillingAdapter) fetchBillPaginated(ctx context.Context, billingCy
 					"CostUnit":              item.CostUnit,
 					"PipCode":               item.PipCode,
 					"ServicePeriod":         item.ServicePeriod,
+					"ServicePeriodUnit":     item.ServicePeriodUnit,
+					"UsageStartTime":        item.UsageStartTime,
+					"UsageEndTime":          item.UsageEndTime,
+					"Item":                  item.Item,
+					"AdjustAmount":          item.AdjustAmount,
+					"OutstandingAmount":     item.OutstandingAmount,
 					"BillingType":           item.BillingType,
 					"Tag":                   item.Tag,
 				}
@@ -254,6 +261,45 @@ func (a *AliyunBillingAdapter) fetchBillPaginated(ctx context.Context, billingCy
 	return items, nil
 }
 
+// FetchBillTotal 拉取账期的账单总览，按产品汇总的应付金额 (PretaxAmount) 求和
+func (a *AliyunBillingAdapter) FetchBillTotal(ctx context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	var total *billing.BillTotal
+	err := retry.WithBackoff(ctx, maxRetries, func() error {
+		request := bssopenapi.CreateQueryBillOverviewRequest()
+		request.BillingCycle = params.BillingCycle
+
+		response, err := a.client.QueryBillOverview(request)
+		if err != nil {
+			if authErr := asAuthError(err); authErr != nil {
+				return authErr
+			}
+			return err
+		}
+		total = sumBillOverview(response.Data.Items.Item, params.BillingCycle)
+		return nil
+	}, isRetryable)
+	if err != nil {
+		return nil, fmt.Errorf("query bill overview for %s: %w", params.BillingCycle, err)
+	}
+	return total, nil
+}
+
+// sumBillOverview 汇总账单总览条目，币种缺省为 CNY
+func sumBillOverview(items []bssopenapi.Item, billingCycle string) *billing.BillTotal {
+	total := &billing.BillTotal{
+		Provider:     domain.CloudProviderAliyun,
+		BillingCycle: billingCycle,
+		Currency:     "CNY",
+	}
+	for _, item := range items {
+		total.Amount += item.PretaxAmount
+		if item.Currency != "" {
+			total.Currency = item.Currency
+		}
+	}
+	return total
+}
+
 // mapGranularity 将通用粒度映射为阿里云 API 粒度参数
 func mapGranularity(granularity string) string {
 	switch strings.ToLower(granularity) {
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	sdkerrors "github.com/aliyun/alibaba-cloud-sdk-go/sdk/errors"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/bssopenapi"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,6 +31,22 @@ func TestMapGranularity(t *testing.T) {
 	}
 }
 
+func TestSumBillOverview(t *testing.T) {
+	total := sumBillOverview([]bssopenapi.Item{
+		{ProductCode: "ecs", PretaxAmount: 120.5, Currency: "CNY"},
+		{ProductCode: "oss", PretaxAmount: 30.25, Currency: "CNY"},
+		{ProductCode: "cdn", PretaxAmount: -0.75},
+	}, "2024-03")
+
+	assert.Equal(t, "2024-03", total.BillingCycle)
+	assert.InDelta(t, 150.0, total.Amount, 1e-9)
+	assert.Equal(t, "CNY", total.Currency)
+
+	empty := sumBillOverview(nil, "2024-04")
+	assert.Zero(t, empty.Amount)
+	assert.Equal(t, "CNY", empty.Currency)
+}
+
 func TestAsAuthError(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -0,0 +1,193 @@
+package aliyun
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/bssopenapi"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// commitmentPageSize 承诺消费查询分页大小
+const commitmentPageSize = 100
+
+// 确保阿里云计费适配器实现承诺消费接口
+var _ billing.CommitmentAdapter = (*AliyunBillingAdapter)(nil)
+
+// FetchCommitments 拉取节省计划与预付费资源包
+// 阿里云预留实例券按实例维度抵扣，BSS 未提供账号级利用率接口，暂不纳入
+func (a *AliyunBillingAdapter) FetchCommitments(ctx context.Context, params billing.FetchCommitmentParams) ([]billing.RawCommitment, error) {
+	hours := params.EndTime.Sub(params.StartTime).Hours()
+
+	var commitments []billing.RawCommitment
+	for pageNum := 1; ; pageNum++ {
+		var response *bssopenapi.QuerySavingsPlansInstanceResponse
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			request := bssopenapi.CreateQuerySavingsPlansInstanceRequest()
+			request.PageNum = requests.NewInteger(pageNum)
+			request.PageSize = requests.NewInteger(commitmentPageSize)
+
+			resp, err := a.client.QuerySavingsPlansInstance(request)
+			if err != nil {
+				if authErr := asAuthError(err); authErr != nil {
+					return authErr
+				}
+				return err
+			}
+			response = resp
+			return nil
+		}, isRetryable)
+		if err != nil {
+			return nil, err
+		}
+
+		commitments = append(commitments, parseSavingsPlans(response.Data.Items, hours)...)
+		if len(response.Data.Items) == 0 || pageNum*commitmentPageSize >= response.Data.TotalCount {
+			break
+		}
+	}
+
+	for pageNum := 1; ; pageNum++ {
+		var response *bssopenapi.QueryResourcePackageInstancesResponse
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			request := bssopenapi.CreateQueryResourcePackageInstancesRequest()
+			request.PageNum = requests.NewInteger(pageNum)
+			request.PageSize = requests.NewInteger(commitmentPageSize)
+
+			resp, err := a.client.QueryResourcePackageInstances(request)
+			if err != nil {
+				if authErr := asAuthError(err); authErr != nil {
+					return authErr
+				}
+				return err
+			}
+			response = resp
+			return nil
+		}, isRetryable)
+		if err != nil {
+			return nil, err
+		}
+
+		instances := response.Data.Instances.Instance
+		commitments = append(commitments, parseResourcePackages(instances)...)
+		if len(instances) == 0 || pageNum*commitmentPageSize >= response.Total {
+			break
+		}
+	}
+
+	a.logger.Info("[aliyun] fetched commitments",
+		elog.String("account_id", params.AccountID),
+		elog.Int("count", len(commitments)),
+	)
+	return commitments, nil
+}
+
+// FetchCoverage 阿里云 BSS 未提供按地域 / 实例族的覆盖率数据，返回空列表
+func (a *AliyunBillingAdapter) FetchCoverage(_ context.Context, _ billing.FetchCommitmentParams) ([]billing.RawCoverage, error) {
+	return nil, nil
+}
+
+// parseSavingsPlans 解析节省计划实例
+// PoolValue 为每小时承诺金额，Utilization 为 0~1 的使用率；统计区间费用按承诺金额 × 小时数估算
+func parseSavingsPlans(items []bssopenapi.SavingsPlansDetailResponse, hours float64) []billing.RawCommitment {
+	commitments := make([]billing.RawCommitment, 0, len(items))
+	for _, item := range items {
+		if item.InstanceId == "" {
+			continue
+		}
+		hourly := parseFloat(item.PoolValue)
+		currency := item.Currency
+		if currency == "" {
+			currency = "CNY"
+		}
+		commitments = append(commitments, billing.RawCommitment{
+			Provider:         domain.CloudProviderAliyun,
+			CommitmentID:     item.InstanceId,
+			Type:             billing.CommitmentTypeSavingsPlan,
+			Region:           item.Region,
+			InstanceFamily:   item.InstanceFamily,
+			ServiceType:      "compute",
+			HourlyCommitment: hourly,
+			UtilizationPct:   parseFloat(item.Utilization) * 100,
+			PeriodCost:       hourly * hours,
+			Currency:         currency,
+			StartTime:        msToTime(item.StartTimestamp),
+			EndTime:          msToTime(item.EndTimestamp),
+			Status:           item.Status,
+			RawData: map[string]interface{}{
+				"SavingsType":     item.SavingsType,
+				"PayMode":         item.PayMode,
+				"Cycle":           item.Cycle,
+				"DeductCycleType": item.DeductCycleType,
+				"PrepayFee":       item.PrepayFee,
+				"TotalSave":       item.TotalSave,
+				"Utilization":     item.Utilization,
+			},
+		})
+	}
+	return commitments
+}
+
+// parseResourcePackages 解析预付费资源包，利用率按已抵扣量 / 总量计算
+func parseResourcePackages(instances []bssopenapi.InstanceInQueryResourcePackageInstances) []billing.RawCommitment {
+	commitments := make([]billing.RawCommitment, 0, len(instances))
+	for _, inst := range instances {
+		if inst.InstanceId == "" {
+			continue
+		}
+		total := parseFloat(inst.TotalAmount)
+		remaining := parseFloat(inst.RemainingAmount)
+		var utilization float64
+		if total > 0 {
+			utilization = (total - remaining) / total * 100
+		}
+		commitments = append(commitments, billing.RawCommitment{
+			Provider:       domain.CloudProviderAliyun,
+			CommitmentID:   inst.InstanceId,
+			Type:           billing.CommitmentTypeResourcePackage,
+			Region:         inst.Region,
+			Quantity:       total,
+			Unit:           inst.TotalAmountUnit,
+			UtilizationPct: utilization,
+			Currency:       "CNY",
+			StartTime:      parseTime(inst.EffectiveTime),
+			EndTime:        parseTime(inst.ExpiryTime),
+			Status:         inst.Status,
+			RawData: map[string]interface{}{
+				"PackageType":         inst.PackageType,
+				"CommodityCode":       inst.CommodityCode,
+				"DeductType":          inst.DeductType,
+				"RemainingAmount":     inst.RemainingAmount,
+				"RemainingAmountUnit": inst.RemainingAmountUnit,
+				"Remark":              inst.Remark,
+			},
+		})
+	}
+	return commitments
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func msToTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
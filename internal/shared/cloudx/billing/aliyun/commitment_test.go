@@ -0,0 +1,63 @@
+package aliyun
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/bssopenapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadFixture 读取 testdata 下录制的 BSS API 响应
+func loadFixture(t *testing.T, name string, out interface{}) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, out))
+}
+
+func TestParseSavingsPlans(t *testing.T) {
+	var response bssopenapi.QuerySavingsPlansInstanceResponse
+	loadFixture(t, "query_savings_plans_instance.json", &response)
+
+	commitments := parseSavingsPlans(response.Data.Items, 720)
+	require.Len(t, commitments, 2)
+
+	ecs := commitments[0]
+	assert.Equal(t, "spn-bp1a2b3c4d5e6f", ecs.CommitmentID)
+	assert.Equal(t, billing.CommitmentTypeSavingsPlan, ecs.Type)
+	assert.Equal(t, "cn-hangzhou", ecs.Region)
+	assert.Equal(t, "ecs.g6", ecs.InstanceFamily)
+	assert.Equal(t, "compute", ecs.ServiceType)
+	assert.Equal(t, 2.5, ecs.HourlyCommitment)
+	assert.InDelta(t, 60.0, ecs.UtilizationPct, 0.001)
+	assert.Equal(t, 1800.0, ecs.PeriodCost)
+	assert.Equal(t, "CNY", ecs.Currency)
+	assert.Equal(t, time.UnixMilli(1798732800000).UTC(), ecs.EndTime)
+
+	universal := commitments[1]
+	assert.Empty(t, universal.Region, "通用型节省计划不限地域")
+	assert.Equal(t, "CNY", universal.Currency, "缺少币种时默认人民币")
+	assert.InDelta(t, 100.0, universal.UtilizationPct, 0.001)
+}
+
+func TestParseResourcePackages(t *testing.T) {
+	var response bssopenapi.QueryResourcePackageInstancesResponse
+	loadFixture(t, "query_resource_package_instances.json", &response)
+
+	commitments := parseResourcePackages(response.Data.Instances.Instance)
+	require.Len(t, commitments, 1)
+
+	pkg := commitments[0]
+	assert.Equal(t, "OSSBAG-cn-0pp1abcd0001", pkg.CommitmentID)
+	assert.Equal(t, billing.CommitmentTypeResourcePackage, pkg.Type)
+	assert.Equal(t, 500.0, pkg.Quantity)
+	assert.Equal(t, "GB", pkg.Unit)
+	assert.InDelta(t, 75.0, pkg.UtilizationPct, 0.001)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), pkg.EndTime)
+	assert.Equal(t, "ossbag", pkg.RawData["CommodityCode"])
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
@@ -35,6 +36,7 @@ type AWSBillingAdapter struct {
 
 func init() {
 	billing.RegisterBillingAdapter(domain.CloudProviderAWS, newAWSBillingAdapter)
+	billing.RegisterCommitmentProvider(domain.CloudProviderAWS)
 }
 
 // newAWSBillingAdapter 创建 AWS 计费适配器
@@ -85,7 +87,7 @@ func (a *AWSBillingAdapter) FetchBillDetails(ctx context.Context, params billing
 					End:   &endDate,
 				},
 				Granularity: mapGranularity(params.Granularity),
-				Metrics:     []string{"UnblendedCost", "UsageQuantity"},
+				Metrics:     []string{"UnblendedCost", "AmortizedCost", "NetUnblendedCost", "NetAmortizedCost", "UsageQuantity"},
 				GroupBy: []cetypes.GroupDefinition{
 					{
 						Type: cetypes.GroupDefinitionTypeDimension,
@@ -180,6 +182,13 @@ func parseResultsByTime(results []cetypes.ResultByTime, billingCycle string) []b
 				"UsageQuantity": usageQty,
 				"Currency":      currency,
 			}
+			// 摊销 / 净额口径，仅在 Cost Explorer 返回时记录
+			for _, metric := range []string{"AmortizedCost", "NetUnblendedCost", "NetAmortizedCost"} {
+				if cost, ok := group.Metrics[metric]; ok && cost.Amount != nil {
+					value, _ := strconv.ParseFloat(*cost.Amount, 64)
+					rawData[metric] = value
+				}
+			}
 
 			items = append(items, billing.RawBillItem{
 				Provider:     domain.CloudProviderAWS,
@@ -199,6 +208,63 @@ func parseResultsByTime(results []cetypes.ResultByTime, billingCycle string) []b
 	return items
 }
 
+// FetchBillTotal 拉取账期的未分组月度 UnblendedCost 合计
+func (a *AWSBillingAdapter) FetchBillTotal(ctx context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	cycle, err := time.Parse("2006-01", params.BillingCycle)
+	if err != nil {
+		return nil, fmt.Errorf("parse billing cycle %s: %w", params.BillingCycle, err)
+	}
+	startDate := cycle.Format("2006-01-02")
+	endDate := cycle.AddDate(0, 1, 0).Format("2006-01-02")
+
+	var total *billing.BillTotal
+	err = retry.WithBackoff(ctx, maxRetries, func() error {
+		response, err := a.client.GetCostAndUsage(ctx, &costexplorer.GetCostAndUsageInput{
+			TimePeriod: &cetypes.DateInterval{
+				Start: &startDate,
+				End:   &endDate,
+			},
+			Granularity: cetypes.GranularityMonthly,
+			Metrics:     []string{"UnblendedCost"},
+		})
+		if err != nil {
+			if authErr := asAuthError(err); authErr != nil {
+				return authErr
+			}
+			return err
+		}
+		total = parseTotalResults(response.ResultsByTime, params.BillingCycle)
+		return nil
+	}, isRetryable)
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// parseTotalResults 汇总未分组查询结果中的 UnblendedCost
+func parseTotalResults(results []cetypes.ResultByTime, billingCycle string) *billing.BillTotal {
+	total := &billing.BillTotal{
+		Provider:     domain.CloudProviderAWS,
+		BillingCycle: billingCycle,
+		Currency:     "USD",
+	}
+	for _, result := range results {
+		cost, ok := result.Total["UnblendedCost"]
+		if !ok {
+			continue
+		}
+		if cost.Amount != nil {
+			amount, _ := strconv.ParseFloat(*cost.Amount, 64)
+			total.Amount += amount
+		}
+		if cost.Unit != nil {
+			total.Currency = *cost.Unit
+		}
+	}
+	return total
+}
+
 // mapGranularity 将通用粒度映射为 AWS Cost Explorer 粒度参数
 func mapGranularity(granularity string) cetypes.Granularity {
 	switch strings.ToLower(granularity) {
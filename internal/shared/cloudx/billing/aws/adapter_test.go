@@ -38,6 +38,19 @@ func TestMapGranularity(t *testing.T) {
 	}
 }
 
+func TestParseTotalResults(t *testing.T) {
+	total := parseTotalResults([]cetypes.ResultByTime{
+		{Total: map[string]cetypes.MetricValue{
+			"UnblendedCost": {Amount: strPtr("1234.5678"), Unit: strPtr("USD")},
+		}},
+		{Total: map[string]cetypes.MetricValue{}},
+	}, "2024-03")
+
+	assert.Equal(t, "2024-03", total.BillingCycle)
+	assert.InDelta(t, 1234.5678, total.Amount, 1e-9)
+	assert.Equal(t, "USD", total.Currency)
+}
+
 func TestAsAuthError(t *testing.T) {
 	tests := []struct {
 		name       string
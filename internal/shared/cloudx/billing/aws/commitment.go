@@ -0,0 +1,333 @@
+package aws
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	cetypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// 确保 AWS 计费适配器实现承诺消费接口
+var _ billing.CommitmentAdapter = (*AWSBillingAdapter)(nil)
+
+// FetchCommitments 拉取预留实例与节省计划
+// 预留实例来自按订阅分组的 RI 利用率报表，节省计划来自 SP 利用率明细；两者都只返回统计区间内有效的承诺
+func (a *AWSBillingAdapter) FetchCommitments(ctx context.Context, params billing.FetchCommitmentParams) ([]billing.RawCommitment, error) {
+	period := dateInterval(params.StartTime, params.EndTime)
+
+	var commitments []billing.RawCommitment
+	var nextPageToken *string
+	for {
+		var output *costexplorer.GetReservationUtilizationOutput
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			resp, err := a.client.GetReservationUtilization(ctx, &costexplorer.GetReservationUtilizationInput{
+				TimePeriod: period,
+				GroupBy: []cetypes.GroupDefinition{
+					{Type: cetypes.GroupDefinitionTypeDimension, Key: strPtr("SUBSCRIPTION_ID")},
+				},
+				NextPageToken: nextPageToken,
+			})
+			if err != nil {
+				if authErr := asAuthError(err); authErr != nil {
+					return authErr
+				}
+				return err
+			}
+			output = resp
+			return nil
+		}, isRetryable)
+		if err != nil {
+			return nil, err
+		}
+
+		commitments = append(commitments, parseReservationUtilization(output.UtilizationsByTime)...)
+		if output.NextPageToken == nil {
+			break
+		}
+		nextPageToken = output.NextPageToken
+	}
+
+	var nextToken *string
+	for {
+		var output *costexplorer.GetSavingsPlansUtilizationDetailsOutput
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			resp, err := a.client.GetSavingsPlansUtilizationDetails(ctx, &costexplorer.GetSavingsPlansUtilizationDetailsInput{
+				TimePeriod: period,
+				NextToken:  nextToken,
+			})
+			if err != nil {
+				if authErr := asAuthError(err); authErr != nil {
+					return authErr
+				}
+				return err
+			}
+			output = resp
+			return nil
+		}, isRetryable)
+		if err != nil {
+			return nil, err
+		}
+
+		commitments = append(commitments, parseSavingsPlansUtilization(output.SavingsPlansUtilizationDetails)...)
+		if output.NextToken == nil {
+			break
+		}
+		nextToken = output.NextToken
+	}
+
+	return commitments, nil
+}
+
+// FetchCoverage 拉取按地域与实例族分组的预留实例覆盖小时数
+func (a *AWSBillingAdapter) FetchCoverage(ctx context.Context, params billing.FetchCommitmentParams) ([]billing.RawCoverage, error) {
+	period := dateInterval(params.StartTime, params.EndTime)
+
+	var coverage []billing.RawCoverage
+	var nextPageToken *string
+	for {
+		var output *costexplorer.GetReservationCoverageOutput
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			resp, err := a.client.GetReservationCoverage(ctx, &costexplorer.GetReservationCoverageInput{
+				TimePeriod: period,
+				GroupBy: []cetypes.GroupDefinition{
+					{Type: cetypes.GroupDefinitionTypeDimension, Key: strPtr("REGION")},
+					{Type: cetypes.GroupDefinitionTypeDimension, Key: strPtr("INSTANCE_TYPE_FAMILY")},
+				},
+				NextPageToken: nextPageToken,
+			})
+			if err != nil {
+				if authErr := asAuthError(err); authErr != nil {
+					return authErr
+				}
+				return err
+			}
+			output = resp
+			return nil
+		}, isRetryable)
+		if err != nil {
+			return nil, err
+		}
+
+		coverage = append(coverage, parseReservationCoverage(output.CoveragesByTime)...)
+		if output.NextPageToken == nil {
+			break
+		}
+		nextPageToken = output.NextPageToken
+	}
+	return coverage, nil
+}
+
+// parseReservationUtilization 解析按订阅分组的 RI 利用率，每个订阅对应一个预留实例
+// 多个时间段的同一订阅合并为一条，利用率按购买小时加权
+func parseReservationUtilization(results []cetypes.UtilizationByTime) []billing.RawCommitment {
+	byID := make(map[string]*billing.RawCommitment)
+	purchased := make(map[string]float64)
+	used := make(map[string]float64)
+	var order []string
+
+	for _, result := range results {
+		for _, group := range result.Groups {
+			attrs := group.Attributes
+			id := attr(attrs, "subscriptionId", "leaseId")
+			if id == "" {
+				id = deref(group.Value)
+			}
+			if id == "" {
+				continue
+			}
+
+			c, ok := byID[id]
+			if !ok {
+				instanceType := attr(attrs, "instanceType")
+				c = &billing.RawCommitment{
+					Provider:       domain.CloudProviderAWS,
+					CommitmentID:   id,
+					Type:           billing.CommitmentTypeReservedInstance,
+					Region:         attr(attrs, "region"),
+					InstanceType:   instanceType,
+					InstanceFamily: instanceFamily(instanceType),
+					ServiceType:    reservationServiceType(instanceType),
+					Quantity:       parseFloat(attr(attrs, "numberOfInstances")),
+					Currency:       "USD",
+					StartTime:      parseTime(attr(attrs, "startDateTime")),
+					EndTime:        parseTime(attr(attrs, "endDateTime")),
+					Status:         attr(attrs, "subscriptionStatus"),
+					RawData:        attributesToRaw(attrs),
+				}
+				byID[id] = c
+				order = append(order, id)
+			}
+
+			if u := group.Utilization; u != nil {
+				purchased[id] += parseFloat(deref(u.PurchasedHours))
+				used[id] += parseFloat(deref(u.TotalActualHours))
+				c.PeriodCost += parseFloat(deref(u.TotalAmortizedFee))
+			}
+		}
+	}
+
+	commitments := make([]billing.RawCommitment, 0, len(order))
+	for _, id := range order {
+		c := byID[id]
+		if purchased[id] > 0 {
+			c.UtilizationPct = used[id] / purchased[id] * 100
+		}
+		commitments = append(commitments, *c)
+	}
+	return commitments
+}
+
+// parseSavingsPlansUtilization 解析节省计划利用率明细
+func parseSavingsPlansUtilization(details []cetypes.SavingsPlansUtilizationDetail) []billing.RawCommitment {
+	commitments := make([]billing.RawCommitment, 0, len(details))
+	for _, d := range details {
+		id := deref(d.SavingsPlanArn)
+		if id == "" {
+			continue
+		}
+		attrs := d.Attributes
+		c := billing.RawCommitment{
+			Provider:         domain.CloudProviderAWS,
+			CommitmentID:     id,
+			Type:             billing.CommitmentTypeSavingsPlan,
+			Region:           attr(attrs, "Region"),
+			InstanceFamily:   attr(attrs, "InstanceFamily"),
+			ServiceType:      savingsPlanServiceType(attr(attrs, "SavingsPlansType")),
+			HourlyCommitment: parseFloat(attr(attrs, "HourlyCommitment")),
+			Currency:         "USD",
+			StartTime:        parseTime(attr(attrs, "StartDateTime", "StartTime")),
+			EndTime:          parseTime(attr(attrs, "EndDateTime", "EndTime")),
+			Status:           attr(attrs, "Status"),
+			RawData:          attributesToRaw(attrs),
+		}
+		c.RawData["SavingsPlansType"] = attr(attrs, "SavingsPlansType")
+		if u := d.Utilization; u != nil {
+			c.UtilizationPct = parseFloat(deref(u.UtilizationPercentage))
+			c.PeriodCost = parseFloat(deref(u.TotalCommitment))
+		}
+		if ac := d.AmortizedCommitment; ac != nil && c.PeriodCost == 0 {
+			c.PeriodCost = parseFloat(deref(ac.TotalAmortizedCommitment))
+		}
+		commitments = append(commitments, c)
+	}
+	return commitments
+}
+
+// parseReservationCoverage 解析按地域与实例族分组的 RI 覆盖小时数，多个时间段累加
+func parseReservationCoverage(results []cetypes.CoverageByTime) []billing.RawCoverage {
+	type key struct{ region, family string }
+	byKey := make(map[key]*billing.RawCoverage)
+	var order []key
+
+	for _, result := range results {
+		for _, group := range result.Groups {
+			k := key{
+				region: attr(group.Attributes, "region"),
+				family: attr(group.Attributes, "instanceTypeFamily"),
+			}
+			c, ok := byKey[k]
+			if !ok {
+				c = &billing.RawCoverage{Region: k.region, InstanceFamily: k.family, Currency: "USD"}
+				byKey[k] = c
+				order = append(order, k)
+			}
+			if group.Coverage == nil {
+				continue
+			}
+			if h := group.Coverage.CoverageHours; h != nil {
+				c.CoveredHours += parseFloat(deref(h.ReservedHours))
+				c.OnDemandHours += parseFloat(deref(h.OnDemandHours))
+			}
+			if cost := group.Coverage.CoverageCost; cost != nil {
+				c.OnDemandCost += parseFloat(deref(cost.OnDemandCost))
+			}
+		}
+	}
+
+	coverage := make([]billing.RawCoverage, 0, len(order))
+	for _, k := range order {
+		coverage = append(coverage, *byKey[k])
+	}
+	return coverage
+}
+
+// dateInterval Cost Explorer 查询区间（End 为开区间）
+func dateInterval(start, end time.Time) *cetypes.DateInterval {
+	startDate := start.Format("2006-01-02")
+	endDate := end.Format("2006-01-02")
+	return &cetypes.DateInterval{Start: &startDate, End: &endDate}
+}
+
+// attr 按候选键读取属性，键名大小写不敏感（不同报表的属性命名风格不一致）
+func attr(attrs map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if v, ok := attrs[key]; ok {
+			return v
+		}
+		for k, v := range attrs {
+			if strings.EqualFold(k, key) {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+// instanceFamily 从实例规格解析实例族，去掉最后一段尺寸（m5.large → m5，db.r5.large → db.r5）
+func instanceFamily(instanceType string) string {
+	if i := strings.LastIndex(instanceType, "."); i > 0 {
+		return instanceType[:i]
+	}
+	return instanceType
+}
+
+// reservationServiceType 按预留实例规格前缀识别适用服务类别（RDS 为 db.*，ElastiCache 为 cache.*）
+func reservationServiceType(instanceType string) string {
+	if strings.HasPrefix(instanceType, "db.") || strings.HasPrefix(instanceType, "cache.") {
+		return "database"
+	}
+	return "compute"
+}
+
+// savingsPlanServiceType 节省计划适用服务类别：SageMaker 节省计划只抵扣机器学习用量（统一分类为 other）
+func savingsPlanServiceType(planType string) string {
+	if strings.HasPrefix(planType, "SageMaker") {
+		return "other"
+	}
+	return "compute"
+}
+
+func attributesToRaw(attrs map[string]string) map[string]interface{} {
+	raw := make(map[string]interface{}, len(attrs))
+	for k, v := range attrs {
+		raw[k] = v
+	}
+	return raw
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05.000Z", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,102 @@
+package aws
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadFixture 读取 testdata 下录制的 Cost Explorer 响应
+func loadFixture(t *testing.T, name string, out interface{}) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, out))
+}
+
+func TestParseReservationUtilization(t *testing.T) {
+	var output costexplorer.GetReservationUtilizationOutput
+	loadFixture(t, "reservation_utilization.json", &output)
+
+	commitments := parseReservationUtilization(output.UtilizationsByTime)
+	require.Len(t, commitments, 2)
+
+	m5 := commitments[0]
+	assert.Equal(t, "1234567890", m5.CommitmentID)
+	assert.Equal(t, billing.CommitmentTypeReservedInstance, m5.Type)
+	assert.Equal(t, "us-east-1", m5.Region)
+	assert.Equal(t, "m5.large", m5.InstanceType)
+	assert.Equal(t, "m5", m5.InstanceFamily)
+	assert.Equal(t, "compute", m5.ServiceType)
+	assert.Equal(t, 4.0, m5.Quantity)
+	assert.Equal(t, "Active", m5.Status)
+	assert.InDelta(t, 75.0, m5.UtilizationPct, 0.001, "两个时间段按购买小时加权：2160/2880")
+	assert.InDelta(t, 276.48, m5.PeriodCost, 0.001)
+	assert.Equal(t, time.Date(2027, 3, 1, 0, 0, 0, 0, time.UTC), m5.EndTime)
+
+	c5 := commitments[1]
+	assert.Equal(t, "9876543210", c5.CommitmentID)
+	assert.Equal(t, "c5", c5.InstanceFamily)
+	assert.InDelta(t, 50.0, c5.UtilizationPct, 0.001)
+}
+
+func TestParseSavingsPlansUtilization(t *testing.T) {
+	var output costexplorer.GetSavingsPlansUtilizationDetailsOutput
+	loadFixture(t, "savings_plans_utilization.json", &output)
+
+	commitments := parseSavingsPlansUtilization(output.SavingsPlansUtilizationDetails)
+	require.Len(t, commitments, 2)
+
+	ec2 := commitments[0]
+	assert.Equal(t, billing.CommitmentTypeSavingsPlan, ec2.Type)
+	assert.Equal(t, "m5", ec2.InstanceFamily)
+	assert.Equal(t, "compute", ec2.ServiceType)
+	assert.Equal(t, 0.5, ec2.HourlyCommitment)
+	assert.Equal(t, 90.0, ec2.UtilizationPct)
+	assert.Equal(t, 360.0, ec2.PeriodCost)
+	assert.Equal(t, "EC2InstanceSavingsPlans", ec2.RawData["SavingsPlansType"])
+
+	compute := commitments[1]
+	assert.Empty(t, compute.InstanceFamily, "计算节省计划不限实例族")
+	assert.Equal(t, 62.5, compute.UtilizationPct)
+	assert.Equal(t, 864.0, compute.PeriodCost, "缺少 TotalCommitment 时回退到摊销承诺")
+	assert.Equal(t, time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC), compute.EndTime)
+}
+
+func TestParseReservationCoverage(t *testing.T) {
+	var output costexplorer.GetReservationCoverageOutput
+	loadFixture(t, "reservation_coverage.json", &output)
+
+	coverage := parseReservationCoverage(output.CoveragesByTime)
+	require.Len(t, coverage, 2)
+
+	assert.Equal(t, "us-east-1", coverage[0].Region)
+	assert.Equal(t, "m5", coverage[0].InstanceFamily)
+	assert.Equal(t, 2160.0, coverage[0].CoveredHours)
+	assert.Equal(t, 360.0, coverage[0].OnDemandHours)
+	assert.InDelta(t, 34.56, coverage[0].OnDemandCost, 0.001)
+
+	assert.Equal(t, "c5", coverage[1].InstanceFamily)
+	assert.Equal(t, 720.0, coverage[1].OnDemandHours)
+}
+
+func TestInstanceFamily(t *testing.T) {
+	assert.Equal(t, "m5", instanceFamily("m5.large"))
+	assert.Equal(t, "db.r5", instanceFamily("db.r5.large"))
+	assert.Equal(t, "db", instanceFamily("db"))
+	assert.Empty(t, instanceFamily(""))
+}
+
+func TestCommitmentServiceType(t *testing.T) {
+	assert.Equal(t, "compute", reservationServiceType("m5.large"))
+	assert.Equal(t, "database", reservationServiceType("db.r5.large"))
+	assert.Equal(t, "database", reservationServiceType("cache.r6g.large"))
+	assert.Equal(t, "compute", savingsPlanServiceType("ComputeSavingsPlans"))
+	assert.Equal(t, "other", savingsPlanServiceType("SageMakerSavingsPlans"))
+}
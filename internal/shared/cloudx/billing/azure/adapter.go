@@ -0,0 +1,303 @@
+package azure
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// costManagementAPIVersion Cost Management Query API 版本
+	costManagementAPIVersion = "2023-03-01"
+	// defaultPageSize 单页最大行数
+	defaultPageSize = 5000
+)
+
+// AzureBillingAdapter Azure 计费适配器
+// 基于 Cost Management Query API 拉取订阅的实际成本 (ActualCost)
+type AzureBillingAdapter struct {
+	client  *azurecommon.Client
+	account *domain.CloudAccount
+	logger  *elog.Component
+}
+
+func init() {
+	billing.RegisterBillingAdapter(domain.CloudProviderAzure, newAzureBillingAdapter)
+}
+
+// newAzureBillingAdapter 创建 Azure 计费适配器
+func newAzureBillingAdapter(account *domain.CloudAccount) (billing.BillingAdapter, error) {
+	client, err := azurecommon.NewClientFromAccount(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create azure client: %w", err)
+	}
+
+	return &AzureBillingAdapter{
+		client:  client,
+		account: account,
+		logger:  elog.DefaultLogger,
+	}, nil
+}
+
+// GetProvider 获取云厂商标识
+func (a *AzureBillingAdapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderAzure
+}
+
+// queryResult Cost Management Query API 响应
+type queryResult struct {
+	Properties struct {
+		NextLink string `json:"nextLink"`
+		Columns  []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"columns"`
+		Rows [][]any `json:"rows"`
+	} `json:"properties"`
+}
+
+// FetchBillDetails 拉取指定时间范围的账单明细
+// 按 ResourceId 与 ServiceName 分组 (Query API 最多支持两个分组维度)，
+// 资源名称与资源组从资源ID中解析
+func (a *AzureBillingAdapter) FetchBillDetails(ctx context.Context, params billing.FetchBillParams) ([]billing.RawBillItem, error) {
+	billingCycle := params.StartTime.Format("2006-01")
+
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		if authErr := asAuthError(err); authErr != nil {
+			return nil, authErr
+		}
+		return nil, err
+	}
+
+	pageSize := params.PageSize
+	if pageSize <= 0 || pageSize > defaultPageSize {
+		pageSize = defaultPageSize
+	}
+
+	body := map[string]any{
+		"type":      "ActualCost",
+		"timeframe": "Custom",
+		"timePeriod": map[string]string{
+			"from": params.StartTime.UTC().Format("2006-01-02T15:04:05Z"),
+			// Query API 的 to 为闭区间，EndTime 为开区间，向前取一秒
+			"to": params.EndTime.UTC().Add(-time.Second).Format("2006-01-02T15:04:05Z"),
+		},
+		"dataset": map[string]any{
+			"granularity": mapGranularity(params.Granularity),
+			"aggregation": map[string]any{
+				"totalCost": map[string]string{"name": "Cost", "function": "Sum"},
+				"usage":     map[string]string{"name": "UsageQuantity", "function": "Sum"},
+			},
+			"grouping": []map[string]string{
+				{"type": "Dimension", "name": "ResourceId"},
+				{"type": "Dimension", "name": "ServiceName"},
+			},
+		},
+	}
+
+	query := url.Values{}
+	query.Set("$top", strconv.Itoa(pageSize))
+	path := subPath + "/providers/Microsoft.CostManagement/query"
+
+	var items []billing.RawBillItem
+	for path != "" {
+		var result queryResult
+		if err := a.client.DoARM(ctx, http.MethodPost, path, costManagementAPIVersion, query, body, &result); err != nil {
+			if authErr := asAuthError(err); authErr != nil {
+				return nil, authErr
+			}
+			return nil, fmt.Errorf("[azure] query cost management failed: %w", err)
+		}
+
+		items = append(items, parseQueryResult(&result, billingCycle)...)
+
+		// nextLink 为完整 URL，已包含 api-version 与 skiptoken
+		path = result.Properties.NextLink
+		query = nil
+	}
+
+	a.logger.Info("fetch azure bill details success",
+		elog.Int64("account_id", a.account.ID),
+		elog.String("billing_cycle", billingCycle),
+		elog.Int("count", len(items)))
+
+	return items, nil
+}
+
+// FetchBillTotal 拉取账期的订阅实际成本合计
+// 不分组、粒度为 None 时 Query API 仅返回一行汇总
+func (a *AzureBillingAdapter) FetchBillTotal(ctx context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	cycle, err := time.Parse("2006-01", params.BillingCycle)
+	if err != nil {
+		return nil, fmt.Errorf("parse billing cycle %s: %w", params.BillingCycle, err)
+	}
+
+	subPath, err := a.client.SubscriptionPath(ctx)
+	if err != nil {
+		if authErr := asAuthError(err); authErr != nil {
+			return nil, authErr
+		}
+		return nil, err
+	}
+
+	body := map[string]any{
+		"type":      "ActualCost",
+		"timeframe": "Custom",
+		"timePeriod": map[string]string{
+			"from": cycle.Format("2006-01-02T15:04:05Z"),
+			"to":   cycle.AddDate(0, 1, 0).Add(-time.Second).Format("2006-01-02T15:04:05Z"),
+		},
+		"dataset": map[string]any{
+			"granularity": "None",
+			"aggregation": map[string]any{
+				"totalCost": map[string]string{"name": "Cost", "function": "Sum"},
+			},
+		},
+	}
+
+	var result queryResult
+	path := subPath + "/providers/Microsoft.CostManagement/query"
+	if err := a.client.DoARM(ctx, http.MethodPost, path, costManagementAPIVersion, nil, body, &result); err != nil {
+		if authErr := asAuthError(err); authErr != nil {
+			return nil, authErr
+		}
+		return nil, fmt.Errorf("[azure] query cost management total failed: %w", err)
+	}
+
+	total := &billing.BillTotal{
+		Provider:     domain.CloudProviderAzure,
+		BillingCycle: params.BillingCycle,
+		Currency:     "USD",
+	}
+	for _, item := range parseQueryResult(&result, params.BillingCycle) {
+		total.Amount += item.Amount
+		total.Currency = item.Currency
+	}
+	return total, nil
+}
+
+// parseQueryResult 按列名解析 Query API 的行数据
+func parseQueryResult(result *queryResult, billingCycle string) []billing.RawBillItem {
+	index := make(map[string]int, len(result.Properties.Columns))
+	for i, col := range result.Properties.Columns {
+		index[strings.ToLower(col.Name)] = i
+	}
+
+	str := func(row []any, name string) string {
+		i, ok := index[strings.ToLower(name)]
+		if !ok || i >= len(row) || row[i] == nil {
+			return ""
+		}
+		switch v := row[i].(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	num := func(row []any, name string) float64 {
+		i, ok := index[strings.ToLower(name)]
+		if !ok || i >= len(row) {
+			return 0
+		}
+		switch v := row[i].(type) {
+		case float64:
+			return v
+		case string:
+			f, _ := strconv.ParseFloat(v, 64)
+			return f
+		default:
+			return 0
+		}
+	}
+
+	items := make([]billing.RawBillItem, 0, len(result.Properties.Rows))
+	for _, row := range result.Properties.Rows {
+		resourceID := str(row, "ResourceId")
+		serviceName := str(row, "ServiceName")
+		amount := num(row, "Cost")
+		usageQty := num(row, "UsageQuantity")
+		currency := str(row, "Currency")
+		if currency == "" {
+			currency = "USD"
+		}
+
+		usageDate := str(row, "UsageDate")
+		if usageDate == "" {
+			usageDate = str(row, "BillingMonth")
+		}
+
+		region := azurecommon.NormalizeLocation(str(row, "ResourceLocation"))
+		resourceName := ""
+		resourceGroup := ""
+		if resourceID != "" {
+			resourceName = azurecommon.NameFromID(resourceID)
+			resourceGroup = azurecommon.ResourceGroupFromID(resourceID)
+		}
+		if resourceName == "" {
+			resourceName = serviceName
+		}
+
+		rawData := map[string]any{
+			"ResourceId":    resourceID,
+			"ResourceGroup": resourceGroup,
+			"ServiceName":   serviceName,
+			"UsageDate":     usageDate,
+			"Cost":          amount,
+			"UsageQuantity": usageQty,
+			"Currency":      currency,
+		}
+		if region != "" {
+			rawData["ResourceLocation"] = region
+		}
+
+		items = append(items, billing.RawBillItem{
+			Provider:     domain.CloudProviderAzure,
+			RawData:      rawData,
+			ServiceType:  serviceName,
+			ResourceID:   resourceID,
+			ResourceName: resourceName,
+			Region:       region,
+			Amount:       amount,
+			Currency:     currency,
+			BillingCycle: billingCycle,
+			Tags:         make(map[string]string),
+		})
+	}
+	return items
+}
+
+// mapGranularity 将通用粒度映射为 Query API 粒度参数
+// Query API 仅支持 Daily 与 None (整个时间范围汇总)
+func mapGranularity(granularity string) string {
+	if strings.EqualFold(granularity, "daily") {
+		return "Daily"
+	}
+	return "None"
+}
+
+// asAuthError 检查是否为认证失败错误，返回格式化的错误信息
+func asAuthError(err error) error {
+	if !azurecommon.IsPermissionError(err) {
+		return nil
+	}
+	code := "unknown"
+	var respErr *azurecommon.ResponseError
+	if stderrors.As(err, &respErr) && respErr.Code != "" {
+		code = respErr.Code
+	}
+	return fmt.Errorf("[azure] authentication failed (code: %s): %w", code, err)
+}
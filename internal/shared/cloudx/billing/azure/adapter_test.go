@@ -0,0 +1,177 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTenantID       = "00000000-0000-0000-0000-000000000001"
+	testClientID       = "00000000-0000-0000-0000-000000000002"
+	testSubscriptionID = "00000000-0000-0000-0000-000000000003"
+)
+
+// newTestAdapter 创建指向本地 fixture 服务的计费适配器
+func newTestAdapter(t *testing.T, handler http.HandlerFunc) (*AzureBillingAdapter, string) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+testTenantID+"/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/", handler)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cred, err := azurecommon.ParseCredential(testTenantID+"/"+testClientID+"/"+testSubscriptionID, "secret")
+	require.NoError(t, err)
+
+	return &AzureBillingAdapter{
+		client:  azurecommon.NewClient(cred, azurecommon.Endpoints{Login: srv.URL, Management: srv.URL}),
+		account: &domain.CloudAccount{ID: 1},
+		logger:  elog.DefaultLogger,
+	}, srv.URL
+}
+
+// loadFixture 读取 testdata 下的录制响应，替换其中的服务地址占位符
+func loadFixture(t *testing.T, name, server string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return []byte(strings.ReplaceAll(string(data), "{{SERVER}}", server))
+}
+
+func TestFetchBillDetails_Paging(t *testing.T) {
+	var bodies []map[string]any
+	var serverURL string
+	adapter, serverURL := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/subscriptions/"+testSubscriptionID+"/providers/Microsoft.CostManagement/query", r.URL.Path)
+		assert.Equal(t, costManagementAPIVersion, r.URL.Query().Get("api-version"))
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+
+		if r.URL.Query().Get("$skiptoken") == "page2" {
+			_, _ = w.Write(loadFixture(t, "query_page2.json", serverURL))
+			return
+		}
+		_, _ = w.Write(loadFixture(t, "query_page1.json", serverURL))
+	})
+
+	items, err := adapter.FetchBillDetails(context.Background(), billing.FetchBillParams{
+		StartTime:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		Granularity: "daily",
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	require.Len(t, bodies, 2)
+
+	// 请求体: ActualCost + Custom 时间范围，to 为闭区间
+	assert.Equal(t, "ActualCost", bodies[0]["type"])
+	timePeriod := bodies[0]["timePeriod"].(map[string]any)
+	assert.Equal(t, "2024-03-01T00:00:00Z", timePeriod["from"])
+	assert.Equal(t, "2024-03-31T23:59:59Z", timePeriod["to"])
+	assert.Equal(t, "Daily", bodies[0]["dataset"].(map[string]any)["granularity"])
+
+	vm := items[0]
+	assert.Equal(t, domain.CloudProviderAzure, vm.Provider)
+	assert.Equal(t, "Virtual Machines", vm.ServiceType)
+	assert.Equal(t, "vm-web-01", vm.ResourceName)
+	assert.Equal(t, 12.5, vm.Amount)
+	assert.Equal(t, "USD", vm.Currency)
+	assert.Equal(t, "2024-03", vm.BillingCycle)
+	assert.Equal(t, "rg-prod", vm.RawData["ResourceGroup"])
+	assert.Equal(t, "20240301", vm.RawData["UsageDate"])
+	assert.Equal(t, 24.0, vm.RawData["UsageQuantity"])
+
+	// 无资源ID的费用 (如 DNS 查询) 以服务名作为资源名
+	dns := items[2]
+	assert.Equal(t, "", dns.ResourceID)
+	assert.Equal(t, "Azure DNS", dns.ResourceName)
+	assert.Equal(t, 3.2, dns.Amount)
+}
+
+func TestFetchBillDetails_AuthError(t *testing.T) {
+	adapter, _ := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"no access"}}`))
+	})
+
+	_, err := adapter.FetchBillDetails(context.Background(), billing.FetchBillParams{
+		StartTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[azure] authentication failed (code: AuthorizationFailed)")
+}
+
+func TestFetchBillTotal(t *testing.T) {
+	var body map[string]any
+	adapter, _ := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subscriptions/"+testSubscriptionID+"/providers/Microsoft.CostManagement/query", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"properties":{"columns":[{"name":"Cost","type":"Number"},{"name":"Currency","type":"String"}],"rows":[[215.7,"EUR"]]}}`))
+	})
+
+	total, err := adapter.FetchBillTotal(context.Background(), billing.FetchBillTotalParams{BillingCycle: "2024-02"})
+	require.NoError(t, err)
+	assert.Equal(t, 215.7, total.Amount)
+	assert.Equal(t, "EUR", total.Currency)
+	assert.Equal(t, "2024-02", total.BillingCycle)
+
+	// 整月汇总，不分组
+	timePeriod := body["timePeriod"].(map[string]any)
+	assert.Equal(t, "2024-02-01T00:00:00Z", timePeriod["from"])
+	assert.Equal(t, "2024-02-29T23:59:59Z", timePeriod["to"])
+	dataset := body["dataset"].(map[string]any)
+	assert.Equal(t, "None", dataset["granularity"])
+	assert.NotContains(t, dataset, "grouping")
+}
+
+func TestMapGranularity(t *testing.T) {
+	assert.Equal(t, "Daily", mapGranularity("daily"))
+	assert.Equal(t, "Daily", mapGranularity("Daily"))
+	assert.Equal(t, "None", mapGranularity("monthly"))
+	assert.Equal(t, "None", mapGranularity(""))
+}
+
+func TestAsAuthError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		expectAuth bool
+	}{
+		{"HTTP 401", &azurecommon.ResponseError{StatusCode: 401, Code: "InvalidAuthenticationToken"}, true},
+		{"HTTP 403", &azurecommon.ResponseError{StatusCode: 403, Code: "AuthorizationFailed"}, true},
+		{"invalid_client", &azurecommon.ResponseError{StatusCode: 400, Code: "invalid_client"}, true},
+		{"throttled", &azurecommon.ResponseError{StatusCode: 429, Code: "TooManyRequests"}, false},
+		{"server error", &azurecommon.ResponseError{StatusCode: 500, Code: "InternalServerError"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := asAuthError(tt.err)
+			if tt.expectAuth {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "[azure] authentication failed")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package billing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+// 承诺消费类型
+const (
+	CommitmentTypeReservedInstance = "reserved_instance" // 预留实例
+	CommitmentTypeSavingsPlan      = "savings_plan"      // 节省计划
+	CommitmentTypeResourcePackage  = "resource_package"  // 预付费资源包
+)
+
+// 已实现承诺消费接口的云厂商
+var commitmentProviders = struct {
+	mu        sync.RWMutex
+	providers map[domain.CloudProvider]bool
+}{providers: make(map[domain.CloudProvider]bool)}
+
+// RegisterCommitmentProvider 登记实现了 CommitmentAdapter 的云厂商
+// 各云厂商包在 init() 中与 RegisterBillingAdapter 一起调用，供无凭证场景判断是否支持承诺消费分析
+func RegisterCommitmentProvider(provider domain.CloudProvider) {
+	commitmentProviders.mu.Lock()
+	defer commitmentProviders.mu.Unlock()
+	commitmentProviders.providers[provider] = true
+}
+
+// SupportsCommitments 判断云厂商是否支持承诺消费分析
+func SupportsCommitments(provider domain.CloudProvider) bool {
+	commitmentProviders.mu.RLock()
+	defer commitmentProviders.mu.RUnlock()
+	return commitmentProviders.providers[provider]
+}
+
+// CommitmentAdapter 承诺消费适配器接口
+// 计费适配器可选实现，用于拉取已购买的预留实例、节省计划与资源包及其覆盖情况；
+// 未实现该接口的云厂商不参与承诺消费分析
+type CommitmentAdapter interface {
+	// FetchCommitments 拉取当前持有的承诺消费及其在统计区间内的利用率
+	FetchCommitments(ctx context.Context, params FetchCommitmentParams) ([]RawCommitment, error)
+
+	// FetchCoverage 拉取统计区间内按地域 / 实例族汇总的计算用量覆盖情况
+	// 厂商不提供覆盖率数据时返回空列表
+	FetchCoverage(ctx context.Context, params FetchCommitmentParams) ([]RawCoverage, error)
+}
+
+// FetchCommitmentParams 承诺消费拉取参数
+type FetchCommitmentParams struct {
+	AccountID string    // 云账号 ID
+	StartTime time.Time // 利用率 / 覆盖率统计起始时间
+	EndTime   time.Time // 利用率 / 覆盖率统计结束时间（开区间）
+}
+
+// RawCommitment 承诺消费条目
+type RawCommitment struct {
+	Provider         domain.CloudProvider   // 云厂商
+	CommitmentID     string                 // 厂商侧实例 ID / ARN
+	Type             string                 // 承诺类型，见 CommitmentType* 常量
+	Region           string                 // 适用地域，为空表示不限地域
+	InstanceFamily   string                 // 适用实例族，为空表示不限实例族
+	ServiceType      string                 // 适用服务类别（统一分类，如 compute / database），为空表示不限服务
+	InstanceType     string                 // 预留实例规格
+	Quantity         float64                // 预留实例数量 / 资源包总量
+	Unit             string                 // 资源包容量单位
+	HourlyCommitment float64                // 节省计划每小时承诺金额
+	UtilizationPct   float64                // 统计区间内利用率百分比（厂商口径）
+	PeriodCost       float64                // 统计区间内摊销的承诺费用
+	Currency         string                 // 币种
+	StartTime        time.Time              // 生效时间
+	EndTime          time.Time              // 到期时间
+	Status           string                 // 厂商原始状态
+	RawData          map[string]interface{} // 原始数据（保留完整字段用于审计）
+}
+
+// RawCoverage 计算用量覆盖情况（按地域 + 实例族汇总）
+type RawCoverage struct {
+	Region         string  // 地域
+	InstanceFamily string  // 实例族
+	CoveredHours   float64 // 被承诺消费覆盖的用量小时
+	OnDemandHours  float64 // 按需计费的用量小时
+	OnDemandCost   float64 // 按需费用
+	Currency       string  // 币种
+}
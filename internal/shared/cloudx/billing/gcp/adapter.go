@@ -0,0 +1,387 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// defaultPageSize 单页最大行数
+	defaultPageSize = 10000
+	// queryTimeoutMs 单次请求等待查询完成的最长时间
+	queryTimeoutMs = 60000
+	// maxPolls 查询未完成时的最大轮询次数
+	maxPolls = 20
+	// detailedExportMarker 资源级明细导出表名标识，仅该表包含 resource 字段
+	detailedExportMarker = "gcp_billing_export_resource_v1"
+)
+
+// GCPBillingAdapter GCP 计费适配器
+// 基于 BigQuery 账单导出表 (Cloud Billing export) 聚合查询费用明细
+type GCPBillingAdapter struct {
+	client  *gcpcommon.Client
+	account *domain.CloudAccount
+	logger  *elog.Component
+}
+
+func init() {
+	billing.RegisterBillingAdapter(domain.CloudProviderGCP, newGCPBillingAdapter)
+}
+
+// newGCPBillingAdapter 创建 GCP 计费适配器
+func newGCPBillingAdapter(account *domain.CloudAccount) (billing.BillingAdapter, error) {
+	client, err := gcpcommon.NewClientFromAccount(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gcp client: %w", err)
+	}
+	if client.Credential().BillingTable == "" {
+		return nil, fmt.Errorf("[gcp] billing export table is not configured, set access key id to projectID/project.dataset.table")
+	}
+
+	return &GCPBillingAdapter{
+		client:  client,
+		account: account,
+		logger:  elog.DefaultLogger,
+	}, nil
+}
+
+// GetProvider 获取云厂商标识
+func (a *GCPBillingAdapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderGCP
+}
+
+// queryResponse BigQuery jobs.query / jobs.getQueryResults 响应
+type queryResponse struct {
+	JobComplete  bool `json:"jobComplete"`
+	JobReference struct {
+		ProjectID string `json:"projectId"`
+		JobID     string `json:"jobId"`
+		Location  string `json:"location"`
+	} `json:"jobReference"`
+	Schema struct {
+		Fields []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"fields"`
+	} `json:"schema"`
+	Rows []struct {
+		F []struct {
+			V json.RawMessage `json:"v"`
+		} `json:"f"`
+	} `json:"rows"`
+	PageToken string `json:"pageToken"`
+}
+
+// FetchBillDetails 拉取指定时间范围的账单明细
+// 按服务、SKU、项目、地域、币种与标签分组汇总，费用为 cost 与 credits (负值) 之和
+func (a *GCPBillingAdapter) FetchBillDetails(ctx context.Context, params billing.FetchBillParams) ([]billing.RawBillItem, error) {
+	billingCycle := params.StartTime.Format("2006-01")
+
+	pageSize := params.PageSize
+	if pageSize <= 0 || pageSize > defaultPageSize {
+		pageSize = defaultPageSize
+	}
+
+	table := a.client.Credential().BillingTable
+	body := map[string]any{
+		"query":         buildQuery(table, params.Granularity),
+		"useLegacySql":  false,
+		"parameterMode": "NAMED",
+		"maxResults":    pageSize,
+		"timeoutMs":     queryTimeoutMs,
+		"queryParameters": []map[string]any{
+			timestampParam("start_time", params.StartTime),
+			timestampParam("end_time", params.EndTime),
+		},
+	}
+
+	var items []billing.RawBillItem
+	err := a.runQuery(ctx, body, pageSize, func(result *queryResponse) {
+		items = append(items, parseQueryResponse(result, billingCycle)...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info("fetch gcp bill details success",
+		elog.Int64("account_id", a.account.ID),
+		elog.String("billing_cycle", billingCycle),
+		elog.Int("count", len(items)))
+
+	return items, nil
+}
+
+// runQuery 提交 BigQuery 查询，轮询至作业完成并逐页回调结果
+func (a *GCPBillingAdapter) runQuery(ctx context.Context, body map[string]any, pageSize int, onPage func(*queryResponse)) error {
+	jobsPath := "/bigquery/v2/projects/" + a.client.ProjectID() + "/queries"
+
+	var result queryResponse
+	if err := a.client.Do(ctx, http.MethodPost, gcpcommon.ServiceBigQuery, jobsPath, nil, body, &result); err != nil {
+		if authErr := asAuthError(err); authErr != nil {
+			return authErr
+		}
+		return fmt.Errorf("[gcp] query billing export failed: %w", err)
+	}
+
+	for polls := 0; ; {
+		if result.JobComplete {
+			onPage(&result)
+			if result.PageToken == "" {
+				return nil
+			}
+		} else {
+			polls++
+			if polls > maxPolls {
+				return fmt.Errorf("[gcp] billing query job %s not completed after %d polls", result.JobReference.JobID, maxPolls)
+			}
+		}
+
+		query := url.Values{}
+		query.Set("maxResults", strconv.Itoa(pageSize))
+		query.Set("timeoutMs", strconv.Itoa(queryTimeoutMs))
+		if result.JobReference.Location != "" {
+			query.Set("location", result.JobReference.Location)
+		}
+		if result.JobComplete {
+			query.Set("pageToken", result.PageToken)
+		}
+
+		jobID := result.JobReference.JobID
+		if jobID == "" {
+			return fmt.Errorf("[gcp] billing query response missing job reference")
+		}
+		var next queryResponse
+		if err := a.client.Do(ctx, http.MethodGet, gcpcommon.ServiceBigQuery, jobsPath+"/"+jobID, query, nil, &next); err != nil {
+			if authErr := asAuthError(err); authErr != nil {
+				return authErr
+			}
+			return fmt.Errorf("[gcp] get billing query results failed: %w", err)
+		}
+		// getQueryResults 响应中 jobReference 可能缺省 location
+		if next.JobReference.JobID == "" {
+			next.JobReference = result.JobReference
+		}
+		result = next
+	}
+}
+
+// FetchBillTotal 按发票月份 (invoice.month) 汇总账期费用，费用为 cost 与 credits 之和
+func (a *GCPBillingAdapter) FetchBillTotal(ctx context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	cycle, err := time.Parse("2006-01", params.BillingCycle)
+	if err != nil {
+		return nil, fmt.Errorf("parse billing cycle %s: %w", params.BillingCycle, err)
+	}
+
+	body := map[string]any{
+		"query":         buildTotalQuery(a.client.Credential().BillingTable),
+		"useLegacySql":  false,
+		"parameterMode": "NAMED",
+		"timeoutMs":     queryTimeoutMs,
+		"queryParameters": []map[string]any{
+			timestampParam("start_time", cycle),
+			{
+				"name":           "invoice_month",
+				"parameterType":  map[string]string{"type": "STRING"},
+				"parameterValue": map[string]string{"value": cycle.Format("200601")},
+			},
+		},
+	}
+
+	total := &billing.BillTotal{
+		Provider:     domain.CloudProviderGCP,
+		BillingCycle: params.BillingCycle,
+		Currency:     "USD",
+	}
+	err = a.runQuery(ctx, body, defaultPageSize, func(result *queryResponse) {
+		for _, item := range parseQueryResponse(result, params.BillingCycle) {
+			total.Amount += item.Amount
+			total.Currency = item.Currency
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// buildTotalQuery 构建发票月份汇总查询
+func buildTotalQuery(table string) string {
+	// 发票月份的费用可能在次月初才写入导出表，分区仅限定下限
+	return "SELECT currency," +
+		" SUM(cost) AS cost," +
+		" SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)) AS credits" +
+		" FROM `" + table + "`" +
+		" WHERE _PARTITIONTIME >= TIMESTAMP_SUB(@start_time, INTERVAL 1 DAY)" +
+		" AND invoice.month = @invoice_month" +
+		" GROUP BY currency"
+}
+
+// buildQuery 构建账单导出表的聚合查询
+// 资源级明细导出表额外按资源分组，daily 粒度额外按用量日期分组
+func buildQuery(table, granularity string) string {
+	columns := []string{
+		"service.description AS service",
+		"sku.description AS sku",
+		"project.id AS project_id",
+		"IFNULL(location.region, location.location) AS region",
+		"currency",
+		"TO_JSON_STRING(labels) AS labels",
+	}
+	groups := []string{"service", "sku", "project_id", "region", "currency", "labels"}
+
+	if strings.Contains(table, detailedExportMarker) {
+		columns = append(columns, "resource.global_name AS resource_id", "resource.name AS resource_name")
+		groups = append(groups, "resource_id", "resource_name")
+	}
+	if mapGranularity(granularity) == "DAY" {
+		columns = append(columns, "FORMAT_DATE('%Y%m%d', DATE(usage_start_time)) AS usage_date")
+		groups = append(groups, "usage_date")
+	}
+
+	columns = append(columns,
+		"SUM(cost) AS cost",
+		"SUM(IFNULL((SELECT SUM(c.amount) FROM UNNEST(credits) c), 0)) AS credits",
+		"SUM(usage.amount) AS usage_amount",
+		"ANY_VALUE(usage.unit) AS usage_unit",
+	)
+
+	// 导出表按导出时间分区，延迟写入的费用可能晚于用量时间，分区下限放宽一天
+	return "SELECT " + strings.Join(columns, ", ") +
+		" FROM `" + table + "`" +
+		" WHERE _PARTITIONTIME >= TIMESTAMP_SUB(@start_time, INTERVAL 1 DAY)" +
+		" AND usage_start_time >= @start_time AND usage_start_time < @end_time" +
+		" GROUP BY " + strings.Join(groups, ", ")
+}
+
+// timestampParam 构建 TIMESTAMP 类型的命名查询参数
+func timestampParam(name string, t time.Time) map[string]any {
+	return map[string]any{
+		"name":           name,
+		"parameterType":  map[string]string{"type": "TIMESTAMP"},
+		"parameterValue": map[string]string{"value": t.UTC().Format("2006-01-02 15:04:05")},
+	}
+}
+
+// parseQueryResponse 按字段名解析查询结果行，BigQuery 的单元格值均以字符串返回
+func parseQueryResponse(result *queryResponse, billingCycle string) []billing.RawBillItem {
+	index := make(map[string]int, len(result.Schema.Fields))
+	for i, field := range result.Schema.Fields {
+		index[field.Name] = i
+	}
+
+	items := make([]billing.RawBillItem, 0, len(result.Rows))
+	for _, row := range result.Rows {
+		str := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(row.F) {
+				return ""
+			}
+			var v string
+			_ = json.Unmarshal(row.F[i].V, &v)
+			return v
+		}
+		num := func(name string) float64 {
+			f, _ := strconv.ParseFloat(str(name), 64)
+			return f
+		}
+
+		serviceName := str("service")
+		cost := num("cost")
+		credits := num("credits")
+		amount := cost + credits
+		currency := str("currency")
+		if currency == "" {
+			currency = "USD"
+		}
+
+		resourceID := str("resource_id")
+		resourceName := str("resource_name")
+		if resourceName == "" {
+			resourceName = serviceName
+		}
+
+		rawData := map[string]any{
+			"Service":     serviceName,
+			"Sku":         str("sku"),
+			"ProjectId":   str("project_id"),
+			"Cost":        cost,
+			"Credits":     credits,
+			"UsageAmount": num("usage_amount"),
+			"UsageUnit":   str("usage_unit"),
+			"Currency":    currency,
+		}
+		if usageDate := str("usage_date"); usageDate != "" {
+			rawData["UsageDate"] = usageDate
+		}
+		if resourceID != "" {
+			rawData["ResourceId"] = resourceID
+		}
+
+		items = append(items, billing.RawBillItem{
+			Provider:     domain.CloudProviderGCP,
+			RawData:      rawData,
+			ServiceType:  serviceName,
+			ResourceID:   resourceID,
+			ResourceName: resourceName,
+			Region:       str("region"),
+			Amount:       amount,
+			Currency:     currency,
+			BillingCycle: billingCycle,
+			Tags:         parseLabels(str("labels")),
+		})
+	}
+	return items
+}
+
+// parseLabels 解析 TO_JSON_STRING(labels) 结果 [{"key":"k","value":"v"}]
+func parseLabels(raw string) map[string]string {
+	tags := make(map[string]string)
+	if raw == "" {
+		return tags
+	}
+	var labels []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal([]byte(raw), &labels); err != nil {
+		return tags
+	}
+	for _, l := range labels {
+		tags[l.Key] = l.Value
+	}
+	return tags
+}
+
+// mapGranularity 将通用粒度映射为查询分组粒度
+// DAY 按用量日期分组，MONTH 为整个时间范围汇总
+func mapGranularity(granularity string) string {
+	if strings.EqualFold(granularity, "daily") {
+		return "DAY"
+	}
+	return "MONTH"
+}
+
+// asAuthError 检查是否为认证失败错误，返回格式化的错误信息
+func asAuthError(err error) error {
+	if !gcpcommon.IsPermissionError(err) {
+		return nil
+	}
+	code := "unknown"
+	var respErr *gcpcommon.ResponseError
+	if stderrors.As(err, &respErr) && respErr.Status != "" {
+		code = respErr.Status
+	}
+	return fmt.Errorf("[gcp] authentication failed (code: %s): %w", code, err)
+}
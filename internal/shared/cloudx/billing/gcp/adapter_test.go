@@ -0,0 +1,251 @@
+package gcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProjectID    = "test-project"
+	testBillingTable = "test-project.billing.gcp_billing_export_resource_v1_0123AB_CDEF45"
+	testJobsPath     = "/bigquery/bigquery/v2/projects/" + testProjectID + "/queries"
+)
+
+// testServiceAccountKey 生成测试用服务账号 JSON 密钥
+func testServiceAccountKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	data, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   testProjectID,
+		"private_key":  string(keyPEM),
+		"client_email": "billing-reader@test-project.iam.gserviceaccount.com",
+	})
+	require.NoError(t, err)
+	return string(data)
+}
+
+// newTestAdapter 创建指向本地 fixture 服务的计费适配器
+func newTestAdapter(t *testing.T, handler http.HandlerFunc) *GCPBillingAdapter {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/", handler)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cred, err := gcpcommon.ParseCredential(testProjectID+"/"+testBillingTable, testServiceAccountKey(t))
+	require.NoError(t, err)
+
+	return &GCPBillingAdapter{
+		client:  gcpcommon.NewClient(cred, gcpcommon.Endpoints{Token: srv.URL + "/token", API: srv.URL}),
+		account: &domain.CloudAccount{ID: 1},
+		logger:  elog.DefaultLogger,
+	}
+}
+
+// loadFixture 读取 testdata 下的录制响应
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	return data
+}
+
+func TestFetchBillDetails_Paging(t *testing.T) {
+	var body map[string]any
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == testJobsPath:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			_, _ = w.Write(loadFixture(t, "query_page1.json"))
+		case r.Method == http.MethodGet && r.URL.Path == testJobsPath+"/job_8f2c1d":
+			assert.Equal(t, "page2", r.URL.Query().Get("pageToken"))
+			assert.Equal(t, "US", r.URL.Query().Get("location"))
+			_, _ = w.Write(loadFixture(t, "query_page2.json"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	items, err := adapter.FetchBillDetails(context.Background(), billing.FetchBillParams{
+		StartTime:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		Granularity: "daily",
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	// 请求体: 标准 SQL + 命名参数，时间范围为左闭右开
+	assert.Equal(t, false, body["useLegacySql"])
+	assert.Equal(t, "NAMED", body["parameterMode"])
+	params := body["queryParameters"].([]any)
+	require.Len(t, params, 2)
+	assert.Equal(t, "2024-03-01 00:00:00", params[0].(map[string]any)["parameterValue"].(map[string]any)["value"])
+	assert.Equal(t, "2024-04-01 00:00:00", params[1].(map[string]any)["parameterValue"].(map[string]any)["value"])
+	assert.Contains(t, body["query"], "FROM `"+testBillingTable+"`")
+	assert.Contains(t, body["query"], "usage_date")
+
+	vm := items[0]
+	assert.Equal(t, domain.CloudProviderGCP, vm.Provider)
+	assert.Equal(t, "Compute Engine", vm.ServiceType)
+	assert.Equal(t, "vm-web-01", vm.ResourceName)
+	assert.Equal(t, "us-central1", vm.Region)
+	// 费用为 cost 与 credits 之和
+	assert.InDelta(t, 10.0, vm.Amount, 0.0001)
+	assert.Equal(t, "USD", vm.Currency)
+	assert.Equal(t, "2024-03", vm.BillingCycle)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "web"}, vm.Tags)
+	assert.Equal(t, "test-project", vm.RawData["ProjectId"])
+	assert.Equal(t, "20240301", vm.RawData["UsageDate"])
+	assert.Equal(t, 12.5, vm.RawData["Cost"])
+
+	assert.Empty(t, items[1].Tags)
+
+	// 无资源的费用 (如 DNS 托管区) 以服务名作为资源名
+	dns := items[2]
+	assert.Equal(t, "", dns.ResourceID)
+	assert.Equal(t, "Cloud DNS", dns.ResourceName)
+	assert.Equal(t, "", dns.Region)
+	assert.Equal(t, 3.2, dns.Amount)
+}
+
+func TestFetchBillDetails_PollsUntilComplete(t *testing.T) {
+	polls := 0
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			_, _ = w.Write([]byte(`{"jobComplete":false,"jobReference":{"projectId":"test-project","jobId":"job_8f2c1d","location":"US"}}`))
+		case http.MethodGet:
+			polls++
+			assert.Empty(t, r.URL.Query().Get("pageToken"))
+			if polls == 1 {
+				_, _ = w.Write([]byte(`{"jobComplete":false}`))
+				return
+			}
+			_, _ = w.Write(loadFixture(t, "query_page2.json"))
+		}
+	})
+
+	items, err := adapter.FetchBillDetails(context.Background(), billing.FetchBillParams{
+		StartTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, polls)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Cloud DNS", items[0].ServiceType)
+}
+
+func TestFetchBillDetails_AuthError(t *testing.T) {
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error":{"code":403,"message":"Access Denied: Table test-project:billing.export","status":"PERMISSION_DENIED","errors":[{"reason":"accessDenied"}]}}`))
+	})
+
+	_, err := adapter.FetchBillDetails(context.Background(), billing.FetchBillParams{
+		StartTime: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[gcp] authentication failed (code: PERMISSION_DENIED)")
+}
+
+func TestFetchBillTotal(t *testing.T) {
+	var body map[string]any
+	adapter := newTestAdapter(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, testJobsPath, r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		_, _ = w.Write([]byte(`{"jobComplete":true,"jobReference":{"jobId":"job_total"},` +
+			`"schema":{"fields":[{"name":"currency","type":"STRING"},{"name":"cost","type":"FLOAT"},{"name":"credits","type":"FLOAT"}]},` +
+			`"rows":[{"f":[{"v":"USD"},{"v":"520.75"},{"v":"-20.75"}]}]}`))
+	})
+
+	total, err := adapter.FetchBillTotal(context.Background(), billing.FetchBillTotalParams{BillingCycle: "2024-03"})
+	require.NoError(t, err)
+	assert.InDelta(t, 500.0, total.Amount, 0.0001)
+	assert.Equal(t, "USD", total.Currency)
+
+	// 按发票月份汇总
+	assert.Contains(t, body["query"], "invoice.month = @invoice_month")
+	params := body["queryParameters"].([]any)
+	require.Len(t, params, 2)
+	assert.Equal(t, "202403", params[1].(map[string]any)["parameterValue"].(map[string]any)["value"])
+}
+
+func TestNewGCPBillingAdapter_RequiresBillingTable(t *testing.T) {
+	_, err := newGCPBillingAdapter(&domain.CloudAccount{
+		AccessKeyID:     testProjectID,
+		AccessKeySecret: testServiceAccountKey(t),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "billing export table is not configured")
+}
+
+func TestBuildQuery(t *testing.T) {
+	// 标准导出表不含 resource 字段，按月汇总时不按日期分组
+	q := buildQuery("test-project.billing.gcp_billing_export_v1_0123AB_CDEF45", "monthly")
+	assert.NotContains(t, q, "resource.")
+	assert.NotContains(t, q, "usage_date")
+	assert.Contains(t, q, "GROUP BY service, sku, project_id, region, currency, labels")
+
+	q = buildQuery(testBillingTable, "daily")
+	assert.Contains(t, q, "resource.global_name AS resource_id")
+	assert.Contains(t, q, "GROUP BY service, sku, project_id, region, currency, labels, resource_id, resource_name, usage_date")
+}
+
+func TestMapGranularity(t *testing.T) {
+	assert.Equal(t, "DAY", mapGranularity("daily"))
+	assert.Equal(t, "DAY", mapGranularity("Daily"))
+	assert.Equal(t, "MONTH", mapGranularity("monthly"))
+	assert.Equal(t, "MONTH", mapGranularity(""))
+}
+
+func TestAsAuthError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		expectAuth bool
+	}{
+		{"HTTP 401", &gcpcommon.ResponseError{StatusCode: 401, Status: "UNAUTHENTICATED"}, true},
+		{"HTTP 403", &gcpcommon.ResponseError{StatusCode: 403, Status: "PERMISSION_DENIED"}, true},
+		{"invalid_grant", &gcpcommon.ResponseError{StatusCode: 400, Status: "invalid_grant"}, true},
+		{"rate limited", &gcpcommon.ResponseError{StatusCode: 403, Status: "PERMISSION_DENIED", Reason: "rateLimitExceeded"}, false},
+		{"server error", &gcpcommon.ResponseError{StatusCode: 500, Status: "INTERNAL"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := asAuthError(tt.err)
+			if tt.expectAuth {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "[gcp] authentication failed")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -198,6 +198,19 @@ func parseBillRecords(response *model.ListCustomerselfResourceRecordDetailsRespo
 		}
 		rawData["ConsumeAmount"] = amount
 
+		// 折扣与抵扣明细
+		rawData["DiscountAmount"] = decimalVal(record.DiscountAmount)
+		rawData["CouponAmount"] = decimalVal(record.CouponAmount)
+		rawData["FlexipurchaseCouponAmount"] = decimalVal(record.FlexipurchaseCouponAmount)
+		rawData["StoredCardAmount"] = decimalVal(record.StoredCardAmount)
+		rawData["BonusAmount"] = decimalVal(record.BonusAmount)
+		if effective := strVal(record.EffectiveTime); effective != "" {
+			rawData["EffectiveTime"] = effective
+		}
+		if expire := strVal(record.ExpireTime); expire != "" {
+			rawData["ExpireTime"] = expire
+		}
+
 		tags := make(map[string]string)
 		if tag := strVal(record.ResourceTag); tag != "" {
 			tags["raw_tag"] = tag
@@ -240,6 +253,50 @@ func parseBillRecords(response *model.ListCustomerselfResourceRecordDetailsRespo
 	return items
 }
 
+// FetchBillTotal 拉取账期的消费汇总金额 (ConsumeAmount)
+func (a *HuaweiBillingAdapter) FetchBillTotal(ctx context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	client, err := a.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var total *billing.BillTotal
+	err = retry.WithBackoff(ctx, maxRetries, func() error {
+		response, err := client.ShowCustomerMonthlySum(&model.ShowCustomerMonthlySumRequest{
+			BillCycle: params.BillingCycle,
+		})
+		if err != nil {
+			if authErr := asAuthError(err); authErr != nil {
+				return authErr
+			}
+			return err
+		}
+		total = parseMonthlySum(response, params.BillingCycle)
+		return nil
+	}, isRetryable)
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+// parseMonthlySum 解析月度汇总响应，币种缺省为 CNY
+func parseMonthlySum(response *model.ShowCustomerMonthlySumResponse, billingCycle string) *billing.BillTotal {
+	total := &billing.BillTotal{
+		Provider:     domain.CloudProviderHuawei,
+		BillingCycle: billingCycle,
+		Currency:     "CNY",
+	}
+	if response == nil {
+		return total
+	}
+	total.Amount = decimalVal(response.ConsumeAmount)
+	if currency := strVal(response.Currency); currency != "" {
+		total.Currency = currency
+	}
+	return total
+}
+
 // asAuthError 检查是否为认证失败错误，返回格式化的错误信息
 func asAuthError(err error) error {
 	if sdkErr, ok := err.(*sdkerr.ServiceResponseError); ok {
@@ -318,5 +375,14 @@ func int32Val(v *int32) int32 {
 	return *v
 }
 
+// decimalVal 将 SDK 的 decimal 金额转换为 float64，nil 视为 0
+func decimalVal[T interface{ Float64() (float64, bool) }](v *T) float64 {
+	if v == nil {
+		return 0
+	}
+	f, _ := (*v).Float64()
+	return f
+}
+
 // 确保编译时检查接口实现
 var _ billing.BillingAdapter = (*HuaweiBillingAdapter)(nil)
@@ -6,9 +6,27 @@ import (
 	"testing"
 
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/sdkerr"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/bss/v2/model"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestParseMonthlySum(t *testing.T) {
+	amount := decimal.RequireFromString("3021.57")
+	currency := "CNY"
+	total := parseMonthlySum(&model.ShowCustomerMonthlySumResponse{
+		ConsumeAmount: &amount,
+		Currency:      &currency,
+	}, "2024-03")
+	assert.Equal(t, "2024-03", total.BillingCycle)
+	assert.InDelta(t, 3021.57, total.Amount, 1e-9)
+	assert.Equal(t, "CNY", total.Currency)
+
+	empty := parseMonthlySum(&model.ShowCustomerMonthlySumResponse{}, "2024-04")
+	assert.Zero(t, empty.Amount)
+	assert.Equal(t, "CNY", empty.Currency)
+}
+
 func TestAsAuthError(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
@@ -123,15 +124,21 @@ func parseBillDetails(response *tcbilling.DescribeBillDetailResponse, billingCyc
 			"BillDay":          strVal(detail.BillDay),
 			"BillMonth":        strVal(detail.BillMonth),
 		}
-		amount := 0.0
+		amount, listCost, voucher, incentive := 0.0, 0.0, 0.0, 0.0
 		if detail.ComponentSet != nil {
 			for _, comp := range detail.ComponentSet {
 				if comp.RealCost != nil {
 					amount += parseFloat(strVal(comp.RealCost))
 				}
+				listCost += parseFloat(strVal(comp.Cost))
+				voucher += parseFloat(strVal(comp.VoucherPayAmount))
+				incentive += parseFloat(strVal(comp.IncentivePayAmount))
 			}
 		}
 		rawData["TotalCost"] = amount
+		rawData["ListCost"] = listCost
+		rawData["VoucherPayAmount"] = voucher
+		rawData["IncentivePayAmount"] = incentive
 		tags := make(map[string]string)
 		if detail.Tags != nil {
 			for _, tag := range detail.Tags {
@@ -166,6 +173,44 @@ func parseBillDetails(response *tcbilling.DescribeBillDetailResponse, billingCyc
 	return items
 }
 
+// FetchBillTotal 按产品汇总接口的 SummaryTotal.RealTotalCost 作为账期应付总额
+func (a *TencentBillingAdapter) FetchBillTotal(ctx context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	cycle, err := time.Parse("2006-01", params.BillingCycle)
+	if err != nil {
+		return nil, fmt.Errorf("parse billing cycle %s: %w", params.BillingCycle, err)
+	}
+	beginTime := cycle.Format("2006-01-02 15:04:05")
+	endTime := cycle.AddDate(0, 1, 0).Add(-time.Second).Format("2006-01-02 15:04:05")
+	var total *billing.BillTotal
+	err = retry.WithBackoff(ctx, maxRetries, func() error {
+		request := tcbilling.NewDescribeBillSummaryByProductRequest()
+		request.BeginTime = &beginTime
+		request.EndTime = &endTime
+		response, err := a.client.DescribeBillSummaryByProduct(request)
+		if err != nil {
+			if authErr := asAuthError(err); authErr != nil {
+				return authErr
+			}
+			return err
+		}
+		total = parseSummaryTotal(response, params.BillingCycle)
+		return nil
+	}, isRetryable)
+	if err != nil {
+		return nil, err
+	}
+	return total, nil
+}
+
+func parseSummaryTotal(response *tcbilling.DescribeBillSummaryByProductResponse, billingCycle string) *billing.BillTotal {
+	total := &billing.BillTotal{Provider: domain.CloudProviderTencent, BillingCycle: billingCycle, Currency: "CNY"}
+	if response == nil || response.Response == nil || response.Response.SummaryTotal == nil {
+		return total
+	}
+	total.Amount = parseFloat(strVal(response.Response.SummaryTotal.RealTotalCost))
+	return total
+}
+
 func asAuthError(err error) error {
 	if sdkErr, ok := err.(*tcerr.TencentCloudSDKError); ok {
 		code := sdkErr.Code
@@ -6,9 +6,23 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	tcbilling "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/billing/v20180709"
 	tcerr "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/errors"
 )
 
+func TestParseSummaryTotal(t *testing.T) {
+	realTotal := "8866.32"
+	response := tcbilling.NewDescribeBillSummaryByProductResponse()
+	response.Response = &tcbilling.DescribeBillSummaryByProductResponseParams{
+		SummaryTotal: &tcbilling.BusinessSummaryTotal{RealTotalCost: &realTotal},
+	}
+	total := parseSummaryTotal(response, "2024-03")
+	assert.InDelta(t, 8866.32, total.Amount, 1e-9)
+	assert.Equal(t, "CNY", total.Currency)
+
+	assert.Zero(t, parseSummaryTotal(tcbilling.NewDescribeBillSummaryByProductResponse(), "2024-03").Amount)
+}
+
 func TestAsAuthError(t *testing.T) {
 	tests := []struct {
 		name       string
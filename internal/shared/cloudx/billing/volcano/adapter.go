@@ -189,6 +189,69 @@ func parseBillDetailItems(output *volcbilling.ListBillDetailOutput, billingCycle
 	return items
 }
 
+// FetchBillTotal 拉取账期按产品汇总的账单总览，应付金额 (PayableAmount) 求和
+func (a *VolcanoBillingAdapter) FetchBillTotal(ctx context.Context, params billing.FetchBillTotalParams) (*billing.BillTotal, error) {
+	total := &billing.BillTotal{
+		Provider:     domain.CloudProviderVolcano,
+		BillingCycle: params.BillingCycle,
+		Currency:     "CNY",
+	}
+
+	limit := int32(defaultPageSize)
+	offset := int32(0)
+	for {
+		var pageSize int
+		var count int32
+		currentOffset := offset
+
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			input := &volcbilling.ListBillOverviewByProdInput{}
+			input.SetBillPeriod(params.BillingCycle)
+			input.SetLimit(limit)
+			input.SetOffset(currentOffset)
+			input.SetNeedRecordNum(1)
+
+			response, err := a.client.ListBillOverviewByProdWithContext(ctx, input)
+			if err != nil {
+				if authErr := asAuthError(err); authErr != nil {
+					return authErr
+				}
+				return err
+			}
+
+			addBillOverview(total, response)
+			pageSize = len(response.List)
+			if response.Total != nil {
+				count = *response.Total
+			}
+			return nil
+		}, isRetryable)
+		if err != nil {
+			return nil, err
+		}
+
+		offset += limit
+		if offset >= count || pageSize == 0 {
+			break
+		}
+	}
+
+	return total, nil
+}
+
+// addBillOverview 累加一页账单总览的应付金额
+func addBillOverview(total *billing.BillTotal, output *volcbilling.ListBillOverviewByProdOutput) {
+	if output == nil {
+		return
+	}
+	for _, item := range output.List {
+		total.Amount += parseFloat(strVal(item.PayableAmount))
+		if currency := strVal(item.Currency); currency != "" {
+			total.Currency = currency
+		}
+	}
+}
+
 // asAuthError 检查是否为认证失败错误，返回格式化的错误信息
 func asAuthError(err error) error {
 	var volcErr volcengineerr.Error
@@ -5,10 +5,28 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/billing"
 	"github.com/stretchr/testify/assert"
+	volcbilling "github.com/volcengine/volcengine-go-sdk/service/billing"
+	"github.com/volcengine/volcengine-go-sdk/volcengine"
 	"github.com/volcengine/volcengine-go-sdk/volcengine/volcengineerr"
 )
 
+func TestAddBillOverview(t *testing.T) {
+	total := &billing.BillTotal{Currency: "CNY"}
+	addBillOverview(total, &volcbilling.ListBillOverviewByProdOutput{
+		List: []*volcbilling.ListForListBillOverviewByProdOutput{
+			{Product: volcengine.String("ECS"), PayableAmount: volcengine.String("100.50")},
+			{Product: volcengine.String("TOS"), PayableAmount: volcengine.String("20.25")},
+			{Product: volcengine.String("CDN")},
+		},
+	})
+	addBillOverview(total, nil)
+
+	assert.InDelta(t, 120.75, total.Amount, 1e-9)
+	assert.Equal(t, "CNY", total.Currency)
+}
+
 func TestAsAuthError(t *testing.T) {
 	tests := []struct {
 		name       string
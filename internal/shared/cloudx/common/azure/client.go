@@ -0,0 +1,423 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+const (
+	// DefaultLoginEndpoint Entra ID (Azure AD) 登录端点
+	DefaultLoginEndpoint = "https://login.microsoftonline.com"
+	// DefaultManagementEndpoint Azure Resource Manager 端点
+	DefaultManagementEndpoint = "https://management.azure.com"
+	// DefaultGraphEndpoint Microsoft Graph 端点
+	DefaultGraphEndpoint = "https://graph.microsoft.com"
+
+	// ManagementScope ARM 访问令牌作用域
+	ManagementScope = "https://management.azure.com/.default"
+	// GraphScope Graph 访问令牌作用域
+	GraphScope = "https://graph.microsoft.com/.default"
+
+	// subscriptionAPIVersion 订阅/地域 API 版本
+	subscriptionAPIVersion = "2022-12-01"
+	// maxRetries 最大重试次数
+	maxRetries = 3
+)
+
+// Credential Azure 服务主体凭证
+type Credential struct {
+	TenantID       string
+	ClientID       string
+	ClientSecret   string
+	SubscriptionID string
+}
+
+// ParseCredential 从云账号 AK/SK 解析 Azure 凭证
+// AccessKeyID 格式: tenantID/clientID[/subscriptionID]，AccessKeySecret 为客户端密钥
+// 未指定 subscriptionID 时使用服务主体可见的第一个订阅
+func ParseCredential(accessKeyID, accessKeySecret string) (*Credential, error) {
+	parts := strings.Split(strings.TrimSpace(accessKeyID), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("azure access key id should be tenantID/clientID[/subscriptionID]")
+	}
+	for _, p := range parts {
+		if !isGUID(p) {
+			return nil, fmt.Errorf("azure access key id segment %q is not a GUID", p)
+		}
+	}
+	if accessKeySecret == "" {
+		return nil, fmt.Errorf("azure client secret is empty")
+	}
+
+	cred := &Credential{
+		TenantID:     parts[0],
+		ClientID:     parts[1],
+		ClientSecret: accessKeySecret,
+	}
+	if len(parts) == 3 {
+		cred.SubscriptionID = parts[2]
+	}
+	return cred, nil
+}
+
+// isGUID 检查是否为 GUID 格式: xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx
+func isGUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Endpoints Azure 服务端点，测试时可替换为本地服务地址
+type Endpoints struct {
+	Login      string
+	Management string
+	Graph      string
+}
+
+// DefaultEndpoints Azure 公有云端点
+var DefaultEndpoints = Endpoints{
+	Login:      DefaultLoginEndpoint,
+	Management: DefaultManagementEndpoint,
+	Graph:      DefaultGraphEndpoint,
+}
+
+// cachedToken 缓存的访问令牌
+type cachedToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Client Azure REST 客户端
+// 负责 OAuth2 client_credentials 令牌获取与缓存、分页、限流和重试
+type Client struct {
+	cred        *Credential
+	endpoints   Endpoints
+	httpClient  *http.Client
+	rateLimiter *RateLimiter
+
+	mu             sync.Mutex
+	tokens         map[string]cachedToken
+	subscriptionID string
+}
+
+// NewClient 创建 Azure REST 客户端
+func NewClient(cred *Credential, endpoints Endpoints) *Client {
+	return &Client{
+		cred:           cred,
+		endpoints:      endpoints,
+		httpClient:     &http.Client{Timeout: 60 * time.Second},
+		rateLimiter:    NewRateLimiter(20),
+		tokens:         make(map[string]cachedToken),
+		subscriptionID: cred.SubscriptionID,
+	}
+}
+
+// NewClientFromAccount 根据云账号创建 Azure REST 客户端
+func NewClientFromAccount(account *domain.CloudAccount) (*Client, error) {
+	if account == nil {
+		return nil, fmt.Errorf("cloud account cannot be nil")
+	}
+	cred, err := ParseCredential(account.AccessKeyID, account.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(cred, DefaultEndpoints), nil
+}
+
+// Credential 获取客户端凭证
+func (c *Client) Credential() *Credential {
+	return c.cred
+}
+
+// SubscriptionID 获取订阅ID，未配置时自动发现第一个可用订阅
+func (c *Client) SubscriptionID(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	sub := c.subscriptionID
+	c.mu.Unlock()
+	if sub != "" {
+		return sub, nil
+	}
+
+	items, err := c.ListARM(ctx, "/subscriptions", subscriptionAPIVersion, nil)
+	if err != nil {
+		return "", fmt.Errorf("list subscriptions failed: %w", err)
+	}
+	for _, raw := range items {
+		var s struct {
+			SubscriptionID string `json:"subscriptionId"`
+			State          string `json:"state"`
+		}
+		if err := json.Unmarshal(raw, &s); err != nil {
+			continue
+		}
+		if s.SubscriptionID != "" && (s.State == "" || strings.EqualFold(s.State, "Enabled")) {
+			c.mu.Lock()
+			c.subscriptionID = s.SubscriptionID
+			c.mu.Unlock()
+			return s.SubscriptionID, nil
+		}
+	}
+	return "", fmt.Errorf("no enabled subscription found for client %s", c.cred.ClientID)
+}
+
+// SubscriptionPath 返回订阅级资源路径前缀: /subscriptions/{id}
+func (c *Client) SubscriptionPath(ctx context.Context) (string, error) {
+	sub, err := c.SubscriptionID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "/subscriptions/" + sub, nil
+}
+
+// Location Azure 地域
+type Location struct {
+	Name                string `json:"name"`
+	DisplayName         string `json:"displayName"`
+	RegionalDisplayName string `json:"regionalDisplayName"`
+	Metadata            struct {
+		RegionType string `json:"regionType"`
+	} `json:"metadata"`
+}
+
+// ListLocations 获取订阅可用的物理地域
+func (c *Client) ListLocations(ctx context.Context) ([]Location, error) {
+	subPath, err := c.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.ListARM(ctx, subPath+"/locations", subscriptionAPIVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]Location, 0, len(items))
+	for _, raw := range items {
+		var loc Location
+		if err := json.Unmarshal(raw, &loc); err != nil {
+			continue
+		}
+		// 逻辑地域(如 "global"、"asia") 不承载资源
+		if loc.Metadata.RegionType != "" && loc.Metadata.RegionType != "Physical" {
+			continue
+		}
+		locations = append(locations, loc)
+	}
+	return locations, nil
+}
+
+// DoARM 调用 ARM API，path 可以是相对路径或完整 URL(如 nextLink)
+func (c *Client) DoARM(ctx context.Context, method, path, apiVersion string, query url.Values, body, out any) error {
+	reqURL, err := c.buildURL(c.endpoints.Management, path, apiVersion, query)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, ManagementScope, method, reqURL, body, out)
+}
+
+// ListARM 调用 ARM 列表 API 并自动跟随 nextLink 翻页，返回所有 value 元素
+func (c *Client) ListARM(ctx context.Context, path, apiVersion string, query url.Values) ([]json.RawMessage, error) {
+	reqURL, err := c.buildURL(c.endpoints.Management, path, apiVersion, query)
+	if err != nil {
+		return nil, err
+	}
+	return c.list(ctx, ManagementScope, reqURL, func(p listPage) string { return p.NextLink })
+}
+
+// DoGraph 调用 Microsoft Graph API
+func (c *Client) DoGraph(ctx context.Context, method, path string, body, out any) error {
+	reqURL, err := c.buildURL(c.endpoints.Graph, path, "", nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, GraphScope, method, reqURL, body, out)
+}
+
+// ListGraph 调用 Graph 列表 API 并自动跟随 @odata.nextLink 翻页
+func (c *Client) ListGraph(ctx context.Context, path string) ([]json.RawMessage, error) {
+	reqURL, err := c.buildURL(c.endpoints.Graph, path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.list(ctx, GraphScope, reqURL, func(p listPage) string { return p.ODataNextLink })
+}
+
+// listPage 列表 API 通用分页响应
+type listPage struct {
+	Value         []json.RawMessage `json:"value"`
+	NextLink      string            `json:"nextLink"`
+	ODataNextLink string            `json:"@odata.nextLink"`
+}
+
+func (c *Client) list(ctx context.Context, scope, reqURL string, next func(listPage) string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	for reqURL != "" {
+		var page listPage
+		if err := c.do(ctx, scope, http.MethodGet, reqURL, nil, &page); err != nil {
+			return nil, err
+		}
+		items = append(items, page.Value...)
+		reqURL = next(page)
+	}
+	return items, nil
+}
+
+// buildURL 拼接请求 URL，完整 URL 原样使用
+func (c *Client) buildURL(base, path, apiVersion string, query url.Values) (string, error) {
+	raw := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		raw = strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid azure url %q: %w", raw, err)
+	}
+	q := u.Query()
+	for k, vs := range query {
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	if apiVersion != "" && q.Get("api-version") == "" {
+		q.Set("api-version", apiVersion)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// do 发送请求，限流错误与服务端错误按指数退避重试
+func (c *Client) do(ctx context.Context, scope, method, reqURL string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal azure request body failed: %w", err)
+		}
+	}
+
+	return retry.WithBackoff(ctx, maxRetries, func() error {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit wait failed: %w", err)
+		}
+
+		token, err := c.token(ctx, scope)
+		if err != nil {
+			return err
+		}
+
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			return parseResponseError(resp.StatusCode, data)
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decode azure response failed: %w", err)
+		}
+		return nil
+	}, IsRetryableError)
+}
+
+// token 获取指定作用域的访问令牌，过期前 5 分钟刷新
+func (c *Client) token(ctx context.Context, scope string) (string, error) {
+	c.mu.Lock()
+	cached, ok := c.tokens[scope]
+	c.mu.Unlock()
+	if ok && time.Until(cached.expiresAt) > 5*time.Minute {
+		return cached.value, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", c.cred.ClientID)
+	form.Set("client_secret", c.cred.ClientSecret)
+	form.Set("scope", scope)
+
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimRight(c.endpoints.Login, "/"), c.cred.TenantID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", parseResponseError(resp.StatusCode, data)
+	}
+
+	var tr struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return "", fmt.Errorf("decode azure token response failed: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return "", &ResponseError{StatusCode: resp.StatusCode, Code: "EmptyToken", Message: "token endpoint returned empty access_token"}
+	}
+
+	c.mu.Lock()
+	c.tokens[scope] = cachedToken{
+		value:     tr.AccessToken,
+		expiresAt: time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second),
+	}
+	c.mu.Unlock()
+	return tr.AccessToken, nil
+}
@@ -0,0 +1,133 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTenantID       = "00000000-0000-0000-0000-000000000001"
+	testClientID       = "00000000-0000-0000-0000-000000000002"
+	testSubscriptionID = "00000000-0000-0000-0000-000000000003"
+)
+
+func TestParseCredential(t *testing.T) {
+	tests := []struct {
+		name    string
+		ak      string
+		sk      string
+		wantSub string
+		wantErr bool
+	}{
+		{"tenant/client", testTenantID + "/" + testClientID, "secret", "", false},
+		{"tenant/client/subscription", testTenantID + "/" + testClientID + "/" + testSubscriptionID, "secret", testSubscriptionID, false},
+		{"仅 clientID", testClientID, "secret", "", true},
+		{"非 GUID", testTenantID + "/not-a-guid", "secret", "", true},
+		{"段数过多", testTenantID + "/" + testClientID + "/" + testSubscriptionID + "/x", "secret", "", true},
+		{"空密钥", testTenantID + "/" + testClientID, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := ParseCredential(tt.ak, tt.sk)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testTenantID, cred.TenantID)
+			assert.Equal(t, testClientID, cred.ClientID)
+			assert.Equal(t, tt.wantSub, cred.SubscriptionID)
+		})
+	}
+}
+
+// newTestClient 创建指向本地服务的客户端，返回令牌请求计数
+func newTestClient(t *testing.T, subscriptionID string, handler http.HandlerFunc) (*Client, *int32) {
+	t.Helper()
+	var tokenCalls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+testTenantID+"/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenCalls, 1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, testClientID, r.PostForm.Get("client_id"))
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600}`, atomic.LoadInt32(&tokenCalls))
+	})
+	mux.HandleFunc("/", handler)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cred := &Credential{TenantID: testTenantID, ClientID: testClientID, ClientSecret: "secret", SubscriptionID: subscriptionID}
+	return NewClient(cred, Endpoints{Login: srv.URL, Management: srv.URL, Graph: srv.URL}), &tokenCalls
+}
+
+func TestClient_TokenCachedPerScope(t *testing.T) {
+	client, tokenCalls := newTestClient(t, testSubscriptionID, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	ctx := context.Background()
+	require.NoError(t, client.DoARM(ctx, http.MethodGet, "/subscriptions/x", "2022-12-01", nil, nil, nil))
+	require.NoError(t, client.DoARM(ctx, http.MethodGet, "/subscriptions/y", "2022-12-01", nil, nil, nil))
+	assert.Equal(t, int32(1), atomic.LoadInt32(tokenCalls))
+}
+
+func TestClient_ListARMFollowsNextLink(t *testing.T) {
+	var serverURL string
+	client, _ := newTestClient(t, testSubscriptionID, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2023-09-01", r.URL.Query().Get("api-version"))
+		if r.URL.Query().Get("$skiptoken") == "2" {
+			_, _ = w.Write([]byte(`{"value":[{"id":"c"}]}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"value":[{"id":"a"},{"id":"b"}],"nextLink":"%s%s?api-version=2023-09-01&$skiptoken=2"}`, serverURL, r.URL.Path)
+	})
+	serverURL = strings.TrimSuffix(client.endpoints.Management, "/")
+
+	items, err := client.ListARM(context.Background(), "/subscriptions/"+testSubscriptionID+"/providers/Microsoft.Compute/disks", "2023-09-01", nil)
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
+
+func TestClient_SubscriptionResolvedWhenMissing(t *testing.T) {
+	client, _ := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subscriptions", r.URL.Path)
+		_, _ = w.Write([]byte(`{"value":[{"subscriptionId":"` + testSubscriptionID + `","state":"Enabled"}]}`))
+	})
+
+	path, err := client.SubscriptionPath(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "/subscriptions/"+testSubscriptionID, path)
+}
+
+func TestClient_ErrorResponse(t *testing.T) {
+	client, _ := newTestClient(t, testSubscriptionID, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"ResourceNotFound","message":"gone"}}`))
+	})
+
+	err := client.DoARM(context.Background(), http.MethodGet, "/subscriptions/x/resourceGroups/rg", "2021-04-01", nil, nil, nil)
+	require.Error(t, err)
+	assert.True(t, IsNotFoundError(err))
+	assert.False(t, IsRetryableError(err))
+	assert.False(t, IsPermissionError(err))
+}
+
+func TestResourceIDHelpers(t *testing.T) {
+	id := "/subscriptions/" + testSubscriptionID + "/resourceGroups/rg-prod/providers/Microsoft.Network/virtualNetworks/vnet-prod/subnets/snet-app"
+	assert.Equal(t, "rg-prod", ResourceGroupFromID(id))
+	assert.Equal(t, "snet-app", NameFromID(id))
+	assert.True(t, SameID(ParentID(id), strings.ToLower(strings.TrimSuffix(id, "/subnets/snet-app"))))
+	assert.True(t, MatchLocation("East US", "eastus"))
+	assert.True(t, MatchLocation("eastus", ""))
+	assert.False(t, MatchLocation("westeurope", "eastus"))
+}
@@ -0,0 +1,120 @@
+package azure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ResponseError Azure API 错误响应
+type ResponseError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error 实现 error 接口
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("azure api error (status: %d, code: %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+// HTTPStatusCode 返回 HTTP 状态码
+func (e *ResponseError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// ErrorCode 返回错误码
+func (e *ResponseError) ErrorCode() string {
+	return e.Code
+}
+
+// parseResponseError 解析 ARM / Graph / OAuth2 三种错误响应格式
+func parseResponseError(status int, body []byte) error {
+	respErr := &ResponseError{StatusCode: status, Code: http.StatusText(status)}
+
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+		// OAuth2 token 端点错误格式
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		respErr.Message = strings.TrimSpace(string(body))
+		return respErr
+	}
+
+	// ARM / Graph: {"error": {"code": "...", "message": "..."}}
+	var detail struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if len(payload.Error) > 0 && json.Unmarshal(payload.Error, &detail) == nil && detail.Code != "" {
+		respErr.Code = detail.Code
+		respErr.Message = detail.Message
+		return respErr
+	}
+
+	// OAuth2: {"error": "invalid_client", "error_description": "..."}
+	var code string
+	if len(payload.Error) > 0 && json.Unmarshal(payload.Error, &code) == nil && code != "" {
+		respErr.Code = code
+		respErr.Message = payload.ErrorDescription
+		return respErr
+	}
+
+	respErr.Message = strings.TrimSpace(string(body))
+	return respErr
+}
+
+// IsThrottlingError 检查是否是 Azure 限流错误
+func IsThrottlingError(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusTooManyRequests ||
+			respErr.Code == "TooManyRequests" ||
+			respErr.Code == "SubscriptionRequestsThrottled"
+	}
+	return false
+}
+
+// IsNotFoundError 检查是否是资源不存在错误
+func IsNotFoundError(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusNotFound ||
+			respErr.Code == "ResourceNotFound" ||
+			respErr.Code == "ResourceGroupNotFound" ||
+			respErr.Code == "Request_ResourceNotFound"
+	}
+	return false
+}
+
+// IsPermissionError 检查是否是认证/权限错误
+func IsPermissionError(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusUnauthorized ||
+			respErr.StatusCode == http.StatusForbidden ||
+			respErr.Code == "invalid_client" ||
+			respErr.Code == "unauthorized_client" ||
+			respErr.Code == "AuthorizationFailed" ||
+			respErr.Code == "InvalidAuthenticationToken"
+	}
+	return false
+}
+
+// IsRetryableError 判断错误是否可重试：限流与 5xx 重试，其余不重试
+func IsRetryableError(err error) bool {
+	if IsThrottlingError(err) {
+		return true
+	}
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError
+	}
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "timeout") ||
+		strings.Contains(errMsg, "connection refused") ||
+		strings.Contains(errMsg, "connection reset")
+}
@@ -0,0 +1,118 @@
+package azure
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Resource ARM 资源通用结构，Properties 由各资源类型自行解析
+type Resource struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	Kind       string            `json:"kind"`
+	Location   string            `json:"location"`
+	Zones      []string          `json:"zones"`
+	Tags       map[string]string `json:"tags"`
+	SKU        *SKU              `json:"sku"`
+	ManagedBy  string            `json:"managedBy"`
+	Properties json.RawMessage   `json:"properties"`
+}
+
+// SKU ARM 资源规格
+type SKU struct {
+	Name     string `json:"name"`
+	Tier     string `json:"tier"`
+	Family   string `json:"family"`
+	Capacity int    `json:"capacity"`
+}
+
+// SubResource ARM 子资源引用
+type SubResource struct {
+	ID string `json:"id"`
+}
+
+// DecodeResources 解析列表 API 返回的资源，properties 解析到 props 生成的结构中
+func DecodeResources[P any](items []json.RawMessage) ([]Resource, []P) {
+	resources := make([]Resource, 0, len(items))
+	props := make([]P, 0, len(items))
+	for _, raw := range items {
+		var r Resource
+		if err := json.Unmarshal(raw, &r); err != nil {
+			continue
+		}
+		var p P
+		if len(r.Properties) > 0 {
+			_ = json.Unmarshal(r.Properties, &p)
+		}
+		resources = append(resources, r)
+		props = append(props, p)
+	}
+	return resources, props
+}
+
+// ResourceGroupFromID 从资源ID中解析资源组名称
+func ResourceGroupFromID(id string) string {
+	return segmentAfter(id, "resourcegroups")
+}
+
+// NameFromID 从资源ID中解析资源名称 (最后一段)
+func NameFromID(id string) string {
+	id = strings.TrimRight(id, "/")
+	if idx := strings.LastIndex(id, "/"); idx >= 0 {
+		return id[idx+1:]
+	}
+	return id
+}
+
+// ParentID 返回子资源的父资源ID，如 NIC ipConfiguration ID -> NIC ID
+func ParentID(id string) string {
+	id = strings.TrimRight(id, "/")
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndex(id, "/")
+		if idx < 0 {
+			return id
+		}
+		id = id[:idx]
+	}
+	return id
+}
+
+// ResourceTypeFromID 从资源ID中解析资源类型，如 Microsoft.Network/networkInterfaces
+func ResourceTypeFromID(id string) string {
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	for i, p := range parts {
+		if strings.EqualFold(p, "providers") && i+2 < len(parts) {
+			return parts[i+1] + "/" + parts[i+2]
+		}
+	}
+	return ""
+}
+
+// NormalizeLocation 统一地域名称格式: "East US" -> "eastus"
+func NormalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// MatchLocation 判断资源地域是否匹配，region 为空表示不过滤
+func MatchLocation(location, region string) bool {
+	if region == "" {
+		return true
+	}
+	return NormalizeLocation(location) == NormalizeLocation(region)
+}
+
+// SameID 比较资源ID (ARM 资源ID大小写不敏感)
+func SameID(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+func segmentAfter(id, key string) string {
+	parts := strings.Split(strings.Trim(id, "/"), "/")
+	for i, p := range parts {
+		if strings.EqualFold(p, key) && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,362 @@
+package gcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// CloudPlatformScope 访问令牌作用域
+	CloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// 各服务的 API 主机前缀: https://{service}.googleapis.com
+	ServiceCompute              = "compute"
+	ServiceSQLAdmin             = "sqladmin"
+	ServiceRedis                = "redis"
+	ServiceStorage              = "storage"
+	ServiceDNS                  = "dns"
+	ServiceBigQuery             = "bigquery"
+	ServiceIAM                  = "iam"
+	ServiceCloudResourceManager = "cloudresourcemanager"
+	ServiceCloudAsset           = "cloudasset"
+	ServiceMonitoring           = "monitoring"
+
+	// maxRetries 最大重试次数
+	maxRetries = 3
+)
+
+// Endpoints GCP 服务端点，测试时可替换为本地服务地址
+// API 为空时使用 https://{service}.googleapis.com，否则使用 {API}/{service}
+type Endpoints struct {
+	Token string
+	API   string
+}
+
+// DefaultEndpoints GCP 公有云端点 (令牌端点优先使用密钥中的 token_uri)
+var DefaultEndpoints = Endpoints{}
+
+// Client GCP REST 客户端
+// 负责服务账号 JWT 换取访问令牌与缓存、分页、限流和重试
+type Client struct {
+	cred        *Credential
+	endpoints   Endpoints
+	httpClient  *http.Client
+	rateLimiter *RateLimiter
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+// NewClient 创建 GCP REST 客户端
+func NewClient(cred *Credential, endpoints Endpoints) *Client {
+	if endpoints.Token == "" {
+		endpoints.Token = cred.Key.TokenURI
+	}
+	if endpoints.Token == "" {
+		endpoints.Token = DefaultTokenURI
+	}
+	return &Client{
+		cred:        cred,
+		endpoints:   endpoints,
+		httpClient:  &http.Client{Timeout: 60 * time.Second},
+		rateLimiter: NewRateLimiter(20),
+	}
+}
+
+// NewClientFromAccount 根据云账号创建 GCP REST 客户端
+func NewClientFromAccount(account *domain.CloudAccount) (*Client, error) {
+	if account == nil {
+		return nil, fmt.Errorf("cloud account cannot be nil")
+	}
+	cred, err := ParseCredential(account.AccessKeyID, account.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(cred, DefaultEndpoints), nil
+}
+
+// Credential 获取客户端凭证
+func (c *Client) Credential() *Credential {
+	return c.cred
+}
+
+// ProjectID 获取项目ID
+func (c *Client) ProjectID() string {
+	return c.cred.ProjectID
+}
+
+// ServiceURL 返回服务的 API 根地址
+func (c *Client) ServiceURL(service string) string {
+	if c.endpoints.API != "" {
+		return strings.TrimRight(c.endpoints.API, "/") + "/" + service
+	}
+	return "https://" + service + ".googleapis.com"
+}
+
+// Region Compute Engine 地域
+type Region struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Zones       []string `json:"zones"`
+}
+
+// ListRegions 获取项目可用的 Compute Engine 地域
+func (c *Client) ListRegions(ctx context.Context) ([]Region, error) {
+	items, err := c.List(ctx, ServiceCompute, "/compute/v1/projects/"+c.cred.ProjectID+"/regions", nil, "items")
+	if err != nil {
+		return nil, err
+	}
+	regions := make([]Region, 0, len(items))
+	for _, raw := range items {
+		var r Region
+		if err := json.Unmarshal(raw, &r); err != nil {
+			continue
+		}
+		if r.Status != "" && r.Status != "UP" {
+			continue
+		}
+		regions = append(regions, r)
+	}
+	return regions, nil
+}
+
+// Do 调用 GCP API，path 可以是相对路径或完整 URL(如 selfLink)
+func (c *Client) Do(ctx context.Context, method, service, path string, query url.Values, body, out any) error {
+	reqURL, err := c.buildURL(service, path, query)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, method, reqURL, body, out)
+}
+
+// List 调用列表 API 并自动跟随 nextPageToken 翻页，返回 field 字段下的所有元素
+func (c *Client) List(ctx context.Context, service, path string, query url.Values, field string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	err := c.paginate(ctx, service, path, query, func(page map[string]json.RawMessage) error {
+		raw, ok := page[field]
+		if !ok {
+			return nil
+		}
+		var values []json.RawMessage
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return fmt.Errorf("decode gcp list field %s failed: %w", field, err)
+		}
+		items = append(items, values...)
+		return nil
+	})
+	return items, err
+}
+
+// AggregatedList 调用 Compute Engine aggregatedList API，合并所有 zone/region 作用域下 field 字段的元素
+func (c *Client) AggregatedList(ctx context.Context, path string, query url.Values, field string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	err := c.paginate(ctx, ServiceCompute, path, query, func(page map[string]json.RawMessage) error {
+		raw, ok := page["items"]
+		if !ok {
+			return nil
+		}
+		var scopes map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &scopes); err != nil {
+			return fmt.Errorf("decode gcp aggregated list failed: %w", err)
+		}
+		for _, scoped := range scopes {
+			values, ok := scoped[field]
+			if !ok {
+				continue
+			}
+			var list []json.RawMessage
+			if err := json.Unmarshal(values, &list); err == nil {
+				items = append(items, list...)
+			}
+		}
+		return nil
+	})
+	return items, err
+}
+
+// paginate 按 pageToken 依次请求各页
+func (c *Client) paginate(ctx context.Context, service, path string, query url.Values, handle func(map[string]json.RawMessage) error) error {
+	q := url.Values{}
+	for k, vs := range query {
+		q[k] = append([]string(nil), vs...)
+	}
+	for {
+		var page map[string]json.RawMessage
+		if err := c.Do(ctx, http.MethodGet, service, path, q, nil, &page); err != nil {
+			return err
+		}
+		if err := handle(page); err != nil {
+			return err
+		}
+
+		var token string
+		if raw, ok := page["nextPageToken"]; ok {
+			_ = json.Unmarshal(raw, &token)
+		}
+		if token == "" {
+			return nil
+		}
+		q.Set("pageToken", token)
+	}
+}
+
+// buildURL 拼接请求 URL，完整 URL 原样使用
+func (c *Client) buildURL(service, path string, query url.Values) (string, error) {
+	raw := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		raw = c.ServiceURL(service) + "/" + strings.TrimLeft(path, "/")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid gcp url %q: %w", raw, err)
+	}
+	q := u.Query()
+	for k, vs := range query {
+		q.Del(k)
+		for _, v := range vs {
+			q.Add(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// do 发送请求，限流错误与服务端错误按指数退避重试
+func (c *Client) do(ctx context.Context, method, reqURL string, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal gcp request body failed: %w", err)
+		}
+	}
+
+	return retry.WithBackoff(ctx, maxRetries, func() error {
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return fmt.Errorf("rate limit wait failed: %w", err)
+		}
+
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return err
+		}
+
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, reader)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			return parseResponseError(resp.StatusCode, data)
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decode gcp response failed: %w", err)
+		}
+		return nil
+	}, IsRetryableError)
+}
+
+// accessToken 使用服务账号签名的 JWT 换取访问令牌，过期前 5 分钟刷新
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	cached, expiry := c.token, c.tokenExpiry
+	c.mu.Unlock()
+	if cached != "" && time.Until(expiry) > 5*time.Minute {
+		return cached, nil
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   c.cred.Key.ClientEmail,
+		"scope": CloudPlatformScope,
+		"aud":   c.endpoints.Token,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	assertion := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if c.cred.Key.PrivateKeyID != "" {
+		assertion.Header["kid"] = c.cred.Key.PrivateKeyID
+	}
+	signed, err := assertion.SignedString(c.cred.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("sign gcp jwt assertion failed: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", signed)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoints.Token, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", parseResponseError(resp.StatusCode, data)
+	}
+
+	var tr struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &tr); err != nil {
+		return "", fmt.Errorf("decode gcp token response failed: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return "", &ResponseError{StatusCode: resp.StatusCode, Status: "EmptyToken", Message: "token endpoint returned empty access_token"}
+	}
+
+	c.mu.Lock()
+	c.token = tr.AccessToken
+	c.tokenExpiry = now.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	c.mu.Unlock()
+	return tr.AccessToken, nil
+}
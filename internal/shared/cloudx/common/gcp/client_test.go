@@ -0,0 +1,204 @@
+package gcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProjectID   = "test-project"
+	testClientEmail = "cam-reader@test-project.iam.gserviceaccount.com"
+)
+
+// testServiceAccountKey 生成测试用服务账号 JSON 密钥，返回密钥 JSON 与公钥
+func testServiceAccountKey(t *testing.T) (string, *rsa.PublicKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     testProjectID,
+		"private_key_id": "key-1",
+		"private_key":    string(keyPEM),
+		"client_email":   testClientEmail,
+	})
+	require.NoError(t, err)
+	return string(data), &key.PublicKey
+}
+
+func TestParseCredential(t *testing.T) {
+	key, _ := testServiceAccountKey(t)
+
+	tests := []struct {
+		name        string
+		ak          string
+		sk          string
+		wantProject string
+		wantTable   string
+		wantErr     bool
+	}{
+		{"项目ID取自密钥", "", key, testProjectID, "", false},
+		{"指定项目ID", "other-project", key, "other-project", "", false},
+		{"项目与账单表", "other-project/billing-proj.billing.gcp_billing_export_v1_01", key, "other-project", "billing-proj.billing.gcp_billing_export_v1_01", false},
+		{"账单表格式错误", "other-project/billing", key, "", "", true},
+		{"项目ID非法", "Bad_Project", key, "", "", true},
+		{"非 JSON 密钥", testProjectID, "secret", "", "", true},
+		{"非服务账号密钥", testProjectID, `{"type":"authorized_user"}`, "", "", true},
+		{"空密钥", testProjectID, "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred, err := ParseCredential(tt.ak, tt.sk)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantProject, cred.ProjectID)
+			assert.Equal(t, tt.wantTable, cred.BillingTable)
+			assert.Equal(t, testClientEmail, cred.ClientEmail())
+		})
+	}
+}
+
+// newTestClient 创建指向本地服务的客户端，返回令牌请求计数
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *int32) {
+	t.Helper()
+	key, publicKey := testServiceAccountKey(t)
+
+	var tokenCalls int32
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenCalls, 1)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.PostForm.Get("grant_type"))
+
+		// 校验 JWT 断言签名与声明
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(r.PostForm.Get("assertion"), claims, func(*jwt.Token) (any, error) {
+			return publicKey, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "key-1", token.Header["kid"])
+		assert.Equal(t, testClientEmail, claims["iss"])
+		assert.Equal(t, CloudPlatformScope, claims["scope"])
+		assert.Equal(t, srv.URL+"/token", claims["aud"])
+
+		_, _ = fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":3600}`, atomic.LoadInt32(&tokenCalls))
+	})
+	mux.HandleFunc("/", handler)
+
+	cred, err := ParseCredential(testProjectID, key)
+	require.NoError(t, err)
+	return NewClient(cred, Endpoints{Token: srv.URL + "/token", API: srv.URL}), &tokenCalls
+}
+
+func TestClient_TokenCached(t *testing.T) {
+	client, tokenCalls := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	ctx := context.Background()
+	require.NoError(t, client.Do(ctx, http.MethodGet, ServiceCompute, "/compute/v1/projects/test-project", nil, nil, nil))
+	require.NoError(t, client.Do(ctx, http.MethodGet, ServiceStorage, "/storage/v1/b", nil, nil, nil))
+	assert.Equal(t, int32(1), atomic.LoadInt32(tokenCalls))
+}
+
+func TestClient_ListFollowsPageToken(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/storage/storage/v1/b", r.URL.Path)
+		assert.Equal(t, testProjectID, r.URL.Query().Get("project"))
+		if r.URL.Query().Get("pageToken") == "2" {
+			_, _ = w.Write([]byte(`{"items":[{"name":"c"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items":[{"name":"a"},{"name":"b"}],"nextPageToken":"2"}`))
+	})
+
+	items, err := client.List(context.Background(), ServiceStorage, "/storage/v1/b", map[string][]string{"project": {testProjectID}}, "items")
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
+
+func TestClient_AggregatedList(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/compute/compute/v1/projects/test-project/aggregated/disks", r.URL.Path)
+		_, _ = w.Write([]byte(`{"items":{
+			"zones/us-central1-a":{"disks":[{"name":"d1"},{"name":"d2"}]},
+			"zones/us-central1-b":{"warning":{"code":"NO_RESULTS_ON_PAGE"}},
+			"regions/us-central1":{"disks":[{"name":"d3"}]}
+		}}`))
+	})
+
+	items, err := client.AggregatedList(context.Background(), "/compute/v1/projects/test-project/aggregated/disks", nil, "disks")
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+}
+
+func TestClient_ErrorResponse(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"The resource 'vm-x' was not found","status":"NOT_FOUND","errors":[{"reason":"notFound"}]}}`))
+	})
+
+	err := client.Do(context.Background(), http.MethodGet, ServiceCompute, "/compute/v1/projects/test-project/zones/us-central1-a/instances/vm-x", nil, nil, nil)
+	require.Error(t, err)
+	assert.True(t, IsNotFoundError(err))
+	assert.False(t, IsRetryableError(err))
+	assert.False(t, IsPermissionError(err))
+
+	var respErr *ResponseError
+	require.ErrorAs(t, err, &respErr)
+	assert.Equal(t, "notFound", respErr.Reason)
+}
+
+func TestParseResponseError(t *testing.T) {
+	// OAuth2 令牌端点错误
+	err := parseResponseError(http.StatusBadRequest, []byte(`{"error":"invalid_grant","error_description":"Invalid JWT Signature."}`))
+	assert.True(t, IsPermissionError(err))
+	assert.Contains(t, err.Error(), "Invalid JWT Signature.")
+
+	// 旧版 API 以 403 + rateLimitExceeded 表示限流
+	err = parseResponseError(http.StatusForbidden, []byte(`{"error":{"code":403,"message":"Rate Limit Exceeded","errors":[{"reason":"rateLimitExceeded"}]}}`))
+	assert.True(t, IsThrottlingError(err))
+	assert.True(t, IsRetryableError(err))
+	assert.False(t, IsPermissionError(err))
+
+	// 非 JSON 响应体
+	err = parseResponseError(http.StatusBadGateway, []byte("bad gateway"))
+	assert.True(t, IsRetryableError(err))
+	assert.Contains(t, err.Error(), "bad gateway")
+}
+
+func TestResourceHelpers(t *testing.T) {
+	link := "https://www.googleapis.com/compute/v1/projects/test-project/zones/us-central1-a/instances/vm-web-01"
+	assert.Equal(t, "vm-web-01", NameFromURL(link))
+	assert.Equal(t, "us-central1-a", ZoneFromURL(link))
+	assert.Equal(t, "us-central1", RegionFromURL(link))
+	assert.Equal(t, GlobalRegion, RegionFromURL("https://www.googleapis.com/compute/v1/projects/test-project/global/networks/default"))
+	assert.True(t, SameResource(link, "projects/test-project/zones/us-central1-a/instances/vm-web-01"))
+	assert.True(t, SameResource(link, "vm-web-01"))
+	assert.False(t, SameResource(link, ""))
+	assert.True(t, MatchRegion("us-central1", ""))
+	assert.False(t, MatchRegion("europe-west1", "us-central1"))
+	assert.Equal(t, "2024-03-01T08:00:00Z", FormatTime("2024-03-01T00:00:00.000-08:00"))
+}
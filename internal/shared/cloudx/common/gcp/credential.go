@@ -0,0 +1,90 @@
+package gcp
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// DefaultTokenURI Google OAuth2 令牌端点
+const DefaultTokenURI = "https://oauth2.googleapis.com/token"
+
+var (
+	// projectIDPattern 项目ID: 6-30 位小写字母、数字或连字符，以字母开头
+	projectIDPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]$`)
+	// billingTablePattern BigQuery 表全名: project.dataset.table
+	billingTablePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{4,28}[a-z0-9]\.[A-Za-z0-9_]+\.[A-Za-z0-9_]+$`)
+)
+
+// ServiceAccountKey 服务账号 JSON 密钥
+type ServiceAccountKey struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	ClientID     string `json:"client_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// Credential GCP 服务账号凭证
+type Credential struct {
+	ProjectID    string
+	BillingTable string
+	Key          *ServiceAccountKey
+
+	privateKey *rsa.PrivateKey
+}
+
+// ParseCredential 从云账号 AK/SK 解析 GCP 凭证
+// AccessKeyID 格式: projectID[/billingTable]，为空时使用密钥中的 project_id；
+// billingTable 为 BigQuery 账单导出表全名 (project.dataset.table)。
+// AccessKeySecret 为服务账号 JSON 密钥，与其他云厂商的 SK 一样加密存储
+func ParseCredential(accessKeyID, accessKeySecret string) (*Credential, error) {
+	if strings.TrimSpace(accessKeySecret) == "" {
+		return nil, fmt.Errorf("gcp service account key is empty")
+	}
+
+	var key ServiceAccountKey
+	if err := json.Unmarshal([]byte(accessKeySecret), &key); err != nil {
+		return nil, fmt.Errorf("gcp service account key is not valid json: %w", err)
+	}
+	if key.Type != "service_account" {
+		return nil, fmt.Errorf("gcp credential type should be service_account, got %q", key.Type)
+	}
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return nil, fmt.Errorf("gcp service account key missing client_email or private_key")
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("gcp service account private key is invalid: %w", err)
+	}
+
+	projectID, billingTable, _ := strings.Cut(strings.TrimSpace(accessKeyID), "/")
+	if projectID == "" {
+		projectID = key.ProjectID
+	}
+	if !projectIDPattern.MatchString(projectID) {
+		return nil, fmt.Errorf("gcp project id %q is invalid", projectID)
+	}
+	if billingTable != "" && !billingTablePattern.MatchString(billingTable) {
+		return nil, fmt.Errorf("gcp billing table should be project.dataset.table, got %q", billingTable)
+	}
+
+	return &Credential{
+		ProjectID:    projectID,
+		BillingTable: billingTable,
+		Key:          &key,
+		privateKey:   privateKey,
+	}, nil
+}
+
+// ClientEmail 返回服务账号邮箱
+func (c *Credential) ClientEmail() string {
+	return c.Key.ClientEmail
+}
@@ -0,0 +1,133 @@
+package gcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ResponseError Google API 错误响应
+type ResponseError struct {
+	StatusCode int
+	// Status 为 google.rpc.Code 名称，如 PERMISSION_DENIED；OAuth2 错误时为 error 字段
+	Status  string
+	Reason  string
+	Message string
+}
+
+// Error 实现 error 接口
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("gcp api error (status: %d, code: %s, reason: %s): %s", e.StatusCode, e.Status, e.Reason, e.Message)
+}
+
+// HTTPStatusCode 返回 HTTP 状态码
+func (e *ResponseError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// ErrorCode 返回错误码
+func (e *ResponseError) ErrorCode() string {
+	return e.Status
+}
+
+// parseResponseError 解析 Google API 与 OAuth2 两种错误响应格式
+func parseResponseError(status int, body []byte) error {
+	respErr := &ResponseError{StatusCode: status, Status: http.StatusText(status)}
+
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+		// OAuth2 令牌端点错误格式
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		respErr.Message = strings.TrimSpace(string(body))
+		return respErr
+	}
+
+	// Google API: {"error": {"code": 403, "message": "...", "status": "PERMISSION_DENIED", "errors": [{"reason": "forbidden"}]}}
+	var detail struct {
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Errors  []struct {
+			Reason string `json:"reason"`
+		} `json:"errors"`
+	}
+	if len(payload.Error) > 0 && json.Unmarshal(payload.Error, &detail) == nil && detail.Message != "" {
+		if detail.Status != "" {
+			respErr.Status = detail.Status
+		}
+		if len(detail.Errors) > 0 {
+			respErr.Reason = detail.Errors[0].Reason
+		}
+		respErr.Message = detail.Message
+		return respErr
+	}
+
+	// OAuth2: {"error": "invalid_grant", "error_description": "..."}
+	var code string
+	if len(payload.Error) > 0 && json.Unmarshal(payload.Error, &code) == nil && code != "" {
+		respErr.Status = code
+		respErr.Message = payload.ErrorDescription
+		return respErr
+	}
+
+	respErr.Message = strings.TrimSpace(string(body))
+	return respErr
+}
+
+// IsThrottlingError 检查是否是 GCP 限流/配额错误
+// 部分老版本 API 以 403 + rateLimitExceeded 表示限流
+func IsThrottlingError(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusTooManyRequests ||
+			respErr.Status == "RESOURCE_EXHAUSTED" ||
+			respErr.Reason == "rateLimitExceeded" ||
+			respErr.Reason == "userRateLimitExceeded"
+	}
+	return false
+}
+
+// IsNotFoundError 检查是否是资源不存在错误
+func IsNotFoundError(err error) bool {
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusNotFound || respErr.Status == "NOT_FOUND"
+	}
+	return false
+}
+
+// IsPermissionError 检查是否是认证/权限错误
+func IsPermissionError(err error) bool {
+	if IsThrottlingError(err) {
+		return false
+	}
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusUnauthorized ||
+			respErr.StatusCode == http.StatusForbidden ||
+			respErr.Status == "PERMISSION_DENIED" ||
+			respErr.Status == "UNAUTHENTICATED" ||
+			respErr.Status == "invalid_grant" ||
+			respErr.Status == "invalid_client" ||
+			respErr.Status == "unauthorized_client"
+	}
+	return false
+}
+
+// IsRetryableError 判断错误是否可重试：限流与 5xx 重试，其余不重试
+func IsRetryableError(err error) bool {
+	if IsThrottlingError(err) {
+		return true
+	}
+	var respErr *ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError
+	}
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "timeout") ||
+		strings.Contains(errMsg, "connection refused") ||
+		strings.Contains(errMsg, "connection reset")
+}
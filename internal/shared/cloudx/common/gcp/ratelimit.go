@@ -0,0 +1,25 @@
+package gcp
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter GCP API 限流器
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewRateLimiter 创建 GCP 限流器
+// qps: 每秒请求数限制
+func NewRateLimiter(qps int) *RateLimiter {
+	return &RateLimiter{
+		limiter: rate.NewLimiter(rate.Limit(qps), qps),
+	}
+}
+
+// Wait 等待限流器允许请求
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	return r.limiter.Wait(ctx)
+}
@@ -0,0 +1,96 @@
+package gcp
+
+import (
+	"strings"
+	"time"
+)
+
+// GlobalRegion 全局资源 (VPC 网络、防火墙规则、多地域存储桶) 的地域标识
+const GlobalRegion = "global"
+
+// NameFromURL 从资源 URL (selfLink) 或相对路径中解析资源名称 (最后一段)
+func NameFromURL(link string) string {
+	link = strings.TrimRight(link, "/")
+	if idx := strings.LastIndex(link, "/"); idx >= 0 {
+		return link[idx+1:]
+	}
+	return link
+}
+
+// ZoneFromURL 从资源 URL 中解析可用区，如 .../zones/us-central1-a/instances/vm -> us-central1-a
+func ZoneFromURL(link string) string {
+	return segmentAfter(link, "zones")
+}
+
+// RegionFromURL 从资源 URL 中解析地域，zonal 资源通过可用区推导
+func RegionFromURL(link string) string {
+	if region := segmentAfter(link, "regions"); region != "" {
+		return region
+	}
+	if zone := ZoneFromURL(link); zone != "" {
+		return RegionFromZone(zone)
+	}
+	if strings.Contains(link, "/global/") {
+		return GlobalRegion
+	}
+	return ""
+}
+
+// RegionFromZone 从可用区推导地域: us-central1-a -> us-central1
+func RegionFromZone(zone string) string {
+	if idx := strings.LastIndex(zone, "-"); idx > 0 {
+		return zone[:idx]
+	}
+	return zone
+}
+
+// RelativeName 去掉 selfLink 中的协议、主机与 API 版本前缀，返回 projects/... 形式的相对名称
+func RelativeName(link string) string {
+	if idx := strings.Index(link, "projects/"); idx >= 0 {
+		return link[idx:]
+	}
+	return link
+}
+
+// SameResource 比较资源标识，完整 selfLink 与相对名称视为相同
+func SameResource(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	ra, rb := RelativeName(a), RelativeName(b)
+	if strings.Contains(ra, "/") && strings.Contains(rb, "/") {
+		return ra == rb
+	}
+	// 其中一方仅为资源名称
+	return NameFromURL(ra) == NameFromURL(rb)
+}
+
+// MatchRegion 判断资源地域是否匹配，region 为空表示不过滤
+func MatchRegion(resourceRegion, region string) bool {
+	return region == "" || strings.EqualFold(resourceRegion, region)
+}
+
+// FormatTime 统一时间格式为 RFC3339
+func FormatTime(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func segmentAfter(link, key string) string {
+	parts := strings.Split(strings.Trim(link, "/"), "/")
+	for i, p := range parts {
+		if p == key && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
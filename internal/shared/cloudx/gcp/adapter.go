@@ -0,0 +1,245 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common"
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+func init() {
+	// 注册GCP适配器创建函数
+	cloudx.RegisterAdapter(domain.CloudProviderGCP, func(account *domain.CloudAccount) (cloudx.CloudAdapter, error) {
+		return NewAdapter(account)
+	})
+}
+
+// Adapter GCP统一适配器
+// 所有子适配器共享同一个 REST 客户端 (令牌缓存、限流)
+type Adapter struct {
+	account       *domain.CloudAccount
+	logger        *elog.Component
+	client        *gcpcommon.Client
+	asset         *AssetAdapter
+	ecs           *ECSAdapter
+	securityGroup *SecurityGroupAdapter
+	disk          *DiskAdapter
+	rds           *RDSAdapter
+	redis         *RedisAdapter
+	vpc           *VPCAdapter
+	vswitch       *VSwitchAdapter
+	oss           *OSSAdapter
+	iam           *IAMAdapter
+	dns           *DNSAdapter
+	tag           *TagAdapterImpl
+}
+
+// NewAdapter 创建GCP适配器
+// 账号 AccessKeyID 格式为 projectID[/billingTable]，AccessKeySecret 为服务账号 JSON 密钥
+func NewAdapter(account *domain.CloudAccount) (*Adapter, error) {
+	if account == nil {
+		return nil, cloudx.ErrInvalidConfig
+	}
+
+	client, err := gcpcommon.NewClientFromAccount(account)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", cloudx.ErrInvalidConfig, err)
+	}
+	return NewAdapterWithClient(account, client), nil
+}
+
+// NewAdapterWithClient 使用指定的 REST 客户端创建GCP适配器
+func NewAdapterWithClient(account *domain.CloudAccount, client *gcpcommon.Client) *Adapter {
+	logger := elog.DefaultLogger
+	if logger == nil {
+		logger = elog.EgoLogger
+	}
+
+	// 获取默认地域
+	defaultRegion := "us-central1"
+	if len(account.Regions) > 0 {
+		defaultRegion = account.Regions[0]
+	}
+
+	adapter := &Adapter{
+		account: account,
+		logger:  logger,
+		client:  client,
+	}
+
+	// Compute Engine
+	adapter.ecs = NewECSAdapter(client, defaultRegion, logger)
+	adapter.asset = NewAssetAdapter(adapter.ecs)
+	// VPC 防火墙规则
+	adapter.securityGroup = NewSecurityGroupAdapter(client, defaultRegion, logger)
+	// Persistent Disk
+	adapter.disk = NewDiskAdapter(client, defaultRegion, logger)
+	// Cloud SQL
+	adapter.rds = NewRDSAdapter(client, defaultRegion, logger)
+	// Memorystore for Redis
+	adapter.redis = NewRedisAdapter(client, defaultRegion, logger)
+	// VPC Network
+	adapter.vpc = NewVPCAdapter(client, defaultRegion, logger)
+	// Subnetwork
+	adapter.vswitch = NewVSwitchAdapter(client, defaultRegion, logger)
+	// Cloud Storage
+	adapter.oss = NewOSSAdapter(client, logger)
+	adapter.iam = NewIAMAdapter(account, logger)
+	// Cloud DNS
+	adapter.dns = NewDNSAdapter(client, logger)
+	// Labels
+	adapter.tag = NewTagAdapter(client, logger)
+
+	return adapter
+}
+
+// GetProvider 获取云厂商类型
+func (a *Adapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderGCP
+}
+
+// Asset 获取资产适配器
+// Deprecated: 请使用 ECS() 获取云虚拟机适配器
+func (a *Adapter) Asset() cloudx.AssetAdapter {
+	return a.asset
+}
+
+// ECS 获取ECS适配器 (Compute Engine)
+func (a *Adapter) ECS() cloudx.ECSAdapter {
+	return a.ecs
+}
+
+// SecurityGroup 获取安全组适配器 (VPC 防火墙规则)
+func (a *Adapter) SecurityGroup() cloudx.SecurityGroupAdapter {
+	return a.securityGroup
+}
+
+// Image 获取镜像适配器 (暂不支持)
+func (a *Adapter) Image() cloudx.ImageAdapter {
+	return nil
+}
+
+// Disk 获取云盘适配器 (Persistent Disk)
+func (a *Adapter) Disk() cloudx.DiskAdapter {
+	return a.disk
+}
+
+// Snapshot 获取快照适配器 (暂不支持)
+func (a *Adapter) Snapshot() cloudx.SnapshotAdapter {
+	return nil
+}
+
+// RDS 获取RDS适配器 (Cloud SQL)
+func (a *Adapter) RDS() cloudx.RDSAdapter {
+	return a.rds
+}
+
+// Redis 获取Redis适配器 (Memorystore for Redis)
+func (a *Adapter) Redis() cloudx.RedisAdapter {
+	return a.redis
+}
+
+// MongoDB 获取MongoDB适配器 (暂不支持)
+func (a *Adapter) MongoDB() cloudx.MongoDBAdapter {
+	return nil
+}
+
+// VPC 获取VPC适配器 (VPC Network)
+func (a *Adapter) VPC() cloudx.VPCAdapter {
+	return a.vpc
+}
+
+// EIP 获取EIP适配器 (暂不支持)
+func (a *Adapter) EIP() cloudx.EIPAdapter {
+	return nil
+}
+
+// ENI 获取弹性网卡适配器 (暂不支持)
+func (a *Adapter) ENI() cloudx.ENIAdapter {
+	return nil
+}
+
+// LB 获取负载均衡适配器 (暂不支持)
+func (a *Adapter) LB() cloudx.LBAdapter {
+	return nil
+}
+
+// CDN 获取CDN适配器 (暂不支持)
+func (a *Adapter) CDN() cloudx.CDNAdapter {
+	return nil
+}
+
+// WAF 获取WAF适配器 (暂不支持)
+func (a *Adapter) WAF() cloudx.WAFAdapter {
+	return nil
+}
+
+// DNS 获取DNS适配器 (Cloud DNS)
+func (a *Adapter) DNS() cloudx.DNSAdapter {
+	return a.dns
+}
+
+// NAS 获取NAS适配器 (暂不支持)
+func (a *Adapter) NAS() cloudx.NASAdapter {
+	return common.NewNASStubAdapter(string(types.ProviderGCP))
+}
+
+// OSS 获取OSS适配器 (Cloud Storage)
+func (a *Adapter) OSS() cloudx.OSSAdapter {
+	return a.oss
+}
+
+// Kafka 获取Kafka适配器 (暂不支持)
+func (a *Adapter) Kafka() cloudx.KafkaAdapter {
+	return nil
+}
+
+// Elasticsearch 获取Elasticsearch适配器 (暂不支持)
+func (a *Adapter) Elasticsearch() cloudx.ElasticsearchAdapter {
+	return nil
+}
+
+// IAM 获取IAM适配器
+func (a *Adapter) IAM() cloudx.IAMAdapter {
+	return a.iam
+}
+
+// VSwitch 获取交换机/子网适配器 (Subnetwork)
+func (a *Adapter) VSwitch() cloudx.VSwitchAdapter {
+	return a.vswitch
+}
+
+// ECSCreate 获取 ECS 创建适配器 (暂不支持)
+func (a *Adapter) ECSCreate() cloudx.ECSCreateAdapter {
+	return nil
+}
+
+// ResourceQuery 获取资源查询适配器
+func (a *Adapter) ResourceQuery() cloudx.ResourceQueryAdapter {
+	return cloudx.NewGenericResourceQueryAdapter(a)
+}
+
+// Tag 获取标签适配器
+func (a *Adapter) Tag() cloudx.TagAdapter {
+	return a.tag
+}
+
+// ValidateCredentials 验证凭证
+func (a *Adapter) ValidateCredentials(ctx context.Context) error {
+	_, err := a.ecs.GetRegions(ctx)
+	if err != nil {
+		return fmt.Errorf("GCP凭证验证失败: %w", err)
+	}
+
+	a.logger.Info("GCP凭证验证成功",
+		elog.Int64("account_id", a.account.ID),
+		elog.String("account_name", a.account.Name),
+		elog.String("project_id", a.client.ProjectID()))
+
+	return nil
+}
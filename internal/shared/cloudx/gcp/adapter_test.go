@@ -0,0 +1,383 @@
+package gcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProjectID = "test-project"
+
+// fixtureRoutes 请求路径 ({service}/{api path}) 到录制响应文件的映射
+var fixtureRoutes = map[string]string{
+	"/compute/compute/v1/projects/test-project/aggregated/instances":                           "instances_aggregated.json",
+	"/compute/compute/v1/projects/test-project/zones/us-central1-a/machineTypes/e2-standard-2": "machine_type_e2_standard_2.json",
+	"/compute/compute/v1/projects/test-project/zones/us-central1-a/instances/vm-web-01":        "instance_vm_web_01.json",
+	"/compute/compute/v1/projects/test-project/global/firewalls":                               "firewalls.json",
+	"/compute/compute/v1/projects/test-project/aggregated/disks":                               "disks_aggregated.json",
+	"/compute/compute/v1/projects/test-project/global/networks":                                "networks.json",
+	"/compute/compute/v1/projects/test-project/aggregated/subnetworks":                         "subnetworks_aggregated.json",
+	"/sqladmin/v1/projects/test-project/instances":                                             "sql_instances.json",
+	"/redis/v1/projects/test-project/locations/-/instances":                                    "redis_instances.json",
+	"/storage/storage/v1/b":                                                                "buckets.json",
+	"/dns/dns/v1/projects/test-project/managedZones":                                       "dns_zones.json",
+	"/dns/dns/v1/projects/test-project/managedZones/example-com/rrsets":                    "dns_rrsets.json",
+	"/dns/dns/v1/projects/test-project/managedZones/example-com/rrsets/www.example.com./A": "dns_rrset_a_www.json",
+	"/cloudasset/v1/projects/test-project:searchAllResources":                              "asset_search.json",
+}
+
+// fixtureServer 回放 testdata 中录制的 API 响应，并记录写请求
+type fixtureServer struct {
+	t      *testing.T
+	mu     sync.Mutex
+	writes []recordedRequest
+}
+
+// recordedRequest 记录的写请求
+type recordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   map[string]any
+}
+
+func (s *fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600,"token_type":"Bearer"}`))
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		s.mu.Lock()
+		s.writes = append(s.writes, recordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: body})
+		s.mu.Unlock()
+		_, _ = w.Write([]byte(`{}`))
+		return
+	}
+
+	file, ok := fixtureRoutes[r.URL.Path]
+	if r.URL.Path == "/monitoring/v3/projects/test-project/timeSeries" {
+		ok = true
+		file = "timeseries_object_count.json"
+		if strings.Contains(r.URL.Query().Get("filter"), "total_bytes") {
+			file = "timeseries_total_bytes.json"
+		}
+	}
+	if ok {
+		data, err := os.ReadFile("testdata/" + file)
+		require.NoError(s.t, err)
+		_, _ = w.Write(data)
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"error":{"code":404,"message":"not recorded","status":"NOT_FOUND"}}`))
+}
+
+// testServiceAccountKey 生成测试用服务账号 JSON 密钥
+func testServiceAccountKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	data, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     testProjectID,
+		"private_key_id": "key-1",
+		"private_key":    string(keyPEM),
+		"client_email":   "cam-reader@test-project.iam.gserviceaccount.com",
+	})
+	require.NoError(t, err)
+	return string(data)
+}
+
+// newTestAdapter 创建指向本地 fixture 服务的GCP适配器
+func newTestAdapter(t *testing.T) (*Adapter, *fixtureServer) {
+	t.Helper()
+	fs := &fixtureServer{t: t}
+	srv := httptest.NewServer(fs)
+	t.Cleanup(srv.Close)
+
+	cred, err := gcpcommon.ParseCredential(testProjectID, testServiceAccountKey(t))
+	require.NoError(t, err)
+	client := gcpcommon.NewClient(cred, gcpcommon.Endpoints{Token: srv.URL + "/token", API: srv.URL})
+
+	account := &domain.CloudAccount{ID: 1, Provider: domain.CloudProviderGCP, Regions: []string{"us-central1"}}
+	return NewAdapterWithClient(account, client), fs
+}
+
+func TestECSAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	instances, err := adapter.ECS().ListInstances(context.Background(), "us-central1")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+
+	vm := instances[0]
+	assert.Equal(t, "projects/test-project/zones/us-central1-a/instances/vm-web-01", vm.InstanceID)
+	assert.Equal(t, "vm-web-01", vm.InstanceName)
+	assert.Equal(t, "running", vm.Status)
+	assert.Equal(t, "us-central1", vm.Region)
+	assert.Equal(t, "us-central1-a", vm.Zone)
+	assert.Equal(t, "e2-standard-2", vm.InstanceType)
+	assert.Equal(t, "e2", vm.InstanceTypeFamily)
+	assert.Equal(t, 2, vm.CPU)
+	assert.Equal(t, 8192, vm.Memory)
+	assert.Equal(t, "linux", vm.OSType)
+	assert.Equal(t, "debian-12-bookworm", vm.OSName)
+	assert.Equal(t, "PostPaid", vm.ChargeType)
+	assert.Equal(t, "10.128.0.2", vm.PrivateIP)
+	assert.Equal(t, "34.72.10.20", vm.PublicIP)
+	assert.Equal(t, "default", vm.VPCName)
+	assert.Equal(t, 20, vm.SystemDisk.Size)
+	require.Len(t, vm.DataDisks, 1)
+	assert.Equal(t, 100, vm.DataDisks[0].Size)
+	assert.Equal(t, "prod", vm.Tags["env"])
+	// 仅 http-server 网络标记匹配的防火墙规则生效
+	require.Len(t, vm.SecurityGroups, 1)
+	assert.Equal(t, "default-allow-http", vm.SecurityGroups[0].Name)
+
+	// TERMINATED 视为已停止，Spot 实例
+	instances, err = adapter.ECS().ListInstances(context.Background(), "europe-west1")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	assert.Equal(t, "stopped", instances[0].Status)
+	assert.Equal(t, "Spot", instances[0].ChargeType)
+	assert.Equal(t, 0, instances[0].CPU)
+}
+
+func TestDiskAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	disks, err := adapter.Disk().ListInstances(context.Background(), "us-central1")
+	require.NoError(t, err)
+	require.Len(t, disks, 3)
+
+	byName := map[string]types.DiskInstance{}
+	for _, d := range disks {
+		byName[d.DiskName] = d
+	}
+	assert.Equal(t, "system", byName["vm-web-01"].DiskType)
+	assert.Equal(t, "in_use", byName["vm-web-01"].Status)
+	assert.Equal(t, "projects/test-project/zones/us-central1-a/instances/vm-web-01", byName["vm-web-01"].InstanceID)
+	assert.Equal(t, "data", byName["data-02"].DiskType)
+	assert.Equal(t, "available", byName["data-02"].Status)
+	assert.Equal(t, "pd-ssd", byName["data-02"].Category)
+	assert.True(t, byName["data-02"].Encrypted)
+	// 地域磁盘没有可用区
+	assert.Equal(t, "us-central1", byName["regional-01"].Region)
+	assert.Empty(t, byName["regional-01"].Zone)
+}
+
+func TestVPCAndVSwitchAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	// VPC 网络为全局资源
+	vpcs, err := adapter.VPC().ListInstances(context.Background(), "us-central1")
+	require.NoError(t, err)
+	assert.Empty(t, vpcs)
+
+	vpcs, err = adapter.VPC().ListInstances(context.Background(), "global")
+	require.NoError(t, err)
+	require.Len(t, vpcs, 1)
+	assert.True(t, vpcs[0].IsDefault)
+	assert.Equal(t, 2, vpcs[0].VSwitchCount)
+
+	subnets, err := adapter.VSwitch().ListInstances(context.Background(), "us-central1")
+	require.NoError(t, err)
+	require.Len(t, subnets, 1)
+	assert.Equal(t, vpcs[0].VPCID, subnets[0].VPCID)
+	assert.Equal(t, int64(4096), subnets[0].TotalIPCount)
+	// 每个子网保留 4 个地址
+	assert.Equal(t, int64(4092), subnets[0].AvailableIPCount)
+}
+
+func TestSecurityGroupAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	groups, err := adapter.SecurityGroup().ListInstances(context.Background(), "global")
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	web := groups[0]
+	assert.Equal(t, "default-allow-http", web.SecurityGroupName)
+	assert.Equal(t, "firewall", web.SecurityGroupType)
+	assert.Equal(t, "global", web.Region)
+	assert.Equal(t, 1, web.InstanceCount)
+	require.Len(t, web.IngressRules, 1)
+	assert.Equal(t, "tcp", web.IngressRules[0].Protocol)
+	assert.Equal(t, "80/80,8080/8090", web.IngressRules[0].PortRange)
+	assert.Equal(t, "0.0.0.0/0", web.IngressRules[0].SourceCIDR)
+	assert.Equal(t, "accept", web.IngressRules[0].Policy)
+
+	deny := groups[1]
+	require.Len(t, deny.IngressRules, 1)
+	assert.Equal(t, "drop", deny.IngressRules[0].Policy)
+	assert.Equal(t, 0, deny.InstanceCount)
+}
+
+func TestRDSAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	dbs, err := adapter.RDS().ListInstances(context.Background(), "us-central1")
+	require.NoError(t, err)
+	require.Len(t, dbs, 2)
+
+	db := dbs[0]
+	assert.Equal(t, "projects/test-project/instances/orders-db", db.InstanceID)
+	assert.Equal(t, "PostgreSQL", db.Engine)
+	assert.Equal(t, "15", db.EngineVersion)
+	assert.Equal(t, 5432, db.Port)
+	assert.Equal(t, 4, db.CPU)
+	assert.Equal(t, 16384, db.Memory)
+	assert.Equal(t, 100, db.Storage)
+	assert.Equal(t, "HighAvailability", db.Category)
+	assert.Equal(t, "35.202.1.2", db.PublicIP)
+	assert.Equal(t, "10.10.0.3", db.PrivateIP)
+	assert.True(t, db.SSLEnabled)
+	assert.Equal(t, "running", db.Status)
+
+	// activationPolicy=NEVER 视为已停止
+	assert.Equal(t, "MySQL", dbs[1].Engine)
+	assert.Equal(t, "stopped", dbs[1].Status)
+}
+
+func TestRedisAdapter_ListInstances(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	instances, err := adapter.Redis().ListInstances(context.Background(), "us-central1")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+
+	r := instances[0]
+	assert.Equal(t, "session cache", r.InstanceName)
+	assert.Equal(t, "running", r.Status)
+	assert.Equal(t, "7.0", r.EngineVersion)
+	assert.Equal(t, 5120, r.Capacity)
+	assert.Equal(t, "double", r.NodeType)
+	assert.True(t, r.SSLEnabled)
+
+	found, err := adapter.Redis().GetInstance(context.Background(), "us-central1", "projects/test-project/locations/us-central1/instances/cache-01")
+	require.NoError(t, err)
+	assert.Equal(t, r.InstanceID, found.InstanceID)
+}
+
+func TestOSSAdapter_ListBuckets(t *testing.T) {
+	adapter, _ := newTestAdapter(t)
+
+	buckets, err := adapter.OSS().ListBuckets(context.Background(), "us-central1")
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+
+	b := buckets[0]
+	assert.Equal(t, "test-project-logs", b.BucketName)
+	assert.Equal(t, "Enabled", b.Versioning)
+	assert.True(t, b.BlockPublicAccess)
+	assert.Equal(t, 1, b.LifecycleRuleCount)
+	assert.Equal(t, int64(1073741824), b.StorageSize)
+	assert.Equal(t, int64(42), b.ObjectCount)
+
+	// 多地域存储桶归入 global
+	buckets, err = adapter.OSS().ListBuckets(context.Background(), "global")
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.True(t, buckets[0].CrossRegionReplication)
+	assert.True(t, buckets[0].WebsiteEnabled)
+}
+
+func TestDNSAdapter(t *testing.T) {
+	ctx := context.Background()
+	adapter, fs := newTestAdapter(t)
+
+	domains, err := adapter.DNS().ListDomains(ctx)
+	require.NoError(t, err)
+	// 私有区域不返回
+	require.Len(t, domains, 1)
+	assert.Equal(t, "example.com", domains[0].DomainName)
+	assert.Equal(t, int64(4), domains[0].RecordCount)
+
+	records, err := adapter.DNS().ListRecords(ctx, "example.com")
+	require.NoError(t, err)
+	// SOA 不返回，MX/A 记录集按值展开
+	require.Len(t, records, 5)
+	assert.Equal(t, "MX/@/0", records[0].RecordID)
+	assert.Equal(t, 10, records[0].Priority)
+	assert.Equal(t, "mail.example.com.", records[0].Value)
+	assert.Equal(t, "A/www/1", records[3].RecordID)
+	assert.Equal(t, "203.0.113.11", records[3].Value)
+	assert.Equal(t, "v=spf1 include:_spf.google.com ~all", records[4].Value)
+
+	record, err := adapter.DNS().GetRecord(ctx, "example.com", "A/www/1")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.11", record.Value)
+
+	// 新增值追加到已有记录集
+	_, err = adapter.DNS().CreateRecord(ctx, "example.com", types.CreateDNSRecordRequest{
+		RR: "www", Type: "A", Value: "203.0.113.12", TTL: 300,
+	})
+	require.NoError(t, err)
+	require.Len(t, fs.writes, 1)
+	assert.Equal(t, http.MethodPatch, fs.writes[0].Method)
+	assert.True(t, strings.HasSuffix(fs.writes[0].Path, "/rrsets/www.example.com./A"))
+	assert.Len(t, fs.writes[0].Body["rrdatas"], 3)
+
+	// 记录集不存在时创建
+	_, err = adapter.DNS().CreateRecord(ctx, "example.com", types.CreateDNSRecordRequest{
+		RR: "api", Type: "CNAME", Value: "ghs.googlehosted.com.",
+	})
+	require.NoError(t, err)
+	require.Len(t, fs.writes, 2)
+	assert.Equal(t, http.MethodPost, fs.writes[1].Method)
+	assert.Equal(t, "api.example.com.", fs.writes[1].Body["name"])
+	assert.Equal(t, float64(300), fs.writes[1].Body["ttl"])
+}
+
+func TestTagAdapter(t *testing.T) {
+	ctx := context.Background()
+	adapter, fs := newTestAdapter(t)
+
+	keys, err := adapter.Tag().ListTagKeys(ctx, "us-central1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env", "team"}, keys)
+
+	values, err := adapter.Tag().ListTagValues(ctx, "", "env")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod", "staging"}, values)
+
+	resources, err := adapter.Tag().ListResourcesByTag(ctx, "us-central1", "env", "prod")
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, "projects/test-project/zones/us-central1-a/instances/vm-web-01", resources[0].ResourceID)
+	assert.Equal(t, "test-project-logs", resources[1].ResourceID)
+
+	// Compute Engine 实例通过 setLabels 写入并携带 labelFingerprint
+	err = adapter.Tag().TagResource(ctx, "us-central1", "ecs", resources[0].ResourceID, map[string]string{"owner": "alice"})
+	require.NoError(t, err)
+	require.Len(t, fs.writes, 1)
+	assert.Equal(t, http.MethodPost, fs.writes[0].Method)
+	assert.True(t, strings.HasSuffix(fs.writes[0].Path, "/instances/vm-web-01/setLabels"))
+	assert.Equal(t, "42WmSpB8rSM=", fs.writes[0].Body["labelFingerprint"])
+	labels := fs.writes[0].Body["labels"].(map[string]any)
+	assert.Equal(t, "alice", labels["owner"])
+	assert.Equal(t, "prod", labels["env"])
+}
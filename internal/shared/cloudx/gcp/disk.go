@@ -0,0 +1,242 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// DiskAdapter GCP Persistent Disk 适配器
+type DiskAdapter struct {
+	client        *gcpcommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewDiskAdapter 创建 GCP Persistent Disk 适配器
+func NewDiskAdapter(client *gcpcommon.Client, defaultRegion string, logger *elog.Component) *DiskAdapter {
+	return &DiskAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// gceDisk 持久磁盘
+type gceDisk struct {
+	ID                    string            `json:"id"`
+	Name                  string            `json:"name"`
+	Description           string            `json:"description"`
+	SizeGb                string            `json:"sizeGb"`
+	Type                  string            `json:"type"`
+	Status                string            `json:"status"`
+	Zone                  string            `json:"zone"`
+	Region                string            `json:"region"`
+	Users                 []string          `json:"users"`
+	SourceImage           string            `json:"sourceImage"`
+	SourceSnapshot        string            `json:"sourceSnapshot"`
+	ProvisionedIops       string            `json:"provisionedIops"`
+	ProvisionedThroughput string            `json:"provisionedThroughput"`
+	CreationTimestamp     string            `json:"creationTimestamp"`
+	LastAttachTimestamp   string            `json:"lastAttachTimestamp"`
+	SelfLink              string            `json:"selfLink"`
+	Labels                map[string]string `json:"labels"`
+	ResourcePolicies      []string          `json:"resourcePolicies"`
+	DiskEncryptionKey     *struct {
+		KmsKeyName string `json:"kmsKeyName"`
+	} `json:"diskEncryptionKey"`
+}
+
+// ListInstances 获取持久磁盘列表 (包含可用区磁盘与地域磁盘)
+func (a *DiskAdapter) ListInstances(ctx context.Context, region string) ([]types.DiskInstance, error) {
+	items, err := a.client.AggregatedList(ctx, computePath(a.client, "/aggregated/disks"), nil, "disks")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP持久磁盘列表失败: %w", err)
+	}
+
+	disks := make([]types.DiskInstance, 0, len(items))
+	for _, item := range items {
+		var d gceDisk
+		if err := json.Unmarshal(item, &d); err != nil {
+			continue
+		}
+		converted := convertDisk(d, a.client.ProjectID())
+		if !gcpcommon.MatchRegion(converted.Region, region) {
+			continue
+		}
+		disks = append(disks, converted)
+	}
+
+	a.logger.Info("获取GCP持久磁盘列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(disks)))
+
+	return disks, nil
+}
+
+// GetInstance 获取单个磁盘详情
+func (a *DiskAdapter) GetInstance(ctx context.Context, region, diskID string) (*types.DiskInstance, error) {
+	disks, err := a.ListInstancesByIDs(ctx, region, []string{diskID})
+	if err != nil {
+		return nil, err
+	}
+	if len(disks) == 0 {
+		return nil, fmt.Errorf("磁盘不存在: %s", diskID)
+	}
+	return &disks[0], nil
+}
+
+// ListInstancesByIDs 批量获取磁盘
+func (a *DiskAdapter) ListInstancesByIDs(ctx context.Context, region string, diskIDs []string) ([]types.DiskInstance, error) {
+	if len(diskIDs) == 0 {
+		return []types.DiskInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.DiskFilter{DiskIDs: diskIDs})
+}
+
+// GetInstanceStatus 获取磁盘状态
+func (a *DiskAdapter) GetInstanceStatus(ctx context.Context, region, diskID string) (string, error) {
+	disk, err := a.GetInstance(ctx, region, diskID)
+	if err != nil {
+		return "", err
+	}
+	return disk.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取磁盘列表
+func (a *DiskAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.DiskFilter) ([]types.DiskInstance, error) {
+	disks, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return disks, nil
+	}
+
+	result := make([]types.DiskInstance, 0, len(disks))
+	for _, d := range disks {
+		if len(filter.DiskIDs) > 0 && !containsID(filter.DiskIDs, d.DiskID, d.DiskName) {
+			continue
+		}
+		if !matchName(d.DiskName, filter.DiskName) {
+			continue
+		}
+		if filter.DiskType != "" && d.DiskType != filter.DiskType {
+			continue
+		}
+		if filter.Category != "" && !strings.EqualFold(d.Category, filter.Category) {
+			continue
+		}
+		if filter.Status != "" && !strings.EqualFold(d.Status, filter.Status) {
+			continue
+		}
+		if filter.InstanceID != "" && !diskAttachedTo(d, filter.InstanceID) {
+			continue
+		}
+		if filter.Encrypted != nil && d.Encrypted != *filter.Encrypted {
+			continue
+		}
+		if filter.ResourceGroupID != "" && d.ResourceGroupID != filter.ResourceGroupID {
+			continue
+		}
+		if !matchTags(d.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, d)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// ListByInstanceID 获取虚拟机挂载的磁盘
+func (a *DiskAdapter) ListByInstanceID(ctx context.Context, region, instanceID string) ([]types.DiskInstance, error) {
+	return a.ListInstancesWithFilter(ctx, region, &types.DiskFilter{InstanceID: instanceID})
+}
+
+// diskAttachedTo 判断磁盘是否挂载到指定实例 (支持多重挂载)
+func diskAttachedTo(d types.DiskInstance, instanceID string) bool {
+	for _, att := range d.Attachments {
+		if gcpcommon.SameResource(att.InstanceID, instanceID) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertDisk 转换 GCP 持久磁盘为通用格式
+// GCP 磁盘状态 (CREATING/READY/RESTORING/FAILED/DELETING) 中 READY 按是否被实例使用区分 in_use/available
+func convertDisk(d gceDisk, projectID string) types.DiskInstance {
+	status := types.NormalizeDiskStatus(d.Status)
+	switch d.Status {
+	case "READY":
+		status = types.DiskStatusAvailable
+		if len(d.Users) > 0 {
+			status = types.DiskStatusInUse
+		}
+	case "RESTORING":
+		status = types.DiskStatusCreating
+	case "FAILED":
+		status = types.DiskStatusError
+	}
+
+	// 从镜像创建的磁盘视为系统盘
+	diskType := "data"
+	if d.SourceImage != "" {
+		diskType = "system"
+	}
+
+	zone := gcpcommon.NameFromURL(d.Zone)
+	region := gcpcommon.RegionFromZone(zone)
+	if zone == "" {
+		// 地域磁盘 (regional persistent disk)
+		region = gcpcommon.NameFromURL(d.Region)
+	}
+
+	disk := types.DiskInstance{
+		DiskID:           gcpcommon.RelativeName(d.SelfLink),
+		DiskName:         d.Name,
+		Description:      d.Description,
+		DiskType:         diskType,
+		Category:         gcpcommon.NameFromURL(d.Type),
+		Size:             atoi(d.SizeGb),
+		IOPS:             atoi(d.ProvisionedIops),
+		Throughput:       atoi(d.ProvisionedThroughput),
+		Status:           status,
+		Portable:         true,
+		AttachedTime:     gcpcommon.FormatTime(d.LastAttachTimestamp),
+		SourceSnapshotID: gcpcommon.RelativeName(d.SourceSnapshot),
+		Zone:             zone,
+		Region:           region,
+		ImageID:          gcpcommon.RelativeName(d.SourceImage),
+		ChargeType:       "PostPaid",
+		ResourceGroupID:  projectID,
+		CreationTime:     gcpcommon.FormatTime(d.CreationTimestamp),
+		Tags:             copyTags(d.Labels),
+		Provider:         string(types.ProviderGCP),
+		MultiAttach:      len(d.Users) > 1,
+	}
+	if d.DiskEncryptionKey != nil {
+		disk.Encrypted = true
+		disk.KMSKeyID = d.DiskEncryptionKey.KmsKeyName
+	}
+	if len(d.ResourcePolicies) > 0 {
+		disk.EnableAutoSnapshot = true
+		disk.AutoSnapshotPolicyID = gcpcommon.RelativeName(d.ResourcePolicies[0])
+	}
+
+	for _, user := range d.Users {
+		disk.Attachments = append(disk.Attachments, types.DiskAttachment{
+			InstanceID:   gcpcommon.RelativeName(user),
+			InstanceName: gcpcommon.NameFromURL(user),
+		})
+	}
+	if len(disk.Attachments) > 0 {
+		disk.InstanceID = disk.Attachments[0].InstanceID
+		disk.InstanceName = disk.Attachments[0].InstanceName
+	}
+	return disk
+}
@@ -0,0 +1,426 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// DNSAdapter GCP Cloud DNS 适配器
+// Cloud DNS 以记录集 (名称+类型) 为单位管理解析，记录集内每个 rrdata 对应一条 DNSRecord，
+// RecordID 格式为 "{类型}/{主机记录}/{序号}"
+type DNSAdapter struct {
+	client *gcpcommon.Client
+	logger *elog.Component
+}
+
+// NewDNSAdapter 创建 GCP Cloud DNS 适配器
+func NewDNSAdapter(client *gcpcommon.Client, logger *elog.Component) *DNSAdapter {
+	return &DNSAdapter{
+		client: client,
+		logger: logger,
+	}
+}
+
+// managedZone 托管区域
+type managedZone struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	DNSName    string `json:"dnsName"`
+	Visibility string `json:"visibility"`
+}
+
+// domain 区域域名 (去掉末尾的点)
+func (z managedZone) domain() string {
+	return strings.TrimSuffix(z.DNSName, ".")
+}
+
+// resourceRecordSet 记录集
+type resourceRecordSet struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	TTL     int      `json:"ttl"`
+	Rrdatas []string `json:"rrdatas"`
+}
+
+// ListDomains 查询托管域名列表 (仅公网区域)
+func (a *DNSAdapter) ListDomains(ctx context.Context) ([]types.DNSDomain, error) {
+	zones, err := a.listZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]types.DNSDomain, 0, len(zones))
+	for _, z := range zones {
+		records, err := a.listRecordSets(ctx, z)
+		if err != nil {
+			a.logger.Warn("获取GCP DNS记录数失败", elog.String("zone", z.Name), elog.FieldErr(err))
+		}
+		domains = append(domains, types.DNSDomain{
+			DomainID:    z.ID,
+			DomainName:  z.domain(),
+			RecordCount: int64(len(records)),
+			Status:      "normal",
+		})
+	}
+
+	a.logger.Info("获取GCP DNS域名列表成功", elog.Int("count", len(domains)))
+	return domains, nil
+}
+
+// ListRecords 查询域名下解析记录列表，domain 可以是域名或托管区域名称
+func (a *DNSAdapter) ListRecords(ctx context.Context, domain string) ([]types.DNSRecord, error) {
+	zone, err := a.resolveZone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	sets, err := a.listRecordSets(ctx, *zone)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []types.DNSRecord
+	for _, rs := range sets {
+		// SOA 记录由 Cloud DNS 托管，不对外暴露
+		if rs.Type == "SOA" {
+			continue
+		}
+		rr := relativeRR(rs.Name, zone.DNSName)
+		for i, data := range rs.Rrdatas {
+			records = append(records, toDNSRecord(zone.domain(), rr, rs.Type, i, rs.TTL, data))
+		}
+	}
+	return records, nil
+}
+
+// GetRecord 查询单条解析记录详情
+func (a *DNSAdapter) GetRecord(ctx context.Context, domain, recordID string) (*types.DNSRecord, error) {
+	recordType, rr, index, err := parseRecordID(recordID)
+	if err != nil {
+		return nil, err
+	}
+	zone, err := a.resolveZone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	rs, err := a.getRecordSet(ctx, *zone, recordType, rr)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil || index >= len(rs.Rrdatas) {
+		return nil, fmt.Errorf("gcp: DNS record %s not found in zone %s", recordID, domain)
+	}
+	record := toDNSRecord(zone.domain(), rr, recordType, index, rs.TTL, rs.Rrdatas[index])
+	return &record, nil
+}
+
+// CreateRecord 创建解析记录，同名同类型记录集已存在时追加值
+func (a *DNSAdapter) CreateRecord(ctx context.Context, domain string, req types.CreateDNSRecordRequest) (*types.DNSRecord, error) {
+	zone, err := a.resolveZone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	recordType := strings.ToUpper(req.Type)
+	rr := normalizeRR(req.RR)
+	existing, err := a.getRecordSet(ctx, *zone, recordType, rr)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := req.TTL
+	if ttl == 0 && existing != nil {
+		ttl = existing.TTL
+	}
+	if ttl == 0 {
+		ttl = 300
+	}
+
+	var datas []string
+	if existing != nil && recordType != "CNAME" {
+		datas = append(datas, existing.Rrdatas...)
+	}
+	datas = append(datas, formatRRData(recordType, req.Value, req.Priority))
+
+	if err := a.writeRecordSet(ctx, *zone, existing != nil, recordType, rr, ttl, datas); err != nil {
+		return nil, fmt.Errorf("gcp: create DNS record failed: %w", err)
+	}
+
+	record := toDNSRecord(zone.domain(), rr, recordType, len(datas)-1, ttl, datas[len(datas)-1])
+	return &record, nil
+}
+
+// UpdateRecord 修改解析记录
+// 主机记录或类型变化时，从原记录集移除该值并写入新记录集
+func (a *DNSAdapter) UpdateRecord(ctx context.Context, domain, recordID string, req types.UpdateDNSRecordRequest) (*types.DNSRecord, error) {
+	recordType, rr, index, err := parseRecordID(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	newType := recordType
+	if req.Type != "" {
+		newType = strings.ToUpper(req.Type)
+	}
+	newRR := rr
+	if req.RR != "" {
+		newRR = normalizeRR(req.RR)
+	}
+	if newType != recordType || newRR != rr {
+		if err := a.DeleteRecord(ctx, domain, recordID); err != nil {
+			return nil, err
+		}
+		return a.CreateRecord(ctx, domain, types.CreateDNSRecordRequest{
+			RR:       newRR,
+			Type:     newType,
+			Value:    req.Value,
+			TTL:      req.TTL,
+			Priority: req.Priority,
+			Line:     req.Line,
+		})
+	}
+
+	zone, err := a.resolveZone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	rs, err := a.getRecordSet(ctx, *zone, recordType, rr)
+	if err != nil {
+		return nil, err
+	}
+	if rs == nil || index >= len(rs.Rrdatas) {
+		return nil, fmt.Errorf("gcp: DNS record %s not found in zone %s", recordID, domain)
+	}
+
+	current := parseRRData(recordType, rs.Rrdatas[index])
+	value, priority := current.Value, current.Priority
+	if req.Value != "" {
+		value = req.Value
+	}
+	if req.Priority != 0 {
+		priority = req.Priority
+	}
+	rs.Rrdatas[index] = formatRRData(recordType, value, priority)
+
+	ttl := rs.TTL
+	if req.TTL != 0 {
+		ttl = req.TTL
+	}
+	if err := a.writeRecordSet(ctx, *zone, true, recordType, rr, ttl, rs.Rrdatas); err != nil {
+		return nil, fmt.Errorf("gcp: update DNS record %s failed: %w", recordID, err)
+	}
+
+	record := toDNSRecord(zone.domain(), rr, recordType, index, ttl, rs.Rrdatas[index])
+	return &record, nil
+}
+
+// DeleteRecord 删除解析记录，记录集中最后一个值被删除时删除整个记录集
+func (a *DNSAdapter) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	recordType, rr, index, err := parseRecordID(recordID)
+	if err != nil {
+		return err
+	}
+	zone, err := a.resolveZone(ctx, domain)
+	if err != nil {
+		return err
+	}
+	rs, err := a.getRecordSet(ctx, *zone, recordType, rr)
+	if err != nil {
+		return err
+	}
+	if rs == nil || index >= len(rs.Rrdatas) {
+		return fmt.Errorf("gcp: DNS record %s not found in zone %s", recordID, domain)
+	}
+
+	datas := append(rs.Rrdatas[:index], rs.Rrdatas[index+1:]...)
+	if len(datas) == 0 {
+		return a.client.Do(ctx, http.MethodDelete, gcpcommon.ServiceDNS, a.recordSetPath(*zone, recordType, rr), nil, nil, nil)
+	}
+	return a.writeRecordSet(ctx, *zone, true, recordType, rr, rs.TTL, datas)
+}
+
+// zonesPath 托管区域 API 路径
+func (a *DNSAdapter) zonesPath() string {
+	return "/dns/v1/projects/" + a.client.ProjectID() + "/managedZones"
+}
+
+// recordSetPath 单个记录集 API 路径
+func (a *DNSAdapter) recordSetPath(zone managedZone, recordType, rr string) string {
+	return fmt.Sprintf("%s/%s/rrsets/%s/%s", a.zonesPath(), zone.Name, url.PathEscape(fqdn(rr, zone.DNSName)), recordType)
+}
+
+// listZones 获取项目下所有公网托管区域
+func (a *DNSAdapter) listZones(ctx context.Context) ([]managedZone, error) {
+	items, err := a.client.List(ctx, gcpcommon.ServiceDNS, a.zonesPath(), nil, "managedZones")
+	if err != nil {
+		return nil, fmt.Errorf("gcp: list dns managed zones failed: %w", err)
+	}
+	zones := make([]managedZone, 0, len(items))
+	for _, item := range items {
+		var z managedZone
+		if err := json.Unmarshal(item, &z); err != nil {
+			continue
+		}
+		if z.Visibility != "" && z.Visibility != "public" {
+			continue
+		}
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
+
+// listRecordSets 获取托管区域下所有记录集
+func (a *DNSAdapter) listRecordSets(ctx context.Context, zone managedZone) ([]resourceRecordSet, error) {
+	items, err := a.client.List(ctx, gcpcommon.ServiceDNS, a.zonesPath()+"/"+zone.Name+"/rrsets", nil, "rrsets")
+	if err != nil {
+		return nil, fmt.Errorf("gcp: list record sets for zone %s failed: %w", zone.Name, err)
+	}
+	sets := make([]resourceRecordSet, 0, len(items))
+	for _, item := range items {
+		var rs resourceRecordSet
+		if err := json.Unmarshal(item, &rs); err != nil {
+			continue
+		}
+		sets = append(sets, rs)
+	}
+	return sets, nil
+}
+
+// resolveZone 将域名或托管区域名称解析为托管区域
+func (a *DNSAdapter) resolveZone(ctx context.Context, domain string) (*managedZone, error) {
+	zones, err := a.listZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(domain, ".")
+	for _, z := range zones {
+		if strings.EqualFold(z.domain(), name) || z.Name == domain || z.ID == domain {
+			zone := z
+			return &zone, nil
+		}
+	}
+	return nil, fmt.Errorf("gcp: dns managed zone %s not found", domain)
+}
+
+// getRecordSet 获取记录集，不存在时返回 nil
+func (a *DNSAdapter) getRecordSet(ctx context.Context, zone managedZone, recordType, rr string) (*resourceRecordSet, error) {
+	var rs resourceRecordSet
+	if err := a.client.Do(ctx, http.MethodGet, gcpcommon.ServiceDNS, a.recordSetPath(zone, recordType, rr), nil, nil, &rs); err != nil {
+		if gcpcommon.IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gcp: get record set %s/%s failed: %w", recordType, rr, err)
+	}
+	return &rs, nil
+}
+
+// writeRecordSet 整体写入记录集，已存在时 PATCH，否则创建
+func (a *DNSAdapter) writeRecordSet(ctx context.Context, zone managedZone, exists bool, recordType, rr string, ttl int, datas []string) error {
+	body := resourceRecordSet{
+		Name:    fqdn(rr, zone.DNSName),
+		Type:    recordType,
+		TTL:     ttl,
+		Rrdatas: datas,
+	}
+	if exists {
+		return a.client.Do(ctx, http.MethodPatch, gcpcommon.ServiceDNS, a.recordSetPath(zone, recordType, rr), nil, body, nil)
+	}
+	return a.client.Do(ctx, http.MethodPost, gcpcommon.ServiceDNS, a.zonesPath()+"/"+zone.Name+"/rrsets", nil, body, nil)
+}
+
+// rrData 解析后的记录值
+type rrData struct {
+	Value    string
+	Priority int
+}
+
+// parseRRData 解析 rrdata，MX/SRV 首段为优先级，TXT 去掉引号
+func parseRRData(recordType, data string) rrData {
+	switch recordType {
+	case "MX", "SRV":
+		if first, rest, ok := strings.Cut(data, " "); ok {
+			if priority, err := strconv.Atoi(first); err == nil {
+				return rrData{Value: rest, Priority: priority}
+			}
+		}
+	case "TXT":
+		return rrData{Value: strings.Trim(data, `"`)}
+	}
+	return rrData{Value: data}
+}
+
+// formatRRData 构造 rrdata，与 parseRRData 互逆
+func formatRRData(recordType, value string, priority int) string {
+	switch recordType {
+	case "MX", "SRV":
+		return fmt.Sprintf("%d %s", priority, value)
+	case "TXT":
+		if !strings.HasPrefix(value, `"`) {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
+
+// toDNSRecord 构造通用解析记录
+func toDNSRecord(domain, rr, recordType string, index, ttl int, data string) types.DNSRecord {
+	v := parseRRData(recordType, data)
+	return types.DNSRecord{
+		RecordID: fmt.Sprintf("%s/%s/%d", recordType, rr, index),
+		Domain:   domain,
+		RR:       rr,
+		Type:     recordType,
+		Value:    v.Value,
+		TTL:      ttl,
+		Priority: v.Priority,
+		Line:     "default",
+		Status:   "enable",
+	}
+}
+
+// parseRecordID 解析 RecordID: "{类型}/{主机记录}/{序号}"
+func parseRecordID(recordID string) (recordType, rr string, index int, err error) {
+	parts := strings.Split(recordID, "/")
+	if len(parts) != 3 {
+		return "", "", 0, fmt.Errorf("gcp: invalid DNS record id %q", recordID)
+	}
+	index, err = strconv.Atoi(parts[2])
+	if err != nil || index < 0 {
+		return "", "", 0, fmt.Errorf("gcp: invalid DNS record id %q", recordID)
+	}
+	return strings.ToUpper(parts[0]), parts[1], index, nil
+}
+
+// normalizeRR 统一主机记录格式，空值表示根域名
+func normalizeRR(rr string) string {
+	if rr == "" {
+		return "@"
+	}
+	return rr
+}
+
+// relativeRR 将记录集全名转换为主机记录: www.example.com. -> www
+func relativeRR(name, zoneDNSName string) string {
+	if strings.EqualFold(name, zoneDNSName) {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+zoneDNSName)
+}
+
+// fqdn 将主机记录转换为记录集全名: www -> www.example.com.
+func fqdn(rr, zoneDNSName string) string {
+	if rr == "@" || rr == "" {
+		return zoneDNSName
+	}
+	return rr + "." + zoneDNSName
+}
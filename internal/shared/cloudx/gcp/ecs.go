@@ -0,0 +1,356 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// ECSAdapter GCP Compute Engine 虚拟机适配器
+type ECSAdapter struct {
+	client        *gcpcommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewECSAdapter 创建 GCP Compute Engine 虚拟机适配器
+func NewECSAdapter(client *gcpcommon.Client, defaultRegion string, logger *elog.Component) *ECSAdapter {
+	return &ECSAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// gceInstance Compute Engine 实例
+type gceInstance struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	MachineType       string            `json:"machineType"`
+	Status            string            `json:"status"`
+	Zone              string            `json:"zone"`
+	CreationTimestamp string            `json:"creationTimestamp"`
+	Hostname          string            `json:"hostname"`
+	SelfLink          string            `json:"selfLink"`
+	Labels            map[string]string `json:"labels"`
+	Tags              struct {
+		Items []string `json:"items"`
+	} `json:"tags"`
+	NetworkInterfaces []struct {
+		Network       string `json:"network"`
+		Subnetwork    string `json:"subnetwork"`
+		NetworkIP     string `json:"networkIP"`
+		AccessConfigs []struct {
+			NatIP string `json:"natIP"`
+		} `json:"accessConfigs"`
+	} `json:"networkInterfaces"`
+	Disks []struct {
+		Source     string   `json:"source"`
+		DeviceName string   `json:"deviceName"`
+		Boot       bool     `json:"boot"`
+		AutoDelete bool     `json:"autoDelete"`
+		DiskSizeGb string   `json:"diskSizeGb"`
+		Licenses   []string `json:"licenses"`
+	} `json:"disks"`
+	ServiceAccounts []struct {
+		Email string `json:"email"`
+	} `json:"serviceAccounts"`
+	Scheduling struct {
+		Preemptible       bool   `json:"preemptible"`
+		ProvisioningModel string `json:"provisioningModel"`
+	} `json:"scheduling"`
+}
+
+// machineType 机型规格
+type machineType struct {
+	GuestCpus int `json:"guestCpus"`
+	MemoryMb  int `json:"memoryMb"`
+}
+
+// primaryNetwork 返回实例主网卡所在网络
+func (i gceInstance) primaryNetwork() string {
+	if len(i.NetworkInterfaces) == 0 {
+		return ""
+	}
+	return i.NetworkInterfaces[0].Network
+}
+
+// serviceAccountEmails 返回实例关联的服务账号
+func (i gceInstance) serviceAccountEmails() []string {
+	emails := make([]string, 0, len(i.ServiceAccounts))
+	for _, sa := range i.ServiceAccounts {
+		emails = append(emails, sa.Email)
+	}
+	return emails
+}
+
+// listGCEInstances 获取项目下所有可用区的实例
+func listGCEInstances(ctx context.Context, client *gcpcommon.Client) ([]gceInstance, error) {
+	items, err := client.AggregatedList(ctx, computePath(client, "/aggregated/instances"), nil, "instances")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP虚拟机列表失败: %w", err)
+	}
+	instances := make([]gceInstance, 0, len(items))
+	for _, item := range items {
+		var inst gceInstance
+		if err := json.Unmarshal(item, &inst); err != nil {
+			continue
+		}
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
+
+// GetRegions 获取支持的地域列表
+// 额外返回 global 伪地域，用于同步 VPC 网络、防火墙规则等全局资源
+func (a *ECSAdapter) GetRegions(ctx context.Context) ([]types.Region, error) {
+	list, err := a.client.ListRegions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP地域列表失败: %w", err)
+	}
+
+	regions := make([]types.Region, 0, len(list)+1)
+	for _, r := range list {
+		regions = append(regions, types.Region{
+			ID:          r.Name,
+			Name:        r.Name,
+			LocalName:   r.Name,
+			Description: r.Description,
+		})
+	}
+	regions = append(regions, types.Region{
+		ID:          gcpcommon.GlobalRegion,
+		Name:        gcpcommon.GlobalRegion,
+		LocalName:   "全局",
+		Description: "全局资源 (VPC 网络、防火墙规则、多地域存储桶)",
+	})
+	return regions, nil
+}
+
+// ListInstances 获取虚拟机列表
+func (a *ECSAdapter) ListInstances(ctx context.Context, region string) ([]types.ECSInstance, error) {
+	all, err := listGCEInstances(ctx, a.client)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]gceInstance, 0, len(all))
+	for _, inst := range all {
+		if gcpcommon.MatchRegion(gcpcommon.RegionFromURL(inst.Zone), region) {
+			matched = append(matched, inst)
+		}
+	}
+	if len(matched) == 0 {
+		return []types.ECSInstance{}, nil
+	}
+
+	firewalls, err := listFirewalls(ctx, a.client)
+	if err != nil {
+		a.logger.Warn("获取GCP防火墙规则失败，虚拟机安全组信息将为空", elog.FieldErr(err))
+	}
+	specs := a.loadMachineTypes(ctx, matched)
+
+	instances := make([]types.ECSInstance, 0, len(matched))
+	for _, inst := range matched {
+		instances = append(instances, convertInstance(inst, a.client.ProjectID(), firewalls, specs))
+	}
+
+	a.logger.Info("获取GCP虚拟机列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(instances)))
+
+	return instances, nil
+}
+
+// GetInstance 获取单个虚拟机详情
+func (a *ECSAdapter) GetInstance(ctx context.Context, region, instanceID string) (*types.ECSInstance, error) {
+	instances, err := a.ListInstancesByIDs(ctx, region, []string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("虚拟机不存在: %s", instanceID)
+	}
+	return &instances[0], nil
+}
+
+// ListInstancesByIDs 批量获取虚拟机
+func (a *ECSAdapter) ListInstancesByIDs(ctx context.Context, region string, instanceIDs []string) ([]types.ECSInstance, error) {
+	if len(instanceIDs) == 0 {
+		return []types.ECSInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &cloudx.ECSInstanceFilter{InstanceIDs: instanceIDs})
+}
+
+// GetInstanceStatus 获取虚拟机状态
+func (a *ECSAdapter) GetInstanceStatus(ctx context.Context, region, instanceID string) (string, error) {
+	instance, err := a.GetInstance(ctx, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取虚拟机列表
+func (a *ECSAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *cloudx.ECSInstanceFilter) ([]types.ECSInstance, error) {
+	instances, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return instances, nil
+	}
+
+	result := make([]types.ECSInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(filter.InstanceIDs) > 0 && !containsID(filter.InstanceIDs, inst.InstanceID, inst.InstanceName) {
+			continue
+		}
+		if !matchName(inst.InstanceName, filter.InstanceName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, inst.Status) {
+			continue
+		}
+		if filter.VPCID != "" && !gcpcommon.SameResource(inst.VPCID, filter.VPCID) {
+			continue
+		}
+		if filter.Zone != "" && inst.Zone != filter.Zone {
+			continue
+		}
+		if !matchTags(inst.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, inst)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// loadMachineTypes 查询实例使用的机型规格，key 为 zone/machineType
+// 单个机型查询失败不影响实例列表，CPU/内存置为 0
+func (a *ECSAdapter) loadMachineTypes(ctx context.Context, instances []gceInstance) map[string]machineType {
+	specs := make(map[string]machineType)
+	for _, inst := range instances {
+		zone := gcpcommon.NameFromURL(inst.Zone)
+		name := gcpcommon.NameFromURL(inst.MachineType)
+		key := zone + "/" + name
+		if _, ok := specs[key]; ok || name == "" {
+			continue
+		}
+
+		var mt machineType
+		path := computePath(a.client, "/zones/"+zone+"/machineTypes/"+name)
+		if err := a.client.Do(ctx, http.MethodGet, gcpcommon.ServiceCompute, path, nil, nil, &mt); err != nil {
+			a.logger.Warn("获取GCP机型规格失败",
+				elog.String("machine_type", key),
+				elog.FieldErr(err))
+		}
+		specs[key] = mt
+	}
+	return specs
+}
+
+// convertInstance 转换 Compute Engine 实例为通用格式
+func convertInstance(inst gceInstance, projectID string, firewalls []gceFirewall, specs map[string]machineType) types.ECSInstance {
+	// TERMINATED 在 GCP 中表示实例已停止 (可再次启动)，而非已销毁
+	status := types.NormalizeStatus(inst.Status)
+	if inst.Status == "TERMINATED" {
+		status = types.StatusStopped
+	}
+
+	chargeType := "PostPaid"
+	if inst.Scheduling.Preemptible || strings.EqualFold(inst.Scheduling.ProvisioningModel, "SPOT") {
+		chargeType = "Spot"
+	}
+
+	zone := gcpcommon.NameFromURL(inst.Zone)
+	machine := gcpcommon.NameFromURL(inst.MachineType)
+	spec := specs[zone+"/"+machine]
+
+	result := types.ECSInstance{
+		InstanceID:   gcpcommon.RelativeName(inst.SelfLink),
+		InstanceName: inst.Name,
+		Status:       status,
+		Region:       gcpcommon.RegionFromZone(zone),
+		Zone:         zone,
+		InstanceType: machine,
+		CPU:          spec.GuestCpus,
+		Memory:       spec.MemoryMb,
+		OSType:       "linux",
+		ChargeType:   chargeType,
+		CreationTime: gcpcommon.FormatTime(inst.CreationTimestamp),
+		NetworkType:  "vpc",
+		ProjectID:    projectID,
+		ProjectName:  projectID,
+		Tags:         copyTags(inst.Labels),
+		Description:  inst.Description,
+		Provider:     string(types.ProviderGCP),
+		HostName:     inst.Hostname,
+	}
+
+	// 机型形如 e2-standard-2 / n2d-highmem-8，规格族取第一段
+	if family, _, ok := strings.Cut(machine, "-"); ok {
+		result.InstanceTypeFamily = family
+	}
+
+	for _, d := range inst.Disks {
+		if d.Boot {
+			result.SystemDisk = types.SystemDisk{
+				DiskID: gcpcommon.RelativeName(d.Source),
+				Size:   atoi(d.DiskSizeGb),
+				Device: d.DeviceName,
+			}
+			// 启动盘许可证形如 .../licenses/windows-server-2022-dc
+			for _, license := range d.Licenses {
+				result.OSName = gcpcommon.NameFromURL(license)
+				if strings.Contains(result.OSName, "windows") {
+					result.OSType = "windows"
+				}
+			}
+			continue
+		}
+		result.DataDisks = append(result.DataDisks, types.DataDisk{
+			DiskID:             gcpcommon.RelativeName(d.Source),
+			Size:               atoi(d.DiskSizeGb),
+			Device:             d.DeviceName,
+			DeleteWithInstance: d.AutoDelete,
+		})
+	}
+
+	// 网络信息取主网卡
+	if len(inst.NetworkInterfaces) > 0 {
+		nic := inst.NetworkInterfaces[0]
+		result.PrivateIP = nic.NetworkIP
+		result.VPCID = gcpcommon.RelativeName(nic.Network)
+		result.VPCName = gcpcommon.NameFromURL(nic.Network)
+		result.VSwitchID = gcpcommon.RelativeName(nic.Subnetwork)
+		result.VSwitchName = gcpcommon.NameFromURL(nic.Subnetwork)
+		for _, ac := range nic.AccessConfigs {
+			if ac.NatIP != "" {
+				result.PublicIP = ac.NatIP
+				break
+			}
+		}
+	}
+
+	// 防火墙规则按网络 + 网络标记/服务账号生效，视为实例的安全组
+	for _, fw := range firewalls {
+		if fw.appliesTo(inst.primaryNetwork(), inst.Tags.Items, inst.serviceAccountEmails()) {
+			result.SecurityGroups = append(result.SecurityGroups, types.SecurityGroup{
+				ID:          gcpcommon.RelativeName(fw.SelfLink),
+				Name:        fw.Name,
+				Description: fw.Description,
+			})
+		}
+	}
+
+	return result
+}
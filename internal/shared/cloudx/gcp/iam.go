@@ -0,0 +1,121 @@
+package gcp
+
+import (
+	"context"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	iamgcp "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// IAMAdapter GCP IAM适配器
+// 绑定云账号，委托给 cloudx/iam/gcp 中基于服务账号与项目 IAM 策略的实现
+type IAMAdapter struct {
+	account *domain.CloudAccount
+	logger  *elog.Component
+	impl    *iamgcp.Adapter
+}
+
+// NewIAMAdapter 创建GCP IAM适配器
+func NewIAMAdapter(account *domain.CloudAccount, logger *elog.Component) *IAMAdapter {
+	return &IAMAdapter{
+		account: account,
+		logger:  logger,
+		impl:    iamgcp.NewAdapter(logger),
+	}
+}
+
+// ========== 用户管理 ==========
+
+// ListUsers 获取用户列表
+func (a *IAMAdapter) ListUsers(ctx context.Context) ([]*domain.CloudUser, error) {
+	return a.impl.ListUsers(ctx, a.account)
+}
+
+// GetUser 获取用户详情
+func (a *IAMAdapter) GetUser(ctx context.Context, userID string) (*domain.CloudUser, error) {
+	return a.impl.GetUser(ctx, a.account, userID)
+}
+
+// GetUserPolicies 获取用户的个人权限策略
+func (a *IAMAdapter) GetUserPolicies(ctx context.Context, userID string) ([]domain.PermissionPolicy, error) {
+	return a.impl.GetUserPolicies(ctx, a.account, userID)
+}
+
+// CreateUser 创建用户
+func (a *IAMAdapter) CreateUser(ctx context.Context, req *types.CreateUserRequest) (*domain.CloudUser, error) {
+	return a.impl.CreateUser(ctx, a.account, &iamgcp.CreateUserParams{
+		Username:    req.Username,
+		DisplayName: req.DisplayName,
+		Email:       req.Email,
+	})
+}
+
+// UpdateUserPermissions 更新用户权限
+func (a *IAMAdapter) UpdateUserPermissions(ctx context.Context, userID string, policies []domain.PermissionPolicy) error {
+	return a.impl.UpdateUserPermissions(ctx, a.account, userID, policies)
+}
+
+// DeleteUser 删除用户
+func (a *IAMAdapter) DeleteUser(ctx context.Context, userID string) error {
+	return a.impl.DeleteUser(ctx, a.account, userID)
+}
+
+// ========== 用户组管理 ==========
+
+// ListGroups 获取用户组列表
+func (a *IAMAdapter) ListGroups(ctx context.Context) ([]*domain.UserGroup, error) {
+	return a.impl.ListGroups(ctx, a.account)
+}
+
+// GetGroup 获取用户组详情
+func (a *IAMAdapter) GetGroup(ctx context.Context, groupID string) (*domain.UserGroup, error) {
+	return a.impl.GetGroup(ctx, a.account, groupID)
+}
+
+// CreateGroup 创建用户组
+func (a *IAMAdapter) CreateGroup(ctx context.Context, req *types.CreateGroupRequest) (*domain.UserGroup, error) {
+	return a.impl.CreateGroup(ctx, a.account, req)
+}
+
+// UpdateGroupPolicies 更新用户组权限策略
+func (a *IAMAdapter) UpdateGroupPolicies(ctx context.Context, groupID string, policies []domain.PermissionPolicy) error {
+	return a.impl.UpdateGroupPolicies(ctx, a.account, groupID, policies)
+}
+
+// DeleteGroup 删除用户组
+func (a *IAMAdapter) DeleteGroup(ctx context.Context, groupID string) error {
+	return a.impl.DeleteGroup(ctx, a.account, groupID)
+}
+
+// ListGroupUsers 获取用户组成员列表
+func (a *IAMAdapter) ListGroupUsers(ctx context.Context, groupID string) ([]*domain.CloudUser, error) {
+	return a.impl.ListGroupUsers(ctx, a.account, groupID)
+}
+
+// AddUserToGroup 将用户添加到用户组
+func (a *IAMAdapter) AddUserToGroup(ctx context.Context, groupID string, userID string) error {
+	return a.impl.AddUserToGroup(ctx, a.account, groupID, userID)
+}
+
+// RemoveUserFromGroup 将用户从用户组移除
+func (a *IAMAdapter) RemoveUserFromGroup(ctx context.Context, groupID string, userID string) error {
+	return a.impl.RemoveUserFromGroup(ctx, a.account, groupID, userID)
+}
+
+// ========== 策略管理 ==========
+
+// ListPolicies 获取权限策略列表
+func (a *IAMAdapter) ListPolicies(ctx context.Context) ([]domain.PermissionPolicy, error) {
+	return a.impl.ListPolicies(ctx, a.account)
+}
+
+// GetPolicy 获取策略详情
+func (a *IAMAdapter) GetPolicy(ctx context.Context, policyID string) (*domain.PermissionPolicy, error) {
+	return a.impl.GetPolicy(ctx, a.account, policyID)
+}
+
+// Ensure compile-time interface compliance
+var _ cloudx.IAMAdapter = (*IAMAdapter)(nil)
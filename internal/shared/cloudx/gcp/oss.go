@@ -0,0 +1,280 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// OSSAdapter GCP Cloud Storage 存储桶适配器
+// 单地域存储桶的地域为所在地域，多地域/双地域存储桶归入 global；
+// 容量统计来自 Cloud Monitoring 指标
+type OSSAdapter struct {
+	client *gcpcommon.Client
+	logger *elog.Component
+}
+
+// NewOSSAdapter 创建 GCP Cloud Storage 适配器
+func NewOSSAdapter(client *gcpcommon.Client, logger *elog.Component) *OSSAdapter {
+	return &OSSAdapter{
+		client: client,
+		logger: logger,
+	}
+}
+
+// gcsBucket Cloud Storage 存储桶
+type gcsBucket struct {
+	Name         string            `json:"name"`
+	Location     string            `json:"location"`
+	LocationType string            `json:"locationType"`
+	StorageClass string            `json:"storageClass"`
+	TimeCreated  time.Time         `json:"timeCreated"`
+	Labels       map[string]string `json:"labels"`
+	Versioning   *struct {
+		Enabled bool `json:"enabled"`
+	} `json:"versioning"`
+	Encryption *struct {
+		DefaultKmsKeyName string `json:"defaultKmsKeyName"`
+	} `json:"encryption"`
+	IAMConfiguration struct {
+		PublicAccessPrevention string `json:"publicAccessPrevention"`
+	} `json:"iamConfiguration"`
+	Lifecycle struct {
+		Rule []json.RawMessage `json:"rule"`
+	} `json:"lifecycle"`
+	Website *struct {
+		MainPageSuffix string `json:"mainPageSuffix"`
+		NotFoundPage   string `json:"notFoundPage"`
+	} `json:"website"`
+	Logging *struct {
+		LogBucket       string `json:"logBucket"`
+		LogObjectPrefix string `json:"logObjectPrefix"`
+	} `json:"logging"`
+	Cors []json.RawMessage `json:"cors"`
+}
+
+// timeSeriesResponse Cloud Monitoring 时间序列查询响应 (数据点按时间倒序)
+type timeSeriesResponse struct {
+	TimeSeries []struct {
+		Points []struct {
+			Value struct {
+				DoubleValue *float64 `json:"doubleValue"`
+				Int64Value  string   `json:"int64Value"`
+			} `json:"value"`
+		} `json:"points"`
+	} `json:"timeSeries"`
+}
+
+// latest 返回最近一个数据点的值
+func (r timeSeriesResponse) latest() int64 {
+	for _, ts := range r.TimeSeries {
+		for _, p := range ts.Points {
+			if p.Value.DoubleValue != nil {
+				return int64(*p.Value.DoubleValue)
+			}
+			if n, err := strconv.ParseInt(p.Value.Int64Value, 10, 64); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// listBuckets 获取项目下所有存储桶
+func (a *OSSAdapter) listBuckets(ctx context.Context) ([]gcsBucket, error) {
+	query := url.Values{}
+	query.Set("project", a.client.ProjectID())
+	query.Set("projection", "full")
+	items, err := a.client.List(ctx, gcpcommon.ServiceStorage, "/storage/v1/b", query, "items")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP存储桶列表失败: %w", err)
+	}
+	buckets := make([]gcsBucket, 0, len(items))
+	for _, item := range items {
+		var b gcsBucket
+		if err := json.Unmarshal(item, &b); err != nil {
+			continue
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+// ListBuckets 获取存储桶列表
+func (a *OSSAdapter) ListBuckets(ctx context.Context, region string) ([]types.OSSBucket, error) {
+	list, err := a.listBuckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]types.OSSBucket, 0, len(list))
+	for _, b := range list {
+		bucket := convertBucket(b)
+		if !gcpcommon.MatchRegion(bucket.Region, region) {
+			continue
+		}
+
+		stats, err := a.bucketStats(ctx, b.Name)
+		if err == nil {
+			bucket.ObjectCount = stats.ObjectCount
+			bucket.StorageSize = stats.StorageSize
+		} else {
+			a.logger.Warn("获取GCP存储桶统计信息失败",
+				elog.String("bucket", b.Name),
+				elog.FieldErr(err))
+		}
+
+		buckets = append(buckets, bucket)
+	}
+
+	a.logger.Info("获取GCP存储桶列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(buckets)))
+
+	return buckets, nil
+}
+
+// GetBucket 获取单个存储桶详情
+func (a *OSSAdapter) GetBucket(ctx context.Context, bucketName string) (*types.OSSBucket, error) {
+	var b gcsBucket
+	query := url.Values{"projection": []string{"full"}}
+	if err := a.client.Do(ctx, http.MethodGet, gcpcommon.ServiceStorage, "/storage/v1/b/"+url.PathEscape(bucketName), query, nil, &b); err != nil {
+		if gcpcommon.IsNotFoundError(err) {
+			return nil, fmt.Errorf("存储桶不存在: %s", bucketName)
+		}
+		return nil, fmt.Errorf("获取GCP存储桶详情失败: %w", err)
+	}
+	bucket := convertBucket(b)
+	return &bucket, nil
+}
+
+// GetBucketStats 获取存储桶统计信息
+func (a *OSSAdapter) GetBucketStats(ctx context.Context, bucketName string) (*types.OSSBucketStats, error) {
+	return a.bucketStats(ctx, bucketName)
+}
+
+// bucketStats 查询存储桶的已用容量和对象数量
+// Cloud Storage 存储指标每天采样一次，取最近 3 天内的最新值
+func (a *OSSAdapter) bucketStats(ctx context.Context, bucketName string) (*types.OSSBucketStats, error) {
+	now := time.Now().UTC()
+	stats := &types.OSSBucketStats{BucketName: bucketName}
+
+	query := func(metric string) (int64, error) {
+		q := url.Values{}
+		q.Set("filter", fmt.Sprintf(`metric.type="storage.googleapis.com/storage/%s" AND resource.labels.bucket_name="%s"`, metric, bucketName))
+		q.Set("interval.startTime", now.Add(-72*time.Hour).Format(time.RFC3339))
+		q.Set("interval.endTime", now.Format(time.RFC3339))
+		var resp timeSeriesResponse
+		path := "/v3/projects/" + a.client.ProjectID() + "/timeSeries"
+		if err := a.client.Do(ctx, http.MethodGet, gcpcommon.ServiceMonitoring, path, q, nil, &resp); err != nil {
+			return 0, err
+		}
+		return resp.latest(), nil
+	}
+
+	size, err := query("total_bytes")
+	if err != nil {
+		return nil, err
+	}
+	stats.StorageSize = size
+
+	count, err := query("object_count")
+	if err != nil {
+		return nil, err
+	}
+	stats.ObjectCount = count
+
+	return stats, nil
+}
+
+// ListBucketsWithFilter 带过滤条件获取存储桶列表
+func (a *OSSAdapter) ListBucketsWithFilter(ctx context.Context, region string, filter *types.OSSBucketFilter) ([]types.OSSBucket, error) {
+	buckets, err := a.ListBuckets(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return buckets, nil
+	}
+
+	result := make([]types.OSSBucket, 0, len(buckets))
+	for _, b := range buckets {
+		if len(filter.BucketNames) > 0 && !containsFold(filter.BucketNames, b.BucketName) {
+			continue
+		}
+		if filter.Prefix != "" && !strings.HasPrefix(b.BucketName, filter.Prefix) {
+			continue
+		}
+		if filter.StorageClass != "" && !strings.EqualFold(b.StorageClass, filter.StorageClass) {
+			continue
+		}
+		if !matchTags(b.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, b)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertBucket 转换 Cloud Storage 存储桶为通用格式
+func convertBucket(b gcsBucket) types.OSSBucket {
+	location := strings.ToLower(b.Location)
+	region := gcpcommon.GlobalRegion
+	if b.LocationType == "region" {
+		region = location
+	}
+
+	// 未强制阻止公共访问时 ACL 取决于 IAM 绑定，此处按私有处理
+	blockPublic := b.IAMConfiguration.PublicAccessPrevention == "enforced"
+
+	encryption := "AES256"
+	kmsKeyID := ""
+	if b.Encryption != nil && b.Encryption.DefaultKmsKeyName != "" {
+		encryption = "KMS"
+		kmsKeyID = b.Encryption.DefaultKmsKeyName
+	}
+
+	versioning := "Suspended"
+	if b.Versioning != nil && b.Versioning.Enabled {
+		versioning = "Enabled"
+	}
+
+	bucket := types.OSSBucket{
+		BucketName:             b.Name,
+		Region:                 region,
+		Location:               location,
+		CreationTime:           b.TimeCreated,
+		StorageClass:           b.StorageClass,
+		ACL:                    "private",
+		Versioning:             versioning,
+		CrossRegionReplication: b.LocationType == "multi-region" || b.LocationType == "dual-region",
+		ExtranetEndpoint:       "https://storage.googleapis.com/" + b.Name,
+		ServerSideEncryption:   encryption,
+		KMSKeyID:               kmsKeyID,
+		BlockPublicAccess:      blockPublic,
+		LifecycleRuleCount:     len(b.Lifecycle.Rule),
+		CORSRuleCount:          len(b.Cors),
+		Tags:                   copyTags(b.Labels),
+		Provider:               string(types.ProviderGCP),
+	}
+	if b.Website != nil {
+		bucket.WebsiteEnabled = b.Website.MainPageSuffix != "" || b.Website.NotFoundPage != ""
+		bucket.IndexDocument = b.Website.MainPageSuffix
+		bucket.ErrorDocument = b.Website.NotFoundPage
+	}
+	if b.Logging != nil && b.Logging.LogBucket != "" {
+		bucket.LoggingEnabled = true
+		bucket.LoggingBucket = b.Logging.LogBucket
+		bucket.LoggingPrefix = b.Logging.LogObjectPrefix
+	}
+	return bucket
+}
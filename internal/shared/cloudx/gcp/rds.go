@@ -0,0 +1,252 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// RDSAdapter GCP Cloud SQL 适配器
+type RDSAdapter struct {
+	client        *gcpcommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewRDSAdapter 创建 GCP Cloud SQL 适配器
+func NewRDSAdapter(client *gcpcommon.Client, defaultRegion string, logger *elog.Component) *RDSAdapter {
+	return &RDSAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// sqlInstance Cloud SQL 实例
+type sqlInstance struct {
+	Name             string   `json:"name"`
+	State            string   `json:"state"`
+	DatabaseVersion  string   `json:"databaseVersion"`
+	Region           string   `json:"region"`
+	GceZone          string   `json:"gceZone"`
+	SecondaryGceZone string   `json:"secondaryGceZone"`
+	ConnectionName   string   `json:"connectionName"`
+	CreateTime       string   `json:"createTime"`
+	ReplicaNames     []string `json:"replicaNames"`
+	SelfLink         string   `json:"selfLink"`
+	IPAddresses      []struct {
+		Type      string `json:"type"`
+		IPAddress string `json:"ipAddress"`
+	} `json:"ipAddresses"`
+	Settings struct {
+		Tier             string            `json:"tier"`
+		DataDiskSizeGb   string            `json:"dataDiskSizeGb"`
+		DataDiskType     string            `json:"dataDiskType"`
+		AvailabilityType string            `json:"availabilityType"`
+		ActivationPolicy string            `json:"activationPolicy"`
+		PricingPlan      string            `json:"pricingPlan"`
+		UserLabels       map[string]string `json:"userLabels"`
+		IPConfiguration  struct {
+			IPv4Enabled        bool   `json:"ipv4Enabled"`
+			PrivateNetwork     string `json:"privateNetwork"`
+			RequireSsl         bool   `json:"requireSsl"`
+			SslMode            string `json:"sslMode"`
+			AuthorizedNetworks []struct {
+				Value string `json:"value"`
+			} `json:"authorizedNetworks"`
+		} `json:"ipConfiguration"`
+		BackupConfiguration struct {
+			Enabled                     bool   `json:"enabled"`
+			StartTime                   string `json:"startTime"`
+			TransactionLogRetentionDays int    `json:"transactionLogRetentionDays"`
+		} `json:"backupConfiguration"`
+	} `json:"settings"`
+}
+
+// ListInstances 获取 Cloud SQL 实例列表
+func (a *RDSAdapter) ListInstances(ctx context.Context, region string) ([]types.RDSInstance, error) {
+	items, err := a.client.List(ctx, gcpcommon.ServiceSQLAdmin, "/v1/projects/"+a.client.ProjectID()+"/instances", nil, "items")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP Cloud SQL实例列表失败: %w", err)
+	}
+
+	instances := make([]types.RDSInstance, 0, len(items))
+	for _, item := range items {
+		var inst sqlInstance
+		if err := json.Unmarshal(item, &inst); err != nil {
+			continue
+		}
+		if !gcpcommon.MatchRegion(inst.Region, region) {
+			continue
+		}
+		instances = append(instances, convertSQLInstance(inst, a.client.ProjectID()))
+	}
+
+	a.logger.Info("获取GCP Cloud SQL实例列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(instances)))
+
+	return instances, nil
+}
+
+// GetInstance 获取单个 Cloud SQL 实例详情
+func (a *RDSAdapter) GetInstance(ctx context.Context, region, instanceID string) (*types.RDSInstance, error) {
+	instances, err := a.ListInstancesByIDs(ctx, region, []string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("Cloud SQL实例不存在: %s", instanceID)
+	}
+	return &instances[0], nil
+}
+
+// ListInstancesByIDs 批量获取 Cloud SQL 实例
+func (a *RDSAdapter) ListInstancesByIDs(ctx context.Context, region string, instanceIDs []string) ([]types.RDSInstance, error) {
+	if len(instanceIDs) == 0 {
+		return []types.RDSInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.RDSInstanceFilter{InstanceIDs: instanceIDs})
+}
+
+// GetInstanceStatus 获取 Cloud SQL 实例状态
+func (a *RDSAdapter) GetInstanceStatus(ctx context.Context, region, instanceID string) (string, error) {
+	instance, err := a.GetInstance(ctx, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取 Cloud SQL 实例列表
+func (a *RDSAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.RDSInstanceFilter) ([]types.RDSInstance, error) {
+	instances, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return instances, nil
+	}
+
+	result := make([]types.RDSInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(filter.InstanceIDs) > 0 && !containsID(filter.InstanceIDs, inst.InstanceID, inst.InstanceName) {
+			continue
+		}
+		if !matchName(inst.InstanceName, filter.InstanceName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, inst.Status) {
+			continue
+		}
+		if filter.Engine != "" && !strings.EqualFold(inst.Engine, filter.Engine) {
+			continue
+		}
+		if filter.VPCID != "" && !gcpcommon.SameResource(inst.VPCID, filter.VPCID) {
+			continue
+		}
+		if !matchTags(inst.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, inst)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertSQLInstance 转换 Cloud SQL 实例为通用格式
+func convertSQLInstance(inst sqlInstance, projectID string) types.RDSInstance {
+	s := inst.Settings
+
+	// activationPolicy=NEVER 表示实例已手动停止，state 仍为 RUNNABLE
+	status := types.NormalizeRDSStatus(inst.State)
+	if inst.State == "RUNNABLE" && s.ActivationPolicy == "NEVER" {
+		status = types.RDSStatusStopped
+	}
+
+	engine, version, port := parseDatabaseVersion(inst.DatabaseVersion)
+	cpu, memory := parseSQLTier(s.Tier)
+
+	category := "Basic"
+	if s.AvailabilityType == "REGIONAL" {
+		category = "HighAvailability"
+	}
+
+	chargeType := "PostPaid"
+	if s.PricingPlan == "PACKAGE" {
+		chargeType = "PrePaid"
+	}
+
+	result := types.RDSInstance{
+		InstanceID:            gcpcommon.RelativeName(inst.SelfLink),
+		InstanceName:          inst.Name,
+		Status:                status,
+		Region:                inst.Region,
+		Zone:                  inst.GceZone,
+		Engine:                engine,
+		EngineVersion:         version,
+		DBInstanceClass:       s.Tier,
+		CPU:                   cpu,
+		Memory:                memory,
+		Storage:               atoi(s.DataDiskSizeGb),
+		StorageType:           s.DataDiskType,
+		ConnectionString:      inst.ConnectionName,
+		Port:                  port,
+		VPCID:                 gcpcommon.RelativeName(s.IPConfiguration.PrivateNetwork),
+		Category:              category,
+		SecondaryZone:         inst.SecondaryGceZone,
+		ReadReplicaCount:      len(inst.ReplicaNames),
+		ChargeType:            chargeType,
+		CreationTime:          gcpcommon.FormatTime(inst.CreateTime),
+		SSLEnabled:            s.IPConfiguration.RequireSsl || strings.HasPrefix(s.IPConfiguration.SslMode, "ENCRYPTED_ONLY") || strings.HasPrefix(s.IPConfiguration.SslMode, "TRUSTED_CLIENT"),
+		BackupRetentionPeriod: s.BackupConfiguration.TransactionLogRetentionDays,
+		PreferredBackupTime:   s.BackupConfiguration.StartTime,
+		ProjectID:             projectID,
+		ProjectName:           projectID,
+		Tags:                  copyTags(s.UserLabels),
+		Provider:              string(types.ProviderGCP),
+	}
+
+	for _, ip := range inst.IPAddresses {
+		switch ip.Type {
+		case "PRIMARY":
+			result.PublicIP = ip.IPAddress
+		case "PRIVATE":
+			result.PrivateIP = ip.IPAddress
+		}
+	}
+	for _, n := range s.IPConfiguration.AuthorizedNetworks {
+		result.SecurityIPList = append(result.SecurityIPList, n.Value)
+	}
+	return result
+}
+
+// parseDatabaseVersion 解析 Cloud SQL 数据库版本: MYSQL_8_0 -> (MySQL, 8.0, 3306)
+func parseDatabaseVersion(v string) (engine, version string, port int) {
+	prefix, rest, _ := strings.Cut(v, "_")
+	switch prefix {
+	case "MYSQL":
+		return "MySQL", strings.ReplaceAll(rest, "_", "."), 3306
+	case "POSTGRES":
+		return "PostgreSQL", strings.ReplaceAll(rest, "_", "."), 5432
+	case "SQLSERVER":
+		// SQLSERVER_2019_STANDARD -> 2019 Standard 版本取年份
+		year, _, _ := strings.Cut(rest, "_")
+		return "SQLServer", year, 1433
+	default:
+		return v, "", 0
+	}
+}
+
+// parseSQLTier 从自定义机型 db-custom-{vCPU}-{内存MB} 解析规格，共享核心/预定义机型返回 0
+func parseSQLTier(tier string) (cpu, memory int) {
+	parts := strings.Split(tier, "-")
+	if len(parts) == 4 && parts[1] == "custom" {
+		return atoi(parts[2]), atoi(parts[3])
+	}
+	return 0, 0
+}
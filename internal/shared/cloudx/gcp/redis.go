@@ -0,0 +1,207 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// RedisAdapter GCP Memorystore for Redis 适配器
+type RedisAdapter struct {
+	client        *gcpcommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewRedisAdapter 创建 GCP Memorystore for Redis 适配器
+func NewRedisAdapter(client *gcpcommon.Client, defaultRegion string, logger *elog.Component) *RedisAdapter {
+	return &RedisAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// memorystoreInstance Memorystore Redis 实例
+type memorystoreInstance struct {
+	Name                  string            `json:"name"`
+	DisplayName           string            `json:"displayName"`
+	Labels                map[string]string `json:"labels"`
+	LocationID            string            `json:"locationId"`
+	AlternativeLocationID string            `json:"alternativeLocationId"`
+	RedisVersion          string            `json:"redisVersion"`
+	Host                  string            `json:"host"`
+	Port                  int               `json:"port"`
+	CreateTime            string            `json:"createTime"`
+	State                 string            `json:"state"`
+	Tier                  string            `json:"tier"`
+	MemorySizeGb          int               `json:"memorySizeGb"`
+	AuthorizedNetwork     string            `json:"authorizedNetwork"`
+	ReplicaCount          int               `json:"replicaCount"`
+	ReadReplicasMode      string            `json:"readReplicasMode"`
+	TransitEncryptionMode string            `json:"transitEncryptionMode"`
+	AuthEnabled           bool              `json:"authEnabled"`
+}
+
+// ListInstances 获取 Memorystore Redis 实例列表
+// locations/- 表示查询所有地域
+func (a *RedisAdapter) ListInstances(ctx context.Context, region string) ([]types.RedisInstance, error) {
+	path := "/v1/projects/" + a.client.ProjectID() + "/locations/-/instances"
+	items, err := a.client.List(ctx, gcpcommon.ServiceRedis, path, nil, "instances")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP Memorystore实例列表失败: %w", err)
+	}
+
+	instances := make([]types.RedisInstance, 0, len(items))
+	for _, item := range items {
+		var inst memorystoreInstance
+		if err := json.Unmarshal(item, &inst); err != nil {
+			continue
+		}
+		converted := convertMemorystore(inst, a.client.ProjectID())
+		if !gcpcommon.MatchRegion(converted.Region, region) {
+			continue
+		}
+		instances = append(instances, converted)
+	}
+
+	a.logger.Info("获取GCP Memorystore实例列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(instances)))
+
+	return instances, nil
+}
+
+// GetInstance 获取单个 Memorystore Redis 实例详情
+func (a *RedisAdapter) GetInstance(ctx context.Context, region, instanceID string) (*types.RedisInstance, error) {
+	instances, err := a.ListInstancesByIDs(ctx, region, []string{instanceID})
+	if err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("Memorystore实例不存在: %s", instanceID)
+	}
+	return &instances[0], nil
+}
+
+// ListInstancesByIDs 批量获取 Memorystore Redis 实例
+func (a *RedisAdapter) ListInstancesByIDs(ctx context.Context, region string, instanceIDs []string) ([]types.RedisInstance, error) {
+	if len(instanceIDs) == 0 {
+		return []types.RedisInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.RedisInstanceFilter{InstanceIDs: instanceIDs})
+}
+
+// GetInstanceStatus 获取 Memorystore Redis 实例状态
+func (a *RedisAdapter) GetInstanceStatus(ctx context.Context, region, instanceID string) (string, error) {
+	instance, err := a.GetInstance(ctx, region, instanceID)
+	if err != nil {
+		return "", err
+	}
+	return instance.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取 Memorystore Redis 实例列表
+func (a *RedisAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.RedisInstanceFilter) ([]types.RedisInstance, error) {
+	instances, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return instances, nil
+	}
+
+	result := make([]types.RedisInstance, 0, len(instances))
+	for _, inst := range instances {
+		if len(filter.InstanceIDs) > 0 && !containsID(filter.InstanceIDs, inst.InstanceID, inst.InstanceName) {
+			continue
+		}
+		if !matchName(inst.InstanceName, filter.InstanceName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, inst.Status) {
+			continue
+		}
+		if filter.Architecture != "" && !strings.EqualFold(inst.Architecture, filter.Architecture) {
+			continue
+		}
+		if filter.VPCID != "" && !gcpcommon.SameResource(inst.VPCID, filter.VPCID) {
+			continue
+		}
+		if !matchTags(inst.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, inst)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertMemorystore 转换 Memorystore Redis 实例为通用格式
+// 实例名称形如 projects/{p}/locations/{region}/instances/{id}
+func convertMemorystore(inst memorystoreInstance, projectID string) types.RedisInstance {
+	name := gcpcommon.NameFromURL(inst.Name)
+	if inst.DisplayName != "" {
+		name = inst.DisplayName
+	}
+
+	region := segmentAfter(inst.Name, "locations")
+	if region == "" {
+		region = gcpcommon.RegionFromZone(inst.LocationID)
+	}
+
+	nodeType := "single"
+	replicas := 0
+	if inst.Tier == "STANDARD_HA" {
+		nodeType = "double"
+		replicas = 1
+		if inst.ReplicaCount > 0 {
+			replicas = inst.ReplicaCount
+		}
+	}
+
+	// REDIS_7_0 -> 7.0
+	version := strings.ReplaceAll(strings.TrimPrefix(inst.RedisVersion, "REDIS_"), "_", ".")
+
+	return types.RedisInstance{
+		InstanceID:       inst.Name,
+		InstanceName:     name,
+		Status:           types.NormalizeRedisStatus(inst.State),
+		Region:           region,
+		Zone:             inst.LocationID,
+		EngineVersion:    version,
+		InstanceClass:    fmt.Sprintf("%s_%dGB", inst.Tier, inst.MemorySizeGb),
+		Architecture:     "standard",
+		Capacity:         inst.MemorySizeGb * 1024,
+		ConnectionDomain: inst.Host,
+		Port:             inst.Port,
+		VPCID:            gcpcommon.RelativeName(inst.AuthorizedNetwork),
+		PrivateIP:        inst.Host,
+		NodeType:         nodeType,
+		ReplicaCount:     replicas,
+		SecondaryZone:    inst.AlternativeLocationID,
+		ChargeType:       "PostPaid",
+		CreationTime:     gcpcommon.FormatTime(inst.CreateTime),
+		SSLEnabled:       inst.TransitEncryptionMode == "SERVER_AUTHENTICATION",
+		Password:         inst.AuthEnabled,
+		ProjectID:        projectID,
+		ProjectName:      projectID,
+		Tags:             copyTags(inst.Labels),
+		Provider:         string(types.ProviderGCP),
+	}
+}
+
+// segmentAfter 返回资源路径中 key 之后的一段
+func segmentAfter(name, key string) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		if p == key && i+1 < len(parts) {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,301 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// SecurityGroupAdapter GCP VPC 防火墙规则适配器
+// 每条防火墙规则视为一个安全组，按网络 + 目标网络标记/服务账号作用于实例；
+// 防火墙规则为全局资源，地域固定为 global
+type SecurityGroupAdapter struct {
+	client        *gcpcommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewSecurityGroupAdapter 创建 GCP 防火墙规则适配器
+func NewSecurityGroupAdapter(client *gcpcommon.Client, defaultRegion string, logger *elog.Component) *SecurityGroupAdapter {
+	return &SecurityGroupAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// gceFirewall VPC 防火墙规则
+type gceFirewall struct {
+	ID                    string         `json:"id"`
+	Name                  string         `json:"name"`
+	Description           string         `json:"description"`
+	Network               string         `json:"network"`
+	Priority              int            `json:"priority"`
+	Direction             string         `json:"direction"`
+	Disabled              bool           `json:"disabled"`
+	SourceRanges          []string       `json:"sourceRanges"`
+	DestinationRanges     []string       `json:"destinationRanges"`
+	SourceTags            []string       `json:"sourceTags"`
+	TargetTags            []string       `json:"targetTags"`
+	TargetServiceAccounts []string       `json:"targetServiceAccounts"`
+	Allowed               []firewallRule `json:"allowed"`
+	Denied                []firewallRule `json:"denied"`
+	CreationTimestamp     string         `json:"creationTimestamp"`
+	SelfLink              string         `json:"selfLink"`
+}
+
+// firewallRule 防火墙规则中的协议与端口
+type firewallRule struct {
+	IPProtocol string   `json:"IPProtocol"`
+	Ports      []string `json:"ports"`
+}
+
+// appliesTo 判断防火墙规则是否作用于指定网络中带有给定网络标记/服务账号的实例
+// 未指定目标时作用于网络内所有实例
+func (fw gceFirewall) appliesTo(network string, tags, serviceAccounts []string) bool {
+	if fw.Disabled || !gcpcommon.SameResource(fw.Network, network) {
+		return false
+	}
+	if len(fw.TargetTags) == 0 && len(fw.TargetServiceAccounts) == 0 {
+		return true
+	}
+	for _, t := range fw.TargetTags {
+		if containsFold(tags, t) {
+			return true
+		}
+	}
+	for _, sa := range fw.TargetServiceAccounts {
+		if containsFold(serviceAccounts, sa) {
+			return true
+		}
+	}
+	return false
+}
+
+// listFirewalls 获取项目下所有防火墙规则
+func listFirewalls(ctx context.Context, client *gcpcommon.Client) ([]gceFirewall, error) {
+	items, err := client.List(ctx, gcpcommon.ServiceCompute, computePath(client, "/global/firewalls"), nil, "items")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP防火墙规则列表失败: %w", err)
+	}
+	firewalls := make([]gceFirewall, 0, len(items))
+	for _, item := range items {
+		var fw gceFirewall
+		if err := json.Unmarshal(item, &fw); err != nil {
+			continue
+		}
+		firewalls = append(firewalls, fw)
+	}
+	return firewalls, nil
+}
+
+// ListInstances 获取防火墙规则列表
+func (a *SecurityGroupAdapter) ListInstances(ctx context.Context, region string) ([]types.SecurityGroupInstance, error) {
+	if !gcpcommon.MatchRegion(gcpcommon.GlobalRegion, region) {
+		return []types.SecurityGroupInstance{}, nil
+	}
+
+	firewalls, err := listFirewalls(ctx, a.client)
+	if err != nil {
+		return nil, err
+	}
+
+	instances, err := listGCEInstances(ctx, a.client)
+	if err != nil {
+		a.logger.Warn("获取GCP虚拟机列表失败，防火墙关联实例将为空", elog.FieldErr(err))
+	}
+
+	groups := make([]types.SecurityGroupInstance, 0, len(firewalls))
+	for _, fw := range firewalls {
+		groups = append(groups, convertFirewall(fw, a.client.ProjectID(), instances))
+	}
+
+	a.logger.Info("获取GCP防火墙规则列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(groups)))
+
+	return groups, nil
+}
+
+// GetInstance 获取单个防火墙规则详情 (包含规则)
+func (a *SecurityGroupAdapter) GetInstance(ctx context.Context, region, securityGroupID string) (*types.SecurityGroupInstance, error) {
+	groups, err := a.ListInstancesByIDs(ctx, region, []string{securityGroupID})
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("防火墙规则不存在: %s", securityGroupID)
+	}
+	return &groups[0], nil
+}
+
+// ListInstancesByIDs 批量获取防火墙规则
+func (a *SecurityGroupAdapter) ListInstancesByIDs(ctx context.Context, region string, securityGroupIDs []string) ([]types.SecurityGroupInstance, error) {
+	if len(securityGroupIDs) == 0 {
+		return []types.SecurityGroupInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.SecurityGroupFilter{SecurityGroupIDs: securityGroupIDs})
+}
+
+// ListInstancesWithFilter 带过滤条件获取防火墙规则列表
+func (a *SecurityGroupAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.SecurityGroupFilter) ([]types.SecurityGroupInstance, error) {
+	groups, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return groups, nil
+	}
+
+	result := make([]types.SecurityGroupInstance, 0, len(groups))
+	for _, g := range groups {
+		if len(filter.SecurityGroupIDs) > 0 && !containsID(filter.SecurityGroupIDs, g.SecurityGroupID, g.SecurityGroupName) {
+			continue
+		}
+		if !matchName(g.SecurityGroupName, filter.SecurityGroupName) {
+			continue
+		}
+		if filter.VPCID != "" && !gcpcommon.SameResource(g.VPCID, filter.VPCID) {
+			continue
+		}
+		if filter.SecurityGroupType != "" && g.SecurityGroupType != filter.SecurityGroupType {
+			continue
+		}
+		if !matchTags(g.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, g)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// GetSecurityGroupRules 获取防火墙规则明细
+func (a *SecurityGroupAdapter) GetSecurityGroupRules(ctx context.Context, region, securityGroupID string) ([]types.SecurityGroupRule, error) {
+	group, err := a.GetInstance(ctx, region, securityGroupID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]types.SecurityGroupRule, 0, len(group.IngressRules)+len(group.EgressRules))
+	rules = append(rules, group.IngressRules...)
+	rules = append(rules, group.EgressRules...)
+	return rules, nil
+}
+
+// ListByInstanceID 获取作用于虚拟机的防火墙规则
+func (a *SecurityGroupAdapter) ListByInstanceID(ctx context.Context, region, instanceID string) ([]types.SecurityGroupInstance, error) {
+	groups, err := a.ListInstances(ctx, gcpcommon.GlobalRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []types.SecurityGroupInstance
+	for _, g := range groups {
+		if containsID(g.InstanceIDs, instanceID, "") {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
+
+// convertFirewall 转换防火墙规则为通用安全组格式
+// InstanceIDs 记录规则作用的实例ID
+func convertFirewall(fw gceFirewall, projectID string, instances []gceInstance) types.SecurityGroupInstance {
+	var ingress, egress []types.SecurityGroupRule
+	for _, rule := range convertFirewallRules(fw) {
+		if rule.Direction == "ingress" {
+			ingress = append(ingress, rule)
+		} else {
+			egress = append(egress, rule)
+		}
+	}
+
+	var instanceIDs []string
+	for _, inst := range instances {
+		if fw.appliesTo(inst.primaryNetwork(), inst.Tags.Items, inst.serviceAccountEmails()) {
+			instanceIDs = append(instanceIDs, gcpcommon.RelativeName(inst.SelfLink))
+		}
+	}
+
+	return types.SecurityGroupInstance{
+		SecurityGroupID:   gcpcommon.RelativeName(fw.SelfLink),
+		SecurityGroupName: fw.Name,
+		Description:       fw.Description,
+		SecurityGroupType: "firewall",
+		VPCID:             gcpcommon.RelativeName(fw.Network),
+		VPCName:           gcpcommon.NameFromURL(fw.Network),
+		IngressRuleCount:  len(ingress),
+		EgressRuleCount:   len(egress),
+		InstanceCount:     len(instanceIDs),
+		InstanceIDs:       instanceIDs,
+		IngressRules:      ingress,
+		EgressRules:       egress,
+		Region:            gcpcommon.GlobalRegion,
+		ResourceGroupID:   projectID,
+		CreationTime:      gcpcommon.FormatTime(fw.CreationTimestamp),
+		Tags:              map[string]string{},
+		Provider:          string(types.ProviderGCP),
+	}
+}
+
+// convertFirewallRules 展开防火墙规则中的 allowed/denied 协议端口为通用规则
+func convertFirewallRules(fw gceFirewall) []types.SecurityGroupRule {
+	direction := "ingress"
+	if strings.EqualFold(fw.Direction, "EGRESS") {
+		direction = "egress"
+	}
+	description := fw.Description
+	if len(fw.TargetTags) > 0 {
+		description = strings.TrimSpace(description + " targetTags=" + strings.Join(fw.TargetTags, ","))
+	}
+
+	var rules []types.SecurityGroupRule
+	appendRules := func(entries []firewallRule, policy string) {
+		for _, e := range entries {
+			protocol := strings.ToLower(e.IPProtocol)
+			portRange := "-1/-1"
+			if len(e.Ports) > 0 {
+				ranges := make([]string, 0, len(e.Ports))
+				for _, port := range e.Ports {
+					ranges = append(ranges, convertPortRange(port))
+				}
+				portRange = strings.Join(ranges, ",")
+			}
+			rule := types.SecurityGroupRule{
+				RuleID:       fmt.Sprintf("%s-%d", fw.ID, len(rules)),
+				Direction:    direction,
+				Protocol:     protocol,
+				PortRange:    portRange,
+				Priority:     fw.Priority,
+				Policy:       policy,
+				Description:  description,
+				CreationTime: gcpcommon.FormatTime(fw.CreationTimestamp),
+			}
+			if direction == "ingress" {
+				rule.SourceCIDR = strings.Join(fw.SourceRanges, ",")
+				rule.SourceGroupID = strings.Join(fw.SourceTags, ",")
+			} else {
+				rule.DestCIDR = strings.Join(fw.DestinationRanges, ",")
+			}
+			rules = append(rules, rule)
+		}
+	}
+	appendRules(fw.Allowed, "accept")
+	appendRules(fw.Denied, "drop")
+	return rules
+}
+
+// convertPortRange 转换端口范围格式: "22" -> "22/22", "8000-9000" -> "8000/9000"
+func convertPortRange(port string) string {
+	if port == "" {
+		return "-1/-1"
+	}
+	if from, to, ok := strings.Cut(port, "-"); ok {
+		return from + "/" + to
+	}
+	return port + "/" + port
+}
@@ -0,0 +1,309 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx"
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// TagAdapterImpl GCP 标签 (labels) 适配器
+// 标签查询基于 Cloud Asset Inventory，标签写入按资源ID形态分发到对应服务:
+//   - projects/{p}/zones/{z}/instances/{n}      Compute Engine 实例
+//   - projects/{p}/zones|regions/{l}/disks/{n}  持久化磁盘
+//   - projects/{p}/instances/{n}                Cloud SQL 实例
+//   - projects/{p}/locations/{l}/instances/{n}  Memorystore 实例
+//   - 其余视为 Cloud Storage 存储桶名称
+type TagAdapterImpl struct {
+	client *gcpcommon.Client
+	logger *elog.Component
+}
+
+// NewTagAdapter 创建 GCP 标签适配器
+func NewTagAdapter(client *gcpcommon.Client, logger *elog.Component) *TagAdapterImpl {
+	return &TagAdapterImpl{
+		client: client,
+		logger: logger,
+	}
+}
+
+// assetResource Cloud Asset 资源搜索结果
+type assetResource struct {
+	Name      string            `json:"name"`
+	AssetType string            `json:"assetType"`
+	Location  string            `json:"location"`
+	Labels    map[string]string `json:"labels"`
+}
+
+// labeledResource 带 labels 和 labelFingerprint 的资源
+type labeledResource struct {
+	Labels           map[string]string `json:"labels"`
+	LabelFingerprint string            `json:"labelFingerprint"`
+	Settings         *struct {
+		UserLabels map[string]string `json:"userLabels"`
+	} `json:"settings"`
+}
+
+// searchResources 在项目范围内搜索资源
+func (a *TagAdapterImpl) searchResources(ctx context.Context, queryText string) ([]assetResource, error) {
+	query := url.Values{}
+	if queryText != "" {
+		query.Set("query", queryText)
+	}
+	path := "/v1/projects/" + a.client.ProjectID() + ":searchAllResources"
+	items, err := a.client.List(ctx, gcpcommon.ServiceCloudAsset, path, query, "results")
+	if err != nil {
+		return nil, fmt.Errorf("gcp: search resources failed: %w", err)
+	}
+	resources := make([]assetResource, 0, len(items))
+	for _, item := range items {
+		var r assetResource
+		if err := json.Unmarshal(item, &r); err != nil {
+			continue
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// ListTagKeys 查询标签键列表
+func (a *TagAdapterImpl) ListTagKeys(ctx context.Context, region string) ([]string, error) {
+	resources, err := a.searchResources(ctx, "labels:*")
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	for _, r := range resources {
+		if !gcpcommon.MatchRegion(assetRegion(r.Location), region) {
+			continue
+		}
+		for k := range r.Labels {
+			seen[k] = struct{}{}
+		}
+	}
+	return sortedKeys(seen), nil
+}
+
+// ListTagValues 查询指定标签键的值列表
+func (a *TagAdapterImpl) ListTagValues(ctx context.Context, region, key string) ([]string, error) {
+	resources, err := a.searchResources(ctx, fmt.Sprintf("labels.%s:*", key))
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]struct{})
+	for _, r := range resources {
+		if !gcpcommon.MatchRegion(assetRegion(r.Location), region) {
+			continue
+		}
+		if v, ok := r.Labels[key]; ok {
+			seen[v] = struct{}{}
+		}
+	}
+	return sortedKeys(seen), nil
+}
+
+// GetResourceTags 查询资源绑定的标签
+func (a *TagAdapterImpl) GetResourceTags(ctx context.Context, region, resourceType, resourceID string) (map[string]string, error) {
+	res, err := a.getLabeled(ctx, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("gcp: get labels of %s failed: %w", resourceID, err)
+	}
+	tags := res.Labels
+	if res.Settings != nil {
+		tags = res.Settings.UserLabels
+	}
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+	return tags, nil
+}
+
+// TagResource 为资源绑定标签 (合并到已有标签)
+func (a *TagAdapterImpl) TagResource(ctx context.Context, region, resourceType, resourceID string, tags map[string]string) error {
+	if err := a.updateLabels(ctx, resourceID, func(labels map[string]string) {
+		for k, v := range tags {
+			labels[k] = v
+		}
+	}); err != nil {
+		return fmt.Errorf("gcp: tag resource %s failed: %w", resourceID, err)
+	}
+	return nil
+}
+
+// UntagResource 解绑资源标签
+func (a *TagAdapterImpl) UntagResource(ctx context.Context, region, resourceType, resourceID string, tagKeys []string) error {
+	if err := a.updateLabels(ctx, resourceID, func(labels map[string]string) {
+		for _, k := range tagKeys {
+			delete(labels, k)
+		}
+	}); err != nil {
+		return fmt.Errorf("gcp: untag resource %s failed: %w", resourceID, err)
+	}
+	return nil
+}
+
+// ListResourcesByTag 按标签查询资源列表
+func (a *TagAdapterImpl) ListResourcesByTag(ctx context.Context, region, key, value string) ([]cloudx.TaggedResource, error) {
+	resources, err := a.searchResources(ctx, fmt.Sprintf("labels.%s=%s", key, value))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]cloudx.TaggedResource, 0, len(resources))
+	for _, r := range resources {
+		if r.Labels[key] != value {
+			continue
+		}
+		resRegion := assetRegion(r.Location)
+		if !gcpcommon.MatchRegion(resRegion, region) {
+			continue
+		}
+		result = append(result, cloudx.TaggedResource{
+			ResourceType: r.AssetType,
+			ResourceID:   assetResourceID(r.Name),
+			Region:       resRegion,
+		})
+	}
+	return result, nil
+}
+
+// getLabeled 读取资源当前的 labels
+func (a *TagAdapterImpl) getLabeled(ctx context.Context, resourceID string) (*labeledResource, error) {
+	service, path := a.resourceEndpoint(resourceID)
+	var res labeledResource
+	if err := a.client.Do(ctx, http.MethodGet, service, path, nil, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// updateLabels 读取-修改-写回资源 labels
+func (a *TagAdapterImpl) updateLabels(ctx context.Context, resourceID string, mutate func(map[string]string)) error {
+	current, err := a.getLabeled(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	labels := make(map[string]string)
+	source := current.Labels
+	if current.Settings != nil {
+		source = current.Settings.UserLabels
+	}
+	for k, v := range source {
+		labels[k] = v
+	}
+	mutate(labels)
+
+	service, path := a.resourceEndpoint(resourceID)
+	switch kindOfResource(resourceID) {
+	case resourceKindCompute:
+		// Compute Engine 使用 setLabels 整体替换，需携带 labelFingerprint 防止并发覆盖
+		body := map[string]any{"labels": labels, "labelFingerprint": current.LabelFingerprint}
+		return a.client.Do(ctx, http.MethodPost, service, path+"/setLabels", nil, body, nil)
+	case resourceKindSQL:
+		body := map[string]any{"settings": map[string]any{"userLabels": labelsPatch(source, labels)}}
+		return a.client.Do(ctx, http.MethodPatch, service, path, nil, body, nil)
+	case resourceKindRedis:
+		query := url.Values{"updateMask": []string{"labels"}}
+		return a.client.Do(ctx, http.MethodPatch, service, path, query, map[string]any{"labels": labels}, nil)
+	default:
+		// Cloud Storage PATCH 为合并语义，值为 null 表示删除该键
+		return a.client.Do(ctx, http.MethodPatch, service, path, nil, map[string]any{"labels": labelsPatch(source, labels)}, nil)
+	}
+}
+
+// resourceKind 标签写入方式
+type resourceKind int
+
+const (
+	resourceKindBucket resourceKind = iota
+	resourceKindCompute
+	resourceKindSQL
+	resourceKindRedis
+)
+
+// kindOfResource 根据资源ID形态判断资源类别
+func kindOfResource(resourceID string) resourceKind {
+	name := gcpcommon.RelativeName(resourceID)
+	switch {
+	case strings.Contains(name, "/locations/") && strings.Contains(name, "/instances/"):
+		return resourceKindRedis
+	case strings.Contains(name, "/zones/") || strings.Contains(name, "/regions/"):
+		return resourceKindCompute
+	case strings.HasPrefix(name, "projects/") && strings.Contains(name, "/instances/"):
+		return resourceKindSQL
+	default:
+		return resourceKindBucket
+	}
+}
+
+// resourceEndpoint 资源对应的服务及 API 路径
+func (a *TagAdapterImpl) resourceEndpoint(resourceID string) (string, string) {
+	name := gcpcommon.RelativeName(resourceID)
+	switch kindOfResource(resourceID) {
+	case resourceKindCompute:
+		return gcpcommon.ServiceCompute, "/compute/v1/" + name
+	case resourceKindSQL:
+		return gcpcommon.ServiceSQLAdmin, "/v1/" + name
+	case resourceKindRedis:
+		return gcpcommon.ServiceRedis, "/v1/" + name
+	default:
+		return gcpcommon.ServiceStorage, "/storage/v1/b/" + url.PathEscape(gcpcommon.NameFromURL(resourceID))
+	}
+}
+
+// labelsPatch 构造合并语义的 labels 补丁，被移除的键置为 null
+func labelsPatch(before, after map[string]string) map[string]any {
+	patch := make(map[string]any, len(after))
+	for k, v := range after {
+		patch[k] = v
+	}
+	for k := range before {
+		if _, ok := after[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
+
+// assetRegion 将 Cloud Asset location 转换为地域，可用区取所属地域
+func assetRegion(location string) string {
+	if location == "" {
+		return gcpcommon.GlobalRegion
+	}
+	location = strings.ToLower(location)
+	if strings.Count(location, "-") >= 2 {
+		return gcpcommon.RegionFromZone(location)
+	}
+	return location
+}
+
+// assetResourceID 将 Cloud Asset 完整资源名转换为资源ID
+// //compute.googleapis.com/projects/p/zones/z/instances/n -> projects/p/zones/z/instances/n
+// //storage.googleapis.com/bucket -> bucket
+func assetResourceID(name string) string {
+	if strings.Contains(name, "projects/") {
+		return gcpcommon.RelativeName(name)
+	}
+	return gcpcommon.NameFromURL(name)
+}
+
+// sortedKeys 返回集合中排序后的键
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Ensure compile-time interface compliance
+var _ cloudx.TagAdapter = (*TagAdapterImpl)(nil)
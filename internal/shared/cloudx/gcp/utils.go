@@ -0,0 +1,85 @@
+package gcp
+
+import (
+	"strconv"
+	"strings"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+)
+
+// computePath 拼接 Compute Engine 项目级 API 路径
+func computePath(client *gcpcommon.Client, suffix string) string {
+	return "/compute/v1/projects/" + client.ProjectID() + suffix
+}
+
+// containsID 判断资源ID列表中是否包含指定ID (支持 selfLink、相对路径或只传资源名称)
+func containsID(ids []string, id, name string) bool {
+	for _, v := range ids {
+		if gcpcommon.SameResource(v, id) || v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold 大小写不敏感的字符串包含判断
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTags 判断资源标签是否包含全部过滤标签
+func matchTags(resourceTags, filterTags map[string]string) bool {
+	for k, v := range filterTags {
+		if resourceTags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// matchName 名称模糊匹配，filter 为空表示不过滤
+func matchName(name, filter string) bool {
+	return filter == "" || strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+// paginate 按页码截取结果，pageSize 为 0 表示不分页
+func paginate[T any](items []T, pageNumber, pageSize int) []T {
+	if pageSize <= 0 {
+		return items
+	}
+	if pageNumber <= 0 {
+		pageNumber = 1
+	}
+	start := (pageNumber - 1) * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// copyTags 复制标签，避免 nil map
+func copyTags(tags map[string]string) map[string]string {
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = v
+	}
+	return result
+}
+
+// atoi GCP 的 int64 字段以字符串返回 (如 sizeGb)，解析失败返回 0
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
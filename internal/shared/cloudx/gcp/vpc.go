@@ -0,0 +1,155 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// VPCAdapter GCP VPC 网络适配器
+// GCP VPC 网络为全局资源 (子网按地域划分)，地域固定为 global
+type VPCAdapter struct {
+	client        *gcpcommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewVPCAdapter 创建 GCP VPC 网络适配器
+func NewVPCAdapter(client *gcpcommon.Client, defaultRegion string, logger *elog.Component) *VPCAdapter {
+	return &VPCAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// gceNetwork VPC 网络
+type gceNetwork struct {
+	ID                    string   `json:"id"`
+	Name                  string   `json:"name"`
+	Description           string   `json:"description"`
+	IPv4Range             string   `json:"IPv4Range"`
+	AutoCreateSubnetworks bool     `json:"autoCreateSubnetworks"`
+	Subnetworks           []string `json:"subnetworks"`
+	EnableUlaInternalIpv6 bool     `json:"enableUlaInternalIpv6"`
+	InternalIpv6Range     string   `json:"internalIpv6Range"`
+	CreationTimestamp     string   `json:"creationTimestamp"`
+	SelfLink              string   `json:"selfLink"`
+}
+
+// ListInstances 获取 VPC 网络列表
+func (a *VPCAdapter) ListInstances(ctx context.Context, region string) ([]types.VPCInstance, error) {
+	if !gcpcommon.MatchRegion(gcpcommon.GlobalRegion, region) {
+		return []types.VPCInstance{}, nil
+	}
+
+	items, err := a.client.List(ctx, gcpcommon.ServiceCompute, computePath(a.client, "/global/networks"), nil, "items")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP VPC网络列表失败: %w", err)
+	}
+
+	vpcs := make([]types.VPCInstance, 0, len(items))
+	for _, item := range items {
+		var n gceNetwork
+		if err := json.Unmarshal(item, &n); err != nil {
+			continue
+		}
+		vpcs = append(vpcs, convertNetwork(n, a.client.ProjectID()))
+	}
+
+	a.logger.Info("获取GCP VPC网络列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(vpcs)))
+
+	return vpcs, nil
+}
+
+// GetInstance 获取单个 VPC 网络详情
+func (a *VPCAdapter) GetInstance(ctx context.Context, region, vpcID string) (*types.VPCInstance, error) {
+	vpcs, err := a.ListInstancesByIDs(ctx, region, []string{vpcID})
+	if err != nil {
+		return nil, err
+	}
+	if len(vpcs) == 0 {
+		return nil, fmt.Errorf("VPC网络不存在: %s", vpcID)
+	}
+	return &vpcs[0], nil
+}
+
+// ListInstancesByIDs 批量获取 VPC 网络
+func (a *VPCAdapter) ListInstancesByIDs(ctx context.Context, region string, vpcIDs []string) ([]types.VPCInstance, error) {
+	if len(vpcIDs) == 0 {
+		return []types.VPCInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.VPCInstanceFilter{VPCIDs: vpcIDs})
+}
+
+// GetInstanceStatus 获取 VPC 网络状态
+func (a *VPCAdapter) GetInstanceStatus(ctx context.Context, region, vpcID string) (string, error) {
+	vpc, err := a.GetInstance(ctx, region, vpcID)
+	if err != nil {
+		return "", err
+	}
+	return vpc.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取 VPC 网络列表
+func (a *VPCAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.VPCInstanceFilter) ([]types.VPCInstance, error) {
+	vpcs, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return vpcs, nil
+	}
+
+	result := make([]types.VPCInstance, 0, len(vpcs))
+	for _, v := range vpcs {
+		if len(filter.VPCIDs) > 0 && !containsID(filter.VPCIDs, v.VPCID, v.VPCName) {
+			continue
+		}
+		if !matchName(v.VPCName, filter.VPCName) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, v.Status) {
+			continue
+		}
+		if filter.CidrBlock != "" && v.CidrBlock != filter.CidrBlock {
+			continue
+		}
+		if filter.IsDefault != nil && v.IsDefault != *filter.IsDefault {
+			continue
+		}
+		if !matchTags(v.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertNetwork 转换 GCP VPC 网络为通用格式
+// 自定义模式网络没有网络级 CIDR，仅旧版 (legacy) 网络有 IPv4Range
+func convertNetwork(n gceNetwork, projectID string) types.VPCInstance {
+	return types.VPCInstance{
+		VPCID:         gcpcommon.RelativeName(n.SelfLink),
+		VPCName:       n.Name,
+		Status:        "available",
+		Region:        gcpcommon.GlobalRegion,
+		Description:   n.Description,
+		CidrBlock:     n.IPv4Range,
+		IPv6CidrBlock: n.InternalIpv6Range,
+		EnableIPv6:    n.EnableUlaInternalIpv6,
+		IsDefault:     n.Name == "default",
+		VSwitchCount:  len(n.Subnetworks),
+		CreationTime:  gcpcommon.FormatTime(n.CreationTimestamp),
+		ProjectID:     projectID,
+		ProjectName:   projectID,
+		Tags:          map[string]string{},
+		Provider:      string(types.ProviderGCP),
+	}
+}
@@ -0,0 +1,195 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// gcpReservedIPCount GCP 每个子网主 IPv4 范围保留 4 个IP地址
+const gcpReservedIPCount = 4
+
+// VSwitchAdapter GCP 子网适配器
+type VSwitchAdapter struct {
+	client        *gcpcommon.Client
+	defaultRegion string
+	logger        *elog.Component
+}
+
+// NewVSwitchAdapter 创建 GCP 子网适配器
+func NewVSwitchAdapter(client *gcpcommon.Client, defaultRegion string, logger *elog.Component) *VSwitchAdapter {
+	return &VSwitchAdapter{
+		client:        client,
+		defaultRegion: defaultRegion,
+		logger:        logger,
+	}
+}
+
+// gceSubnetwork 子网
+type gceSubnetwork struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	Network            string `json:"network"`
+	IPCidrRange        string `json:"ipCidrRange"`
+	GatewayAddress     string `json:"gatewayAddress"`
+	Region             string `json:"region"`
+	StackType          string `json:"stackType"`
+	InternalIpv6Prefix string `json:"internalIpv6Prefix"`
+	ExternalIpv6Prefix string `json:"externalIpv6Prefix"`
+	State              string `json:"state"`
+	CreationTimestamp  string `json:"creationTimestamp"`
+	SelfLink           string `json:"selfLink"`
+}
+
+// ListInstances 获取子网列表
+func (a *VSwitchAdapter) ListInstances(ctx context.Context, region string) ([]types.VSwitchInstance, error) {
+	items, err := a.client.AggregatedList(ctx, computePath(a.client, "/aggregated/subnetworks"), nil, "subnetworks")
+	if err != nil {
+		return nil, fmt.Errorf("获取GCP子网列表失败: %w", err)
+	}
+
+	subnets := make([]types.VSwitchInstance, 0, len(items))
+	for _, item := range items {
+		var s gceSubnetwork
+		if err := json.Unmarshal(item, &s); err != nil {
+			continue
+		}
+		if !gcpcommon.MatchRegion(gcpcommon.NameFromURL(s.Region), region) {
+			continue
+		}
+		subnets = append(subnets, convertSubnetwork(s, a.client.ProjectID()))
+	}
+
+	a.logger.Info("获取GCP子网列表成功",
+		elog.String("region", region),
+		elog.Int("count", len(subnets)))
+
+	return subnets, nil
+}
+
+// GetInstance 获取单个子网详情
+func (a *VSwitchAdapter) GetInstance(ctx context.Context, region, vswitchID string) (*types.VSwitchInstance, error) {
+	subnets, err := a.ListInstancesByIDs(ctx, region, []string{vswitchID})
+	if err != nil {
+		return nil, err
+	}
+	if len(subnets) == 0 {
+		return nil, fmt.Errorf("子网不存在: %s", vswitchID)
+	}
+	return &subnets[0], nil
+}
+
+// ListInstancesByIDs 批量获取子网
+func (a *VSwitchAdapter) ListInstancesByIDs(ctx context.Context, region string, vswitchIDs []string) ([]types.VSwitchInstance, error) {
+	if len(vswitchIDs) == 0 {
+		return []types.VSwitchInstance{}, nil
+	}
+	return a.ListInstancesWithFilter(ctx, region, &types.VSwitchInstanceFilter{VSwitchIDs: vswitchIDs})
+}
+
+// GetInstanceStatus 获取子网状态
+func (a *VSwitchAdapter) GetInstanceStatus(ctx context.Context, region, vswitchID string) (string, error) {
+	subnet, err := a.GetInstance(ctx, region, vswitchID)
+	if err != nil {
+		return "", err
+	}
+	return subnet.Status, nil
+}
+
+// ListInstancesWithFilter 带过滤条件获取子网列表
+func (a *VSwitchAdapter) ListInstancesWithFilter(ctx context.Context, region string, filter *types.VSwitchInstanceFilter) ([]types.VSwitchInstance, error) {
+	subnets, err := a.ListInstances(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	if filter == nil {
+		return subnets, nil
+	}
+
+	result := make([]types.VSwitchInstance, 0, len(subnets))
+	for _, s := range subnets {
+		if len(filter.VSwitchIDs) > 0 && !containsID(filter.VSwitchIDs, s.VSwitchID, s.VSwitchName) {
+			continue
+		}
+		if !matchName(s.VSwitchName, filter.VSwitchName) {
+			continue
+		}
+		if filter.VPCID != "" && !gcpcommon.SameResource(s.VPCID, filter.VPCID) {
+			continue
+		}
+		if len(filter.Status) > 0 && !containsFold(filter.Status, s.Status) {
+			continue
+		}
+		if filter.IsDefault != nil && s.IsDefault != *filter.IsDefault {
+			continue
+		}
+		if !matchTags(s.Tags, filter.Tags) {
+			continue
+		}
+		result = append(result, s)
+	}
+	return paginate(result, filter.PageNumber, filter.PageSize), nil
+}
+
+// convertSubnetwork 转换 GCP 子网为通用格式
+// GCP 子网为地域级资源，不隶属于可用区
+func convertSubnetwork(s gceSubnetwork, projectID string) types.VSwitchInstance {
+	status := "available"
+	if s.State == "DRAINING" {
+		status = "deleting"
+	}
+
+	ipv6 := s.InternalIpv6Prefix
+	if ipv6 == "" {
+		ipv6 = s.ExternalIpv6Prefix
+	}
+
+	total := subnetIPCount(s.IPCidrRange)
+	available := total - gcpReservedIPCount
+	if available < 0 {
+		available = 0
+	}
+
+	networkName := gcpcommon.NameFromURL(s.Network)
+	return types.VSwitchInstance{
+		VSwitchID:        gcpcommon.RelativeName(s.SelfLink),
+		VSwitchName:      s.Name,
+		Status:           status,
+		Region:           gcpcommon.NameFromURL(s.Region),
+		Description:      s.Description,
+		CidrBlock:        s.IPCidrRange,
+		IPv6CidrBlock:    ipv6,
+		EnableIPv6:       ipv6 != "",
+		IsDefault:        networkName == "default",
+		GatewayIP:        s.GatewayAddress,
+		VPCID:            gcpcommon.RelativeName(s.Network),
+		VPCName:          networkName,
+		AvailableIPCount: available,
+		TotalIPCount:     total,
+		CreationTime:     gcpcommon.FormatTime(s.CreationTimestamp),
+		ProjectID:        projectID,
+		ProjectName:      projectID,
+		ResourceGroupID:  projectID,
+		Tags:             map[string]string{},
+		Provider:         string(types.ProviderGCP),
+	}
+}
+
+// subnetIPCount 计算 IPv4 CIDR 的地址总数
+func subnetIPCount(cidr string) int64 {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0
+	}
+	ones, bits := ipNet.Mask.Size()
+	if bits != 32 {
+		return 0
+	}
+	return int64(1) << uint(bits-ones)
+}
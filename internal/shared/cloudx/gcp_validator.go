@@ -0,0 +1,124 @@
+package cloudx
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+// GCPValidator GCP 验证器
+type GCPValidator struct{}
+
+// NewGCPValidator 创建 GCP 验证器
+func NewGCPValidator() CloudValidator {
+	return &GCPValidator{}
+}
+
+// ValidateCredentials 验证 GCP 服务账号凭证
+func (v *GCPValidator) ValidateCredentials(ctx context.Context, account *domain.CloudAccount) (*ValidationResult, error) {
+	startTime := time.Now()
+
+	// 验证凭证格式
+	cred, err := v.validateCredentialFormat(account)
+	if err != nil {
+		return &ValidationResult{
+			Valid:        false,
+			Message:      err.Error(),
+			ValidatedAt:  time.Now(),
+			ResponseTime: time.Since(startTime).Milliseconds(),
+		}, nil
+	}
+
+	// 调用 GCP API 验证
+	regions, err := v.listRegions(ctx, account)
+	if err != nil {
+		return &ValidationResult{
+			Valid:        false,
+			Message:      fmt.Sprintf("GCP API 调用失败: %v", err),
+			ValidatedAt:  time.Now(),
+			ResponseTime: time.Since(startTime).Milliseconds(),
+		}, nil
+	}
+
+	return &ValidationResult{
+		Valid:        true,
+		Message:      "GCP 凭证验证成功",
+		Regions:      regions,
+		Permissions:  []string{"compute.instances.list", "cloudsql.instances.list", "storage.buckets.list"},
+		AccountInfo:  fmt.Sprintf("Project: %s, ServiceAccount: %s", cred.ProjectID, maskAccessKey(cred.ClientEmail())),
+		ValidatedAt:  time.Now(),
+		ResponseTime: time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// GetSupportedRegions 获取 GCP 支持的地域
+func (v *GCPValidator) GetSupportedRegions(ctx context.Context, account *domain.CloudAccount) ([]string, error) {
+	if regions, err := v.listRegions(ctx, account); err == nil && len(regions) > 0 {
+		return regions, nil
+	}
+
+	// 降级为常用地域列表
+	return []string{
+		"us-central1",             // 美国艾奥瓦
+		"us-east1",                // 美国南卡罗来纳
+		"us-east4",                // 美国北弗吉尼亚
+		"us-west1",                // 美国俄勒冈
+		"us-west2",                // 美国洛杉矶
+		"northamerica-northeast1", // 加拿大蒙特利尔
+		"southamerica-east1",      // 巴西圣保罗
+		"europe-west1",            // 比利时
+		"europe-west2",            // 英国伦敦
+		"europe-west3",            // 德国法兰克福
+		"europe-west4",            // 荷兰
+		"europe-north1",           // 芬兰
+		"asia-east1",              // 中国台湾
+		"asia-east2",              // 中国香港
+		"asia-northeast1",         // 日本东京
+		"asia-northeast3",         // 韩国首尔
+		"asia-southeast1",         // 新加坡
+		"asia-south1",             // 印度孟买
+		"australia-southeast1",    // 澳大利亚悉尼
+	}, nil
+}
+
+// TestConnection 测试 GCP 连接
+func (v *GCPValidator) TestConnection(ctx context.Context, account *domain.CloudAccount) error {
+	_, err := v.listRegions(ctx, account)
+	return err
+}
+
+// validateCredentialFormat 验证 GCP 凭证格式
+// AccessKeyID 格式为 projectID[/billingTable]，AccessKeySecret 为服务账号 JSON 密钥
+func (v *GCPValidator) validateCredentialFormat(account *domain.CloudAccount) (*gcpcommon.Credential, error) {
+	cred, err := gcpcommon.ParseCredential(account.AccessKeyID, account.AccessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("GCP 凭证格式不正确: %w", err)
+	}
+	return cred, nil
+}
+
+// listRegions 调用 Compute Engine 地域接口，可同时校验服务账号密钥与项目访问权限
+func (v *GCPValidator) listRegions(ctx context.Context, account *domain.CloudAccount) ([]string, error) {
+	client, err := gcpcommon.NewClientFromAccount(account)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.ListRegions(ctx)
+	if err != nil {
+		if gcpcommon.IsPermissionError(err) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+		}
+		if ctx.Err() != nil {
+			return nil, ErrConnectionTimeout
+		}
+		return nil, err
+	}
+	regions := make([]string, 0, len(list))
+	for _, r := range list {
+		regions = append(regions, r.Name)
+	}
+	return regions, nil
+}
@@ -203,7 +203,7 @@ func (a *LBAdapter) convertToLBInstance(lb model.LoadBalancer, reg string, clien
 	listeners := a.fetchListenerDetails(client, lb.Id)
 
 	// 获取后端服务器详情
-	backendServers := a.fetchBackendServers(client, lb.Pools)
+	backendServers, backendFetched := a.fetchBackendServers(client, lb.Pools)
 
 	return types.LBInstance{
 		LoadBalancerID:     lb.Id,
@@ -220,6 +220,7 @@ func (a *LBAdapter) convertToLBInstance(lb model.LoadBalancer, reg string, clien
 		BackendServerCount: len(lb.Pools),
 		Listeners:          listeners,
 		BackendServers:     backendServers,
+		BackendFetched:     backendFetched,
 		CreationTime:       lb.CreatedAt,
 		ProjectID:          lb.ProjectId,
 		Description:        lb.Description,
@@ -262,13 +263,17 @@ func (a *LBAdapter) fetchListenerDetails(client *v3.ElbClient, lbID string) []ty
 	return listeners
 }
 
-// fetchBackendServers 获取后端服务器组中的成员详情
-func (a *LBAdapter) fetchBackendServers(client *v3.ElbClient, pools []model.PoolRef) []types.LBBackendServer {
-	if client == nil || len(pools) == 0 {
-		return nil
+// fetchBackendServers 获取后端服务器组中的成员详情，未查询或查询失败时 fetched 为 false
+func (a *LBAdapter) fetchBackendServers(client *v3.ElbClient, pools []model.PoolRef) ([]types.LBBackendServer, bool) {
+	if client == nil {
+		return nil, false
+	}
+	if len(pools) == 0 {
+		return nil, true
 	}
 
 	var backendServers []types.LBBackendServer
+	fetched := true
 	for _, pool := range pools {
 		request := &model.ListMembersRequest{
 			PoolId: pool.Id,
@@ -279,6 +284,7 @@ func (a *LBAdapter) fetchBackendServers(client *v3.ElbClient, pools []model.Pool
 			a.logger.Warn("获取后端服务器列表失败",
 				elog.String("pool_id", pool.Id),
 				elog.FieldErr(err))
+			fetched = false
 			continue
 		}
 
@@ -302,5 +308,5 @@ func (a *LBAdapter) fetchBackendServers(client *v3.ElbClient, pools []model.Pool
 			})
 		}
 	}
-	return backendServers
+	return backendServers, fetched
 }
@@ -75,6 +75,7 @@ func TestConvertToLBInstance(t *testing.T) {
 				BackendServerCount: 1,
 				Listeners:          nil, // client is nil, so fetchListenerDetails returns nil
 				BackendServers:     nil, // client is nil, so fetchBackendServers returns nil
+				BackendFetched:     false,
 				CreationTime:       "2024-01-01T00:00:00Z",
 				ProjectID:          "project-001",
 				Description:        "web负载均衡",
@@ -0,0 +1,498 @@
+package azure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/google/uuid"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	authorizationAPIVersion = "2022-04-01"
+
+	userSelect  = "id,userPrincipalName,displayName,mail,otherMails,accountEnabled,userType,createdDateTime,lastPasswordChangeDateTime"
+	groupSelect = "id,displayName,description,mailNickname,securityEnabled,createdDateTime"
+)
+
+// Adapter Azure IAM 适配器
+// 用户和用户组来自 Entra ID (Microsoft Graph)，权限策略对应订阅级别的 RBAC 角色
+type Adapter struct {
+	logger      *elog.Component
+	rateLimiter *azurecommon.RateLimiter
+	endpoints   azurecommon.Endpoints
+}
+
+// NewAdapter 创建 Azure IAM 适配器实例
+func NewAdapter(logger *elog.Component) *Adapter {
+	return &Adapter{
+		logger:      logger,
+		rateLimiter: azurecommon.NewRateLimiter(10), // 10 QPS
+		endpoints:   azurecommon.DefaultEndpoints,
+	}
+}
+
+// newClient 根据云账号创建 Azure REST 客户端
+func (a *Adapter) newClient(account *domain.CloudAccount) (*azurecommon.Client, error) {
+	if account == nil {
+		return nil, fmt.Errorf("cloud account cannot be nil")
+	}
+	cred, err := azurecommon.ParseCredential(account.AccessKeyID, account.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	return azurecommon.NewClient(cred, a.endpoints), nil
+}
+
+// ValidateCredentials 验证凭证
+func (a *Adapter) ValidateCredentials(ctx context.Context, account *domain.CloudAccount) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Value []graphUser `json:"value"`
+	}
+	if err := client.DoGraph(ctx, http.MethodGet, "/v1.0/users?$top=1&$select=id", nil, &resp); err != nil {
+		a.logger.Error("validate azure credentials failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.FieldErr(err))
+		return fmt.Errorf("invalid azure credentials: %w", err)
+	}
+
+	a.logger.Info("validate azure credentials success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)))
+
+	return nil
+}
+
+// ListUsers 获取 Entra ID 用户列表
+func (a *Adapter) ListUsers(ctx context.Context, account *domain.CloudAccount) ([]*domain.CloudUser, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := client.ListGraph(ctx, "/v1.0/users?$top=999&$select="+userSelect)
+	if err != nil {
+		a.logger.Error("list azure users failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to list entra id users: %w", err)
+	}
+
+	users := make([]*domain.CloudUser, 0, len(items))
+	for _, item := range items {
+		var u graphUser
+		if err := json.Unmarshal(item, &u); err != nil {
+			continue
+		}
+		users = append(users, ConvertGraphUserToCloudUser(u, account))
+	}
+
+	a.logger.Info("list azure users success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.Int("count", len(users)))
+
+	return users, nil
+}
+
+// GetUser 获取用户详情，userID 可以是对象ID或 userPrincipalName
+func (a *Adapter) GetUser(ctx context.Context, account *domain.CloudAccount, userID string) (*domain.CloudUser, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var u graphUser
+	path := "/v1.0/users/" + url.PathEscape(userID) + "?$select=" + userSelect
+	if err := client.DoGraph(ctx, http.MethodGet, path, nil, &u); err != nil {
+		a.logger.Error("get azure user failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to get entra id user: %w", err)
+	}
+
+	return ConvertGraphUserToCloudUser(u, account), nil
+}
+
+// CreateUser 创建 Entra ID 用户
+// 初始密码随机生成，用户首次登录时必须修改
+func (a *Adapter) CreateUser(ctx context.Context, account *domain.CloudAccount, params *CreateUserParams) (*domain.CloudUser, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	upn := params.Username
+	if !strings.Contains(upn, "@") {
+		defaultDomain, err := a.defaultDomain(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		upn = upn + "@" + defaultDomain
+	}
+	nickname := strings.SplitN(upn, "@", 2)[0]
+
+	displayName := params.DisplayName
+	if displayName == "" {
+		displayName = nickname
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]any{
+		"accountEnabled":    true,
+		"displayName":       displayName,
+		"mailNickname":      nickname,
+		"userPrincipalName": upn,
+		"passwordProfile": map[string]any{
+			"forceChangePasswordNextSignIn": true,
+			"password":                      password,
+		},
+	}
+	if params.Email != "" {
+		body["otherMails"] = []string{params.Email}
+	}
+
+	var created graphUser
+	if err := client.DoGraph(ctx, http.MethodPost, "/v1.0/users", body, &created); err != nil {
+		a.logger.Error("create azure user failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("username", upn),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to create entra id user: %w", err)
+	}
+
+	a.logger.Info("create azure user success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("username", upn))
+
+	return ConvertGraphUserToCloudUser(created, account), nil
+}
+
+// DeleteUser 删除用户 (先移除订阅级别的角色分配)
+func (a *Adapter) DeleteUser(ctx context.Context, account *domain.CloudAccount, userID string) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	assignments, err := a.listRoleAssignments(ctx, client, userID)
+	if err == nil {
+		for _, ra := range assignments {
+			if err := client.DoARM(ctx, http.MethodDelete, ra.ID, authorizationAPIVersion, nil, nil, nil); err != nil {
+				a.logger.Warn("delete role assignment failed",
+					elog.String("assignment_id", ra.ID),
+					elog.FieldErr(err))
+			}
+		}
+	}
+
+	if err := client.DoGraph(ctx, http.MethodDelete, "/v1.0/users/"+url.PathEscape(userID), nil, nil); err != nil {
+		a.logger.Error("delete azure user failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return fmt.Errorf("failed to delete entra id user: %w", err)
+	}
+
+	a.logger.Info("delete azure user success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("user_id", userID))
+
+	return nil
+}
+
+// UpdateUserPermissions 更新用户的订阅级别角色分配
+func (a *Adapter) UpdateUserPermissions(ctx context.Context, account *domain.CloudAccount, userID string, policies []domain.PermissionPolicy) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	attached, detached, err := a.syncRoleAssignments(ctx, client, userID, "User", policies)
+	if err != nil {
+		a.logger.Error("update azure user permissions failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return err
+	}
+
+	a.logger.Info("update user permissions success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("user_id", userID),
+		elog.Int("attached", attached),
+		elog.Int("detached", detached))
+
+	return nil
+}
+
+// ListPolicies 获取订阅可用的 RBAC 角色定义
+func (a *Adapter) ListPolicies(ctx context.Context, account *domain.CloudAccount) ([]domain.PermissionPolicy, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions, err := a.listRoleDefinitions(ctx, client)
+	if err != nil {
+		a.logger.Error("list azure role definitions failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.FieldErr(err))
+		return nil, err
+	}
+
+	policies := make([]domain.PermissionPolicy, 0, len(definitions))
+	for _, rd := range definitions {
+		policies = append(policies, ConvertRoleDefinitionToPolicy(rd))
+	}
+
+	a.logger.Info("list azure policies success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.Int("count", len(policies)))
+
+	return policies, nil
+}
+
+// GetPolicy 获取角色定义详情，policyID 为角色定义资源ID
+func (a *Adapter) GetPolicy(ctx context.Context, account *domain.CloudAccount, policyID string) (*domain.PermissionPolicy, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var rd roleDefinition
+	if err := client.DoARM(ctx, http.MethodGet, policyID, authorizationAPIVersion, nil, nil, &rd); err != nil {
+		return nil, fmt.Errorf("failed to get role definition: %w", err)
+	}
+	policy := ConvertRoleDefinitionToPolicy(rd)
+	return &policy, nil
+}
+
+// GetUserPolicies 获取用户直接分配的 RBAC 角色
+func (a *Adapter) GetUserPolicies(ctx context.Context, account *domain.CloudAccount, userID string) ([]domain.PermissionPolicy, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.principalPolicies(ctx, client, userID)
+}
+
+// defaultDomain 获取租户默认域名
+func (a *Adapter) defaultDomain(ctx context.Context, client *azurecommon.Client) (string, error) {
+	items, err := client.ListGraph(ctx, "/v1.0/domains")
+	if err != nil {
+		return "", fmt.Errorf("failed to list entra id domains: %w", err)
+	}
+	for _, item := range items {
+		var d struct {
+			ID        string `json:"id"`
+			IsDefault bool   `json:"isDefault"`
+		}
+		if err := json.Unmarshal(item, &d); err == nil && d.IsDefault {
+			return d.ID, nil
+		}
+	}
+	return "", fmt.Errorf("entra id tenant has no default domain")
+}
+
+// listRoleDefinitions 获取订阅级别的角色定义
+func (a *Adapter) listRoleDefinitions(ctx context.Context, client *azurecommon.Client) ([]roleDefinition, error) {
+	subPath, err := client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := client.ListARM(ctx, subPath+"/providers/Microsoft.Authorization/roleDefinitions", authorizationAPIVersion, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role definitions: %w", err)
+	}
+	definitions := make([]roleDefinition, 0, len(items))
+	for _, item := range items {
+		var rd roleDefinition
+		if err := json.Unmarshal(item, &rd); err == nil {
+			definitions = append(definitions, rd)
+		}
+	}
+	return definitions, nil
+}
+
+// listRoleAssignments 获取主体在订阅级别的角色分配
+func (a *Adapter) listRoleAssignments(ctx context.Context, client *azurecommon.Client, principalID string) ([]roleAssignment, error) {
+	subPath, err := client.SubscriptionPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("$filter", fmt.Sprintf("principalId eq '%s'", principalID))
+	items, err := client.ListARM(ctx, subPath+"/providers/Microsoft.Authorization/roleAssignments", authorizationAPIVersion, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role assignments: %w", err)
+	}
+
+	assignments := make([]roleAssignment, 0, len(items))
+	for _, item := range items {
+		var ra roleAssignment
+		if err := json.Unmarshal(item, &ra); err != nil {
+			continue
+		}
+		// 仅管理订阅级别的分配，资源组/资源级别的分配保持不变
+		if !azurecommon.SameID(ra.Properties.Scope, subPath) {
+			continue
+		}
+		assignments = append(assignments, ra)
+	}
+	return assignments, nil
+}
+
+// principalPolicies 获取主体已分配的角色，转换为权限策略
+func (a *Adapter) principalPolicies(ctx context.Context, client *azurecommon.Client, principalID string) ([]domain.PermissionPolicy, error) {
+	assignments, err := a.listRoleAssignments(ctx, client, principalID)
+	if err != nil {
+		return nil, err
+	}
+	if len(assignments) == 0 {
+		return []domain.PermissionPolicy{}, nil
+	}
+
+	definitions, err := a.listRoleDefinitions(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]roleDefinition, len(definitions))
+	for _, rd := range definitions {
+		byName[strings.ToLower(rd.Name)] = rd
+	}
+
+	policies := make([]domain.PermissionPolicy, 0, len(assignments))
+	for _, ra := range assignments {
+		rd, ok := byName[strings.ToLower(azurecommon.NameFromID(ra.Properties.RoleDefinitionID))]
+		if !ok {
+			rd = roleDefinition{ID: ra.Properties.RoleDefinitionID}
+			rd.Properties.RoleName = azurecommon.NameFromID(ra.Properties.RoleDefinitionID)
+		}
+		policies = append(policies, ConvertRoleDefinitionToPolicy(rd))
+	}
+	return policies, nil
+}
+
+// syncRoleAssignments 将主体的订阅级别角色分配同步为目标策略集合
+// 角色定义按 GUID 比较，忽略资源ID中订阅前缀的差异
+func (a *Adapter) syncRoleAssignments(ctx context.Context, client *azurecommon.Client, principalID, principalType string, policies []domain.PermissionPolicy) (int, int, error) {
+	current, err := a.listRoleAssignments(ctx, client, principalID)
+	if err != nil {
+		return 0, 0, err
+	}
+	subPath, err := client.SubscriptionPath(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	currentRoles := make(map[string]roleAssignment, len(current))
+	for _, ra := range current {
+		currentRoles[strings.ToLower(azurecommon.NameFromID(ra.Properties.RoleDefinitionID))] = ra
+	}
+	targetRoles := make(map[string]domain.PermissionPolicy)
+	for _, p := range policies {
+		if p.Provider == domain.CloudProviderAzure {
+			targetRoles[strings.ToLower(azurecommon.NameFromID(p.PolicyID))] = p
+		}
+	}
+
+	detached := 0
+	for role, ra := range currentRoles {
+		if _, ok := targetRoles[role]; ok {
+			continue
+		}
+		if err := client.DoARM(ctx, http.MethodDelete, ra.ID, authorizationAPIVersion, nil, nil, nil); err != nil {
+			a.logger.Error("delete role assignment failed",
+				elog.String("principal_id", principalID),
+				elog.String("assignment_id", ra.ID),
+				elog.FieldErr(err))
+			// 继续处理其他角色
+			continue
+		}
+		detached++
+	}
+
+	attached := 0
+	for role, p := range targetRoles {
+		if _, ok := currentRoles[role]; ok {
+			continue
+		}
+		body := map[string]any{
+			"properties": map[string]any{
+				"roleDefinitionId": subPath + "/providers/Microsoft.Authorization/roleDefinitions/" + role,
+				"principalId":      principalID,
+				"principalType":    principalType,
+			},
+		}
+		path := subPath + "/providers/Microsoft.Authorization/roleAssignments/" + uuid.NewString()
+		if err := client.DoARM(ctx, http.MethodPut, path, authorizationAPIVersion, nil, body, nil); err != nil {
+			return attached, detached, fmt.Errorf("failed to assign role %s: %w", p.PolicyName, err)
+		}
+		attached++
+	}
+
+	return attached, detached, nil
+}
+
+// randomPassword 生成满足 Entra ID 复杂度要求的随机初始密码
+func randomPassword() (string, error) {
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate password failed: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf) + "Aa1!", nil
+}
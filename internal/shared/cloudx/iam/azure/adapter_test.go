@@ -0,0 +1,150 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	azurecommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTenantID       = "00000000-0000-0000-0000-000000000001"
+	testClientID       = "00000000-0000-0000-0000-000000000002"
+	testSubscriptionID = "00000000-0000-0000-0000-000000000003"
+	testSubPath        = "/subscriptions/" + testSubscriptionID
+
+	readerRoleGUID      = "acdd72a7-3385-48ef-bd42-f606fba81ae7"
+	contributorRoleGUID = "b24988ac-6180-42a0-ab88-20f7382dd24c"
+)
+
+// graphServer 模拟 Graph 与 Authorization API
+type graphServer struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *graphServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") {
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1.0/users" && r.URL.Query().Get("$skiptoken") == "2":
+		_, _ = w.Write([]byte(`{"value":[
+			{"id":"u-2","userPrincipalName":"bob@contoso.onmicrosoft.com","displayName":"Bob","otherMails":["bob@example.com"],"accountEnabled":false,"userType":"Guest"}
+		]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/v1.0/users":
+		_, _ = w.Write([]byte(`{"value":[
+			{"id":"u-1","userPrincipalName":"alice@contoso.onmicrosoft.com","displayName":"Alice","mail":"alice@contoso.com","accountEnabled":true,"userType":"Member","createdDateTime":"2024-01-02T03:04:05Z"}
+		],"@odata.nextLink":"http://` + r.Host + `/v1.0/users?$skiptoken=2"}`))
+	case r.Method == http.MethodGet && r.URL.Path == testSubPath+"/providers/Microsoft.Authorization/roleAssignments":
+		_, _ = w.Write([]byte(`{"value":[
+			{"id":"` + testSubPath + `/providers/Microsoft.Authorization/roleAssignments/ra-1","name":"ra-1","properties":{"roleDefinitionId":"` + testSubPath + `/providers/Microsoft.Authorization/roleDefinitions/` + readerRoleGUID + `","principalId":"u-1","principalType":"User","scope":"` + testSubPath + `"}},
+			{"id":"` + testSubPath + `/resourceGroups/rg/providers/Microsoft.Authorization/roleAssignments/ra-2","name":"ra-2","properties":{"roleDefinitionId":"` + testSubPath + `/providers/Microsoft.Authorization/roleDefinitions/` + contributorRoleGUID + `","principalId":"u-1","principalType":"User","scope":"` + testSubPath + `/resourceGroups/rg"}}
+		]}`))
+	case r.Method == http.MethodGet && r.URL.Path == testSubPath+"/providers/Microsoft.Authorization/roleDefinitions":
+		_, _ = w.Write([]byte(`{"value":[
+			{"id":"` + testSubPath + `/providers/Microsoft.Authorization/roleDefinitions/` + readerRoleGUID + `","name":"` + readerRoleGUID + `","properties":{"roleName":"Reader","type":"BuiltInRole","permissions":[{"actions":["*/read"],"notActions":[]}]}},
+			{"id":"` + testSubPath + `/providers/Microsoft.Authorization/roleDefinitions/` + contributorRoleGUID + `","name":"` + contributorRoleGUID + `","properties":{"roleName":"Contributor","type":"BuiltInRole","permissions":[{"actions":["*"],"notActions":["Microsoft.Authorization/*/Write"]}]}}
+		]}`))
+	case r.Method == http.MethodPut || r.Method == http.MethodDelete:
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":"Request_ResourceNotFound","message":"not recorded"}}`))
+	}
+}
+
+func newTestAdapter(t *testing.T) (*Adapter, *graphServer, *domain.CloudAccount) {
+	t.Helper()
+	gs := &graphServer{}
+	srv := httptest.NewServer(gs)
+	t.Cleanup(srv.Close)
+
+	adapter := NewAdapter(elog.DefaultLogger)
+	adapter.endpoints = azurecommon.Endpoints{Login: srv.URL, Management: srv.URL, Graph: srv.URL}
+
+	account := &domain.CloudAccount{
+		ID:              7,
+		TenantID:        "tenant-a",
+		AccessKeyID:     testTenantID + "/" + testClientID + "/" + testSubscriptionID,
+		AccessKeySecret: "secret",
+	}
+	return adapter, gs, account
+}
+
+func TestAdapter_ListUsers(t *testing.T) {
+	adapter, _, account := newTestAdapter(t)
+
+	users, err := adapter.ListUsers(context.Background(), account)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	u := users[0]
+	assert.Equal(t, "alice@contoso.onmicrosoft.com", u.Username)
+	assert.Equal(t, domain.CloudUserTypeEntraUser, u.UserType)
+	assert.Equal(t, domain.CloudProviderAzure, u.Provider)
+	assert.Equal(t, "u-1", u.CloudUserID)
+	assert.Equal(t, domain.CloudUserStatusActive, u.Status)
+	assert.Equal(t, "tenant-a", u.TenantID)
+
+	// 禁用账号与 otherMails 回退
+	assert.Equal(t, domain.CloudUserStatusInactive, users[1].Status)
+	assert.Equal(t, "bob@example.com", users[1].Email)
+}
+
+func TestAdapter_GetUserPolicies_SubscriptionScopeOnly(t *testing.T) {
+	adapter, _, account := newTestAdapter(t)
+
+	policies, err := adapter.GetUserPolicies(context.Background(), account, "u-1")
+	require.NoError(t, err)
+	// 资源组级别的 Contributor 分配不计入
+	require.Len(t, policies, 1)
+	assert.Equal(t, "Reader", policies[0].PolicyName)
+	assert.Equal(t, domain.PolicyTypeSystem, policies[0].PolicyType)
+
+	var perms []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(policies[0].PolicyDocument), &perms))
+	assert.Equal(t, []any{"*/read"}, perms[0]["actions"])
+}
+
+func TestAdapter_UpdateUserPermissions(t *testing.T) {
+	adapter, gs, account := newTestAdapter(t)
+
+	err := adapter.UpdateUserPermissions(context.Background(), account, "u-1", []domain.PermissionPolicy{
+		{
+			PolicyID:   testSubPath + "/providers/Microsoft.Authorization/roleDefinitions/" + contributorRoleGUID,
+			PolicyName: "Contributor",
+			Provider:   domain.CloudProviderAzure,
+		},
+	})
+	require.NoError(t, err)
+
+	var puts, deletes []string
+	for _, req := range gs.requests {
+		switch {
+		case strings.HasPrefix(req, http.MethodPut+" "):
+			puts = append(puts, req)
+		case strings.HasPrefix(req, http.MethodDelete+" "):
+			deletes = append(deletes, req)
+		}
+	}
+	// 新增 Contributor，移除订阅级 Reader
+	require.Len(t, puts, 1)
+	assert.Contains(t, puts[0], testSubPath+"/providers/Microsoft.Authorization/roleAssignments/")
+	require.Len(t, deletes, 1)
+	assert.True(t, strings.HasSuffix(deletes[0], "/roleAssignments/ra-1"))
+}
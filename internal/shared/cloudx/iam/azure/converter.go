@@ -0,0 +1,111 @@
+package azure
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+// ConvertGraphUserToCloudUser 转换 Entra ID 用户为 CloudUser 领域模型
+func ConvertGraphUserToCloudUser(u graphUser, account *domain.CloudAccount) *domain.CloudUser {
+	now := time.Now()
+
+	createTime := now
+	if t, err := time.Parse(time.RFC3339, u.CreatedDateTime); err == nil {
+		createTime = t
+	}
+
+	var passwordLastSet *time.Time
+	if t, err := time.Parse(time.RFC3339, u.LastPasswordChange); err == nil {
+		passwordLastSet = &t
+	}
+
+	status := domain.CloudUserStatusActive
+	if !u.AccountEnabled {
+		status = domain.CloudUserStatusInactive
+	}
+
+	email := u.Mail
+	if email == "" && len(u.OtherMails) > 0 {
+		email = u.OtherMails[0]
+	}
+
+	tags := map[string]string{}
+	if u.UserType != "" {
+		tags["userType"] = u.UserType
+	}
+
+	return &domain.CloudUser{
+		Username:       u.UserPrincipalName,
+		UserType:       domain.CloudUserTypeEntraUser,
+		CloudAccountID: account.ID,
+		Provider:       domain.CloudProviderAzure,
+		CloudUserID:    u.ID,
+		DisplayName:    u.DisplayName,
+		Email:          email,
+		Status:         status,
+		TenantID:       account.TenantID,
+		CreateTime:     createTime,
+		UpdateTime:     now,
+		CTime:          createTime.Unix(),
+		UTime:          now.Unix(),
+		Metadata: domain.CloudUserMetadata{
+			PasswordLastSet: passwordLastSet,
+			LastSyncTime:    &now,
+			Tags:            tags,
+		},
+	}
+}
+
+// ConvertGraphGroupToUserGroup 转换 Entra ID 用户组为 UserGroup 领域模型
+func ConvertGraphGroupToUserGroup(g graphGroup, account *domain.CloudAccount) *domain.UserGroup {
+	now := time.Now()
+
+	createTime := now
+	if t, err := time.Parse(time.RFC3339, g.CreatedDateTime); err == nil {
+		createTime = t
+	}
+
+	return &domain.UserGroup{
+		Name:           g.DisplayName,
+		GroupName:      g.DisplayName,
+		DisplayName:    g.DisplayName,
+		Description:    g.Description,
+		CloudAccountID: account.ID,
+		Provider:       domain.CloudProviderAzure,
+		CloudGroupID:   g.ID,
+		CloudPlatforms: []domain.CloudProvider{domain.CloudProviderAzure},
+		TenantID:       account.TenantID,
+		CreateTime:     createTime,
+		UpdateTime:     now,
+		CTime:          createTime.Unix(),
+		UTime:          now.Unix(),
+	}
+}
+
+// ConvertRoleDefinitionToPolicy 转换 RBAC 角色定义为权限策略
+// PolicyID 使用角色定义的完整资源ID，PolicyDocument 为权限列表 JSON
+func ConvertRoleDefinitionToPolicy(rd roleDefinition) domain.PermissionPolicy {
+	document := ""
+	if data, err := json.Marshal(rd.Properties.Permissions); err == nil {
+		document = string(data)
+	}
+
+	return domain.PermissionPolicy{
+		PolicyID:       rd.ID,
+		PolicyName:     rd.Properties.RoleName,
+		PolicyDocument: document,
+		Provider:       domain.CloudProviderAzure,
+		PolicyType:     ConvertRoleType(rd.Properties.Type),
+	}
+}
+
+// ConvertRoleType 转换角色类型: BuiltInRole 为系统策略，CustomRole 为自定义策略
+func ConvertRoleType(roleType string) domain.PolicyType {
+	if strings.EqualFold(roleType, "BuiltInRole") {
+		return domain.PolicyTypeSystem
+	}
+	return domain.PolicyTypeCustom
+}
@@ -0,0 +1,314 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// mailNicknameInvalid mailNickname 不允许的字符
+var mailNicknameInvalid = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// ListGroups 获取 Entra ID 安全组列表
+func (a *Adapter) ListGroups(ctx context.Context, account *domain.CloudAccount) ([]*domain.UserGroup, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := client.ListGraph(ctx, "/v1.0/groups?$top=999&$filter=securityEnabled eq true&$select="+groupSelect)
+	if err != nil {
+		a.logger.Error("list azure groups failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to list entra id groups: %w", err)
+	}
+
+	groups := make([]*domain.UserGroup, 0, len(items))
+	for _, item := range items {
+		var g graphGroup
+		if err := json.Unmarshal(item, &g); err != nil {
+			continue
+		}
+		group := ConvertGraphGroupToUserGroup(g, account)
+
+		policies, err := a.principalPolicies(ctx, client, g.ID)
+		if err != nil {
+			a.logger.Warn("failed to list group policies",
+				elog.String("group_id", g.ID),
+				elog.FieldErr(err))
+		} else {
+			group.Policies = policies
+		}
+
+		groups = append(groups, group)
+	}
+
+	a.logger.Info("list azure groups success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.Int("count", len(groups)))
+
+	return groups, nil
+}
+
+// GetGroup 获取用户组详情
+func (a *Adapter) GetGroup(ctx context.Context, account *domain.CloudAccount, groupID string) (*domain.UserGroup, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var g graphGroup
+	if err := client.DoGraph(ctx, http.MethodGet, "/v1.0/groups/"+url.PathEscape(groupID)+"?$select="+groupSelect, nil, &g); err != nil {
+		a.logger.Error("get azure group failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to get entra id group: %w", err)
+	}
+
+	group := ConvertGraphGroupToUserGroup(g, account)
+
+	policies, err := a.principalPolicies(ctx, client, g.ID)
+	if err != nil {
+		a.logger.Warn("failed to list group policies",
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+	} else {
+		group.Policies = policies
+	}
+
+	members, err := client.ListGraph(ctx, "/v1.0/groups/"+url.PathEscape(groupID)+"/members/microsoft.graph.user?$select=id")
+	if err != nil {
+		a.logger.Warn("failed to list group members",
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+	} else {
+		group.MemberCount = len(members)
+	}
+
+	a.logger.Info("get azure group success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("group_id", groupID))
+
+	return group, nil
+}
+
+// CreateGroup 创建安全组
+func (a *Adapter) CreateGroup(ctx context.Context, account *domain.CloudAccount, req *types.CreateGroupRequest) (*domain.UserGroup, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := req.DisplayName
+	if displayName == "" {
+		displayName = req.GroupName
+	}
+	nickname := mailNicknameInvalid.ReplaceAllString(req.GroupName, "")
+	if nickname == "" {
+		nickname = "group"
+	}
+
+	body := map[string]any{
+		"displayName":     displayName,
+		"mailNickname":    nickname,
+		"mailEnabled":     false,
+		"securityEnabled": true,
+	}
+	if req.Description != "" {
+		body["description"] = req.Description
+	}
+
+	var created graphGroup
+	if err := client.DoGraph(ctx, http.MethodPost, "/v1.0/groups", body, &created); err != nil {
+		a.logger.Error("create azure group failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_name", req.GroupName),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to create entra id group: %w", err)
+	}
+
+	a.logger.Info("create azure group success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("group_id", created.ID))
+
+	return ConvertGraphGroupToUserGroup(created, account), nil
+}
+
+// UpdateGroupPolicies 更新用户组的订阅级别角色分配
+func (a *Adapter) UpdateGroupPolicies(ctx context.Context, account *domain.CloudAccount, groupID string, policies []domain.PermissionPolicy) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	attached, detached, err := a.syncRoleAssignments(ctx, client, groupID, "Group", policies)
+	if err != nil {
+		a.logger.Error("update azure group policies failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+		return err
+	}
+
+	a.logger.Info("update group policies success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("group_id", groupID),
+		elog.Int("attached", attached),
+		elog.Int("detached", detached))
+
+	return nil
+}
+
+// DeleteGroup 删除用户组
+func (a *Adapter) DeleteGroup(ctx context.Context, account *domain.CloudAccount, groupID string) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	// 先解除角色分配，避免残留孤立的分配记录
+	if _, _, err := a.syncRoleAssignments(ctx, client, groupID, "Group", nil); err != nil {
+		a.logger.Warn("failed to detach group role assignments",
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+	}
+
+	if err := client.DoGraph(ctx, http.MethodDelete, "/v1.0/groups/"+url.PathEscape(groupID), nil, nil); err != nil {
+		a.logger.Error("delete azure group failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+		return fmt.Errorf("failed to delete entra id group: %w", err)
+	}
+
+	a.logger.Info("delete azure group success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("group_id", groupID))
+
+	return nil
+}
+
+// ListGroupUsers 获取用户组成员列表 (仅用户类型成员)
+func (a *Adapter) ListGroupUsers(ctx context.Context, account *domain.CloudAccount, groupID string) ([]*domain.CloudUser, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := client.ListGraph(ctx, "/v1.0/groups/"+url.PathEscape(groupID)+"/members/microsoft.graph.user?$top=999&$select="+userSelect)
+	if err != nil {
+		a.logger.Error("list azure group users failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to list entra id group members: %w", err)
+	}
+
+	users := make([]*domain.CloudUser, 0, len(items))
+	for _, item := range items {
+		var u graphUser
+		if err := json.Unmarshal(item, &u); err != nil {
+			continue
+		}
+		users = append(users, ConvertGraphUserToCloudUser(u, account))
+	}
+
+	a.logger.Info("list azure group users success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("group_id", groupID),
+		elog.Int("count", len(users)))
+
+	return users, nil
+}
+
+// AddUserToGroup 将用户添加到用户组
+func (a *Adapter) AddUserToGroup(ctx context.Context, account *domain.CloudAccount, groupID string, userID string) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{
+		"@odata.id": strings.TrimRight(a.endpoints.Graph, "/") + "/v1.0/directoryObjects/" + url.PathEscape(userID),
+	}
+	if err := client.DoGraph(ctx, http.MethodPost, "/v1.0/groups/"+url.PathEscape(groupID)+"/members/$ref", body, nil); err != nil {
+		a.logger.Error("add user to azure group failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return fmt.Errorf("failed to add user to entra id group: %w", err)
+	}
+
+	a.logger.Info("add user to azure group success",
+		elog.String("group_id", groupID),
+		elog.String("user_id", userID))
+
+	return nil
+}
+
+// RemoveUserFromGroup 将用户从用户组移除
+func (a *Adapter) RemoveUserFromGroup(ctx context.Context, account *domain.CloudAccount, groupID string, userID string) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	path := "/v1.0/groups/" + url.PathEscape(groupID) + "/members/" + url.PathEscape(userID) + "/$ref"
+	if err := client.DoGraph(ctx, http.MethodDelete, path, nil, nil); err != nil {
+		a.logger.Error("remove user from azure group failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return fmt.Errorf("failed to remove user from entra id group: %w", err)
+	}
+
+	a.logger.Info("remove user from azure group success",
+		elog.String("group_id", groupID),
+		elog.String("user_id", userID))
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package azure
+
+// CreateUserParams Azure Entra ID 创建用户参数
+// Username 不含 "@" 时自动拼接租户默认域名作为 userPrincipalName
+type CreateUserParams struct {
+	Username    string
+	DisplayName string
+	Email       string
+}
+
+// graphUser Graph 用户对象
+type graphUser struct {
+	ID                 string   `json:"id"`
+	UserPrincipalName  string   `json:"userPrincipalName"`
+	DisplayName        string   `json:"displayName"`
+	Mail               string   `json:"mail"`
+	AccountEnabled     bool     `json:"accountEnabled"`
+	UserType           string   `json:"userType"`
+	CreatedDateTime    string   `json:"createdDateTime"`
+	LastPasswordChange string   `json:"lastPasswordChangeDateTime"`
+	OtherMails         []string `json:"otherMails"`
+}
+
+// graphGroup Graph 用户组对象
+type graphGroup struct {
+	ID              string `json:"id"`
+	DisplayName     string `json:"displayName"`
+	Description     string `json:"description"`
+	MailNickname    string `json:"mailNickname"`
+	SecurityEnabled bool   `json:"securityEnabled"`
+	CreatedDateTime string `json:"createdDateTime"`
+}
+
+// roleDefinition Azure RBAC 角色定义
+type roleDefinition struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		RoleName    string `json:"roleName"`
+		Description string `json:"description"`
+		Type        string `json:"type"`
+		Permissions []struct {
+			Actions    []string `json:"actions"`
+			NotActions []string `json:"notActions"`
+		} `json:"permissions"`
+	} `json:"properties"`
+}
+
+// roleAssignment Azure RBAC 角色分配
+type roleAssignment struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		RoleDefinitionID string `json:"roleDefinitionId"`
+		PrincipalID      string `json:"principalId"`
+		PrincipalType    string `json:"principalType"`
+		Scope            string `json:"scope"`
+	} `json:"properties"`
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/aliyun"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/aws"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/azure"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/gcp"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/huawei"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/tencent"
 	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/iam/volcano"
@@ -49,6 +51,10 @@ func (f *adapterFactory) CreateAdapter(provider domain.CloudProvider) (CloudIAMA
 		adapter, err = f.createAliyunAdapter()
 	case domain.CloudProviderAWS:
 		adapter, err = f.createAWSAdapter()
+	case domain.CloudProviderAzure:
+		adapter, err = f.createAzureAdapter()
+	case domain.CloudProviderGCP:
+		adapter, err = f.createGCPAdapter()
 	case domain.CloudProviderHuawei:
 		adapter, err = f.createHuaweiAdapter()
 	case domain.CloudProviderTencent:
@@ -81,6 +87,16 @@ func (f *adapterFactory) createAWSAdapter() (CloudIAMAdapter, error) {
 	return aws.NewAdapterWrapper(adapter), nil
 }
 
+func (f *adapterFactory) createAzureAdapter() (CloudIAMAdapter, error) {
+	adapter := azure.NewAdapter(f.logger)
+	return azure.NewAdapterWrapper(adapter), nil
+}
+
+func (f *adapterFactory) createGCPAdapter() (CloudIAMAdapter, error) {
+	adapter := gcp.NewAdapter(f.logger)
+	return gcp.NewAdapterWrapper(adapter), nil
+}
+
 func (f *adapterFactory) createHuaweiAdapter() (CloudIAMAdapter, error) {
 	adapter := huawei.NewAdapter(f.logger)
 	return huawei.NewAdapterWrapper(adapter), nil
@@ -0,0 +1,491 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// memberServiceAccount IAM 绑定中服务账号成员前缀
+	memberServiceAccount = "serviceAccount:"
+	// memberGroup IAM 绑定中 Google 群组成员前缀
+	memberGroup = "group:"
+
+	// policyVersion 读取 IAM 策略时请求的版本，带条件的绑定需要版本 3
+	policyVersion = 3
+	// maxPolicyWriteAttempts etag 冲突时写入 IAM 策略的最大尝试次数
+	maxPolicyWriteAttempts = 3
+)
+
+// Adapter GCP IAM 适配器
+// 用户对应项目下的服务账号，权限策略对应 IAM 角色，授权通过项目 IAM 策略中的角色绑定完成
+type Adapter struct {
+	logger      *elog.Component
+	rateLimiter *gcpcommon.RateLimiter
+	endpoints   gcpcommon.Endpoints
+}
+
+// NewAdapter 创建 GCP IAM 适配器实例
+func NewAdapter(logger *elog.Component) *Adapter {
+	return &Adapter{
+		logger:      logger,
+		rateLimiter: gcpcommon.NewRateLimiter(10), // 10 QPS
+		endpoints:   gcpcommon.DefaultEndpoints,
+	}
+}
+
+// newClient 根据云账号创建 GCP REST 客户端
+func (a *Adapter) newClient(account *domain.CloudAccount) (*gcpcommon.Client, error) {
+	if account == nil {
+		return nil, fmt.Errorf("cloud account cannot be nil")
+	}
+	cred, err := gcpcommon.ParseCredential(account.AccessKeyID, account.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	return gcpcommon.NewClient(cred, a.endpoints), nil
+}
+
+// ValidateCredentials 验证凭证
+func (a *Adapter) ValidateCredentials(ctx context.Context, account *domain.CloudAccount) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"pageSize": []string{"1"}}
+	if err := client.Do(ctx, http.MethodGet, gcpcommon.ServiceIAM, serviceAccountsPath(client), query, nil, nil); err != nil {
+		a.logger.Error("validate gcp credentials failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.FieldErr(err))
+		return fmt.Errorf("invalid gcp credentials: %w", err)
+	}
+
+	a.logger.Info("validate gcp credentials success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)))
+
+	return nil
+}
+
+// ListUsers 获取项目服务账号列表
+func (a *Adapter) ListUsers(ctx context.Context, account *domain.CloudAccount) ([]*domain.CloudUser, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := client.List(ctx, gcpcommon.ServiceIAM, serviceAccountsPath(client), nil, "accounts")
+	if err != nil {
+		a.logger.Error("list gcp service accounts failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to list gcp service accounts: %w", err)
+	}
+
+	users := make([]*domain.CloudUser, 0, len(items))
+	for _, item := range items {
+		var sa serviceAccount
+		if err := json.Unmarshal(item, &sa); err != nil {
+			continue
+		}
+		users = append(users, ConvertServiceAccountToCloudUser(sa, account))
+	}
+
+	a.logger.Info("list gcp service accounts success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.Int("count", len(users)))
+
+	return users, nil
+}
+
+// GetUser 获取服务账号详情，userID 可以是邮箱或 uniqueId
+func (a *Adapter) GetUser(ctx context.Context, account *domain.CloudAccount, userID string) (*domain.CloudUser, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	sa, err := a.getServiceAccount(ctx, client, userID)
+	if err != nil {
+		a.logger.Error("get gcp service account failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return nil, err
+	}
+
+	return ConvertServiceAccountToCloudUser(*sa, account), nil
+}
+
+// CreateUser 创建服务账号
+func (a *Adapter) CreateUser(ctx context.Context, account *domain.CloudAccount, params *CreateUserParams) (*domain.CloudUser, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID := strings.ToLower(accountIDFromEmail(params.Username))
+	displayName := params.DisplayName
+	if displayName == "" {
+		displayName = accountID
+	}
+
+	sa := map[string]any{"displayName": displayName}
+	if params.Email != "" {
+		sa["description"] = params.Email
+	}
+	body := map[string]any{
+		"accountId":      accountID,
+		"serviceAccount": sa,
+	}
+
+	var created serviceAccount
+	if err := client.Do(ctx, http.MethodPost, gcpcommon.ServiceIAM, serviceAccountsPath(client), nil, body, &created); err != nil {
+		a.logger.Error("create gcp service account failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("username", accountID),
+			elog.FieldErr(err))
+		return nil, fmt.Errorf("failed to create gcp service account: %w", err)
+	}
+
+	a.logger.Info("create gcp service account success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("email", created.Email))
+
+	return ConvertServiceAccountToCloudUser(created, account), nil
+}
+
+// DeleteUser 删除服务账号 (先移除项目 IAM 策略中的角色绑定)
+func (a *Adapter) DeleteUser(ctx context.Context, account *domain.CloudAccount, userID string) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	sa, err := a.getServiceAccount(ctx, client, userID)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := a.syncBindings(ctx, client, memberServiceAccount+sa.Email, nil); err != nil {
+		a.logger.Warn("failed to remove service account bindings",
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+	}
+
+	if err := client.Do(ctx, http.MethodDelete, gcpcommon.ServiceIAM, serviceAccountPath(client, sa.Email), nil, nil, nil); err != nil {
+		a.logger.Error("delete gcp service account failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return fmt.Errorf("failed to delete gcp service account: %w", err)
+	}
+
+	a.logger.Info("delete gcp service account success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("user_id", userID))
+
+	return nil
+}
+
+// UpdateUserPermissions 更新服务账号在项目 IAM 策略中的角色绑定
+func (a *Adapter) UpdateUserPermissions(ctx context.Context, account *domain.CloudAccount, userID string, policies []domain.PermissionPolicy) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	sa, err := a.getServiceAccount(ctx, client, userID)
+	if err != nil {
+		return err
+	}
+
+	attached, detached, err := a.syncBindings(ctx, client, memberServiceAccount+sa.Email, policies)
+	if err != nil {
+		a.logger.Error("update gcp user permissions failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("user_id", userID),
+			elog.FieldErr(err))
+		return err
+	}
+
+	a.logger.Info("update user permissions success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("user_id", userID),
+		elog.Int("attached", attached),
+		elog.Int("detached", detached))
+
+	return nil
+}
+
+// ListPolicies 获取预定义角色与项目自定义角色
+func (a *Adapter) ListPolicies(ctx context.Context, account *domain.CloudAccount) ([]domain.PermissionPolicy, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []domain.PermissionPolicy
+	for _, path := range []string{"/v1/roles", "/v1/projects/" + client.ProjectID() + "/roles"} {
+		items, err := client.List(ctx, gcpcommon.ServiceIAM, path, url.Values{"pageSize": []string{"1000"}}, "roles")
+		if err != nil {
+			a.logger.Error("list gcp roles failed",
+				elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+				elog.String("path", path),
+				elog.FieldErr(err))
+			return nil, fmt.Errorf("failed to list gcp roles: %w", err)
+		}
+		for _, item := range items {
+			var role iamRole
+			if err := json.Unmarshal(item, &role); err != nil || role.Deleted {
+				continue
+			}
+			policies = append(policies, ConvertRoleToPolicy(role))
+		}
+	}
+
+	a.logger.Info("list gcp policies success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.Int("count", len(policies)))
+
+	return policies, nil
+}
+
+// GetPolicy 获取角色详情，policyID 为角色名称
+func (a *Adapter) GetPolicy(ctx context.Context, account *domain.CloudAccount, policyID string) (*domain.PermissionPolicy, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	var role iamRole
+	if err := client.Do(ctx, http.MethodGet, gcpcommon.ServiceIAM, "/v1/"+strings.TrimPrefix(policyID, "/"), nil, nil, &role); err != nil {
+		return nil, fmt.Errorf("failed to get gcp role: %w", err)
+	}
+	policy := ConvertRoleToPolicy(role)
+	return &policy, nil
+}
+
+// GetUserPolicies 获取服务账号在项目 IAM 策略中直接绑定的角色
+func (a *Adapter) GetUserPolicies(ctx context.Context, account *domain.CloudAccount, userID string) ([]domain.PermissionPolicy, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	sa, err := a.getServiceAccount(ctx, client, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := a.getIAMPolicy(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return memberPolicies(policy, memberServiceAccount+sa.Email), nil
+}
+
+// getServiceAccount 获取服务账号
+func (a *Adapter) getServiceAccount(ctx context.Context, client *gcpcommon.Client, userID string) (*serviceAccount, error) {
+	var sa serviceAccount
+	if err := client.Do(ctx, http.MethodGet, gcpcommon.ServiceIAM, serviceAccountPath(client, userID), nil, nil, &sa); err != nil {
+		return nil, fmt.Errorf("failed to get gcp service account: %w", err)
+	}
+	return &sa, nil
+}
+
+// getIAMPolicy 读取项目 IAM 策略
+func (a *Adapter) getIAMPolicy(ctx context.Context, client *gcpcommon.Client) (*iamPolicy, error) {
+	body := map[string]any{
+		"options": map[string]any{"requestedPolicyVersion": policyVersion},
+	}
+	var policy iamPolicy
+	path := "/v1/projects/" + client.ProjectID() + ":getIamPolicy"
+	if err := client.Do(ctx, http.MethodPost, gcpcommon.ServiceCloudResourceManager, path, nil, body, &policy); err != nil {
+		return nil, fmt.Errorf("failed to get gcp project iam policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// setIAMPolicy 写回项目 IAM 策略，etag 不一致时由服务端拒绝
+func (a *Adapter) setIAMPolicy(ctx context.Context, client *gcpcommon.Client, policy *iamPolicy) error {
+	if policy.Version < policyVersion {
+		policy.Version = policyVersion
+	}
+	body := map[string]any{"policy": policy}
+	path := "/v1/projects/" + client.ProjectID() + ":setIamPolicy"
+	return client.Do(ctx, http.MethodPost, gcpcommon.ServiceCloudResourceManager, path, nil, body, nil)
+}
+
+// syncBindings 将成员在项目 IAM 策略中的角色绑定同步为目标策略集合
+// 仅管理无条件绑定，带条件的绑定保持不变；etag 冲突时重新读取策略后重试
+func (a *Adapter) syncBindings(ctx context.Context, client *gcpcommon.Client, member string, policies []domain.PermissionPolicy) (int, int, error) {
+	target := make(map[string]struct{})
+	for _, p := range policies {
+		if p.Provider == domain.CloudProviderGCP && p.PolicyID != "" {
+			target[p.PolicyID] = struct{}{}
+		}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxPolicyWriteAttempts; attempt++ {
+		policy, err := a.getIAMPolicy(ctx, client)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		attached, detached := applyBindings(policy, member, target)
+		if attached == 0 && detached == 0 {
+			return 0, 0, nil
+		}
+
+		lastErr = a.setIAMPolicy(ctx, client, policy)
+		if lastErr == nil {
+			return attached, detached, nil
+		}
+		if !isConcurrentModification(lastErr) {
+			break
+		}
+		a.logger.Warn("gcp iam policy etag conflict, retrying",
+			elog.String("member", member),
+			elog.Int("attempt", attempt+1))
+	}
+	return 0, 0, fmt.Errorf("failed to set gcp project iam policy: %w", lastErr)
+}
+
+// applyBindings 在策略中为成员增删角色绑定，返回新增与移除的数量
+func applyBindings(policy *iamPolicy, member string, target map[string]struct{}) (int, int) {
+	current := make(map[string]struct{})
+	detached := 0
+	bindings := make([]iamBinding, 0, len(policy.Bindings))
+	for _, b := range policy.Bindings {
+		if len(b.Condition) == 0 && containsMember(b.Members, member) {
+			current[b.Role] = struct{}{}
+			if _, keep := target[b.Role]; !keep {
+				b.Members = removeMember(b.Members, member)
+				detached++
+			}
+		}
+		if len(b.Members) > 0 {
+			bindings = append(bindings, b)
+		}
+	}
+
+	attached := 0
+	for role := range target {
+		if _, ok := current[role]; ok {
+			continue
+		}
+		attached++
+		added := false
+		for i := range bindings {
+			if bindings[i].Role == role && len(bindings[i].Condition) == 0 {
+				bindings[i].Members = append(bindings[i].Members, member)
+				added = true
+				break
+			}
+		}
+		if !added {
+			bindings = append(bindings, iamBinding{Role: role, Members: []string{member}})
+		}
+	}
+
+	policy.Bindings = bindings
+	return attached, detached
+}
+
+// memberPolicies 获取成员在无条件绑定中的角色
+func memberPolicies(policy *iamPolicy, member string) []domain.PermissionPolicy {
+	policies := []domain.PermissionPolicy{}
+	for _, b := range policy.Bindings {
+		if len(b.Condition) == 0 && containsMember(b.Members, member) {
+			policies = append(policies, ConvertRoleToPolicy(iamRole{Name: b.Role}))
+		}
+	}
+	return policies
+}
+
+// containsMember 判断成员列表是否包含成员 (邮箱不区分大小写)
+func containsMember(members []string, member string) bool {
+	for _, m := range members {
+		if strings.EqualFold(m, member) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeMember 从成员列表中移除成员
+func removeMember(members []string, member string) []string {
+	result := make([]string, 0, len(members))
+	for _, m := range members {
+		if !strings.EqualFold(m, member) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// isConcurrentModification 判断是否为 IAM 策略 etag 冲突
+func isConcurrentModification(err error) bool {
+	var respErr *gcpcommon.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusConflict || respErr.Status == "ABORTED"
+	}
+	return false
+}
+
+// serviceAccountsPath 服务账号列表路径
+func serviceAccountsPath(client *gcpcommon.Client) string {
+	return "/v1/projects/" + client.ProjectID() + "/serviceAccounts"
+}
+
+// serviceAccountPath 单个服务账号路径，标识可以是邮箱或 uniqueId
+func serviceAccountPath(client *gcpcommon.Client, id string) string {
+	return serviceAccountsPath(client) + "/" + url.PathEscape(id)
+}
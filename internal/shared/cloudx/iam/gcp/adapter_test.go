@@ -0,0 +1,221 @@
+package gcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	gcpcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/gcp"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProjectID = "test-project"
+	testSAEmail   = "app@test-project.iam.gserviceaccount.com"
+
+	iamPolicyJSON = `{
+		"version": 3,
+		"etag": "BwXhqDJ2Zw4=",
+		"bindings": [
+			{"role": "roles/viewer", "members": ["serviceAccount:` + testSAEmail + `", "group:devops@example.com"]},
+			{"role": "roles/storage.admin", "members": ["serviceAccount:` + testSAEmail + `"],
+			 "condition": {"title": "expires", "expression": "request.time < timestamp('2030-01-01T00:00:00Z')"}},
+			{"role": "projects/test-project/roles/deployer", "members": ["group:DevOps@example.com", "user:alice@example.com"]}
+		]
+	}`
+)
+
+// iamServer 模拟 IAM 与 Cloud Resource Manager API
+type iamServer struct {
+	mu        sync.Mutex
+	conflicts int
+	setBodies []map[string]any
+}
+
+func (s *iamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/token":
+		_, _ = w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/iam/v1/projects/test-project/serviceAccounts" && r.URL.Query().Get("pageToken") == "2":
+		_, _ = w.Write([]byte(`{"accounts":[
+			{"name":"projects/test-project/serviceAccounts/old@test-project.iam.gserviceaccount.com","email":"old@test-project.iam.gserviceaccount.com","uniqueId":"102","disabled":true}
+		]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/iam/v1/projects/test-project/serviceAccounts":
+		_, _ = w.Write([]byte(`{"accounts":[
+			{"name":"projects/test-project/serviceAccounts/` + testSAEmail + `","projectId":"test-project","uniqueId":"101","email":"` + testSAEmail + `","displayName":"App runtime"}
+		],"nextPageToken":"2"}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/iam/v1/projects/test-project/serviceAccounts/"+testSAEmail:
+		_, _ = w.Write([]byte(`{"name":"projects/test-project/serviceAccounts/` + testSAEmail + `","uniqueId":"101","email":"` + testSAEmail + `"}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/cloudresourcemanager/v1/projects/test-project:getIamPolicy":
+		_, _ = w.Write([]byte(iamPolicyJSON))
+	case r.Method == http.MethodPost && r.URL.Path == "/cloudresourcemanager/v1/projects/test-project:setIamPolicy":
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(data, &body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.setBodies = append(s.setBodies, body)
+		if s.conflicts > 0 {
+			s.conflicts--
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":{"code":409,"message":"There were concurrent policy changes.","status":"ABORTED"}}`))
+			return
+		}
+		_, _ = w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"code":404,"message":"not recorded","status":"NOT_FOUND"}}`))
+	}
+}
+
+// testServiceAccountKey 生成测试用服务账号 JSON 密钥
+func testServiceAccountKey(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	data, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   testProjectID,
+		"private_key":  string(keyPEM),
+		"client_email": "cam-admin@test-project.iam.gserviceaccount.com",
+	})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func newTestAdapter(t *testing.T) (*Adapter, *iamServer, *domain.CloudAccount) {
+	t.Helper()
+	is := &iamServer{}
+	srv := httptest.NewServer(is)
+	t.Cleanup(srv.Close)
+
+	adapter := NewAdapter(elog.DefaultLogger)
+	adapter.endpoints = gcpcommon.Endpoints{Token: srv.URL + "/token", API: srv.URL}
+
+	account := &domain.CloudAccount{
+		ID:              9,
+		TenantID:        "tenant-a",
+		AccessKeyID:     testProjectID,
+		AccessKeySecret: testServiceAccountKey(t),
+	}
+	return adapter, is, account
+}
+
+// policyBindings 解析 setIamPolicy 请求中的绑定为 role -> members
+func policyBindings(t *testing.T, body map[string]any) map[string][]any {
+	t.Helper()
+	policy, ok := body["policy"].(map[string]any)
+	require.True(t, ok)
+	bindings := map[string][]any{}
+	for _, raw := range policy["bindings"].([]any) {
+		b := raw.(map[string]any)
+		if _, conditional := b["condition"]; conditional {
+			continue
+		}
+		bindings[b["role"].(string)] = b["members"].([]any)
+	}
+	return bindings
+}
+
+func TestAdapter_ListUsers(t *testing.T) {
+	adapter, _, account := newTestAdapter(t)
+
+	users, err := adapter.ListUsers(context.Background(), account)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	u := users[0]
+	assert.Equal(t, testSAEmail, u.Username)
+	assert.Equal(t, testSAEmail, u.CloudUserID)
+	assert.Equal(t, "App runtime", u.DisplayName)
+	assert.Equal(t, domain.CloudUserTypeServiceAccount, u.UserType)
+	assert.Equal(t, domain.CloudProviderGCP, u.Provider)
+	assert.Equal(t, domain.CloudUserStatusActive, u.Status)
+	assert.Equal(t, "tenant-a", u.TenantID)
+
+	// 已禁用的服务账号，显示名回退为 accountId
+	assert.Equal(t, domain.CloudUserStatusInactive, users[1].Status)
+	assert.Equal(t, "old", users[1].DisplayName)
+}
+
+func TestAdapter_GetUserPolicies_UnconditionalOnly(t *testing.T) {
+	adapter, _, account := newTestAdapter(t)
+
+	policies, err := adapter.GetUserPolicies(context.Background(), account, testSAEmail)
+	require.NoError(t, err)
+	// 带条件的 storage.admin 绑定不计入
+	require.Len(t, policies, 1)
+	assert.Equal(t, "roles/viewer", policies[0].PolicyID)
+	assert.Equal(t, domain.PolicyTypeSystem, policies[0].PolicyType)
+}
+
+func TestAdapter_UpdateUserPermissions(t *testing.T) {
+	adapter, is, account := newTestAdapter(t)
+	is.conflicts = 1
+
+	err := adapter.UpdateUserPermissions(context.Background(), account, testSAEmail, []domain.PermissionPolicy{
+		{PolicyID: "projects/test-project/roles/deployer", Provider: domain.CloudProviderGCP},
+		{PolicyID: "roles/owner", Provider: domain.CloudProviderAWS},
+	})
+	require.NoError(t, err)
+
+	// 第一次写入 etag 冲突，重新读取后重试成功
+	require.Len(t, is.setBodies, 2)
+	policy := is.setBodies[1]["policy"].(map[string]any)
+	assert.Equal(t, "BwXhqDJ2Zw4=", policy["etag"])
+	assert.Equal(t, float64(3), policy["version"])
+
+	bindings := policyBindings(t, is.setBodies[1])
+	assert.Equal(t, []any{"group:devops@example.com"}, bindings["roles/viewer"])
+	assert.Contains(t, bindings["projects/test-project/roles/deployer"], "serviceAccount:"+testSAEmail)
+	assert.NotContains(t, bindings, "roles/owner")
+
+	// 带条件的绑定原样保留
+	raw := policy["bindings"].([]any)
+	conditional := 0
+	for _, b := range raw {
+		if _, ok := b.(map[string]any)["condition"]; ok {
+			conditional++
+		}
+	}
+	assert.Equal(t, 1, conditional)
+}
+
+func TestAdapter_Groups(t *testing.T) {
+	ctx := context.Background()
+	adapter, is, account := newTestAdapter(t)
+
+	groups, err := adapter.ListGroups(ctx, account)
+	require.NoError(t, err)
+	// 群组邮箱不区分大小写去重
+	require.Len(t, groups, 1)
+	g := groups[0]
+	assert.Equal(t, "devops@example.com", g.CloudGroupID)
+	assert.Equal(t, "devops", g.GroupName)
+	require.Len(t, g.Policies, 2)
+	assert.Equal(t, domain.PolicyTypeCustom, g.Policies[1].PolicyType)
+
+	_, err = adapter.CreateGroup(ctx, account, nil)
+	assert.ErrorIs(t, err, ErrGroupMembershipNotSupported)
+	assert.ErrorIs(t, adapter.AddUserToGroup(ctx, account, g.CloudGroupID, testSAEmail), ErrGroupMembershipNotSupported)
+
+	// 删除用户组即移除其全部角色绑定
+	require.NoError(t, adapter.DeleteGroup(ctx, account, g.CloudGroupID))
+	require.Len(t, is.setBodies, 1)
+	bindings := policyBindings(t, is.setBodies[0])
+	assert.Equal(t, []any{"serviceAccount:" + testSAEmail}, bindings["roles/viewer"])
+	assert.Equal(t, []any{"user:alice@example.com"}, bindings["projects/test-project/roles/deployer"])
+}
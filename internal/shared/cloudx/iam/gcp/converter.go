@@ -0,0 +1,113 @@
+package gcp
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+// ConvertServiceAccountToCloudUser 转换服务账号为 CloudUser 领域模型
+// CloudUserID 使用服务账号邮箱，IAM 绑定中的成员标识同样基于邮箱
+func ConvertServiceAccountToCloudUser(sa serviceAccount, account *domain.CloudAccount) *domain.CloudUser {
+	now := time.Now()
+
+	status := domain.CloudUserStatusActive
+	if sa.Disabled {
+		status = domain.CloudUserStatusInactive
+	}
+
+	displayName := sa.DisplayName
+	if displayName == "" {
+		displayName = accountIDFromEmail(sa.Email)
+	}
+
+	tags := map[string]string{}
+	if sa.UniqueID != "" {
+		tags["uniqueId"] = sa.UniqueID
+	}
+	if sa.ProjectID != "" {
+		tags["projectId"] = sa.ProjectID
+	}
+
+	return &domain.CloudUser{
+		Username:       sa.Email,
+		UserType:       domain.CloudUserTypeServiceAccount,
+		CloudAccountID: account.ID,
+		Provider:       domain.CloudProviderGCP,
+		CloudUserID:    sa.Email,
+		DisplayName:    displayName,
+		Email:          sa.Email,
+		Status:         status,
+		TenantID:       account.TenantID,
+		CreateTime:     now,
+		UpdateTime:     now,
+		CTime:          now.Unix(),
+		UTime:          now.Unix(),
+		Metadata: domain.CloudUserMetadata{
+			LastSyncTime: &now,
+			Tags:         tags,
+		},
+	}
+}
+
+// ConvertGroupToUserGroup 转换 IAM 策略中的 Google 群组为 UserGroup 领域模型
+func ConvertGroupToUserGroup(email string, policies []domain.PermissionPolicy, account *domain.CloudAccount) *domain.UserGroup {
+	now := time.Now()
+	name := accountIDFromEmail(email)
+
+	return &domain.UserGroup{
+		Name:           name,
+		GroupName:      name,
+		DisplayName:    email,
+		CloudAccountID: account.ID,
+		Provider:       domain.CloudProviderGCP,
+		CloudGroupID:   email,
+		CloudPlatforms: []domain.CloudProvider{domain.CloudProviderGCP},
+		Policies:       policies,
+		TenantID:       account.TenantID,
+		CreateTime:     now,
+		UpdateTime:     now,
+		CTime:          now.Unix(),
+		UTime:          now.Unix(),
+	}
+}
+
+// ConvertRoleToPolicy 转换 IAM 角色为权限策略
+// PolicyID 使用角色名称 (roles/xxx 或 projects/{p}/roles/xxx)，PolicyDocument 为权限列表 JSON
+func ConvertRoleToPolicy(role iamRole) domain.PermissionPolicy {
+	document := ""
+	if len(role.IncludedPermissions) > 0 {
+		if data, err := json.Marshal(role.IncludedPermissions); err == nil {
+			document = string(data)
+		}
+	}
+
+	name := role.Title
+	if name == "" {
+		name = role.Name
+	}
+
+	return domain.PermissionPolicy{
+		PolicyID:       role.Name,
+		PolicyName:     name,
+		PolicyDocument: document,
+		Provider:       domain.CloudProviderGCP,
+		PolicyType:     ConvertRoleType(role.Name),
+	}
+}
+
+// ConvertRoleType 转换角色类型: roles/ 前缀为预定义角色，其余为自定义角色
+func ConvertRoleType(roleName string) domain.PolicyType {
+	if strings.HasPrefix(roleName, "roles/") {
+		return domain.PolicyTypeSystem
+	}
+	return domain.PolicyTypeCustom
+}
+
+// accountIDFromEmail 取邮箱 @ 之前的部分
+func accountIDFromEmail(email string) string {
+	name, _, _ := strings.Cut(email, "@")
+	return name
+}
@@ -0,0 +1,167 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/types"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+// 用户组对应项目 IAM 策略中以 group: 出现的 Google 群组，CloudGroupID 为群组邮箱
+// 群组本身及其成员由 Cloud Identity 管理，此处仅维护群组在项目上的角色绑定
+
+// ListGroups 获取项目 IAM 策略中绑定的 Google 群组
+func (a *Adapter) ListGroups(ctx context.Context, account *domain.CloudAccount) ([]*domain.UserGroup, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := a.getIAMPolicy(ctx, client)
+	if err != nil {
+		a.logger.Error("list gcp groups failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.FieldErr(err))
+		return nil, err
+	}
+
+	emails := groupEmails(policy)
+	groups := make([]*domain.UserGroup, 0, len(emails))
+	for _, email := range emails {
+		groups = append(groups, ConvertGroupToUserGroup(email, memberPolicies(policy, memberGroup+email), account))
+	}
+
+	a.logger.Info("list gcp groups success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.Int("count", len(groups)))
+
+	return groups, nil
+}
+
+// GetGroup 获取用户组详情，groupID 为群组邮箱
+func (a *Adapter) GetGroup(ctx context.Context, account *domain.CloudAccount, groupID string) (*domain.UserGroup, error) {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := a.getIAMPolicy(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, email := range groupEmails(policy) {
+		if strings.EqualFold(email, groupID) {
+			return ConvertGroupToUserGroup(email, memberPolicies(policy, memberGroup+email), account), nil
+		}
+	}
+	return nil, fmt.Errorf("gcp group %s has no binding in project %s", groupID, client.ProjectID())
+}
+
+// CreateGroup 创建用户组 (Google 群组需在 Cloud Identity 中创建)
+func (a *Adapter) CreateGroup(ctx context.Context, account *domain.CloudAccount, req *types.CreateGroupRequest) (*domain.UserGroup, error) {
+	return nil, ErrGroupMembershipNotSupported
+}
+
+// UpdateGroupPolicies 更新群组在项目 IAM 策略中的角色绑定
+func (a *Adapter) UpdateGroupPolicies(ctx context.Context, account *domain.CloudAccount, groupID string, policies []domain.PermissionPolicy) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	attached, detached, err := a.syncBindings(ctx, client, memberGroup+groupID, policies)
+	if err != nil {
+		a.logger.Error("update gcp group policies failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+		return err
+	}
+
+	a.logger.Info("update group policies success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("group_id", groupID),
+		elog.Int("attached", attached),
+		elog.Int("detached", detached))
+
+	return nil
+}
+
+// DeleteGroup 删除用户组，即移除群组在项目上的全部角色绑定
+func (a *Adapter) DeleteGroup(ctx context.Context, account *domain.CloudAccount, groupID string) error {
+	if err := a.rateLimiter.Wait(ctx); err != nil {
+		return fmt.Errorf("rate limit wait failed: %w", err)
+	}
+
+	client, err := a.newClient(account)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := a.syncBindings(ctx, client, memberGroup+groupID, nil); err != nil {
+		a.logger.Error("delete gcp group bindings failed",
+			elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+			elog.String("group_id", groupID),
+			elog.FieldErr(err))
+		return err
+	}
+
+	a.logger.Info("delete gcp group bindings success",
+		elog.String("account_id", fmt.Sprintf("%d", account.ID)),
+		elog.String("group_id", groupID))
+
+	return nil
+}
+
+// ListGroupUsers 获取用户组成员列表
+// 群组成员需通过 Cloud Identity 查询，项目级凭证无法获取，返回空列表
+func (a *Adapter) ListGroupUsers(ctx context.Context, account *domain.CloudAccount, groupID string) ([]*domain.CloudUser, error) {
+	a.logger.Debug("gcp group members are managed by cloud identity, skip",
+		elog.String("group_id", groupID))
+	return []*domain.CloudUser{}, nil
+}
+
+// AddUserToGroup 将用户添加到用户组
+func (a *Adapter) AddUserToGroup(ctx context.Context, account *domain.CloudAccount, groupID string, userID string) error {
+	return ErrGroupMembershipNotSupported
+}
+
+// RemoveUserFromGroup 将用户从用户组移除
+func (a *Adapter) RemoveUserFromGroup(ctx context.Context, account *domain.CloudAccount, groupID string, userID string) error {
+	return ErrGroupMembershipNotSupported
+}
+
+// groupEmails 从 IAM 策略中提取所有 Google 群组邮箱 (去重并排序)
+func groupEmails(policy *iamPolicy) []string {
+	seen := make(map[string]struct{})
+	for _, b := range policy.Bindings {
+		for _, m := range b.Members {
+			if email, ok := strings.CutPrefix(m, memberGroup); ok {
+				seen[strings.ToLower(email)] = struct{}{}
+			}
+		}
+	}
+	emails := make([]string, 0, len(seen))
+	for email := range seen {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	return emails
+}
@@ -0,0 +1,53 @@
+package gcp
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrGroupMembershipNotSupported Google 群组的创建与成员管理由 Cloud Identity 负责，项目级 IAM 无法操作
+var ErrGroupMembershipNotSupported = errors.New("gcp: google group management requires cloud identity, not supported")
+
+// CreateUserParams GCP 创建服务账号参数
+// Username 作为 accountId (6-30 位小写字母、数字和连字符)，Email 记录在描述中
+type CreateUserParams struct {
+	Username    string
+	DisplayName string
+	Email       string
+}
+
+// serviceAccount IAM 服务账号
+type serviceAccount struct {
+	Name           string `json:"name"`
+	ProjectID      string `json:"projectId"`
+	UniqueID       string `json:"uniqueId"`
+	Email          string `json:"email"`
+	DisplayName    string `json:"displayName"`
+	Description    string `json:"description"`
+	OAuth2ClientID string `json:"oauth2ClientId"`
+	Disabled       bool   `json:"disabled"`
+}
+
+// iamRole IAM 角色 (预定义角色或项目自定义角色)
+type iamRole struct {
+	Name                string   `json:"name"`
+	Title               string   `json:"title"`
+	Description         string   `json:"description"`
+	IncludedPermissions []string `json:"includedPermissions"`
+	Stage               string   `json:"stage"`
+	Deleted             bool     `json:"deleted"`
+}
+
+// iamPolicy 项目 IAM 策略
+type iamPolicy struct {
+	Version  int          `json:"version,omitempty"`
+	Etag     string       `json:"etag,omitempty"`
+	Bindings []iamBinding `json:"bindings"`
+}
+
+// iamBinding 角色绑定
+type iamBinding struct {
+	Role      string          `json:"role"`
+	Members   []string        `json:"members"`
+	Condition json.RawMessage `json:"condition,omitempty"`
+}
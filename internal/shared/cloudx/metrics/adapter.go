@@ -0,0 +1,60 @@
+// Package metrics 云监控指标适配器
+// 统一各云厂商监控服务（阿里云云监控、AWS CloudWatch、腾讯云/华为云/火山引擎云监控）的指标查询，
+// 输出按资源、按自然日聚合的 CPU / 内存 / 网络 / 磁盘 IOPS 数据，供成本优化建议使用
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+// 资源类型（与 CMDB 模型 UID 后缀一致）
+const (
+	ResourceTypeECS   = "ecs"
+	ResourceTypeRDS   = "rds"
+	ResourceTypeRedis = "redis"
+)
+
+// ErrUnsupportedResourceType 云厂商监控不支持的资源类型
+var ErrUnsupportedResourceType = errors.New("unsupported metrics resource type")
+
+// MetricsAdapter 云厂商监控指标适配器接口
+type MetricsAdapter interface {
+	// GetProvider 获取云厂商标识
+	GetProvider() domain.CloudProvider
+
+	// FetchDailyMetrics 拉取一批同地域、同类型资源在时间范围内的日聚合指标
+	// 每个资源每天最多返回一条记录，无数据的日期不返回
+	FetchDailyMetrics(ctx context.Context, params FetchMetricsParams) ([]DailyMetric, error)
+}
+
+// FetchMetricsParams 指标拉取参数
+type FetchMetricsParams struct {
+	Region       string    // 地域
+	ResourceType string    // 资源类型: ecs / rds / redis
+	ResourceIDs  []string  // 资源 ID 列表
+	StartTime    time.Time // 起始时间
+	EndTime      time.Time // 结束时间
+}
+
+// DailyMetric 单个资源的日聚合指标
+type DailyMetric struct {
+	ResourceID    string
+	ResourceType  string
+	Date          string  // YYYY-MM-DD（UTC+8 自然日）
+	CPUAvg        float64 // CPU 日均使用率（%）
+	CPUMax        float64 // CPU 日峰值使用率（%）
+	MemoryAvg     float64 // 内存日均使用率（%）
+	MemoryMax     float64 // 内存日峰值使用率（%）
+	HasMemory     bool    // 是否采集到内存指标（ECS 内存通常依赖监控插件）
+	NetworkInBps  float64 // 入网流量日均速率（字节/秒）
+	NetworkOutBps float64 // 出网流量日均速率（字节/秒）
+	DiskReadIOPS  float64 // 磁盘读 IOPS 日均值
+	DiskWriteIOPS float64 // 磁盘写 IOPS 日均值
+}
+
+// MetricsAdapterCreator 监控指标适配器创建函数
+type MetricsAdapterCreator func(account *domain.CloudAccount) (MetricsAdapter, error)
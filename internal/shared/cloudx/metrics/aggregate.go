@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// 统一指标名称，各云厂商适配器将原始指标映射到这些名称后交给 Aggregator 聚合
+const (
+	MetricCPU           = "cpu"            // CPU 使用率（%）
+	MetricMemory        = "memory"         // 内存使用率（%）
+	MetricNetworkIn     = "net_in"         // 入网速率（字节/秒）
+	MetricNetworkOut    = "net_out"        // 出网速率（字节/秒）
+	MetricDiskReadIOPS  = "disk_read_iops" // 磁盘读 IOPS
+	MetricDiskWriteIOPS = "disk_write_iops"
+)
+
+// dateLocation 日聚合按 UTC+8 自然日切分，与账单日期口径一致
+var dateLocation = time.FixedZone("CST", 8*3600)
+
+// Sample 单个监控数据点（通常为小时粒度）
+type Sample struct {
+	ResourceID string
+	Metric     string
+	Timestamp  time.Time
+	Avg        float64 // 周期内平均值
+	Max        float64 // 周期内最大值，云厂商未返回时与 Avg 相同
+}
+
+// DateOf 返回时间点所属的自然日（YYYY-MM-DD）
+func DateOf(t time.Time) string {
+	return t.In(dateLocation).Format("2006-01-02")
+}
+
+type bucketKey struct {
+	resourceID string
+	date       string
+}
+
+type metricStat struct {
+	sum   float64
+	count int
+	max   float64
+}
+
+func (s *metricStat) add(avg, max float64) {
+	if s.count == 0 || max > s.max {
+		s.max = max
+	}
+	s.sum += avg
+	s.count++
+}
+
+func (s *metricStat) avg() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// Aggregator 将小时粒度数据点聚合为按资源、按自然日的 DailyMetric
+// 日均值为数据点平均值的算术平均，日峰值为数据点最大值中的最大者
+type Aggregator struct {
+	resourceType string
+	buckets      map[bucketKey]map[string]*metricStat
+}
+
+// NewAggregator 创建日聚合器
+func NewAggregator(resourceType string) *Aggregator {
+	return &Aggregator{
+		resourceType: resourceType,
+		buckets:      make(map[bucketKey]map[string]*metricStat),
+	}
+}
+
+// Add 添加数据点
+func (a *Aggregator) Add(s Sample) {
+	if s.ResourceID == "" || s.Metric == "" {
+		return
+	}
+	key := bucketKey{resourceID: s.ResourceID, date: DateOf(s.Timestamp)}
+	stats, ok := a.buckets[key]
+	if !ok {
+		stats = make(map[string]*metricStat)
+		a.buckets[key] = stats
+	}
+	stat, ok := stats[s.Metric]
+	if !ok {
+		stat = &metricStat{}
+		stats[s.Metric] = stat
+	}
+	stat.add(s.Avg, s.Max)
+}
+
+// Result 输出日聚合结果，按资源 ID、日期升序
+func (a *Aggregator) Result() []DailyMetric {
+	result := make([]DailyMetric, 0, len(a.buckets))
+	for key, stats := range a.buckets {
+		daily := DailyMetric{
+			ResourceID:   key.resourceID,
+			ResourceType: a.resourceType,
+			Date:         key.date,
+		}
+		if s, ok := stats[MetricCPU]; ok {
+			daily.CPUAvg = s.avg()
+			daily.CPUMax = s.max
+		}
+		if s, ok := stats[MetricMemory]; ok {
+			daily.MemoryAvg = s.avg()
+			daily.MemoryMax = s.max
+			daily.HasMemory = true
+		}
+		if s, ok := stats[MetricNetworkIn]; ok {
+			daily.NetworkInBps = s.avg()
+		}
+		if s, ok := stats[MetricNetworkOut]; ok {
+			daily.NetworkOutBps = s.avg()
+		}
+		if s, ok := stats[MetricDiskReadIOPS]; ok {
+			daily.DiskReadIOPS = s.avg()
+		}
+		if s, ok := stats[MetricDiskWriteIOPS]; ok {
+			daily.DiskWriteIOPS = s.avg()
+		}
+		result = append(result, daily)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].ResourceID != result[j].ResourceID {
+			return result[i].ResourceID < result[j].ResourceID
+		}
+		return result[i].Date < result[j].Date
+	})
+	return result
+}
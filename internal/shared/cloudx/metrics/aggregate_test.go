@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregator_Result(t *testing.T) {
+	agg := NewAggregator(ResourceTypeECS)
+	// 2024-03-01 15:00 UTC 已是 UTC+8 的 03-01 23:00，16:00 UTC 归入 03-02
+	base := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	agg.Add(Sample{ResourceID: "i-1", Metric: MetricCPU, Timestamp: base, Avg: 10, Max: 30})
+	agg.Add(Sample{ResourceID: "i-1", Metric: MetricCPU, Timestamp: base.Add(-time.Hour), Avg: 20, Max: 25})
+	agg.Add(Sample{ResourceID: "i-1", Metric: MetricCPU, Timestamp: base.Add(time.Hour), Avg: 50, Max: 90})
+	agg.Add(Sample{ResourceID: "i-1", Metric: MetricMemory, Timestamp: base, Avg: 40, Max: 45})
+	agg.Add(Sample{ResourceID: "i-1", Metric: MetricNetworkIn, Timestamp: base, Avg: 1024, Max: 2048})
+	agg.Add(Sample{ResourceID: "i-1", Metric: MetricDiskReadIOPS, Timestamp: base, Avg: 12, Max: 12})
+	agg.Add(Sample{ResourceID: "i-0", Metric: MetricCPU, Timestamp: base, Avg: 5, Max: 5})
+	agg.Add(Sample{ResourceID: "", Metric: MetricCPU, Timestamp: base, Avg: 99, Max: 99})
+
+	result := agg.Result()
+	require.Len(t, result, 3)
+
+	assert.Equal(t, "i-0", result[0].ResourceID)
+	assert.False(t, result[0].HasMemory)
+
+	day1 := result[1]
+	assert.Equal(t, "i-1", day1.ResourceID)
+	assert.Equal(t, ResourceTypeECS, day1.ResourceType)
+	assert.Equal(t, "2024-03-01", day1.Date)
+	assert.InDelta(t, 15, day1.CPUAvg, 0.001)
+	assert.Equal(t, 30.0, day1.CPUMax)
+	assert.True(t, day1.HasMemory)
+	assert.Equal(t, 40.0, day1.MemoryAvg)
+	assert.Equal(t, 45.0, day1.MemoryMax)
+	assert.Equal(t, 1024.0, day1.NetworkInBps)
+	assert.Equal(t, 12.0, day1.DiskReadIOPS)
+
+	day2 := result[2]
+	assert.Equal(t, "2024-03-02", day2.Date)
+	assert.Equal(t, 50.0, day2.CPUAvg)
+	assert.Equal(t, 90.0, day2.CPUMax)
+	assert.False(t, day2.HasMemory)
+}
+
+func TestFakeAdapter_FetchDailyMetrics(t *testing.T) {
+	fake := NewFakeAdapter(domain.CloudProviderAliyun)
+	fake.AddMetrics("cn-hangzhou",
+		DailyMetric{ResourceID: "i-1", ResourceType: ResourceTypeECS, Date: "2024-03-01", CPUAvg: 3},
+		DailyMetric{ResourceID: "i-1", ResourceType: ResourceTypeECS, Date: "2024-03-05", CPUAvg: 4},
+		DailyMetric{ResourceID: "i-2", ResourceType: ResourceTypeECS, Date: "2024-03-01", CPUAvg: 5},
+		DailyMetric{ResourceID: "rm-1", ResourceType: ResourceTypeRDS, Date: "2024-03-01", CPUAvg: 6},
+	)
+
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, dateLocation)
+	got, err := fake.FetchDailyMetrics(context.Background(), FetchMetricsParams{
+		Region:       "cn-hangzhou",
+		ResourceType: ResourceTypeECS,
+		ResourceIDs:  []string{"i-1"},
+		StartTime:    start,
+		EndTime:      start.AddDate(0, 0, 2),
+	})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 3.0, got[0].CPUAvg)
+	assert.Len(t, fake.Calls(), 1)
+
+	got, err = fake.FetchDailyMetrics(context.Background(), FetchMetricsParams{Region: "cn-beijing", StartTime: start, EndTime: start})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	fake.SetError(errors.New("boom"))
+	_, err = fake.FetchDailyMetrics(context.Background(), FetchMetricsParams{Region: "cn-hangzhou"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+package aliyun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	aliyuncommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/aliyun"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/cms"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// maxRetries 最大重试次数
+	maxRetries = 3
+	// period 查询周期（秒），小时粒度数据再聚合为日指标
+	period = "3600"
+	// pageLength 单页最大数据点数
+	pageLength = "1440"
+	// batchSize 单次查询的实例数
+	batchSize = 50
+)
+
+// metricDef 云监控指标映射
+type metricDef struct {
+	namespace string
+	name      string
+	metric    string  // 统一指标名称
+	scale     float64 // 单位换算系数
+}
+
+// metricDefs 各资源类型需要拉取的云监控指标
+// ECS 内存指标依赖云监控插件，未安装插件的实例不会返回该指标；网络速率单位为 bit/s，换算为字节/秒
+var metricDefs = map[string][]metricDef{
+	metrics.ResourceTypeECS: {
+		{"acs_ecs_dashboard", "CPUUtilization", metrics.MetricCPU, 1},
+		{"acs_ecs_dashboard", "memory_usedutilization", metrics.MetricMemory, 1},
+		{"acs_ecs_dashboard", "IntranetInRate", metrics.MetricNetworkIn, 1.0 / 8},
+		{"acs_ecs_dashboard", "IntranetOutRate", metrics.MetricNetworkOut, 1.0 / 8},
+		{"acs_ecs_dashboard", "DiskReadIOPS", metrics.MetricDiskReadIOPS, 1},
+		{"acs_ecs_dashboard", "DiskWriteIOPS", metrics.MetricDiskWriteIOPS, 1},
+	},
+	metrics.ResourceTypeRDS: {
+		{"acs_rds_dashboard", "CpuUsage", metrics.MetricCPU, 1},
+		{"acs_rds_dashboard", "MemoryUsage", metrics.MetricMemory, 1},
+	},
+	metrics.ResourceTypeRedis: {
+		{"acs_kvstore", "CpuUsage", metrics.MetricCPU, 1},
+		{"acs_kvstore", "MemoryUsage", metrics.MetricMemory, 1},
+		{"acs_kvstore", "IntranetIn", metrics.MetricNetworkIn, 1024},
+		{"acs_kvstore", "IntranetOut", metrics.MetricNetworkOut, 1024},
+	},
+}
+
+// AliyunMetricsAdapter 阿里云云监控指标适配器
+type AliyunMetricsAdapter struct {
+	account *domain.CloudAccount
+	logger  *elog.Component
+}
+
+func init() {
+	metrics.RegisterMetricsAdapter(domain.CloudProviderAliyun, newAliyunMetricsAdapter)
+}
+
+// newAliyunMetricsAdapter 创建阿里云监控适配器
+// 云监控客户端按地域创建，这里只校验凭证
+func newAliyunMetricsAdapter(account *domain.CloudAccount) (metrics.MetricsAdapter, error) {
+	if account.AccessKeyID == "" || account.AccessKeySecret == "" {
+		return nil, fmt.Errorf("aliyun access key id or secret is empty")
+	}
+	return &AliyunMetricsAdapter{account: account, logger: elog.DefaultLogger}, nil
+}
+
+// GetProvider 获取云厂商标识
+func (a *AliyunMetricsAdapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderAliyun
+}
+
+// FetchDailyMetrics 拉取日聚合指标
+func (a *AliyunMetricsAdapter) FetchDailyMetrics(ctx context.Context, params metrics.FetchMetricsParams) ([]metrics.DailyMetric, error) {
+	defs, ok := metricDefs[params.ResourceType]
+	if !ok {
+		return nil, fmt.Errorf("%w: aliyun %s", metrics.ErrUnsupportedResourceType, params.ResourceType)
+	}
+	if len(params.ResourceIDs) == 0 {
+		return nil, nil
+	}
+
+	client, err := cms.NewClientWithAccessKey(params.Region, a.account.AccessKeyID, a.account.AccessKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aliyun cms client: %w", err)
+	}
+
+	agg := metrics.NewAggregator(params.ResourceType)
+	for start := 0; start < len(params.ResourceIDs); start += batchSize {
+		end := min(start+batchSize, len(params.ResourceIDs))
+		dimensions, err := buildDimensions(params.ResourceIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, def := range defs {
+			if err := a.fetchMetric(ctx, client, def, dimensions, params, agg); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return agg.Result(), nil
+}
+
+// fetchMetric 分页拉取单个指标
+func (a *AliyunMetricsAdapter) fetchMetric(ctx context.Context, client *cms.Client, def metricDef, dimensions string,
+	params metrics.FetchMetricsParams, agg *metrics.Aggregator) error {
+	nextToken := ""
+	for {
+		var response *cms.DescribeMetricListResponse
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			request := cms.CreateDescribeMetricListRequest()
+			request.Namespace = def.namespace
+			request.MetricName = def.name
+			request.Period = period
+			request.Length = pageLength
+			request.Dimensions = dimensions
+			request.StartTime = strconv.FormatInt(params.StartTime.UnixMilli(), 10)
+			request.EndTime = strconv.FormatInt(params.EndTime.UnixMilli(), 10)
+			request.NextToken = nextToken
+
+			resp, err := client.DescribeMetricList(request)
+			if err != nil {
+				return err
+			}
+			response = resp
+			return nil
+		}, aliyuncommon.IsThrottlingError)
+		if err != nil {
+			return fmt.Errorf("describe metric list %s/%s: %w", def.namespace, def.name, err)
+		}
+		if !response.Success {
+			// 指标不存在（如未安装监控插件）时跳过该指标，不影响其他指标
+			a.logger.Warn("[aliyun] DescribeMetricList failed",
+				elog.String("namespace", def.namespace),
+				elog.String("metric", def.name),
+				elog.String("code", response.Code),
+				elog.String("message", response.Message))
+			return nil
+		}
+
+		samples, err := parseDatapoints(response.Datapoints, def)
+		if err != nil {
+			return err
+		}
+		for _, s := range samples {
+			agg.Add(s)
+		}
+
+		if response.NextToken == "" {
+			return nil
+		}
+		nextToken = response.NextToken
+	}
+}
+
+// buildDimensions 构建多实例维度参数
+func buildDimensions(resourceIDs []string) (string, error) {
+	dims := make([]map[string]string, 0, len(resourceIDs))
+	for _, id := range resourceIDs {
+		dims = append(dims, map[string]string{"instanceId": id})
+	}
+	data, err := json.Marshal(dims)
+	if err != nil {
+		return "", fmt.Errorf("marshal aliyun metric dimensions: %w", err)
+	}
+	return string(data), nil
+}
+
+// datapoint 云监控数据点（Datapoints 字段为 JSON 字符串）
+type datapoint struct {
+	Timestamp  int64    `json:"timestamp"`
+	InstanceID string   `json:"instanceId"`
+	Average    *float64 `json:"Average"`
+	Maximum    *float64 `json:"Maximum"`
+	Value      *float64 `json:"Value"`
+}
+
+// parseDatapoints 解析 Datapoints 并换算单位
+// 部分指标只返回 Value 而无 Average/Maximum，此时以 Value 作为平均值和最大值
+func parseDatapoints(raw string, def metricDef) ([]metrics.Sample, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var points []datapoint
+	if err := json.Unmarshal([]byte(raw), &points); err != nil {
+		return nil, fmt.Errorf("parse aliyun datapoints: %w", err)
+	}
+
+	samples := make([]metrics.Sample, 0, len(points))
+	for _, p := range points {
+		avg := p.Average
+		if avg == nil {
+			avg = p.Value
+		}
+		if avg == nil {
+			continue
+		}
+		maxValue := *avg
+		if p.Maximum != nil {
+			maxValue = *p.Maximum
+		}
+		samples = append(samples, metrics.Sample{
+			ResourceID: p.InstanceID,
+			Metric:     def.metric,
+			Timestamp:  time.UnixMilli(p.Timestamp),
+			Avg:        *avg * def.scale,
+			Max:        maxValue * def.scale,
+		})
+	}
+	return samples, nil
+}
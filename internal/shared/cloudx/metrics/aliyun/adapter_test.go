@@ -0,0 +1,83 @@
+package aliyun
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/cms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadFixture 读取 testdata 下录制的云监控 API 响应
+func loadFixture(t *testing.T, name string, out interface{}) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, out))
+}
+
+func TestParseDatapoints(t *testing.T) {
+	var response cms.DescribeMetricListResponse
+	loadFixture(t, "describe_metric_list.json", &response)
+
+	def := metricDef{"acs_ecs_dashboard", "CPUUtilization", metrics.MetricCPU, 1}
+	samples, err := parseDatapoints(response.Datapoints, def)
+	require.NoError(t, err)
+	require.Len(t, samples, 3, "datapoint without value should be skipped")
+
+	assert.Equal(t, "i-bp1a2b3c", samples[0].ResourceID)
+	assert.Equal(t, metrics.MetricCPU, samples[0].Metric)
+	assert.Equal(t, 12.5, samples[0].Avg)
+	assert.Equal(t, 40.2, samples[0].Max)
+	assert.Equal(t, int64(1709280000000), samples[0].Timestamp.UnixMilli())
+
+	assert.Equal(t, "i-bp4d5e6f", samples[2].ResourceID)
+	assert.Equal(t, 3.0, samples[2].Avg)
+	assert.Equal(t, 3.0, samples[2].Max)
+
+	agg := metrics.NewAggregator(metrics.ResourceTypeECS)
+	for _, s := range samples {
+		agg.Add(s)
+	}
+	daily := agg.Result()
+	require.Len(t, daily, 2)
+	assert.Equal(t, "2024-03-01", daily[0].Date)
+	assert.Equal(t, 10.0, daily[0].CPUAvg)
+	assert.Equal(t, 40.2, daily[0].CPUMax)
+}
+
+func TestParseDatapoints_ScaleAndInvalid(t *testing.T) {
+	def := metricDef{"acs_ecs_dashboard", "IntranetInRate", metrics.MetricNetworkIn, 1.0 / 8}
+	samples, err := parseDatapoints(`[{"timestamp":1709280000000,"instanceId":"i-1","Average":800,"Maximum":1600}]`, def)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 100.0, samples[0].Avg)
+	assert.Equal(t, 200.0, samples[0].Max)
+
+	samples, err = parseDatapoints("", def)
+	assert.NoError(t, err)
+	assert.Empty(t, samples)
+
+	_, err = parseDatapoints("{", def)
+	assert.Error(t, err)
+}
+
+func TestBuildDimensions(t *testing.T) {
+	dims, err := buildDimensions([]string{"i-1", "i-2"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"instanceId":"i-1"},{"instanceId":"i-2"}]`, dims)
+}
+
+func TestNewAliyunMetricsAdapter(t *testing.T) {
+	_, err := newAliyunMetricsAdapter(&domain.CloudAccount{})
+	assert.Error(t, err)
+
+	adapter, err := newAliyunMetricsAdapter(&domain.CloudAccount{AccessKeyID: "ak", AccessKeySecret: "sk"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CloudProviderAliyun, adapter.GetProvider())
+	assert.True(t, metrics.IsMetricsProviderRegistered(domain.CloudProviderAliyun))
+}
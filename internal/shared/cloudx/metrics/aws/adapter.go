@@ -0,0 +1,257 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	awscommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/aws"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/gotomicro/ego/core/elog"
+)
+
+const (
+	// maxRetries 最大重试次数
+	maxRetries = 3
+	// period 查询周期（秒）
+	period = 3600
+	// maxQueriesPerRequest GetMetricData 单次请求最多 500 个查询
+	maxQueriesPerRequest = 500
+)
+
+// 统计方式
+const (
+	statAverage = "Average"
+	statMaximum = "Maximum"
+	statSum     = "Sum"
+)
+
+// metricDef CloudWatch 指标映射
+// stat 为 Sum 的指标按周期累计值换算为每秒速率，平均值与峰值均取换算结果
+type metricDef struct {
+	namespace string
+	name      string
+	dimension string
+	metric    string
+	stat      string
+}
+
+// metricDefs 各资源类型需要拉取的 CloudWatch 指标
+// EC2 内存使用率来自 CloudWatch Agent（CWAgent 命名空间），未安装 Agent 时无数据
+var metricDefs = map[string][]metricDef{
+	metrics.ResourceTypeECS: {
+		{"AWS/EC2", "CPUUtilization", "InstanceId", metrics.MetricCPU, statAverage},
+		{"CWAgent", "mem_used_percent", "InstanceId", metrics.MetricMemory, statAverage},
+		{"AWS/EC2", "NetworkIn", "InstanceId", metrics.MetricNetworkIn, statSum},
+		{"AWS/EC2", "NetworkOut", "InstanceId", metrics.MetricNetworkOut, statSum},
+		{"AWS/EC2", "EBSReadOps", "InstanceId", metrics.MetricDiskReadIOPS, statSum},
+		{"AWS/EC2", "EBSWriteOps", "InstanceId", metrics.MetricDiskWriteIOPS, statSum},
+	},
+	metrics.ResourceTypeRDS: {
+		{"AWS/RDS", "CPUUtilization", "DBInstanceIdentifier", metrics.MetricCPU, statAverage},
+		{"AWS/RDS", "ReadIOPS", "DBInstanceIdentifier", metrics.MetricDiskReadIOPS, statAverage},
+		{"AWS/RDS", "WriteIOPS", "DBInstanceIdentifier", metrics.MetricDiskWriteIOPS, statAverage},
+		{"AWS/RDS", "NetworkReceiveThroughput", "DBInstanceIdentifier", metrics.MetricNetworkIn, statAverage},
+		{"AWS/RDS", "NetworkTransmitThroughput", "DBInstanceIdentifier", metrics.MetricNetworkOut, statAverage},
+	},
+	metrics.ResourceTypeRedis: {
+		{"AWS/ElastiCache", "CPUUtilization", "CacheClusterId", metrics.MetricCPU, statAverage},
+		{"AWS/ElastiCache", "DatabaseMemoryUsagePercentage", "CacheClusterId", metrics.MetricMemory, statAverage},
+		{"AWS/ElastiCache", "NetworkBytesIn", "CacheClusterId", metrics.MetricNetworkIn, statSum},
+		{"AWS/ElastiCache", "NetworkBytesOut", "CacheClusterId", metrics.MetricNetworkOut, statSum},
+	},
+}
+
+// query 单个 MetricDataQuery 对应的资源与指标
+type query struct {
+	resourceID string
+	def        metricDef
+	stat       string
+}
+
+// AWSMetricsAdapter AWS CloudWatch 指标适配器
+type AWSMetricsAdapter struct {
+	account *domain.CloudAccount
+	logger  *elog.Component
+}
+
+func init() {
+	metrics.RegisterMetricsAdapter(domain.CloudProviderAWS, newAWSMetricsAdapter)
+}
+
+// newAWSMetricsAdapter 创建 AWS 监控适配器
+// CloudWatch 为地域级服务，客户端在查询时按地域创建
+func newAWSMetricsAdapter(account *domain.CloudAccount) (metrics.MetricsAdapter, error) {
+	if account.AccessKeyID == "" || account.AccessKeySecret == "" {
+		return nil, fmt.Errorf("aws access key id or secret is empty")
+	}
+	return &AWSMetricsAdapter{account: account, logger: elog.DefaultLogger}, nil
+}
+
+// GetProvider 获取云厂商标识
+func (a *AWSMetricsAdapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderAWS
+}
+
+// FetchDailyMetrics 拉取日聚合指标
+func (a *AWSMetricsAdapter) FetchDailyMetrics(ctx context.Context, params metrics.FetchMetricsParams) ([]metrics.DailyMetric, error) {
+	defs, ok := metricDefs[params.ResourceType]
+	if !ok {
+		return nil, fmt.Errorf("%w: aws %s", metrics.ErrUnsupportedResourceType, params.ResourceType)
+	}
+	if len(params.ResourceIDs) == 0 {
+		return nil, nil
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion(params.Region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			a.account.AccessKeyID,
+			a.account.AccessKeySecret,
+			"",
+		)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load aws config: %w", err)
+	}
+	client := cloudwatch.NewFromConfig(cfg)
+
+	queries := buildQueries(params.ResourceIDs, defs)
+	agg := metrics.NewAggregator(params.ResourceType)
+	for start := 0; start < len(queries); start += maxQueriesPerRequest {
+		end := min(start+maxQueriesPerRequest, len(queries))
+		results, err := a.getMetricData(ctx, client, queries[start:end], start, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range parseResults(results, queries) {
+			agg.Add(s)
+		}
+	}
+	return agg.Result(), nil
+}
+
+// getMetricData 分页执行一批查询，offset 为该批查询在全部查询中的起始下标（用于生成唯一 ID）
+func (a *AWSMetricsAdapter) getMetricData(ctx context.Context, client *cloudwatch.Client, batch []query, offset int,
+	params metrics.FetchMetricsParams) ([]cwtypes.MetricDataResult, error) {
+	dataQueries := make([]cwtypes.MetricDataQuery, 0, len(batch))
+	for i, q := range batch {
+		dataQueries = append(dataQueries, cwtypes.MetricDataQuery{
+			Id: aws.String(queryID(offset + i)),
+			MetricStat: &cwtypes.MetricStat{
+				Metric: &cwtypes.Metric{
+					Namespace:  aws.String(q.def.namespace),
+					MetricName: aws.String(q.def.name),
+					Dimensions: []cwtypes.Dimension{{Name: aws.String(q.def.dimension), Value: aws.String(q.resourceID)}},
+				},
+				Period: aws.Int32(period),
+				Stat:   aws.String(q.stat),
+			},
+		})
+	}
+
+	var results []cwtypes.MetricDataResult
+	var nextToken *string
+	for {
+		var output *cloudwatch.GetMetricDataOutput
+		err := retry.WithBackoff(ctx, maxRetries, func() error {
+			out, err := client.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+				StartTime:         aws.Time(params.StartTime),
+				EndTime:           aws.Time(params.EndTime),
+				MetricDataQueries: dataQueries,
+				NextToken:         nextToken,
+			})
+			if err != nil {
+				return err
+			}
+			output = out
+			return nil
+		}, awscommon.IsThrottlingError)
+		if err != nil {
+			return nil, fmt.Errorf("get metric data: %w", err)
+		}
+		results = append(results, output.MetricDataResults...)
+		if output.NextToken == nil || *output.NextToken == "" {
+			return results, nil
+		}
+		nextToken = output.NextToken
+	}
+}
+
+// buildQueries 展开资源 × 指标 × 统计方式的查询列表
+// Average 指标同时查询 Maximum 作为峰值，Sum 指标只查询 Sum
+func buildQueries(resourceIDs []string, defs []metricDef) []query {
+	queries := make([]query, 0, len(resourceIDs)*len(defs)*2)
+	for _, id := range resourceIDs {
+		for _, def := range defs {
+			queries = append(queries, query{resourceID: id, def: def, stat: def.stat})
+			if def.stat == statAverage {
+				queries = append(queries, query{resourceID: id, def: def, stat: statMaximum})
+			}
+		}
+	}
+	return queries
+}
+
+// queryID 查询 ID 需以小写字母开头
+func queryID(index int) string {
+	return fmt.Sprintf("q%d", index)
+}
+
+type sampleKey struct {
+	resourceID string
+	metric     string
+	timestamp  time.Time
+}
+
+// parseResults 合并同一资源、指标、时间点的 Average 与 Maximum 结果
+func parseResults(results []cwtypes.MetricDataResult, queries []query) []metrics.Sample {
+	index := make(map[string]query, len(queries))
+	for i, q := range queries {
+		index[queryID(i)] = q
+	}
+
+	samples := make(map[sampleKey]*metrics.Sample)
+	var order []sampleKey
+	for _, result := range results {
+		q, ok := index[aws.ToString(result.Id)]
+		if !ok {
+			continue
+		}
+		for i, ts := range result.Timestamps {
+			if i >= len(result.Values) {
+				break
+			}
+			value := result.Values[i]
+			if q.stat == statSum {
+				value /= period
+			}
+			key := sampleKey{resourceID: q.resourceID, metric: q.def.metric, timestamp: ts}
+			s, exists := samples[key]
+			if !exists {
+				s = &metrics.Sample{ResourceID: q.resourceID, Metric: q.def.metric, Timestamp: ts, Avg: value, Max: value}
+				samples[key] = s
+				order = append(order, key)
+			}
+			switch q.stat {
+			case statMaximum:
+				s.Max = value
+			default:
+				s.Avg = value
+			}
+		}
+	}
+
+	out := make([]metrics.Sample, 0, len(order))
+	for _, key := range order {
+		out = append(out, *samples[key])
+	}
+	return out
+}
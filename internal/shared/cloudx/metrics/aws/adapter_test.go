@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadFixture 读取 testdata 下录制的 CloudWatch API 响应
+func loadFixture(t *testing.T, name string, out interface{}) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, out))
+}
+
+func TestBuildQueries(t *testing.T) {
+	queries := buildQueries([]string{"i-1", "i-2"}, metricDefs[metrics.ResourceTypeECS])
+	// CPU、内存为 Average + Maximum，网络、磁盘为 Sum
+	require.Len(t, queries, 2*(2*2+4))
+	assert.Equal(t, "i-1", queries[0].resourceID)
+	assert.Equal(t, statAverage, queries[0].stat)
+	assert.Equal(t, statMaximum, queries[1].stat)
+	assert.Equal(t, "i-2", queries[len(queries)-1].resourceID)
+	assert.Equal(t, "q0", queryID(0))
+}
+
+func TestParseResults(t *testing.T) {
+	var results []cwtypes.MetricDataResult
+	loadFixture(t, "get_metric_data.json", &results)
+
+	queries := []query{
+		{resourceID: "i-1", def: metricDefs[metrics.ResourceTypeECS][0], stat: statAverage},
+		{resourceID: "i-1", def: metricDefs[metrics.ResourceTypeECS][0], stat: statMaximum},
+		{resourceID: "i-1", def: metricDefs[metrics.ResourceTypeECS][2], stat: statSum},
+		{resourceID: "i-1", def: metricDefs[metrics.ResourceTypeECS][1], stat: statAverage},
+	}
+	samples := parseResults(results, queries)
+	require.Len(t, samples, 3)
+
+	assert.Equal(t, metrics.MetricCPU, samples[0].Metric)
+	assert.Equal(t, 12.0, samples[0].Avg)
+	assert.Equal(t, 35.0, samples[0].Max)
+	assert.Equal(t, 8.0, samples[1].Avg)
+	assert.Equal(t, 20.0, samples[1].Max)
+
+	// Sum 按周期换算为每秒字节数
+	assert.Equal(t, metrics.MetricNetworkIn, samples[2].Metric)
+	assert.Equal(t, 2000.0, samples[2].Avg)
+	assert.Equal(t, 2000.0, samples[2].Max)
+}
+
+func TestNewAWSMetricsAdapter(t *testing.T) {
+	_, err := newAWSMetricsAdapter(&domain.CloudAccount{})
+	assert.Error(t, err)
+
+	adapter, err := newAWSMetricsAdapter(&domain.CloudAccount{AccessKeyID: "ak", AccessKeySecret: "sk"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CloudProviderAWS, adapter.GetProvider())
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+// FakeAdapter 内存实现的监控指标适配器
+// 用于离线测试优化建议与指标同步流程，数据通过 AddMetrics 预置
+type FakeAdapter struct {
+	provider domain.CloudProvider
+
+	mu      sync.RWMutex
+	metrics map[string][]DailyMetric // region -> 日指标
+	calls   []FetchMetricsParams
+	err     error
+}
+
+// NewFakeAdapter 创建内存监控适配器
+func NewFakeAdapter(provider domain.CloudProvider) *FakeAdapter {
+	return &FakeAdapter{
+		provider: provider,
+		metrics:  make(map[string][]DailyMetric),
+	}
+}
+
+// AddMetrics 预置某地域的日指标
+func (f *FakeAdapter) AddMetrics(region string, metrics ...DailyMetric) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.metrics[region] = append(f.metrics[region], metrics...)
+}
+
+// SetError 设置 FetchDailyMetrics 返回的错误，传 nil 恢复正常
+func (f *FakeAdapter) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Calls 返回已发生的拉取调用参数
+func (f *FakeAdapter) Calls() []FetchMetricsParams {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]FetchMetricsParams(nil), f.calls...)
+}
+
+// Creator 返回固定返回该适配器的创建函数，便于注入到依赖 MetricsAdapterCreator 的服务
+func (f *FakeAdapter) Creator() MetricsAdapterCreator {
+	return func(*domain.CloudAccount) (MetricsAdapter, error) {
+		return f, nil
+	}
+}
+
+// GetProvider 获取云厂商标识
+func (f *FakeAdapter) GetProvider() domain.CloudProvider {
+	return f.provider
+}
+
+// FetchDailyMetrics 按地域、资源类型、资源 ID 与日期范围筛选预置数据
+func (f *FakeAdapter) FetchDailyMetrics(_ context.Context, params FetchMetricsParams) ([]DailyMetric, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, params)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	ids := make(map[string]bool, len(params.ResourceIDs))
+	for _, id := range params.ResourceIDs {
+		ids[id] = true
+	}
+	startDate, endDate := DateOf(params.StartTime), DateOf(params.EndTime)
+
+	var result []DailyMetric
+	for _, m := range f.metrics[params.Region] {
+		if params.ResourceType != "" && m.ResourceType != params.ResourceType {
+			continue
+		}
+		if len(ids) > 0 && !ids[m.ResourceID] {
+			continue
+		}
+		if m.Date < startDate || m.Date > endDate {
+			continue
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
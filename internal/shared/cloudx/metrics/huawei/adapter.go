@@ -0,0 +1,265 @@
+package huawei
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	huaweicommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/huawei"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	ces "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ces/v1"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ces/v1/model"
+	cesregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ces/v1/region"
+)
+
+const (
+	// maxRetries 最大重试次数
+	maxRetries = 3
+	// maxMetricsPerRequest BatchListMetricData 单次最多查询 500 个指标
+	maxMetricsPerRequest = 500
+)
+
+// metricDef 云监控指标映射
+type metricDef struct {
+	namespace string
+	name      string
+	dimension string
+	metric    string
+	scale     float64
+}
+
+// metricDefs 各资源类型需要拉取的云监控指标
+// ECS 内存指标来自 Agent（AGT.ECS 命名空间），未安装 Agent 的实例无数据
+var metricDefs = map[string][]metricDef{
+	metrics.ResourceTypeECS: {
+		{"SYS.ECS", "cpu_util", "instance_id", metrics.MetricCPU, 1},
+		{"AGT.ECS", "mem_usedPercent", "instance_id", metrics.MetricMemory, 1},
+		{"SYS.ECS", "network_incoming_bytes_rate_inband", "instance_id", metrics.MetricNetworkIn, 1},
+		{"SYS.ECS", "network_outgoing_bytes_rate_inband", "instance_id", metrics.MetricNetworkOut, 1},
+		{"SYS.ECS", "disk_read_requests_rate", "instance_id", metrics.MetricDiskReadIOPS, 1},
+		{"SYS.ECS", "disk_write_requests_rate", "instance_id", metrics.MetricDiskWriteIOPS, 1},
+	},
+	metrics.ResourceTypeRDS: {
+		{"SYS.RDS", "rds001_cpu_util", "rds_cluster_id", metrics.MetricCPU, 1},
+		{"SYS.RDS", "rds002_mem_util", "rds_cluster_id", metrics.MetricMemory, 1},
+		{"SYS.RDS", "rds004_bytes_in", "rds_cluster_id", metrics.MetricNetworkIn, 1},
+		{"SYS.RDS", "rds005_bytes_out", "rds_cluster_id", metrics.MetricNetworkOut, 1},
+	},
+	metrics.ResourceTypeRedis: {
+		{"SYS.DCS", "cpu_usage", "dcs_instance_id", metrics.MetricCPU, 1},
+		{"SYS.DCS", "memory_usage", "dcs_instance_id", metrics.MetricMemory, 1},
+		{"SYS.DCS", "instantaneous_input_kbps", "dcs_instance_id", metrics.MetricNetworkIn, 1024},
+		{"SYS.DCS", "instantaneous_output_kbps", "dcs_instance_id", metrics.MetricNetworkOut, 1024},
+	},
+}
+
+// HuaweiMetricsAdapter 华为云云监控（CES）指标适配器
+type HuaweiMetricsAdapter struct {
+	account *domain.CloudAccount
+	logger  *elog.Component
+}
+
+func init() {
+	metrics.RegisterMetricsAdapter(domain.CloudProviderHuawei, newHuaweiMetricsAdapter)
+}
+
+// newHuaweiMetricsAdapter 创建华为云监控适配器
+// CES 为地域级服务，客户端在查询时按地域创建
+func newHuaweiMetricsAdapter(account *domain.CloudAccount) (metrics.MetricsAdapter, error) {
+	if account.AccessKeyID == "" || account.AccessKeySecret == "" {
+		return nil, fmt.Errorf("huawei cloud access key id or secret is empty")
+	}
+	return &HuaweiMetricsAdapter{account: account, logger: elog.DefaultLogger}, nil
+}
+
+// GetProvider 获取云厂商标识
+func (a *HuaweiMetricsAdapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderHuawei
+}
+
+// FetchDailyMetrics 拉取日聚合指标
+// 平均值与最大值需分别以 average / max 过滤方式查询
+func (a *HuaweiMetricsAdapter) FetchDailyMetrics(ctx context.Context, params metrics.FetchMetricsParams) ([]metrics.DailyMetric, error) {
+	defs, ok := metricDefs[params.ResourceType]
+	if !ok {
+		return nil, fmt.Errorf("%w: huawei %s", metrics.ErrUnsupportedResourceType, params.ResourceType)
+	}
+	if len(params.ResourceIDs) == 0 {
+		return nil, nil
+	}
+
+	client, err := a.newClient(params.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := buildMetricInfos(params.ResourceIDs, defs)
+	merger := newSampleMerger(defs)
+	for start := 0; start < len(infos); start += maxMetricsPerRequest {
+		end := min(start+maxMetricsPerRequest, len(infos))
+		for _, filter := range []model.Filter{model.GetFilterEnum().AVERAGE, model.GetFilterEnum().MAX} {
+			response, err := a.batchListMetricData(ctx, client, infos[start:end], filter, params)
+			if err != nil {
+				return nil, err
+			}
+			merger.add(response, filter)
+		}
+	}
+
+	agg := metrics.NewAggregator(params.ResourceType)
+	for _, s := range merger.samples() {
+		agg.Add(s)
+	}
+	return agg.Result(), nil
+}
+
+// newClient 创建指定地域的 CES 客户端
+func (a *HuaweiMetricsAdapter) newClient(regionID string) (*ces.CesClient, error) {
+	region, err := cesregion.SafeValueOf(regionID)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported huawei ces region %s: %w", regionID, err)
+	}
+	auth, err := basic.NewCredentialsBuilder().
+		WithAk(a.account.AccessKeyID).
+		WithSk(a.account.AccessKeySecret).
+		SafeBuild()
+	if err != nil {
+		return nil, fmt.Errorf("创建华为云凭证失败: %w", err)
+	}
+	hcClient, err := ces.CesClientBuilder().
+		WithRegion(region).
+		WithCredential(auth).
+		SafeBuild()
+	if err != nil {
+		return nil, fmt.Errorf("创建华为云 CES 客户端失败: %w", err)
+	}
+	return ces.NewCesClient(hcClient), nil
+}
+
+// batchListMetricData 批量查询指标数据
+func (a *HuaweiMetricsAdapter) batchListMetricData(ctx context.Context, client *ces.CesClient, infos []model.MetricInfo,
+	filter model.Filter, params metrics.FetchMetricsParams) (*model.BatchListMetricDataResponse, error) {
+	periodValue := model.GetBatchPeriodEnum().E_3600
+	var response *model.BatchListMetricDataResponse
+	err := retry.WithBackoff(ctx, maxRetries, func() error {
+		resp, err := client.BatchListMetricData(&model.BatchListMetricDataRequest{
+			Body: &model.BatchListMetricDataRequestBody{
+				Metrics: infos,
+				Period:  &periodValue,
+				Filter:  &filter,
+				From:    params.StartTime.UnixMilli(),
+				To:      params.EndTime.UnixMilli(),
+			},
+		})
+		if err != nil {
+			return err
+		}
+		response = resp
+		return nil
+	}, huaweicommon.IsThrottlingError)
+	if err != nil {
+		return nil, fmt.Errorf("batch list metric data: %w", err)
+	}
+	return response, nil
+}
+
+// buildMetricInfos 展开资源 × 指标的查询列表
+func buildMetricInfos(resourceIDs []string, defs []metricDef) []model.MetricInfo {
+	infos := make([]model.MetricInfo, 0, len(resourceIDs)*len(defs))
+	for _, id := range resourceIDs {
+		for _, def := range defs {
+			infos = append(infos, model.MetricInfo{
+				Namespace:  def.namespace,
+				MetricName: def.name,
+				Dimensions: []model.MetricsDimension{{Name: def.dimension, Value: id}},
+			})
+		}
+	}
+	return infos
+}
+
+type sampleKey struct {
+	resourceID string
+	metric     string
+	timestamp  int64
+}
+
+// sampleMerger 合并 average 与 max 两次查询的结果
+type sampleMerger struct {
+	defs  map[string]metricDef // namespace/metric_name -> 指标映射
+	data  map[sampleKey]*metrics.Sample
+	order []sampleKey
+}
+
+func newSampleMerger(defs []metricDef) *sampleMerger {
+	m := &sampleMerger{
+		defs: make(map[string]metricDef, len(defs)),
+		data: make(map[sampleKey]*metrics.Sample),
+	}
+	for _, def := range defs {
+		m.defs[def.namespace+"/"+def.name] = def
+	}
+	return m
+}
+
+// add 合并一次查询结果
+func (m *sampleMerger) add(response *model.BatchListMetricDataResponse, filter model.Filter) {
+	if response == nil || response.Metrics == nil {
+		return
+	}
+	isMax := filter.Value() == model.GetFilterEnum().MAX.Value()
+	for _, data := range *response.Metrics {
+		namespace := ""
+		if data.Namespace != nil {
+			namespace = *data.Namespace
+		}
+		def, ok := m.defs[namespace+"/"+data.MetricName]
+		if !ok || data.Dimensions == nil {
+			continue
+		}
+		resourceID := ""
+		for _, dim := range *data.Dimensions {
+			if dim.Name == def.dimension {
+				resourceID = dim.Value
+			}
+		}
+		if resourceID == "" {
+			continue
+		}
+		for _, dp := range data.Datapoints {
+			value := dp.Average
+			if isMax {
+				value = dp.Max
+			}
+			if value == nil {
+				continue
+			}
+			v := *value * def.scale
+			key := sampleKey{resourceID: resourceID, metric: def.metric, timestamp: dp.Timestamp}
+			s, exists := m.data[key]
+			if !exists {
+				s = &metrics.Sample{ResourceID: resourceID, Metric: def.metric, Timestamp: time.UnixMilli(dp.Timestamp), Avg: v, Max: v}
+				m.data[key] = s
+				m.order = append(m.order, key)
+			}
+			if isMax {
+				s.Max = v
+			} else {
+				s.Avg = v
+			}
+		}
+	}
+}
+
+// samples 输出合并后的数据点
+func (m *sampleMerger) samples() []metrics.Sample {
+	out := make([]metrics.Sample, 0, len(m.order))
+	for _, key := range m.order {
+		out = append(out, *m.data[key])
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+)
+
+var (
+	// ErrUnsupportedMetricsProvider 不支持的监控云厂商
+	ErrUnsupportedMetricsProvider = errors.New("unsupported metrics provider")
+)
+
+// 全局监控适配器注册表
+var metricsAdapterRegistry = &metricsRegistry{
+	creators: make(map[domain.CloudProvider]MetricsAdapterCreator),
+}
+
+// metricsRegistry 监控适配器注册表
+type metricsRegistry struct {
+	mu       sync.RWMutex
+	creators map[domain.CloudProvider]MetricsAdapterCreator
+}
+
+// RegisterMetricsAdapter 注册监控适配器创建函数
+// 各云厂商包在 init() 中调用此函数注册自己的监控适配器
+func RegisterMetricsAdapter(provider domain.CloudProvider, creator MetricsAdapterCreator) {
+	metricsAdapterRegistry.mu.Lock()
+	defer metricsAdapterRegistry.mu.Unlock()
+	metricsAdapterRegistry.creators[provider] = creator
+}
+
+// GetMetricsAdapter 获取监控适配器创建函数
+func GetMetricsAdapter(provider domain.CloudProvider) (MetricsAdapterCreator, error) {
+	metricsAdapterRegistry.mu.RLock()
+	defer metricsAdapterRegistry.mu.RUnlock()
+
+	creator, ok := metricsAdapterRegistry.creators[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMetricsProvider, provider)
+	}
+	return creator, nil
+}
+
+// GetRegisteredMetricsProviders 获取已注册的监控云厂商列表
+func GetRegisteredMetricsProviders() []domain.CloudProvider {
+	metricsAdapterRegistry.mu.RLock()
+	defer metricsAdapterRegistry.mu.RUnlock()
+
+	providers := make([]domain.CloudProvider, 0, len(metricsAdapterRegistry.creators))
+	for provider := range metricsAdapterRegistry.creators {
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
+// IsMetricsProviderRegistered 检查监控云厂商是否已注册
+func IsMetricsProviderRegistered(provider domain.CloudProvider) bool {
+	metricsAdapterRegistry.mu.RLock()
+	defer metricsAdapterRegistry.mu.RUnlock()
+	_, ok := metricsAdapterRegistry.creators[provider]
+	return ok
+}
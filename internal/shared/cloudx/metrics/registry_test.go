@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterMetricsAdapter(t *testing.T) {
+	testProvider := domain.CloudProvider("test_metrics_provider")
+	fake := NewFakeAdapter(testProvider)
+	RegisterMetricsAdapter(testProvider, fake.Creator())
+	defer func() {
+		metricsAdapterRegistry.mu.Lock()
+		delete(metricsAdapterRegistry.creators, testProvider)
+		metricsAdapterRegistry.mu.Unlock()
+	}()
+
+	assert.True(t, IsMetricsProviderRegistered(testProvider))
+	assert.Contains(t, GetRegisteredMetricsProviders(), testProvider)
+
+	creator, err := GetMetricsAdapter(testProvider)
+	require.NoError(t, err)
+	adapter, err := creator(nil)
+	require.NoError(t, err)
+	assert.Equal(t, testProvider, adapter.GetProvider())
+}
+
+func TestGetMetricsAdapter_UnsupportedProvider(t *testing.T) {
+	creator, err := GetMetricsAdapter("nonexistent_metrics_provider")
+	assert.Nil(t, creator)
+	assert.ErrorIs(t, err, ErrUnsupportedMetricsProvider)
+	assert.False(t, IsMetricsProviderRegistered("nonexistent_metrics_provider"))
+}
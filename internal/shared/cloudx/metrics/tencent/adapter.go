@@ -0,0 +1,220 @@
+package tencent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	tencentcommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/tencent"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common"
+	tchttp "github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/http"
+	"github.com/tencentcloud/tencentcloud-sdk-go/tencentcloud/common/profile"
+)
+
+const (
+	// maxRetries 最大重试次数
+	maxRetries = 3
+	// period 统计周期（秒）
+	period = 3600
+	// batchSize GetMonitorData 单次最多查询 10 个实例
+	batchSize = 10
+
+	monitorService = "monitor"
+	monitorVersion = "2018-07-24"
+	monitorAction  = "GetMonitorData"
+)
+
+// metricDef 云监控指标映射
+type metricDef struct {
+	namespace string
+	name      string
+	dimension string
+	metric    string
+	scale     float64
+}
+
+// mbpsToBytes 带宽 Mbps 换算为字节/秒
+const mbpsToBytes = 1000 * 1000 / 8
+
+// metricDefs 各资源类型需要拉取的云监控指标
+var metricDefs = map[string][]metricDef{
+	metrics.ResourceTypeECS: {
+		{"QCE/CVM", "CpuUsage", "InstanceId", metrics.MetricCPU, 1},
+		{"QCE/CVM", "MemUsage", "InstanceId", metrics.MetricMemory, 1},
+		{"QCE/CVM", "LanIntraffic", "InstanceId", metrics.MetricNetworkIn, mbpsToBytes},
+		{"QCE/CVM", "LanOuttraffic", "InstanceId", metrics.MetricNetworkOut, mbpsToBytes},
+	},
+	metrics.ResourceTypeRDS: {
+		{"QCE/CDB", "CpuUseRate", "InstanceId", metrics.MetricCPU, 1},
+		{"QCE/CDB", "MemoryUseRate", "InstanceId", metrics.MetricMemory, 1},
+		{"QCE/CDB", "BytesReceived", "InstanceId", metrics.MetricNetworkIn, 1},
+		{"QCE/CDB", "BytesSent", "InstanceId", metrics.MetricNetworkOut, 1},
+	},
+	metrics.ResourceTypeRedis: {
+		{"QCE/REDIS_MEM", "CpuUtil", "instanceid", metrics.MetricCPU, 1},
+		{"QCE/REDIS_MEM", "MemUtil", "instanceid", metrics.MetricMemory, 1},
+		{"QCE/REDIS_MEM", "InFlow", "instanceid", metrics.MetricNetworkIn, 1000 / 8},
+		{"QCE/REDIS_MEM", "OutFlow", "instanceid", metrics.MetricNetworkOut, 1000 / 8},
+	},
+}
+
+// TencentMetricsAdapter 腾讯云云监控指标适配器
+// SDK 未引入 monitor 模块，通过通用请求（CommonClient）调用 GetMonitorData
+type TencentMetricsAdapter struct {
+	account *domain.CloudAccount
+	logger  *elog.Component
+}
+
+func init() {
+	metrics.RegisterMetricsAdapter(domain.CloudProviderTencent, newTencentMetricsAdapter)
+}
+
+// newTencentMetricsAdapter 创建腾讯云监控适配器
+func newTencentMetricsAdapter(account *domain.CloudAccount) (metrics.MetricsAdapter, error) {
+	if account.AccessKeyID == "" || account.AccessKeySecret == "" {
+		return nil, fmt.Errorf("tencent cloud secret id or secret key is empty")
+	}
+	return &TencentMetricsAdapter{account: account, logger: elog.DefaultLogger}, nil
+}
+
+// GetProvider 获取云厂商标识
+func (a *TencentMetricsAdapter) GetProvider() domain.CloudProvider {
+	return domain.CloudProviderTencent
+}
+
+// FetchDailyMetrics 拉取日聚合指标
+// 云监控按小时返回平均值，日峰值取小时均值中的最大值
+func (a *TencentMetricsAdapter) FetchDailyMetrics(ctx context.Context, params metrics.FetchMetricsParams) ([]metrics.DailyMetric, error) {
+	defs, ok := metricDefs[params.ResourceType]
+	if !ok {
+		return nil, fmt.Errorf("%w: tencent %s", metrics.ErrUnsupportedResourceType, params.ResourceType)
+	}
+	if len(params.ResourceIDs) == 0 {
+		return nil, nil
+	}
+
+	credential := common.NewCredential(a.account.AccessKeyID, a.account.AccessKeySecret)
+	client := common.NewCommonClient(credential, params.Region, profile.NewClientProfile())
+
+	agg := metrics.NewAggregator(params.ResourceType)
+	for start := 0; start < len(params.ResourceIDs); start += batchSize {
+		end := min(start+batchSize, len(params.ResourceIDs))
+		for _, def := range defs {
+			body, err := a.getMonitorData(ctx, client, def, params.ResourceIDs[start:end], params)
+			if err != nil {
+				return nil, err
+			}
+			samples, err := parseMonitorData(body, def)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range samples {
+				agg.Add(s)
+			}
+		}
+	}
+	return agg.Result(), nil
+}
+
+// getMonitorData 调用 GetMonitorData 并返回原始响应体
+func (a *TencentMetricsAdapter) getMonitorData(ctx context.Context, client *common.Client, def metricDef,
+	resourceIDs []string, params metrics.FetchMetricsParams) ([]byte, error) {
+	var body []byte
+	err := retry.WithBackoff(ctx, maxRetries, func() error {
+		request := tchttp.NewCommonRequest(monitorService, monitorVersion, monitorAction)
+		if err := request.SetActionParameters(buildRequestParams(def, resourceIDs, params)); err != nil {
+			return err
+		}
+		response := tchttp.NewCommonResponse()
+		if err := client.Send(request, response); err != nil {
+			return err
+		}
+		body = response.GetBody()
+		return nil
+	}, tencentcommon.IsThrottlingError)
+	if err != nil {
+		return nil, fmt.Errorf("get monitor data %s/%s: %w", def.namespace, def.name, err)
+	}
+	return body, nil
+}
+
+// buildRequestParams 构建 GetMonitorData 请求参数
+func buildRequestParams(def metricDef, resourceIDs []string, params metrics.FetchMetricsParams) map[string]interface{} {
+	instances := make([]map[string]interface{}, 0, len(resourceIDs))
+	for _, id := range resourceIDs {
+		instances = append(instances, map[string]interface{}{
+			"Dimensions": []map[string]string{{"Name": def.dimension, "Value": id}},
+		})
+	}
+	return map[string]interface{}{
+		"Namespace":  def.namespace,
+		"MetricName": def.name,
+		"Period":     period,
+		"StartTime":  params.StartTime.Format(time.RFC3339),
+		"EndTime":    params.EndTime.Format(time.RFC3339),
+		"Instances":  instances,
+	}
+}
+
+// monitorDataResponse GetMonitorData 响应
+type monitorDataResponse struct {
+	Response struct {
+		DataPoints []struct {
+			Dimensions []struct {
+				Name  string `json:"Name"`
+				Value string `json:"Value"`
+			} `json:"Dimensions"`
+			Timestamps []float64 `json:"Timestamps"`
+			Values     []float64 `json:"Values"`
+		} `json:"DataPoints"`
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+	} `json:"Response"`
+}
+
+// parseMonitorData 解析 GetMonitorData 响应体
+func parseMonitorData(body []byte, def metricDef) ([]metrics.Sample, error) {
+	var resp monitorDataResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parse tencent monitor data: %w", err)
+	}
+	if resp.Response.Error != nil {
+		return nil, fmt.Errorf("tencent monitor error %s: %s", resp.Response.Error.Code, resp.Response.Error.Message)
+	}
+
+	var samples []metrics.Sample
+	for _, dp := range resp.Response.DataPoints {
+		resourceID := ""
+		for _, dim := range dp.Dimensions {
+			if strings.EqualFold(dim.Name, def.dimension) {
+				resourceID = dim.Value
+				break
+			}
+		}
+		if resourceID == "" {
+			continue
+		}
+		for i, ts := range dp.Timestamps {
+			if i >= len(dp.Values) {
+				break
+			}
+			value := dp.Values[i] * def.scale
+			samples = append(samples, metrics.Sample{
+				ResourceID: resourceID,
+				Metric:     def.metric,
+				Timestamp:  time.Unix(int64(ts), 0),
+				Avg:        value,
+				Max:        value,
+			})
+		}
+	}
+	return samples, nil
+}
@@ -0,0 +1,72 @@
+package tencent
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMonitorData(t *testing.T) {
+	body, err := os.ReadFile("testdata/get_monitor_data.json")
+	require.NoError(t, err)
+
+	samples, err := parseMonitorData(body, metricDefs[metrics.ResourceTypeECS][0])
+	require.NoError(t, err)
+	require.Len(t, samples, 4)
+	assert.Equal(t, "ins-3kd9x2a1", samples[0].ResourceID)
+	assert.Equal(t, metrics.MetricCPU, samples[0].Metric)
+	assert.Equal(t, int64(1709222400), samples[0].Timestamp.Unix())
+
+	agg := metrics.NewAggregator(metrics.ResourceTypeECS)
+	for _, s := range samples {
+		agg.Add(s)
+	}
+	daily := agg.Result()
+	require.Len(t, daily, 2)
+	assert.Equal(t, "2024-03-01", daily[0].Date)
+	assert.InDelta(t, 10.0, daily[0].CPUAvg, 0.001)
+	assert.Equal(t, 20.0, daily[0].CPUMax)
+}
+
+func TestParseMonitorData_Error(t *testing.T) {
+	_, err := parseMonitorData([]byte(`{"Response":{"Error":{"Code":"AuthFailure","Message":"denied"}}}`), metricDefs[metrics.ResourceTypeECS][0])
+	assert.ErrorContains(t, err, "AuthFailure")
+
+	_, err = parseMonitorData([]byte(`{`), metricDefs[metrics.ResourceTypeECS][0])
+	assert.Error(t, err)
+}
+
+func TestParseMonitorData_Scale(t *testing.T) {
+	body := []byte(`{"Response":{"DataPoints":[{"Dimensions":[{"Name":"InstanceId","Value":"ins-1"}],"Timestamps":[1709222400],"Values":[8]}]}}`)
+	samples, err := parseMonitorData(body, metricDefs[metrics.ResourceTypeECS][2])
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, float64(mbpsToBytes*8), samples[0].Avg)
+}
+
+func TestBuildRequestParams(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	params := buildRequestParams(metricDefs[metrics.ResourceTypeRedis][0], []string{"crs-1", "crs-2"}, metrics.FetchMetricsParams{
+		StartTime: start,
+		EndTime:   start.AddDate(0, 0, 1),
+	})
+	assert.Equal(t, "QCE/REDIS_MEM", params["Namespace"])
+	assert.Equal(t, "2024-03-01T00:00:00+08:00", params["StartTime"])
+	instances := params["Instances"].([]map[string]interface{})
+	require.Len(t, instances, 2)
+	assert.Equal(t, []map[string]string{{"Name": "instanceid", "Value": "crs-2"}}, instances[1]["Dimensions"])
+}
+
+func TestNewTencentMetricsAdapter(t *testing.T) {
+	_, err := newTencentMetricsAdapter(&domain.CloudAccount{})
+	assert.Error(t, err)
+
+	adapter, err := newTencentMetricsAdapter(&domain.CloudAccount{AccessKeyID: "id", AccessKeySecret: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CloudProviderTencent, adapter.GetProvider())
+}
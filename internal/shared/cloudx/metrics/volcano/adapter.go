@@ -0,0 +1,193 @@
+package volcano
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/retry"
+	volcanocommon "github.com/Havens-blog/e-cam-service/internal/shared/cloudx/common/volcano"
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/gotomicro/ego/core/elog"
+	"github.com/volcengine/volcengine-go-sdk/service/cloudmonitor"
+	"github.com/volcengine/volcengine-go-sdk/volcengine"
+	"github.com/volcengine/volcengine-go-sdk/volcengine/credentials"
+	"github.com/volcengine/volcengine-go-sdk/volcengine/session"
+)
+
+const (
+	// maxRetries 最大重试次数
+	maxRetries = 3
+	// period 统计周期
+	period = "1h"
+	// batchSize 单次查询的实例数
+	batchSize = 10
+	// resourceDimension 火山引擎云监控实例维度名
+	resourceDimension = "ResourceID"
+)
+
+// metricDef 云监控指标映射
+type metricDef struct {
+	namespace    string
+	subNamespace string
+	name         string
+	metric       string
+	scale        float64
+}
+
+// metricDefs 各资源类型需要拉取的云监控指标
+// ECS 内存指标依赖云监控插件；网络速率单位为 bit/s，换算为字节/秒
+var metricDefs = map[string][]metricDef{
+	metrics.ResourceTypeECS: {
+		{"VCM_ECS", "Instance", "CpuTotal", metrics.MetricCPU, 1},
+		{"VCM_ECS", "Instance", "MemoryUsedUtilization", metrics.MetricMemory, 1},
+		{"VCM_ECS", "Instance", "NetRxBits", metrics.MetricNetworkIn, 1.0 / 8},
+		{"VCM_ECS", "Instance", "NetTxBits", metrics.MetricNetworkOut, 1.0 / 8},
+		{"VCM_ECS", "Instance", "DiskReadIOPS", metrics.MetricDiskReadIOPS, 1},
+		{"VCM_ECS", "Instance", "DiskWriteIOPS", metrics.MetricDiskWriteIOPS, 1},
+	},
+	metrics.ResourceTypeRDS: {
+		{"VCM_RDS_MySQL", "resource_monitor", "CpuUtil", metrics.MetricCPU, 1},
+		{"VCM_RDS_MySQL", "resource_monitor", "MemUtil", metrics.MetricMemory, 1},
+	},
+	metrics.ResourceTypeRedis: {
+		{"VCM_Redis", "aggregated_server", "CpuUtil", metrics.MetricCPU, 1},
+		{"VCM_Redis", "aggregated_server", "UsedMemUtil", metrics.MetricMemory, 1},
+	},
+}
+
+// VolcanoMetricsAdapter 火山引擎云监控指标适配器
+type VolcanoMetricsAdapter struct {
+	provider domain.CloudProvider
+	account  *domain.CloudAccount
+	logger   *elog.Component
+}
+
+func init() {
+	metrics.RegisterMetricsAdapter(domain.CloudProviderVolcano, newVolcanoMetricsAdapter)
+	metrics.RegisterMetricsAdapter(domain.CloudProviderVolcengine, newVolcanoMetricsAdapter)
+}
+
+// newVolcanoMetricsAdapter 创建火山引擎监控适配器
+func newVolcanoMetricsAdapter(account *domain.CloudAccount) (metrics.MetricsAdapter, error) {
+	if account.AccessKeyID == "" || account.AccessKeySecret == "" {
+		return nil, fmt.Errorf("volcano access key id or secret is empty")
+	}
+	provider := account.Provider
+	if provider == "" {
+		provider = domain.CloudProviderVolcano
+	}
+	return &VolcanoMetricsAdapter{provider: provider, account: account, logger: elog.DefaultLogger}, nil
+}
+
+// GetProvider 获取云厂商标识
+func (a *VolcanoMetricsAdapter) GetProvider() domain.CloudProvider {
+	return a.provider
+}
+
+// FetchDailyMetrics 拉取日聚合指标
+// 云监控按小时返回平均值，日峰值取小时均值中的最大值
+func (a *VolcanoMetricsAdapter) FetchDailyMetrics(ctx context.Context, params metrics.FetchMetricsParams) ([]metrics.DailyMetric, error) {
+	defs, ok := metricDefs[params.ResourceType]
+	if !ok {
+		return nil, fmt.Errorf("%w: volcano %s", metrics.ErrUnsupportedResourceType, params.ResourceType)
+	}
+	if len(params.ResourceIDs) == 0 {
+		return nil, nil
+	}
+
+	config := volcengine.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials(
+			a.account.AccessKeyID,
+			a.account.AccessKeySecret,
+			"",
+		)).
+		WithRegion(params.Region)
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create volcano cloudmonitor session: %w", err)
+	}
+	client := cloudmonitor.New(sess)
+
+	agg := metrics.NewAggregator(params.ResourceType)
+	for start := 0; start < len(params.ResourceIDs); start += batchSize {
+		end := min(start+batchSize, len(params.ResourceIDs))
+		for _, def := range defs {
+			var output *cloudmonitor.GetMetricDataOutput
+			err := retry.WithBackoff(ctx, maxRetries, func() error {
+				out, err := client.GetMetricDataWithContext(ctx, buildInput(def, params.ResourceIDs[start:end], params))
+				if err != nil {
+					return err
+				}
+				output = out
+				return nil
+			}, volcanocommon.IsThrottlingError)
+			if err != nil {
+				return nil, fmt.Errorf("get metric data %s/%s: %w", def.namespace, def.name, err)
+			}
+			for _, s := range parseMetricData(output, def) {
+				agg.Add(s)
+			}
+		}
+	}
+	return agg.Result(), nil
+}
+
+// buildInput 构建 GetMetricData 请求
+func buildInput(def metricDef, resourceIDs []string, params metrics.FetchMetricsParams) *cloudmonitor.GetMetricDataInput {
+	instances := make([]*cloudmonitor.InstanceForGetMetricDataInput, 0, len(resourceIDs))
+	for _, id := range resourceIDs {
+		instances = append(instances, &cloudmonitor.InstanceForGetMetricDataInput{
+			Dimensions: []*cloudmonitor.DimensionForGetMetricDataInput{{
+				Name:  volcengine.String(resourceDimension),
+				Value: volcengine.String(id),
+			}},
+		})
+	}
+	return &cloudmonitor.GetMetricDataInput{
+		Namespace:    volcengine.String(def.namespace),
+		SubNamespace: volcengine.String(def.subNamespace),
+		MetricName:   volcengine.String(def.name),
+		Period:       volcengine.String(period),
+		StartTime:    volcengine.Int32(int32(params.StartTime.Unix())),
+		EndTime:      volcengine.Int32(int32(params.EndTime.Unix())),
+		Instances:    instances,
+	}
+}
+
+// parseMetricData 解析 GetMetricData 响应
+func parseMetricData(output *cloudmonitor.GetMetricDataOutput, def metricDef) []metrics.Sample {
+	if output == nil || output.Data == nil {
+		return nil
+	}
+	var samples []metrics.Sample
+	for _, result := range output.Data.MetricDataResults {
+		if result == nil {
+			continue
+		}
+		resourceID := ""
+		for _, dim := range result.Dimensions {
+			if dim != nil && volcengine.StringValue(dim.Name) == resourceDimension {
+				resourceID = volcengine.StringValue(dim.Value)
+			}
+		}
+		if resourceID == "" {
+			continue
+		}
+		for _, dp := range result.DataPoints {
+			if dp == nil || dp.Value == nil || dp.Timestamp == nil {
+				continue
+			}
+			value := *dp.Value * def.scale
+			samples = append(samples, metrics.Sample{
+				ResourceID: resourceID,
+				Metric:     def.metric,
+				Timestamp:  time.Unix(int64(*dp.Timestamp), 0),
+				Avg:        value,
+				Max:        value,
+			})
+		}
+	}
+	return samples
+}
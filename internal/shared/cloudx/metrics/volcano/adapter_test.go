@@ -0,0 +1,62 @@
+package volcano
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Havens-blog/e-cam-service/internal/shared/cloudx/metrics"
+	"github.com/Havens-blog/e-cam-service/internal/shared/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/volcengine/volcengine-go-sdk/service/cloudmonitor"
+	"github.com/volcengine/volcengine-go-sdk/volcengine"
+)
+
+func TestParseMetricData(t *testing.T) {
+	data, err := os.ReadFile("testdata/get_metric_data.json")
+	require.NoError(t, err)
+	var output cloudmonitor.GetMetricDataOutput
+	require.NoError(t, json.Unmarshal(data, &output))
+
+	samples := parseMetricData(&output, metricDefs[metrics.ResourceTypeECS][0])
+	require.Len(t, samples, 2)
+	assert.Equal(t, "i-ycb1x2a3b4", samples[0].ResourceID)
+	assert.Equal(t, metrics.MetricCPU, samples[0].Metric)
+
+	agg := metrics.NewAggregator(metrics.ResourceTypeECS)
+	for _, s := range samples {
+		agg.Add(s)
+	}
+	daily := agg.Result()
+	require.Len(t, daily, 1)
+	assert.Equal(t, "2024-03-01", daily[0].Date)
+	assert.Equal(t, 4.0, daily[0].CPUAvg)
+	assert.Equal(t, 6.0, daily[0].CPUMax)
+
+	assert.Empty(t, parseMetricData(nil, metricDefs[metrics.ResourceTypeECS][0]))
+}
+
+func TestBuildInput(t *testing.T) {
+	start := time.Unix(1709222400, 0)
+	input := buildInput(metricDefs[metrics.ResourceTypeRDS][0], []string{"mysql-1", "mysql-2"}, metrics.FetchMetricsParams{
+		StartTime: start,
+		EndTime:   start.Add(24 * time.Hour),
+	})
+	assert.Equal(t, "VCM_RDS_MySQL", volcengine.StringValue(input.Namespace))
+	assert.Equal(t, "1h", volcengine.StringValue(input.Period))
+	assert.Equal(t, int32(1709222400), volcengine.Int32Value(input.StartTime))
+	require.Len(t, input.Instances, 2)
+	assert.Equal(t, "mysql-2", volcengine.StringValue(input.Instances[1].Dimensions[0].Value))
+}
+
+func TestNewVolcanoMetricsAdapter(t *testing.T) {
+	_, err := newVolcanoMetricsAdapter(&domain.CloudAccount{})
+	assert.Error(t, err)
+
+	adapter, err := newVolcanoMetricsAdapter(&domain.CloudAccount{Provider: domain.CloudProviderVolcengine, AccessKeyID: "ak", AccessKeySecret: "sk"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.CloudProviderVolcengine, adapter.GetProvider())
+	assert.True(t, metrics.IsMetricsProviderRegistered(domain.CloudProviderVolcano))
+}
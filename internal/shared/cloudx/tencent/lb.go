@@ -173,9 +173,10 @@ func (a *LBAdapter) ListInstancesWithFilter(ctx context.Context, region string,
 			allInstances[i].Listeners = listeners
 			allInstances[i].ListenerCount = listenerCount
 
-			backendServers, backendServerCount := a.fetchBackendServers(client, allInstances[i].LoadBalancerID)
+			backendServers, backendServerCount, fetched := a.fetchBackendServers(client, allInstances[i].LoadBalancerID)
 			allInstances[i].BackendServers = backendServers
 			allInstances[i].BackendServerCount = backendServerCount
+			allInstances[i].BackendFetched = fetched
 		}
 	}
 
@@ -338,8 +339,8 @@ func (a *LBAdapter) fetchListenerDetails(client *clb.Client, lbID string) ([]typ
 	return listeners, len(listeners)
 }
 
-// fetchBackendServers 获取CLB后端服务器详情
-func (a *LBAdapter) fetchBackendServers(client *clb.Client, lbID string) ([]types.LBBackendServer, int) {
+// fetchBackendServers 获取CLB后端服务器详情，查询失败时 fetched 为 false
+func (a *LBAdapter) fetchBackendServers(client *clb.Client, lbID string) ([]types.LBBackendServer, int, bool) {
 	request := clb.NewDescribeTargetsRequest()
 	request.LoadBalancerId = common.StringPtr(lbID)
 
@@ -348,11 +349,11 @@ func (a *LBAdapter) fetchBackendServers(client *clb.Client, lbID string) ([]type
 		a.logger.Warn("获取CLB后端服务器列表失败",
 			elog.String("lb_id", lbID),
 			elog.FieldErr(err))
-		return nil, 0
+		return nil, 0, false
 	}
 
 	if response.Response.Listeners == nil {
-		return nil, 0
+		return nil, 0, true
 	}
 
 	// 使用 map 去重，同一个后端服务器可能绑定到多个监听器
@@ -402,5 +403,5 @@ func (a *LBAdapter) fetchBackendServers(client *clb.Client, lbID string) ([]type
 		backendServers = append(backendServers, server)
 	}
 
-	return backendServers, len(backendServers)
+	return backendServers, len(backendServers), true
 }
@@ -7,6 +7,7 @@ const (
 	ProviderAliyun  CloudProvider = "aliyun"
 	ProviderAWS     CloudProvider = "aws"
 	ProviderAzure   CloudProvider = "azure"
+	ProviderGCP     CloudProvider = "gcp"
 	ProviderHuawei  CloudProvider = "huawei"
 	ProviderVolcano CloudProvider = "volcano"
 	ProviderTencent CloudProvider = "tencent"
@@ -40,6 +40,7 @@ type LBInstance struct {
 	BackendServerCount int               `json:"backend_server_count"` // 后端服务器数量
 	Listeners          []LBListener      `json:"listeners"`            // 监听器详情列表
 	BackendServers     []LBBackendServer `json:"backend_servers"`      // 后端服务器详情列表
+	BackendFetched     bool              `json:"backend_fetched"`      // 后端服务器是否已成功查询（未查询或查询失败时 BackendServers 不可信）
 
 	// 计费信息
 	ChargeType         string `json:"charge_type"`          // 付费类型: PrePaid/PostPaid
@@ -59,6 +59,18 @@ var statusMapping = map[string]string{
 
 	// 火山引擎 (全大写)
 	// RUNNING, STOPPED 等已在上面定义
+
+	// Azure (PowerState/xxx 去掉前缀后，running/stopped/stopping 已在上面定义)
+	"starting":     StatusStarting,
+	"deallocating": StatusStopping,
+	"deallocated":  StatusStopped,
+
+	// GCP Compute Engine (RUNNING/STOPPING 已在上面定义，TERMINATED 表示已停止，由适配器转换)
+	"PROVISIONING": StatusPending,
+	"STAGING":      StatusStarting,
+	"SUSPENDING":   StatusStopping,
+	"SUSPENDED":    StatusStopped,
+	"REPAIRING":    StatusError,
 }
 
 // NormalizeStatus 标准化实例状态
@@ -194,6 +206,19 @@ var rdsStatusMapping = map[string]string{
 	"10": RDSStatusMaintaining, // 实例迁移中
 	"12": RDSStatusMaintaining, // 灾备实例同步中
 	"14": RDSStatusMaintaining, // 版本升级中
+
+	// Azure SQL Database
+	"Online":  RDSStatusRunning,
+	"Paused":  RDSStatusStopped,
+	"Offline": RDSStatusStopped,
+	"Scaling": RDSStatusUpgrading,
+
+	// GCP Cloud SQL
+	"RUNNABLE":       RDSStatusRunning,
+	"SUSPENDED":      RDSStatusStopped,
+	"PENDING_CREATE": RDSStatusCreating,
+	"PENDING_DELETE": RDSStatusDeleting,
+	"MAINTENANCE":    RDSStatusMaintaining,
 }
 
 // NormalizeRDSStatus 标准化RDS实例状态
@@ -273,6 +298,22 @@ var redisStatusMapping = map[string]string{
 	"2":  RedisStatusRunning,     // 运行中
 	"-2": RedisStatusDeleting,    // 已隔离
 	"-3": RedisStatusDeleting,    // 待删除
+
+	// Azure Cache for Redis (provisioningState)
+	"Succeeded": RedisStatusRunning,
+	"Scaling":   RedisStatusChanging,
+	"Updating":  RedisStatusChanging,
+	"Failed":    RedisStatusError,
+	"Disabled":  RedisStatusError,
+
+	// GCP Memorystore (CREATING 已在上面定义)
+	"READY":        RedisStatusRunning,
+	"UPDATING":     RedisStatusChanging,
+	"DELETING":     RedisStatusDeleting,
+	"REPAIRING":    RedisStatusMaintaining,
+	"MAINTENANCE":  RedisStatusMaintaining,
+	"IMPORTING":    RedisStatusMaintaining,
+	"FAILING_OVER": RedisStatusMaintaining,
 }
 
 // NormalizeRedisStatus 标准化Redis实例状态
@@ -367,6 +408,11 @@ var mongoDBStatusMapping = map[string]string{
 	"Restarting": MongoDBStatusRestarting,
 	"Upgrading":  MongoDBStatusUpgrading,
 	"Error":      MongoDBStatusError,
+
+	// Azure Cosmos DB (provisioningState)
+	"Succeeded": MongoDBStatusRunning,
+	"Updating":  MongoDBStatusMaintaining,
+	"Failed":    MongoDBStatusError,
 }
 
 // NormalizeMongoDBStatus 标准化MongoDB实例状态
@@ -93,6 +93,26 @@ func TestNormalizeStatus_Tencent(t *testing.T) {
 	}
 }
 
+func TestNormalizeStatus_GCP(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"RUNNING", StatusRunning},
+		{"PROVISIONING", StatusPending},
+		{"STAGING", StatusStarting},
+		{"STOPPING", StatusStopping},
+		{"SUSPENDING", StatusStopping},
+		{"SUSPENDED", StatusStopped},
+		{"REPAIRING", StatusError},
+	}
+	for _, tt := range tests {
+		t.Run("gcp_"+tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeStatus(tt.input))
+		})
+	}
+}
+
 func TestNormalizeStatus_EdgeCases(t *testing.T) {
 	// 空字符串 → unknown
 	assert.Equal(t, StatusUnknown, NormalizeStatus(""))
@@ -167,6 +187,14 @@ func TestNormalizeRDSStatus(t *testing.T) {
 		{"tencent_deleting", "4", RDSStatusDeleting},
 		{"tencent_isolated", "5", RDSStatusStopped},
 
+		// GCP Cloud SQL
+		{"gcp_runnable", "RUNNABLE", RDSStatusRunning},
+		{"gcp_suspended", "SUSPENDED", RDSStatusStopped},
+		{"gcp_pending_create", "PENDING_CREATE", RDSStatusCreating},
+		{"gcp_pending_delete", "PENDING_DELETE", RDSStatusDeleting},
+		{"gcp_maintenance", "MAINTENANCE", RDSStatusMaintaining},
+		{"gcp_failed", "FAILED", RDSStatusError},
+
 		// 边界情况
 		{"empty", "", RDSStatusUnknown},
 		{"unknown", "weird_status", "weird_status"},
@@ -212,6 +240,13 @@ func TestNormalizeRedisStatus(t *testing.T) {
 		{"tencent_running", "2", RedisStatusRunning},
 		{"tencent_deleting", "-2", RedisStatusDeleting},
 
+		// GCP Memorystore
+		{"gcp_ready", "READY", RedisStatusRunning},
+		{"gcp_creating", "CREATING", RedisStatusCreating},
+		{"gcp_updating", "UPDATING", RedisStatusChanging},
+		{"gcp_deleting", "DELETING", RedisStatusDeleting},
+		{"gcp_failing_over", "FAILING_OVER", RedisStatusMaintaining},
+
 		// 边界
 		{"empty", "", RedisStatusUnknown},
 	}
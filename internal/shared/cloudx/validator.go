@@ -47,6 +47,8 @@ func (f *DefaultCloudValidatorFactory) CreateValidator(provider domain.CloudProv
 		return NewAWSValidator(), nil
 	case domain.CloudProviderAzure:
 		return NewAzureValidator(), nil
+	case domain.CloudProviderGCP:
+		return NewGCPValidator(), nil
 	case domain.CloudProviderTencent:
 		return NewTencentValidator(), nil
 	case domain.CloudProviderHuawei:
@@ -19,11 +19,12 @@ func TestDefaultCloudValidatorFactory_CreateValidator(t *testing.T) {
 		{"阿里云", domain.CloudProviderAliyun, false},
 		{"AWS", domain.CloudProviderAWS, false},
 		{"Azure", domain.CloudProviderAzure, false},
+		{"GCP", domain.CloudProviderGCP, false},
 		{"腾讯云", domain.CloudProviderTencent, false},
 		{"华为云", domain.CloudProviderHuawei, false},
 		{"火山云", domain.CloudProviderVolcano, false},
 		{"火山引擎", domain.CloudProviderVolcengine, false},
-		{"不支持的厂商", domain.CloudProvider("gcp"), true},
+		{"不支持的厂商", domain.CloudProvider("oracle"), true},
 		{"空字符串", domain.CloudProvider(""), true},
 	}
 	for _, tt := range tests {
@@ -290,21 +290,22 @@ func (a *LBAdapter) listALBInstances(ctx context.Context, region string, filter
 
 	// 补充每个 ALB 的监听器详情和后端服务器
 	for i := range albInstances {
-		listeners, serverGroupIDs, listenerCount := a.getALBListenerDetailsAndServerGroups(albClient, albInstances[i].LoadBalancerID)
+		listeners, serverGroupIDs, listenerCount, listenersOK := a.getALBListenerDetailsAndServerGroups(albClient, albInstances[i].LoadBalancerID)
 		albInstances[i].ListenerCount = listenerCount
 		albInstances[i].Listeners = listeners
 
 		// 通过 ServerGroupId 获取后端服务器
-		backendServers := a.getALBBackendServers(albClient, serverGroupIDs)
+		backendServers, serversOK := a.getALBBackendServers(albClient, serverGroupIDs)
 		albInstances[i].BackendServers = backendServers
 		albInstances[i].BackendServerCount = len(backendServers)
+		albInstances[i].BackendFetched = listenersOK && serversOK
 	}
 
 	return albInstances, nil
 }
 
-// getALBListenerDetailsAndServerGroups 获取 ALB 监听器详情和关联的 ServerGroupID
-func (a *LBAdapter) getALBListenerDetailsAndServerGroups(client *alb.ALB, lbID string) ([]types.LBListener, []string, int) {
+// getALBListenerDetailsAndServerGroups 获取 ALB 监听器详情和关联的 ServerGroupID，查询失败时 ok 为 false
+func (a *LBAdapter) getALBListenerDetailsAndServerGroups(client *alb.ALB, lbID string) ([]types.LBListener, []string, int, bool) {
 	var allListeners []types.LBListener
 	serverGroupIDSet := make(map[string]bool)
 	pageNumber := int64(1)
@@ -323,7 +324,7 @@ func (a *LBAdapter) getALBListenerDetailsAndServerGroups(client *alb.ALB, lbID s
 			a.logger.Warn("获取ALB监听器列表失败",
 				elog.String("lb_id", lbID),
 				elog.FieldErr(err))
-			return nil, nil, 0
+			return nil, nil, 0, false
 		}
 
 		if output.TotalCount != nil {
@@ -370,13 +371,14 @@ func (a *LBAdapter) getALBListenerDetailsAndServerGroups(client *alb.ALB, lbID s
 		serverGroupIDs = append(serverGroupIDs, sgID)
 	}
 
-	return allListeners, serverGroupIDs, totalCount
+	return allListeners, serverGroupIDs, totalCount, true
 }
 
-// getALBBackendServers 通过 ServerGroupId 列表获取 ALB 后端服务器
-func (a *LBAdapter) getALBBackendServers(client *alb.ALB, serverGroupIDs []string) []types.LBBackendServer {
+// getALBBackendServers 通过 ServerGroupId 列表获取 ALB 后端服务器，任一服务器组查询失败时 fetched 为 false
+func (a *LBAdapter) getALBBackendServers(client *alb.ALB, serverGroupIDs []string) ([]types.LBBackendServer, bool) {
 	var allServers []types.LBBackendServer
 	seen := make(map[string]bool)
+	fetched := true
 
 	for _, sgID := range serverGroupIDs {
 		input := &alb.DescribeServerGroupBackendServersInput{
@@ -388,6 +390,7 @@ func (a *LBAdapter) getALBBackendServers(client *alb.ALB, serverGroupIDs []strin
 			a.logger.Warn("获取ALB后端服务器失败",
 				elog.String("server_group_id", sgID),
 				elog.FieldErr(err))
+			fetched = false
 			continue
 		}
 
@@ -426,7 +429,7 @@ func (a *LBAdapter) getALBBackendServers(client *alb.ALB, serverGroupIDs []strin
 		}
 	}
 
-	return allServers
+	return allServers, fetched
 }
 
 // enrichCLBDetails 为CLB实例补充监听器和后端服务器详情
@@ -443,9 +446,10 @@ func (a *LBAdapter) enrichCLBDetails(client *clb.CLB, instances []types.LBInstan
 		instances[i].ListenerCount = len(listeners)
 
 		// 通过 DescribeLoadBalancerAttributes 获取 ServerGroup 列表，再查询后端服务器
-		backendServers := a.getCLBBackendServers(client, lbID)
+		backendServers, fetched := a.getCLBBackendServers(client, lbID)
 		instances[i].BackendServers = backendServers
 		instances[i].BackendServerCount = len(backendServers)
+		instances[i].BackendFetched = fetched
 	}
 }
 
@@ -507,7 +511,8 @@ func (a *LBAdapter) getCLBListenerDetails(client *clb.CLB, lbID string) []types.
 }
 
 // getCLBBackendServers 获取 CLB 后端服务器列表 (通过 DescribeLoadBalancerAttributes 获取 ServerGroup，再查询后端)
-func (a *LBAdapter) getCLBBackendServers(client *clb.CLB, lbID string) []types.LBBackendServer {
+// 任一查询失败时 fetched 为 false
+func (a *LBAdapter) getCLBBackendServers(client *clb.CLB, lbID string) ([]types.LBBackendServer, bool) {
 	// 先获取 LB 的 ServerGroup 列表
 	attrInput := &clb.DescribeLoadBalancerAttributesInput{
 		LoadBalancerId: volcengine.String(lbID),
@@ -517,15 +522,16 @@ func (a *LBAdapter) getCLBBackendServers(client *clb.CLB, lbID string) []types.L
 		a.logger.Warn("获取CLB属性失败，跳过后端服务器查询",
 			elog.String("lb_id", lbID),
 			elog.FieldErr(err))
-		return nil
+		return nil, false
 	}
 
 	if len(attrOutput.ServerGroups) == 0 {
-		return nil
+		return nil, true
 	}
 
 	var allServers []types.LBBackendServer
 	seen := make(map[string]struct{})
+	fetched := true
 
 	for _, sg := range attrOutput.ServerGroups {
 		if sg.ServerGroupId == nil {
@@ -542,6 +548,7 @@ func (a *LBAdapter) getCLBBackendServers(client *clb.CLB, lbID string) []types.L
 				elog.String("lb_id", lbID),
 				elog.String("server_group_id", sgID),
 				elog.FieldErr(err))
+			fetched = false
 			continue
 		}
 
@@ -581,7 +588,7 @@ func (a *LBAdapter) getCLBBackendServers(client *clb.CLB, lbID string) []types.L
 		}
 	}
 
-	return allServers
+	return allServers, fetched
 }
 
 // convertToLBInstance 转换为通用LB实例
@@ -9,12 +9,14 @@ import (
 type CloudUserType string
 
 const (
-	CloudUserTypeAPIKey    CloudUserType = "api_key"
-	CloudUserTypeAccessKey CloudUserType = "access_key"
-	CloudUserTypeRAMUser   CloudUserType = "ram_user"  // 阿里云 RAM 用户
-	CloudUserTypeIAMUser   CloudUserType = "iam_user"  // AWS/华为云 IAM 用户
-	CloudUserTypeCAMUser   CloudUserType = "cam_user"  // 腾讯云 CAM 用户
-	CloudUserTypeVolcUser  CloudUserType = "volc_user" // 火山云用户
+	CloudUserTypeAPIKey         CloudUserType = "api_key"
+	CloudUserTypeAccessKey      CloudUserType = "access_key"
+	CloudUserTypeRAMUser        CloudUserType = "ram_user"        // 阿里云 RAM 用户
+	CloudUserTypeIAMUser        CloudUserType = "iam_user"        // AWS/华为云 IAM 用户
+	CloudUserTypeCAMUser        CloudUserType = "cam_user"        // 腾讯云 CAM 用户
+	CloudUserTypeVolcUser       CloudUserType = "volc_user"       // 火山云用户
+	CloudUserTypeEntraUser      CloudUserType = "entra_user"      // Azure Entra ID 用户
+	CloudUserTypeServiceAccount CloudUserType = "service_account" // GCP 服务账号
 )
 
 // CloudUserStatus 用户状态
@@ -27,6 +27,7 @@ const (
 	ProviderAliyun  = domain.ProviderAliyun
 	ProviderAWS     = domain.ProviderAWS
 	ProviderAzure   = domain.ProviderAzure
+	ProviderGCP     = domain.ProviderGCP
 	ProviderHuawei  = domain.ProviderHuawei
 	ProviderVolcano = domain.ProviderVolcano
 	ProviderTencent = domain.ProviderTencent
@@ -394,7 +394,8 @@ func (e *SyncAssetsExecutor) syncRegionAssets(
 					"bandwidth": inst.Bandwidth, "zone": inst.Zone,
 					"load_balancer_spec": inst.LoadBalancerSpec,
 					"listener_count":     inst.ListenerCount, "backend_server_count": inst.BackendServerCount,
-					"charge_type": inst.ChargeType, "creation_time": inst.CreationTime,
+					"backend_fetched": inst.BackendFetched,
+					"charge_type":     inst.ChargeType, "creation_time": inst.CreationTime,
 					"cloud_account_id": account.ID, "cloud_account_name": account.Name,
 					"tags": inst.Tags, "description": inst.Description,
 				}
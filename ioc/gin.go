@@ -137,6 +137,30 @@ func InitWebServer(sp session.Provider, mdls []gin.HandlerFunc, checkPolicy *mid
 		logger.Info("注册采集管理路由")
 		camModule.CollectorHdl.PrivateRoutes(server)
 	}
+	if camModule.ExchangeRateHdl != nil {
+		logger.Info("注册汇率管理路由")
+		camModule.ExchangeRateHdl.PrivateRoutes(server)
+	}
+	if camModule.CommitmentHdl != nil {
+		logger.Info("注册承诺消费分析路由")
+		camModule.CommitmentHdl.PrivateRoutes(server)
+	}
+	if camModule.ReconcileHdl != nil {
+		logger.Info("注册账单对账路由")
+		camModule.ReconcileHdl.PrivateRoutes(server)
+	}
+	if camModule.ChargebackHdl != nil {
+		logger.Info("注册分账单路由")
+		camModule.ChargebackHdl.PrivateRoutes(server)
+	}
+	if camModule.UnitCostHdl != nil {
+		logger.Info("注册单位成本路由")
+		camModule.UnitCostHdl.PrivateRoutes(server)
+	}
+	if camModule.K8sCostHdl != nil {
+		logger.Info("注册容器成本路由")
+		camModule.K8sCostHdl.PrivateRoutes(server)
+	}
 
 	// 注册数据字典路由
 	if camModule.DictHdl != nil {
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/Havens-blog/e-cam-service/internal/cam"
+	costexport "github.com/Havens-blog/e-cam-service/internal/cam/cost/export"
 	"github.com/gotomicro/ego/core/elog"
 	"github.com/gotomicro/ego/task/ecron"
 	"github.com/spf13/viper"
@@ -29,6 +30,19 @@ func InitJobs(camModule *cam.Module) []*ecron.Component {
 	logger := elog.DefaultLogger
 	var jobs []*ecron.Component
 
+	// 汇率同步：每日 1:00 执行 (0 1 * * *)，先于账单采集写入当日汇率
+	if camModule.CostExchangeRateSvc != nil {
+		exchangeSvc := camModule.CostExchangeRateSvc
+		jobs = append(jobs, ecron.DefaultContainer().Build(
+			ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+				logger.Info("开始每日汇率同步")
+				_, err := exchangeSvc.SyncRates(ctx, time.Now())
+				return err
+			})),
+			ecron.WithSpec("0 1 * * *"),
+		))
+	}
+
 	// 账单采集：每 6 小时执行一次 (0 */6 * * *)
 	if camModule.CostCollectorSvc != nil {
 		collectorSvc := camModule.CostCollectorSvc
@@ -39,6 +53,11 @@ func InitJobs(camModule *cam.Module) []*ecron.Component {
 			})),
 			ecron.WithSpec("0 */6 * * *"),
 		))
+
+		// 账单文件目录投递：默认每小时第 15 分钟扫描 (15 * * * *)
+		if job := initCostImportJob(collectorSvc); job != nil {
+			jobs = append(jobs, job)
+		}
 	}
 
 	// 预算检查：每日 8:00 执行 (0 8 * * *)
@@ -78,5 +97,205 @@ func InitJobs(camModule *cam.Module) []*ecron.Component {
 		))
 	}
 
+	// 承诺消费同步与到期提醒：每日 5:00 执行 (0 5 * * *)
+	if camModule.CostCommitmentSvc != nil {
+		commitmentSvc := camModule.CostCommitmentSvc
+		jobs = append(jobs, ecron.DefaultContainer().Build(
+			ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+				logger.Info("开始每日承诺消费同步")
+				if err := commitmentSvc.SyncAll(ctx, ""); err != nil {
+					return err
+				}
+				_, err := commitmentSvc.CheckExpiring(ctx, "", time.Now())
+				return err
+			})),
+			ecron.WithSpec("0 5 * * *"),
+		))
+	}
+
+	// 云监控指标同步：每日 3:30 执行 (30 3 * * *)，早于 7:00 的优化建议生成
+	if camModule.CostMetricsSvc != nil {
+		metricsSvc := camModule.CostMetricsSvc
+		jobs = append(jobs, ecron.DefaultContainer().Build(
+			ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+				logger.Info("开始定时同步云监控指标")
+				return metricsSvc.StartScheduledSync(ctx)
+			})),
+			ecron.WithSpec("30 3 * * *"),
+		))
+	}
+
+	// FOCUS 成本导出：默认每月 2 日 4:00 导出上一自然月 (0 4 2 * *)
+	if camModule.CostExportSvc != nil {
+		if job := initCostExportJob(camModule.CostExportSvc, logger); job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	// 账单对账：默认每日 9:00 对账最近两个已结束自然月 (0 9 * * *)
+	if camModule.CostReconcileSvc != nil {
+		if job := initCostReconcileJob(camModule.CostReconcileSvc, logger); job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	// 分账单：默认每月 3 日 10:00 生成上一自然月分账单并按告警规则推送 (0 10 3 * *)
+	if camModule.CostChargebackSvc != nil {
+		if job := initCostChargebackJob(camModule.CostChargebackSvc, logger); job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
+	// 单位成本异常检测：默认每日 6:30 检测前一日 (30 6 * * *)
+	if camModule.CostUnitCostSvc != nil {
+		if job := initCostUnitCostJob(camModule.CostUnitCostSvc, logger); job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+
 	return jobs
 }
+
+// initCostImportJob 按 cost_import 配置创建账单文件目录投递扫描任务，未启用或未配置投递根目录时返回 nil
+func initCostImportJob(collectorSvc cam.CostCollectorService) *ecron.Component {
+	type Config struct {
+		Enabled bool          `mapstructure:"enabled"`
+		Spec    string        `mapstructure:"spec"`
+		Root    string        `mapstructure:"root"`
+		MinAge  time.Duration `mapstructure:"min_age"`
+	}
+	cfg := Config{Enabled: true, Spec: "15 * * * *", MinAge: 5 * time.Minute}
+	if err := viper.UnmarshalKey("cost_import", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled || cfg.Root == "" {
+		return nil
+	}
+	collectorSvc.SetImportDirs(cfg.Root, cfg.MinAge)
+
+	return ecron.DefaultContainer().Build(
+		ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+			return collectorSvc.ScanImportDirs(ctx)
+		})),
+		ecron.WithSpec(cfg.Spec),
+	)
+}
+
+// initCostExportJob 按 cost_export 配置创建导出存储与定时任务，未启用或存储不可用时返回 nil
+func initCostExportJob(exportSvc cam.CostExportService, logger *elog.Component) *ecron.Component {
+	type Config struct {
+		Enabled bool                     `mapstructure:"enabled"`
+		Spec    string                   `mapstructure:"spec"`
+		Format  string                   `mapstructure:"format"`
+		Sink    string                   `mapstructure:"sink"` // local | oss
+		Dir     string                   `mapstructure:"dir"`
+		OSS     costexport.OSSSinkConfig `mapstructure:"oss"`
+	}
+	cfg := Config{Spec: "0 4 2 * *", Format: costexport.FormatParquet, Sink: "local"}
+	if err := viper.UnmarshalKey("cost_export", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+
+	var (
+		sink costexport.Sink
+		err  error
+	)
+	switch cfg.Sink {
+	case "oss":
+		sink, err = costexport.NewOSSSink(cfg.OSS)
+	default:
+		sink, err = costexport.NewLocalSink(cfg.Dir)
+	}
+	if err != nil {
+		logger.Error("初始化成本导出存储失败，定时导出未启用", elog.FieldErr(err))
+		return nil
+	}
+	exportSvc.SetSink(sink)
+
+	return ecron.DefaultContainer().Build(
+		ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+			logger.Info("开始定时导出 FOCUS 成本数据")
+			return exportSvc.StartScheduledExport(ctx, cfg.Format, time.Now())
+		})),
+		ecron.WithSpec(cfg.Spec),
+	)
+}
+
+// initCostReconcileJob 按 cost_reconcile 配置创建账单对账定时任务，未启用时返回 nil
+func initCostReconcileJob(reconcileSvc cam.CostReconcileService, logger *elog.Component) *ecron.Component {
+	type Config struct {
+		Enabled      bool    `mapstructure:"enabled"`
+		Spec         string  `mapstructure:"spec"`
+		Months       int     `mapstructure:"months"`        // 对账最近几个已结束自然月（覆盖厂商次月调账）
+		TolerancePct float64 `mapstructure:"tolerance_pct"` // 相对容差（%）
+		ToleranceAbs float64 `mapstructure:"tolerance_abs"` // 绝对容差（账单币种）
+	}
+	cfg := Config{Enabled: true, Spec: "0 9 * * *", Months: 2}
+	if err := viper.UnmarshalKey("cost_reconcile", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	reconcileSvc.SetTolerance(cfg.TolerancePct, cfg.ToleranceAbs)
+
+	return ecron.DefaultContainer().Build(
+		ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+			logger.Info("开始每日账单对账")
+			return reconcileSvc.ReconcileAll(ctx, "", cfg.Months, time.Now())
+		})),
+		ecron.WithSpec(cfg.Spec),
+	)
+}
+
+// initCostChargebackJob 按 cost_chargeback 配置创建分账单生成定时任务，未启用时返回 nil
+func initCostChargebackJob(chargebackSvc cam.CostChargebackService, logger *elog.Component) *ecron.Component {
+	type Config struct {
+		Enabled  bool   `mapstructure:"enabled"`
+		Spec     string `mapstructure:"spec"`
+		LinkBase string `mapstructure:"link_base"` // 推送消息中下载链接的站点地址，为空时不附链接
+	}
+	cfg := Config{Spec: "0 10 3 * *"}
+	if err := viper.UnmarshalKey("cost_chargeback", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+	chargebackSvc.SetLinkBase(cfg.LinkBase)
+
+	return ecron.DefaultContainer().Build(
+		ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+			logger.Info("开始生成月度分账单")
+			return chargebackSvc.StartScheduledGeneration(ctx, time.Now())
+		})),
+		ecron.WithSpec(cfg.Spec),
+	)
+}
+
+// initCostUnitCostJob 按 cost_unit_economics 配置创建单位成本异常检测定时任务，未启用时返回 nil
+func initCostUnitCostJob(unitCostSvc cam.CostUnitCostService, logger *elog.Component) *ecron.Component {
+	type Config struct {
+		Enabled bool   `mapstructure:"enabled"`
+		Spec    string `mapstructure:"spec"`
+	}
+	cfg := Config{Enabled: true, Spec: "30 6 * * *"}
+	if err := viper.UnmarshalKey("cost_unit_economics", &cfg); err != nil {
+		panic(err)
+	}
+	if !cfg.Enabled {
+		return nil
+	}
+
+	return ecron.DefaultContainer().Build(
+		ecron.WithJob(ecron.FuncJob(func(ctx context.Context) error {
+			logger.Info("开始每日单位成本异常检测")
+			yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+			return unitCostSvc.DetectUnitCostAnomalies(ctx, "", yesterday)
+		})),
+		ecron.WithSpec(cfg.Spec),
+	)
+}